@@ -0,0 +1,259 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prefixes of settings whose value is read from a secret backend, e.g.
+// "vault:secret/data/narapulse#jwt_secret" or
+// "aws-secretsmanager:narapulse/production#JWT_SECRET". The part after # is
+// the key of the secret; AWS secrets without one are used whole.
+const (
+	vaultSecretPrefix = "vault:"
+	awsSecretPrefix   = "aws-secretsmanager:"
+)
+
+// secretBackendSettings configure the secret backends. They are read as set,
+// never from a backend, and are known settings of the config file.
+var secretBackendSettings = []string{
+	"VAULT_ADDR", "VAULT_TOKEN", "VAULT_NAMESPACE",
+	"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	"AWS_SECRETS_MANAGER_ENDPOINT",
+}
+
+// secretResolver reads the secrets settings reference. Every secret is fetched
+// once per load, however many settings reference it.
+type secretResolver struct {
+	ctx     context.Context
+	client  *http.Client
+	setting func(key string) string
+	now     func() time.Time
+	fetched map[string]map[string]string
+}
+
+func newSecretResolver(ctx context.Context, setting func(key string) string) *secretResolver {
+	return &secretResolver{
+		ctx:     ctx,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		setting: setting,
+		now:     time.Now,
+		fetched: make(map[string]map[string]string),
+	}
+}
+
+// resolve returns the secret a value references, or the value when it is not a reference
+func (r *secretResolver) resolve(value string) (string, error) {
+	var backend, ref string
+	switch {
+	case strings.HasPrefix(value, vaultSecretPrefix):
+		backend, ref = vaultSecretPrefix, strings.TrimPrefix(value, vaultSecretPrefix)
+	case strings.HasPrefix(value, awsSecretPrefix):
+		backend, ref = awsSecretPrefix, strings.TrimPrefix(value, awsSecretPrefix)
+	default:
+		return value, nil
+	}
+
+	name, key := ref, ""
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		name, key = ref[:i], ref[i+1:]
+	}
+	if name == "" {
+		return "", fmt.Errorf("secret reference %q names no secret", value)
+	}
+
+	cacheKey := backend + name
+	secret, ok := r.fetched[cacheKey]
+	if !ok {
+		var err error
+		if backend == vaultSecretPrefix {
+			secret, err = r.fetchVault(name)
+		} else {
+			secret, err = r.fetchAWS(name)
+		}
+		if err != nil {
+			return "", err
+		}
+		r.fetched[cacheKey] = secret
+	}
+
+	if key == "" {
+		if whole, ok := secret[""]; ok {
+			return whole, nil
+		}
+		return "", fmt.Errorf("secret %s holds several values; reference one with #key", name)
+	}
+	v, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", name, key)
+	}
+	return v, nil
+}
+
+// fetchVault reads a secret of a KV version 1 or 2 engine
+func (r *secretResolver) fetchVault(path string) (map[string]string, error) {
+	addr, token := r.setting("VAULT_ADDR"), r.setting("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return nil, errors.New("vault secrets need VAULT_ADDR and VAULT_TOKEN")
+	}
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault secret path %s: %w", path, err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := r.setting("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := r.do(req, "vault", path, &body); err != nil {
+		return nil, err
+	}
+	// KV version 2 nests the secret under data with its metadata next to it
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	return secretValues(data), nil
+}
+
+// fetchAWS reads a secret of AWS Secrets Manager. Secrets holding a JSON
+// object have a value per key; others are used whole.
+func (r *secretResolver) fetchAWS(secretID string) (map[string]string, error) {
+	region := r.setting("AWS_REGION")
+	if region == "" {
+		region = r.setting("AWS_DEFAULT_REGION")
+	}
+	accessKey, secretKey := r.setting("AWS_ACCESS_KEY_ID"), r.setting("AWS_SECRET_ACCESS_KEY")
+	if region == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("AWS Secrets Manager secrets need AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	endpoint := r.setting("AWS_SECRETS_MANAGER_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	payload, _ := json.Marshal(map[string]string{"SecretId": secretID})
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AWS_SECRETS_MANAGER_ENDPOINT: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signAWSRequest(req, payload, region, "secretsmanager", accessKey, secretKey, r.setting("AWS_SESSION_TOKEN"), r.now())
+
+	var body struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := r.do(req, "AWS Secrets Manager", secretID, &body); err != nil {
+		return nil, err
+	}
+	if body.SecretString == nil {
+		return nil, fmt.Errorf("secret %s is binary; only string secrets are supported", secretID)
+	}
+
+	values := map[string]string{"": *body.SecretString}
+	var object map[string]interface{}
+	if json.Unmarshal([]byte(*body.SecretString), &object) == nil {
+		values = secretValues(object)
+	}
+	return values, nil
+}
+
+// do sends a request to a secret backend and decodes its JSON response
+func (r *secretResolver) do(req *http.Request, backend, name string, out interface{}) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s for secret %s: %w", backend, name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d for secret %s: %s", backend, resp.StatusCode, name, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response for secret %s: %w", backend, name, err)
+	}
+	return nil
+}
+
+// secretValues turns the values of a secret into strings; values that are
+// not strings are kept as JSON
+func secretValues(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		values[key] = string(encoded)
+	}
+	return values
+}
+
+// signAWSRequest signs a request without query parameters with AWS Signature Version 4
+func signAWSRequest(req *http.Request, payload []byte, region, service, accessKey, secretKey, sessionToken string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{req.Method, path, "", canonicalHeaders.String(), signedHeaders, sha256Hex(payload)}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
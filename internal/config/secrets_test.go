@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceReadsVaultSecrets(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Vault-Token") != "root" || r.URL.Path != "/v1/secret/data/narapulse" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"data": {"jwt_secret": "from-vault", "smtp_password": "mail"}, "metadata": {"version": 3}}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("JWT_SECRET", "vault:secret/data/narapulse#jwt_secret")
+	t.Setenv("SMTP_PASSWORD", "vault:secret/data/narapulse#smtp_password")
+
+	s, err := NewService(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault", s.Config().JWTSecret)
+	assert.Equal(t, "mail", s.Config().SMTPPassword)
+	assert.Equal(t, 1, requests, "a secret is fetched once per load")
+
+	t.Setenv("SMTP_PASSWORD", "vault:secret/data/narapulse#missing")
+	_, err = NewService(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SMTP_PASSWORD: secret secret/data/narapulse has no key missing")
+}
+
+func TestServiceReadsAWSSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		secrets := map[string]string{
+			"narapulse/production": `{"CREDENTIALS_ENCRYPTION_KEY": "from-aws"}`,
+			"narapulse/metrics":    "scrape-token",
+		}
+		secret, ok := secrets[req.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "ResourceNotFoundException"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": secret})
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_REGION", "ap-southeast-3")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SECRETS_MANAGER_ENDPOINT", server.URL)
+	t.Setenv("CREDENTIALS_ENCRYPTION_KEY", "aws-secretsmanager:narapulse/production#CREDENTIALS_ENCRYPTION_KEY")
+	t.Setenv("METRICS_TOKEN", "aws-secretsmanager:narapulse/metrics")
+
+	s, err := NewService(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "from-aws", s.Config().CredentialsEncryptionKey)
+	assert.Equal(t, "scrape-token", s.Config().MetricsToken, "plain secrets are used whole")
+
+	t.Setenv("METRICS_TOKEN", "aws-secretsmanager:narapulse/missing")
+	_, err = NewService(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "METRICS_TOKEN: AWS Secrets Manager returned 400 for secret narapulse/missing")
+}
+
+func TestSignAWSRequest(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Header = http.Header{}
+	signAWSRequest(req, nil, "us-east-1", "service", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"narapulse-be/internal/pkg/logger"
+)
+
+// defaultService is the service Load returns the configuration of
+var defaultService atomic.Pointer[Service]
+
+// SetDefault makes Load return the configuration of the service
+func SetDefault(s *Service) {
+	defaultService.Store(s)
+}
+
+// ValidationError lists every problem of a configuration, so they can all be
+// fixed at once
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Service loads the configuration in layers: the defaults, the file named by
+// CONFIG_FILE, then the environment, the first one setting a value winning.
+// Values referencing a secret backend are replaced with the secret. The
+// configuration is validated on every load, and a load with problems fails
+// listing all of them.
+//
+// Reloads apply the settings tagged reload:"hot", which are read where they
+// are used; other changes are logged and apply after a restart.
+type Service struct {
+	file    string
+	current atomic.Pointer[Config]
+
+	mu          sync.Mutex // Serializes reloads and guards the fields below
+	fileModTime time.Time
+	listeners   []func(previous, current *Config)
+}
+
+// NewService loads and validates the configuration
+func NewService(ctx context.Context) (*Service, error) {
+	s := &Service{file: os.Getenv("CONFIG_FILE")}
+	cfg, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(cfg)
+	return s, nil
+}
+
+// Config returns the current configuration. It is shared and must not be modified.
+func (s *Service) Config() *Config {
+	return s.current.Load()
+}
+
+// OnReload registers a function called after a reload changed hot settings,
+// to apply them to state built from the configuration
+func (s *Service) OnReload(fn func(previous, current *Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Reload loads the configuration again and applies its hot settings. A
+// configuration with problems is not applied.
+func (s *Service) Reload(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fresh, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	previous := s.Config()
+	next, applied, restartRequired := applyHotSettings(previous, fresh)
+	if len(restartRequired) > 0 {
+		logger.L().Warn().Strs("settings", restartRequired).Msg("Changed settings apply after a restart")
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	s.current.Store(next)
+	for _, fn := range s.listeners {
+		fn(previous, next)
+	}
+	logger.L().Info().Strs("settings", applied).Msg("Configuration reloaded")
+	return nil
+}
+
+// Watch reloads the configuration on SIGHUP, and when the config file
+// changed, checking every interval, until the context is done
+func (s *Service) Watch(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	if s.file != "" && interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-tick:
+			if !s.fileChanged() {
+				continue
+			}
+		}
+		if err := s.Reload(ctx); err != nil {
+			logger.L().Error().Err(err).Msg("Configuration not reloaded")
+		}
+	}
+}
+
+// load reads the configuration from its layers and validates it
+func (s *Service) load(ctx context.Context) (*Config, error) {
+	layers := []layer{envLayer}
+	var settings fileSettings
+	if s.file != "" {
+		info, err := os.Stat(s.file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if settings, err = readConfigFile(s.file); err != nil {
+			return nil, err
+		}
+		s.fileModTime = info.ModTime()
+		layers = append(layers, settings.lookup)
+	}
+
+	l := &loader{layers: layers, seen: make(map[string]bool)}
+	backends := &loader{layers: layers, seen: l.seen}
+	l.secrets = newSecretResolver(ctx, backends.raw)
+	for _, key := range secretBackendSettings {
+		l.seen[key] = true
+	}
+
+	cfg := read(l)
+	problems := append(l.problems, validate(cfg)...)
+	for _, key := range settings.unknown(l.seen) {
+		problems = append(problems, fmt.Sprintf("%s: unknown setting in %s", key, s.file))
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// fileChanged reports whether the config file was modified since it was loaded
+func (s *Service) fileChanged() bool {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !info.ModTime().Equal(s.fileModTime)
+}
+
+// applyHotSettings returns the current configuration with the hot settings of
+// the fresh one, and the names of the changed settings applied and left for a
+// restart
+func applyHotSettings(current, fresh *Config) (*Config, []string, []string) {
+	next := *current
+	nextValue, freshValue := reflect.ValueOf(&next).Elem(), reflect.ValueOf(fresh).Elem()
+
+	var applied, restartRequired []string
+	for i := 0; i < nextValue.NumField(); i++ {
+		field := nextValue.Type().Field(i)
+		if reflect.DeepEqual(nextValue.Field(i).Interface(), freshValue.Field(i).Interface()) {
+			continue
+		}
+		if field.Tag.Get("reload") == "hot" {
+			nextValue.Field(i).Set(freshValue.Field(i))
+			applied = append(applied, field.Name)
+		} else {
+			restartRequired = append(restartRequired, field.Name)
+		}
+	}
+	return &next, applied, restartRequired
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestServiceLayersFileAndEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "narapulse.yaml")
+	writeConfigFile(t, path, "PORT: 9000\nrate_limit_api_per_minute: 120\nLOG_LEVEL: debug\n")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("LOG_LEVEL", "warn")
+
+	s, err := NewService(context.Background())
+	require.NoError(t, err)
+	cfg := s.Config()
+	assert.Equal(t, "9000", cfg.Port, "the file overrides the default")
+	assert.Equal(t, 120, cfg.RateLimitAPIPerMinute, "file settings are case-insensitive")
+	assert.Equal(t, "warn", cfg.LogLevel, "the environment overrides the file")
+	assert.Equal(t, 20, cfg.RateLimitAIPerMinute, "unset settings keep their default")
+}
+
+func TestServiceListsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "narapulse.yaml")
+	writeConfigFile(t, path, "JOB_WORKER: 8\n")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("JOB_MAX_ATTEMPTS", "five")
+	t.Setenv("PII_MASK_MODE", "blur")
+	t.Setenv("ENVIRONMENT", "production")
+
+	_, err := NewService(context.Background())
+	var invalid *ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Contains(t, invalid.Problems, `JOB_MAX_ATTEMPTS: "five" is not an integer`)
+	assert.Contains(t, invalid.Problems, `PII_MASK_MODE: "blur" is not redact or hash`)
+	assert.Contains(t, invalid.Problems, "JWT_SECRET: the default is not allowed in production")
+	assert.Contains(t, invalid.Problems, "JOB_WORKER: unknown setting in "+path)
+}
+
+func TestServiceReloadsHotSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "narapulse.yaml")
+	writeConfigFile(t, path, "RATE_LIMIT_API_PER_MINUTE: 300\nPORT: 8080\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	s, err := NewService(context.Background())
+	require.NoError(t, err)
+	var reloaded *Config
+	s.OnReload(func(_, current *Config) { reloaded = current })
+	before := s.Config()
+
+	// Hot settings apply; others wait for a restart
+	writeConfigFile(t, path, "RATE_LIMIT_API_PER_MINUTE: 60\nPORT: 9000\n")
+	require.NoError(t, s.Reload(context.Background()))
+	assert.Equal(t, 60, s.Config().RateLimitAPIPerMinute)
+	assert.Equal(t, "8080", s.Config().Port)
+	assert.Same(t, s.Config(), reloaded)
+	assert.Equal(t, 300, before.RateLimitAPIPerMinute, "configurations handed out are not changed")
+
+	// A configuration with problems is not applied
+	writeConfigFile(t, path, "RATE_LIMIT_API_PER_MINUTE: lots\n")
+	assert.Error(t, s.Reload(context.Background()))
+	assert.Equal(t, 60, s.Config().RateLimitAPIPerMinute)
+}
+
+func TestServiceDetectsFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "narapulse.yaml")
+	writeConfigFile(t, path, "LOG_LEVEL: info\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	s, err := NewService(context.Background())
+	require.NoError(t, err)
+	assert.False(t, s.fileChanged())
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	assert.True(t, s.fileChanged())
+}
+
+func TestLoadUsesTheDefaultService(t *testing.T) {
+	t.Setenv("RATE_LIMIT_AI_PER_MINUTE", "7")
+	assert.Equal(t, 7, Load().RateLimitAIPerMinute, "without a service the environment is read")
+
+	s, err := NewService(context.Background())
+	require.NoError(t, err)
+	SetDefault(s)
+	defer SetDefault(nil)
+
+	t.Setenv("RATE_LIMIT_AI_PER_MINUTE", "8")
+	assert.Same(t, s.Config(), Load())
+	assert.Equal(t, 7, Load().RateLimitAIPerMinute)
+}
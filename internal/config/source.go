@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layer looks up a setting by its environment variable name
+type layer func(key string) (string, bool)
+
+func envLayer(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// loader reads settings from its layers, the first layer setting a
+// non-empty value winning, and records the problems of the values it cannot
+// use instead of silently falling back to the default
+type loader struct {
+	layers   []layer
+	secrets  *secretResolver // Resolves secret references; nil leaves them as they are
+	seen     map[string]bool // Settings read, to report unknown ones of the config file
+	problems []string
+}
+
+// raw returns the value of a setting as set in the layers
+func (l *loader) raw(key string) string {
+	if l.seen != nil {
+		l.seen[key] = true
+	}
+	for _, lookup := range l.layers {
+		if value, ok := lookup(key); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// value returns the value of a setting with its secret reference resolved
+func (l *loader) value(key string) string {
+	value := l.raw(key)
+	if value == "" || l.secrets == nil {
+		return value
+	}
+	resolved, err := l.secrets.resolve(value)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s: %v", key, err))
+		return ""
+	}
+	return resolved
+}
+
+func (l *loader) getEnv(key, defaultValue string) string {
+	if value := l.value(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (l *loader) getEnvInt(key string, defaultValue int) int {
+	value := l.value(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s: %q is not an integer", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) getEnvFloat(key string, defaultValue float64) float64 {
+	value := l.value(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s: %q is not a number", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) getEnvBool(key string, defaultValue bool) bool {
+	value := l.value(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s: %q is not true or false", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// fileSettings are the settings of a config file by environment variable name
+type fileSettings map[string]string
+
+func (f fileSettings) lookup(key string) (string, bool) {
+	value, ok := f[key]
+	return value, ok
+}
+
+// unknown returns the settings of the file the loader did not read, sorted
+func (f fileSettings) unknown(seen map[string]bool) []string {
+	var keys []string
+	for key := range f {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readConfigFile reads a YAML or JSON file of settings named like their
+// environment variables, e.g. "RATE_LIMIT_API_PER_MINUTE: 300". Names are
+// case-insensitive; values must be scalars.
+func readConfigFile(path string) (fileSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	settings := make(fileSettings, len(values))
+	for key, value := range values {
+		key = strings.ToUpper(key)
+		switch v := value.(type) {
+		case nil:
+			settings[key] = ""
+		case string, bool, int, float64:
+			settings[key] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("config file %s: %s must be a string, number or boolean", path, key)
+		}
+	}
+	return settings, nil
+}
@@ -2,11 +2,14 @@ package connectors
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	entity "narapulse-be/internal/models/entity"
 
 	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -42,11 +45,17 @@ func (b *BigQueryConnector) Connect(config map[string]interface{}) error {
 
 	// Check if service account key is provided
 	var client *bigquery.Client
-	var err error
+	serviceAccountKey, err := bigQueryCredentials(config)
+	if err != nil {
+		return err
+	}
 
-	if serviceAccountKey, ok := config["service_account_key"].(string); ok && serviceAccountKey != "" {
+	if emulatorHost := os.Getenv("BIGQUERY_EMULATOR_HOST"); emulatorHost != "" {
+		// Local emulator used by the integration tests; it does not check credentials
+		client, err = bigquery.NewClient(b.ctx, projectID, option.WithEndpoint("http://"+emulatorHost), option.WithoutAuthentication())
+	} else if serviceAccountKey != nil {
 		// Use service account key
-		client, err = bigquery.NewClient(b.ctx, projectID, option.WithCredentialsJSON([]byte(serviceAccountKey)))
+		client, err = bigquery.NewClient(b.ctx, projectID, option.WithCredentialsJSON(serviceAccountKey))
 	} else {
 		// Use default credentials (ADC - Application Default Credentials)
 		client, err = bigquery.NewClient(b.ctx, projectID)
@@ -226,4 +235,102 @@ func (b *BigQueryConnector) isValidTableName(tableName string) bool {
 		}
 	}
 	return len(tableName) > 0 && len(tableName) <= 1024 // BigQuery table name length limit
-}
\ No newline at end of file
+}
+// EstimateQueryCost runs the query as a dry-run job, which reports the bytes
+// it would process without running it or incurring charges
+func (b *BigQueryConnector) EstimateQueryCost(query string) (*entity.QueryCostEstimate, error) {
+	if b.client == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	q := b.client.Query(query)
+	q.DryRun = true
+	q.DefaultProjectID = b.projectID
+	q.DefaultDatasetID = b.datasetID
+
+	job, err := q.Run(b.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dry-run query: %w", err)
+	}
+
+	status := job.LastStatus()
+	if err := status.Err(); err != nil {
+		return nil, fmt.Errorf("dry-run failed: %w", err)
+	}
+
+	estimate := &entity.QueryCostEstimate{Source: entity.QueryCostSourceDryRun}
+	if status.Statistics != nil {
+		estimate.BytesScanned = status.Statistics.TotalBytesProcessed
+	}
+	return estimate, nil
+}
+
+// ExecuteQuery runs a query and returns up to limit rows. With maxBytesBilled
+// set, BigQuery fails the job rather than bill more than that; see
+// IsBytesBilledLimitExceeded.
+func (b *BigQueryConnector) ExecuteQuery(query string, limit int, maxBytesBilled int64) ([]entity.Column, []map[string]interface{}, error) {
+	if b.client == nil {
+		return nil, nil, fmt.Errorf("no active connection")
+	}
+
+	q := b.client.Query(query)
+	q.DefaultProjectID = b.projectID
+	q.DefaultDatasetID = b.datasetID
+	q.MaxBytesBilled = maxBytesBilled
+
+	it, err := q.Read(b.ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	var rows []map[string]interface{}
+	for limit <= 0 || len(rows) < limit {
+		var row []bigquery.Value
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read row: %w", err)
+		}
+
+		rowMap := make(map[string]interface{}, len(row))
+		for i, field := range it.Schema {
+			if i < len(row) {
+				rowMap[field.Name] = row[i]
+			}
+		}
+		rows = append(rows, rowMap)
+	}
+
+	columns := make([]entity.Column, len(it.Schema))
+	for i, field := range it.Schema {
+		columns[i] = entity.Column{
+			Name:     field.Name,
+			Type:     b.convertFieldType(field.Type),
+			Nullable: !field.Required,
+		}
+	}
+	return columns, rows, nil
+}
+
+// IsBytesBilledLimitExceeded reports whether a query failed because it would
+// have billed more than its maximum bytes billed
+func IsBytesBilledLimitExceeded(err error) bool {
+	const reason = "bytesBilledLimitExceeded"
+
+	// A failed job reports a BigQuery error, a rejected request an API error
+	var jobErr *bigquery.Error
+	if errors.As(err, &jobErr) {
+		return jobErr.Reason == reason
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		for _, item := range apiErr.Errors {
+			if item.Reason == reason {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	appconfig "narapulse-be/internal/config"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+
+	"cloud.google.com/go/bigquery"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// bigQueryDatasetListLimit caps the datasets listed while validating a service account
+const bigQueryDatasetListLimit = 1000
+
+// ServiceAccountKey is the part of a Google Cloud service account key file
+// that identifies the account
+type ServiceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseServiceAccountKey parses a service account key file, reporting the
+// problems that make it unusable as issues
+func ParseServiceAccountKey(credentialsJSON string) (*ServiceAccountKey, []entity.BigQueryCredentialIssue) {
+	var key ServiceAccountKey
+	if err := json.Unmarshal([]byte(credentialsJSON), &key); err != nil {
+		return nil, []entity.BigQueryCredentialIssue{{
+			Code:    entity.BigQueryIssueInvalidJSON,
+			Message: fmt.Sprintf("credentials are not valid JSON: %v", err),
+			Hint:    "Upload the JSON key file downloaded from IAM & Admin > Service Accounts > Keys",
+		}}
+	}
+	if key.Type != "service_account" {
+		return nil, []entity.BigQueryCredentialIssue{{
+			Code:    entity.BigQueryIssueNotServiceAccount,
+			Message: fmt.Sprintf("credentials are of type %q, not service_account", key.Type),
+			Hint:    "OAuth client secrets and user credentials cannot be used; create a key for a service account",
+		}}
+	}
+
+	var issues []entity.BigQueryCredentialIssue
+	for _, field := range []struct{ name, value string }{
+		{"project_id", key.ProjectID},
+		{"private_key", key.PrivateKey},
+		{"client_email", key.ClientEmail},
+	} {
+		if field.value == "" {
+			issues = append(issues, entity.BigQueryCredentialIssue{
+				Code:    entity.BigQueryIssueMissingField,
+				Message: fmt.Sprintf("%s is missing from the key", field.name),
+				Hint:    "The key file was edited or truncated; download a new key",
+			})
+		}
+	}
+	if len(issues) > 0 {
+		return nil, issues
+	}
+	return &key, nil
+}
+
+// bigQueryCredentials returns the service account key of a config: the key of
+// a stored service account, which is encrypted, credentials_json, or the older
+// service_account_key. Nil means Application Default Credentials.
+func bigQueryCredentials(config map[string]interface{}) ([]byte, error) {
+	if encrypted, ok := config["credentials_encrypted"].(string); ok && encrypted != "" {
+		key, err := utils.DecryptSecret(encrypted, appconfig.Load().CredentialsEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt service account key: %w", err)
+		}
+		return []byte(key), nil
+	}
+	for _, field := range []string{"credentials_json", "service_account_key"} {
+		if key, ok := config[field].(string); ok && key != "" {
+			return []byte(key), nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidateBigQueryServiceAccount checks that a service account key can get a
+// token for the BigQuery scope, run query jobs in the project and, when a
+// dataset is given, read it. The datasets the account can list are reported.
+func ValidateBigQueryServiceAccount(ctx context.Context, credentialsJSON, projectID, datasetID string) *entity.BigQueryServiceAccountValidation {
+	return validateBigQueryServiceAccount(ctx, credentialsJSON, projectID, datasetID)
+}
+
+func validateBigQueryServiceAccount(ctx context.Context, credentialsJSON, projectID, datasetID string, opts ...option.ClientOption) *entity.BigQueryServiceAccountValidation {
+	result := &entity.BigQueryServiceAccountValidation{DatasetID: datasetID}
+
+	key, issues := ParseServiceAccountKey(credentialsJSON)
+	if key == nil {
+		result.Issues = issues
+		return result
+	}
+	if projectID == "" {
+		projectID = key.ProjectID
+	}
+	result.ProjectID = projectID
+	result.ClientEmail = key.ClientEmail
+
+	// Getting a token proves the key is live and may be used for the scope
+	credentials, err := google.CredentialsFromJSON(ctx, []byte(credentialsJSON), bigquery.Scope)
+	if err == nil {
+		_, err = credentials.TokenSource.Token()
+	}
+	if err != nil {
+		result.Issues = append(result.Issues, tokenIssue(err, key.ClientEmail))
+		return result
+	}
+	result.Scopes = []string{bigquery.Scope}
+
+	client, err := bigquery.NewClient(ctx, projectID, append([]option.ClientOption{option.WithCredentials(credentials)}, opts...)...)
+	if err != nil {
+		result.Issues = append(result.Issues, entity.BigQueryCredentialIssue{Code: entity.BigQueryIssueUnknown, Message: err.Error()})
+		return result
+	}
+	defer client.Close()
+
+	// A dry run needs bigquery.jobs.create on the project, like every query
+	q := client.Query("SELECT 1")
+	q.DryRun = true
+	if _, err := q.Run(ctx); err != nil {
+		result.Issues = append(result.Issues, classifyBigQueryError(err, entity.BigQueryIssueJobDenied, key.ClientEmail, projectID, ""))
+	} else {
+		result.CanRunQueries = true
+	}
+
+	it := client.Datasets(ctx)
+	for len(result.Datasets) < bigQueryDatasetListLimit {
+		dataset, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			result.Issues = append(result.Issues, classifyBigQueryError(err, entity.BigQueryIssueListDenied, key.ClientEmail, projectID, ""))
+			break
+		}
+		result.Datasets = append(result.Datasets, dataset.DatasetID)
+	}
+
+	if datasetID != "" {
+		if _, err := client.Dataset(datasetID).Metadata(ctx); err != nil {
+			result.Issues = append(result.Issues, classifyBigQueryError(err, entity.BigQueryIssueDatasetDenied, key.ClientEmail, projectID, datasetID))
+		} else {
+			result.DatasetAccess = true
+		}
+	}
+
+	result.Valid = result.CanRunQueries && (datasetID == "" || result.DatasetAccess)
+	return result
+}
+
+// tokenIssue explains why Google refused a token for the key
+func tokenIssue(err error, clientEmail string) entity.BigQueryCredentialIssue {
+	issue := entity.BigQueryCredentialIssue{
+		Code:    entity.BigQueryIssueKeyRejected,
+		Message: fmt.Sprintf("Google rejected the key: %v", err),
+		Hint:    "Check that the private key was copied completely",
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		return issue
+	}
+
+	// The JWT flow does not parse the error of the token response
+	response := struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}{retrieveErr.ErrorCode, retrieveErr.ErrorDescription}
+	if response.Error == "" {
+		json.Unmarshal(retrieveErr.Body, &response)
+	}
+	if response.Error == "invalid_grant" {
+		issue.Message = fmt.Sprintf("Google rejected the key of %s: %s", clientEmail, response.Description)
+		issue.Hint = "The key was deleted or the service account is disabled; create a new key in IAM & Admin > Service Accounts"
+	}
+	return issue
+}
+
+// classifyBigQueryError turns an API error into an issue naming the missing
+// role or setting. Permission errors are reported with the given code.
+func classifyBigQueryError(err error, deniedCode entity.BigQueryCredentialIssueCode, clientEmail, projectID, datasetID string) entity.BigQueryCredentialIssue {
+	issue := entity.BigQueryCredentialIssue{Code: entity.BigQueryIssueUnknown, Message: err.Error()}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return issue
+	}
+	issue.Message = apiErr.Message
+
+	reasons := make(map[string]bool, len(apiErr.Errors))
+	for _, item := range apiErr.Errors {
+		reasons[item.Reason] = true
+	}
+
+	switch {
+	case reasons["accessNotConfigured"] || strings.Contains(apiErr.Message, "has not been used in project") || strings.Contains(apiErr.Message, "is disabled"):
+		issue.Code = entity.BigQueryIssueAPIDisabled
+		issue.Hint = fmt.Sprintf("Enable the BigQuery API in APIs & Services of project %s", projectID)
+	case apiErr.Code == http.StatusNotFound && datasetID != "":
+		issue.Code = entity.BigQueryIssueDatasetNotFound
+		issue.Hint = fmt.Sprintf("Dataset %s does not exist in project %s; check the dataset ID and its project", datasetID, projectID)
+	case apiErr.Code == http.StatusNotFound:
+		issue.Code = entity.BigQueryIssueProjectNotFound
+		issue.Hint = fmt.Sprintf("Project %s does not exist or %s cannot see it", projectID, clientEmail)
+	case apiErr.Code == http.StatusForbidden || reasons["accessDenied"]:
+		issue.Code = deniedCode
+		switch deniedCode {
+		case entity.BigQueryIssueJobDenied:
+			issue.Hint = fmt.Sprintf("Grant %s the BigQuery Job User role (roles/bigquery.jobUser) on project %s", clientEmail, projectID)
+		case entity.BigQueryIssueDatasetDenied:
+			issue.Hint = fmt.Sprintf("Grant %s the BigQuery Data Viewer role (roles/bigquery.dataViewer) on dataset %s", clientEmail, datasetID)
+		case entity.BigQueryIssueListDenied:
+			issue.Hint = fmt.Sprintf("Grant %s the BigQuery Metadata Viewer role on project %s to list datasets; queries on granted datasets still work", clientEmail, projectID)
+		}
+	}
+	return issue
+}
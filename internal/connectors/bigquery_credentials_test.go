@@ -0,0 +1,167 @@
+package connectors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+// newFakeBigQuery serves a token endpoint and the BigQuery calls made while
+// validating a service account; the account may run jobs and read "sales" only
+func newFakeBigQuery(t *testing.T) *httptest.Server {
+	writeError := func(w http.ResponseWriter, code int, reason, message string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{"code": code, "message": message, "errors": []map[string]string{{"reason": reason, "message": message}}},
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "Bearer", "expires_in": 3600})
+		case "/projects/acme/jobs":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"jobReference": map[string]string{"projectId": "acme", "jobId": "dry"},
+				"status":       map[string]string{"state": "DONE"},
+				"statistics":   map[string]interface{}{"query": map[string]string{"totalBytesProcessed": "0"}},
+			})
+		case "/projects/acme/datasets":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"datasets": []map[string]interface{}{{"datasetReference": map[string]string{"projectId": "acme", "datasetId": "sales"}}},
+			})
+		case "/projects/acme/datasets/sales":
+			json.NewEncoder(w).Encode(map[string]interface{}{"datasetReference": map[string]string{"projectId": "acme", "datasetId": "sales"}})
+		case "/projects/acme/datasets/finance":
+			writeError(w, http.StatusForbidden, "accessDenied", "Access Denied: Dataset acme:finance")
+		case "/projects/acme/datasets/missing":
+			writeError(w, http.StatusNotFound, "notFound", "Not found: Dataset acme:missing")
+		case "/projects/other/jobs", "/projects/other/datasets":
+			writeError(w, http.StatusForbidden, "accessDenied", "Access Denied: Project other")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// serviceAccountKeyJSON returns a key file whose tokens come from tokenURI
+func serviceAccountKeyJSON(t *testing.T, tokenURI string) string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	key, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "acme",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "reader@acme.iam.gserviceaccount.com",
+		"token_uri":      tokenURI,
+	})
+	require.NoError(t, err)
+	return string(key)
+}
+
+func TestValidateBigQueryServiceAccount(t *testing.T) {
+	server := newFakeBigQuery(t)
+	key := serviceAccountKeyJSON(t, server.URL+"/token")
+	validate := func(projectID, datasetID string) *entity.BigQueryServiceAccountValidation {
+		return validateBigQueryServiceAccount(context.Background(), key, projectID, datasetID, option.WithEndpoint(server.URL))
+	}
+
+	result := validate("", "sales")
+	assert.True(t, result.Valid, "%+v", result.Issues)
+	assert.Equal(t, "acme", result.ProjectID)
+	assert.Equal(t, "reader@acme.iam.gserviceaccount.com", result.ClientEmail)
+	assert.Equal(t, []string{"https://www.googleapis.com/auth/bigquery"}, result.Scopes)
+	assert.Equal(t, []string{"sales"}, result.Datasets)
+	assert.True(t, result.CanRunQueries)
+	assert.True(t, result.DatasetAccess)
+
+	result = validate("", "finance")
+	assert.False(t, result.Valid)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, entity.BigQueryIssueDatasetDenied, result.Issues[0].Code)
+	assert.Contains(t, result.Issues[0].Hint, "roles/bigquery.dataViewer")
+
+	result = validate("", "missing")
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, entity.BigQueryIssueDatasetNotFound, result.Issues[0].Code)
+
+	result = validate("other", "")
+	assert.False(t, result.Valid)
+	require.Len(t, result.Issues, 2)
+	assert.Equal(t, entity.BigQueryIssueJobDenied, result.Issues[0].Code)
+	assert.Contains(t, result.Issues[0].Hint, "roles/bigquery.jobUser")
+	assert.Equal(t, entity.BigQueryIssueListDenied, result.Issues[1].Code)
+}
+
+func TestValidateBigQueryServiceAccount_RejectedKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "Invalid JWT Signature."})
+	}))
+	defer server.Close()
+
+	result := ValidateBigQueryServiceAccount(context.Background(), serviceAccountKeyJSON(t, server.URL), "", "")
+	assert.False(t, result.Valid)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, entity.BigQueryIssueKeyRejected, result.Issues[0].Code)
+	assert.Contains(t, result.Issues[0].Message, "Invalid JWT Signature.")
+	assert.Contains(t, result.Issues[0].Hint, "create a new key")
+}
+
+func TestParseServiceAccountKey(t *testing.T) {
+	_, issues := ParseServiceAccountKey("not json")
+	require.Len(t, issues, 1)
+	assert.Equal(t, entity.BigQueryIssueInvalidJSON, issues[0].Code)
+
+	_, issues = ParseServiceAccountKey(`{"type":"authorized_user","client_id":"x"}`)
+	require.Len(t, issues, 1)
+	assert.Equal(t, entity.BigQueryIssueNotServiceAccount, issues[0].Code)
+
+	_, issues = ParseServiceAccountKey(`{"type":"service_account","project_id":"acme"}`)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "private_key is missing from the key", issues[0].Message)
+	assert.Equal(t, "client_email is missing from the key", issues[1].Message)
+}
+
+func TestBigQueryCredentials(t *testing.T) {
+	t.Setenv("CREDENTIALS_ENCRYPTION_KEY", "test-key")
+	encrypted, err := utils.EncryptSecret(`{"type":"service_account"}`, "test-key")
+	require.NoError(t, err)
+
+	key, err := bigQueryCredentials(map[string]interface{}{"credentials_encrypted": encrypted, "credentials_json": "inline"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"service_account"}`, string(key))
+
+	key, err = bigQueryCredentials(map[string]interface{}{"service_account_key": "legacy"})
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", string(key))
+
+	key, err = bigQueryCredentials(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	t.Setenv("CREDENTIALS_ENCRYPTION_KEY", "rotated")
+	_, err = bigQueryCredentials(map[string]interface{}{"credentials_encrypted": encrypted})
+	assert.ErrorIs(t, err, utils.ErrInvalidCiphertext)
+}
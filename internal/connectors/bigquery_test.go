@@ -1,9 +1,13 @@
 package connectors
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
+	"cloud.google.com/go/bigquery"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
 )
 
 func TestNewBigQueryConnector(t *testing.T) {
@@ -81,4 +85,10 @@ func TestBigQueryConnector_GetData_NoConnection(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, data)
 	assert.Contains(t, err.Error(), "no active connection")
-}
\ No newline at end of file
+}
+func TestIsBytesBilledLimitExceeded(t *testing.T) {
+	assert.True(t, IsBytesBilledLimitExceeded(fmt.Errorf("failed to execute query: %w", &bigquery.Error{Reason: "bytesBilledLimitExceeded"})))
+	assert.True(t, IsBytesBilledLimitExceeded(&googleapi.Error{Code: 400, Errors: []googleapi.ErrorItem{{Reason: "bytesBilledLimitExceeded"}}}))
+	assert.False(t, IsBytesBilledLimitExceeded(&bigquery.Error{Reason: "invalidQuery"}))
+	assert.False(t, IsBytesBilledLimitExceeded(errors.New("timeout")))
+}
@@ -0,0 +1,163 @@
+package connectors
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	entity "narapulse-be/internal/models/entity"
+
+	"google.golang.org/api/iterator"
+)
+
+// ga4ShardPattern matches the daily tables of a GA4 export, e.g. events_20240131
+// or events_intraday_20240131
+var ga4ShardPattern = regexp.MustCompile(`^(.+_)(\d{8})$`)
+
+// ga4ColumnDescriptions documents the export columns whose meaning is not obvious
+// from the name. BigQuery does not ship descriptions for the GA4 export schema.
+var ga4ColumnDescriptions = map[string]string{
+	"event_date":      "Date of the event in the property time zone, as a YYYYMMDD string",
+	"event_timestamp": "Time of the event in microseconds since the epoch (UTC)",
+	"event_name":      "Name of the event, e.g. page_view, session_start, purchase",
+	"event_params":    "Repeated key/value event parameters; read one with (SELECT value.int_value FROM UNNEST(event_params) WHERE key = 'ga_session_id')",
+	"user_pseudo_id":  "Pseudonymous device identifier of the user",
+	"user_id":         "User ID set by the site, when the user is signed in",
+	"user_properties": "Repeated key/value user properties, read with UNNEST(user_properties)",
+	"device":          "Device record: device.category, device.operating_system, device.web_info.browser",
+	"geo":             "Location record: geo.country, geo.region, geo.city",
+	"traffic_source":  "First-touch acquisition record: traffic_source.source, traffic_source.medium, traffic_source.name (campaign)",
+	"ecommerce":       "Ecommerce record: ecommerce.transaction_id, ecommerce.purchase_revenue",
+	"items":           "Repeated items of ecommerce events, read with UNNEST(items)",
+	"platform":        "Stream platform: WEB, IOS or ANDROID",
+}
+
+// GA4Connector reads the BigQuery export of a Google Analytics 4 property. The
+// export writes one table per day; they are registered as a single wildcard
+// table (events_*) whose columns come from the most recent day.
+type GA4Connector struct {
+	*BigQueryConnector
+	latestShards map[string]string // Wildcard table name -> most recent daily table
+}
+
+// NewGA4Connector creates a new GA4 connector
+func NewGA4Connector() *GA4Connector {
+	return &GA4Connector{
+		BigQueryConnector: NewBigQueryConnector(),
+		latestShards:      make(map[string]string),
+	}
+}
+
+// Connect connects to the export dataset, analytics_<property_id> unless
+// dataset_id is set explicitly
+func (g *GA4Connector) Connect(config map[string]interface{}) error {
+	datasetID := GA4DatasetID(config)
+	if datasetID == "" {
+		return fmt.Errorf("property_id or dataset_id is required")
+	}
+
+	bigQueryConfig := make(map[string]interface{}, len(config)+1)
+	for key, value := range config {
+		bigQueryConfig[key] = value
+	}
+	bigQueryConfig["dataset_id"] = datasetID
+
+	return g.BigQueryConnector.Connect(bigQueryConfig)
+}
+
+// GetSchema returns the columns of the export tables. Daily tables are reported
+// once under their wildcard name, using the schema of the latest day.
+func (g *GA4Connector) GetSchema() ([]entity.Column, error) {
+	if g.client == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	var tableIDs []string
+	it := g.client.Dataset(g.datasetID).Tables(g.ctx)
+	for {
+		table, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate tables: %w", err)
+		}
+		tableIDs = append(tableIDs, table.TableID)
+	}
+
+	g.latestShards = LatestGA4Shards(tableIDs)
+
+	names := make([]string, 0, len(g.latestShards))
+	for name := range g.latestShards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var allColumns []entity.Column
+	for _, name := range names {
+		tableID := g.latestShards[name]
+		meta, err := g.client.Dataset(g.datasetID).Table(tableID).Metadata(g.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get table metadata for %s: %w", tableID, err)
+		}
+
+		for _, field := range meta.Schema {
+			description := field.Description
+			if description == "" {
+				description = ga4ColumnDescriptions[field.Name]
+			}
+			allColumns = append(allColumns, entity.Column{
+				Name:        fmt.Sprintf("%s.%s", name, field.Name),
+				Type:        g.convertFieldType(field.Type),
+				Nullable:    !field.Required,
+				Description: description,
+			})
+		}
+	}
+
+	return allColumns, nil
+}
+
+// GetData samples a table; for a wildcard table the latest daily table is read
+func (g *GA4Connector) GetData(tableName string, limit int) ([]map[string]interface{}, error) {
+	if tableID, ok := g.latestShards[tableName]; ok {
+		tableName = tableID
+	}
+	return g.BigQueryConnector.GetData(tableName, limit)
+}
+
+// GA4DatasetID returns the export dataset of a GA4 data source config
+func GA4DatasetID(config map[string]interface{}) string {
+	if datasetID, ok := config["dataset_id"].(string); ok && datasetID != "" {
+		return datasetID
+	}
+	switch propertyID := config["property_id"].(type) {
+	case string:
+		if propertyID != "" {
+			return "analytics_" + propertyID
+		}
+	case float64:
+		return fmt.Sprintf("analytics_%.0f", propertyID)
+	}
+	return ""
+}
+
+// LatestGA4Shards maps each table of an export dataset to the table its schema
+// is read from. Daily tables (events_20240131) are grouped under their wildcard
+// name (events_*) and mapped to the most recent day; other tables map to themselves.
+func LatestGA4Shards(tableIDs []string) map[string]string {
+	latest := make(map[string]string)
+	for _, tableID := range tableIDs {
+		match := ga4ShardPattern.FindStringSubmatch(tableID)
+		if match == nil {
+			latest[tableID] = tableID
+			continue
+		}
+
+		wildcard := match[1] + "*"
+		if current, ok := latest[wildcard]; !ok || tableID > current {
+			latest[wildcard] = tableID
+		}
+	}
+	return latest
+}
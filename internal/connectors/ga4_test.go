@@ -0,0 +1,45 @@
+package connectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGA4DatasetID(t *testing.T) {
+	assert.Equal(t, "analytics_123456", GA4DatasetID(map[string]interface{}{"property_id": "123456"}))
+	assert.Equal(t, "analytics_123456", GA4DatasetID(map[string]interface{}{"property_id": float64(123456)}))
+	assert.Equal(t, "ga4_export", GA4DatasetID(map[string]interface{}{"property_id": "123456", "dataset_id": "ga4_export"}))
+	assert.Equal(t, "", GA4DatasetID(map[string]interface{}{"property_id": ""}))
+}
+
+func TestLatestGA4Shards(t *testing.T) {
+	shards := LatestGA4Shards([]string{
+		"events_20240130",
+		"events_20240131",
+		"events_20240129",
+		"events_intraday_20240201",
+		"pseudonymous_users_20240131",
+		"campaign_costs",
+	})
+
+	assert.Equal(t, map[string]string{
+		"events_*":             "events_20240131",
+		"events_intraday_*":    "events_intraday_20240201",
+		"pseudonymous_users_*": "pseudonymous_users_20240131",
+		"campaign_costs":       "campaign_costs",
+	}, shards)
+}
+
+func TestGA4Connector_Connect_InvalidConfig(t *testing.T) {
+	connector := NewGA4Connector()
+
+	err := connector.Connect(map[string]interface{}{"project_id": "acme"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "property_id or dataset_id is required")
+
+	_, err = connector.GetSchema()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no active connection")
+}
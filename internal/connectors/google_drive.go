@@ -0,0 +1,252 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	googleDriveFolderMimeType = "application/vnd.google-apps.folder"
+	// googleDriveFileFields are the file fields the connector reads
+	googleDriveFileFields = "id,name,mimeType,size,modifiedTime,headRevisionId,md5Checksum"
+	defaultDrivePageSize  = 50
+)
+
+// googleDriveFileExtensions maps the MIME types of importable Drive files to their extension
+var googleDriveFileExtensions = map[string]string{
+	"text/csv":                 ".csv",
+	"application/vnd.ms-excel": ".xls",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
+}
+
+// GoogleDriveOAuthConfig is the OAuth client asking for read-only access to
+// Drive, using the GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET of the app
+func GoogleDriveOAuthConfig(redirectURI string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     getEnvOrDefault("GOOGLE_CLIENT_ID", ""),
+		ClientSecret: getEnvOrDefault("GOOGLE_CLIENT_SECRET", ""),
+		RedirectURL:  redirectURI,
+		Scopes:       []string{drive.DriveReadonlyScope},
+		Endpoint:     google.Endpoint,
+	}
+}
+
+// GoogleDriveConnector lists and downloads CSV and Excel files in Google Drive
+type GoogleDriveConnector struct {
+	service *drive.Service
+	fileID  string
+	ctx     context.Context
+}
+
+// NewGoogleDriveConnector creates a new Google Drive connector
+func NewGoogleDriveConnector() *GoogleDriveConnector {
+	return &GoogleDriveConnector{
+		ctx: context.Background(),
+	}
+}
+
+// Connect creates the Drive client from OAuth tokens, service account
+// credentials or Application Default Credentials. file_id is only required
+// to test the connection or download.
+func (g *GoogleDriveConnector) Connect(config map[string]interface{}) error {
+	g.fileID, _ = config["file_id"].(string)
+
+	// GOOGLE_DRIVE_ENDPOINT points the client at a fake Drive API (integration tests)
+	if endpoint := os.Getenv("GOOGLE_DRIVE_ENDPOINT"); endpoint != "" {
+		service, err := drive.NewService(g.ctx, option.WithEndpoint(endpoint), option.WithoutAuthentication())
+		if err != nil {
+			return fmt.Errorf("failed to create Drive service for %s: %w", endpoint, err)
+		}
+		g.service = service
+		return nil
+	}
+
+	var opts []option.ClientOption
+	if accessToken, ok := config["access_token"].(string); ok && accessToken != "" {
+		token := &oauth2.Token{AccessToken: accessToken}
+		if refreshToken, ok := config["refresh_token"].(string); ok && refreshToken != "" {
+			token.RefreshToken = refreshToken
+		}
+		opts = append(opts, option.WithHTTPClient(GoogleDriveOAuthConfig("").Client(g.ctx, token)))
+	} else if credentialsJSON, ok := config["credentials_json"].(string); ok && credentialsJSON != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentialsJSON)), option.WithScopes(drive.DriveReadonlyScope))
+	} else {
+		opts = append(opts, option.WithScopes(drive.DriveReadonlyScope))
+	}
+
+	service, err := drive.NewService(g.ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create Drive service: %w", err)
+	}
+	g.service = service
+	return nil
+}
+
+// Disconnect closes the Google Drive connection
+func (g *GoogleDriveConnector) Disconnect() error {
+	g.service = nil
+	return nil
+}
+
+// TestConnection checks that the configured file can be read and imported
+func (g *GoogleDriveConnector) TestConnection() error {
+	_, err := g.GetFile()
+	return err
+}
+
+// GetFile returns the metadata of the configured file, which must be a CSV or Excel file
+func (g *GoogleDriveConnector) GetFile() (*entity.GoogleDriveFile, error) {
+	if g.service == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+	if g.fileID == "" {
+		return nil, fmt.Errorf("file_id is required")
+	}
+
+	file, err := g.service.Files.Get(g.fileID).SupportsAllDrives(true).
+		Fields(googleapi.Field(googleDriveFileFields)).Context(g.ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Drive file: %w", err)
+	}
+	if GoogleDriveFileExtension(file.Name, file.MimeType) == "" {
+		return nil, fmt.Errorf("Drive file %q is not a CSV or Excel file", file.Name)
+	}
+	return googleDriveFile(file), nil
+}
+
+// Download returns the metadata and content of the configured file; the
+// caller closes the content
+func (g *GoogleDriveConnector) Download() (*entity.GoogleDriveFile, io.ReadCloser, error) {
+	file, err := g.GetFile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := g.service.Files.Get(g.fileID).SupportsAllDrives(true).Context(g.ctx).Download()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download Drive file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("failed to download Drive file: status %d", resp.StatusCode)
+	}
+	return file, resp.Body, nil
+}
+
+// ListFiles lists the CSV and Excel files and the folders in a folder, or
+// searches all of Drive by name when query is set
+func (g *GoogleDriveConnector) ListFiles(folderID, query, pageToken string, pageSize int) (*entity.GoogleDriveBrowseResponse, error) {
+	if g.service == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+	if pageSize <= 0 {
+		pageSize = defaultDrivePageSize
+	}
+
+	call := g.service.Files.List().
+		Q(googleDriveListQuery(folderID, query)).
+		OrderBy("folder,name").
+		PageSize(int64(pageSize)).
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
+		Fields(googleapi.Field("nextPageToken,files(" + googleDriveFileFields + ")")).
+		Context(g.ctx)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	list, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Drive files: %w", err)
+	}
+
+	response := &entity.GoogleDriveBrowseResponse{
+		Files:         make([]entity.GoogleDriveFile, 0, len(list.Files)),
+		NextPageToken: list.NextPageToken,
+	}
+	for _, file := range list.Files {
+		response.Files = append(response.Files, *googleDriveFile(file))
+	}
+	return response, nil
+}
+
+// GoogleDriveFileExtension returns the extension a Drive file is imported
+// with, from its name or else its MIME type, or "" when it is not a CSV or
+// Excel file
+func GoogleDriveFileExtension(name, mimeType string) string {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".csv", ".xlsx", ".xls":
+		return ext
+	}
+	return googleDriveFileExtensions[mimeType]
+}
+
+// googleDriveListQuery builds the Drive search query for folders and importable files
+func googleDriveListQuery(folderID, query string) string {
+	types := []string{
+		fmt.Sprintf("mimeType = '%s'", googleDriveFolderMimeType),
+		"mimeType = 'text/csv'",
+		"mimeType = 'application/vnd.ms-excel'",
+		"mimeType = 'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet'",
+		// Files are often uploaded without a precise type
+		"name contains '.csv'",
+		"name contains '.xlsx'",
+	}
+
+	q := "trashed = false and (" + strings.Join(types, " or ") + ")"
+	if query != "" {
+		return q + fmt.Sprintf(" and name contains '%s'", escapeDriveQuery(query))
+	}
+	if folderID == "" {
+		folderID = "root"
+	}
+	return q + fmt.Sprintf(" and '%s' in parents", escapeDriveQuery(folderID))
+}
+
+// escapeDriveQuery escapes a value for a quoted string in a Drive search query
+func escapeDriveQuery(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+func googleDriveFile(file *drive.File) *entity.GoogleDriveFile {
+	result := &entity.GoogleDriveFile{
+		ID:       file.Id,
+		Name:     file.Name,
+		MimeType: file.MimeType,
+		IsFolder: file.MimeType == googleDriveFolderMimeType,
+		Size:     file.Size,
+	}
+	if modified, err := time.Parse(time.RFC3339, file.ModifiedTime); err == nil {
+		result.ModifiedTime = &modified
+	}
+	if !result.IsFolder {
+		result.RevisionID = googleDriveRevision(file)
+	}
+	return result
+}
+
+// googleDriveRevision identifies the content of a file: the head revision,
+// else the checksum, else the modification time
+func googleDriveRevision(file *drive.File) string {
+	switch {
+	case file.HeadRevisionId != "":
+		return file.HeadRevisionId
+	case file.Md5Checksum != "":
+		return "md5:" + file.Md5Checksum
+	default:
+		return "modified:" + file.ModifiedTime
+	}
+}
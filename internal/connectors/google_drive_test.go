@@ -0,0 +1,103 @@
+package connectors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeDrive serves file metadata, content and listings like the Drive API
+func newFakeDrive(t *testing.T, queries *[]string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/drive/v3/files/sales":
+			if r.URL.Query().Get("alt") == "media" {
+				io.WriteString(w, "region,revenue\nnorth,120\n")
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": "sales", "name": "sales.csv", "mimeType": "text/csv", "size": "25",
+				"modifiedTime": "2025-10-01T08:00:00Z", "headRevisionId": "rev-2",
+			})
+		case "/drive/v3/files/notes":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "notes", "name": "notes.pdf", "mimeType": "application/pdf"})
+		case "/drive/v3/files":
+			*queries = append(*queries, r.URL.Query().Get("q"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"nextPageToken": "page-2",
+				"files": []map[string]interface{}{
+					{"id": "reports", "name": "Reports", "mimeType": googleDriveFolderMimeType},
+					{"id": "sales", "name": "sales.csv", "mimeType": "text/csv", "md5Checksum": "abc"},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GOOGLE_DRIVE_ENDPOINT", server.URL+"/drive/v3/")
+}
+
+func TestGoogleDriveConnector_GetFileAndDownload(t *testing.T) {
+	newFakeDrive(t, nil)
+	connector := NewGoogleDriveConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"file_id": "sales"}))
+
+	file, content, err := connector.Download()
+	require.NoError(t, err)
+	defer content.Close()
+	assert.Equal(t, "sales.csv", file.Name)
+	assert.Equal(t, int64(25), file.Size)
+	assert.Equal(t, "rev-2", file.RevisionID)
+	require.NotNil(t, file.ModifiedTime)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, "region,revenue\nnorth,120\n", string(data))
+}
+
+func TestGoogleDriveConnector_RejectsOtherFiles(t *testing.T) {
+	newFakeDrive(t, nil)
+	connector := NewGoogleDriveConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"file_id": "notes"}))
+
+	err := connector.TestConnection()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a CSV or Excel file")
+}
+
+func TestGoogleDriveConnector_ListFiles(t *testing.T) {
+	var queries []string
+	newFakeDrive(t, &queries)
+	connector := NewGoogleDriveConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"access_token": "token"}))
+
+	list, err := connector.ListFiles("folder-1", "", "", 10)
+	require.NoError(t, err)
+	require.Len(t, list.Files, 2)
+	assert.True(t, list.Files[0].IsFolder)
+	assert.Empty(t, list.Files[0].RevisionID)
+	assert.Equal(t, "md5:abc", list.Files[1].RevisionID)
+	assert.Equal(t, "page-2", list.NextPageToken)
+	require.Len(t, queries, 1)
+	assert.True(t, strings.HasSuffix(queries[0], "and 'folder-1' in parents"))
+}
+
+func TestGoogleDriveListQuery(t *testing.T) {
+	assert.True(t, strings.HasSuffix(googleDriveListQuery("", ""), "and 'root' in parents"))
+	query := googleDriveListQuery("folder-1", `Q3 'final'`)
+	assert.True(t, strings.HasSuffix(query, `and name contains 'Q3 \'final\''`))
+	assert.NotContains(t, query, "in parents")
+}
+
+func TestGoogleDriveFileExtension(t *testing.T) {
+	assert.Equal(t, ".csv", GoogleDriveFileExtension("Sales.CSV", "application/octet-stream"))
+	assert.Equal(t, ".xlsx", GoogleDriveFileExtension("Budget", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"))
+	assert.Empty(t, GoogleDriveFileExtension("notes.pdf", "application/pdf"))
+}
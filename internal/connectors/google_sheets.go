@@ -19,7 +19,7 @@ import (
 type GoogleSheetsConnector struct {
 	service       *sheets.Service
 	spreadsheetID string
-	sheetName     string
+	selection     *SheetSelection
 	ctx           context.Context
 }
 
@@ -38,11 +38,20 @@ func (g *GoogleSheetsConnector) Connect(config map[string]interface{}) error {
 	}
 	g.spreadsheetID = spreadsheetID
 
-	sheetName, ok := config["sheet_name"].(string)
-	if !ok {
-		g.sheetName = "Sheet1" // default sheet name
-	} else {
-		g.sheetName = sheetName
+	selection, err := SheetSelectionFromConfig(config)
+	if err != nil {
+		return err
+	}
+	g.selection = selection
+
+	// GOOGLE_SHEETS_ENDPOINT points the client at a fake Sheets API (integration tests)
+	if endpoint := os.Getenv("GOOGLE_SHEETS_ENDPOINT"); endpoint != "" {
+		service, err := sheets.NewService(g.ctx, option.WithEndpoint(endpoint), option.WithoutAuthentication())
+		if err != nil {
+			return fmt.Errorf("failed to create Sheets service for %s: %w", endpoint, err)
+		}
+		g.service = service
+		return nil
 	}
 
 	// Check if we have OAuth2 tokens
@@ -111,45 +120,43 @@ func (g *GoogleSheetsConnector) TestConnection() error {
 	return nil
 }
 
-// GetSchema retrieves the schema information from Google Sheets
+// GetSchema retrieves the schema information of the selected sheets, reading
+// the header row and a few rows of the configured range of each
 func (g *GoogleSheetsConnector) GetSchema() ([]entity.Column, error) {
 	if g.service == nil {
 		return nil, fmt.Errorf("no active connection")
 	}
 
-	// Get spreadsheet metadata
-	spreadsheet, err := g.service.Spreadsheets.Get(g.spreadsheetID).Do()
+	sheetInfos, err := g.SelectedSheets()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get spreadsheet: %w", err)
+		return nil, err
 	}
 
 	var allColumns []entity.Column
 
 	// Process each sheet
-	for _, sheet := range spreadsheet.Sheets {
-		sheetTitle := sheet.Properties.Title
-		
-		// Get the first few rows to infer schema
-		readRange := fmt.Sprintf("%s!1:3", sheetTitle) // Read first 3 rows
-		resp, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, readRange).Do()
+	for _, sheet := range sheetInfos {
+		sheetTitle := sheet.Title
+
+		// Get the header and the first rows to infer schema
+		values, err := g.readValues(sheetTitle, sheetSchemaRows)
 		if err != nil {
 			continue // Skip sheets that can't be read
 		}
 
-		if len(resp.Values) == 0 {
+		if len(values) == 0 {
 			continue // Skip empty sheets
 		}
 
-		// Assume first row contains headers
-		headerRow := resp.Values[0]
+		headerRow := values[0]
 		for i, header := range headerRow {
 			headerStr, ok := header.(string)
-			if !ok {
+			if !ok || headerStr == "" {
 				headerStr = fmt.Sprintf("Column_%d", i+1)
 			}
 
 			// Infer data type from sample data
-			dataType := g.inferColumnType(resp.Values, i)
+			dataType := g.inferColumnType(values, i)
 
 			column := entity.Column{
 				Name:     fmt.Sprintf("%s.%s", sheetTitle, headerStr),
@@ -164,7 +171,7 @@ func (g *GoogleSheetsConnector) GetSchema() ([]entity.Column, error) {
 	return allColumns, nil
 }
 
-// GetData retrieves data from a specific sheet
+// GetData retrieves data from a specific sheet, within the configured range
 func (g *GoogleSheetsConnector) GetData(sheetName string, limit int) ([]map[string]interface{}, error) {
 	if g.service == nil {
 		return nil, fmt.Errorf("no active connection")
@@ -174,33 +181,25 @@ func (g *GoogleSheetsConnector) GetData(sheetName string, limit int) ([]map[stri
 		limit = 100 // default limit
 	}
 
-	// Build range - get headers first
-	headerRange := fmt.Sprintf("%s!1:1", sheetName)
-	headerResp, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, headerRange).Do()
+	// The header row and the data rows below it
+	values, err := g.readValues(sheetName, limit+1)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get headers: %w", err)
+		return nil, fmt.Errorf("failed to get data: %w", err)
 	}
 
-	if len(headerResp.Values) == 0 || len(headerResp.Values[0]) == 0 {
+	if len(values) == 0 || len(values[0]) == 0 {
 		return nil, fmt.Errorf("no headers found")
 	}
 
-	headers := headerResp.Values[0]
-
-	// Get data rows
-	dataRange := fmt.Sprintf("%s!2:%d", sheetName, limit+1) // +1 because we start from row 2
-	dataResp, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, dataRange).Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get data: %w", err)
-	}
+	headers := values[0]
 
 	var result []map[string]interface{}
 
-	for _, row := range dataResp.Values {
+	for _, row := range values[1:] {
 		rowMap := make(map[string]interface{})
 		for i, header := range headers {
 			headerStr, ok := header.(string)
-			if !ok {
+			if !ok || headerStr == "" {
 				headerStr = fmt.Sprintf("Column_%d", i+1)
 			}
 
@@ -219,6 +218,114 @@ func (g *GoogleSheetsConnector) GetData(sheetName string, limit int) ([]map[stri
 	return result, nil
 }
 
+// SheetInfo describes a sheet of the spreadsheet
+type SheetInfo struct {
+	Title    string
+	RowCount int64 // Grid rows, an upper bound of the rows holding data
+}
+
+// ListSheets returns the sheets of the spreadsheet in display order
+func (g *GoogleSheetsConnector) ListSheets() ([]SheetInfo, error) {
+	if g.service == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	spreadsheet, err := g.service.Spreadsheets.Get(g.spreadsheetID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spreadsheet: %w", err)
+	}
+
+	sheetInfos := make([]SheetInfo, 0, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		info := SheetInfo{Title: sheet.Properties.Title}
+		if sheet.Properties.GridProperties != nil {
+			info.RowCount = sheet.Properties.GridProperties.RowCount
+		}
+		sheetInfos = append(sheetInfos, info)
+	}
+
+	return sheetInfos, nil
+}
+
+// SelectedSheets returns the sheets the configuration selects, in the order
+// they are selected, or all of them in display order when it selects none
+func (g *GoogleSheetsConnector) SelectedSheets() ([]SheetInfo, error) {
+	sheetInfos, err := g.ListSheets()
+	if err != nil {
+		return nil, err
+	}
+	if g.selection == nil || len(g.selection.Sheets) == 0 {
+		return sheetInfos, nil
+	}
+
+	byTitle := make(map[string]SheetInfo, len(sheetInfos))
+	for _, sheet := range sheetInfos {
+		byTitle[sheet.Title] = sheet
+	}
+	selected := make([]SheetInfo, 0, len(g.selection.Sheets))
+	for _, title := range g.selection.Sheets {
+		sheet, ok := byTitle[title]
+		if !ok {
+			return nil, fmt.Errorf("sheet %q not found in spreadsheet", title)
+		}
+		selected = append(selected, sheet)
+	}
+	return selected, nil
+}
+
+// GetRows returns up to limit rows of a sheet as text, the header row included
+func (g *GoogleSheetsConnector) GetRows(sheetName string, limit int) ([][]string, error) {
+	if g.service == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	values, err := g.readValues(sheetName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows: %w", err)
+	}
+
+	rows := make([][]string, len(values))
+	for i, values := range values {
+		rows[i] = make([]string, len(values))
+		for j, value := range values {
+			rows[i][j] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	return rows, nil
+}
+
+// readValues reads up to limit rows of the configured range of a sheet,
+// starting at the header row. Blank rows above the headers, such as those
+// left for a title, are skipped.
+func (g *GoogleSheetsConnector) readValues(sheetName string, limit int) ([][]interface{}, error) {
+	sheetRange := SheetRange{}
+	if g.selection != nil {
+		sheetRange = g.selection.Range
+	}
+
+	resp, err := g.service.Spreadsheets.Values.Get(g.spreadsheetID, sheetRange.A1(sheetName, limit)).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	values := resp.Values
+	for len(values) > 0 && blankSheetRow(values[0]) {
+		values = values[1:]
+	}
+	return values, nil
+}
+
+// blankSheetRow reports whether every cell of a row is empty
+func blankSheetRow(row []interface{}) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(fmt.Sprintf("%v", cell)) != "" {
+			return false
+		}
+	}
+	return true
+}
+
 // inferColumnType infers the data type of a column based on sample values
 func (g *GoogleSheetsConnector) inferColumnType(values [][]interface{}, columnIndex int) string {
 	if len(values) <= 1 {
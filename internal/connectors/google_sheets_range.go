@@ -0,0 +1,179 @@
+package connectors
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sheetSchemaRows is the number of rows, the header included, read to infer column types
+const sheetSchemaRows = 11
+
+// sheetMaxRows bounds open row ranges; a spreadsheet holds at most 10 million cells
+const sheetMaxRows = 10000000
+
+// sheetCellPattern matches the corner of an A1 range: a column, a row or both
+var sheetCellPattern = regexp.MustCompile(`^([A-Za-z]*)([0-9]*)$`)
+
+// sheetTitlePattern matches sheet titles that need no quoting in A1 notation
+var sheetTitlePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// SheetRange is the block of cells read from each sheet, in A1 terms. The
+// first row of the block holds the headers.
+type SheetRange struct {
+	StartColumn string // Empty for whole rows
+	EndColumn   string
+	StartRow    int // 1-based
+	EndRow      int // 0 reads to the last row with data
+}
+
+// SheetSelection is the sheets and the range of each a Google Sheets data source reads
+type SheetSelection struct {
+	Sheets []string // Sheets registered as tables; empty for all of them
+	Range  SheetRange
+}
+
+// SheetSelectionFromConfig reads the sheet selection of a Google Sheets config:
+// sheet_name or sheets select the sheets, range limits the cells read (e.g.
+// "B3:F" or "Orders!B3:F500", whose sheet is then selected) and header_row is
+// the sheet row holding the headers when it is below the start of the range.
+func SheetSelectionFromConfig(config map[string]interface{}) (*SheetSelection, error) {
+	selection := &SheetSelection{Range: SheetRange{StartRow: 1}}
+
+	if name, ok := config["sheet_name"].(string); ok && name != "" {
+		selection.Sheets = append(selection.Sheets, name)
+	}
+	if value, ok := config["sheets"]; ok && value != nil {
+		names, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("sheets must be a list of sheet names")
+		}
+		for _, name := range names {
+			title, ok := name.(string)
+			if !ok || title == "" {
+				return nil, fmt.Errorf("sheets must be a list of sheet names")
+			}
+			selection.Sheets = appendUnique(selection.Sheets, title)
+		}
+	}
+
+	if a1, ok := config["range"].(string); ok && strings.TrimSpace(a1) != "" {
+		sheet, sheetRange, err := ParseSheetRange(a1)
+		if err != nil {
+			return nil, err
+		}
+		if sheet != "" {
+			if len(selection.Sheets) > 0 && (len(selection.Sheets) > 1 || selection.Sheets[0] != sheet) {
+				return nil, fmt.Errorf("range names sheet %q, which conflicts with the selected sheets", sheet)
+			}
+			selection.Sheets = []string{sheet}
+		}
+		selection.Range = sheetRange
+	}
+
+	if value, ok := config["header_row"]; ok {
+		headerRow, ok := value.(float64)
+		if !ok || headerRow < 1 || headerRow != float64(int(headerRow)) {
+			return nil, fmt.Errorf("header_row must be a positive row number")
+		}
+		row := int(headerRow)
+		if row < selection.Range.StartRow || (selection.Range.EndRow > 0 && row > selection.Range.EndRow) {
+			return nil, fmt.Errorf("header_row %d is outside the range", row)
+		}
+		selection.Range.StartRow = row
+	}
+
+	return selection, nil
+}
+
+// ParseSheetRange parses an A1 range such as "B3:F", "A:D", "2:500" or
+// "'Q1 Sales'!A1:C", returning the sheet it names, if any
+func ParseSheetRange(a1 string) (string, SheetRange, error) {
+	a1 = strings.TrimSpace(a1)
+
+	var sheet string
+	if i := strings.LastIndex(a1, "!"); i >= 0 {
+		sheet = a1[:i]
+		if len(sheet) >= 2 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+			sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+		}
+		a1 = a1[i+1:]
+	}
+
+	start, end, ok := strings.Cut(a1, ":")
+	if !ok {
+		return "", SheetRange{}, fmt.Errorf("invalid range %q: expected a start and end such as A1:D", a1)
+	}
+	startMatch := sheetCellPattern.FindStringSubmatch(start)
+	endMatch := sheetCellPattern.FindStringSubmatch(end)
+	if startMatch == nil || endMatch == nil || start == "" || end == "" {
+		return "", SheetRange{}, fmt.Errorf("invalid range %q", a1)
+	}
+
+	sheetRange := SheetRange{
+		StartColumn: strings.ToUpper(startMatch[1]),
+		EndColumn:   strings.ToUpper(endMatch[1]),
+		StartRow:    1,
+	}
+	if (sheetRange.StartColumn == "") != (sheetRange.EndColumn == "") {
+		return "", SheetRange{}, fmt.Errorf("invalid range %q: both ends need a column, or neither", a1)
+	}
+	if startMatch[2] != "" {
+		sheetRange.StartRow, _ = strconv.Atoi(startMatch[2])
+	}
+	if endMatch[2] != "" {
+		sheetRange.EndRow, _ = strconv.Atoi(endMatch[2])
+	}
+	if sheetRange.StartRow < 1 || (sheetRange.EndRow > 0 && sheetRange.EndRow < sheetRange.StartRow) {
+		return "", SheetRange{}, fmt.Errorf("invalid range %q: rows must start at 1 or later and not end before they start", a1)
+	}
+	return sheet, sheetRange, nil
+}
+
+// A1 returns the A1 notation of up to limit rows of the range on a sheet,
+// e.g. "Orders!B3:F12" or "Orders!1:100"; limit 0 reads the whole range
+func (r SheetRange) A1(sheet string, limit int) string {
+	startRow := r.StartRow
+	if startRow < 1 {
+		startRow = 1
+	}
+	endRow := r.EndRow
+	if limit > 0 && (endRow == 0 || startRow+limit-1 < endRow) {
+		endRow = startRow + limit - 1
+	}
+
+	end := ""
+	if endRow > 0 {
+		end = strconv.Itoa(endRow)
+	}
+	if r.StartColumn == "" {
+		if end == "" && startRow == 1 {
+			return quoteSheetTitle(sheet)
+		}
+		if end == "" {
+			// Whole rows need an end; reads past the last row return no values
+			end = strconv.Itoa(sheetMaxRows)
+		}
+		return fmt.Sprintf("%s!%d:%s", quoteSheetTitle(sheet), startRow, end)
+	}
+	return fmt.Sprintf("%s!%s%d:%s%s", quoteSheetTitle(sheet), r.StartColumn, startRow, r.EndColumn, end)
+}
+
+// quoteSheetTitle quotes sheet titles with spaces or punctuation for A1 notation
+func quoteSheetTitle(title string) string {
+	if sheetTitlePattern.MatchString(title) {
+		return title
+	}
+	return "'" + strings.ReplaceAll(title, "'", "''") + "'"
+}
+
+// appendUnique appends a value unless the slice already holds it
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
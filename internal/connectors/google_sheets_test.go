@@ -1,9 +1,14 @@
 package connectors
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewGoogleSheetsConnector(t *testing.T) {
@@ -50,8 +55,10 @@ func TestGoogleSheetsConnector_Connect_DefaultSheetName(t *testing.T) {
 	}
 
 	err := connector.Connect(config)
-	// Should set default sheet name
-	assert.Equal(t, "Sheet1", connector.sheetName)
+	// Without a sheet name every sheet is read
+	if assert.NotNil(t, connector.selection) {
+		assert.Empty(t, connector.selection.Sheets)
+	}
 
 	// Error should not be about missing sheet_name
 	if err != nil {
@@ -197,4 +204,126 @@ func TestGetEnvOrDefault(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// newFakeSheets serves a spreadsheet with a Summary sheet whose headers are on
+// row 3 below blank rows, and an Orders sheet; requested ranges are recorded
+func newFakeSheets(t *testing.T, ranges *[]string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v4/spreadsheets/book":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"spreadsheetId": "book",
+				"sheets": []map[string]interface{}{
+					{"properties": map[string]interface{}{"title": "Summary", "gridProperties": map[string]interface{}{"rowCount": 100}}},
+					{"properties": map[string]interface{}{"title": "Orders", "gridProperties": map[string]interface{}{"rowCount": 500}}},
+				},
+			})
+		case strings.HasPrefix(r.URL.Path, "/v4/spreadsheets/book/values/"):
+			a1 := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/book/values/")
+			*ranges = append(*ranges, a1)
+			values := [][]interface{}{{"region", "revenue"}, {"north", "120"}, {"south", "80"}}
+			if strings.HasPrefix(a1, "Summary!1:") {
+				values = append([][]interface{}{{}, {"", " "}}, values...)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"range": a1, "values": values})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GOOGLE_SHEETS_ENDPOINT", server.URL+"/")
+}
+
+func TestGoogleSheetsConnector_SelectedSheetsAndRange(t *testing.T) {
+	var ranges []string
+	newFakeSheets(t, &ranges)
+
+	connector := NewGoogleSheetsConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{
+		"spreadsheet_id": "book", "sheets": []interface{}{"Orders"}, "range": "B4:C",
+	}))
+
+	columns, err := connector.GetSchema()
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	assert.Equal(t, "Orders.region", columns[0].Name)
+	assert.Equal(t, "integer", columns[1].Type)
+	assert.Equal(t, []string{"Orders!B4:C14"}, ranges)
+
+	rows, err := connector.GetData("Orders", 5)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "Orders!B4:C9", ranges[1])
+
+	missing := NewGoogleSheetsConnector()
+	require.NoError(t, missing.Connect(map[string]interface{}{"spreadsheet_id": "book", "sheet_name": "Returns"}))
+	_, err = missing.GetSchema()
+	assert.ErrorContains(t, err, `sheet "Returns" not found`)
+}
+
+func TestGoogleSheetsConnector_SkipsRowsAboveHeaders(t *testing.T) {
+	var ranges []string
+	newFakeSheets(t, &ranges)
+
+	connector := NewGoogleSheetsConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"spreadsheet_id": "book", "sheet_name": "Summary"}))
+
+	rows, err := connector.GetRows("Summary", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"region", "revenue"}, rows[0])
+	assert.Len(t, rows, 3)
+}
+
+func TestParseSheetRange(t *testing.T) {
+	sheet, sheetRange, err := ParseSheetRange("'Q1 Sales'!b3:f500")
+	require.NoError(t, err)
+	assert.Equal(t, "Q1 Sales", sheet)
+	assert.Equal(t, SheetRange{StartColumn: "B", EndColumn: "F", StartRow: 3, EndRow: 500}, sheetRange)
+
+	_, sheetRange, err = ParseSheetRange("A:D")
+	require.NoError(t, err)
+	assert.Equal(t, SheetRange{StartColumn: "A", EndColumn: "D", StartRow: 1}, sheetRange)
+
+	_, sheetRange, err = ParseSheetRange("4:200")
+	require.NoError(t, err)
+	assert.Equal(t, SheetRange{StartRow: 4, EndRow: 200}, sheetRange)
+
+	for _, invalid := range []string{"A1", "A1:", "B:4", "A5:C2", "A0:C", "A1:C-3"} {
+		_, _, err := ParseSheetRange(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSheetRange_A1(t *testing.T) {
+	assert.Equal(t, "Orders", SheetRange{StartRow: 1}.A1("Orders", 0))
+	assert.Equal(t, "Orders!1:100", SheetRange{StartRow: 1}.A1("Orders", 100))
+	assert.Equal(t, "Orders!4:10000000", SheetRange{StartRow: 4}.A1("Orders", 0))
+	assert.Equal(t, "'Q1 Sales'!B3:F12", SheetRange{StartColumn: "B", EndColumn: "F", StartRow: 3}.A1("Q1 Sales", 10))
+	assert.Equal(t, "Orders!B3:F20", SheetRange{StartColumn: "B", EndColumn: "F", StartRow: 3, EndRow: 20}.A1("Orders", 100))
+	assert.Equal(t, "'Bob''s'!B3:F", SheetRange{StartColumn: "B", EndColumn: "F", StartRow: 3}.A1("Bob's", 0))
+}
+
+func TestSheetSelectionFromConfig(t *testing.T) {
+	selection, err := SheetSelectionFromConfig(map[string]interface{}{"sheet_name": "Orders", "sheets": []interface{}{"Orders", "Returns"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders", "Returns"}, selection.Sheets)
+	assert.Equal(t, SheetRange{StartRow: 1}, selection.Range)
+
+	selection, err = SheetSelectionFromConfig(map[string]interface{}{"range": "Orders!A2:D", "header_row": float64(4)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Orders"}, selection.Sheets)
+	assert.Equal(t, SheetRange{StartColumn: "A", EndColumn: "D", StartRow: 4}, selection.Range)
+
+	for _, config := range []map[string]interface{}{
+		{"sheets": "Orders"},
+		{"sheets": []interface{}{1}},
+		{"range": "Orders!A1:D", "sheet_name": "Returns"},
+		{"range": "A3:D10", "header_row": float64(2)},
+		{"header_row": float64(0)},
+		{"header_row": "3"},
+	} {
+		_, err := SheetSelectionFromConfig(config)
+		assert.Error(t, err, "%v", config)
+	}
+}
@@ -0,0 +1,248 @@
+//go:build integration
+
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"narapulse-be/internal/pkg/testenv"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	integrationPostgres *testenv.Postgres
+	integrationBigQuery *testenv.BigQueryEmulator
+)
+
+// integrationSchema is the fixture loaded into the PostgreSQL container
+const integrationSchema = `
+CREATE TABLE customers (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	region VARCHAR(20)
+);
+CREATE TABLE orders (
+	id SERIAL PRIMARY KEY,
+	customer_id INTEGER NOT NULL REFERENCES customers(id),
+	amount NUMERIC(10,2) NOT NULL,
+	ordered_at DATE NOT NULL
+);
+INSERT INTO customers (name, region) VALUES ('Ann', 'EU'), ('Bob', 'US'), ('Cy', NULL);
+INSERT INTO orders (customer_id, amount, ordered_at) VALUES
+	(1, 10.50, '2024-01-01'), (1, 20.00, '2024-01-02'), (2, 5.25, '2024-01-03'), (3, 99.99, '2024-02-01');
+`
+
+func TestMain(m *testing.M) {
+	env, err := testenv.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration tests need docker: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer env.Close()
+
+		if integrationPostgres, err = env.StartPostgres(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if _, err := integrationPostgres.DB.Exec(integrationSchema); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load postgres fixture: %v\n", err)
+			return 1
+		}
+
+		if integrationBigQuery, err = env.StartBigQueryEmulator(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := seedBigQuery(integrationBigQuery); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load bigquery fixture: %v\n", err)
+			return 1
+		}
+
+		return m.Run()
+	}()
+	os.Exit(code)
+}
+
+type bigQueryOrder struct {
+	ID       int64     `bigquery:"id"`
+	Region   string    `bigquery:"region"`
+	Amount   float64   `bigquery:"amount"`
+	Ordered  time.Time `bigquery:"ordered_at"`
+	Priority bool      `bigquery:"priority"`
+}
+
+func seedBigQuery(emulator *testenv.BigQueryEmulator) error {
+	os.Setenv("BIGQUERY_EMULATOR_HOST", emulator.Host)
+	connector := NewBigQueryConnector()
+	if err := connector.Connect(emulator.Config); err != nil {
+		return err
+	}
+	defer connector.Disconnect()
+
+	ctx := context.Background()
+	schema, err := bigquery.InferSchema(bigQueryOrder{})
+	if err != nil {
+		return err
+	}
+	table := connector.client.Dataset(emulator.DatasetID).Table("orders")
+	if err := table.Create(ctx, &bigquery.TableMetadata{Schema: schema}); err != nil {
+		return err
+	}
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return table.Inserter().Put(ctx, []bigQueryOrder{
+		{ID: 1, Region: "EU", Amount: 10.5, Ordered: day, Priority: true},
+		{ID: 2, Region: "US", Amount: 5.25, Ordered: day.AddDate(0, 0, 1)},
+		{ID: 3, Region: "EU", Amount: 99.99, Ordered: day.AddDate(0, 1, 0)},
+	})
+}
+
+func connectPostgres(t *testing.T) *PostgreSQLConnector {
+	t.Helper()
+	connector := NewPostgreSQLConnector()
+	require.NoError(t, connector.Connect(integrationPostgres.Config))
+	t.Cleanup(func() { connector.Disconnect() })
+	return connector
+}
+
+func TestPostgreSQLIntegration_Schema(t *testing.T) {
+	connector := connectPostgres(t)
+	require.NoError(t, connector.TestConnection())
+
+	columns, err := connector.GetSchema()
+	require.NoError(t, err)
+
+	byName := make(map[string]string)
+	for _, column := range columns {
+		byName[column.Name] = column.Type
+		switch column.Name {
+		case "orders.id", "customers.id":
+			assert.True(t, column.PrimaryKey, column.Name)
+		case "orders.customer_id":
+			require.NotNil(t, column.ForeignKey)
+			assert.Equal(t, "customers", column.ForeignKey.Table)
+			assert.Equal(t, "id", column.ForeignKey.Column)
+		case "customers.region":
+			assert.True(t, column.Nullable)
+		case "customers.name":
+			assert.False(t, column.Nullable)
+		}
+	}
+	assert.Equal(t, "integer", byName["orders.customer_id"])
+	assert.Equal(t, "date", byName["orders.ordered_at"])
+	assert.Contains(t, byName, "customers.name")
+}
+
+func TestPostgreSQLIntegration_Data(t *testing.T) {
+	connector := connectPostgres(t)
+
+	rows, err := connector.GetData("orders", 2)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Contains(t, rows[0], "amount")
+
+	count, err := connector.GetRowCount("orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	_, err = connector.GetData("orders; DROP TABLE orders", 1)
+	assert.Error(t, err)
+}
+
+func TestPostgreSQLIntegration_Execution(t *testing.T) {
+	connector := connectPostgres(t)
+
+	estimate, err := connector.EstimateQueryCost("SELECT c.region, SUM(o.amount) FROM orders o JOIN customers c ON c.id = o.customer_id GROUP BY c.region")
+	require.NoError(t, err)
+	assert.Greater(t, estimate.EstimatedCost, 0.0)
+	assert.Greater(t, estimate.EstimatedRows, int64(0))
+
+	_, err = connector.EstimateQueryCost("SELECT * FROM missing_table")
+	assert.Error(t, err)
+}
+
+func TestBigQueryIntegration(t *testing.T) {
+	t.Setenv("BIGQUERY_EMULATOR_HOST", integrationBigQuery.Host)
+
+	connector := NewBigQueryConnector()
+	require.NoError(t, connector.Connect(integrationBigQuery.Config))
+	defer connector.Disconnect()
+	require.NoError(t, connector.TestConnection())
+
+	columns, err := connector.GetSchema()
+	require.NoError(t, err)
+	types := make(map[string]string)
+	for _, column := range columns {
+		types[column.Name] = column.Type
+	}
+	assert.Equal(t, map[string]string{
+		"orders.id":         "integer",
+		"orders.region":     "string",
+		"orders.amount":     "float",
+		"orders.ordered_at": "timestamp",
+		"orders.priority":   "boolean",
+	}, types)
+
+	rows, err := connector.GetData("orders", 2)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Contains(t, rows[0], "region")
+
+	_, err = connector.GetData("missing", 1)
+	assert.Error(t, err)
+}
+
+func TestGoogleSheetsIntegration(t *testing.T) {
+	server := testenv.NewSheetsServer(map[string][]testenv.Sheet{
+		"sheet-1": {
+			{Title: "Orders", RowCount: 500, Values: [][]interface{}{
+				{"id", "region", "amount"},
+				{"1", "EU", "10.5"},
+				{"2", "US", "5.25"},
+				{"3", "EU"},
+			}},
+			{Title: "Notes"},
+		},
+	})
+	defer server.Close()
+	t.Setenv("GOOGLE_SHEETS_ENDPOINT", server.URL)
+
+	connector := NewGoogleSheetsConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"spreadsheet_id": "sheet-1", "sheet_name": "Orders"}))
+	defer connector.Disconnect()
+	require.NoError(t, connector.TestConnection())
+
+	columns, err := connector.GetSchema()
+	require.NoError(t, err)
+	require.Len(t, columns, 3)
+	assert.Equal(t, "Orders.id", columns[0].Name)
+	assert.Equal(t, "integer", columns[0].Type)
+	assert.Equal(t, "float", columns[2].Type)
+
+	rows, err := connector.GetData("Orders", 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "EU", rows[2]["region"])
+	assert.Nil(t, rows[2]["amount"])
+
+	sheets, err := connector.ListSheets()
+	require.NoError(t, err)
+	assert.Equal(t, []SheetInfo{{Title: "Orders", RowCount: 500}, {Title: "Notes", RowCount: 1000}}, sheets)
+
+	raw, err := connector.GetRows("Orders", 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "region", "amount"}, {"1", "EU", "10.5"}}, raw)
+
+	missing := NewGoogleSheetsConnector()
+	require.NoError(t, missing.Connect(map[string]interface{}{"spreadsheet_id": "unknown"}))
+	assert.Error(t, missing.TestConnection())
+}
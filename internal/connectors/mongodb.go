@@ -0,0 +1,516 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// mongoSampleSize is the number of documents sampled per collection to infer its fields
+const mongoSampleSize = 100
+
+// mongoNestedDepth bounds how deep embedded documents are described
+const mongoNestedDepth = 3
+
+// mongoQueryTimeout bounds a single schema, data or aggregation request
+const mongoQueryTimeout = 30 * time.Second
+
+// MongoDBConnector implements the Connector interface for MongoDB databases.
+// Collections are reported as tables and top-level document fields as columns;
+// field types are inferred by sampling documents since collections have no schema.
+type MongoDBConnector struct {
+	client   *mongo.Client
+	database *mongo.Database
+	ctx      context.Context
+}
+
+// NewMongoDBConnector creates a new MongoDB connector
+func NewMongoDBConnector() *MongoDBConnector {
+	return &MongoDBConnector{
+		ctx: context.Background(),
+	}
+}
+
+// Connect establishes a connection to a MongoDB database. The config holds
+// either a connection_uri or host, port, username and password, plus the database.
+func (m *MongoDBConnector) Connect(config map[string]interface{}) error {
+	database, ok := config["database"].(string)
+	if !ok || database == "" {
+		return fmt.Errorf("database is required")
+	}
+
+	uri, err := mongoConnectionURI(config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, mongoQueryTimeout)
+	defer cancel()
+
+	clientOptions := options.Client().
+		ApplyURI(uri).
+		SetServerSelectionTimeout(10 * time.Second).
+		SetReadPreference(readpref.SecondaryPreferred())
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create MongoDB client: %w", err)
+	}
+
+	// Test the connection
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	m.client = client
+	m.database = client.Database(database)
+	return nil
+}
+
+// mongoConnectionURI returns the connection URI from the config, building it
+// from host and credentials when no connection_uri is given
+func mongoConnectionURI(config map[string]interface{}) (string, error) {
+	if uri, ok := config["connection_uri"].(string); ok && uri != "" {
+		if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+			return "", fmt.Errorf("connection_uri must start with mongodb:// or mongodb+srv://")
+		}
+		return uri, nil
+	}
+
+	host, ok := config["host"].(string)
+	if !ok || host == "" {
+		return "", fmt.Errorf("host or connection_uri is required")
+	}
+
+	port, ok := config["port"].(string)
+	if !ok || port == "" {
+		port = "27017" // default MongoDB port
+	}
+
+	u := url.URL{Scheme: "mongodb", Host: host + ":" + port, Path: "/"}
+	if username, ok := config["username"].(string); ok && username != "" {
+		password, _ := config["password"].(string)
+		u.User = url.UserPassword(username, password)
+	}
+
+	query := url.Values{}
+	if authSource, ok := config["auth_source"].(string); ok && authSource != "" {
+		query.Set("authSource", authSource)
+	}
+	if tls, ok := config["tls"].(bool); ok && tls {
+		query.Set("tls", "true")
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Disconnect closes the client
+func (m *MongoDBConnector) Disconnect() error {
+	if m.client != nil {
+		return m.client.Disconnect(context.Background())
+	}
+	return nil
+}
+
+// TestConnection tests if the connection is working
+func (m *MongoDBConnector) TestConnection() error {
+	if m.client == nil {
+		return fmt.Errorf("no active connection")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, mongoQueryTimeout)
+	defer cancel()
+	return m.client.Ping(ctx, nil)
+}
+
+// GetSchema lists the collections of the database and infers their fields
+// from a random sample of documents
+func (m *MongoDBConnector) GetSchema() ([]entity.Column, error) {
+	if m.database == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, mongoQueryTimeout)
+	defer cancel()
+
+	// Views are skipped: sampling them runs their whole pipeline
+	names, err := m.database.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	sort.Strings(names)
+
+	var columns []entity.Column
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+
+		documents, err := m.sampleDocuments(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range inferMongoFields(documents) {
+			field.Name = name + "." + field.Name
+			columns = append(columns, field)
+		}
+	}
+
+	return columns, nil
+}
+
+// sampleDocuments returns up to mongoSampleSize random documents of a collection
+func (m *MongoDBConnector) sampleDocuments(ctx context.Context, collection string) ([]bson.M, error) {
+	cursor, err := m.database.Collection(collection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: mongoSampleSize}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample collection %s: %w", collection, err)
+	}
+
+	var documents []bson.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("failed to read sample of collection %s: %w", collection, err)
+	}
+	return documents, nil
+}
+
+// GetData retrieves documents from a collection
+func (m *MongoDBConnector) GetData(collection string, limit int) ([]map[string]interface{}, error) {
+	if m.database == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	if limit <= 0 {
+		limit = 100 // default limit
+	}
+
+	if !isValidCollectionName(collection) {
+		return nil, fmt.Errorf("invalid collection name")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, mongoQueryTimeout)
+	defer cancel()
+
+	cursor, err := m.database.Collection(collection).Find(ctx, bson.D{}, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data: %w", err)
+	}
+	return readMongoRows(ctx, cursor)
+}
+
+// GetRowCount returns the estimated number of documents in a collection
+func (m *MongoDBConnector) GetRowCount(collection string) (int64, error) {
+	if m.database == nil {
+		return 0, fmt.Errorf("no active connection")
+	}
+
+	if !isValidCollectionName(collection) {
+		return 0, fmt.Errorf("invalid collection name")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, mongoQueryTimeout)
+	defer cancel()
+
+	count, err := m.database.Collection(collection).EstimatedDocumentCount(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
+// Aggregate runs an aggregation pipeline, given as an extended JSON array of
+// stages, on a collection and returns at most limit result documents. The
+// pipeline must already be validated as read-only by the caller.
+func (m *MongoDBConnector) Aggregate(collection string, pipelineJSON []byte, limit int) ([]map[string]interface{}, error) {
+	if m.database == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	if !isValidCollectionName(collection) {
+		return nil, fmt.Errorf("invalid collection name")
+	}
+
+	pipeline, err := parseMongoPipeline(pipelineJSON)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, mongoQueryTimeout)
+	defer cancel()
+
+	cursor, err := m.database.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run aggregation: %w", err)
+	}
+	return readMongoRows(ctx, cursor)
+}
+
+// parseMongoPipeline decodes an extended JSON array of stages, keeping the key
+// order of every stage (e.g. for multi-key $sort)
+func parseMongoPipeline(pipelineJSON []byte) (mongo.Pipeline, error) {
+	var wrapper struct {
+		Pipeline mongo.Pipeline `bson:"pipeline"`
+	}
+	doc := append(append([]byte(`{"pipeline":`), pipelineJSON...), '}')
+	if err := bson.UnmarshalExtJSON(doc, false, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid aggregation pipeline: %w", err)
+	}
+	return wrapper.Pipeline, nil
+}
+
+// readMongoRows drains a cursor into JSON-friendly rows
+func readMongoRows(ctx context.Context, cursor *mongo.Cursor) ([]map[string]interface{}, error) {
+	defer cursor.Close(ctx)
+
+	var result []map[string]interface{}
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+		result = append(result, normalizeMongoValue(document).(map[string]interface{}))
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	return result, nil
+}
+
+// normalizeMongoValue converts BSON values to plain values that serialize to
+// readable JSON: ObjectIDs become hex strings, dates become time.Time and
+// embedded documents and arrays are converted recursively
+func normalizeMongoValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		row := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			row[key] = normalizeMongoValue(val)
+		}
+		return row
+	case bson.D:
+		row := make(map[string]interface{}, len(v))
+		for _, elem := range v {
+			row[elem.Key] = normalizeMongoValue(elem.Value)
+		}
+		return row
+	case bson.A:
+		values := make([]interface{}, len(v))
+		for i, val := range v {
+			values[i] = normalizeMongoValue(val)
+		}
+		return values
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC()
+	case primitive.Timestamp:
+		return time.Unix(int64(v.T), 0).UTC()
+	case primitive.Decimal128:
+		return v.String()
+	case primitive.Binary:
+		return v.Data
+	case primitive.Regex:
+		return v.String()
+	case primitive.Null, primitive.Undefined:
+		return nil
+	default:
+		return v
+	}
+}
+
+// mongoFieldStats collects what was seen of one field across sampled documents
+type mongoFieldStats struct {
+	types   map[string]int
+	present int
+	nested  []bson.M
+}
+
+// inferMongoFields infers one column per top-level field of the sampled
+// documents. A field missing from or null in some documents is nullable, and
+// embedded documents list their nested fields in the column description.
+func inferMongoFields(documents []bson.M) []entity.Column {
+	stats := make(map[string]*mongoFieldStats)
+	for _, document := range documents {
+		for key, value := range document {
+			field, ok := stats[key]
+			if !ok {
+				field = &mongoFieldStats{types: make(map[string]int)}
+				stats[key] = field
+			}
+			if value == nil {
+				continue
+			}
+			if _, ok := value.(primitive.Null); ok {
+				continue
+			}
+			field.present++
+			field.types[mongoTypeName(value)]++
+			if nested, ok := value.(bson.M); ok {
+				field.nested = append(field.nested, nested)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// _id first, the rest alphabetically
+		if names[i] == "_id" || names[j] == "_id" {
+			return names[i] == "_id"
+		}
+		return names[i] < names[j]
+	})
+
+	columns := make([]entity.Column, 0, len(names))
+	for _, name := range names {
+		field := stats[name]
+		column := entity.Column{
+			Name:       name,
+			Type:       mergeMongoTypes(field.types),
+			Nullable:   field.present < len(documents),
+			PrimaryKey: name == "_id",
+		}
+		var notes []string
+		if column.Type == "mixed" {
+			notes = append(notes, "Mixed types: "+strings.Join(sortedKeys(field.types), ", "))
+		}
+		if paths := describeNestedFields(name, field.nested, 1); len(paths) > 0 {
+			notes = append(notes, "Fields: "+strings.Join(paths, ", "))
+		}
+		column.Description = strings.Join(notes, "; ")
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// describeNestedFields lists "path (type)" for the fields of embedded documents
+func describeNestedFields(prefix string, documents []bson.M, depth int) []string {
+	types := make(map[string]map[string]int)
+	nested := make(map[string][]bson.M)
+	for _, document := range documents {
+		for key, value := range document {
+			if value == nil {
+				continue
+			}
+			if _, ok := value.(primitive.Null); ok {
+				continue
+			}
+			path := prefix + "." + key
+			if types[path] == nil {
+				types[path] = make(map[string]int)
+			}
+			types[path][mongoTypeName(value)]++
+			if doc, ok := value.(bson.M); ok {
+				nested[path] = append(nested[path], doc)
+			}
+		}
+	}
+
+	var paths []string
+	for _, path := range sortedKeys(types) {
+		paths = append(paths, fmt.Sprintf("%s (%s)", path, mergeMongoTypes(types[path])))
+		if docs := nested[path]; len(docs) > 0 && depth < mongoNestedDepth {
+			paths = append(paths, describeNestedFields(path, docs, depth+1)...)
+		}
+	}
+	return paths
+}
+
+// mongoTypeName maps a decoded BSON value to a column type
+func mongoTypeName(value interface{}) string {
+	switch value.(type) {
+	case int32:
+		return "integer"
+	case int64:
+		return "bigint"
+	case float64:
+		return "double"
+	case primitive.Decimal128:
+		return "decimal"
+	case bool:
+		return "boolean"
+	case string, primitive.Symbol:
+		return "string"
+	case primitive.DateTime, primitive.Timestamp:
+		return "timestamp"
+	case primitive.ObjectID:
+		return "objectid"
+	case bson.M, bson.D:
+		return "object"
+	case bson.A:
+		return "array"
+	case primitive.Binary:
+		return "binary"
+	default:
+		return "string" // fallback to string for unknown types
+	}
+}
+
+// numericMongoTypes orders numeric types from narrowest to widest
+var numericMongoTypes = []string{"integer", "bigint", "double", "decimal"}
+
+// mergeMongoTypes picks the column type of a field seen with the given types.
+// Numeric types widen to the widest one seen; other combinations are "mixed".
+func mergeMongoTypes(types map[string]int) string {
+	if len(types) == 0 {
+		return "string"
+	}
+	if len(types) == 1 {
+		for t := range types {
+			return t
+		}
+	}
+
+	widest := -1
+	for t := range types {
+		rank := -1
+		for i, numeric := range numericMongoTypes {
+			if t == numeric {
+				rank = i
+			}
+		}
+		if rank < 0 {
+			return "mixed"
+		}
+		if rank > widest {
+			widest = rank
+		}
+	}
+	return numericMongoTypes[widest]
+}
+
+// isValidCollectionName rejects collection names MongoDB does not allow for user data
+func isValidCollectionName(name string) bool {
+	return name != "" && len(name) <= 255 &&
+		!strings.HasPrefix(name, "system.") &&
+		!strings.ContainsAny(name, "$\x00")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
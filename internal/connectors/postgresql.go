@@ -1,18 +1,23 @@
 package connectors
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	entity "narapulse-be/internal/models/entity"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // PostgreSQLConnector implements the Connector interface for PostgreSQL databases
 type PostgreSQLConnector struct {
-	db *sql.DB
+	db     *sql.DB
+	pooled bool // db belongs to a PostgreSQLPool and stays open on Disconnect
 }
 
 // NewPostgreSQLConnector creates a new PostgreSQL connector
@@ -20,11 +25,43 @@ func NewPostgreSQLConnector() *PostgreSQLConnector {
 	return &PostgreSQLConnector{}
 }
 
+// NewPooledPostgreSQLConnector creates a connector on a database handle of a
+// PostgreSQLPool; it is already connected and Disconnect leaves the handle open
+func NewPooledPostgreSQLConnector(db *sql.DB) *PostgreSQLConnector {
+	return &PostgreSQLConnector{db: db, pooled: true}
+}
+
 // Connect establishes a connection to PostgreSQL database
 func (p *PostgreSQLConnector) Connect(config map[string]interface{}) error {
+	connStr, err := PostgreSQLConnString(config)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	p.db = db
+	return nil
+}
+
+// postgreSQLConnectTimeout bounds dialing and the startup of a connection, so
+// unreachable servers fail instead of hanging
+const postgreSQLConnectTimeout = 10 * time.Second
+
+// PostgreSQLConnString builds the lib/pq connection string of a data source configuration
+func PostgreSQLConnString(config map[string]interface{}) (string, error) {
 	host, ok := config["host"].(string)
 	if !ok {
-		return fmt.Errorf("host is required")
+		return "", fmt.Errorf("host is required")
 	}
 
 	port, ok := config["port"].(string)
@@ -34,17 +71,17 @@ func (p *PostgreSQLConnector) Connect(config map[string]interface{}) error {
 
 	database, ok := config["database"].(string)
 	if !ok {
-		return fmt.Errorf("database is required")
+		return "", fmt.Errorf("database is required")
 	}
 
 	username, ok := config["username"].(string)
 	if !ok {
-		return fmt.Errorf("username is required")
+		return "", fmt.Errorf("username is required")
 	}
 
 	password, ok := config["password"].(string)
 	if !ok {
-		return fmt.Errorf("password is required")
+		return "", fmt.Errorf("password is required")
 	}
 
 	sslMode, ok := config["ssl_mode"].(string)
@@ -52,27 +89,13 @@ func (p *PostgreSQLConnector) Connect(config map[string]interface{}) error {
 		sslMode = "disable" // default SSL mode
 	}
 
-	// Build connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		host, port, username, password, database, sslMode)
-
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return fmt.Errorf("failed to open database connection: %w", err)
-	}
-
-	// Test the connection
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	p.db = db
-	return nil
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
+		host, port, username, password, database, sslMode, int(postgreSQLConnectTimeout.Seconds())), nil
 }
 
 // Disconnect closes the database connection
 func (p *PostgreSQLConnector) Disconnect() error {
-	if p.db != nil {
+	if p.db != nil && !p.pooled {
 		return p.db.Close()
 	}
 	return nil
@@ -112,6 +135,12 @@ func (p *PostgreSQLConnector) GetSchema() ([]entity.Column, error) {
 	}
 	defer rows.Close()
 
+	// Load key metadata so callers know how tables relate to each other
+	primaryKeys, foreignKeys, err := p.getKeyMetadata()
+	if err != nil {
+		return nil, err
+	}
+
 	var columns []entity.Column
 
 	for rows.Next() {
@@ -126,10 +155,15 @@ func (p *PostgreSQLConnector) GetSchema() ([]entity.Column, error) {
 		standardType := p.convertDataType(dataType)
 
 		// Create column
+		qualifiedName := fmt.Sprintf("%s.%s", tableName, columnName)
 		column := entity.Column{
-			Name:     fmt.Sprintf("%s.%s", tableName, columnName),
-			Type:     standardType,
-			Nullable: isNullable == "YES",
+			Name:       qualifiedName,
+			Type:       standardType,
+			Nullable:   isNullable == "YES",
+			PrimaryKey: primaryKeys[qualifiedName],
+		}
+		if ref, ok := foreignKeys[qualifiedName]; ok {
+			column.ForeignKey = &ref
 		}
 
 		columns = append(columns, column)
@@ -142,6 +176,74 @@ func (p *PostgreSQLConnector) GetSchema() ([]entity.Column, error) {
 	return columns, nil
 }
 
+// getKeyMetadata loads primary and foreign keys of the public schema, keyed by "table.column"
+func (p *PostgreSQLConnector) getKeyMetadata() (map[string]bool, map[string]entity.ForeignKeyRef, error) {
+	primaryKeys := make(map[string]bool)
+	foreignKeys := make(map[string]entity.ForeignKeyRef)
+
+	pkQuery := `
+		SELECT kcu.table_name, kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON tc.constraint_name = kcu.constraint_name
+			AND tc.table_schema = kcu.table_schema
+		WHERE tc.table_schema = 'public' AND tc.constraint_type = 'PRIMARY KEY'
+	`
+
+	pkRows, err := p.db.Query(pkQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query primary keys: %w", err)
+	}
+	defer pkRows.Close()
+
+	for pkRows.Next() {
+		var tableName, columnName string
+		if err := pkRows.Scan(&tableName, &columnName); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan primary key: %w", err)
+		}
+		primaryKeys[fmt.Sprintf("%s.%s", tableName, columnName)] = true
+	}
+	if err := pkRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating primary keys: %w", err)
+	}
+
+	// Match referencing and referenced columns by position so composite keys pair up correctly
+	fkQuery := `
+		SELECT kcu.table_name, kcu.column_name, rkcu.table_name, rkcu.column_name
+		FROM information_schema.referential_constraints rc
+		JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_name = rc.constraint_name
+			AND kcu.constraint_schema = rc.constraint_schema
+		JOIN information_schema.key_column_usage rkcu
+			ON rkcu.constraint_name = rc.unique_constraint_name
+			AND rkcu.constraint_schema = rc.unique_constraint_schema
+			AND rkcu.ordinal_position = kcu.position_in_unique_constraint
+		WHERE kcu.table_schema = 'public'
+	`
+
+	fkRows, err := p.db.Query(fkQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer fkRows.Close()
+
+	for fkRows.Next() {
+		var tableName, columnName, refTable, refColumn string
+		if err := fkRows.Scan(&tableName, &columnName, &refTable, &refColumn); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		foreignKeys[fmt.Sprintf("%s.%s", tableName, columnName)] = entity.ForeignKeyRef{
+			Table:  refTable,
+			Column: refColumn,
+		}
+	}
+	if err := fkRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating foreign keys: %w", err)
+	}
+
+	return primaryKeys, foreignKeys, nil
+}
+
 // GetData retrieves data from a specific table
 func (p *PostgreSQLConnector) GetData(tableName string, limit int) ([]map[string]interface{}, error) {
 	if p.db == nil {
@@ -207,6 +309,105 @@ func (p *PostgreSQLConnector) GetData(tableName string, limit int) ([]map[string
 	return result, nil
 }
 
+// ExecuteQuery runs a query in a read-only transaction, so it cannot write even
+// if it got past validation, and returns its columns and at most limit rows
+// (every row when limit is 0)
+func (p *PostgreSQLConnector) ExecuteQuery(query string, limit int) ([]entity.Column, []map[string]interface{}, error) {
+	if p.db == nil {
+		return nil, nil, fmt.Errorf("no active connection")
+	}
+
+	tx, err := p.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	columns := make([]entity.Column, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = entity.Column{Name: columnType.Name(), Type: p.convertDataType(columnType.DatabaseTypeName())}
+	}
+
+	result := []map[string]interface{}{}
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	for rows.Next() && (limit <= 0 || len(result) < limit) {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column.Name] = postgreSQLValue(values[i], column.Type)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return columns, result, nil
+}
+
+// postgreSQLValue converts a scanned value for JSON: decimals, which the
+// driver returns as text, become numbers and other text becomes a string
+func postgreSQLValue(value interface{}, columnType string) interface{} {
+	b, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	if columnType == "decimal" {
+		if number, err := strconv.ParseFloat(string(b), 64); err == nil {
+			return number
+		}
+	}
+	return string(b)
+}
+
+// GetRowCount returns the number of rows in a table. It uses the planner
+// estimate when available and falls back to COUNT(*) for unanalyzed tables.
+func (p *PostgreSQLConnector) GetRowCount(tableName string) (int64, error) {
+	if p.db == nil {
+		return 0, fmt.Errorf("no active connection")
+	}
+
+	if !p.isValidTableName(tableName) {
+		return 0, fmt.Errorf("invalid table name")
+	}
+
+	var estimate sql.NullInt64
+	err := p.db.QueryRow(`
+		SELECT c.reltuples::bigint
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relname = $1
+	`, tableName).Scan(&estimate)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to estimate row count: %w", err)
+	}
+	if estimate.Valid && estimate.Int64 >= 0 {
+		return estimate.Int64, nil
+	}
+
+	var count int64
+	if err := p.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
+
 // convertDataType converts PostgreSQL data types to standard types
 func (p *PostgreSQLConnector) convertDataType(pgType string) string {
 	switch strings.ToLower(pgType) {
@@ -251,4 +452,178 @@ func (p *PostgreSQLConnector) isValidTableName(tableName string) bool {
 		}
 	}
 	return len(tableName) > 0 && len(tableName) <= 63 // PostgreSQL identifier length limit
-}
\ No newline at end of file
+}
+
+// profileRowLimit bounds how many rows column statistics are computed over
+const profileRowLimit = 10000
+
+// sampleValueLimit is the number of representative values kept per text column
+const sampleValueLimit = 5
+
+// ProfileColumns fills Stats and SampleValues of the given (unqualified) columns
+// of a table. Statistics are computed over the first profileRowLimit rows so
+// profiling large tables stays cheap.
+func (p *PostgreSQLConnector) ProfileColumns(tableName string, columns []entity.Column) error {
+	if p.db == nil {
+		return fmt.Errorf("no active connection")
+	}
+
+	if !p.isValidTableName(tableName) {
+		return fmt.Errorf("invalid table name")
+	}
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	sampleQuery := fmt.Sprintf("(SELECT * FROM %s LIMIT %d) s", tableName, profileRowLimit)
+
+	// One aggregate query for all columns: total, non-null, distinct, min, max
+	expressions := []string{"COUNT(*)"}
+	for _, col := range columns {
+		ident := pq.QuoteIdentifier(col.Name)
+		expressions = append(expressions, fmt.Sprintf("COUNT(%s)", ident))
+		if col.Type == "json" {
+			expressions = append(expressions, "NULL::bigint")
+		} else {
+			expressions = append(expressions, fmt.Sprintf("COUNT(DISTINCT %s)", ident))
+		}
+		if p.isOrderedType(col.Type) {
+			expressions = append(expressions, fmt.Sprintf("MIN(%s)::text", ident), fmt.Sprintf("MAX(%s)::text", ident))
+		} else {
+			expressions = append(expressions, "NULL::text", "NULL::text")
+		}
+	}
+
+	var total int64
+	nonNull := make([]sql.NullInt64, len(columns))
+	distinct := make([]sql.NullInt64, len(columns))
+	minValues := make([]sql.NullString, len(columns))
+	maxValues := make([]sql.NullString, len(columns))
+
+	dest := []interface{}{&total}
+	for i := range columns {
+		dest = append(dest, &nonNull[i], &distinct[i], &minValues[i], &maxValues[i])
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(expressions, ", "), sampleQuery)
+	if err := p.db.QueryRow(query).Scan(dest...); err != nil {
+		return fmt.Errorf("failed to compute column statistics: %w", err)
+	}
+
+	for i := range columns {
+		stats := &entity.ColumnStats{
+			Min:           minValues[i].String,
+			Max:           maxValues[i].String,
+			DistinctCount: distinct[i].Int64,
+		}
+		if total > 0 {
+			stats.NullRatio = float64(total-nonNull[i].Int64) / float64(total)
+		}
+		columns[i].Stats = stats
+
+		// Most frequent values give the model real filter literals
+		if columns[i].Type == "string" && distinct[i].Int64 > 0 {
+			values, err := p.topValues(sampleQuery, columns[i].Name)
+			if err != nil {
+				return err
+			}
+			columns[i].SampleValues = values
+		}
+	}
+
+	return nil
+}
+
+// topValues returns the most frequent non-null values of a column in the sample
+func (p *PostgreSQLConnector) topValues(sampleQuery, columnName string) ([]interface{}, error) {
+	ident := pq.QuoteIdentifier(columnName)
+	query := fmt.Sprintf(
+		"SELECT %s::text FROM %s WHERE %s IS NOT NULL GROUP BY 1 ORDER BY COUNT(*) DESC, 1 LIMIT %d",
+		ident, sampleQuery, ident, sampleValueLimit,
+	)
+
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sample values for %s: %w", columnName, err)
+	}
+	defer rows.Close()
+
+	var values []interface{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan sample value: %w", err)
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// isOrderedType reports whether MIN/MAX are meaningful for a standard column type
+func (p *PostgreSQLConnector) isOrderedType(columnType string) bool {
+	switch columnType {
+	case "integer", "bigint", "smallint", "decimal", "float", "double", "date", "time", "timestamp":
+		return true
+	default:
+		return false
+	}
+}
+
+// explainPlan is a node of the EXPLAIN (FORMAT JSON) output
+type explainPlan struct {
+	NodeType  string        `json:"Node Type"`
+	TotalCost float64       `json:"Total Cost"`
+	PlanRows  float64       `json:"Plan Rows"`
+	PlanWidth int64         `json:"Plan Width"`
+	Plans     []explainPlan `json:"Plans"`
+}
+
+// EstimateQueryCost asks the planner for the cost of a query without running it
+func (p *PostgreSQLConnector) EstimateQueryCost(query string) (*entity.QueryCostEstimate, error) {
+	if p.db == nil {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	var planJSON []byte
+	if err := p.db.QueryRow("EXPLAIN (FORMAT JSON) " + query).Scan(&planJSON); err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	return parseExplainPlan(planJSON)
+}
+
+// parseExplainPlan converts EXPLAIN (FORMAT JSON) output into a cost estimate.
+// Bytes scanned is approximated from the rows and width of the scan nodes.
+func parseExplainPlan(planJSON []byte) (*entity.QueryCostEstimate, error) {
+	var plans []struct {
+		Plan explainPlan `json:"Plan"`
+	}
+	if err := json.Unmarshal(planJSON, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("empty query plan")
+	}
+
+	root := plans[0].Plan
+	return &entity.QueryCostEstimate{
+		Source:        entity.QueryCostSourceExplain,
+		EstimatedRows: int64(root.PlanRows),
+		BytesScanned:  scannedBytes(root),
+		EstimatedCost: root.TotalCost,
+	}, nil
+}
+
+// scannedBytes sums rows x width over the scan nodes of a plan
+func scannedBytes(plan explainPlan) int64 {
+	var total int64
+	if strings.HasSuffix(plan.NodeType, "Scan") {
+		total += int64(plan.PlanRows) * plan.PlanWidth
+	}
+	for _, child := range plan.Plans {
+		total += scannedBytes(child)
+	}
+	return total
+}
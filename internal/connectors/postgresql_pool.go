@@ -0,0 +1,140 @@
+package connectors
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolOptions limits the connections a PostgreSQLPool keeps per data source
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// PostgreSQLPool keeps one database handle per PostgreSQL data source, so
+// repeated connection tests, schema discovery and queries reuse connections
+// instead of dialing the server every time. A handle is replaced when the
+// connection configuration of its data source changes.
+type PostgreSQLPool struct {
+	options PoolOptions
+	mu      sync.Mutex
+	entries map[uint]*pooledDB
+}
+
+type pooledDB struct {
+	db          *sql.DB
+	fingerprint string // Hash of the connection string the handle was opened with
+}
+
+// NewPostgreSQLPool creates an empty pool
+func NewPostgreSQLPool(options PoolOptions) *PostgreSQLPool {
+	return &PostgreSQLPool{
+		options: options,
+		entries: make(map[uint]*pooledDB),
+	}
+}
+
+// Get returns the handle of a data source, opening it on first use or when
+// the configuration differs from the one the handle was opened with. Handles
+// are opened outside the lock, so a slow or unreachable server only delays
+// the callers of its own data source.
+func (p *PostgreSQLPool) Get(dataSourceID uint, config map[string]interface{}) (*sql.DB, error) {
+	connStr, err := PostgreSQLConnString(config)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := connStringFingerprint(connStr)
+
+	p.mu.Lock()
+	entry, ok := p.entries[dataSourceID]
+	p.mu.Unlock()
+	if ok && entry.fingerprint == fingerprint {
+		return entry.db, nil
+	}
+
+	db, err := p.open(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Another caller may have opened a handle meanwhile; the first one stored is kept
+	if entry, ok := p.entries[dataSourceID]; ok {
+		if entry.fingerprint == fingerprint {
+			db.Close()
+			return entry.db, nil
+		}
+		entry.db.Close()
+	}
+	p.entries[dataSourceID] = &pooledDB{db: db, fingerprint: fingerprint}
+	return db, nil
+}
+
+// open opens a handle with the limits of the pool and checks it can connect
+func (p *PostgreSQLPool) open(connStr string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	db.SetMaxOpenConns(p.options.MaxOpenConns)
+	db.SetMaxIdleConns(p.options.MaxIdleConns)
+	db.SetConnMaxLifetime(p.options.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(p.options.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), postgreSQLConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+// Invalidate closes the handle of a data source, e.g. after it was deleted
+func (p *PostgreSQLPool) Invalidate(dataSourceID uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.entries[dataSourceID]; ok {
+		entry.db.Close()
+		delete(p.entries, dataSourceID)
+	}
+}
+
+// Stats returns the connection statistics of every open handle by data source ID
+func (p *PostgreSQLPool) Stats() map[uint]sql.DBStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[uint]sql.DBStats, len(p.entries))
+	for id, entry := range p.entries {
+		stats[id] = entry.db.Stats()
+	}
+	return stats
+}
+
+// Close closes every handle of the pool
+func (p *PostgreSQLPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, entry := range p.entries {
+		entry.db.Close()
+		delete(p.entries, id)
+	}
+}
+
+// connStringFingerprint hashes a connection string so the pool does not keep credentials in memory
+func connStringFingerprint(connStr string) string {
+	sum := sha256.Sum256([]byte(connStr))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+package connectors
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPoolOptions() PoolOptions {
+	return PoolOptions{MaxOpenConns: 2, MaxIdleConns: 1, ConnMaxLifetime: time.Minute, ConnMaxIdleTime: time.Minute}
+}
+
+func TestPostgreSQLPool_Get_InvalidConfig(t *testing.T) {
+	pool := NewPostgreSQLPool(testPoolOptions())
+
+	db, err := pool.Get(1, map[string]interface{}{})
+	assert.Error(t, err)
+	assert.Nil(t, db)
+	assert.Contains(t, err.Error(), "host is required")
+	assert.Empty(t, pool.Stats())
+}
+
+func TestPostgreSQLPool_InvalidateUnknown(t *testing.T) {
+	pool := NewPostgreSQLPool(testPoolOptions())
+	pool.Invalidate(42)
+	pool.Close()
+	assert.Empty(t, pool.Stats())
+}
+
+func TestPostgreSQLPool_GetIsNotBlockedByStalledDataSource(t *testing.T) {
+	// The stalled server accepts connections and never answers the startup
+	stalled, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer stalled.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := stalled.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	// Nothing listens on the port of the refusing server
+	refusing, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	refusing.Close()
+
+	config := func(addr net.Addr) map[string]interface{} {
+		host, port, _ := net.SplitHostPort(addr.String())
+		return map[string]interface{}{"host": host, "port": port, "database": "app", "username": "app", "password": "secret"}
+	}
+	pool := NewPostgreSQLPool(testPoolOptions())
+	defer pool.Close()
+
+	stalledDone := make(chan error, 1)
+	go func() {
+		_, err := pool.Get(1, config(stalled.Addr()))
+		stalledDone <- err
+	}()
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stalled server was not dialed")
+	}
+
+	refusedDone := make(chan error, 1)
+	go func() {
+		_, err := pool.Get(2, config(refusing.Addr()))
+		refusedDone <- err
+	}()
+	select {
+	case err := <-refusedDone:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Get of another data source waited for the stalled one")
+	}
+
+	// Dropping the connection ends the stalled Get
+	conn.Close()
+	select {
+	case err := <-stalledDone:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stalled Get did not return")
+	}
+	assert.Empty(t, pool.Stats())
+}
+
+func TestPostgreSQLConnStringHasConnectTimeout(t *testing.T) {
+	connStr, err := PostgreSQLConnString(map[string]interface{}{"host": "localhost", "database": "app", "username": "app", "password": "secret"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(connStr, " connect_timeout=10"), connStr)
+}
+
+func TestConnStringFingerprint(t *testing.T) {
+	config := map[string]interface{}{"host": "localhost", "database": "app", "username": "app", "password": "secret"}
+	first, err := PostgreSQLConnString(config)
+	require.NoError(t, err)
+
+	config["password"] = "rotated"
+	second, err := PostgreSQLConnString(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, connStringFingerprint(first), connStringFingerprint(first))
+	assert.NotEqual(t, connStringFingerprint(first), connStringFingerprint(second))
+	assert.NotContains(t, connStringFingerprint(first), "secret")
+}
+
+func TestPooledPostgreSQLConnector_DisconnectKeepsHandleOpen(t *testing.T) {
+	// sql.Open does not dial, so no server is needed
+	db, err := sql.Open("postgres", "host=localhost dbname=app sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+
+	connector := NewPooledPostgreSQLConnector(db)
+	require.NoError(t, connector.Disconnect())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		assert.NotContains(t, err.Error(), "database is closed")
+	}
+}
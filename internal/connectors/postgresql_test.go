@@ -3,6 +3,8 @@ package connectors
 import (
 	"testing"
 
+	entity "narapulse-be/internal/models/entity"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,4 +87,49 @@ func TestPostgreSQLConnector_GetData_NoConnection(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, data)
 	assert.Contains(t, err.Error(), "no active connection")
-}
\ No newline at end of file
+}
+
+func TestPostgreSQLConnector_ProfileColumns_NoConnection(t *testing.T) {
+	connector := NewPostgreSQLConnector()
+
+	err := connector.ProfileColumns("test_table", []entity.Column{{Name: "id", Type: "integer"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no active connection")
+}
+
+func TestPostgreSQLConnector_IsOrderedType(t *testing.T) {
+	connector := NewPostgreSQLConnector()
+
+	assert.True(t, connector.isOrderedType("decimal"))
+	assert.True(t, connector.isOrderedType("timestamp"))
+	assert.False(t, connector.isOrderedType("string"))
+	assert.False(t, connector.isOrderedType("json"))
+}
+
+func TestPostgreSQLConnector_EstimateQueryCost_NoConnection(t *testing.T) {
+	connector := NewPostgreSQLConnector()
+
+	estimate, err := connector.EstimateQueryCost("SELECT 1")
+	assert.Error(t, err)
+	assert.Nil(t, estimate)
+	assert.Contains(t, err.Error(), "no active connection")
+}
+
+func TestParseExplainPlan(t *testing.T) {
+	plan := []byte(`[{"Plan": {"Node Type": "Hash Join", "Total Cost": 250.5, "Plan Rows": 40, "Plan Width": 16,
+		"Plans": [
+			{"Node Type": "Seq Scan", "Total Cost": 120.0, "Plan Rows": 1000, "Plan Width": 32},
+			{"Node Type": "Hash", "Total Cost": 80.0, "Plan Rows": 200, "Plan Width": 8,
+				"Plans": [{"Node Type": "Index Scan", "Total Cost": 80.0, "Plan Rows": 200, "Plan Width": 8}]}
+		]}}]`)
+
+	estimate, err := parseExplainPlan(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.QueryCostSourceExplain, estimate.Source)
+	assert.Equal(t, 250.5, estimate.EstimatedCost)
+	assert.Equal(t, int64(40), estimate.EstimatedRows)
+	assert.Equal(t, int64(1000*32+200*8), estimate.BytesScanned)
+
+	_, err = parseExplainPlan([]byte(`[]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,420 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// REST API pagination schemes
+const (
+	RESTPaginationNone   = "none"   // A single request
+	RESTPaginationPage   = "page"   // ?page=1&per_page=100, page numbers from 1
+	RESTPaginationOffset = "offset" // ?offset=0&limit=100
+	RESTPaginationCursor = "cursor" // ?cursor=<value of next_cursor_path in the previous response>
+	RESTPaginationLink   = "link"   // Follows the rel="next" URL of the Link header
+)
+
+const (
+	restDefaultPageSize   = 100
+	restDefaultMaxPages   = 50
+	restDefaultMaxRecords = 100000
+	restRequestTimeout    = 30 * time.Second
+	restMaxResponseBytes  = 50 << 20
+)
+
+var restLinkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+var restTableNamePattern = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// RESTAPIConnector fetches JSON records from an HTTP API. The records are
+// found at a JSON path in each response, pages are followed with the
+// configured pagination scheme, and nested objects are flattened into
+// columns so the records can be materialized as a single table.
+type RESTAPIConnector struct {
+	client         *http.Client
+	url            string
+	authHeader     string
+	authValue      string
+	recordsPath    string
+	pagination     string
+	pageParam      string
+	pageSizeParam  string
+	pageSize       int
+	cursorParam    string
+	nextCursorPath string
+	maxPages       int
+	maxRecords     int
+	tableName      string
+	ctx            context.Context
+}
+
+// NewRESTAPIConnector creates a new REST API connector
+func NewRESTAPIConnector() *RESTAPIConnector {
+	return &RESTAPIConnector{
+		client: &http.Client{Timeout: restRequestTimeout},
+		ctx:    context.Background(),
+	}
+}
+
+// Connect reads the API configuration. No request is made until records are fetched.
+func (r *RESTAPIConnector) Connect(config map[string]interface{}) error {
+	rawURL, ok := config["url"].(string)
+	if !ok || rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	r.url = rawURL
+
+	// An incremental pull sends the watermark of the last pull, so the API
+	// only returns records changed since
+	if since, _ := config["incremental_since"].(string); since != "" {
+		param, _ := config["incremental_param"].(string)
+		if param != "" {
+			query := parsed.Query()
+			query.Set(param, since)
+			parsed.RawQuery = query.Encode()
+			r.url = parsed.String()
+		}
+	}
+
+	r.authValue, _ = config["auth_value"].(string)
+	r.authHeader = configString(config, "auth_header", "Authorization")
+	r.recordsPath, _ = config["records_path"].(string)
+
+	r.pagination = configString(config, "pagination", RESTPaginationNone)
+	switch r.pagination {
+	case RESTPaginationNone, RESTPaginationPage, RESTPaginationOffset, RESTPaginationLink:
+	case RESTPaginationCursor:
+		if _, ok := config["next_cursor_path"].(string); !ok {
+			return fmt.Errorf("next_cursor_path is required for cursor pagination")
+		}
+	default:
+		return fmt.Errorf("unsupported pagination: %s", r.pagination)
+	}
+
+	defaultPageParam := "page"
+	if r.pagination == RESTPaginationOffset {
+		defaultPageParam = "offset"
+	}
+	defaultPageSizeParam := "per_page"
+	if r.pagination == RESTPaginationOffset {
+		defaultPageSizeParam = "limit"
+	}
+	r.pageParam = configString(config, "page_param", defaultPageParam)
+	r.pageSizeParam = configString(config, "page_size_param", defaultPageSizeParam)
+	r.cursorParam = configString(config, "cursor_param", "cursor")
+	r.nextCursorPath, _ = config["next_cursor_path"].(string)
+	r.pageSize = configInt(config, "page_size", restDefaultPageSize)
+	r.maxPages = configInt(config, "max_pages", restDefaultMaxPages)
+	r.maxRecords = configInt(config, "max_records", restDefaultMaxRecords)
+
+	r.tableName = RESTTableName(config)
+	return nil
+}
+
+// Disconnect is a no-op; requests do not keep a connection
+func (r *RESTAPIConnector) Disconnect() error {
+	return nil
+}
+
+// TestConnection fetches the first page and checks that the records path
+// points at an array
+func (r *RESTAPIConnector) TestConnection() error {
+	if r.url == "" {
+		return fmt.Errorf("no active connection")
+	}
+
+	body, _, err := r.fetchPage(r.pageURL(r.url, 0, ""))
+	if err != nil {
+		return err
+	}
+	_, err = extractRecords(body, r.recordsPath)
+	return err
+}
+
+// TableName returns the name of the table the records are materialized as
+func (r *RESTAPIConnector) TableName() string {
+	return r.tableName
+}
+
+// FetchRecords requests every page, up to max_pages and max_records, and
+// returns the flattened records
+func (r *RESTAPIConnector) FetchRecords() ([]map[string]interface{}, error) {
+	if r.url == "" {
+		return nil, fmt.Errorf("no active connection")
+	}
+
+	var records []map[string]interface{}
+	next := r.pageURL(r.url, 0, "")
+	for page := 0; page < r.maxPages && next != ""; page++ {
+		body, header, err := r.fetchPage(next)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page+1, err)
+		}
+
+		items, err := extractRecords(body, r.recordsPath)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page+1, err)
+		}
+		for _, item := range items {
+			records = append(records, FlattenRecord(item))
+			if len(records) >= r.maxRecords {
+				return records, nil
+			}
+		}
+
+		next = r.nextPageURL(page, len(items), body, header)
+	}
+
+	return records, nil
+}
+
+// nextPageURL returns the URL of the page after the given one, or "" when
+// the last page was reached
+func (r *RESTAPIConnector) nextPageURL(page, count int, body interface{}, header http.Header) string {
+	switch r.pagination {
+	case RESTPaginationPage, RESTPaginationOffset:
+		if count < r.pageSize {
+			return ""
+		}
+		return r.pageURL(r.url, page+1, "")
+	case RESTPaginationCursor:
+		cursor, ok := lookupJSONPath(body, r.nextCursorPath)
+		if !ok || cursor == nil || fmt.Sprint(cursor) == "" {
+			return ""
+		}
+		return r.pageURL(r.url, 0, fmt.Sprint(cursor))
+	case RESTPaginationLink:
+		match := restLinkNextPattern.FindStringSubmatch(header.Get("Link"))
+		if match == nil {
+			return ""
+		}
+		// Only follow links on the configured host, so the auth header is not sent elsewhere
+		base, _ := url.Parse(r.url)
+		next, err := base.Parse(match[1])
+		if err != nil || next.Host != base.Host {
+			return ""
+		}
+		return next.String()
+	}
+	return ""
+}
+
+// pageURL adds the pagination parameters for a zero-based page to the base URL
+func (r *RESTAPIConnector) pageURL(base string, page int, cursor string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+	query := u.Query()
+	switch r.pagination {
+	case RESTPaginationPage:
+		query.Set(r.pageParam, strconv.Itoa(page+1))
+		query.Set(r.pageSizeParam, strconv.Itoa(r.pageSize))
+	case RESTPaginationOffset:
+		query.Set(r.pageParam, strconv.Itoa(page*r.pageSize))
+		query.Set(r.pageSizeParam, strconv.Itoa(r.pageSize))
+	case RESTPaginationCursor:
+		if cursor != "" {
+			query.Set(r.cursorParam, cursor)
+		}
+	default:
+		return base
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// fetchPage requests a URL and decodes the JSON response
+func (r *RESTAPIConnector) fetchPage(pageURL string) (interface{}, http.Header, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, restRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.authValue != "" {
+		req.Header.Set(r.authHeader, r.authValue)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var body interface{}
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, restMaxResponseBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return nil, nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return body, resp.Header, nil
+}
+
+// extractRecords returns the array of objects found at the records path
+func extractRecords(body interface{}, path string) ([]map[string]interface{}, error) {
+	value, ok := lookupJSONPath(body, path)
+	if !ok {
+		return nil, fmt.Errorf("records_path %q not found in response", path)
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("records_path %q does not point at an array", path)
+	}
+
+	records := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if record, ok := item.(map[string]interface{}); ok {
+			records = append(records, record)
+		} else {
+			// Arrays of scalars become single-column records
+			records = append(records, map[string]interface{}{"value": item})
+		}
+	}
+	return records, nil
+}
+
+// lookupJSONPath resolves a dot-separated path such as "data.items" or
+// "$.results.0.rows"; numeric segments index arrays. An empty path or "$"
+// is the document itself.
+func lookupJSONPath(value interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return value, true
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// FlattenRecord flattens nested objects into underscore-joined columns
+// ({"user": {"id": 1}} becomes {"user_id": 1}). Arrays are kept as JSON
+// text and JSON numbers become int64 or float64.
+func FlattenRecord(record map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{}, len(record))
+	flattenInto(flat, "", record)
+	return flat
+}
+
+func flattenInto(flat map[string]interface{}, prefix string, value map[string]interface{}) {
+	for key, child := range value {
+		name := key
+		if prefix != "" {
+			name = prefix + "_" + key
+		}
+
+		switch v := child.(type) {
+		case map[string]interface{}:
+			if len(v) == 0 {
+				flat[name] = nil
+				continue
+			}
+			flattenInto(flat, name, v)
+		case []interface{}:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				flat[name] = fmt.Sprint(v)
+				continue
+			}
+			flat[name] = string(encoded)
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				flat[name] = n
+			} else if f, err := v.Float64(); err == nil {
+				flat[name] = f
+			} else {
+				flat[name] = v.String()
+			}
+		default:
+			flat[name] = v
+		}
+	}
+}
+
+// RESTTableName returns the table_name of the config, or a name derived
+// from the last segment of the URL path ("https://api.example.com/v1/orders.json"
+// becomes "orders")
+func RESTTableName(config map[string]interface{}) string {
+	name, _ := config["table_name"].(string)
+	if name == "" {
+		if rawURL, ok := config["url"].(string); ok {
+			if u, err := url.Parse(rawURL); err == nil {
+				segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+				name = strings.TrimSuffix(segments[len(segments)-1], path.Ext(u.Path))
+			}
+		}
+	}
+
+	name = strings.Trim(restTableNamePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	switch {
+	case name == "":
+		return "records"
+	case name[0] >= '0' && name[0] <= '9':
+		return "t_" + name
+	}
+	return name
+}
+
+// configString returns a string config value or the default
+func configString(config map[string]interface{}, key, defaultValue string) string {
+	if value, ok := config[key].(string); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// configInt returns a positive numeric config value or the default. JSON
+// numbers decode as float64; numeric strings are accepted too.
+func configInt(config map[string]interface{}, key string, defaultValue int) int {
+	switch value := config[key].(type) {
+	case float64:
+		if value > 0 {
+			return int(value)
+		}
+	case int:
+		if value > 0 {
+			return value
+		}
+	case string:
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,182 @@
+package connectors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESTAPIConnector_Connect_InvalidConfig(t *testing.T) {
+	connector := NewRESTAPIConnector()
+
+	err := connector.Connect(map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "url is required")
+
+	err = connector.Connect(map[string]interface{}{"url": "ftp://example.com/orders"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "absolute http or https URL")
+
+	err = connector.Connect(map[string]interface{}{"url": "https://example.com/orders", "pagination": "cursor"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "next_cursor_path is required")
+
+	err = connector.Connect(map[string]interface{}{"url": "https://example.com/orders", "pagination": "scroll"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported pagination")
+}
+
+func TestRESTAPIConnector_FetchRecords_PagePagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		assert.Equal(t, "2", r.URL.Query().Get("per_page"))
+
+		switch page {
+		case 1:
+			fmt.Fprint(w, `{"data":{"items":[{"id":1,"customer":{"name":"Ann","tier":"gold"}},{"id":2,"customer":{"name":"Bob"}}]}}`)
+		case 2:
+			fmt.Fprint(w, `{"data":{"items":[{"id":3,"amount":9.5,"tags":["a","b"]}]}}`)
+		default:
+			t.Errorf("unexpected page %d", page)
+		}
+	}))
+	defer server.Close()
+
+	connector := NewRESTAPIConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{
+		"url":          server.URL + "/v1/orders",
+		"auth_value":   "Bearer secret",
+		"records_path": "data.items",
+		"pagination":   "page",
+		"page_size":    float64(2),
+	}))
+	require.NoError(t, connector.TestConnection())
+	assert.Equal(t, "orders", connector.TableName())
+
+	records, err := connector.FetchRecords()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": int64(1), "customer_name": "Ann", "customer_tier": "gold"},
+		{"id": int64(2), "customer_name": "Bob"},
+		{"id": int64(3), "amount": 9.5, "tags": `["a","b"]`},
+	}, records)
+}
+
+func TestRESTAPIConnector_FetchRecords_CursorPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"results":[{"id":"a"}],"meta":{"next":"c1"}}`)
+		case "c1":
+			fmt.Fprint(w, `{"results":[{"id":"b"}],"meta":{"next":null}}`)
+		}
+	}))
+	defer server.Close()
+
+	connector := NewRESTAPIConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{
+		"url":              server.URL,
+		"records_path":     "$.results",
+		"pagination":       "cursor",
+		"cursor_param":     "after",
+		"next_cursor_path": "meta.next",
+	}))
+
+	records, err := connector.FetchRecords()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": "a"}, {"id": "b"}}, records)
+}
+
+func TestRESTAPIConnector_FetchRecords_LinkPagination(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `<`+server.URL+`/?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"n":1}]`)
+			return
+		}
+		// Links to other hosts are not followed
+		w.Header().Set("Link", `<https://elsewhere.example.com/?page=3>; rel="next"`)
+		fmt.Fprint(w, `[{"n":2}]`)
+	}))
+	defer server.Close()
+
+	connector := NewRESTAPIConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"url": server.URL, "pagination": "link"}))
+
+	records, err := connector.FetchRecords()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"n": int64(1)}, {"n": int64(2)}}, records)
+}
+
+func TestRESTAPIConnector_FetchRecords_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"count":3}}`)
+	}))
+	defer server.Close()
+
+	connector := NewRESTAPIConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{"url": server.URL + "/missing"}))
+	_, err := connector.FetchRecords()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 404")
+
+	require.NoError(t, connector.Connect(map[string]interface{}{"url": server.URL, "records_path": "data.count"}))
+	_, err = connector.FetchRecords()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not point at an array")
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	body := map[string]interface{}{
+		"results": []interface{}{map[string]interface{}{"rows": []interface{}{"x"}}},
+	}
+
+	value, ok := lookupJSONPath(body, "$.results.0.rows")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"x"}, value)
+
+	value, ok = lookupJSONPath(body, "")
+	assert.True(t, ok)
+	assert.Equal(t, body, value)
+
+	_, ok = lookupJSONPath(body, "results.1")
+	assert.False(t, ok)
+}
+
+func TestRESTTableName(t *testing.T) {
+	assert.Equal(t, "orders", RESTTableName(map[string]interface{}{"url": "https://api.example.com/v1/orders/"}))
+	assert.Equal(t, "line_items", RESTTableName(map[string]interface{}{"url": "https://api.example.com/line-items.json", "table_name": ""}))
+	assert.Equal(t, "sales_2024", RESTTableName(map[string]interface{}{"table_name": "Sales 2024"}))
+	assert.Equal(t, "t_2024", RESTTableName(map[string]interface{}{"url": "https://api.example.com/2024"}))
+	assert.Equal(t, "records", RESTTableName(map[string]interface{}{"url": "https://api.example.com/"}))
+}
+
+func TestRESTAPIConnector_FetchRecords_IncrementalSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2025-10-01T00:00:00Z", r.URL.Query().Get("updated_since"))
+		assert.Equal(t, "open", r.URL.Query().Get("status"))
+		fmt.Fprint(w, `[{"id":7,"updated_at":"2025-10-02T00:00:00Z"}]`)
+	}))
+	defer server.Close()
+
+	connector := NewRESTAPIConnector()
+	require.NoError(t, connector.Connect(map[string]interface{}{
+		"url":               server.URL + "/orders?status=open",
+		"incremental_param": "updated_since",
+		"incremental_since": "2025-10-01T00:00:00Z",
+	}))
+	records, err := connector.FetchRecords()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
@@ -0,0 +1,188 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"narapulse-be/internal/grpcapi/pb"
+	"narapulse-be/internal/middleware"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/pkg/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// route is the REST route a method is authorized as, so the Casbin route
+// policies apply to both APIs
+type route struct {
+	path   string
+	method string
+	withID bool // The id of the request is appended to the path
+}
+
+var methodRoutes = map[string]route{
+	pb.NL2SQLService_Convert_FullMethodName:              {path: "/api/v1/nl2sql/convert", method: "POST"},
+	pb.NL2SQLService_Execute_FullMethodName:              {path: "/api/v1/nl2sql/execute", method: "POST"},
+	pb.DataSourceService_CreateDataSource_FullMethodName: {path: "/api/v1/data-sources", method: "POST"},
+	pb.DataSourceService_GetDataSource_FullMethodName:    {path: "/api/v1/data-sources", method: "GET", withID: true},
+	pb.DataSourceService_ListDataSources_FullMethodName:  {path: "/api/v1/data-sources", method: "GET"},
+	pb.DataSourceService_UpdateDataSource_FullMethodName: {path: "/api/v1/data-sources", method: "PUT", withID: true},
+	pb.DataSourceService_DeleteDataSource_FullMethodName: {path: "/api/v1/data-sources", method: "DELETE", withID: true},
+}
+
+type claimsKey struct{}
+
+// authenticator validates the bearer access token in the authorization
+// metadata and checks its user is still active, like middleware.AuthMiddleware,
+// and authorizes the method with the user's current role against the policies
+// of its REST route, like middleware.CasbinMiddleware. Without an authorizer
+// (Casbin unavailable) every authenticated user is allowed, as on the REST
+// routes.
+type authenticator struct {
+	jwtSecret  string
+	authorizer middleware.Authorizer
+	accounts   middleware.AccountResolver
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// Streams are authorized before the request is read, so their routes take no id
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod, nil)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+	if !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+	}
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "token is required")
+	}
+
+	claims, err := utils.ValidateToken(token, a.jwtSecret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	// MFA, enrollment and embed tokens only grant access to their REST routes
+	if claims.Purpose != utils.TokenPurposeAccess {
+		return nil, status.Error(codes.Unauthenticated, "only access tokens are accepted")
+	}
+
+	account, err := a.accounts.GetAccount(ctx, claims.UserID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("user_id", claims.UserID).Msg("Failed to load user of token")
+		return nil, status.Error(codes.Internal, "authentication failed")
+	}
+	if account == nil || !account.IsActive {
+		return nil, status.Error(codes.Unauthenticated, "user account is deactivated")
+	}
+	// The role and email of the token may be outdated
+	current := *claims
+	current.Email = account.Email
+	current.Role = account.Role
+	claims = &current
+
+	if err := a.authorize(ctx, claims, fullMethod, req); err != nil {
+		return nil, err
+	}
+	// Database access with the returned context is scoped to the user's tenant
+	ctx = tenancy.WithTenant(ctx, middleware.TokenTenant(claims))
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+func (a *authenticator) authorize(ctx context.Context, claims *utils.Claims, fullMethod string, req interface{}) error {
+	if a.authorizer == nil {
+		return nil
+	}
+	r, ok := methodRoutes[fullMethod]
+	if !ok {
+		return status.Error(codes.PermissionDenied, "you do not have permission to call this method")
+	}
+	path := r.path
+	if withID, ok := req.(interface{ GetId() uint32 }); ok && r.withID {
+		path = fmt.Sprintf("%s/%d", path, withID.GetId())
+	}
+
+	var subjects []string
+	if claims.Email != "" {
+		subjects = append(subjects, claims.Email)
+	}
+	if claims.Role != "" {
+		subjects = append(subjects, claims.Role)
+	}
+	for _, subject := range subjects {
+		allowed, err := a.authorizer.Enforce(subject, path, r.method)
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Str("subject", subject).Str("grpc_method", fullMethod).Msg("Failed to authorize call")
+			return status.Error(codes.Internal, "authorization failed")
+		}
+		if allowed {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "you do not have permission to call this method")
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// userID returns the user the call was authenticated as
+func userID(ctx context.Context) uint {
+	claims, _ := ctx.Value(claimsKey{}).(*utils.Claims)
+	if claims == nil {
+		return 0
+	}
+	return claims.UserID
+}
+
+// auditActor is the actor audit entries of the call are recorded for, like
+// middleware.GetAuditActor for REST requests
+func auditActor(ctx context.Context) models.AuditActor {
+	var actor models.AuditActor
+	if claims, _ := ctx.Value(claimsKey{}).(*utils.Claims); claims != nil {
+		actor.UserID = claims.UserID
+		actor.Email = claims.Email
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		actor.IPAddress = p.Addr.String()
+		if host, _, err := net.SplitHostPort(actor.IPAddress); err == nil {
+			actor.IPAddress = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			actor.UserAgent = values[0]
+		}
+	}
+	return actor
+}
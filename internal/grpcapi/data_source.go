@@ -0,0 +1,139 @@
+package grpcapi
+
+import (
+	"context"
+
+	"narapulse-be/internal/grpcapi/pb"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/services"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type dataSourceServer struct {
+	pb.UnimplementedDataSourceServiceServer
+	dataSourceService services.DataSourceService
+	auditService      *services.AuditService
+}
+
+func (s *dataSourceServer) CreateDataSource(ctx context.Context, req *pb.CreateDataSourceRequest) (*pb.DataSource, error) {
+	request := models.DataSourceCreateRequest{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Type:        models.DataSourceType(req.GetType()),
+	}
+	if req.GetConfig() != nil {
+		request.Config = req.GetConfig().AsMap()
+	}
+	if err := validate.Struct(&request); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	dataSource, err := s.dataSourceService.WithContext(ctx).CreateDataSource(userID(ctx), &request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to create data source: %v", err)
+	}
+
+	s.auditService.Log(auditActor(ctx), models.AuditActionDataSourceCreate, "data_source", dataSource.ID, nil, dataSource, grpcAuditDetails(nil))
+	return toDataSource(dataSource)
+}
+
+func (s *dataSourceServer) GetDataSource(ctx context.Context, req *pb.GetDataSourceRequest) (*pb.DataSource, error) {
+	dataSource, err := s.dataSourceService.WithContext(ctx).GetDataSource(uint(req.GetId()), userID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "data source not found")
+	}
+	return toDataSource(dataSource)
+}
+
+func (s *dataSourceServer) ListDataSources(ctx context.Context, _ *pb.ListDataSourcesRequest) (*pb.ListDataSourcesResponse, error) {
+	dataSources, err := s.dataSourceService.WithContext(ctx).GetUserDataSources(userID(ctx))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get data sources: %v", err)
+	}
+
+	response := &pb.ListDataSourcesResponse{DataSources: make([]*pb.DataSource, 0, len(dataSources))}
+	for i := range dataSources {
+		dataSource, err := toDataSource(&dataSources[i])
+		if err != nil {
+			return nil, err
+		}
+		response.DataSources = append(response.DataSources, dataSource)
+	}
+	return response, nil
+}
+
+func (s *dataSourceServer) UpdateDataSource(ctx context.Context, req *pb.UpdateDataSourceRequest) (*pb.DataSource, error) {
+	request := models.DataSourceUpdateRequest{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+	}
+	if req.GetConfig() != nil {
+		request.Config = req.GetConfig().AsMap()
+	}
+	if err := validate.Struct(&request); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	before, err := s.dataSourceService.WithContext(ctx).GetDataSource(uint(req.GetId()), userID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "data source not found")
+	}
+
+	dataSource, err := s.dataSourceService.WithContext(ctx).UpdateDataSource(uint(req.GetId()), userID(ctx), &request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to update data source: %v", err)
+	}
+
+	s.auditService.Log(auditActor(ctx), models.AuditActionDataSourceUpdate, "data_source", dataSource.ID, before, dataSource, grpcAuditDetails(nil))
+	return toDataSource(dataSource)
+}
+
+func (s *dataSourceServer) DeleteDataSource(ctx context.Context, req *pb.DeleteDataSourceRequest) (*pb.DeleteDataSourceResponse, error) {
+	before, err := s.dataSourceService.WithContext(ctx).GetDataSource(uint(req.GetId()), userID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "data source not found")
+	}
+
+	if err := s.dataSourceService.WithContext(ctx).DeleteDataSource(uint(req.GetId()), userID(ctx), req.GetDeleteQueries()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to delete data source: %v", err)
+	}
+
+	s.auditService.Log(auditActor(ctx), models.AuditActionDataSourceDelete, "data_source", uint(req.GetId()), before, nil,
+		grpcAuditDetails(map[string]interface{}{"delete_queries": req.GetDeleteQueries()}))
+	return &pb.DeleteDataSourceResponse{}, nil
+}
+
+// grpcAuditDetails marks audit entries of gRPC calls apart from REST ones
+func grpcAuditDetails(details map[string]interface{}) map[string]interface{} {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["grpc"] = true
+	return details
+}
+
+func toDataSource(dataSource *models.DataSourceResponse) (*pb.DataSource, error) {
+	config, err := toStruct(dataSource.Config)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode data source config: %v", err)
+	}
+
+	result := &pb.DataSource{
+		Id:           uint32(dataSource.ID),
+		Name:         dataSource.Name,
+		Description:  dataSource.Description,
+		Type:         string(dataSource.Type),
+		Status:       string(dataSource.Status),
+		Config:       config,
+		ErrorMessage: dataSource.ErrorMsg,
+		CreatedAt:    timestamppb.New(dataSource.CreatedAt),
+		UpdatedAt:    timestamppb.New(dataSource.UpdatedAt),
+	}
+	if dataSource.LastTested != nil {
+		result.LastTested = timestamppb.New(*dataSource.LastTested)
+	}
+	return result, nil
+}
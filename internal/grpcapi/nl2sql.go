@@ -0,0 +1,187 @@
+package grpcapi
+
+import (
+	"context"
+
+	"narapulse-be/internal/grpcapi/pb"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	defaultBatchSize = 500
+	maxBatchSize     = 1000
+	maxExecuteLimit  = 10000
+)
+
+var validate = validator.New()
+
+type nl2sqlServer struct {
+	pb.UnimplementedNL2SQLServiceServer
+	nl2sqlService *services.NL2SQLService
+	auditService  *services.AuditService
+}
+
+func (s *nl2sqlServer) Convert(ctx context.Context, req *pb.ConvertRequest) (*pb.ConvertResponse, error) {
+	request := models.NL2SQLRequest{
+		NLQuery:      req.GetNlQuery(),
+		DataSourceID: uint(req.GetDataSourceId()),
+		Language:     req.GetLanguage(),
+		DryRun:       req.GetDryRun(),
+	}
+	if req.GetParentQueryId() != 0 {
+		parentQueryID := uint(req.GetParentQueryId())
+		request.ParentQueryID = &parentQueryID
+	}
+	if err := validate.Struct(&request); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	response, err := s.nl2sqlService.WithContext(ctx).ConvertNL2SQL(userID(ctx), &request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toConvertResponse(response), nil
+}
+
+func (s *nl2sqlServer) Execute(req *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.RowBatch]) error {
+	ctx := stream.Context()
+	if req.GetQueryId() == 0 {
+		return status.Error(codes.InvalidArgument, "query_id is required")
+	}
+	if req.GetLimit() < 0 || req.GetLimit() > maxExecuteLimit {
+		return status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxExecuteLimit)
+	}
+	batchSize := int(req.GetBatchSize())
+	if batchSize < 0 || batchSize > maxBatchSize {
+		return status.Errorf(codes.InvalidArgument, "batch_size must be between 1 and %d", maxBatchSize)
+	}
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+
+	// The whole result is read at once, like the REST API without page_size, so
+	// currency conversion applies to every batch. PII is always masked.
+	response, err := s.nl2sqlService.WithContext(ctx).ExecuteQuery(userID(ctx), &models.QueryExecutionRequest{
+		QueryID:    uint(req.GetQueryId()),
+		Limit:      int(req.GetLimit()),
+		Anonymize:  req.GetAnonymize(),
+		Parameters: req.GetParameters().AsMap(),
+		Currency:   req.GetCurrency(),
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	s.auditExecution(ctx, response)
+	if response.Status == models.QueryStatusFailed {
+		return status.Error(codes.Aborted, response.Message)
+	}
+
+	batches, err := toRowBatches(response, batchSize)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode rows: %v", err)
+	}
+	for _, batch := range batches {
+		if err := stream.Send(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditExecution records the execution like NL2SQLHandler does for REST calls
+func (s *nl2sqlServer) auditExecution(ctx context.Context, execution *models.QueryExecutionResponse) {
+	details := map[string]interface{}{
+		"sql":               execution.ExecutedSQL,
+		"status":            execution.Status,
+		"row_count":         execution.RowCount,
+		"execution_time_ms": execution.ExecutionTime,
+	}
+	if execution.ResultID != 0 {
+		details["result_id"] = execution.ResultID
+	}
+	if query, err := s.nl2sqlService.WithContext(ctx).GetQueryDetails(userID(ctx), execution.QueryID); err == nil {
+		details["data_source_id"] = query.DataSourceID
+		details["nl_query"] = query.NLQuery
+		if execution.ExecutedSQL == "" {
+			details["sql"] = query.GeneratedSQL
+		}
+	}
+
+	s.auditService.Log(auditActor(ctx), models.AuditActionQueryExecute, "nl2sql_query", execution.QueryID, nil, nil, grpcAuditDetails(details))
+}
+
+func toConvertResponse(response *models.NL2SQLResponse) *pb.ConvertResponse {
+	result := &pb.ConvertResponse{
+		QueryId:       uint32(response.QueryID),
+		GeneratedSql:  response.GeneratedSQL,
+		CanExecute:    response.CanExecute,
+		SafetyScore:   response.SafetyScore,
+		EstimatedCost: response.EstimatedCost,
+		Messages:      response.Messages,
+		Language:      response.Language,
+		DryRun:        response.DryRun,
+		Validation: &pb.Validation{
+			Dialect:    string(response.Validation.Dialect),
+			IsValid:    response.Validation.IsValid,
+			IsReadOnly: response.Validation.IsReadOnly,
+			HasLimit:   response.Validation.HasLimit,
+			Violations: response.Validation.Violations,
+			Warnings:   response.Validation.Warnings,
+		},
+	}
+	for _, parameter := range response.Parameters {
+		result.Parameters = append(result.Parameters, parameter.Name)
+	}
+	return result
+}
+
+// toRowBatches splits the rows of an execution into batches of at most
+// batchSize rows. The first batch carries the columns and timing; a result
+// without rows is a single empty last batch.
+func toRowBatches(execution *models.QueryExecutionResponse, batchSize int) ([]*pb.RowBatch, error) {
+	var batches []*pb.RowBatch
+	total := int64(len(execution.Data))
+	for offset := 0; offset == 0 || offset < len(execution.Data); offset += batchSize {
+		end := offset + batchSize
+		if end > len(execution.Data) {
+			end = len(execution.Data)
+		}
+
+		batch := &pb.RowBatch{
+			QueryId:       uint32(execution.QueryID),
+			ResultId:      uint32(execution.ResultID),
+			RowOffset:     int64(offset),
+			TotalRows:     total,
+			Last:          end == len(execution.Data),
+			MaskedColumns: execution.MaskedColumns,
+		}
+		if offset == 0 {
+			batch.ExecutionTimeMs = execution.ExecutionTime
+			for _, column := range execution.Columns {
+				batch.Columns = append(batch.Columns, &pb.Column{
+					Name:        column.Name,
+					Type:        column.Type,
+					Nullable:    column.Nullable,
+					Description: column.Description,
+				})
+			}
+		}
+		batch.Rows = make([]*structpb.Struct, 0, end-offset)
+		for _, row := range execution.Data[offset:end] {
+			value, err := toStruct(row)
+			if err != nil {
+				return nil, err
+			}
+			batch.Rows = append(batch.Rows, value)
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
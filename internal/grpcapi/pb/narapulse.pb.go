@@ -0,0 +1,1252 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        (unknown)
+// source: narapulse/v1/narapulse.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ConvertRequest is a question about a data source.
+type ConvertRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	NlQuery      string                 `protobuf:"bytes,1,opt,name=nl_query,json=nlQuery,proto3" json:"nl_query,omitempty"`
+	DataSourceId uint32                 `protobuf:"varint,2,opt,name=data_source_id,json=dataSourceId,proto3" json:"data_source_id,omitempty"`
+	// Language of the question, en or id; detected when empty.
+	Language string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	// Generate and validate without storing the query.
+	DryRun bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Earlier query of the user the question follows up on.
+	ParentQueryId uint32 `protobuf:"varint,5,opt,name=parent_query_id,json=parentQueryId,proto3" json:"parent_query_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConvertRequest) GetNlQuery() string {
+	if x != nil {
+		return x.NlQuery
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetDataSourceId() uint32 {
+	if x != nil {
+		return x.DataSourceId
+	}
+	return 0
+}
+
+func (x *ConvertRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ConvertRequest) GetParentQueryId() uint32 {
+	if x != nil {
+		return x.ParentQueryId
+	}
+	return 0
+}
+
+// ConvertResponse is the generated SQL with its validation.
+type ConvertResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Zero for dry runs.
+	QueryId       uint32      `protobuf:"varint,1,opt,name=query_id,json=queryId,proto3" json:"query_id,omitempty"`
+	GeneratedSql  string      `protobuf:"bytes,2,opt,name=generated_sql,json=generatedSql,proto3" json:"generated_sql,omitempty"`
+	CanExecute    bool        `protobuf:"varint,3,opt,name=can_execute,json=canExecute,proto3" json:"can_execute,omitempty"`
+	SafetyScore   float64     `protobuf:"fixed64,4,opt,name=safety_score,json=safetyScore,proto3" json:"safety_score,omitempty"`
+	EstimatedCost float64     `protobuf:"fixed64,5,opt,name=estimated_cost,json=estimatedCost,proto3" json:"estimated_cost,omitempty"`
+	Messages      []string    `protobuf:"bytes,6,rep,name=messages,proto3" json:"messages,omitempty"`
+	Language      string      `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	DryRun        bool        `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Validation    *Validation `protobuf:"bytes,9,opt,name=validation,proto3" json:"validation,omitempty"`
+	// Placeholders to give values for on execution.
+	Parameters    []string `protobuf:"bytes,10,rep,name=parameters,proto3" json:"parameters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConvertResponse) GetQueryId() uint32 {
+	if x != nil {
+		return x.QueryId
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetGeneratedSql() string {
+	if x != nil {
+		return x.GeneratedSql
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetCanExecute() bool {
+	if x != nil {
+		return x.CanExecute
+	}
+	return false
+}
+
+func (x *ConvertResponse) GetSafetyScore() float64 {
+	if x != nil {
+		return x.SafetyScore
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetEstimatedCost() float64 {
+	if x != nil {
+		return x.EstimatedCost
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetMessages() []string {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ConvertResponse) GetValidation() *Validation {
+	if x != nil {
+		return x.Validation
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetParameters() []string {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+// Validation is the result of validating generated SQL.
+type Validation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dialect       string                 `protobuf:"bytes,1,opt,name=dialect,proto3" json:"dialect,omitempty"`
+	IsValid       bool                   `protobuf:"varint,2,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	IsReadOnly    bool                   `protobuf:"varint,3,opt,name=is_read_only,json=isReadOnly,proto3" json:"is_read_only,omitempty"`
+	HasLimit      bool                   `protobuf:"varint,4,opt,name=has_limit,json=hasLimit,proto3" json:"has_limit,omitempty"`
+	Violations    []string               `protobuf:"bytes,5,rep,name=violations,proto3" json:"violations,omitempty"`
+	Warnings      []string               `protobuf:"bytes,6,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Validation) Reset() {
+	*x = Validation{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Validation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Validation) ProtoMessage() {}
+
+func (x *Validation) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Validation.ProtoReflect.Descriptor instead.
+func (*Validation) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Validation) GetDialect() string {
+	if x != nil {
+		return x.Dialect
+	}
+	return ""
+}
+
+func (x *Validation) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *Validation) GetIsReadOnly() bool {
+	if x != nil {
+		return x.IsReadOnly
+	}
+	return false
+}
+
+func (x *Validation) GetHasLimit() bool {
+	if x != nil {
+		return x.HasLimit
+	}
+	return false
+}
+
+func (x *Validation) GetViolations() []string {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+func (x *Validation) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// ExecuteRequest executes a stored query.
+type ExecuteRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	QueryId uint32                 `protobuf:"varint,1,opt,name=query_id,json=queryId,proto3" json:"query_id,omitempty"`
+	// Rows returned in total; the default of the REST API when zero.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Rows per batch, at most 1000; 500 when zero.
+	BatchSize int32 `protobuf:"varint,3,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	// Values of the query placeholders.
+	Parameters *structpb.Struct `protobuf:"bytes,4,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	// Pseudonymize strings and jitter numbers in the rows.
+	Anonymize bool `protobuf:"varint,5,opt,name=anonymize,proto3" json:"anonymize,omitempty"`
+	// Convert currency columns to this ISO 4217 code.
+	Currency      string `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRequest) Reset() {
+	*x = ExecuteRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRequest) ProtoMessage() {}
+
+func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExecuteRequest) GetQueryId() uint32 {
+	if x != nil {
+		return x.QueryId
+	}
+	return 0
+}
+
+func (x *ExecuteRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ExecuteRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *ExecuteRequest) GetParameters() *structpb.Struct {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetAnonymize() bool {
+	if x != nil {
+		return x.Anonymize
+	}
+	return false
+}
+
+func (x *ExecuteRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+// RowBatch is a batch of the rows of an execution.
+type RowBatch struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	QueryId  uint32                 `protobuf:"varint,1,opt,name=query_id,json=queryId,proto3" json:"query_id,omitempty"`
+	ResultId uint32                 `protobuf:"varint,2,opt,name=result_id,json=resultId,proto3" json:"result_id,omitempty"`
+	// Set on the first batch only.
+	Columns []*Column          `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	Rows    []*structpb.Struct `protobuf:"bytes,4,rep,name=rows,proto3" json:"rows,omitempty"`
+	// Index of the first row of the batch.
+	RowOffset int64 `protobuf:"varint,5,opt,name=row_offset,json=rowOffset,proto3" json:"row_offset,omitempty"`
+	TotalRows int64 `protobuf:"varint,6,opt,name=total_rows,json=totalRows,proto3" json:"total_rows,omitempty"`
+	Last      bool  `protobuf:"varint,7,opt,name=last,proto3" json:"last,omitempty"`
+	// Set on the first batch only.
+	ExecutionTimeMs int64 `protobuf:"varint,8,opt,name=execution_time_ms,json=executionTimeMs,proto3" json:"execution_time_ms,omitempty"`
+	// PII columns whose values were masked.
+	MaskedColumns []string `protobuf:"bytes,9,rep,name=masked_columns,json=maskedColumns,proto3" json:"masked_columns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RowBatch) Reset() {
+	*x = RowBatch{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RowBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RowBatch) ProtoMessage() {}
+
+func (x *RowBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RowBatch.ProtoReflect.Descriptor instead.
+func (*RowBatch) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RowBatch) GetQueryId() uint32 {
+	if x != nil {
+		return x.QueryId
+	}
+	return 0
+}
+
+func (x *RowBatch) GetResultId() uint32 {
+	if x != nil {
+		return x.ResultId
+	}
+	return 0
+}
+
+func (x *RowBatch) GetColumns() []*Column {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *RowBatch) GetRows() []*structpb.Struct {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *RowBatch) GetRowOffset() int64 {
+	if x != nil {
+		return x.RowOffset
+	}
+	return 0
+}
+
+func (x *RowBatch) GetTotalRows() int64 {
+	if x != nil {
+		return x.TotalRows
+	}
+	return 0
+}
+
+func (x *RowBatch) GetLast() bool {
+	if x != nil {
+		return x.Last
+	}
+	return false
+}
+
+func (x *RowBatch) GetExecutionTimeMs() int64 {
+	if x != nil {
+		return x.ExecutionTimeMs
+	}
+	return 0
+}
+
+func (x *RowBatch) GetMaskedColumns() []string {
+	if x != nil {
+		return x.MaskedColumns
+	}
+	return nil
+}
+
+// Column is a column of a result.
+type Column struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Nullable      bool                   `protobuf:"varint,3,opt,name=nullable,proto3" json:"nullable,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Column) Reset() {
+	*x = Column{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Column) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Column) ProtoMessage() {}
+
+func (x *Column) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Column.ProtoReflect.Descriptor instead.
+func (*Column) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Column) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Column) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Column) GetNullable() bool {
+	if x != nil {
+		return x.Nullable
+	}
+	return false
+}
+
+func (x *Column) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// DataSource is a data source with its credentials masked.
+type DataSource struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Config        *structpb.Struct       `protobuf:"bytes,6,opt,name=config,proto3" json:"config,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	LastTested    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_tested,json=lastTested,proto3" json:"last_tested,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataSource) Reset() {
+	*x = DataSource{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataSource) ProtoMessage() {}
+
+func (x *DataSource) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataSource.ProtoReflect.Descriptor instead.
+func (*DataSource) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DataSource) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DataSource) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DataSource) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DataSource) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DataSource) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DataSource) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *DataSource) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *DataSource) GetLastTested() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastTested
+	}
+	return nil
+}
+
+func (x *DataSource) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *DataSource) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// CreateDataSourceRequest creates a data source.
+type CreateDataSourceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Config        *structpb.Struct       `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDataSourceRequest) Reset() {
+	*x = CreateDataSourceRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDataSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDataSourceRequest) ProtoMessage() {}
+
+func (x *CreateDataSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDataSourceRequest.ProtoReflect.Descriptor instead.
+func (*CreateDataSourceRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateDataSourceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateDataSourceRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateDataSourceRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreateDataSourceRequest) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// GetDataSourceRequest selects a data source.
+type GetDataSourceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataSourceRequest) Reset() {
+	*x = GetDataSourceRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataSourceRequest) ProtoMessage() {}
+
+func (x *GetDataSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataSourceRequest.ProtoReflect.Descriptor instead.
+func (*GetDataSourceRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetDataSourceRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// ListDataSourcesRequest lists the data sources of the user.
+type ListDataSourcesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataSourcesRequest) Reset() {
+	*x = ListDataSourcesRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataSourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataSourcesRequest) ProtoMessage() {}
+
+func (x *ListDataSourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataSourcesRequest.ProtoReflect.Descriptor instead.
+func (*ListDataSourcesRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{9}
+}
+
+// ListDataSourcesResponse holds the data sources of the user.
+type ListDataSourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DataSources   []*DataSource          `protobuf:"bytes,1,rep,name=data_sources,json=dataSources,proto3" json:"data_sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataSourcesResponse) Reset() {
+	*x = ListDataSourcesResponse{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataSourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataSourcesResponse) ProtoMessage() {}
+
+func (x *ListDataSourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataSourcesResponse.ProtoReflect.Descriptor instead.
+func (*ListDataSourcesResponse) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListDataSourcesResponse) GetDataSources() []*DataSource {
+	if x != nil {
+		return x.DataSources
+	}
+	return nil
+}
+
+// UpdateDataSourceRequest updates a data source.
+type UpdateDataSourceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Config        *structpb.Struct       `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDataSourceRequest) Reset() {
+	*x = UpdateDataSourceRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDataSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDataSourceRequest) ProtoMessage() {}
+
+func (x *UpdateDataSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDataSourceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDataSourceRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateDataSourceRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateDataSourceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateDataSourceRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateDataSourceRequest) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+// DeleteDataSourceRequest deletes a data source.
+type DeleteDataSourceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Also delete the query history and results of the data source.
+	DeleteQueries bool `protobuf:"varint,2,opt,name=delete_queries,json=deleteQueries,proto3" json:"delete_queries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDataSourceRequest) Reset() {
+	*x = DeleteDataSourceRequest{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDataSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDataSourceRequest) ProtoMessage() {}
+
+func (x *DeleteDataSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDataSourceRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDataSourceRequest) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteDataSourceRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteDataSourceRequest) GetDeleteQueries() bool {
+	if x != nil {
+		return x.DeleteQueries
+	}
+	return false
+}
+
+// DeleteDataSourceResponse is empty.
+type DeleteDataSourceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDataSourceResponse) Reset() {
+	*x = DeleteDataSourceResponse{}
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDataSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDataSourceResponse) ProtoMessage() {}
+
+func (x *DeleteDataSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_narapulse_v1_narapulse_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDataSourceResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDataSourceResponse) Descriptor() ([]byte, []int) {
+	return file_narapulse_v1_narapulse_proto_rawDescGZIP(), []int{13}
+}
+
+var File_narapulse_v1_narapulse_proto protoreflect.FileDescriptor
+
+const file_narapulse_v1_narapulse_proto_rawDesc = "" +
+	"\n" +
+	"\x1cnarapulse/v1/narapulse.proto\x12\fnarapulse.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xae\x01\n" +
+	"\x0eConvertRequest\x12\x19\n" +
+	"\bnl_query\x18\x01 \x01(\tR\anlQuery\x12$\n" +
+	"\x0edata_source_id\x18\x02 \x01(\rR\fdataSourceId\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12&\n" +
+	"\x0fparent_query_id\x18\x05 \x01(\rR\rparentQueryId\"\xe7\x02\n" +
+	"\x0fConvertResponse\x12\x19\n" +
+	"\bquery_id\x18\x01 \x01(\rR\aqueryId\x12#\n" +
+	"\rgenerated_sql\x18\x02 \x01(\tR\fgeneratedSql\x12\x1f\n" +
+	"\vcan_execute\x18\x03 \x01(\bR\n" +
+	"canExecute\x12!\n" +
+	"\fsafety_score\x18\x04 \x01(\x01R\vsafetyScore\x12%\n" +
+	"\x0eestimated_cost\x18\x05 \x01(\x01R\restimatedCost\x12\x1a\n" +
+	"\bmessages\x18\x06 \x03(\tR\bmessages\x12\x1a\n" +
+	"\blanguage\x18\a \x01(\tR\blanguage\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\x128\n" +
+	"\n" +
+	"validation\x18\t \x01(\v2\x18.narapulse.v1.ValidationR\n" +
+	"validation\x12\x1e\n" +
+	"\n" +
+	"parameters\x18\n" +
+	" \x03(\tR\n" +
+	"parameters\"\xbc\x01\n" +
+	"\n" +
+	"Validation\x12\x18\n" +
+	"\adialect\x18\x01 \x01(\tR\adialect\x12\x19\n" +
+	"\bis_valid\x18\x02 \x01(\bR\aisValid\x12 \n" +
+	"\fis_read_only\x18\x03 \x01(\bR\n" +
+	"isReadOnly\x12\x1b\n" +
+	"\thas_limit\x18\x04 \x01(\bR\bhasLimit\x12\x1e\n" +
+	"\n" +
+	"violations\x18\x05 \x03(\tR\n" +
+	"violations\x12\x1a\n" +
+	"\bwarnings\x18\x06 \x03(\tR\bwarnings\"\xd3\x01\n" +
+	"\x0eExecuteRequest\x12\x19\n" +
+	"\bquery_id\x18\x01 \x01(\rR\aqueryId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x03 \x01(\x05R\tbatchSize\x127\n" +
+	"\n" +
+	"parameters\x18\x04 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"parameters\x12\x1c\n" +
+	"\tanonymize\x18\x05 \x01(\bR\tanonymize\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\"\xc4\x02\n" +
+	"\bRowBatch\x12\x19\n" +
+	"\bquery_id\x18\x01 \x01(\rR\aqueryId\x12\x1b\n" +
+	"\tresult_id\x18\x02 \x01(\rR\bresultId\x12.\n" +
+	"\acolumns\x18\x03 \x03(\v2\x14.narapulse.v1.ColumnR\acolumns\x12+\n" +
+	"\x04rows\x18\x04 \x03(\v2\x17.google.protobuf.StructR\x04rows\x12\x1d\n" +
+	"\n" +
+	"row_offset\x18\x05 \x01(\x03R\trowOffset\x12\x1d\n" +
+	"\n" +
+	"total_rows\x18\x06 \x01(\x03R\ttotalRows\x12\x12\n" +
+	"\x04last\x18\a \x01(\bR\x04last\x12*\n" +
+	"\x11execution_time_ms\x18\b \x01(\x03R\x0fexecutionTimeMs\x12%\n" +
+	"\x0emasked_columns\x18\t \x03(\tR\rmaskedColumns\"n\n" +
+	"\x06Column\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\bnullable\x18\x03 \x01(\bR\bnullable\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\"\x87\x03\n" +
+	"\n" +
+	"DataSource\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12/\n" +
+	"\x06config\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x06config\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x12;\n" +
+	"\vlast_tested\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastTested\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x94\x01\n" +
+	"\x17CreateDataSourceRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12/\n" +
+	"\x06config\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x06config\"&\n" +
+	"\x14GetDataSourceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"\x18\n" +
+	"\x16ListDataSourcesRequest\"V\n" +
+	"\x17ListDataSourcesResponse\x12;\n" +
+	"\fdata_sources\x18\x01 \x03(\v2\x18.narapulse.v1.DataSourceR\vdataSources\"\x90\x01\n" +
+	"\x17UpdateDataSourceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12/\n" +
+	"\x06config\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x06config\"P\n" +
+	"\x17DeleteDataSourceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12%\n" +
+	"\x0edelete_queries\x18\x02 \x01(\bR\rdeleteQueries\"\x1a\n" +
+	"\x18DeleteDataSourceResponse2\x9a\x01\n" +
+	"\rNL2SQLService\x12F\n" +
+	"\aConvert\x12\x1c.narapulse.v1.ConvertRequest\x1a\x1d.narapulse.v1.ConvertResponse\x12A\n" +
+	"\aExecute\x12\x1c.narapulse.v1.ExecuteRequest\x1a\x16.narapulse.v1.RowBatch0\x012\xcf\x03\n" +
+	"\x11DataSourceService\x12S\n" +
+	"\x10CreateDataSource\x12%.narapulse.v1.CreateDataSourceRequest\x1a\x18.narapulse.v1.DataSource\x12M\n" +
+	"\rGetDataSource\x12\".narapulse.v1.GetDataSourceRequest\x1a\x18.narapulse.v1.DataSource\x12^\n" +
+	"\x0fListDataSources\x12$.narapulse.v1.ListDataSourcesRequest\x1a%.narapulse.v1.ListDataSourcesResponse\x12S\n" +
+	"\x10UpdateDataSource\x12%.narapulse.v1.UpdateDataSourceRequest\x1a\x18.narapulse.v1.DataSource\x12a\n" +
+	"\x10DeleteDataSource\x12%.narapulse.v1.DeleteDataSourceRequest\x1a&.narapulse.v1.DeleteDataSourceResponseB%Z#narapulse-be/internal/grpcapi/pb;pbb\x06proto3"
+
+var (
+	file_narapulse_v1_narapulse_proto_rawDescOnce sync.Once
+	file_narapulse_v1_narapulse_proto_rawDescData []byte
+)
+
+func file_narapulse_v1_narapulse_proto_rawDescGZIP() []byte {
+	file_narapulse_v1_narapulse_proto_rawDescOnce.Do(func() {
+		file_narapulse_v1_narapulse_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_narapulse_v1_narapulse_proto_rawDesc), len(file_narapulse_v1_narapulse_proto_rawDesc)))
+	})
+	return file_narapulse_v1_narapulse_proto_rawDescData
+}
+
+var file_narapulse_v1_narapulse_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_narapulse_v1_narapulse_proto_goTypes = []any{
+	(*ConvertRequest)(nil),           // 0: narapulse.v1.ConvertRequest
+	(*ConvertResponse)(nil),          // 1: narapulse.v1.ConvertResponse
+	(*Validation)(nil),               // 2: narapulse.v1.Validation
+	(*ExecuteRequest)(nil),           // 3: narapulse.v1.ExecuteRequest
+	(*RowBatch)(nil),                 // 4: narapulse.v1.RowBatch
+	(*Column)(nil),                   // 5: narapulse.v1.Column
+	(*DataSource)(nil),               // 6: narapulse.v1.DataSource
+	(*CreateDataSourceRequest)(nil),  // 7: narapulse.v1.CreateDataSourceRequest
+	(*GetDataSourceRequest)(nil),     // 8: narapulse.v1.GetDataSourceRequest
+	(*ListDataSourcesRequest)(nil),   // 9: narapulse.v1.ListDataSourcesRequest
+	(*ListDataSourcesResponse)(nil),  // 10: narapulse.v1.ListDataSourcesResponse
+	(*UpdateDataSourceRequest)(nil),  // 11: narapulse.v1.UpdateDataSourceRequest
+	(*DeleteDataSourceRequest)(nil),  // 12: narapulse.v1.DeleteDataSourceRequest
+	(*DeleteDataSourceResponse)(nil), // 13: narapulse.v1.DeleteDataSourceResponse
+	(*structpb.Struct)(nil),          // 14: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),    // 15: google.protobuf.Timestamp
+}
+var file_narapulse_v1_narapulse_proto_depIdxs = []int32{
+	2,  // 0: narapulse.v1.ConvertResponse.validation:type_name -> narapulse.v1.Validation
+	14, // 1: narapulse.v1.ExecuteRequest.parameters:type_name -> google.protobuf.Struct
+	5,  // 2: narapulse.v1.RowBatch.columns:type_name -> narapulse.v1.Column
+	14, // 3: narapulse.v1.RowBatch.rows:type_name -> google.protobuf.Struct
+	14, // 4: narapulse.v1.DataSource.config:type_name -> google.protobuf.Struct
+	15, // 5: narapulse.v1.DataSource.last_tested:type_name -> google.protobuf.Timestamp
+	15, // 6: narapulse.v1.DataSource.created_at:type_name -> google.protobuf.Timestamp
+	15, // 7: narapulse.v1.DataSource.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 8: narapulse.v1.CreateDataSourceRequest.config:type_name -> google.protobuf.Struct
+	6,  // 9: narapulse.v1.ListDataSourcesResponse.data_sources:type_name -> narapulse.v1.DataSource
+	14, // 10: narapulse.v1.UpdateDataSourceRequest.config:type_name -> google.protobuf.Struct
+	0,  // 11: narapulse.v1.NL2SQLService.Convert:input_type -> narapulse.v1.ConvertRequest
+	3,  // 12: narapulse.v1.NL2SQLService.Execute:input_type -> narapulse.v1.ExecuteRequest
+	7,  // 13: narapulse.v1.DataSourceService.CreateDataSource:input_type -> narapulse.v1.CreateDataSourceRequest
+	8,  // 14: narapulse.v1.DataSourceService.GetDataSource:input_type -> narapulse.v1.GetDataSourceRequest
+	9,  // 15: narapulse.v1.DataSourceService.ListDataSources:input_type -> narapulse.v1.ListDataSourcesRequest
+	11, // 16: narapulse.v1.DataSourceService.UpdateDataSource:input_type -> narapulse.v1.UpdateDataSourceRequest
+	12, // 17: narapulse.v1.DataSourceService.DeleteDataSource:input_type -> narapulse.v1.DeleteDataSourceRequest
+	1,  // 18: narapulse.v1.NL2SQLService.Convert:output_type -> narapulse.v1.ConvertResponse
+	4,  // 19: narapulse.v1.NL2SQLService.Execute:output_type -> narapulse.v1.RowBatch
+	6,  // 20: narapulse.v1.DataSourceService.CreateDataSource:output_type -> narapulse.v1.DataSource
+	6,  // 21: narapulse.v1.DataSourceService.GetDataSource:output_type -> narapulse.v1.DataSource
+	10, // 22: narapulse.v1.DataSourceService.ListDataSources:output_type -> narapulse.v1.ListDataSourcesResponse
+	6,  // 23: narapulse.v1.DataSourceService.UpdateDataSource:output_type -> narapulse.v1.DataSource
+	13, // 24: narapulse.v1.DataSourceService.DeleteDataSource:output_type -> narapulse.v1.DeleteDataSourceResponse
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_narapulse_v1_narapulse_proto_init() }
+func file_narapulse_v1_narapulse_proto_init() {
+	if File_narapulse_v1_narapulse_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_narapulse_v1_narapulse_proto_rawDesc), len(file_narapulse_v1_narapulse_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_narapulse_v1_narapulse_proto_goTypes,
+		DependencyIndexes: file_narapulse_v1_narapulse_proto_depIdxs,
+		MessageInfos:      file_narapulse_v1_narapulse_proto_msgTypes,
+	}.Build()
+	File_narapulse_v1_narapulse_proto = out.File
+	file_narapulse_v1_narapulse_proto_goTypes = nil
+	file_narapulse_v1_narapulse_proto_depIdxs = nil
+}
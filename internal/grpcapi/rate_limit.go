@@ -0,0 +1,68 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"narapulse-be/internal/grpcapi/pb"
+	"narapulse-be/internal/middleware"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimits are the limits calls take from, the buckets of the REST routes
+// per user and tenant: every call takes from the API limit, like the
+// authenticated routes, and NL2SQL conversions from the AI limit as well.
+// Without a limiter calls are not limited.
+type RateLimits struct {
+	Limiter *middleware.RateLimiter
+	API     func() middleware.Limits
+	AI      func() middleware.Limits
+}
+
+// aiMethods are the methods whose REST routes are guarded by the AI limit
+var aiMethods = map[string]bool{
+	pb.NL2SQLService_Convert_FullMethodName: true,
+}
+
+func (l RateLimits) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := l.take(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (l RateLimits) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.take(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// take takes the call of the authenticated user from the buckets of the
+// method, failing with ResourceExhausted when one is empty
+func (l RateLimits) take(ctx context.Context, fullMethod string) error {
+	if err := l.takeFrom(ctx, middleware.RateLimitAPI, l.API); err != nil {
+		return err
+	}
+	if aiMethods[fullMethod] {
+		return l.takeFrom(ctx, middleware.RateLimitAI, l.AI)
+	}
+	return nil
+}
+
+func (l RateLimits) takeFrom(ctx context.Context, name string, current func() middleware.Limits) error {
+	if l.Limiter == nil || current == nil {
+		return nil
+	}
+	tenantID, _ := tenancy.FromContext(ctx)
+	err := l.Limiter.Take(ctx, name, userID(ctx), tenantID, current())
+	var exceeded *middleware.RateLimitExceededError
+	if errors.As(err, &exceeded) {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded: "+exceeded.Error())
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package grpcapi serves NL2SQL and data source management over gRPC for
+// internal services, next to the REST API. It calls the same services as the
+// Fiber handlers; the protobuf definitions are in proto/narapulse/v1.
+package grpcapi
+
+import (
+	"encoding/json"
+	"errors"
+
+	"narapulse-be/internal/grpcapi/pb"
+	"narapulse-be/internal/middleware"
+	"narapulse-be/internal/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// NewServer returns a gRPC server with the NL2SQL and data source services
+// registered. Calls need an access token of an active user, are authorized
+// against the policies of the equivalent REST routes and take from their rate
+// limits; authorizer may be nil when Casbin is unavailable.
+func NewServer(nl2sqlService *services.NL2SQLService, dataSourceService services.DataSourceService, auditService *services.AuditService,
+	authorizer middleware.Authorizer, accounts middleware.AccountResolver, jwtSecret string, limits RateLimits) *grpc.Server {
+	auth := &authenticator{jwtSecret: jwtSecret, authorizer: authorizer, accounts: accounts}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auth.unary, limits.unary),
+		grpc.ChainStreamInterceptor(auth.stream, limits.stream),
+	)
+	pb.RegisterNL2SQLServiceServer(server, &nl2sqlServer{nl2sqlService: nl2sqlService, auditService: auditService})
+	pb.RegisterDataSourceServiceServer(server, &dataSourceServer{dataSourceService: dataSourceService, auditService: auditService})
+	return server
+}
+
+// statusError converts an error of the services to a gRPC status with the
+// code matching the status the REST API responds with
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrUsageQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrFeatureDisabled):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrParentQueryNotFound):
+		return status.Error(codes.NotFound, "parent query not found")
+	case err.Error() == "query not found":
+		return status.Error(codes.NotFound, "query not found")
+	case err.Error() == "query is not executable":
+		return status.Error(codes.FailedPrecondition, "query is not executable")
+	case errors.Is(err, services.ErrQueryCostExceeded), errors.Is(err, services.ErrInvalidQueryParameters),
+		errors.Is(err, services.ErrUnsupportedCurrency), errors.Is(err, services.ErrFederationInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// toStruct converts a JSON object, such as a result row or a data source
+// config, to a Struct. Values are encoded as in REST responses, so times
+// become RFC 3339 strings. Nil converts to nil.
+func toStruct(value interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	result := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
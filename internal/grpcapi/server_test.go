@@ -0,0 +1,155 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"narapulse-be/internal/grpcapi/pb"
+	"narapulse-be/internal/middleware"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/ratelimit"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/pkg/utils"
+	"narapulse-be/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAuthorizer allows the subject/path/method combinations in its set
+type fakeAuthorizer struct {
+	allowed map[string]bool
+}
+
+func (a *fakeAuthorizer) Enforce(sub, obj, act string) (bool, error) {
+	return a.allowed[sub+" "+act+" "+obj], nil
+}
+
+// fakeAccounts resolves the users of the test tokens
+type fakeAccounts map[uint]*models.User
+
+func (a fakeAccounts) GetAccount(_ context.Context, id uint) (*models.User, error) {
+	return a[id], nil
+}
+
+func callUnary(t *testing.T, auth *authenticator, token, fullMethod string, req interface{}) (uint, error) {
+	t.Helper()
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+	var user uint
+	_, err := auth.unary(ctx, req, &grpc.UnaryServerInfo{FullMethod: fullMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		user = userID(ctx)
+		return nil, nil
+	})
+	return user, err
+}
+
+func TestAuthenticatorUsesRESTRoutePolicies(t *testing.T) {
+	auth := &authenticator{jwtSecret: "secret", authorizer: &fakeAuthorizer{allowed: map[string]bool{
+		"user GET /api/v1/data-sources/7": true,
+	}}, accounts: fakeAccounts{3: {ID: 3, Email: "analyst@narapulse.com", Role: "user", IsActive: true}}}
+	token, err := utils.GenerateToken(3, 1, "analyst@narapulse.com", "user", "secret")
+	require.NoError(t, err)
+
+	user, err := callUnary(t, auth, token, pb.DataSourceService_GetDataSource_FullMethodName, &pb.GetDataSourceRequest{Id: 7})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), user)
+
+	_, err = callUnary(t, auth, token, pb.DataSourceService_DeleteDataSource_FullMethodName, &pb.DeleteDataSourceRequest{Id: 7})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = callUnary(t, auth, "", pb.DataSourceService_GetDataSource_FullMethodName, &pb.GetDataSourceRequest{Id: 7})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	mfaToken, err := utils.GenerateScopedToken(3, 1, "analyst@narapulse.com", "user", utils.TokenPurposeMFA, "secret", time.Minute)
+	require.NoError(t, err)
+	_, err = callUnary(t, auth, mfaToken, pb.DataSourceService_GetDataSource_FullMethodName, &pb.GetDataSourceRequest{Id: 7})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "tokens still needing a second factor are refused")
+
+	// Tokens of deactivated users are refused, and the current role is authorized rather than the token's
+	auth.accounts = fakeAccounts{3: {ID: 3, Email: "analyst@narapulse.com", Role: "user", IsActive: false}}
+	_, err = callUnary(t, auth, token, pb.DataSourceService_GetDataSource_FullMethodName, &pb.GetDataSourceRequest{Id: 7})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	auth.accounts = fakeAccounts{3: {ID: 3, Email: "analyst@narapulse.com", Role: "viewer", IsActive: true}}
+	_, err = callUnary(t, auth, token, pb.DataSourceService_GetDataSource_FullMethodName, &pb.GetDataSourceRequest{Id: 7})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestRateLimitsShareTheRESTBuckets(t *testing.T) {
+	limiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore())
+	limits := RateLimits{
+		Limiter: limiter,
+		API:     func() middleware.Limits { return middleware.Limits{User: ratelimit.PerMinute(3), Tenant: ratelimit.PerMinute(4)} },
+		AI:      func() middleware.Limits { return middleware.Limits{User: ratelimit.PerMinute(1)} },
+	}
+	call := func(userID, tenantID uint, fullMethod string) error {
+		ctx := tenancy.WithTenant(context.WithValue(context.Background(), claimsKey{}, &utils.Claims{UserID: userID}), tenantID)
+		_, err := limits.unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, func(context.Context, interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	// Conversions take from the AI limit as well as the API limit
+	require.NoError(t, call(1, 1, pb.NL2SQLService_Convert_FullMethodName))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call(1, 1, pb.NL2SQLService_Convert_FullMethodName)))
+	require.NoError(t, call(1, 1, pb.DataSourceService_ListDataSources_FullMethodName), "other methods only take from the API limit")
+
+	// The user's bucket is the one of the REST routes
+	ctx := tenancy.WithTenant(context.Background(), 1)
+	assert.Error(t, limiter.Take(ctx, middleware.RateLimitAPI, 1, 1, limits.API()), "REST requests find the bucket emptied by the calls")
+
+	// Another user of the tenant shares the tenant's bucket, 3 of which are taken now
+	require.NoError(t, call(2, 1, pb.DataSourceService_ListDataSources_FullMethodName))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call(2, 1, pb.DataSourceService_ListDataSources_FullMethodName)))
+	require.NoError(t, call(3, 2, pb.DataSourceService_ListDataSources_FullMethodName), "other tenants are not limited")
+}
+
+func TestStatusErrorMatchesRESTStatuses(t *testing.T) {
+	assert.Equal(t, codes.ResourceExhausted, status.Code(statusError(services.ErrUsageQuotaExceeded)))
+	assert.Equal(t, codes.NotFound, status.Code(statusError(errors.New("query not found"))))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(statusError(errors.New("query is not executable"))))
+	assert.Equal(t, codes.InvalidArgument, status.Code(statusError(fmt.Errorf("%w: missing region", services.ErrInvalidQueryParameters))))
+	assert.Equal(t, codes.Internal, status.Code(statusError(errors.New("connection refused"))))
+}
+
+func TestToRowBatches(t *testing.T) {
+	execution := &models.QueryExecutionResponse{
+		QueryID:       4,
+		ResultID:      9,
+		Columns:       []models.Column{{Name: "region", Type: "string"}, {Name: "revenue", Type: "float"}},
+		ExecutionTime: 12,
+		MaskedColumns: []string{"email"},
+	}
+	for i := 0; i < 5; i++ {
+		execution.Data = append(execution.Data, map[string]interface{}{"region": fmt.Sprintf("r%d", i), "revenue": float64(i)})
+	}
+
+	batches, err := toRowBatches(execution, 2)
+	require.NoError(t, err)
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[0].Columns, 2)
+	assert.Equal(t, int64(12), batches[0].ExecutionTimeMs)
+	assert.Empty(t, batches[1].Columns, "only the first batch carries the columns")
+	assert.Equal(t, int64(4), batches[2].RowOffset)
+	assert.Len(t, batches[2].Rows, 1)
+	assert.Equal(t, "r4", batches[2].Rows[0].AsMap()["region"])
+	assert.True(t, batches[2].Last)
+	assert.False(t, batches[1].Last)
+
+	execution.Data = nil
+	batches, err = toRowBatches(execution, 2)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.True(t, batches[0].Last)
+	assert.Len(t, batches[0].Columns, 2)
+}
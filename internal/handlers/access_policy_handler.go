@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccessPolicyHandler struct {
+	casbinService *services.CasbinService
+	auditService  *services.AuditService
+	validator     *validator.Validate
+}
+
+func NewAccessPolicyHandler(casbinService *services.CasbinService, auditService *services.AuditService) *AccessPolicyHandler {
+	return &AccessPolicyHandler{
+		casbinService: casbinService,
+		auditService:  auditService,
+		validator:     validator.New(),
+	}
+}
+
+// GetPolicies godoc
+// @Summary List route access policies
+// @Description List the policies that allow roles and users to call routes
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.AccessPolicy}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/access-policies [get]
+func (h *AccessPolicyHandler) GetPolicies(c *fiber.Ctx) error {
+	policies, err := h.casbinService.GetPolicies()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get access policies", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Access policies retrieved successfully", policies)
+}
+
+// AddPolicy godoc
+// @Summary Add a route access policy
+// @Description Allow a role or user to call the routes matching the path with the method. Takes effect immediately.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param policy body models.AccessPolicy true "Policy; a trailing * in the path matches any suffix, method * matches every method"
+// @Success 201 {object} models.StandardResponse{data=models.AccessPolicy}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/access-policies [post]
+func (h *AccessPolicyHandler) AddPolicy(c *fiber.Ctx) error {
+	var req entity.AccessPolicy
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	added, err := h.casbinService.AddPolicy(req.Role, req.Path, req.Method)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to add access policy", err.Error())
+	}
+	if !added {
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, "Access policy already exists", nil)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionAccessPolicyChange, "access_policy", 0, nil, req, nil)
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Access policy added successfully",
+		Data:    req,
+	})
+}
+
+// RemovePolicy godoc
+// @Summary Remove a route access policy
+// @Description Remove a policy; requests it allowed are refused from then on
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param policy body models.AccessPolicy true "Policy to remove"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/access-policies [delete]
+func (h *AccessPolicyHandler) RemovePolicy(c *fiber.Ctx) error {
+	var req entity.AccessPolicy
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	removed, err := h.casbinService.RemovePolicy(req.Role, req.Path, req.Method)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to remove access policy", err.Error())
+	}
+	if !removed {
+		return entity.NotFoundResponse(c, "Access policy not found")
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionAccessPolicyChange, "access_policy", 0, req, nil, nil)
+
+	return entity.SuccessResponse(c, "Access policy removed successfully", nil)
+}
+
+// GetRoleAssignments godoc
+// @Summary List role assignments
+// @Description List the roles assigned to users in addition to the role stored on the user
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.RoleAssignment}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/role-assignments [get]
+func (h *AccessPolicyHandler) GetRoleAssignments(c *fiber.Ctx) error {
+	assignments, err := h.casbinService.GetRoleAssignments()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get role assignments", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Role assignments retrieved successfully", assignments)
+}
+
+// AssignRole godoc
+// @Summary Assign a role to a user
+// @Description Grant a user, identified by email, the policies of a role. Takes effect immediately.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param assignment body models.RoleAssignment true "Role assignment"
+// @Success 201 {object} models.StandardResponse{data=models.RoleAssignment}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/role-assignments [post]
+func (h *AccessPolicyHandler) AssignRole(c *fiber.Ctx) error {
+	var req entity.RoleAssignment
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	added, err := h.casbinService.AddRoleForUser(req.User, req.Role)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to assign role", err.Error())
+	}
+	if !added {
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, "Role is already assigned", nil)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionUserRoleChange, "role_assignment", 0, nil, req, nil)
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Role assigned successfully",
+		Data:    req,
+	})
+}
+
+// UnassignRole godoc
+// @Summary Remove a role from a user
+// @Description Remove a role assignment; requests it allowed are refused from then on
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param assignment body models.RoleAssignment true "Role assignment to remove"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/role-assignments [delete]
+func (h *AccessPolicyHandler) UnassignRole(c *fiber.Ctx) error {
+	var req entity.RoleAssignment
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	removed, err := h.casbinService.DeleteRoleForUser(req.User, req.Role)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to remove role", err.Error())
+	}
+	if !removed {
+		return entity.NotFoundResponse(c, "Role assignment not found")
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionUserRoleChange, "role_assignment", 0, req, nil, nil)
+
+	return entity.SuccessResponse(c, "Role removed successfully", nil)
+}
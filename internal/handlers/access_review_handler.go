@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccessReviewHandler struct {
+	accessReviewService *services.AccessReviewService
+	auditService        *services.AuditService
+}
+
+func NewAccessReviewHandler(accessReviewService *services.AccessReviewService, auditService *services.AuditService) *AccessReviewHandler {
+	return &AccessReviewHandler{
+		accessReviewService: accessReviewService,
+		auditService:        auditService,
+	}
+}
+
+// GetReport godoc
+// @Summary Get the access review report
+// @Description Per-user entitlement report for access reviews: roles, accessible data sources, masked (PII) columns, data API keys and last activity. Use format=csv to download it as CSV.
+// @Tags admin
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "Response format: json or csv" default(json)
+// @Success 200 {object} models.StandardResponse{data=models.AccessReviewReport}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/access-review [get]
+func (h *AccessReviewHandler) GetReport(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "csv" {
+		return entity.BadRequestResponse(c, "Invalid format", "format must be json or csv")
+	}
+
+	report, err := h.accessReviewService.WithContext(c.UserContext()).Generate()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to generate access review report", err.Error())
+	}
+
+	if format == "json" {
+		return entity.SuccessResponse(c, "Access review report generated successfully", report)
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteAccessReviewCSV(&buf, report); err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to export access review report", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataExport, "access_review", 0, nil, nil,
+		map[string]interface{}{"format": format, "users": len(report.Users)})
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="access-review-%s.csv"`, report.GeneratedAt.Format("2006-01-02")))
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AlertHandler struct {
+	alertService *services.AlertService
+	validator    *validator.Validate
+}
+
+func NewAlertHandler(alertService *services.AlertService) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+		validator:    validator.New(),
+	}
+}
+
+// GetAlertRules godoc
+// @Summary List alert rules
+// @Description List the user's KPI alert rules with their last check
+// @Tags alerts
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.AlertRule}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /alerts [get]
+func (h *AlertHandler) GetAlertRules(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	rules, err := h.alertService.WithContext(c.UserContext()).List(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve alert rules", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Alert rules retrieved successfully", rules)
+}
+
+// GetAlertRule godoc
+// @Summary Get an alert rule
+// @Tags alerts
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} models.StandardResponse{data=models.AlertRule}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /alerts/{id} [get]
+func (h *AlertHandler) GetAlertRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid alert rule ID", err.Error())
+	}
+
+	rule, err := h.alertService.WithContext(c.UserContext()).Get(userID, uint(id))
+	if err != nil {
+		return alertErrorResponse(c, "Failed to retrieve alert rule", err)
+	}
+
+	return entity.SuccessResponse(c, "Alert rule retrieved successfully", rule)
+}
+
+// CreateAlertRule godoc
+// @Summary Create an alert rule
+// @Description Watch a KPI and post to a Slack or Teams integration when it goes above or below a threshold, and when it recovers
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param rule body models.AlertRuleRequest true "Alert rule"
+// @Success 201 {object} models.StandardResponse{data=models.AlertRule}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /alerts [post]
+func (h *AlertHandler) CreateAlertRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.AlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	rule, err := h.alertService.WithContext(c.UserContext()).Create(userID, &req)
+	if err != nil {
+		return alertErrorResponse(c, "Failed to create alert rule", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Alert rule created successfully", rule)
+}
+
+// UpdateAlertRule godoc
+// @Summary Update an alert rule
+// @Description Replace an alert rule; changing the KPI, condition or threshold resets its state
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Param rule body models.AlertRuleRequest true "Alert rule"
+// @Success 200 {object} models.StandardResponse{data=models.AlertRule}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /alerts/{id} [put]
+func (h *AlertHandler) UpdateAlertRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid alert rule ID", err.Error())
+	}
+
+	var req entity.AlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	rule, err := h.alertService.WithContext(c.UserContext()).Update(userID, uint(id), &req)
+	if err != nil {
+		return alertErrorResponse(c, "Failed to update alert rule", err)
+	}
+
+	return entity.SuccessResponse(c, "Alert rule updated successfully", rule)
+}
+
+// DeleteAlertRule godoc
+// @Summary Delete an alert rule
+// @Tags alerts
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /alerts/{id} [delete]
+func (h *AlertHandler) DeleteAlertRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid alert rule ID", err.Error())
+	}
+
+	if err := h.alertService.WithContext(c.UserContext()).Delete(userID, uint(id)); err != nil {
+		return alertErrorResponse(c, "Failed to delete alert rule", err)
+	}
+
+	return entity.SuccessResponse(c, "Alert rule deleted successfully", nil)
+}
+
+// CheckAlertRule godoc
+// @Summary Check an alert rule now
+// @Description Compute the KPI of a rule now, posting to its channel if the rule triggers or resolves
+// @Tags alerts
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} models.StandardResponse{data=models.AlertRule}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /alerts/{id}/check [post]
+func (h *AlertHandler) CheckAlertRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid alert rule ID", err.Error())
+	}
+
+	rule, err := h.alertService.WithContext(c.UserContext()).Check(c.UserContext(), userID, uint(id))
+	if err != nil {
+		return alertErrorResponse(c, "Failed to check alert rule", err)
+	}
+
+	return entity.SuccessResponse(c, "Alert rule checked", rule)
+}
+
+func alertErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrAlertRuleNotFound):
+		return entity.NotFoundResponse(c, "Alert rule not found")
+	case errors.Is(err, services.ErrKPINotFound):
+		return entity.NotFoundResponse(c, "KPI not found")
+	case errors.Is(err, services.ErrIntegrationNotFound):
+		return entity.NotFoundResponse(c, "Integration not found")
+	case errors.Is(err, services.ErrInvalidIntegration):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetMyQueryMetrics godoc
+// @Summary Get query metrics for the current user
+// @Description Get aggregate NL2SQL query metrics served from the analytics cache
+// @Tags analytics
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD), defaults to today"
+// @Param data_source_id query int false "Filter by data source"
+// @Success 200 {object} models.StandardResponse{data=models.QueryMetricsResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /analytics/queries [get]
+func (h *AnalyticsHandler) GetMyQueryMetrics(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	filter, err := parseMetricsFilter(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid date range", err.Error())
+	}
+	filter.UserID = userID
+
+	metrics, err := h.analyticsService.WithContext(c.UserContext()).GetQueryMetrics(filter)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get query metrics", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Query metrics retrieved successfully", metrics)
+}
+
+// GetQueryMetrics godoc
+// @Summary Get query metrics for all users
+// @Description Get aggregate NL2SQL query metrics across users served from the analytics cache
+// @Tags analytics
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD), defaults to today"
+// @Param user_id query int false "Filter by user"
+// @Param data_source_id query int false "Filter by data source"
+// @Success 200 {object} models.StandardResponse{data=models.QueryMetricsResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/analytics/queries [get]
+func (h *AnalyticsHandler) GetQueryMetrics(c *fiber.Ctx) error {
+	filter, err := parseMetricsFilter(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid date range", err.Error())
+	}
+	filter.UserID = uint(c.QueryInt("user_id", 0))
+
+	metrics, err := h.analyticsService.WithContext(c.UserContext()).GetQueryMetrics(filter)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get query metrics", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Query metrics retrieved successfully", metrics)
+}
+
+// RefreshCache godoc
+// @Summary Refresh the analytics cache
+// @Description Recompute the analytics rollup for days changed since the last refresh
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.AnalyticsRefreshState}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/analytics/refresh [post]
+func (h *AnalyticsHandler) RefreshCache(c *fiber.Ctx) error {
+	state, err := h.analyticsService.WithContext(c.UserContext()).Refresh()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to refresh analytics cache", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Analytics cache refreshed", state)
+}
+
+// parseMetricsFilter reads the date range and data source filter from the query string
+func parseMetricsFilter(c *fiber.Ctx) (entity.QueryMetricsFilter, error) {
+	filter := entity.QueryMetricsFilter{
+		DataSourceID: uint(c.QueryInt("data_source_id", 0)),
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = parsed
+	}
+
+	return filter, nil
+}
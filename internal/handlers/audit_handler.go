@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// SearchLogs godoc
+// @Summary Search the audit trail
+// @Description Search the audit log of sensitive actions, newest first
+// @Tags admin
+// @Produce json
+// @Param actor_id query int false "User who performed the action"
+// @Param action query string false "Action, e.g. data_source.update or query.execute"
+// @Param resource_type query string false "Resource type, e.g. data_source, nl2sql_query or user"
+// @Param resource_id query string false "Resource ID"
+// @Param q query string false "Text to find in the SQL, snapshots or changes"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Param limit query int false "Page size (max 500)" default(50)
+// @Param offset query int false "Entries to skip" default(0)
+// @Success 200 {object} models.StandardResponse{data=models.AuditLogPage}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/audit-logs [get]
+func (h *AuditHandler) SearchLogs(c *fiber.Ctx) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid date range", err.Error())
+	}
+	filter.Limit = c.QueryInt("limit", 50)
+	filter.Offset = c.QueryInt("offset", 0)
+
+	page, err := h.auditService.Search(filter)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to search audit logs", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Audit logs retrieved successfully", page)
+}
+
+// ExportLogs godoc
+// @Summary Export the audit trail
+// @Description Download the audit log entries matching the filters, oldest first, as CSV or JSON. The export itself is recorded in the audit trail.
+// @Tags admin
+// @Produce text/csv
+// @Produce json
+// @Param format query string false "File format: csv or json" default(csv)
+// @Param actor_id query int false "User who performed the action"
+// @Param action query string false "Action, e.g. data_source.update or query.execute"
+// @Param resource_type query string false "Resource type"
+// @Param resource_id query string false "Resource ID"
+// @Param q query string false "Text to find in the SQL, snapshots or changes"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date inclusive (YYYY-MM-DD)"
+// @Success 200 {array} models.AuditLog
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/audit-logs/export [get]
+func (h *AuditHandler) ExportLogs(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if format != "json" && format != "csv" {
+		return entity.BadRequestResponse(c, "Invalid format", "format must be csv or json")
+	}
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid date range", err.Error())
+	}
+
+	logs, err := h.auditService.Export(filter)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to export audit logs", err.Error())
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "json" {
+		contentType = fiber.MIMEApplicationJSON
+		err = json.NewEncoder(&buf).Encode(logs)
+	} else {
+		err = services.WriteAuditLogCSV(&buf, logs)
+	}
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to export audit logs", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataExport, "audit_log", 0, nil, nil,
+		map[string]interface{}{"format": format, "entries": len(logs), "query": c.Context().QueryArgs().String()})
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="audit-log-%s.%s"`, time.Now().UTC().Format("2006-01-02"), format))
+	return c.Send(buf.Bytes())
+}
+
+// parseAuditFilter reads the audit log filters from the query string; the to date is inclusive
+func parseAuditFilter(c *fiber.Ctx) (entity.AuditLogFilter, error) {
+	filter := entity.AuditLogFilter{
+		ActorID:      uint(c.QueryInt("actor_id", 0)),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Search:       c.Query("q"),
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = parsed.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
@@ -6,23 +6,33 @@ import (
 	"narapulse-be/internal/repositories"
 	"narapulse-be/internal/services"
 	"narapulse-be/internal/pkg/utils"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
+const (
+	// mfaTokenTTL is how long a login can be completed with a second factor
+	mfaTokenTTL = 5 * time.Minute
+	// mfaEnrollmentTokenTTL is how long a user required to use MFA has to set it up
+	mfaEnrollmentTokenTTL = 15 * time.Minute
+)
+
 type AuthHandler struct {
 	userService services.UserService
+	mfaService  *services.MFAService
 	validator   *validator.Validate
 	config      *config.Config
 }
 
-func NewAuthHandler(db *gorm.DB) *AuthHandler {
+func NewAuthHandler(db *gorm.DB, mfaService *services.MFAService) *AuthHandler {
 	userRepo := repositories.NewUserRepository(db)
 	userService := services.NewUserService(userRepo)
 	return &AuthHandler{
 		userService: userService,
+		mfaService:  mfaService,
 		validator:   validator.New(),
 		config:      config.Load(),
 	}
@@ -42,16 +52,16 @@ func NewAuthHandler(db *gorm.DB) *AuthHandler {
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req entity.UserCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return entity.BadRequestResponse(c, "Invalid request body", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
-		return entity.BadRequestResponse(c, "Validation failed", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
 	}
 
-	// Create user
-	user, err := h.userService.CreateUser(&req)
+	// Create user, in the tenant of the domain when it is served on one
+	user, err := h.userService.WithContext(c.UserContext()).CreateUser(&req)
 	if err != nil {
 		return entity.BadRequestResponse(c, "Failed to create user", err.Error())
 	}
@@ -65,7 +75,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. With MFA the response carries an MFA token instead, to complete the login at /auth/mfa/verify or, when MFA is required but not set up, to enroll.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -78,28 +88,71 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req entity.LoginRequest
 	if err := c.BodyParser(&req); err != nil {
-		return entity.BadRequestResponse(c, "Invalid request body", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
-		return entity.BadRequestResponse(c, "Validation failed", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
 	}
 
 	// Authenticate user
-	user, err := h.userService.AuthenticateUser(req.Email, req.Password)
+	user, err := h.userService.WithContext(c.UserContext()).AuthenticateUser(req.Email, req.Password)
 	if err != nil {
 		return entity.UnauthorizedResponse(c, err.Error())
 	}
 
+	// Users with MFA enabled complete the login with a code at /auth/mfa/verify;
+	// users required to use MFA without it set up must enroll first
+	mfaEnabled, err := h.mfaService.WithContext(c.UserContext()).Enabled(user.ID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to check MFA", err.Error())
+	}
+	if mfaEnabled {
+		mfaToken, err := utils.GenerateScopedToken(user.ID, user.TenantID, user.Email, user.Role, utils.TokenPurposeMFA, h.config.JWTSecret, mfaTokenTTL)
+		if err != nil {
+			return entity.InternalServerErrorResponse(c, "Failed to generate token", err.Error())
+		}
+		return entity.SuccessResponse(c, "MFA code required", entity.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			User:        newUserResponse(user),
+		})
+	}
+
+	mfaRequired, err := h.mfaService.WithContext(c.UserContext()).Required(user)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to check MFA", err.Error())
+	}
+	if mfaRequired {
+		enrollmentToken, err := utils.GenerateScopedToken(user.ID, user.TenantID, user.Email, user.Role, utils.TokenPurposeMFAEnrollment, h.config.JWTSecret, mfaEnrollmentTokenTTL)
+		if err != nil {
+			return entity.InternalServerErrorResponse(c, "Failed to generate token", err.Error())
+		}
+		return entity.SuccessResponse(c, "MFA enrollment required", entity.LoginResponse{
+			EnrollmentRequired: true,
+			MFAToken:           enrollmentToken,
+			User:               newUserResponse(user),
+		})
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, h.config.JWTSecret)
+	token, err := utils.GenerateToken(user.ID, user.TenantID, user.Email, user.Role, h.config.JWTSecret)
 	if err != nil {
 		return entity.InternalServerErrorResponse(c, "Failed to generate token", err.Error())
 	}
 
-	// Convert user to response format
-	userResponse := &entity.UserResponse{
+	response := entity.LoginResponse{
+		Token: token,
+		User:  newUserResponse(user),
+	}
+
+	return entity.SuccessResponse(c, "Login successful", response)
+}
+
+// newUserResponse converts a user to the response format
+func newUserResponse(user *entity.User) entity.UserResponse {
+	return entity.UserResponse{
 		ID:        user.ID,
 		Email:     user.Email,
 		Username:  user.Username,
@@ -110,11 +163,4 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
-
-	response := entity.LoginResponse{
-		Token: token,
-		User:  *userResponse,
-	}
-
-	return entity.SuccessResponse(c, "Login successful", response)
 }
\ No newline at end of file
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BigQueryServiceAccountHandler struct {
+	serviceAccountService *services.BigQueryServiceAccountService
+	validator             *validator.Validate
+}
+
+func NewBigQueryServiceAccountHandler(serviceAccountService *services.BigQueryServiceAccountService) *BigQueryServiceAccountHandler {
+	return &BigQueryServiceAccountHandler{
+		serviceAccountService: serviceAccountService,
+		validator:             validator.New(),
+	}
+}
+
+// ValidateServiceAccount godoc
+// @Summary Validate a BigQuery service account key
+// @Description Check a service account key without storing it: that it parses, gets a token for the BigQuery scope, can run query jobs in the project and can read the dataset when one is given. Lists the datasets the account can see and explains IAM problems with the role to grant.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param key body models.BigQueryServiceAccountRequest true "Service account key"
+// @Success 200 {object} models.StandardResponse{data=models.BigQueryServiceAccountValidation}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/bigquery/service-accounts/validate [post]
+func (h *BigQueryServiceAccountHandler) ValidateServiceAccount(c *fiber.Ctx) error {
+	var req entity.BigQueryServiceAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	validation := h.serviceAccountService.WithContext(c.UserContext()).Validate(c.UserContext(), &req)
+	return entity.SuccessResponse(c, "Service account validated", validation)
+}
+
+// CreateServiceAccount godoc
+// @Summary Upload a BigQuery service account key
+// @Description Validate a service account key and store it encrypted. BigQuery and GA4 data sources use it by setting service_account_id in their config instead of credentials_json. A key that fails validation is not stored; the response lists its issues.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param key body models.BigQueryServiceAccountRequest true "Service account key"
+// @Success 201 {object} models.StandardResponse{data=models.BigQueryServiceAccountResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 422 {object} models.StandardResponse{error=models.ErrorResponse{details=models.BigQueryServiceAccountResponse}}
+// @Security ApiKeyAuth
+// @Router /data-sources/bigquery/service-accounts [post]
+func (h *BigQueryServiceAccountHandler) CreateServiceAccount(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.BigQueryServiceAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	account, err := h.serviceAccountService.WithContext(c.UserContext()).Create(c.UserContext(), userID, &req)
+	if errors.Is(err, services.ErrServiceAccountInvalid) {
+		return entity.ErrorResponseWithStatus(c, fiber.StatusUnprocessableEntity, "Service account key failed validation", account)
+	}
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to save service account", err.Error())
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Service account saved successfully", account)
+}
+
+// GetServiceAccounts godoc
+// @Summary List BigQuery service accounts
+// @Description List the service account keys uploaded by the current user; the keys themselves are never returned
+// @Tags data-sources
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.BigQueryServiceAccount}
+// @Security ApiKeyAuth
+// @Router /data-sources/bigquery/service-accounts [get]
+func (h *BigQueryServiceAccountHandler) GetServiceAccounts(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	accounts, err := h.serviceAccountService.WithContext(c.UserContext()).List(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get service accounts", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Service accounts retrieved successfully", accounts)
+}
+
+// DeleteServiceAccount godoc
+// @Summary Delete a BigQuery service account
+// @Description Delete a stored service account key. Data sources created with it keep working until their config is updated.
+// @Tags data-sources
+// @Produce json
+// @Param accountId path int true "Service Account ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/bigquery/service-accounts/{accountId} [delete]
+func (h *BigQueryServiceAccountHandler) DeleteServiceAccount(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("accountId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid service account ID", err.Error())
+	}
+
+	if err := h.serviceAccountService.WithContext(c.UserContext()).Delete(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrServiceAccountNotFound) {
+			return entity.NotFoundResponse(c, "Service account not found")
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to delete service account", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Service account deleted successfully", nil)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ColumnMetadataHandler struct {
+	columnMetadataService *services.ColumnMetadataService
+	dataSourceService     services.DataSourceService
+	auditService          *services.AuditService
+	validator             *validator.Validate
+}
+
+func NewColumnMetadataHandler(columnMetadataService *services.ColumnMetadataService, dataSourceService services.DataSourceService, auditService *services.AuditService) *ColumnMetadataHandler {
+	return &ColumnMetadataHandler{
+		columnMetadataService: columnMetadataService,
+		dataSourceService:     dataSourceService,
+		auditService:          auditService,
+		validator:             validator.New(),
+	}
+}
+
+// GetColumnMetadata godoc
+// @Summary List curated column metadata
+// @Description List the display names, descriptions, semantic tags and PII flags curated for the columns of a data source
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.ColumnMetadataResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/column-metadata [get]
+func (h *ColumnMetadataHandler) GetColumnMetadata(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	metadata, err := h.columnMetadataService.WithContext(c.UserContext()).List(uint(id))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve column metadata", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Column metadata retrieved successfully", metadata)
+}
+
+// UpdateColumnMetadata godoc
+// @Summary Curate a column
+// @Description Replace the display name, description, semantic tags and PII flag of a column. The curated description is preferred over the discovered one in NL2SQL prompts, and the column is re-embedded in the background.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param table path string true "Table name"
+// @Param column path string true "Column name"
+// @Param metadata body models.ColumnMetadataRequest true "Column metadata"
+// @Success 200 {object} models.StandardResponse{data=models.ColumnMetadataResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/schemas/{table}/columns/{column} [put]
+func (h *ColumnMetadataHandler) UpdateColumnMetadata(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+	table, err := url.PathUnescape(c.Params("table"))
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid table name", err.Error())
+	}
+	column, err := url.PathUnescape(c.Params("column"))
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid column name", err.Error())
+	}
+
+	var req entity.ColumnMetadataRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	metadata, err := h.columnMetadataService.WithContext(c.UserContext()).UpdateColumn(c.UserContext(), uint(id), table, column, userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrColumnNotFound) {
+			return entity.NotFoundResponse(c, "Column not found")
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to update column metadata", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionColumnMetadata, "data_source", uint(id), nil, metadata, nil)
+
+	return entity.SuccessResponse(c, "Column metadata updated successfully", metadata)
+}
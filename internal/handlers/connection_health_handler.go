@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConnectionHealthHandler struct {
+	connectionHealthService *services.ConnectionHealthService
+}
+
+func NewConnectionHealthHandler(connectionHealthService *services.ConnectionHealthService) *ConnectionHealthHandler {
+	return &ConnectionHealthHandler{
+		connectionHealthService: connectionHealthService,
+	}
+}
+
+// GetHealth godoc
+// @Summary Get data source connection health
+// @Description Get the status, uptime over 24h, 7d and 30d and recent scheduled connection checks of a data source
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceHealthResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/health [get]
+func (h *ConnectionHealthHandler) GetHealth(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	health, err := h.connectionHealthService.WithContext(c.UserContext()).GetHealth(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrHealthDataSourceNotFound) {
+			return entity.NotFoundResponse(c, "Data source not found")
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to get data source health", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Data source health retrieved successfully", health)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CustomSQLFunctionHandler struct {
+	functionService *services.CustomSQLFunctionService
+	validator       *validator.Validate
+}
+
+func NewCustomSQLFunctionHandler(functionService *services.CustomSQLFunctionService) *CustomSQLFunctionHandler {
+	return &CustomSQLFunctionHandler{
+		functionService: functionService,
+		validator:       validator.New(),
+	}
+}
+
+// GetFunctions godoc
+// @Summary List custom SQL functions
+// @Description List the custom functions allowed in queries on a data source
+// @Tags admin
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.CustomSQLFunction}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/sql-functions [get]
+func (h *CustomSQLFunctionHandler) GetFunctions(c *fiber.Ctx) error {
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	functions, err := h.functionService.WithContext(c.UserContext()).List(uint(dataSourceID))
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to get custom SQL functions", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Custom SQL functions retrieved successfully", functions)
+}
+
+// CreateFunction godoc
+// @Summary Register a custom SQL function
+// @Description Allow a warehouse UDF or macro in queries on a data source; its signature and docs are added to the NL2SQL prompt
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param function body models.CustomSQLFunctionRequest true "Custom SQL function"
+// @Success 201 {object} models.StandardResponse{data=models.CustomSQLFunction}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/sql-functions [post]
+func (h *CustomSQLFunctionHandler) CreateFunction(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.CustomSQLFunctionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	function, err := h.functionService.WithContext(c.UserContext()).Create(adminID, uint(dataSourceID), &req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to create custom SQL function", err.Error())
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Custom SQL function created successfully", function)
+}
+
+// UpdateFunction godoc
+// @Summary Update a custom SQL function
+// @Description Replace the name, signature and docs of a custom SQL function
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param functionId path int true "Function ID"
+// @Param function body models.CustomSQLFunctionRequest true "Custom SQL function"
+// @Success 200 {object} models.StandardResponse{data=models.CustomSQLFunction}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/sql-functions/{functionId} [put]
+func (h *CustomSQLFunctionHandler) UpdateFunction(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, functionID, err := parseFunctionParams(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	var req entity.CustomSQLFunctionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	function, err := h.functionService.WithContext(c.UserContext()).Update(adminID, dataSourceID, functionID, &req)
+	if err != nil {
+		return functionErrorResponse(c, "Failed to update custom SQL function", err)
+	}
+
+	return entity.SuccessResponse(c, "Custom SQL function updated successfully", function)
+}
+
+// DeleteFunction godoc
+// @Summary Delete a custom SQL function
+// @Description Unregister a custom SQL function; queries using it no longer pass validation
+// @Tags admin
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param functionId path int true "Function ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/sql-functions/{functionId} [delete]
+func (h *CustomSQLFunctionHandler) DeleteFunction(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, functionID, err := parseFunctionParams(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	if err := h.functionService.WithContext(c.UserContext()).Delete(adminID, dataSourceID, functionID); err != nil {
+		return functionErrorResponse(c, "Failed to delete custom SQL function", err)
+	}
+
+	return entity.SuccessResponse(c, "Custom SQL function deleted successfully", nil)
+}
+
+func parseFunctionParams(c *fiber.Ctx) (uint, uint, error) {
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	functionID, err := strconv.ParseUint(c.Params("functionId"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(dataSourceID), uint(functionID), nil
+}
+
+func functionErrorResponse(c *fiber.Ctx, message string, err error) error {
+	if errors.Is(err, services.ErrCustomSQLFunctionNotFound) {
+		return entity.NotFoundResponse(c, err.Error())
+	}
+	return entity.BadRequestResponse(c, message, err.Error())
+}
@@ -0,0 +1,523 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// dashboardLivePingInterval keeps live connections open through proxies and detects closed clients
+const dashboardLivePingInterval = 15 * time.Second
+
+type DashboardHandler struct {
+	dashboardService *services.DashboardService
+	validator        *validator.Validate
+}
+
+func NewDashboardHandler(dashboardService *services.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+		validator:        validator.New(),
+	}
+}
+
+// CreateDashboard godoc
+// @Summary Create a dashboard
+// @Description Create an empty dashboard owned by the current user
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param dashboard body models.DashboardRequest true "Dashboard"
+// @Success 201 {object} models.StandardResponse{data=models.Dashboard}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards [post]
+func (h *DashboardHandler) CreateDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.DashboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	dashboard, err := h.dashboardService.WithContext(c.UserContext()).CreateDashboard(userID, &req)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to create dashboard", err.Error())
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Dashboard created successfully", dashboard)
+}
+
+// GetDashboards godoc
+// @Summary List dashboards
+// @Description List the dashboards the current user owns or collaborates on
+// @Tags dashboards
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.Dashboard}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards [get]
+func (h *DashboardHandler) GetDashboards(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	dashboards, err := h.dashboardService.WithContext(c.UserContext()).ListDashboards(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get dashboards", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Dashboards retrieved successfully", dashboards)
+}
+
+// GetDashboard godoc
+// @Summary Get a dashboard
+// @Description Get a dashboard with its widgets
+// @Tags dashboards
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Success 200 {object} models.StandardResponse{data=models.Dashboard}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id} [get]
+func (h *DashboardHandler) GetDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	dashboard, err := h.dashboardService.WithContext(c.UserContext()).GetDashboard(userID, uint(id))
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to get dashboard", err)
+	}
+
+	return entity.SuccessResponse(c, "Dashboard retrieved successfully", dashboard)
+}
+
+// UpdateDashboard godoc
+// @Summary Rename a dashboard
+// @Description Change the name and description of a dashboard
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param dashboard body models.DashboardRequest true "Dashboard"
+// @Success 200 {object} models.StandardResponse{data=models.Dashboard}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id} [put]
+func (h *DashboardHandler) UpdateDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	var req entity.DashboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	dashboard, err := h.dashboardService.WithContext(c.UserContext()).UpdateDashboard(userID, uint(id), &req)
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to update dashboard", err)
+	}
+
+	return entity.SuccessResponse(c, "Dashboard updated successfully", dashboard)
+}
+
+// DeleteDashboard godoc
+// @Summary Delete a dashboard
+// @Description Delete a dashboard owned by the current user
+// @Tags dashboards
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id} [delete]
+func (h *DashboardHandler) DeleteDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	if err := h.dashboardService.WithContext(c.UserContext()).DeleteDashboard(userID, uint(id)); err != nil {
+		return dashboardErrorResponse(c, "Failed to delete dashboard", err)
+	}
+
+	return entity.SuccessResponse(c, "Dashboard deleted successfully", nil)
+}
+
+// AddWidget godoc
+// @Summary Add a dashboard widget
+// @Description Place a widget on the dashboard and broadcast it to connected analysts
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param widget body models.DashboardWidgetCreateRequest true "Widget"
+// @Success 201 {object} models.StandardResponse{data=models.DashboardWidget}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/widgets [post]
+func (h *DashboardHandler) AddWidget(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	var req entity.DashboardWidgetCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	widget, err := h.dashboardService.WithContext(c.UserContext()).AddWidget(userID, uint(id), &req)
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to add widget", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Widget added successfully", widget)
+}
+
+// UpdateWidget godoc
+// @Summary Edit a dashboard widget
+// @Description Change the fields that are set, based on base_version. Returns 409 with the latest widget when another analyst changed it first.
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param widgetId path int true "Widget ID"
+// @Param widget body models.DashboardWidgetPatchRequest true "Changed fields"
+// @Success 200 {object} models.StandardResponse{data=models.DashboardWidget}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse{data=models.DashboardWidget}
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/widgets/{widgetId} [patch]
+func (h *DashboardHandler) UpdateWidget(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+	widgetID, err := strconv.ParseUint(c.Params("widgetId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid widget ID", err.Error())
+	}
+
+	var req entity.DashboardWidgetPatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	widget, err := h.dashboardService.WithContext(c.UserContext()).UpdateWidget(userID, uint(id), uint(widgetID), &req)
+	if errors.Is(err, services.ErrWidgetVersionConflict) {
+		// The client rebases its edit on the latest widget and retries
+		return c.Status(fiber.StatusConflict).JSON(entity.StandardResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    widget,
+			Error:   &entity.ErrorResponse{Code: entity.ErrorCodeConflict, Message: err.Error()},
+		})
+	}
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to update widget", err)
+	}
+
+	return entity.SuccessResponse(c, "Widget updated successfully", widget)
+}
+
+// DeleteWidget godoc
+// @Summary Remove a dashboard widget
+// @Description Remove a widget and broadcast the removal to connected analysts
+// @Tags dashboards
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param widgetId path int true "Widget ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/widgets/{widgetId} [delete]
+func (h *DashboardHandler) DeleteWidget(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+	widgetID, err := strconv.ParseUint(c.Params("widgetId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid widget ID", err.Error())
+	}
+
+	if err := h.dashboardService.WithContext(c.UserContext()).DeleteWidget(userID, uint(id), uint(widgetID)); err != nil {
+		return dashboardErrorResponse(c, "Failed to remove widget", err)
+	}
+
+	return entity.SuccessResponse(c, "Widget removed successfully", nil)
+}
+
+// GetCollaborators godoc
+// @Summary List dashboard collaborators
+// @Description List the users a dashboard is shared with
+// @Tags dashboards
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.DashboardCollaborator}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/collaborators [get]
+func (h *DashboardHandler) GetCollaborators(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	collaborators, err := h.dashboardService.WithContext(c.UserContext()).ListCollaborators(userID, uint(id))
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to get collaborators", err)
+	}
+
+	return entity.SuccessResponse(c, "Collaborators retrieved successfully", collaborators)
+}
+
+// SetCollaborator godoc
+// @Summary Share a dashboard
+// @Description Share a dashboard owned by the current user, or change a collaborator's role
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param collaborator body models.DashboardCollaboratorRequest true "Collaborator"
+// @Success 200 {object} models.StandardResponse{data=models.DashboardCollaborator}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/collaborators [put]
+func (h *DashboardHandler) SetCollaborator(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	var req entity.DashboardCollaboratorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	collaborator, err := h.dashboardService.WithContext(c.UserContext()).SetCollaborator(userID, uint(id), &req)
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to share dashboard", err)
+	}
+
+	return entity.SuccessResponse(c, "Dashboard shared successfully", collaborator)
+}
+
+// RemoveCollaborator godoc
+// @Summary Stop sharing a dashboard
+// @Description Remove a collaborator from a dashboard owned by the current user
+// @Tags dashboards
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param userId path int true "Collaborator user ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/collaborators/{userId} [delete]
+func (h *DashboardHandler) RemoveCollaborator(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+	collaboratorID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	if err := h.dashboardService.WithContext(c.UserContext()).RemoveCollaborator(userID, uint(id), uint(collaboratorID)); err != nil {
+		return dashboardErrorResponse(c, "Failed to remove collaborator", err)
+	}
+
+	return entity.SuccessResponse(c, "Collaborator removed successfully", nil)
+}
+
+// GetPresence godoc
+// @Summary List analysts on a dashboard
+// @Description List the analysts with the dashboard open and the widget each is editing
+// @Tags dashboards
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.DashboardPresence}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/presence [get]
+func (h *DashboardHandler) GetPresence(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	presence, err := h.dashboardService.WithContext(c.UserContext()).Presence(userID, uint(id))
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to get presence", err)
+	}
+
+	return entity.SuccessResponse(c, "Presence retrieved successfully", presence)
+}
+
+// UpdatePresence godoc
+// @Summary Share the widget being edited
+// @Description Heartbeat of an analyst with the live stream open; selected_widget is shown to the other analysts
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param presence body models.DashboardPresenceRequest true "Presence"
+// @Success 200 {object} models.StandardResponse{data=[]models.DashboardPresence}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/presence [post]
+func (h *DashboardHandler) UpdatePresence(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	var req entity.DashboardPresenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	presence, err := h.dashboardService.WithContext(c.UserContext()).Heartbeat(userID, uint(id), &req)
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to update presence", err)
+	}
+
+	return entity.SuccessResponse(c, "Presence updated successfully", presence)
+}
+
+// Live godoc
+// @Summary Stream dashboard changes
+// @Description Server-Sent Events stream of widget changes and presence for the dashboard room
+// @Tags dashboards
+// @Produce text/event-stream
+// @Param id path int true "Dashboard ID"
+// @Success 200 {object} models.DashboardEvent
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /dashboards/{id}/live [get]
+func (h *DashboardHandler) Live(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+	dashboardID := uint(id)
+
+	dashboards := h.dashboardService.WithContext(c.UserContext())
+	events, leave, err := dashboards.Join(userID, dashboardID)
+	if err != nil {
+		return dashboardErrorResponse(c, "Failed to join dashboard", err)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer leave()
+
+		ticker := time.NewTicker(dashboardLivePingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					// Dropped for falling behind; the client reconnects and reloads
+					return
+				}
+				data, _ := json.Marshal(event)
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-ticker.C:
+				dashboards.KeepAlive(userID, dashboardID)
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func dashboardErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrDashboardNotFound), errors.Is(err, services.ErrWidgetNotFound):
+		return entity.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrDashboardReadOnly), errors.Is(err, services.ErrDashboardOwnerOnly):
+		return entity.ForbiddenResponse(c, err.Error())
+	default:
+		return entity.BadRequestResponse(c, message, err.Error())
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataAPIHandler struct {
+	dataAPIService *services.DataAPIService
+	validator      *validator.Validate
+}
+
+func NewDataAPIHandler(dataAPIService *services.DataAPIService) *DataAPIHandler {
+	return &DataAPIHandler{
+		dataAPIService: dataAPIService,
+		validator:      validator.New(),
+	}
+}
+
+// CreateEndpoint godoc
+// @Summary Publish a data API endpoint
+// @Description Publish a saved query or SQL template with {{param}} placeholders as GET /apis/{slug}
+// @Tags data-apis
+// @Accept json
+// @Produce json
+// @Param endpoint body models.DataAPIEndpointCreateRequest true "Endpoint definition"
+// @Success 201 {object} models.StandardResponse{data=models.DataAPIEndpointResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis [post]
+func (h *DataAPIHandler) CreateEndpoint(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.DataAPIEndpointCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	endpoint, err := h.dataAPIService.WithContext(c.UserContext()).CreateEndpoint(userID, &req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to create data API endpoint", err.Error())
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Data API endpoint created successfully", endpoint)
+}
+
+// GetEndpoints godoc
+// @Summary List data API endpoints
+// @Description List the data API endpoints published by the current user
+// @Tags data-apis
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.DataAPIEndpointResponse}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis [get]
+func (h *DataAPIHandler) GetEndpoints(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	endpoints, err := h.dataAPIService.WithContext(c.UserContext()).ListEndpoints(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get data API endpoints", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Data API endpoints retrieved successfully", endpoints)
+}
+
+// GetEndpoint godoc
+// @Summary Get a data API endpoint
+// @Description Get a data API endpoint by ID
+// @Tags data-apis
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} models.StandardResponse{data=models.DataAPIEndpointResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis/{id} [get]
+func (h *DataAPIHandler) GetEndpoint(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid endpoint ID", err.Error())
+	}
+
+	endpoint, err := h.dataAPIService.WithContext(c.UserContext()).GetEndpoint(userID, uint(id))
+	if err != nil {
+		return h.managementError(c, "Failed to get data API endpoint", err)
+	}
+
+	return entity.SuccessResponse(c, "Data API endpoint retrieved successfully", endpoint)
+}
+
+// UpdateEndpoint godoc
+// @Summary Update a data API endpoint
+// @Description Update a data API endpoint; template changes are re-validated
+// @Tags data-apis
+// @Accept json
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param endpoint body models.DataAPIEndpointUpdateRequest true "Endpoint update"
+// @Success 200 {object} models.StandardResponse{data=models.DataAPIEndpointResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis/{id} [put]
+func (h *DataAPIHandler) UpdateEndpoint(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid endpoint ID", err.Error())
+	}
+
+	var req entity.DataAPIEndpointUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	endpoint, err := h.dataAPIService.WithContext(c.UserContext()).UpdateEndpoint(userID, uint(id), &req)
+	if err != nil {
+		return h.managementError(c, "Failed to update data API endpoint", err)
+	}
+
+	return entity.SuccessResponse(c, "Data API endpoint updated successfully", endpoint)
+}
+
+// DeleteEndpoint godoc
+// @Summary Delete a data API endpoint
+// @Description Unpublish a data API endpoint and revoke its keys
+// @Tags data-apis
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis/{id} [delete]
+func (h *DataAPIHandler) DeleteEndpoint(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid endpoint ID", err.Error())
+	}
+
+	if err := h.dataAPIService.WithContext(c.UserContext()).DeleteEndpoint(userID, uint(id)); err != nil {
+		return h.managementError(c, "Failed to delete data API endpoint", err)
+	}
+
+	return entity.SuccessResponse(c, "Data API endpoint deleted successfully", nil)
+}
+
+// CreateKey godoc
+// @Summary Create a data API key
+// @Description Create an API key for an endpoint. The key is only returned once.
+// @Tags data-apis
+// @Accept json
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param key body models.DataAPIKeyCreateRequest true "Key name"
+// @Success 201 {object} models.StandardResponse{data=models.DataAPIKeyCreateResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis/{id}/keys [post]
+func (h *DataAPIHandler) CreateKey(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid endpoint ID", err.Error())
+	}
+
+	var req entity.DataAPIKeyCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	key, err := h.dataAPIService.WithContext(c.UserContext()).CreateKey(userID, uint(id), &req)
+	if err != nil {
+		return h.managementError(c, "Failed to create API key", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "API key created successfully", key)
+}
+
+// GetKeys godoc
+// @Summary List data API keys
+// @Description List the API keys of an endpoint
+// @Tags data-apis
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.DataAPIKey}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis/{id}/keys [get]
+func (h *DataAPIHandler) GetKeys(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid endpoint ID", err.Error())
+	}
+
+	keys, err := h.dataAPIService.WithContext(c.UserContext()).ListKeys(userID, uint(id))
+	if err != nil {
+		return h.managementError(c, "Failed to get API keys", err)
+	}
+
+	return entity.SuccessResponse(c, "API keys retrieved successfully", keys)
+}
+
+// RevokeKey godoc
+// @Summary Revoke a data API key
+// @Description Revoke an API key of an endpoint
+// @Tags data-apis
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param keyId path int true "Key ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-apis/{id}/keys/{keyId} [delete]
+func (h *DataAPIHandler) RevokeKey(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid endpoint ID", err.Error())
+	}
+	keyID, err := strconv.ParseUint(c.Params("keyId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid key ID", err.Error())
+	}
+
+	if err := h.dataAPIService.WithContext(c.UserContext()).RevokeKey(userID, uint(id), uint(keyID)); err != nil {
+		return h.managementError(c, "Failed to revoke API key", err)
+	}
+
+	return entity.SuccessResponse(c, "API key revoked successfully", nil)
+}
+
+// Invoke godoc
+// @Summary Call a data API endpoint
+// @Description Run a published query with the given query string parameters. Authenticated with the X-API-Key header.
+// @Tags data-apis
+// @Produce json
+// @Param slug path string true "Endpoint slug"
+// @Param X-API-Key header string true "Data API key"
+// @Success 200 {object} models.StandardResponse{data=models.DataAPIResult}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 429 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Router /apis/{slug} [get]
+func (h *DataAPIHandler) Invoke(c *fiber.Ctx) error {
+	values := make(map[string]string)
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		values[string(key)] = string(value)
+	})
+
+	result, rate, err := h.dataAPIService.WithContext(c.UserContext()).Invoke(c.Params("slug"), c.Get("X-API-Key"), values)
+	if rate != nil {
+		c.Set("X-RateLimit-Limit", strconv.Itoa(rate.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(rate.Remaining))
+		c.Set("X-RateLimit-Reset", strconv.FormatInt(rate.ResetAt.Unix(), 10))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDataAPINotFound):
+			return entity.NotFoundResponse(c, "Data API endpoint not found")
+		case errors.Is(err, services.ErrInvalidAPIKey):
+			return entity.UnauthorizedResponse(c, "Invalid or missing API key")
+		case errors.Is(err, services.ErrDataAPIRateLimited):
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(time.Until(rate.ResetAt).Seconds())+1))
+			return entity.ErrorResponseWithStatus(c, fiber.StatusTooManyRequests, "Rate limit exceeded", nil)
+		case errors.Is(err, services.ErrInvalidDataAPIParams):
+			return entity.BadRequestResponse(c, "Invalid parameters", err.Error())
+		case errors.Is(err, services.ErrQueryCostExceeded):
+			return entity.BadRequestResponse(c, "Query exceeds the cost ceiling", err.Error())
+		default:
+			return entity.InternalServerErrorResponse(c, "Failed to run data API endpoint", err.Error())
+		}
+	}
+
+	if result.Cached {
+		c.Set("X-Cache", "HIT")
+	} else {
+		c.Set("X-Cache", "MISS")
+	}
+
+	return entity.SuccessResponse(c, "Data retrieved successfully", result)
+}
+
+// managementError maps service errors of the management endpoints to responses
+func (h *DataAPIHandler) managementError(c *fiber.Ctx, message string, err error) error {
+	if errors.Is(err, services.ErrDataAPINotFound) {
+		return entity.NotFoundResponse(c, "Data API endpoint not found")
+	}
+	return entity.BadRequestResponse(c, message, err.Error())
+}
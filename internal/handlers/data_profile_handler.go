@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataProfileHandler struct {
+	dataProfileService *services.DataProfileService
+}
+
+func NewDataProfileHandler(dataProfileService *services.DataProfileService) *DataProfileHandler {
+	return &DataProfileHandler{
+		dataProfileService: dataProfileService,
+	}
+}
+
+// GetProfile godoc
+// @Summary Profile a table
+// @Description Sample a table of the data source and return per-column completeness, uniqueness, min/max, top values and numeric histograms. Files and REST APIs are profiled over their stored sample rows. Profiles are cached for an hour unless refresh is set.
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param schema_id path int true "Schema ID"
+// @Param refresh query bool false "Profile again instead of serving the cached profile"
+// @Success 200 {object} models.StandardResponse{data=models.SchemaProfileResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/schemas/{schema_id}/profile [get]
+func (h *DataProfileHandler) GetProfile(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+	schemaID, err := strconv.ParseUint(c.Params("schema_id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid schema ID", err.Error())
+	}
+
+	profile, err := h.dataProfileService.WithContext(c.UserContext()).GetProfile(userID, uint(id), uint(schemaID), c.QueryBool("refresh"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProfileDataSourceNotFound):
+			return entity.NotFoundResponse(c, "Data source not found")
+		case errors.Is(err, services.ErrSchemaNotFound):
+			return entity.NotFoundResponse(c, "Schema not found")
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to profile table", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Table profile retrieved successfully", profile)
+}
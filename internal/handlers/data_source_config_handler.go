@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataSourceConfigHandler struct {
+	dataSourceService services.DataSourceService
+	auditService      *services.AuditService
+	validator         *validator.Validate
+}
+
+func NewDataSourceConfigHandler(dataSourceService services.DataSourceService, auditService *services.AuditService) *DataSourceConfigHandler {
+	return &DataSourceConfigHandler{
+		dataSourceService: dataSourceService,
+		auditService:      auditService,
+		validator:         validator.New(),
+	}
+}
+
+// ApplyDataSourceConfig godoc
+// @Summary Apply a declarative data source config
+// @Description Converge the data sources of the user to a declared list, matched by name: missing ones are created, differing ones updated (or replaced when the type changed) and undeclared ones deleted, restorable within the restore window. Credentials can be secret references, env:NAME, to environment variables starting with DATA_SOURCE_SECRET_ENV_PREFIX. Every declaration is validated before anything changes. With dry_run=true, only the plan is returned.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Return the plan without applying it"
+// @Param config body models.DataSourceConfigRequest true "Every data source of the user"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceConfigPlan}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse{error=models.ErrorResponse{details=models.DataSourceConfigPlan}}
+// @Security ApiKeyAuth
+// @Router /config/data-sources [put]
+func (h *DataSourceConfigHandler) ApplyDataSourceConfig(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.DataSourceConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	dryRun := c.QueryBool("dry_run", false)
+	plan, err := h.dataSourceService.WithContext(c.UserContext()).ApplyDataSourceConfig(userID, &req, dryRun)
+	if err != nil {
+		var applyErr *services.DataSourceConfigApplyError
+		if errors.As(err, &applyErr) {
+			h.auditChanges(c, applyErr.Plan)
+			return entity.InternalServerErrorResponse(c, err.Error(), applyErr.Plan)
+		}
+		if errors.Is(err, services.ErrDataSourceConfigInvalid) {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to apply data source config", err.Error())
+	}
+
+	if dryRun {
+		return entity.SuccessResponse(c, "Data source config planned successfully", plan)
+	}
+	h.auditChanges(c, plan)
+	return entity.SuccessResponse(c, "Data source config applied successfully", plan)
+}
+
+// auditChanges records the applied changes of a plan like the same changes
+// made one by one
+func (h *DataSourceConfigHandler) auditChanges(c *fiber.Ctx, plan *entity.DataSourceConfigPlan) {
+	actor := middleware.GetAuditActor(c)
+	details := map[string]interface{}{"declarative": true}
+	for _, change := range plan.Changes {
+		// A replace that failed to create may have deleted already
+		if change.ReplacedID != 0 {
+			h.auditService.Log(actor, entity.AuditActionDataSourceDelete, "data_source", change.ReplacedID, nil, nil, details)
+		}
+		if !change.Applied {
+			continue
+		}
+		switch change.Action {
+		case entity.DataSourceConfigActionCreate, entity.DataSourceConfigActionReplace:
+			h.auditService.Log(actor, entity.AuditActionDataSourceCreate, "data_source", change.DataSourceID, nil, change, details)
+		case entity.DataSourceConfigActionUpdate:
+			h.auditService.Log(actor, entity.AuditActionDataSourceUpdate, "data_source", change.DataSourceID, nil, change, details)
+		case entity.DataSourceConfigActionDelete:
+			h.auditService.Log(actor, entity.AuditActionDataSourceDelete, "data_source", change.DataSourceID, change, nil, details)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 
+	"narapulse-be/internal/middleware"
 	entity "narapulse-be/internal/models/entity"
 	services "narapulse-be/internal/services"
 
@@ -12,12 +15,16 @@ import (
 
 type DataSourceHandler struct {
 	dataSourceService services.DataSourceService
+	dryImportService  *services.DryImportService
+	auditService      *services.AuditService
 	validator         *validator.Validate
 }
 
-func NewDataSourceHandler(dataSourceService services.DataSourceService) *DataSourceHandler {
+func NewDataSourceHandler(dataSourceService services.DataSourceService, dryImportService *services.DryImportService, auditService *services.AuditService) *DataSourceHandler {
 	return &DataSourceHandler{
 		dataSourceService: dataSourceService,
+		dryImportService:  dryImportService,
+		auditService:      auditService,
 		validator:         validator.New(),
 	}
 }
@@ -41,20 +48,22 @@ func (h *DataSourceHandler) CreateDataSource(c *fiber.Ctx) error {
 
 	var req entity.DataSourceCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return entity.BadRequestResponse(c, "Invalid request body", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
-		return entity.BadRequestResponse(c, "Validation failed", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
 	}
 
 	// Create data source
-	dataSource, err := h.dataSourceService.CreateDataSource(userID, &req)
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).CreateDataSource(userID, &req)
 	if err != nil {
 		return entity.BadRequestResponse(c, "Failed to create data source", err.Error())
 	}
 
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataSourceCreate, "data_source", dataSource.ID, nil, dataSource, nil)
+
 	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
 		Success: true,
 		Message: "Data source created successfully",
@@ -62,6 +71,68 @@ func (h *DataSourceHandler) CreateDataSource(c *fiber.Ctx) error {
 	})
 }
 
+// DuplicateDataSource godoc
+// @Summary Duplicate a data source
+// @Description Create a data source with the type and configuration of an existing one. Credentials are not copied: provide them in config (which may also override other fields); missing ones are listed in the validation error.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param data_source body models.DataSourceDuplicateRequest true "Name, and credentials and overrides of the copy"
+// @Success 201 {object} models.StandardResponse{data=models.DataSourceResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/duplicate [post]
+func (h *DataSourceHandler) DuplicateDataSource(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.DataSourceDuplicateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).DuplicateDataSource(uint(id), userID, &req)
+	if err != nil {
+		return configValuesErrorResponse(c, "Failed to duplicate data source", err)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataSourceCreate, "data_source", dataSource.ID, nil, dataSource, map[string]interface{}{"duplicated_from": id})
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Data source duplicated successfully",
+		Data:    dataSource,
+	})
+}
+
+// configValuesErrorResponse reports the credentials or placeholder values a
+// request is missing as a validation error, and other errors as bad requests
+func configValuesErrorResponse(c *fiber.Ctx, message string, err error) error {
+	var missingErr *services.MissingConfigValuesError
+	if errors.As(err, &missingErr) {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", fiber.Map{
+			"message":        err.Error(),
+			"missing_fields": missingErr.Fields,
+		})
+	}
+	return entity.BadRequestResponse(c, message, err.Error())
+}
+
 // GetDataSources godoc
 // @Summary Get user's data sources
 // @Description Get all data sources for the authenticated user
@@ -77,7 +148,7 @@ func (h *DataSourceHandler) GetDataSources(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id").(uint)
 
-	dataSources, err := h.dataSourceService.GetUserDataSources(userID)
+	dataSources, err := h.dataSourceService.WithContext(c.UserContext()).GetUserDataSources(userID)
 	if err != nil {
 		return entity.InternalServerErrorResponse(c, "Failed to get data sources", err.Error())
 	}
@@ -106,10 +177,10 @@ func (h *DataSourceHandler) GetDataSource(c *fiber.Ctx) error {
 	// Parse data source ID
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		return entity.BadRequestResponse(c, "Invalid data source ID", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
 	}
 
-	dataSource, err := h.dataSourceService.GetDataSource(uint(id), userID)
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID)
 	if err != nil {
 		return entity.NotFoundResponse(c, "Data source not found")
 	}
@@ -139,35 +210,44 @@ func (h *DataSourceHandler) UpdateDataSource(c *fiber.Ctx) error {
 	// Parse data source ID
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		return entity.BadRequestResponse(c, "Invalid data source ID", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
 	}
 
 	var req entity.DataSourceUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return entity.BadRequestResponse(c, "Invalid request body", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
-		return entity.BadRequestResponse(c, "Validation failed", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	// Snapshot the data source for the audit trail before changing it
+	before, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID)
+	if err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
 	}
 
 	// Update data source
-	dataSource, err := h.dataSourceService.UpdateDataSource(uint(id), userID, &req)
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).UpdateDataSource(uint(id), userID, &req)
 	if err != nil {
 		return entity.BadRequestResponse(c, "Failed to update data source", err.Error())
 	}
 
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataSourceUpdate, "data_source", dataSource.ID, before, dataSource, nil)
+
 	return entity.SuccessResponse(c, "Data source updated successfully", dataSource)
 }
 
 // DeleteDataSource godoc
 // @Summary Delete a data source
-// @Description Delete a data source and its associated schemas
+// @Description Delete a data source with its schemas and embeddings, and optionally its query history and results. It can be restored within the restore window.
 // @Tags data-sources
 // @Accept json
 // @Produce json
 // @Param id path int true "Data Source ID"
+// @Param delete_queries query bool false "Also delete the queries and results of the data source"
 // @Success 200 {object} models.StandardResponse
 // @Failure 400 {object} models.StandardResponse
 // @Failure 401 {object} models.StandardResponse
@@ -182,17 +262,65 @@ func (h *DataSourceHandler) DeleteDataSource(c *fiber.Ctx) error {
 	// Parse data source ID
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		return entity.BadRequestResponse(c, "Invalid data source ID", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	// Snapshot the data source for the audit trail before deleting it
+	before, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID)
+	if err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
 	}
 
 	// Delete data source
-	if err := h.dataSourceService.DeleteDataSource(uint(id), userID); err != nil {
+	deleteQueries := c.QueryBool("delete_queries")
+	if err := h.dataSourceService.WithContext(c.UserContext()).DeleteDataSource(uint(id), userID, deleteQueries); err != nil {
 		return entity.BadRequestResponse(c, "Failed to delete data source", err.Error())
 	}
 
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataSourceDelete, "data_source", uint(id), before, nil,
+		map[string]interface{}{"delete_queries": deleteQueries})
+
 	return entity.SuccessResponse(c, "Data source deleted successfully", nil)
 }
 
+// RestoreDataSource godoc
+// @Summary Restore a deleted data source
+// @Description Restore a data source deleted within the restore window, with the schemas, embeddings, queries and results deleted with it
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/restore [post]
+func (h *DataSourceHandler) RestoreDataSource(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).RestoreDataSource(uint(id), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDeletedDataSourceNotFound):
+			return entity.NotFoundResponse(c, "Deleted data source not found")
+		case errors.Is(err, services.ErrDataSourceRestoreExpired):
+			return entity.BadRequestResponse(c, "Data source can no longer be restored", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to restore data source", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataSourceRestore, "data_source", dataSource.ID, nil, dataSource, nil)
+
+	return entity.SuccessResponse(c, "Data source restored successfully", dataSource)
+}
+
 // TestConnection godoc
 // @Summary Test data source connection
 // @Description Test connection to a data source without creating it
@@ -209,16 +337,17 @@ func (h *DataSourceHandler) DeleteDataSource(c *fiber.Ctx) error {
 func (h *DataSourceHandler) TestConnection(c *fiber.Ctx) error {
 	var req entity.TestConnectionRequest
 	if err := c.BodyParser(&req); err != nil {
-		return entity.BadRequestResponse(c, "Invalid request body", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
-		return entity.BadRequestResponse(c, "Validation failed", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
 	}
 
 	// Test connection
-	result, err := h.dataSourceService.TestConnection(&req)
+	userID := c.Locals("user_id").(uint)
+	result, err := h.dataSourceService.WithContext(c.UserContext()).TestConnection(userID, &req)
 	if err != nil {
 		return entity.InternalServerErrorResponse(c, "Failed to test connection", err.Error())
 	}
@@ -247,11 +376,11 @@ func (h *DataSourceHandler) RefreshSchema(c *fiber.Ctx) error {
 	// Parse data source ID
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		return entity.BadRequestResponse(c, "Invalid data source ID", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
 	}
 
 	// Refresh schema
-	dataSource, err := h.dataSourceService.RefreshSchema(uint(id), userID)
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).RefreshSchema(uint(id), userID)
 	if err != nil {
 		return entity.BadRequestResponse(c, "Failed to refresh schema", err.Error())
 	}
@@ -259,13 +388,119 @@ func (h *DataSourceHandler) RefreshSchema(c *fiber.Ctx) error {
 	return entity.SuccessResponse(c, "Schema refreshed successfully", dataSource)
 }
 
+// GetDiscovery godoc
+// @Summary Get schema discovery progress
+// @Description Get the discovery status of a data source (connecting, discovering, active or error) with its latest status transitions and the background job running it
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceDiscoveryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/discovery [get]
+func (h *DataSourceHandler) GetDiscovery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	discovery, err := h.dataSourceService.WithContext(c.UserContext()).GetDiscovery(uint(id), userID)
+	if err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	return entity.SuccessResponse(c, "Discovery status retrieved successfully", discovery)
+}
+
+// RetryDiscovery godoc
+// @Summary Retry schema discovery
+// @Description Queue a new connection test and schema discovery of a data source, e.g. after it failed
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 202 {object} models.StandardResponse{data=models.DataSourceResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/discovery/retry [post]
+func (h *DataSourceHandler) RetryDiscovery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	dataSource, err := h.dataSourceService.WithContext(c.UserContext()).RetryDiscovery(uint(id), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrDiscoveryInProgress) {
+			return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to retry schema discovery", err.Error())
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Schema discovery queued",
+		Data:    dataSource,
+	})
+}
+
+// GetSchemaChanges godoc
+// @Summary Get schema changes
+// @Description List the schema changes found when the data source was rediscovered, newest first, with the saved queries and KPIs that reference removed tables or columns
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.SchemaChangeResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/schema-changes [get]
+func (h *DataSourceHandler) GetSchemaChanges(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	changes, err := h.dataSourceService.WithContext(c.UserContext()).GetSchemaChanges(uint(id), userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve schema changes", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Schema changes retrieved successfully", changes)
+}
+
 // UploadFile godoc
 // @Summary Upload a file for CSV/Excel data source
-// @Description Upload a CSV or Excel file to create a file-based data source
+// @Description Upload a CSV or Excel file to create a file-based data source. The response has the inferred table of a CSV file, or of each imported Excel sheet.
 // @Tags data-sources
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "CSV or Excel file"
+// @Param delimiter formData string false "CSV field delimiter (default ,; tab for tab-separated files)"
+// @Param encoding formData string false "CSV encoding, e.g. windows-1252 (detected when empty)"
+// @Param has_header formData bool false "Whether the first row holds the column names (default true)"
+// @Param sheets formData string false "Comma-separated Excel sheets to import (default all)"
 // @Success 200 {object} models.StandardResponse{data=models.FileUploadResponse}
 // @Failure 400 {object} models.StandardResponse
 // @Failure 401 {object} models.StandardResponse
@@ -296,14 +531,91 @@ func (h *DataSourceHandler) UploadFile(c *fiber.Ctx) error {
 		return entity.BadRequestResponse(c, "File too large. Maximum size is 50MB", nil)
 	}
 
+	options, err := fileImportOptions(c)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Invalid upload options", err.Error())
+	}
+
+	tables, err := h.dataSourceService.WithContext(c.UserContext()).InspectFile(file, options)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to read file", err.Error())
+	}
+
 	// TODO: Implement file storage logic
-	// For now, return a mock response
 	response := &entity.FileUploadResponse{
 		FileName: file.Filename,
 		FilePath: "/uploads/" + file.Filename, // This should be the actual stored path
 		FileSize: file.Size,
 		MimeType: file.Header.Get("Content-Type"),
+		Options:  options,
+		Tables:   tables,
 	}
 
 	return entity.SuccessResponse(c, "File uploaded successfully", response)
-}
\ No newline at end of file
+}
+
+// fileImportOptions reads the import options sent with a file upload
+func fileImportOptions(c *fiber.Ctx) (entity.FileImportOptions, error) {
+	options := entity.FileImportOptions{
+		Delimiter: c.FormValue("delimiter"),
+		Encoding:  c.FormValue("encoding"),
+	}
+	if value := c.FormValue("has_header"); value != "" {
+		hasHeader, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, errors.New("has_header must be true or false")
+		}
+		options.HasHeader = &hasHeader
+	}
+	for _, sheet := range strings.Split(c.FormValue("sheets"), ",") {
+		if sheet = strings.TrimSpace(sheet); sheet != "" {
+			options.Sheets = append(options.Sheets, sheet)
+		}
+	}
+	return options, nil
+}
+
+// DryImport godoc
+// @Summary Validate a data source before importing it
+// @Description Report the inferred schema, row count estimate, type conflicts, duplicate headers and encoding problems of a CSV/Excel file or Google Sheets spreadsheet without creating a data source. Send a multipart file, or a JSON body with type google_sheets and its config.
+// @Tags data-sources
+// @Accept multipart/form-data,json
+// @Produce json
+// @Param file formData file false "CSV or Excel file"
+// @Param connection body models.TestConnectionRequest false "Google Sheets configuration"
+// @Success 200 {object} models.StandardResponse{data=models.DryImportReport}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/dry-import [post]
+func (h *DataSourceHandler) DryImport(c *fiber.Ctx) error {
+	if file, err := c.FormFile("file"); err == nil {
+		// Validate file size (max 50MB), same as uploads
+		if file.Size > int64(50*1024*1024) {
+			return entity.BadRequestResponse(c, "File too large. Maximum size is 50MB", nil)
+		}
+
+		report, err := h.dryImportService.AnalyzeFile(file)
+		if err != nil {
+			return entity.BadRequestResponse(c, "Failed to analyze file", err.Error())
+		}
+		return entity.SuccessResponse(c, "Dry import completed", report)
+	}
+
+	var req entity.TestConnectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	if req.Type != entity.DataSourceTypeGoogleSheets {
+		return entity.BadRequestResponse(c, "Dry import supports CSV and Excel uploads and Google Sheets", nil)
+	}
+
+	report, err := h.dryImportService.AnalyzeGoogleSheets(req.Config)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to analyze spreadsheet", err.Error())
+	}
+	return entity.SuccessResponse(c, "Dry import completed", report)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataSourceTemplateHandler struct {
+	templateService *services.DataSourceTemplateService
+	auditService    *services.AuditService
+	validator       *validator.Validate
+}
+
+func NewDataSourceTemplateHandler(templateService *services.DataSourceTemplateService, auditService *services.AuditService) *DataSourceTemplateHandler {
+	return &DataSourceTemplateHandler{
+		templateService: templateService,
+		auditService:    auditService,
+		validator:       validator.New(),
+	}
+}
+
+// GetTemplates godoc
+// @Summary List data source templates
+// @Description List the connection templates curated by admins, with the placeholders to fill in
+// @Tags data-sources
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.DataSourceTemplateResponse}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-source-templates [get]
+func (h *DataSourceTemplateHandler) GetTemplates(c *fiber.Ctx) error {
+	templates, err := h.templateService.WithContext(c.UserContext()).ListTemplates()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve data source templates", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Data source templates retrieved successfully", templates)
+}
+
+// GetTemplate godoc
+// @Summary Get a data source template
+// @Description Get a connection template with the placeholders to fill in
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceTemplateResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-source-templates/{id} [get]
+func (h *DataSourceTemplateHandler) GetTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid template ID", err.Error())
+	}
+
+	template, err := h.templateService.WithContext(c.UserContext()).GetTemplate(uint(id))
+	if err != nil {
+		return dataSourceTemplateErrorResponse(c, "Failed to retrieve data source template", err)
+	}
+
+	return entity.SuccessResponse(c, "Data source template retrieved successfully", template)
+}
+
+// CreateDataSource godoc
+// @Summary Create a data source from a template
+// @Description Create a data source from a connection template with values for its placeholders, including the credentials. Missing values are listed in the validation error.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param data_source body models.DataSourceFromTemplateRequest true "Name and placeholder values"
+// @Success 201 {object} models.StandardResponse{data=models.DataSourceResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-source-templates/{id}/data-sources [post]
+func (h *DataSourceTemplateHandler) CreateDataSource(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid template ID", err.Error())
+	}
+
+	var req entity.DataSourceFromTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	dataSource, err := h.templateService.WithContext(c.UserContext()).CreateDataSource(uint(id), userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrDataSourceTemplateNotFound) {
+			return entity.NotFoundResponse(c, "Data source template not found")
+		}
+		return configValuesErrorResponse(c, "Failed to create data source", err)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataSourceCreate, "data_source", dataSource.ID, nil, dataSource, map[string]interface{}{"template_id": id})
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Data source created successfully",
+		Data:    dataSource,
+	})
+}
+
+// CreateTemplate godoc
+// @Summary Create a data source template (Admin only)
+// @Description Add a connection template. String values may contain {{name}} placeholders; credential fields must be a single placeholder.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param template body models.DataSourceTemplateRequest true "Template"
+// @Success 201 {object} models.StandardResponse{data=models.DataSourceTemplateResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-source-templates [post]
+func (h *DataSourceTemplateHandler) CreateTemplate(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.DataSourceTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	template, err := h.templateService.WithContext(c.UserContext()).CreateTemplate(adminID, &req)
+	if err != nil {
+		return dataSourceTemplateErrorResponse(c, "Failed to create data source template", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Data source template created successfully",
+		Data:    template,
+	})
+}
+
+// UpdateTemplate godoc
+// @Summary Update a data source template (Admin only)
+// @Description Replace a connection template; data sources created from it are not changed
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param template body models.DataSourceTemplateRequest true "Template"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceTemplateResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-source-templates/{id} [put]
+func (h *DataSourceTemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid template ID", err.Error())
+	}
+
+	var req entity.DataSourceTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	template, err := h.templateService.WithContext(c.UserContext()).UpdateTemplate(uint(id), &req)
+	if err != nil {
+		return dataSourceTemplateErrorResponse(c, "Failed to update data source template", err)
+	}
+
+	return entity.SuccessResponse(c, "Data source template updated successfully", template)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a data source template (Admin only)
+// @Description Remove a connection template; data sources created from it are kept
+// @Tags admin
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-source-templates/{id} [delete]
+func (h *DataSourceTemplateHandler) DeleteTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid template ID", err.Error())
+	}
+
+	if err := h.templateService.WithContext(c.UserContext()).DeleteTemplate(uint(id)); err != nil {
+		return dataSourceTemplateErrorResponse(c, "Failed to delete data source template", err)
+	}
+
+	return entity.SuccessResponse(c, "Data source template deleted successfully", nil)
+}
+
+// dataSourceTemplateErrorResponse maps template service errors to responses
+func dataSourceTemplateErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrDataSourceTemplateNotFound):
+		return entity.NotFoundResponse(c, "Data source template not found")
+	case errors.Is(err, services.ErrDataSourceTemplateNameTaken):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrTemplateCredentials):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
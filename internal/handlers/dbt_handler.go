@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxDbtArtifactSize bounds an uploaded manifest.json or catalog.json
+const maxDbtArtifactSize = 50 * 1024 * 1024
+
+type DbtHandler struct {
+	dbtService        *services.DbtService
+	dataSourceService services.DataSourceService
+	auditService      *services.AuditService
+}
+
+func NewDbtHandler(dbtService *services.DbtService, dataSourceService services.DataSourceService, auditService *services.AuditService) *DbtHandler {
+	return &DbtHandler{
+		dbtService:        dbtService,
+		dataSourceService: dataSourceService,
+		auditService:      auditService,
+	}
+}
+
+// ImportDbt godoc
+// @Summary Import dbt documentation
+// @Description Upload the manifest.json of a dbt project, and optionally its catalog.json, to import model, source and column descriptions, tags and tests into the column metadata of the matching tables. Descriptions curated by users are kept; tests are replaced. Described metrics become glossary terms. Changed tables are re-embedded in the background.
+// @Tags data-sources
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param manifest formData file true "dbt manifest.json"
+// @Param catalog formData file false "dbt catalog.json"
+// @Success 200 {object} models.StandardResponse{data=models.DbtImportResult}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/dbt [post]
+func (h *DbtHandler) ImportDbt(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	manifestFile, err := c.FormFile("manifest")
+	if err != nil {
+		return entity.BadRequestResponse(c, "No manifest uploaded", err.Error())
+	}
+	manifest, err := readDbtArtifact(manifestFile)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Invalid manifest", err.Error())
+	}
+	var catalog []byte
+	if catalogFile, err := c.FormFile("catalog"); err == nil {
+		if catalog, err = readDbtArtifact(catalogFile); err != nil {
+			return entity.BadRequestResponse(c, "Invalid catalog", err.Error())
+		}
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	result, err := h.dbtService.WithContext(c.UserContext()).Import(c.UserContext(), uint(id), userID, manifest, catalog)
+	if err != nil {
+		if errors.Is(err, services.ErrDbtManifestInvalid) {
+			return entity.BadRequestResponse(c, "Invalid dbt manifest", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to import dbt manifest", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDbtImport, "data_source", uint(id), nil, result, nil)
+
+	return entity.SuccessResponse(c, "dbt manifest imported successfully", result)
+}
+
+// readDbtArtifact reads an uploaded dbt JSON artifact
+func readDbtArtifact(file *multipart.FileHeader) ([]byte, error) {
+	if file.Size > maxDbtArtifactSize {
+		return nil, fmt.Errorf("%s is too large, maximum size is 50MB", file.Filename)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DigestHandler struct {
+	digestService *services.DigestService
+	validator     *validator.Validate
+}
+
+func NewDigestHandler(digestService *services.DigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+		validator:     validator.New(),
+	}
+}
+
+// GetSubscription godoc
+// @Summary Get digest preference
+// @Description Get how often the current user receives the workspace digest email
+// @Tags digests
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.DigestSubscription}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /digest/subscription [get]
+func (h *DigestHandler) GetSubscription(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	subscription, err := h.digestService.WithContext(c.UserContext()).GetSubscription(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get digest subscription", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Digest subscription retrieved successfully", subscription)
+}
+
+// UpdateSubscription godoc
+// @Summary Update digest preference
+// @Description Receive the workspace digest daily or weekly, or turn it off
+// @Tags digests
+// @Accept json
+// @Produce json
+// @Param subscription body models.DigestSubscriptionRequest true "Digest preference"
+// @Success 200 {object} models.StandardResponse{data=models.DigestSubscription}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /digest/subscription [put]
+func (h *DigestHandler) UpdateSubscription(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.DigestSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	subscription, err := h.digestService.WithContext(c.UserContext()).UpdateSubscription(userID, &req)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to update digest subscription", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Digest subscription updated successfully", subscription)
+}
+
+// Preview godoc
+// @Summary Preview the digest
+// @Description Compose the digest the current user would receive now without sending it
+// @Tags digests
+// @Produce json
+// @Param frequency query string false "daily or weekly, defaults to the user's preference"
+// @Success 200 {object} models.StandardResponse{data=models.Digest}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /digest/preview [get]
+func (h *DigestHandler) Preview(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	frequency := entity.DigestFrequency(c.Query("frequency"))
+	if frequency != "" && frequency != entity.DigestFrequencyDaily && frequency != entity.DigestFrequencyWeekly {
+		return entity.BadRequestResponse(c, "Invalid frequency", "frequency must be daily or weekly")
+	}
+
+	digest, err := h.digestService.WithContext(c.UserContext()).Preview(userID, frequency)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to compose digest", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Digest composed successfully", digest)
+}
+
+// RunDue godoc
+// @Summary Send due digests
+// @Description Email every digest that is due (typically called by a cron job)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.DigestRunResult}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/digests/run [post]
+func (h *DigestHandler) RunDue(c *fiber.Ctx) error {
+	result, err := h.digestService.WithContext(c.UserContext()).RunDue(c.UserContext())
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to send digests", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Due digests processed", result)
+}
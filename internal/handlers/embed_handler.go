@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmbedHandler struct {
+	embedService *services.EmbedService
+	auditService *services.AuditService
+	validator    *validator.Validate
+}
+
+func NewEmbedHandler(embedService *services.EmbedService, auditService *services.AuditService) *EmbedHandler {
+	return &EmbedHandler{
+		embedService: embedService,
+		auditService: auditService,
+		validator:    validator.New(),
+	}
+}
+
+// CreateEmbedToken godoc
+// @Summary Issue an embed token
+// @Description Issue a token granting read-only access to one dashboard, or one widget of it, for embedding in another product. The token expires (default 60 minutes, max 24 hours), shows at most row_limit rows per widget with PII masked, and may be restricted to the domains allowed to embed it.
+// @Tags embed
+// @Accept json
+// @Produce json
+// @Param token body models.EmbedTokenRequest true "Embed token"
+// @Success 201 {object} models.StandardResponse{data=models.EmbedTokenResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /embed/tokens [post]
+func (h *EmbedHandler) CreateEmbedToken(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.EmbedTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	token, err := h.embedService.WithContext(c.UserContext()).IssueToken(userID, &req)
+	if err != nil {
+		return embedErrorResponse(c, "Failed to issue embed token", err)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataExport, "dashboard", req.DashboardID, nil, nil,
+		map[string]interface{}{"destination": "embed", "widget_id": req.WidgetID, "domains": token.Domains, "expires_at": token.ExpiresAt})
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Embed token issued successfully", token)
+}
+
+// GetEmbeddedDashboard godoc
+// @Summary Get an embedded dashboard
+// @Description Read-only dashboard with the latest results of its widgets. Authenticated with an embed token for the dashboard, as a bearer token or the embed_token query parameter.
+// @Tags embed
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param embed_token query string false "Embed token, when not sent as a bearer token"
+// @Success 200 {object} models.StandardResponse{data=models.SharedDashboard}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Router /embed/dashboards/{id} [get]
+func (h *EmbedHandler) GetEmbeddedDashboard(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+
+	dashboard, err := h.embedService.WithContext(c.UserContext()).Dashboard(middleware.GetEmbedClaims(c), uint(id))
+	if err != nil {
+		return embedErrorResponse(c, "Failed to load embedded dashboard", err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return entity.SuccessResponse(c, "Dashboard retrieved successfully", dashboard)
+}
+
+// GetEmbeddedWidget godoc
+// @Summary Get an embedded widget
+// @Description Read-only widget with the latest result of its query. Authenticated with an embed token for the widget or its dashboard.
+// @Tags embed
+// @Produce json
+// @Param id path int true "Dashboard ID"
+// @Param widgetId path int true "Widget ID"
+// @Param embed_token query string false "Embed token, when not sent as a bearer token"
+// @Success 200 {object} models.StandardResponse{data=models.SharedWidget}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Router /embed/dashboards/{id}/widgets/{widgetId} [get]
+func (h *EmbedHandler) GetEmbeddedWidget(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid dashboard ID", err.Error())
+	}
+	widgetID, err := strconv.ParseUint(c.Params("widgetId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid widget ID", err.Error())
+	}
+
+	widget, err := h.embedService.WithContext(c.UserContext()).Widget(middleware.GetEmbedClaims(c), uint(id), uint(widgetID))
+	if err != nil {
+		return embedErrorResponse(c, "Failed to load embedded widget", err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return entity.SuccessResponse(c, "Widget retrieved successfully", widget)
+}
+
+func embedErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrDashboardNotFound), errors.Is(err, services.ErrWidgetNotFound):
+		return entity.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrDashboardReadOnly), errors.Is(err, services.ErrEmbedScope):
+		return entity.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrInvalidEmbedDomain):
+		return entity.BadRequestResponse(c, "Invalid domain", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
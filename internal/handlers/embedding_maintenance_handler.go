@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmbeddingMaintenanceHandler struct {
+	maintenanceService *services.EmbeddingMaintenanceService
+	auditService       *services.AuditService
+}
+
+func NewEmbeddingMaintenanceHandler(maintenanceService *services.EmbeddingMaintenanceService, auditService *services.AuditService) *EmbeddingMaintenanceHandler {
+	return &EmbeddingMaintenanceHandler{
+		maintenanceService: maintenanceService,
+		auditService:       auditService,
+	}
+}
+
+// GetStorageStats godoc
+// @Summary Embedding storage statistics (Admin only)
+// @Description Size of the schema embeddings table and its vector indexes, dead rows, and the embeddings of each data source
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.EmbeddingStorageStats}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/embeddings/stats [get]
+func (h *EmbeddingMaintenanceHandler) GetStorageStats(c *fiber.Ctx) error {
+	stats, err := h.maintenanceService.WithContext(c.UserContext()).GetStorageStats(c.UserContext())
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get embedding storage statistics", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Embedding storage statistics retrieved", stats)
+}
+
+// Compact godoc
+// @Summary Compact embedding storage (Admin only)
+// @Description Permanently delete soft-deleted embeddings, optionally of one data source or deleted before a date, and vacuum the table. full=true rewrites the table and locks it meanwhile.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.EmbeddingCompactRequest false "Embeddings to purge"
+// @Success 200 {object} models.StandardResponse{data=models.EmbeddingCompactRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/embeddings/compact [post]
+func (h *EmbeddingMaintenanceHandler) Compact(c *fiber.Ctx) error {
+	var req entity.EmbeddingCompactRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+
+	run, err := h.maintenanceService.WithContext(c.UserContext()).Compact(c.UserContext(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidEmbeddingMaintenance) {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to compact embeddings", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionEmbeddingMaintain, "schema_embedding", 0, nil, nil, map[string]interface{}{
+		"operation":         "compact",
+		"data_source_id":    req.DataSourceID,
+		"deleted_before":    req.DeletedBefore,
+		"full":              req.Full,
+		"embeddings_purged": run.EmbeddingsPurged,
+	})
+
+	return entity.SuccessResponse(c, "Embeddings compacted", run)
+}
+
+// RebuildIndexes godoc
+// @Summary Rebuild vector indexes (Admin only)
+// @Description Rebuild the pgvector indexes concurrently, without blocking searches or syncs; a missing index is created
+// @Tags admin
+// @Produce json
+// @Param index query string false "Only this index, by index or table name"
+// @Success 200 {object} models.StandardResponse{data=[]models.VectorIndexRebuild}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/embeddings/reindex [post]
+func (h *EmbeddingMaintenanceHandler) RebuildIndexes(c *fiber.Ctx) error {
+	index := c.Query("index")
+	rebuilds, err := h.maintenanceService.WithContext(c.UserContext()).RebuildIndexes(c.UserContext(), index)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidEmbeddingMaintenance) {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to rebuild vector indexes", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionEmbeddingMaintain, "schema_embedding", 0, nil, nil, map[string]interface{}{
+		"operation": "reindex",
+		"index":     index,
+	})
+
+	return entity.SuccessResponse(c, "Vector indexes rebuilt", rebuilds)
+}
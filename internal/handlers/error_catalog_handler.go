@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	entity "narapulse-be/internal/models/entity"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ErrorCatalogHandler struct{}
+
+func NewErrorCatalogHandler() *ErrorCatalogHandler {
+	return &ErrorCatalogHandler{}
+}
+
+// ListErrorCodes godoc
+// @Summary List error codes
+// @Description List every code the API returns in error.code with its HTTP status and meaning, so clients can map errors without parsing messages
+// @Tags errors
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ErrorCodeInfo}
+// @Router /errors [get]
+func (h *ErrorCatalogHandler) ListErrorCodes(c *fiber.Ctx) error {
+	return entity.SuccessResponse(c, "Error codes retrieved successfully", entity.ErrorCatalog())
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExtractHandler struct {
+	extractService *services.ExtractService
+	validator      *validator.Validate
+}
+
+func NewExtractHandler(extractService *services.ExtractService) *ExtractHandler {
+	return &ExtractHandler{
+		extractService: extractService,
+		validator:      validator.New(),
+	}
+}
+
+// GetExtracts godoc
+// @Summary List extracts
+// @Description List the query extracts of the current user with the freshness of their snapshots
+// @Tags extracts
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ExtractResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts [get]
+func (h *ExtractHandler) GetExtracts(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	extracts, err := h.extractService.WithContext(c.UserContext()).List(userID)
+	if err != nil {
+		return extractErrorResponse(c, "Failed to get extracts", err)
+	}
+
+	return entity.SuccessResponse(c, "Extracts retrieved successfully", extracts)
+}
+
+// CreateExtract godoc
+// @Summary Create an extract
+// @Description Snapshot the results of a query into local storage, refreshed every refresh_interval_minutes (default 60; 0 refreshes on demand only). The first snapshot is queued. Bind dashboard widgets to the extract with extract_id so views read the snapshot instead of querying the data source.
+// @Tags extracts
+// @Accept json
+// @Produce json
+// @Param extract body models.ExtractRequest true "Extract"
+// @Success 201 {object} models.StandardResponse{data=models.ExtractResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts [post]
+func (h *ExtractHandler) CreateExtract(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.ExtractRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	extract, err := h.extractService.WithContext(c.UserContext()).Create(c.UserContext(), userID, &req)
+	if err != nil {
+		return extractErrorResponse(c, "Failed to create extract", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Extract created successfully", extract)
+}
+
+// GetExtract godoc
+// @Summary Get an extract
+// @Description Get an extract with the status of its last refresh and the freshness of its snapshot
+// @Tags extracts
+// @Produce json
+// @Param id path int true "Extract ID"
+// @Success 200 {object} models.StandardResponse{data=models.ExtractResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts/{id} [get]
+func (h *ExtractHandler) GetExtract(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid extract ID", err.Error())
+	}
+
+	extract, err := h.extractService.WithContext(c.UserContext()).Get(userID, uint(id))
+	if err != nil {
+		return extractErrorResponse(c, "Failed to get extract", err)
+	}
+
+	return entity.SuccessResponse(c, "Extract retrieved successfully", extract)
+}
+
+// UpdateExtract godoc
+// @Summary Update an extract
+// @Description Change the name, description, refresh interval or row limit of an extract; its query cannot change. A new row limit applies from the next refresh.
+// @Tags extracts
+// @Accept json
+// @Produce json
+// @Param id path int true "Extract ID"
+// @Param extract body models.ExtractRequest true "Extract"
+// @Success 200 {object} models.StandardResponse{data=models.ExtractResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts/{id} [put]
+func (h *ExtractHandler) UpdateExtract(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid extract ID", err.Error())
+	}
+
+	var req entity.ExtractRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	extract, err := h.extractService.WithContext(c.UserContext()).Update(userID, uint(id), &req)
+	if err != nil {
+		return extractErrorResponse(c, "Failed to update extract", err)
+	}
+
+	return entity.SuccessResponse(c, "Extract updated successfully", extract)
+}
+
+// DeleteExtract godoc
+// @Summary Delete an extract
+// @Description Remove an extract and its snapshot. Widgets bound to it display the latest result of their query again.
+// @Tags extracts
+// @Produce json
+// @Param id path int true "Extract ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts/{id} [delete]
+func (h *ExtractHandler) DeleteExtract(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid extract ID", err.Error())
+	}
+
+	if err := h.extractService.WithContext(c.UserContext()).Delete(userID, uint(id)); err != nil {
+		return extractErrorResponse(c, "Failed to delete extract", err)
+	}
+
+	return entity.SuccessResponse(c, "Extract deleted successfully", nil)
+}
+
+// RefreshExtract godoc
+// @Summary Refresh an extract
+// @Description Queue a refresh of the snapshot of an extract now rather than at its refresh interval. A failed refresh keeps the previous snapshot.
+// @Tags extracts
+// @Produce json
+// @Param id path int true "Extract ID"
+// @Success 202 {object} models.StandardResponse{data=models.ExtractResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts/{id}/refresh [post]
+func (h *ExtractHandler) RefreshExtract(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid extract ID", err.Error())
+	}
+
+	extract, err := h.extractService.WithContext(c.UserContext()).QueueRefresh(c.UserContext(), userID, uint(id))
+	if err != nil {
+		return extractErrorResponse(c, "Failed to refresh extract", err)
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return entity.SuccessResponse(c, "Extract refresh queued", extract)
+}
+
+// GetExtractData godoc
+// @Summary Get the rows of an extract
+// @Description Page through the snapshot of an extract, with its freshness. PII columns are masked unless the caller may unmask them.
+// @Tags extracts
+// @Produce json
+// @Param id path int true "Extract ID"
+// @Param offset query int false "Rows to skip"
+// @Param limit query int false "Rows to return (default 1000, max 10000)"
+// @Success 200 {object} models.StandardResponse{data=models.ExtractData}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /extracts/{id}/data [get]
+func (h *ExtractHandler) GetExtractData(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid extract ID", err.Error())
+	}
+
+	var req entity.ExtractDataRequest
+	if err := c.QueryParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query parameters", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	req.UnmaskPII = middleware.CanUnmaskPII(c)
+
+	data, err := h.extractService.WithContext(c.UserContext()).Data(userID, uint(id), &req)
+	if err != nil {
+		return extractErrorResponse(c, "Failed to get extract data", err)
+	}
+
+	return entity.SuccessResponse(c, "Extract data retrieved successfully", data)
+}
+
+func extractErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrExtractNotFound):
+		return entity.NotFoundResponse(c, "Extract not found")
+	case errors.Is(err, services.ErrQueryNotFound):
+		return entity.NotFoundResponse(c, "Query not found")
+	case errors.Is(err, services.ErrExtractNotRefreshed):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrExtractInvalid):
+		return entity.BadRequestResponse(c, message, err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FeatureFlagHandler struct {
+	flagService *services.FeatureFlagService
+	validator   *validator.Validate
+}
+
+func NewFeatureFlagHandler(flagService *services.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagService: flagService,
+		validator:   validator.New(),
+	}
+}
+
+// GetMyFeatureFlags godoc
+// @Summary Get the feature flags of the user
+// @Description Evaluate every feature flag for the authenticated user, so clients show the capabilities rolled out to them
+// @Tags feature-flags
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.FeatureFlagEvaluation}
+// @Security ApiKeyAuth
+// @Router /feature-flags [get]
+func (h *FeatureFlagHandler) GetMyFeatureFlags(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(uint)
+	return entity.SuccessResponse(c, "Feature flags retrieved successfully", h.flagService.EvaluateAll(c.UserContext(), userID))
+}
+
+// ListFeatureFlags godoc
+// @Summary List feature flags (Admin only)
+// @Description List the feature flags with their rollout and overrides
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.FeatureFlag}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/feature-flags [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(c *fiber.Ctx) error {
+	flags, err := h.flagService.ListFlags(c.UserContext())
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve feature flags", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Feature flags retrieved successfully", flags)
+}
+
+// CreateFeatureFlag godoc
+// @Summary Create a feature flag (Admin only)
+// @Description Create a flag gating a capability. An enabled flag is on for the rollout percentage of users; users keep their answer as the rollout grows.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param flag body models.FeatureFlagCreateRequest true "Feature flag"
+// @Success 201 {object} models.StandardResponse{data=models.FeatureFlag}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/feature-flags [post]
+func (h *FeatureFlagHandler) CreateFeatureFlag(c *fiber.Ctx) error {
+	var req entity.FeatureFlagCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	flag, err := h.flagService.CreateFlag(c.UserContext(), &req)
+	if err != nil {
+		return featureFlagErrorResponse(c, "Failed to create feature flag", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Feature flag created successfully",
+		Data:    flag,
+	})
+}
+
+// UpdateFeatureFlag godoc
+// @Summary Update a feature flag (Admin only)
+// @Description Change the description, state or rollout percentage of a flag
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key path string true "Feature flag key"
+// @Param flag body models.FeatureFlagUpdateRequest true "Changes"
+// @Success 200 {object} models.StandardResponse{data=models.FeatureFlag}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/feature-flags/{key} [put]
+func (h *FeatureFlagHandler) UpdateFeatureFlag(c *fiber.Ctx) error {
+	var req entity.FeatureFlagUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	flag, err := h.flagService.UpdateFlag(c.UserContext(), c.Params("key"), &req)
+	if err != nil {
+		return featureFlagErrorResponse(c, "Failed to update feature flag", err)
+	}
+
+	return entity.SuccessResponse(c, "Feature flag updated successfully", flag)
+}
+
+// DeleteFeatureFlag godoc
+// @Summary Delete a feature flag (Admin only)
+// @Description Delete a flag with its overrides. Capabilities it gated are off afterwards.
+// @Tags admin
+// @Produce json
+// @Param key path string true "Feature flag key"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/feature-flags/{key} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *fiber.Ctx) error {
+	if err := h.flagService.DeleteFlag(c.UserContext(), c.Params("key")); err != nil {
+		return featureFlagErrorResponse(c, "Failed to delete feature flag", err)
+	}
+
+	return entity.SuccessResponse(c, "Feature flag deleted successfully", nil)
+}
+
+// SetFeatureFlagOverride godoc
+// @Summary Override a feature flag for a tenant or user (Admin only)
+// @Description Turn a flag on or off for a tenant or user regardless of its state and rollout. Overrides for the user win over those for the tenant.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key path string true "Feature flag key"
+// @Param override body models.FeatureFlagOverrideRequest true "Override"
+// @Success 200 {object} models.StandardResponse{data=models.FeatureFlagOverride}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/feature-flags/{key}/overrides [put]
+func (h *FeatureFlagHandler) SetFeatureFlagOverride(c *fiber.Ctx) error {
+	var req entity.FeatureFlagOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	override, err := h.flagService.SetOverride(c.UserContext(), c.Params("key"), &req)
+	if err != nil {
+		return featureFlagErrorResponse(c, "Failed to override feature flag", err)
+	}
+
+	return entity.SuccessResponse(c, "Feature flag override saved successfully", override)
+}
+
+// DeleteFeatureFlagOverride godoc
+// @Summary Remove the override of a feature flag (Admin only)
+// @Description Remove the override of a flag for a tenant or user, who get the flag's rollout again
+// @Tags admin
+// @Produce json
+// @Param key path string true "Feature flag key"
+// @Param scope path string true "tenant or user"
+// @Param subjectId path int true "Tenant or user ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/feature-flags/{key}/overrides/{scope}/{subjectId} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlagOverride(c *fiber.Ctx) error {
+	scope := c.Params("scope")
+	if scope != entity.FeatureFlagScopeTenant && scope != entity.FeatureFlagScopeUser {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Scope must be tenant or user", nil)
+	}
+	subjectID, err := strconv.ParseUint(c.Params("subjectId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid tenant or user ID", err.Error())
+	}
+
+	if err := h.flagService.DeleteOverride(c.UserContext(), c.Params("key"), scope, uint(subjectID)); err != nil {
+		return featureFlagErrorResponse(c, "Failed to remove feature flag override", err)
+	}
+
+	return entity.SuccessResponse(c, "Feature flag override removed successfully", nil)
+}
+
+// featureFlagErrorResponse maps feature flag service errors to responses
+func featureFlagErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrFeatureFlagNotFound):
+		return entity.NotFoundResponse(c, "Feature flag not found")
+	case errors.Is(err, services.ErrFeatureFlagKeyTaken):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidFeatureFlagKey):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, err.Error(), nil)
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FileUploadHandler struct {
+	fileUploadService *services.FileUploadService
+	validator         *validator.Validate
+}
+
+func NewFileUploadHandler(fileUploadService *services.FileUploadService) *FileUploadHandler {
+	return &FileUploadHandler{
+		fileUploadService: fileUploadService,
+		validator:         validator.New(),
+	}
+}
+
+// InitiateUpload godoc
+// @Summary Start a chunked file upload
+// @Description Start a resumable upload of a CSV or Excel file too large for a single request. Send the file as total_parts parts of chunk_size bytes (the last part holds the rest), then complete the upload. Incomplete uploads expire after 24 hours.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param upload body models.FileUploadInitiateRequest true "File to upload"
+// @Success 201 {object} models.StandardResponse{data=models.FileUploadSessionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/uploads [post]
+func (h *FileUploadHandler) InitiateUpload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.FileUploadInitiateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	upload, err := h.fileUploadService.WithContext(c.UserContext()).Initiate(userID, &req)
+	if err != nil {
+		return fileUploadErrorResponse(c, "Failed to start upload", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Upload started successfully", upload)
+}
+
+// GetUpload godoc
+// @Summary Get a chunked file upload
+// @Description Upload status with the received and missing parts, to resume an interrupted upload. Once completed, it has the stored file_path and the inferred tables.
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Upload ID"
+// @Success 200 {object} models.StandardResponse{data=models.FileUploadSessionResponse}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/uploads/{id} [get]
+func (h *FileUploadHandler) GetUpload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid upload ID", err.Error())
+	}
+
+	upload, err := h.fileUploadService.WithContext(c.UserContext()).Get(userID, uint(id))
+	if err != nil {
+		return fileUploadErrorResponse(c, "Failed to get upload", err)
+	}
+
+	return entity.SuccessResponse(c, "Upload retrieved successfully", upload)
+}
+
+// UploadPart godoc
+// @Summary Upload a part of a chunked file upload
+// @Description Send part number of the file as the raw request body. Every part but the last must be exactly chunk_size bytes. Parts may be sent in any order; resending a part replaces it.
+// @Tags data-sources
+// @Accept octet-stream
+// @Produce json
+// @Param id path int true "Upload ID"
+// @Param number path int true "Part number, from 1"
+// @Success 200 {object} models.StandardResponse{data=models.FileUploadPart}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/uploads/{id}/parts/{number} [put]
+func (h *FileUploadHandler) UploadPart(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid upload ID", err.Error())
+	}
+	number, err := strconv.Atoi(c.Params("number"))
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid part number", err.Error())
+	}
+
+	part, err := h.fileUploadService.WithContext(c.UserContext()).UploadPart(c.UserContext(), userID, uint(id), number, bytes.NewReader(c.Body()))
+	if err != nil {
+		return fileUploadErrorResponse(c, "Failed to upload part", err)
+	}
+
+	return entity.SuccessResponse(c, "Part uploaded successfully", part)
+}
+
+// CompleteUpload godoc
+// @Summary Complete a chunked file upload
+// @Description Assemble the parts into the stored file and infer its tables in the background. Poll the upload until its status is completed or failed.
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Upload ID"
+// @Success 202 {object} models.StandardResponse{data=models.FileUploadSessionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/uploads/{id}/complete [post]
+func (h *FileUploadHandler) CompleteUpload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid upload ID", err.Error())
+	}
+
+	upload, err := h.fileUploadService.WithContext(c.UserContext()).Complete(c.UserContext(), userID, uint(id))
+	if err != nil {
+		return fileUploadErrorResponse(c, "Failed to complete upload", err)
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return entity.SuccessResponse(c, "Upload is being processed", upload)
+}
+
+// AbortUpload godoc
+// @Summary Abort a chunked file upload
+// @Description Delete an upload with its parts and stored file
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Upload ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/uploads/{id} [delete]
+func (h *FileUploadHandler) AbortUpload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid upload ID", err.Error())
+	}
+
+	if err := h.fileUploadService.WithContext(c.UserContext()).Abort(c.UserContext(), userID, uint(id)); err != nil {
+		return fileUploadErrorResponse(c, "Failed to abort upload", err)
+	}
+
+	return entity.SuccessResponse(c, "Upload aborted successfully", nil)
+}
+
+func fileUploadErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrFileUploadNotFound):
+		return entity.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrFileUploadState):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, message, err.Error())
+	case errors.Is(err, services.ErrInvalidFileUpload), errors.Is(err, services.ErrFileUploadIncomplete),
+		errors.Is(err, services.ErrInvalidFileImportOptions):
+		return entity.BadRequestResponse(c, message, err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GoogleDriveHandler struct {
+	googleDriveService *services.GoogleDriveService
+	validator          *validator.Validate
+}
+
+func NewGoogleDriveHandler(googleDriveService *services.GoogleDriveService) *GoogleDriveHandler {
+	return &GoogleDriveHandler{
+		googleDriveService: googleDriveService,
+		validator:          validator.New(),
+	}
+}
+
+// GetAuthURL godoc
+// @Summary Get the Google Drive consent URL
+// @Description Google consent page granting NaraPulse read-only access to Drive (drive.readonly scope). Google redirects back to redirect_uri with a code to exchange for tokens.
+// @Tags data-sources
+// @Produce json
+// @Param redirect_uri query string true "Redirect URI registered with the Google OAuth client"
+// @Param state query string false "Opaque value returned with the redirect"
+// @Success 200 {object} models.StandardResponse{data=models.GoogleDriveAuthURLResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 503 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/google-drive/auth-url [get]
+func (h *GoogleDriveHandler) GetAuthURL(c *fiber.Ctx) error {
+	redirectURI := c.Query("redirect_uri")
+	if err := h.validator.Var(redirectURI, "required,url"); err != nil {
+		return entity.BadRequestResponse(c, "Invalid redirect_uri", err.Error())
+	}
+
+	authURL, err := h.googleDriveService.AuthURL(redirectURI, c.Query("state"))
+	if err != nil {
+		return googleDriveErrorResponse(c, "Failed to create consent URL", err)
+	}
+
+	return entity.SuccessResponse(c, "Consent URL created successfully", authURL)
+}
+
+// ExchangeToken godoc
+// @Summary Exchange a Google authorization code
+// @Description Exchange the code Google redirected back with for the access and refresh tokens of a google_drive data source config
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param token body models.GoogleDriveTokenRequest true "Authorization code"
+// @Success 200 {object} models.StandardResponse{data=models.GoogleDriveTokenResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 503 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/google-drive/token [post]
+func (h *GoogleDriveHandler) ExchangeToken(c *fiber.Ctx) error {
+	var req entity.GoogleDriveTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	token, err := h.googleDriveService.ExchangeCode(c.UserContext(), &req)
+	if err != nil {
+		return googleDriveErrorResponse(c, "Failed to exchange authorization code", err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return entity.SuccessResponse(c, "Authorization code exchanged successfully", token)
+}
+
+// BrowseFiles godoc
+// @Summary Browse Google Drive
+// @Description List the folders and CSV/Excel files of a Drive folder (root by default), or search Drive by name, to pick the file_id of a google_drive data source
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param browse body models.GoogleDriveBrowseRequest true "Credentials and folder"
+// @Success 200 {object} models.StandardResponse{data=models.GoogleDriveBrowseResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/google-drive/files [post]
+func (h *GoogleDriveHandler) BrowseFiles(c *fiber.Ctx) error {
+	var req entity.GoogleDriveBrowseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	files, err := h.googleDriveService.Browse(&req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to browse Google Drive", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Files retrieved successfully", files)
+}
+
+func googleDriveErrorResponse(c *fiber.Ctx, message string, err error) error {
+	if errors.Is(err, services.ErrGoogleOAuthNotConfigured) {
+		return entity.ErrorResponseWithStatus(c, fiber.StatusServiceUnavailable, message, err.Error())
+	}
+	return entity.BadRequestResponse(c, message, err.Error())
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GoogleSheetsHandler struct {
+	googleSheetsService *services.GoogleSheetsService
+	validator           *validator.Validate
+}
+
+func NewGoogleSheetsHandler(googleSheetsService *services.GoogleSheetsService) *GoogleSheetsHandler {
+	return &GoogleSheetsHandler{
+		googleSheetsService: googleSheetsService,
+		validator:           validator.New(),
+	}
+}
+
+// ListSheets godoc
+// @Summary List the sheets of a spreadsheet
+// @Description List the sheets of the spreadsheet of a google_sheets config with the headers its range and header_row give each, to choose the sheets registered as tables. Sheets selected by sheet_name, sheets or range are marked.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param sheets body models.GoogleSheetsListRequest true "Spreadsheet config"
+// @Success 200 {object} models.StandardResponse{data=models.GoogleSheetsListResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/google-sheets/sheets [post]
+func (h *GoogleSheetsHandler) ListSheets(c *fiber.Ctx) error {
+	var req entity.GoogleSheetsListRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	sheets, err := h.googleSheetsService.ListSheets(&req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to list sheets", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Sheets retrieved successfully", sheets)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GovernanceHandler struct {
+	governanceService *services.GovernanceService
+	validator         *validator.Validate
+}
+
+func NewGovernanceHandler(governanceService *services.GovernanceService) *GovernanceHandler {
+	return &GovernanceHandler{
+		governanceService: governanceService,
+		validator:         validator.New(),
+	}
+}
+
+// GetEvents godoc
+// @Summary List governance events
+// @Description List governance events from the ordered event log
+// @Tags governance
+// @Accept json
+// @Produce json
+// @Param after query int false "Only return events with a sequence greater than this"
+// @Param type query string false "Filter by event type"
+// @Param limit query int false "Maximum number of events" default(100)
+// @Success 200 {object} models.StandardResponse{data=[]models.GovernanceEvent}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/governance/events [get]
+func (h *GovernanceHandler) GetEvents(c *fiber.Ctx) error {
+	after, err := strconv.ParseUint(c.Query("after", "0"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid after sequence", err.Error())
+	}
+
+	events, err := h.governanceService.WithContext(c.UserContext()).ListEvents(uint(after), c.Query("type"), c.QueryInt("limit", 100))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get governance events", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Governance events retrieved successfully", events)
+}
+
+// GetDeliveryStatus godoc
+// @Summary Get compliance webhook delivery status
+// @Description Get the last delivered sequence and error of the compliance webhook
+// @Tags governance
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.GovernanceWebhookCursor}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/governance/webhook [get]
+func (h *GovernanceHandler) GetDeliveryStatus(c *fiber.Ctx) error {
+	cursor, err := h.governanceService.WithContext(c.UserContext()).GetCursor()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get delivery status", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Delivery status retrieved successfully", cursor)
+}
+
+// DispatchEvents godoc
+// @Summary Deliver pending governance events
+// @Description Deliver pending events to the compliance webhook in sequence order
+// @Tags governance
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.GovernanceDeliveryResult}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/governance/webhook/dispatch [post]
+func (h *GovernanceHandler) DispatchEvents(c *fiber.Ctx) error {
+	result, err := h.governanceService.WithContext(c.UserContext()).Dispatch()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to dispatch governance events", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Governance events dispatched", result)
+}
+
+// ReplayEvents godoc
+// @Summary Replay governance events
+// @Description Re-send a range of already delivered events to the compliance webhook in order
+// @Tags governance
+// @Accept json
+// @Produce json
+// @Param replay body models.GovernanceReplayRequest true "Sequence range to replay"
+// @Success 200 {object} models.StandardResponse{data=models.GovernanceDeliveryResult}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/governance/webhook/replay [post]
+func (h *GovernanceHandler) ReplayEvents(c *fiber.Ctx) error {
+	var req entity.GovernanceReplayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	result, err := h.governanceService.WithContext(c.UserContext()).Replay(req.FromSequence, req.ToSequence)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to replay governance events", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Governance events replayed", result)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/graphql"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxGraphQLQueryLength bounds the query of a request, long before the
+// parser's nesting bound or the body limit of the server
+const maxGraphQLQueryLength = 64 * 1024
+
+type GraphQLHandler struct {
+	graphQLService *services.GraphQLService
+}
+
+func NewGraphQLHandler(graphQLService *services.GraphQLService) *GraphQLHandler {
+	return &GraphQLHandler{
+		graphQLService: graphQLService,
+	}
+}
+
+// Query godoc
+// @Summary Run a GraphQL query
+// @Description Query users, data sources, schemas, saved queries, KPI values and dashboards in one request. Relations of lists are loaded in batches. The response follows the GraphQL over HTTP format ({data, errors}) rather than the standard response; invalid queries return 400 without data, and queries longer than 64 KiB return 413.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graphql.Request true "Query, operation name and variables"
+// @Success 200 {object} graphql.Response
+// @Failure 400 {object} graphql.Response
+// @Failure 413 {object} graphql.Response
+// @Security ApiKeyAuth
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req graphql.Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Response{Errors: []*graphql.Error{{Message: "Invalid request body: " + err.Error()}}})
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Response{Errors: []*graphql.Error{{Message: "query is required"}}})
+	}
+	if len(req.Query) > maxGraphQLQueryLength {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(&graphql.Response{Errors: []*graphql.Error{{Message: fmt.Sprintf("query must be at most %d bytes", maxGraphQLQueryLength)}}})
+	}
+
+	response := h.graphQLService.Execute(c.UserContext(), userID, req)
+	if response.Data == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response)
+	}
+	return c.JSON(response)
+}
+
+// GetSchema godoc
+// @Summary Get the GraphQL schema
+// @Description Get the schema of the GraphQL endpoint in the schema definition language, e.g. for code generation
+// @Tags graphql
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=string}
+// @Security ApiKeyAuth
+// @Router /graphql/schema [get]
+func (h *GraphQLHandler) GetSchema(c *fiber.Ctx) error {
+	return entity.SuccessResponse(c, "GraphQL schema retrieved successfully", h.graphQLService.SDL())
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"narapulse-be/internal/pkg/graphql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLQueryRejectsLongQueries(t *testing.T) {
+	// The query is rejected before it reaches the service
+	handler := NewGraphQLHandler(nil)
+	app := fiber.New()
+	app.Post("/graphql", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uint(1))
+		return handler.Query(c)
+	})
+
+	body, err := json.Marshal(graphql.Request{Query: "{ users { id } }" + strings.Repeat(" ", maxGraphQLQueryLength)})
+	require.NoError(t, err)
+	req := httptest.NewRequest(fiber.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	var response graphql.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Nil(t, response.Data)
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0].Message, "at most")
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/chat"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IntegrationHandler struct {
+	integrationService *services.IntegrationService
+	auditService       *services.AuditService
+	validator          *validator.Validate
+}
+
+func NewIntegrationHandler(integrationService *services.IntegrationService, auditService *services.AuditService) *IntegrationHandler {
+	return &IntegrationHandler{
+		integrationService: integrationService,
+		auditService:       auditService,
+		validator:          validator.New(),
+	}
+}
+
+// GetIntegrations godoc
+// @Summary List chat integrations (admin)
+// @Description List the Slack and Teams integrations of the workspace; secrets are never returned
+// @Tags integrations
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ChatIntegrationResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/integrations [get]
+func (h *IntegrationHandler) GetIntegrations(c *fiber.Ctx) error {
+	integrations, err := h.integrationService.WithContext(c.UserContext()).List()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve integrations", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Integrations retrieved successfully", integrations)
+}
+
+// CreateIntegration godoc
+// @Summary Create a chat integration (admin)
+// @Description Configure a Slack or Teams incoming webhook, or a Slack app bot token. A signing secret with a command user and data source enables the command endpoint.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param integration body models.ChatIntegrationRequest true "Integration"
+// @Success 201 {object} models.StandardResponse{data=models.ChatIntegrationResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/integrations [post]
+func (h *IntegrationHandler) CreateIntegration(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.ChatIntegrationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	integration, err := h.integrationService.WithContext(c.UserContext()).Create(userID, &req)
+	if err != nil {
+		return integrationErrorResponse(c, "Failed to create integration", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Integration created successfully", integration)
+}
+
+// UpdateIntegration godoc
+// @Summary Update a chat integration (admin)
+// @Description Replace an integration; secrets left empty keep their current value
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Param integration body models.ChatIntegrationRequest true "Integration"
+// @Success 200 {object} models.StandardResponse{data=models.ChatIntegrationResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/integrations/{id} [put]
+func (h *IntegrationHandler) UpdateIntegration(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid integration ID", err.Error())
+	}
+
+	var req entity.ChatIntegrationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	integration, err := h.integrationService.WithContext(c.UserContext()).Update(uint(id), &req)
+	if err != nil {
+		return integrationErrorResponse(c, "Failed to update integration", err)
+	}
+
+	return entity.SuccessResponse(c, "Integration updated successfully", integration)
+}
+
+// DeleteIntegration godoc
+// @Summary Delete a chat integration (admin)
+// @Tags integrations
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/integrations/{id} [delete]
+func (h *IntegrationHandler) DeleteIntegration(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid integration ID", err.Error())
+	}
+
+	if err := h.integrationService.WithContext(c.UserContext()).Delete(uint(id)); err != nil {
+		return integrationErrorResponse(c, "Failed to delete integration", err)
+	}
+
+	return entity.SuccessResponse(c, "Integration deleted successfully", nil)
+}
+
+// TestIntegration godoc
+// @Summary Test a chat integration (admin)
+// @Description Post a test message through an integration, to its default channel or the given one
+// @Tags integrations
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Param channel query string false "Channel, for Slack apps"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/integrations/{id}/test [post]
+func (h *IntegrationHandler) TestIntegration(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid integration ID", err.Error())
+	}
+
+	if err := h.integrationService.WithContext(c.UserContext()).Test(c.UserContext(), uint(id), c.Query("channel")); err != nil {
+		return integrationErrorResponse(c, "Failed to post test message", err)
+	}
+
+	return entity.SuccessResponse(c, "Test message posted", nil)
+}
+
+// GetAvailableIntegrations godoc
+// @Summary List chat destinations
+// @Description List the active Slack and Teams integrations query results can be shared to
+// @Tags integrations
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ChatIntegrationSummary}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /integrations [get]
+func (h *IntegrationHandler) GetAvailableIntegrations(c *fiber.Ctx) error {
+	integrations, err := h.integrationService.WithContext(c.UserContext()).ListActive()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve integrations", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Integrations retrieved successfully", integrations)
+}
+
+// ShareResult godoc
+// @Summary Send a query result to Slack or Teams
+// @Description Post the question and the first rows of a stored result of one of your queries to a chat integration
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Param share body models.ChatShareRequest true "Result to share"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /integrations/{id}/share [post]
+func (h *IntegrationHandler) ShareResult(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid integration ID", err.Error())
+	}
+
+	var req entity.ChatShareRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	if err := h.integrationService.WithContext(c.UserContext()).ShareResult(c.UserContext(), userID, uint(id), &req); err != nil {
+		return integrationErrorResponse(c, "Failed to share result", err)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataExport, "nl2sql_query", req.QueryID, nil, nil,
+		map[string]interface{}{"destination": "chat", "integration_id": id, "result_id": req.ResultID, "channel": req.Channel})
+
+	return entity.SuccessResponse(c, "Result shared successfully", nil)
+}
+
+// HandleCommand godoc
+// @Summary Answer a chat command
+// @Description Endpoint for a Slack slash command or a Teams outgoing webhook. Requests are verified with the integration's signing secret; the question is answered as the command user and the reply is a sentence or a table snapshot.
+// @Tags integrations
+// @Accept x-www-form-urlencoded
+// @Accept json
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Router /integrations/{id}/command [post]
+func (h *IntegrationHandler) HandleCommand(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid integration ID", err.Error())
+	}
+
+	// The body is copied since the reply may be answered after the request ends
+	req := &entity.ChatCommandRequest{
+		Body:          append([]byte(nil), c.Body()...),
+		Timestamp:     c.Get("X-Slack-Request-Timestamp"),
+		Signature:     c.Get("X-Slack-Signature"),
+		Authorization: c.Get(fiber.HeaderAuthorization),
+	}
+
+	reply, err := h.integrationService.WithContext(c.UserContext()).HandleCommand(c.UserContext(), uint(id), req)
+	if err != nil {
+		return integrationErrorResponse(c, "Failed to handle command", err)
+	}
+
+	return c.JSON(reply)
+}
+
+func integrationErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrIntegrationNotFound):
+		return entity.NotFoundResponse(c, "Integration not found")
+	case errors.Is(err, services.ErrIntegrationCommandsDisabled):
+		return entity.NotFoundResponse(c, "Commands are not enabled for this integration")
+	case errors.Is(err, chat.ErrInvalidSignature):
+		return entity.UnauthorizedResponse(c, "Invalid request signature")
+	case err.Error() == "query not found" || errors.Is(err, services.ErrQueryResultNotFound):
+		return entity.NotFoundResponse(c, "Query or result not found")
+	case errors.Is(err, services.ErrInvalidIntegration):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobHandler struct {
+	jobService *services.JobService
+}
+
+func NewJobHandler(jobService *services.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// GetJobs godoc
+// @Summary List background jobs
+// @Description List background jobs, newest first. Use status=dead for the dead letter queue.
+// @Tags admin
+// @Produce json
+// @Param status query string false "pending, running, succeeded or dead"
+// @Param type query string false "Job type, e.g. data_source.discover or schema_sync.all"
+// @Param page query int false "Page" default(1)
+// @Param limit query int false "Page size (max 100)" default(20)
+// @Success 200 {object} entity.StandardResponse{data=[]entity.Job}
+// @Failure 500 {object} entity.StandardResponse
+// @Security BearerAuth
+// @Router /admin/jobs [get]
+func (h *JobHandler) GetJobs(c *fiber.Ctx) error {
+	filter := entity.JobListFilter{
+		Status: entity.JobStatus(c.Query("status")),
+		Type:   c.Query("type"),
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+	}
+
+	jobs, total, err := h.jobService.ListJobs(&filter)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve jobs", err.Error())
+	}
+
+	meta := &entity.Meta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      int(total),
+		TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+	}
+	return entity.SuccessResponseWithMeta(c, "Jobs retrieved successfully", jobs, meta)
+}
+
+// GetStats godoc
+// @Summary Background job counts
+// @Description Count the background jobs in each status
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entity.StandardResponse{data=entity.JobStats}
+// @Failure 500 {object} entity.StandardResponse
+// @Security BearerAuth
+// @Router /admin/jobs/stats [get]
+func (h *JobHandler) GetStats(c *fiber.Ctx) error {
+	stats, err := h.jobService.GetStats()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to count jobs", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Job stats retrieved successfully", stats)
+}
+
+// GetJob godoc
+// @Summary Get a background job
+// @Description Get a background job with its attempts and last error
+// @Tags admin
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} entity.StandardResponse{data=entity.Job}
+// @Failure 400 {object} entity.StandardResponse
+// @Failure 404 {object} entity.StandardResponse
+// @Security BearerAuth
+// @Router /admin/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid job ID", err.Error())
+	}
+
+	job, err := h.jobService.GetJob(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrJobNotFound) {
+			return entity.NotFoundResponse(c, "Job not found")
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve job", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Job retrieved successfully", job)
+}
+
+// RetryJob godoc
+// @Summary Retry a dead job
+// @Description Move a dead-lettered job back to the queue with a fresh set of attempts
+// @Tags admin
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} entity.StandardResponse{data=entity.Job}
+// @Failure 400 {object} entity.StandardResponse
+// @Failure 404 {object} entity.StandardResponse
+// @Failure 409 {object} entity.StandardResponse
+// @Security BearerAuth
+// @Router /admin/jobs/{id}/retry [post]
+func (h *JobHandler) RetryJob(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid job ID", err.Error())
+	}
+
+	job, err := h.jobService.RetryJob(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrJobNotFound):
+			return entity.NotFoundResponse(c, "Job not found")
+		case errors.Is(err, services.ErrJobNotRetryable):
+			return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to retry job", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Job queued for retry", job)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type KPIHandler struct {
+	kpiService *services.KPIService
+	validator  *validator.Validate
+}
+
+func NewKPIHandler(kpiService *services.KPIService) *KPIHandler {
+	return &KPIHandler{
+		kpiService: kpiService,
+		validator:  validator.New(),
+	}
+}
+
+// ComputeKPI godoc
+// @Summary Compute a KPI
+// @Description Run the formula of a KPI on its data source for a date range, with a series per grain (daily, weekly or monthly) and optionally the prior period of the same length with the percent change
+// @Tags kpis
+// @Accept json
+// @Produce json
+// @Param id path int true "KPI ID"
+// @Param compute body models.KPIComputeRequest false "Range, grain and comparison"
+// @Success 200 {object} models.StandardResponse{data=models.KPIComputeResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /kpis/{id}/compute [post]
+func (h *KPIHandler) ComputeKPI(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid KPI ID", err.Error())
+	}
+
+	var req entity.KPIComputeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	result, err := h.kpiService.WithContext(c.UserContext()).Compute(userID, uint(id), &req)
+	if err != nil {
+		return kpiErrorResponse(c, "Failed to compute KPI", err)
+	}
+
+	return entity.SuccessResponse(c, "KPI computed successfully", result)
+}
+
+// kpiErrorResponse maps KPI service errors to responses
+func kpiErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrKPINotFound):
+		return entity.NotFoundResponse(c, "KPI not found")
+	case errors.Is(err, services.ErrInvalidKPICompute),
+		errors.Is(err, services.ErrQueryCostExceeded):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
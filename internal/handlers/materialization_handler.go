@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MaterializationHandler struct {
+	materializationService *services.MaterializationService
+}
+
+func NewMaterializationHandler(materializationService *services.MaterializationService) *MaterializationHandler {
+	return &MaterializationHandler{
+		materializationService: materializationService,
+	}
+}
+
+// GetMaterializations godoc
+// @Summary Get the materialized tables of a data source
+// @Description Get the local copies Google Sheets and REST API data sources are queried through, with the row count, watermark and status of their last refresh, and the freshness of the data queries run on
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse{data=models.DataSourceMaterializationsResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/materializations [get]
+func (h *MaterializationHandler) GetMaterializations(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	materializations, err := h.materializationService.WithContext(c.UserContext()).List(userID, uint(id))
+	if err != nil {
+		return materializationErrorResponse(c, "Failed to get materializations", err)
+	}
+
+	return entity.SuccessResponse(c, "Materializations retrieved successfully", materializations)
+}
+
+// RefreshMaterializations godoc
+// @Summary Refresh the materialized tables of a data source
+// @Description Queue a refresh of the local copy of a data source now rather than at its refresh interval. REST APIs with an incremental column only pull records past the watermark unless full is set.
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param refresh body models.MaterializationRefreshRequest false "Refresh options"
+// @Success 202 {object} models.StandardResponse{data=models.DataSourceMaterializationsResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/materializations/refresh [post]
+func (h *MaterializationHandler) RefreshMaterializations(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.MaterializationRefreshRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+
+	materializations, err := h.materializationService.WithContext(c.UserContext()).QueueRefresh(c.UserContext(), userID, uint(id), req.Full)
+	if err != nil {
+		return materializationErrorResponse(c, "Failed to refresh materializations", err)
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return entity.SuccessResponse(c, "Materialization refresh queued", materializations)
+}
+
+func materializationErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrMaterializationDataSourceNotFound):
+		return entity.NotFoundResponse(c, "Data source not found")
+	case errors.Is(err, services.ErrNotMaterialized):
+		return entity.BadRequestResponse(c, message, err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
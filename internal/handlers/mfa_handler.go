@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/config"
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+	"narapulse-be/internal/repositories"
+	"narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type MFAHandler struct {
+	mfaService   *services.MFAService
+	userService  services.UserService
+	auditService *services.AuditService
+	validator    *validator.Validate
+	config       *config.Config
+}
+
+func NewMFAHandler(db *gorm.DB, mfaService *services.MFAService, auditService *services.AuditService) *MFAHandler {
+	userRepo := repositories.NewUserRepository(db)
+	return &MFAHandler{
+		mfaService:   mfaService,
+		userService:  services.NewUserService(userRepo),
+		auditService: auditService,
+		validator:    validator.New(),
+		config:       config.Load(),
+	}
+}
+
+// Verify godoc
+// @Summary Complete a login with MFA
+// @Description Exchange the MFA token returned by the login and a TOTP or recovery code for an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFAVerifyRequest true "MFA token and code"
+// @Success 200 {object} models.StandardResponse{data=models.LoginResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Router /auth/mfa/verify [post]
+func (h *MFAHandler) Verify(c *fiber.Ctx) error {
+	var req entity.MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	claims, err := utils.ValidateToken(req.MFAToken, h.config.JWTSecret)
+	if err != nil || claims.Purpose != utils.TokenPurposeMFA {
+		return entity.UnauthorizedResponse(c, "Invalid or expired MFA token")
+	}
+
+	if err := h.mfaService.WithContext(c.UserContext()).Verify(claims.UserID, req.Code); err != nil {
+		if errors.Is(err, services.ErrMFAInvalidCode) || errors.Is(err, services.ErrMFANotEnrolled) {
+			return entity.UnauthorizedResponse(c, services.ErrMFAInvalidCode.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to verify MFA code", err.Error())
+	}
+
+	user, err := h.userService.WithContext(c.UserContext()).GetUserByID(claims.UserID)
+	if err != nil || !user.IsActive {
+		return entity.UnauthorizedResponse(c, "Invalid or expired MFA token")
+	}
+
+	token, err := utils.GenerateToken(user.ID, user.TenantID, user.Email, user.Role, h.config.JWTSecret)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to generate token", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Login successful", entity.LoginResponse{
+		Token: token,
+		User:  newUserResponse(user),
+	})
+}
+
+// GetStatus godoc
+// @Summary Get MFA status
+// @Description Whether MFA is enabled and required for the authenticated user, and how many recovery codes are left
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.MFAStatus}
+// @Failure 401 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /auth/mfa [get]
+func (h *MFAHandler) GetStatus(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return entity.UnauthorizedResponse(c, "User not found")
+	}
+
+	status, err := h.mfaService.WithContext(c.UserContext()).Status(user)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get MFA status", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "MFA status retrieved successfully", status)
+}
+
+// BeginEnrollment godoc
+// @Summary Start MFA enrollment
+// @Description Generate a TOTP secret for an authenticator app. MFA is enabled once a code from it is confirmed. Accepts the enrollment token returned by the login.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.MFAEnrollment}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /auth/mfa/enroll [post]
+func (h *MFAHandler) BeginEnrollment(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return entity.UnauthorizedResponse(c, "User not found")
+	}
+
+	enrollment, err := h.mfaService.WithContext(c.UserContext()).BeginEnrollment(user)
+	if err != nil {
+		if errors.Is(err, services.ErrMFAAlreadyEnabled) {
+			return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to start MFA enrollment", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Add the secret to an authenticator app and confirm a code", enrollment)
+}
+
+// ConfirmEnrollment godoc
+// @Summary Confirm MFA enrollment
+// @Description Enable MFA with a code from the authenticator app. Returns recovery codes, shown only once, and an access token when the enrollment completes a login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP code"
+// @Success 200 {object} models.StandardResponse{data=models.MFAEnrollmentConfirmation}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /auth/mfa/enroll/confirm [post]
+func (h *MFAHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return entity.UnauthorizedResponse(c, "User not found")
+	}
+
+	var req entity.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	codes, err := h.mfaService.WithContext(c.UserContext()).ConfirmEnrollment(user.ID, req.Code)
+	if err != nil {
+		return mfaErrorResponse(c, "Failed to confirm MFA enrollment", err)
+	}
+
+	confirmation := entity.MFAEnrollmentConfirmation{RecoveryCodes: codes}
+	if enrolling, _ := c.Locals("mfa_enrollment").(bool); enrolling {
+		confirmation.Token, err = utils.GenerateToken(user.ID, user.TenantID, user.Email, user.Role, h.config.JWTSecret)
+		if err != nil {
+			return entity.InternalServerErrorResponse(c, "Failed to generate token", err.Error())
+		}
+	}
+
+	return entity.SuccessResponse(c, "MFA enabled successfully", confirmation)
+}
+
+// Disable godoc
+// @Summary Disable MFA
+// @Description Turn MFA off with a current TOTP or recovery code. Not allowed when MFA is required for the user.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return entity.UnauthorizedResponse(c, "User not found")
+	}
+
+	var req entity.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	if err := h.mfaService.WithContext(c.UserContext()).Disable(user, req.Code); err != nil {
+		return mfaErrorResponse(c, "Failed to disable MFA", err)
+	}
+
+	return entity.SuccessResponse(c, "MFA disabled successfully", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate MFA recovery codes
+// @Description Replace the recovery codes with new ones, shown only once, after checking a current TOTP code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP code"
+// @Success 200 {object} models.StandardResponse{data=models.MFARecoveryCodes}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	codes, err := h.mfaService.WithContext(c.UserContext()).RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return mfaErrorResponse(c, "Failed to regenerate recovery codes", err)
+	}
+
+	return entity.SuccessResponse(c, "Recovery codes regenerated successfully", entity.MFARecoveryCodes{RecoveryCodes: codes})
+}
+
+// SetUserRequirement godoc
+// @Summary Require MFA for a user
+// @Description Set whether a user must use MFA; a user without MFA set up is asked to enroll at the next login
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body models.MFAUserRequirementRequest true "Requirement"
+// @Success 200 {object} models.StandardResponse{data=models.UserMFA}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/mfa [put]
+func (h *MFAHandler) SetUserRequirement(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	var req entity.MFAUserRequirementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if _, err := h.userService.WithContext(c.UserContext()).GetUserByID(uint(id)); err != nil {
+		return entity.NotFoundResponse(c, "User not found")
+	}
+
+	mfa, err := h.mfaService.WithContext(c.UserContext()).SetUserRequired(uint(id), req.Required)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to set MFA requirement", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionMFAChange, "user", uint(id), nil, req, nil)
+
+	return entity.SuccessResponse(c, "MFA requirement updated successfully", mfa)
+}
+
+// ResetUser godoc
+// @Summary Reset MFA of a user
+// @Description Turn MFA off and remove the secret and recovery codes of a user, e.g. after a lost device. A user required to use MFA enrolls again at the next login.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/mfa [delete]
+func (h *MFAHandler) ResetUser(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	if err := h.mfaService.WithContext(c.UserContext()).Reset(uint(id)); err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to reset MFA", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionMFAChange, "user", uint(id), nil, nil, map[string]interface{}{"reset": true})
+
+	return entity.SuccessResponse(c, "MFA reset successfully", nil)
+}
+
+// GetSettings godoc
+// @Summary Get workspace MFA settings
+// @Description Whether MFA is required for every user or for admins
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.MFASettings}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/mfa-settings [get]
+func (h *MFAHandler) GetSettings(c *fiber.Ctx) error {
+	settings, err := h.mfaService.WithContext(c.UserContext()).GetSettings()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get MFA settings", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "MFA settings retrieved successfully", settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update workspace MFA settings
+// @Description Require MFA for every user or for admins; users without MFA set up are asked to enroll at the next login
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param settings body models.MFASettingsRequest true "Enforcement flags"
+// @Success 200 {object} models.StandardResponse{data=models.MFASettings}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/mfa-settings [put]
+func (h *MFAHandler) UpdateSettings(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.MFASettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	before, err := h.mfaService.WithContext(c.UserContext()).GetSettings()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get MFA settings", err.Error())
+	}
+	beforeSnapshot := *before
+
+	settings, err := h.mfaService.WithContext(c.UserContext()).UpdateSettings(&req, adminID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to update MFA settings", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionMFAChange, "mfa_settings", settings.ID, beforeSnapshot, settings, nil)
+
+	return entity.SuccessResponse(c, "MFA settings updated successfully", settings)
+}
+
+// currentUser loads the authenticated user
+func (h *MFAHandler) currentUser(c *fiber.Ctx) (*entity.User, error) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.userService.WithContext(c.UserContext()).GetUserByID(userID)
+}
+
+// mfaErrorResponse maps MFA service errors to responses
+func mfaErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrMFAInvalidCode), errors.Is(err, services.ErrMFANotEnrolled):
+		return entity.BadRequestResponse(c, err.Error(), nil)
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrMFARequired):
+		return entity.ForbiddenResponse(c, err.Error())
+	default:
+		return entity.InternalServerErrorResponse(c, message, err.Error())
+	}
+}
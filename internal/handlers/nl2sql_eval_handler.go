@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type NL2SQLEvalHandler struct {
+	evalService *services.NL2SQLEvalService
+	validator   *validator.Validate
+}
+
+func NewNL2SQLEvalHandler(evalService *services.NL2SQLEvalService) *NL2SQLEvalHandler {
+	return &NL2SQLEvalHandler{
+		evalService: evalService,
+		validator:   validator.New(),
+	}
+}
+
+// GetGoldenQueries godoc
+// @Summary List golden queries (Admin only)
+// @Description List the natural language questions with their expected SQL used to evaluate NL2SQL accuracy
+// @Tags admin
+// @Produce json
+// @Param data_source_id query int false "Only the golden queries of this data source"
+// @Success 200 {object} models.StandardResponse{data=[]models.GoldenQuery}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/golden-queries [get]
+func (h *NL2SQLEvalHandler) GetGoldenQueries(c *fiber.Ctx) error {
+	dataSourceID, err := strconv.ParseUint(c.Query("data_source_id", "0"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	goldens, err := h.evalService.WithContext(c.UserContext()).ListGoldenQueries(uint(dataSourceID))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve golden queries", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Golden queries retrieved successfully", goldens)
+}
+
+// CreateGoldenQuery godoc
+// @Summary Create a golden query (Admin only)
+// @Description Add a natural language question with the SQL known to answer it. The expected SQL must pass the validation policy of the data source.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param golden_query body models.GoldenQueryRequest true "Golden query"
+// @Success 201 {object} models.StandardResponse{data=models.GoldenQuery}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/golden-queries [post]
+func (h *NL2SQLEvalHandler) CreateGoldenQuery(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.GoldenQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	golden, err := h.evalService.WithContext(c.UserContext()).CreateGoldenQuery(adminID, &req)
+	if err != nil {
+		return nl2sqlEvalErrorResponse(c, "Failed to create golden query", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Golden query created successfully",
+		Data:    golden,
+	})
+}
+
+// UpdateGoldenQuery godoc
+// @Summary Update a golden query (Admin only)
+// @Description Replace a golden query; results of past runs are not changed
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Golden query ID"
+// @Param golden_query body models.GoldenQueryRequest true "Golden query"
+// @Success 200 {object} models.StandardResponse{data=models.GoldenQuery}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/golden-queries/{id} [put]
+func (h *NL2SQLEvalHandler) UpdateGoldenQuery(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid golden query ID", err.Error())
+	}
+
+	var req entity.GoldenQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	golden, err := h.evalService.WithContext(c.UserContext()).UpdateGoldenQuery(uint(id), &req)
+	if err != nil {
+		return nl2sqlEvalErrorResponse(c, "Failed to update golden query", err)
+	}
+
+	return entity.SuccessResponse(c, "Golden query updated successfully", golden)
+}
+
+// DeleteGoldenQuery godoc
+// @Summary Delete a golden query (Admin only)
+// @Description Remove a golden query; results of past runs are kept
+// @Tags admin
+// @Produce json
+// @Param id path int true "Golden query ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/golden-queries/{id} [delete]
+func (h *NL2SQLEvalHandler) DeleteGoldenQuery(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid golden query ID", err.Error())
+	}
+
+	if err := h.evalService.WithContext(c.UserContext()).DeleteGoldenQuery(uint(id)); err != nil {
+		return nl2sqlEvalErrorResponse(c, "Failed to delete golden query", err)
+	}
+
+	return entity.SuccessResponse(c, "Golden query deleted successfully", nil)
+}
+
+// StartRun godoc
+// @Summary Run an NL2SQL evaluation (Admin only)
+// @Description Queue a run of the generator over the active golden queries of a data source. Both the generated and the expected query are executed and their result sets compared.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param run body models.EvalRunRequest true "Data source and a label for what changed"
+// @Success 202 {object} models.StandardResponse{data=models.EvalRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/runs [post]
+func (h *NL2SQLEvalHandler) StartRun(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.EvalRunRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	run, err := h.evalService.WithContext(c.UserContext()).StartRun(c.UserContext(), adminID, &req)
+	if err != nil {
+		return nl2sqlEvalErrorResponse(c, "Failed to start evaluation run", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Evaluation run queued",
+		Data:    run,
+	})
+}
+
+// GetRuns godoc
+// @Summary List NL2SQL evaluation runs (Admin only)
+// @Description List evaluation runs with their accuracy metrics, newest first, to compare prompt and model changes over time
+// @Tags admin
+// @Produce json
+// @Param data_source_id query int false "Only the runs of this data source"
+// @Param limit query int false "Number of runs (default 50)"
+// @Success 200 {object} models.StandardResponse{data=[]models.EvalRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/runs [get]
+func (h *NL2SQLEvalHandler) GetRuns(c *fiber.Ctx) error {
+	dataSourceID, err := strconv.ParseUint(c.Query("data_source_id", "0"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	runs, err := h.evalService.WithContext(c.UserContext()).ListRuns(uint(dataSourceID), c.QueryInt("limit", 0))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve evaluation runs", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Evaluation runs retrieved successfully", runs)
+}
+
+// GetRun godoc
+// @Summary Get an NL2SQL evaluation run (Admin only)
+// @Description Get an evaluation run with the generated SQL and outcome of each golden query
+// @Tags admin
+// @Produce json
+// @Param id path int true "Run ID"
+// @Success 200 {object} models.StandardResponse{data=models.EvalRunDetailResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/nl2sql-eval/runs/{id} [get]
+func (h *NL2SQLEvalHandler) GetRun(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid run ID", err.Error())
+	}
+
+	run, err := h.evalService.WithContext(c.UserContext()).GetRun(uint(id))
+	if err != nil {
+		return nl2sqlEvalErrorResponse(c, "Failed to retrieve evaluation run", err)
+	}
+
+	return entity.SuccessResponse(c, "Evaluation run retrieved successfully", run)
+}
+
+// nl2sqlEvalErrorResponse maps evaluation service errors to responses
+func nl2sqlEvalErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrGoldenQueryNotFound):
+		return entity.NotFoundResponse(c, "Golden query not found")
+	case errors.Is(err, services.ErrEvalRunNotFound):
+		return entity.NotFoundResponse(c, "Evaluation run not found")
+	case errors.Is(err, services.ErrEvalDataSource):
+		return entity.NotFoundResponse(c, "Data source not found or not active")
+	case errors.Is(err, services.ErrInvalidGoldenSQL), errors.Is(err, services.ErrNoActiveGoldenQueries):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
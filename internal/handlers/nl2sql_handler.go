@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
+	"narapulse-be/internal/middleware"
 	models "narapulse-be/internal/models/entity"
 	"narapulse-be/internal/services"
 
@@ -12,129 +19,267 @@ import (
 // NL2SQLHandler handles NL2SQL related HTTP requests
 type NL2SQLHandler struct {
 	nl2sqlService *services.NL2SQLService
+	auditService  *services.AuditService
 }
 
 // NewNL2SQLHandler creates a new NL2SQL handler
-func NewNL2SQLHandler(nl2sqlService *services.NL2SQLService) *NL2SQLHandler {
+func NewNL2SQLHandler(nl2sqlService *services.NL2SQLService, auditService *services.AuditService) *NL2SQLHandler {
 	return &NL2SQLHandler{
 		nl2sqlService: nl2sqlService,
+		auditService:  auditService,
 	}
 }
 
 // ConvertNL2SQL handles natural language to SQL conversion
+// @Summary Convert a question to SQL
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param request body models.NL2SQLRequest true "Question"
+// @Success 200 {object} models.StandardResponse{data=models.NL2SQLResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/convert [post]
 func (h *NL2SQLHandler) ConvertNL2SQL(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		return models.UnauthorizedResponse(c, "User not authenticated")
 	}
 
 	// Parse request body
 	var request models.NL2SQLRequest
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request format: " + err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate required fields
 	if request.NLQuery == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Natural language query is required",
-		})
+		return models.BadRequestResponse(c, "Natural language query is required", nil)
 	}
 
 	if request.DataSourceID == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Data source ID is required",
-		})
+		return models.BadRequestResponse(c, "Data source ID is required", nil)
 	}
 
 	// Convert NL to SQL
-	response, err := h.nl2sqlService.ConvertNL2SQL(userID.(uint), &request)
+	response, err := h.nl2sqlService.WithContext(c.UserContext()).ConvertNL2SQL(userID.(uint), &request)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to convert query: " + err.Error(),
-		})
+		if errors.Is(err, services.ErrUsageQuotaExceeded) {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeUsageQuotaExceeded, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrParentQueryNotFound) {
+			return models.NotFoundResponse(c, "Parent query not found")
+		}
+		if errors.Is(err, services.ErrFederationInvalid) {
+			return models.BadRequestResponse(c, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrFeatureDisabled) {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeFeatureDisabled, "This feature is not enabled for your account", err.Error())
+		}
+		return models.InternalServerErrorResponse(c, "Failed to convert query", err.Error())
+	}
+
+	return models.SuccessResponse(c, "Query converted successfully", response)
+}
+
+// AnswerQuestion handles questions answered with a single sentence instead of a table
+// @Summary Answer a question with one sentence
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param request body models.NL2SQLAnswerRequest true "Question"
+// @Success 200 {object} models.StandardResponse{data=models.NL2SQLAnswerResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/answer [post]
+func (h *NL2SQLHandler) AnswerQuestion(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	// Parse request body
+	var request models.NL2SQLAnswerRequest
+	if err := c.BodyParser(&request); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	// Validate required fields
+	if request.NLQuery == "" {
+		return models.BadRequestResponse(c, "Natural language query is required", nil)
+	}
+
+	if request.DataSourceID == 0 {
+		return models.BadRequestResponse(c, "Data source ID is required", nil)
+	}
+
+	response, err := h.nl2sqlService.WithContext(c.UserContext()).AnswerQuestion(userID.(uint), &request)
+	if err != nil {
+		if errors.Is(err, services.ErrUsageQuotaExceeded) {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeUsageQuotaExceeded, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrQueryCostExceeded) {
+			return models.BadRequestResponse(c, err.Error(), nil)
+		}
+		return models.InternalServerErrorResponse(c, "Failed to answer question", err.Error())
+	}
+
+	// A stored result or an answer means the query ran
+	if response.ResultID != 0 || response.Answered {
+		h.auditService.Log(middleware.GetAuditActor(c), models.AuditActionQueryExecute, "nl2sql_query", response.QueryID, nil, nil,
+			map[string]interface{}{"sql": response.GeneratedSQL, "result_id": response.ResultID, "source": "answer"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"data":    response,
+	return models.SuccessResponse(c, "Question answered successfully", response)
+}
+
+// StreamNL2SQL handles conversion and execution reported as Server-Sent Events:
+// one event per stage, with result rows sent in batches as they are stored
+// @Summary Convert and execute a question with live progress
+// @Tags nl2sql
+// @Accept json
+// @Produce text/event-stream
+// @Param request body models.NL2SQLStreamRequest true "Question"
+// @Success 200 {string} string "Server-Sent Events"
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/stream [post]
+func (h *NL2SQLHandler) StreamNL2SQL(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	// Parse request body
+	var request models.NL2SQLStreamRequest
+	if err := c.BodyParser(&request); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	request.UnmaskPII = middleware.CanUnmaskPII(c)
+
+	// Validate required fields
+	if request.NLQuery == "" {
+		return models.BadRequestResponse(c, "Natural language query is required", nil)
+	}
+
+	if request.DataSourceID == 0 {
+		return models.BadRequestResponse(c, "Data source ID is required", nil)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	uid := userID.(uint)
+	actor := middleware.GetAuditActor(c)
+	ctx := c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		disconnected := false
+		h.nl2sqlService.WithContext(ctx).StreamNL2SQL(uid, &request, func(event models.NL2SQLProgressEvent) {
+			if event.Execution != nil {
+				h.auditExecution(ctx, actor, uid, event.Execution)
+			}
+			if disconnected {
+				return
+			}
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Stage, data)
+			if err := w.Flush(); err != nil {
+				// The client went away; the query still finishes and is stored
+				disconnected = true
+			}
+		})
 	})
+
+	return nil
 }
 
 // ExecuteQuery handles SQL query execution
+// @Summary Execute a generated query
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param request body models.QueryExecutionRequest true "Execution"
+// @Success 200 {object} models.StandardResponse{data=models.QueryExecutionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/execute [post]
 func (h *NL2SQLHandler) ExecuteQuery(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		return models.UnauthorizedResponse(c, "User not authenticated")
 	}
 
 	// Parse request body
 	var request models.QueryExecutionRequest
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request format: " + err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
+	request.UnmaskPII = middleware.CanUnmaskPII(c)
 
 	// Validate required fields
 	if request.QueryID == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Query ID is required",
-		})
+		return models.BadRequestResponse(c, "Query ID is required", nil)
+	}
+	if request.PageSize < 0 || request.PageSize > 1000 {
+		return models.BadRequestResponse(c, "Page size must be between 1 and 1000", nil)
 	}
 
 	// Execute query
-	response, err := h.nl2sqlService.ExecuteQuery(userID.(uint), &request)
+	response, err := h.nl2sqlService.WithContext(c.UserContext()).ExecuteQuery(userID.(uint), &request)
 	if err != nil {
 		if err.Error() == "query not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Query not found",
-			})
+			return models.NotFoundResponse(c, "Query not found")
 		}
 		if err.Error() == "query is not executable" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Query is not executable",
-			})
+			return models.BadRequestResponse(c, "Query is not executable", nil)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to execute query: " + err.Error(),
-		})
+		if errors.Is(err, services.ErrQueryCostExceeded) || errors.Is(err, services.ErrInvalidQueryParameters) ||
+			errors.Is(err, services.ErrUnsupportedCurrency) || errors.Is(err, services.ErrFederationInvalid) {
+			return models.BadRequestResponse(c, err.Error(), nil)
+		}
+		return models.InternalServerErrorResponse(c, "Failed to execute query", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"data":    response,
-	})
+	h.auditExecution(c.UserContext(), middleware.GetAuditActor(c), userID.(uint), response)
+
+	return models.SuccessResponse(c, "Query executed successfully", response)
 }
 
 // GetQueryHistory handles getting query history
+// @Summary List query history
+// @Tags nl2sql
+// @Produce json
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} models.StandardResponse{data=[]models.QueryHistoryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/history [get]
 func (h *NL2SQLHandler) GetQueryHistory(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		return models.UnauthorizedResponse(c, "User not authenticated")
 	}
 
 	// Parse query parameters
@@ -143,18 +288,12 @@ func (h *NL2SQLHandler) GetQueryHistory(c *fiber.Ctx) error {
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid limit parameter",
-		})
+		return models.BadRequestResponse(c, "Invalid limit parameter", nil)
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid offset parameter",
-		})
+		return models.BadRequestResponse(c, "Invalid offset parameter", nil)
 	}
 
 	// Limit maximum results to prevent abuse
@@ -163,135 +302,637 @@ func (h *NL2SQLHandler) GetQueryHistory(c *fiber.Ctx) error {
 	}
 
 	// Get query history
-	history, err := h.nl2sqlService.GetQueryHistory(userID.(uint), limit, offset)
+	history, err := h.nl2sqlService.WithContext(c.UserContext()).GetQueryHistory(userID.(uint), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to get query history: " + err.Error(),
-		})
+		return models.InternalServerErrorResponse(c, "Failed to get query history", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"data":    history,
-	})
+	return models.SuccessResponse(c, "Query history retrieved successfully", history)
+}
+
+// Autocomplete handles suggesting the tables, columns, KPIs and glossary terms
+// matching what the user is typing
+// @Summary Autocomplete a question
+// @Tags nl2sql
+// @Produce json
+// @Param request query models.AutocompleteRequest true "Prefix and data source"
+// @Success 200 {object} models.StandardResponse{data=[]models.AutocompleteSuggestion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/autocomplete [get]
+func (h *NL2SQLHandler) Autocomplete(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req models.AutocompleteRequest
+	if err := c.QueryParser(&req); err != nil {
+		return models.BadRequestResponse(c, "Invalid autocomplete parameters", err.Error())
+	}
+	if req.DataSourceID == 0 {
+		return models.BadRequestResponse(c, "Data source ID is required", nil)
+	}
+	if strings.TrimSpace(req.Prefix) == "" || len(req.Prefix) > 100 {
+		return models.BadRequestResponse(c, "Prefix is required and must be at most 100 characters", nil)
+	}
+
+	suggestions, err := h.nl2sqlService.WithContext(c.UserContext()).Autocomplete(userID.(uint), &req)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrAutocompleteDataSourceNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return models.ErrorResponseWithStatus(c, status, err.Error(), nil)
+	}
+
+	return models.SuccessResponse(c, "Suggestions retrieved successfully", suggestions)
 }
 
 // ValidateSQL handles SQL validation without execution
+// @Summary Validate SQL without executing it
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param request body object true "sql, and optionally dialect and data_source_id"
+// @Success 200 {object} models.StandardResponse{data=models.SQLValidationResult}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/validate [post]
 func (h *NL2SQLHandler) ValidateSQL(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		return models.UnauthorizedResponse(c, "User not authenticated")
 	}
 
 	// Parse request body
-	var request map[string]string
+	var request struct {
+		SQL          string `json:"sql"`
+		Dialect      string `json:"dialect"`
+		DataSourceID uint   `json:"data_source_id"`
+	}
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request format: " + err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
-	sql, exists := request["sql"]
-	if !exists || sql == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "SQL query is required",
-		})
+	sql := request.SQL
+	if sql == "" {
+		return models.BadRequestResponse(c, "SQL query is required", nil)
 	}
 
-	// Create SQL validator service
-	validator := services.NewSQLValidatorService()
+	// Use the requested dialect, defaulting to PostgreSQL
+	dialect := models.SQLDialect(request.Dialect)
+	if dialect == "" {
+		dialect = models.SQLDialectPostgreSQL
+	}
+	if !dialect.IsValid() {
+		return models.BadRequestResponse(c, "Unsupported SQL dialect: " + request.Dialect, nil)
+	}
 
-	// Validate SQL
-	result, err := validator.ValidateSQL(sql)
+	// Validate SQL, applying the data source dialect and validation policy when one is given
+	var result *models.SQLValidationResult
+	var err error
+	if request.DataSourceID != 0 {
+		result, err = h.nl2sqlService.WithContext(c.UserContext()).ValidateSQLForDataSource(userID.(uint), request.DataSourceID, sql)
+	} else {
+		result, err = services.NewSQLValidatorService().ValidateSQLForDialect(sql, dialect)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to validate SQL: " + err.Error(),
-		})
+		return models.InternalServerErrorResponse(c, "Failed to validate SQL", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"data":    result,
-	})
+	return models.SuccessResponse(c, "SQL validated successfully", result)
 }
 
 // GetQueryDetails handles getting detailed information about a specific query
+// @Summary Get a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=models.NL2SQLQuery}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id} [get]
 func (h *NL2SQLHandler) GetQueryDetails(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		return models.UnauthorizedResponse(c, "User not authenticated")
 	}
 
 	// Parse query ID from path
 	queryIDStr := c.Params("id")
 	queryIDUint, err := strconv.ParseUint(queryIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid query ID",
-		})
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
 	}
 
 	// Get query details
-	query, err := h.nl2sqlService.GetQueryDetails(userID.(uint), uint(queryIDUint))
+	query, err := h.nl2sqlService.WithContext(c.UserContext()).GetQueryDetails(userID.(uint), uint(queryIDUint))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": err.Error(),
-		})
+		return models.NotFoundResponse(c, err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Query details retrieved successfully",
-		"data":    query,
-	})
+	return models.SuccessResponse(c, "Query details retrieved successfully", query)
+}
+
+// GetQueryTrace handles getting the retrieval trace of a query: the retrieved
+// elements with their scores, the final prompt, the model settings and the
+// validation output, with secrets redacted. It is gated by the RAG trace
+// permission and covers the queries of all users, so each access is audited.
+// @Summary Get the RAG trace of a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=models.NL2SQLQueryTrace}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/trace [get]
+func (h *NL2SQLHandler) GetQueryTrace(c *fiber.Ctx) error {
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	trace, err := h.nl2sqlService.WithContext(c.UserContext()).GetQueryTrace(uint(queryID))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrQueryNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return models.ErrorResponseWithStatus(c, status, err.Error(), nil)
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), models.AuditActionQueryTrace, "nl2sql_query", trace.QueryID, nil, nil,
+		map[string]interface{}{"query_user_id": trace.UserID, "data_source_id": trace.DataSourceID})
+
+	return models.SuccessResponse(c, "Query trace retrieved successfully", trace)
+}
+
+// GetQueryResults handles paging through the stored results of a query
+// @Summary Page through the stored results of a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param request query models.QueryResultPageRequest false "Page"
+// @Success 200 {object} models.StandardResponse{data=models.QueryResultPage}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/results [get]
+func (h *NL2SQLHandler) GetQueryResults(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	// Parse query ID from path
+	queryIDStr := c.Params("id")
+	queryIDUint, err := strconv.ParseUint(queryIDStr, 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	var req models.QueryResultPageRequest
+	if err := c.QueryParser(&req); err != nil {
+		return models.BadRequestResponse(c, "Invalid pagination parameters", err.Error())
+	}
+	req.UnmaskPII = middleware.CanUnmaskPII(c)
+
+	page, err := h.nl2sqlService.WithContext(c.UserContext()).GetQueryResults(userID.(uint), uint(queryIDUint), &req)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case err.Error() == "query not found" || errors.Is(err, services.ErrQueryResultNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, services.ErrInvalidResultCursor):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, services.ErrQueryResultArchived):
+			status = fiber.StatusConflict
+		}
+		return models.ErrorResponseWithStatus(c, status, err.Error(), nil)
+	}
+
+	return models.SuccessResponse(c, "Query results retrieved successfully", page)
+}
+
+// TransformQueryResults handles reshaping a stored result with group-by,
+// pivot, sort and top-N operations
+// @Summary Transform the stored results of a query
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param request body models.ResultTransformRequest true "Transform"
+// @Success 200 {object} models.StandardResponse{data=models.ResultTransformResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/results/transform [post]
+func (h *NL2SQLHandler) TransformQueryResults(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	// Parse query ID from path
+	queryIDUint, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	var req models.ResultTransformRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	req.UnmaskPII = middleware.CanUnmaskPII(c)
+
+	result, err := h.nl2sqlService.WithContext(c.UserContext()).TransformQueryResult(userID.(uint), uint(queryIDUint), &req)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case err.Error() == "query not found" || errors.Is(err, services.ErrQueryResultNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, services.ErrResultTransformInvalid), errors.Is(err, services.ErrQueryResultTooLarge):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, services.ErrQueryResultArchived):
+			status = fiber.StatusConflict
+		}
+		return models.ErrorResponseWithStatus(c, status, err.Error(), nil)
+	}
+
+	return models.SuccessResponse(c, "Query results transformed successfully", result)
 }
 
 // DeleteQuery handles deleting a query from history
+// @Summary Delete a query from history
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id} [delete]
 func (h *NL2SQLHandler) DeleteQuery(c *fiber.Ctx) error {
 	// Get user ID from context
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not authenticated",
-		})
+		return models.UnauthorizedResponse(c, "User not authenticated")
 	}
 
 	// Parse query ID from path
 	queryIDStr := c.Params("id")
 	queryIDUint, err := strconv.ParseUint(queryIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid query ID",
-		})
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
 	}
 
 	// Delete query
-	err = h.nl2sqlService.DeleteQuery(userID.(uint), uint(queryIDUint))
+	err = h.nl2sqlService.WithContext(c.UserContext()).DeleteQuery(userID.(uint), uint(queryIDUint))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": err.Error(),
-		})
+		return models.NotFoundResponse(c, err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Query deleted successfully",
-	})
-}
\ No newline at end of file
+	return models.SuccessResponse(c, "Query deleted successfully", nil)
+}
+
+// UpdateQuerySQL handles a human edit of a query's SQL
+// @Summary Edit the SQL of a query
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param request body models.QuerySQLUpdateRequest true "SQL"
+// @Success 200 {object} models.StandardResponse{data=models.QuerySQLVersionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/sql [put]
+func (h *NL2SQLHandler) UpdateQuerySQL(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	var request models.QuerySQLUpdateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if request.SQL == "" {
+		return models.BadRequestResponse(c, "SQL query is required", nil)
+	}
+	if len(request.Comment) > 500 {
+		return models.BadRequestResponse(c, "Comment must be at most 500 characters", nil)
+	}
+
+	response, err := h.nl2sqlService.WithContext(c.UserContext()).UpdateQuerySQL(userID.(uint), uint(queryID), &request)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query SQL updated successfully", response)
+}
+
+// RefineQuerySQL handles regenerating the SQL of a query with a correction hint
+// @Summary Regenerate the SQL of a query with a hint
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param request body models.QuerySQLRefineRequest true "Correction hint"
+// @Success 200 {object} models.StandardResponse{data=models.QuerySQLRefineResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/refine [post]
+func (h *NL2SQLHandler) RefineQuerySQL(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	var request models.QuerySQLRefineRequest
+	if err := c.BodyParser(&request); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if strings.TrimSpace(request.Hint) == "" {
+		return models.BadRequestResponse(c, "Hint is required", nil)
+	}
+	if len(request.Hint) > 1000 {
+		return models.BadRequestResponse(c, "Hint must be at most 1000 characters", nil)
+	}
+
+	response, err := h.nl2sqlService.WithContext(c.UserContext()).RefineQuerySQL(userID.(uint), uint(queryID), &request)
+	if err != nil {
+		if errors.Is(err, services.ErrUsageQuotaExceeded) {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeUsageQuotaExceeded, err.Error(), nil)
+		}
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query SQL refined successfully", response)
+}
+
+// GetQueryVersions handles listing the SQL version history of a query
+// @Summary List the SQL versions of a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.QuerySQLVersion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/versions [get]
+func (h *NL2SQLHandler) GetQueryVersions(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	versions, err := h.nl2sqlService.WithContext(c.UserContext()).ListQueryVersions(userID.(uint), uint(queryID))
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query versions retrieved successfully", versions)
+}
+
+// GetQueryLineage handles getting the tree of queries a query belongs to, with their SQL versions
+// @Summary Get the lineage of a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=models.QueryLineage}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/lineage [get]
+func (h *NL2SQLHandler) GetQueryLineage(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	lineage, err := h.nl2sqlService.WithContext(c.UserContext()).GetQueryLineage(userID.(uint), uint(queryID))
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query lineage retrieved successfully", lineage)
+}
+
+// GetQueryVersion handles getting a single SQL version of a query
+// @Summary Get an SQL version of a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param version path int true "SQL version"
+// @Success 200 {object} models.StandardResponse{data=models.QuerySQLVersion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/versions/{version} [get]
+func (h *NL2SQLHandler) GetQueryVersion(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil || version < 1 {
+		return models.BadRequestResponse(c, "Invalid version", nil)
+	}
+
+	result, err := h.nl2sqlService.WithContext(c.UserContext()).GetQueryVersion(userID.(uint), uint(queryID), version)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query version retrieved successfully", result)
+}
+
+// DiffQueryVersions handles diffing two SQL versions of a query
+// @Summary Diff two SQL versions of a query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param from query int true "From version"
+// @Param to query int true "To version"
+// @Success 200 {object} models.StandardResponse{data=models.SQLVersionDiff}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/versions/diff [get]
+func (h *NL2SQLHandler) DiffQueryVersions(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || from < 1 || to < 1 {
+		return models.BadRequestResponse(c, "Query parameters from and to must be version numbers", nil)
+	}
+
+	diff, err := h.nl2sqlService.WithContext(c.UserContext()).DiffQueryVersions(userID.(uint), uint(queryID), from, to)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query versions compared successfully", diff)
+}
+
+// RollbackQuerySQL handles restoring an earlier SQL version of a query
+// @Summary Roll back the SQL of a query
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param version path int true "SQL version"
+// @Param request body models.QuerySQLRollbackRequest false "Canary options"
+// @Success 200 {object} models.StandardResponse{data=models.QuerySQLVersionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse{error=models.ErrorResponse{details=models.CanaryReport}}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/versions/{version}/rollback [post]
+func (h *NL2SQLHandler) RollbackQuerySQL(c *fiber.Ctx) error {
+	// Get user ID from context
+	userID := c.Locals("user_id")
+	if userID == nil {
+		return models.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return models.BadRequestResponse(c, "Invalid query ID", nil)
+	}
+
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil || version < 1 {
+		return models.BadRequestResponse(c, "Invalid version", nil)
+	}
+
+	// The body is optional
+	var request models.QuerySQLRollbackRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+
+	response, err := h.nl2sqlService.WithContext(c.UserContext()).RollbackQuerySQL(userID.(uint), uint(queryID), version, &request)
+	if err != nil {
+		return versionErrorResponse(c, err)
+	}
+
+	return models.SuccessResponse(c, "Query SQL rolled back successfully", response)
+}
+
+// versionErrorResponse maps SQL version errors to HTTP responses
+func versionErrorResponse(c *fiber.Ctx, err error) error {
+	// A diverging canary run is reported with its comparison; resend with confirm to apply
+	var canaryErr *services.CanaryDivergenceError
+	if errors.As(err, &canaryErr) {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeCanaryDivergence, err.Error(), canaryErr.Report)
+	}
+	if err.Error() == "query not found" || errors.Is(err, services.ErrSQLVersionNotFound) {
+		return models.NotFoundResponse(c, err.Error())
+	}
+	return models.BadRequestResponse(c, err.Error(), nil)
+}
+
+// auditExecution records an execution of a query in the audit trail with the
+// SQL that ran: the bound SQL when the query has parameters, else the generated SQL
+func (h *NL2SQLHandler) auditExecution(ctx context.Context, actor models.AuditActor, userID uint, execution *models.QueryExecutionResponse) {
+	details := map[string]interface{}{
+		"sql":               execution.ExecutedSQL,
+		"status":            execution.Status,
+		"row_count":         execution.RowCount,
+		"execution_time_ms": execution.ExecutionTime,
+	}
+	if execution.ResultID != 0 {
+		details["result_id"] = execution.ResultID
+	}
+	if query, err := h.nl2sqlService.WithContext(ctx).GetQueryDetails(userID, execution.QueryID); err == nil {
+		details["data_source_id"] = query.DataSourceID
+		details["nl_query"] = query.NLQuery
+		if execution.ExecutedSQL == "" {
+			details["sql"] = query.GeneratedSQL
+		}
+	}
+
+	h.auditService.Log(actor, models.AuditActionQueryExecute, "nl2sql_query", execution.QueryID, nil, nil, details)
+}
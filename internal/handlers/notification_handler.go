@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/websocket"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// notificationPingInterval keeps notification connections open through proxies and detects closed clients
+const notificationPingInterval = 30 * time.Second
+
+type NotificationHandler struct {
+	notificationHub *services.NotificationHub
+}
+
+func NewNotificationHandler(notificationHub *services.NotificationHub) *NotificationHandler {
+	return &NotificationHandler{
+		notificationHub: notificationHub,
+	}
+}
+
+// Live godoc
+// @Summary Receive notifications over WebSocket
+// @Description Upgrade to a WebSocket that pushes the events of the user's background work as JSON text messages: datasource.discovery_finished, report.completed and alert.triggered. Browsers, which cannot set headers on WebSocket handshakes, pass the access token as the access_token query parameter. Messages of the client are ignored; events while disconnected are not replayed.
+// @Tags notifications
+// @Param access_token query string false "Access token, when the Authorization header cannot be set"
+// @Success 101 {object} models.Notification
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /notifications/live [get]
+func (h *NotificationHandler) Live(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	err := websocket.Upgrade(c, func(conn *websocket.Conn) {
+		notifications, unsubscribe := h.notificationHub.Subscribe(userID)
+		defer unsubscribe()
+
+		// Reading answers pings and notices when the client leaves
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ticker := time.NewTicker(notificationPingInterval)
+		defer ticker.Stop()
+
+		for {
+			var err error
+			select {
+			case <-closed:
+				return
+			case notification, ok := <-notifications:
+				if !ok {
+					// Dropped for falling behind; the client reconnects
+					_ = conn.WriteClose(websocket.CloseTryAgainLater, "too many pending notifications")
+					return
+				}
+				data, _ := json.Marshal(notification)
+				err = conn.WriteText(data)
+			case <-ticker.C:
+				err = conn.WritePing()
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeBadRequest, "WebSocket upgrade required", err.Error())
+	}
+	return nil
+}
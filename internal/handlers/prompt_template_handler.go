@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PromptTemplateHandler struct {
+	promptTemplateService *services.PromptTemplateService
+	validator             *validator.Validate
+}
+
+func NewPromptTemplateHandler(promptTemplateService *services.PromptTemplateService) *PromptTemplateHandler {
+	return &PromptTemplateHandler{
+		promptTemplateService: promptTemplateService,
+		validator:             validator.New(),
+	}
+}
+
+// GetTemplates godoc
+// @Summary List active NL2SQL prompt templates (Admin only)
+// @Description List the active workspace default template and data source overrides, with the built-in template and the variables templates can use
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.PromptTemplateListResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/prompt-templates [get]
+func (h *PromptTemplateHandler) GetTemplates(c *fiber.Ctx) error {
+	templates, err := h.promptTemplateService.WithContext(c.UserContext()).ListActive()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve prompt templates", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Prompt templates retrieved successfully", templates)
+}
+
+// GetVersions godoc
+// @Summary List prompt template versions (Admin only)
+// @Description List the versions of the workspace default template, or of the override of a data source, newest first
+// @Tags admin
+// @Produce json
+// @Param data_source_id query int false "Data source of the override; omit for the workspace default"
+// @Success 200 {object} models.StandardResponse{data=[]models.PromptTemplate}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/prompt-templates/versions [get]
+func (h *PromptTemplateHandler) GetVersions(c *fiber.Ctx) error {
+	var dataSourceID *uint
+	if raw := c.Query("data_source_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+		}
+		scope := uint(id)
+		dataSourceID = &scope
+	}
+
+	versions, err := h.promptTemplateService.WithContext(c.UserContext()).ListVersions(dataSourceID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve prompt template versions", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Prompt template versions retrieved successfully", versions)
+}
+
+// GetTemplate godoc
+// @Summary Get a prompt template version (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "Prompt template ID"
+// @Success 200 {object} models.StandardResponse{data=models.PromptTemplate}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/prompt-templates/{id} [get]
+func (h *PromptTemplateHandler) GetTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid prompt template ID", err.Error())
+	}
+
+	template, err := h.promptTemplateService.WithContext(c.UserContext()).Get(uint(id))
+	if err != nil {
+		return promptTemplateErrorResponse(c, "Failed to retrieve prompt template", err)
+	}
+
+	return entity.SuccessResponse(c, "Prompt template retrieved successfully", template)
+}
+
+// CreateTemplate godoc
+// @Summary Save a prompt template version (Admin only)
+// @Description Save a new version of the workspace default template, or of the override of a data source, and make it active. Templates use {{variable}} placeholders and must include {{query}}.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param template body models.PromptTemplateRequest true "Prompt template"
+// @Success 201 {object} models.StandardResponse{data=models.PromptTemplate}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/prompt-templates [post]
+func (h *PromptTemplateHandler) CreateTemplate(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.PromptTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	template, err := h.promptTemplateService.WithContext(c.UserContext()).Create(adminID, &req)
+	if err != nil {
+		return promptTemplateErrorResponse(c, "Failed to create prompt template", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Prompt template created successfully",
+		Data:    template,
+	})
+}
+
+// ActivateTemplate godoc
+// @Summary Activate a prompt template version (Admin only)
+// @Description Make a version the active template of its scope, e.g. to roll back
+// @Tags admin
+// @Produce json
+// @Param id path int true "Prompt template ID"
+// @Success 200 {object} models.StandardResponse{data=models.PromptTemplate}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/prompt-templates/{id}/activate [post]
+func (h *PromptTemplateHandler) ActivateTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid prompt template ID", err.Error())
+	}
+
+	template, err := h.promptTemplateService.WithContext(c.UserContext()).Activate(uint(id))
+	if err != nil {
+		return promptTemplateErrorResponse(c, "Failed to activate prompt template", err)
+	}
+
+	return entity.SuccessResponse(c, "Prompt template activated successfully", template)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a prompt template version (Admin only)
+// @Description Remove a version. When it was active, the latest remaining version of its scope becomes active; a data source without versions falls back to the workspace default, then to the built-in template.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Prompt template ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/prompt-templates/{id} [delete]
+func (h *PromptTemplateHandler) DeleteTemplate(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid prompt template ID", err.Error())
+	}
+
+	if err := h.promptTemplateService.WithContext(c.UserContext()).Delete(uint(id)); err != nil {
+		return promptTemplateErrorResponse(c, "Failed to delete prompt template", err)
+	}
+
+	return entity.SuccessResponse(c, "Prompt template deleted successfully", nil)
+}
+
+// promptTemplateErrorResponse maps prompt template service errors to responses
+func promptTemplateErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPromptTemplateNotFound):
+		return entity.NotFoundResponse(c, "Prompt template not found")
+	case errors.Is(err, services.ErrPromptDataSourceNotFound):
+		return entity.NotFoundResponse(c, "Data source not found")
+	case errors.Is(err, services.ErrInvalidPromptTemplate):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type QueryCollaborationHandler struct {
+	collaborationService *services.QueryCollaborationService
+	validator            *validator.Validate
+}
+
+func NewQueryCollaborationHandler(collaborationService *services.QueryCollaborationService) *QueryCollaborationHandler {
+	return &QueryCollaborationHandler{
+		collaborationService: collaborationService,
+		validator:            validator.New(),
+	}
+}
+
+// CreateLink godoc
+// @Summary Create a collaboration link
+// @Description Issue a short-lived link through which a teammate can view the query's validation results and suggest SQL edits before execution. The token is only returned once.
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param link body models.QueryCollaborationLinkRequest false "Link options"
+// @Success 201 {object} models.StandardResponse{data=models.QueryCollaborationLinkResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/collaboration-links [post]
+func (h *QueryCollaborationHandler) CreateLink(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query ID", err.Error())
+	}
+
+	var req entity.QueryCollaborationLinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	link, err := h.collaborationService.WithContext(c.UserContext()).CreateLink(userID, uint(queryID), &req)
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to create collaboration link", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Collaboration link created successfully", link)
+}
+
+// GetLinks godoc
+// @Summary List collaboration links
+// @Description List the collaboration links of a query, including revoked and expired ones
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.QueryCollaborationLink}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/collaboration-links [get]
+func (h *QueryCollaborationHandler) GetLinks(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query ID", err.Error())
+	}
+
+	links, err := h.collaborationService.WithContext(c.UserContext()).ListLinks(userID, uint(queryID))
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to get collaboration links", err)
+	}
+
+	return entity.SuccessResponse(c, "Collaboration links retrieved successfully", links)
+}
+
+// RevokeLink godoc
+// @Summary Revoke a collaboration link
+// @Description End a collaboration link before it expires
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param linkId path int true "Link ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/collaboration-links/{linkId} [delete]
+func (h *QueryCollaborationHandler) RevokeLink(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, linkID, err := parseQueryChildParams(c, "linkId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	if err := h.collaborationService.WithContext(c.UserContext()).RevokeLink(userID, queryID, linkID); err != nil {
+		return collaborationErrorResponse(c, "Failed to revoke collaboration link", err)
+	}
+
+	return entity.SuccessResponse(c, "Collaboration link revoked successfully", nil)
+}
+
+// GetSuggestions godoc
+// @Summary List SQL suggestions
+// @Description List the SQL edits teammates suggested on a query, newest first
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.QuerySQLSuggestion}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/suggestions [get]
+func (h *QueryCollaborationHandler) GetSuggestions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query ID", err.Error())
+	}
+
+	suggestions, err := h.collaborationService.WithContext(c.UserContext()).ListSuggestions(userID, uint(queryID))
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to get suggestions", err)
+	}
+
+	return entity.SuccessResponse(c, "Suggestions retrieved successfully", suggestions)
+}
+
+// AcceptSuggestion godoc
+// @Summary Accept a SQL suggestion
+// @Description Apply a pending suggestion as a new SQL version of the query, attributed to the teammate who made it. When dashboards show the query and the canary run diverges, 409 is returned with the canary report; resend with confirm to apply anyway.
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param suggestionId path int true "Suggestion ID"
+// @Param review body models.QuerySuggestionReviewRequest false "Version comment and canary confirmation"
+// @Success 200 {object} models.StandardResponse{data=models.QuerySQLVersionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse{error=models.ErrorResponse{details=models.CanaryReport}}
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/suggestions/{suggestionId}/accept [post]
+func (h *QueryCollaborationHandler) AcceptSuggestion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, suggestionID, err := parseQueryChildParams(c, "suggestionId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	var req entity.QuerySuggestionReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	version, err := h.collaborationService.WithContext(c.UserContext()).AcceptSuggestion(userID, queryID, suggestionID, &req)
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to accept suggestion", err)
+	}
+
+	return entity.SuccessResponse(c, "Suggestion accepted successfully", version)
+}
+
+// RejectSuggestion godoc
+// @Summary Reject a SQL suggestion
+// @Description Close a pending suggestion without changing the query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param suggestionId path int true "Suggestion ID"
+// @Success 200 {object} models.StandardResponse{data=models.QuerySQLSuggestion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/suggestions/{suggestionId}/reject [post]
+func (h *QueryCollaborationHandler) RejectSuggestion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, suggestionID, err := parseQueryChildParams(c, "suggestionId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	suggestion, err := h.collaborationService.WithContext(c.UserContext()).RejectSuggestion(userID, queryID, suggestionID)
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to reject suggestion", err)
+	}
+
+	return entity.SuccessResponse(c, "Suggestion rejected successfully", suggestion)
+}
+
+// GetEvents godoc
+// @Summary Get the collaboration audit trail
+// @Description List every action taken on a query through collaboration links, with the acting user
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.QueryCollaborationEvent}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/collaboration-events [get]
+func (h *QueryCollaborationHandler) GetEvents(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query ID", err.Error())
+	}
+
+	events, err := h.collaborationService.WithContext(c.UserContext()).ListEvents(userID, uint(queryID))
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to get collaboration events", err)
+	}
+
+	return entity.SuccessResponse(c, "Collaboration events retrieved successfully", events)
+}
+
+// View godoc
+// @Summary Open a collaboration link
+// @Description View the query of a collaboration link with the validation result of its current SQL and the suggestions made so far
+// @Tags nl2sql
+// @Produce json
+// @Param token path string true "Collaboration token"
+// @Success 200 {object} models.StandardResponse{data=models.QueryCollaborationView}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/collaborate/{token} [get]
+func (h *QueryCollaborationHandler) View(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	view, err := h.collaborationService.WithContext(c.UserContext()).View(userID, c.Params("token"))
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to open collaboration link", err)
+	}
+
+	return entity.SuccessResponse(c, "Collaboration link opened successfully", view)
+}
+
+// Suggest godoc
+// @Summary Suggest a SQL edit
+// @Description Propose new SQL for the query of a collaboration link. The SQL is validated against the data source policy and the owner decides whether to apply it.
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param token path string true "Collaboration token"
+// @Param suggestion body models.QuerySQLSuggestionRequest true "Suggested SQL"
+// @Success 201 {object} models.StandardResponse{data=models.QuerySQLSuggestion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/collaborate/{token}/suggestions [post]
+func (h *QueryCollaborationHandler) Suggest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.QuerySQLSuggestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	suggestion, err := h.collaborationService.WithContext(c.UserContext()).Suggest(userID, c.Params("token"), &req)
+	if err != nil {
+		return collaborationErrorResponse(c, "Failed to suggest SQL", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Suggestion created successfully", suggestion)
+}
+
+// parseQueryChildParams parses the query ID and the ID of a resource under it
+func parseQueryChildParams(c *fiber.Ctx, childParam string) (uint, uint, error) {
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	childID, err := strconv.ParseUint(c.Params(childParam), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(queryID), uint(childID), nil
+}
+
+func collaborationErrorResponse(c *fiber.Ctx, message string, err error) error {
+	var canaryErr *services.CanaryDivergenceError
+	if errors.As(err, &canaryErr) {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeCanaryDivergence, err.Error(), canaryErr.Report)
+	}
+
+	switch {
+	case err.Error() == "query not found",
+		errors.Is(err, services.ErrCollaborationLinkInvalid),
+		errors.Is(err, services.ErrCollaborationLinkNotFound),
+		errors.Is(err, services.ErrQuerySuggestionNotFound):
+		return entity.NotFoundResponse(c, err.Error())
+	default:
+		return entity.BadRequestResponse(c, message, err.Error())
+	}
+}
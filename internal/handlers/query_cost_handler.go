@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type QueryCostHandler struct {
+	queryCostService *services.QueryCostService
+	validator        *validator.Validate
+}
+
+func NewQueryCostHandler(queryCostService *services.QueryCostService) *QueryCostHandler {
+	return &QueryCostHandler{
+		queryCostService: queryCostService,
+		validator:        validator.New(),
+	}
+}
+
+// GetCostCeiling godoc
+// @Summary Get the effective query cost ceiling
+// @Description Get the strictest combination of the current user's ceiling and the data source ceiling
+// @Tags nl2sql
+// @Produce json
+// @Param data_source_id query int false "Data source ID"
+// @Success 200 {object} models.StandardResponse{data=models.EffectiveQueryCostCeiling}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/cost-ceiling [get]
+func (h *QueryCostHandler) GetCostCeiling(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	dataSourceID, err := strconv.ParseUint(c.Query("data_source_id", "0"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	ceiling, err := h.queryCostService.WithContext(c.UserContext()).GetEffectiveCeiling(userID, uint(dataSourceID))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get cost ceiling", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Cost ceiling retrieved successfully", ceiling)
+}
+
+// SetDataSourceCeiling godoc
+// @Summary Set a data source cost ceiling
+// @Description Block queries against the data source whose estimated cost, bytes scanned or rows exceed the limits
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param ceiling body models.QueryCostCeilingRequest true "Cost limits (0 disables a limit)"
+// @Success 200 {object} models.StandardResponse{data=models.QueryCostCeiling}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/cost-ceiling [put]
+func (h *QueryCostHandler) SetDataSourceCeiling(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.QueryCostCeilingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	ceiling, err := h.queryCostService.WithContext(c.UserContext()).SetDataSourceCeiling(userID, uint(id), &req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to set cost ceiling", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Cost ceiling updated successfully", ceiling)
+}
+
+// DeleteDataSourceCeiling godoc
+// @Summary Remove a data source cost ceiling
+// @Description Remove the cost ceiling of a data source
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/cost-ceiling [delete]
+func (h *QueryCostHandler) DeleteDataSourceCeiling(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	if err := h.queryCostService.WithContext(c.UserContext()).DeleteDataSourceCeiling(userID, uint(id)); err != nil {
+		return entity.BadRequestResponse(c, "Failed to delete cost ceiling", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Cost ceiling deleted successfully", nil)
+}
+
+// SetUserCeiling godoc
+// @Summary Set a user cost ceiling
+// @Description Block queries of the user whose estimated cost, bytes scanned or rows exceed the limits
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param ceiling body models.QueryCostCeilingRequest true "Cost limits (0 disables a limit)"
+// @Success 200 {object} models.StandardResponse{data=models.QueryCostCeiling}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/cost-ceiling [put]
+func (h *QueryCostHandler) SetUserCeiling(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	var req entity.QueryCostCeilingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	ceiling, err := h.queryCostService.WithContext(c.UserContext()).SetUserCeiling(uint(id), adminID, &req)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to set cost ceiling", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Cost ceiling updated successfully", ceiling)
+}
+
+// DeleteUserCeiling godoc
+// @Summary Remove a user cost ceiling
+// @Description Remove the cost ceiling of a user
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/cost-ceiling [delete]
+func (h *QueryCostHandler) DeleteUserCeiling(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	if err := h.queryCostService.WithContext(c.UserContext()).DeleteUserCeiling(uint(id)); err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to delete cost ceiling", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Cost ceiling deleted successfully", nil)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type QueryResultArchiveHandler struct {
+	archiveService *services.QueryResultArchiveService
+}
+
+func NewQueryResultArchiveHandler(archiveService *services.QueryResultArchiveService) *QueryResultArchiveHandler {
+	return &QueryResultArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// Rehydrate godoc
+// @Summary Rehydrate an archived query result
+// @Description Restore the rows of a query result from cold storage so they can be paged through again
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Query ID"
+// @Param resultId path int true "Result ID"
+// @Success 200 {object} models.StandardResponse{data=models.QueryResult}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/queries/{id}/results/{resultId}/rehydrate [post]
+func (h *QueryResultArchiveHandler) Rehydrate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	queryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query ID", err.Error())
+	}
+	resultID, err := strconv.ParseUint(c.Params("resultId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid result ID", err.Error())
+	}
+
+	result, err := h.archiveService.WithContext(c.UserContext()).Rehydrate(c.UserContext(), userID, uint(queryID), uint(resultID))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrQueryResultNotFound):
+			return entity.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrQueryResultNotArchived):
+			return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+		default:
+			return entity.InternalServerErrorResponse(c, "Failed to rehydrate query result", err.Error())
+		}
+	}
+
+	return entity.SuccessResponse(c, "Query result rehydrated successfully", result)
+}
+
+// Archive godoc
+// @Summary Archive old query results
+// @Description Move the rows of query results older than the archive age to cold storage (typically called by a cron job)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.QueryResultArchiveRun}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/query-results/archive [post]
+func (h *QueryResultArchiveHandler) Archive(c *fiber.Ctx) error {
+	run, err := h.archiveService.WithContext(c.UserContext()).Archive(c.UserContext())
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to archive query results", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Query results archived", run)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type QueryRetentionHandler struct {
+	retentionService *services.QueryRetentionService
+	auditService     *services.AuditService
+}
+
+func NewQueryRetentionHandler(retentionService *services.QueryRetentionService, auditService *services.AuditService) *QueryRetentionHandler {
+	return &QueryRetentionHandler{
+		retentionService: retentionService,
+		auditService:     auditService,
+	}
+}
+
+// DeleteOwnHistory godoc
+// @Summary Delete query history in bulk
+// @Description Permanently delete the user's queries of a data source and/or created in a date range, with their results and SQL versions
+// @Tags nl2sql
+// @Produce json
+// @Param data_source_id query int false "Only queries of this data source"
+// @Param from query string false "Created at or after (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Created before (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} models.StandardResponse{data=models.QueryRetentionRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/history [delete]
+func (h *QueryRetentionHandler) DeleteOwnHistory(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var filter entity.QueryHistoryDeleteRequest
+	if err := c.QueryParser(&filter); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query parameters", err.Error())
+	}
+	filter.UserID = userID
+
+	return h.deleteHistory(c, &filter)
+}
+
+// DeleteHistory godoc
+// @Summary Delete query history of all users in bulk (Admin only)
+// @Description Permanently delete the queries of a data source and/or created in a date range, with their results and SQL versions
+// @Tags admin
+// @Produce json
+// @Param user_id query int false "Only queries of this user"
+// @Param data_source_id query int false "Only queries of this data source"
+// @Param from query string false "Created at or after (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Created before (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} models.StandardResponse{data=models.QueryRetentionRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/query-history [delete]
+func (h *QueryRetentionHandler) DeleteHistory(c *fiber.Ctx) error {
+	var filter entity.QueryHistoryDeleteRequest
+	if err := c.QueryParser(&filter); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query parameters", err.Error())
+	}
+
+	return h.deleteHistory(c, &filter)
+}
+
+// Purge godoc
+// @Summary Purge query history past its retention (Admin only)
+// @Description Run the retention purge now instead of waiting for the scheduled run
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.QueryRetentionRun}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/query-history/purge [post]
+func (h *QueryRetentionHandler) Purge(c *fiber.Ctx) error {
+	run, err := h.retentionService.WithContext(c.UserContext()).Purge(c.UserContext())
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to purge query history", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Query history purged", run)
+}
+
+func (h *QueryRetentionHandler) deleteHistory(c *fiber.Ctx, filter *entity.QueryHistoryDeleteRequest) error {
+	run, err := h.retentionService.WithContext(c.UserContext()).DeleteHistory(c.UserContext(), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidHistoryFilter) {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to delete query history", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionQueryHistoryDelete, "nl2sql_query", 0, nil, nil, map[string]interface{}{
+		"user_id":         filter.UserID,
+		"data_source_id":  filter.DataSourceID,
+		"from":            filter.From,
+		"to":              filter.To,
+		"queries_deleted": run.QueriesDeleted,
+		"results_deleted": run.ResultsDeleted,
+	})
+
+	return entity.SuccessResponse(c, "Query history deleted", run)
+}
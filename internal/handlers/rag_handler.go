@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
 	models "narapulse-be/internal/models/entity"
 	"narapulse-be/internal/services"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -13,13 +20,17 @@ import (
 type RAGHandler struct {
 	ragService       *services.RAGService
 	embeddingService *services.EmbeddingService
+	questionService  *services.QuestionAnalyticsService
+	validator        *validator.Validate
 }
 
 // NewRAGHandler creates a new RAG handler
-func NewRAGHandler(ragService *services.RAGService, embeddingService *services.EmbeddingService) *RAGHandler {
+func NewRAGHandler(ragService *services.RAGService, embeddingService *services.EmbeddingService, questionService *services.QuestionAnalyticsService) *RAGHandler {
 	return &RAGHandler{
 		ragService:       ragService,
 		embeddingService: embeddingService,
+		questionService:  questionService,
+		validator:        validator.New(),
 	}
 }
 
@@ -30,25 +41,20 @@ func NewRAGHandler(ragService *services.RAGService, embeddingService *services.E
 // @Accept json
 // @Produce json
 // @Param request body models.RAGSearchRequest true "Search request"
-// @Success 200 {object} models.RAGSearchResponse
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 200 {object} models.StandardResponse{data=models.RAGSearchResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/search [post]
 func (h *RAGHandler) SearchSimilar(c *fiber.Ctx) error {
 	var req models.RAGSearchRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_REQUEST_BODY",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if req.Query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "QUERY_REQUIRED",
-			Message: "Please provide a search query",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide a search query", nil)
 	}
 
 	// Set defaults
@@ -60,15 +66,13 @@ func (h *RAGHandler) SearchSimilar(c *fiber.Ctx) error {
 	}
 
 	// Perform search
-	result, err := h.ragService.SearchSimilar(c.Context(), req.Query, req.DataSourceID, req.TopK, req.ElementTypes)
+	userID := c.Locals("user_id").(uint)
+	result, err := h.ragService.WithContext(c.UserContext()).SearchSimilar(usageContext(c), userID, req.Query, req.DataSourceID, req.TopK, req.ElementTypes)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SEARCH_FAILED",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), "Failed to search similar elements", err.Error())
 	}
 
-	return c.JSON(result)
+	return models.SuccessResponse(c, "Similar elements retrieved successfully", result)
 }
 
 // BuildNL2SQLContext builds context for NL2SQL conversion
@@ -79,44 +83,47 @@ func (h *RAGHandler) SearchSimilar(c *fiber.Ctx) error {
 // @Produce json
 // @Param data_source_id query int true "Data source ID"
 // @Param query query string true "Natural language query"
-// @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Param rerank query bool false "Rerank retrieved context before building it"
+// @Param rerank_threshold query number false "Minimum rerank relevance to keep"
+// @Success 200 {object} models.StandardResponse{data=object}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/nl2sql-context [get]
 func (h *RAGHandler) BuildNL2SQLContext(c *fiber.Ctx) error {
 	query := c.Query("query")
 	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "QUERY_PARAMETER_REQUIRED",
-			Message: "Please provide a query parameter",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide a query parameter", nil)
 	}
 
 	dataSourceIDStr := c.Query("data_source_id")
 	if dataSourceIDStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "DATA_SOURCE_ID_REQUIRED",
-			Message: "Please provide a data_source_id parameter",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide a data_source_id parameter", nil)
 	}
 
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Data source ID must be a valid number",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Data source ID must be a valid number", nil)
+	}
+
+	opts := services.NL2SQLContextOptions{
+		Rerank: c.QueryBool("rerank", false),
+	}
+	if thresholdStr := c.Query("rerank_threshold"); thresholdStr != "" {
+		threshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Rerank threshold must be a valid number", nil)
+		}
+		opts.RerankThreshold = threshold
 	}
 
-	context, err := h.ragService.BuildNL2SQLContext(c.Context(), query, uint(dataSourceID))
+	userID := c.Locals("user_id").(uint)
+	context, err := h.ragService.WithContext(c.UserContext()).BuildNL2SQLContextWithOptions(usageContext(c), userID, query, uint(dataSourceID), opts)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "CONTEXT_BUILD_FAILED",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), "Failed to build NL2SQL context", err.Error())
 	}
 
-	return c.JSON(context)
+	return models.SuccessResponse(c, "NL2SQL context built successfully", context)
 }
 
 // GetAvailableSchemas returns available schemas for a data source
@@ -125,64 +132,51 @@ func (h *RAGHandler) BuildNL2SQLContext(c *fiber.Ctx) error {
 // @Tags RAG
 // @Produce json
 // @Param data_source_id path int true "Data source ID"
-// @Success 200 {array} map[string]interface{}
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 200 {object} models.StandardResponse{data=[]object}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/schemas/{data_source_id} [get]
 func (h *RAGHandler) GetAvailableSchemas(c *fiber.Ctx) error {
 	dataSourceIDStr := c.Params("data_source_id")
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Data source ID must be a valid number",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Data source ID must be a valid number", nil)
 	}
 
-	schemas, err := h.ragService.GetAvailableSchemas(uint(dataSourceID))
+	userID := c.Locals("user_id").(uint)
+	schemas, err := h.ragService.WithContext(c.UserContext()).GetAvailableSchemas(userID, uint(dataSourceID))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "GET_SCHEMAS_FAILED",
-			Message: err.Error(),
-		})
+		return models.InternalServerErrorResponse(c, "Failed to get schemas", err.Error())
 	}
 
-	return c.JSON(schemas)
+	return models.SuccessResponse(c, "Schemas retrieved successfully", schemas)
 }
 
 // SyncSchemaEmbeddings synchronizes embeddings for a data source
 // @Summary Sync schema embeddings
-// @Description Synchronize embeddings for all schemas in a data source
+// @Description Synchronize embeddings for all schemas in a data source. Only tables and columns whose content changed are embedded again; the response counts embedded, unchanged and removed elements.
 // @Tags RAG
 // @Accept json
 // @Produce json
 // @Param data_source_id path int true "Data source ID"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 200 {object} models.StandardResponse{data=object}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/sync/{data_source_id} [post]
 func (h *RAGHandler) SyncSchemaEmbeddings(c *fiber.Ctx) error {
 	dataSourceIDStr := c.Params("data_source_id")
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Data source ID must be a valid number",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Data source ID must be a valid number", nil)
 	}
 
-	err = h.ragService.SyncSchemaEmbeddings(c.Context(), uint(dataSourceID))
+	progress, err := h.ragService.WithContext(c.UserContext()).SyncSchemaEmbeddings(usageContext(c), uint(dataSourceID))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SYNC_EMBEDDINGS_FAILED",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), err.Error(), progress)
 	}
 
-	return c.JSON(map[string]string{
-		"message": "Schema embeddings synchronized successfully",
-		"status":  "success",
-	})
+	return models.SuccessResponse(c, "Schema embeddings synchronized successfully", progress)
 }
 
 // EmbedKPIDefinition embeds a KPI definition
@@ -192,25 +186,20 @@ func (h *RAGHandler) SyncSchemaEmbeddings(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param request body models.KPIDefinitionRequest true "KPI definition request"
-// @Success 201 {object} models.KPIDefinitionResponse
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 201 {object} models.StandardResponse{data=object}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/kpi [post]
 func (h *RAGHandler) EmbedKPIDefinition(c *fiber.Ctx) error {
 	var req models.KPIDefinitionRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_REQUEST_BODY",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if req.Name == "" || req.Description == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "NAME_AND_DESCRIPTION_REQUIRED",
-			Message: "Please provide both name and description for the KPI",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide both name and description for the KPI", nil)
 	}
 
 	// Create KPI definition from request
@@ -222,21 +211,21 @@ func (h *RAGHandler) EmbedKPIDefinition(c *fiber.Ctx) error {
 		Category:    req.Category,
 		Unit:        req.Unit,
 		Grain:       req.Grain,
+		DataSourceID: req.DataSourceID,
+		SourceTable: req.SourceTable,
+		TimeColumn:  req.TimeColumn,
 		// Convert filters and tags to JSON
 	}
 
-	err := h.embeddingService.EmbedKPIDefinition(c.Context(), kpi)
+	err := h.embeddingService.WithContext(c.UserContext()).EmbedKPIDefinition(usageContext(c), kpi)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "EMBED_KPI_FAILED",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), "Failed to embed KPI definition", err.Error())
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(map[string]interface{}{
-		"message": "KPI definition embedded successfully",
-		"id":      kpi.ID,
-		"name":    kpi.Name,
+	c.Status(fiber.StatusCreated)
+	return models.SuccessResponse(c, "KPI definition embedded successfully", map[string]interface{}{
+		"id":   kpi.ID,
+		"name": kpi.Name,
 	})
 }
 
@@ -247,25 +236,20 @@ func (h *RAGHandler) EmbedKPIDefinition(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param request body models.BusinessGlossaryRequest true "Glossary term request"
-// @Success 201 {object} models.BusinessGlossaryResponse
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 201 {object} models.StandardResponse{data=object}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/glossary [post]
 func (h *RAGHandler) EmbedGlossaryTerm(c *fiber.Ctx) error {
 	var req models.BusinessGlossaryRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_REQUEST_BODY",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if req.Term == "" || req.Definition == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "TERM_AND_DEFINITION_REQUIRED",
-			Message: "Please provide both term and definition",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide both term and definition", nil)
 	}
 
 	// Create glossary term from request
@@ -276,22 +260,85 @@ func (h *RAGHandler) EmbedGlossaryTerm(c *fiber.Ctx) error {
 		Domain:     req.Domain,
 		// Convert arrays to JSON
 	}
+	if len(req.Synonyms) > 0 {
+		synonymsJSON, _ := json.Marshal(req.Synonyms)
+		glossary.Synonyms = models.JSON(synonymsJSON)
+	}
+	if len(req.Translations) > 0 {
+		translationsJSON, _ := json.Marshal(req.Translations)
+		glossary.Translations = models.JSON(translationsJSON)
+	}
 
-	err := h.embeddingService.EmbedGlossaryTerm(c.Context(), glossary)
+	err := h.embeddingService.WithContext(c.UserContext()).EmbedGlossaryTerm(usageContext(c), glossary)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "EMBED_GLOSSARY_FAILED",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), "Failed to embed glossary term", err.Error())
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(map[string]interface{}{
-		"message": "Glossary term embedded successfully",
-		"id":      glossary.ID,
-		"term":    glossary.Term,
+	c.Status(fiber.StatusCreated)
+	return models.SuccessResponse(c, "Glossary term embedded successfully", map[string]interface{}{
+		"id":   glossary.ID,
+		"term": glossary.Term,
 	})
 }
 
+// EmbedAll embeds every KPI definition and glossary term lacking an embedding
+// @Summary Embed all KPIs and glossary terms without embeddings
+// @Description Walk existing KPI definitions and glossary terms that have no embedding (e.g. imported via SQL) and embed them in batches. With Accept: text/event-stream, progress is streamed as "progress" events after every batch followed by a "done" event; otherwise the final counts are returned.
+// @Tags RAG
+// @Accept json
+// @Produce json
+// @Produce text/event-stream
+// @Param request body models.EmbedAllRequest false "Batch options"
+// @Success 200 {object} models.StandardResponse{data=models.EmbedAllProgress}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Router /api/v1/rag/embed-all [post]
+func (h *RAGHandler) EmbedAll(c *fiber.Ctx) error {
+	var req models.EmbedAllRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+	if req.BatchSize < 0 || req.BatchSize > 500 {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Batch size must be between 1 and 500", nil)
+	}
+
+	if !strings.Contains(c.Get(fiber.HeaderAccept), "text/event-stream") {
+		result, err := h.embeddingService.WithContext(c.UserContext()).EmbedAllMissing(c.Context(), req.BatchSize, nil)
+		if err != nil {
+			return models.InternalServerErrorResponse(c, "Failed to embed KPIs and glossary terms", err.Error())
+		}
+		return models.SuccessResponse(c, "KPIs and glossary terms embedded successfully", result)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	embeddings := h.embeddingService.WithContext(c.UserContext())
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The run continues when the client disconnects, like a plain request would
+		writeEvent := func(event string, data interface{}) {
+			payload, _ := json.Marshal(data)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+			w.Flush()
+		}
+
+		result, err := embeddings.EmbedAllMissing(context.Background(), req.BatchSize, func(progress models.EmbedAllProgress) {
+			writeEvent("progress", progress)
+		})
+		if err != nil {
+			writeEvent("error", models.ErrorResponse{Code: models.ErrorCodeInternal, Message: "Failed to embed KPIs and glossary terms", Details: err.Error()})
+			return
+		}
+		writeEvent("done", result)
+	})
+
+	return nil
+}
+
 // GetEnhancedNL2SQLPrompt builds enhanced prompt for NL2SQL
 // @Summary Get enhanced NL2SQL prompt
 // @Description Build an enhanced prompt with context for NL2SQL conversion
@@ -299,44 +346,34 @@ func (h *RAGHandler) EmbedGlossaryTerm(c *fiber.Ctx) error {
 // @Produce json
 // @Param data_source_id query int true "Data source ID"
 // @Param query query string true "Natural language query"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 200 {object} models.StandardResponse{data=map[string]string}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/nl2sql-prompt [get]
 func (h *RAGHandler) GetEnhancedNL2SQLPrompt(c *fiber.Ctx) error {
 	query := c.Query("query")
 	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "QUERY_PARAMETER_REQUIRED",
-			Message: "Please provide a query parameter",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide a query parameter", nil)
 	}
 
 	dataSourceIDStr := c.Query("data_source_id")
 	if dataSourceIDStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "DATA_SOURCE_ID_REQUIRED",
-			Message: "Please provide a data_source_id parameter",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide a data_source_id parameter", nil)
 	}
 
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Data source ID must be a valid number",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Data source ID must be a valid number", nil)
 	}
 
-	prompt, err := h.ragService.BuildEnhancedNL2SQLPrompt(c.Context(), query, uint(dataSourceID))
+	userID := c.Locals("user_id").(uint)
+	prompt, err := h.ragService.WithContext(c.UserContext()).BuildEnhancedNL2SQLPrompt(usageContext(c), userID, query, uint(dataSourceID))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "BUILD_PROMPT_FAILED",
-			Message: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), "Failed to build prompt", err.Error())
 	}
 
-	return c.JSON(map[string]string{
+	return models.SuccessResponse(c, "Prompt built successfully", map[string]string{
 		"prompt": prompt,
 		"query":  query,
 	})
@@ -348,18 +385,15 @@ func (h *RAGHandler) GetEnhancedNL2SQLPrompt(c *fiber.Ctx) error {
 // @Tags RAG
 // @Produce json
 // @Param data_source_id path int true "Data source ID"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
 // @Router /api/v1/rag/embeddings/{data_source_id} [delete]
 func (h *RAGHandler) DeleteEmbeddings(c *fiber.Ctx) error {
 	dataSourceIDStr := c.Params("data_source_id")
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Data source ID must be a valid number",
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Data source ID must be a valid number", nil)
 	}
 
 	// Get optional schema_id parameter
@@ -368,20 +402,14 @@ func (h *RAGHandler) DeleteEmbeddings(c *fiber.Ctx) error {
 	if schemaIDStr != "" {
 		parsedSchemaID, err := strconv.ParseUint(schemaIDStr, 10, 32)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-				Code:    "INVALID_SCHEMA_ID",
-				Message: "Schema ID must be a valid number",
-			})
+			return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Schema ID must be a valid number", nil)
 		}
 		schemaID = uint(parsedSchemaID)
 	}
 
-	err = h.embeddingService.DeleteEmbeddings(uint(dataSourceID), schemaID)
+	err = h.embeddingService.WithContext(c.UserContext()).DeleteEmbeddings(uint(dataSourceID), schemaID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "DELETE_EMBEDDINGS_FAILED",
-			Message: err.Error(),
-		})
+		return models.InternalServerErrorResponse(c, "Failed to delete embeddings", err.Error())
 	}
 
 	message := "All embeddings deleted successfully"
@@ -389,8 +417,82 @@ func (h *RAGHandler) DeleteEmbeddings(c *fiber.Ctx) error {
 		message = "Schema embeddings deleted successfully"
 	}
 
-	return c.JSON(map[string]string{
-		"message": message,
-		"status":  "success",
-	})
-}
\ No newline at end of file
+	return models.SuccessResponse(c, message, nil)
+}
+
+// GetSimilarQuestions returns the user's past questions similar to a new one
+// @Summary Get similar past questions
+// @Description Find the requesting user's past questions on a data source whose embeddings are closest to the query, with the SQL they were converted to
+// @Tags RAG
+// @Produce json
+// @Param query query string true "Natural language question"
+// @Param data_source_id query int true "Data source ID"
+// @Param limit query int false "Questions returned (default 5, max 20)"
+// @Param min_score query number false "Minimum similarity (default 0.75)"
+// @Success 200 {object} models.StandardResponse{data=[]models.SimilarQuestion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 402 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Router /api/v1/rag/similar-questions [get]
+func (h *RAGHandler) GetSimilarQuestions(c *fiber.Ctx) error {
+	var req models.SimilarQuestionsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Invalid query parameters", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Please provide a query and a data_source_id", err.Error())
+	}
+
+	userID := c.Locals("user_id").(uint)
+	questions, err := h.questionService.WithContext(c.UserContext()).SimilarQuestions(usageContext(c), userID, &req)
+	if err != nil {
+		return models.ErrorResponseWithCode(c, ragErrorCode(err), "Failed to find similar questions", err.Error())
+	}
+
+	return models.SuccessResponse(c, "Similar questions retrieved successfully", questions)
+}
+
+// GetQuestionTopics clusters recent questions into topics
+// @Summary Get frequent question topics
+// @Description Cluster the recent questions of all users by embedding similarity and list the largest topics (admin only)
+// @Tags RAG
+// @Produce json
+// @Param data_source_id query int false "Only questions on this data source"
+// @Param days query int false "Questions of the last days (default 30)"
+// @Param threshold query number false "Similarity joining a question to a topic (default 0.85)"
+// @Param min_questions query int false "Smallest topic listed (default 2)"
+// @Param limit query int false "Topics listed (default 20)"
+// @Success 200 {object} models.StandardResponse{data=models.QuestionTopicsResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Router /api/v1/rag/question-topics [get]
+func (h *RAGHandler) GetQuestionTopics(c *fiber.Ctx) error {
+	var req models.QuestionTopicsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Invalid query parameters", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return models.ErrorResponseWithCode(c, models.ErrorCodeValidationFailed, "Invalid topic parameters", err.Error())
+	}
+
+	topics, err := h.questionService.WithContext(c.UserContext()).Topics(&req)
+	if err != nil {
+		return models.InternalServerErrorResponse(c, "Failed to cluster question topics", err.Error())
+	}
+
+	return models.SuccessResponse(c, "Question topics retrieved successfully", topics)
+}
+
+// usageContext attributes the AI calls of the request to the requesting user
+func usageContext(c *fiber.Ctx) context.Context {
+	userID, _ := c.Locals("user_id").(uint)
+	return services.WithUsageUser(c.UserContext(), userID)
+}
+
+// ragErrorCode returns the code of an exhausted AI quota, else of an internal error
+func ragErrorCode(err error) models.ErrorCode {
+	if errors.Is(err, services.ErrUsageQuotaExceeded) {
+		return models.ErrorCodeUsageQuotaExceeded
+	}
+	return models.ErrorCodeInternal
+}
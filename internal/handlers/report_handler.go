@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+	validator     *validator.Validate
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		validator:     validator.New(),
+	}
+}
+
+// GetReports godoc
+// @Summary List reports
+// @Description List the user's reports, most recently updated first
+// @Tags reports
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ReportResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports [get]
+func (h *ReportHandler) GetReports(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	reports, err := h.reportService.WithContext(c.UserContext()).List(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve reports", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Reports retrieved successfully", reports)
+}
+
+// GetReport godoc
+// @Summary Get a report
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.StandardResponse{data=models.ReportResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports/{id} [get]
+func (h *ReportHandler) GetReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid report ID", err.Error())
+	}
+
+	report, err := h.reportService.WithContext(c.UserContext()).Get(userID, uint(id))
+	if err != nil {
+		return reportErrorResponse(c, "Failed to retrieve report", err)
+	}
+
+	return entity.SuccessResponse(c, "Report retrieved successfully", report)
+}
+
+// CreateReport godoc
+// @Summary Create a report
+// @Description Compose a report of saved queries, KPIs and dashboards, rendered to HTML or PDF and optionally emailed daily or weekly
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param report body models.ReportRequest true "Report"
+// @Success 201 {object} models.StandardResponse{data=models.ReportResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports [post]
+func (h *ReportHandler) CreateReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.ReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	report, err := h.reportService.WithContext(c.UserContext()).Create(userID, &req)
+	if err != nil {
+		return reportErrorResponse(c, "Failed to create report", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Report created successfully", report)
+}
+
+// UpdateReport godoc
+// @Summary Update a report
+// @Description Replace a report; changing the frequency reschedules its next delivery
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param report body models.ReportRequest true "Report"
+// @Success 200 {object} models.StandardResponse{data=models.ReportResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports/{id} [put]
+func (h *ReportHandler) UpdateReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid report ID", err.Error())
+	}
+
+	var req entity.ReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	report, err := h.reportService.WithContext(c.UserContext()).Update(userID, uint(id), &req)
+	if err != nil {
+		return reportErrorResponse(c, "Failed to update report", err)
+	}
+
+	return entity.SuccessResponse(c, "Report updated successfully", report)
+}
+
+// DeleteReport godoc
+// @Summary Delete a report
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports/{id} [delete]
+func (h *ReportHandler) DeleteReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid report ID", err.Error())
+	}
+
+	if err := h.reportService.WithContext(c.UserContext()).Delete(userID, uint(id)); err != nil {
+		return reportErrorResponse(c, "Failed to delete report", err)
+	}
+
+	return entity.SuccessResponse(c, "Report deleted successfully", nil)
+}
+
+// RunReport godoc
+// @Summary Run a report
+// @Description Render a report now and optionally email it to its recipients; the run is recorded in its history
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param run body models.ReportRunRequest false "Run options"
+// @Success 200 {object} models.StandardResponse{data=models.ReportRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports/{id}/run [post]
+func (h *ReportHandler) RunReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid report ID", err.Error())
+	}
+
+	var req entity.ReportRunRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+
+	run, err := h.reportService.WithContext(c.UserContext()).Run(c.UserContext(), userID, uint(id), &req)
+	if err != nil {
+		return reportErrorResponse(c, "Failed to run report", err)
+	}
+
+	return entity.SuccessResponse(c, "Report run completed", run)
+}
+
+// GetReportRuns godoc
+// @Summary List report runs
+// @Description List the most recent runs of a report, newest first
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.StandardResponse{data=[]models.ReportRun}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports/{id}/runs [get]
+func (h *ReportHandler) GetReportRuns(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid report ID", err.Error())
+	}
+
+	runs, err := h.reportService.WithContext(c.UserContext()).ListRuns(userID, uint(id))
+	if err != nil {
+		return reportErrorResponse(c, "Failed to retrieve report runs", err)
+	}
+
+	return entity.SuccessResponse(c, "Report runs retrieved successfully", runs)
+}
+
+// DownloadReportRun godoc
+// @Summary Download a rendered report
+// @Description Download the HTML or PDF document rendered by a run
+// @Tags reports
+// @Produce html
+// @Produce application/pdf
+// @Param id path int true "Report ID"
+// @Param runId path int true "Run ID"
+// @Success 200 {file} file
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /reports/{id}/runs/{runId}/download [get]
+func (h *ReportHandler) DownloadReportRun(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid report ID", err.Error())
+	}
+	runID, err := strconv.ParseUint(c.Params("runId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid run ID", err.Error())
+	}
+
+	data, contentType, filename, err := h.reportService.WithContext(c.UserContext()).Download(c.UserContext(), userID, uint(id), uint(runID))
+	if err != nil {
+		return reportErrorResponse(c, "Failed to download report", err)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(data)
+}
+
+func reportErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrReportNotFound):
+		return entity.NotFoundResponse(c, "Report not found")
+	case errors.Is(err, services.ErrReportRunNotFound):
+		return entity.NotFoundResponse(c, "Report run not found")
+	case errors.Is(err, services.ErrInvalidReport):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
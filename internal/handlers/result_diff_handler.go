@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ResultDiffHandler struct {
+	resultDiffService *services.ResultDiffService
+	validator         *validator.Validate
+}
+
+func NewResultDiffHandler(resultDiffService *services.ResultDiffService) *ResultDiffHandler {
+	return &ResultDiffHandler{
+		resultDiffService: resultDiffService,
+		validator:         validator.New(),
+	}
+}
+
+// DiffResults godoc
+// @Summary Diff two query results
+// @Description Compare the latest results of two queries, two results of one query, or the two latest runs of a saved query. Rows are matched on the key columns (by default the shared non-numeric columns, or by position when there are none) and reported as added, removed or changed with per-column deltas, along with column changes and the delta of every numeric column total. PII columns are masked unless the caller may unmask them.
+// @Tags analysis
+// @Accept json
+// @Produce json
+// @Param diff body models.ResultDiffRequest true "Results to compare"
+// @Success 200 {object} models.StandardResponse{data=models.ResultDiff}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /analysis/diff [post]
+func (h *ResultDiffHandler) DiffResults(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.ResultDiffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	req.UnmaskPII = middleware.CanUnmaskPII(c)
+
+	diff, err := h.resultDiffService.WithContext(c.UserContext()).Diff(userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrQueryNotFound):
+			return entity.NotFoundResponse(c, "Query not found")
+		case errors.Is(err, services.ErrQueryResultNotFound):
+			return entity.NotFoundResponse(c, "Query result not found")
+		case errors.Is(err, services.ErrSavedQueryNotFound):
+			return entity.NotFoundResponse(c, "Saved query not found")
+		case errors.Is(err, services.ErrQueryResultArchived):
+			return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+		case errors.Is(err, services.ErrResultDiffInvalid),
+			errors.Is(err, services.ErrQueryResultTooLarge),
+			errors.Is(err, services.ErrResultDiffNotEnoughRuns):
+			return entity.BadRequestResponse(c, "Cannot diff results", err.Error())
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to diff results", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Results compared successfully", diff)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RetrievalConfigHandler struct {
+	retrievalConfigService *services.RetrievalConfigService
+	validator              *validator.Validate
+}
+
+func NewRetrievalConfigHandler(retrievalConfigService *services.RetrievalConfigService) *RetrievalConfigHandler {
+	return &RetrievalConfigHandler{
+		retrievalConfigService: retrievalConfigService,
+		validator:              validator.New(),
+	}
+}
+
+// GetConfigs godoc
+// @Summary List NL2SQL retrieval configs (Admin only)
+// @Description List the workspace default and data source retrieval configs, with the built-in defaults used without a config
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.RetrievalConfigListResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/retrieval-configs [get]
+func (h *RetrievalConfigHandler) GetConfigs(c *fiber.Ctx) error {
+	configs, err := h.retrievalConfigService.WithContext(c.UserContext()).List()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve retrieval configs", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Retrieval configs retrieved successfully", configs)
+}
+
+// GetEffectiveConfig godoc
+// @Summary Get the retrieval config of a data source (Admin only)
+// @Description Get the config NL2SQL retrieves with for a data source: its own, the workspace default or the built-in defaults
+// @Tags admin
+// @Produce json
+// @Param data_source_id path int true "Data source ID"
+// @Success 200 {object} models.StandardResponse{data=models.RetrievalConfig}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/retrieval-configs/effective/{data_source_id} [get]
+func (h *RetrievalConfigHandler) GetEffectiveConfig(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("data_source_id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	config, err := h.retrievalConfigService.WithContext(c.UserContext()).Resolve(uint(id))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to resolve retrieval config", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Retrieval config retrieved successfully", config)
+}
+
+// SetConfig godoc
+// @Summary Set a retrieval config (Admin only)
+// @Description Create or replace the retrieval config of the workspace default, or of a data source: top K of tables and columns, KPIs and glossary terms (0 leaves the section out), the schema element types, the minimum similarity, and the context and prompt token budgets
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param config body models.RetrievalConfigRequest true "Retrieval config"
+// @Success 200 {object} models.StandardResponse{data=models.RetrievalConfig}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/retrieval-configs [put]
+func (h *RetrievalConfigHandler) SetConfig(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.RetrievalConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	config, err := h.retrievalConfigService.WithContext(c.UserContext()).Set(adminID, &req)
+	if err != nil {
+		return retrievalConfigErrorResponse(c, "Failed to save retrieval config", err)
+	}
+
+	return entity.SuccessResponse(c, "Retrieval config saved successfully", config)
+}
+
+// DeleteConfig godoc
+// @Summary Delete a retrieval config (Admin only)
+// @Description Remove the retrieval config of the workspace default, or of a data source, which then falls back to the workspace default or the built-in defaults
+// @Tags admin
+// @Produce json
+// @Param data_source_id query int false "Data source of the config; omit for the workspace default"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/retrieval-configs [delete]
+func (h *RetrievalConfigHandler) DeleteConfig(c *fiber.Ctx) error {
+	var dataSourceID *uint
+	if raw := c.Query("data_source_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+		}
+		scope := uint(id)
+		dataSourceID = &scope
+	}
+
+	if err := h.retrievalConfigService.WithContext(c.UserContext()).Delete(dataSourceID); err != nil {
+		return retrievalConfigErrorResponse(c, "Failed to delete retrieval config", err)
+	}
+
+	return entity.SuccessResponse(c, "Retrieval config deleted successfully", nil)
+}
+
+// retrievalConfigErrorResponse maps retrieval config service errors to responses
+func retrievalConfigErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrRetrievalConfigNotFound):
+		return entity.NotFoundResponse(c, "Retrieval config not found")
+	case errors.Is(err, services.ErrRetrievalDataSourceNotFound):
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
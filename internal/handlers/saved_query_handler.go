@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SavedQueryHandler struct {
+	savedQueryService *services.SavedQueryService
+	auditService      *services.AuditService
+	validator         *validator.Validate
+}
+
+func NewSavedQueryHandler(savedQueryService *services.SavedQueryService, auditService *services.AuditService) *SavedQueryHandler {
+	return &SavedQueryHandler{
+		savedQueryService: savedQueryService,
+		auditService:      auditService,
+		validator:         validator.New(),
+	}
+}
+
+// GetSavedQueries godoc
+// @Summary List saved queries
+// @Description List the user's saved queries and those shared with the workspace, most recently updated first
+// @Tags nl2sql
+// @Produce json
+// @Param tag query string false "Only saved queries with this tag"
+// @Param data_source_id query int false "Only saved queries of this data source"
+// @Param search query string false "Match the name, description or question"
+// @Param mine query bool false "Only the user's own saved queries"
+// @Success 200 {object} models.StandardResponse{data=[]models.SavedQueryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/saved-queries [get]
+func (h *SavedQueryHandler) GetSavedQueries(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var filter entity.SavedQueryFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid query parameters", err.Error())
+	}
+
+	saved, err := h.savedQueryService.WithContext(c.UserContext()).List(userID, &filter)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve saved queries", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Saved queries retrieved successfully", saved)
+}
+
+// GetSavedQuery godoc
+// @Summary Get a saved query
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Saved query ID"
+// @Success 200 {object} models.StandardResponse{data=models.SavedQueryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/saved-queries/{id} [get]
+func (h *SavedQueryHandler) GetSavedQuery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid saved query ID", err.Error())
+	}
+
+	saved, err := h.savedQueryService.WithContext(c.UserContext()).Get(userID, uint(id))
+	if err != nil {
+		return savedQueryErrorResponse(c, "Failed to retrieve saved query", err)
+	}
+
+	return entity.SuccessResponse(c, "Saved query retrieved successfully", saved)
+}
+
+// CreateSavedQuery godoc
+// @Summary Save a query
+// @Description Save a question with its SQL on a data source, or an entry of the query history through query_id. The SQL is validated against the data source and pinned.
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param saved_query body models.SavedQueryRequest true "Saved query"
+// @Success 201 {object} models.StandardResponse{data=models.SavedQueryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/saved-queries [post]
+func (h *SavedQueryHandler) CreateSavedQuery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.SavedQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	saved, err := h.savedQueryService.WithContext(c.UserContext()).Create(userID, &req)
+	if err != nil {
+		return savedQueryErrorResponse(c, "Failed to save query", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Query saved successfully",
+		Data:    saved,
+	})
+}
+
+// UpdateSavedQuery godoc
+// @Summary Update a saved query
+// @Description Replace a saved query; only its owner can update it
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved query ID"
+// @Param saved_query body models.SavedQueryRequest true "Saved query"
+// @Success 200 {object} models.StandardResponse{data=models.SavedQueryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/saved-queries/{id} [put]
+func (h *SavedQueryHandler) UpdateSavedQuery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid saved query ID", err.Error())
+	}
+
+	var req entity.SavedQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	saved, err := h.savedQueryService.WithContext(c.UserContext()).Update(userID, uint(id), &req)
+	if err != nil {
+		return savedQueryErrorResponse(c, "Failed to update saved query", err)
+	}
+
+	return entity.SuccessResponse(c, "Saved query updated successfully", saved)
+}
+
+// DeleteSavedQuery godoc
+// @Summary Delete a saved query
+// @Description Remove a saved query; only its owner can delete it. Query history is kept.
+// @Tags nl2sql
+// @Produce json
+// @Param id path int true "Saved query ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/saved-queries/{id} [delete]
+func (h *SavedQueryHandler) DeleteSavedQuery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid saved query ID", err.Error())
+	}
+
+	if err := h.savedQueryService.WithContext(c.UserContext()).Delete(userID, uint(id)); err != nil {
+		return savedQueryErrorResponse(c, "Failed to delete saved query", err)
+	}
+
+	return entity.SuccessResponse(c, "Saved query deleted successfully", nil)
+}
+
+// RunSavedQuery godoc
+// @Summary Run a saved query
+// @Description Execute the pinned SQL of a saved query on fresh data. The run is added to the caller's query history; cost ceilings and PII masking apply to the caller.
+// @Tags nl2sql
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved query ID"
+// @Param run body models.SavedQueryRunRequest false "Execution options"
+// @Success 200 {object} models.StandardResponse{data=models.QueryExecutionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /nl2sql/saved-queries/{id}/run [post]
+func (h *SavedQueryHandler) RunSavedQuery(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid saved query ID", err.Error())
+	}
+
+	var req entity.SavedQueryRunRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+		}
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	req.UnmaskPII = middleware.CanUnmaskPII(c)
+
+	response, err := h.savedQueryService.WithContext(c.UserContext()).Run(userID, uint(id), &req)
+	if err != nil {
+		return savedQueryErrorResponse(c, "Failed to run saved query", err)
+	}
+
+	details := map[string]interface{}{
+		"saved_query_id":    id,
+		"sql":               response.ExecutedSQL,
+		"status":            response.Status,
+		"row_count":         response.RowCount,
+		"execution_time_ms": response.ExecutionTime,
+	}
+	if response.ResultID != 0 {
+		details["result_id"] = response.ResultID
+	}
+	if saved, err := h.savedQueryService.WithContext(c.UserContext()).Get(userID, uint(id)); err == nil {
+		details["data_source_id"] = saved.DataSourceID
+		details["nl_query"] = saved.NLQuery
+		if response.ExecutedSQL == "" {
+			details["sql"] = saved.SQL
+		}
+	}
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionQueryExecute, "nl2sql_query", response.QueryID, nil, nil, details)
+
+	return entity.SuccessResponse(c, "Saved query executed", response)
+}
+
+// savedQueryErrorResponse maps saved query service errors to responses
+func savedQueryErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSavedQueryNotFound):
+		return entity.NotFoundResponse(c, "Saved query not found")
+	case errors.Is(err, services.ErrSavedQueryDataSource):
+		return entity.NotFoundResponse(c, "Data source not found or not active")
+	case errors.Is(err, services.ErrSavedQueryInvalid),
+		errors.Is(err, services.ErrQueryCostExceeded),
+		errors.Is(err, services.ErrInvalidQueryParameters):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
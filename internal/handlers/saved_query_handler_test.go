@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/services"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// savedQueryTestUsers maps the users of the test to their tenants
+var savedQueryTestUsers = map[uint]uint{1: 1, 2: 1, 3: 2}
+
+func newSavedQueryTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: entity.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&entity.SavedQuery{}, &entity.DataSource{}))
+
+	nl2sqlService := services.NewNL2SQLService(db, nil, nil, nil, nil, nil)
+	handler := NewSavedQueryHandler(services.NewSavedQueryService(db, nl2sqlService), services.NewAuditService(db))
+
+	app := fiber.New()
+	// Authenticates the user of X-User and scopes the request to their tenant, like the auth and tenancy middleware
+	app.Use(func(c *fiber.Ctx) error {
+		var userID uint
+		fmt.Sscan(c.Get("X-User"), &userID)
+		c.Locals("user_id", userID)
+		c.SetUserContext(tenancy.WithTenant(c.UserContext(), savedQueryTestUsers[userID]))
+		return c.Next()
+	})
+	app.Get("/saved-queries", handler.GetSavedQueries)
+	app.Get("/saved-queries/:id", handler.GetSavedQuery)
+	app.Post("/saved-queries/:id/run", handler.RunSavedQuery)
+	return app, db
+}
+
+func TestSavedQueriesAreNotSharedAcrossTenants(t *testing.T) {
+	app, db := newSavedQueryTestApp(t)
+	shared := &entity.SavedQuery{UserID: 1, TenantID: 1, DataSourceID: 1, Name: "Revenue by month", SQL: "SELECT 1", Shared: true}
+	require.NoError(t, db.Create(shared).Error)
+	require.NoError(t, db.Create(&entity.DataSource{UserID: 1, TenantID: 1, Name: "warehouse", Type: entity.DataSourceTypePostgreSQL, Status: entity.ConnectionStatusActive}).Error)
+
+	request := func(method, path string, userID uint) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-User", fmt.Sprint(userID))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	list := func(userID uint) []entity.SavedQueryResponse {
+		resp := request(fiber.MethodGet, "/saved-queries", userID)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body struct {
+			Data []entity.SavedQueryResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Data
+	}
+
+	// A colleague in the same tenant sees the shared query
+	colleague := list(2)
+	require.Len(t, colleague, 1)
+	assert.Equal(t, shared.ID, colleague[0].ID)
+	assert.Equal(t, fiber.StatusOK, request(fiber.MethodGet, fmt.Sprintf("/saved-queries/%d", shared.ID), 2).StatusCode)
+
+	// A user of another tenant can neither list, get nor run it
+	assert.Empty(t, list(3))
+	assert.Equal(t, fiber.StatusNotFound, request(fiber.MethodGet, fmt.Sprintf("/saved-queries/%d", shared.ID), 3).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, request(fiber.MethodPost, fmt.Sprintf("/saved-queries/%d/run", shared.ID), 3).StatusCode)
+
+	var saved entity.SavedQuery
+	require.NoError(t, db.First(&saved, shared.ID).Error)
+	assert.Zero(t, saved.RunCount, "the query did not run")
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	models "narapulse-be/internal/models/entity"
@@ -11,63 +12,70 @@ import (
 // SchemaSyncHandler handles schema synchronization API endpoints
 type SchemaSyncHandler struct {
 	schemaSyncService *services.SchemaSyncService
+	jobService        *services.JobService
+	scheduler         *services.SchemaSyncScheduler
 }
 
 // NewSchemaSyncHandler creates a new schema sync handler
-func NewSchemaSyncHandler(schemaSyncService *services.SchemaSyncService) *SchemaSyncHandler {
+func NewSchemaSyncHandler(schemaSyncService *services.SchemaSyncService, jobService *services.JobService, scheduler *services.SchemaSyncScheduler) *SchemaSyncHandler {
 	return &SchemaSyncHandler{
 		schemaSyncService: schemaSyncService,
+		jobService:        jobService,
+		scheduler:         scheduler,
 	}
 }
 
+// enqueueSyncAll queues a sync of all data sources, or returns the one that is already queued or running
+func (h *SchemaSyncHandler) enqueueSyncAll(c *fiber.Ctx, trigger models.SchemaSyncTrigger) error {
+	job, err := h.jobService.EnqueueUnique(c.UserContext(), models.JobTypeSchemaSyncAll, models.JobTypeSchemaSyncAll,
+		models.SchemaSyncJobPayload{Trigger: trigger})
+	if err != nil {
+		return models.InternalServerErrorResponse(c, "Failed to queue sync of all data sources", err.Error())
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return models.SuccessResponse(c, "Sync of all data sources queued", job)
+}
+
 // GetSyncStatus returns synchronization status for all data sources
 // @Summary Get synchronization status
-// @Description Get the synchronization status for all active data sources
+// @Description Get the synchronization status for all active data sources, and the schedule: whether scheduled syncs are enabled, when the next one is queued and the last sync of all data sources
 // @Tags Schema Sync
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]interface{} "Sync status retrieved successfully"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Success 200 {object} models.StandardResponse{data=object} "Sync status retrieved successfully"
+// @Failure 500 {object} models.StandardResponse "Internal server error"
 // @Router /api/v1/schema-sync/status [get]
 func (h *SchemaSyncHandler) GetSyncStatus(c *fiber.Ctx) error {
-	status, err := h.schemaSyncService.GetSyncStatus()
+	status, err := h.schemaSyncService.WithContext(c.UserContext()).GetSyncStatus()
+	if err != nil {
+		return models.InternalServerErrorResponse(c, "Failed to get sync status", err.Error())
+	}
+
+	schedule, err := h.scheduler.Schedule()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SYNC_STATUS_ERROR",
-			Message: "Failed to get sync status",
-			Details: err.Error(),
-		})
+		return models.InternalServerErrorResponse(c, "Failed to get sync schedule", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(map[string]interface{}{
-		"message": "Sync status retrieved successfully",
-		"data":    status,
+	return models.SuccessResponse(c, "Sync status retrieved successfully", map[string]interface{}{
+		"data_sources": status,
+		"schedule":     schedule,
 	})
 }
 
 // TriggerSyncAll triggers synchronization for all data sources
 // @Summary Trigger sync for all data sources
-// @Description Manually trigger synchronization for all active data sources
+// @Description Queue a background synchronization of all active data sources. The queued job is returned; while it is queued or running, the same job is returned.
 // @Tags Schema Sync
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]interface{} "Sync triggered successfully"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /api/v1/schema-sync/trigger-all [post]
+// @Success 202 {object} models.StandardResponse{data=object} "Sync queued"
+// @Failure 500 {object} models.StandardResponse "Internal server error"
+// @Router /api/v1/schema-sync/trigger [post]
 func (h *SchemaSyncHandler) TriggerSyncAll(c *fiber.Ctx) error {
-	if err := h.schemaSyncService.SyncAllDataSources(c.Context()); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SYNC_ALL_ERROR",
-			Message: "Failed to sync all data sources",
-			Details: err.Error(),
-		})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(map[string]interface{}{
-		"message": "Sync triggered successfully for all data sources",
-	})
+	return h.enqueueSyncAll(c, models.SchemaSyncTriggerManual)
 }
 
 // TriggerSync triggers synchronization for a specific data source
@@ -78,31 +86,26 @@ func (h *SchemaSyncHandler) TriggerSyncAll(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param data_source_id path int true "Data Source ID"
-// @Success 200 {object} map[string]interface{} "Sync triggered successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid data source ID"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Success 200 {object} models.StandardResponse{data=object} "Sync triggered successfully"
+// @Failure 400 {object} models.StandardResponse "Invalid data source ID"
+// @Failure 409 {object} models.StandardResponse "Sync already in progress on another instance"
+// @Failure 500 {object} models.StandardResponse "Internal server error"
 // @Router /api/v1/schema-sync/trigger/{data_source_id} [post]
 func (h *SchemaSyncHandler) TriggerSync(c *fiber.Ctx) error {
 	dataSourceIDStr := c.Params("data_source_id")
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Invalid data source ID",
-			Details: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
 	}
 
-	if err := h.schemaSyncService.TriggerSync(c.Context(), uint(dataSourceID)); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SYNC_ERROR",
-			Message: "Failed to sync data source",
-			Details: err.Error(),
-		})
+	if err := h.schemaSyncService.WithContext(c.UserContext()).TriggerSync(c.Context(), uint(dataSourceID)); err != nil {
+		if errors.Is(err, services.ErrSyncInProgress) {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeSyncInProgress, "Data source is already being synced", err.Error())
+		}
+		return models.InternalServerErrorResponse(c, "Failed to sync data source", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(map[string]interface{}{
-		"message": "Sync triggered successfully",
+	return models.SuccessResponse(c, "Sync triggered successfully", map[string]interface{}{
 		"data_source_id": dataSourceID,
 	})
 }
@@ -115,67 +118,79 @@ func (h *SchemaSyncHandler) TriggerSync(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param data_source_id path int true "Data Source ID"
-// @Success 200 {object} map[string]interface{} "Sync status retrieved successfully"
-// @Failure 400 {object} models.ErrorResponse "Invalid data source ID"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Success 200 {object} models.StandardResponse{data=object} "Sync status retrieved successfully"
+// @Failure 400 {object} models.StandardResponse "Invalid data source ID"
+// @Failure 404 {object} models.StandardResponse "Data source not found or not active"
+// @Failure 500 {object} models.StandardResponse "Internal server error"
 // @Router /api/v1/schema-sync/status/{data_source_id} [get]
 func (h *SchemaSyncHandler) GetDataSourceSyncStatus(c *fiber.Ctx) error {
 	dataSourceIDStr := c.Params("data_source_id")
 	dataSourceID, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    "INVALID_DATA_SOURCE_ID",
-			Message: "Invalid data source ID",
-			Details: err.Error(),
-		})
+		return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
 	}
 
 	// Get all sync status and filter for the specific data source
-	allStatus, err := h.schemaSyncService.GetSyncStatus()
+	allStatus, err := h.schemaSyncService.WithContext(c.UserContext()).GetSyncStatus()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SYNC_STATUS_ERROR",
-			Message: "Failed to get sync status",
-			Details: err.Error(),
-		})
+		return models.InternalServerErrorResponse(c, "Failed to get sync status", err.Error())
 	}
 
 	// Find the specific data source status
 	for _, status := range allStatus {
 		if status.DataSourceID == uint(dataSourceID) {
-			return c.Status(fiber.StatusOK).JSON(map[string]interface{}{
-				"message": "Sync status retrieved successfully",
-				"data":    status,
-			})
+			return models.SuccessResponse(c, "Sync status retrieved successfully", status)
 		}
 	}
 
-	return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-		Code:    "DATA_SOURCE_NOT_FOUND",
-		Message: "Data source not found or not active",
-	})
+	return models.NotFoundResponse(c, "Data source not found or not active")
 }
 
 // ScheduledSync endpoint for triggering scheduled synchronization
 // @Summary Trigger scheduled sync
-// @Description Trigger scheduled synchronization (typically called by cron jobs)
+// @Description Queue a scheduled synchronization (typically called by cron jobs when SCHEMA_SYNC_ENABLED is false)
 // @Tags Schema Sync
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]interface{} "Scheduled sync completed successfully"
-// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Success 202 {object} models.StandardResponse{data=object} "Scheduled sync queued"
+// @Failure 500 {object} models.StandardResponse "Internal server error"
 // @Router /api/v1/schema-sync/scheduled [post]
 func (h *SchemaSyncHandler) ScheduledSync(c *fiber.Ctx) error {
-	if err := h.schemaSyncService.ScheduledSync(c.Context()); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    "SCHEDULED_SYNC_ERROR",
-			Message: "Scheduled sync failed",
-			Details: err.Error(),
-		})
+	return h.enqueueSyncAll(c, models.SchemaSyncTriggerScheduled)
+}
+
+// GetSyncHistory returns recent schema sync runs of the user's data sources
+// @Summary Get sync history
+// @Description Get recent schema sync runs of your data sources including the sync ID and the instance that ran them
+// @Tags Schema Sync
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param data_source_id query int false "Filter by data source ID"
+// @Param limit query int false "Maximum number of runs to return" default(20)
+// @Success 200 {object} models.StandardResponse{data=object} "Sync history retrieved successfully"
+// @Failure 400 {object} models.StandardResponse "Invalid data source ID"
+// @Failure 500 {object} models.StandardResponse "Internal server error"
+// @Router /api/v1/schema-sync/history [get]
+func (h *SchemaSyncHandler) GetSyncHistory(c *fiber.Ctx) error {
+	var dataSourceID uint64
+	if dataSourceIDStr := c.Query("data_source_id"); dataSourceIDStr != "" {
+		id, err := strconv.ParseUint(dataSourceIDStr, 10, 32)
+		if err != nil {
+			return models.ErrorResponseWithCode(c, models.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+		}
+		dataSourceID = id
+	}
+
+	userID := c.Locals("user_id").(uint)
+	runs, err := h.schemaSyncService.WithContext(c.UserContext()).GetSyncHistory(userID, uint(dataSourceID), c.QueryInt("limit", 20))
+	if err != nil {
+		return models.InternalServerErrorResponse(c, "Failed to get sync history", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(map[string]interface{}{
-		"message": "Scheduled sync completed successfully",
+	return models.SuccessResponse(c, "Sync history retrieved successfully", map[string]interface{}{
+		"instance_id": h.schemaSyncService.WithContext(c.UserContext()).InstanceID(),
+		"runs":        runs,
 	})
 }
\ No newline at end of file
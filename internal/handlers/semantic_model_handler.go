@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SemanticModelHandler struct {
+	semanticLayer *services.SemanticLayerService
+	validator     *validator.Validate
+}
+
+func NewSemanticModelHandler(semanticLayer *services.SemanticLayerService) *SemanticModelHandler {
+	return &SemanticModelHandler{
+		semanticLayer: semanticLayer,
+		validator:     validator.New(),
+	}
+}
+
+// GetModel godoc
+// @Summary Get the semantic model of a data source
+// @Description List the metrics and dimensions NL2SQL compiles to SQL for a data source
+// @Tags admin
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Success 200 {object} models.StandardResponse{data=models.SemanticModelResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model [get]
+func (h *SemanticModelHandler) GetModel(c *fiber.Ctx) error {
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	model, err := h.semanticLayer.WithContext(c.UserContext()).GetModel(uint(dataSourceID))
+	if err != nil {
+		return semanticModelErrorResponse(c, "Failed to retrieve semantic model", err)
+	}
+
+	return entity.SuccessResponse(c, "Semantic model retrieved successfully", model)
+}
+
+// CreateMetric godoc
+// @Summary Define a metric
+// @Description Define a metric as an aggregate over a table of the data source, with an optional filter and default time column
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param metric body models.SemanticMetricRequest true "Metric"
+// @Success 201 {object} models.StandardResponse{data=models.SemanticMetricResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model/metrics [post]
+func (h *SemanticModelHandler) CreateMetric(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.SemanticMetricRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	metric, err := h.semanticLayer.WithContext(c.UserContext()).CreateMetric(adminID, uint(dataSourceID), &req)
+	if err != nil {
+		return semanticModelErrorResponse(c, "Failed to create metric", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Metric created successfully", metric)
+}
+
+// UpdateMetric godoc
+// @Summary Update a metric
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param metricId path int true "Metric ID"
+// @Param metric body models.SemanticMetricRequest true "Metric"
+// @Success 200 {object} models.StandardResponse{data=models.SemanticMetricResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model/metrics/{metricId} [put]
+func (h *SemanticModelHandler) UpdateMetric(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, metricID, err := parseSemanticParams(c, "metricId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	var req entity.SemanticMetricRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	metric, err := h.semanticLayer.WithContext(c.UserContext()).UpdateMetric(adminID, dataSourceID, metricID, &req)
+	if err != nil {
+		return semanticModelErrorResponse(c, "Failed to update metric", err)
+	}
+
+	return entity.SuccessResponse(c, "Metric updated successfully", metric)
+}
+
+// DeleteMetric godoc
+// @Summary Delete a metric
+// @Description Remove a metric; questions about it are generated by the LLM again
+// @Tags admin
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param metricId path int true "Metric ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model/metrics/{metricId} [delete]
+func (h *SemanticModelHandler) DeleteMetric(c *fiber.Ctx) error {
+	dataSourceID, metricID, err := parseSemanticParams(c, "metricId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	if err := h.semanticLayer.WithContext(c.UserContext()).DeleteMetric(dataSourceID, metricID); err != nil {
+		return semanticModelErrorResponse(c, "Failed to delete metric", err)
+	}
+
+	return entity.SuccessResponse(c, "Metric deleted successfully", nil)
+}
+
+// CreateDimension godoc
+// @Summary Define a dimension
+// @Description Define a column metrics can be broken down by; time dimensions are bucketed by the grain asked for
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param dimension body models.SemanticDimensionRequest true "Dimension"
+// @Success 201 {object} models.StandardResponse{data=models.SemanticDimensionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model/dimensions [post]
+func (h *SemanticModelHandler) CreateDimension(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.SemanticDimensionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	dimension, err := h.semanticLayer.WithContext(c.UserContext()).CreateDimension(adminID, uint(dataSourceID), &req)
+	if err != nil {
+		return semanticModelErrorResponse(c, "Failed to create dimension", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Dimension created successfully", dimension)
+}
+
+// UpdateDimension godoc
+// @Summary Update a dimension
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param dimensionId path int true "Dimension ID"
+// @Param dimension body models.SemanticDimensionRequest true "Dimension"
+// @Success 200 {object} models.StandardResponse{data=models.SemanticDimensionResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model/dimensions/{dimensionId} [put]
+func (h *SemanticModelHandler) UpdateDimension(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	dataSourceID, dimensionID, err := parseSemanticParams(c, "dimensionId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	var req entity.SemanticDimensionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	dimension, err := h.semanticLayer.WithContext(c.UserContext()).UpdateDimension(adminID, dataSourceID, dimensionID, &req)
+	if err != nil {
+		return semanticModelErrorResponse(c, "Failed to update dimension", err)
+	}
+
+	return entity.SuccessResponse(c, "Dimension updated successfully", dimension)
+}
+
+// DeleteDimension godoc
+// @Summary Delete a dimension
+// @Tags admin
+// @Produce json
+// @Param id path int true "Data source ID"
+// @Param dimensionId path int true "Dimension ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/data-sources/{id}/semantic-model/dimensions/{dimensionId} [delete]
+func (h *SemanticModelHandler) DeleteDimension(c *fiber.Ctx) error {
+	dataSourceID, dimensionID, err := parseSemanticParams(c, "dimensionId")
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid ID", err.Error())
+	}
+
+	if err := h.semanticLayer.WithContext(c.UserContext()).DeleteDimension(dataSourceID, dimensionID); err != nil {
+		return semanticModelErrorResponse(c, "Failed to delete dimension", err)
+	}
+
+	return entity.SuccessResponse(c, "Dimension deleted successfully", nil)
+}
+
+func parseSemanticParams(c *fiber.Ctx, param string) (uint, uint, error) {
+	dataSourceID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.ParseUint(c.Params(param), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(dataSourceID), uint(id), nil
+}
+
+// semanticModelErrorResponse maps semantic layer service errors to responses
+func semanticModelErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSemanticMetricNotFound),
+		errors.Is(err, services.ErrSemanticDimensionNotFound),
+		errors.Is(err, services.ErrSemanticDataSourceNotFound):
+		return entity.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrInvalidSemanticModel):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShareHandler struct {
+	shareService *services.ShareService
+	auditService *services.AuditService
+	validator    *validator.Validate
+}
+
+func NewShareHandler(shareService *services.ShareService, auditService *services.AuditService) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+		auditService: auditService,
+		validator:    validator.New(),
+	}
+}
+
+// GetShares godoc
+// @Summary List share links
+// @Description List the public share links created by the current user, including expired and revoked ones
+// @Tags shares
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ShareLinkResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /shares [get]
+func (h *ShareHandler) GetShares(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	links, err := h.shareService.WithContext(c.UserContext()).List(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve share links", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Share links retrieved successfully", links)
+}
+
+// CreateShare godoc
+// @Summary Create a share link
+// @Description Share a query result or a dashboard read-only with anyone holding the link. The link expires (default 7 days), shows at most row_limit rows per result (default 100) with PII masked, and may require a password. The URL is only returned here.
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param share body models.ShareLinkRequest true "Share link"
+// @Success 201 {object} models.StandardResponse{data=models.ShareLinkResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 403 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /shares [post]
+func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.ShareLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	link, err := h.shareService.WithContext(c.UserContext()).Create(userID, &req)
+	if err != nil {
+		return shareErrorResponse(c, "Failed to create share link", err)
+	}
+
+	resourceType, resourceID := "nl2sql_query", req.QueryID
+	if req.ResourceType == entity.ShareResourceDashboard {
+		resourceType, resourceID = "dashboard", req.DashboardID
+	}
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionDataExport, resourceType, resourceID, nil, nil,
+		map[string]interface{}{"destination": "share_link", "share_link_id": link.ID, "expires_at": link.ExpiresAt, "password": link.HasPassword})
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Share link created successfully", link)
+}
+
+// RevokeShare godoc
+// @Summary Revoke a share link
+// @Description The link stops working at once
+// @Tags shares
+// @Produce json
+// @Param id path int true "Share link ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /shares/{id} [delete]
+func (h *ShareHandler) RevokeShare(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid share link ID", err.Error())
+	}
+
+	if err := h.shareService.WithContext(c.UserContext()).Revoke(userID, uint(id)); err != nil {
+		return shareErrorResponse(c, "Failed to revoke share link", err)
+	}
+
+	return entity.SuccessResponse(c, "Share link revoked successfully", nil)
+}
+
+// ViewShare godoc
+// @Summary View a shared query result or dashboard
+// @Description Public, read-only view of a share link. Password-protected links need the X-Share-Password header.
+// @Tags shares
+// @Produce json
+// @Param token path string true "Share token"
+// @Param X-Share-Password header string false "Password of a protected link"
+// @Success 200 {object} models.StandardResponse{data=models.SharedResourceResponse}
+// @Failure 401 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 410 {object} models.StandardResponse
+// @Failure 429 {object} models.StandardResponse
+// @Router /public/shares/{token} [get]
+func (h *ShareHandler) ViewShare(c *fiber.Ctx) error {
+	shared, err := h.shareService.WithContext(c.UserContext()).View(c.Params("token"), c.Get("X-Share-Password"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShareNotFound):
+			return entity.NotFoundResponse(c, "Share link not found")
+		case errors.Is(err, services.ErrShareExpired):
+			return entity.ErrorResponseWithStatus(c, fiber.StatusGone, "Share link has expired or was revoked", nil)
+		case errors.Is(err, services.ErrSharePasswordRequired):
+			return entity.UnauthorizedResponse(c, "Password required")
+		case errors.Is(err, services.ErrQueryResultNotFound), errors.Is(err, services.ErrQueryResultArchived):
+			return entity.ErrorResponseWithStatus(c, fiber.StatusGone, "The shared result is no longer available", nil)
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to load share link", err.Error())
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return entity.SuccessResponse(c, "Shared content retrieved successfully", shared)
+}
+
+func shareErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrShareNotFound):
+		return entity.NotFoundResponse(c, "Share link not found")
+	case errors.Is(err, services.ErrDashboardNotFound):
+		return entity.NotFoundResponse(c, "Dashboard not found")
+	case errors.Is(err, services.ErrDashboardOwnerOnly):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusForbidden, "Only the dashboard owner can share it", nil)
+	case errors.Is(err, services.ErrQueryResultNotFound):
+		return entity.NotFoundResponse(c, "Query result not found")
+	case errors.Is(err, services.ErrQueryResultArchived):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, "Query result is archived; rehydrate it first", nil)
+	case err.Error() == "query not found":
+		return entity.NotFoundResponse(c, "Query not found")
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SuggestedQuestionHandler struct {
+	suggestedQuestionService *services.SuggestedQuestionService
+	dataSourceService        services.DataSourceService
+	validator                *validator.Validate
+}
+
+func NewSuggestedQuestionHandler(suggestedQuestionService *services.SuggestedQuestionService, dataSourceService services.DataSourceService) *SuggestedQuestionHandler {
+	return &SuggestedQuestionHandler{
+		suggestedQuestionService: suggestedQuestionService,
+		dataSourceService:        dataSourceService,
+		validator:                validator.New(),
+	}
+}
+
+// GetSuggestedQuestions godoc
+// @Summary Suggested questions
+// @Description Example questions to seed the ask-a-question UI: the curated ones, then the most frequently asked and the most recent questions that converted to valid SQL over the last 90 days
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param limit query int false "Questions returned (default 10, max 50)"
+// @Success 200 {object} models.StandardResponse{data=[]models.QuestionSuggestion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/suggested-questions [get]
+func (h *SuggestedQuestionHandler) GetSuggestedQuestions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	suggestions, err := h.suggestedQuestionService.WithContext(c.UserContext()).Suggest(uint(id), c.QueryInt("limit", 0))
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve suggested questions", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Suggested questions retrieved successfully", suggestions)
+}
+
+// CreateSuggestedQuestion godoc
+// @Summary Curate a suggested question
+// @Description Add an example question to the data source; curated questions are suggested first, in ascending position
+// @Tags data-sources
+// @Accept json
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param question body models.SuggestedQuestionRequest true "Question"
+// @Success 201 {object} models.StandardResponse{data=models.SuggestedQuestion}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/suggested-questions [post]
+func (h *SuggestedQuestionHandler) CreateSuggestedQuestion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+
+	var req entity.SuggestedQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	question, err := h.suggestedQuestionService.WithContext(c.UserContext()).Create(uint(id), userID, &req)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to create suggested question", err.Error())
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Suggested question created successfully", question)
+}
+
+// DeleteSuggestedQuestion godoc
+// @Summary Remove a curated question
+// @Description Remove a curated example question of the data source
+// @Tags data-sources
+// @Produce json
+// @Param id path int true "Data Source ID"
+// @Param questionId path int true "Suggested question ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /data-sources/{id}/suggested-questions/{questionId} [delete]
+func (h *SuggestedQuestionHandler) DeleteSuggestedQuestion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid data source ID", err.Error())
+	}
+	questionID, err := strconv.ParseUint(c.Params("questionId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid suggested question ID", err.Error())
+	}
+
+	if _, err := h.dataSourceService.WithContext(c.UserContext()).GetDataSource(uint(id), userID); err != nil {
+		return entity.NotFoundResponse(c, "Data source not found")
+	}
+
+	if err := h.suggestedQuestionService.WithContext(c.UserContext()).Delete(uint(id), uint(questionID)); err != nil {
+		if errors.Is(err, services.ErrSuggestedQuestionNotFound) {
+			return entity.NotFoundResponse(c, "Suggested question not found")
+		}
+		return entity.InternalServerErrorResponse(c, "Failed to delete suggested question", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Suggested question deleted successfully", nil)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TenantHandler struct {
+	tenantService *services.TenantService
+	validator     *validator.Validate
+}
+
+func NewTenantHandler(tenantService *services.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		validator:     validator.New(),
+	}
+}
+
+// GetCurrentTenant godoc
+// @Summary Get the tenant of the user
+// @Description Get the organization of the authenticated user with its feature flags, so clients hide features switched off for it
+// @Tags tenants
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.Tenant}
+// @Security ApiKeyAuth
+// @Router /tenant [get]
+func (h *TenantHandler) GetCurrentTenant(c *fiber.Ctx) error {
+	return entity.SuccessResponse(c, "Tenant retrieved successfully", middleware.GetTenantFromContext(c))
+}
+
+// ListTenants godoc
+// @Summary List tenants (Admin only)
+// @Description List the organizations sharing the installation. Only administrators of the default tenant manage tenants.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.Tenant}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants [get]
+func (h *TenantHandler) ListTenants(c *fiber.Ctx) error {
+	tenants, err := h.tenantService.ListTenants(c.UserContext())
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve tenants", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Tenants retrieved successfully", tenants)
+}
+
+// CreateTenant godoc
+// @Summary Create a tenant (Admin only)
+// @Description Create an organization whose users and data are isolated from the other tenants, optionally served on its own domain. Users registering on the domain join the tenant.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param tenant body models.TenantCreateRequest true "Tenant"
+// @Success 201 {object} models.StandardResponse{data=models.Tenant}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants [post]
+func (h *TenantHandler) CreateTenant(c *fiber.Ctx) error {
+	var req entity.TenantCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	tenant, err := h.tenantService.CreateTenant(c.UserContext(), &req)
+	if err != nil {
+		return tenantErrorResponse(c, "Failed to create tenant", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entity.StandardResponse{
+		Success: true,
+		Message: "Tenant created successfully",
+		Data:    tenant,
+	})
+}
+
+// UpdateTenant godoc
+// @Summary Update a tenant (Admin only)
+// @Description Change the name, domain, feature flags or active state of a tenant. Requests of users of a deactivated tenant are refused; the default tenant cannot be deactivated.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Tenant ID"
+// @Param tenant body models.TenantUpdateRequest true "Changes"
+// @Success 200 {object} models.StandardResponse{data=models.Tenant}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Failure 409 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/tenants/{id} [put]
+func (h *TenantHandler) UpdateTenant(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid tenant ID", err.Error())
+	}
+
+	var req entity.TenantUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(c.UserContext(), uint(id), &req)
+	if err != nil {
+		return tenantErrorResponse(c, "Failed to update tenant", err)
+	}
+
+	return entity.SuccessResponse(c, "Tenant updated successfully", tenant)
+}
+
+// tenantErrorResponse maps tenant service errors to responses
+func tenantErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrTenantNotFound):
+		return entity.NotFoundResponse(c, "Tenant not found")
+	case errors.Is(err, services.ErrTenantSlugTaken), errors.Is(err, services.ErrTenantDomainTaken):
+		return entity.ErrorResponseWithStatus(c, fiber.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrDefaultTenantDeactivation):
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeBadRequest, err.Error(), nil)
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UsageHandler struct {
+	usageService *services.UsageService
+	validator    *validator.Validate
+}
+
+func NewUsageHandler(usageService *services.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+		validator:    validator.New(),
+	}
+}
+
+// GetUsage godoc
+// @Summary Get AI usage
+// @Description Get the tokens and estimated cost of the current user's embedding and LLM calls per day, with the monthly quota status
+// @Tags usage
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to the start of the month"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} models.StandardResponse{data=models.UsageSummary}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /usage [get]
+func (h *UsageHandler) GetUsage(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	from, to, err := parseUsageRange(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid date range", err.Error())
+	}
+
+	summary, err := h.usageService.WithContext(c.UserContext()).Summary(userID, from, to)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get usage", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Usage retrieved successfully", summary)
+}
+
+// GetQuota godoc
+// @Summary Get AI usage quota
+// @Description Get the current user's month-to-date AI usage against the monthly quota
+// @Tags usage
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=models.UsageQuotaStatus}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /usage/quota [get]
+func (h *UsageHandler) GetQuota(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	status, err := h.usageService.WithContext(c.UserContext()).QuotaStatus(userID)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get usage quota", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Usage quota retrieved successfully", status)
+}
+
+// GetWorkspaceUsage godoc
+// @Summary Get workspace AI usage
+// @Description Get the tokens and estimated cost of AI calls per user and day
+// @Tags admin
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to the start of the month"
+// @Param to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} models.StandardResponse{data=[]models.UsageDailyTotal}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/usage [get]
+func (h *UsageHandler) GetWorkspaceUsage(c *fiber.Ctx) error {
+	from, to, err := parseUsageRange(c)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid date range", err.Error())
+	}
+
+	daily, err := h.usageService.WithContext(c.UserContext()).WorkspaceDaily(from, to)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get usage", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Usage retrieved successfully", daily)
+}
+
+// SetUserQuota godoc
+// @Summary Set a user AI usage quota
+// @Description Override the default monthly AI token and cost quota of a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param quota body models.UsageQuotaRequest true "Monthly limits (0 disables a limit)"
+// @Success 200 {object} models.StandardResponse{data=models.UsageQuota}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/usage-quota [put]
+func (h *UsageHandler) SetUserQuota(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	var req entity.UsageQuotaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	quota, err := h.usageService.WithContext(c.UserContext()).SetQuota(uint(id), adminID, &req)
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to set usage quota", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Usage quota updated successfully", quota)
+}
+
+// DeleteUserQuota godoc
+// @Summary Remove a user AI usage quota
+// @Description Return a user to the default monthly AI quota
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/usage-quota [delete]
+func (h *UsageHandler) DeleteUserQuota(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	if err := h.usageService.WithContext(c.UserContext()).DeleteQuota(uint(id)); err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to delete usage quota", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Usage quota deleted successfully", nil)
+}
+
+// parseUsageRange reads the inclusive from and to dates of the query string as
+// a half-open range, defaulting to the current month up to now
+func parseUsageRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return from, to, err
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return from, to, err
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	return from, to, nil
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"narapulse-be/internal/middleware"
 	entity "narapulse-be/internal/models/entity"
 	"narapulse-be/internal/repositories"
 	"narapulse-be/internal/services"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -13,19 +15,26 @@ import (
 )
 
 type UserHandler struct {
-	userService services.UserService
-	validator   *validator.Validate
+	userService  services.UserService
+	auditService *services.AuditService
+	validator    *validator.Validate
 }
 
-func NewUserHandler(db *gorm.DB) *UserHandler {
+func NewUserHandler(db *gorm.DB, auditService *services.AuditService) *UserHandler {
 	userRepo := repositories.NewUserRepository(db)
 	userService := services.NewUserService(userRepo)
 	return &UserHandler{
-		userService: userService,
-		validator:   validator.New(),
+		userService:  userService,
+		auditService: auditService,
+		validator:    validator.New(),
 	}
 }
 
+// users returns the user service scoped to the tenant of the request
+func (h *UserHandler) users(c *fiber.Ctx) services.UserService {
+	return h.userService.WithContext(c.UserContext())
+}
+
 // GetProfile godoc
 // @Summary Get user profile
 // @Description Get the profile of the authenticated user
@@ -44,7 +53,7 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 		return entity.UnauthorizedResponse(c, err.Error())
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.users(c).GetUserByID(userID)
 	if err != nil {
 		return entity.NotFoundResponse(c, err.Error())
 	}
@@ -74,15 +83,15 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 	var req entity.UserUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return entity.BadRequestResponse(c, "Invalid request body", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
 	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
-		return entity.BadRequestResponse(c, "Validation failed", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
 	}
 
-	user, err := h.userService.UpdateUser(userID, &req)
+	user, err := h.users(c).UpdateUser(userID, &req)
 	if err != nil {
 		return entity.BadRequestResponse(c, "Failed to update profile", err.Error())
 	}
@@ -92,25 +101,160 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 
 // GetAllUsers godoc
 // @Summary Get all users (Admin only)
-// @Description Get a paginated list of all users
+// @Description Get a paginated list of users, optionally searched and filtered
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(10)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Param search query string false "Matched against email, username, first and last name"
+// @Param role query string false "Role" Enums(admin, user)
+// @Param is_active query bool false "Active state"
+// @Param sort query string false "Sort column" Enums(created_at, email, username, first_name, last_name, role) default(created_at)
+// @Param order query string false "Sort order" Enums(asc, desc) default(desc)
 // @Success 200 {object} entity.StandardResponse{data=[]entity.UserResponse}
+// @Failure 400 {object} entity.StandardResponse
 // @Failure 401 {object} entity.StandardResponse
 // @Failure 403 {object} entity.StandardResponse
 // @Failure 500 {object} entity.StandardResponse
 // @Router /admin/users [get]
 func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
-	users, err := h.userService.GetAllUsers()
+	filter := entity.UserListFilter{
+		Search: strings.TrimSpace(c.Query("search")),
+		Role:   c.Query("role"),
+		Sort:   c.Query("sort"),
+		Order:  strings.ToLower(c.Query("order")),
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+	}
+	if isActive := c.Query("is_active"); isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return entity.BadRequestResponse(c, "Invalid is_active", err.Error())
+		}
+		filter.IsActive = &active
+	}
+
+	users, total, err := h.users(c).ListUsers(&filter)
 	if err != nil {
 		return entity.InternalServerErrorResponse(c, "Failed to retrieve users", err.Error())
 	}
 
-	return entity.SuccessResponse(c, "Users retrieved successfully", users)
+	meta := &entity.Meta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      int(total),
+		TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+	}
+	return entity.SuccessResponseWithMeta(c, "Users retrieved successfully", users, meta)
+}
+
+// UpdateUser godoc
+// @Summary Update user (Admin only)
+// @Description Change the role or active state of a user; deactivated users cannot log in. Changes that would leave no active admin are rejected. Changes are recorded in the audit trail.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param user body entity.UserAdminUpdateRequest true "Fields to change"
+// @Success 200 {object} entity.StandardResponse{data=entity.User}
+// @Failure 400 {object} entity.StandardResponse
+// @Failure 401 {object} entity.StandardResponse
+// @Failure 403 {object} entity.StandardResponse
+// @Failure 404 {object} entity.StandardResponse
+// @Router /admin/users/{id} [patch]
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	var req entity.UserAdminUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+	if req.Role == nil && req.IsActive == nil {
+		return entity.BadRequestResponse(c, "Nothing to update", "role or is_active is required")
+	}
+
+	// Admins could otherwise lock themselves out
+	if uint(userID) == adminID && req.IsActive != nil && !*req.IsActive {
+		return entity.BadRequestResponse(c, "You cannot deactivate your own account", nil)
+	}
+
+	// Snapshot the user for the audit trail before changing it
+	before, err := h.users(c).GetUserByID(uint(userID))
+	if err != nil {
+		return entity.NotFoundResponse(c, "User not found")
+	}
+
+	user, err := h.users(c).UpdateUserAdmin(uint(userID), &req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to update user", err.Error())
+	}
+
+	actor := middleware.GetAuditActor(c)
+	if before.Role != user.Role {
+		h.auditService.Log(actor, entity.AuditActionUserRoleChange, "user", user.ID, before, user, nil)
+	}
+	if before.IsActive != user.IsActive {
+		h.auditService.Log(actor, entity.AuditActionUserStatusChange, "user", user.ID, before, user, nil)
+	}
+
+	return entity.SuccessResponse(c, "User updated successfully", user)
+}
+
+// BulkDeactivateUsers godoc
+// @Summary Deactivate users (Admin only)
+// @Description Deactivate several users at once; deactivated users cannot log in. Deactivations that would leave no active admin are rejected.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body entity.UserBulkDeactivateRequest true "Users to deactivate"
+// @Success 200 {object} entity.StandardResponse{data=entity.UserBulkDeactivateResponse}
+// @Failure 400 {object} entity.StandardResponse
+// @Failure 401 {object} entity.StandardResponse
+// @Failure 403 {object} entity.StandardResponse
+// @Router /admin/users/bulk-deactivate [post]
+func (h *UserHandler) BulkDeactivateUsers(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.UserBulkDeactivateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	for _, id := range req.UserIDs {
+		if id == adminID {
+			return entity.BadRequestResponse(c, "You cannot deactivate your own account", nil)
+		}
+	}
+
+	deactivated, err := h.users(c).DeactivateUsers(req.UserIDs)
+	if errors.Is(err, services.ErrLastAdmin) {
+		return entity.BadRequestResponse(c, "Failed to deactivate users", err.Error())
+	}
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to deactivate users", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionUserStatusChange, "user", 0, nil, nil,
+		map[string]interface{}{"deactivated_user_ids": req.UserIDs, "deactivated": deactivated})
+
+	return entity.SuccessResponse(c, "Users deactivated successfully", entity.UserBulkDeactivateResponse{Deactivated: deactivated})
 }
 
 // DeleteUser godoc
@@ -132,12 +276,66 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	userIDStr := c.Params("id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
-		return entity.BadRequestResponse(c, "Invalid user ID", err.Error())
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	// Snapshot the user for the audit trail before deleting it
+	before, err := h.users(c).GetUserByID(uint(userID))
+	if err != nil {
+		return entity.NotFoundResponse(c, "User not found")
 	}
 
-	if err := h.userService.DeleteUser(uint(userID)); err != nil {
+	if err := h.users(c).DeleteUser(uint(userID)); err != nil {
 		return entity.BadRequestResponse(c, "Failed to delete user", err.Error())
 	}
 
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionUserDelete, "user", uint(userID), before, nil, nil)
+
 	return entity.SuccessResponse(c, "User deleted successfully", nil)
-}
\ No newline at end of file
+}
+
+// UpdateUserRole godoc
+// @Summary Change user role (Admin only)
+// @Description Change the role of a user; the change is recorded in the audit trail
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role body entity.UserRoleUpdateRequest true "New role"
+// @Success 200 {object} entity.StandardResponse{data=entity.User}
+// @Failure 400 {object} entity.StandardResponse
+// @Failure 401 {object} entity.StandardResponse
+// @Failure 403 {object} entity.StandardResponse
+// @Failure 404 {object} entity.StandardResponse
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid user ID", err.Error())
+	}
+
+	var req entity.UserRoleUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	// Snapshot the user for the audit trail before changing the role
+	before, err := h.users(c).GetUserByID(uint(userID))
+	if err != nil {
+		return entity.NotFoundResponse(c, "User not found")
+	}
+
+	user, err := h.users(c).UpdateUserRole(uint(userID), req.Role)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to change user role", err.Error())
+	}
+
+	h.auditService.Log(middleware.GetAuditActor(c), entity.AuditActionUserRoleChange, "user", user.ID, before, user, nil)
+
+	return entity.SuccessResponse(c, "User role updated successfully", user)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/services"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// userTestTenants maps the users of the test to their tenants
+var userTestTenants = map[uint]uint{1: 1, 2: 1, 3: 1, 4: 2}
+
+func newUserTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: entity.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&entity.User{}, &entity.AuditLog{}))
+
+	for id, role := range map[uint]string{1: "admin", 2: "user", 3: "admin", 4: "admin"} {
+		require.NoError(t, db.Create(&entity.User{ID: id, TenantID: userTestTenants[id], Email: fmt.Sprintf("user%d@narapulse.com", id),
+			Username: fmt.Sprintf("user%d", id), Password: "x", Role: role, IsActive: true}).Error)
+	}
+
+	handler := NewUserHandler(db, services.NewAuditService(db))
+	app := fiber.New()
+	// Authenticates the admin of X-User and scopes the request to their tenant, like the auth and tenancy middleware
+	app.Use(func(c *fiber.Ctx) error {
+		var userID uint
+		fmt.Sscan(c.Get("X-User"), &userID)
+		c.Locals("user_id", userID)
+		c.SetUserContext(tenancy.WithTenant(c.UserContext(), userTestTenants[userID]))
+		return c.Next()
+	})
+	app.Patch("/admin/users/:id", handler.UpdateUser)
+	app.Post("/admin/users/bulk-deactivate", handler.BulkDeactivateUsers)
+	return app, db
+}
+
+func userTestRequest(t *testing.T, app *fiber.App, method, path, body string, userID uint) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set("X-User", fmt.Sprint(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func activeAdmins(t *testing.T, db *gorm.DB, tenantID uint) []uint {
+	t.Helper()
+	var ids []uint
+	require.NoError(t, db.Model(&entity.User{}).Where("tenant_id = ? AND role = ? AND is_active = ?", tenantID, "admin", true).
+		Order("id").Pluck("id", &ids).Error)
+	return ids
+}
+
+func TestUpdateUserKeepsAnActiveAdmin(t *testing.T) {
+	app, db := newUserTestApp(t)
+
+	// Demoting another admin is fine while one remains
+	resp := userTestRequest(t, app, fiber.MethodPatch, "/admin/users/3", `{"role":"user"}`, 1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, []uint{1}, activeAdmins(t, db, 1))
+
+	// The last admin can demote neither themselves nor be deactivated by another user,
+	// and admins of other tenants do not count
+	resp = userTestRequest(t, app, fiber.MethodPatch, "/admin/users/1", `{"role":"user"}`, 1)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	resp = userTestRequest(t, app, fiber.MethodPatch, "/admin/users/1", `{"is_active":false}`, 3)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []uint{1}, activeAdmins(t, db, 1))
+
+	// Once another admin is active again the former last one may step down
+	resp = userTestRequest(t, app, fiber.MethodPatch, "/admin/users/2", `{"role":"admin"}`, 1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp = userTestRequest(t, app, fiber.MethodPatch, "/admin/users/1", `{"role":"user"}`, 1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, []uint{2}, activeAdmins(t, db, 1))
+}
+
+func TestBulkDeactivateUsersKeepsAnActiveAdmin(t *testing.T) {
+	app, db := newUserTestApp(t)
+
+	// Deactivating an admin is fine while another remains
+	resp := userTestRequest(t, app, fiber.MethodPost, "/admin/users/bulk-deactivate", `{"user_ids":[2,3]}`, 1)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, []uint{1}, activeAdmins(t, db, 1))
+	resp = userTestRequest(t, app, fiber.MethodPatch, "/admin/users/2", `{"is_active":true}`, 1)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Deactivating the last admin is rejected as a whole, and admins of other tenants do not count
+	resp = userTestRequest(t, app, fiber.MethodPost, "/admin/users/bulk-deactivate", `{"user_ids":[1,2]}`, 3)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []uint{1}, activeAdmins(t, db, 1))
+	var user entity.User
+	require.NoError(t, db.First(&user, 2).Error)
+	assert.True(t, user.IsActive, "nothing is deactivated when the last admin would be")
+	assert.Equal(t, []uint{4}, activeAdmins(t, db, 2))
+}
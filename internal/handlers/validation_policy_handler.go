@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ValidationPolicyHandler struct {
+	policyService *services.ValidationPolicyService
+	validator     *validator.Validate
+}
+
+func NewValidationPolicyHandler(policyService *services.ValidationPolicyService) *ValidationPolicyHandler {
+	return &ValidationPolicyHandler{
+		policyService: policyService,
+		validator:     validator.New(),
+	}
+}
+
+// GetPolicies godoc
+// @Summary List SQL validation policies
+// @Description List the workspace default policy and all data source policies
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.ValidationPolicyResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/validation-policies [get]
+func (h *ValidationPolicyHandler) GetPolicies(c *fiber.Ctx) error {
+	policies, err := h.policyService.WithContext(c.UserContext()).List()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to get validation policies", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Validation policies retrieved successfully", policies)
+}
+
+// CreatePolicy godoc
+// @Summary Create a SQL validation policy
+// @Description Create the policy of a data source, or the workspace default policy when data_source_id is omitted
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param policy body models.ValidationPolicyRequest true "Validation policy"
+// @Success 201 {object} models.StandardResponse{data=models.ValidationPolicyResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/validation-policies [post]
+func (h *ValidationPolicyHandler) CreatePolicy(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	var req entity.ValidationPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	policy, err := h.policyService.WithContext(c.UserContext()).Create(adminID, &req)
+	if err != nil {
+		return entity.BadRequestResponse(c, "Failed to create validation policy", err.Error())
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Validation policy created successfully", policy)
+}
+
+// GetPolicy godoc
+// @Summary Get a SQL validation policy
+// @Description Get a validation policy by ID
+// @Tags admin
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Success 200 {object} models.StandardResponse{data=models.ValidationPolicyResponse}
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/validation-policies/{id} [get]
+func (h *ValidationPolicyHandler) GetPolicy(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid policy ID", err.Error())
+	}
+
+	policy, err := h.policyService.WithContext(c.UserContext()).Get(uint(id))
+	if err != nil {
+		return policyErrorResponse(c, "Failed to get validation policy", err)
+	}
+
+	return entity.SuccessResponse(c, "Validation policy retrieved successfully", policy)
+}
+
+// UpdatePolicy godoc
+// @Summary Update a SQL validation policy
+// @Description Replace the rules of a validation policy; its data source cannot change
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Param policy body models.ValidationPolicyRequest true "Validation policy"
+// @Success 200 {object} models.StandardResponse{data=models.ValidationPolicyResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/validation-policies/{id} [put]
+func (h *ValidationPolicyHandler) UpdatePolicy(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid policy ID", err.Error())
+	}
+
+	var req entity.ValidationPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	policy, err := h.policyService.WithContext(c.UserContext()).Update(adminID, uint(id), &req)
+	if err != nil {
+		return policyErrorResponse(c, "Failed to update validation policy", err)
+	}
+
+	return entity.SuccessResponse(c, "Validation policy updated successfully", policy)
+}
+
+// DeletePolicy godoc
+// @Summary Delete a SQL validation policy
+// @Description Delete a validation policy; its data source falls back to the workspace default
+// @Tags admin
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/validation-policies/{id} [delete]
+func (h *ValidationPolicyHandler) DeletePolicy(c *fiber.Ctx) error {
+	adminID := c.Locals("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid policy ID", err.Error())
+	}
+
+	if err := h.policyService.WithContext(c.UserContext()).Delete(adminID, uint(id)); err != nil {
+		return policyErrorResponse(c, "Failed to delete validation policy", err)
+	}
+
+	return entity.SuccessResponse(c, "Validation policy deleted successfully", nil)
+}
+
+func policyErrorResponse(c *fiber.Ctx, message string, err error) error {
+	if errors.Is(err, services.ErrValidationPolicyNotFound) {
+		return entity.NotFoundResponse(c, err.Error())
+	}
+	return entity.BadRequestResponse(c, message, err.Error())
+}
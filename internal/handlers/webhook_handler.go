@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	services "narapulse-be/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	validator      *validator.Validate
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator.New(),
+	}
+}
+
+// GetWebhooks godoc
+// @Summary List webhooks (admin)
+// @Description List the webhook subscriptions to platform events; secrets are never returned
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} models.StandardResponse{data=[]models.WebhookResponse}
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.webhookService.WithContext(c.UserContext()).List()
+	if err != nil {
+		return entity.InternalServerErrorResponse(c, "Failed to retrieve webhooks", err.Error())
+	}
+
+	return entity.SuccessResponse(c, "Webhooks retrieved successfully", webhooks)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook (admin)
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.StandardResponse{data=models.WebhookResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid webhook ID", err.Error())
+	}
+
+	webhook, err := h.webhookService.WithContext(c.UserContext()).Get(uint(id))
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to retrieve webhook", err)
+	}
+
+	return entity.SuccessResponse(c, "Webhook retrieved successfully", webhook)
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook (admin)
+// @Description Subscribe an HTTPS endpoint to query.completed, datasource.error, schema.changed and alert.triggered events. The signing secret is generated unless given and only returned here.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookRequest true "Webhook"
+// @Success 201 {object} models.StandardResponse{data=models.WebhookResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 500 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint)
+
+	var req entity.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	webhook, err := h.webhookService.WithContext(c.UserContext()).Create(userID, &req)
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to create webhook", err)
+	}
+
+	c.Status(fiber.StatusCreated)
+	return entity.SuccessResponse(c, "Webhook created successfully", webhook)
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook (admin)
+// @Description Replace a webhook; an empty secret keeps the current one
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body models.WebhookRequest true "Webhook"
+// @Success 200 {object} models.StandardResponse{data=models.WebhookResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid webhook ID", err.Error())
+	}
+
+	var req entity.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidRequestBody, "Invalid request body", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeValidationFailed, "Validation failed", err.Error())
+	}
+
+	webhook, err := h.webhookService.WithContext(c.UserContext()).Update(uint(id), &req)
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to update webhook", err)
+	}
+
+	return entity.SuccessResponse(c, "Webhook updated successfully", webhook)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook (admin)
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.StandardResponse
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid webhook ID", err.Error())
+	}
+
+	if err := h.webhookService.WithContext(c.UserContext()).Delete(uint(id)); err != nil {
+		return webhookErrorResponse(c, "Failed to delete webhook", err)
+	}
+
+	return entity.SuccessResponse(c, "Webhook deleted successfully", nil)
+}
+
+// PingWebhook godoc
+// @Summary Ping a webhook (admin)
+// @Description Queue a signed ping event to the webhook; its outcome shows in the delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 202 {object} models.StandardResponse{data=models.WebhookDeliveryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id}/ping [post]
+func (h *WebhookHandler) PingWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid webhook ID", err.Error())
+	}
+
+	delivery, err := h.webhookService.WithContext(c.UserContext()).Ping(c.UserContext(), uint(id))
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to ping webhook", err)
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return entity.SuccessResponse(c, "Ping queued", delivery)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary List webhook deliveries (admin)
+// @Description The delivery log of a webhook, newest first, with the outcome of the latest attempt of each delivery
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending, succeeded or failed"
+// @Param limit query int false "Deliveries to return (default 50, max 200)"
+// @Success 200 {object} models.StandardResponse{data=[]models.WebhookDeliveryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid webhook ID", err.Error())
+	}
+
+	status := entity.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", entity.WebhookDeliveryPending, entity.WebhookDeliverySucceeded, entity.WebhookDeliveryFailed:
+	default:
+		return entity.BadRequestResponse(c, "Invalid status", "status must be pending, succeeded or failed")
+	}
+
+	deliveries, err := h.webhookService.WithContext(c.UserContext()).ListDeliveries(uint(id), status, c.QueryInt("limit"))
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to retrieve webhook deliveries", err)
+	}
+
+	return entity.SuccessResponse(c, "Webhook deliveries retrieved successfully", deliveries)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook event (admin)
+// @Description Queue a delivery again with its original body
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} models.StandardResponse{data=models.WebhookDeliveryResponse}
+// @Failure 400 {object} models.StandardResponse
+// @Failure 404 {object} models.StandardResponse
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid webhook ID", err.Error())
+	}
+	deliveryID, err := strconv.ParseUint(c.Params("deliveryId"), 10, 32)
+	if err != nil {
+		return entity.ErrorResponseWithCode(c, entity.ErrorCodeInvalidParameter, "Invalid delivery ID", err.Error())
+	}
+
+	delivery, err := h.webhookService.WithContext(c.UserContext()).Redeliver(c.UserContext(), uint(id), uint(deliveryID))
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to redeliver webhook event", err)
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return entity.SuccessResponse(c, "Redelivery queued", delivery)
+}
+
+func webhookErrorResponse(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		return entity.NotFoundResponse(c, "Webhook not found")
+	case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		return entity.NotFoundResponse(c, "Webhook delivery not found")
+	}
+	return entity.InternalServerErrorResponse(c, message, err.Error())
+}
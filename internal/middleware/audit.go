@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	entity "narapulse-be/internal/models/entity"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAuditActor identifies the user and client of the request for the audit
+// trail. The user is zero for requests that did not pass AuthMiddleware.
+func GetAuditActor(c *fiber.Ctx) entity.AuditActor {
+	actor := entity.AuditActor{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+	if userID, ok := c.Locals("user_id").(uint); ok {
+		actor.UserID = userID
+	}
+	if email, ok := c.Locals("user_email").(string); ok {
+		actor.Email = email
+	}
+	if requestID, ok := c.Locals("request_id").(string); ok {
+		actor.RequestID = requestID
+	}
+	return actor
+}
@@ -1,19 +1,47 @@
 package middleware
 
 import (
+	"context"
 	"narapulse-be/internal/config"
 	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
 	"narapulse-be/internal/pkg/utils"
+	"narapulse-be/internal/pkg/websocket"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthMiddleware validates JWT token
-func AuthMiddleware() fiber.Handler {
+// AccountResolver loads the users tokens were issued to
+type AccountResolver interface {
+	// GetAccount returns nil when the user no longer exists
+	GetAccount(ctx context.Context, id uint) (*entity.User, error)
+}
+
+// AuthMiddleware validates JWT token. Only access tokens are accepted; tokens
+// issued while a login still needs a second factor are refused.
+func AuthMiddleware(accounts AccountResolver) fiber.Handler {
+	return authenticate(accounts, utils.TokenPurposeAccess)
+}
+
+// MFAEnrollmentMiddleware validates access tokens as well as the enrollment
+// tokens issued to users who must set up MFA before they can log in. The
+// "mfa_enrollment" local is true for enrollment tokens.
+func MFAEnrollmentMiddleware(accounts AccountResolver) fiber.Handler {
+	return authenticate(accounts, utils.TokenPurposeAccess, utils.TokenPurposeMFAEnrollment)
+}
+
+// authenticate validates the bearer JWT token and that it was issued for one
+// of the purposes. The user is loaded on every request, so deactivated or
+// deleted users are refused and role changes apply before their tokens expire.
+func authenticate(accounts AccountResolver, purposes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get token from Authorization header
+		// Get token from Authorization header. Browsers cannot set headers on
+		// WebSocket handshakes, so those may pass it as the access_token query parameter.
 		authHeader := c.Get("Authorization")
+		if authHeader == "" && websocket.IsUpgrade(c) && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
 		if authHeader == "" {
 			return entity.UnauthorizedResponse(c, "Authorization header is required")
 		}
@@ -35,16 +63,54 @@ func AuthMiddleware() fiber.Handler {
 		if err != nil {
 			return entity.UnauthorizedResponse(c, "Invalid or expired token")
 		}
+		if !tokenPurposeAllowed(claims.Purpose, purposes) {
+			if claims.Purpose == utils.TokenPurposeMFAEnrollment {
+				return entity.ErrorResponseWithCode(c, entity.ErrorCodeMFARequired, "MFA enrollment required", nil)
+			}
+			if claims.Purpose == utils.TokenPurposeEmbed {
+				return entity.UnauthorizedResponse(c, "Embed tokens only grant access to embed routes")
+			}
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeMFARequired, "MFA verification required", nil)
+		}
+
+		account, err := accounts.GetAccount(c.UserContext(), claims.UserID)
+		if err != nil {
+			logger.FromContext(c.UserContext()).Error().Err(err).Uint("user_id", claims.UserID).Msg("Failed to load user of token")
+			return entity.InternalServerErrorResponse(c, "Failed to authenticate", err.Error())
+		}
+		if account == nil || !account.IsActive {
+			return entity.UnauthorizedResponse(c, "User account is deactivated")
+		}
 
 		// Store user info in context
 		c.Locals("user_id", claims.UserID)
-		c.Locals("user_email", claims.Email)
-		c.Locals("user_role", claims.Role)
+		c.Locals("user_email", account.Email)
+		c.Locals("user_role", account.Role)
+		c.Locals("tenant_id", TokenTenant(claims))
+		c.Locals("mfa_enrollment", claims.Purpose == utils.TokenPurposeMFAEnrollment)
 
 		return c.Next()
 	}
 }
 
+// TokenTenant returns the tenant of the token; tokens issued before tenancy
+// belong to the default tenant
+func TokenTenant(claims *utils.Claims) uint {
+	if claims.TenantID == 0 {
+		return entity.DefaultTenantID
+	}
+	return claims.TenantID
+}
+
+func tokenPurposeAllowed(purpose string, purposes []string) bool {
+	for _, allowed := range purposes {
+		if purpose == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 // AdminMiddleware checks if user has admin role
 func AdminMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"narapulse-be/internal/config"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+	"narapulse-be/internal/repositories"
+	"narapulse-be/internal/services"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type fakeAccountResolver map[uint]*entity.User
+
+func (r fakeAccountResolver) GetAccount(_ context.Context, id uint) (*entity.User, error) {
+	return r[id], nil
+}
+
+// testAccounts has the active analyst the test tokens are issued to
+var testAccounts = fakeAccountResolver{1: {ID: 1, Email: "analyst@narapulse.com", Role: "user", IsActive: true}}
+
+func authTestStatus(t *testing.T, handler fiber.Handler, purpose string) int {
+	t.Helper()
+
+	token, err := utils.GenerateScopedToken(1, 1, "analyst@narapulse.com", "user", purpose, config.Load().JWTSecret, time.Minute)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/", handler, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestAuthMiddlewareOnlyAcceptsAccessTokens(t *testing.T) {
+	assert.Equal(t, fiber.StatusOK, authTestStatus(t, AuthMiddleware(testAccounts), utils.TokenPurposeAccess))
+	assert.Equal(t, fiber.StatusUnauthorized, authTestStatus(t, AuthMiddleware(testAccounts), utils.TokenPurposeMFA))
+	assert.Equal(t, fiber.StatusUnauthorized, authTestStatus(t, AuthMiddleware(testAccounts), utils.TokenPurposeMFAEnrollment))
+}
+
+func TestMFAEnrollmentMiddlewareAcceptsEnrollmentTokens(t *testing.T) {
+	assert.Equal(t, fiber.StatusOK, authTestStatus(t, MFAEnrollmentMiddleware(testAccounts), utils.TokenPurposeAccess))
+	assert.Equal(t, fiber.StatusOK, authTestStatus(t, MFAEnrollmentMiddleware(testAccounts), utils.TokenPurposeMFAEnrollment))
+	assert.Equal(t, fiber.StatusUnauthorized, authTestStatus(t, MFAEnrollmentMiddleware(testAccounts), utils.TokenPurposeMFA))
+}
+
+func TestAuthMiddlewareAcceptsQueryTokenOnWebSocketHandshakes(t *testing.T) {
+	token, err := utils.GenerateToken(1, 1, "analyst@narapulse.com", "user", config.Load().JWTSecret)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/", AuthMiddleware(testAccounts), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest(fiber.MethodGet, "/?access_token="+token, nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Other requests keep tokens out of URLs
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/?access_token="+token, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuthMiddlewareAppliesAccountChangesToIssuedTokens(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&entity.User{}))
+	users := services.NewUserService(repositories.NewUserRepository(db))
+	analyst := &entity.User{Email: "analyst@narapulse.com", Username: "analyst", Password: "x", Role: "user", IsActive: true}
+	admin := &entity.User{Email: "admin@narapulse.com", Username: "admin", Password: "x", Role: "admin", IsActive: true}
+	require.NoError(t, db.Create(analyst).Error)
+	require.NoError(t, db.Create(admin).Error)
+	// Another admin remains, so the admin can be demoted
+	require.NoError(t, db.Create(&entity.User{Email: "owner@narapulse.com", Username: "owner", Password: "x", Role: "admin", IsActive: true}).Error)
+
+	app := fiber.New()
+	app.Get("/profile", AuthMiddleware(users), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Get("/admin", AuthMiddleware(users), AdminMiddleware(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	// Tokens are issued before the changes and keep carrying the old state
+	analystToken, err := utils.GenerateToken(analyst.ID, 1, analyst.Email, analyst.Role, config.Load().JWTSecret)
+	require.NoError(t, err)
+	adminToken, err := utils.GenerateToken(admin.ID, 1, admin.Email, admin.Role, config.Load().JWTSecret)
+	require.NoError(t, err)
+	status := func(path, token string) int {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	require.Equal(t, fiber.StatusOK, status("/profile", analystToken))
+	require.Equal(t, fiber.StatusOK, status("/admin", adminToken))
+
+	_, err = users.DeactivateUsers([]uint{analyst.ID})
+	require.NoError(t, err)
+	role := "user"
+	_, err = users.UpdateUserAdmin(admin.ID, &entity.UserAdminUpdateRequest{Role: &role})
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusUnauthorized, status("/profile", analystToken), "deactivated users are refused")
+	assert.Equal(t, fiber.StatusForbidden, status("/admin", adminToken), "demoted admins lose admin routes")
+	assert.Equal(t, fiber.StatusOK, status("/profile", adminToken))
+
+	require.NoError(t, users.DeleteUser(admin.ID))
+	assert.Equal(t, fiber.StatusUnauthorized, status("/profile", adminToken), "deleted users are refused")
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Authorizer decides whether a subject may call a path with a method
+type Authorizer interface {
+	Enforce(sub, obj, act string) (bool, error)
+}
+
+// CasbinMiddleware authorizes the request path and method against the route
+// policies. It must run after AuthMiddleware. The request is allowed when the
+// user's email or the role stored on the user is granted it; policies are read
+// on every request, so changes apply without a restart. Authorization fails
+// closed: when the policies cannot be evaluated the request is refused.
+func CasbinMiddleware(authorizer Authorizer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var subjects []string
+		if email, ok := c.Locals("user_email").(string); ok && email != "" {
+			subjects = append(subjects, email)
+		}
+		if role, ok := c.Locals("user_role").(string); ok && role != "" {
+			subjects = append(subjects, role)
+		}
+		if len(subjects) == 0 {
+			return entity.UnauthorizedResponse(c, "User not authenticated")
+		}
+
+		for _, subject := range subjects {
+			allowed, err := authorizer.Enforce(subject, c.Path(), c.Method())
+			if err != nil {
+				logger.FromContext(c.UserContext()).Error().Err(err).Str("subject", subject).Str("method", c.Method()).Str("path", c.Path()).Msg("Failed to authorize request")
+				return entity.InternalServerErrorResponse(c, "Authorization failed", err.Error())
+			}
+			if allowed {
+				return c.Next()
+			}
+		}
+
+		return entity.ForbiddenResponse(c, "You do not have permission to access this resource")
+	}
+}
+
+// Seeing unmasked PII in query results needs a policy on this object and
+// action, e.g. "p, analyst@narapulse.com, pii, unmask". Route policies such as
+// "/api/v1/*" do not match it, so the permission is always explicit.
+const (
+	PIIUnmaskObject = "pii"
+	PIIUnmaskAction = "unmask"
+)
+
+// PIIUnmaskMiddleware records whether the user's email or role is granted the
+// PII unmask permission; read it with CanUnmaskPII. Without an authorizer
+// (Casbin unavailable) admins are granted it. Errors mask, like a refusal.
+func PIIUnmaskMiddleware(authorizer Authorizer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("user_role").(string)
+		if authorizer == nil {
+			c.Locals("pii_unmask", role == "admin")
+			return c.Next()
+		}
+
+		var subjects []string
+		if email, ok := c.Locals("user_email").(string); ok && email != "" {
+			subjects = append(subjects, email)
+		}
+		if role != "" {
+			subjects = append(subjects, role)
+		}
+
+		for _, subject := range subjects {
+			allowed, err := authorizer.Enforce(subject, PIIUnmaskObject, PIIUnmaskAction)
+			if err != nil {
+				logger.FromContext(c.UserContext()).Error().Err(err).Str("subject", subject).Msg("Failed to check PII unmask permission")
+				break
+			}
+			if allowed {
+				c.Locals("pii_unmask", true)
+				return c.Next()
+			}
+		}
+		c.Locals("pii_unmask", false)
+		return c.Next()
+	}
+}
+
+// CanUnmaskPII reports whether PIIUnmaskMiddleware granted the request unmasked PII
+func CanUnmaskPII(c *fiber.Ctx) bool {
+	unmask, _ := c.Locals("pii_unmask").(bool)
+	return unmask
+}
+
+// Retrieval traces of queries, which show the prompts and retrieved schemas of
+// other users, need a policy on this object and action, e.g.
+// "p, ml-engineer@narapulse.com, rag, trace"
+const (
+	RAGTraceObject = "rag"
+	RAGTraceAction = "trace"
+)
+
+// PermissionMiddleware refuses the request unless the user's email or role is
+// granted the action on the object. Like route policies, wildcards such as
+// "/api/v1/*" do not match it. Without an authorizer (Casbin unavailable) only
+// admins are allowed; errors refuse, like CasbinMiddleware.
+func PermissionMiddleware(authorizer Authorizer, object, action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("user_role").(string)
+		if authorizer == nil {
+			if role == "admin" {
+				return c.Next()
+			}
+			return entity.ForbiddenResponse(c, "You do not have permission to access this resource")
+		}
+
+		var subjects []string
+		if email, ok := c.Locals("user_email").(string); ok && email != "" {
+			subjects = append(subjects, email)
+		}
+		if role != "" {
+			subjects = append(subjects, role)
+		}
+		if len(subjects) == 0 {
+			return entity.UnauthorizedResponse(c, "User not authenticated")
+		}
+
+		for _, subject := range subjects {
+			allowed, err := authorizer.Enforce(subject, object, action)
+			if err != nil {
+				logger.FromContext(c.UserContext()).Error().Err(err).Str("subject", subject).Str("object", object).Str("action", action).Msg("Failed to check permission")
+				return entity.InternalServerErrorResponse(c, "Authorization failed", err.Error())
+			}
+			if allowed {
+				return c.Next()
+			}
+		}
+
+		return entity.ForbiddenResponse(c, "You do not have permission to access this resource")
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthorizer allows the subject/path/method combinations in its set
+type fakeAuthorizer struct {
+	allowed map[string]bool
+	err     error
+}
+
+func (a *fakeAuthorizer) Enforce(sub, obj, act string) (bool, error) {
+	return a.allowed[sub+" "+act+" "+obj], a.err
+}
+
+func newCasbinTestApp(authorizer Authorizer, email, role string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if email != "" {
+			c.Locals("user_email", email)
+		}
+		if role != "" {
+			c.Locals("user_role", role)
+		}
+		return c.Next()
+	})
+	app.Use(CasbinMiddleware(authorizer))
+	app.Get("/api/v1/admin/users", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+func casbinTestStatus(t *testing.T, app *fiber.App) int {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/admin/users", nil))
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestCasbinMiddlewareAppliesPolicyChangesWithoutRestart(t *testing.T) {
+	authorizer := &fakeAuthorizer{allowed: map[string]bool{}}
+	app := newCasbinTestApp(authorizer, "analyst@narapulse.com", "user")
+	assert.Equal(t, fiber.StatusForbidden, casbinTestStatus(t, app))
+
+	// Granted to the role
+	authorizer.allowed["user GET /api/v1/admin/users"] = true
+	assert.Equal(t, fiber.StatusOK, casbinTestStatus(t, app))
+
+	// Granted to the user only
+	delete(authorizer.allowed, "user GET /api/v1/admin/users")
+	authorizer.allowed["analyst@narapulse.com GET /api/v1/admin/users"] = true
+	assert.Equal(t, fiber.StatusOK, casbinTestStatus(t, app))
+
+	delete(authorizer.allowed, "analyst@narapulse.com GET /api/v1/admin/users")
+	assert.Equal(t, fiber.StatusForbidden, casbinTestStatus(t, app))
+}
+
+func TestCasbinMiddlewareRequiresUser(t *testing.T) {
+	app := newCasbinTestApp(&fakeAuthorizer{}, "", "")
+	assert.Equal(t, fiber.StatusUnauthorized, casbinTestStatus(t, app))
+}
+
+func TestCasbinMiddlewareFailsClosed(t *testing.T) {
+	authorizer := &fakeAuthorizer{
+		allowed: map[string]bool{"admin GET /api/v1/admin/users": true},
+		err:     errors.New("policy unavailable"),
+	}
+	app := newCasbinTestApp(authorizer, "admin@narapulse.com", "admin")
+	assert.Equal(t, fiber.StatusInternalServerError, casbinTestStatus(t, app))
+}
+
+func TestPIIUnmaskMiddleware(t *testing.T) {
+	unmaskStatus := func(authorizer Authorizer, email, role string) bool {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("user_email", email)
+			c.Locals("user_role", role)
+			return c.Next()
+		})
+		app.Use(PIIUnmaskMiddleware(authorizer))
+		app.Get("/", func(c *fiber.Ctx) error {
+			if CanUnmaskPII(c) {
+				return c.SendStatus(fiber.StatusOK)
+			}
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		require.NoError(t, err)
+		return resp.StatusCode == fiber.StatusOK
+	}
+
+	authorizer := &fakeAuthorizer{allowed: map[string]bool{"analyst@narapulse.com unmask pii": true}}
+	assert.True(t, unmaskStatus(authorizer, "analyst@narapulse.com", "user"))
+	assert.False(t, unmaskStatus(authorizer, "viewer@narapulse.com", "admin"))
+
+	// Errors mask
+	authorizer.err = errors.New("policy unavailable")
+	assert.False(t, unmaskStatus(authorizer, "analyst@narapulse.com", "user"))
+
+	// Without Casbin only admins see PII
+	assert.True(t, unmaskStatus(nil, "admin@narapulse.com", "admin"))
+	assert.False(t, unmaskStatus(nil, "analyst@narapulse.com", "user"))
+}
+
+func TestPermissionMiddleware(t *testing.T) {
+	traceStatus := func(authorizer Authorizer, email, role string) int {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("user_email", email)
+			c.Locals("user_role", role)
+			return c.Next()
+		})
+		app.Get("/trace", PermissionMiddleware(authorizer, RAGTraceObject, RAGTraceAction), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/trace", nil))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	authorizer := &fakeAuthorizer{allowed: map[string]bool{
+		"ml@narapulse.com trace rag":               true,
+		"admin GET /api/v1/nl2sql/queries/1/trace": true,
+	}}
+	assert.Equal(t, fiber.StatusOK, traceStatus(authorizer, "ml@narapulse.com", "user"))
+	// Route policies do not grant it, not even to admins
+	assert.Equal(t, fiber.StatusForbidden, traceStatus(authorizer, "admin@narapulse.com", "admin"))
+	assert.Equal(t, fiber.StatusUnauthorized, traceStatus(authorizer, "", ""))
+
+	// Errors refuse
+	authorizer.err = errors.New("policy unavailable")
+	assert.Equal(t, fiber.StatusInternalServerError, traceStatus(authorizer, "ml@narapulse.com", "user"))
+
+	// Without Casbin only admins are allowed
+	assert.Equal(t, fiber.StatusOK, traceStatus(nil, "admin@narapulse.com", "admin"))
+	assert.Equal(t, fiber.StatusForbidden, traceStatus(nil, "ml@narapulse.com", "user"))
+}
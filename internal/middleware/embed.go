@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"narapulse-be/internal/config"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EmbedMiddleware validates the embed token of embed routes, given as a bearer
+// token or, for iframes, as the embed_token query parameter. Tokens restricted
+// to domains are only accepted from pages on those domains, by the Origin or
+// else the Referer header. The claims are stored in the "embed_claims" local.
+func EmbedMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = c.Query("embed_token")
+		}
+		if token == "" {
+			return entity.UnauthorizedResponse(c, "Embed token is required")
+		}
+
+		claims, err := utils.ValidateEmbedToken(token, config.Load().JWTSecret)
+		if err != nil {
+			return entity.UnauthorizedResponse(c, "Invalid or expired embed token")
+		}
+
+		if len(claims.Domains) > 0 {
+			origin := c.Get(fiber.HeaderOrigin)
+			if origin == "" {
+				origin = c.Get(fiber.HeaderReferer)
+			}
+			if !embedOriginAllowed(origin, claims.Domains) {
+				return entity.ForbiddenResponse(c, "This embed token is not valid on this domain")
+			}
+			c.Set(fiber.HeaderContentSecurityPolicy, "frame-ancestors "+embedFrameAncestors(claims.Domains))
+		}
+
+		c.Locals("embed_claims", claims)
+		return c.Next()
+	}
+}
+
+// GetEmbedClaims returns the claims of the embed token of the request
+func GetEmbedClaims(c *fiber.Ctx) *utils.EmbedClaims {
+	claims, _ := c.Locals("embed_claims").(*utils.EmbedClaims)
+	return claims
+}
+
+// embedOriginAllowed reports whether the host of an origin or referer URL is
+// one of the domains; "*.example.com" matches every subdomain of example.com
+func embedOriginAllowed(origin string, domains []string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if suffix, ok := strings.CutPrefix(domain, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// embedFrameAncestors lists the domains as CSP frame-ancestors sources
+func embedFrameAncestors(domains []string) string {
+	sources := make([]string, len(domains))
+	for i, domain := range domains {
+		sources[i] = "https://" + domain
+	}
+	return strings.Join(sources, " ")
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"narapulse-be/internal/config"
+	"narapulse-be/internal/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func embedTestStatus(t *testing.T, token, origin string) int {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", EmbedMiddleware(), func(c *fiber.Ctx) error {
+		assert.Equal(t, uint(4), GetEmbedClaims(c).DashboardID)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/?embed_token="+token, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestEmbedMiddleware(t *testing.T) {
+	secret := config.Load().JWTSecret
+	open, _, err := utils.GenerateEmbedToken(utils.EmbedClaims{UserID: 1, DashboardID: 4}, secret, time.Minute)
+	require.NoError(t, err)
+	restricted, _, err := utils.GenerateEmbedToken(utils.EmbedClaims{UserID: 1, DashboardID: 4, Domains: []string{"*.example.com"}}, secret, time.Minute)
+	require.NoError(t, err)
+	access, err := utils.GenerateToken(1, 1, "analyst@narapulse.com", "user", secret)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, embedTestStatus(t, open, ""))
+	assert.Equal(t, fiber.StatusOK, embedTestStatus(t, restricted, "https://app.example.com"))
+	assert.Equal(t, fiber.StatusForbidden, embedTestStatus(t, restricted, "https://example.org"))
+	assert.Equal(t, fiber.StatusForbidden, embedTestStatus(t, restricted, ""))
+	assert.Equal(t, fiber.StatusUnauthorized, embedTestStatus(t, access, ""))
+	assert.Equal(t, fiber.StatusUnauthorized, embedTestStatus(t, "", ""))
+}
+
+func TestAuthMiddlewareRejectsEmbedTokens(t *testing.T) {
+	assert.Equal(t, fiber.StatusUnauthorized, authTestStatus(t, AuthMiddleware(testAccounts), utils.TokenPurposeEmbed))
+}
+
+func TestEmbedOriginAllowed(t *testing.T) {
+	domains := []string{"app.example.com", "*.customer.io"}
+
+	assert.True(t, embedOriginAllowed("https://app.example.com", domains))
+	assert.True(t, embedOriginAllowed("https://APP.example.com:8443/page?x=1", domains))
+	assert.True(t, embedOriginAllowed("https://eu.customer.io", domains))
+	assert.False(t, embedOriginAllowed("https://customer.io", domains))
+	assert.False(t, embedOriginAllowed("https://evil-app.example.com", domains))
+	assert.False(t, embedOriginAllowed("null", domains))
+}
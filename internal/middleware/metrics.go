@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"time"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MetricsMiddleware records the latency of every request by route pattern
+func MetricsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		metrics.ObserveHTTPRequest(c.Method(), c.Route().Path, status, time.Since(start))
+		return err
+	}
+}
+
+// MetricsAuthMiddleware requires the bearer token on the metrics endpoint; an empty token allows every scraper
+func MetricsAuthMiddleware(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Next()
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			return entity.UnauthorizedResponse(c, "Invalid metrics token")
+		}
+		return c.Next()
+	}
+}
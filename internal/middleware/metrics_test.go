@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"narapulse-be/internal/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddlewareLabelsRequestsByRoutePattern(t *testing.T) {
+	app := fiber.New()
+	app.Use(MetricsMiddleware())
+	app.Get("/api/v1/data-sources/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Get("/metrics", MetricsAuthMiddleware("scrape-token"), adaptor.HTTPHandler(metrics.Handler()))
+
+	for _, path := range []string{"/api/v1/data-sources/1", "/api/v1/data-sources/2"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-token")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `narapulse_http_request_duration_seconds_count{method="GET",route="/api/v1/data-sources/:id",status="200"} 2`)
+	assert.NotContains(t, string(body), `route="/api/v1/data-sources/1"`)
+}
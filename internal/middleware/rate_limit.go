@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Names of the limits guarding the authenticated API and the endpoints calling the AI
+const (
+	RateLimitAPI = "api"
+	RateLimitAI  = "ai"
+)
+
+// RateLimiter limits requests per user and per tenant with token buckets kept in a shared store
+type RateLimiter struct {
+	store ratelimit.Store
+}
+
+// NewRateLimiter creates a rate limiter on the store
+func NewRateLimiter(store ratelimit.Store) *RateLimiter {
+	return &RateLimiter{store: store}
+}
+
+// Limits are the limits of a route guard: per user, and per tenant, the
+// workspace its users share so one busy team cannot exhaust the instance.
+// A zero limit is not enforced.
+type Limits struct {
+	User   ratelimit.Limit
+	Tenant ratelimit.Limit
+}
+
+// Limit returns middleware that allows each user the limit on the routes it
+// guards. Routes guarded by the same name share a bucket; requests without a
+// user (before AuthMiddleware) are limited per client IP. The
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers are
+// set on every response, and Retry-After when the limit is exceeded. A store
+// failure lets the request through.
+func (r *RateLimiter) Limit(name string, limit ratelimit.Limit) fiber.Handler {
+	return r.LimitFunc(name, func() Limits { return Limits{User: limit} })
+}
+
+// LimitFunc is Limit with a tenant limit as well, the limits read on every
+// request so a changed limit applies without a restart. The tenant bucket is
+// only taken from when the user's allows the request; the headers describe
+// the bucket with the fewest requests left.
+func (r *RateLimiter) LimitFunc(name string, current func() Limits) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limits := current()
+		buckets := []rateLimitBucket{{key: rateLimitKey(name, c), limit: limits.User}}
+		if tenantID, ok := c.Locals("tenant_id").(uint); ok {
+			buckets = append(buckets, rateLimitBucket{key: tenantRateLimitKey(name, tenantID), limit: limits.Tenant, scope: " per workspace"})
+		}
+
+		tightest, err := r.take(c.UserContext(), name, buckets)
+		var exceeded *RateLimitExceededError
+		if errors.As(err, &exceeded) {
+			setRateLimitHeaders(c, exceeded.Result)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(exceeded.retryAfterSeconds()))
+			return entity.ErrorResponseWithStatus(c, fiber.StatusTooManyRequests, "Rate limit exceeded", exceeded.Error())
+		}
+		if tightest != nil {
+			setRateLimitHeaders(c, *tightest)
+		}
+		return c.Next()
+	}
+}
+
+// Take takes a request of a user of a tenant from the buckets the routes
+// guarded by Limit or LimitFunc with the same name take from, so requests
+// arriving other than through Fiber, such as gRPC calls, share the limits of
+// the REST routes. A tenant of 0 has no tenant bucket. It fails with a
+// *RateLimitExceededError when a bucket is empty; a store failure lets the
+// request through.
+func (r *RateLimiter) Take(ctx context.Context, name string, userID, tenantID uint, limits Limits) error {
+	buckets := []rateLimitBucket{{key: userRateLimitKey(name, userID), limit: limits.User}}
+	if tenantID != 0 {
+		buckets = append(buckets, rateLimitBucket{key: tenantRateLimitKey(name, tenantID), limit: limits.Tenant, scope: " per workspace"})
+	}
+	_, err := r.take(ctx, name, buckets)
+	return err
+}
+
+// RateLimitExceededError is returned when a bucket has no requests left
+type RateLimitExceededError struct {
+	Limit  ratelimit.Limit
+	Scope  string // Appended to the limit in the message, e.g. " per workspace"
+	Result ratelimit.Result
+}
+
+func (e *RateLimitExceededError) Error() string {
+	return fmt.Sprintf("%d requests per %s allowed%s; retry in %d seconds", e.Limit.Requests, e.Limit.Per, e.Scope, e.retryAfterSeconds())
+}
+
+func (e *RateLimitExceededError) retryAfterSeconds() int {
+	return int(math.Ceil(e.Result.RetryAfter.Seconds()))
+}
+
+// take takes a token from each bucket in order, stopping at the first one
+// that is empty, and returns the result of the bucket with the fewest
+// requests left
+func (r *RateLimiter) take(ctx context.Context, name string, buckets []rateLimitBucket) (*ratelimit.Result, error) {
+	var tightest *ratelimit.Result
+	for _, bucket := range buckets {
+		if !bucket.limit.Enabled() {
+			continue
+		}
+		result, err := r.store.Take(ctx, bucket.key, bucket.limit)
+		if err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Str("limit", name).Msg("Rate limit unavailable")
+			continue
+		}
+		if !result.Allowed {
+			return nil, &RateLimitExceededError{Limit: bucket.limit, Scope: bucket.scope, Result: result}
+		}
+		if tightest == nil || result.Remaining < tightest.Remaining {
+			tightest = &result
+		}
+	}
+	return tightest, nil
+}
+
+// rateLimitBucket is a bucket a request takes a token from
+type rateLimitBucket struct {
+	key   string
+	limit ratelimit.Limit
+	scope string
+}
+
+func setRateLimitHeaders(c *fiber.Ctx, result ratelimit.Result) {
+	c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+}
+
+// rateLimitKey identifies the bucket of the requesting user, or client IP when unauthenticated
+func rateLimitKey(name string, c *fiber.Ctx) string {
+	if userID, ok := c.Locals("user_id").(uint); ok {
+		return userRateLimitKey(name, userID)
+	}
+	return fmt.Sprintf("ratelimit:%s:ip:%s", name, c.IP())
+}
+
+func userRateLimitKey(name string, userID uint) string {
+	return fmt.Sprintf("ratelimit:%s:user:%d", name, userID)
+}
+
+func tenantRateLimitKey(name string, tenantID uint) string {
+	return fmt.Sprintf("ratelimit:%s:tenant:%d", name, tenantID)
+}
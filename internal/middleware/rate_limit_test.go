@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"narapulse-be/internal/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterLimitsUsersAndTenants(t *testing.T) {
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore())
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		userID, _ := strconv.Atoi(c.Get("X-User"))
+		tenantID, _ := strconv.Atoi(c.Get("X-Tenant"))
+		c.Locals("user_id", uint(userID))
+		c.Locals("tenant_id", uint(tenantID))
+		return c.Next()
+	}, limiter.LimitFunc("api", func() Limits {
+		return Limits{User: ratelimit.PerMinute(2), Tenant: ratelimit.PerMinute(3)}
+	}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	request := func(userID, tenantID int) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-User", strconv.Itoa(userID))
+		req.Header.Set("X-Tenant", strconv.Itoa(tenantID))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := request(1, 1)
+	assert.Equal(t, fiber.StatusNoContent, first.StatusCode)
+	assert.Equal(t, "2", first.Header.Get("X-RateLimit-Limit"), "the headers describe the bucket with the fewest requests left")
+	assert.Equal(t, "1", first.Header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, fiber.StatusNoContent, request(1, 1).StatusCode)
+	assert.Equal(t, fiber.StatusTooManyRequests, request(1, 1).StatusCode, "the user's limit applies")
+
+	// Another user of the tenant has their own bucket but shares the tenant's
+	assert.Equal(t, fiber.StatusNoContent, request(2, 1).StatusCode)
+	limited := request(2, 1)
+	assert.Equal(t, fiber.StatusTooManyRequests, limited.StatusCode, "the tenant's limit applies")
+	assert.Equal(t, "3", limited.Header.Get("X-RateLimit-Limit"))
+	assert.NotEmpty(t, limited.Header.Get(fiber.HeaderRetryAfter))
+
+	assert.Equal(t, fiber.StatusNoContent, request(3, 2).StatusCode, "other tenants are not limited")
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"narapulse-be/internal/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches request IDs accepted from clients and proxies
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware assigns every request an ID, reusing a valid one sent by
+// the client or a proxy, and returns it in the X-Request-ID header. The user
+// context carries a logger that adds the ID to every log line of the request.
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(RequestIDHeader, requestID)
+		c.Locals("request_id", requestID)
+		c.SetUserContext(logger.WithRequestID(c.UserContext(), requestID))
+		return c.Next()
+	}
+}
+
+// RequestLoggerMiddleware logs every request with its status and latency. It
+// must run after RequestIDMiddleware.
+func RequestLoggerMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+
+		event := logger.FromContext(c.UserContext()).Info()
+		if status >= fiber.StatusInternalServerError {
+			event = logger.FromContext(c.UserContext()).Error().Err(err)
+		}
+		if userID, ok := c.Locals("user_id").(uint); ok {
+			event = event.Uint("user_id", userID)
+		}
+		event.
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("ip", c.IP()).
+			Msg("request")
+		return err
+	}
+}
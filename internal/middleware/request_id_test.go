@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(c.Locals("request_id").(string)) })
+
+	// A valid ID from a proxy is kept
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "edge-42")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "edge-42", resp.Header.Get(RequestIDHeader))
+
+	// Missing or unsafe IDs are replaced
+	for _, sent := range []string{"", "bad id\nwith newline"} {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		if sent != "" {
+			req.Header.Set(RequestIDHeader, sent)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Len(t, resp.Header.Get(RequestIDHeader), 36)
+		assert.NotEqual(t, sent, resp.Header.Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net"
+
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TenantResolver loads the tenants requests belong to
+type TenantResolver interface {
+	GetTenant(ctx context.Context, id uint) (*entity.Tenant, error)
+	// GetTenantByDomain returns nil when no tenant is served on the domain
+	GetTenantByDomain(ctx context.Context, domain string) (*entity.Tenant, error)
+}
+
+// TenancyMiddleware resolves the tenant of the request and scopes the
+// database access made with the request context (c.UserContext()) to it. On
+// authenticated routes, after AuthMiddleware, the tenant is the one of the
+// token, and requests to the domain of another tenant are refused. Public
+// routes are in the tenant served on the domain; on other domains they have
+// no tenant and are not scoped. Requests of deactivated tenants are refused.
+// Read the tenant with GetTenantFromContext.
+func TenancyMiddleware(resolver TenantResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		hostTenant, err := resolver.GetTenantByDomain(ctx, requestHost(c))
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to resolve tenant of domain")
+			return entity.InternalServerErrorResponse(c, "Failed to resolve tenant", err.Error())
+		}
+
+		tenant := hostTenant
+		tokenTenant, authenticated := c.Locals("tenant_id").(uint)
+		switch {
+		case authenticated && hostTenant != nil && hostTenant.ID != tokenTenant:
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeTenantMismatch, "Your account does not belong to the tenant of this domain", nil)
+		case authenticated && hostTenant == nil:
+			tenant, err = resolver.GetTenant(ctx, tokenTenant)
+		case hostTenant == nil:
+			return c.Next()
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to load tenant")
+			return entity.InternalServerErrorResponse(c, "Failed to resolve tenant", err.Error())
+		}
+		if tenant == nil || !tenant.IsActive {
+			return entity.ForbiddenResponse(c, "The tenant does not exist or is deactivated")
+		}
+
+		c.Locals("tenant_id", tenant.ID)
+		c.Locals("tenant", tenant)
+		c.SetUserContext(tenancy.WithTenant(ctx, tenant.ID))
+		return c.Next()
+	}
+}
+
+// RequireTenantFeature refuses requests of tenants the feature is switched off
+// for. It must run after TenancyMiddleware.
+func RequireTenantFeature(feature string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if tenant := GetTenantFromContext(c); tenant != nil && !tenant.FeatureEnabled(feature) {
+			return entity.ErrorResponseWithCode(c, entity.ErrorCodeFeatureDisabled, "This feature is not enabled for your organization", fiber.Map{"feature": feature})
+		}
+		return c.Next()
+	}
+}
+
+// DefaultTenantOnly restricts routes to users of the default tenant, whose
+// admins administer the installation. It must run after TenancyMiddleware.
+func DefaultTenantOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if tenantID, _ := c.Locals("tenant_id").(uint); tenantID != entity.DefaultTenantID {
+			return entity.ForbiddenResponse(c, "Only administrators of the installation may access this resource")
+		}
+		return c.Next()
+	}
+}
+
+// GetTenantFromContext returns the tenant resolved by TenancyMiddleware, nil before it
+func GetTenantFromContext(c *fiber.Ctx) *entity.Tenant {
+	tenant, _ := c.Locals("tenant").(*entity.Tenant)
+	return tenant
+}
+
+// requestHost returns the host the request was sent to, without the port
+func requestHost(c *fiber.Ctx) string {
+	host := c.Hostname()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"narapulse-be/internal/config"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTenantResolver map[uint]*entity.Tenant
+
+func (r fakeTenantResolver) GetTenant(_ context.Context, id uint) (*entity.Tenant, error) {
+	return r[id], nil
+}
+
+func (r fakeTenantResolver) GetTenantByDomain(_ context.Context, domain string) (*entity.Tenant, error) {
+	for _, tenant := range r {
+		if tenant.Domain != nil && *tenant.Domain == domain {
+			return tenant, nil
+		}
+	}
+	return nil, nil
+}
+
+func newTenancyTestApp() *fiber.App {
+	acme := "acme.narapulse.com"
+	resolver := fakeTenantResolver{
+		entity.DefaultTenantID: {ID: entity.DefaultTenantID, IsActive: true},
+		2:                      {ID: 2, Domain: &acme, IsActive: true, Features: entity.TenantFeatures{entity.TenantFeatureGraphQL: false}},
+		3:                      {ID: 3, IsActive: false},
+	}
+	tenantOfContext := func(c *fiber.Ctx) error {
+		tenantID, _ := tenancy.FromContext(c.UserContext())
+		return c.SendString(strconv.Itoa(int(tenantID)))
+	}
+
+	app := fiber.New()
+	app.Get("/public", TenancyMiddleware(resolver), tenantOfContext)
+	app.Get("/private", AuthMiddleware(testAccounts), TenancyMiddleware(resolver), tenantOfContext)
+	app.Get("/graphql", AuthMiddleware(testAccounts), TenancyMiddleware(resolver), RequireTenantFeature(entity.TenantFeatureGraphQL), tenantOfContext)
+	app.Get("/admin", AuthMiddleware(testAccounts), TenancyMiddleware(resolver), DefaultTenantOnly(), tenantOfContext)
+	return app
+}
+
+func tenancyTestRequest(t *testing.T, app *fiber.App, path, host string, tenantID uint) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	req.Host = host
+	if tenantID != 0 {
+		token, err := utils.GenerateScopedToken(1, tenantID, "analyst@narapulse.com", "user", utils.TokenPurposeAccess, config.Load().JWTSecret, time.Minute)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body := make([]byte, 512)
+	n, _ := resp.Body.Read(body)
+	return resp.StatusCode, string(body[:n])
+}
+
+func TestTenancyMiddlewareResolvesTheTenant(t *testing.T) {
+	app := newTenancyTestApp()
+
+	// Public routes are in the tenant of the domain; elsewhere they have none
+	status, body := tenancyTestRequest(t, app, "/public", "acme.narapulse.com", 0)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", body)
+	_, body = tenancyTestRequest(t, app, "/public", "localhost:8080", 0)
+	assert.Equal(t, "0", body)
+
+	// Authenticated routes are in the tenant of the token
+	status, body = tenancyTestRequest(t, app, "/private", "localhost", 2)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", body)
+	status, body = tenancyTestRequest(t, app, "/private", "acme.narapulse.com:443", 2)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", body)
+
+	// Tokens of another tenant are refused on a tenant's domain
+	status, body = tenancyTestRequest(t, app, "/private", "acme.narapulse.com", 1)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Contains(t, body, string(entity.ErrorCodeTenantMismatch))
+
+	// Deactivated tenants are refused
+	status, _ = tenancyTestRequest(t, app, "/private", "localhost", 3)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
+
+func TestRequireTenantFeature(t *testing.T) {
+	app := newTenancyTestApp()
+
+	status, _ := tenancyTestRequest(t, app, "/graphql", "localhost", 1)
+	assert.Equal(t, fiber.StatusOK, status, "features are on unless switched off")
+	status, body := tenancyTestRequest(t, app, "/graphql", "localhost", 2)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Contains(t, body, string(entity.ErrorCodeFeatureDisabled))
+}
+
+func TestDefaultTenantOnly(t *testing.T) {
+	app := newTenancyTestApp()
+
+	status, _ := tenancyTestRequest(t, app, "/admin", "localhost", 1)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = tenancyTestRequest(t, app, "/admin", "localhost", 2)
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
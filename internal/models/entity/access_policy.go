@@ -0,0 +1,18 @@
+package models
+
+// AccessPolicy allows a role (or a single user, by email) to call the routes
+// matching Path with Method. A trailing * in Path matches any suffix and a
+// Method of * matches every method. Path pii with Method unmask grants
+// unmasked PII in query results.
+type AccessPolicy struct {
+	Role   string `json:"role" validate:"required,max=100"`
+	Path   string `json:"path" validate:"required,max=100,startswith=/api/|eq=pii"`
+	Method string `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE * unmask"`
+}
+
+// RoleAssignment grants a user, identified by email, the policies of a role
+// in addition to the role stored on the user
+type RoleAssignment struct {
+	User string `json:"user" validate:"required,email,max=100"`
+	Role string `json:"role" validate:"required,max=100"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// AccessReviewReport lists the entitlements of every user for periodic access reviews
+type AccessReviewReport struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Users       []AccessReviewEntry `json:"users"`
+}
+
+// AccessReviewEntry is the entitlements of one user
+type AccessReviewEntry struct {
+	UserID         uint                     `json:"user_id"`
+	Email          string                   `json:"email"`
+	Username       string                   `json:"username"`
+	IsActive       bool                     `json:"is_active"`
+	Roles          []string                 `json:"roles"`          // User role plus roles granted in Casbin
+	DataSources    []AccessReviewDataSource `json:"data_sources"`   // Data sources the user can query
+	MaskedColumns  []string                 `json:"masked_columns"` // PII columns of those data sources, as source.table.column
+	APIKeys        []AccessReviewAPIKey     `json:"api_keys"`       // Keys of the data APIs the user published
+	LastActivityAt *time.Time               `json:"last_activity_at"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+// AccessReviewDataSource is a data source accessible to a user
+type AccessReviewDataSource struct {
+	ID   uint           `json:"id"`
+	Name string         `json:"name"`
+	Type DataSourceType `json:"type"`
+}
+
+// AccessReviewAPIKey is a data API key owned by a user
+type AccessReviewAPIKey struct {
+	ID           uint       `json:"id"`
+	Name         string     `json:"name"`
+	KeyPrefix    string     `json:"key_prefix"`
+	EndpointSlug string     `json:"endpoint_slug"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
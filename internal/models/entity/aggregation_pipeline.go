@@ -0,0 +1,11 @@
+package models
+
+import "encoding/json"
+
+// AggregationQuery is the generated query of a MongoDB data source: an
+// aggregation pipeline over one collection. It is stored as JSON in the
+// GeneratedSQL of the query, so versioning and history work unchanged.
+type AggregationQuery struct {
+	Collection string            `json:"collection"`
+	Pipeline   []json.RawMessage `json:"pipeline"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// QueryAnalyticsDaily is a pre-aggregated rollup of nl2_sql_queries per day,
+// tenant, user, data source and status. Metrics endpoints read from it instead of
+// scanning the query history.
+type QueryAnalyticsDaily struct {
+	Day              time.Time   `json:"day" gorm:"primaryKey;type:date"`
+	TenantID         uint        `json:"-" gorm:"primaryKey;autoIncrement:false;default:1"`
+	UserID           uint        `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	DataSourceID     uint        `json:"data_source_id" gorm:"primaryKey;autoIncrement:false"`
+	Status           QueryStatus `json:"status" gorm:"primaryKey"`
+	QueryCount       int64       `json:"query_count"`
+	TotalExecutionMs int64       `json:"total_execution_ms"`
+	TotalRows        int64       `json:"total_rows"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// TableName overrides the default pluralized table name
+func (QueryAnalyticsDaily) TableName() string {
+	return "query_analytics_daily"
+}
+
+// AnalyticsRefreshState tracks how far the analytics cache has been refreshed
+type AnalyticsRefreshState struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Watermark   time.Time `json:"watermark"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	DaysUpdated int       `json:"days_updated"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// QueryMetricsSummary holds totals over the requested period
+type QueryMetricsSummary struct {
+	TotalQueries       int64   `json:"total_queries"`
+	CompletedQueries   int64   `json:"completed_queries"`
+	FailedQueries      int64   `json:"failed_queries"`
+	SuccessRate        float64 `json:"success_rate"`
+	AvgExecutionTimeMs float64 `json:"avg_execution_time_ms"`
+	TotalRowsReturned  int64   `json:"total_rows_returned"`
+}
+
+// DailyQueryMetrics holds totals for a single day
+type DailyQueryMetrics struct {
+	Day              string `json:"day"`
+	TotalQueries     int64  `json:"total_queries"`
+	CompletedQueries int64  `json:"completed_queries"`
+	FailedQueries    int64  `json:"failed_queries"`
+}
+
+// DataSourceQueryMetrics holds totals for a single data source
+type DataSourceQueryMetrics struct {
+	DataSourceID   uint   `json:"data_source_id"`
+	DataSourceName string `json:"data_source_name"`
+	TotalQueries   int64  `json:"total_queries"`
+	FailedQueries  int64  `json:"failed_queries"`
+}
+
+// QueryMetricsResponse is returned by the query metrics endpoints
+type QueryMetricsResponse struct {
+	Summary          QueryMetricsSummary      `json:"summary"`
+	Daily            []DailyQueryMetrics      `json:"daily"`
+	ByDataSource     []DataSourceQueryMetrics `json:"by_data_source"`
+	CacheRefreshedAt *time.Time               `json:"cache_refreshed_at,omitempty"`
+}
+
+// QueryMetricsFilter narrows the metrics to a period, user or data source
+type QueryMetricsFilter struct {
+	UserID       uint // 0 means all users
+	DataSourceID uint // 0 means all data sources
+	From         time.Time
+	To           time.Time
+}
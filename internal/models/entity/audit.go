@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditAction is a sensitive action recorded in the audit trail
+type AuditAction string
+
+const (
+	AuditActionDataSourceCreate   AuditAction = "data_source.create"
+	AuditActionDataSourceUpdate   AuditAction = "data_source.update"
+	AuditActionDataSourceDelete   AuditAction = "data_source.delete"
+	AuditActionDataSourceRestore  AuditAction = "data_source.restore"
+	AuditActionQueryExecute       AuditAction = "query.execute"
+	AuditActionUserRoleChange     AuditAction = "user.role_change"
+	AuditActionUserStatusChange   AuditAction = "user.status_change"
+	AuditActionUserDelete         AuditAction = "user.delete"
+	AuditActionDataExport         AuditAction = "data.export"
+	AuditActionAccessPolicyChange AuditAction = "access_policy.change"
+	AuditActionMFAChange          AuditAction = "mfa.change"
+	AuditActionColumnMetadata     AuditAction = "column_metadata.update"
+	AuditActionDbtImport          AuditAction = "column_metadata.dbt_import"
+	AuditActionQueryHistoryDelete AuditAction = "query_history.delete"
+	AuditActionEmbeddingMaintain  AuditAction = "embedding.maintain"
+	AuditActionQueryTrace         AuditAction = "query.trace"
+)
+
+// AuditLog records who performed a sensitive action, from where, and how the
+// resource changed. Before and After are snapshots with secrets redacted;
+// Changes maps each changed field (dotted path) to its old and new value.
+type AuditLog struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	TenantID     uint        `json:"-" gorm:"not null;default:1;index"`
+	ActorID      uint        `json:"actor_id" gorm:"index"`
+	ActorEmail   string      `json:"actor_email"`
+	Action       AuditAction `json:"action" gorm:"not null;index"`
+	ResourceType string      `json:"resource_type" gorm:"not null;index:idx_audit_logs_resource"`
+	ResourceID   string      `json:"resource_id,omitempty" gorm:"index:idx_audit_logs_resource"`
+	IPAddress    string      `json:"ip_address"`
+	UserAgent    string      `json:"user_agent,omitempty"`
+	Before       JSON        `json:"before,omitempty" gorm:"type:jsonb"`
+	After        JSON        `json:"after,omitempty" gorm:"type:jsonb"`
+	Changes      JSON        `json:"changes,omitempty" gorm:"type:jsonb"`
+	Details      JSON        `json:"details,omitempty" gorm:"type:jsonb"` // e.g. the executed SQL
+	CreatedAt    time.Time   `json:"created_at" gorm:"index"`
+}
+
+// AuditActor identifies who performed an action and from where
+type AuditActor struct {
+	UserID    uint
+	Email     string
+	IPAddress string
+	UserAgent string
+	RequestID string // Correlates the entry with the log lines of the request
+}
+
+// AuditChange is the old and new value of a changed field
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Request/Response DTOs
+
+// AuditLogFilter selects audit log entries; zero values match everything
+type AuditLogFilter struct {
+	ActorID      uint
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Search       string // Matched against the SQL, snapshots and changes
+	From         time.Time
+	To           time.Time
+	Limit        int
+	Offset       int
+}
+
+// AuditLogPage is a page of audit log entries, newest first
+type AuditLogPage struct {
+	Logs   []AuditLog `json:"logs"`
+	Total  int64      `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+}
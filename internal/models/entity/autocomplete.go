@@ -0,0 +1,36 @@
+package models
+
+// Kinds of schema terms suggested while typing a question
+const (
+	AutocompleteTypeTable    = "table"
+	AutocompleteTypeColumn   = "column"
+	AutocompleteTypeKPI      = "kpi"
+	AutocompleteTypeGlossary = "glossary"
+)
+
+// How a suggestion matched the prefix
+const (
+	AutocompleteMatchPrefix = "prefix" // The term starts with the prefix
+	AutocompleteMatchWord   = "word"   // A later word of the term starts with the prefix
+	AutocompleteMatchFuzzy  = "fuzzy"  // The term starts with the prefix give or take a typo
+)
+
+// AutocompleteRequest asks for the schema terms of a data source matching
+// what the user is typing
+type AutocompleteRequest struct {
+	DataSourceID uint   `query:"data_source_id"`
+	Prefix       string `query:"prefix"`
+	Limit        int    `query:"limit"` // Default 10, at most 50
+}
+
+// AutocompleteSuggestion is a table, column, KPI or glossary term to complete
+// a question with
+type AutocompleteSuggestion struct {
+	Text        string  `json:"text"`            // The name or alias that matched, to insert in the question
+	Type        string  `json:"type"`            // table, column, kpi or glossary
+	Name        string  `json:"name"`            // Canonical name of the table, column, KPI or glossary term
+	Table       string  `json:"table,omitempty"` // Table of a column
+	Description string  `json:"description,omitempty"`
+	Match       string  `json:"match"` // prefix, word or fuzzy
+	Score       float64 `json:"score"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// BigQueryServiceAccount is a validated Google Cloud service account key a
+// user uploaded for BigQuery data sources. The key is stored encrypted and
+// referenced from data source configs by service_account_id.
+type BigQueryServiceAccount struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	TenantID     uint       `json:"-" gorm:"not null;default:1;index"`
+	Name         string     `json:"name" gorm:"not null"`
+	ProjectID    string     `json:"project_id" gorm:"not null"`
+	ClientEmail  string     `json:"client_email" gorm:"not null"`
+	PrivateKeyID string     `json:"private_key_id"`
+	EncryptedKey string     `json:"-" gorm:"type:text;not null"` // Key JSON encrypted with CREDENTIALS_ENCRYPTION_KEY
+	ValidatedAt  *time.Time `json:"validated_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name, which splits BigQuery into two words
+func (BigQueryServiceAccount) TableName() string {
+	return "bigquery_service_accounts"
+}
+
+// BigQueryCredentialIssueCode identifies a common problem with a service account key
+type BigQueryCredentialIssueCode string
+
+const (
+	BigQueryIssueInvalidJSON       BigQueryCredentialIssueCode = "invalid_json"
+	BigQueryIssueNotServiceAccount BigQueryCredentialIssueCode = "not_service_account"
+	BigQueryIssueMissingField      BigQueryCredentialIssueCode = "missing_field"
+	BigQueryIssueKeyRejected       BigQueryCredentialIssueCode = "key_rejected"         // Key deleted, disabled or malformed
+	BigQueryIssueAPIDisabled       BigQueryCredentialIssueCode = "api_disabled"         // BigQuery API not enabled on the project
+	BigQueryIssueProjectNotFound   BigQueryCredentialIssueCode = "project_not_found"    // Project does not exist or is not visible
+	BigQueryIssueDatasetNotFound   BigQueryCredentialIssueCode = "dataset_not_found"    // Dataset does not exist in the project
+	BigQueryIssueDatasetDenied     BigQueryCredentialIssueCode = "dataset_denied"       // Missing BigQuery Data Viewer on the dataset
+	BigQueryIssueJobDenied         BigQueryCredentialIssueCode = "job_denied"           // Missing BigQuery Job User on the project
+	BigQueryIssueListDenied        BigQueryCredentialIssueCode = "list_datasets_denied" // Datasets cannot be listed; queries may still work
+	BigQueryIssueUnknown           BigQueryCredentialIssueCode = "unknown"
+)
+
+// BigQueryCredentialIssue is a problem found while validating a service
+// account key, with the fix to apply in Google Cloud
+type BigQueryCredentialIssue struct {
+	Code    BigQueryCredentialIssueCode `json:"code"`
+	Message string                      `json:"message"`
+	Hint    string                      `json:"hint,omitempty"`
+}
+
+// Request/Response DTOs
+
+// BigQueryServiceAccountRequest uploads a service account key. The project
+// defaults to the one in the key; with a dataset, access to it is tested.
+type BigQueryServiceAccountRequest struct {
+	Name            string `json:"name" validate:"max=255"`
+	CredentialsJSON string `json:"credentials_json" validate:"required"`
+	ProjectID       string `json:"project_id,omitempty"`
+	DatasetID       string `json:"dataset_id,omitempty"`
+}
+
+// BigQueryServiceAccountValidation is the result of checking a service account
+// key: that it is well-formed, can get a token for the BigQuery scopes, can run
+// query jobs and can read the datasets
+type BigQueryServiceAccountValidation struct {
+	Valid         bool                      `json:"valid"`
+	ProjectID     string                    `json:"project_id,omitempty"`
+	ClientEmail   string                    `json:"client_email,omitempty"`
+	Scopes        []string                  `json:"scopes,omitempty"`   // Scopes the key obtained a token for
+	Datasets      []string                  `json:"datasets,omitempty"` // Datasets of the project the account can list
+	DatasetID     string                    `json:"dataset_id,omitempty"`
+	DatasetAccess bool                      `json:"dataset_access"`
+	CanRunQueries bool                      `json:"can_run_queries"`
+	Issues        []BigQueryCredentialIssue `json:"issues,omitempty"`
+}
+
+// BigQueryServiceAccountResponse is a stored service account with the result of its validation
+type BigQueryServiceAccountResponse struct {
+	Account    *BigQueryServiceAccount           `json:"account,omitempty"`
+	Validation *BigQueryServiceAccountValidation `json:"validation"`
+}
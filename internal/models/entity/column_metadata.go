@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// ColumnMetadata is the curated metadata of a data source column. It is kept
+// by table and column name, so it survives schema rediscovery and is applied
+// to the discovered columns. A row without a column name describes the table.
+type ColumnMetadata struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;uniqueIndex:idx_column_metadata_column"`
+	Table        string    `json:"table_name" gorm:"column:table_name;not null;uniqueIndex:idx_column_metadata_column"`
+	Column       string    `json:"column_name" gorm:"column:column_name;not null;uniqueIndex:idx_column_metadata_column"`
+	DisplayName  string    `json:"display_name"`
+	Description  string    `json:"description" gorm:"type:text"`
+	Tags         JSON      `json:"-" gorm:"type:jsonb"` // []string
+	PII          bool      `json:"pii" gorm:"column:pii"`
+	Unit         string    `json:"unit" gorm:"size:50"`    // currency, percentage, count or free text like "ms"
+	Currency     string    `json:"currency" gorm:"size:3"` // ISO 4217 code of currency columns
+	Tests        JSON      `json:"-" gorm:"type:jsonb"`    // []string, dbt tests of the column, e.g. unique or accepted_values(placed, shipped)
+	Source       string    `json:"source" gorm:"size:20"`  // user or dbt: who wrote the description and tags
+	UpdatedBy    uint      `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name of ColumnMetadata
+func (ColumnMetadata) TableName() string {
+	return "column_metadata"
+}
+
+// Sources of column metadata
+const (
+	ColumnMetadataSourceUser = "user"
+	ColumnMetadataSourceDbt  = "dbt" // Imported from a dbt manifest
+)
+
+// Request/Response DTOs
+
+// ColumnMetadataRequest replaces the curated metadata of a column
+type ColumnMetadataRequest struct {
+	DisplayName string   `json:"display_name" validate:"max=255"`
+	Description string   `json:"description" validate:"max=2000"`
+	Tags        []string `json:"tags" validate:"max=20,dive,min=1,max=50"`
+	PII         bool     `json:"pii"`
+	Unit        string   `json:"unit" validate:"max=50"`
+	Currency    string   `json:"currency" validate:"omitempty,len=3,alpha"`
+}
+
+// ColumnMetadataResponse is the curated metadata of a column
+type ColumnMetadataResponse struct {
+	DataSourceID uint      `json:"data_source_id"`
+	Table        string    `json:"table_name"`
+	Column       string    `json:"column_name"`
+	DisplayName  string    `json:"display_name"`
+	Description  string    `json:"description"`
+	Tags         []string  `json:"tags"`
+	PII          bool      `json:"pii"`
+	Unit         string    `json:"unit,omitempty"`
+	Currency     string    `json:"currency,omitempty"`
+	Tests        []string  `json:"tests,omitempty"`
+	Source       string    `json:"source"`
+	UpdatedBy    uint      `json:"updated_by"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// ConnectionHealthLog records one scheduled connection test of a data source
+type ConnectionHealthLog struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	TenantID     uint             `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint             `json:"data_source_id" gorm:"not null;index:idx_connection_health_logs_source_checked"`
+	Status       ConnectionStatus `json:"status" gorm:"not null"` // active or error
+	ErrorMsg     string           `json:"error_message,omitempty" gorm:"column:error_message"`
+	LatencyMs    int64            `json:"latency_ms"`
+	CheckedAt    time.Time        `json:"checked_at" gorm:"not null;index:idx_connection_health_logs_source_checked"`
+}
+
+// Request/Response DTOs
+
+// ConnectionUptime summarizes the health checks of a data source over a window
+type ConnectionUptime struct {
+	Window           string   `json:"window"` // 24h, 7d or 30d
+	Checks           int      `json:"checks"`
+	SuccessfulChecks int      `json:"successful_checks"`
+	UptimePercent    *float64 `json:"uptime_percent"` // nil when there were no checks in the window
+	AvgLatencyMs     float64  `json:"avg_latency_ms"`
+}
+
+// DataSourceHealthResponse is the connection health of a data source
+type DataSourceHealthResponse struct {
+	DataSourceID        uint                  `json:"data_source_id"`
+	Status              ConnectionStatus      `json:"status"`
+	ErrorMsg            string                `json:"error_message,omitempty"`
+	LastTested          *time.Time            `json:"last_tested"`
+	ConsecutiveFailures int                   `json:"consecutive_failures"`
+	Uptime              []ConnectionUptime    `json:"uptime"`
+	RecentChecks        []ConnectionHealthLog `json:"recent_checks"` // Newest first
+}
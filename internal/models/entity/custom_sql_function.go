@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// CustomSQLFunction is a warehouse function (typically a UDF such as
+// fiscal_quarter()) registered for a data source. Registered functions are
+// allowed by the SQL validator and documented in the NL2SQL prompt.
+type CustomSQLFunction struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;uniqueIndex:idx_custom_sql_functions_name"`
+	Name         string    `json:"name" gorm:"not null;size:128;uniqueIndex:idx_custom_sql_functions_name"` // Upper-case, as matched by the validator
+	Signature    string    `json:"signature" gorm:"not null"`                                               // e.g. fiscal_quarter(d DATE) RETURNS INT64
+	Description  string    `json:"description"`
+	Example      string    `json:"example"`
+	UpdatedBy    uint      `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// CustomSQLFunctionRequest registers or replaces a custom SQL function
+type CustomSQLFunctionRequest struct {
+	Name        string `json:"name" validate:"required,max=128"`
+	Signature   string `json:"signature" validate:"required,max=500"`
+	Description string `json:"description" validate:"max=2000"`
+	Example     string `json:"example" validate:"max=500"`
+}
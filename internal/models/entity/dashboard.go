@@ -0,0 +1,148 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DashboardRole is the access level of a dashboard collaborator
+type DashboardRole string
+
+const (
+	DashboardRoleEditor DashboardRole = "editor"
+	DashboardRoleViewer DashboardRole = "viewer"
+)
+
+// WidgetType is the visualization of a dashboard widget
+type WidgetType string
+
+const (
+	WidgetTypeTable WidgetType = "table"
+	WidgetTypeChart WidgetType = "chart"
+	WidgetTypeKPI   WidgetType = "kpi"
+	WidgetTypeText  WidgetType = "text"
+)
+
+// Dashboard arranges query widgets on a grid. Several analysts can edit it at
+// the same time; every widget carries its own version so concurrent edits of
+// different widgets never conflict.
+type Dashboard struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	UserID      uint              `json:"user_id" gorm:"not null;index"` // Owner
+	TenantID    uint              `json:"-" gorm:"not null;default:1;index"`
+	Name        string            `json:"name" gorm:"not null"`
+	Description string            `json:"description"`
+	Widgets     []DashboardWidget `json:"widgets,omitempty" gorm:"foreignKey:DashboardID"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt    `json:"-" gorm:"index"`
+}
+
+// DashboardWidget is a widget placed on a dashboard grid
+type DashboardWidget struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"-" gorm:"not null;default:1;index"`
+	DashboardID uint       `json:"dashboard_id" gorm:"not null;index"`
+	QueryID     *uint      `json:"query_id,omitempty"`   // NL2SQL query the widget displays
+	ExtractID   *uint      `json:"extract_id,omitempty"` // Snapshot displayed instead of the latest result of the query
+	Type        WidgetType `json:"type" gorm:"not null"`
+	Title       string     `json:"title"`
+	Config      JSON       `json:"config" gorm:"type:jsonb"` // Visualization settings
+	X           int        `json:"x"`
+	Y           int        `json:"y"`
+	Width       int        `json:"width" gorm:"default:4"`
+	Height      int        `json:"height" gorm:"default:3"`
+	Version     int        `json:"version" gorm:"not null;default:1"` // Incremented on every change
+	UpdatedBy   uint       `json:"updated_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// DashboardCollaborator grants a user access to another user's dashboard
+type DashboardCollaborator struct {
+	DashboardID uint          `json:"dashboard_id" gorm:"primaryKey;autoIncrement:false"`
+	TenantID    uint          `json:"-" gorm:"not null;default:1;index"`
+	UserID      uint          `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Role        DashboardRole `json:"role" gorm:"not null;default:editor"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// DashboardEventType is the kind of change broadcast to a dashboard room
+type DashboardEventType string
+
+const (
+	DashboardEventWidgetAdded     DashboardEventType = "widget_added"
+	DashboardEventWidgetUpdated   DashboardEventType = "widget_updated"
+	DashboardEventWidgetRemoved   DashboardEventType = "widget_removed"
+	DashboardEventDashboardUpdate DashboardEventType = "dashboard_updated"
+	DashboardEventPresence        DashboardEventType = "presence"
+)
+
+// DashboardEvent is sent to every analyst connected to a dashboard
+type DashboardEvent struct {
+	Type        DashboardEventType  `json:"type"`
+	DashboardID uint                `json:"dashboard_id"`
+	UserID      uint                `json:"user_id"` // Who made the change
+	Widget      *DashboardWidget    `json:"widget,omitempty"`
+	WidgetID    uint                `json:"widget_id,omitempty"`
+	Dashboard   *Dashboard          `json:"dashboard,omitempty"`
+	Presence    []DashboardPresence `json:"presence,omitempty"`
+	Timestamp   time.Time           `json:"timestamp"`
+}
+
+// DashboardPresence describes an analyst currently viewing a dashboard
+type DashboardPresence struct {
+	UserID         uint      `json:"user_id"`
+	Username       string    `json:"username"`
+	SelectedWidget uint      `json:"selected_widget,omitempty"` // Widget the analyst is editing
+	LastSeen       time.Time `json:"last_seen"`
+}
+
+// Request/Response DTOs
+
+// DashboardRequest creates or renames a dashboard
+type DashboardRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=200"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// DashboardWidgetCreateRequest adds a widget to a dashboard
+type DashboardWidgetCreateRequest struct {
+	QueryID   *uint                  `json:"query_id,omitempty"`
+	ExtractID *uint                  `json:"extract_id,omitempty"`
+	Type      WidgetType             `json:"type" validate:"required,oneof=table chart kpi text"`
+	Title     string                 `json:"title" validate:"max=200"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+	X         int                    `json:"x" validate:"min=0"`
+	Y         int                    `json:"y" validate:"min=0"`
+	Width     int                    `json:"width" validate:"min=0,max=24"`
+	Height    int                    `json:"height" validate:"min=0,max=100"`
+}
+
+// DashboardWidgetPatchRequest changes only the fields that are set.
+// BaseVersion is the widget version the edit was made against; an edit based
+// on an older version is rejected so the client can rebase on the latest widget.
+type DashboardWidgetPatchRequest struct {
+	BaseVersion int                    `json:"base_version" validate:"required,min=1"`
+	QueryID     *uint                  `json:"query_id,omitempty"`
+	ExtractID   *uint                  `json:"extract_id,omitempty"` // 0 unbinds the extract
+	Type        *WidgetType            `json:"type,omitempty" validate:"omitempty,oneof=table chart kpi text"`
+	Title       *string                `json:"title,omitempty" validate:"omitempty,max=200"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+	X           *int                   `json:"x,omitempty" validate:"omitempty,min=0"`
+	Y           *int                   `json:"y,omitempty" validate:"omitempty,min=0"`
+	Width       *int                   `json:"width,omitempty" validate:"omitempty,min=1,max=24"`
+	Height      *int                   `json:"height,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// DashboardCollaboratorRequest shares a dashboard with another user
+type DashboardCollaboratorRequest struct {
+	UserID uint          `json:"user_id" validate:"required"`
+	Role   DashboardRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+// DashboardPresenceRequest is the heartbeat of an analyst viewing a dashboard
+type DashboardPresenceRequest struct {
+	SelectedWidget uint `json:"selected_widget"`
+}
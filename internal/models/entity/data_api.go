@@ -0,0 +1,174 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataAPIParamType is the type of a data API query parameter
+type DataAPIParamType string
+
+const (
+	DataAPIParamString  DataAPIParamType = "string"
+	DataAPIParamInteger DataAPIParamType = "integer"
+	DataAPIParamNumber  DataAPIParamType = "number"
+	DataAPIParamBoolean DataAPIParamType = "boolean"
+	DataAPIParamDate    DataAPIParamType = "date"
+)
+
+// DataAPIParameter declares a {{name}} placeholder of a data API SQL template
+type DataAPIParameter struct {
+	Name        string           `json:"name" validate:"required"`
+	Type        DataAPIParamType `json:"type" validate:"required,oneof=string integer number boolean date"`
+	Required    bool             `json:"required"`
+	Default     string           `json:"default,omitempty"`
+	Description string           `json:"description,omitempty"`
+}
+
+// DataAPIEndpoint publishes a saved query as GET /apis/{slug}
+type DataAPIEndpoint struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	UserID             uint           `json:"user_id" gorm:"not null;index"`
+	TenantID           uint           `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID       uint           `json:"data_source_id" gorm:"not null;index"`
+	QueryID            uint           `json:"query_id,omitempty"` // Saved NL2SQL query the endpoint was published from
+	Slug               string         `json:"slug" gorm:"not null;uniqueIndex"`
+	Name               string         `json:"name" gorm:"not null"`
+	Description        string         `json:"description"`
+	SQLTemplate        string         `json:"sql_template" gorm:"type:text;not null"`
+	Parameters         JSON           `json:"parameters" gorm:"type:jsonb"`
+	RateLimitPerMinute int            `json:"rate_limit_per_minute" gorm:"default:60"`
+	CacheTTLSeconds    int            `json:"cache_ttl_seconds" gorm:"default:300"`
+	MaxRows            int            `json:"max_rows" gorm:"default:1000"`
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// GetParameters decodes the declared parameters
+func (e *DataAPIEndpoint) GetParameters() []DataAPIParameter {
+	var params []DataAPIParameter
+	if len(e.Parameters) > 0 {
+		json.Unmarshal(e.Parameters, &params)
+	}
+	return params
+}
+
+// DataAPIKey grants access to a single data API endpoint. Only the SHA-256
+// hash of the key is stored; the plaintext is returned once on creation.
+type DataAPIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	TenantID   uint       `json:"-" gorm:"not null;default:1;index"`
+	EndpointID uint       `json:"endpoint_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	KeyPrefix  string     `json:"key_prefix" gorm:"not null"`
+	KeyHash    string     `json:"-" gorm:"not null;uniqueIndex"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// DataAPIRateWindow counts requests per endpoint and key in a one minute window
+type DataAPIRateWindow struct {
+	EndpointID   uint      `gorm:"primaryKey;autoIncrement:false"`
+	APIKeyID     uint      `gorm:"primaryKey;autoIncrement:false"`
+	WindowStart  time.Time `gorm:"primaryKey"`
+	RequestCount int       `gorm:"not null"`
+}
+
+// Request/Response DTOs
+
+// DataAPIEndpointCreateRequest publishes a saved query or a SQL template
+type DataAPIEndpointCreateRequest struct {
+	QueryID            uint               `json:"query_id,omitempty"`       // Saved query to publish (provides SQL and data source)
+	DataSourceID       uint               `json:"data_source_id,omitempty"` // Required when query_id is not set
+	SQLTemplate        string             `json:"sql_template,omitempty"`   // Overrides the saved query SQL; may contain {{param}} placeholders
+	Slug               string             `json:"slug" validate:"required,min=3,max=64"`
+	Name               string             `json:"name" validate:"required,min=1,max=100"`
+	Description        string             `json:"description,omitempty"`
+	Parameters         []DataAPIParameter `json:"parameters,omitempty" validate:"dive"`
+	RateLimitPerMinute int                `json:"rate_limit_per_minute,omitempty" validate:"omitempty,min=1,max=10000"`
+	CacheTTLSeconds    int                `json:"cache_ttl_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	MaxRows            int                `json:"max_rows,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+// DataAPIEndpointUpdateRequest updates a published endpoint
+type DataAPIEndpointUpdateRequest struct {
+	Name               *string            `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description        *string            `json:"description,omitempty"`
+	SQLTemplate        *string            `json:"sql_template,omitempty"`
+	Parameters         []DataAPIParameter `json:"parameters,omitempty" validate:"dive"`
+	RateLimitPerMinute *int               `json:"rate_limit_per_minute,omitempty" validate:"omitempty,min=1,max=10000"`
+	CacheTTLSeconds    *int               `json:"cache_ttl_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	MaxRows            *int               `json:"max_rows,omitempty" validate:"omitempty,min=1,max=10000"`
+	IsActive           *bool              `json:"is_active,omitempty"`
+}
+
+// DataAPIEndpointResponse is the management view of an endpoint
+type DataAPIEndpointResponse struct {
+	ID                 uint               `json:"id"`
+	DataSourceID       uint               `json:"data_source_id"`
+	QueryID            uint               `json:"query_id,omitempty"`
+	Slug               string             `json:"slug"`
+	Path               string             `json:"path"`
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	SQLTemplate        string             `json:"sql_template"`
+	Parameters         []DataAPIParameter `json:"parameters"`
+	RateLimitPerMinute int                `json:"rate_limit_per_minute"`
+	CacheTTLSeconds    int                `json:"cache_ttl_seconds"`
+	MaxRows            int                `json:"max_rows"`
+	IsActive           bool               `json:"is_active"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// ToResponse converts DataAPIEndpoint to DataAPIEndpointResponse
+func (e *DataAPIEndpoint) ToResponse() *DataAPIEndpointResponse {
+	params := e.GetParameters()
+	if params == nil {
+		params = []DataAPIParameter{}
+	}
+	return &DataAPIEndpointResponse{
+		ID:                 e.ID,
+		DataSourceID:       e.DataSourceID,
+		QueryID:            e.QueryID,
+		Slug:               e.Slug,
+		Path:               "/api/v1/apis/" + e.Slug,
+		Name:               e.Name,
+		Description:        e.Description,
+		SQLTemplate:        e.SQLTemplate,
+		Parameters:         params,
+		RateLimitPerMinute: e.RateLimitPerMinute,
+		CacheTTLSeconds:    e.CacheTTLSeconds,
+		MaxRows:            e.MaxRows,
+		IsActive:           e.IsActive,
+		CreatedAt:          e.CreatedAt,
+		UpdatedAt:          e.UpdatedAt,
+	}
+}
+
+// DataAPIKeyCreateRequest creates an API key for an endpoint
+type DataAPIKeyCreateRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// DataAPIKeyCreateResponse contains the plaintext key, shown only once
+type DataAPIKeyCreateResponse struct {
+	DataAPIKey
+	Key string `json:"key"`
+}
+
+// DataAPIResult is the body returned by a published endpoint
+type DataAPIResult struct {
+	Columns     []Column                 `json:"columns"`
+	Data        []map[string]interface{} `json:"data"`
+	RowCount    int                      `json:"row_count"`
+	Cached      bool                     `json:"cached"`
+	GeneratedAt time.Time                `json:"generated_at"`
+
+	MaskedColumns []string `json:"masked_columns,omitempty"` // PII columns whose values were masked
+}
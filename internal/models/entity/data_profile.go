@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Sources of the rows a profile is computed from
+const (
+	ProfileSourceLive         = "live"          // Sampled from the data source
+	ProfileSourceStoredSample = "stored_sample" // The sample rows stored at discovery, when the source cannot be sampled
+)
+
+// SchemaProfile caches the latest data profile of a schema
+type SchemaProfile struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	SchemaID     uint      `json:"schema_id" gorm:"not null;uniqueIndex"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;index"`
+	Profile      JSON      `json:"-" gorm:"type:jsonb"` // SchemaProfileResponse
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// SchemaProfileResponse profiles the columns of a table over a sample of its rows
+type SchemaProfileResponse struct {
+	SchemaID     uint            `json:"schema_id"`
+	DataSourceID uint            `json:"data_source_id"`
+	Table        string          `json:"table"`
+	Source       string          `json:"source"` // live or stored_sample
+	SampledRows  int             `json:"sampled_rows"`
+	Columns      []ColumnProfile `json:"columns"`
+	Cached       bool            `json:"cached"`
+	ProfiledAt   time.Time       `json:"profiled_at"`
+}
+
+// ColumnProfile describes the values of a column in the sampled rows
+type ColumnProfile struct {
+	Name            string            `json:"name"`
+	Type            string            `json:"type"`
+	TotalCount      int               `json:"total_count"`
+	NullCount       int               `json:"null_count"`
+	EmptyCount      int               `json:"empty_count"`
+	DistinctCount   int               `json:"distinct_count"`
+	CompletenessPct float64           `json:"completeness_pct"`
+	UniquenessPct   float64           `json:"uniqueness_pct"`
+	Min             string            `json:"min,omitempty"`
+	Max             string            `json:"max,omitempty"`
+	TopValues       []ValueCount      `json:"top_values"`
+	Histogram       []HistogramBucket `json:"histogram,omitempty"` // Numeric columns only
+	PII             bool              `json:"pii"`                 // Values are left out of the profile
+}
+
+// ValueCount is a value with the number of sampled rows holding it
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// HistogramBucket counts the numeric values in [Lower, Upper); the last bucket includes Upper
+type HistogramBucket struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int     `json:"count"`
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -46,22 +47,34 @@ const (
 	DataSourceTypePostgreSQL DataSourceType = "postgresql"
 	DataSourceTypeBigQuery   DataSourceType = "bigquery"
 	DataSourceTypeGoogleSheets DataSourceType = "google_sheets"
+	DataSourceTypeMongoDB      DataSourceType = "mongodb"
+	DataSourceTypeRESTAPI      DataSourceType = "rest_api"
+	DataSourceTypeGA4          DataSourceType = "ga4" // BigQuery export of a Google Analytics 4 property
+	DataSourceTypeGoogleDrive  DataSourceType = "google_drive" // CSV/Excel file in Google Drive, downloaded and kept fresh
 )
 
+// UsesAggregationPipeline reports whether queries on the data source type are
+// MongoDB aggregation pipelines rather than SQL
+func (t DataSourceType) UsesAggregationPipeline() bool {
+	return t == DataSourceTypeMongoDB
+}
+
 // ConnectionStatus represents the status of a data source connection
 type ConnectionStatus string
 
 const (
-	ConnectionStatusActive     ConnectionStatus = "active"
-	ConnectionStatusInactive   ConnectionStatus = "inactive"
-	ConnectionStatusError      ConnectionStatus = "error"
-	ConnectionStatusConnecting ConnectionStatus = "connecting"
+	ConnectionStatusActive      ConnectionStatus = "active"
+	ConnectionStatusInactive    ConnectionStatus = "inactive"
+	ConnectionStatusError       ConnectionStatus = "error"
+	ConnectionStatusConnecting  ConnectionStatus = "connecting"  // Discovery queued or testing the connection
+	ConnectionStatusDiscovering ConnectionStatus = "discovering" // Connected, discovering the schema
 )
 
 // DataSource represents a data source configuration
 type DataSource struct {
 	ID          uint                   `json:"id" gorm:"primaryKey"`
 	UserID      uint                   `json:"user_id" gorm:"not null;index"`
+	TenantID    uint                   `json:"-" gorm:"not null;default:1;index"`
 	Name        string                 `json:"name" gorm:"not null"`
 	Description string                 `json:"description"`
 	Type        DataSourceType         `json:"type" gorm:"not null"`
@@ -79,9 +92,22 @@ type DataSource struct {
 	Schemas []Schema `json:"schemas" gorm:"foreignKey:DataSourceID"`
 }
 
+// DataSourceDiscoveryEvent records a status transition of the connection test
+// and schema discovery of a data source
+type DataSourceDiscoveryEvent struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	TenantID     uint             `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint             `json:"data_source_id" gorm:"not null;index"`
+	JobID        uint             `json:"job_id,omitempty"`
+	Status       ConnectionStatus `json:"status" gorm:"not null"`
+	Message      string           `json:"message,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
 // Schema represents the schema of a data source
 type Schema struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
+	TenantID     uint           `json:"-" gorm:"not null;default:1;index"`
 	DataSourceID uint           `json:"data_source_id" gorm:"not null;index"`
 	Name         string         `json:"name" gorm:"not null"` // table name, sheet name, etc.
 	DisplayName  string         `json:"display_name"`
@@ -89,6 +115,7 @@ type Schema struct {
 	Columns      JSON           `json:"columns" gorm:"type:jsonb"` // Store column definitions
 	RowCount     int64          `json:"row_count"`
 	SampleData   JSON           `json:"sample_data" gorm:"type:jsonb"` // Store sample rows
+	Relationships JSON          `json:"relationships" gorm:"type:jsonb"` // Store foreign key relationships
 	IsActive     bool           `json:"is_active" gorm:"default:true"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -106,6 +133,50 @@ type Column struct {
 	PrimaryKey  bool   `json:"primary_key"`
 	Description string `json:"description"`
 	SampleValues []interface{} `json:"sample_values,omitempty"`
+	ForeignKey  *ForeignKeyRef `json:"foreign_key,omitempty"` // Referenced column when this is a foreign key
+	Stats       *ColumnStats   `json:"stats,omitempty"`       // Statistics collected during schema discovery
+
+	// Curated by users, see ColumnMetadata
+	DisplayName        string   `json:"display_name,omitempty"`
+	CuratedDescription string   `json:"curated_description,omitempty"`
+	Tags               []string `json:"tags,omitempty"` // Semantic tags, e.g. currency or customer_id
+	PII                bool     `json:"pii,omitempty"`
+	Unit               string   `json:"unit,omitempty"`
+	Currency           string   `json:"currency,omitempty"`
+	Tests              []string `json:"tests,omitempty"` // dbt tests, e.g. unique or not_null
+
+	// Set on result columns only, see ColumnFormat
+	Format *ColumnFormat `json:"format,omitempty"`
+}
+
+// PreferredDescription returns the curated description of the column, or the discovered one
+func (c Column) PreferredDescription() string {
+	if c.CuratedDescription != "" {
+		return c.CuratedDescription
+	}
+	return c.Description
+}
+
+// ColumnStats holds basic statistics of a column, computed over a bounded sample of rows
+type ColumnStats struct {
+	Min           string  `json:"min,omitempty"`
+	Max           string  `json:"max,omitempty"`
+	DistinctCount int64   `json:"distinct_count"`
+	NullRatio     float64 `json:"null_ratio"`
+}
+
+// ForeignKeyRef points at the column referenced by a foreign key
+type ForeignKeyRef struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+}
+
+// TableRelationship describes a join path between two tables
+type TableRelationship struct {
+	FromTable  string `json:"from_table"`
+	FromColumn string `json:"from_column"`
+	ToTable    string `json:"to_table"`
+	ToColumn   string `json:"to_column"`
 }
 
 // ConnectionConfig represents configuration for different data source types
@@ -117,6 +188,7 @@ type ConnectionConfig struct {
 	HasHeader    bool   `json:"has_header,omitempty"`
 	Delimiter    string `json:"delimiter,omitempty"`
 	Encoding     string `json:"encoding,omitempty"`
+	Sheets       []string `json:"sheets,omitempty"` // Excel or Google Sheets sheets imported as tables; all when empty
 
 	// For database connections
 	Host     string `json:"host,omitempty"`
@@ -130,13 +202,52 @@ type ConnectionConfig struct {
 	ProjectID      string `json:"project_id,omitempty"`
 	DatasetID      string `json:"dataset_id,omitempty"`
 	CredentialsJSON string `json:"credentials_json,omitempty"` // Should be encrypted
+	// Stored service account used instead of CredentialsJSON, and its encrypted key copied on save
+	ServiceAccountID     uint   `json:"service_account_id,omitempty"`
+	CredentialsEncrypted string `json:"credentials_encrypted,omitempty"`
 
-	// For Google Sheets
+	// For GA4 BigQuery exports (ProjectID is shared; DatasetID defaults to analytics_<property_id>)
+	PropertyID string `json:"property_id,omitempty"`
+
+	// For Google Sheets (Sheets is shared and, like SheetName, selects the sheets registered as tables)
 	SpreadsheetID string `json:"spreadsheet_id,omitempty"`
 	SheetName     string `json:"sheet_name,omitempty"`
-	Range         string `json:"range,omitempty"`
+	Range         string `json:"range,omitempty"`      // A1 range read from each sheet, e.g. B3:F or Orders!A1:D500
+	HeaderRow     int    `json:"header_row,omitempty"` // Sheet row holding the headers, when below the start of the range
 	AccessToken   string `json:"access_token,omitempty"`   // Should be encrypted
 	RefreshToken  string `json:"refresh_token,omitempty"` // Should be encrypted
+
+	// For Google Drive files (AccessToken, RefreshToken and CredentialsJSON are shared). The
+	// file is downloaded to FilePath and read with the file options above.
+	FileID                 string `json:"file_id,omitempty"`
+	RevisionID             string `json:"revision_id,omitempty"`              // Drive revision of the downloaded file
+	RefreshIntervalMinutes int    `json:"refresh_interval_minutes,omitempty"` // How often Drive is checked for a new revision, or materialized data is refreshed; default 60
+
+	// For MongoDB (Host, Port, Database, Username and Password are shared)
+	ConnectionURI string `json:"connection_uri,omitempty"` // Should be encrypted
+	AuthSource    string `json:"auth_source,omitempty"`
+	TLS           bool   `json:"tls,omitempty"`
+
+	// For REST APIs (records are fetched, flattened and materialized to FilePath)
+	URL            string `json:"url,omitempty"`
+	AuthHeader     string `json:"auth_header,omitempty"` // Defaults to Authorization
+	AuthValue      string `json:"auth_value,omitempty"`  // Should be encrypted
+	RecordsPath    string `json:"records_path,omitempty"` // Dot path to the record array, e.g. data.items
+	Pagination     string `json:"pagination,omitempty"`   // none, page, offset, cursor or link
+	PageParam      string `json:"page_param,omitempty"`
+	PageSizeParam  string `json:"page_size_param,omitempty"`
+	PageSize       int    `json:"page_size,omitempty"`
+	CursorParam    string `json:"cursor_param,omitempty"`
+	NextCursorPath string `json:"next_cursor_path,omitempty"`
+	MaxPages       int    `json:"max_pages,omitempty"`
+	MaxRecords     int    `json:"max_records,omitempty"`
+	TableName      string `json:"table_name,omitempty"`
+
+	// For materialized copies of Google Sheets and REST APIs, see DataSourceMaterialization
+	Materialize       bool   `json:"materialize,omitempty"`        // Copy Google Sheets locally so they can be queried; REST APIs always are
+	IncrementalColumn string `json:"incremental_column,omitempty"` // Record field whose highest value is the watermark of incremental pulls
+	IncrementalParam  string `json:"incremental_param,omitempty"`  // Query parameter the watermark is sent as, e.g. updated_since
+	PrimaryKey        string `json:"primary_key,omitempty"`        // Record field identifying records replaced by incremental pulls
 }
 
 // Request/Response DTOs
@@ -147,6 +258,15 @@ type DataSourceCreateRequest struct {
 	Config      map[string]interface{} `json:"config" validate:"required"`
 }
 
+// DataSourceDuplicateRequest copies the configuration of a data source to a new
+// one. Credentials are not copied: Config must provide them, and may override
+// other fields such as the database.
+type DataSourceDuplicateRequest struct {
+	Name        string                 `json:"name" validate:"required,min=1,max=100"`
+	Description string                 `json:"description" validate:"max=500"`
+	Config      map[string]interface{} `json:"config"`
+}
+
 type DataSourceUpdateRequest struct {
 	Name        string                 `json:"name" validate:"min=1,max=100"`
 	Description string                 `json:"description" validate:"max=500"`
@@ -168,6 +288,15 @@ type DataSourceResponse struct {
 	Schemas     []SchemaResponse       `json:"schemas,omitempty"`
 }
 
+// DataSourceDiscoveryResponse is the progress of the schema discovery of a data source
+type DataSourceDiscoveryResponse struct {
+	DataSourceID uint                       `json:"data_source_id"`
+	Status       ConnectionStatus           `json:"status"`
+	ErrorMsg     string                     `json:"error_message,omitempty"`
+	Job          *Job                       `json:"job,omitempty"` // Latest discovery job, with its attempts and retry time
+	Events       []DataSourceDiscoveryEvent `json:"events"`        // Oldest first
+}
+
 type SchemaResponse struct {
 	ID          uint                   `json:"id"`
 	Name        string                 `json:"name"`
@@ -176,6 +305,7 @@ type SchemaResponse struct {
 	Columns     []Column               `json:"columns"`
 	RowCount    int64                  `json:"row_count"`
 	SampleData  []map[string]interface{} `json:"sample_data,omitempty"`
+	Relationships []TableRelationship  `json:"relationships,omitempty"`
 	IsActive    bool                   `json:"is_active"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
@@ -193,12 +323,34 @@ type TestConnectionResponse struct {
 }
 
 type FileUploadResponse struct {
-	FileName string `json:"file_name"`
-	FilePath string `json:"file_path"`
-	FileSize int64  `json:"file_size"`
-	MimeType string `json:"mime_type"`
+	FileName string            `json:"file_name"`
+	FilePath string            `json:"file_path"`
+	FileSize int64             `json:"file_size"`
+	MimeType string            `json:"mime_type"`
+	Options  FileImportOptions `json:"options"`
+	Tables   []FileUploadTable `json:"tables"` // One per CSV file or imported Excel sheet
+}
+
+// FileImportOptions control how an uploaded CSV or Excel file is read
+type FileImportOptions struct {
+	Delimiter string   `json:"delimiter,omitempty"`  // CSV field delimiter, "," by default; "tab" or "\\t" for tab-separated files
+	Encoding  string   `json:"encoding,omitempty"`   // CSV character encoding, e.g. windows-1252 or iso-8859-1; detected when empty
+	HasHeader *bool    `json:"has_header,omitempty"` // Whether the first row holds the column names, true by default
+	Sheets    []string `json:"sheets,omitempty"`     // Excel sheets to import, each as its own table; all when empty
 }
 
+// FileUploadTable is the inferred schema of a CSV file or an Excel sheet
+type FileUploadTable struct {
+	Name       string                   `json:"name"`
+	Columns    []Column                 `json:"columns"`
+	RowCount   int64                    `json:"row_count"`
+	SampleData []map[string]interface{} `json:"sample_data"`
+}
+
+// SensitiveConfigFields are the configuration fields holding credentials; they
+// are masked in responses and never copied between data sources
+var SensitiveConfigFields = []string{"password", "credentials_json", "credentials_encrypted", "access_token", "refresh_token", "connection_uri", "auth_value"}
+
 // Helper methods
 func (ds *DataSource) MaskSensitiveConfig() map[string]interface{} {
 	var config map[string]interface{}
@@ -207,8 +359,7 @@ func (ds *DataSource) MaskSensitiveConfig() map[string]interface{} {
 	}
 
 	// Mask sensitive fields
-	sensitiveFields := []string{"password", "credentials_json", "access_token", "refresh_token"}
-	for _, field := range sensitiveFields {
+	for _, field := range SensitiveConfigFields {
 		if _, exists := config[field]; exists {
 			config[field] = "***masked***"
 		}
@@ -249,6 +400,11 @@ func (s *Schema) ToResponse() *SchemaResponse {
 		// Successfully unmarshaled
 	}
 
+	var relationships []TableRelationship
+	if err := json.Unmarshal(s.Relationships, &relationships); err == nil {
+		// Successfully unmarshaled
+	}
+
 	return &SchemaResponse{
 		ID:          s.ID,
 		Name:        s.Name,
@@ -257,8 +413,37 @@ func (s *Schema) ToResponse() *SchemaResponse {
 		Columns:     columns,
 		RowCount:    s.RowCount,
 		SampleData:  sampleData,
+		Relationships: relationships,
 		IsActive:    s.IsActive,
 		CreatedAt:   s.CreatedAt,
 		UpdatedAt:   s.UpdatedAt,
 	}
+}
+
+// BuildRelationships derives table relationships from foreign key columns.
+// Column names are expected in "table.column" form as returned by database connectors.
+func BuildRelationships(columns []Column) []TableRelationship {
+	var relationships []TableRelationship
+	for _, col := range columns {
+		if col.ForeignKey == nil {
+			continue
+		}
+		tableName, columnName := SplitColumnName(col.Name)
+		relationships = append(relationships, TableRelationship{
+			FromTable:  tableName,
+			FromColumn: columnName,
+			ToTable:    col.ForeignKey.Table,
+			ToColumn:   col.ForeignKey.Column,
+		})
+	}
+	return relationships
+}
+
+// SplitColumnName splits a qualified "table.column" name into its parts.
+// Unqualified names return an empty table name.
+func SplitColumnName(name string) (string, string) {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
 }
\ No newline at end of file
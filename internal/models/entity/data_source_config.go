@@ -0,0 +1,52 @@
+package models
+
+// DataSourceConfigRequest declares every data source of the user. Applying it
+// creates the declared data sources that do not exist, updates the ones that
+// differ and deletes the ones not declared. Data sources are matched by name.
+type DataSourceConfigRequest struct {
+	DataSources []DataSourceSpec `json:"data_sources" validate:"dive"`
+}
+
+// DataSourceSpec declares a data source. Credentials can be given as secret
+// references instead of values, so the declaration can be checked in.
+type DataSourceSpec struct {
+	Name        string                 `json:"name" validate:"required,min=1,max=100"`
+	Description string                 `json:"description" validate:"max=500"`
+	Type        DataSourceType         `json:"type" validate:"required"`
+	Config      map[string]interface{} `json:"config" validate:"required"`
+	Secrets     map[string]string      `json:"secrets,omitempty"` // Config field to secret reference, e.g. {"password": "env:NARAPULSE_SECRET_PG_PASSWORD"}
+}
+
+// DataSourceConfigAction is what applying a declaration does to a data source
+type DataSourceConfigAction string
+
+const (
+	DataSourceConfigActionCreate    DataSourceConfigAction = "create"
+	DataSourceConfigActionUpdate    DataSourceConfigAction = "update"
+	DataSourceConfigActionReplace   DataSourceConfigAction = "replace" // The type changed: deleted and created again
+	DataSourceConfigActionDelete    DataSourceConfigAction = "delete"
+	DataSourceConfigActionUnchanged DataSourceConfigAction = "unchanged"
+)
+
+// DataSourceConfigChange is the change of one data source in a plan
+type DataSourceConfigChange struct {
+	Action       DataSourceConfigAction `json:"action"`
+	Name         string                 `json:"name"`
+	Type         DataSourceType         `json:"type"`
+	DataSourceID uint                   `json:"data_source_id,omitempty"` // The existing data source, or the created one once applied
+	ReplacedID   uint                   `json:"replaced_id,omitempty"`    // The data source a replace deleted
+	Fields       []string               `json:"fields,omitempty"`         // Changed fields, e.g. description or config.host; values are never shown
+	Applied      bool                   `json:"applied"`
+}
+
+// DataSourceConfigPlan lists the changes converging the data sources of the
+// user to a declaration, in the order they are applied
+type DataSourceConfigPlan struct {
+	DryRun    bool                     `json:"dry_run"`
+	Changes   []DataSourceConfigChange `json:"changes"`
+	Created   int                      `json:"created"`
+	Updated   int                      `json:"updated"`
+	Replaced  int                      `json:"replaced"`
+	Deleted   int                      `json:"deleted"`
+	Unchanged int                      `json:"unchanged"`
+}
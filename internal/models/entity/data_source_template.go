@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// DataSourceTemplate is an admin-curated connection configuration that users
+// instantiate into data sources. String values may contain {{name}}
+// placeholders, which are filled in when the template is used; credentials
+// are always placeholders.
+type DataSourceTemplate struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null;uniqueIndex"`
+	Description string         `json:"description"`
+	Type        DataSourceType `json:"type" gorm:"not null"`
+	Config      JSON           `json:"-" gorm:"type:jsonb"`
+	CreatedBy   uint           `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// DataSourceTemplateRequest creates or replaces a data source template
+type DataSourceTemplateRequest struct {
+	Name        string                 `json:"name" validate:"required,min=1,max=100"`
+	Description string                 `json:"description" validate:"max=500"`
+	Type        DataSourceType         `json:"type" validate:"required"`
+	Config      map[string]interface{} `json:"config" validate:"required"`
+}
+
+// DataSourceTemplateResponse is a template with the placeholders to fill in
+type DataSourceTemplateResponse struct {
+	ID           uint                   `json:"id"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Type         DataSourceType         `json:"type"`
+	Config       map[string]interface{} `json:"config"`
+	Placeholders []string               `json:"placeholders"`
+	CreatedBy    uint                   `json:"created_by"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// DataSourceFromTemplateRequest creates a data source from a template with
+// values for its placeholders
+type DataSourceFromTemplateRequest struct {
+	Name        string                 `json:"name" validate:"required,min=1,max=100"`
+	Description string                 `json:"description" validate:"max=500"`
+	Values      map[string]interface{} `json:"values"`
+}
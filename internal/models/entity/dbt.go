@@ -0,0 +1,16 @@
+package models
+
+// DbtImportResult summarizes the import of a dbt manifest into the column
+// metadata of a data source and the glossary
+type DbtImportResult struct {
+	Project              string   `json:"project,omitempty"`
+	DbtVersion           string   `json:"dbt_version,omitempty"`
+	ModelsMatched        int      `json:"models_matched"`             // Models, seeds, snapshots and sources found among the tables of the data source
+	UnmatchedModels      []string `json:"unmatched_models,omitempty"` // Relations of the manifest the data source has no table for
+	TablesUpdated        int      `json:"tables_updated"`
+	ColumnsUpdated       int      `json:"columns_updated"`
+	TestsImported        int      `json:"tests_imported"`
+	GlossaryTermsCreated int      `json:"glossary_terms_created"` // From the metrics of the manifest
+	GlossaryTermsUpdated int      `json:"glossary_terms_updated"`
+	GlossaryTermsSkipped int      `json:"glossary_terms_skipped"` // Terms of the same name the user wrote themselves are kept
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+)
+
+// DigestFrequency is how often a user receives the workspace digest email
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff    DigestFrequency = "off"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// DigestSubscription stores a user's digest preference and when the next one is due
+type DigestSubscription struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	UserID     uint            `json:"user_id" gorm:"not null;uniqueIndex"`
+	TenantID   uint            `json:"-" gorm:"not null;default:1;index"`
+	Frequency  DigestFrequency `json:"frequency" gorm:"not null;default:weekly"`
+	LastSentAt *time.Time      `json:"last_sent_at,omitempty"`
+	NextRunAt  time.Time       `json:"next_run_at" gorm:"not null;index"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// KPISnapshot is a KPI value observed when a question about the KPI was
+// answered. Digests compare snapshots to report KPI movements.
+type KPISnapshot struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	TenantID   uint      `json:"-" gorm:"not null;default:1;index"`
+	KPIID      uint      `json:"kpi_id" gorm:"column:kpi_id;not null;index"`
+	QueryID    uint      `json:"query_id"`
+	Value      float64   `json:"value"`
+	RecordedAt time.Time `json:"recorded_at" gorm:"not null;index"`
+}
+
+// Digest is the composed summary of a user's workspace over one period
+type Digest struct {
+	UserID         uint                `json:"user_id"`
+	Frequency      DigestFrequency     `json:"frequency"`
+	PeriodStart    time.Time           `json:"period_start"`
+	PeriodEnd      time.Time           `json:"period_end"`
+	TopQueries     []DigestQuery       `json:"top_queries"`
+	KPIMovements   []DigestKPIMovement `json:"kpi_movements"`
+	FailedSyncs    []DigestFailedSync  `json:"failed_syncs"`
+	NewDataSources []DigestDataSource  `json:"new_data_sources"`
+}
+
+// DigestQuery is a question the user ran often during the period
+type DigestQuery struct {
+	NLQuery  string `json:"nl_query"`
+	RunCount int64  `json:"run_count"`
+}
+
+// DigestKPIMovement compares the last value of a KPI in the period with its
+// last value in the prior period
+type DigestKPIMovement struct {
+	KPIID         uint     `json:"kpi_id"`
+	Name          string   `json:"name"`
+	Unit          string   `json:"unit,omitempty"`
+	Current       float64  `json:"current"`
+	Previous      *float64 `json:"previous,omitempty"` // Nil when the KPI was not observed in the prior period
+	Change        *float64 `json:"change,omitempty"`
+	ChangePercent *float64 `json:"change_percent,omitempty"` // Nil when the previous value is missing or zero
+}
+
+// DigestFailedSync summarizes the failed schema syncs of a data source
+type DigestFailedSync struct {
+	DataSourceID   uint      `json:"data_source_id"`
+	DataSourceName string    `json:"data_source_name"`
+	Failures       int64     `json:"failures"`
+	LastError      string    `json:"last_error"`
+	LastFailedAt   time.Time `json:"last_failed_at"`
+}
+
+// DigestDataSource is a data source added during the period
+type DigestDataSource struct {
+	ID        uint           `json:"id"`
+	Name      string         `json:"name"`
+	Type      DataSourceType `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// DigestSubscriptionRequest updates the digest preference of the current user
+type DigestSubscriptionRequest struct {
+	Frequency DigestFrequency `json:"frequency" validate:"required,oneof=off daily weekly"`
+}
+
+// DigestRunResult reports a run of the digest job
+type DigestRunResult struct {
+	Due     int      `json:"due"`
+	Sent    int      `json:"sent"`
+	Skipped int      `json:"skipped"` // Inactive or deleted users
+	Failed  int      `json:"failed"`  // Retried an hour later
+	Errors  []string `json:"errors,omitempty"`
+}
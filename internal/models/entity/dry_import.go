@@ -0,0 +1,55 @@
+package models
+
+// DryImportSeverity tells whether an issue blocks the import
+type DryImportSeverity string
+
+const (
+	DryImportSeverityError   DryImportSeverity = "error"   // Must be fixed before importing
+	DryImportSeverityWarning DryImportSeverity = "warning" // Import works, but data may be wrong
+)
+
+// DryImportIssueCode identifies the kind of problem found by a dry import
+type DryImportIssueCode string
+
+const (
+	DryImportIssueEmptyFile       DryImportIssueCode = "empty_file"
+	DryImportIssueEmptyHeader     DryImportIssueCode = "empty_header"
+	DryImportIssueDuplicateHeader DryImportIssueCode = "duplicate_header"
+	DryImportIssueInvalidEncoding DryImportIssueCode = "invalid_encoding"
+	DryImportIssueReplacementChar DryImportIssueCode = "replacement_character"
+	DryImportIssueMalformedRow    DryImportIssueCode = "malformed_row"
+	DryImportIssueRaggedRow       DryImportIssueCode = "ragged_row"
+	DryImportIssueTypeConflict    DryImportIssueCode = "type_conflict"
+)
+
+// DryImportIssue is a problem found while validating a file or sheet before import.
+// Repeated problems are reported once per table and column with a count.
+type DryImportIssue struct {
+	Severity DryImportSeverity  `json:"severity"`
+	Code     DryImportIssueCode `json:"code"`
+	Table    string             `json:"table,omitempty"`
+	Column   string             `json:"column,omitempty"`
+	Message  string             `json:"message"`
+	Count    int                `json:"count,omitempty"`    // Number of affected rows or cells
+	Rows     []int              `json:"rows,omitempty"`     // First affected rows, 1-based including the header row
+	Examples []string           `json:"examples,omitempty"` // First offending values
+}
+
+// DryImportTable is the inferred schema of one file or sheet
+type DryImportTable struct {
+	Name              string                   `json:"name"`
+	Columns           []Column                 `json:"columns"`
+	RowCount          int64                    `json:"row_count"`
+	RowCountEstimated bool                     `json:"row_count_estimated"` // Only part of the data was scanned
+	RowsScanned       int64                    `json:"rows_scanned"`
+	SampleData        []map[string]interface{} `json:"sample_data,omitempty"`
+}
+
+// DryImportReport is the result of validating a data source without creating it
+type DryImportReport struct {
+	Type      DataSourceType   `json:"type"`
+	FileName  string           `json:"file_name,omitempty"`
+	Tables    []DryImportTable `json:"tables"`
+	Issues    []DryImportIssue `json:"issues"`
+	CanImport bool             `json:"can_import"` // No error-severity issues
+}
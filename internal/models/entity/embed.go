@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Request/Response DTOs
+
+// EmbedTokenRequest issues an embed token for a dashboard, or one widget of
+// it. Domains restrict the pages that may embed it, e.g. "app.example.com"
+// or "*.example.com".
+type EmbedTokenRequest struct {
+	DashboardID      uint     `json:"dashboard_id" validate:"required"`
+	WidgetID         uint     `json:"widget_id,omitempty"`
+	Domains          []string `json:"domains,omitempty" validate:"omitempty,max=10,dive,min=1,max=253"`
+	RowLimit         int      `json:"row_limit,omitempty" validate:"omitempty,min=1,max=1000"`          // Default 100
+	ExpiresInMinutes int      `json:"expires_in_minutes,omitempty" validate:"omitempty,min=1,max=1440"` // Default 60
+}
+
+// EmbedTokenResponse is an issued embed token
+type EmbedTokenResponse struct {
+	Token       string    `json:"token"`
+	DashboardID uint      `json:"dashboard_id"`
+	WidgetID    uint      `json:"widget_id,omitempty"`
+	Domains     []string  `json:"domains,omitempty"`
+	RowLimit    int       `json:"row_limit"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	URL         string    `json:"url"` // Embed route of the dashboard or widget, to be called with the token
+}
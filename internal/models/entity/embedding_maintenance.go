@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// Request/Response DTOs
+
+// EmbeddingCompactRequest selects the soft-deleted embeddings a compaction
+// removes permanently. Vacuuming always covers the whole table.
+type EmbeddingCompactRequest struct {
+	DataSourceID  *uint  `json:"data_source_id,omitempty"` // Only embeddings of this data source; 0 for KPIs and glossary terms
+	DeletedBefore string `json:"deleted_before,omitempty"` // Only embeddings deleted before; YYYY-MM-DD or RFC 3339
+	Full          bool   `json:"full"`                     // VACUUM FULL: returns the space to the OS but locks the table while it rewrites it
+}
+
+// EmbeddingCompactRun reports what a compaction removed and reclaimed
+type EmbeddingCompactRun struct {
+	EmbeddingsPurged int64  `json:"embeddings_purged"`  // Soft-deleted rows removed permanently
+	Vacuum           string `json:"vacuum"`             // The VACUUM statement run
+	TableBytesBefore int64  `json:"table_bytes_before"` // Table, TOAST and indexes
+	TableBytesAfter  int64  `json:"table_bytes_after"`
+	DurationMs       int64  `json:"duration_ms"`
+}
+
+// VectorIndexRebuild reports the rebuild of a pgvector index
+type VectorIndexRebuild struct {
+	Name        string `json:"name"`
+	Table       string `json:"table"`
+	Created     bool   `json:"created"` // The index was missing and has been created
+	BytesBefore int64  `json:"bytes_before"`
+	BytesAfter  int64  `json:"bytes_after"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+// EmbeddingStorageStats reports the storage of the schema embeddings table
+// and its embeddings per data source
+type EmbeddingStorageStats struct {
+	TableBytes    int64                        `json:"table_bytes"` // Heap and TOAST
+	IndexBytes    int64                        `json:"index_bytes"` // All indexes
+	VectorIndexes []VectorIndexStats           `json:"vector_indexes"`
+	LiveRows      int64                        `json:"live_rows"` // Estimates of the statistics collector
+	DeadRows      int64                        `json:"dead_rows"`
+	LastVacuumAt  *time.Time                   `json:"last_vacuum_at,omitempty"` // Manual or automatic, whichever was last
+	LastAnalyzeAt *time.Time                   `json:"last_analyze_at,omitempty"`
+	DataSources   []DataSourceEmbeddingStorage `json:"data_sources"`
+}
+
+// VectorIndexStats reports the size and validity of a pgvector index
+type VectorIndexStats struct {
+	Name   string `json:"name"`
+	Table  string `json:"table"`
+	Exists bool   `json:"exists"`
+	Valid  bool   `json:"valid"` // False after a failed concurrent rebuild; rebuild it again
+	Bytes  int64  `json:"bytes"`
+}
+
+// DataSourceEmbeddingStorage counts the embeddings of a data source. Data
+// source 0 holds the KPI and glossary embeddings shared by all data sources.
+type DataSourceEmbeddingStorage struct {
+	DataSourceID      uint       `json:"data_source_id"`
+	DataSourceName    string     `json:"data_source_name,omitempty"`
+	Embeddings        int64      `json:"embeddings"`
+	DeletedEmbeddings int64      `json:"deleted_embeddings"` // Soft-deleted rows a compaction removes
+	Tables            int64      `json:"tables"`
+	Columns           int64      `json:"columns"`
+	KPIs              int64      `json:"kpis" gorm:"column:kpis"`
+	GlossaryTerms     int64      `json:"glossary_terms"`
+	Unhashed          int64      `json:"unhashed"` // Stored before content hashes; re-embedded on the next sync
+	Bytes             int64      `json:"bytes"`    // Row sizes, deleted rows included
+	LastUpdatedAt     *time.Time `json:"last_updated_at,omitempty"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import "github.com/gofiber/fiber/v2"
+
+// ErrorCode identifies the kind of an API error, so clients can handle errors
+// without parsing messages. Every code has one HTTP status, see ErrorCatalog.
+type ErrorCode string
+
+const (
+	ErrorCodeBadRequest          ErrorCode = "BAD_REQUEST"
+	ErrorCodeInvalidRequestBody  ErrorCode = "INVALID_REQUEST_BODY"
+	ErrorCodeValidationFailed    ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeInvalidParameter    ErrorCode = "INVALID_PARAMETER"
+	ErrorCodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	ErrorCodeMFARequired         ErrorCode = "MFA_REQUIRED"
+	ErrorCodeUsageQuotaExceeded  ErrorCode = "USAGE_QUOTA_EXCEEDED"
+	ErrorCodeForbidden           ErrorCode = "FORBIDDEN"
+	ErrorCodeTenantMismatch      ErrorCode = "TENANT_MISMATCH"
+	ErrorCodeFeatureDisabled     ErrorCode = "FEATURE_DISABLED"
+	ErrorCodeNotFound            ErrorCode = "NOT_FOUND"
+	ErrorCodeConflict            ErrorCode = "CONFLICT"
+	ErrorCodeSyncInProgress      ErrorCode = "SYNC_IN_PROGRESS"
+	ErrorCodeCanaryDivergence    ErrorCode = "CANARY_DIVERGENCE"
+	ErrorCodeGone                ErrorCode = "GONE"
+	ErrorCodePayloadTooLarge     ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrorCodeUnprocessableEntity ErrorCode = "UNPROCESSABLE_ENTITY"
+	ErrorCodeRateLimited         ErrorCode = "RATE_LIMITED"
+	ErrorCodeInternal            ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeServiceUnavailable  ErrorCode = "SERVICE_UNAVAILABLE"
+)
+
+// ErrorCodeInfo describes an error code of the catalog
+type ErrorCodeInfo struct {
+	Code        ErrorCode `json:"code"`
+	Status      int       `json:"status"` // HTTP status of responses with the code
+	Description string    `json:"description"`
+}
+
+// errorCatalog lists every error code the API returns. The first code of a
+// status is the one responses with that status get unless a handler picks
+// a more specific one.
+var errorCatalog = []ErrorCodeInfo{
+	{ErrorCodeBadRequest, fiber.StatusBadRequest, "The request is invalid"},
+	{ErrorCodeInvalidRequestBody, fiber.StatusBadRequest, "The request body could not be parsed"},
+	{ErrorCodeValidationFailed, fiber.StatusBadRequest, "The request body or query failed validation; details name the failing fields"},
+	{ErrorCodeInvalidParameter, fiber.StatusBadRequest, "A path or query parameter, such as an ID, is malformed"},
+	{ErrorCodeUnauthorized, fiber.StatusUnauthorized, "The request is not authenticated, or its token is invalid or expired"},
+	{ErrorCodeMFARequired, fiber.StatusUnauthorized, "The user must enroll in or complete multi-factor authentication first"},
+	{ErrorCodeUsageQuotaExceeded, fiber.StatusPaymentRequired, "The LLM token quota of the user is used up for the period"},
+	{ErrorCodeForbidden, fiber.StatusForbidden, "The user may not perform the action on the resource"},
+	{ErrorCodeTenantMismatch, fiber.StatusForbidden, "The token belongs to another tenant than the one served on the requested domain"},
+	{ErrorCodeFeatureDisabled, fiber.StatusForbidden, "The feature is switched off for the tenant"},
+	{ErrorCodeNotFound, fiber.StatusNotFound, "The resource does not exist or belongs to another user"},
+	{ErrorCodeConflict, fiber.StatusConflict, "The resource is in a state that does not allow the action, or already exists"},
+	{ErrorCodeSyncInProgress, fiber.StatusConflict, "The data source is already being synced"},
+	{ErrorCodeCanaryDivergence, fiber.StatusConflict, "The new SQL returned different results than the current SQL; details hold the canary report"},
+	{ErrorCodeGone, fiber.StatusGone, "The resource expired or was revoked"},
+	{ErrorCodePayloadTooLarge, fiber.StatusRequestEntityTooLarge, "The request body exceeds the size limit"},
+	{ErrorCodeUnprocessableEntity, fiber.StatusUnprocessableEntity, "The request is well-formed but its content was rejected; details explain why"},
+	{ErrorCodeRateLimited, fiber.StatusTooManyRequests, "Too many requests; retry after the Retry-After header"},
+	{ErrorCodeInternal, fiber.StatusInternalServerError, "An unexpected server error"},
+	{ErrorCodeServiceUnavailable, fiber.StatusServiceUnavailable, "A service the request depends on is not configured or unavailable"},
+}
+
+// ErrorCatalog returns every error code of the API with its HTTP status
+func ErrorCatalog() []ErrorCodeInfo {
+	return append([]ErrorCodeInfo(nil), errorCatalog...)
+}
+
+// Status returns the HTTP status of responses with the code
+func (c ErrorCode) Status() int {
+	for _, info := range errorCatalog {
+		if info.Code == c {
+			return info.Status
+		}
+	}
+	return fiber.StatusInternalServerError
+}
+
+// ErrorCodeForStatus returns the general error code of an HTTP status
+func ErrorCodeForStatus(status int) ErrorCode {
+	for _, info := range errorCatalog {
+		if info.Status == status {
+			return info.Code
+		}
+	}
+	if status < fiber.StatusInternalServerError {
+		return ErrorCodeBadRequest
+	}
+	return ErrorCodeInternal
+}
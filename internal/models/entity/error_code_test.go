@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCatalogCodesAreUnique(t *testing.T) {
+	seen := make(map[ErrorCode]bool)
+	for _, info := range ErrorCatalog() {
+		assert.False(t, seen[info.Code], "duplicate code %s", info.Code)
+		assert.NotEmpty(t, info.Description, info.Code)
+		seen[info.Code] = true
+	}
+}
+
+func TestErrorCodeStatus(t *testing.T) {
+	assert.Equal(t, fiber.StatusBadRequest, ErrorCodeValidationFailed.Status())
+	assert.Equal(t, fiber.StatusPaymentRequired, ErrorCodeUsageQuotaExceeded.Status())
+	assert.Equal(t, fiber.StatusConflict, ErrorCodeCanaryDivergence.Status())
+	assert.Equal(t, fiber.StatusInternalServerError, ErrorCode("UNKNOWN").Status())
+}
+
+func TestErrorCodeForStatus(t *testing.T) {
+	assert.Equal(t, ErrorCodeBadRequest, ErrorCodeForStatus(fiber.StatusBadRequest), "the general code, not a specific one")
+	assert.Equal(t, ErrorCodeUnauthorized, ErrorCodeForStatus(fiber.StatusUnauthorized))
+	assert.Equal(t, ErrorCodeConflict, ErrorCodeForStatus(fiber.StatusConflict))
+	assert.Equal(t, ErrorCodeBadRequest, ErrorCodeForStatus(fiber.StatusTeapot))
+	assert.Equal(t, ErrorCodeInternal, ErrorCodeForStatus(fiber.StatusBadGateway))
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Extract is a snapshot of the results of a query kept in local storage.
+// Dashboard widgets bound to an extract display the snapshot rather than
+// querying the data source on every view. The query runs again when its
+// refresh interval has passed, or on demand; a failed refresh keeps the
+// previous snapshot.
+type Extract struct {
+	ID                     uint                  `json:"id" gorm:"primaryKey"`
+	UserID                 uint                  `json:"user_id" gorm:"not null;index"`
+	TenantID               uint                  `json:"-" gorm:"not null;default:1;index"`
+	QueryID                uint                  `json:"query_id" gorm:"not null;index"`
+	DataSourceID           uint                  `json:"data_source_id" gorm:"not null"` // Of the query; PII columns are masked by its settings
+	Name                   string                `json:"name" gorm:"not null"`
+	Description            string                `json:"description,omitempty" gorm:"type:text"`
+	RefreshIntervalMinutes int                   `json:"refresh_interval_minutes" gorm:"not null"` // 0 refreshes on demand only
+	RowLimit               int                   `json:"row_limit" gorm:"not null;default:10000"`
+	FilePath               string                `json:"-"`                         // JSON lines of the snapshot rows
+	Columns                JSON                  `json:"columns" gorm:"type:jsonb"` // []Column of the snapshot
+	RowCount               int64                 `json:"row_count"`
+	Truncated              bool                  `json:"truncated"` // The query returned the row limit; more rows may exist
+	Status                 MaterializationStatus `json:"status" gorm:"not null;default:syncing"`
+	Error                  string                `json:"error,omitempty" gorm:"type:text"`
+	DurationMs             int64                 `json:"duration_ms"`            // Of the last successful refresh
+	RefreshedAt            *time.Time            `json:"refreshed_at,omitempty"` // Last successful refresh
+	CreatedAt              time.Time             `json:"created_at"`
+	UpdatedAt              time.Time             `json:"updated_at"`
+	DeletedAt              gorm.DeletedAt        `json:"-" gorm:"index"`
+}
+
+// Request/Response DTOs
+
+// ExtractRequest creates an extract of a query of the user, or changes one;
+// the query of an extract cannot change
+type ExtractRequest struct {
+	QueryID                uint   `json:"query_id,omitempty"`
+	Name                   string `json:"name" validate:"required,max=255"`
+	Description            string `json:"description,omitempty" validate:"max=2000"`
+	RefreshIntervalMinutes *int   `json:"refresh_interval_minutes,omitempty" validate:"omitempty,min=0,max=10080"` // Default 60
+	RowLimit               int    `json:"row_limit,omitempty" validate:"omitempty,min=1,max=10000"`                // Default 10000
+}
+
+// ExtractResponse is an extract with the freshness of its snapshot
+type ExtractResponse struct {
+	Extract
+	Freshness *DataFreshness `json:"freshness"`
+	JobID     uint           `json:"job_id,omitempty"` // Set when a refresh was queued
+}
+
+// ExtractDataRequest pages through the snapshot of an extract
+type ExtractDataRequest struct {
+	Offset    int  `query:"offset" validate:"min=0"`
+	Limit     int  `query:"limit" validate:"min=0,max=10000"` // Default 1000
+	UnmaskPII bool `query:"-"`                                // Set from the caller's permission
+}
+
+// ExtractData is a page of the snapshot of an extract
+type ExtractData struct {
+	ExtractID     uint                     `json:"extract_id"`
+	Columns       []Column                 `json:"columns"`
+	Data          []map[string]interface{} `json:"data"`
+	TotalRows     int64                    `json:"total_rows"`
+	HasMore       bool                     `json:"has_more"`
+	MaskedColumns []string                 `json:"masked_columns,omitempty"`
+	Freshness     *DataFreshness           `json:"freshness"`
+}
+
+// ExtractJobPayload is the payload of jobs that refresh one extract
+type ExtractJobPayload struct {
+	ExtractID uint `json:"extract_id"`
+}
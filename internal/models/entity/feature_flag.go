@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Flags gating capabilities as they roll out. Unknown flags are off.
+const (
+	FeatureFlagFederatedQueries = "federated_queries" // Questions spanning several data sources
+)
+
+// Targets of feature flag overrides
+const (
+	FeatureFlagScopeTenant = "tenant"
+	FeatureFlagScopeUser   = "user"
+)
+
+// Reasons a feature flag evaluated as it did
+const (
+	FeatureFlagReasonUnknown        = "unknown"         // No flag has the key
+	FeatureFlagReasonUserOverride   = "user_override"   // Overridden for the user
+	FeatureFlagReasonTenantOverride = "tenant_override" // Overridden for the user's tenant
+	FeatureFlagReasonDisabled       = "disabled"        // Switched off for everyone else
+	FeatureFlagReasonRollout        = "rollout"         // Decided by the rollout percentage
+)
+
+// FeatureFlag gates a capability for the whole installation. An enabled flag
+// is on for the rollout percentage of users, picked by a stable hash of the
+// flag and user, so a user keeps the same answer as the rollout grows.
+// Overrides for a tenant or user take precedence, those for the user first.
+type FeatureFlag struct {
+	ID                uint                  `json:"id" gorm:"primaryKey"`
+	Key               string                `json:"key" gorm:"not null;uniqueIndex"`
+	Description       string                `json:"description"`
+	Enabled           bool                  `json:"enabled" gorm:"not null"`
+	RolloutPercentage int                   `json:"rollout_percentage" gorm:"not null"` // Share of users the enabled flag is on for, 0-100
+	Overrides         []FeatureFlagOverride `json:"overrides" gorm:"foreignKey:FlagID;constraint:OnDelete:CASCADE"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+}
+
+// FeatureFlagOverride turns a flag on or off for a tenant or a user regardless
+// of the flag's state and rollout
+type FeatureFlagOverride struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	FlagID    uint      `json:"flag_id" gorm:"not null;uniqueIndex:idx_feature_flag_overrides_target"`
+	Scope     string    `json:"scope" gorm:"not null;uniqueIndex:idx_feature_flag_overrides_target"`      // tenant or user
+	SubjectID uint      `json:"subject_id" gorm:"not null;uniqueIndex:idx_feature_flag_overrides_target"` // ID of the tenant or user
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// FeatureFlagCreateRequest creates a feature flag
+type FeatureFlagCreateRequest struct {
+	Key               string `json:"key" validate:"required,max=100"` // Lowercase letters, digits, dots, dashes and underscores
+	Description       string `json:"description" validate:"max=500"`
+	Enabled           bool   `json:"enabled"`
+	RolloutPercentage *int   `json:"rollout_percentage" validate:"omitempty,min=0,max=100"` // Defaults to 100
+}
+
+// FeatureFlagUpdateRequest changes a feature flag; omitted fields are kept
+type FeatureFlagUpdateRequest struct {
+	Description       *string `json:"description" validate:"omitempty,max=500"`
+	Enabled           *bool   `json:"enabled"`
+	RolloutPercentage *int    `json:"rollout_percentage" validate:"omitempty,min=0,max=100"`
+}
+
+// FeatureFlagOverrideRequest sets the override of a flag for a tenant or user
+type FeatureFlagOverrideRequest struct {
+	Scope     string `json:"scope" validate:"required,oneof=tenant user"`
+	SubjectID uint   `json:"subject_id" validate:"required"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// FeatureFlagEvaluation is whether a flag is on for a user, and why
+type FeatureFlagEvaluation struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// FederationEngineDuckDB is the engine federated queries are joined in
+const FederationEngineDuckDB = "duckdb"
+
+// FederatedSourceRequest adds a data source to a federated question
+type FederatedSourceRequest struct {
+	DataSourceID uint `json:"data_source_id" validate:"required"`
+	RowLimit     int  `json:"row_limit,omitempty" validate:"omitempty,min=1,max=100000"` // Rows pulled per table; default 10000
+}
+
+// FederatedSource is a data source of a federated query. Its tables are
+// referenced as alias.table in the SQL of the query.
+type FederatedSource struct {
+	DataSourceID uint           `json:"data_source_id"`
+	Name         string         `json:"name"`
+	Type         DataSourceType `json:"type"`
+	Alias        string         `json:"alias"`
+	Tables       []string       `json:"tables"`    // Tables the query reads
+	RowLimit     int            `json:"row_limit"` // Rows pulled per table
+}
+
+// FederatedTablePull is a table pulled from its data source for a federated run
+type FederatedTablePull struct {
+	DataSourceID uint   `json:"data_source_id"`
+	Table        string `json:"table"` // alias.table
+	Rows         int64  `json:"rows"`
+	Truncated    bool   `json:"truncated"` // The row limit was reached, so the join ran on part of the table
+}
+
+// Federation describes a federated query: the tables it reads are pulled from
+// their data sources, up to a row limit per source, and joined in DuckDB
+type Federation struct {
+	Engine  string               `json:"engine"` // Always "duckdb"
+	Sources []FederatedSource    `json:"sources"`
+	Pulls   []FederatedTablePull `json:"pulls,omitempty"` // Set when the query was executed
+}
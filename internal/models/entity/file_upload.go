@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+// FileUploadStatus is the state of a chunked file upload
+type FileUploadStatus string
+
+const (
+	FileUploadStatusUploading  FileUploadStatus = "uploading"  // Waiting for parts
+	FileUploadStatusProcessing FileUploadStatus = "processing" // Parts are assembled and the schema inferred in the background
+	FileUploadStatusCompleted  FileUploadStatus = "completed"  // File stored and its tables inferred
+	FileUploadStatusFailed     FileUploadStatus = "failed"     // File could not be read; see error
+)
+
+// FileUpload is a chunked, resumable upload of a CSV or Excel file that is too
+// large for a single request. Parts are stored as they arrive and assembled
+// into one file when the upload is completed.
+type FileUpload struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	UserID      uint             `json:"user_id" gorm:"not null;index"`
+	TenantID    uint             `json:"-" gorm:"not null;default:1;index"`
+	FileName    string           `json:"file_name" gorm:"not null"`
+	FileSize    int64            `json:"file_size" gorm:"not null"` // Declared size of the whole file
+	MimeType    string           `json:"mime_type,omitempty"`
+	ChunkSize   int64            `json:"chunk_size" gorm:"not null"` // Size of every part but the last
+	TotalParts  int              `json:"total_parts" gorm:"not null"`
+	Status      FileUploadStatus `json:"status" gorm:"not null;default:uploading;index"`
+	Options     JSON             `json:"-" gorm:"type:jsonb"` // FileImportOptions
+	ObjectKey   string           `json:"-"`                   // Assembled file in the upload store
+	Tables      JSON             `json:"-" gorm:"type:jsonb"` // []FileUploadTable inferred from the file
+	Error       string           `json:"error,omitempty" gorm:"type:text"`
+	ExpiresAt   time.Time        `json:"expires_at" gorm:"not null;index"` // Incomplete uploads are deleted after this
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// FileUploadPart is a received part of a chunked upload; parts are numbered from 1
+type FileUploadPart struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	TenantID   uint      `json:"-" gorm:"not null;default:1;index"`
+	UploadID   uint      `json:"-" gorm:"not null;uniqueIndex:idx_file_upload_parts_number"`
+	PartNumber int       `json:"part_number" gorm:"not null;uniqueIndex:idx_file_upload_parts_number"`
+	Size       int64     `json:"size" gorm:"not null"`
+	Checksum   string    `json:"checksum" gorm:"not null"` // Hex SHA-256 of the part
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Request/Response DTOs
+
+// FileUploadInitiateRequest starts a chunked upload
+type FileUploadInitiateRequest struct {
+	FileName  string            `json:"file_name" validate:"required,max=255"`
+	FileSize  int64             `json:"file_size" validate:"required,min=1"`
+	MimeType  string            `json:"mime_type,omitempty" validate:"max=255"`
+	ChunkSize int64             `json:"chunk_size,omitempty" validate:"omitempty,min=1048576,max=33554432"` // 1-32MB, default 8MB
+	Options   FileImportOptions `json:"options"`
+}
+
+// FileUploadSessionResponse describes a chunked upload. Missing parts are the ones
+// to send when resuming an interrupted upload.
+type FileUploadSessionResponse struct {
+	FileUpload
+	Options       FileImportOptions `json:"options"`
+	ReceivedParts []int             `json:"received_parts"`
+	MissingParts  []int             `json:"missing_parts"`
+	UploadedBytes int64             `json:"uploaded_bytes"`
+	FilePath      string            `json:"file_path,omitempty"` // Set once completed, for the data source config
+	Tables        []FileUploadTable `json:"tables,omitempty"`
+	JobID         *uint             `json:"job_id,omitempty"` // Background job processing the upload
+}
+
+// FileUploadJobPayload is the payload of the job that processes a completed upload
+type FileUploadJobPayload struct {
+	UploadID uint `json:"upload_id"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// GoogleDriveFile is a CSV or Excel file, or a folder, in Google Drive
+type GoogleDriveFile struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	MimeType     string     `json:"mime_type"`
+	IsFolder     bool       `json:"is_folder"`
+	Size         int64      `json:"size,omitempty"`
+	ModifiedTime *time.Time `json:"modified_time,omitempty"`
+	RevisionID   string     `json:"revision_id,omitempty"` // Changes whenever the content changes
+}
+
+// Request/Response DTOs
+
+// GoogleDriveBrowseRequest lists a Drive folder, or searches Drive by name,
+// with the credentials of a google_drive data source
+type GoogleDriveBrowseRequest struct {
+	Config    map[string]interface{} `json:"config" validate:"required"` // access_token and refresh_token, or credentials_json
+	FolderID  string                 `json:"folder_id,omitempty"`        // Defaults to "root"; ignored when searching
+	Query     string                 `json:"query,omitempty" validate:"max=200"`
+	PageToken string                 `json:"page_token,omitempty"`
+	PageSize  int                    `json:"page_size,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// GoogleDriveBrowseResponse is a page of Drive files and folders, folders first
+type GoogleDriveBrowseResponse struct {
+	Files         []GoogleDriveFile `json:"files"`
+	NextPageToken string            `json:"next_page_token,omitempty"`
+}
+
+// GoogleDriveAuthURLResponse is the Google consent page granting read access to Drive
+type GoogleDriveAuthURLResponse struct {
+	URL   string `json:"url"`
+	Scope string `json:"scope"`
+}
+
+// GoogleDriveTokenRequest exchanges the code Google redirected back with for tokens
+type GoogleDriveTokenRequest struct {
+	Code        string `json:"code" validate:"required"`
+	RedirectURI string `json:"redirect_uri" validate:"required,url"`
+}
+
+// GoogleDriveTokenResponse holds the tokens for the config of a google_drive data source
+type GoogleDriveTokenResponse struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
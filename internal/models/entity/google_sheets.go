@@ -0,0 +1,24 @@
+package models
+
+// GoogleSheet is a sheet of a spreadsheet that a google_sheets data source
+// can register as a table
+type GoogleSheet struct {
+	Title    string   `json:"title"`
+	RowCount int64    `json:"row_count"`         // Grid rows, an upper bound of the rows holding data
+	Headers  []string `json:"headers,omitempty"` // First row of the configured range, read as the column names
+	Selected bool     `json:"selected"`          // Registered as a table with the given config
+}
+
+// Request/Response DTOs
+
+// GoogleSheetsListRequest lists the sheets of the spreadsheet of a
+// google_sheets data source config, applying its sheets, range and header_row
+type GoogleSheetsListRequest struct {
+	Config map[string]interface{} `json:"config" validate:"required"` // spreadsheet_id with access_token or credentials_json
+}
+
+// GoogleSheetsListResponse is the sheets of a spreadsheet in display order
+type GoogleSheetsListResponse struct {
+	SpreadsheetID string        `json:"spreadsheet_id"`
+	Sheets        []GoogleSheet `json:"sheets"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// GovernanceEventType represents the type of a compliance/governance event
+type GovernanceEventType string
+
+const (
+	GovernanceEventPIIColumnDetected   GovernanceEventType = "pii_column_detected"
+	GovernanceEventMaskedColumnQueried GovernanceEventType = "masked_column_queried"
+	GovernanceEventApprovalGranted     GovernanceEventType = "approval_granted"
+	GovernanceEventPolicyChanged       GovernanceEventType = "policy_changed"
+)
+
+// GovernanceEvent is an append-only log entry. The ID doubles as the
+// delivery sequence number, so webhooks are delivered and replayed in ID order.
+type GovernanceEvent struct {
+	ID           uint                `json:"sequence" gorm:"primaryKey"`
+	TenantID     uint                `json:"-" gorm:"not null;default:1;index"`
+	Type         GovernanceEventType `json:"type" gorm:"not null;index"`
+	DataSourceID uint                `json:"data_source_id,omitempty" gorm:"index"`
+	ActorID      uint                `json:"actor_id,omitempty"`
+	Payload      JSON                `json:"payload" gorm:"type:jsonb"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// GovernanceWebhookCursor tracks the last event delivered to the compliance webhook
+type GovernanceWebhookCursor struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	LastSequence    uint       `json:"last_sequence"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at"`
+	LastError       string     `json:"last_error,omitempty" gorm:"type:text"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// GovernanceReplayRequest selects a range of events to re-send
+type GovernanceReplayRequest struct {
+	FromSequence uint `json:"from_sequence" validate:"required"`
+	ToSequence   uint `json:"to_sequence,omitempty"` // 0 means up to the last delivered event
+}
+
+// GovernanceDeliveryResult summarizes a dispatch or replay run
+type GovernanceDeliveryResult struct {
+	Delivered    int    `json:"delivered"`
+	LastSequence uint   `json:"last_sequence"`
+	Error        string `json:"error,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// InsightKind is a kind of observation about a result set
+type InsightKind string
+
+const (
+	InsightKindTrend              InsightKind = "trend"               // Direction of a measure over time
+	InsightKindTopMover           InsightKind = "top_mover"           // Largest change between the first and last period
+	InsightKindBiggestContributor InsightKind = "biggest_contributor" // Largest share of a measure's total
+)
+
+// Trend directions
+const (
+	InsightDirectionUp   = "up"
+	InsightDirectionDown = "down"
+	InsightDirectionFlat = "flat"
+)
+
+// QueryInsight is one observation about a result set
+type QueryInsight struct {
+	Kind          InsightKind `json:"kind"`
+	Measure       string      `json:"measure"`             // Numeric column the insight is about
+	Dimension     string      `json:"dimension,omitempty"` // Column the label is a value of
+	Label         string      `json:"label,omitempty"`     // Category or period the insight points at
+	Value         float64     `json:"value"`
+	Change        *float64    `json:"change,omitempty"`
+	PercentChange *float64    `json:"percent_change,omitempty"`
+	Share         *float64    `json:"share,omitempty"` // Percent of the measure's total
+	Direction     string      `json:"direction,omitempty"`
+	Sentence      string      `json:"sentence"`
+}
+
+// QueryInsights summarizes a result set in natural language
+type QueryInsights struct {
+	Narrative string         `json:"narrative"`
+	Insights  []QueryInsight `json:"insights"`
+	Cached    bool           `json:"cached,omitempty"` // Served from the cache of identical results
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IntegrationProvider is the chat platform of an integration
+type IntegrationProvider string
+
+const (
+	IntegrationProviderSlack IntegrationProvider = "slack"
+	IntegrationProviderTeams IntegrationProvider = "teams"
+)
+
+// ChatIntegration is a workspace-level Slack or Teams destination configured
+// by an admin. Messages go to an incoming webhook or, for Slack apps, through
+// the bot token to any channel. With a signing secret, a command user and a
+// data source, the platform can also send questions to the command endpoint.
+// Integrations belong to the tenant of the admin who configured them.
+type ChatIntegration struct {
+	ID                  uint                `json:"id" gorm:"primaryKey"`
+	TenantID            uint                `json:"-" gorm:"not null;default:1;index"`
+	Provider            IntegrationProvider `json:"provider" gorm:"not null"`
+	Name                string              `json:"name" gorm:"not null"`
+	WebhookURL          string              `json:"-" gorm:"type:text"` // Incoming webhook; should be encrypted
+	BotToken            string              `json:"-" gorm:"type:text"` // Slack app bot token; should be encrypted
+	SigningSecret       string              `json:"-" gorm:"type:text"` // Slack signing secret or Teams outgoing webhook token
+	DefaultChannel      string              `json:"default_channel,omitempty"`
+	CommandUserID       *uint               `json:"command_user_id,omitempty"`        // User commands run as
+	CommandDataSourceID *uint               `json:"command_data_source_id,omitempty"` // Data source commands are asked about
+	CreatedBy           uint                `json:"created_by"`
+	IsActive            bool                `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// AlertCondition is the comparison of an alert rule with its threshold
+type AlertCondition string
+
+const (
+	AlertConditionAbove AlertCondition = "above"
+	AlertConditionBelow AlertCondition = "below"
+)
+
+// AlertRule watches a KPI and posts to a chat integration when the KPI
+// crosses the threshold, and again when it recovers
+type AlertRule struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"not null;index"` // Owner; the KPI is computed as this user
+	TenantID        uint           `json:"-" gorm:"not null;default:1;index"`
+	Name            string         `json:"name" gorm:"not null"`
+	KPIID           uint           `json:"kpi_id" gorm:"column:kpi_id;not null;index"`
+	Condition       AlertCondition `json:"condition" gorm:"not null"`
+	Threshold       float64        `json:"threshold"`
+	IntegrationID   uint           `json:"integration_id" gorm:"not null;index"`
+	Channel         string         `json:"channel,omitempty"` // Defaults to the integration's channel
+	IntervalMinutes int            `json:"interval_minutes" gorm:"not null;default:60"`
+	IsActive        bool           `json:"is_active" gorm:"not null;default:true"`
+	Triggered       bool           `json:"triggered"` // The KPI was past the threshold at the last check
+	LastValue       *float64       `json:"last_value,omitempty"`
+	LastError       string         `json:"last_error,omitempty" gorm:"type:text"`
+	LastCheckedAt   *time.Time     `json:"last_checked_at,omitempty"`
+	LastTriggeredAt *time.Time     `json:"last_triggered_at,omitempty"`
+	NextCheckAt     time.Time      `json:"next_check_at" gorm:"not null;index"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Request/Response DTOs
+
+// ChatIntegrationRequest configures a chat integration. Secrets left empty
+// on update keep their current value.
+type ChatIntegrationRequest struct {
+	Provider            IntegrationProvider `json:"provider" validate:"required,oneof=slack teams"`
+	Name                string              `json:"name" validate:"required,max=255"`
+	WebhookURL          string              `json:"webhook_url,omitempty" validate:"omitempty,url,startswith=https://"`
+	BotToken            string              `json:"bot_token,omitempty" validate:"max=255"`
+	SigningSecret       string              `json:"signing_secret,omitempty" validate:"max=255"`
+	DefaultChannel      string              `json:"default_channel,omitempty" validate:"max=255"`
+	CommandUserID       *uint               `json:"command_user_id,omitempty"`
+	CommandDataSourceID *uint               `json:"command_data_source_id,omitempty"`
+	IsActive            *bool               `json:"is_active,omitempty"` // Defaults to true
+}
+
+// ChatIntegrationResponse is a chat integration without its secrets
+type ChatIntegrationResponse struct {
+	ChatIntegration
+	HasWebhook       bool   `json:"has_webhook"`
+	HasBotToken      bool   `json:"has_bot_token"`
+	HasSigningSecret bool   `json:"has_signing_secret"`
+	CommandPath      string `json:"command_path,omitempty"` // Set when commands are enabled
+}
+
+// ChatIntegrationSummary is what users see of an integration when sharing
+type ChatIntegrationSummary struct {
+	ID             uint                `json:"id"`
+	Provider       IntegrationProvider `json:"provider"`
+	Name           string              `json:"name"`
+	DefaultChannel string              `json:"default_channel,omitempty"`
+	AnyChannel     bool                `json:"any_channel"` // Messages can go to other channels than the default
+}
+
+// ChatShareRequest posts a snapshot of a stored query result to a chat integration
+type ChatShareRequest struct {
+	QueryID  uint   `json:"query_id" validate:"required"`
+	ResultID uint   `json:"result_id,omitempty"` // Defaults to the latest result
+	Channel  string `json:"channel,omitempty" validate:"max=255"`
+	Comment  string `json:"comment,omitempty" validate:"max=1000"`
+	Rows     int    `json:"rows,omitempty" validate:"omitempty,min=1,max=25"` // Defaults to 10
+}
+
+// ChatCommandRequest is a command received from a chat platform, with the
+// headers needed to verify it
+type ChatCommandRequest struct {
+	Body          []byte
+	Timestamp     string // X-Slack-Request-Timestamp
+	Signature     string // X-Slack-Signature
+	Authorization string // Teams HMAC authorization
+}
+
+// AlertRuleRequest creates or replaces an alert rule
+type AlertRuleRequest struct {
+	Name            string         `json:"name" validate:"required,max=255"`
+	KPIID           uint           `json:"kpi_id" validate:"required"`
+	Condition       AlertCondition `json:"condition" validate:"required,oneof=above below"`
+	Threshold       float64        `json:"threshold"`
+	IntegrationID   uint           `json:"integration_id" validate:"required"`
+	Channel         string         `json:"channel,omitempty" validate:"max=255"`
+	IntervalMinutes int            `json:"interval_minutes,omitempty" validate:"omitempty,min=5,max=10080"` // Defaults to 60
+	IsActive        *bool          `json:"is_active,omitempty"`                                             // Defaults to true
+}
+
+// AlertCheckResult reports a run of the alert check job
+type AlertCheckResult struct {
+	Due       int      `json:"due"`
+	Triggered int      `json:"triggered"`
+	Resolved  int      `json:"resolved"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
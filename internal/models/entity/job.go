@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+// JobStatus represents the state of a background job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"   // Waiting for run_at, including retries
+	JobStatusRunning   JobStatus = "running"   // Claimed by a worker
+	JobStatusSucceeded JobStatus = "succeeded" // Finished without error
+	JobStatusDead      JobStatus = "dead"      // Out of attempts (dead letter), retried by an admin
+)
+
+// Job types run by the background job runner
+const (
+	JobTypeDataSourceDiscover     = "data_source.discover"    // Test a data source connection and discover its schema
+	JobTypeSchemaSync             = "schema_sync.data_source" // Sync the schema embeddings of a data source
+	JobTypeSchemaSyncAll          = "schema_sync.all"
+	JobTypeHealthCheck            = "connection_health.check_all"
+	JobTypeSchemaReembed          = "schema.reembed_columns"    // Re-embed a table and its curated columns
+	JobTypeNL2SQLEval             = "nl2sql.evaluate"           // Run the golden queries of a data source through the generator
+	JobTypeQueryRetention         = "query_retention.purge"     // Purge query results and queries older than the retention
+	JobTypeReportRunDue           = "reports.run_due"           // Render and email the reports whose schedule is due
+	JobTypeAlertCheck             = "alerts.check_due"          // Check the alert rules that are due and post to chat
+	JobTypeWebhookDeliver         = "webhooks.deliver"          // Post an event to a webhook; retried with backoff on failure
+	JobTypeFileUploadProcess      = "file_uploads.process"      // Assemble the parts of a chunked upload and infer its schema
+	JobTypeFileUploadPurge        = "file_uploads.purge"        // Delete chunked uploads that expired before completion
+	JobTypeGoogleDriveSync        = "google_drive.sync_due"     // Download Google Drive files that have a new revision
+	JobTypeMaterializationSync    = "materializations.sync_due" // Refresh the materialized copies whose interval has passed
+	JobTypeMaterializationRefresh = "materializations.refresh"  // Refresh the materialized copy of one data source
+	JobTypeExtractSync            = "extracts.sync_due"         // Refresh the query extracts whose interval has passed
+	JobTypeExtractRefresh         = "extracts.refresh"          // Refresh one query extract
+)
+
+// Job is a unit of background work. Workers claim pending jobs whose run_at
+// has passed; failed jobs are retried with backoff until max_attempts, after
+// which they are dead-lettered.
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Type        string     `json:"type" gorm:"not null;index"`
+	TenantID    uint       `json:"tenant_id,omitempty" gorm:"not null;default:0;index"` // Tenant the job runs for; 0 for jobs of the whole installation
+	Payload     JSON       `json:"payload" gorm:"type:jsonb"`
+	Key         string     `json:"key,omitempty" gorm:"not null;default:''"` // Deduplicates pending and running jobs when set
+	Status      JobStatus  `json:"status" gorm:"not null;index"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null"`
+	LockedBy    string     `json:"locked_by,omitempty" gorm:"not null;default:''"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// JobListFilter narrows the job list of the admin API
+type JobListFilter struct {
+	Status JobStatus
+	Type   string
+	Page   int
+	Limit  int
+}
+
+// DataSourceJobPayload is the payload of jobs that work on one data source
+type DataSourceJobPayload struct {
+	DataSourceID uint `json:"data_source_id"`
+}
+
+// JobStats counts the jobs in each status
+type JobStats struct {
+	Pending   int64 `json:"pending"`
+	Running   int64 `json:"running"`
+	Succeeded int64 `json:"succeeded"`
+	Dead      int64 `json:"dead"`
+}
+
+// SchemaSyncJobPayload is the payload of jobs that sync all data sources; an
+// empty trigger means a scheduled run
+type SchemaSyncJobPayload struct {
+	Trigger SchemaSyncTrigger `json:"trigger,omitempty"`
+}
+
+// SchemaReembedJobPayload is the payload of jobs that re-embed curated columns of a table
+type SchemaReembedJobPayload struct {
+	DataSourceID uint     `json:"data_source_id"`
+	Table        string   `json:"table"`
+	Columns      []string `json:"columns"`
+}
@@ -0,0 +1,41 @@
+package models
+
+// KPIComputeRequest computes a KPI over a date range. The data source, table
+// and time column default to those of the KPI definition.
+type KPIComputeRequest struct {
+	From         string `json:"from,omitempty"`                                                  // YYYY-MM-DD, defaults to 29 days before to
+	To           string `json:"to,omitempty"`                                                    // YYYY-MM-DD, inclusive, defaults to today (UTC)
+	Grain        string `json:"grain,omitempty" validate:"omitempty,oneof=daily weekly monthly"` // Defaults to the KPI grain; empty for totals only
+	Compare      bool   `json:"compare"`                                                         // Also compute the prior period of the same length
+	DataSourceID uint   `json:"data_source_id,omitempty"`
+	SourceTable  string `json:"source_table,omitempty" validate:"max=255"`
+	TimeColumn   string `json:"time_column,omitempty" validate:"max=255"`
+}
+
+// KPISeriesPoint is the value of a KPI in one period of the grain
+type KPISeriesPoint struct {
+	Period string   `json:"period"` // Start of the period
+	Value  *float64 `json:"value"`
+}
+
+// KPIPeriodValue is the value of a KPI over a date range, with its series per grain
+type KPIPeriodValue struct {
+	From   string           `json:"from"`
+	To     string           `json:"to"` // Inclusive
+	Value  *float64         `json:"value"`
+	Series []KPISeriesPoint `json:"series,omitempty"`
+}
+
+// KPIComputeResponse is a computed KPI, ready for a KPI card
+type KPIComputeResponse struct {
+	KPIID         uint            `json:"kpi_id"`
+	Name          string          `json:"name"`
+	DisplayName   string          `json:"display_name,omitempty"`
+	Unit          string          `json:"unit,omitempty"`
+	DataSourceID  uint            `json:"data_source_id"`
+	Grain         string          `json:"grain,omitempty"`
+	Current       KPIPeriodValue  `json:"current"`
+	Previous      *KPIPeriodValue `json:"previous,omitempty"`
+	Change        *float64        `json:"change,omitempty"`         // Current minus previous value
+	PercentChange *float64        `json:"percent_change,omitempty"` // Omitted when the previous value is zero or missing
+}
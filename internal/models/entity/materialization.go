@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// MaterializationStatus is the state of the last refresh of a materialized table
+type MaterializationStatus string
+
+const (
+	MaterializationStatusSyncing   MaterializationStatus = "syncing"
+	MaterializationStatusCompleted MaterializationStatus = "completed"
+	MaterializationStatusFailed    MaterializationStatus = "failed" // The previous copy is kept; see error
+)
+
+// MaterializationMode is how the last refresh of a materialized table pulled its records
+type MaterializationMode string
+
+const (
+	MaterializationModeFull        MaterializationMode = "full"        // All records were pulled and the copy replaced
+	MaterializationModeIncremental MaterializationMode = "incremental" // Records past the watermark were merged into the copy
+)
+
+// DataSourceMaterialization is a local copy of a table of a slow data source
+// (Google Sheets, REST API) that queries run on instead of the source. It is
+// refreshed on a schedule; the watermark is the highest value of the
+// incremental column seen, so the next refresh only pulls newer records.
+type DataSourceMaterialization struct {
+	ID              uint                  `json:"id" gorm:"primaryKey"`
+	TenantID        uint                  `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID    uint                  `json:"data_source_id" gorm:"not null;uniqueIndex:idx_data_source_materializations_table"`
+	Table           string                `json:"table" gorm:"column:table_name;not null;uniqueIndex:idx_data_source_materializations_table"`
+	FilePath        string                `json:"-" gorm:"not null"` // JSON lines read by the DuckDB file engine
+	Mode            MaterializationMode   `json:"mode,omitempty"`
+	WatermarkColumn string                `json:"watermark_column,omitempty"`
+	Watermark       string                `json:"watermark,omitempty"`
+	RowCount        int64                 `json:"row_count"`
+	Status          MaterializationStatus `json:"status" gorm:"not null;default:syncing"`
+	Error           string                `json:"error,omitempty" gorm:"type:text"`
+	SyncedAt        *time.Time            `json:"synced_at,omitempty"` // Last successful refresh
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// DataFreshness tells how current the data a query ran on is. It is only set
+// for data sources queried through a materialized copy, and for extracts.
+type DataFreshness struct {
+	Source         string     `json:"source"`                    // "materialized" or "extract"
+	MaterializedAt *time.Time `json:"materialized_at,omitempty"` // Oldest refresh among the tables of the data source, or of the extract
+	AgeSeconds     int64      `json:"age_seconds"`
+	Stale          bool       `json:"stale"` // The last refresh failed or is overdue
+}
+
+// MaterializationRefreshRequest asks for a refresh of the materialized copy of a data source
+type MaterializationRefreshRequest struct {
+	Full bool `json:"full,omitempty"` // Pull all records even when an incremental pull is possible
+}
+
+// DataSourceMaterializationsResponse lists the materialized tables of a data source
+type DataSourceMaterializationsResponse struct {
+	Tables    []DataSourceMaterialization `json:"tables"`
+	Freshness *DataFreshness              `json:"freshness,omitempty"`
+	JobID     uint                        `json:"job_id,omitempty"` // Set when a refresh was queued
+}
+
+// MaterializationJobPayload is the payload of jobs that refresh one data source
+type MaterializationJobPayload struct {
+	DataSourceID uint `json:"data_source_id"`
+	Full         bool `json:"full,omitempty"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+)
+
+// UserMFA is the TOTP second factor of a user. The secret is set when
+// enrollment starts; MFA is enabled once the user confirms a code from it.
+type UserMFA struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	TenantID        uint       `json:"-" gorm:"not null;default:1;index"`
+	Secret          string     `json:"-" gorm:"not null"`
+	Enabled         bool       `json:"enabled" gorm:"default:false"`
+	Required        bool       `json:"required" gorm:"default:false"` // Set by an admin; login needs MFA
+	LastUsedCounter int64      `json:"-"`                             // Time step of the last accepted code, refused from then on
+	EnabledAt       *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// MFARecoveryCode is a single-use code that replaces a TOTP code, e.g. when the
+// authenticator device is lost. Only the SHA-256 hash of the code is stored.
+type MFARecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TenantID  uint       `json:"-" gorm:"not null;default:1;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFASettings are the workspace-wide MFA enforcement flags (a single row)
+type MFASettings struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	RequireAll    bool      `json:"require_all" gorm:"default:false"`    // Every user must use MFA
+	RequireAdmins bool      `json:"require_admins" gorm:"default:false"` // Admins must use MFA
+	UpdatedBy     uint      `json:"updated_by,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// MFAStatus describes the MFA state of a user
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // By the user flag or the workspace settings
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollment is the secret to add to an authenticator app, as text and as
+// the otpauth:// URI usually shown as a QR code
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFACodeRequest carries a TOTP code, or a recovery code where accepted
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAVerifyRequest completes a login with the token returned by the login and a code
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFAEnrollmentConfirmation is returned once MFA is enabled. The recovery codes
+// are only shown once; Token is set when the enrollment completed a login.
+type MFAEnrollmentConfirmation struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token,omitempty"`
+}
+
+// MFARecoveryCodes are newly generated recovery codes, only shown once
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAUserRequirementRequest sets whether a user must use MFA
+type MFAUserRequirementRequest struct {
+	Required bool `json:"required"`
+}
+
+// MFASettingsRequest updates the workspace MFA enforcement flags
+type MFASettingsRequest struct {
+	RequireAll    bool `json:"require_all"`
+	RequireAdmins bool `json:"require_admins"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,13 +26,31 @@ const (
 	QueryTypeExplore   QueryType = "explore"
 )
 
+// Languages NL questions can be asked in. Schemas and glossary terms are in
+// English; questions in other languages are translated through glossaries.
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
 // NL2SQLQuery represents a natural language to SQL query
 type NL2SQLQuery struct {
 	ID             uint           `json:"id" gorm:"primaryKey"`
 	UserID         uint           `json:"user_id" gorm:"not null;index"`
+	TenantID       uint           `json:"-" gorm:"not null;default:1;index"`
 	DataSourceID   uint           `json:"data_source_id" gorm:"not null;index"`
 	NLQuery        string         `json:"nl_query" gorm:"type:text;not null"`
+	Language       string         `json:"language" gorm:"size:8;default:en"` // Language the question was asked in
 	GeneratedSQL   string         `json:"generated_sql" gorm:"type:text"`
+	SQLVersion     int            `json:"sql_version"` // Current entry in query_sql_versions
+	Parameters     JSON           `json:"parameters" gorm:"type:jsonb"` // {{name}} placeholders detected in GeneratedSQL
+	PromptTemplateID      *uint   `json:"prompt_template_id,omitempty"` // Nil for the built-in prompt
+	PromptTemplateVersion int     `json:"prompt_template_version"`      // 0 for the built-in prompt
+	ParentQueryID         *uint   `json:"parent_query_id,omitempty" gorm:"index"` // Query this one was derived from
+	ParentSQLVersion      int     `json:"parent_sql_version,omitempty"`           // Version of the parent's SQL it was derived from
+	SavedQueryID          *uint   `json:"saved_query_id,omitempty" gorm:"index"`  // Saved query this query is a run of
+	Federated             bool    `json:"federated" gorm:"not null;default:false"` // Joins several data sources in DuckDB
+	Federation            JSON    `json:"federation,omitempty" gorm:"type:jsonb"`  // Federation of a federated query
 	Status         QueryStatus    `json:"status" gorm:"default:pending"`
 	Type           QueryType      `json:"type" gorm:"default:analytics"`
 	Context        JSON           `json:"context" gorm:"type:jsonb"`
@@ -40,7 +59,7 @@ type NL2SQLQuery struct {
 	ExecutionTime  int64          `json:"execution_time"` // in milliseconds
 	RowsReturned   int64          `json:"rows_returned"`
 	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"index"` // Used by the incremental analytics refresh
 	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations - removed User and DataSource to avoid foreign key constraint issues
@@ -52,10 +71,16 @@ type NL2SQLQuery struct {
 // QueryResult represents the result of a query execution
 type QueryResult struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  uint           `json:"-" gorm:"not null;default:1;index"`
 	QueryID   uint           `json:"query_id" gorm:"not null;index"`
 	Columns   JSON           `json:"columns" gorm:"type:jsonb"` // Column definitions
 	Data      JSON           `json:"data" gorm:"type:jsonb"` // Query result data
 	RowCount  int64          `json:"row_count"`
+	SQLVersion int           `json:"sql_version"` // Version of the query SQL that produced this result
+	ChunkCount int           `json:"chunk_count"` // Rows are stored in query_result_chunks when > 0, otherwise in Data
+	ArchivedAt *time.Time    `json:"archived_at,omitempty" gorm:"index"` // Rows moved to cold storage; rehydrate to read them
+	ArchiveKey string        `json:"-"`                                  // Object storage key of the archived rows
+	ArchiveBytes int64       `json:"archive_bytes,omitempty"`            // Compressed size of the archive
 	CreatedAt time.Time      `json:"created_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
@@ -63,8 +88,22 @@ type QueryResult struct {
 	Query NL2SQLQuery `json:"query" gorm:"foreignKey:QueryID"`
 }
 
+// QueryResultChunk holds a consecutive slice of the rows of a QueryResult, so
+// large results are written as they stream in and read back one page at a time
+type QueryResultChunk struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TenantID   uint      `json:"-" gorm:"not null;default:1;index"`
+	ResultID   uint      `json:"result_id" gorm:"not null;uniqueIndex:idx_query_result_chunks_result_chunk"`
+	ChunkIndex int       `json:"chunk_index" gorm:"not null;uniqueIndex:idx_query_result_chunks_result_chunk"`
+	RowOffset  int64     `json:"row_offset" gorm:"not null"` // Index of the first row of the chunk in the result
+	RowCount   int       `json:"row_count" gorm:"not null"`
+	Data       JSON      `json:"data" gorm:"type:jsonb"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // SQLValidationResult represents the result of SQL validation
 type SQLValidationResult struct {
+	Dialect      SQLDialect `json:"dialect"`
 	IsValid      bool     `json:"is_valid"`
 	IsReadOnly   bool     `json:"is_read_only"`
 	HasLimit     bool     `json:"has_limit"`
@@ -74,6 +113,12 @@ type SQLValidationResult struct {
 	Warnings     []string `json:"warnings"`
 }
 
+// QueryParameter is a {{name}} placeholder in the SQL of a query. Values are
+// bound as validated literals each time the query is executed.
+type QueryParameter struct {
+	Name string `json:"name"`
+}
+
 // QueryContext represents the context for NL2SQL generation
 type QueryContext struct {
 	AllowedTables []string               `json:"allowed_tables"`
@@ -93,10 +138,19 @@ type TimeRange struct {
 
 // NL2SQLRequest represents a request to convert natural language to SQL
 type NL2SQLRequest struct {
-	NLQuery      string                 `json:"nl_query" validate:"required,min=1,max=1000"`
-	DataSourceID uint                   `json:"data_source_id" validate:"required"`
-	Context      map[string]interface{} `json:"context,omitempty"`
-	Type         QueryType              `json:"type,omitempty"`
+	NLQuery         string                 `json:"nl_query" validate:"required,min=1,max=1000"`
+	DataSourceID    uint                   `json:"data_source_id" validate:"required"`
+	Context         map[string]interface{} `json:"context,omitempty"`
+	Type            QueryType              `json:"type,omitempty"`
+	Rerank          bool                   `json:"rerank,omitempty"`           // Rerank retrieved context before prompting
+	RerankThreshold float64                `json:"rerank_threshold,omitempty"` // Minimum relevance to keep (0 uses default)
+	ParentQueryID   *uint                  `json:"parent_query_id,omitempty"`  // Earlier query of the user this question follows up on
+	DryRun          bool                   `json:"dry_run,omitempty"`          // Generate and validate without saving the query
+	Language        string                 `json:"language,omitempty" validate:"omitempty,oneof=en id"` // Detected from the question when empty
+	// Other data sources the question spans; the query is then federated: the
+	// tables it reads are pulled into DuckDB and joined there. An entry for
+	// data_source_id only sets its row limit.
+	FederatedSources []FederatedSourceRequest `json:"federated_sources,omitempty" validate:"omitempty,max=5,dive"`
 }
 
 // NL2SQLResponse represents the response from NL2SQL conversion
@@ -105,15 +159,51 @@ type NL2SQLResponse struct {
 	GeneratedSQL  string               `json:"generated_sql"`
 	Validation    SQLValidationResult  `json:"validation"`
 	EstimatedCost float64              `json:"estimated_cost"`
+	CostEstimate  *QueryCostEstimate   `json:"cost_estimate,omitempty"` // Engine estimate (EXPLAIN / dry run) when available
 	SafetyScore   float64              `json:"safety_score"`
 	Messages      []string             `json:"messages"`
 	CanExecute    bool                 `json:"can_execute"`
+	Parameters    []QueryParameter     `json:"parameters,omitempty"` // Placeholders to supply on execution
+	DryRun        bool                 `json:"dry_run,omitempty"`    // Nothing was saved; QueryID is 0
+	Semantic      *SemanticResolution  `json:"semantic,omitempty"`   // Metrics and dimensions of the semantic model used
+	Language      string               `json:"language"`             // Language of the question; messages are in it
+	Federation    *Federation          `json:"federation,omitempty"` // Set for federated queries
+}
+
+// NL2SQLAnswerRequest asks a question that should be answered with a single
+// value instead of a table
+type NL2SQLAnswerRequest struct {
+	NLQuery      string `json:"nl_query" validate:"required,min=1,max=1000"`
+	DataSourceID uint   `json:"data_source_id" validate:"required"`
+}
+
+// NL2SQLAnswerResponse is a direct answer to a question. When the result is not
+// a single value Answered is false and the full result has to be used instead.
+type NL2SQLAnswerResponse struct {
+	QueryID      uint        `json:"query_id"`
+	ResultID     uint        `json:"result_id,omitempty"`
+	Answered     bool        `json:"answered"`
+	Answer       string      `json:"answer,omitempty"` // One sentence, e.g. "Revenue is 12,500.00 USD."
+	Value        interface{} `json:"value,omitempty"`
+	Unit         string      `json:"unit,omitempty"` // Unit of the matched KPI definition
+	KPI          string      `json:"kpi,omitempty"`  // Name of the matched KPI definition
+	GeneratedSQL string      `json:"generated_sql"`
+	ResultURL    string      `json:"result_url,omitempty"` // Full stored result
+	Message      string      `json:"message,omitempty"`
 }
 
 // QueryExecutionRequest represents a request to execute a query
 type QueryExecutionRequest struct {
-	QueryID uint `json:"query_id" validate:"required"`
-	Limit   int  `json:"limit,omitempty" validate:"min=1,max=10000"`
+	QueryID   uint `json:"query_id" validate:"required"`
+	Limit     int  `json:"limit,omitempty" validate:"min=1,max=10000"`
+	Anonymize bool `json:"anonymize,omitempty"` // Pseudonymize strings and jitter numbers in returned data
+	PageSize  int  `json:"page_size,omitempty" validate:"omitempty,min=1,max=1000"` // Return only the first page; fetch the rest with next_cursor
+	// Values for the query placeholders: strings, numbers, booleans, null,
+	// YYYY-MM-DD dates, RFC 3339 timestamps, or arrays of these for IN lists
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	UnmaskPII  bool                   `json:"-"` // Set for callers with the PII unmask permission
+	Currency   string                 `json:"currency,omitempty" validate:"omitempty,len=3,alpha"` // Convert currency columns to this ISO 4217 code
+	IncludeInsights bool              `json:"include_insights,omitempty"` // Summarize the result in natural language
 }
 
 // QueryExecutionResponse represents the response from query execution
@@ -125,6 +215,94 @@ type QueryExecutionResponse struct {
 	ExecutionTime int64                    `json:"execution_time"`
 	Status        QueryStatus              `json:"status"`
 	Message       string                   `json:"message,omitempty"`
+	Anonymized    bool                     `json:"anonymized,omitempty"`
+	MaskedColumns []string                 `json:"masked_columns,omitempty"` // PII columns whose values were masked
+	CostEstimate  *QueryCostEstimate       `json:"cost_estimate,omitempty"`
+	ExecutedSQL   string                   `json:"executed_sql,omitempty"` // SQL with parameters bound
+	ResultID      uint                     `json:"result_id,omitempty"`    // Stored result to page through
+	NextCursor    string                   `json:"next_cursor,omitempty"`  // Set when Data holds only the first page
+	Insights      *QueryInsights           `json:"insights,omitempty"`     // Set when insights were asked for
+	Freshness     *DataFreshness           `json:"freshness,omitempty"`    // Set when the query ran on a materialized copy
+	Federation    *Federation              `json:"federation,omitempty"`   // Set for federated queries, with the rows pulled per table
+}
+
+// NL2SQLStreamRequest converts a question and, when the SQL is safe, executes
+// it while reporting progress as Server-Sent Events
+type NL2SQLStreamRequest struct {
+	NL2SQLRequest
+	Limit     int  `json:"limit,omitempty"`
+	Anonymize bool `json:"anonymize,omitempty"` // Pseudonymize strings and jitter numbers in streamed rows
+	UnmaskPII bool `json:"-"`                   // Set for callers with the PII unmask permission
+}
+
+// NL2SQLProgressStage is a step of NL2SQL conversion and execution
+type NL2SQLProgressStage string
+
+const (
+	NL2SQLStageContextBuilt NL2SQLProgressStage = "context_built"
+	NL2SQLStageSQLGenerated NL2SQLProgressStage = "sql_generated"
+	NL2SQLStageValidated    NL2SQLProgressStage = "validated"
+	NL2SQLStageExecuting    NL2SQLProgressStage = "executing"
+	NL2SQLStageRowsStreamed NL2SQLProgressStage = "rows_streamed"
+	NL2SQLStageDone         NL2SQLProgressStage = "done"
+	NL2SQLStageError        NL2SQLProgressStage = "error"
+)
+
+// NL2SQLProgressEvent reports one stage. Rows arrive in batches of
+// rows_streamed events; done carries the conversion and execution summaries
+// without the rows already sent.
+type NL2SQLProgressEvent struct {
+	Stage        NL2SQLProgressStage      `json:"stage"`
+	QueryID      uint                     `json:"query_id,omitempty"`
+	Message      string                   `json:"message,omitempty"`
+	GeneratedSQL string                   `json:"generated_sql,omitempty"`
+	Validation   *SQLValidationResult     `json:"validation,omitempty"`
+	CanExecute   *bool                    `json:"can_execute,omitempty"`
+	Columns      []Column                 `json:"columns,omitempty"`
+	Rows         []map[string]interface{} `json:"rows,omitempty"`
+	RowOffset    int64                    `json:"row_offset,omitempty"` // Index of the first row of the batch
+	Conversion   *NL2SQLResponse          `json:"conversion,omitempty"`
+	Execution    *QueryExecutionResponse  `json:"execution,omitempty"`
+	Timestamp    time.Time                `json:"timestamp"`
+}
+
+// QueryResultArchiveRun reports a run of the query result archiving job
+type QueryResultArchiveRun struct {
+	Cutoff   time.Time `json:"cutoff"` // Results created before this were eligible
+	Archived int       `json:"archived"`
+	Rows     int64     `json:"rows"`
+	Bytes    int64     `json:"bytes"` // Compressed size written to object storage
+	Failed   int       `json:"failed"`
+	Errors   []string  `json:"errors,omitempty"`
+}
+
+// QueryResultPageRequest selects a page of a stored result, either by page
+// number or by the cursor returned with the previous page
+type QueryResultPageRequest struct {
+	ResultID  uint   `query:"result_id"` // Defaults to the latest result of the query
+	Page      int    `query:"page"`      // 1-based
+	Limit     int    `query:"limit"`
+	Cursor    string `query:"cursor"`
+	Anonymize bool   `query:"anonymize"` // Pseudonymize strings and jitter numbers in returned data
+	UnmaskPII bool   `query:"-"`         // Set for callers with the PII unmask permission
+}
+
+// QueryResultPage is one page of the rows of a stored query result
+
+type QueryResultPage struct {
+	QueryID       uint                     `json:"query_id"`
+	ResultID      uint                     `json:"result_id"`
+	Columns       []Column                 `json:"columns"`
+	Data          []map[string]interface{} `json:"data"`
+	TotalRows     int64                    `json:"total_rows"`
+	Offset        int64                    `json:"offset"`
+	Limit         int                      `json:"limit"`
+	Page          int                      `json:"page"`
+	HasMore       bool                     `json:"has_more"`
+	NextCursor    string                   `json:"next_cursor,omitempty"`
+	Anonymized    bool                     `json:"anonymized,omitempty"`
+	MaskedColumns []string                 `json:"masked_columns,omitempty"` // PII columns whose values were masked
+	CreatedAt     time.Time                `json:"created_at"`
 }
 
 // QueryHistoryResponse represents a query in the history
@@ -140,6 +318,8 @@ type QueryHistoryResponse struct {
 	RowsReturned  int64       `json:"rows_returned"`
 	CreatedAt     time.Time   `json:"created_at"`
 	ErrorMsg      string      `json:"error_message,omitempty"`
+	ParentQueryID *uint       `json:"parent_query_id,omitempty"`
+	Federated     bool        `json:"federated,omitempty"`
 }
 
 // Methods
@@ -158,7 +338,18 @@ func (q *NL2SQLQuery) ToHistoryResponse() *QueryHistoryResponse {
 		RowsReturned:   q.RowsReturned,
 		CreatedAt:      q.CreatedAt,
 		ErrorMsg:       q.ErrorMsg,
+		ParentQueryID:  q.ParentQueryID,
+		Federated:      q.Federated,
+	}
+}
+
+// GetParameters decodes the detected placeholders
+func (q *NL2SQLQuery) GetParameters() []QueryParameter {
+	var params []QueryParameter
+	if len(q.Parameters) > 0 {
+		json.Unmarshal(q.Parameters, &params)
 	}
+	return params
 }
 
 // IsExecutable checks if the query can be executed
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+)
+
+// EvalRunStatus represents the state of an NL2SQL evaluation run
+type EvalRunStatus string
+
+const (
+	EvalRunStatusPending   EvalRunStatus = "pending"
+	EvalRunStatusRunning   EvalRunStatus = "running"
+	EvalRunStatusCompleted EvalRunStatus = "completed"
+	EvalRunStatusFailed    EvalRunStatus = "failed"
+)
+
+// EvalOutcome is the result of evaluating one golden query
+type EvalOutcome string
+
+const (
+	EvalOutcomeMatch            EvalOutcome = "match"             // Generated query returns the expected rows
+	EvalOutcomeMismatch         EvalOutcome = "mismatch"          // Generated query returns other rows
+	EvalOutcomeGenerationFailed EvalOutcome = "generation_failed" // No query generated, or it failed validation
+	EvalOutcomeExecutionFailed  EvalOutcome = "execution_failed"  // Generated query failed to run
+	EvalOutcomeGoldenFailed     EvalOutcome = "golden_failed"     // Expected SQL failed to run; not counted in accuracy
+)
+
+// GoldenQuery is a natural language question with the SQL known to answer it
+// on a data source, used to measure NL2SQL accuracy
+type GoldenQuery struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;index"`
+	NLQuery      string    `json:"nl_query" gorm:"type:text;not null"`
+	ExpectedSQL  string    `json:"expected_sql" gorm:"type:text;not null"`
+	Notes        string    `json:"notes,omitempty" gorm:"type:text"`
+	IsActive     bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// EvalRun runs the NL2SQL generator over the active golden queries of a data
+// source and keeps the accuracy metrics, so runs can be compared over time
+type EvalRun struct {
+	ID                 uint          `json:"id" gorm:"primaryKey"`
+	TenantID           uint          `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID       uint          `json:"data_source_id" gorm:"not null;index"`
+	Label              string        `json:"label,omitempty"` // What changed, e.g. the prompt version
+	Model              string        `json:"model,omitempty"` // LLM model configured when the run started
+	Status             EvalRunStatus `json:"status" gorm:"not null"`
+	Total              int           `json:"total"`
+	Evaluated          int           `json:"evaluated"` // Total without golden queries that failed to run
+	Matches            int           `json:"matches"`
+	ExactMatches       int           `json:"exact_matches"` // Generated SQL equal to the expected SQL
+	GenerationFailures int           `json:"generation_failures"`
+	ExecutionFailures  int           `json:"execution_failures"`
+	GoldenFailures     int           `json:"golden_failures"`
+	Accuracy           float64       `json:"accuracy"`         // Matches / evaluated, in percent
+	ExactMatchRate     float64       `json:"exact_match_rate"` // Exact matches / evaluated, in percent
+	AvgGenerationMs    int64         `json:"avg_generation_ms"`
+	ErrorMsg           string        `json:"error_msg,omitempty" gorm:"type:text"`
+	TriggeredBy        uint          `json:"triggered_by"`
+	StartedAt          *time.Time    `json:"started_at,omitempty"`
+	CompletedAt        *time.Time    `json:"completed_at,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+// EvalResult is the evaluation of one golden query in a run
+type EvalResult struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	TenantID      uint        `json:"-" gorm:"not null;default:1;index"`
+	RunID         uint        `json:"run_id" gorm:"not null;index"`
+	GoldenQueryID uint        `json:"golden_query_id" gorm:"not null"`
+	NLQuery       string      `json:"nl_query" gorm:"type:text"`
+	ExpectedSQL   string      `json:"expected_sql" gorm:"type:text"`
+	GeneratedSQL  string      `json:"generated_sql,omitempty" gorm:"type:text"`
+	Outcome       EvalOutcome `json:"outcome" gorm:"not null"`
+	SQLMatch      bool        `json:"sql_match"`
+	ExpectedRows  int         `json:"expected_rows"`
+	GeneratedRows int         `json:"generated_rows"`
+	GenerationMs  int64       `json:"generation_ms"`
+	Error         string      `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// Request/Response DTOs
+
+// GoldenQueryRequest creates or replaces a golden query
+type GoldenQueryRequest struct {
+	DataSourceID uint   `json:"data_source_id" validate:"required"`
+	NLQuery      string `json:"nl_query" validate:"required,max=1000"`
+	ExpectedSQL  string `json:"expected_sql" validate:"required"`
+	Notes        string `json:"notes,omitempty" validate:"max=1000"`
+	IsActive     *bool  `json:"is_active,omitempty"` // Defaults to true
+}
+
+// EvalRunRequest starts an evaluation run
+type EvalRunRequest struct {
+	DataSourceID uint   `json:"data_source_id" validate:"required"`
+	Label        string `json:"label,omitempty" validate:"max=255"`
+}
+
+// EvalRunDetailResponse is an evaluation run with the result of each golden query
+type EvalRunDetailResponse struct {
+	EvalRun
+	Results []EvalResult `json:"results"`
+}
+
+// EvalRunJobPayload is the payload of jobs that run an evaluation
+type EvalRunJobPayload struct {
+	RunID uint `json:"run_id"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// LLMSettings are the model and sampling settings SQL was generated with
+type LLMSettings struct {
+	Model       string  `json:"model"`
+	Temperature float64 `json:"temperature"`
+}
+
+// NL2SQLQueryTrace shows what went into generating the SQL of a query: the
+// retrieved elements with their scores, the prompt sent to the model and how
+// the SQL validated. Secrets in it are redacted.
+type NL2SQLQueryTrace struct {
+	QueryID      uint        `json:"query_id"`
+	UserID       uint        `json:"user_id"`
+	DataSourceID uint        `json:"data_source_id"`
+	NLQuery      string      `json:"nl_query"`
+	Language     string      `json:"language"`
+	Status       QueryStatus `json:"status"`
+	CreatedAt    time.Time   `json:"created_at"`
+
+	Retrieval *RetrievalConfig  `json:"retrieval,omitempty"` // Config the elements were retrieved with
+	Retrieved *RetrievedContext `json:"retrieved,omitempty"` // Elements that made it into the prompt
+	Reranked  bool              `json:"reranked"`
+
+	Prompt                string        `json:"prompt"`
+	PromptTokens          int           `json:"prompt_tokens"`
+	PromptBudget          *PromptBudget `json:"prompt_budget,omitempty"`
+	PromptTemplateID      *uint         `json:"prompt_template_id,omitempty"` // Nil for the built-in prompt
+	PromptTemplateVersion int           `json:"prompt_template_version"`
+	LLM                   *LLMSettings  `json:"llm,omitempty"`
+
+	GeneratedSQL string               `json:"generated_sql"`
+	SQLVersion   int                  `json:"sql_version"`
+	Validation   *SQLValidationResult `json:"validation,omitempty"` // Of the current SQL
+
+	// Recorded is false for queries converted before traces were kept; they
+	// only have the query and its validation
+	Recorded bool `json:"recorded"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationType is the kind of event pushed to the notification channel of a user
+type NotificationType string
+
+const (
+	NotificationDiscoveryFinished NotificationType = "datasource.discovery_finished" // Schema discovery of a data source succeeded or failed
+	NotificationReportCompleted   NotificationType = "report.completed"              // A scheduled report ran
+	NotificationAlertTriggered    NotificationType = "alert.triggered"               // An alert rule crossed its threshold
+)
+
+// Notification is an event pushed to the connected clients of a user
+type Notification struct {
+	Type      NotificationType `json:"type"`
+	UserID    uint             `json:"user_id"`
+	Data      json.RawMessage  `json:"data,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// DiscoveryFinishedNotification is the data of datasource.discovery_finished notifications
+type DiscoveryFinishedNotification struct {
+	DataSourceID uint             `json:"data_source_id"`
+	Name         string           `json:"name"`
+	Status       ConnectionStatus `json:"status"` // active, or error when the discovery failed
+	Message      string           `json:"message"`
+}
+
+// ReportCompletedNotification is the data of report.completed notifications
+type ReportCompletedNotification struct {
+	ReportID uint            `json:"report_id"`
+	RunID    uint            `json:"run_id"`
+	Name     string          `json:"name"`
+	Status   ReportRunStatus `json:"status"`
+	Error    string          `json:"error,omitempty"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// Variables a prompt template can use. Section variables render with their
+// heading, or as nothing when the section is empty.
+const (
+	PromptVariableDialect         = "dialect"          // Display name of the SQL dialect
+	PromptVariableDialectGuidance = "dialect_guidance" // Dialect-specific syntax notes
+	PromptVariableSchema          = "schema"           // Section: relevant tables and columns
+	PromptVariableKPIs            = "kpis"             // Section: relevant KPIs
+	PromptVariableGlossary        = "glossary"         // Section: relevant business terms
+	PromptVariableJoinPaths       = "join_paths"       // Section: join paths from foreign keys
+	PromptVariableCustomFunctions = "custom_functions" // Section: custom SQL functions of the data source
+	PromptVariableQuery           = "query"            // The natural language question
+	PromptVariableInstructions    = "instructions"     // Numbered output instructions for the dialect
+)
+
+// PromptTemplateVariables lists the variables a prompt template can use
+var PromptTemplateVariables = []string{
+	PromptVariableDialect,
+	PromptVariableDialectGuidance,
+	PromptVariableSchema,
+	PromptVariableKPIs,
+	PromptVariableGlossary,
+	PromptVariableJoinPaths,
+	PromptVariableCustomFunctions,
+	PromptVariableQuery,
+	PromptVariableInstructions,
+}
+
+// BuiltinPromptTemplate is the NL2SQL prompt used when no template is active
+const BuiltinPromptTemplate = "You are an expert SQL generator. Convert natural language queries to SQL using the provided schema context.\n\n" +
+	"SQL DIALECT: {{dialect}}\n{{dialect_guidance}}\n\n" +
+	"{{schema}}{{kpis}}{{glossary}}{{join_paths}}{{custom_functions}}" +
+	"\nQUERY: {{query}}\n\n" +
+	"INSTRUCTIONS:\n{{instructions}}"
+
+// PromptTemplate is a version of the NL2SQL prompt. Saving a template adds a
+// version to its scope and activates it; the active version of a data source
+// overrides the active workspace default.
+type PromptTemplate struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID *uint     `json:"data_source_id,omitempty" gorm:"index"` // Nil for the workspace default
+	Version      int       `json:"version" gorm:"not null"`
+	Template     string    `json:"template" gorm:"type:text;not null"`
+	Comment      string    `json:"comment,omitempty"`
+	IsActive     bool      `json:"is_active" gorm:"not null;default:false"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Request/Response DTOs
+
+// PromptTemplateRequest saves a new version of the workspace default template,
+// or of the override of a data source
+type PromptTemplateRequest struct {
+	DataSourceID *uint  `json:"data_source_id,omitempty"` // Omit for the workspace default
+	Template     string `json:"template" validate:"required,max=20000"`
+	Comment      string `json:"comment,omitempty" validate:"max=500"`
+}
+
+// PromptTemplateListResponse lists the active templates with the built-in
+// template and the variables templates can use
+type PromptTemplateListResponse struct {
+	Active    []PromptTemplate `json:"active"` // Workspace default first
+	Builtin   string           `json:"builtin"`
+	Variables []string         `json:"variables"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+)
+
+// QueryCollaborationLink lets a teammate review a query before it is executed.
+// Only the SHA-256 hash of the token is stored; the plaintext is returned once on creation.
+type QueryCollaborationLink struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TenantID    uint       `json:"-" gorm:"not null;default:1;index"`
+	QueryID     uint       `json:"query_id" gorm:"not null;index"`
+	CreatedBy   uint       `json:"created_by" gorm:"not null"`
+	TokenPrefix string     `json:"token_prefix" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// QuerySuggestionStatus is the review state of a suggested SQL edit
+type QuerySuggestionStatus string
+
+const (
+	QuerySuggestionPending  QuerySuggestionStatus = "pending"
+	QuerySuggestionAccepted QuerySuggestionStatus = "accepted"
+	QuerySuggestionRejected QuerySuggestionStatus = "rejected"
+)
+
+// QuerySQLSuggestion is an edit of a query's SQL proposed through a
+// collaboration link. The owner accepts it as a new SQL version or rejects it.
+type QuerySQLSuggestion struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	TenantID       uint                  `json:"-" gorm:"not null;default:1;index"`
+	QueryID        uint                  `json:"query_id" gorm:"not null;index"`
+	LinkID         uint                  `json:"link_id" gorm:"not null"`
+	SuggestedBy    uint                  `json:"suggested_by" gorm:"not null"`
+	BaseVersion    int                   `json:"base_version"` // SQL version the suggestion was made on
+	SQL            string                `json:"sql" gorm:"column:sql;type:text;not null"`
+	Comment        string                `json:"comment,omitempty"`
+	Validation     JSON                  `json:"validation" gorm:"type:jsonb"` // SQLValidationResult of the suggested SQL
+	Status         QuerySuggestionStatus `json:"status" gorm:"not null;default:pending"`
+	ReviewedBy     uint                  `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time            `json:"reviewed_at,omitempty"`
+	AppliedVersion int                   `json:"applied_version,omitempty"` // SQL version created on accept
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+// QueryCollaborationAction is an action recorded for a collaboration link
+type QueryCollaborationAction string
+
+const (
+	QueryCollaborationLinkCreated        QueryCollaborationAction = "link_created"
+	QueryCollaborationLinkRevoked        QueryCollaborationAction = "link_revoked"
+	QueryCollaborationViewed             QueryCollaborationAction = "viewed"
+	QueryCollaborationSuggested          QueryCollaborationAction = "suggested"
+	QueryCollaborationSuggestionAccepted QueryCollaborationAction = "suggestion_accepted"
+	QueryCollaborationSuggestionRejected QueryCollaborationAction = "suggestion_rejected"
+)
+
+// QueryCollaborationEvent records who did what on a query through collaboration links
+type QueryCollaborationEvent struct {
+	ID           uint                     `json:"id" gorm:"primaryKey"`
+	QueryID      uint                     `json:"query_id" gorm:"not null;index"`
+	LinkID       uint                     `json:"link_id"`
+	SuggestionID uint                     `json:"suggestion_id,omitempty"`
+	UserID       uint                     `json:"user_id" gorm:"not null"`
+	TenantID     uint                     `json:"-" gorm:"not null;default:1;index"`
+	Action       QueryCollaborationAction `json:"action" gorm:"not null"`
+	CreatedAt    time.Time                `json:"created_at"`
+}
+
+// Request/Response DTOs
+
+// QueryCollaborationLinkRequest creates a collaboration link
+type QueryCollaborationLinkRequest struct {
+	TTLMinutes int `json:"ttl_minutes" validate:"omitempty,min=5,max=1440"` // Default 60
+}
+
+// QueryCollaborationLinkResponse is returned once when a link is created
+type QueryCollaborationLinkResponse struct {
+	QueryCollaborationLink
+	Token string `json:"token"`
+}
+
+// QuerySQLSuggestionRequest proposes new SQL for the query of a collaboration link
+type QuerySQLSuggestionRequest struct {
+	SQL     string `json:"sql" validate:"required"`
+	Comment string `json:"comment,omitempty" validate:"max=500"`
+}
+
+// QuerySuggestionReviewRequest accepts a suggestion
+type QuerySuggestionReviewRequest struct {
+	Comment string `json:"comment,omitempty" validate:"max=500"` // Version comment, defaults to the suggestion comment
+	Confirm bool   `json:"confirm,omitempty"`                    // Apply even when the canary run diverges
+}
+
+// QueryCollaborationView is what a teammate sees through a collaboration link
+type QueryCollaborationView struct {
+	QueryID      uint                 `json:"query_id"`
+	DataSourceID uint                 `json:"data_source_id"`
+	NLQuery      string               `json:"nl_query"`
+	SQL          string               `json:"sql"`
+	SQLVersion   int                  `json:"sql_version"`
+	Status       QueryStatus          `json:"status"`
+	Validation   *SQLValidationResult `json:"validation,omitempty"`
+	CanExecute   bool                 `json:"can_execute"`
+	ExpiresAt    time.Time            `json:"expires_at"`
+	Suggestions  []QuerySQLSuggestion `json:"suggestions"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// QueryCostSource tells where a cost estimate came from
+type QueryCostSource string
+
+const (
+	QueryCostSourceExplain   QueryCostSource = "explain"   // PostgreSQL EXPLAIN
+	QueryCostSourceDryRun    QueryCostSource = "dry_run"   // BigQuery dry-run job
+	QueryCostSourceHeuristic QueryCostSource = "heuristic" // Static SQL heuristic, used when the engine cannot be asked
+)
+
+// QueryCostEstimate is the engine estimate of a query before it is executed
+type QueryCostEstimate struct {
+	Source         QueryCostSource `json:"source"`
+	EstimatedRows  int64           `json:"estimated_rows"`
+	BytesScanned   int64           `json:"bytes_scanned"`
+	EstimatedCost  float64         `json:"estimated_cost"`             // Planner cost units (PostgreSQL) or heuristic score
+	CostUSD        float64         `json:"cost_usd,omitempty"`         // On-demand price of the bytes a BigQuery dry run reports
+	MaxBytesBilled int64           `json:"max_bytes_billed,omitempty"` // Limit the BigQuery job runs with; it fails rather than bill more
+	ExceedsCeiling bool            `json:"exceeds_ceiling"`
+	Violations     []string        `json:"violations,omitempty"`
+	Message        string          `json:"message,omitempty"` // Why the heuristic was used, if it was
+}
+
+// QueryCostCeiling limits the estimated cost of queries a user may run, or
+// that may run against a data source. Exactly one of UserID and DataSourceID
+// is set. Zero limits are not enforced.
+type QueryCostCeiling struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	TenantID        uint      `json:"-" gorm:"not null;default:1;index"`
+	UserID          *uint     `json:"user_id,omitempty" gorm:"uniqueIndex"`
+	DataSourceID    *uint     `json:"data_source_id,omitempty" gorm:"uniqueIndex"`
+	MaxCost         float64   `json:"max_cost"`
+	MaxBytesScanned int64     `json:"max_bytes_scanned"`
+	MaxRows         int64     `json:"max_rows"`
+	UpdatedBy       uint      `json:"updated_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// QueryCostCeilingRequest sets a cost ceiling; zero disables a limit
+type QueryCostCeilingRequest struct {
+	MaxCost         float64 `json:"max_cost" validate:"min=0"`
+	MaxBytesScanned int64   `json:"max_bytes_scanned" validate:"min=0"`
+	MaxRows         int64   `json:"max_rows" validate:"min=0"`
+}
+
+// EffectiveQueryCostCeiling is the strictest combination of the user and
+// data source ceilings
+type EffectiveQueryCostCeiling struct {
+	MaxCost         float64           `json:"max_cost"`
+	MaxBytesScanned int64             `json:"max_bytes_scanned"`
+	MaxRows         int64             `json:"max_rows"`
+	MaxBytesBilled  int64             `json:"max_bytes_billed,omitempty"` // Platform limit on the bytes a BigQuery query may process
+	UserCeiling     *QueryCostCeiling `json:"user_ceiling,omitempty"`
+	SourceCeiling   *QueryCostCeiling `json:"data_source_ceiling,omitempty"`
+}
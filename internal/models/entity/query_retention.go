@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Request/Response DTOs
+
+// QueryHistoryDeleteRequest selects the queries deleted in bulk. At least one
+// of the data source and the date range is required.
+type QueryHistoryDeleteRequest struct {
+	UserID       uint   `query:"user_id"` // Admin only; 0 for every user
+	DataSourceID uint   `query:"data_source_id"`
+	From         string `query:"from"` // Created at or after; YYYY-MM-DD or RFC 3339
+	To           string `query:"to"`   // Created before; YYYY-MM-DD or RFC 3339
+}
+
+// QueryRetentionRun reports what a retention purge or a bulk delete removed
+type QueryRetentionRun struct {
+	ResultCutoff    *time.Time `json:"result_cutoff,omitempty"` // Results created before this were purged
+	QueryCutoff     *time.Time `json:"query_cutoff,omitempty"`  // Queries created before this were purged
+	QueriesDeleted  int64      `json:"queries_deleted"`
+	ResultsDeleted  int64      `json:"results_deleted"`
+	ArchivesDeleted int        `json:"archives_deleted"` // Archived result rows removed from object storage
+	Errors          []string   `json:"errors,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SimilarQuestion is a past question of the user close to a new one, with
+// the SQL it was converted to
+type SimilarQuestion struct {
+	QueryID      uint        `json:"query_id"`
+	DataSourceID uint        `json:"data_source_id"`
+	Question     string      `json:"question"`
+	Score        float64     `json:"score"` // Cosine similarity of the question embeddings
+	GeneratedSQL string      `json:"generated_sql"`
+	Status       QueryStatus `json:"status"`
+	AskedAt      time.Time   `json:"asked_at"`
+}
+
+// SimilarQuestionsRequest asks for the past questions of the user similar to Query
+type SimilarQuestionsRequest struct {
+	Query        string  `query:"query" validate:"required,max=1000"`
+	DataSourceID uint    `query:"data_source_id" validate:"required"`
+	Limit        int     `query:"limit" validate:"min=0,max=20"`    // Default 5
+	MinScore     float64 `query:"min_score" validate:"min=0,max=1"` // Default 0.75
+}
+
+// QuestionTopicsRequest filters and tunes the clustering of question topics
+type QuestionTopicsRequest struct {
+	DataSourceID uint    `query:"data_source_id"`                   // 0 for every data source
+	Days         int     `query:"days" validate:"min=0,max=365"`    // Questions of the last days; default 30
+	Threshold    float64 `query:"threshold" validate:"min=0,max=1"` // Similarity joining a question to a topic; default 0.85
+	MinQuestions int     `query:"min_questions" validate:"min=0"`   // Smallest topic reported; default 2
+	Limit        int     `query:"limit" validate:"min=0,max=100"`   // Topics reported; default 20
+}
+
+// QuestionTopic is a cluster of similar questions
+type QuestionTopic struct {
+	Label       string    `json:"label"` // The question closest to the centre of the topic
+	Questions   int       `json:"questions"`
+	Users       int       `json:"users"`
+	Samples     []string  `json:"samples"` // Distinct questions of the topic, most recent first
+	LastAskedAt time.Time `json:"last_asked_at"`
+}
+
+// QuestionTopicsResponse lists the most frequent question topics
+type QuestionTopicsResponse struct {
+	Topics            []QuestionTopic `json:"topics"`
+	QuestionsAnalyzed int             `json:"questions_analyzed"`
+	Since             time.Time       `json:"since"`
+	Threshold         float64         `json:"threshold"`
+}
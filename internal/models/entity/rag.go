@@ -12,13 +12,16 @@ type SchemaEmbedding struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	DataSourceID uint           `json:"data_source_id" gorm:"not null;index"`
 	SchemaID     uint           `json:"schema_id" gorm:"not null;index"`
+	UserID       uint           `json:"user_id" gorm:"not null;index"` // Owner: the user of a KPI or glossary term, or the owner of the data source of a table or column; searches only return the requesting user's
+	TenantID     uint           `json:"-" gorm:"not null;default:1;index"`
 	ElementType  string         `json:"element_type" gorm:"not null"` // table, column, kpi, glossary
 	ElementName  string         `json:"element_name" gorm:"not null"`
 	Content      string         `json:"content" gorm:"type:text"` // The text content that was embedded
 	Embedding    []float32 `json:"-" gorm:"type:vector(1536)"` // OpenAI ada-002 embedding size
 	Metadata     JSON           `json:"metadata" gorm:"type:jsonb"` // Additional metadata
+	ContentHash  string         `json:"content_hash" gorm:"size:64"` // SHA-256 of content and metadata; syncs re-embed only elements whose hash changed
 	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
+	UpdatedAt    time.Time      `json:"updated_at"` // Last sync that embedded or verified the element
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations
@@ -30,6 +33,7 @@ type SchemaEmbedding struct {
 type KPIDefinition struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	UserID      uint           `json:"user_id" gorm:"not null;index"`
+	TenantID    uint           `json:"-" gorm:"not null;default:1;index"`
 	Name        string         `json:"name" gorm:"not null;uniqueIndex:idx_user_kpi_name"`
 	DisplayName string         `json:"display_name"`
 	Description string         `json:"description" gorm:"type:text"`
@@ -39,6 +43,9 @@ type KPIDefinition struct {
 	Grain       string         `json:"grain"` // daily, weekly, monthly, etc.
 	Filters     JSON           `json:"filters" gorm:"type:jsonb"` // Default filters
 	Tags        JSON           `json:"tags" gorm:"type:jsonb"` // Tags for categorization
+	DataSourceID *uint         `json:"data_source_id,omitempty" gorm:"index"` // Data source the formula is computed on
+	SourceTable string         `json:"source_table,omitempty"` // Table the formula aggregates
+	TimeColumn  string         `json:"time_column,omitempty"` // Column time ranges and grains apply to
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
@@ -48,13 +55,20 @@ type KPIDefinition struct {
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
 
+// TableName overrides the default table name, which splits the KPI acronym
+func (KPIDefinition) TableName() string {
+	return "kpi_definitions"
+}
+
 // BusinessGlossary stores business term definitions
 type BusinessGlossary struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	UserID      uint           `json:"user_id" gorm:"not null;index"`
+	TenantID    uint           `json:"-" gorm:"not null;default:1;index"`
 	Term        string         `json:"term" gorm:"not null;uniqueIndex:idx_user_term"`
 	Definition  string         `json:"definition" gorm:"type:text;not null"`
 	Synonyms    JSON           `json:"synonyms" gorm:"type:jsonb"` // Alternative terms
+	Translations JSON          `json:"translations" gorm:"type:jsonb"` // Language code to phrases, e.g. {"id": ["omzet"]}
 	Category    string         `json:"category"` // business, technical, domain-specific
 	Domain      string         `json:"domain"` // finance, marketing, operations, etc.
 	Examples    JSON           `json:"examples" gorm:"type:jsonb"` // Usage examples
@@ -72,7 +86,9 @@ type BusinessGlossary struct {
 type RAGQueryContext struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	UserID       uint           `json:"user_id" gorm:"not null;index"`
+	TenantID     uint           `json:"-" gorm:"not null;default:1;index"`
 	DataSourceID uint           `json:"data_source_id" gorm:"not null;index"`
+	QueryID      *uint          `json:"query_id,omitempty" gorm:"index"` // NL2SQL query the question was converted in
 	Query        string         `json:"query" gorm:"type:text;not null"`
 	Context      JSON           `json:"context" gorm:"type:jsonb"` // Retrieved context from RAG
 	Embedding    []float32 `json:"-" gorm:"type:vector(1536)"` // Query embedding
@@ -94,6 +110,9 @@ type KPIDefinitionRequest struct {
 	Grain       string                 `json:"grain" validate:"max=20"`
 	Filters     map[string]interface{} `json:"filters"`
 	Tags        []string               `json:"tags"`
+	DataSourceID *uint                 `json:"data_source_id,omitempty"`
+	SourceTable string                 `json:"source_table" validate:"max=255"`
+	TimeColumn  string                 `json:"time_column" validate:"max=255"`
 }
 
 type KPIDefinitionResponse struct {
@@ -107,6 +126,9 @@ type KPIDefinitionResponse struct {
 	Grain       string                 `json:"grain"`
 	Filters     map[string]interface{} `json:"filters"`
 	Tags        []string               `json:"tags"`
+	DataSourceID *uint                 `json:"data_source_id,omitempty"`
+	SourceTable string                 `json:"source_table,omitempty"`
+	TimeColumn  string                 `json:"time_column,omitempty"`
 	IsActive    bool                   `json:"is_active"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
@@ -116,6 +138,7 @@ type BusinessGlossaryRequest struct {
 	Term         string   `json:"term" validate:"required,min=1,max=100"`
 	Definition   string   `json:"definition" validate:"required,min=1,max=1000"`
 	Synonyms     []string `json:"synonyms"`
+	Translations map[string][]string `json:"translations"` // Language code to phrases of the term in that language
 	Category     string   `json:"category" validate:"max=50"`
 	Domain       string   `json:"domain" validate:"max=50"`
 	Examples     []string `json:"examples"`
@@ -127,6 +150,7 @@ type BusinessGlossaryResponse struct {
 	Term         string    `json:"term"`
 	Definition   string    `json:"definition"`
 	Synonyms     []string  `json:"synonyms"`
+	Translations map[string][]string `json:"translations,omitempty"`
 	Category     string    `json:"category"`
 	Domain       string    `json:"domain"`
 	Examples     []string  `json:"examples"`
@@ -136,6 +160,37 @@ type BusinessGlossaryResponse struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// Sources of a query expansion
+const (
+	QueryExpansionSourceGlossary    = "glossary"
+	QueryExpansionSourceKPI         = "kpi"
+	QueryExpansionSourceTranslation = "translation" // Built-in domain term translations
+)
+
+// QueryExpansion records a phrase of a question that was expanded with its
+// canonical glossary term or KPI name before retrieval and SQL generation
+type QueryExpansion struct {
+	Phrase     string `json:"phrase"`      // As written in the question
+	ExpandedTo string `json:"expanded_to"` // Glossary term or KPI name
+	Source     string `json:"source"`      // glossary, kpi or translation
+}
+
+// EmbedAllRequest configures a run embedding all KPIs and glossary terms that lack embeddings
+type EmbedAllRequest struct {
+	BatchSize int `json:"batch_size" validate:"omitempty,min=1,max=500"` // Texts per embedding request, default 50
+}
+
+// EmbedAllProgress reports a batch embedding run. It is sent after every batch
+// and as the final result.
+type EmbedAllProgress struct {
+	ElementType string   `json:"element_type,omitempty"` // kpi or glossary: the type of the last batch
+	Total       int      `json:"total"`                  // KPIs and terms lacking embeddings when the run started
+	Embedded    int      `json:"embedded"`
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors,omitempty"`
+	Done        bool     `json:"done"`
+}
+
 type RAGSearchRequest struct {
 	Query        string `json:"query" validate:"required"`
 	DataSourceID uint   `json:"data_source_id" validate:"required"`
@@ -148,6 +203,7 @@ type RAGSearchResult struct {
 	ElementName string                 `json:"element_name"`
 	Content     string                 `json:"content"`
 	Score       float64                `json:"score"`
+	RerankScore float64                `json:"rerank_score,omitempty"` // Set when the reranker was applied
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
@@ -180,6 +236,9 @@ func (k *KPIDefinition) ToResponse() *KPIDefinitionResponse {
 		Grain:       k.Grain,
 		Filters:     filters,
 		Tags:        tags,
+		DataSourceID: k.DataSourceID,
+		SourceTable: k.SourceTable,
+		TimeColumn:  k.TimeColumn,
 		IsActive:    k.IsActive,
 		CreatedAt:   k.CreatedAt,
 		UpdatedAt:   k.UpdatedAt,
@@ -192,6 +251,11 @@ func (g *BusinessGlossary) ToResponse() *BusinessGlossaryResponse {
 		_ = json.Unmarshal(g.Synonyms, &synonyms)
 	}
 
+	var translations map[string][]string
+	if g.Translations != nil {
+		_ = json.Unmarshal(g.Translations, &translations)
+	}
+
 	var examples []string
 	if g.Examples != nil {
 		_ = json.Unmarshal(g.Examples, &examples)
@@ -207,6 +271,7 @@ func (g *BusinessGlossary) ToResponse() *BusinessGlossaryResponse {
 		Term:         g.Term,
 		Definition:   g.Definition,
 		Synonyms:     synonyms,
+		Translations: translations,
 		Category:     g.Category,
 		Domain:       g.Domain,
 		Examples:     examples,
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportFormat is the document format a report is rendered to
+type ReportFormat string
+
+const (
+	ReportFormatHTML ReportFormat = "html"
+	ReportFormatPDF  ReportFormat = "pdf"
+)
+
+// ReportFrequency is how often a report is delivered to its recipients
+type ReportFrequency string
+
+const (
+	ReportFrequencyOff    ReportFrequency = "off" // Only run on demand
+	ReportFrequencyDaily  ReportFrequency = "daily"
+	ReportFrequencyWeekly ReportFrequency = "weekly"
+)
+
+// ReportItemType is the kind of content a report item shows
+type ReportItemType string
+
+const (
+	ReportItemSavedQuery ReportItemType = "saved_query" // Fresh results of a saved query
+	ReportItemKPI        ReportItemType = "kpi"         // KPI value compared with the prior period
+	ReportItemDashboard  ReportItemType = "dashboard"   // Latest results of the widgets of a dashboard
+)
+
+// ReportRunTrigger is what started a report run
+type ReportRunTrigger string
+
+const (
+	ReportRunTriggerSchedule ReportRunTrigger = "schedule"
+	ReportRunTriggerManual   ReportRunTrigger = "manual"
+)
+
+// ReportRunStatus is the state of a report run
+type ReportRunStatus string
+
+const (
+	ReportRunStatusRunning   ReportRunStatus = "running"
+	ReportRunStatusSucceeded ReportRunStatus = "succeeded"
+	ReportRunStatusFailed    ReportRunStatus = "failed"
+)
+
+// Report is a document composed of saved queries, KPIs and dashboards that is
+// rendered to HTML or PDF and emailed to its recipients on a schedule
+type Report struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	UserID      uint            `json:"user_id" gorm:"not null;index"` // Owner; items run as this user
+	TenantID    uint            `json:"-" gorm:"not null;default:1;index"`
+	Name        string          `json:"name" gorm:"not null"`
+	Description string          `json:"description,omitempty" gorm:"type:text"`
+	Format      ReportFormat    `json:"format" gorm:"not null;default:html"`
+	Frequency   ReportFrequency `json:"frequency" gorm:"not null;default:off"`
+	Recipients  JSON            `json:"-" gorm:"type:jsonb"`                // []string
+	Items       JSON            `json:"-" gorm:"type:jsonb"`                // []ReportItem
+	NextRunAt   *time.Time      `json:"next_run_at,omitempty" gorm:"index"` // Nil when the report is not scheduled
+	LastRunAt   *time.Time      `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `json:"-" gorm:"index"`
+}
+
+// ReportItem is a section of a report
+type ReportItem struct {
+	Type  ReportItemType `json:"type" validate:"required,oneof=saved_query kpi dashboard"`
+	RefID uint           `json:"ref_id" validate:"required"`         // Saved query, KPI or dashboard ID
+	Title string         `json:"title,omitempty" validate:"max=255"` // Defaults to the name of the referenced item
+}
+
+// ReportRun records one rendering of a report and its delivery
+type ReportRun struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`
+	TenantID   uint             `json:"-" gorm:"not null;default:1;index"`
+	ReportID   uint             `json:"report_id" gorm:"not null;index"`
+	Trigger    ReportRunTrigger `json:"trigger" gorm:"not null"`
+	Status     ReportRunStatus  `json:"status" gorm:"not null"`
+	Format     ReportFormat     `json:"format" gorm:"not null"`
+	OutputKey  string           `json:"-"` // Object store key of the rendered document
+	OutputSize int64            `json:"output_size,omitempty"`
+	Recipients int              `json:"recipients"` // Recipients the report was emailed to
+	Error      string           `json:"error,omitempty" gorm:"type:text"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+// Request/Response DTOs
+
+// ReportRequest creates or replaces a report
+type ReportRequest struct {
+	Name        string          `json:"name" validate:"required,max=255"`
+	Description string          `json:"description,omitempty" validate:"max=2000"`
+	Format      ReportFormat    `json:"format,omitempty" validate:"omitempty,oneof=html pdf"`            // Defaults to html
+	Frequency   ReportFrequency `json:"frequency,omitempty" validate:"omitempty,oneof=off daily weekly"` // Defaults to off
+	Recipients  []string        `json:"recipients,omitempty" validate:"max=50,dive,email"`
+	Items       []ReportItem    `json:"items" validate:"required,min=1,max=20,dive"`
+}
+
+// ReportRunRequest runs a report on demand
+type ReportRunRequest struct {
+	Send bool `json:"send"` // Also email the report to its recipients
+}
+
+// ReportResponse is a report with its recipients and items
+type ReportResponse struct {
+	ID          uint            `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Format      ReportFormat    `json:"format"`
+	Frequency   ReportFrequency `json:"frequency"`
+	Recipients  []string        `json:"recipients"`
+	Items       []ReportItem    `json:"items"`
+	NextRunAt   *time.Time      `json:"next_run_at,omitempty"`
+	LastRunAt   *time.Time      `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ReportSection is the rendered content of a report item. Items that fail to
+// run render their error instead of failing the whole report.
+type ReportSection struct {
+	Title     string         `json:"title"`
+	Type      ReportItemType `json:"type"`
+	Summary   string         `json:"summary,omitempty"` // KPI value and change, or the question of a query
+	Columns   []string       `json:"columns,omitempty"`
+	Rows      [][]string     `json:"rows,omitempty"`      // Formatted cell values
+	Truncated bool           `json:"truncated,omitempty"` // More rows were available than shown
+	Error     string         `json:"error,omitempty"`
+}
+
+// ReportRunResult reports a run of the scheduled report job
+type ReportRunResult struct {
+	Due     int      `json:"due"`
+	Sent    int      `json:"sent"`
+	Skipped int      `json:"skipped"` // Reports of inactive or deleted users
+	Failed  int      `json:"failed"`  // Retried an hour later
+	Errors  []string `json:"errors,omitempty"`
+}
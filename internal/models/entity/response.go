@@ -6,9 +6,9 @@ import "github.com/gofiber/fiber/v2"
 type StandardResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   interface{} `json:"error,omitempty"`
-	Meta    *Meta       `json:"meta,omitempty"`
+	Data    interface{}    `json:"data,omitempty"`
+	Error   *ErrorResponse `json:"error,omitempty"` // Set when success is false
+	Meta    *Meta          `json:"meta,omitempty"`
 }
 
 // Meta represents pagination and additional metadata
@@ -21,7 +21,7 @@ type Meta struct {
 
 // ErrorResponse represents error details
 type ErrorResponse struct {
-	Code    string      `json:"code"`
+	Code    ErrorCode   `json:"code"` // See ErrorCatalog
 	Message string      `json:"message"`
 	Details interface{} `json:"details,omitempty"`
 }
@@ -44,11 +44,22 @@ func SuccessResponseWithMeta(c *fiber.Ctx, message string, data interface{}, met
 	})
 }
 
+// ErrorResponseWithStatus responds with the general error code of the status,
+// with err as the details of the error
 func ErrorResponseWithStatus(c *fiber.Ctx, status int, message string, err interface{}) error {
 	return c.Status(status).JSON(StandardResponse{
 		Success: false,
 		Message: message,
-		Error:   err,
+		Error:   &ErrorResponse{Code: ErrorCodeForStatus(status), Message: message, Details: err},
+	})
+}
+
+// ErrorResponseWithCode responds with an error code of the catalog and its status
+func ErrorResponseWithCode(c *fiber.Ctx, code ErrorCode, message string, details interface{}) error {
+	return c.Status(code.Status()).JSON(StandardResponse{
+		Success: false,
+		Message: message,
+		Error:   &ErrorResponse{Code: code, Message: message, Details: details},
 	})
 }
 
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// How a row differs between two results
+const (
+	RowDiffAdded   = "added"   // Only in the compared result
+	RowDiffRemoved = "removed" // Only in the base result
+	RowDiffChanged = "changed" // In both with different values
+)
+
+// ResultDiffRequest names the two results to diff: the latest results of two
+// queries, two stored results of one query, or the two latest runs of a saved
+// query. Rows are matched on the key columns; by default those are the shared
+// non-numeric columns, and rows are matched by position when there are none.
+type ResultDiffRequest struct {
+	BaseQueryID     uint     `json:"base_query_id,omitempty"`
+	CompareQueryID  uint     `json:"compare_query_id,omitempty"`
+	BaseResultID    uint     `json:"base_result_id,omitempty"`    // Default the latest result of the base query
+	CompareResultID uint     `json:"compare_result_id,omitempty"` // Default the latest result of the compared query
+	SavedQueryID    uint     `json:"saved_query_id,omitempty"`    // Instead of queries: its previous run against its latest
+	KeyColumns      []string `json:"key_columns,omitempty" validate:"max=20"`
+	MaxChanges      int      `json:"max_changes,omitempty" validate:"min=0,max=1000"` // Rows listed; default 100
+	UnmaskPII       bool     `json:"-"`                                               // Set from the caller's permission
+}
+
+// ResultDiffSide identifies one of the two diffed results
+type ResultDiffSide struct {
+	QueryID    uint      `json:"query_id"`
+	ResultID   uint      `json:"result_id"`
+	SQLVersion int       `json:"sql_version"`
+	RowCount   int64     `json:"row_count"`
+	ExecutedAt time.Time `json:"executed_at"`
+}
+
+// ColumnTypeChange is a column whose type differs between the results
+type ColumnTypeChange struct {
+	Column string `json:"column"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// ResultDiffColumns lists the columns added, removed or retyped
+type ResultDiffColumns struct {
+	Added       []string           `json:"added"`
+	Removed     []string           `json:"removed"`
+	TypeChanged []ColumnTypeChange `json:"type_changed"`
+}
+
+// CellChange is a value of a matched row that changed
+type CellChange struct {
+	Column    string      `json:"column"`
+	Base      interface{} `json:"base"`
+	Compare   interface{} `json:"compare"`
+	Delta     *float64    `json:"delta,omitempty"`      // Numeric values only
+	PctChange *float64    `json:"pct_change,omitempty"` // Percent of the base value, when it is not zero
+}
+
+// RowDiff is a row added, removed or changed
+type RowDiff struct {
+	Status  string                 `json:"status"`            // added, removed or changed
+	Key     map[string]interface{} `json:"key,omitempty"`     // Key column values; empty when matched by position
+	Index   int                    `json:"index"`             // Position of the row in the compared result, or in the base result when removed
+	Base    map[string]interface{} `json:"base,omitempty"`    // Removed and changed rows
+	Compare map[string]interface{} `json:"compare,omitempty"` // Added and changed rows
+	Changes []CellChange           `json:"changes,omitempty"` // Changed rows
+}
+
+// AggregateDelta compares the total of a numeric column
+type AggregateDelta struct {
+	Column    string   `json:"column"`
+	Base      float64  `json:"base"`
+	Compare   float64  `json:"compare"`
+	Delta     float64  `json:"delta"`
+	PctChange *float64 `json:"pct_change,omitempty"`
+}
+
+// ResultDiff is how a compared result differs from a base result
+type ResultDiff struct {
+	Base          ResultDiffSide    `json:"base"`
+	Compare       ResultDiffSide    `json:"compare"`
+	KeyColumns    []string          `json:"key_columns"` // Empty when rows were matched by position
+	Columns       ResultDiffColumns `json:"columns"`
+	RowsAdded     int               `json:"rows_added"`
+	RowsRemoved   int               `json:"rows_removed"`
+	RowsChanged   int               `json:"rows_changed"`
+	RowsUnchanged int               `json:"rows_unchanged"`
+	RowCountDelta int64             `json:"row_count_delta"`
+	Aggregates    []AggregateDelta  `json:"aggregates"`
+	Rows          []RowDiff         `json:"rows"`
+	Truncated     bool              `json:"truncated"` // More rows differ than are listed
+	MaskedColumns []string          `json:"masked_columns,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// ColumnFormatKind tells clients how to render the values of a result column
+type ColumnFormatKind string
+
+const (
+	ColumnFormatNumber     ColumnFormatKind = "number"
+	ColumnFormatCurrency   ColumnFormatKind = "currency"
+	ColumnFormatPercentage ColumnFormatKind = "percentage"
+	ColumnFormatCount      ColumnFormatKind = "count"
+)
+
+// Sources of column formats
+const (
+	ColumnFormatSourceColumnMetadata = "column_metadata"
+	ColumnFormatSourceKPI            = "kpi"
+)
+
+// ColumnFormat annotates a numeric result column with its unit, currency and
+// a scale suited to its values, so every client renders "Rp 1.2M" the same way.
+// Values stay raw; divide by Divisor and append Suffix to abbreviate them.
+type ColumnFormat struct {
+	Kind          ColumnFormatKind `json:"kind"`
+	Unit          string           `json:"unit,omitempty"`     // Free text unit of number columns, e.g. "ms"
+	Currency      string           `json:"currency,omitempty"` // ISO 4217 code of currency columns
+	Symbol        string           `json:"symbol,omitempty"`   // e.g. "Rp" or "$"
+	Decimals      int              `json:"decimals"`           // Decimals to show for abbreviated values
+	Divisor       float64          `json:"divisor,omitempty"`  // 1e3, 1e6, 1e9 or 1e12 when values are abbreviated
+	Suffix        string           `json:"suffix,omitempty"`   // K, M, B or T
+	Example       string           `json:"example,omitempty"`  // Largest value of the column, formatted
+	Source        string           `json:"source"`             // column_metadata or kpi
+	ConvertedFrom string           `json:"converted_from,omitempty"`
+	Rate          float64          `json:"rate,omitempty"` // Exchange rate values were multiplied by
+}
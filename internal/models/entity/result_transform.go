@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Operations reshaping a stored result
+const (
+	ResultTransformGroupBy = "group_by" // Aggregate rows by the group_by columns
+	ResultTransformPivot   = "pivot"    // Turn the values of pivot_column into columns
+	ResultTransformSort    = "sort"     // Order rows by the sort keys
+	ResultTransformTopN    = "top_n"    // Keep the n rows with the largest (or smallest) by values
+)
+
+// Aggregate functions of group_by and pivot
+const (
+	AggregateSum           = "sum"
+	AggregateAvg           = "avg"
+	AggregateMin           = "min"
+	AggregateMax           = "max"
+	AggregateCount         = "count"          // Non-null values, or rows without a column
+	AggregateCountDistinct = "count_distinct" // Distinct non-null values
+)
+
+// ResultAggregate computes a column of a group_by
+type ResultAggregate struct {
+	Function string `json:"function"`
+	Column   string `json:"column,omitempty"` // Optional for count
+	As       string `json:"as,omitempty"`     // Default function_column, e.g. sum_revenue
+}
+
+// ResultSortKey orders rows by a column
+type ResultSortKey struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// ResultTransformOperation is one step of a transform; the fields used depend on Op
+type ResultTransformOperation struct {
+	Op string `json:"op"`
+
+	// group_by: the grouping columns and the aggregates computed per group.
+	// pivot: the columns identifying a row of the pivoted result.
+	GroupBy    []string          `json:"group_by,omitempty"`
+	Aggregates []ResultAggregate `json:"aggregates,omitempty"`
+
+	// pivot: a column per distinct value of PivotColumn holding Function of Value
+	PivotColumn string `json:"pivot_column,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Function    string `json:"function,omitempty"` // Default sum
+
+	// sort
+	Sort []ResultSortKey `json:"sort,omitempty"`
+
+	// top_n: the N rows with the largest By values, or the smallest with Ascending
+	N         int    `json:"n,omitempty"`
+	By        string `json:"by,omitempty"`
+	Ascending bool   `json:"ascending,omitempty"`
+}
+
+// ResultTransformRequest reshapes a stored result of a query without querying
+// the data source again. Operations apply in order, each to the output of the
+// previous one.
+type ResultTransformRequest struct {
+	ResultID   uint                       `json:"result_id,omitempty"` // Default the latest result
+	Operations []ResultTransformOperation `json:"operations"`
+	Limit      int                        `json:"limit,omitempty"` // Rows returned; default 1000
+	UnmaskPII  bool                       `json:"-"`               // Set from the caller's permission
+}
+
+// ResultTransformResponse is the derived result set
+type ResultTransformResponse struct {
+	QueryID       uint                     `json:"query_id"`
+	ResultID      uint                     `json:"result_id"`
+	Columns       []Column                 `json:"columns"`
+	Data          []map[string]interface{} `json:"data"`
+	TotalRows     int                      `json:"total_rows"` // Rows of the derived result
+	Truncated     bool                     `json:"truncated"`  // More rows than the limit were derived
+	SourceRows    int64                    `json:"source_rows"`
+	MaskedColumns []string                 `json:"masked_columns,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"` // When the source result was stored
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Element types retrieved as schema context
+var SchemaElementTypes = []string{"table", "column"}
+
+// RetrievalConfig controls how the NL2SQL context is retrieved: how many
+// tables and columns, KPIs and glossary terms, the minimum similarity and the
+// token budget of the retrieved context. The config without a data source is
+// the workspace default of its tenant, used by data sources that have no
+// config of their own; without either the built-in defaults apply.
+type RetrievalConfig struct {
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	TenantID           uint      `json:"-" gorm:"not null;default:1;index;uniqueIndex:idx_retrieval_configs_default,where:data_source_id IS NULL"`
+	DataSourceID       *uint     `json:"data_source_id,omitempty" gorm:"uniqueIndex"` // Nil for the workspace default
+	SchemaTopK         int       `json:"schema_top_k"`                                // Tables and columns, ranked together
+	SchemaElementTypes JSON      `json:"schema_element_types" gorm:"type:jsonb"`      // "table", "column" or both
+	KPITopK            int       `json:"kpi_top_k" gorm:"column:kpi_top_k"`
+	GlossaryTopK       int       `json:"glossary_top_k"`
+	MinScore           float64   `json:"min_score"`          // Elements less similar to the question are left out
+	MaxContextTokens   int       `json:"max_context_tokens"` // Budget of the retrieved context; 0 for none
+	MaxPromptTokens    int       `json:"max_prompt_tokens"`  // Budget of the whole prompt; 0 for none
+	UpdatedBy          uint      `json:"updated_by"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// DefaultMaxPromptTokens keeps prompts well within the context window of the
+// generation models, leaving room for the response
+const DefaultMaxPromptTokens = 12000
+
+// DefaultRetrievalConfig is the built-in retrieval used without a config
+func DefaultRetrievalConfig() *RetrievalConfig {
+	types, _ := json.Marshal(SchemaElementTypes)
+	return &RetrievalConfig{
+		SchemaTopK:         10,
+		SchemaElementTypes: JSON(types),
+		KPITopK:            5,
+		GlossaryTopK:       5,
+		MaxPromptTokens:    DefaultMaxPromptTokens,
+	}
+}
+
+// GetSchemaElementTypes decodes the schema element types, all of them when unset
+func (c *RetrievalConfig) GetSchemaElementTypes() []string {
+	var types []string
+	if len(c.SchemaElementTypes) > 0 {
+		json.Unmarshal(c.SchemaElementTypes, &types)
+	}
+	if len(types) == 0 {
+		return SchemaElementTypes
+	}
+	return types
+}
+
+// Request/Response DTOs
+
+// RetrievalConfigRequest sets the retrieval config of the workspace or of a
+// data source. A top K of 0 leaves that section out of the context.
+type RetrievalConfigRequest struct {
+	DataSourceID       *uint    `json:"data_source_id,omitempty"` // Omit for the workspace default
+	SchemaTopK         int      `json:"schema_top_k" validate:"min=0,max=50"`
+	SchemaElementTypes []string `json:"schema_element_types,omitempty" validate:"dive,oneof=table column"` // Both when empty
+	KPITopK            int      `json:"kpi_top_k" validate:"min=0,max=20"`
+	GlossaryTopK       int      `json:"glossary_top_k" validate:"min=0,max=20"`
+	MinScore           float64  `json:"min_score" validate:"min=0,max=1"`
+	MaxContextTokens   int      `json:"max_context_tokens" validate:"min=0,max=200000"`
+	MaxPromptTokens    int      `json:"max_prompt_tokens" validate:"min=0,max=200000"`
+}
+
+// RetrievalConfigListResponse lists the configs with the built-in defaults
+type RetrievalConfigListResponse struct {
+	Configs []RetrievalConfig `json:"configs"` // Workspace default first
+	Builtin *RetrievalConfig  `json:"builtin"`
+}
+
+// RetrievedContext holds the elements retrieved for an NL2SQL question, best
+// first within each section, less those trimmed to fit the token budgets
+type RetrievedContext struct {
+	Schema   []RAGSearchResult `json:"schema"`
+	KPIs     []RAGSearchResult `json:"kpis"`
+	Glossary []RAGSearchResult `json:"glossary"`
+}
+
+// PromptBudget reports how a rendered NL2SQL prompt fits its token budget
+type PromptBudget struct {
+	MaxTokens       int  `json:"max_tokens"`       // 0 for no budget
+	Tokens          int  `json:"tokens"`           // Of the rendered prompt
+	ElementsTrimmed int  `json:"elements_trimmed"` // Retrieved elements left out to fit, lowest scoring first
+	OverBudget      bool `json:"over_budget"`      // Still over budget with every element left out
+}
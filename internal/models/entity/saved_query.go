@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+)
+
+// SavedQuery is a bookmarked query: a question with its pinned SQL on a data
+// source, which can be run again on fresh data. Shared saved queries are
+// visible to and runnable by everyone in the workspace; only the owner edits them.
+type SavedQuery struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	TenantID     uint       `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint       `json:"data_source_id" gorm:"not null;index"`
+	QueryID      *uint      `json:"query_id,omitempty"` // History entry it was saved from
+	Name         string     `json:"name" gorm:"not null"`
+	Description  string     `json:"description,omitempty" gorm:"type:text"`
+	Tags         JSON       `json:"-" gorm:"type:jsonb"` // []string
+	NLQuery      string     `json:"nl_query" gorm:"type:text"`
+	SQL          string     `json:"sql" gorm:"column:sql;type:text;not null"`
+	Shared       bool       `json:"shared" gorm:"not null;default:false;index"`
+	RunCount     int        `json:"run_count" gorm:"not null;default:0"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// SavedQueryRequest saves a query. With query_id, the question, SQL and data
+// source default to those of the history entry.
+type SavedQueryRequest struct {
+	QueryID      *uint    `json:"query_id,omitempty"`
+	DataSourceID uint     `json:"data_source_id,omitempty"`
+	Name         string   `json:"name" validate:"required,max=255"`
+	Description  string   `json:"description,omitempty" validate:"max=2000"`
+	Tags         []string `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"`
+	NLQuery      string   `json:"nl_query,omitempty" validate:"max=1000"`
+	SQL          string   `json:"sql,omitempty"`
+	Shared       bool     `json:"shared"` // Share with the workspace
+}
+
+// SavedQueryFilter narrows the saved queries listed
+type SavedQueryFilter struct {
+	Tag          string `query:"tag"`
+	DataSourceID uint   `query:"data_source_id"`
+	Search       string `query:"search"` // Matches the name, description or question
+	Mine         bool   `query:"mine"`   // Only the user's own, not those shared by others
+}
+
+// SavedQueryResponse is a saved query with its tags
+type SavedQueryResponse struct {
+	ID           uint       `json:"id"`
+	UserID       uint       `json:"user_id"`
+	DataSourceID uint       `json:"data_source_id"`
+	QueryID      *uint      `json:"query_id,omitempty"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"`
+	Tags         []string   `json:"tags"`
+	NLQuery      string     `json:"nl_query"`
+	SQL          string     `json:"sql"`
+	Shared       bool       `json:"shared"`
+	Owned        bool       `json:"owned"` // Saved by the current user
+	RunCount     int        `json:"run_count"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// SavedQueryRunRequest runs a saved query again; the options match query execution
+type SavedQueryRunRequest struct {
+	Limit      int                    `json:"limit,omitempty" validate:"omitempty,min=1,max=10000"`
+	Anonymize  bool                   `json:"anonymize,omitempty"`
+	PageSize   int                    `json:"page_size,omitempty" validate:"omitempty,min=1,max=1000"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	UnmaskPII  bool                   `json:"-"` // Set for callers with the PII unmask permission
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// SchemaChange records how the schema of a data source changed when it was
+// rediscovered, and the saved queries and KPIs that reference what was removed
+type SchemaChange struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	TenantID        uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID    uint      `json:"data_source_id" gorm:"not null;index"`
+	HasChanges      bool      `json:"has_changes"`
+	Diff            JSON      `json:"-" gorm:"type:jsonb"`                      // SchemaDiff
+	AffectedQueries JSON      `json:"-" gorm:"type:jsonb"`                      // []SchemaChangeImpact
+	AffectedKPIs    JSON      `json:"-" gorm:"column:affected_kpis;type:jsonb"` // []SchemaChangeImpact
+	CreatedAt       time.Time `json:"created_at" gorm:"index"`
+}
+
+// SchemaDiff lists the tables and columns added, removed or retyped between two discoveries
+type SchemaDiff struct {
+	AddedTables    []string             `json:"added_tables"`
+	RemovedTables  []string             `json:"removed_tables"`
+	AddedColumns   []SchemaColumnChange `json:"added_columns"`
+	RemovedColumns []SchemaColumnChange `json:"removed_columns"`
+	RetypedColumns []SchemaColumnChange `json:"retyped_columns"`
+}
+
+// Empty reports whether the schema did not change
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 &&
+		len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 && len(d.RetypedColumns) == 0
+}
+
+// SchemaColumnChange is a column that was added, removed or changed type
+type SchemaColumnChange struct {
+	Table   string `json:"table"`
+	Column  string `json:"column"`
+	OldType string `json:"old_type,omitempty"`
+	NewType string `json:"new_type,omitempty"`
+}
+
+// SchemaChangeImpact is a saved query or KPI that references removed tables or columns
+type SchemaChangeImpact struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`       // Question of the query or name of the KPI
+	References []string `json:"references"` // Removed tables and table.column names it uses
+}
+
+// Request/Response DTOs
+
+// SchemaChangeResponse is a recorded schema change with its diff and impact
+type SchemaChangeResponse struct {
+	ID              uint                 `json:"id"`
+	DataSourceID    uint                 `json:"data_source_id"`
+	HasChanges      bool                 `json:"has_changes"`
+	Diff            SchemaDiff           `json:"diff"`
+	AffectedQueries []SchemaChangeImpact `json:"affected_queries"`
+	AffectedKPIs    []SchemaChangeImpact `json:"affected_kpis"`
+	CreatedAt       time.Time            `json:"created_at"`
+}
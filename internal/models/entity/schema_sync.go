@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+)
+
+// SchemaSyncStatus represents the outcome of a schema sync run
+type SchemaSyncStatus string
+
+const (
+	SchemaSyncStatusRunning   SchemaSyncStatus = "running"
+	SchemaSyncStatusCompleted SchemaSyncStatus = "completed"
+	SchemaSyncStatusSkipped   SchemaSyncStatus = "skipped"
+	SchemaSyncStatusFailed    SchemaSyncStatus = "failed"
+)
+
+// SchemaSyncTrigger represents what started a schema sync run
+type SchemaSyncTrigger string
+
+const (
+	SchemaSyncTriggerManual    SchemaSyncTrigger = "manual"
+	SchemaSyncTriggerScheduled SchemaSyncTrigger = "scheduled"
+	SchemaSyncTriggerAuto      SchemaSyncTrigger = "auto"
+)
+
+// SchemaSyncLock is a per data source lease that prevents several instances
+// from syncing the same data source at the same time
+type SchemaSyncLock struct {
+	DataSourceID uint      `json:"data_source_id" gorm:"primaryKey;autoIncrement:false"`
+	SyncID       string    `json:"sync_id" gorm:"not null"`
+	InstanceID   string    `json:"instance_id" gorm:"not null"`
+	LockedAt     time.Time `json:"locked_at" gorm:"not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// SchemaSyncRun records a single schema sync attempt (sync history)
+type SchemaSyncRun struct {
+	ID           uint               `json:"id" gorm:"primaryKey"`
+	TenantID     uint               `json:"-" gorm:"not null;default:1;index"` // Tenant of the data source
+	SyncID       string             `json:"sync_id" gorm:"not null;uniqueIndex"`
+	DataSourceID uint               `json:"data_source_id" gorm:"not null;index"`
+	InstanceID   string             `json:"instance_id" gorm:"not null"`
+	TriggeredBy  SchemaSyncTrigger  `json:"triggered_by" gorm:"not null"`
+	Status       SchemaSyncStatus   `json:"status" gorm:"not null;index"`
+	Message      string             `json:"message,omitempty" gorm:"type:text"`
+	StartedAt    time.Time          `json:"started_at" gorm:"not null"`
+	FinishedAt   *time.Time         `json:"finished_at,omitempty"`
+	DurationMs   int64              `json:"duration_ms"`
+	Progress     SchemaSyncProgress `json:"progress" gorm:"embedded"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// SchemaSyncProgress counts the work of an embedding sync. It is saved after
+// every schema, so a running sync reports how far it got.
+type SchemaSyncProgress struct {
+	SchemasTotal      int `json:"schemas_total"`
+	SchemasSynced     int `json:"schemas_synced"`
+	SchemasFailed     int `json:"schemas_failed"`
+	ElementsEmbedded  int `json:"elements_embedded"`  // New or changed tables and columns
+	ElementsUnchanged int `json:"elements_unchanged"` // Kept, their content hash matched
+	ElementsRemoved   int `json:"elements_removed"`   // Dropped tables and columns
+}
+
+// Add adds the element counts of a schema sync
+func (p *SchemaSyncProgress) Add(other SchemaSyncProgress) {
+	p.ElementsEmbedded += other.ElementsEmbedded
+	p.ElementsUnchanged += other.ElementsUnchanged
+	p.ElementsRemoved += other.ElementsRemoved
+}
+
+// Finish marks the run as finished with the given status
+func (r *SchemaSyncRun) Finish(status SchemaSyncStatus, message string) {
+	now := time.Now()
+	r.Status = status
+	r.Message = message
+	r.FinishedAt = &now
+	r.DurationMs = now.Sub(r.StartedAt).Milliseconds()
+}
+
+// KnowledgeSyncStateID is the sync state row of the KPI and glossary
+// embeddings, which all data sources share
+const KnowledgeSyncStateID uint = 0
+
+// Reasons a data source needs a sync
+const (
+	SyncReasonNeverSynced      = "never_synced"      // No completed sync yet, e.g. a new data source
+	SyncReasonNoEmbeddings     = "no_embeddings"     // Active schemas but no table embeddings
+	SyncReasonSchemaChanged    = "schema_changed"    // Schemas added, edited, deactivated or deleted
+	SyncReasonKnowledgeChanged = "knowledge_changed" // KPIs or glossary terms added, edited or deleted
+)
+
+// SchemaSyncState records the sources the last completed sync of a data source
+// embedded, so later syncs can tell whether anything changed. The row of
+// KnowledgeSyncStateID tracks KPIs and glossary terms.
+type SchemaSyncState struct {
+	DataSourceID  uint      `json:"data_source_id" gorm:"primaryKey;autoIncrement:false"`
+	SourceVersion time.Time `json:"source_version"` // Latest change to the sources when the sync started
+	SourceCount   int64     `json:"source_count"`   // Active schemas, or KPIs and glossary terms, when the sync started
+	SyncID        string    `json:"sync_id"`
+	SyncedAt      time.Time `json:"synced_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SyncSourceVersion is the fingerprint of the sources of a sync: how many are
+// active and when any of them last changed or was deleted
+type SyncSourceVersion struct {
+	Count   int64
+	Version *time.Time // Nil when there are no sources
+}
+
+// Changed reports whether the sources differ from those of a completed sync
+func (v SyncSourceVersion) Changed(state *SchemaSyncState) bool {
+	if v.Count != state.SourceCount {
+		return true
+	}
+	return v.Version != nil && v.Version.After(state.SourceVersion)
+}
+
+// SchemaSyncSchedule describes the scheduled syncs of all data sources
+type SchemaSyncSchedule struct {
+	Enabled         bool       `json:"enabled"`
+	IntervalMinutes int        `json:"interval_minutes"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty"` // On this instance; nil when disabled
+	LastRun         *Job       `json:"last_run,omitempty"`    // Latest sync of all data sources, scheduled or not, on any instance
+}
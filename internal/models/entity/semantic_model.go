@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SemanticMetric is a business measure defined once for a data source as an
+// aggregate over one of its tables, e.g. revenue = SUM(amount) on orders.
+// Questions about a metric are compiled to SQL instead of generated freely.
+type SemanticMetric struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;uniqueIndex:idx_semantic_metrics_name"`
+	Name         string    `json:"name" gorm:"not null;size:128;uniqueIndex:idx_semantic_metrics_name"` // Lower-case, as matched in questions
+	Synonyms     JSON      `json:"-" gorm:"type:jsonb"`                                                 // []string
+	Description  string    `json:"description,omitempty" gorm:"type:text"`
+	Table        string    `json:"table" gorm:"column:table_name;not null"`
+	Expression   string    `json:"expression" gorm:"type:text;not null"` // Aggregate over the table, e.g. SUM(amount)
+	Filter       string    `json:"filter,omitempty" gorm:"type:text"`    // Condition always applied, e.g. status = 'paid'
+	TimeColumn   string    `json:"time_column,omitempty"`                // Default time dimension
+	UpdatedBy    uint      `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SemanticDimension is a column metrics can be broken down by. A dimension on
+// another table than the metric is reached through the stored relationships.
+type SemanticDimension struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;uniqueIndex:idx_semantic_dimensions_name"`
+	Name         string    `json:"name" gorm:"not null;size:128;uniqueIndex:idx_semantic_dimensions_name"` // Lower-case, as matched in questions
+	Synonyms     JSON      `json:"-" gorm:"type:jsonb"`                                                    // []string
+	Description  string    `json:"description,omitempty" gorm:"type:text"`
+	Table        string    `json:"table" gorm:"column:table_name;not null"`
+	Column       string    `json:"column" gorm:"column:column_name;not null"`
+	IsTime       bool      `json:"is_time" gorm:"not null;default:false"` // Bucketed by the requested time grain
+	UpdatedBy    uint      `json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TimeGrain is the bucket a time dimension is truncated to
+type TimeGrain string
+
+const (
+	TimeGrainDay     TimeGrain = "day"
+	TimeGrainWeek    TimeGrain = "week"
+	TimeGrainMonth   TimeGrain = "month"
+	TimeGrainQuarter TimeGrain = "quarter"
+	TimeGrainYear    TimeGrain = "year"
+)
+
+// Request/Response DTOs
+
+// SemanticMetricRequest defines or replaces a metric
+type SemanticMetricRequest struct {
+	Name        string   `json:"name" validate:"required,max=128"`
+	Synonyms    []string `json:"synonyms,omitempty" validate:"max=20,dive,min=1,max=128"`
+	Description string   `json:"description,omitempty" validate:"max=2000"`
+	Table       string   `json:"table" validate:"required,max=255"`
+	Expression  string   `json:"expression" validate:"required,max=1000"`
+	Filter      string   `json:"filter,omitempty" validate:"max=1000"`
+	TimeColumn  string   `json:"time_column,omitempty" validate:"max=255"`
+}
+
+// SemanticDimensionRequest defines or replaces a dimension
+type SemanticDimensionRequest struct {
+	Name        string   `json:"name" validate:"required,max=128"`
+	Synonyms    []string `json:"synonyms,omitempty" validate:"max=20,dive,min=1,max=128"`
+	Description string   `json:"description,omitempty" validate:"max=2000"`
+	Table       string   `json:"table" validate:"required,max=255"`
+	Column      string   `json:"column" validate:"required,max=255"`
+	IsTime      bool     `json:"is_time"`
+}
+
+// SemanticMetricResponse is a metric with its synonyms
+type SemanticMetricResponse struct {
+	SemanticMetric
+	Synonyms []string `json:"synonyms"`
+}
+
+// SemanticDimensionResponse is a dimension with its synonyms
+type SemanticDimensionResponse struct {
+	SemanticDimension
+	Synonyms []string `json:"synonyms"`
+}
+
+// SemanticModelResponse is the semantic model of a data source
+type SemanticModelResponse struct {
+	DataSourceID uint                        `json:"data_source_id"`
+	Metrics      []SemanticMetricResponse    `json:"metrics"`
+	Dimensions   []SemanticDimensionResponse `json:"dimensions"`
+}
+
+// SemanticResolution reports which metrics and dimensions of the semantic
+// model a question used. When Compiled is set the SQL was built from their
+// definitions; otherwise they were passed to the LLM, for the Reason given.
+type SemanticResolution struct {
+	Metrics    []string  `json:"metrics"`
+	Dimensions []string  `json:"dimensions,omitempty"`
+	TimeGrain  TimeGrain `json:"time_grain,omitempty"`
+	Compiled   bool      `json:"compiled"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// SynonymList returns the alternative names of the metric
+func (m *SemanticMetric) SynonymList() []string {
+	synonyms := []string{}
+	if m.Synonyms != nil {
+		_ = json.Unmarshal(m.Synonyms, &synonyms)
+	}
+	return synonyms
+}
+
+func (m *SemanticMetric) ToResponse() SemanticMetricResponse {
+	return SemanticMetricResponse{SemanticMetric: *m, Synonyms: m.SynonymList()}
+}
+
+// SynonymList returns the alternative names of the dimension
+func (d *SemanticDimension) SynonymList() []string {
+	synonyms := []string{}
+	if d.Synonyms != nil {
+		_ = json.Unmarshal(d.Synonyms, &synonyms)
+	}
+	return synonyms
+}
+
+func (d *SemanticDimension) ToResponse() SemanticDimensionResponse {
+	return SemanticDimensionResponse{SemanticDimension: *d, Synonyms: d.SynonymList()}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ShareResourceType is what a share link exposes
+type ShareResourceType string
+
+const (
+	ShareResourceQueryResult ShareResourceType = "query_result"
+	ShareResourceDashboard   ShareResourceType = "dashboard"
+)
+
+// ShareLink exposes a query result or a dashboard read-only at
+// GET /public/shares/{token} to anyone holding the token. Only the SHA-256
+// hash of the token is stored; the URL is returned once on creation. Links
+// expire, can be revoked and may require a password.
+type ShareLink struct {
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	UserID       uint              `json:"user_id" gorm:"not null;index"` // Who shared it
+	TenantID     uint              `json:"-" gorm:"not null;default:1;index"`
+	ResourceType ShareResourceType `json:"resource_type" gorm:"not null"`
+	QueryID      *uint             `json:"query_id,omitempty" gorm:"index"`
+	ResultID     *uint             `json:"result_id,omitempty"` // Result pinned when the link was created
+	DashboardID  *uint             `json:"dashboard_id,omitempty" gorm:"index"`
+	Title        string            `json:"title"`
+	TokenPrefix  string            `json:"token_prefix" gorm:"not null"`
+	TokenHash    string            `json:"-" gorm:"not null;uniqueIndex"`
+	PasswordHash string            `json:"-"`
+	RowLimit     int               `json:"row_limit" gorm:"not null"` // Rows shown per result
+	ExpiresAt    time.Time         `json:"expires_at" gorm:"not null"`
+	RevokedAt    *time.Time        `json:"revoked_at,omitempty"`
+	ViewCount    int64             `json:"view_count" gorm:"not null;default:0"`
+	LastViewedAt *time.Time        `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// ShareLinkRequest shares a query result or a dashboard. A query share pins
+// result_id, or the latest result of the query when it is not set.
+type ShareLinkRequest struct {
+	ResourceType   ShareResourceType `json:"resource_type" validate:"required,oneof=query_result dashboard"`
+	QueryID        uint              `json:"query_id,omitempty" validate:"required_if=ResourceType query_result"`
+	ResultID       uint              `json:"result_id,omitempty"`
+	DashboardID    uint              `json:"dashboard_id,omitempty" validate:"required_if=ResourceType dashboard"`
+	Title          string            `json:"title,omitempty" validate:"max=200"`
+	Password       string            `json:"password,omitempty" validate:"omitempty,min=6,max=72"`
+	RowLimit       int               `json:"row_limit,omitempty" validate:"omitempty,min=1,max=1000"`        // Default 100
+	ExpiresInHours int               `json:"expires_in_hours,omitempty" validate:"omitempty,min=1,max=2160"` // Default 7 days
+}
+
+// ShareLinkResponse is the management view of a share link
+type ShareLinkResponse struct {
+	ShareLink
+	HasPassword bool   `json:"has_password"`
+	Active      bool   `json:"active"`        // Neither expired nor revoked
+	URL         string `json:"url,omitempty"` // Only returned on creation
+}
+
+// SharedResult is a read-only snapshot of a query result
+type SharedResult struct {
+	Question      string                   `json:"question"`
+	Columns       []Column                 `json:"columns"`
+	Data          []map[string]interface{} `json:"data"`
+	TotalRows     int64                    `json:"total_rows"`
+	Truncated     bool                     `json:"truncated"`
+	MaskedColumns []string                 `json:"masked_columns,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"`
+	Freshness     *DataFreshness           `json:"freshness,omitempty"` // Set for widgets bound to an extract
+}
+
+// SharedWidget is a dashboard widget with the result of its query
+type SharedWidget struct {
+	ID     uint            `json:"id"`
+	Type   WidgetType      `json:"type"`
+	Title  string          `json:"title"`
+	Config json.RawMessage `json:"config,omitempty"`
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Result *SharedResult   `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"` // Why the result could not be shown
+}
+
+// SharedDashboard is a read-only snapshot of a dashboard
+type SharedDashboard struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Widgets     []SharedWidget `json:"widgets"`
+}
+
+// SharedResourceResponse is what GET /public/shares/{token} returns
+type SharedResourceResponse struct {
+	ResourceType ShareResourceType `json:"resource_type"`
+	Title        string            `json:"title"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	Result       *SharedResult     `json:"result,omitempty"`
+	Dashboard    *SharedDashboard  `json:"dashboard,omitempty"`
+}
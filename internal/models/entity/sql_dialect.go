@@ -0,0 +1,51 @@
+package models
+
+// SQLDialect identifies the SQL flavour a data source understands
+type SQLDialect string
+
+const (
+	SQLDialectPostgreSQL SQLDialect = "postgresql"
+	SQLDialectBigQuery   SQLDialect = "bigquery"
+	SQLDialectDuckDB     SQLDialect = "duckdb"
+	// SQLDialectMongoDB marks aggregation pipelines; it is not accepted by the SQL validator
+	SQLDialectMongoDB SQLDialect = "mongodb"
+)
+
+// DialectForDataSourceType returns the dialect used to query a data source type.
+// File and sheet sources, and REST APIs materialized to files, are queried through DuckDB.
+func DialectForDataSourceType(dsType DataSourceType) SQLDialect {
+	switch dsType {
+	case DataSourceTypeBigQuery, DataSourceTypeGA4:
+		return SQLDialectBigQuery
+	case DataSourceTypeCSV, DataSourceTypeExcel, DataSourceTypeGoogleSheets, DataSourceTypeRESTAPI, DataSourceTypeGoogleDrive:
+		return SQLDialectDuckDB
+	case DataSourceTypeMongoDB:
+		return SQLDialectMongoDB
+	default:
+		return SQLDialectPostgreSQL
+	}
+}
+
+// IsValid checks if the dialect is supported
+func (d SQLDialect) IsValid() bool {
+	switch d {
+	case SQLDialectPostgreSQL, SQLDialectBigQuery, SQLDialectDuckDB:
+		return true
+	default:
+		return false
+	}
+}
+
+// DisplayName returns the human readable name of the dialect
+func (d SQLDialect) DisplayName() string {
+	switch d {
+	case SQLDialectBigQuery:
+		return "BigQuery Standard SQL"
+	case SQLDialectDuckDB:
+		return "DuckDB"
+	case SQLDialectMongoDB:
+		return "MongoDB aggregation pipeline"
+	default:
+		return "PostgreSQL"
+	}
+}
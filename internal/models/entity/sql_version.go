@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+)
+
+// SQLVersionSource records what produced a version of a query's SQL
+type SQLVersionSource string
+
+const (
+	SQLVersionSourceGenerated  SQLVersionSource = "generated"   // Produced by NL2SQL generation
+	SQLVersionSourceAutoRepair SQLVersionSource = "auto_repair" // Rewritten automatically after a failure
+	SQLVersionSourceHumanEdit  SQLVersionSource = "human_edit"  // Edited by a user
+	SQLVersionSourceRollback   SQLVersionSource = "rollback"    // Restored from an earlier version
+	SQLVersionSourceSuggestion SQLVersionSource = "suggestion"  // Suggested through a collaboration link and accepted by the owner
+	SQLVersionSourceSavedQuery SQLVersionSource = "saved_query" // Pinned SQL of a saved query, run again
+	SQLVersionSourceRefinement SQLVersionSource = "refinement"  // Regenerated from the previous SQL with a correction hint
+)
+
+// QuerySQLVersion is an immutable snapshot of a query's SQL. Versions are
+// append-only: a rollback adds a new version with the restored SQL.
+type QuerySQLVersion struct {
+	ID             uint             `json:"id" gorm:"primaryKey"`
+	TenantID       uint             `json:"-" gorm:"not null;default:1;index"`
+	QueryID        uint             `json:"query_id" gorm:"not null;uniqueIndex:idx_query_sql_versions_query_version"`
+	Version        int              `json:"version" gorm:"not null;uniqueIndex:idx_query_sql_versions_query_version"`
+	SQL            string           `json:"sql" gorm:"column:sql;type:text;not null"`
+	Source         SQLVersionSource `json:"source" gorm:"not null"`
+	RolledBackFrom int              `json:"rolled_back_from,omitempty"` // Version restored by a rollback
+	Comment        string           `json:"comment,omitempty"`
+	Canary         JSON             `json:"canary,omitempty" gorm:"type:jsonb"` // CanaryReport when the version replaced SQL used by dashboards
+	CreatedBy      uint             `json:"created_by"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// Request/Response DTOs
+
+// QuerySQLUpdateRequest replaces the SQL of a query with a human edit
+type QuerySQLUpdateRequest struct {
+	SQL     string `json:"sql" validate:"required"`
+	Comment string `json:"comment,omitempty" validate:"max=500"`
+	Confirm bool   `json:"confirm,omitempty"` // Apply even when the canary run diverges
+}
+
+// QuerySQLRollbackRequest restores an earlier version
+type QuerySQLRollbackRequest struct {
+	Comment string `json:"comment,omitempty" validate:"max=500"`
+	Confirm bool   `json:"confirm,omitempty"` // Apply even when the canary run diverges
+}
+
+// QuerySQLRefineRequest regenerates the SQL of a query with a correction hint,
+// e.g. "use the orders table, not sales" or "group by month"
+type QuerySQLRefineRequest struct {
+	Hint    string `json:"hint" validate:"required,max=1000"`
+	Confirm bool   `json:"confirm,omitempty"` // Apply even when the canary run diverges
+}
+
+// QuerySQLVersionResponse is returned after an edit or rollback
+type QuerySQLVersionResponse struct {
+	QueryID    uint                `json:"query_id"`
+	Version    QuerySQLVersion     `json:"version"`
+	Validation SQLValidationResult `json:"validation"`
+	CanExecute bool                `json:"can_execute"`
+	Canary     *CanaryReport       `json:"canary,omitempty"`
+}
+
+// QuerySQLRefineResponse is the version produced by a refinement with its diff
+// against the SQL it replaced
+type QuerySQLRefineResponse struct {
+	QuerySQLVersionResponse
+	Diff *SQLVersionDiff `json:"diff"`
+}
+
+// CanaryResultSummary describes the result of one side of a canary run
+type CanaryResultSummary struct {
+	Columns  []Column           `json:"columns"`
+	RowCount int                `json:"row_count"`
+	Totals   map[string]float64 `json:"totals"` // Sum of every numeric column
+	Error    string             `json:"error,omitempty"`
+}
+
+// CanaryReport compares the results of the current and the edited SQL of a
+// query that dashboards depend on. A diverging edit needs explicit confirmation.
+type CanaryReport struct {
+	Threshold   float64             `json:"threshold"`
+	Widgets     int64               `json:"widgets"` // Dashboard widgets showing the query
+	Diverged    bool                `json:"diverged"`
+	Confirmed   bool                `json:"confirmed"`
+	Differences []string            `json:"differences,omitempty"`
+	Previous    CanaryResultSummary `json:"previous"`
+	Candidate   CanaryResultSummary `json:"candidate"`
+}
+
+// SQLDiffOp is the operation of a diff line
+type SQLDiffOp string
+
+const (
+	SQLDiffEqual  SQLDiffOp = "equal"
+	SQLDiffInsert SQLDiffOp = "insert"
+	SQLDiffDelete SQLDiffOp = "delete"
+)
+
+// SQLDiffLine is one line of a diff between two SQL versions
+type SQLDiffLine struct {
+	Op   SQLDiffOp `json:"op"`
+	Text string    `json:"text"`
+}
+
+// SQLVersionDiff is the line diff between two versions of a query's SQL
+type SQLVersionDiff struct {
+	QueryID     uint          `json:"query_id"`
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Unified     string        `json:"unified"`
+	Lines       []SQLDiffLine `json:"lines"`
+}
+
+// QueryLineageVersion is a SQL version of a query in a lineage
+type QueryLineageVersion struct {
+	Version       int              `json:"version"`
+	ParentVersion int              `json:"parent_version,omitempty"` // Version it replaced, or restored by a rollback
+	Source        SQLVersionSource `json:"source"`
+	SQL           string           `json:"sql"`
+	Comment       string           `json:"comment,omitempty"`
+	Current       bool             `json:"current"`
+	CreatedBy     uint             `json:"created_by"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// QueryLineageNode is a query in a lineage tree with its SQL versions and the
+// queries derived from it
+type QueryLineageNode struct {
+	QueryID          uint                  `json:"query_id"`
+	ParentQueryID    *uint                 `json:"parent_query_id,omitempty"`
+	ParentSQLVersion int                   `json:"parent_sql_version,omitempty"`
+	NLQuery          string                `json:"nl_query"`
+	Status           QueryStatus           `json:"status"`
+	CurrentVersion   int                   `json:"current_version"`
+	CreatedAt        time.Time             `json:"created_at"`
+	Versions         []QueryLineageVersion `json:"versions"`
+	Children         []QueryLineageNode    `json:"children"`
+}
+
+// QueryLineage is the tree of queries a query belongs to, from its oldest
+// ancestor down. Any version can be made current again with a rollback.
+type QueryLineage struct {
+	QueryID uint             `json:"query_id"` // The query the lineage was requested for
+	Root    QueryLineageNode `json:"root"`
+}
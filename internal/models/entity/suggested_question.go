@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Where a suggested question comes from
+const (
+	SuggestionSourceCurated  = "curated"  // Added by the data source owner
+	SuggestionSourceFrequent = "frequent" // Asked often, grouped with similar questions
+	SuggestionSourceRecent   = "recent"   // Recently converted to valid SQL
+)
+
+// SuggestedQuestion is an example question curated for a data source
+type SuggestedQuestion struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TenantID     uint      `json:"-" gorm:"not null;default:1;index"`
+	DataSourceID uint      `json:"data_source_id" gorm:"not null;index"`
+	Question     string    `json:"question" gorm:"type:text;not null"`
+	Position     int       `json:"position" gorm:"not null;default:0"` // Curated questions are suggested in ascending position
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Request/Response DTOs
+
+// SuggestedQuestionRequest curates an example question
+type SuggestedQuestionRequest struct {
+	Question string `json:"question" validate:"required,min=3,max=500"`
+	Position int    `json:"position" validate:"min=0"`
+}
+
+// QuestionSuggestion is a question suggested to seed the ask-a-question UI
+type QuestionSuggestion struct {
+	Question  string     `json:"question"`
+	Source    string     `json:"source"`               // curated, frequent or recent
+	ID        *uint      `json:"id,omitempty"`         // Of curated questions
+	QueryID   *uint      `json:"query_id,omitempty"`   // Latest query of mined questions
+	Asked     int        `json:"asked,omitempty"`      // Similar questions asked, of frequent ones
+	LastAsked *time.Time `json:"last_asked,omitempty"` // Of mined questions
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DefaultTenantID is the tenant of single-tenant installations. Rows created
+// before multi-tenancy and tokens issued without a tenant belong to it, and its
+// admins administer the installation.
+const DefaultTenantID uint = 1
+
+// TenantModels returns the models whose rows belong to a tenant. Their tables
+// have a tenant_id column; statements on them in the context of a tenant are
+// scoped to its rows, and raw SQL on them is refused there. The other tables
+// belong to the installation: tenants, feature flags, MFA settings, data
+// source templates, the RBAC rules, sync locks and states, analytics and
+// rate limit counters, and the compliance webhook cursor.
+func TenantModels() []interface{} {
+	return []interface{}{
+		&AlertRule{}, &AuditLog{}, &BigQueryServiceAccount{}, &BusinessGlossary{}, &ChatIntegration{},
+		&ColumnMetadata{}, &ConnectionHealthLog{}, &CustomSQLFunction{}, &Dashboard{}, &DashboardCollaborator{},
+		&DashboardWidget{}, &DataAPIEndpoint{}, &DataAPIKey{}, &DataSource{}, &DataSourceDiscoveryEvent{},
+		&DataSourceMaterialization{}, &DigestSubscription{}, &EvalResult{}, &EvalRun{}, &Extract{},
+		&FileUpload{}, &FileUploadPart{}, &GoldenQuery{}, &GovernanceEvent{}, &Job{},
+		&KPIDefinition{}, &KPISnapshot{}, &MFARecoveryCode{}, &NL2SQLQuery{}, &PromptTemplate{},
+		&QueryAnalyticsDaily{}, &QueryCollaborationEvent{}, &QueryCollaborationLink{}, &QueryCostCeiling{}, &QueryResult{},
+		&QueryResultChunk{}, &QuerySQLSuggestion{}, &QuerySQLVersion{}, &RAGQueryContext{}, &Report{},
+		&ReportRun{}, &RetrievalConfig{}, &SavedQuery{}, &Schema{}, &SchemaChange{},
+		&SchemaEmbedding{}, &SchemaProfile{}, &SchemaSyncRun{}, &SemanticDimension{}, &SemanticMetric{},
+		&ShareLink{}, &SuggestedQuestion{}, &UsageQuota{}, &UsageRecord{}, &User{},
+		&UserMFA{}, &ValidationPolicy{}, &Webhook{}, &WebhookDelivery{},
+	}
+}
+
+// Features tenants can be switched off for. Features are on unless the
+// tenant's flags turn them off.
+const (
+	TenantFeatureGraphQL       = "graphql"                // GraphQL API
+	TenantFeatureNotifications = "realtime_notifications" // WebSocket notifications
+)
+
+// TenantFeatures are the per-tenant feature flags, by feature name
+type TenantFeatures map[string]bool
+
+// Scan implements the Scanner interface for database/sql
+func (f *TenantFeatures) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+	switch s := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(s), f)
+	case []byte:
+		return json.Unmarshal(s, f)
+	default:
+		return errors.New("cannot scan into TenantFeatures")
+	}
+}
+
+// Value implements the driver Valuer interface
+func (f TenantFeatures) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(f)
+	return string(data), err
+}
+
+// Tenant is an organization whose users and data are isolated from the other
+// tenants. Requests are in the tenant of the user's token; a request to the
+// domain of another tenant is refused.
+type Tenant struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"not null"`
+	Slug      string         `json:"slug" gorm:"not null;uniqueIndex"`
+	Domain    *string        `json:"domain,omitempty" gorm:"uniqueIndex"` // Host the tenant is served on, e.g. acme.narapulse.com
+	Features  TenantFeatures `json:"features" gorm:"type:jsonb;not null;default:'{}'"`
+	IsActive  bool           `json:"is_active" gorm:"not null;default:true"` // Requests of inactive tenants are refused
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// FeatureEnabled reports whether the feature is on for the tenant
+func (t *Tenant) FeatureEnabled(feature string) bool {
+	enabled, ok := t.Features[feature]
+	return !ok || enabled
+}
+
+// Request/Response DTOs
+
+// TenantCreateRequest creates a tenant
+type TenantCreateRequest struct {
+	Name     string         `json:"name" validate:"required,max=200"`
+	Slug     string         `json:"slug" validate:"required,max=63,hostname_rfc1123"`
+	Domain   string         `json:"domain,omitempty" validate:"omitempty,fqdn"`
+	Features TenantFeatures `json:"features,omitempty"`
+}
+
+// TenantUpdateRequest changes a tenant; omitted fields are kept. An empty
+// domain removes it; features replace the tenant's flags.
+type TenantUpdateRequest struct {
+	Name     *string        `json:"name" validate:"omitempty,max=200"`
+	Domain   *string        `json:"domain" validate:"omitempty,fqdn"`
+	Features TenantFeatures `json:"features"`
+	IsActive *bool          `json:"is_active"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestTenantModelsAreIsolated(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: TenantModels()}))
+
+	acme := tenancy.WithTenant(context.Background(), 1)
+	globex := tenancy.WithTenant(context.Background(), 2)
+
+	for _, model := range TenantModels() {
+		modelType := reflect.TypeOf(model).Elem()
+		t.Run(modelType.Name(), func(t *testing.T) {
+			require.NoError(t, db.AutoMigrate(model))
+			stmt := &gorm.Statement{DB: db}
+			require.NoError(t, stmt.Parse(model))
+			table := stmt.Schema.Table
+
+			row := reflect.New(modelType).Interface()
+			require.NoError(t, db.WithContext(acme).Create(row).Error)
+
+			var count int64
+			require.NoError(t, db.WithContext(acme).Model(model).Count(&count).Error)
+			assert.Equal(t, int64(1), count, "the tenant reads its row")
+
+			require.NoError(t, db.WithContext(globex).Model(model).Count(&count).Error)
+			assert.Zero(t, count, "another tenant reads nothing")
+			rows := reflect.New(reflect.SliceOf(modelType)).Interface()
+			require.NoError(t, db.WithContext(globex).Unscoped().Find(rows).Error)
+			assert.Zero(t, reflect.ValueOf(rows).Elem().Len(), "another tenant finds nothing, even unscoped")
+
+			assert.ErrorIs(t, db.WithContext(globex).Raw("SELECT COUNT(*) FROM "+table).Scan(&count).Error, tenancy.ErrRawSQL)
+			assert.ErrorIs(t, db.WithContext(globex).Exec("DELETE FROM "+table).Error, tenancy.ErrRawSQL)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// UsageKind is the kind of AI call a usage record is for
+type UsageKind string
+
+const (
+	UsageKindEmbedding UsageKind = "embedding"
+	UsageKindLLM       UsageKind = "llm"
+)
+
+// UsageRecord is the token usage and estimated cost of one embedding or LLM call.
+// UserID is 0 for calls made outside a user request, such as background syncs.
+type UsageRecord struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           uint      `json:"user_id" gorm:"not null;index:idx_usage_records_user_created"`
+	TenantID         uint      `json:"-" gorm:"not null;default:1;index"`
+	Kind             UsageKind `json:"kind" gorm:"not null"`
+	Model            string    `json:"model" gorm:"not null"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	CostUSD          float64   `json:"cost_usd" gorm:"column:cost_usd"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index:idx_usage_records_user_created"`
+}
+
+// UsageQuota overrides the default monthly AI quota of a user. Zero limits are not enforced.
+type UsageQuota struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	UserID         uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+	TenantID       uint      `json:"-" gorm:"not null;default:1;index"`
+	MonthlyTokens  int64     `json:"monthly_tokens"`
+	MonthlyCostUSD float64   `json:"monthly_cost_usd" gorm:"column:monthly_cost_usd"`
+	UpdatedBy      uint      `json:"updated_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name, which does not pluralize quota
+func (UsageQuota) TableName() string {
+	return "usage_quotas"
+}
+
+// Request/Response DTOs
+
+// UsageQuotaRequest sets the monthly AI quota of a user; zero disables a limit
+type UsageQuotaRequest struct {
+	MonthlyTokens  int64   `json:"monthly_tokens" validate:"min=0"`
+	MonthlyCostUSD float64 `json:"monthly_cost_usd" validate:"min=0"`
+}
+
+// UsageDailyTotal aggregates the AI usage of one day and kind, per user for workspace reports
+type UsageDailyTotal struct {
+	Date        string    `json:"date"` // YYYY-MM-DD (UTC)
+	UserID      uint      `json:"user_id,omitempty"`
+	Kind        UsageKind `json:"kind"`
+	Calls       int64     `json:"calls"`
+	TotalTokens int64     `json:"total_tokens"`
+	CostUSD     float64   `json:"cost_usd"`
+}
+
+// UsageQuotaStatus is the month-to-date usage of a user against the effective quota
+type UsageQuotaStatus struct {
+	MonthlyTokens  int64     `json:"monthly_tokens"`   // 0 means unlimited
+	MonthlyCostUSD float64   `json:"monthly_cost_usd"` // 0 means unlimited
+	UsedTokens     int64     `json:"used_tokens"`
+	UsedCostUSD    float64   `json:"used_cost_usd"`
+	Exceeded       bool      `json:"exceeded"`
+	PeriodStart    time.Time `json:"period_start"`
+	Custom         bool      `json:"custom"` // The user has a quota of their own instead of the default
+}
+
+// UsageSummary is the daily AI usage of a user over a period with the quota status
+type UsageSummary struct {
+	From   time.Time         `json:"from"`
+	To     time.Time         `json:"to"`
+	Daily  []UsageDailyTotal `json:"daily"`
+	Totals []UsageDailyTotal `json:"totals"` // Per kind over the period; Date is empty
+	Quota  UsageQuotaStatus  `json:"quota"`
+}
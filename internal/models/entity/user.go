@@ -8,6 +8,7 @@ import (
 
 type User struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  uint           `json:"-" gorm:"not null;default:1;index"`
 	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
 	Username  string         `json:"username" gorm:"uniqueIndex;not null"`
 	Password  string         `json:"-" gorm:"not null"`
@@ -34,6 +35,37 @@ type UserUpdateRequest struct {
 	Email     string `json:"email" validate:"email"`
 }
 
+type UserRoleUpdateRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin user"`
+}
+
+// UserAdminUpdateRequest changes the role or active state of a user; omitted fields are kept
+type UserAdminUpdateRequest struct {
+	Role     *string `json:"role" validate:"omitempty,oneof=admin user"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// UserBulkDeactivateRequest deactivates several users at once
+type UserBulkDeactivateRequest struct {
+	UserIDs []uint `json:"user_ids" validate:"required,min=1,max=500"`
+}
+
+// UserBulkDeactivateResponse reports how many users were deactivated
+type UserBulkDeactivateResponse struct {
+	Deactivated int64 `json:"deactivated"`
+}
+
+// UserListFilter selects and orders a page of users; zero values match everything
+type UserListFilter struct {
+	Search   string // Matched against email, username, first and last name
+	Role     string
+	IsActive *bool
+	Sort     string // created_at, email, username, first_name, last_name or role
+	Order    string // asc or desc
+	Page     int
+	Limit    int
+}
+
 type UserResponse struct {
 	ID        uint      `json:"id"`
 	Email     string    `json:"email"`
@@ -51,7 +83,14 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// LoginResponse carries the access token, unless the login needs a second
+// step: with MFARequired the MFA token and a code complete it at
+// /auth/mfa/verify; with EnrollmentRequired the MFA token authorizes setting up
+// MFA, which then completes it.
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token              string       `json:"token,omitempty"`
+	MFARequired        bool         `json:"mfa_required,omitempty"`
+	EnrollmentRequired bool         `json:"enrollment_required,omitempty"`
+	MFAToken           string       `json:"mfa_token,omitempty"`
+	User               UserResponse `json:"user"`
 }
\ No newline at end of file
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DefaultLargeTableRowThreshold is the row count above which a table counts as
+// large when a policy requires WHERE clauses on large tables
+const DefaultLargeTableRowThreshold = 1000000
+
+// ValidationPolicy customizes SQL validation for a data source. The policy
+// without a data source is the workspace default, used by data sources that
+// have no policy of their own. Lists extend the built-in validator rules.
+type ValidationPolicy struct {
+	ID                     uint      `json:"id" gorm:"primaryKey"`
+	TenantID               uint      `json:"-" gorm:"not null;default:1;index;uniqueIndex:idx_validation_policies_default,where:data_source_id IS NULL"`
+	DataSourceID           *uint     `json:"data_source_id,omitempty" gorm:"uniqueIndex"`
+	Name                   string    `json:"name" gorm:"not null"`
+	AllowedFunctions       JSON      `json:"allowed_functions" gorm:"type:jsonb"` // Functions allowed in addition to the defaults
+	BlockedFunctions       JSON      `json:"blocked_functions" gorm:"type:jsonb"` // Functions blocked even if allowed by default
+	BlockedKeywords        JSON      `json:"blocked_keywords" gorm:"type:jsonb"`  // Keywords blocked in addition to the defaults
+	BlockedPatterns        JSON      `json:"blocked_patterns" gorm:"type:jsonb"`  // Case-insensitive regular expressions
+	MaxJoins               int       `json:"max_joins"`                           // 0 keeps the default (warning only)
+	MaxLimit               int       `json:"max_limit"`                           // 0 keeps the default
+	RequireWhereOnLarge    bool      `json:"require_where_on_large_tables" gorm:"column:require_where_on_large_tables"`
+	LargeTableRowThreshold int64     `json:"large_table_row_threshold"`
+	UpdatedBy              uint      `json:"updated_by"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// GetStringList decodes a JSON list field of the policy
+func (p *ValidationPolicy) GetStringList(field JSON) []string {
+	var values []string
+	if len(field) > 0 {
+		json.Unmarshal(field, &values)
+	}
+	if values == nil {
+		values = []string{}
+	}
+	return values
+}
+
+// ToResponse converts ValidationPolicy to ValidationPolicyResponse
+func (p *ValidationPolicy) ToResponse() *ValidationPolicyResponse {
+	return &ValidationPolicyResponse{
+		ID:                     p.ID,
+		DataSourceID:           p.DataSourceID,
+		Name:                   p.Name,
+		AllowedFunctions:       p.GetStringList(p.AllowedFunctions),
+		BlockedFunctions:       p.GetStringList(p.BlockedFunctions),
+		BlockedKeywords:        p.GetStringList(p.BlockedKeywords),
+		BlockedPatterns:        p.GetStringList(p.BlockedPatterns),
+		MaxJoins:               p.MaxJoins,
+		MaxLimit:               p.MaxLimit,
+		RequireWhereOnLarge:    p.RequireWhereOnLarge,
+		LargeTableRowThreshold: p.LargeTableRowThreshold,
+		UpdatedBy:              p.UpdatedBy,
+		CreatedAt:              p.CreatedAt,
+		UpdatedAt:              p.UpdatedAt,
+	}
+}
+
+// Request/Response DTOs
+
+// ValidationPolicyRequest creates or replaces a validation policy
+type ValidationPolicyRequest struct {
+	DataSourceID           *uint    `json:"data_source_id,omitempty"` // Omit for the workspace default policy
+	Name                   string   `json:"name" validate:"required,min=1,max=100"`
+	AllowedFunctions       []string `json:"allowed_functions,omitempty"`
+	BlockedFunctions       []string `json:"blocked_functions,omitempty"`
+	BlockedKeywords        []string `json:"blocked_keywords,omitempty"`
+	BlockedPatterns        []string `json:"blocked_patterns,omitempty"`
+	MaxJoins               int      `json:"max_joins" validate:"min=0,max=50"`
+	MaxLimit               int      `json:"max_limit" validate:"min=0,max=1000000"`
+	RequireWhereOnLarge    bool     `json:"require_where_on_large_tables"`
+	LargeTableRowThreshold int64    `json:"large_table_row_threshold" validate:"min=0"`
+}
+
+// ValidationPolicyResponse is the API view of a validation policy
+type ValidationPolicyResponse struct {
+	ID                     uint      `json:"id"`
+	DataSourceID           *uint     `json:"data_source_id,omitempty"`
+	Name                   string    `json:"name"`
+	AllowedFunctions       []string  `json:"allowed_functions"`
+	BlockedFunctions       []string  `json:"blocked_functions"`
+	BlockedKeywords        []string  `json:"blocked_keywords"`
+	BlockedPatterns        []string  `json:"blocked_patterns"`
+	MaxJoins               int       `json:"max_joins"`
+	MaxLimit               int       `json:"max_limit"`
+	RequireWhereOnLarge    bool      `json:"require_where_on_large_tables"`
+	LargeTableRowThreshold int64     `json:"large_table_row_threshold"`
+	UpdatedBy              uint      `json:"updated_by"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
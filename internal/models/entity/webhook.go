@@ -0,0 +1,155 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEvent is a platform event webhooks can subscribe to
+type WebhookEvent string
+
+const (
+	WebhookEventQueryCompleted  WebhookEvent = "query.completed"  // A query ran successfully
+	WebhookEventDataSourceError WebhookEvent = "datasource.error" // A data source connection started failing
+	WebhookEventSchemaChanged   WebhookEvent = "schema.changed"   // Rediscovery found schema changes
+	WebhookEventAlertTriggered  WebhookEvent = "alert.triggered"  // An alert rule crossed its threshold
+	WebhookEventPing            WebhookEvent = "ping"             // Sent on demand to test a webhook
+)
+
+// WebhookEvents are the events webhooks can subscribe to
+var WebhookEvents = []WebhookEvent{
+	WebhookEventQueryCompleted,
+	WebhookEventDataSourceError,
+	WebhookEventSchemaChanged,
+	WebhookEventAlertTriggered,
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Queued, not attempted yet
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded" // The endpoint answered with a 2xx status
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // The last attempt failed; retried with backoff
+)
+
+// Webhook is a workspace-level subscription of an external endpoint to the
+// platform events of its tenant. Deliveries are signed with the secret.
+type Webhook struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  uint           `json:"-" gorm:"not null;default:1;index"`
+	Name      string         `json:"name" gorm:"not null"`
+	URL       string         `json:"url" gorm:"type:text;not null"`
+	Secret    string         `json:"-" gorm:"not null"`   // HMAC-SHA256 signing key
+	Events    JSON           `json:"-" gorm:"type:jsonb"` // []WebhookEvent
+	CreatedBy uint           `json:"created_by"`
+	IsActive  bool           `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// WebhookDelivery is an event sent to a webhook and the outcome of its latest attempt
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	TenantID       uint                  `json:"-" gorm:"not null;default:1;index"`
+	WebhookID      uint                  `json:"webhook_id" gorm:"not null;index"`
+	EventID        string                `json:"event_id" gorm:"not null"` // Shared by the deliveries of one event
+	Event          WebhookEvent          `json:"event" gorm:"not null"`
+	Payload        JSON                  `json:"-" gorm:"type:jsonb"` // Body posted to the endpoint
+	Status         WebhookDeliveryStatus `json:"status" gorm:"not null;index"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	ResponseBody   string                `json:"response_body,omitempty" gorm:"type:text"` // Truncated
+	Error          string                `json:"error,omitempty" gorm:"type:text"`
+	DurationMs     int64                 `json:"duration_ms"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"index"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// Request/Response DTOs
+
+// WebhookRequest creates or replaces a webhook. An empty secret is generated
+// on create and kept on update.
+type WebhookRequest struct {
+	Name     string         `json:"name" validate:"required,max=255"`
+	URL      string         `json:"url" validate:"required,url,startswith=https://"`
+	Secret   string         `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	Events   []WebhookEvent `json:"events" validate:"required,min=1,dive,oneof=query.completed datasource.error schema.changed alert.triggered"`
+	IsActive *bool          `json:"is_active,omitempty"` // Defaults to true
+}
+
+// WebhookResponse is a webhook; the secret is only returned when it was
+// generated or changed
+type WebhookResponse struct {
+	Webhook
+	Events []WebhookEvent `json:"events"`
+	Secret string         `json:"secret,omitempty"`
+}
+
+// WebhookDeliveryResponse is a delivery with the body that was posted
+type WebhookDeliveryResponse struct {
+	WebhookDelivery
+	Payload json.RawMessage `json:"payload"`
+}
+
+// WebhookPayload is the body posted for an event
+type WebhookPayload struct {
+	ID        string       `json:"id"` // Event ID, the same on retries
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"created_at"`
+	Data      interface{}  `json:"data"`
+}
+
+// WebhookDeliveryJobPayload is the payload of the job that sends a delivery
+type WebhookDeliveryJobPayload struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// QueryCompletedEvent is the data of query.completed events
+type QueryCompletedEvent struct {
+	QueryID       uint   `json:"query_id"`
+	ResultID      uint   `json:"result_id,omitempty"`
+	UserID        uint   `json:"user_id"`
+	DataSourceID  uint   `json:"data_source_id"`
+	NLQuery       string `json:"nl_query"`
+	RowCount      int64  `json:"row_count"`
+	ExecutionTime int64  `json:"execution_time_ms"`
+}
+
+// DataSourceErrorEvent is the data of datasource.error events
+type DataSourceErrorEvent struct {
+	DataSourceID uint      `json:"data_source_id"`
+	UserID       uint      `json:"user_id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Error        string    `json:"error"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// SchemaChangedEvent is the data of schema.changed events
+type SchemaChangedEvent struct {
+	ChangeID        uint       `json:"change_id"`
+	DataSourceID    uint       `json:"data_source_id"`
+	UserID          uint       `json:"user_id"`
+	Name            string     `json:"name"`
+	Diff            SchemaDiff `json:"diff"`
+	AffectedQueries int        `json:"affected_queries"`
+	AffectedKPIs    int        `json:"affected_kpis"`
+}
+
+// AlertTriggeredEvent is the data of alert.triggered events
+type AlertTriggeredEvent struct {
+	AlertRuleID uint           `json:"alert_rule_id"`
+	UserID      uint           `json:"user_id"`
+	Name        string         `json:"name"`
+	KPIID       uint           `json:"kpi_id"`
+	KPI         string         `json:"kpi"`
+	Condition   AlertCondition `json:"condition"`
+	Threshold   float64        `json:"threshold"`
+	Value       float64        `json:"value"`
+	TriggeredAt time.Time      `json:"triggered_at"`
+}
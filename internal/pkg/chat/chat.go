@@ -0,0 +1,231 @@
+// Package chat posts messages to Slack and Microsoft Teams and verifies the
+// signatures of the commands they send. Services depend on the Poster
+// interface; Slack is reached through an incoming webhook or a bot token,
+// Teams through an incoming webhook.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature is returned when a command is not signed with the shared secret
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// requestTimeout bounds a request to a chat platform
+const requestTimeout = 10 * time.Second
+
+// slackSignatureMaxAge is how old a signed Slack request may be, against replays
+const slackSignatureMaxAge = 5 * time.Minute
+
+// SlackAPIURL is the Slack Web API endpoint bot token posters use
+var SlackAPIURL = "https://slack.com/api/chat.postMessage"
+
+// Message is a chat message: text with an optional table shown in a
+// fixed-width font
+type Message struct {
+	Text  string
+	Table []string // Lines of a table laid out in fixed-width columns
+}
+
+// Poster posts messages to a chat channel
+type Poster interface {
+	// Post posts the message; channel overrides the default channel where the
+	// destination allows it
+	Post(ctx context.Context, channel string, msg Message) error
+}
+
+// SlackWebhook posts to the channel of a Slack incoming webhook
+type SlackWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackWebhook creates a poster for a Slack incoming webhook URL
+func NewSlackWebhook(url string) *SlackWebhook {
+	return &SlackWebhook{url: url, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Post posts the message; incoming webhooks always post to their own channel
+func (w *SlackWebhook) Post(ctx context.Context, _ string, msg Message) error {
+	return postJSON(ctx, w.client, w.url, SlackPayload(msg, ""))
+}
+
+// SlackApp posts through the Slack Web API with a bot token, to any channel
+// the app was invited to
+type SlackApp struct {
+	token          string
+	defaultChannel string
+	client         *http.Client
+}
+
+// NewSlackApp creates a poster for a Slack bot token
+func NewSlackApp(token, defaultChannel string) *SlackApp {
+	return &SlackApp{token: token, defaultChannel: defaultChannel, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Post posts the message to the channel, or the default channel
+func (a *SlackApp) Post(ctx context.Context, channel string, msg Message) error {
+	if channel == "" {
+		channel = a.defaultChannel
+	}
+	if channel == "" {
+		return fmt.Errorf("no Slack channel given")
+	}
+
+	payload := SlackPayload(msg, "")
+	payload["channel"] = channel
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, SlackAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+a.token)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The Web API reports errors in the body with a 200 status
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to read Slack response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("Slack rejected the message: %s", result.Error)
+	}
+	return nil
+}
+
+// TeamsWebhook posts to the channel of a Microsoft Teams incoming webhook
+type TeamsWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewTeamsWebhook creates a poster for a Teams incoming webhook URL
+func NewTeamsWebhook(url string) *TeamsWebhook {
+	return &TeamsWebhook{url: url, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Post posts the message; incoming webhooks always post to their own channel
+func (w *TeamsWebhook) Post(ctx context.Context, _ string, msg Message) error {
+	return postJSON(ctx, w.client, w.url, TeamsPayload(msg))
+}
+
+// SlackPayload is the Slack message body of a message, with the table in a
+// code block. responseType is set for command replies: in_channel or ephemeral.
+func SlackPayload(msg Message, responseType string) map[string]interface{} {
+	text := msg.Text
+	if len(msg.Table) > 0 {
+		text += "\n```\n" + strings.Join(msg.Table, "\n") + "\n```"
+	}
+	payload := map[string]interface{}{"text": text, "mrkdwn": true}
+	if responseType != "" {
+		payload["response_type"] = responseType
+	}
+	return payload
+}
+
+// TeamsPayload is the Teams message body of a message, with the table
+// preformatted. It is accepted by incoming webhooks and as the reply to an
+// outgoing webhook.
+func TeamsPayload(msg Message) map[string]interface{} {
+	text := strings.ReplaceAll(escapeHTML(msg.Text), "\n", "<br>")
+	if len(msg.Table) > 0 {
+		text += "<pre>" + escapeHTML(strings.Join(msg.Table, "\n")) + "</pre>"
+	}
+	return map[string]interface{}{"type": "message", "text": text}
+}
+
+// PostResponse posts a delayed reply to a Slack response_url
+func PostResponse(ctx context.Context, responseURL string, payload map[string]interface{}) error {
+	return postJSON(ctx, &http.Client{Timeout: requestTimeout}, responseURL, payload)
+}
+
+// VerifySlackSignature checks the X-Slack-Signature of a request against the
+// signing secret of the Slack app
+func VerifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if math.Abs(now.Sub(time.Unix(ts, 0)).Seconds()) > slackSignatureMaxAge.Seconds() {
+		return fmt.Errorf("%w: request timestamp too old", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyTeamsSignature checks the Authorization header of a Teams outgoing
+// webhook request against its base64 security token
+func VerifyTeamsSignature(secret, authorization string, body []byte) error {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return fmt.Errorf("%w: security token is not base64", ErrInvalidSignature)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	expected := "HMAC " + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(authorization)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// postJSON posts a JSON payload and fails on a non-2xx status
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("message rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// escapeHTML escapes the characters Teams would read as markup
+var escapeHTML = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
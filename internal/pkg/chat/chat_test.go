@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte("token=x&text=revenue+by+month")
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("signing-secret"))
+	mac.Write([]byte("v0:" + timestamp + ":" + string(body)))
+	signature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.NoError(t, VerifySlackSignature("signing-secret", timestamp, signature, body, now))
+	assert.ErrorIs(t, VerifySlackSignature("other-secret", timestamp, signature, body, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySlackSignature("signing-secret", timestamp, signature, []byte("text=drop"), now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySlackSignature("signing-secret", timestamp, signature, body, now.Add(10*time.Minute)), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySlackSignature("signing-secret", "", signature, body, now), ErrInvalidSignature)
+}
+
+func TestVerifyTeamsSignature(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte("security-token"))
+	body := []byte(`{"text":"<at>NaraPulse</at> revenue by month"}`)
+	mac := hmac.New(sha256.New, []byte("security-token"))
+	mac.Write(body)
+	authorization := "HMAC " + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	assert.NoError(t, VerifyTeamsSignature(secret, authorization, body))
+	assert.ErrorIs(t, VerifyTeamsSignature(secret, authorization, []byte(`{}`)), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyTeamsSignature("not base64!", authorization, body), ErrInvalidSignature)
+}
+
+func TestPayloads(t *testing.T) {
+	msg := Message{Text: "Revenue <by> month", Table: []string{"month  revenue", "-----  -------"}}
+
+	slack := SlackPayload(msg, "in_channel")
+	assert.Equal(t, "Revenue <by> month\n```\nmonth  revenue\n-----  -------\n```", slack["text"])
+	assert.Equal(t, "in_channel", slack["response_type"])
+
+	teams := TeamsPayload(msg)
+	assert.Equal(t, "Revenue &lt;by&gt; month<pre>month  revenue\n-----  -------</pre>", teams["text"])
+}
+
+func TestSlackAppPostsToChannel(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxb-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got["channel"] == "#missing" {
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	original := SlackAPIURL
+	SlackAPIURL = server.URL
+	defer func() { SlackAPIURL = original }()
+
+	app := NewSlackApp("xoxb-token", "#analytics")
+	require.NoError(t, app.Post(context.Background(), "", Message{Text: "hello"}))
+	assert.Equal(t, "#analytics", got["channel"])
+
+	err := app.Post(context.Background(), "#missing", Message{Text: "hello"})
+	assert.ErrorContains(t, err, "channel_not_found")
+}
+
+func TestWebhookFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewTeamsWebhook(server.URL).Post(context.Background(), "", Message{Text: "hello"})
+	assert.ErrorContains(t, err, "status 403")
+}
@@ -2,21 +2,29 @@ package database
 
 import (
 	"fmt"
-	"log"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 func Initialize(databaseURL string) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: gormlogger.Default.LogMode(gormlogger.Info),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Scope the rows of user-owned tables to the tenant of the statement's context
+	if err := db.Use(tenancy.Plugin{Models: models.TenantModels()}); err != nil {
+		return nil, fmt.Errorf("failed to register tenancy plugin: %w", err)
+	}
+
 	// Test the connection
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -27,6 +35,6 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Database connected successfully")
+	logger.L().Info().Msg("Database connected successfully")
 	return db, nil
 }
\ No newline at end of file
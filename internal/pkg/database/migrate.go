@@ -1,17 +1,106 @@
 package database
 
 import (
-	models "narapulse-be/internal/models/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/migrations"
+
+	"github.com/pressly/goose/v3"
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 )
 
-// AutoMigrate runs GORM auto-migration for all models
-// Note: Auto-migration is disabled as we use SQL migrations via Goose
-// However, we enable it for NL2SQL models for development purposes
-func AutoMigrate(db *gorm.DB) error {
-	// Auto-migrate NL2SQL models
-	return db.AutoMigrate(
-		&models.NL2SQLQuery{},
-		&models.QueryResult{},
-	)
-}
\ No newline at end of file
+// migrationLockID is the Postgres advisory lock held while migrating, so
+// instances booting together apply each migration once
+const migrationLockID = 5887940537704921958
+
+// Migrate applies the pending embedded migrations. Migrations missing from
+// the version table are applied also when later ones were applied before,
+// so migrations merged from branches are not skipped.
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := setupGoose(db, gooseLogger{logger.FromContext(ctx)})
+	if err != nil {
+		return err
+	}
+	return locked(ctx, db, sqlDB, func() error {
+		return goose.UpContext(ctx, sqlDB, ".", goose.WithAllowMissing())
+	})
+}
+
+// RunMigrateCommand runs the migrate subcommand with its arguments: up (the
+// default) applies the pending migrations, down rolls back the latest one
+// and status lists them
+func RunMigrateCommand(ctx context.Context, db *gorm.DB, args []string, out io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	sqlDB, err := setupGoose(db, log.New(out, "", 0))
+	if err != nil {
+		return err
+	}
+
+	return locked(ctx, db, sqlDB, func() error {
+		switch command {
+		case "up":
+			return goose.UpContext(ctx, sqlDB, ".", goose.WithAllowMissing())
+		case "down":
+			return goose.DownContext(ctx, sqlDB, ".")
+		case "status":
+			return goose.StatusContext(ctx, sqlDB, ".")
+		default:
+			return fmt.Errorf("unknown migrate command %q; use up, down or status", command)
+		}
+	})
+}
+
+// setupGoose points goose at the embedded migrations and the dialect of db
+func setupGoose(db *gorm.DB, l goose.Logger) (*sql.DB, error) {
+	dialect := "postgres"
+	if db.Dialector.Name() == "sqlite" {
+		dialect = "sqlite3"
+	}
+	if err := goose.SetDialect(dialect); err != nil {
+		return nil, err
+	}
+	goose.SetBaseFS(migrations.FS)
+	goose.SetLogger(l)
+	return db.DB()
+}
+
+// locked runs fn while holding the migration lock on a connection of its own
+func locked(ctx context.Context, db *gorm.DB, sqlDB *sql.DB, fn func() error) error {
+	if db.Dialector.Name() != "postgres" {
+		return fn()
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", int64(migrationLockID)); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", int64(migrationLockID))
+	return fn()
+}
+
+// gooseLogger logs the progress of goose with the application logger
+type gooseLogger struct {
+	logger *zerolog.Logger
+}
+
+func (l gooseLogger) Printf(format string, v ...interface{}) {
+	l.logger.Info().Msg(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l gooseLogger) Fatalf(format string, v ...interface{}) {
+	l.logger.Fatal().Msg(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
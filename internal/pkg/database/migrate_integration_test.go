@@ -0,0 +1,108 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/testenv"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var integrationPostgres *testenv.Postgres
+
+func TestMain(m *testing.M) {
+	env, err := testenv.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration tests need docker: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer env.Close()
+
+		if integrationPostgres, err = env.StartPostgres(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return m.Run()
+	}()
+	os.Exit(code)
+}
+
+// baselineNL2SQLQuery and baselineQueryResult are the NL2SQL models GORM
+// auto-migrated before the tables were created by migrations
+type baselineNL2SQLQuery struct {
+	ID            uint        `gorm:"primaryKey"`
+	UserID        uint        `gorm:"not null;index"`
+	DataSourceID  uint        `gorm:"not null;index"`
+	NLQuery       string      `gorm:"type:text;not null"`
+	GeneratedSQL  string      `gorm:"type:text"`
+	Status        string      `gorm:"default:pending"`
+	Type          string      `gorm:"default:analytics"`
+	Context       models.JSON `gorm:"type:jsonb"`
+	Metadata      models.JSON `gorm:"type:jsonb"`
+	ErrorMsg      string      `gorm:"type:text"`
+	ExecutionTime int64
+	RowsReturned  int64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt        `gorm:"index"`
+	Results       []baselineQueryResult `gorm:"foreignKey:QueryID"`
+}
+
+func (baselineNL2SQLQuery) TableName() string { return "nl2_sql_queries" }
+
+type baselineQueryResult struct {
+	ID        uint        `gorm:"primaryKey"`
+	QueryID   uint        `gorm:"not null;index"`
+	Columns   models.JSON `gorm:"type:jsonb"`
+	Data      models.JSON `gorm:"type:jsonb"`
+	RowCount  int64
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineQueryResult) TableName() string { return "query_results" }
+
+func TestMigrateIntegration_UpgradesAutoMigratedTables(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: integrationPostgres.DB}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&baselineNL2SQLQuery{}, &baselineQueryResult{}))
+
+	query := baselineNL2SQLQuery{UserID: 1, DataSourceID: 1, NLQuery: "total revenue", GeneratedSQL: "SELECT SUM(amount) FROM orders"}
+	require.NoError(t, db.Create(&query).Error)
+	require.NoError(t, db.Create(&baselineQueryResult{QueryID: query.ID, RowCount: 1}).Error)
+
+	require.NoError(t, Migrate(context.Background(), db))
+
+	for _, column := range []string{"language", "sql_version", "parameters", "prompt_template_id", "prompt_template_version", "parent_query_id", "parent_sql_version"} {
+		assert.True(t, db.Migrator().HasColumn("nl2_sql_queries", column), column)
+	}
+	for _, column := range []string{"sql_version", "chunk_count", "archived_at", "archive_key", "archive_bytes"} {
+		assert.True(t, db.Migrator().HasColumn("query_results", column), column)
+	}
+
+	// The current models read the rows created before the upgrade
+	var upgraded models.NL2SQLQuery
+	require.NoError(t, db.Preload("Results").First(&upgraded, query.ID).Error)
+	assert.Equal(t, "total revenue", upgraded.NLQuery)
+	assert.Equal(t, models.LanguageEnglish, upgraded.Language)
+	require.Len(t, upgraded.Results, 1)
+	assert.Zero(t, upgraded.Results[0].ChunkCount)
+
+	// Migrating again changes nothing
+	require.NoError(t, Migrate(context.Background(), db))
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestRunMigrateCommand(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	require.NoError(t, RunMigrateCommand(ctx, db, []string{"status"}, &out))
+	assert.Regexp(t, `(?m)^ +Pending +-- 00001_create_users_table.sql$`, out.String())
+
+	assert.EqualError(t, RunMigrateCommand(ctx, db, []string{"sideways"}, &out), `unknown migrate command "sideways"; use up, down or status`)
+}
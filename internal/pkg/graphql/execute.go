@@ -0,0 +1,630 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is nil when the request was
+// invalid and nothing was executed.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is an error of a request, or of the field at Path
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Args are the arguments of a field after coercion: Int values are int64, ID
+// values strings and lists []interface{}
+type Args map[string]interface{}
+
+// String returns a String or ID argument, or "" when it is null or missing
+func (a Args) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+// Int returns an Int argument, or 0 when it is null or missing
+func (a Args) Int(name string) int {
+	n, _ := a[name].(int64)
+	return int(n)
+}
+
+// Bool returns a Boolean argument, or false when it is null or missing
+func (a Args) Bool(name string) bool {
+	b, _ := a[name].(bool)
+	return b
+}
+
+// ID returns a numeric ID argument, or 0 when it is null, missing or not a number
+func (a Args) ID(name string) uint {
+	id, _ := strconv.ParseUint(a.String(name), 10, 64)
+	return uint(id)
+}
+
+// IDs returns the numeric IDs of a list argument, skipping those that are not numbers
+func (a Args) IDs(name string) []uint {
+	list, _ := a[name].([]interface{})
+	ids := make([]uint, 0, len(list))
+	for _, item := range list {
+		s, _ := item.(string)
+		if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// Execute validates and executes a query. Errors of fields resolve them to
+// null and are listed in the response next to the data of the other fields.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query, s.maxDepth)
+	if err != nil {
+		return requestError(err)
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return requestError(err)
+	}
+	if op.kind != "query" {
+		return requestError(fmt.Errorf("only queries are supported, not %ss", op.kind))
+	}
+
+	e := &executor{schema: s, doc: doc, args: map[*fieldNode]Args{}}
+	if e.variables, err = coerceVariables(op, req.Variables); err != nil {
+		return requestError(err)
+	}
+	if err := e.validate(s.query, op.selections, 1, map[string]bool{}); err != nil {
+		return requestError(err)
+	}
+
+	results := e.executeSelections(ctx, s.query, op.selections, []interface{}{nil}, [][]interface{}{{}})
+	return &Response{Data: results[0], Errors: e.errors}
+}
+
+func requestError(err error) *Response {
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+func coerceVariables(op *operation, given map[string]interface{}) (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	for _, definition := range op.variables {
+		raw, ok := given[definition.name]
+		if !ok {
+			if definition.defaultValue == nil {
+				if isNonNull(definition.typ) {
+					return nil, fmt.Errorf("variable $%s of type %s is required", definition.name, definition.typ)
+				}
+				continue
+			}
+			value, err := literalValue(definition.defaultValue, nil)
+			if err != nil {
+				return nil, err
+			}
+			raw = value
+		}
+		value, err := coerce(definition.typ, raw)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", definition.name, err)
+		}
+		variables[definition.name] = value
+	}
+	return variables, nil
+}
+
+func isNonNull(typ string) bool {
+	return len(typ) > 0 && typ[len(typ)-1] == '!'
+}
+
+// literalValue converts a value of the document to a Go value like one decoded
+// from JSON, substituting variables
+func literalValue(v *value, variables map[string]interface{}) (interface{}, error) {
+	switch v.kind {
+	case valueVariable:
+		return variables[v.raw], nil
+	case valueInt:
+		n, err := strconv.ParseInt(v.raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Int %s", v.raw)
+		}
+		return n, nil
+	case valueFloat:
+		f, err := strconv.ParseFloat(v.raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Float %s", v.raw)
+		}
+		return f, nil
+	case valueString, valueEnum:
+		return v.raw, nil
+	case valueBoolean:
+		return v.raw == "true", nil
+	case valueList:
+		list := make([]interface{}, 0, len(v.list))
+		for _, item := range v.list {
+			value, err := literalValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case valueObject:
+		object := make(map[string]interface{}, len(v.fields))
+		for _, field := range v.fields {
+			value, err := literalValue(field.value, variables)
+			if err != nil {
+				return nil, err
+			}
+			object[field.name] = value
+		}
+		return object, nil
+	}
+	return nil, nil
+}
+
+// coerce converts an input value to the Go type of its GraphQL type
+func coerce(typ string, v interface{}) (interface{}, error) {
+	if v == nil {
+		if isNonNull(typ) {
+			return nil, fmt.Errorf("null is not a valid %s", typ)
+		}
+		return nil, nil
+	}
+	name, list, err := namedType(typ)
+	if err != nil {
+		return nil, err
+	}
+	if list {
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v} // A single value is a list of one
+		}
+		itemType := typ[1 : len(typ)-1]
+		if isNonNull(typ) {
+			itemType = typ[1 : len(typ)-2]
+		}
+		coerced := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			value, err := coerce(itemType, item)
+			if err != nil {
+				return nil, err
+			}
+			coerced = append(coerced, value)
+		}
+		return coerced, nil
+	}
+
+	switch name {
+	case "Int":
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case float64:
+			if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+				return int64(n), nil
+			}
+		}
+	case "Float":
+		switch n := v.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case "String":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "Boolean":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case "ID":
+		switch id := v.(type) {
+		case string:
+			return id, nil
+		case int64:
+			return strconv.FormatInt(id, 10), nil
+		case float64:
+			if id == math.Trunc(id) {
+				return strconv.FormatFloat(id, 'f', 0, 64), nil
+			}
+		}
+	default:
+		return v, nil
+	}
+	return nil, fmt.Errorf("%v is not a valid %s", v, name)
+}
+
+type executor struct {
+	schema    *Schema
+	doc       *document
+	variables map[string]interface{}
+	args      map[*fieldNode]Args // Coerced while validating
+	errors    []*Error
+}
+
+// validate checks the selections against the object type before anything is
+// executed, and coerces the arguments of the fields
+func (e *executor) validate(object *Object, selections []*selection, depth int, spreading map[string]bool) error {
+	if depth > e.schema.maxDepth {
+		return fmt.Errorf("the query is nested deeper than %d levels", e.schema.maxDepth)
+	}
+	for _, sel := range selections {
+		for _, directive := range sel.directives {
+			if directive.name != "skip" && directive.name != "include" {
+				return fmt.Errorf("unknown directive @%s", directive.name)
+			}
+			if _, err := e.directiveCondition(directive); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case sel.spread != "":
+			frag, ok := e.doc.fragments[sel.spread]
+			if !ok {
+				return fmt.Errorf("unknown fragment %q", sel.spread)
+			}
+			if spreading[frag.name] {
+				return fmt.Errorf("fragment %q spreads itself", frag.name)
+			}
+			if frag.typeCondition != object.Name {
+				return fmt.Errorf("fragment %q on %s cannot be spread on %s", frag.name, frag.typeCondition, object.Name)
+			}
+			spreading[frag.name] = true
+			err := e.validate(object, frag.selections, depth, spreading)
+			delete(spreading, frag.name)
+			if err != nil {
+				return err
+			}
+		case sel.inline != nil:
+			if sel.inline.typeCondition != "" && sel.inline.typeCondition != object.Name {
+				return fmt.Errorf("fragment on %s cannot be spread on %s", sel.inline.typeCondition, object.Name)
+			}
+			if err := e.validate(object, sel.inline.selections, depth, spreading); err != nil {
+				return err
+			}
+		default:
+			if err := e.validateField(object, sel.field, depth, spreading); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) validateField(object *Object, node *fieldNode, depth int, spreading map[string]bool) error {
+	if node.name == "__typename" {
+		if len(node.args) > 0 || len(node.selections) > 0 {
+			return fmt.Errorf("line %d: __typename takes no arguments or selections", node.line)
+		}
+		return nil
+	}
+	field := object.field(node.name)
+	if field == nil {
+		return fmt.Errorf("line %d: cannot query field %q on type %s", node.line, node.name, object.Name)
+	}
+
+	args := Args{}
+	for _, arg := range node.args {
+		definition := field.argument(arg.name)
+		if definition == nil {
+			return fmt.Errorf("line %d: unknown argument %q on field %s.%s", node.line, arg.name, object.Name, field.Name)
+		}
+		raw, err := literalValue(arg.value, e.variables)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.line, err)
+		}
+		if arg.value.kind == valueVariable {
+			if _, ok := e.variables[arg.value.raw]; !ok && definition.Default != nil {
+				continue
+			}
+		}
+		value, err := coerce(definition.Type, raw)
+		if err != nil {
+			return fmt.Errorf("line %d: argument %q of %s.%s: %w", node.line, arg.name, object.Name, field.Name, err)
+		}
+		args[arg.name] = value
+	}
+	for _, definition := range field.Args {
+		if _, ok := args[definition.Name]; ok {
+			continue
+		}
+		if definition.Default != nil {
+			value, err := coerce(definition.Type, definition.Default)
+			if err != nil {
+				return fmt.Errorf("graphql: default of argument %q of %s.%s: %w", definition.Name, object.Name, field.Name, err)
+			}
+			args[definition.Name] = value
+		} else if isNonNull(definition.Type) {
+			return fmt.Errorf("line %d: argument %q of %s.%s is required", node.line, definition.Name, object.Name, field.Name)
+		}
+	}
+	e.args[node] = args
+
+	name, _, _ := namedType(field.Type)
+	fieldObject, isObject := e.schema.objects[name]
+	switch {
+	case isObject && len(node.selections) == 0:
+		return fmt.Errorf("line %d: field %s.%s of type %s must have a selection of subfields", node.line, object.Name, field.Name, field.Type)
+	case !isObject && len(node.selections) > 0:
+		return fmt.Errorf("line %d: field %s.%s of type %s cannot have a selection of subfields", node.line, object.Name, field.Name, field.Type)
+	case isObject:
+		return e.validate(fieldObject, node.selections, depth+1, spreading)
+	}
+	return nil
+}
+
+// directiveCondition returns whether a @skip or @include directive keeps its selection
+func (e *executor) directiveCondition(directive *argumentList) (bool, error) {
+	if len(directive.args) != 1 || directive.args[0].name != "if" {
+		return false, fmt.Errorf("@%s takes one argument, if", directive.name)
+	}
+	raw, err := literalValue(directive.args[0].value, e.variables)
+	if err != nil {
+		return false, err
+	}
+	condition, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("the if argument of @%s must be a Boolean", directive.name)
+	}
+	if directive.name == "skip" {
+		return !condition, nil
+	}
+	return condition, nil
+}
+
+// collectedField is a response key with the field nodes selected under it
+type collectedField struct {
+	key   string
+	nodes []*fieldNode
+}
+
+// collectFields flattens fragments and merges the fields selected under the
+// same response key, in the order they first appear
+func (e *executor) collectFields(selections []*selection, fields []*collectedField) []*collectedField {
+	for _, sel := range selections {
+		included := true
+		for _, directive := range sel.directives {
+			keep, _ := e.directiveCondition(directive)
+			included = included && keep
+		}
+		if !included {
+			continue
+		}
+
+		switch {
+		case sel.spread != "":
+			fields = e.collectFields(e.doc.fragments[sel.spread].selections, fields)
+		case sel.inline != nil:
+			fields = e.collectFields(sel.inline.selections, fields)
+		default:
+			key := sel.field.responseKey()
+			merged := false
+			for _, field := range fields {
+				if field.key == key {
+					field.nodes = append(field.nodes, sel.field)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				fields = append(fields, &collectedField{key: key, nodes: []*fieldNode{sel.field}})
+			}
+		}
+	}
+	return fields
+}
+
+// executeSelections resolves the selections on every source at once and
+// returns the result object of each source
+func (e *executor) executeSelections(ctx context.Context, object *Object, selections []*selection, sources []interface{}, paths [][]interface{}) []*orderedObject {
+	results := make([]*orderedObject, len(sources))
+	for i := range results {
+		results[i] = &orderedObject{}
+	}
+
+	for _, collected := range e.collectFields(selections, nil) {
+		node := collected.nodes[0]
+		if node.name == "__typename" {
+			for _, result := range results {
+				result.set(collected.key, object.Name)
+			}
+			continue
+		}
+
+		field := object.field(node.name)
+		values, err := field.Resolve(ctx, sources, e.args[node])
+		if err == nil && len(values) != len(sources) {
+			err = fmt.Errorf("resolved %d values for %d objects", len(values), len(sources))
+		}
+		if err != nil {
+			for i, result := range results {
+				result.set(collected.key, nil)
+				e.errors = append(e.errors, &Error{Message: err.Error(), Path: appendPath(paths[i], collected.key)})
+			}
+			continue
+		}
+
+		for i, value := range values {
+			if err, ok := value.(error); ok {
+				values[i] = nil
+				e.errors = append(e.errors, &Error{Message: err.Error(), Path: appendPath(paths[i], collected.key)})
+			}
+		}
+
+		name, list, _ := namedType(field.Type)
+		fieldObject, isObject := e.schema.objects[name]
+		if !isObject {
+			for i, result := range results {
+				result.set(collected.key, serializeScalar(name, values[i]))
+			}
+			continue
+		}
+
+		var subSelections []*selection
+		for _, n := range collected.nodes {
+			subSelections = append(subSelections, n.selections...)
+		}
+		e.executeObjects(ctx, fieldObject, subSelections, collected.key, list, values, results, paths)
+	}
+	return results
+}
+
+// executeObjects resolves the selections of an object field on the values of
+// every source at once, flattening lists, and sets the results
+func (e *executor) executeObjects(ctx context.Context, object *Object, selections []*selection, key string, list bool,
+	values []interface{}, results []*orderedObject, paths [][]interface{}) {
+	var sources []interface{}
+	var sourcePaths [][]interface{}
+	owners := make([][]int, len(values)) // Index in sources of each item, -1 for nulls
+	for i, value := range values {
+		if isNil(value) {
+			continue
+		}
+		path := appendPath(paths[i], key)
+		if !list {
+			owners[i] = []int{len(sources)}
+			sources = append(sources, value)
+			sourcePaths = append(sourcePaths, path)
+			continue
+		}
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			e.errors = append(e.errors, &Error{Message: "expected a list", Path: path})
+			continue
+		}
+		owners[i] = make([]int, items.Len())
+		for j := 0; j < items.Len(); j++ {
+			item := items.Index(j).Interface()
+			if isNil(item) {
+				owners[i][j] = -1
+				continue
+			}
+			owners[i][j] = len(sources)
+			sources = append(sources, item)
+			sourcePaths = append(sourcePaths, appendPath(path, j))
+		}
+	}
+
+	var objects []*orderedObject
+	if len(sources) > 0 {
+		objects = e.executeSelections(ctx, object, selections, sources, sourcePaths)
+	}
+	for i, result := range results {
+		switch {
+		case owners[i] == nil:
+			result.set(key, nil)
+		case !list:
+			result.set(key, objects[owners[i][0]])
+		default:
+			items := make([]interface{}, len(owners[i]))
+			for j, index := range owners[i] {
+				if index >= 0 {
+					items[j] = objects[index]
+				}
+			}
+			result.set(key, items)
+		}
+	}
+}
+
+func appendPath(path []interface{}, element interface{}) []interface{} {
+	extended := make([]interface{}, len(path), len(path)+1)
+	copy(extended, path)
+	return append(extended, element)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
+
+// serializeScalar dereferences pointers and formats IDs as strings
+func serializeScalar(name string, v interface{}) interface{} {
+	if isNil(v) {
+		return nil
+	}
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if name == "ID" {
+		return fmt.Sprint(value.Interface())
+	}
+	return value.Interface()
+}
+
+// orderedObject is a result object that keeps its fields in selection order
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *orderedObject) set(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,220 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAuthor struct {
+	ID   uint
+	Name string
+}
+
+type testPost struct {
+	ID       uint
+	Title    string
+	AuthorID uint
+}
+
+// testSchema serves posts and their authors, counting how often authors are loaded
+func testSchema(t *testing.T, authorLoads *int) *Schema {
+	t.Helper()
+	authors := map[uint]*testAuthor{1: {ID: 1, Name: "Ana"}, 2: {ID: 2, Name: "Budi"}}
+	posts := []*testPost{{ID: 10, Title: "Revenue", AuthorID: 1}, {ID: 11, Title: "Churn", AuthorID: 2}, {ID: 12, Title: "Orders", AuthorID: 1}}
+
+	author := &Object{Name: "Author", Fields: []*Field{
+		{Name: "id", Type: "ID!", Resolve: Property(func(s interface{}) interface{} { return s.(*testAuthor).ID })},
+		{Name: "name", Type: "String!", Resolve: Property(func(s interface{}) interface{} { return s.(*testAuthor).Name })},
+	}}
+	author.Fields = append(author.Fields, &Field{Name: "posts", Type: "[Post!]!", Resolve: Each(func(_ context.Context, source interface{}, _ Args) (interface{}, error) {
+		var written []*testPost
+		for _, p := range posts {
+			if p.AuthorID == source.(*testAuthor).ID {
+				written = append(written, p)
+			}
+		}
+		return written, nil
+	})})
+	post := &Object{Name: "Post", Fields: []*Field{
+		{Name: "id", Type: "ID!", Resolve: Property(func(s interface{}) interface{} { return s.(*testPost).ID })},
+		{Name: "title", Type: "String!", Resolve: Property(func(s interface{}) interface{} { return s.(*testPost).Title })},
+		{Name: "author", Type: "Author", Resolve: func(_ context.Context, sources []interface{}, _ Args) ([]interface{}, error) {
+			*authorLoads++
+			values := make([]interface{}, len(sources))
+			for i, source := range sources {
+				values[i] = authors[source.(*testPost).AuthorID]
+			}
+			return values, nil
+		}},
+		{Name: "broken", Type: "String", Resolve: Each(func(_ context.Context, source interface{}, _ Args) (interface{}, error) {
+			if source.(*testPost).ID == 11 {
+				return nil, errors.New("cannot load")
+			}
+			return "loaded", nil
+		})},
+	}}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "posts", Type: "[Post!]!", Args: []*Argument{{Name: "first", Type: "Int", Default: int64(10)}},
+			Resolve: Each(func(_ context.Context, _ interface{}, args Args) (interface{}, error) {
+				if first := args.Int("first"); first < len(posts) {
+					return posts[:first], nil
+				}
+				return posts, nil
+			})},
+		{Name: "post", Type: "Post", Args: []*Argument{{Name: "id", Type: "ID!"}},
+			Resolve: Each(func(_ context.Context, _ interface{}, args Args) (interface{}, error) {
+				for _, p := range posts {
+					if p.ID == args.ID("id") {
+						return p, nil
+					}
+				}
+				return (*testPost)(nil), nil
+			})},
+	}}
+
+	schema, err := NewSchema(query, []*Object{post, author}, 3)
+	require.NoError(t, err)
+	return schema
+}
+
+func execute(t *testing.T, schema *Schema, req Request) (string, []*Error) {
+	t.Helper()
+	response := schema.Execute(context.Background(), req)
+	if response.Data == nil {
+		return "", response.Errors
+	}
+	data, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	return string(data), response.Errors
+}
+
+func TestExecuteBatchesFieldsAcrossLists(t *testing.T) {
+	loads := 0
+	schema := testSchema(t, &loads)
+
+	data, errs := execute(t, schema, Request{Query: `{ posts { id title author { name } } }`})
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"posts":[
+		{"id":"10","title":"Revenue","author":{"name":"Ana"}},
+		{"id":"11","title":"Churn","author":{"name":"Budi"}},
+		{"id":"12","title":"Orders","author":{"name":"Ana"}}]}`, data)
+	assert.Equal(t, 1, loads, "the authors of every post are loaded at once")
+}
+
+func TestExecuteAliasesFragmentsAndDirectives(t *testing.T) {
+	loads := 0
+	schema := testSchema(t, &loads)
+
+	data, errs := execute(t, schema, Request{
+		Query: `query Top($n: Int, $withAuthor: Boolean!) {
+			top: posts(first: $n) { ...postFields author @include(if: $withAuthor) { __typename name } }
+			missing: post(id: 99) { id }
+		}
+		fragment postFields on Post { headline: title }`,
+		Variables: map[string]interface{}{"n": float64(1), "withAuthor": false},
+	})
+	require.Empty(t, errs)
+	assert.Equal(t, `{"top":[{"headline":"Revenue"}],"missing":null}`, data, "keys keep the order of the selection")
+	assert.Zero(t, loads)
+
+	data, errs = execute(t, schema, Request{Query: `{ post(id: "11") { author { __typename name } } }`})
+	require.Empty(t, errs)
+	assert.Equal(t, `{"post":{"author":{"__typename":"Author","name":"Budi"}}}`, data)
+}
+
+func TestExecuteReportsFieldErrorsWithPaths(t *testing.T) {
+	loads := 0
+	schema := testSchema(t, &loads)
+
+	data, errs := execute(t, schema, Request{Query: `{ posts(first: 2) { id broken } }`})
+	assert.JSONEq(t, `{"posts":[{"id":"10","broken":"loaded"},{"id":"11","broken":null}]}`, data)
+	require.Len(t, errs, 1, "only the failing post loses the field")
+	assert.Equal(t, "cannot load", errs[0].Message)
+	assert.Equal(t, []interface{}{"posts", 1, "broken"}, errs[0].Path)
+}
+
+func TestExecuteRejectsInvalidRequests(t *testing.T) {
+	loads := 0
+	schema := testSchema(t, &loads)
+
+	for name, req := range map[string]Request{
+		"syntax":            {Query: `{ posts { id }`},
+		"unknown field":     {Query: `{ posts { body } }`},
+		"unknown argument":  {Query: `{ posts(last: 1) { id } }`},
+		"required argument": {Query: `{ post { id } }`},
+		"missing selection": {Query: `{ posts }`},
+		"scalar selection":  {Query: `{ posts { id { value } } }`},
+		"too deep":          {Query: `{ posts { author { posts { id } } } }`},
+		"argument type":     {Query: `{ posts(first: "two") { id } }`},
+		"required variable": {Query: `query ($id: ID!) { post(id: $id) { id } }`},
+		"mutation":          {Query: `mutation { posts { id } }`},
+		"fragment cycle":    {Query: `{ posts { ...a } } fragment a on Post { ...a }`},
+		"fragment type":     {Query: `{ posts { ...a } } fragment a on Author { name }`},
+		"operation name":    {Query: `query A { posts { id } } query B { posts { id } }`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := schema.Execute(context.Background(), req)
+			assert.Nil(t, response.Data)
+			assert.Len(t, response.Errors, 1)
+		})
+	}
+	assert.Zero(t, loads)
+}
+
+func TestExecuteRejectsPathologicallyNestedDocuments(t *testing.T) {
+	loads := 0
+	schema := testSchema(t, &loads)
+	const levels = 100000
+
+	for name, query := range map[string]string{
+		"selections":       strings.Repeat("{ posts ", levels) + strings.Repeat("}", levels),
+		"inline fragments": "{ posts " + strings.Repeat("{ ... ", levels) + strings.Repeat("}", levels) + " }",
+		"lists":            "{ post(id: " + strings.Repeat("[", levels) + strings.Repeat("]", levels) + ") { id } }",
+		"objects":          "{ post(id: " + strings.Repeat("{ a: ", levels) + "1" + strings.Repeat("}", levels) + ") { id } }",
+		"variable default": "query ($id: ID = " + strings.Repeat("[", levels) + strings.Repeat("]", levels) + ") { post(id: $id) { id } }",
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := schema.Execute(context.Background(), Request{Query: query})
+			assert.Nil(t, response.Data)
+			require.Len(t, response.Errors, 1)
+			assert.Contains(t, response.Errors[0].Message, "nested deeper than 3 levels")
+		})
+	}
+	assert.Zero(t, loads)
+
+	// Inline fragments do not count as a level, as in the executor
+	data, errs := execute(t, schema, Request{Query: `{ posts(first: 1) { ... on Post { author { ... { name } } } } }`})
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"posts":[{"author":{"name":"Ana"}}]}`, data)
+}
+
+func TestNewSchemaChecksTypes(t *testing.T) {
+	resolve := Property(func(interface{}) interface{} { return nil })
+
+	_, err := NewSchema(&Object{Name: "Query", Fields: []*Field{{Name: "x", Type: "Missing", Resolve: resolve}}}, nil, 0)
+	assert.Error(t, err)
+
+	_, err = NewSchema(&Object{Name: "Query", Fields: []*Field{{Name: "x", Type: "String"}}}, nil, 0)
+	assert.Error(t, err, "fields need a resolver")
+
+	other := &Object{Name: "Other", Fields: []*Field{{Name: "x", Type: "String", Resolve: resolve}}}
+	_, err = NewSchema(&Object{Name: "Query", Fields: []*Field{
+		{Name: "x", Type: "String", Args: []*Argument{{Name: "o", Type: "Other"}}, Resolve: resolve},
+	}}, []*Object{other}, 0)
+	assert.Error(t, err, "arguments must be scalars")
+}
+
+func TestSDL(t *testing.T) {
+	loads := 0
+	sdl := testSchema(t, &loads).SDL()
+
+	assert.Contains(t, sdl, "type Query {\n  posts(first: Int = 10): [Post!]!\n  post(id: ID!): Post\n}\n")
+	assert.Contains(t, sdl, "type Author {\n  id: ID!\n  name: String!\n  posts: [Post!]!\n}\n")
+	assert.Contains(t, sdl, "scalar JSON\n")
+}
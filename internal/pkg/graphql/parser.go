@@ -0,0 +1,664 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed GraphQL request document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string // query, mutation or subscription
+	name       string
+	variables  []*variableDefinition
+	selections []*selection
+}
+
+type variableDefinition struct {
+	name         string
+	typ          string
+	defaultValue *value
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	selections    []*selection
+}
+
+// selection is a field, a fragment spread or an inline fragment
+type selection struct {
+	field      *fieldNode
+	spread     string
+	inline     *fragment // Inline fragments have no name
+	directives []*argumentList
+}
+
+type fieldNode struct {
+	alias      string
+	name       string
+	args       []*argument
+	selections []*selection
+	line       int
+}
+
+// responseKey is the key of the field in the response: its alias or name
+func (f *fieldNode) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type argument struct {
+	name  string
+	value *value
+}
+
+// argumentList is a directive with its arguments
+type argumentList struct {
+	name string
+	args []*argument
+}
+
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+type value struct {
+	kind   valueKind
+	raw    string // Name of variables and enums, text of scalars
+	list   []*value
+	fields []*argument // Fields of input objects
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lex splits a document into tokens. Whitespace, commas and comments are
+// insignificant and dropped.
+func lex(source string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, token{kind: tokenPunctuator, text: "...", line: line})
+			i += 3
+		case strings.ContainsRune("!$()&:=@[]{}|", rune(c)):
+			tokens = append(tokens, token{kind: tokenPunctuator, text: string(c), line: line})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(source) && (source[i] == '_' || isLetter(source[i]) || isDigit(source[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: source[start:i], line: line})
+		case c == '-' || isDigit(c):
+			start := i
+			kind := tokenInt
+			if c == '-' {
+				i++
+			}
+			for i < len(source) && isDigit(source[i]) {
+				i++
+			}
+			if i < len(source) && source[i] == '.' {
+				kind = tokenFloat
+				i++
+				for i < len(source) && isDigit(source[i]) {
+					i++
+				}
+			}
+			if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+				kind = tokenFloat
+				i++
+				if i < len(source) && (source[i] == '+' || source[i] == '-') {
+					i++
+				}
+				for i < len(source) && isDigit(source[i]) {
+					i++
+				}
+			}
+			text := source[start:i]
+			if text == "-" || strings.HasSuffix(text, ".") {
+				return nil, fmt.Errorf("syntax error on line %d: invalid number %q", line, text)
+			}
+			tokens = append(tokens, token{kind: kind, text: text, line: line})
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(source[i+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("syntax error on line %d: unterminated block string", line)
+			}
+			text := source[i+3 : i+3+end]
+			tokens = append(tokens, token{kind: tokenString, text: strings.TrimSpace(text), line: line})
+			line += strings.Count(text, "\n")
+			i += end + 6
+		case c == '"':
+			text, n, err := lexString(source[i:])
+			if err != nil {
+				return nil, fmt.Errorf("syntax error on line %d: %v", line, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, line: line})
+			i += n
+		default:
+			r, _ := utf8.DecodeRuneInString(source[i:])
+			return nil, fmt.Errorf("syntax error on line %d: unexpected character %q", line, r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+// lexString reads a quoted string and returns its value and length
+func lexString(source string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(source); i++ {
+		switch c := source[i]; c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case '\\':
+			i++
+			if i >= len(source) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch source[i] {
+			case '"', '\\', '/':
+				b.WriteByte(source[i])
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+4 >= len(source) {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(source[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", source[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	tokens   []token
+	pos      int
+	maxDepth int
+	// How deep the selection set, inline fragment and value being parsed
+	// are nested, each counted like the executor counts them
+	depth, inlineDepth, valueDepth int
+}
+
+// parse parses an executable document: operations and fragments. Selection
+// sets, lists and objects nested deeper than maxDepth are rejected while
+// parsing, before they can exhaust the stack.
+func parse(source string, maxDepth int) (*document, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, maxDepth: maxDepth}
+	doc := &document{fragments: map[string]*fragment{}}
+	for p.peek().kind != tokenEOF {
+		switch {
+		case p.peekIs("{"):
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections})
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peekName("fragment"):
+			frag, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[frag.name]; ok {
+				return nil, fmt.Errorf("there can be only one fragment named %q", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document has no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) peekIs(punctuator string) bool {
+	t := p.peek()
+	return t.kind == tokenPunctuator && t.text == punctuator
+}
+
+func (p *parser) peekName(name string) bool {
+	t := p.peek()
+	return t.kind == tokenName && t.text == name
+}
+
+func (p *parser) skip(punctuator string) bool {
+	if p.peekIs(punctuator) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.skip(punctuator) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	t := p.peek()
+	if t.kind != tokenName {
+		return "", p.unexpected()
+	}
+	p.pos++
+	return t.text, nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("syntax error on line %d: unexpected end of document", t.line)
+	}
+	return fmt.Errorf("syntax error on line %d: unexpected %q", t.line, t.text)
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: p.next().text}
+	if p.peek().kind == tokenName {
+		op.name = p.next().text
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			typ, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			definition := &variableDefinition{name: name, typ: typ}
+			if p.skip("=") {
+				if definition.defaultValue, err = p.parseValue(true); err != nil {
+					return nil, err
+				}
+			}
+			op.variables = append(op.variables, definition)
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+func (p *parser) parseType() (string, error) {
+	var typ string
+	if p.skip("[") {
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+	if p.skip("!") {
+		typ += "!"
+	}
+	return typ, nil
+}
+
+func (p *parser) parseFragment() (*fragment, error) {
+	p.next()
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("syntax error: a fragment cannot be named \"on\"")
+	}
+	if !p.peekName("on") {
+		return nil, p.unexpected()
+	}
+	p.next()
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, typeCondition: typeCondition, selections: selections}, nil
+}
+
+// nest enters a selection set, inline fragment, list or object, failing if
+// it is nested too deep; the caller leaves it again by decrementing depth
+func (p *parser) nest(depth *int) error {
+	*depth++
+	if *depth > p.maxDepth {
+		return fmt.Errorf("syntax error on line %d: the query is nested deeper than %d levels", p.peek().line, p.maxDepth)
+	}
+	return nil
+}
+
+// parseSelectionSet parses the selections of an operation, fragment or field,
+// which nest one level deeper
+func (p *parser) parseSelectionSet() ([]*selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.nest(&p.depth); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	return p.parseSelections()
+}
+
+func (p *parser) parseSelections() ([]*selection, error) {
+	var selections []*selection
+	for !p.skip("}") {
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("syntax error: empty selection set")
+	}
+	return selections, nil
+}
+
+func (p *parser) parseSelection() (*selection, error) {
+	if p.skip("...") {
+		if p.peek().kind == tokenName && !p.peekName("on") {
+			name := p.next().text
+			directives, err := p.parseDirectives()
+			if err != nil {
+				return nil, err
+			}
+			return &selection{spread: name, directives: directives}, nil
+		}
+		inline := &fragment{}
+		if p.peekName("on") {
+			p.next()
+			typeCondition, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			inline.typeCondition = typeCondition
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		// The selections of an inline fragment are on the level of the fragment
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		if err := p.nest(&p.inlineDepth); err != nil {
+			return nil, err
+		}
+		inline.selections, err = p.parseSelections()
+		p.inlineDepth--
+		if err != nil {
+			return nil, err
+		}
+		return &selection{inline: inline, directives: directives}, nil
+	}
+
+	line := p.peek().line
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	field := &fieldNode{name: name, line: line}
+	if p.skip(":") {
+		field.alias = name
+		if field.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekIs("(") {
+		if field.args, err = p.parseArguments(); err != nil {
+			return nil, err
+		}
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+	if p.peekIs("{") {
+		if field.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return &selection{field: field, directives: directives}, nil
+}
+
+func (p *parser) parseArguments() ([]*argument, error) {
+	p.next()
+	var args []*argument
+	for !p.skip(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name, value: v})
+	}
+	return args, nil
+}
+
+func (p *parser) parseDirectives() ([]*argumentList, error) {
+	var directives []*argumentList
+	for p.skip("@") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		directive := &argumentList{name: name}
+		if p.peekIs("(") {
+			if directive.args, err = p.parseArguments(); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// parseValue parses a value; constant values, such as variable defaults, cannot use variables
+func (p *parser) parseValue(constant bool) (*value, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenInt:
+		p.next()
+		return &value{kind: valueInt, raw: t.text}, nil
+	case tokenFloat:
+		p.next()
+		return &value{kind: valueFloat, raw: t.text}, nil
+	case tokenString:
+		p.next()
+		return &value{kind: valueString, raw: t.text}, nil
+	case tokenName:
+		p.next()
+		switch t.text {
+		case "true", "false":
+			return &value{kind: valueBoolean, raw: t.text}, nil
+		case "null":
+			return &value{kind: valueNull}, nil
+		}
+		return &value{kind: valueEnum, raw: t.text}, nil
+	case tokenPunctuator:
+		switch t.text {
+		case "$":
+			if constant {
+				return nil, fmt.Errorf("syntax error on line %d: variables are not allowed here", t.line)
+			}
+			p.next()
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return &value{kind: valueVariable, raw: name}, nil
+		case "[":
+			p.next()
+			if err := p.nest(&p.valueDepth); err != nil {
+				return nil, err
+			}
+			defer func() { p.valueDepth-- }()
+			list := &value{kind: valueList}
+			for !p.skip("]") {
+				item, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list.list = append(list.list, item)
+			}
+			return list, nil
+		case "{":
+			p.next()
+			if err := p.nest(&p.valueDepth); err != nil {
+				return nil, err
+			}
+			defer func() { p.valueDepth-- }()
+			object := &value{kind: valueObject}
+			for !p.skip("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				field, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				object.fields = append(object.fields, &argument{name: name, value: field})
+			}
+			return object, nil
+		}
+	}
+	return nil, p.unexpected()
+}
@@ -0,0 +1,238 @@
+// Package graphql executes GraphQL queries against a schema of objects whose
+// fields are resolved in batches: a field is resolved once for all the objects
+// at the same place in the response, so loading a relation of a list costs
+// one lookup rather than one per item, like a dataloader. Only queries are
+// supported. Non-null types are documented in the schema but not enforced.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Built-in scalars, and the JSON and Time scalars for untyped objects and
+// RFC 3339 times
+var scalars = map[string]string{
+	"String":  "",
+	"Int":     "",
+	"Float":   "",
+	"Boolean": "",
+	"ID":      "",
+	"JSON":    "Any JSON value",
+	"Time":    "An RFC 3339 time",
+}
+
+// DefaultMaxDepth is how deep selections may nest when the schema does not set it
+const DefaultMaxDepth = 10
+
+// Resolver resolves a field for a batch of sources and returns one value per
+// source, in the same order. Object fields return the object, or a slice of
+// objects for list types; nil values resolve to null. An error value fails the
+// field of its source only, while a returned error fails it for the batch.
+type Resolver func(ctx context.Context, sources []interface{}, args Args) ([]interface{}, error)
+
+// Property resolves a field from each source on its own, for fields that are
+// read from the source without loading anything
+func Property(get func(source interface{}) interface{}) Resolver {
+	return func(_ context.Context, sources []interface{}, _ Args) ([]interface{}, error) {
+		values := make([]interface{}, len(sources))
+		for i, source := range sources {
+			values[i] = get(source)
+		}
+		return values, nil
+	}
+}
+
+// Each resolves a field one source at a time, for root fields and fields that
+// cannot be loaded in batches. An error fails the field of its source only.
+func Each(resolve func(ctx context.Context, source interface{}, args Args) (interface{}, error)) Resolver {
+	return func(ctx context.Context, sources []interface{}, args Args) ([]interface{}, error) {
+		values := make([]interface{}, len(sources))
+		for i, source := range sources {
+			value, err := resolve(ctx, source, args)
+			if err != nil {
+				values[i] = err
+				continue
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+}
+
+// Object is an object type
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// Field is a field of an object. Type is in GraphQL notation, e.g. "[Schema!]!".
+type Field struct {
+	Name        string
+	Type        string
+	Description string
+	Args        []*Argument
+	Resolve     Resolver
+}
+
+// Argument is an argument of a field; Default applies when it is not given
+type Argument struct {
+	Name        string
+	Type        string
+	Description string
+	Default     interface{}
+}
+
+func (o *Object) field(name string) *Field {
+	for _, field := range o.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func (f *Field) argument(name string) *Argument {
+	for _, arg := range f.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// Schema is a set of object types with the Query type as entry point
+type Schema struct {
+	query    *Object
+	objects  map[string]*Object
+	maxDepth int
+}
+
+// NewSchema checks that the types of every field and argument are known and
+// returns the schema. maxDepth limits how deep selections may nest; 0 uses
+// DefaultMaxDepth.
+func NewSchema(query *Object, objects []*Object, maxDepth int) (*Schema, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	s := &Schema{query: query, objects: map[string]*Object{query.Name: query}, maxDepth: maxDepth}
+	for _, object := range objects {
+		if _, ok := s.objects[object.Name]; ok {
+			return nil, fmt.Errorf("graphql: type %s is defined twice", object.Name)
+		}
+		if _, ok := scalars[object.Name]; ok {
+			return nil, fmt.Errorf("graphql: type %s is a scalar", object.Name)
+		}
+		s.objects[object.Name] = object
+	}
+
+	for _, object := range s.objects {
+		for _, field := range object.Fields {
+			if field.Resolve == nil {
+				return nil, fmt.Errorf("graphql: field %s.%s has no resolver", object.Name, field.Name)
+			}
+			name, _, err := namedType(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("graphql: field %s.%s: %w", object.Name, field.Name, err)
+			}
+			if _, ok := s.objects[name]; !ok {
+				if _, ok := scalars[name]; !ok {
+					return nil, fmt.Errorf("graphql: field %s.%s has the unknown type %s", object.Name, field.Name, name)
+				}
+			}
+			for _, arg := range field.Args {
+				name, _, err := namedType(arg.Type)
+				if err != nil {
+					return nil, fmt.Errorf("graphql: argument %s of %s.%s: %w", arg.Name, object.Name, field.Name, err)
+				}
+				if _, ok := scalars[name]; !ok {
+					return nil, fmt.Errorf("graphql: argument %s of %s.%s must be a scalar", arg.Name, object.Name, field.Name)
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+// namedType returns the type a type in GraphQL notation wraps, and whether it
+// is a list. Lists of lists are not supported.
+func namedType(typ string) (string, bool, error) {
+	inner := strings.TrimSuffix(typ, "!")
+	list := strings.HasPrefix(inner, "[")
+	if list {
+		if !strings.HasSuffix(inner, "]") {
+			return "", false, fmt.Errorf("invalid type %q", typ)
+		}
+		inner = strings.TrimSuffix(strings.TrimSuffix(inner[1:], "]"), "!")
+	}
+	if inner == "" || strings.ContainsAny(inner, "[]!") {
+		return "", false, fmt.Errorf("invalid type %q", typ)
+	}
+	return inner, list, nil
+}
+
+// SDL returns the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	names := make([]string, 0, len(s.objects))
+	for name := range s.objects {
+		if name != s.query.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	writeObject(&b, s.query)
+	for _, name := range names {
+		b.WriteString("\n")
+		writeObject(&b, s.objects[name])
+	}
+
+	scalarNames := make([]string, 0, len(scalars))
+	for name, description := range scalars {
+		if description != "" {
+			scalarNames = append(scalarNames, name)
+		}
+	}
+	sort.Strings(scalarNames)
+	for _, name := range scalarNames {
+		fmt.Fprintf(&b, "\n%q\nscalar %s\n", scalars[name], name)
+	}
+	return b.String()
+}
+
+func writeObject(b *strings.Builder, object *Object) {
+	if object.Description != "" {
+		fmt.Fprintf(b, "%q\n", object.Description)
+	}
+	fmt.Fprintf(b, "type %s {\n", object.Name)
+	for _, field := range object.Fields {
+		if field.Description != "" {
+			fmt.Fprintf(b, "  %q\n", field.Description)
+		}
+		b.WriteString("  " + field.Name)
+		if len(field.Args) > 0 {
+			args := make([]string, 0, len(field.Args))
+			for _, arg := range field.Args {
+				definition := arg.Name + ": " + arg.Type
+				if arg.Default != nil {
+					definition += fmt.Sprintf(" = %v", literal(arg.Default))
+				}
+				args = append(args, definition)
+			}
+			b.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+		b.WriteString(": " + field.Type + "\n")
+	}
+	b.WriteString("}\n")
+}
+
+// literal formats a default value as a GraphQL literal
+func literal(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,91 @@
+// Package logger provides the structured application logger. Log lines of a
+// request carry its request ID: handlers pass c.UserContext() on to services,
+// which log through FromContext.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// base is the application logger; it is replaced, never changed, so loggers
+// handed out keep working while the level changes
+var base atomic.Pointer[zerolog.Logger]
+
+func init() {
+	base.Store(newLogger(os.Stdout, zerolog.InfoLevel, "json"))
+}
+
+// Setup configures the application logger. level is one of trace, debug,
+// info, warn or error; format is json, or console for human-readable output
+// during development.
+func Setup(level, format string) {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		parsed = zerolog.InfoLevel
+	}
+	base.Store(newLogger(os.Stdout, parsed, format))
+
+	if err != nil {
+		L().Warn().Str("level", level).Msg("Unknown log level, using info")
+	}
+}
+
+// SetLevel changes the level of the application logger, e.g. when the
+// configuration is reloaded. Requests already being served keep the level
+// they started with.
+func SetLevel(level string) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l := L().Level(parsed)
+	base.Store(&l)
+	return nil
+}
+
+func parseLevel(level string) (zerolog.Level, error) {
+	parsed, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil || parsed == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q", level)
+	}
+	return parsed, nil
+}
+
+func newLogger(w io.Writer, level zerolog.Level, format string) *zerolog.Logger {
+	if format == "console" {
+		w = zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339}
+	}
+	l := zerolog.New(w).Level(level).With().Timestamp().Logger()
+	return &l
+}
+
+// L returns the application logger, for logging outside of a request
+func L() *zerolog.Logger {
+	return base.Load()
+}
+
+// FromContext returns the logger of the request the context belongs to, or
+// the application logger when there is none
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		// zerolog returns a disabled logger for contexts without one
+		if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+			return l
+		}
+	}
+	return L()
+}
+
+// WithRequestID returns a context whose logger adds the request ID to every line
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	l := FromContext(ctx).With().Str("request_id", requestID).Logger()
+	return l.WithContext(ctx)
+}
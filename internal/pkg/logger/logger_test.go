@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContextAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	previous := base.Load()
+	base.Store(newLogger(&buf, zerolog.InfoLevel, "json"))
+	defer base.Store(previous)
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	FromContext(ctx).Info().Uint("user_id", 7).Msg("query executed")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-123", line["request_id"])
+	assert.Equal(t, "query executed", line["message"])
+	assert.Equal(t, float64(7), line["user_id"])
+	assert.Equal(t, "info", line["level"])
+}
+
+func TestFromContextFallsBackToApplicationLogger(t *testing.T) {
+	assert.Same(t, L(), FromContext(context.Background()))
+	assert.Same(t, L(), FromContext(nil))
+}
+
+func TestSetupLevel(t *testing.T) {
+	previous := base.Load()
+	defer base.Store(previous)
+
+	Setup("debug", "json")
+	assert.Equal(t, zerolog.DebugLevel, L().GetLevel())
+
+	Setup("verbose", "json")
+	assert.Equal(t, zerolog.InfoLevel, L().GetLevel())
+}
+
+func TestSetLevel(t *testing.T) {
+	previous := base.Load()
+	defer base.Store(previous)
+
+	Setup("info", "json")
+	held := L()
+	require.NoError(t, SetLevel("debug"))
+	assert.Equal(t, zerolog.DebugLevel, L().GetLevel())
+	assert.Equal(t, zerolog.InfoLevel, held.GetLevel(), "loggers handed out are not changed")
+
+	assert.Error(t, SetLevel("verbose"))
+	assert.Equal(t, zerolog.DebugLevel, L().GetLevel())
+}
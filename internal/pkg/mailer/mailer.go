@@ -0,0 +1,165 @@
+// Package mailer sends outgoing email. Services depend on the Sender interface
+// so delivery can be swapped (SMTP in production, a log sender in development).
+package mailer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"narapulse-be/internal/pkg/logger"
+)
+
+// Message is a multipart email with a plain text and an optional HTML body
+// and attachments
+type Message struct {
+	To          []string
+	Subject     string
+	TextBody    string
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPConfig configures the SMTP sender
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// New returns an SMTP sender, or a sender that only logs messages when no SMTP
+// host is configured
+func New(cfg SMTPConfig) Sender {
+	if cfg.Host == "" {
+		return LogSender{}
+	}
+	return &SMTPSender{config: cfg}
+}
+
+// LogSender writes messages to the log instead of delivering them
+type LogSender struct{}
+
+// Send logs the recipients and subject of the message
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	logger.FromContext(ctx).Info().Strs("to", msg.To).Str("subject", msg.Subject).Int("attachments", len(msg.Attachments)).
+		Msg("Email not sent (SMTP not configured)")
+	return nil
+}
+
+// SMTPSender delivers messages through an SMTP server, using STARTTLS and
+// PLAIN auth when credentials are configured
+type SMTPSender struct {
+	config SMTPConfig
+}
+
+// Send delivers the message to every recipient
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+
+	addr := net.JoinHostPort(s.config.Host, fmt.Sprint(s.config.Port))
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, s.config.From, msg.To, buildMessage(s.config.From, msg, time.Now()))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// mimeBoundary separates the text and HTML parts of a message
+const mimeBoundary = "narapulse-alternative"
+
+// mixedBoundary separates the body of a message from its attachments
+const mixedBoundary = "narapulse-mixed"
+
+// headerValue strips line breaks so values cannot inject extra headers
+var headerValue = strings.NewReplacer("\r", "", "\n", "")
+
+// buildMessage renders the RFC 5322 message, as multipart/alternative when it
+// has an HTML body, wrapped in multipart/mixed when it has attachments
+func buildMessage(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue.Replace(from) + "\r\n")
+	b.WriteString("To: " + headerValue.Replace(strings.Join(msg.To, ", ")) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(msg.Attachments) == 0 {
+		writeBody(&b, msg)
+		return []byte(b.String())
+	}
+
+	b.WriteString("Content-Type: multipart/mixed; boundary=" + mixedBoundary + "\r\n\r\n")
+	b.WriteString("--" + mixedBoundary + "\r\n")
+	writeBody(&b, msg)
+	b.WriteString("\r\n")
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		filename := mime.QEncoding.Encode("utf-8", headerValue.Replace(attachment.Filename))
+		b.WriteString("--" + mixedBoundary + "\r\n")
+		b.WriteString("Content-Type: " + headerValue.Replace(contentType) + "\r\n")
+		b.WriteString("Content-Transfer-Encoding: base64\r\n")
+		b.WriteString(`Content-Disposition: attachment; filename="` + filename + `"` + "\r\n\r\n")
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	b.WriteString("--" + mixedBoundary + "--\r\n")
+	return []byte(b.String())
+}
+
+// writeBody writes the content type header and the text and HTML parts
+func writeBody(b *strings.Builder, msg Message) {
+	if msg.HTMLBody == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		b.WriteString(msg.TextBody)
+		return
+	}
+
+	b.WriteString("Content-Type: multipart/alternative; boundary=" + mimeBoundary + "\r\n\r\n")
+	b.WriteString("--" + mimeBoundary + "\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.TextBody + "\r\n")
+	b.WriteString("--" + mimeBoundary + "\r\nContent-Type: text/html; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.HTMLBody + "\r\n")
+	b.WriteString("--" + mimeBoundary + "--\r\n")
+}
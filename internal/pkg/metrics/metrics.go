@@ -0,0 +1,139 @@
+// Package metrics collects the Prometheus metrics of the API: request
+// latency per route, NL2SQL conversions and executions, embedding API calls,
+// data source query durations, background jobs and database connection pool
+// statistics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "narapulse"
+
+// Outcome label values
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Registry holds every metric of the API, including Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	nl2sqlConversions = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nl2sql_conversion_duration_seconds",
+		Help:      "Duration of natural language to SQL conversions by outcome.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40},
+	}, []string{"status"})
+
+	nl2sqlExecutions = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nl2sql_execution_duration_seconds",
+		Help:      "Duration of NL2SQL query executions by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+
+	embeddingRequests = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "embedding_request_duration_seconds",
+		Help:      "Duration of embedding API calls by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"status"})
+
+	connectorQueries = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "connector_query_duration_seconds",
+		Help:      "Duration of queries run against data sources by data source type and outcome.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"type", "status"})
+
+	jobRuns = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of background job runs by job type and outcome.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 60, 300, 900, 1800},
+	}, []string{"type", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		nl2sqlConversions,
+		nl2sqlExecutions,
+		embeddingRequests,
+		connectorQueries,
+		jobRuns,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records the latency of a request. route is the matched
+// route pattern rather than the path, so IDs do not create new series.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveNL2SQLConversion records an NL2SQL conversion that started at start
+func ObserveNL2SQLConversion(start time.Time, err error) {
+	nl2sqlConversions.WithLabelValues(outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveNL2SQLExecution records an NL2SQL query execution that started at start
+func ObserveNL2SQLExecution(start time.Time, err error) {
+	nl2sqlExecutions.WithLabelValues(outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveEmbeddingRequest records an embedding API call that started at start
+func ObserveEmbeddingRequest(start time.Time, err error) {
+	embeddingRequests.WithLabelValues(outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveConnectorQuery records a query against a data source of the type that started at start
+func ObserveConnectorQuery(dataSourceType string, start time.Time, err error) {
+	connectorQueries.WithLabelValues(dataSourceType, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveJob records a run of a background job of the type that started at start
+func ObserveJob(jobType string, start time.Time, err error) {
+	jobRuns.WithLabelValues(jobType, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// RegisterDB exports the connection pool statistics of a database handle under the name
+func RegisterDB(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterDataSourcePools exports the connection pool statistics of the data
+// source handles returned by stats, labelled by data source ID
+func RegisterDataSourcePools(stats func() map[uint]sql.DBStats) error {
+	return Registry.Register(&poolCollector{stats: stats})
+}
+
+// outcome returns the status label of an operation that returned err
+func outcome(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusSuccess
+}
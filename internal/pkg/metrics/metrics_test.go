@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, StatusSuccess, outcome(nil))
+	assert.Equal(t, StatusError, outcome(errors.New("timeout")))
+}
+
+func TestPoolCollectorReportsOpenHandles(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(&poolCollector{stats: func() map[uint]sql.DBStats {
+		return map[uint]sql.DBStats{
+			7: {OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 4, WaitDuration: 1500 * time.Millisecond},
+		}
+	}}))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			require.Equal(t, "7", metric.GetLabel()[0].GetValue())
+			if metric.GetGauge() != nil {
+				values[family.GetName()] = metric.GetGauge().GetValue()
+			} else {
+				values[family.GetName()] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"narapulse_datasource_pool_open_connections":            3,
+		"narapulse_datasource_pool_in_use_connections":          1,
+		"narapulse_datasource_pool_idle_connections":            2,
+		"narapulse_datasource_pool_wait_count_total":            4,
+		"narapulse_datasource_pool_wait_duration_seconds_total": 1.5,
+	}, values)
+}
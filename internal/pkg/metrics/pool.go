@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolOpenDesc = prometheus.NewDesc(namespace+"_datasource_pool_open_connections",
+		"Open connections of the data source pool.", []string{"data_source_id"}, nil)
+	poolInUseDesc = prometheus.NewDesc(namespace+"_datasource_pool_in_use_connections",
+		"Connections of the data source pool currently in use.", []string{"data_source_id"}, nil)
+	poolIdleDesc = prometheus.NewDesc(namespace+"_datasource_pool_idle_connections",
+		"Idle connections of the data source pool.", []string{"data_source_id"}, nil)
+	poolWaitCountDesc = prometheus.NewDesc(namespace+"_datasource_pool_wait_count_total",
+		"Connections of the data source pool waited for.", []string{"data_source_id"}, nil)
+	poolWaitDurationDesc = prometheus.NewDesc(namespace+"_datasource_pool_wait_duration_seconds_total",
+		"Time spent waiting for connections of the data source pool.", []string{"data_source_id"}, nil)
+)
+
+// poolCollector reports the statistics of the data source handles open at scrape time
+type poolCollector struct {
+	stats func() map[uint]sql.DBStats
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolOpenDesc
+	ch <- poolInUseDesc
+	ch <- poolIdleDesc
+	ch <- poolWaitCountDesc
+	ch <- poolWaitDurationDesc
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	for id, stats := range c.stats() {
+		label := strconv.FormatUint(uint64(id), 10)
+		ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections), label)
+		ch <- prometheus.MustNewConstMetric(poolInUseDesc, prometheus.GaugeValue, float64(stats.InUse), label)
+		ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(stats.Idle), label)
+		ch <- prometheus.MustNewConstMetric(poolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount), label)
+		ch <- prometheus.MustNewConstMetric(poolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds(), label)
+	}
+}
@@ -0,0 +1,219 @@
+// Package pdf writes simple text documents as PDF: headings, wrapped
+// paragraphs and fixed-width lines on A4 pages, using the standard Helvetica
+// and Courier fonts so no font files need to be embedded. Text outside the
+// Windows-1252 character set is replaced with "?".
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth  = 595.0 // A4 in points
+	pageHeight = 842.0
+	margin     = 50.0
+
+	headingSize = 14.0
+	textSize    = 10.0
+	monoSize    = 8.0
+	lineSpacing = 1.4
+
+	// Average glyph widths as a fraction of the font size; Courier is exact
+	helveticaWidth = 0.5
+	courierWidth   = 0.6
+)
+
+// Fonts of the standard 14 used by the writer
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontMono    = "F3" // Courier
+)
+
+// line is a line of text placed on a page
+type line struct {
+	font string
+	size float64
+	y    float64
+	text string
+}
+
+// Document is a PDF being written. Content flows top to bottom and starts a
+// new page when the current one is full.
+type Document struct {
+	pages [][]line
+	y     float64 // Baseline of the next line on the current page
+}
+
+// New creates an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// Heading adds a bold heading, wrapped to the page width
+func (d *Document) Heading(text string) {
+	d.Space()
+	for _, l := range wrap(text, maxChars(headingSize, helveticaWidth)) {
+		d.add(fontBold, headingSize, l)
+	}
+}
+
+// Text adds a paragraph, wrapped to the page width
+func (d *Document) Text(text string) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, l := range wrap(paragraph, maxChars(textSize, helveticaWidth)) {
+			d.add(fontRegular, textSize, l)
+		}
+	}
+}
+
+// Mono adds a line in a fixed-width font, e.g. a table row. Lines longer than
+// the page width are cut off.
+func (d *Document) Mono(text string) {
+	if limit := maxChars(monoSize, courierWidth); len([]rune(text)) > limit {
+		text = string([]rune(text)[:limit])
+	}
+	d.add(fontMono, monoSize, text)
+}
+
+// MonoWidth is the number of characters that fit on a Mono line
+func MonoWidth() int {
+	return maxChars(monoSize, courierWidth)
+}
+
+// Space adds a blank line
+func (d *Document) Space() {
+	if len(d.pages) > 0 {
+		d.y -= textSize * lineSpacing
+	}
+}
+
+// add places a line, starting a new page when it does not fit
+func (d *Document) add(font string, size float64, text string) {
+	height := size * lineSpacing
+	if len(d.pages) == 0 || d.y-height < margin {
+		d.pages = append(d.pages, nil)
+		d.y = pageHeight - margin
+	}
+	d.y -= height
+	page := len(d.pages) - 1
+	d.pages[page] = append(d.pages[page], line{font: font, size: size, y: d.y, text: text})
+}
+
+// Bytes renders the document. An empty document has one blank page.
+func (d *Document) Bytes() []byte {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = [][]line{nil}
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objects 1-5 are the catalog, the page tree and the fonts; each page is
+	// followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 7+2*i))
+
+		var content bytes.Buffer
+		for _, l := range page {
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", l.font, l.size, margin, l.y, encode(l.text))
+		}
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// maxChars is the number of characters of a font that fit on a line
+func maxChars(size, width float64) int {
+	return int((pageWidth - 2*margin) / (size * width))
+}
+
+// wrap breaks text into lines of at most limit characters at spaces; words
+// longer than a line are split
+func wrap(text string, limit int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		for len([]rune(word)) > limit {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			lines = append(lines, string([]rune(word)[:limit]))
+			word = string([]rune(word)[limit:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= limit:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	return append(lines, current)
+}
+
+// winAnsi maps the characters of Windows-1252 outside Latin-1 to their codes
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// encode converts text to a Windows-1252 PDF string body, escaping the
+// characters that delimit strings
+func encode(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		case r == '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
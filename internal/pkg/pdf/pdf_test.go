@@ -0,0 +1,67 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytesWritesValidStructure(t *testing.T) {
+	doc := New()
+	doc.Heading("Weekly sales")
+	doc.Text("Revenue (IDR) grew 4.2% — see the table below")
+	doc.Mono("region  revenue")
+
+	out := doc.Bytes()
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+
+	// The xref offsets point at the objects they list
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, xref)
+	start, err := strconv.Atoi(string(xref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[start:], []byte("xref\n0 8\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[start:], -1)
+	require.Len(t, entries, 7)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))), "object %d", i+1)
+	}
+
+	assert.Contains(t, string(out), `(Revenue \(IDR\) grew 4.2% `+"\x97"+` see the table below) Tj`)
+	assert.Contains(t, string(out), "/F2 14.0 Tf")
+	assert.Contains(t, string(out), "/F3 8.0 Tf")
+}
+
+func TestLongContentStartsNewPages(t *testing.T) {
+	doc := New()
+	for i := 0; i < 100; i++ {
+		doc.Text(fmt.Sprintf("Line %d", i))
+	}
+
+	out := string(doc.Bytes())
+	assert.Contains(t, out, "/Count 2")
+	assert.Equal(t, 2, strings.Count(out, "/Type /Page /Parent"))
+}
+
+func TestEmptyDocumentHasOnePage(t *testing.T) {
+	assert.Contains(t, string(New().Bytes()), "/Count 1")
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"the quick", "brown fox"}, wrap("the quick brown fox", 10))
+	assert.Equal(t, []string{"abcde", "fghij", "k"}, wrap("abcdefghijk", 5))
+	assert.Equal(t, []string{""}, wrap("   ", 5))
+}
+
+func TestEncodeReplacesUnsupportedCharacters(t *testing.T) {
+	assert.Equal(t, "Rp 1.2M \\(\\\\\\) ? \x80", encode("Rp 1.2M (\\) 日 €"))
+	assert.Equal(t, "caf\xe9", encode("café"))
+}
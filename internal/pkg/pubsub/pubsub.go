@@ -0,0 +1,66 @@
+// Package pubsub delivers messages between the parts of the application, in
+// process or across instances through Redis
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Broker delivers the messages published on a channel to every subscriber of
+// the channel, on every instance sharing the broker
+type Broker interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	// Subscribe calls handle with every message published on the channel until
+	// ctx is done. handle must not block.
+	Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error
+}
+
+// MemoryBroker delivers messages within the process
+type MemoryBroker struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[*subscription]bool
+}
+
+type subscription struct {
+	handle func(payload []byte)
+}
+
+// NewMemoryBroker creates a broker for a single instance
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscriptions: make(map[string]map[*subscription]bool)}
+}
+
+// Publish calls the subscribers of the channel before returning
+func (b *MemoryBroker) Publish(_ context.Context, channel string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscriptions[channel] {
+		sub.handle(payload)
+	}
+	return nil
+}
+
+// Subscribe registers handle until ctx is done
+func (b *MemoryBroker) Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error {
+	sub := &subscription{handle: handle}
+
+	b.mu.Lock()
+	if b.subscriptions[channel] == nil {
+		b.subscriptions[channel] = make(map[*subscription]bool)
+	}
+	b.subscriptions[channel][sub] = true
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscriptions[channel], sub)
+		if len(b.subscriptions[channel]) == 0 {
+			delete(b.subscriptions, channel)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,36 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBroker_Publish(t *testing.T) {
+	broker := NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var first, second, other []string
+	require.NoError(t, broker.Subscribe(ctx, "events", func(payload []byte) { first = append(first, string(payload)) }))
+	require.NoError(t, broker.Subscribe(context.Background(), "events", func(payload []byte) { second = append(second, string(payload)) }))
+	require.NoError(t, broker.Subscribe(context.Background(), "other", func(payload []byte) { other = append(other, string(payload)) }))
+
+	require.NoError(t, broker.Publish(context.Background(), "events", []byte("a")))
+	assert.Equal(t, []string{"a"}, first)
+	assert.Equal(t, []string{"a"}, second)
+	assert.Empty(t, other)
+
+	// Subscriptions end with their context
+	cancel()
+	assert.Eventually(t, func() bool {
+		broker.mu.RLock()
+		defer broker.mu.RUnlock()
+		return len(broker.subscriptions["events"]) == 1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, broker.Publish(context.Background(), "events", []byte("b")))
+	assert.Equal(t, []string{"a"}, first)
+	assert.Equal(t, []string{"a", "b"}, second)
+}
@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBroker delivers messages through Redis pub/sub, so they reach the
+// subscribers of every instance. Messages published while an instance is
+// disconnected are lost to it.
+type RedisBroker struct {
+	client *redis.Client
+}
+
+// NewRedisBroker connects to the Redis server of a redis:// URL
+func NewRedisBroker(url string) (*RedisBroker, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	return &RedisBroker{client: redis.NewClient(options)}, nil
+}
+
+// Publish sends the message to the subscribers of the channel
+func (b *RedisBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	if err := b.client.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// Subscribe waits until the subscription is confirmed, then calls handle from
+// a goroutine until ctx is done. The client reconnects after connection errors.
+func (b *RedisBroker) Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error {
+	ps := b.client.Subscribe(ctx, channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	go func() {
+		defer ps.Close()
+		messages := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				handle([]byte(msg.Payload))
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package ratelimit implements token bucket rate limiting on a store shared
+// by all instances (Redis) or local to one instance (memory).
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests requests per Per window. The bucket holds Requests
+// tokens and refills continuously, so bursts up to the full limit are allowed.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// PerMinute returns a limit of n requests per minute
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Per: time.Minute}
+}
+
+// Enabled reports whether the limit restricts anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// ratePerMs is the number of tokens added to the bucket per millisecond
+func (l Limit) ratePerMs() float64 {
+	return float64(l.Requests) / float64(l.Per.Milliseconds())
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // Time until a token is available when not allowed
+	ResetAfter time.Duration // Time until the bucket is full again
+}
+
+// Store takes tokens from the bucket of a key
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// refill returns the tokens of a bucket last updated at lastMs, at nowMs
+func refill(tokens float64, lastMs, nowMs int64, limit Limit) float64 {
+	elapsed := nowMs - lastMs
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Requests), tokens+float64(elapsed)*limit.ratePerMs())
+}
+
+// newResult describes a bucket holding tokens after the request was (not) allowed
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.ratePerMs()
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit.Requests,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration(math.Ceil((float64(limit.Requests)-tokens)/rate)) * time.Millisecond,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+	return result
+}
+
+// MemoryStore keeps buckets in process memory. Limits are per instance, so it
+// is meant for development and single-instance deployments.
+type MemoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*memoryBucket
+	lastSweepMs int64
+	now         func() time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	lastMs int64
+	fullMs int64 // When the bucket is full again and can be forgotten
+}
+
+// memorySweepInterval is how often full buckets are removed from a MemoryStore
+const memorySweepInterval = time.Minute
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Take removes a token from the bucket of the key when one is available
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nowMs := s.now().UnixMilli()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(limit.Requests), lastMs: nowMs}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = refill(bucket.tokens, bucket.lastMs, nowMs, limit)
+	bucket.lastMs = nowMs
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	result := newResult(limit, bucket.tokens, allowed)
+	bucket.fullMs = nowMs + result.ResetAfter.Milliseconds()
+
+	// Full buckets carry no state; drop them so the map does not grow without bound
+	if nowMs-s.lastSweepMs >= memorySweepInterval.Milliseconds() {
+		for k, b := range s.buckets {
+			if b.fullMs <= nowMs {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweepMs = nowMs
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := PerMinute(3)
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(context.Background(), "user:1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(context.Background(), "user:1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.ResetAfter)
+
+	// Other keys have their own bucket
+	result, err = store.Take(context.Background(), "user:2", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// One token is back after a third of the window
+	now = now.Add(20 * time.Second)
+	result, err = store.Take(context.Background(), "user:1", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := PerMinute(10)
+
+	_, err := store.Take(context.Background(), "user:1", limit)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = store.Take(context.Background(), "user:2", limit)
+	require.NoError(t, err)
+
+	assert.NotContains(t, store.buckets, "user:1")
+	assert.Contains(t, store.buckets, "user:2")
+}
+
+func TestRefill(t *testing.T) {
+	limit := PerMinute(60) // one token per second
+
+	assert.InDelta(t, 5.0, refill(0, 0, 5000, limit), 0.0001)
+	assert.Equal(t, 60.0, refill(10, 0, 600000, limit))
+	assert.Equal(t, 3.0, refill(3, 5000, 1000, limit)) // clock going back adds nothing
+}
+
+func TestLimitEnabled(t *testing.T) {
+	assert.True(t, PerMinute(1).Enabled())
+	assert.False(t, PerMinute(0).Enabled())
+	assert.False(t, Limit{Requests: 5}.Enabled())
+}
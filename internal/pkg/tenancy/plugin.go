@@ -0,0 +1,236 @@
+package tenancy
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantField is the field of the models whose rows belong to a tenant
+const tenantField = "TenantID"
+
+// Plugin scopes the statements on models with a TenantID field to the tenant
+// of the statement's context (db.WithContext):
+//   - queries, updates and deletes only match the tenant's rows, even without
+//     conditions on the tenant and with Unscoped
+//   - creates stamp the tenant on the rows, and upserts only update its rows
+//   - creating a row of another tenant or moving a row to one fails with
+//     ErrCrossTenant
+//
+// Raw and Exec SQL cannot be scoped: in the context of a tenant, raw SQL
+// naming the table of one of Models fails with ErrRawSQL. Statements without a
+// tenant in their context and on other models are not scoped.
+type Plugin struct {
+	Models []interface{} // Models whose rows belong to a tenant
+}
+
+// Name implements gorm.Plugin
+func (Plugin) Name() string {
+	return "tenancy"
+}
+
+// Initialize implements gorm.Plugin
+func (p Plugin) Initialize(db *gorm.DB) error {
+	tables := make(tenantTables, len(p.Models))
+	for _, model := range p.Models {
+		s, err := schema.Parse(model, &sync.Map{}, db.NamingStrategy)
+		if err != nil {
+			return fmt.Errorf("failed to parse tenant model: %w", err)
+		}
+		if s.LookUpField(tenantField) == nil {
+			return fmt.Errorf("tenant model %s has no %s field", s.Name, tenantField)
+		}
+		tables[s.Table] = true
+	}
+
+	if err := db.Callback().Raw().Before("gorm:raw").Register("tenancy:raw", tables.refuse); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenancy:raw_query", tables.refuse); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenancy:raw_row", tables.refuse); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register("tenancy:create", p.create); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenancy:query", p.query); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenancy:row", p.query); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenancy:update", p.update); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenancy:delete", p.delete)
+}
+
+// scope returns the tenant of the statement and the tenant field of its model,
+// when the statement is to be scoped
+func scope(db *gorm.DB) (uint, *schema.Field, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return 0, nil, false
+	}
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		return 0, nil, false
+	}
+	field := db.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return 0, nil, false
+	}
+	return tenantID, field, true
+}
+
+// tenantTables are the tables of the tenant models
+type tenantTables map[string]bool
+
+// sqlWord matches the identifiers of SQL statements
+var sqlWord = regexp.MustCompile(`[a-z_][a-z0-9_]*`)
+
+// refuse fails raw SQL on tenant tables in the context of a tenant. Raw SQL is
+// the SQL of the statement before GORM builds it.
+func (t tenantTables) refuse(db *gorm.DB) {
+	if db.Error != nil || len(t) == 0 || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	if _, ok := FromContext(db.Statement.Context); !ok {
+		return
+	}
+	for _, word := range sqlWord.FindAllString(strings.ToLower(db.Statement.SQL.String()), -1) {
+		if t[word] {
+			_ = db.AddError(fmt.Errorf("%w: %s", ErrRawSQL, word))
+			return
+		}
+	}
+}
+
+func tenantCondition(field *schema.Field, tenantID uint) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID}
+}
+
+func (Plugin) create(db *gorm.DB) {
+	tenantID, field, ok := scope(db)
+	if !ok {
+		return
+	}
+
+	stamp := func(rv reflect.Value) {
+		value, zero := field.ValueOf(db.Statement.Context, rv)
+		if zero {
+			if err := field.Set(db.Statement.Context, rv, tenantID); err != nil {
+				_ = db.AddError(err)
+			}
+			return
+		}
+		if id, _ := value.(uint); id != tenantID {
+			_ = db.AddError(ErrCrossTenant)
+		}
+	}
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			stamp(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(rv)
+	}
+
+	// An upsert must not take over the conflicting row of another tenant
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, tenantCondition(field, tenantID))
+			db.Statement.AddClause(onConflict)
+		}
+	}
+}
+
+func (Plugin) query(db *gorm.DB) {
+	if tenantID, field, ok := scope(db); ok {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{tenantCondition(field, tenantID)}})
+	}
+}
+
+func (Plugin) update(db *gorm.DB) {
+	tenantID, field, ok := scope(db)
+	if !ok || !hasConditions(db) {
+		return
+	}
+	if movesTenant(db, field, tenantID) {
+		_ = db.AddError(ErrCrossTenant)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{tenantCondition(field, tenantID)}})
+}
+
+func (Plugin) delete(db *gorm.DB) {
+	tenantID, field, ok := scope(db)
+	if !ok || !hasConditions(db) {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{tenantCondition(field, tenantID)}})
+}
+
+// hasConditions reports whether GORM will restrict the update or delete to
+// some rows. Statements it would refuse for lacking conditions are left
+// alone, so the tenant condition does not turn them into tenant-wide ones.
+func hasConditions(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["WHERE"]; ok || db.AllowGlobalUpdate {
+		return true
+	}
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	case reflect.Struct:
+		for _, primary := range db.Statement.Schema.PrimaryFields {
+			if _, zero := primary.ValueOf(db.Statement.Context, rv); !zero {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// movesTenant reports whether the update assigns another tenant to the rows
+func movesTenant(db *gorm.DB, field *schema.Field, tenantID uint) bool {
+	var value interface{}
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		if v, ok := dest[field.DBName]; ok {
+			value = v
+		} else if v, ok := dest[field.Name]; ok {
+			value = v
+		}
+	default:
+		rv := reflect.Indirect(reflect.ValueOf(dest))
+		if rv.Kind() != reflect.Struct || rv.Type() != db.Statement.Schema.ModelType {
+			return false
+		}
+		v, zero := field.ValueOf(db.Statement.Context, rv)
+		if zero {
+			return false
+		}
+		value = v
+	}
+	if value == nil {
+		return false
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() != int64(tenantID)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() != uint64(tenantID)
+	}
+	return true
+}
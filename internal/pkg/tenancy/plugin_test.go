@@ -0,0 +1,137 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type note struct {
+	ID        uint
+	TenantID  uint `gorm:"not null;default:1;index"`
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+// tag has no tenant, like the tables whose rows belong to a tenant through their parent
+type tag struct {
+	ID   uint
+	Name string
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(Plugin{Models: []interface{}{&note{}}}))
+	require.NoError(t, db.AutoMigrate(&note{}, &tag{}))
+	return db
+}
+
+func TestPluginIsolatesTenants(t *testing.T) {
+	db := newTestDB(t)
+	acme := WithTenant(context.Background(), 1)
+	globex := WithTenant(context.Background(), 2)
+
+	own := note{Title: "acme"}
+	require.NoError(t, db.WithContext(acme).Create(&own).Error)
+	assert.Equal(t, uint(1), own.TenantID, "the tenant is stamped on created rows")
+	other := note{Title: "globex"}
+	require.NoError(t, db.WithContext(globex).Create(&other).Error)
+	assert.Equal(t, uint(2), other.TenantID)
+
+	var notes []note
+	require.NoError(t, db.WithContext(acme).Find(&notes).Error)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "acme", notes[0].Title)
+
+	// Rows of another tenant cannot be read, changed or deleted, even by ID
+	var found note
+	assert.ErrorIs(t, db.WithContext(acme).First(&found, other.ID).Error, gorm.ErrRecordNotFound)
+	var count int64
+	require.NoError(t, db.WithContext(acme).Unscoped().Model(&note{}).Where("id = ?", other.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	result := db.WithContext(acme).Model(&note{ID: other.ID}).Update("title", "taken")
+	require.NoError(t, result.Error)
+	assert.Zero(t, result.RowsAffected)
+	result = db.WithContext(acme).Unscoped().Delete(&note{}, other.ID)
+	require.NoError(t, result.Error)
+	assert.Zero(t, result.RowsAffected)
+
+	// Saving a row with the ID of another tenant's row does not take it over
+	require.NoError(t, db.WithContext(acme).Save(&note{ID: other.ID, Title: "taken"}).Error)
+
+	// Without a tenant in the context nothing is scoped
+	require.NoError(t, db.Find(&notes).Error)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "globex", notes[1].Title)
+	assert.Equal(t, uint(2), notes[1].TenantID)
+
+	// Models without a tenant are not scoped
+	require.NoError(t, db.WithContext(globex).Create(&tag{Name: "shared"}).Error)
+	var tags []tag
+	require.NoError(t, db.WithContext(acme).Find(&tags).Error)
+	assert.Len(t, tags, 1)
+}
+
+func TestPluginRefusesCrossTenantWrites(t *testing.T) {
+	db := newTestDB(t)
+	acme := WithTenant(context.Background(), 1)
+
+	assert.ErrorIs(t, db.WithContext(acme).Create(&note{TenantID: 2, Title: "smuggled"}).Error, ErrCrossTenant)
+	assert.ErrorIs(t, db.WithContext(acme).Create(&[]note{{Title: "ok"}, {TenantID: 2}}).Error, ErrCrossTenant)
+
+	own := note{Title: "acme"}
+	require.NoError(t, db.WithContext(acme).Create(&own).Error)
+	assert.ErrorIs(t, db.WithContext(acme).Model(&own).Update("tenant_id", 2).Error, ErrCrossTenant)
+	own.TenantID = 2
+	assert.ErrorIs(t, db.WithContext(acme).Save(&own).Error, ErrCrossTenant)
+
+	// Updates without conditions are still refused rather than applied to the whole tenant
+	assert.ErrorIs(t, db.WithContext(acme).Model(&note{}).Update("title", "all").Error, gorm.ErrMissingWhereClause)
+	assert.ErrorIs(t, db.WithContext(acme).Delete(&note{}).Error, gorm.ErrMissingWhereClause)
+}
+
+func TestPluginRefusesRawSQLOnTenantTables(t *testing.T) {
+	db := newTestDB(t)
+	acme := WithTenant(context.Background(), 1)
+	require.NoError(t, db.Create(&[]note{{TenantID: 1, Title: "acme"}, {TenantID: 2, Title: "globex"}}).Error)
+
+	var count int64
+	assert.ErrorIs(t, db.WithContext(acme).Raw("SELECT COUNT(*) FROM notes").Scan(&count).Error, ErrRawSQL)
+	var notes []note
+	assert.ErrorIs(t, db.WithContext(acme).Raw("SELECT * FROM notes").Find(&notes).Error, ErrRawSQL)
+	assert.ErrorIs(t, db.WithContext(acme).Exec("UPDATE notes SET title = ?", "taken").Error, ErrRawSQL)
+	assert.ErrorIs(t, db.WithContext(acme).Exec(`DELETE FROM "notes"`).Error, ErrRawSQL)
+
+	// Raw SQL on other tables, and in the context of the installation, runs
+	require.NoError(t, db.WithContext(acme).Exec("INSERT INTO tags (name) VALUES (?)", "shared").Error)
+	require.NoError(t, db.WithContext(WithTenant(acme, 0)).Raw("SELECT COUNT(*) FROM notes").Scan(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// Built statements are scoped rather than refused
+	require.NoError(t, db.WithContext(acme).Model(&note{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	require.NoError(t, db.WithContext(acme).Find(&notes).Error)
+	assert.Len(t, notes, 1)
+}
+
+func TestPluginRejectsModelsWithoutTenant(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	assert.EqualError(t, db.Use(Plugin{Models: []interface{}{&tag{}}}), "tenant model tag has no TenantID field")
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	tenantID, ok := FromContext(WithTenant(context.Background(), 3))
+	assert.True(t, ok)
+	assert.Equal(t, uint(3), tenantID)
+}
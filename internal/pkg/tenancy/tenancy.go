@@ -0,0 +1,32 @@
+// Package tenancy isolates the rows of tenants sharing the application
+// database. The tenant of a request travels in its context; the GORM plugin
+// scopes every statement run with that context to the tenant's rows.
+package tenancy
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCrossTenant is returned when a statement would write a row of another tenant
+var ErrCrossTenant = errors.New("row belongs to another tenant")
+
+// ErrRawSQL is returned when raw SQL on a tenant table runs in the context of a
+// tenant. Such SQL runs with the context of the installation, WithTenant(ctx, 0).
+var ErrRawSQL = errors.New("raw SQL on a tenant table cannot be scoped to the tenant")
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant of ctx, if it carries one
+func FromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantID, ok := ctx.Value(tenantKey{}).(uint)
+	return tenantID, ok && tenantID != 0
+}
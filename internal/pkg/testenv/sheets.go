@@ -0,0 +1,108 @@
+//go:build integration
+
+package testenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// Sheet is the content of one sheet of a fake spreadsheet
+type Sheet struct {
+	Title    string
+	RowCount int64 // Grid rows; defaults to 1000 like a new Google sheet
+	Values   [][]interface{}
+}
+
+// SheetsServer fakes the parts of the Google Sheets v4 REST API the connector
+// uses. There is no Sheets emulator image, so unlike the other backends it runs
+// in process. Point GOOGLE_SHEETS_ENDPOINT at URL to use it.
+type SheetsServer struct {
+	*httptest.Server
+	URL string // Endpoint including the trailing slash the client expects
+}
+
+// NewSheetsServer serves the given spreadsheets, keyed by spreadsheet ID
+func NewSheetsServer(spreadsheets map[string][]Sheet) *SheetsServer {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// /v4/spreadsheets/{id} or /v4/spreadsheets/{id}/values/{range}
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/"), "/", 3)
+		sheets, ok := spreadsheets[parts[0]]
+		if !ok || r.Method != http.MethodGet {
+			writeSheetsError(w, http.StatusNotFound, "Requested entity was not found.")
+			return
+		}
+
+		if len(parts) == 1 {
+			writeSpreadsheet(w, parts[0], sheets)
+			return
+		}
+		if len(parts) == 3 && parts[1] == "values" {
+			writeValues(w, sheets, parts[2])
+			return
+		}
+		writeSheetsError(w, http.StatusNotFound, "Unknown method")
+	}))
+
+	return &SheetsServer{Server: server, URL: server.URL + "/"}
+}
+
+func writeSpreadsheet(w http.ResponseWriter, id string, sheets []Sheet) {
+	properties := make([]map[string]interface{}, len(sheets))
+	for i, sheet := range sheets {
+		rowCount := sheet.RowCount
+		if rowCount == 0 {
+			rowCount = 1000
+		}
+		properties[i] = map[string]interface{}{
+			"properties": map[string]interface{}{
+				"sheetId":        i,
+				"title":          sheet.Title,
+				"index":          i,
+				"gridProperties": map[string]interface{}{"rowCount": rowCount, "columnCount": 26},
+			},
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"spreadsheetId": id, "sheets": properties})
+}
+
+// writeValues answers row ranges like "Orders!2:101"
+func writeValues(w http.ResponseWriter, sheets []Sheet, a1Range string) {
+	title, rows, _ := strings.Cut(a1Range, "!")
+	for _, sheet := range sheets {
+		if sheet.Title != title {
+			continue
+		}
+
+		first, last := 1, len(sheet.Values)
+		if from, to, ok := strings.Cut(rows, ":"); ok {
+			first, _ = strconv.Atoi(from)
+			if n, err := strconv.Atoi(to); err == nil && n < last {
+				last = n
+			}
+		}
+
+		var values [][]interface{}
+		for i := first - 1; i >= 0 && i < last; i++ {
+			values = append(values, sheet.Values[i])
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"range": a1Range, "majorDimension": "ROWS", "values": values})
+		return
+	}
+	writeSheetsError(w, http.StatusBadRequest, "Unable to parse range: "+a1Range)
+}
+
+func writeSheetsError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"code": status, "message": message},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,157 @@
+//go:build integration
+
+// Package testenv starts the backends used by the opt-in integration tests.
+// Run them with `make test-integration` (go test -tags integration); Docker
+// must be available.
+package testenv
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// containerTTL is how long Docker keeps a container when a test run is killed
+// before it could purge it
+const containerTTL = 10 * time.Minute
+
+// Environment holds the containers started for one test binary
+type Environment struct {
+	pool      *dockertest.Pool
+	resources []*dockertest.Resource
+}
+
+// Postgres is a disposable PostgreSQL server
+type Postgres struct {
+	DB     *sql.DB
+	Config map[string]interface{} // Data source config for the PostgreSQL connector
+}
+
+// BigQueryEmulator is a disposable BigQuery emulator with one empty dataset
+type BigQueryEmulator struct {
+	Host      string // host:port, the value for BIGQUERY_EMULATOR_HOST
+	ProjectID string
+	DatasetID string
+	Config    map[string]interface{} // Data source config for the BigQuery connector
+}
+
+// New connects to the local Docker daemon
+func New() (*Environment, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker pool: %w", err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to reach docker: %w", err)
+	}
+	pool.MaxWait = 2 * time.Minute
+
+	return &Environment{pool: pool}, nil
+}
+
+// Close removes every container started by the environment
+func (e *Environment) Close() {
+	for _, resource := range e.resources {
+		e.pool.Purge(resource)
+	}
+	e.resources = nil
+}
+
+// StartPostgres starts PostgreSQL with the pgvector extension available, so
+// the migrations can run on it, and waits until it accepts connections
+func (e *Environment) StartPostgres() (*Postgres, error) {
+	resource, err := e.run(&dockertest.RunOptions{
+		Repository: "pgvector/pgvector",
+		Tag:        "pg16",
+		Env: []string{
+			"POSTGRES_USER=narapulse",
+			"POSTGRES_PASSWORD=narapulse",
+			"POSTGRES_DB=narapulse_test",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]interface{}{
+		"host":     resource.GetBoundIP("5432/tcp"),
+		"port":     resource.GetPort("5432/tcp"),
+		"database": "narapulse_test",
+		"username": "narapulse",
+		"password": "narapulse",
+		"ssl_mode": "disable",
+	}
+	dsn := fmt.Sprintf("host=%s port=%s user=narapulse password=narapulse dbname=narapulse_test sslmode=disable",
+		config["host"], config["port"])
+
+	var db *sql.DB
+	err = e.pool.Retry(func() error {
+		var err error
+		if db, err = sql.Open("postgres", dsn); err != nil {
+			return err
+		}
+		return db.Ping()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("postgres did not become ready: %w", err)
+	}
+
+	return &Postgres{DB: db, Config: config}, nil
+}
+
+// StartBigQueryEmulator starts the goccy/bigquery-emulator with an empty dataset
+func (e *Environment) StartBigQueryEmulator() (*BigQueryEmulator, error) {
+	emulator := &BigQueryEmulator{ProjectID: "narapulse-test", DatasetID: "analytics"}
+	resource, err := e.run(&dockertest.RunOptions{
+		Repository:   "ghcr.io/goccy/bigquery-emulator",
+		Tag:          "0.6.6",
+		Platform:     "linux/amd64",
+		Cmd:          []string{"--project=" + emulator.ProjectID, "--dataset=" + emulator.DatasetID},
+		ExposedPorts: []string{"9050/tcp"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	emulator.Host = resource.GetHostPort("9050/tcp")
+	emulator.Config = map[string]interface{}{
+		"project_id": emulator.ProjectID,
+		"dataset_id": emulator.DatasetID,
+	}
+
+	datasetURL := fmt.Sprintf("http://%s/bigquery/v2/projects/%s/datasets/%s", emulator.Host, emulator.ProjectID, emulator.DatasetID)
+	err = e.pool.Retry(func() error {
+		resp, err := http.Get(datasetURL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("emulator returned %s", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bigquery emulator did not become ready: %w", err)
+	}
+
+	return emulator, nil
+}
+
+func (e *Environment) run(options *dockertest.RunOptions) (*dockertest.Resource, error) {
+	resource, err := e.pool.RunWithOptions(options, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s:%s: %w", options.Repository, options.Tag, err)
+	}
+	resource.Expire(uint(containerTTL.Seconds()))
+	e.resources = append(e.resources, resource)
+	return resource, nil
+}
@@ -0,0 +1,78 @@
+// Package tokenizer counts the tokens of prompt text. It approximates the BPE
+// tokenizers of OpenAI chat models (cl100k, o200k): text is split the way they
+// pre-tokenize it, and pieces longer than a typical vocabulary entry count as
+// several tokens. Counts are close to the real tokenizer's for English and
+// Indonesian prompts, schema names and SQL, without loading a vocabulary.
+package tokenizer
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pieces splits text like the cl100k pre-tokenizer: contractions, words with
+// their leading space, numbers of up to three digits, punctuation runs and
+// whitespace
+var pieces = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)| ?\pL+| ?\pN{1,3}| ?[^\s\pL\pN]+|\s+`)
+
+const (
+	// wordRunes is about how many letters of a word one token covers; common
+	// words are a single token
+	wordRunes = 7
+	// symbolRunes is about how many punctuation characters one token covers
+	symbolRunes = 2
+)
+
+// Count returns the number of tokens of text
+func Count(text string) int {
+	count := 0
+	for _, piece := range pieces.FindAllString(text, -1) {
+		count += pieceTokens(piece)
+	}
+	return count
+}
+
+// Fits reports whether text fits a budget of tokens; a budget of 0 or less has no limit
+func Fits(text string, budget int) bool {
+	return budget <= 0 || Count(text) <= budget
+}
+
+// pieceTokens returns the tokens of a pre-tokenized piece
+func pieceTokens(piece string) int {
+	first, size := utf8.DecodeRuneInString(piece)
+	if first == ' ' && len(piece) > 1 {
+		// The leading space is part of the token
+		first, _ = utf8.DecodeRuneInString(piece[size:])
+		piece = piece[size:]
+	}
+	runes := utf8.RuneCountInString(piece)
+
+	switch {
+	case unicode.IsSpace(first):
+		return 1
+	case unicode.IsNumber(first):
+		return 1
+	case unicode.IsLetter(first):
+		if !isLatin(piece) {
+			return runes // Scripts such as CJK take about a token per character
+		}
+		return ceilDiv(runes, wordRunes)
+	default:
+		return ceilDiv(runes, symbolRunes)
+	}
+}
+
+// isLatin reports whether every letter of a word is ASCII or Latin-1
+func isLatin(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxLatin1 && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
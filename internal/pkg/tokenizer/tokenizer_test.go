@@ -0,0 +1,34 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello", 1},
+		{"Hello world", 2},
+		{"SELECT * FROM orders", 4},
+		{"total_revenue", 3},        // total, _, revenue
+		{"12345", 2},                // 123, 45
+		{"penjualan bulan lalu", 4}, // penj-ualan, bulan, lalu
+		{"销售额", 3},
+		{"a\n\nb", 3},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Count(tt.text), tt.text)
+	}
+}
+
+func TestFits(t *testing.T) {
+	assert.True(t, Fits("SELECT * FROM orders", 4))
+	assert.False(t, Fits("SELECT * FROM orders", 3))
+	assert.True(t, Fits("SELECT * FROM orders", 0))
+}
@@ -7,21 +7,39 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token purposes. Access tokens authorize API calls; the others only allow
+// completing a login that needs a second factor.
+const (
+	TokenPurposeAccess        = ""
+	TokenPurposeMFA           = "mfa"            // Password checked, TOTP or recovery code still needed
+	TokenPurposeMFAEnrollment = "mfa_enrollment" // MFA is required but not set up yet
+	TokenPurposeEmbed         = "embed"          // Read-only access to one embedded dashboard or widget
+)
+
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID   uint   `json:"user_id"`
+	TenantID uint   `json:"tenant_id,omitempty"` // Tenant of the user; tokens issued before tenancy have none
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Purpose  string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken generates a JWT token for a user
-func GenerateToken(userID uint, email, role, secret string) (string, error) {
+func GenerateToken(userID, tenantID uint, email, role, secret string) (string, error) {
+	return GenerateScopedToken(userID, tenantID, email, role, TokenPurposeAccess, secret, 24*time.Hour)
+}
+
+// GenerateScopedToken generates a JWT token for a purpose that expires after ttl
+func GenerateScopedToken(userID, tenantID uint, email, role, purpose, secret string, ttl time.Duration) (string, error) {
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:   userID,
+		TenantID: tenantID,
+		Email:    email,
+		Role:     role,
+		Purpose:  purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "narapulse-be",
@@ -51,4 +69,55 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 	}
 
 	return nil, errors.New("invalid token")
-}
\ No newline at end of file
+}
+
+// EmbedClaims scope an embed token to one dashboard, or one widget of it, and
+// optionally to the domains allowed to embed it
+type EmbedClaims struct {
+	UserID      uint     `json:"user_id"` // Who issued the token
+	Purpose     string   `json:"purpose"`
+	DashboardID uint     `json:"dashboard_id"`
+	WidgetID    uint     `json:"widget_id,omitempty"` // Only this widget when set
+	Domains     []string `json:"domains,omitempty"`   // Hosts allowed to embed; any when empty
+	RowLimit    int      `json:"row_limit"`
+	jwt.RegisteredClaims
+}
+
+// GenerateEmbedToken generates an embed token that expires after ttl
+func GenerateEmbedToken(claims EmbedClaims, secret string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims.Purpose = TokenPurposeEmbed
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    "narapulse-be",
+		Subject:   "embed",
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
+	signed, err := token.SignedString([]byte(secret))
+	return signed, expiresAt, err
+}
+
+// ValidateEmbedToken validates an embed token and returns its claims. Access
+// tokens and the other scoped tokens are refused.
+func ValidateEmbedToken(tokenString, secret string) (*EmbedClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &EmbedClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secret), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*EmbedClaims)
+	if !ok || !token.Valid || claims.Purpose != TokenPurposeEmbed || claims.DashboardID == 0 {
+		return nil, errors.New("invalid embed token")
+	}
+	return claims, nil
+}
@@ -0,0 +1,296 @@
+// Package websocket implements the server side of the WebSocket protocol
+// (RFC 6455) on Fiber requests, enough to push messages to browsers and read
+// their small control messages. Extensions and subprotocols are not supported.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// acceptGUID is appended to the key of the client to compute the accept header
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of the frames
+const (
+	OpContinuation byte = 0x0
+	OpText         byte = 0x1
+	OpBinary       byte = 0x2
+	OpClose        byte = 0x8
+	OpPing         byte = 0x9
+	OpPong         byte = 0xA
+)
+
+// Close codes
+const (
+	CloseNormal          uint16 = 1000
+	CloseGoingAway       uint16 = 1001
+	CloseProtocolError   uint16 = 1002
+	CloseMessageTooBig   uint16 = 1009
+	CloseTryAgainLater   uint16 = 1013
+	closeNoStatusPresent uint16 = 1005
+)
+
+// MaxMessageSize is the largest message read from a client
+const MaxMessageSize = 64 * 1024
+
+// writeTimeout bounds how long a write may block on a slow client
+const writeTimeout = 10 * time.Second
+
+var (
+	// ErrNotUpgrade is returned when a request does not ask for a WebSocket
+	ErrNotUpgrade = errors.New("not a WebSocket upgrade request")
+	// ErrProtocol is returned when the client breaks the protocol
+	ErrProtocol = errors.New("WebSocket protocol error")
+	// ErrMessageTooBig is returned when a message of the client exceeds MaxMessageSize
+	ErrMessageTooBig = errors.New("WebSocket message too big")
+)
+
+// CloseError is returned by ReadMessage once the client closed the connection
+type CloseError struct {
+	Code   uint16
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("WebSocket closed with %d %s", e.Code, e.Reason)
+}
+
+// IsUpgrade reports whether the request asks to switch to WebSocket
+func IsUpgrade(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodGet &&
+		strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") &&
+		headerContainsToken(c.Get(fiber.HeaderConnection), "upgrade")
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for the key of a client
+func AcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Upgrade answers the handshake and runs handle with the connection once the
+// response is sent. The Fiber context must not be used from handle; the
+// connection is closed when it returns.
+func Upgrade(c *fiber.Ctx, handle func(conn *Conn)) error {
+	if !IsUpgrade(c) {
+		return ErrNotUpgrade
+	}
+	key := c.Get("Sec-WebSocket-Key")
+	if key == "" || c.Get("Sec-WebSocket-Version") != "13" {
+		return fmt.Errorf("%w: Sec-WebSocket-Key and Sec-WebSocket-Version 13 are required", ErrNotUpgrade)
+	}
+
+	c.Status(fiber.StatusSwitchingProtocols)
+	c.Set(fiber.HeaderUpgrade, "websocket")
+	c.Set(fiber.HeaderConnection, "Upgrade")
+	c.Set("Sec-WebSocket-Accept", AcceptKey(key))
+	c.Context().Hijack(func(netConn net.Conn) {
+		conn := NewConn(netConn)
+		defer conn.Close()
+		handle(conn)
+	})
+	return nil
+}
+
+func headerContainsToken(header, token string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn is the server side of a WebSocket connection. Writes may be made from
+// several goroutines; reads from one at a time.
+type Conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// NewConn wraps a connection that completed the handshake
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// WriteText sends a text message
+func (c *Conn) WriteText(data []byte) error {
+	return c.writeFrame(OpText, data)
+}
+
+// WritePing sends a ping the client answers with a pong
+func (c *Conn) WritePing() error {
+	return c.writeFrame(OpPing, nil)
+}
+
+// WriteClose starts the closing handshake
+func (c *Conn) WriteClose(code uint16, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, code)
+	return c.writeFrame(OpClose, append(payload, reason...))
+}
+
+// Close closes the connection without a closing handshake
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	if opcode == OpClose {
+		c.closed = true
+	}
+
+	// Server frames are final and unmasked
+	header := []byte{0x80 | opcode, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	n := 2
+	switch length := len(payload); {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+		n = 4
+	default:
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+		n = 10
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(header[:n], payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadMessage returns the next text or binary message of the client. Pings
+// are answered on the way. Once the client closes the connection, the close
+// is answered and a *CloseError returned; protocol errors close the
+// connection with the matching code.
+func (c *Conn) ReadMessage() (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+	for {
+		fin, frameOpcode, payload, err := c.readFrame()
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrMessageTooBig):
+				_ = c.WriteClose(CloseMessageTooBig, "")
+			case errors.Is(err, ErrProtocol):
+				_ = c.WriteClose(CloseProtocolError, "")
+			}
+			return 0, nil, err
+		}
+
+		switch frameOpcode {
+		case OpPing:
+			if err := c.writeFrame(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			closeErr := &CloseError{Code: closeNoStatusPresent}
+			if len(payload) >= 2 {
+				closeErr.Code = binary.BigEndian.Uint16(payload)
+				closeErr.Reason = string(payload[2:])
+			}
+			_ = c.WriteClose(CloseNormal, "")
+			return 0, nil, closeErr
+		case OpText, OpBinary:
+			if opcode != 0 {
+				_ = c.WriteClose(CloseProtocolError, "")
+				return 0, nil, fmt.Errorf("%w: new message before the previous one ended", ErrProtocol)
+			}
+			opcode = frameOpcode
+		case OpContinuation:
+			if opcode == 0 {
+				_ = c.WriteClose(CloseProtocolError, "")
+				return 0, nil, fmt.Errorf("%w: continuation without a message", ErrProtocol)
+			}
+		default:
+			_ = c.WriteClose(CloseProtocolError, "")
+			return 0, nil, fmt.Errorf("%w: unknown opcode %d", ErrProtocol, frameOpcode)
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			_ = c.WriteClose(CloseMessageTooBig, "")
+			return 0, nil, ErrMessageTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// readFrame reads one frame and unmasks its payload
+func (c *Conn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0F
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("%w: reserved bits set", ErrProtocol)
+	}
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, fmt.Errorf("%w: client frames must be masked", ErrProtocol)
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if opcode >= OpClose && (length > 125 || !fin) {
+		return false, 0, nil, fmt.Errorf("%w: invalid control frame", ErrProtocol)
+	}
+	if length > MaxMessageSize {
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example handshake of RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// writeClientFrame writes a masked frame like a browser does
+func writeClientFrame(t *testing.T, w io.Writer, fin bool, opcode byte, payload []byte) {
+	t.Helper()
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, 0x80 | byte(len(payload)), 1, 2, 3, 4}
+	for i, b := range payload {
+		frame = append(frame, b^frame[2+i%4])
+	}
+	_, err := w.Write(frame)
+	require.NoError(t, err)
+}
+
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	_, err := io.ReadFull(r, head[:])
+	require.NoError(t, err)
+	assert.Zero(t, head[1]&0x80, "server frames are not masked")
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		_, err := io.ReadFull(r, extended[:])
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+	return head[0] & 0x0F, payload
+}
+
+func TestUpgradeAndMessages(t *testing.T) {
+	received := make(chan string, 1)
+	closed := make(chan error, 1)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", func(c *fiber.Ctx) error {
+		err := Upgrade(c, func(conn *Conn) {
+			assert.NoError(t, conn.WriteText([]byte(`{"type":"hello"}`)))
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			received <- string(message)
+			_, _, err = conn.ReadMessage()
+			closed <- err
+		})
+		if err != nil {
+			return c.Status(fiber.StatusUpgradeRequired).SendString(err.Error())
+		}
+		return nil
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer app.Shutdown()
+
+	// Plain requests are refused
+	resp, err := http.Get("http://" + listener.Addr().String() + "/ws")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	opcode, payload := readServerFrame(t, reader)
+	assert.Equal(t, OpText, opcode)
+	assert.Equal(t, `{"type":"hello"}`, string(payload))
+
+	// Pings are answered, fragments joined
+	writeClientFrame(t, conn, true, OpPing, []byte("p"))
+	opcode, payload = readServerFrame(t, reader)
+	assert.Equal(t, OpPong, opcode)
+	assert.Equal(t, "p", string(payload))
+	writeClientFrame(t, conn, false, OpText, []byte("hel"))
+	writeClientFrame(t, conn, true, OpContinuation, []byte("lo"))
+	assert.Equal(t, "hello", <-received)
+
+	// The close handshake is answered
+	writeClientFrame(t, conn, true, OpClose, []byte{0x03, 0xE8})
+	opcode, payload = readServerFrame(t, reader)
+	assert.Equal(t, OpClose, opcode)
+	assert.Equal(t, CloseNormal, binary.BigEndian.Uint16(payload))
+	var closeErr *CloseError
+	require.True(t, errors.As(<-closed, &closeErr))
+	assert.Equal(t, CloseNormal, closeErr.Code)
+}
+
+func TestReadMessageRejectsUnmaskedFrames(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := NewConn(server)
+	defer conn.Close()
+
+	go func() { _, _ = client.Write([]byte{0x81, 0x01, 'x'}) }()
+	go func() { _, _ = io.Copy(io.Discard, client) }()
+	_, _, err := conn.ReadMessage()
+	assert.ErrorIs(t, err, ErrProtocol)
+}
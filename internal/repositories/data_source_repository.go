@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"narapulse-be/internal/models/entity"
 
 	"gorm.io/gorm"
@@ -10,10 +13,20 @@ type DataSourceRepository interface {
 	Create(dataSource *models.DataSource) error
 	GetByID(id uint) (*models.DataSource, error)
 	GetByUserID(userID uint) ([]models.DataSource, error)
+	GetByIDs(ids []uint) ([]models.DataSource, error)
+	GetByType(dsType models.DataSourceType) ([]models.DataSource, error)
 	Update(dataSource *models.DataSource) error
 	Delete(id uint) error
+	DeleteCascade(id uint, deleteQueries bool) error
+	GetDeletedByID(id uint) (*models.DataSource, error)
+	Restore(dataSource *models.DataSource) error
 	GetWithSchemas(id uint) (*models.DataSource, error)
 	TestConnection(dataSource *models.DataSource) error
+	CreateDiscoveryEvent(event *models.DataSourceDiscoveryEvent) error
+	GetDiscoveryEvents(dataSourceID uint, limit int) ([]models.DataSourceDiscoveryEvent, error)
+	// WithContext returns the repository running its statements with ctx, which
+	// scopes them to the tenant of the request
+	WithContext(ctx context.Context) DataSourceRepository
 }
 
 type dataSourceRepository struct {
@@ -26,6 +39,10 @@ func NewDataSourceRepository(db *gorm.DB) DataSourceRepository {
 	}
 }
 
+func (r *dataSourceRepository) WithContext(ctx context.Context) DataSourceRepository {
+	return &dataSourceRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *dataSourceRepository) Create(dataSource *models.DataSource) error {
 	return r.db.Create(dataSource).Error
 }
@@ -45,6 +62,23 @@ func (r *dataSourceRepository) GetByUserID(userID uint) ([]models.DataSource, er
 	return dataSources, err
 }
 
+// GetByIDs returns the data sources with the IDs, in one query
+func (r *dataSourceRepository) GetByIDs(ids []uint) ([]models.DataSource, error) {
+	var dataSources []models.DataSource
+	if len(ids) == 0 {
+		return dataSources, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&dataSources).Error
+	return dataSources, err
+}
+
+// GetByType returns the data sources of a type across all users
+func (r *dataSourceRepository) GetByType(dsType models.DataSourceType) ([]models.DataSource, error) {
+	var dataSources []models.DataSource
+	err := r.db.Where("type = ?", dsType).Order("id").Find(&dataSources).Error
+	return dataSources, err
+}
+
 func (r *dataSourceRepository) Update(dataSource *models.DataSource) error {
 	return r.db.Save(dataSource).Error
 }
@@ -53,6 +87,67 @@ func (r *dataSourceRepository) Delete(id uint) error {
 	return r.db.Delete(&models.DataSource{}, id).Error
 }
 
+// DeleteCascade soft-deletes a data source with its schemas and schema
+// embeddings, and with its queries and their results when deleteQueries is set.
+// Every row gets the same deleted_at, so Restore brings back exactly this
+// deletion. Cached RAG query contexts are removed for good.
+func (r *dataSourceRepository) DeleteCascade(id uint, deleteQueries bool) error {
+	now := time.Now().Truncate(time.Microsecond)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Schema{}).Where("data_source_id = ?", id).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SchemaEmbedding{}).Where("data_source_id = ?", id).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("data_source_id = ?", id).Delete(&models.RAGQueryContext{}).Error; err != nil {
+			return err
+		}
+		if deleteQueries {
+			queryIDs := tx.Model(&models.NL2SQLQuery{}).Select("id").Where("data_source_id = ?", id)
+			if err := tx.Model(&models.QueryResult{}).Where("query_id IN (?)", queryIDs).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.NL2SQLQuery{}).Where("data_source_id = ?", id).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.DataSource{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
+}
+
+// GetDeletedByID returns a soft-deleted data source
+func (r *dataSourceRepository) GetDeletedByID(id uint) (*models.DataSource, error) {
+	var dataSource models.DataSource
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&dataSource).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dataSource, nil
+}
+
+// Restore un-deletes a data source and the rows deleted together with it by DeleteCascade
+func (r *dataSourceRepository) Restore(dataSource *models.DataSource) error {
+	deletedAt := dataSource.DeletedAt.Time
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped()
+		if err := tx.Model(&models.Schema{}).Where("data_source_id = ? AND deleted_at = ?", dataSource.ID, deletedAt).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SchemaEmbedding{}).Where("data_source_id = ? AND deleted_at = ?", dataSource.ID, deletedAt).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		queryIDs := tx.Model(&models.NL2SQLQuery{}).Select("id").Where("data_source_id = ?", dataSource.ID)
+		if err := tx.Model(&models.QueryResult{}).Where("query_id IN (?) AND deleted_at = ?", queryIDs, deletedAt).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.NL2SQLQuery{}).Where("data_source_id = ? AND deleted_at = ?", dataSource.ID, deletedAt).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.DataSource{}).Where("id = ?", dataSource.ID).Update("deleted_at", nil).Error
+	})
+}
+
 func (r *dataSourceRepository) GetWithSchemas(id uint) (*models.DataSource, error) {
 	var dataSource models.DataSource
 	err := r.db.Preload("Schemas").First(&dataSource, id).Error
@@ -62,6 +157,23 @@ func (r *dataSourceRepository) GetWithSchemas(id uint) (*models.DataSource, erro
 	return &dataSource, nil
 }
 
+func (r *dataSourceRepository) CreateDiscoveryEvent(event *models.DataSourceDiscoveryEvent) error {
+	return r.db.Create(event).Error
+}
+
+// GetDiscoveryEvents returns the latest discovery events of a data source, oldest first
+func (r *dataSourceRepository) GetDiscoveryEvents(dataSourceID uint, limit int) ([]models.DataSourceDiscoveryEvent, error) {
+	var events []models.DataSourceDiscoveryEvent
+	err := r.db.Where("data_source_id = ?", dataSourceID).Order("id DESC").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
 func (r *dataSourceRepository) TestConnection(dataSource *models.DataSource) error {
 	// This method will be implemented by specific connector services
 	// For now, just update the last_tested timestamp
@@ -73,9 +185,12 @@ type SchemaRepository interface {
 	Create(schema *models.Schema) error
 	GetByID(id uint) (*models.Schema, error)
 	GetByDataSourceID(dataSourceID uint) ([]models.Schema, error)
+	GetByDataSourceIDs(dataSourceIDs []uint) ([]models.Schema, error)
 	Update(schema *models.Schema) error
 	Delete(id uint) error
 	DeleteByDataSourceID(dataSourceID uint) error
+	// WithContext returns the repository running its statements with ctx
+	WithContext(ctx context.Context) SchemaRepository
 }
 
 type schemaRepository struct {
@@ -88,6 +203,10 @@ func NewSchemaRepository(db *gorm.DB) SchemaRepository {
 	}
 }
 
+func (r *schemaRepository) WithContext(ctx context.Context) SchemaRepository {
+	return &schemaRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *schemaRepository) Create(schema *models.Schema) error {
 	return r.db.Create(schema).Error
 }
@@ -107,6 +226,16 @@ func (r *schemaRepository) GetByDataSourceID(dataSourceID uint) ([]models.Schema
 	return schemas, err
 }
 
+// GetByDataSourceIDs returns the schemas of several data sources, in one query
+func (r *schemaRepository) GetByDataSourceIDs(dataSourceIDs []uint) ([]models.Schema, error) {
+	var schemas []models.Schema
+	if len(dataSourceIDs) == 0 {
+		return schemas, nil
+	}
+	err := r.db.Where("data_source_id IN ?", dataSourceIDs).Order("data_source_id, name").Find(&schemas).Error
+	return schemas, err
+}
+
 func (r *schemaRepository) Update(schema *models.Schema) error {
 	return r.db.Save(schema).Error
 }
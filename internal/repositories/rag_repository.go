@@ -1,17 +1,19 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"narapulse-be/internal/models/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RAGRepository interface {
 	// Schema Embeddings
 	CreateSchemaEmbedding(embedding *models.SchemaEmbedding) error
 	GetSchemaEmbeddingsByDataSource(dataSourceID uint) ([]models.SchemaEmbedding, error)
-	SearchSimilarEmbeddings(embedding []float32, dataSourceID uint, limit int) ([]models.SchemaEmbedding, error)
+	SearchSimilarEmbeddings(embedding []float32, userID uint, dataSourceID uint, limit int) ([]models.SchemaEmbedding, error)
 	DeleteSchemaEmbeddingsByDataSource(dataSourceID uint) error
 
 	// KPI Definitions
@@ -33,6 +35,10 @@ type RAGRepository interface {
 	// RAG Query Context
 	CreateRAGQueryContext(context *models.RAGQueryContext) error
 	GetRAGQueryContextsByUser(userID uint, limit int) ([]models.RAGQueryContext, error)
+
+	// WithContext returns the repository running its statements with ctx, which
+	// scopes them to the tenant of the request
+	WithContext(ctx context.Context) RAGRepository
 }
 
 type ragRepository struct {
@@ -43,6 +49,10 @@ func NewRAGRepository(db *gorm.DB) RAGRepository {
 	return &ragRepository{db: db}
 }
 
+func (r *ragRepository) WithContext(ctx context.Context) RAGRepository {
+	return &ragRepository{db: r.db.WithContext(ctx)}
+}
+
 // Schema Embeddings Implementation
 func (r *ragRepository) CreateSchemaEmbedding(embedding *models.SchemaEmbedding) error {
 	return r.db.Create(embedding).Error
@@ -54,7 +64,7 @@ func (r *ragRepository) GetSchemaEmbeddingsByDataSource(dataSourceID uint) ([]mo
 	return embeddings, err
 }
 
-func (r *ragRepository) SearchSimilarEmbeddings(embedding []float32, dataSourceID uint, limit int) ([]models.SchemaEmbedding, error) {
+func (r *ragRepository) SearchSimilarEmbeddings(embedding []float32, userID uint, dataSourceID uint, limit int) ([]models.SchemaEmbedding, error) {
 	var embeddings []models.SchemaEmbedding
 	
 	// Convert embedding to PostgreSQL vector format
@@ -67,13 +77,11 @@ func (r *ragRepository) SearchSimilarEmbeddings(embedding []float32, dataSourceI
 	}
 	embeddingStr += "]"
 	
-	err := r.db.Raw(`
-		SELECT *, (embedding <=> ?::vector) as distance 
-		FROM schema_embeddings 
-		WHERE data_source_id = ? 
-		ORDER BY embedding <=> ?::vector 
-		LIMIT ?
-	`, embeddingStr, dataSourceID, embeddingStr, limit).Scan(&embeddings).Error
+	// Built rather than raw SQL, so the search is scoped to the tenant
+	err := r.db.Where("user_id = ? AND data_source_id = ?", userID, dataSourceID).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "embedding <=> ?::vector", Vars: []interface{}{embeddingStr}, WithoutParentheses: true}}).
+		Limit(limit).
+		Find(&embeddings).Error
 	
 	return embeddings, err
 }
@@ -1,21 +1,36 @@
 package repositories
 
 import (
+	"context"
+
 	entity "narapulse-be/internal/models/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(user *entity.User) error
 	GetByID(id uint) (*entity.User, error)
+	GetByIDs(ids []uint) ([]*entity.User, error)
 	GetByEmail(email string) (*entity.User, error)
 	GetByUsername(username string) (*entity.User, error)
 	Update(user *entity.User) error
 	Delete(id uint) error
 	GetAll() ([]*entity.User, error)
+	List(filter *entity.UserListFilter) ([]*entity.User, int64, error)
+	SetActive(ids []uint, active bool) (int64, error)
+	// ActiveAdminIDs returns the active admins, locking them until the end
+	// of the transaction
+	ActiveAdminIDs() ([]uint, error)
+	// Transaction runs fn with a repository whose statements run in one
+	// transaction, committed unless fn fails
+	Transaction(fn func(repo UserRepository) error) error
 	ExistsByEmail(email string) (bool, error)
 	ExistsByUsername(username string) (bool, error)
+	// WithContext returns the repository running its statements with ctx, which
+	// scopes them to the tenant of the request
+	WithContext(ctx context.Context) UserRepository
 }
 
 type userRepository struct {
@@ -26,6 +41,10 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *userRepository) Create(user *entity.User) error {
 	return r.db.Create(user).Error
 }
@@ -39,6 +58,18 @@ func (r *userRepository) GetByID(id uint) (*entity.User, error) {
 	return &user, nil
 }
 
+// GetByIDs returns the users with the IDs, in one query
+func (r *userRepository) GetByIDs(ids []uint) ([]*entity.User, error) {
+	var users []*entity.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 	var user entity.User
 	err := r.db.Where("email = ?", email).First(&user).Error
@@ -74,6 +105,59 @@ func (r *userRepository) GetAll() ([]*entity.User, error) {
 	return users, nil
 }
 
+// List returns a page of the users matching the filter and the number of matching users.
+// The filter must already be normalized: Sort and Order are used as given.
+func (r *userRepository) List(filter *entity.UserListFilter) ([]*entity.User, int64, error) {
+	query := r.db.Model(&entity.User{})
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("email ILIKE ? OR username ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*entity.User
+	err := query.Order(filter.Sort + " " + filter.Order + ", id").
+		Limit(filter.Limit).
+		Offset((filter.Page - 1) * filter.Limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// SetActive activates or deactivates the users and returns how many changed
+func (r *userRepository) SetActive(ids []uint, active bool) (int64, error) {
+	result := r.db.Model(&entity.User{}).
+		Where("id IN ? AND is_active <> ?", ids, active).
+		Update("is_active", active)
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) ActiveAdminIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&entity.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND is_active = ?", "admin", true).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *userRepository) Transaction(fn func(repo UserRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&userRepository{db: tx})
+	})
+}
+
 func (r *userRepository) ExistsByEmail(email string) (bool, error) {
 	var count int64
 	err := r.db.Model(&entity.User{}).Where("email = ?", email).Count(&count).Error
@@ -2,28 +2,39 @@ package routes
 
 import (
 	"narapulse-be/internal/handlers"
-	"narapulse-be/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// SetupNL2SQLRoutes sets up NL2SQL related routes
-func SetupNL2SQLRoutes(router fiber.Router, nl2sqlHandler *handlers.NL2SQLHandler) {
+// SetupNL2SQLRoutes sets up NL2SQL related routes; authenticate validates the
+// token, aiLimit guards the endpoints
+// that call the LLM, piiUnmask checks the PII unmask permission on the ones
+// returning rows and ragTrace requires the RAG trace permission
+func SetupNL2SQLRoutes(router fiber.Router, nl2sqlHandler *handlers.NL2SQLHandler, authenticate, aiLimit, piiUnmask, ragTrace fiber.Handler) {
 	// NL2SQL routes group
 	nl2sql := router.Group("/nl2sql")
 	
 	// Apply authentication middleware to all NL2SQL routes
-	nl2sql.Use(middleware.AuthMiddleware())
+	nl2sql.Use(authenticate)
 
 	// Convert natural language to SQL
-	nl2sql.Post("/convert", nl2sqlHandler.ConvertNL2SQL)
+	nl2sql.Post("/convert", aiLimit, nl2sqlHandler.ConvertNL2SQL)
+
+	// Convert and execute with live progress (Server-Sent Events)
+	nl2sql.Post("/stream", aiLimit, piiUnmask, nl2sqlHandler.StreamNL2SQL)
 
 	// Execute SQL query
-	nl2sql.Post("/execute", nl2sqlHandler.ExecuteQuery)
+	nl2sql.Post("/execute", piiUnmask, nl2sqlHandler.ExecuteQuery)
+
+	// Answer a simple aggregate question with one sentence
+	nl2sql.Post("/answer", aiLimit, nl2sqlHandler.AnswerQuestion)
 
 	// Get query history
 	nl2sql.Get("/history", nl2sqlHandler.GetQueryHistory)
 
+	// Complete the question being typed with table, column, KPI and glossary names
+	nl2sql.Get("/autocomplete", nl2sqlHandler.Autocomplete)
+
 	// Validate SQL without execution
 	nl2sql.Post("/validate", nl2sqlHandler.ValidateSQL)
 
@@ -35,4 +46,22 @@ func SetupNL2SQLRoutes(router fiber.Router, nl2sqlHandler *handlers.NL2SQLHandle
 
 	// Delete query from history
 	queries.Delete("/:id", nl2sqlHandler.DeleteQuery)
+
+	// Page through stored results (?page=&limit= or ?cursor=)
+	queries.Get("/:id/results", piiUnmask, nl2sqlHandler.GetQueryResults)
+
+	// Group, pivot, sort or take the top rows of a stored result without re-querying
+	queries.Post("/:id/results/transform", piiUnmask, nl2sqlHandler.TransformQueryResults)
+
+	// What the model got: retrieved elements, prompt and validation (RAG trace permission)
+	queries.Get("/:id/trace", ragTrace, nl2sqlHandler.GetQueryTrace)
+
+	// SQL version history: edit, diff and rollback
+	queries.Put("/:id/sql", nl2sqlHandler.UpdateQuerySQL)
+	queries.Post("/:id/refine", aiLimit, nl2sqlHandler.RefineQuerySQL)
+	queries.Get("/:id/versions", nl2sqlHandler.GetQueryVersions)
+	queries.Get("/:id/lineage", nl2sqlHandler.GetQueryLineage)
+	queries.Get("/:id/versions/diff", nl2sqlHandler.DiffQueryVersions)
+	queries.Get("/:id/versions/:version", nl2sqlHandler.GetQueryVersion)
+	queries.Post("/:id/versions/:version/rollback", nl2sqlHandler.RollbackQuerySQL)
 }
\ No newline at end of file
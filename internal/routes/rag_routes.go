@@ -2,31 +2,39 @@ package routes
 
 import (
 	"narapulse-be/internal/handlers"
-	"narapulse-be/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// SetupRAGRoutes sets up RAG-related routes
-func SetupRAGRoutes(app *fiber.App, ragHandler *handlers.RAGHandler) {
+// SetupRAGRoutes sets up RAG-related routes. authenticate validates the token,
+// authorize checks the route policies, adminOnly guards admin endpoints and
+// aiLimit the endpoints that compute embeddings.
+func SetupRAGRoutes(app *fiber.App, ragHandler *handlers.RAGHandler, authenticate, authorize, adminOnly, aiLimit fiber.Handler) {
 	// Create RAG route group
 	rag := app.Group("/api/v1/rag")
 
 	// Apply authentication middleware to all RAG routes
-	rag.Use(middleware.AuthMiddleware())
+	rag.Use(authenticate, authorize)
 
 	// Search and retrieval endpoints
-	rag.Post("/search", ragHandler.SearchSimilar)
-	rag.Get("/nl2sql-context", ragHandler.BuildNL2SQLContext)
-	rag.Get("/nl2sql-prompt", ragHandler.GetEnhancedNL2SQLPrompt)
+	rag.Post("/search", aiLimit, ragHandler.SearchSimilar)
+	rag.Get("/nl2sql-context", aiLimit, ragHandler.BuildNL2SQLContext)
+	rag.Get("/nl2sql-prompt", aiLimit, ragHandler.GetEnhancedNL2SQLPrompt)
+
+	// Past questions: similar ones of the user, and frequent topics of all users (admin)
+	rag.Get("/similar-questions", aiLimit, ragHandler.GetSimilarQuestions)
+	rag.Get("/question-topics", adminOnly, ragHandler.GetQuestionTopics)
 
 	// Schema management endpoints
 	rag.Get("/schemas/:data_source_id", ragHandler.GetAvailableSchemas)
-	rag.Post("/sync/:data_source_id", ragHandler.SyncSchemaEmbeddings)
+	rag.Post("/sync/:data_source_id", aiLimit, ragHandler.SyncSchemaEmbeddings)
 
 	// KPI and Glossary management endpoints
-	rag.Post("/kpi", ragHandler.EmbedKPIDefinition)
-	rag.Post("/glossary", ragHandler.EmbedGlossaryTerm)
+	rag.Post("/kpi", aiLimit, ragHandler.EmbedKPIDefinition)
+	rag.Post("/glossary", aiLimit, ragHandler.EmbedGlossaryTerm)
+
+	// Batch embedding of existing KPIs and glossary terms (admin)
+	rag.Post("/embed-all", adminOnly, aiLimit, ragHandler.EmbedAll)
 
 	// Embedding management endpoints
 	rag.Delete("/embeddings/:data_source_id", ragHandler.DeleteEmbeddings)
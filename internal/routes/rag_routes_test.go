@@ -0,0 +1,88 @@
+package routes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"narapulse-be/internal/config"
+	"narapulse-be/internal/handlers"
+	"narapulse-be/internal/middleware"
+	entity "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+	"narapulse-be/internal/services"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// policyFileAuthorizer authorizes with the policies new installations are seeded with
+type policyFileAuthorizer struct {
+	enforcer *casbin.SyncedEnforcer
+}
+
+func (a policyFileAuthorizer) Enforce(sub, obj, act string) (bool, error) {
+	return a.enforcer.Enforce(sub, obj, act)
+}
+
+type fakeAccounts map[uint]*entity.User
+
+func (a fakeAccounts) GetAccount(_ context.Context, id uint) (*entity.User, error) {
+	return a[id], nil
+}
+
+// embeddingRoundTripper answers embedding requests without calling the API
+type embeddingRoundTripper struct{}
+
+func (embeddingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	body := `{"data":[{"embedding":[0.1,0.2,0.3],"index":0}],"model":"test","usage":{"prompt_tokens":3,"total_tokens":3}}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestRAGRoutesAllowUsersTheirSimilarQuestions(t *testing.T) {
+	// The embedding service uses the default transport
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = embeddingRoundTripper{}
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&entity.NL2SQLQuery{}, &entity.RAGQueryContext{}))
+	enforcer, err := casbin.NewSyncedEnforcer("../../configs/rbac_model.conf", "../../configs/rbac_policy.csv")
+	require.NoError(t, err)
+
+	embeddings := services.NewEmbeddingService(db, "test-key", "", nil)
+	ragService := services.NewRAGService(db, embeddings, services.NewRerankService("", "", "", 0))
+	ragHandler := handlers.NewRAGHandler(ragService, embeddings, services.NewQuestionAnalyticsService(db, ragService))
+	authorize := middleware.CasbinMiddleware(policyFileAuthorizer{enforcer: enforcer})
+	passThrough := func(c *fiber.Ctx) error { return c.Next() }
+	accounts := fakeAccounts{1: {ID: 1, Email: "analyst@narapulse.com", Role: "user", IsActive: true}}
+
+	app := fiber.New()
+	SetupRAGRoutes(app, ragHandler, middleware.AuthMiddleware(accounts), authorize, authorize, passThrough)
+
+	token, err := utils.GenerateToken(1, 1, "analyst@narapulse.com", "user", config.Load().JWTSecret)
+	require.NoError(t, err)
+	request := func(path string) int {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, request("/api/v1/rag/similar-questions?query=total+sales&data_source_id=3"))
+	assert.Equal(t, fiber.StatusForbidden, request("/api/v1/rag/question-topics"), "topics of all users stay admin only")
+}
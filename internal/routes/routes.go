@@ -1,58 +1,424 @@
 package routes
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
 	_ "narapulse-be/docs"
+	"narapulse-be/internal/config"
+	"narapulse-be/internal/connectors"
+	"narapulse-be/internal/grpcapi"
 	"narapulse-be/internal/handlers"
 	"narapulse-be/internal/middleware"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/mailer"
+	"narapulse-be/internal/pkg/metrics"
+	"narapulse-be/internal/pkg/objectstore"
+	"narapulse-be/internal/pkg/pubsub"
+	"narapulse-be/internal/pkg/ratelimit"
 	"narapulse-be/internal/repositories"
 	"narapulse-be/internal/services"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/swaggo/fiber-swagger"
 	"gorm.io/gorm"
 )
 
-func Setup(app *fiber.App, db *gorm.DB) {
+// Setup registers the routes of the API and starts the gRPC API when enabled.
+// The returned stop function stops the gRPC API, letting calls in flight
+// finish; starting it fails when its port cannot be listened on.
+func Setup(app *fiber.App, db *gorm.DB) (stop func(), err error) {
+	cfg := config.Load()
+
 	// Initialize repositories
 	dataSourceRepo := repositories.NewDataSourceRepository(db)
 	schemaRepo := repositories.NewSchemaRepository(db)
 
 	// Initialize services
-	connectorService := services.NewConnectorService()
-	dataSourceService := services.NewDataSourceService(dataSourceRepo, schemaRepo, connectorService)
-	
+	pgPool := connectors.NewPostgreSQLPool(connectors.PoolOptions{
+		MaxOpenConns:    cfg.PGPoolMaxOpenConns,
+		MaxIdleConns:    cfg.PGPoolMaxIdleConns,
+		ConnMaxLifetime: time.Duration(cfg.PGPoolConnMaxLifetimeMinutes) * time.Minute,
+		ConnMaxIdleTime: time.Duration(cfg.PGPoolConnMaxIdleMinutes) * time.Minute,
+	})
+	connectorService := services.NewPooledConnectorService(pgPool)
+	governanceService := services.NewGovernanceService(db, cfg.ComplianceWebhookURL, cfg.ComplianceWebhookSecret)
+	auditService := services.NewAuditService(db)
+
 	// Initialize RAG-related services
-	embeddingService := services.NewEmbeddingService(db, "")
-	ragService := services.NewRAGService(db, embeddingService)
-	nl2sqlService := services.NewNL2SQLService(db, ragService)
+	usageService := services.NewUsageService(db, services.UsagePricing{
+		LLMModel:           cfg.AILLMModel,
+		EmbeddingPer1K:     cfg.AIEmbeddingCostPer1KTokens,
+		LLMPromptPer1K:     cfg.AILLMPromptCostPer1KTokens,
+		LLMCompletionPer1K: cfg.AILLMCompletionCostPer1K,
+	}, models.UsageQuotaRequest{
+		MonthlyTokens:  int64(cfg.AIMonthlyTokenQuota),
+		MonthlyCostUSD: cfg.AIMonthlyCostQuotaUSD,
+	})
+	// Background jobs run on a worker pool fed from the jobs table; the subsystems
+	// below register the job types they enqueue
+	jobService := services.NewJobService(db, cfg.InstanceID, services.JobOptions{
+		Workers:      cfg.JobWorkers,
+		PollInterval: time.Duration(cfg.JobPollIntervalSeconds) * time.Second,
+		MaxAttempts:  cfg.JobMaxAttempts,
+	})
+
+	// Webhook subscriptions to platform events; deliveries are sent by jobs
+	webhookService := services.NewWebhookService(db, jobService)
+	webhookService.RegisterJobs(jobService)
+
+	// Realtime notifications of background work; relayed across instances through Redis when configured
+	var notificationBroker pubsub.Broker = pubsub.NewMemoryBroker()
+	if cfg.RedisURL != "" {
+		redisBroker, err := pubsub.NewRedisBroker(cfg.RedisURL)
+		if err != nil {
+			logger.L().Warn().Err(err).Msg("Redis unavailable, notifications only reach clients of this instance")
+		} else {
+			notificationBroker = redisBroker
+		}
+	}
+	notificationHub := services.NewNotificationHub(notificationBroker)
+	if err := notificationHub.Start(context.Background()); err != nil {
+		logger.L().Error().Err(err).Msg("Failed to subscribe to notifications")
+	}
+
+	embeddingService := services.NewEmbeddingService(db, "", cfg.AIEmbeddingModel, usageService)
+	ga4TemplateService := services.NewGA4TemplateService(db, embeddingService)
+	columnMetadataService := services.NewColumnMetadataService(db, jobService, embeddingService)
+	// Local copies of Sheets and REST API records that queries run on, refreshed by jobs
+	materializationService := services.NewMaterializationService(db, connectorService, jobService, cfg.MaterializedDataDir)
+	materializationService.RegisterJobs(jobService)
+	// Encrypted BigQuery service account keys data sources reference by service_account_id
+	bigQueryServiceAccountService := services.NewBigQueryServiceAccountService(db, cfg.CredentialsEncryptionKey)
+	dataSourceService := services.NewDataSourceService(dataSourceRepo, schemaRepo, connectorService, governanceService, cfg.MaterializedDataDir, ga4TemplateService, time.Duration(cfg.DataSourceRestoreDays)*24*time.Hour, jobService, services.NewSchemaChangeService(db, webhookService), columnMetadataService, materializationService, bigQueryServiceAccountService, cfg.DataSourceSecretEnvPrefix, notificationHub)
+	connectionHealthService := services.NewConnectionHealthService(db, connectorService, webhookService)
+	connectionHealthService.RegisterJobs(jobService)
+	rerankService := services.NewRerankService(cfg.RerankAPIURL, cfg.RerankAPIKey, cfg.RerankModel, cfg.RerankThreshold)
+	ragService := services.NewRAGService(db, embeddingService, rerankService)
+	// Past questions for similar question search, topics and suggestions
+	questionAnalyticsService := services.NewQuestionAnalyticsService(db, ragService)
+	// Feature flags gate capabilities as they roll out to users and tenants
+	featureFlagService := services.NewFeatureFlagService(db)
+	nl2sqlService := services.NewNL2SQLService(db, connectorService, ragService, usageService, webhookService, featureFlagService)
+	nl2sqlEvalService := services.NewNL2SQLEvalService(db, nl2sqlService, jobService)
 	
 	// Initialize schema sync service
 	schemaSyncService := services.NewSchemaSyncService(db, ragService, embeddingService)
+	schemaSyncService.RegisterJobs(jobService)
+	schemaSyncScheduler := services.NewSchemaSyncScheduler(db, jobService, cfg.SchemaSyncEnabled,
+		time.Duration(cfg.SchemaSyncIntervalMinutes)*time.Minute)
+
+	// Initialize query history retention; archived result rows live in the archive store
+	archiveStore := objectstore.NewFileStore(cfg.QueryResultArchiveDir)
+	queryRetentionService := services.NewQueryRetentionService(db, archiveStore,
+		time.Duration(cfg.QueryResultRetentionDays)*24*time.Hour,
+		time.Duration(cfg.QueryRetentionDays)*24*time.Hour)
+	queryRetentionService.RegisterJobs(jobService)
+	retentionInterval := time.Duration(cfg.QueryRetentionIntervalMinutes) * time.Minute
+	if cfg.QueryResultRetentionDays <= 0 && cfg.QueryRetentionDays <= 0 {
+		retentionInterval = 0
+	}
+
+	// Initialize chunked uploads of large files; assembled files live in the upload store
+	fileUploadService := services.NewFileUploadService(db, connectorService, objectstore.NewFileStore(cfg.UploadDir), jobService,
+		int64(cfg.UploadMaxSizeMB)*1024*1024)
+	fileUploadService.RegisterJobs(jobService)
+
+	jobService.Start(context.Background())
+	jobService.Schedule(context.Background(), models.JobTypeHealthCheck, time.Duration(cfg.HealthCheckIntervalMinutes)*time.Minute)
+	schemaSyncScheduler.Start(context.Background())
+	jobService.Schedule(context.Background(), models.JobTypeQueryRetention, retentionInterval)
+	jobService.Schedule(context.Background(), models.JobTypeFileUploadPurge, services.FileUploadPurgeInterval)
+	jobService.Schedule(context.Background(), models.JobTypeGoogleDriveSync, time.Duration(cfg.GoogleDriveSyncIntervalMinutes)*time.Minute)
+	jobService.Schedule(context.Background(), models.JobTypeMaterializationSync, time.Duration(cfg.MaterializationSyncIntervalMinutes)*time.Minute)
+
+	// Initialize analytics cache service
+	analyticsService := services.NewAnalyticsService(db)
+
+	// Initialize data API service
+	dataAPIService := services.NewDataAPIService(db, nl2sqlService)
+
+	// Initialize query cost service
+	queryCostService := services.NewQueryCostService(db, int64(cfg.BigQueryMaxBytesBilledGB)<<30, cfg.BigQueryPricePerTiB)
+
+	// Initialize validation policy service
+	validationPolicyService := services.NewValidationPolicyService(db, governanceService)
+
+	// Initialize Casbin route authorization. When the enforcer cannot load, only admin
+	// routes are restricted (by the role on the user) and access reviews only report user roles.
+	casbinService, err := services.NewCasbinService(db)
+	if err != nil {
+		logger.L().Warn().Err(err).Msg("Casbin unavailable, falling back to role checks on admin routes")
+		casbinService = nil
+	}
+	authorize := func(c *fiber.Ctx) error { return c.Next() }
+	adminOnly := middleware.AdminMiddleware()
+	if casbinService != nil {
+		casbinService.StartPolicyReload(time.Duration(cfg.CasbinPolicyReloadSeconds) * time.Second)
+		authorize = middleware.CasbinMiddleware(casbinService)
+		adminOnly = authorize
+	}
+	// PII in query results is masked unless a policy grants the pii/unmask permission
+	var piiAuthorizer middleware.Authorizer
+	if casbinService != nil {
+		piiAuthorizer = casbinService
+	}
+	piiUnmask := middleware.PIIUnmaskMiddleware(piiAuthorizer)
+	// Retrieval traces expose prompts of all users, so they need the rag/trace permission
+	ragTrace := middleware.PermissionMiddleware(piiAuthorizer, middleware.RAGTraceObject, middleware.RAGTraceAction)
+	accessReviewService := services.NewAccessReviewService(db, casbinService, governanceService)
+
+	// Tokens are checked against the current state of their user, so deactivations and role changes apply at once
+	accounts := services.NewUserService(repositories.NewUserRepository(db))
+
+	// Rate limits per user and tenant; buckets are shared across instances through Redis when configured
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RedisURL != "" {
+		redisStore, err := ratelimit.NewRedisStore(cfg.RedisURL)
+		if err != nil {
+			logger.L().Warn().Err(err).Msg("Redis unavailable, rate limits are kept per instance")
+		} else {
+			rateLimitStore = redisStore
+		}
+	}
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore)
+	// The API and AI limits are hot settings: reloads of the configuration apply to the next requests
+	apiLimits := func() middleware.Limits {
+		current := config.Load()
+		return middleware.Limits{User: ratelimit.PerMinute(current.RateLimitAPIPerMinute), Tenant: ratelimit.PerMinute(current.RateLimitTenantAPIPerMinute)}
+	}
+	aiLimits := func() middleware.Limits {
+		current := config.Load()
+		return middleware.Limits{User: ratelimit.PerMinute(current.RateLimitAIPerMinute), Tenant: ratelimit.PerMinute(current.RateLimitTenantAIPerMinute)}
+	}
+	apiLimit := rateLimiter.LimitFunc(middleware.RateLimitAPI, apiLimits)
+	aiLimit := rateLimiter.LimitFunc(middleware.RateLimitAI, aiLimits)
+	mfaLimit := rateLimiter.Limit("mfa", ratelimit.PerMinute(10))
+	shareLimit := rateLimiter.Limit("share", ratelimit.PerMinute(30))
+
+	// Serve the gRPC API for internal services on its own port; calls are authorized
+	// against the policies of the equivalent REST routes and take from their rate limits
+	stop = func() {}
+	if cfg.GRPCEnabled {
+		grpcServer := grpcapi.NewServer(nl2sqlService, dataSourceService, auditService, piiAuthorizer, accounts, cfg.JWTSecret,
+			grpcapi.RateLimits{Limiter: rateLimiter, API: apiLimits, AI: aiLimits})
+		listener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen for gRPC on port %s: %w", cfg.GRPCPort, err)
+		}
+		logger.L().Info().Str("port", cfg.GRPCPort).Msg("gRPC API listening")
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.L().Error().Err(err).Msg("gRPC server stopped")
+			}
+		}()
+		stop = grpcServer.GracefulStop
+	}
+
+	// Initialize custom SQL function service
+	customSQLFunctionService := services.NewCustomSQLFunctionService(db, governanceService)
+
+	// Initialize KPI computation service
+	kpiService := services.NewKPIService(db, nl2sqlService)
+
+	// Initialize semantic layer service
+	semanticLayerService := services.NewSemanticLayerService(db)
+
+	// Initialize query collaboration link service
+	queryCollaborationService := services.NewQueryCollaborationService(db, nl2sqlService)
+
+	// Initialize saved query service
+	savedQueryService := services.NewSavedQueryService(db, nl2sqlService)
+
+	// Initialize result diff service
+	resultDiffService := services.NewResultDiffService(db, nl2sqlService, savedQueryService)
+
+	// Initialize dashboard service with its realtime collaboration hub
+	dashboardService := services.NewDashboardService(db, services.NewDashboardHub())
+
+	// Initialize GraphQL gateway over data sources, saved queries, KPIs and dashboards
+	graphQLService, err := services.NewGraphQLService(db, dataSourceRepo, schemaRepo, savedQueryService, kpiService, dashboardService)
+	if err != nil {
+		logger.L().Fatal().Err(err).Msg("Invalid GraphQL schema")
+	}
+
+	// Snapshots of query results dashboard widgets display, refreshed by jobs
+	extractService := services.NewExtractService(db, nl2sqlService, jobService, cfg.ExtractDir)
+	extractService.RegisterJobs(jobService)
+	jobService.Schedule(context.Background(), models.JobTypeExtractSync, time.Duration(cfg.ExtractSyncIntervalMinutes)*time.Minute)
+
+	// Initialize digest service with the configured email sender
+	emailSender := mailer.New(mailer.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+	digestService := services.NewDigestService(db, emailSender)
+
+	// Initialize scheduled reports; rendered documents live in the report store
+	reportService := services.NewReportService(db, savedQueryService, kpiService, dashboardService, nl2sqlService,
+		extractService, objectstore.NewFileStore(cfg.ReportOutputDir), emailSender, notificationHub)
+	reportService.RegisterJobs(jobService)
+	jobService.Schedule(context.Background(), models.JobTypeReportRunDue, time.Duration(cfg.ReportIntervalMinutes)*time.Minute)
+
+	// Initialize Slack/Teams integrations and the KPI alert rules delivered through them
+	integrationService := services.NewIntegrationService(db, nl2sqlService)
+	alertService := services.NewAlertService(db, kpiService, integrationService, webhookService, notificationHub)
+	alertService.RegisterJobs(jobService)
+	jobService.Schedule(context.Background(), models.JobTypeAlertCheck, time.Duration(cfg.AlertCheckIntervalMinutes)*time.Minute)
+
+	// Initialize query result archiving to cold storage
+	queryResultArchiveService := services.NewQueryResultArchiveService(db, archiveStore,
+		time.Duration(cfg.QueryResultArchiveAfterDays)*24*time.Hour)
+
+	// Initialize TOTP two-factor login
+	mfaService := services.NewMFAService(db, cfg.MFAIssuer)
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(db)
-	authHandler := handlers.NewAuthHandler(db)
+	userHandler := handlers.NewUserHandler(db, auditService)
+	authHandler := handlers.NewAuthHandler(db, mfaService)
+	mfaHandler := handlers.NewMFAHandler(db, mfaService, auditService)
 	// Initialize DataSourceHandler
-	dataSourceHandler := handlers.NewDataSourceHandler(dataSourceService)
+	dataSourceHandler := handlers.NewDataSourceHandler(dataSourceService, services.NewDryImportService(), auditService)
+	fileUploadHandler := handlers.NewFileUploadHandler(fileUploadService)
+	googleDriveHandler := handlers.NewGoogleDriveHandler(services.NewGoogleDriveService(connectorService))
+	bigQueryServiceAccountHandler := handlers.NewBigQueryServiceAccountHandler(bigQueryServiceAccountService)
+	googleSheetsHandler := handlers.NewGoogleSheetsHandler(services.NewGoogleSheetsService(connectorService))
+	columnMetadataHandler := handlers.NewColumnMetadataHandler(columnMetadataService, dataSourceService, auditService)
+	// dbt manifests enrich column metadata and the glossary
+	dbtHandler := handlers.NewDbtHandler(services.NewDbtService(db, jobService, embeddingService), dataSourceService, auditService)
+	suggestedQuestionHandler := handlers.NewSuggestedQuestionHandler(services.NewSuggestedQuestionService(db, questionAnalyticsService), dataSourceService)
+	connectionHealthHandler := handlers.NewConnectionHealthHandler(connectionHealthService)
+	materializationHandler := handlers.NewMaterializationHandler(materializationService)
+	dataProfileHandler := handlers.NewDataProfileHandler(services.NewDataProfileService(db, connectorService, services.NewPIIMaskingService(db, cfg.PIIMaskMode, cfg.AnonymizeSecret)))
 	// Initialize NL2SQLHandler
-	nl2sqlHandler := handlers.NewNL2SQLHandler(nl2sqlService)
+	nl2sqlHandler := handlers.NewNL2SQLHandler(nl2sqlService, auditService)
 	// Initialize Schema Sync Handler
-	schemaSyncHandler := handlers.NewSchemaSyncHandler(schemaSyncService)
+	schemaSyncHandler := handlers.NewSchemaSyncHandler(schemaSyncService, jobService, schemaSyncScheduler)
 	// Initialize RAG Handler
-	ragHandler := handlers.NewRAGHandler(ragService, embeddingService)
+	ragHandler := handlers.NewRAGHandler(ragService, embeddingService, questionAnalyticsService)
+	// Initialize Governance Handler
+	governanceHandler := handlers.NewGovernanceHandler(governanceService)
+	// Initialize Analytics Handler
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	// Initialize Data API Handler
+	dataAPIHandler := handlers.NewDataAPIHandler(dataAPIService)
+	// Initialize Query Cost Handler
+	queryCostHandler := handlers.NewQueryCostHandler(queryCostService)
+	// Initialize Validation Policy Handler
+	validationPolicyHandler := handlers.NewValidationPolicyHandler(validationPolicyService)
+	// Initialize Access Review Handler
+	accessReviewHandler := handlers.NewAccessReviewHandler(accessReviewService, auditService)
+	// Initialize Custom SQL Function Handler
+	customSQLFunctionHandler := handlers.NewCustomSQLFunctionHandler(customSQLFunctionService)
+	// Initialize KPI Handler
+	kpiHandler := handlers.NewKPIHandler(kpiService)
+	// Initialize Semantic Model Handler
+	semanticModelHandler := handlers.NewSemanticModelHandler(semanticLayerService)
+	// Initialize Dashboard Handler
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
+	// Initialize GraphQL Handler
+	graphQLHandler := handlers.NewGraphQLHandler(graphQLService)
+	// Initialize Notification Handler
+	notificationHandler := handlers.NewNotificationHandler(notificationHub)
+	// Initialize Digest Handler
+	digestHandler := handlers.NewDigestHandler(digestService)
+	// Initialize Report Handler
+	reportHandler := handlers.NewReportHandler(reportService)
+	// Initialize Integration and Alert Handlers
+	integrationHandler := handlers.NewIntegrationHandler(integrationService, auditService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	// Initialize Webhook Handler
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	// Initialize Share Handler: public read-only links to query results and dashboards
+	shareService := services.NewShareService(db, nl2sqlService, dashboardService, extractService)
+	shareHandler := handlers.NewShareHandler(shareService, auditService)
+	// Initialize Embed Handler: dashboards and widgets embedded in other products with scoped tokens
+	embedHandler := handlers.NewEmbedHandler(services.NewEmbedService(dashboardService, shareService, cfg.JWTSecret), auditService)
+	// Initialize Query Result Archive Handler
+	queryResultArchiveHandler := handlers.NewQueryResultArchiveHandler(queryResultArchiveService)
+	// Initialize Query Retention Handler
+	queryRetentionHandler := handlers.NewQueryRetentionHandler(queryRetentionService, auditService)
+
+	// Initialize Embedding Maintenance Handler
+	embeddingMaintenanceHandler := handlers.NewEmbeddingMaintenanceHandler(services.NewEmbeddingMaintenanceService(db), auditService)
+	nl2sqlEvalHandler := handlers.NewNL2SQLEvalHandler(nl2sqlEvalService)
+	promptTemplateHandler := handlers.NewPromptTemplateHandler(services.NewPromptTemplateService(db))
+	retrievalConfigHandler := handlers.NewRetrievalConfigHandler(services.NewRetrievalConfigService(db))
+	// Initialize Query Collaboration Handler
+	queryCollaborationHandler := handlers.NewQueryCollaborationHandler(queryCollaborationService)
+	// Initialize Saved Query Handler
+	savedQueryHandler := handlers.NewSavedQueryHandler(savedQueryService, auditService)
+	// Initialize Result Diff Handler
+	resultDiffHandler := handlers.NewResultDiffHandler(resultDiffService)
+	// Initialize Extract Handler
+	extractHandler := handlers.NewExtractHandler(extractService)
+	// Initialize Audit Handler
+	auditHandler := handlers.NewAuditHandler(auditService)
+	// Initialize Usage Handler
+	usageHandler := handlers.NewUsageHandler(usageService)
+	// Initialize Data Source Template Handler
+	dataSourceTemplateHandler := handlers.NewDataSourceTemplateHandler(services.NewDataSourceTemplateService(db, dataSourceService), auditService)
+	// Initialize Job Handler
+	jobHandler := handlers.NewJobHandler(jobService)
+	// Initialize Data Source Config Handler
+	dataSourceConfigHandler := handlers.NewDataSourceConfigHandler(dataSourceService, auditService)
+	// Initialize Error Catalog Handler
+	errorCatalogHandler := handlers.NewErrorCatalogHandler()
+	// Tenants isolate organizations; requests are resolved to the tenant of their token or domain
+	tenantService := services.NewTenantService(db)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	resolveTenant := middleware.TenancyMiddleware(tenantService)
+	authenticate := middleware.AuthMiddleware(accounts)
+	// Initialize Feature Flag Handler
+	featureFlagHandler := handlers.NewFeatureFlagHandler(featureFlagService)
 
 	// API routes
 	api := app.Group("/api/v1")
 
 	// Public routes
 	auth := api.Group("/auth")
-	auth.Post("/register", authHandler.Register)
-	auth.Post("/login", authHandler.Login)
+	auth.Post("/register", resolveTenant, authHandler.Register)
+	auth.Post("/login", resolveTenant, authHandler.Login)
+
+	// MFA: verify completes a login; enrollment also accepts the enrollment token of users
+	// required to set up MFA, so these routes are outside the protected group
+	auth.Post("/mfa/verify", mfaLimit, mfaHandler.Verify)
+	auth.Get("/mfa", middleware.MFAEnrollmentMiddleware(accounts), mfaHandler.GetStatus)
+	auth.Post("/mfa/enroll", middleware.MFAEnrollmentMiddleware(accounts), mfaLimit, mfaHandler.BeginEnrollment)
+	auth.Post("/mfa/enroll/confirm", middleware.MFAEnrollmentMiddleware(accounts), mfaLimit, mfaHandler.ConfirmEnrollment)
+	auth.Post("/mfa/disable", authenticate, mfaLimit, mfaHandler.Disable)
+	auth.Post("/mfa/recovery-codes", authenticate, mfaLimit, mfaHandler.RegenerateRecoveryCodes)
+
+	// Error codes of the API (public, clients fetch it before authenticating)
+	api.Get("/errors", errorCatalogHandler.ListErrorCodes)
+
+	// Published data APIs (public, authenticated with X-API-Key)
+	api.Get("/apis/:slug", dataAPIHandler.Invoke)
+
+	// Slack slash commands and Teams outgoing webhooks (public, verified with the integration's signing secret)
+	api.Post("/integrations/:id/command", aiLimit, integrationHandler.HandleCommand)
+
+	// Share links (public, the token is the credential; rate limited per IP against password guessing)
+	api.Get("/public/shares/:token", shareLimit, shareHandler.ViewShare)
+
+	// Embedded dashboards and widgets (public, authenticated with an embed token)
+	embedded := api.Group("/embed/dashboards", apiLimit, middleware.EmbedMiddleware())
+	embedded.Get("/:id", embedHandler.GetEmbeddedDashboard)
+	embedded.Get("/:id/widgets/:widgetId", embedHandler.GetEmbeddedWidget)
 
 	// Protected routes
-	protected := api.Group("/", middleware.AuthMiddleware())
+	protected := api.Group("/", authenticate, resolveTenant, authorize, apiLimit)
 	protected.Get("/profile", userHandler.GetProfile)
 	protected.Put("/profile", userHandler.UpdateProfile)
+	protected.Get("/tenant", tenantHandler.GetCurrentTenant)
+	protected.Get("/feature-flags", featureFlagHandler.GetMyFeatureFlags)
 
 	// Data Sources routes (protected)
 	dataSources := protected.Group("/data-sources")
@@ -61,38 +427,356 @@ func Setup(app *fiber.App, db *gorm.DB) {
 	dataSources.Get("/:id", dataSourceHandler.GetDataSource)
 	dataSources.Put("/:id", dataSourceHandler.UpdateDataSource)
 	dataSources.Delete("/:id", dataSourceHandler.DeleteDataSource)
+	dataSources.Post("/:id/restore", dataSourceHandler.RestoreDataSource)
 	dataSources.Post("/test-connection", dataSourceHandler.TestConnection)
 	dataSources.Post("/:id/refresh-schema", dataSourceHandler.RefreshSchema)
+	dataSources.Post("/:id/duplicate", dataSourceHandler.DuplicateDataSource)
+	dataSources.Get("/:id/discovery", dataSourceHandler.GetDiscovery)
+	dataSources.Post("/:id/discovery/retry", dataSourceHandler.RetryDiscovery)
+	dataSources.Get("/:id/schema-changes", dataSourceHandler.GetSchemaChanges)
+	dataSources.Get("/:id/column-metadata", columnMetadataHandler.GetColumnMetadata)
+	dataSources.Post("/:id/dbt", dbtHandler.ImportDbt)
+	dataSources.Get("/:id/suggested-questions", suggestedQuestionHandler.GetSuggestedQuestions)
+	dataSources.Post("/:id/suggested-questions", suggestedQuestionHandler.CreateSuggestedQuestion)
+	dataSources.Delete("/:id/suggested-questions/:questionId", suggestedQuestionHandler.DeleteSuggestedQuestion)
+	dataSources.Put("/:id/schemas/:table/columns/:column", columnMetadataHandler.UpdateColumnMetadata)
+	dataSources.Get("/:id/schemas/:schema_id/profile", dataProfileHandler.GetProfile)
+	dataSources.Get("/:id/health", connectionHealthHandler.GetHealth)
+	dataSources.Get("/:id/materializations", materializationHandler.GetMaterializations)
+	dataSources.Post("/:id/materializations/refresh", materializationHandler.RefreshMaterializations)
 	dataSources.Post("/upload", dataSourceHandler.UploadFile)
+	dataSources.Post("/uploads", fileUploadHandler.InitiateUpload)
+	dataSources.Get("/uploads/:id", fileUploadHandler.GetUpload)
+	dataSources.Put("/uploads/:id/parts/:number", fileUploadHandler.UploadPart)
+	dataSources.Post("/uploads/:id/complete", fileUploadHandler.CompleteUpload)
+	dataSources.Delete("/uploads/:id", fileUploadHandler.AbortUpload)
+	dataSources.Get("/google-drive/auth-url", googleDriveHandler.GetAuthURL)
+	dataSources.Post("/google-drive/token", googleDriveHandler.ExchangeToken)
+	dataSources.Post("/google-drive/files", googleDriveHandler.BrowseFiles)
+	dataSources.Post("/google-sheets/sheets", googleSheetsHandler.ListSheets)
+	dataSources.Post("/bigquery/service-accounts/validate", bigQueryServiceAccountHandler.ValidateServiceAccount)
+	dataSources.Post("/bigquery/service-accounts", bigQueryServiceAccountHandler.CreateServiceAccount)
+	dataSources.Get("/bigquery/service-accounts", bigQueryServiceAccountHandler.GetServiceAccounts)
+	dataSources.Delete("/bigquery/service-accounts/:accountId", bigQueryServiceAccountHandler.DeleteServiceAccount)
+	dataSources.Post("/dry-import", dataSourceHandler.DryImport)
+	dataSources.Put("/:id/cost-ceiling", queryCostHandler.SetDataSourceCeiling)
+	dataSources.Delete("/:id/cost-ceiling", queryCostHandler.DeleteDataSourceCeiling)
+
+	// Connection templates curated by admins
+	protected.Get("/data-source-templates", dataSourceTemplateHandler.GetTemplates)
+	protected.Get("/data-source-templates/:id", dataSourceTemplateHandler.GetTemplate)
+	protected.Post("/data-source-templates/:id/data-sources", dataSourceTemplateHandler.CreateDataSource)
+
+	// Declarative data source config: converge to a declared list (?dry_run=true for the plan)
+	protected.Put("/config/data-sources", dataSourceConfigHandler.ApplyDataSourceConfig)
 
 	// NL2SQL routes (protected)
-	SetupNL2SQLRoutes(protected, nl2sqlHandler)
+	SetupNL2SQLRoutes(protected, nl2sqlHandler, authenticate, aiLimit, piiUnmask, ragTrace)
+	protected.Get("/nl2sql/cost-ceiling", queryCostHandler.GetCostCeiling)
+	protected.Post("/nl2sql/queries/:id/results/:resultId/rehydrate", queryResultArchiveHandler.Rehydrate)
+	protected.Delete("/nl2sql/history", queryRetentionHandler.DeleteOwnHistory)
+
+	// Query collaboration links: the owner shares a query, teammates review and suggest SQL
+	protected.Post("/nl2sql/queries/:id/collaboration-links", queryCollaborationHandler.CreateLink)
+	protected.Get("/nl2sql/queries/:id/collaboration-links", queryCollaborationHandler.GetLinks)
+	protected.Delete("/nl2sql/queries/:id/collaboration-links/:linkId", queryCollaborationHandler.RevokeLink)
+	protected.Get("/nl2sql/queries/:id/collaboration-events", queryCollaborationHandler.GetEvents)
+	protected.Get("/nl2sql/queries/:id/suggestions", queryCollaborationHandler.GetSuggestions)
+	protected.Post("/nl2sql/queries/:id/suggestions/:suggestionId/accept", queryCollaborationHandler.AcceptSuggestion)
+	protected.Post("/nl2sql/queries/:id/suggestions/:suggestionId/reject", queryCollaborationHandler.RejectSuggestion)
+	protected.Get("/nl2sql/collaborate/:token", queryCollaborationHandler.View)
+	protected.Post("/nl2sql/collaborate/:token/suggestions", queryCollaborationHandler.Suggest)
+
+	// Saved queries: bookmarks with tags, optionally shared with the workspace, run again on fresh data
+	savedQueries := protected.Group("/nl2sql/saved-queries")
+	savedQueries.Get("/", savedQueryHandler.GetSavedQueries)
+	savedQueries.Post("/", savedQueryHandler.CreateSavedQuery)
+	savedQueries.Get("/:id", savedQueryHandler.GetSavedQuery)
+	savedQueries.Put("/:id", savedQueryHandler.UpdateSavedQuery)
+	savedQueries.Delete("/:id", savedQueryHandler.DeleteSavedQuery)
+	savedQueries.Post("/:id/run", piiUnmask, savedQueryHandler.RunSavedQuery)
+
+	// What changed between two executions: row and column differences and total deltas
+	analysis := protected.Group("/analysis")
+	analysis.Post("/diff", piiUnmask, resultDiffHandler.DiffResults)
+
+	// Query result snapshots dashboard widgets bind to, refreshed on a schedule
+	extracts := protected.Group("/extracts")
+	extracts.Get("/", extractHandler.GetExtracts)
+	extracts.Post("/", extractHandler.CreateExtract)
+	extracts.Get("/:id", extractHandler.GetExtract)
+	extracts.Put("/:id", extractHandler.UpdateExtract)
+	extracts.Delete("/:id", extractHandler.DeleteExtract)
+	extracts.Post("/:id/refresh", extractHandler.RefreshExtract)
+	extracts.Get("/:id/data", piiUnmask, extractHandler.GetExtractData)
+
+	// AI usage and quota of the current user
+	protected.Get("/usage", usageHandler.GetUsage)
+	protected.Get("/usage/quota", usageHandler.GetQuota)
 
 	// RAG routes (protected)
-	SetupRAGRoutes(app, ragHandler)
+	SetupRAGRoutes(app, ragHandler, authenticate, authorize, adminOnly, aiLimit)
 
 	// Schema Sync routes (protected)
 	schemaSync := protected.Group("/schema-sync")
 	schemaSync.Get("/status", schemaSyncHandler.GetSyncStatus)
 	schemaSync.Post("/trigger", schemaSyncHandler.TriggerSyncAll)
-	schemaSync.Post("/trigger/:id", schemaSyncHandler.TriggerSync)
-	schemaSync.Get("/status/:id", schemaSyncHandler.GetDataSourceSyncStatus)
+	schemaSync.Post("/trigger/:data_source_id", schemaSyncHandler.TriggerSync)
+	schemaSync.Get("/status/:data_source_id", schemaSyncHandler.GetDataSourceSyncStatus)
 	schemaSync.Post("/scheduled", schemaSyncHandler.ScheduledSync)
+	schemaSync.Get("/history", schemaSyncHandler.GetSyncHistory)
+
+	// Analytics routes (protected, served from the analytics cache)
+	protected.Get("/analytics/queries", analyticsHandler.GetMyQueryMetrics)
+
+	// Data API management routes (protected)
+	dataAPIs := protected.Group("/data-apis")
+	dataAPIs.Post("/", dataAPIHandler.CreateEndpoint)
+	dataAPIs.Get("/", dataAPIHandler.GetEndpoints)
+	dataAPIs.Get("/:id", dataAPIHandler.GetEndpoint)
+	dataAPIs.Put("/:id", dataAPIHandler.UpdateEndpoint)
+	dataAPIs.Delete("/:id", dataAPIHandler.DeleteEndpoint)
+	dataAPIs.Post("/:id/keys", dataAPIHandler.CreateKey)
+	dataAPIs.Get("/:id/keys", dataAPIHandler.GetKeys)
+	dataAPIs.Delete("/:id/keys/:keyId", dataAPIHandler.RevokeKey)
 
-	// Admin routes
-	admin := api.Group("/admin", middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	// KPI computation for KPI cards (protected)
+	kpis := protected.Group("/kpis")
+	kpis.Post("/:id/compute", kpiHandler.ComputeKPI)
+
+	// Dashboard routes (protected, edited collaboratively in realtime)
+	dashboards := protected.Group("/dashboards")
+	dashboards.Post("/", dashboardHandler.CreateDashboard)
+	dashboards.Get("/", dashboardHandler.GetDashboards)
+	dashboards.Get("/:id", dashboardHandler.GetDashboard)
+	dashboards.Put("/:id", dashboardHandler.UpdateDashboard)
+	dashboards.Delete("/:id", dashboardHandler.DeleteDashboard)
+	dashboards.Post("/:id/widgets", dashboardHandler.AddWidget)
+	dashboards.Patch("/:id/widgets/:widgetId", dashboardHandler.UpdateWidget)
+	dashboards.Delete("/:id/widgets/:widgetId", dashboardHandler.DeleteWidget)
+	dashboards.Get("/:id/collaborators", dashboardHandler.GetCollaborators)
+	dashboards.Put("/:id/collaborators", dashboardHandler.SetCollaborator)
+	dashboards.Delete("/:id/collaborators/:userId", dashboardHandler.RemoveCollaborator)
+	dashboards.Get("/:id/presence", dashboardHandler.GetPresence)
+	dashboards.Post("/:id/presence", dashboardHandler.UpdatePresence)
+	dashboards.Get("/:id/live", dashboardHandler.Live)
+
+	// GraphQL gateway for dashboards and metadata (protected, read-only)
+	protected.Post("/graphql", middleware.RequireTenantFeature(models.TenantFeatureGraphQL), graphQLHandler.Query)
+	protected.Get("/graphql/schema", middleware.RequireTenantFeature(models.TenantFeatureGraphQL), graphQLHandler.GetSchema)
+
+	// Realtime notifications of background work over WebSocket (protected)
+	protected.Get("/notifications/live", middleware.RequireTenantFeature(models.TenantFeatureNotifications), notificationHandler.Live)
+
+	// Digest email routes (protected)
+	digest := protected.Group("/digest")
+	digest.Get("/subscription", digestHandler.GetSubscription)
+	digest.Put("/subscription", digestHandler.UpdateSubscription)
+	digest.Get("/preview", digestHandler.Preview)
+
+	// Share links to query results and dashboards
+	shares := protected.Group("/shares")
+	shares.Get("/", shareHandler.GetShares)
+	shares.Post("/", shareHandler.CreateShare)
+	shares.Delete("/:id", shareHandler.RevokeShare)
+	protected.Post("/embed/tokens", embedHandler.CreateEmbedToken)
+
+	// Scheduled reports: saved queries, KPIs and dashboards rendered to HTML or PDF and emailed
+	reports := protected.Group("/reports")
+	reports.Get("/", reportHandler.GetReports)
+	reports.Post("/", reportHandler.CreateReport)
+	reports.Get("/:id", reportHandler.GetReport)
+	reports.Put("/:id", reportHandler.UpdateReport)
+	reports.Delete("/:id", reportHandler.DeleteReport)
+	reports.Post("/:id/run", reportHandler.RunReport)
+	reports.Get("/:id/runs", reportHandler.GetReportRuns)
+	reports.Get("/:id/runs/:runId/download", reportHandler.DownloadReportRun)
+
+	// Chat integrations: share query results to Slack or Teams
+	integrations := protected.Group("/integrations")
+	integrations.Get("/", integrationHandler.GetAvailableIntegrations)
+	integrations.Post("/:id/share", integrationHandler.ShareResult)
+
+	// KPI alert rules delivered to chat channels
+	alerts := protected.Group("/alerts")
+	alerts.Get("/", alertHandler.GetAlertRules)
+	alerts.Post("/", alertHandler.CreateAlertRule)
+	alerts.Get("/:id", alertHandler.GetAlertRule)
+	alerts.Put("/:id", alertHandler.UpdateAlertRule)
+	alerts.Delete("/:id", alertHandler.DeleteAlertRule)
+	alerts.Post("/:id/check", alertHandler.CheckAlertRule)
+
+	// Admin routes; admins of the default tenant administer the installation
+	admin := api.Group("/admin", authenticate, resolveTenant, middleware.DefaultTenantOnly(), adminOnly)
 	admin.Get("/users", userHandler.GetAllUsers)
+	admin.Post("/users/bulk-deactivate", userHandler.BulkDeactivateUsers)
+	admin.Patch("/users/:id", userHandler.UpdateUser)
 	admin.Delete("/users/:id", userHandler.DeleteUser)
+	admin.Put("/users/:id/role", userHandler.UpdateUserRole)
+	admin.Put("/users/:id/cost-ceiling", queryCostHandler.SetUserCeiling)
+	admin.Delete("/users/:id/cost-ceiling", queryCostHandler.DeleteUserCeiling)
+	admin.Put("/users/:id/usage-quota", usageHandler.SetUserQuota)
+	admin.Delete("/users/:id/usage-quota", usageHandler.DeleteUserQuota)
+	admin.Get("/usage", usageHandler.GetWorkspaceUsage)
+	admin.Put("/users/:id/mfa", mfaHandler.SetUserRequirement)
+	admin.Delete("/users/:id/mfa", mfaHandler.ResetUser)
+	admin.Get("/mfa-settings", mfaHandler.GetSettings)
+	admin.Put("/mfa-settings", mfaHandler.UpdateSettings)
+
+	// Tenants and their feature flags
+	admin.Get("/tenants", tenantHandler.ListTenants)
+	admin.Post("/tenants", tenantHandler.CreateTenant)
+	admin.Put("/tenants/:id", tenantHandler.UpdateTenant)
+
+	// Feature flags with percentage rollouts and overrides per tenant or user
+	admin.Get("/feature-flags", featureFlagHandler.ListFeatureFlags)
+	admin.Post("/feature-flags", featureFlagHandler.CreateFeatureFlag)
+	admin.Put("/feature-flags/:key", featureFlagHandler.UpdateFeatureFlag)
+	admin.Delete("/feature-flags/:key", featureFlagHandler.DeleteFeatureFlag)
+	admin.Put("/feature-flags/:key/overrides", featureFlagHandler.SetFeatureFlagOverride)
+	admin.Delete("/feature-flags/:key/overrides/:scope/:subjectId", featureFlagHandler.DeleteFeatureFlagOverride)
+
+	// Audit trail of sensitive actions
+	admin.Get("/audit-logs", auditHandler.SearchLogs)
+	admin.Get("/audit-logs/export", auditHandler.ExportLogs)
+
+	// Governance event log and compliance webhook (admin)
+	governance := admin.Group("/governance")
+	governance.Get("/events", governanceHandler.GetEvents)
+	governance.Get("/webhook", governanceHandler.GetDeliveryStatus)
+	governance.Post("/webhook/dispatch", governanceHandler.DispatchEvents)
+	governance.Post("/webhook/replay", governanceHandler.ReplayEvents)
+
+	// SQL validation policies (admin)
+	validationPolicies := admin.Group("/validation-policies")
+	validationPolicies.Get("/", validationPolicyHandler.GetPolicies)
+	validationPolicies.Post("/", validationPolicyHandler.CreatePolicy)
+	validationPolicies.Get("/:id", validationPolicyHandler.GetPolicy)
+	validationPolicies.Put("/:id", validationPolicyHandler.UpdatePolicy)
+	validationPolicies.Delete("/:id", validationPolicyHandler.DeletePolicy)
+
+	// Custom SQL functions allowed per data source (admin)
+	sqlFunctions := admin.Group("/data-sources/:id/sql-functions")
+	sqlFunctions.Get("/", customSQLFunctionHandler.GetFunctions)
+	sqlFunctions.Post("/", customSQLFunctionHandler.CreateFunction)
+	sqlFunctions.Put("/:functionId", customSQLFunctionHandler.UpdateFunction)
+	sqlFunctions.Delete("/:functionId", customSQLFunctionHandler.DeleteFunction)
+
+	// Semantic model of metrics and dimensions per data source (admin)
+	semanticModel := admin.Group("/data-sources/:id/semantic-model")
+	semanticModel.Get("/", semanticModelHandler.GetModel)
+	semanticModel.Post("/metrics", semanticModelHandler.CreateMetric)
+	semanticModel.Put("/metrics/:metricId", semanticModelHandler.UpdateMetric)
+	semanticModel.Delete("/metrics/:metricId", semanticModelHandler.DeleteMetric)
+	semanticModel.Post("/dimensions", semanticModelHandler.CreateDimension)
+	semanticModel.Put("/dimensions/:dimensionId", semanticModelHandler.UpdateDimension)
+	semanticModel.Delete("/dimensions/:dimensionId", semanticModelHandler.DeleteDimension)
+
+	// Route access policies and role assignments (admin), enforced by Casbin
+	if casbinService != nil {
+		accessPolicyHandler := handlers.NewAccessPolicyHandler(casbinService, auditService)
+		admin.Get("/access-policies", accessPolicyHandler.GetPolicies)
+		admin.Post("/access-policies", accessPolicyHandler.AddPolicy)
+		admin.Delete("/access-policies", accessPolicyHandler.RemovePolicy)
+		admin.Get("/role-assignments", accessPolicyHandler.GetRoleAssignments)
+		admin.Post("/role-assignments", accessPolicyHandler.AssignRole)
+		admin.Delete("/role-assignments", accessPolicyHandler.UnassignRole)
+	}
+
+	// Access review entitlement report (admin)
+	admin.Get("/access-review", accessReviewHandler.GetReport)
+
+	// Analytics cache (admin)
+	admin.Get("/analytics/queries", analyticsHandler.GetQueryMetrics)
+	admin.Post("/analytics/refresh", analyticsHandler.RefreshCache)
+
+	// Data source connection templates (admin)
+	admin.Post("/data-source-templates", dataSourceTemplateHandler.CreateTemplate)
+	admin.Put("/data-source-templates/:id", dataSourceTemplateHandler.UpdateTemplate)
+	admin.Delete("/data-source-templates/:id", dataSourceTemplateHandler.DeleteTemplate)
+
+	// Background jobs and their dead letter queue (admin)
+	admin.Get("/jobs", jobHandler.GetJobs)
+	admin.Get("/jobs/stats", jobHandler.GetStats)
+	admin.Get("/jobs/:id", jobHandler.GetJob)
+	admin.Post("/jobs/:id/retry", jobHandler.RetryJob)
+
+	// Digest job (admin, called by cron)
+	admin.Post("/digests/run", digestHandler.RunDue)
+
+	// Chat integrations (Slack, Teams)
+	adminIntegrations := admin.Group("/integrations")
+	adminIntegrations.Get("/", integrationHandler.GetIntegrations)
+	adminIntegrations.Post("/", integrationHandler.CreateIntegration)
+	adminIntegrations.Put("/:id", integrationHandler.UpdateIntegration)
+	adminIntegrations.Delete("/:id", integrationHandler.DeleteIntegration)
+	adminIntegrations.Post("/:id/test", integrationHandler.TestIntegration)
+
+	// Webhook subscriptions to platform events
+	webhooks := admin.Group("/webhooks")
+	webhooks.Get("/", webhookHandler.GetWebhooks)
+	webhooks.Post("/", webhookHandler.CreateWebhook)
+	webhooks.Get("/:id", webhookHandler.GetWebhook)
+	webhooks.Put("/:id", webhookHandler.UpdateWebhook)
+	webhooks.Delete("/:id", webhookHandler.DeleteWebhook)
+	webhooks.Post("/:id/ping", webhookHandler.PingWebhook)
+	webhooks.Get("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	webhooks.Post("/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+
+	// Query result cold storage (admin, called by cron)
+	admin.Post("/query-results/archive", queryResultArchiveHandler.Archive)
+	admin.Post("/query-history/purge", queryRetentionHandler.Purge)
+	admin.Delete("/query-history", queryRetentionHandler.DeleteHistory)
+
+	// Embedding storage compaction and vector index maintenance (admin)
+	embeddings := admin.Group("/embeddings")
+	embeddings.Get("/stats", embeddingMaintenanceHandler.GetStorageStats)
+	embeddings.Post("/compact", embeddingMaintenanceHandler.Compact)
+	embeddings.Post("/reindex", embeddingMaintenanceHandler.RebuildIndexes)
+
+	// NL2SQL prompt templates, versioned per scope (admin)
+	promptTemplates := admin.Group("/prompt-templates")
+	promptTemplates.Get("/", promptTemplateHandler.GetTemplates)
+	promptTemplates.Post("/", promptTemplateHandler.CreateTemplate)
+	promptTemplates.Get("/versions", promptTemplateHandler.GetVersions)
+	promptTemplates.Get("/:id", promptTemplateHandler.GetTemplate)
+	promptTemplates.Post("/:id/activate", promptTemplateHandler.ActivateTemplate)
+	promptTemplates.Delete("/:id", promptTemplateHandler.DeleteTemplate)
+
+	// NL2SQL retrieval parameters per scope (admin)
+	retrievalConfigs := admin.Group("/retrieval-configs")
+	retrievalConfigs.Get("/", retrievalConfigHandler.GetConfigs)
+	retrievalConfigs.Put("/", retrievalConfigHandler.SetConfig)
+	retrievalConfigs.Delete("/", retrievalConfigHandler.DeleteConfig)
+	retrievalConfigs.Get("/effective/:data_source_id", retrievalConfigHandler.GetEffectiveConfig)
+
+	// NL2SQL accuracy evaluation over golden queries (admin)
+	nl2sqlEval := admin.Group("/nl2sql-eval")
+	nl2sqlEval.Get("/golden-queries", nl2sqlEvalHandler.GetGoldenQueries)
+	nl2sqlEval.Post("/golden-queries", nl2sqlEvalHandler.CreateGoldenQuery)
+	nl2sqlEval.Put("/golden-queries/:id", nl2sqlEvalHandler.UpdateGoldenQuery)
+	nl2sqlEval.Delete("/golden-queries/:id", nl2sqlEvalHandler.DeleteGoldenQuery)
+	nl2sqlEval.Get("/runs", nl2sqlEvalHandler.GetRuns)
+	nl2sqlEval.Post("/runs", nl2sqlEvalHandler.StartRun)
+	nl2sqlEval.Get("/runs/:id", nl2sqlEvalHandler.GetRun)
 
 	// Swagger documentation
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
+	// Prometheus metrics, including the connection pools of the application database and data sources
+	if sqlDB, err := db.DB(); err == nil {
+		if err := metrics.RegisterDB("narapulse", sqlDB); err != nil {
+			logger.L().Warn().Err(err).Msg("Failed to register database pool metrics")
+		}
+	}
+	if err := metrics.RegisterDataSourcePools(pgPool.Stats); err != nil {
+		logger.L().Warn().Err(err).Msg("Failed to register data source pool metrics")
+	}
+	app.Get("/metrics", middleware.MetricsAuthMiddleware(cfg.MetricsToken), adaptor.HTTPHandler(metrics.Handler()))
+
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"success": true,
-			"message": "Server is running",
-			"data":    nil,
-		})
+		return models.SuccessResponse(c, "Server is running", nil)
 	})
+	return stop, nil
 }
\ No newline at end of file
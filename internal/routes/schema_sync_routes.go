@@ -3,16 +3,15 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"narapulse-be/internal/handlers"
-	"narapulse-be/internal/middleware"
 )
 
-// SetupSchemaSyncRoutes sets up the schema synchronization routes
-func SetupSchemaSyncRoutes(app *fiber.App, schemaSyncHandler *handlers.SchemaSyncHandler) {
+// SetupSchemaSyncRoutes sets up the schema synchronization routes; authenticate validates the token
+func SetupSchemaSyncRoutes(app *fiber.App, schemaSyncHandler *handlers.SchemaSyncHandler, authenticate fiber.Handler) {
 	// Schema sync routes group
 	schemaSync := app.Group("/api/v1/schema-sync")
 
 	// Apply authentication middleware to all schema sync routes
-	schemaSync.Use(middleware.AuthMiddleware())
+	schemaSync.Use(authenticate)
 
 	// Get sync status for all data sources
 	schemaSync.Get("/status", schemaSyncHandler.GetSyncStatus)
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// AccessReviewService builds the per-user entitlement report used in
+// quarterly access reviews. Roles come from the user record and Casbin;
+// activity comes from queries, governance events and data API key usage.
+type AccessReviewService struct {
+	db                *gorm.DB
+	casbinService     *CasbinService // Optional; without it only the user role is reported
+	governanceService *GovernanceService
+}
+
+// NewAccessReviewService creates a new access review service
+func NewAccessReviewService(db *gorm.DB, casbinService *CasbinService, governanceService *GovernanceService) *AccessReviewService {
+	return &AccessReviewService{
+		db:                db,
+		casbinService:     casbinService,
+		governanceService: governanceService,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *AccessReviewService) WithContext(ctx context.Context) *AccessReviewService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.governanceService = s.governanceService.WithContext(ctx)
+	return &scoped
+}
+
+// accessReviewActivity is the latest activity timestamp of a user from one source
+type accessReviewActivity struct {
+	UserID uint
+	LastAt time.Time
+}
+
+// Generate builds the entitlement report for all users, ordered by email
+func (s *AccessReviewService) Generate() (*models.AccessReviewReport, error) {
+	var users []models.User
+	if err := s.db.Order("email").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	var dataSources []models.DataSource
+	if err := s.db.Select("id", "user_id", "name", "type").Order("name").Find(&dataSources).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data sources: %w", err)
+	}
+
+	var schemas []models.Schema
+	if err := s.db.Select("data_source_id", "name", "columns").Where("is_active").Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	piiColumns := s.piiColumnsByDataSource(schemas)
+
+	apiKeys, err := s.apiKeysByUser()
+	if err != nil {
+		return nil, err
+	}
+
+	lastActivity, err := s.lastActivityByUser()
+	if err != nil {
+		return nil, err
+	}
+
+	dataSourcesByUser := make(map[uint][]models.DataSource)
+	for _, ds := range dataSources {
+		dataSourcesByUser[ds.UserID] = append(dataSourcesByUser[ds.UserID], ds)
+	}
+
+	report := &models.AccessReviewReport{
+		GeneratedAt: time.Now(),
+		Users:       make([]models.AccessReviewEntry, 0, len(users)),
+	}
+	for _, user := range users {
+		entry := models.AccessReviewEntry{
+			UserID:        user.ID,
+			Email:         user.Email,
+			Username:      user.Username,
+			IsActive:      user.IsActive,
+			Roles:         s.rolesForUser(user),
+			DataSources:   []models.AccessReviewDataSource{},
+			MaskedColumns: []string{},
+			APIKeys:       apiKeys[user.ID],
+			CreatedAt:     user.CreatedAt,
+		}
+		if entry.APIKeys == nil {
+			entry.APIKeys = []models.AccessReviewAPIKey{}
+		}
+		if last, ok := lastActivity[user.ID]; ok {
+			entry.LastActivityAt = &last
+		}
+
+		for _, ds := range dataSourcesByUser[user.ID] {
+			entry.DataSources = append(entry.DataSources, models.AccessReviewDataSource{ID: ds.ID, Name: ds.Name, Type: ds.Type})
+			for _, column := range piiColumns[ds.ID] {
+				entry.MaskedColumns = append(entry.MaskedColumns, ds.Name+"."+column)
+			}
+		}
+
+		report.Users = append(report.Users, entry)
+	}
+
+	return report, nil
+}
+
+// rolesForUser combines the role on the user record with the roles Casbin
+// grants to the user's email
+func (s *AccessReviewService) rolesForUser(user models.User) []string {
+	roles := []string{}
+	if user.Role != "" {
+		roles = append(roles, user.Role)
+	}
+
+	if s.casbinService != nil {
+		casbinRoles, err := s.casbinService.GetRolesForUser(user.Email)
+		if err != nil {
+			logger.L().Error().Err(err).Uint("user_id", user.ID).Msg("Failed to get Casbin roles")
+		}
+		roles = append(roles, casbinRoles...)
+	}
+
+	return uniqueSorted(roles)
+}
+
+// piiColumnsByDataSource returns the PII columns of each data source as table.column
+func (s *AccessReviewService) piiColumnsByDataSource(schemas []models.Schema) map[uint][]string {
+	columnsByDataSource := make(map[uint][]string)
+	for _, schema := range schemas {
+		var columns []models.Column
+		if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+			continue
+		}
+		for _, column := range s.governanceService.DetectPIIColumns(columns) {
+			columnsByDataSource[schema.DataSourceID] = append(columnsByDataSource[schema.DataSourceID], schema.Name+"."+column)
+		}
+	}
+	for id := range columnsByDataSource {
+		sort.Strings(columnsByDataSource[id])
+	}
+	return columnsByDataSource
+}
+
+// apiKeysByUser returns the data API keys of each endpoint owner
+func (s *AccessReviewService) apiKeysByUser() (map[uint][]models.AccessReviewAPIKey, error) {
+	var endpoints []models.DataAPIEndpoint
+	if err := s.db.Select("id", "user_id", "slug").Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data API endpoints: %w", err)
+	}
+	endpointByID := make(map[uint]models.DataAPIEndpoint, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpointByID[endpoint.ID] = endpoint
+	}
+
+	var keys []models.DataAPIKey
+	if err := s.db.Order("id").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data API keys: %w", err)
+	}
+
+	keysByUser := make(map[uint][]models.AccessReviewAPIKey)
+	for _, key := range keys {
+		endpoint, ok := endpointByID[key.EndpointID]
+		if !ok {
+			continue
+		}
+		keysByUser[endpoint.UserID] = append(keysByUser[endpoint.UserID], models.AccessReviewAPIKey{
+			ID:           key.ID,
+			Name:         key.Name,
+			KeyPrefix:    key.KeyPrefix,
+			EndpointSlug: endpoint.Slug,
+			LastUsedAt:   key.LastUsedAt,
+			RevokedAt:    key.RevokedAt,
+		})
+	}
+	return keysByUser, nil
+}
+
+// lastActivityByUser returns the latest of each user's last query, last
+// governance event and last data API key use
+func (s *AccessReviewService) lastActivityByUser() (map[uint]time.Time, error) {
+	sources := []struct {
+		name  string
+		query *gorm.DB
+	}{
+		{"queries", s.db.Model(&models.NL2SQLQuery{}).
+			Select("user_id, MAX(created_at) AS last_at").Group("user_id")},
+		{"governance events", s.db.Model(&models.GovernanceEvent{}).
+			Select("actor_id AS user_id, MAX(created_at) AS last_at").Where("actor_id > 0").Group("actor_id")},
+		{"data API key usage", s.db.Model(&models.DataAPIKey{}).
+			Joins("JOIN data_api_endpoints ON data_api_endpoints.id = data_api_keys.endpoint_id").
+			Select("data_api_endpoints.user_id AS user_id, MAX(data_api_keys.last_used_at) AS last_at").
+			Where("data_api_keys.last_used_at IS NOT NULL").Group("data_api_endpoints.user_id")},
+	}
+
+	lastActivity := make(map[uint]time.Time)
+	for _, source := range sources {
+		var rows []accessReviewActivity
+		if err := source.query.Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to load last activity from %s: %w", source.name, err)
+		}
+		for _, row := range rows {
+			if row.LastAt.After(lastActivity[row.UserID]) {
+				lastActivity[row.UserID] = row.LastAt
+			}
+		}
+	}
+	return lastActivity, nil
+}
+
+// accessReviewCSVHeader is the header row of the CSV export
+var accessReviewCSVHeader = []string{
+	"user_id", "email", "username", "is_active", "roles", "data_sources",
+	"masked_columns", "api_keys", "last_activity_at", "created_at",
+}
+
+// WriteAccessReviewCSV writes the report as CSV with one row per user. List
+// columns are joined with "; " and API keys are written as name (prefix…, slug),
+// marked revoked where applicable.
+func WriteAccessReviewCSV(w io.Writer, report *models.AccessReviewReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(accessReviewCSVHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range report.Users {
+		dataSources := make([]string, len(entry.DataSources))
+		for i, ds := range entry.DataSources {
+			dataSources[i] = fmt.Sprintf("%s (%s)", ds.Name, ds.Type)
+		}
+
+		apiKeys := make([]string, len(entry.APIKeys))
+		for i, key := range entry.APIKeys {
+			apiKeys[i] = fmt.Sprintf("%s (%s…, %s)", key.Name, key.KeyPrefix, key.EndpointSlug)
+			if key.RevokedAt != nil {
+				apiKeys[i] += " revoked"
+			}
+		}
+
+		lastActivity := ""
+		if entry.LastActivityAt != nil {
+			lastActivity = entry.LastActivityAt.UTC().Format(time.RFC3339)
+		}
+
+		if err := writer.Write([]string{
+			strconv.FormatUint(uint64(entry.UserID), 10),
+			entry.Email,
+			entry.Username,
+			strconv.FormatBool(entry.IsActive),
+			strings.Join(entry.Roles, "; "),
+			strings.Join(dataSources, "; "),
+			strings.Join(entry.MaskedColumns, "; "),
+			strings.Join(apiKeys, "; "),
+			lastActivity,
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// uniqueSorted returns the distinct values in sorted order
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAccessReviewCSV(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	lastActivity := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	report := &models.AccessReviewReport{
+		Users: []models.AccessReviewEntry{
+			{
+				UserID:   1,
+				Email:    "ana@example.com",
+				Username: "ana",
+				IsActive: true,
+				Roles:    []string{"admin", "user"},
+				DataSources: []models.AccessReviewDataSource{
+					{ID: 3, Name: "warehouse", Type: models.DataSourceTypePostgreSQL},
+				},
+				MaskedColumns:  []string{"warehouse.customers.email"},
+				APIKeys:        []models.AccessReviewAPIKey{{Name: "reporting", KeyPrefix: "np_ab12", EndpointSlug: "sales", RevokedAt: &created}},
+				LastActivityAt: &lastActivity,
+				CreatedAt:      created,
+			},
+			{UserID: 2, Email: "budi@example.com", Username: "budi", Roles: []string{"user"}, CreatedAt: created},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAccessReviewCSV(&buf, report))
+	assert.Equal(t,
+		"user_id,email,username,is_active,roles,data_sources,masked_columns,api_keys,last_activity_at,created_at\n"+
+			"1,ana@example.com,ana,true,admin; user,warehouse (postgresql),warehouse.customers.email,\"reporting (np_ab12…, sales) revoked\",2025-09-01T08:00:00Z,2025-01-02T03:04:05Z\n"+
+			"2,budi@example.com,budi,false,user,,,,,2025-01-02T03:04:05Z\n",
+		buf.String())
+}
+
+func TestAccessReviewService_RolesWithoutCasbin(t *testing.T) {
+	service := NewAccessReviewService(nil, nil, nil)
+	assert.Equal(t, []string{"admin"}, service.rolesForUser(models.User{Role: "admin"}))
+	assert.Equal(t, []string{}, service.rolesForUser(models.User{}))
+}
+
+func TestAccessReviewService_PIIColumnsByDataSource(t *testing.T) {
+	service := NewAccessReviewService(nil, nil, NewGovernanceService(nil, "", ""))
+	columns := service.piiColumnsByDataSource([]models.Schema{
+		{DataSourceID: 1, Name: "customers", Columns: models.JSON(`[{"name":"id"},{"name":"email"},{"name":"phone_number"}]`)},
+		{DataSourceID: 1, Name: "orders", Columns: models.JSON(`[{"name":"amount"}]`)},
+		{DataSourceID: 2, Name: "broken", Columns: models.JSON(`not json`)},
+	})
+	assert.Equal(t, map[uint][]string{1: {"customers.email", "customers.phone_number"}}, columns)
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/chat"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+// ErrAlertRuleNotFound is returned when an alert rule does not exist or belongs to another user
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+const (
+	// alertCheckBatch bounds the rules checked by one run of the alert job
+	alertCheckBatch = 100
+	// defaultAlertIntervalMinutes is how often a rule is checked unless set
+	defaultAlertIntervalMinutes = 60
+)
+
+// AlertService checks KPI threshold rules and posts to a chat integration
+// when a KPI crosses its threshold and when it recovers. A KPI is compared
+// over its default range, the last 30 days. CheckDue is run periodically by
+// the alert job.
+type AlertService struct {
+	db            *gorm.DB
+	kpis          *KPIService
+	integrations  *IntegrationService
+	webhooks      *WebhookService
+	notifications *NotificationHub
+	now           func() time.Time
+}
+
+// NewAlertService creates a new alert service
+func NewAlertService(db *gorm.DB, kpis *KPIService, integrations *IntegrationService, webhooks *WebhookService, notifications *NotificationHub) *AlertService {
+	return &AlertService{
+		db:            db,
+		kpis:          kpis,
+		integrations:  integrations,
+		webhooks:      webhooks,
+		notifications: notifications,
+		now:           time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *AlertService) WithContext(ctx context.Context) *AlertService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.kpis = s.kpis.WithContext(ctx)
+	scoped.integrations = s.integrations.WithContext(ctx)
+	scoped.webhooks = s.webhooks.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the job checking due alert rules; it is scheduled at the alert check interval
+func (s *AlertService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeAlertCheck, func(ctx context.Context, _ json.RawMessage) error {
+		result, err := s.CheckDue(ctx)
+		if err != nil {
+			return err
+		}
+		if result.Due > 0 {
+			logger.FromContext(ctx).Info().Int("due", result.Due).Int("triggered", result.Triggered).
+				Int("resolved", result.Resolved).Int("failed", result.Failed).Msg("Alert rules checked")
+		}
+		return nil
+	})
+}
+
+// List returns the alert rules of a user
+func (s *AlertService) List(userID uint) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	if err := s.db.Where("user_id = ?", userID).Order("name").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Get returns an alert rule of the user
+func (s *AlertService) Get(userID, id uint) (*models.AlertRule, error) {
+	return s.owned(userID, id)
+}
+
+// Create creates an alert rule, first checked at once
+func (s *AlertService) Create(userID uint, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	rule := &models.AlertRule{UserID: userID, NextCheckAt: s.now()}
+	if err := s.apply(userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Update replaces an alert rule. Changing what is compared resets its state.
+func (s *AlertService) Update(userID, id uint, req *models.AlertRuleRequest) (*models.AlertRule, error) {
+	rule, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.KPIID != req.KPIID || rule.Condition != req.Condition || rule.Threshold != req.Threshold {
+		rule.Triggered = false
+		rule.NextCheckAt = s.now()
+	}
+	if err := s.apply(userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Delete deletes an alert rule
+func (s *AlertService) Delete(userID, id uint) error {
+	rule, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(rule).Error; err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return nil
+}
+
+// Check checks an alert rule of the user now, posting if its state changes
+func (s *AlertService) Check(ctx context.Context, userID, id uint) (*models.AlertRule, error) {
+	rule, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.check(ctx, rule); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// CheckDue checks the active rules whose next check is due
+func (s *AlertService) CheckDue(ctx context.Context) (*models.AlertCheckResult, error) {
+	now := s.now()
+
+	var rules []models.AlertRule
+	err := s.db.Where("is_active = ? AND next_check_at <= ?", true, now).
+		Order("next_check_at").Limit(alertCheckBatch).Find(&rules).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due alert rules: %w", err)
+	}
+
+	result := &models.AlertCheckResult{Due: len(rules)}
+	for i := range rules {
+		rule := &rules[i]
+		if ctx.Err() != nil {
+			break
+		}
+
+		next := now.Add(time.Duration(rule.IntervalMinutes) * time.Minute)
+		claim := s.db.Model(&models.AlertRule{}).
+			Where("id = ? AND next_check_at = ?", rule.ID, rule.NextCheckAt).
+			Update("next_check_at", next)
+		if claim.Error != nil {
+			return result, fmt.Errorf("failed to claim alert rule: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		rule.NextCheckAt = next
+
+		// The rule is checked in the tenant of its owner
+		tenantCtx := tenancy.WithTenant(ctx, rule.TenantID)
+		changed, err := s.WithContext(tenantCtx).check(tenantCtx, rule)
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("alert_rule_id", rule.ID).Msg("Failed to check alert rule")
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("alert rule %d: %v", rule.ID, err))
+			continue
+		}
+		if changed && rule.Triggered {
+			result.Triggered++
+		} else if changed {
+			result.Resolved++
+		}
+	}
+	return result, nil
+}
+
+// check computes the KPI of a rule and posts when the rule triggers or
+// resolves, recording the outcome. It reports whether the state changed.
+// A failed post leaves the state as it was, so the next check posts again.
+func (s *AlertService) check(ctx context.Context, rule *models.AlertRule) (bool, error) {
+	now := s.now()
+	rule.LastCheckedAt = &now
+
+	kpi, err := s.kpis.Compute(rule.UserID, rule.KPIID, &models.KPIComputeRequest{})
+	if err != nil {
+		return false, s.record(rule, err)
+	}
+	if kpi.Current.Value == nil {
+		return false, s.record(rule, fmt.Errorf("KPI has no value"))
+	}
+	value := *kpi.Current.Value
+	rule.LastValue = &value
+
+	triggered := alertTriggered(rule.Condition, value, rule.Threshold)
+	if triggered == rule.Triggered {
+		return false, s.record(rule, nil)
+	}
+
+	name := kpi.DisplayName
+	if name == "" {
+		name = kpi.Name
+	}
+	msg := chat.Message{Text: describeAlert(rule, name, kpi.Unit, value, triggered)}
+	if err := s.integrations.Post(ctx, rule.IntegrationID, rule.Channel, msg); err != nil {
+		return false, s.record(rule, fmt.Errorf("failed to post alert: %w", err))
+	}
+
+	rule.Triggered = triggered
+	if triggered {
+		rule.LastTriggeredAt = &now
+		event := models.AlertTriggeredEvent{
+			AlertRuleID: rule.ID,
+			UserID:      rule.UserID,
+			Name:        rule.Name,
+			KPIID:       rule.KPIID,
+			KPI:         name,
+			Condition:   rule.Condition,
+			Threshold:   rule.Threshold,
+			Value:       value,
+			TriggeredAt: now,
+		}
+		s.webhooks.Publish(ctx, models.WebhookEventAlertTriggered, event)
+		s.notifications.Notify(ctx, rule.UserID, models.NotificationAlertTriggered, event)
+	}
+	return true, s.record(rule, nil)
+}
+
+// record saves the outcome of a check, returning the check error
+func (s *AlertService) record(rule *models.AlertRule, checkErr error) error {
+	rule.LastError = ""
+	if checkErr != nil {
+		rule.LastError = checkErr.Error()
+	}
+	err := s.db.Model(&models.AlertRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+		"triggered":         rule.Triggered,
+		"last_value":        rule.LastValue,
+		"last_error":        rule.LastError,
+		"last_checked_at":   rule.LastCheckedAt,
+		"last_triggered_at": rule.LastTriggeredAt,
+	}).Error
+	if checkErr != nil {
+		return checkErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return nil
+}
+
+// apply validates a request and copies it onto a rule
+func (s *AlertService) apply(userID uint, rule *models.AlertRule, req *models.AlertRuleRequest) error {
+	var count int64
+	err := s.db.Model(&models.KPIDefinition{}).
+		Where("id = ? AND user_id = ? AND is_active = ?", req.KPIID, userID, true).Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check KPI: %w", err)
+	}
+	if count == 0 {
+		return ErrKPINotFound
+	}
+	integration, err := s.integrations.get(req.IntegrationID, true)
+	if err != nil {
+		return err
+	}
+	if req.Channel != "" && integration.BotToken == "" {
+		return fmt.Errorf("%w: only Slack apps with a bot token can post to another channel", ErrInvalidIntegration)
+	}
+
+	interval := req.IntervalMinutes
+	if interval == 0 {
+		interval = defaultAlertIntervalMinutes
+	}
+	rule.Name = req.Name
+	rule.KPIID = req.KPIID
+	rule.Condition = req.Condition
+	rule.Threshold = req.Threshold
+	rule.IntegrationID = req.IntegrationID
+	rule.Channel = req.Channel
+	rule.IntervalMinutes = interval
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+	return nil
+}
+
+func (s *AlertService) owned(userID, id uint) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// alertTriggered reports whether a value is past the threshold of a rule
+func alertTriggered(condition models.AlertCondition, value, threshold float64) bool {
+	if condition == models.AlertConditionBelow {
+		return value < threshold
+	}
+	return value > threshold
+}
+
+// describeAlert phrases the message posted when a rule triggers or resolves
+func describeAlert(rule *models.AlertRule, kpiName, unit string, value float64, triggered bool) string {
+	current := formatAnswerValue(value, unit)
+	threshold := formatAnswerValue(rule.Threshold, unit)
+	if triggered {
+		return fmt.Sprintf("Alert %q: %s is %s, %s the threshold of %s.", rule.Name, kpiName, current, rule.Condition, threshold)
+	}
+	return fmt.Sprintf("Resolved %q: %s is back at %s, no longer %s the threshold of %s.", rule.Name, kpiName, current, rule.Condition, threshold)
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertTriggered(t *testing.T) {
+	assert.True(t, alertTriggered(models.AlertConditionAbove, 12, 10))
+	assert.False(t, alertTriggered(models.AlertConditionAbove, 10, 10))
+	assert.True(t, alertTriggered(models.AlertConditionBelow, 8, 10))
+	assert.False(t, alertTriggered(models.AlertConditionBelow, 10, 10))
+}
+
+func TestDescribeAlert(t *testing.T) {
+	rule := &models.AlertRule{Name: "Churn spike", Condition: models.AlertConditionAbove, Threshold: 5}
+
+	assert.Equal(t, `Alert "Churn spike": Churn rate is 7.5%, above the threshold of 5%.`,
+		describeAlert(rule, "Churn rate", "percentage", 7.5, true))
+	assert.Equal(t, `Resolved "Churn spike": Churn rate is back at 4.25%, no longer above the threshold of 5%.`,
+		describeAlert(rule, "Churn rate", "percentage", 4.25, false))
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"narapulse-be/internal/config"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+// analyticsStateID is the single refresh state row of the analytics cache
+const analyticsStateID = 1
+
+// analyticsRefreshLockKey is the advisory lock that keeps refreshes from overlapping
+const analyticsRefreshLockKey = 72850001
+
+// analyticsWatermarkOverlap re-checks a short window before the watermark so rows
+// committed by transactions that started before the last refresh are not missed
+const analyticsWatermarkOverlap = time.Minute
+
+// defaultMetricsPeriod is used when no start date is given
+const defaultMetricsPeriod = 30 * 24 * time.Hour
+
+// AnalyticsService maintains the query_analytics_daily rollup and serves the
+// aggregate metrics endpoints from it. The rollup is refreshed incrementally:
+// only days that contain queries changed since the last refresh are recomputed.
+type AnalyticsService struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	cfg := config.Load()
+	return &AnalyticsService{
+		db:       db,
+		cacheTTL: time.Duration(cfg.AnalyticsCacheTTLSeconds) * time.Second,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *AnalyticsService) WithContext(ctx context.Context) *AnalyticsService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Refresh recomputes the rollup for every day touched since the last refresh.
+// The rollup is shared by the installation, so every tenant's rows of those
+// days are rebuilt, whatever the tenant of the service's context.
+// If another refresh is running, the current state is returned unchanged.
+func (s *AnalyticsService) Refresh() (*models.AnalyticsRefreshState, error) {
+	state := models.AnalyticsRefreshState{ID: analyticsStateID}
+	startTime := time.Now()
+
+	db := s.db.WithContext(tenancy.WithTenant(s.db.Statement.Context, 0))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", analyticsRefreshLockKey).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("failed to acquire refresh lock: %w", err)
+		}
+
+		if err := tx.FirstOrCreate(&state, models.AnalyticsRefreshState{ID: analyticsStateID}).Error; err != nil {
+			return fmt.Errorf("failed to load refresh state: %w", err)
+		}
+		if !locked {
+			return nil
+		}
+
+		var dbNow time.Time
+		if err := tx.Raw("SELECT now()").Scan(&dbNow).Error; err != nil {
+			return fmt.Errorf("failed to read database time: %w", err)
+		}
+
+		// A zero watermark means the cache has never been built, so every day is rebuilt
+		since := time.Unix(0, 0)
+		if !state.Watermark.IsZero() {
+			since = state.Watermark.Add(-analyticsWatermarkOverlap)
+		}
+		var days []time.Time
+		if err := tx.Raw(`
+			SELECT DISTINCT created_at::date
+			FROM nl2_sql_queries
+			WHERE updated_at > ? OR deleted_at > ?`, since, since).
+			Scan(&days).Error; err != nil {
+			return fmt.Errorf("failed to find changed days: %w", err)
+		}
+
+		if len(days) > 0 {
+			if err := tx.Where("day IN ?", days).Delete(&models.QueryAnalyticsDaily{}).Error; err != nil {
+				return fmt.Errorf("failed to clear changed days: %w", err)
+			}
+
+			if err := tx.Exec(`
+				INSERT INTO query_analytics_daily
+					(day, tenant_id, user_id, data_source_id, status, query_count, total_execution_ms, total_rows, updated_at)
+				SELECT created_at::date, tenant_id, user_id, data_source_id, status,
+					COUNT(*), COALESCE(SUM(execution_time), 0), COALESCE(SUM(rows_returned), 0), ?
+				FROM nl2_sql_queries
+				WHERE deleted_at IS NULL AND created_at::date IN ?
+				GROUP BY created_at::date, tenant_id, user_id, data_source_id, status`, dbNow, days).Error; err != nil {
+				return fmt.Errorf("failed to rebuild changed days: %w", err)
+			}
+		}
+
+		state.Watermark = dbNow
+		state.RefreshedAt = time.Now()
+		state.DaysUpdated = len(days)
+		state.DurationMs = time.Since(startTime).Milliseconds()
+		return tx.Save(&state).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// GetQueryMetrics returns aggregate query metrics of the tenant of the service's
+// context, refreshing the cache first when it is stale
+func (s *AnalyticsService) GetQueryMetrics(filter models.QueryMetricsFilter) (*models.QueryMetricsResponse, error) {
+	state := s.ensureFresh()
+
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultMetricsPeriod)
+	}
+
+	query := s.db.Where("day BETWEEN ?::date AND ?::date", filter.From, filter.To)
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.DataSourceID > 0 {
+		query = query.Where("data_source_id = ?", filter.DataSourceID)
+	}
+
+	var rows []models.QueryAnalyticsDaily
+	if err := query.Order("day ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get query metrics: %w", err)
+	}
+
+	response := summarizeQueryAnalytics(rows)
+	if err := s.fillDataSourceNames(response.ByDataSource); err != nil {
+		return nil, err
+	}
+	if state != nil {
+		response.CacheRefreshedAt = &state.RefreshedAt
+	}
+
+	return response, nil
+}
+
+// ensureFresh refreshes the cache when it is older than the TTL. Failures are
+// logged and the stale cache is served.
+func (s *AnalyticsService) ensureFresh() *models.AnalyticsRefreshState {
+	var state models.AnalyticsRefreshState
+	err := s.db.First(&state, analyticsStateID).Error
+	if err == nil && time.Since(state.RefreshedAt) < s.cacheTTL {
+		return &state
+	}
+
+	refreshed, refreshErr := s.Refresh()
+	if refreshErr != nil {
+		logger.L().Error().Err(refreshErr).Msg("Analytics cache refresh failed")
+		if err != nil {
+			return nil
+		}
+		return &state
+	}
+	return refreshed
+}
+
+// fillDataSourceNames looks up the names of the data sources in the breakdown
+func (s *AnalyticsService) fillDataSourceNames(metrics []models.DataSourceQueryMetrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(metrics))
+	for i, m := range metrics {
+		ids[i] = m.DataSourceID
+	}
+
+	var dataSources []models.DataSource
+	if err := s.db.Select("id", "name").Where("id IN ?", ids).Find(&dataSources).Error; err != nil {
+		return fmt.Errorf("failed to get data source names: %w", err)
+	}
+
+	names := make(map[uint]string, len(dataSources))
+	for _, ds := range dataSources {
+		names[ds.ID] = ds.Name
+	}
+	for i := range metrics {
+		metrics[i].DataSourceName = names[metrics[i].DataSourceID]
+	}
+	return nil
+}
+
+// summarizeQueryAnalytics folds rollup rows into totals, a daily series and a
+// per data source breakdown
+func summarizeQueryAnalytics(rows []models.QueryAnalyticsDaily) *models.QueryMetricsResponse {
+	response := &models.QueryMetricsResponse{
+		Daily:        []models.DailyQueryMetrics{},
+		ByDataSource: []models.DataSourceQueryMetrics{},
+	}
+
+	dailyIndex := make(map[string]int)
+	sourceIndex := make(map[uint]int)
+	var completedExecutionMs int64
+
+	for _, row := range rows {
+		summary := &response.Summary
+		summary.TotalQueries += row.QueryCount
+		summary.TotalRowsReturned += row.TotalRows
+
+		day := row.Day.Format("2006-01-02")
+		i, ok := dailyIndex[day]
+		if !ok {
+			i = len(response.Daily)
+			dailyIndex[day] = i
+			response.Daily = append(response.Daily, models.DailyQueryMetrics{Day: day})
+		}
+		daily := &response.Daily[i]
+		daily.TotalQueries += row.QueryCount
+
+		j, ok := sourceIndex[row.DataSourceID]
+		if !ok {
+			j = len(response.ByDataSource)
+			sourceIndex[row.DataSourceID] = j
+			response.ByDataSource = append(response.ByDataSource, models.DataSourceQueryMetrics{DataSourceID: row.DataSourceID})
+		}
+		source := &response.ByDataSource[j]
+		source.TotalQueries += row.QueryCount
+
+		switch row.Status {
+		case models.QueryStatusCompleted:
+			summary.CompletedQueries += row.QueryCount
+			daily.CompletedQueries += row.QueryCount
+			completedExecutionMs += row.TotalExecutionMs
+		case models.QueryStatusFailed:
+			summary.FailedQueries += row.QueryCount
+			daily.FailedQueries += row.QueryCount
+			source.FailedQueries += row.QueryCount
+		}
+	}
+
+	summary := &response.Summary
+	if finished := summary.CompletedQueries + summary.FailedQueries; finished > 0 {
+		summary.SuccessRate = float64(summary.CompletedQueries) / float64(finished)
+	}
+	if summary.CompletedQueries > 0 {
+		summary.AvgExecutionTimeMs = float64(completedExecutionMs) / float64(summary.CompletedQueries)
+	}
+
+	sort.Slice(response.Daily, func(a, b int) bool {
+		return response.Daily[a].Day < response.Daily[b].Day
+	})
+	sort.Slice(response.ByDataSource, func(a, b int) bool {
+		return response.ByDataSource[a].TotalQueries > response.ByDataSource[b].TotalQueries
+	})
+
+	return response
+}
@@ -0,0 +1,72 @@
+//go:build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/database"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestAnalyticsServiceIntegration_RefreshKeepsTenantsApart(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: integrationPostgres.DB}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, database.Migrate(context.Background(), db))
+
+	tenantA := tenancy.WithTenant(context.Background(), 1)
+	tenantB := tenancy.WithTenant(context.Background(), 2)
+	sourceA := models.DataSource{Name: "warehouse-a", Type: models.DataSourceTypePostgreSQL, UserID: 1}
+	sourceB := models.DataSource{Name: "warehouse-b", Type: models.DataSourceTypePostgreSQL, UserID: 2}
+	require.NoError(t, db.WithContext(tenantA).Create(&sourceA).Error)
+	require.NoError(t, db.WithContext(tenantB).Create(&sourceB).Error)
+
+	queries := []struct {
+		ctx   context.Context
+		query models.NL2SQLQuery
+	}{
+		{tenantA, models.NL2SQLQuery{UserID: 1, DataSourceID: sourceA.ID, NLQuery: "revenue", Status: models.QueryStatusCompleted, ExecutionTime: 10, RowsReturned: 3}},
+		{tenantA, models.NL2SQLQuery{UserID: 1, DataSourceID: sourceA.ID, NLQuery: "orders", Status: models.QueryStatusFailed}},
+		{tenantB, models.NL2SQLQuery{UserID: 2, DataSourceID: sourceB.ID, NLQuery: "churn", Status: models.QueryStatusCompleted, ExecutionTime: 40, RowsReturned: 7}},
+	}
+	for _, q := range queries {
+		require.NoError(t, db.WithContext(q.ctx).Create(&q.query).Error)
+	}
+
+	// A refresh started by a request of one tenant rebuilds the rollup of all of them
+	state, err := NewAnalyticsService(db).WithContext(tenantA).Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.DaysUpdated)
+
+	metricsA, err := NewAnalyticsService(db).WithContext(tenantA).GetQueryMetrics(models.QueryMetricsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), metricsA.Summary.TotalQueries)
+	assert.Equal(t, int64(1), metricsA.Summary.FailedQueries)
+	assert.Equal(t, int64(3), metricsA.Summary.TotalRowsReturned)
+	require.Len(t, metricsA.ByDataSource, 1)
+	assert.Equal(t, "warehouse-a", metricsA.ByDataSource[0].DataSourceName)
+
+	metricsB, err := NewAnalyticsService(db).WithContext(tenantB).GetQueryMetrics(models.QueryMetricsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), metricsB.Summary.TotalQueries)
+	assert.Zero(t, metricsB.Summary.FailedQueries)
+	assert.Equal(t, int64(7), metricsB.Summary.TotalRowsReturned)
+	require.Len(t, metricsB.ByDataSource, 1)
+	assert.Equal(t, "warehouse-b", metricsB.ByDataSource[0].DataSourceName)
+
+	// The other tenant's data source is not found even when asked for by ID
+	filtered, err := NewAnalyticsService(db).WithContext(tenantB).GetQueryMetrics(models.QueryMetricsFilter{DataSourceID: sourceA.ID})
+	require.NoError(t, err)
+	assert.Zero(t, filtered.Summary.TotalQueries)
+}
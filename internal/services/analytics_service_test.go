@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestSummarizeQueryAnalytics(t *testing.T) {
+	day1 := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	rows := []models.QueryAnalyticsDaily{
+		{Day: day2, DataSourceID: 1, Status: models.QueryStatusCompleted, QueryCount: 2, TotalExecutionMs: 300, TotalRows: 20},
+		{Day: day1, DataSourceID: 1, Status: models.QueryStatusCompleted, QueryCount: 4, TotalExecutionMs: 500, TotalRows: 40},
+		{Day: day1, DataSourceID: 2, Status: models.QueryStatusFailed, QueryCount: 2},
+		{Day: day1, DataSourceID: 2, Status: models.QueryStatusPending, QueryCount: 1},
+	}
+
+	result := summarizeQueryAnalytics(rows)
+
+	assert.Equal(t, int64(9), result.Summary.TotalQueries)
+	assert.Equal(t, int64(6), result.Summary.CompletedQueries)
+	assert.Equal(t, int64(2), result.Summary.FailedQueries)
+	assert.InDelta(t, 0.75, result.Summary.SuccessRate, 0.0001)
+	assert.InDelta(t, 800.0/6.0, result.Summary.AvgExecutionTimeMs, 0.0001)
+	assert.Equal(t, int64(60), result.Summary.TotalRowsReturned)
+
+	// Days are sorted ascending
+	assert.Len(t, result.Daily, 2)
+	assert.Equal(t, "2025-09-01", result.Daily[0].Day)
+	assert.Equal(t, int64(7), result.Daily[0].TotalQueries)
+	assert.Equal(t, int64(2), result.Daily[0].FailedQueries)
+	assert.Equal(t, "2025-09-02", result.Daily[1].Day)
+
+	// Data sources are sorted by volume
+	assert.Len(t, result.ByDataSource, 2)
+	assert.Equal(t, uint(1), result.ByDataSource[0].DataSourceID)
+	assert.Equal(t, int64(6), result.ByDataSource[0].TotalQueries)
+	assert.Equal(t, int64(2), result.ByDataSource[1].FailedQueries)
+}
+
+func TestSummarizeQueryAnalytics_Empty(t *testing.T) {
+	result := summarizeQueryAnalytics(nil)
+
+	assert.Equal(t, int64(0), result.Summary.TotalQueries)
+	assert.Equal(t, 0.0, result.Summary.SuccessRate)
+	assert.NotNil(t, result.Daily)
+	assert.NotNil(t, result.ByDataSource)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// defaultJitterRatio is the maximum relative change applied to numeric values
+const defaultJitterRatio = 0.05
+
+// AnonymizerService pseudonymizes query results for demos. The same input
+// always maps to the same output, so joins, group-bys and distributions survive.
+type AnonymizerService struct {
+	secret      []byte
+	jitterRatio float64
+}
+
+// NewAnonymizerService creates a new anonymizer keyed by the given secret
+func NewAnonymizerService(secret string) *AnonymizerService {
+	return &AnonymizerService{
+		secret:      []byte(secret),
+		jitterRatio: defaultJitterRatio,
+	}
+}
+
+// AnonymizeRows returns a copy of the rows with string values pseudonymized
+// and numeric values jittered. Date, time and boolean columns are left as-is.
+func (s *AnonymizerService) AnonymizeRows(columns []models.Column, rows []map[string]interface{}) []map[string]interface{} {
+	columnTypes := make(map[string]string, len(columns))
+	for _, col := range columns {
+		columnTypes[col.Name] = strings.ToLower(col.Type)
+	}
+
+	anonymized := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		newRow := make(map[string]interface{}, len(row))
+		for name, value := range row {
+			newRow[name] = s.anonymizeValue(name, columnTypes[name], value)
+		}
+		anonymized[i] = newRow
+	}
+	return anonymized
+}
+
+// anonymizeValue anonymizes a single cell based on its column type and Go type
+func (s *AnonymizerService) anonymizeValue(column, columnType string, value interface{}) interface{} {
+	switch columnType {
+	case "date", "time", "timestamp", "datetime", "boolean":
+		return value
+	}
+
+	switch v := value.(type) {
+	case nil, bool:
+		return v
+	case string:
+		if v == "" {
+			return v
+		}
+		return s.pseudonym(column, v)
+	case int:
+		return int(math.Round(s.jitter(column, fmt.Sprint(v), float64(v))))
+	case int32:
+		return int32(math.Round(s.jitter(column, fmt.Sprint(v), float64(v))))
+	case int64:
+		return int64(math.Round(s.jitter(column, fmt.Sprint(v), float64(v))))
+	case float32:
+		return float32(s.jitter(column, fmt.Sprint(v), float64(v)))
+	case float64:
+		return s.jitter(column, fmt.Sprint(v), v)
+	default:
+		return value
+	}
+}
+
+// pseudonym replaces a string with a stable token derived from the column and value
+func (s *AnonymizerService) pseudonym(column, value string) string {
+	sum := s.digest(column, value)
+	prefix := column
+	if idx := strings.LastIndex(prefix, "."); idx >= 0 {
+		prefix = prefix[idx+1:]
+	}
+	return fmt.Sprintf("%s_%s", prefix, hex.EncodeToString(sum[:4]))
+}
+
+// jitter scales a number by a deterministic factor in [1-ratio, 1+ratio]
+func (s *AnonymizerService) jitter(column, key string, value float64) float64 {
+	sum := s.digest(column, key)
+	fraction := float64(binary.BigEndian.Uint64(sum[:8])) / float64(math.MaxUint64)
+	factor := 1 + (fraction*2-1)*s.jitterRatio
+	return value * factor
+}
+
+func (s *AnonymizerService) digest(column, value string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(column))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
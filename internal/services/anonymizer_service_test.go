@@ -0,0 +1,51 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestAnonymizerService_AnonymizeRows(t *testing.T) {
+	anonymizer := NewAnonymizerService("test-secret")
+
+	columns := []models.Column{
+		{Name: "customer", Type: "string"},
+		{Name: "amount", Type: "decimal"},
+		{Name: "orders", Type: "integer"},
+		{Name: "date", Type: "date"},
+	}
+	rows := []map[string]interface{}{
+		{"customer": "Acme Corp", "amount": 100.0, "orders": 40, "date": "2024-01-15"},
+		{"customer": "Acme Corp", "amount": 250.0, "orders": 12, "date": "2024-01-16"},
+	}
+
+	result := anonymizer.AnonymizeRows(columns, rows)
+	assert.Len(t, result, 2)
+
+	// Strings are replaced with a stable pseudonym
+	assert.NotEqual(t, "Acme Corp", result[0]["customer"])
+	assert.True(t, strings.HasPrefix(result[0]["customer"].(string), "customer_"))
+	assert.Equal(t, result[0]["customer"], result[1]["customer"])
+
+	// Numbers stay within the jitter range and keep their Go type
+	assert.InDelta(t, 100.0, result[0]["amount"], 100.0*defaultJitterRatio)
+	assert.IsType(t, 0, result[0]["orders"])
+	assert.InDelta(t, 40, result[0]["orders"], 40*defaultJitterRatio+1)
+
+	// Dates are untouched and the input rows are not modified
+	assert.Equal(t, "2024-01-15", result[0]["date"])
+	assert.Equal(t, "Acme Corp", rows[0]["customer"])
+}
+
+func TestAnonymizerService_Deterministic(t *testing.T) {
+	first := NewAnonymizerService("secret-a")
+	second := NewAnonymizerService("secret-a")
+	other := NewAnonymizerService("secret-b")
+
+	assert.Equal(t, first.pseudonym("name", "Jane"), second.pseudonym("name", "Jane"))
+	assert.NotEqual(t, first.pseudonym("name", "Jane"), other.pseudonym("name", "Jane"))
+	assert.Equal(t, first.jitter("amount", "10", 10), second.jitter("amount", "10", 10))
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// auditExportLimit bounds how many entries a single export returns
+const auditExportLimit = 10000
+
+// auditRedacted replaces the value of secret fields in snapshots
+const auditRedacted = "[REDACTED]"
+
+// auditSecretHints are field name fragments whose values are never stored
+var auditSecretHints = []string{
+	"password", "secret", "token", "api_key", "apikey", "private_key", "credentials", "access_key",
+}
+
+// auditOmittedFields are snapshot fields left out as related records rather than state
+var auditOmittedFields = []string{"schemas", "user"}
+
+// auditUnchangedFields are fields that change on every update and are not reported as changes
+var auditUnchangedFields = map[string]bool{"updated_at": true}
+
+// AuditService records sensitive actions in the audit trail and searches and exports it
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Log records an action of the actor on a resource. Before and after are
+// snapshots of the resource (nil when it did not exist before or after) and
+// details holds extra context such as the executed SQL. The action already
+// happened, so a failure to record it is logged rather than returned.
+func (s *AuditService) Log(actor models.AuditActor, action models.AuditAction, resourceType string, resourceID uint, before, after interface{}, details map[string]interface{}) {
+	if s == nil {
+		return
+	}
+
+	entry := models.AuditLog{
+		ActorID:      actor.UserID,
+		ActorEmail:   actor.Email,
+		Action:       action,
+		ResourceType: resourceType,
+		IPAddress:    actor.IPAddress,
+		UserAgent:    actor.UserAgent,
+	}
+	if resourceID != 0 {
+		entry.ResourceID = strconv.FormatUint(uint64(resourceID), 10)
+	}
+
+	beforeSnapshot, err := auditSnapshot(before)
+	if err != nil {
+		logger.L().Error().Err(err).Str("request_id", actor.RequestID).Str("resource_type", resourceType).Uint("resource_id", resourceID).Msg("Failed to snapshot resource for audit")
+	}
+	afterSnapshot, err := auditSnapshot(after)
+	if err != nil {
+		logger.L().Error().Err(err).Str("request_id", actor.RequestID).Str("resource_type", resourceType).Uint("resource_id", resourceID).Msg("Failed to snapshot resource for audit")
+	}
+	entry.Before = auditJSON(beforeSnapshot)
+	entry.After = auditJSON(afterSnapshot)
+	if beforeSnapshot != nil && afterSnapshot != nil {
+		entry.Changes = auditJSON(auditChanges(beforeSnapshot, afterSnapshot))
+	}
+	if len(details) > 0 {
+		redactAudit(details)
+		entry.Details = auditJSON(details)
+	}
+
+	if err := s.db.Create(&entry).Error; err != nil {
+		logger.L().Error().Err(err).Str("request_id", actor.RequestID).Str("action", string(action)).Uint("user_id", actor.UserID).Msg("Failed to record audit log")
+	}
+}
+
+// Search returns a page of entries matching the filter, newest first
+func (s *AuditService) Search(filter models.AuditLogFilter) (*models.AuditLogPage, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	page := &models.AuditLogPage{Logs: []models.AuditLog{}, Limit: filter.Limit, Offset: filter.Offset}
+	query := s.filtered(filter)
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).
+		Find(&page.Logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to search audit logs: %w", err)
+	}
+	return page, nil
+}
+
+// Export returns up to auditExportLimit entries matching the filter, oldest first
+func (s *AuditService) Export(filter models.AuditLogFilter) ([]models.AuditLog, error) {
+	logs := []models.AuditLog{}
+	if err := s.filtered(filter).Order("created_at ASC, id ASC").Limit(auditExportLimit).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to export audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+func (s *AuditService) filtered(filter models.AuditLogFilter) *gorm.DB {
+	query := s.db.Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("details::text ILIKE ? OR before::text ILIKE ? OR after::text ILIKE ? OR changes::text ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+	return query
+}
+
+// auditLogCSVHeader is the header row of the CSV export
+var auditLogCSVHeader = []string{
+	"id", "created_at", "actor_id", "actor_email", "action", "resource_type", "resource_id",
+	"ip_address", "user_agent", "changes", "details",
+}
+
+// WriteAuditLogCSV writes the entries as CSV with one row per entry. Changes
+// and details are written as JSON; snapshots are left to the JSON export.
+func WriteAuditLogCSV(w io.Writer, logs []models.AuditLog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditLogCSVHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range logs {
+		if err := writer.Write([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatUint(uint64(entry.ActorID), 10),
+			entry.ActorEmail,
+			string(entry.Action),
+			entry.ResourceType,
+			entry.ResourceID,
+			entry.IPAddress,
+			entry.UserAgent,
+			string(entry.Changes),
+			string(entry.Details),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// auditSnapshot converts a resource to its JSON fields without related
+// records and with secrets redacted
+func auditSnapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, nil
+	}
+
+	for _, field := range auditOmittedFields {
+		delete(snapshot, field)
+	}
+	redactAudit(snapshot)
+	return snapshot, nil
+}
+
+// redactAudit replaces the values of secret fields at any depth
+func redactAudit(fields map[string]interface{}) {
+	for key, value := range fields {
+		if isAuditSecret(key) {
+			fields[key] = auditRedacted
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactAudit(nested)
+		}
+	}
+}
+
+// isAuditSecret reports whether a field name looks like it holds a secret
+func isAuditSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, hint := range auditSecretHints {
+		if strings.Contains(key, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditChanges returns the fields whose values differ between the snapshots,
+// keyed by dotted path so a changed connection setting shows as e.g. config.host
+func auditChanges(before, after map[string]interface{}) map[string]models.AuditChange {
+	flatBefore := make(map[string]interface{})
+	flatAfter := make(map[string]interface{})
+	flattenAudit("", before, flatBefore)
+	flattenAudit("", after, flatAfter)
+
+	keys := make([]string, 0, len(flatBefore)+len(flatAfter))
+	for key := range flatBefore {
+		keys = append(keys, key)
+	}
+	for key := range flatAfter {
+		if _, ok := flatBefore[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make(map[string]models.AuditChange)
+	for _, key := range keys {
+		if auditUnchangedFields[key] {
+			continue
+		}
+		from, to := flatBefore[key], flatAfter[key]
+		if !reflect.DeepEqual(from, to) {
+			changes[key] = models.AuditChange{From: from, To: to}
+		}
+	}
+	return changes
+}
+
+// flattenAudit collects the leaf values of nested objects under dotted paths
+func flattenAudit(prefix string, fields map[string]interface{}, out map[string]interface{}) {
+	for key, value := range fields {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenAudit(path, nested, out)
+			continue
+		}
+		out[path] = value
+	}
+}
+
+// auditJSON encodes a snapshot, changes or details, or returns nil when empty
+func auditJSON(v interface{}) models.JSON {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Map && rv.Len() == 0) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return models.JSON(data)
+}
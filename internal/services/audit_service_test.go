@@ -0,0 +1,113 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditSnapshotRedactsSecretsAndOmitsRelations(t *testing.T) {
+	dataSource := &models.DataSourceResponse{
+		ID:   3,
+		Name: "Warehouse",
+		Type: models.DataSourceTypePostgreSQL,
+		Config: map[string]interface{}{
+			"host":     "db.internal",
+			"password": "hunter2",
+			"oauth":    map[string]interface{}{"refresh_token": "abc"},
+		},
+		Schemas: []models.SchemaResponse{{Name: "orders"}},
+	}
+
+	snapshot, err := auditSnapshot(dataSource)
+	require.NoError(t, err)
+
+	config := snapshot["config"].(map[string]interface{})
+	assert.Equal(t, "db.internal", config["host"])
+	assert.Equal(t, auditRedacted, config["password"])
+	assert.Equal(t, auditRedacted, config["oauth"].(map[string]interface{})["refresh_token"])
+	assert.NotContains(t, snapshot, "schemas")
+}
+
+func TestAuditSnapshotOfNil(t *testing.T) {
+	snapshot, err := auditSnapshot(nil)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	var user *models.User
+	snapshot, err = auditSnapshot(user)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+func TestAuditChanges(t *testing.T) {
+	before := map[string]interface{}{
+		"name":       "Warehouse",
+		"updated_at": "2025-09-01T00:00:00Z",
+		"config":     map[string]interface{}{"host": "old.internal", "port": float64(5432)},
+	}
+	after := map[string]interface{}{
+		"name":       "Warehouse",
+		"updated_at": "2025-09-02T00:00:00Z",
+		"config":     map[string]interface{}{"host": "new.internal", "port": float64(5432), "sslmode": "require"},
+	}
+
+	changes := auditChanges(before, after)
+	assert.Equal(t, map[string]models.AuditChange{
+		"config.host":    {From: "old.internal", To: "new.internal"},
+		"config.sslmode": {From: nil, To: "require"},
+	}, changes)
+}
+
+func TestIsAuditSecret(t *testing.T) {
+	for _, key := range []string{"password", "DB_PASSWORD", "api_key", "clientSecret", "credentials_json", "access_token"} {
+		assert.True(t, isAuditSecret(key), key)
+	}
+	for _, key := range []string{"host", "username", "dataset_id"} {
+		assert.False(t, isAuditSecret(key), key)
+	}
+}
+
+func TestAuditJSONOmitsEmpty(t *testing.T) {
+	assert.Nil(t, auditJSON(nil))
+	assert.Nil(t, auditJSON(map[string]interface{}{}))
+	assert.Nil(t, auditJSON(map[string]models.AuditChange(nil)))
+	assert.JSONEq(t, `{"sql":"SELECT 1"}`, string(auditJSON(map[string]interface{}{"sql": "SELECT 1"})))
+}
+
+func TestWriteAuditLogCSV(t *testing.T) {
+	logs := []models.AuditLog{{
+		ID:           1,
+		ActorID:      2,
+		ActorEmail:   "admin@narapulse.com",
+		Action:       models.AuditActionQueryExecute,
+		ResourceType: "nl2sql_query",
+		ResourceID:   "9",
+		IPAddress:    "10.0.0.1",
+		Details:      models.JSON(`{"sql":"SELECT 1"}`),
+		CreatedAt:    time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC),
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAuditLogCSV(&buf, logs))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, auditLogCSVHeader, rows[0])
+	assert.Equal(t, []string{"1", "2025-09-01T08:00:00Z", "2", "admin@narapulse.com", "query.execute", "nl2sql_query", "9",
+		"10.0.0.1", "", "", `{"sql":"SELECT 1"}`}, rows[1])
+}
+
+func TestAuditServiceDisabled(t *testing.T) {
+	var s *AuditService
+	assert.NotPanics(t, func() {
+		s.Log(models.AuditActor{UserID: 1}, models.AuditActionDataExport, "audit_log", 0, nil, nil, nil)
+	})
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+// ErrAutocompleteDataSourceNotFound is returned when the user does not own the
+// data source terms are suggested from
+var ErrAutocompleteDataSourceNotFound = errors.New("data source not found or access denied")
+
+// Defaults and bounds of autocomplete
+const (
+	autocompleteLimit         = 10
+	autocompleteMaxLimit      = 50
+	autocompleteFuzzyMinRunes = 3 // Shorter prefixes only match exactly
+)
+
+// autocompleteTypeOrder breaks ties between suggestions of the same score,
+// tables before their columns before business terms
+var autocompleteTypeOrder = map[string]int{
+	models.AutocompleteTypeTable:    0,
+	models.AutocompleteTypeColumn:   1,
+	models.AutocompleteTypeKPI:      2,
+	models.AutocompleteTypeGlossary: 3,
+}
+
+// autocompleteCandidate is a schema term with the names it can be typed as
+type autocompleteCandidate struct {
+	suggestion models.AutocompleteSuggestion
+	aliases    []string // Canonical name first
+}
+
+// Autocomplete suggests the tables and columns of a data source the user owns,
+// and the user's KPIs and glossary terms, whose names start with the prefix
+// or nearly so
+func (s *NL2SQLService) Autocomplete(userID uint, req *models.AutocompleteRequest) ([]models.AutocompleteSuggestion, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = autocompleteLimit
+	}
+	if limit > autocompleteMaxLimit {
+		limit = autocompleteMaxLimit
+	}
+
+	var dataSource models.DataSource
+	if err := s.db.Select("id").Where("id = ? AND user_id = ?", req.DataSourceID, userID).First(&dataSource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutocompleteDataSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to validate data source access: %v", err)
+	}
+
+	var schemas []models.Schema
+	if err := s.db.Where("data_source_id = ? AND is_active = ?", dataSource.ID, true).Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schemas: %v", err)
+	}
+	// KPIs computed on another data source do not apply to this one
+	var kpis []models.KPIDefinition
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).
+		Where("data_source_id IS NULL OR data_source_id = ?", dataSource.ID).
+		Find(&kpis).Error; err != nil {
+		return nil, fmt.Errorf("failed to get KPIs: %v", err)
+	}
+	var glossary []models.BusinessGlossary
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&glossary).Error; err != nil {
+		return nil, fmt.Errorf("failed to get glossary: %v", err)
+	}
+
+	return suggestCompletions(req.Prefix, autocompleteCandidates(schemas, kpis, glossary), limit), nil
+}
+
+// autocompleteCandidates lists the tables, columns, KPIs and glossary terms
+// with their display names and synonyms
+func autocompleteCandidates(schemas []models.Schema, kpis []models.KPIDefinition, glossary []models.BusinessGlossary) []autocompleteCandidate {
+	var candidates []autocompleteCandidate
+	for _, schema := range schemas {
+		candidates = append(candidates, autocompleteCandidate{
+			suggestion: models.AutocompleteSuggestion{Type: models.AutocompleteTypeTable, Name: schema.Name, Description: schema.Description},
+			aliases:    []string{schema.Name, schema.DisplayName},
+		})
+
+		var columns []models.Column
+		if schema.Columns != nil {
+			_ = json.Unmarshal(schema.Columns, &columns)
+		}
+		for _, column := range columns {
+			description := column.CuratedDescription
+			if description == "" {
+				description = column.Description
+			}
+			candidates = append(candidates, autocompleteCandidate{
+				suggestion: models.AutocompleteSuggestion{Type: models.AutocompleteTypeColumn, Name: column.Name, Table: schema.Name, Description: description},
+				aliases:    []string{column.Name, column.DisplayName},
+			})
+		}
+	}
+	for _, kpi := range kpis {
+		candidates = append(candidates, autocompleteCandidate{
+			suggestion: models.AutocompleteSuggestion{Type: models.AutocompleteTypeKPI, Name: kpi.Name, Description: kpi.Description},
+			aliases:    []string{kpi.Name, kpi.DisplayName},
+		})
+	}
+	for _, entry := range glossary {
+		var synonyms []string
+		if entry.Synonyms != nil {
+			_ = json.Unmarshal(entry.Synonyms, &synonyms)
+		}
+		candidates = append(candidates, autocompleteCandidate{
+			suggestion: models.AutocompleteSuggestion{Type: models.AutocompleteTypeGlossary, Name: entry.Term, Description: entry.Definition},
+			aliases:    append([]string{entry.Term}, synonyms...),
+		})
+	}
+	return candidates
+}
+
+// suggestCompletions scores every candidate by its best matching alias and
+// returns the best matches, highest score first
+func suggestCompletions(prefix string, candidates []autocompleteCandidate, limit int) []models.AutocompleteSuggestion {
+	suggestions := []models.AutocompleteSuggestion{}
+	normalizedPrefix := normalizeSchemaTerm(prefix)
+	if normalizedPrefix == "" {
+		return suggestions
+	}
+
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		best := candidate.suggestion
+		for _, alias := range candidate.aliases {
+			if alias == "" {
+				continue
+			}
+			score, match := matchSchemaTerm(normalizedPrefix, normalizeSchemaTerm(alias))
+			if score > best.Score {
+				best.Text, best.Score, best.Match = alias, score, match
+			}
+		}
+		if best.Score == 0 {
+			continue
+		}
+		// The same column may be listed twice, e.g. by two schemas of a table
+		key := best.Type + "\x00" + best.Table + "\x00" + best.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, best)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if autocompleteTypeOrder[a.Type] != autocompleteTypeOrder[b.Type] {
+			return autocompleteTypeOrder[a.Type] < autocompleteTypeOrder[b.Type]
+		}
+		return a.Text < b.Text
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// matchSchemaTerm scores how well a normalized term completes a normalized
+// prefix: a term starting with the prefix scores highest, then a term with a
+// later word starting with it, then a term starting with it give or take a
+// typo or two. Shorter terms score higher within each kind of match, and 0
+// means no match.
+func matchSchemaTerm(prefix, term string) (float64, string) {
+	prefixLen, termLen := len([]rune(prefix)), len([]rune(term))
+	coverage := float64(prefixLen) / float64(max(prefixLen, termLen))
+
+	if strings.HasPrefix(term, prefix) {
+		return 0.8 + 0.2*coverage, models.AutocompleteMatchPrefix
+	}
+	words := strings.Split(term, " ")
+	for i := 1; i < len(words); i++ {
+		if strings.HasPrefix(strings.Join(words[i:], " "), prefix) {
+			return 0.6 + 0.2*coverage, models.AutocompleteMatchWord
+		}
+	}
+
+	if prefixLen < autocompleteFuzzyMinRunes {
+		return 0, ""
+	}
+	// One typo per four characters typed, rounded
+	maxEdits := (prefixLen + 1) / 4
+	if maxEdits == 0 {
+		return 0, ""
+	}
+	best := maxEdits + 1
+	for i := range words {
+		best = min(best, prefixEditDistance(prefix, strings.Join(words[i:], " ")))
+	}
+	if best > maxEdits {
+		return 0, ""
+	}
+	return 0.5 * (1 - float64(best)/float64(prefixLen)) * (0.5 + 0.5*coverage), models.AutocompleteMatchFuzzy
+}
+
+// prefixEditDistance is the smallest Levenshtein distance between prefix and
+// any prefix of term
+func prefixEditDistance(prefix, term string) int {
+	p, t := []rune(prefix), []rune(term)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(p); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if p[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	// The prefix may end anywhere in the term
+	best := previous[0]
+	for _, distance := range previous {
+		best = min(best, distance)
+	}
+	return best
+}
+
+// normalizeSchemaTerm lowercases a name and splits its words on underscores,
+// dashes, dots and camel case, e.g. "orderDate" and "order_date" both become
+// "order date"
+func normalizeSchemaTerm(name string) string {
+	var builder strings.Builder
+	runes := []rune(strings.TrimSpace(name))
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			builder.WriteRune(' ')
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			builder.WriteRune(' ')
+			builder.WriteRune(unicode.ToLower(r))
+		default:
+			builder.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSchemaTerm(t *testing.T) {
+	assert.Equal(t, "order date", normalizeSchemaTerm("orderDate"))
+	assert.Equal(t, "order date", normalizeSchemaTerm(" ORDER_date "))
+	assert.Equal(t, "gross margin pct", normalizeSchemaTerm("gross-margin.pct"))
+}
+
+func TestPrefixEditDistance(t *testing.T) {
+	assert.Equal(t, 0, prefixEditDistance("cust", "customers"))
+	assert.Equal(t, 2, prefixEditDistance("cutsom", "customers"))
+	assert.Equal(t, 1, prefixEditDistance("revnue", "revenue"))
+	assert.Equal(t, 3, prefixEditDistance("abc", "xyz"))
+}
+
+func TestSuggestCompletions(t *testing.T) {
+	candidates := autocompleteCandidates(
+		[]models.Schema{
+			{Name: "orders", DisplayName: "Orders", Columns: models.JSON(`[{"name":"order_date"},{"name":"customer_id","display_name":"Customer"},{"name":"total"}]`)},
+			{Name: "customers", Columns: models.JSON(`[{"name":"name"}]`)},
+		},
+		[]models.KPIDefinition{{Name: "revenue", DisplayName: "Total Revenue"}},
+		[]models.BusinessGlossary{{Term: "revenue", Synonyms: models.JSON(`["turnover","sales"]`)}},
+	)
+
+	suggestions := suggestCompletions("cust", candidates, 10)
+	require.Len(t, suggestions, 2)
+	// The shorter name completes more of what was typed
+	assert.Equal(t, models.AutocompleteTypeColumn, suggestions[0].Type)
+	assert.Equal(t, "Customer", suggestions[0].Text)
+	assert.Equal(t, "customer_id", suggestions[0].Name)
+	assert.Equal(t, "orders", suggestions[0].Table)
+	assert.Equal(t, "customers", suggestions[1].Text)
+	assert.Equal(t, models.AutocompleteMatchPrefix, suggestions[1].Match)
+
+	// A later word of a name matches below whole-name prefixes
+	suggestions = suggestCompletions("rev", candidates, 10)
+	require.Len(t, suggestions, 2)
+	assert.Equal(t, models.AutocompleteTypeKPI, suggestions[0].Type)
+	assert.Equal(t, models.AutocompleteTypeGlossary, suggestions[1].Type)
+	suggestions = suggestCompletions("date", candidates, 10)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, models.AutocompleteMatchWord, suggestions[0].Match)
+
+	// Synonyms complete to the synonym of their term
+	suggestions = suggestCompletions("turn", candidates, 10)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "turnover", suggestions[0].Text)
+	assert.Equal(t, "revenue", suggestions[0].Name)
+
+	// Typos match fuzzily, below exact matches
+	suggestions = suggestCompletions("ordrs", candidates, 10)
+	require.NotEmpty(t, suggestions)
+	assert.Equal(t, "orders", suggestions[0].Name)
+	assert.Equal(t, models.AutocompleteMatchFuzzy, suggestions[0].Match)
+	assert.Less(t, suggestions[0].Score, 0.6)
+
+	// Short prefixes do not match fuzzily
+	assert.Empty(t, suggestCompletions("xo", candidates, 10))
+	assert.Empty(t, suggestCompletions("  ", candidates, 10))
+	assert.Len(t, suggestCompletions("o", candidates, 1), 1)
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"narapulse-be/internal/connectors"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrServiceAccountNotFound is returned for service accounts that do not exist or belong to another user
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	// ErrServiceAccountInvalid is returned when an uploaded key fails validation; the validation lists the issues
+	ErrServiceAccountInvalid = errors.New("service account key failed validation")
+)
+
+// BigQueryServiceAccountService validates and stores the service account keys
+// BigQuery data sources connect with. Keys are encrypted at rest; data sources
+// reference them by service_account_id and carry a copy of the encrypted key.
+type BigQueryServiceAccountService struct {
+	db            *gorm.DB
+	encryptionKey string
+	validate      func(ctx context.Context, credentialsJSON, projectID, datasetID string) *models.BigQueryServiceAccountValidation
+	now           func() time.Time
+}
+
+// NewBigQueryServiceAccountService creates a service account service encrypting keys with encryptionKey
+func NewBigQueryServiceAccountService(db *gorm.DB, encryptionKey string) *BigQueryServiceAccountService {
+	return &BigQueryServiceAccountService{
+		db:            db,
+		encryptionKey: encryptionKey,
+		validate:      connectors.ValidateBigQueryServiceAccount,
+		now:           time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *BigQueryServiceAccountService) WithContext(ctx context.Context) *BigQueryServiceAccountService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Validate checks a key against Google Cloud without storing it
+func (s *BigQueryServiceAccountService) Validate(ctx context.Context, req *models.BigQueryServiceAccountRequest) *models.BigQueryServiceAccountValidation {
+	return s.validate(ctx, req.CredentialsJSON, req.ProjectID, req.DatasetID)
+}
+
+// Create validates a key and stores it encrypted. A key that fails validation
+// is not stored; the response still carries the validation with its issues.
+func (s *BigQueryServiceAccountService) Create(ctx context.Context, userID uint, req *models.BigQueryServiceAccountRequest) (*models.BigQueryServiceAccountResponse, error) {
+	validation := s.Validate(ctx, req)
+	response := &models.BigQueryServiceAccountResponse{Validation: validation}
+	if !validation.Valid {
+		return response, ErrServiceAccountInvalid
+	}
+
+	key, _ := connectors.ParseServiceAccountKey(req.CredentialsJSON)
+	encrypted, err := utils.EncryptSecret(req.CredentialsJSON, s.encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt service account key: %w", err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = key.ClientEmail
+	}
+	validatedAt := s.now()
+	account := &models.BigQueryServiceAccount{
+		UserID:       userID,
+		Name:         name,
+		ProjectID:    validation.ProjectID,
+		ClientEmail:  key.ClientEmail,
+		PrivateKeyID: key.PrivateKeyID,
+		EncryptedKey: encrypted,
+		ValidatedAt:  &validatedAt,
+	}
+	if err := s.db.Create(account).Error; err != nil {
+		return nil, fmt.Errorf("failed to save service account: %w", err)
+	}
+
+	response.Account = account
+	return response, nil
+}
+
+// List returns the service accounts of a user, newest first
+func (s *BigQueryServiceAccountService) List(userID uint) ([]models.BigQueryServiceAccount, error) {
+	var accounts []models.BigQueryServiceAccount
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// Delete removes a stored key. Data sources created with it keep their copy
+// of the encrypted key until their config is updated.
+func (s *BigQueryServiceAccountService) Delete(userID, id uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.BigQueryServiceAccount{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete service account: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+// AttachCredentials copies the encrypted key of the service account a data
+// source config references by service_account_id into the config, and fills
+// project_id from the account when it is not set. Configs without a
+// service_account_id are left as they are.
+func (s *BigQueryServiceAccountService) AttachCredentials(userID uint, config map[string]interface{}) error {
+	id, ok, err := serviceAccountID(config)
+	if err != nil || !ok {
+		return err
+	}
+
+	var account models.BigQueryServiceAccount
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrServiceAccountNotFound
+		}
+		return fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	config["credentials_encrypted"] = account.EncryptedKey
+	if _, ok := config["project_id"]; !ok {
+		config["project_id"] = account.ProjectID
+	}
+	return nil
+}
+
+// serviceAccountID reads service_account_id from a config, which JSON decodes
+// as a number but clients may send as a string
+func serviceAccountID(config map[string]interface{}) (uint, bool, error) {
+	switch value := config["service_account_id"].(type) {
+	case nil:
+		return 0, false, nil
+	case float64:
+		if value > 0 && value == float64(uint(value)) {
+			return uint(value), true, nil
+		}
+	case string:
+		if id, err := strconv.ParseUint(value, 10, 32); err == nil && id > 0 {
+			return uint(id), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("service_account_id must be a positive integer")
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceAccountID(t *testing.T) {
+	id, ok, err := serviceAccountID(map[string]interface{}{"service_account_id": float64(7)})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint(7), id)
+
+	id, ok, err = serviceAccountID(map[string]interface{}{"service_account_id": "12"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint(12), id)
+
+	_, ok, err = serviceAccountID(map[string]interface{}{"credentials_json": "{}"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, value := range []interface{}{float64(0), float64(1.5), "abc", true} {
+		_, _, err = serviceAccountID(map[string]interface{}{"service_account_id": value})
+		assert.Error(t, err, "%v", value)
+	}
+}
@@ -1,15 +1,62 @@
 package services
 
 import (
-	"log"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/gorm-adapter/v3"
 	"gorm.io/gorm"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
 )
 
+// casbinModelPath is the RBAC model: subjects are user emails or roles, objects
+// are request paths matched with keyMatch (a trailing * matches any suffix) and
+// actions are HTTP methods, where * allows every method
+const casbinModelPath = "configs/rbac_model.conf"
+
+// defaultCasbinPolicies are the route policies a new installation starts with.
+// Admins may call every route; users every route but the admin ones.
+var defaultCasbinPolicies = [][]string{
+	{"admin", "/api/v1/*", "*"},
+	{"user", "/api/v1/profile", "*"},
+	{"user", "/api/v1/data-sources*", "*"},
+	{"user", "/api/v1/data-source-templates*", "*"},
+	{"user", "/api/v1/config/data-sources", "PUT"},
+	{"user", "/api/v1/nl2sql*", "*"},
+	{"user", "/api/v1/rag/search", "*"},
+	{"user", "/api/v1/rag/nl2sql-*", "*"},
+	{"user", "/api/v1/rag/schemas/*", "*"},
+	{"user", "/api/v1/rag/sync/*", "*"},
+	{"user", "/api/v1/rag/kpi", "*"},
+	{"user", "/api/v1/rag/glossary", "*"},
+	{"user", "/api/v1/rag/embeddings/*", "*"},
+	{"user", "/api/v1/rag/similar-questions", "GET"},
+	{"user", "/api/v1/schema-sync*", "*"},
+	{"user", "/api/v1/usage*", "GET"},
+	{"user", "/api/v1/analytics/queries", "GET"},
+	{"user", "/api/v1/data-apis*", "*"},
+	{"user", "/api/v1/dashboards*", "*"},
+	{"user", "/api/v1/graphql*", "*"},
+	{"user", "/api/v1/notifications*", "GET"},
+	{"user", "/api/v1/tenant", "GET"},
+	{"user", "/api/v1/feature-flags", "GET"},
+	{"user", "/api/v1/digest*", "*"},
+	{"user", "/api/v1/shares*", "*"},
+	{"user", "/api/v1/embed/tokens", "POST"},
+	{"user", "/api/v1/kpis*", "*"},
+	{"user", "/api/v1/reports*", "*"},
+	{"user", "/api/v1/integrations*", "*"},
+	{"user", "/api/v1/alerts*", "*"},
+	{"user", "/api/v1/analysis*", "*"},
+	{"user", "/api/v1/extracts*", "*"},
+}
+
+// CasbinService authorizes requests against route policies stored in the
+// database. Policy and role changes made through it apply immediately; changes
+// made by other instances apply once the policy is reloaded.
 type CasbinService struct {
-	enforcer *casbin.Enforcer
+	enforcer *casbin.SyncedEnforcer
 }
 
 func NewCasbinService(db *gorm.DB) (*CasbinService, error) {
@@ -19,8 +66,8 @@ func NewCasbinService(db *gorm.DB) (*CasbinService, error) {
 		return nil, err
 	}
 
-	// Initialize the enforcer
-	enforcer, err := casbin.NewEnforcer("configs/rbac_model.conf", adapter)
+	// Initialize the enforcer; it is shared by all requests, so it must be synchronized
+	enforcer, err := casbin.NewSyncedEnforcer(casbinModelPath, adapter)
 	if err != nil {
 		return nil, err
 	}
@@ -43,20 +90,17 @@ func NewCasbinService(db *gorm.DB) (*CasbinService, error) {
 		}
 	}
 
-	log.Println("Casbin enforcer initialized successfully")
-	return &CasbinService{enforcer: enforcer}, nil
+	logger.L().Info().Msg("Casbin enforcer initialized successfully")
+	return newCasbinService(enforcer), nil
 }
 
-func loadInitialPolicies(enforcer *casbin.Enforcer) error {
-	// Add role-based policies
-	policies := [][]string{
-		{"admin", "/api/v1/admin/*", "*"},
-		{"admin", "/api/v1/profile", "*"},
-		{"user", "/api/v1/profile", "GET"},
-		{"user", "/api/v1/profile", "PUT"},
-	}
+func newCasbinService(enforcer *casbin.SyncedEnforcer) *CasbinService {
+	return &CasbinService{enforcer: enforcer}
+}
 
-	for _, policy := range policies {
+func loadInitialPolicies(enforcer *casbin.SyncedEnforcer) error {
+	// Add role-based policies
+	for _, policy := range defaultCasbinPolicies {
 		_, err := enforcer.AddPolicy(policy)
 		if err != nil {
 			return err
@@ -79,6 +123,15 @@ func loadInitialPolicies(enforcer *casbin.Enforcer) error {
 	return enforcer.SavePolicy()
 }
 
+// StartPolicyReload reloads the policies from the database every interval, so
+// changes made through other instances take effect without a restart
+func (cs *CasbinService) StartPolicyReload(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	cs.enforcer.StartAutoLoadPolicy(interval)
+}
+
 // Enforce checks if a user has permission to access a resource
 func (cs *CasbinService) Enforce(user, resource, action string) (bool, error) {
 	return cs.enforcer.Enforce(user, resource, action)
@@ -94,6 +147,23 @@ func (cs *CasbinService) RemovePolicy(role, resource, action string) (bool, erro
 	return cs.enforcer.RemovePolicy(role, resource, action)
 }
 
+// GetPolicies lists all route policies
+func (cs *CasbinService) GetPolicies() ([]models.AccessPolicy, error) {
+	rules, err := cs.enforcer.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]models.AccessPolicy, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) < 3 {
+			continue
+		}
+		policies = append(policies, models.AccessPolicy{Role: rule[0], Path: rule[1], Method: rule[2]})
+	}
+	return policies, nil
+}
+
 // AddRoleForUser assigns a role to a user
 func (cs *CasbinService) AddRoleForUser(user, role string) (bool, error) {
 	return cs.enforcer.AddRoleForUser(user, role)
@@ -104,6 +174,23 @@ func (cs *CasbinService) DeleteRoleForUser(user, role string) (bool, error) {
 	return cs.enforcer.DeleteRoleForUser(user, role)
 }
 
+// GetRoleAssignments lists all roles assigned to users
+func (cs *CasbinService) GetRoleAssignments() ([]models.RoleAssignment, error) {
+	rules, err := cs.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]models.RoleAssignment, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) < 2 {
+			continue
+		}
+		assignments = append(assignments, models.RoleAssignment{User: rule[0], Role: rule[1]})
+	}
+	return assignments, nil
+}
+
 // GetRolesForUser gets all roles for a user
 func (cs *CasbinService) GetRolesForUser(user string) ([]string, error) {
 	roles, err := cs.enforcer.GetRolesForUser(user)
@@ -114,4 +201,4 @@ func (cs *CasbinService) GetRolesForUser(user string) ([]string, error) {
 func (cs *CasbinService) GetUsersForRole(role string) ([]string, error) {
 	users, err := cs.enforcer.GetUsersForRole(role)
 	return users, err
-}
\ No newline at end of file
+}
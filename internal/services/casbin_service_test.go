@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCasbinModelPath = "../../" + casbinModelPath
+
+func newTestCasbinService(t *testing.T) *CasbinService {
+	t.Helper()
+
+	enforcer, err := casbin.NewSyncedEnforcer(testCasbinModelPath)
+	require.NoError(t, err)
+	for _, policy := range defaultCasbinPolicies {
+		_, err := enforcer.AddPolicy(policy)
+		require.NoError(t, err)
+	}
+	return newCasbinService(enforcer)
+}
+
+func assertAllowed(t *testing.T, s *CasbinService, sub, obj, act string, want bool) {
+	t.Helper()
+
+	allowed, err := s.Enforce(sub, obj, act)
+	require.NoError(t, err)
+	assert.Equal(t, want, allowed, "%s %s %s", sub, act, obj)
+}
+
+func TestDefaultCasbinPolicies(t *testing.T) {
+	s := newTestCasbinService(t)
+
+	assertAllowed(t, s, "user", "/api/v1/profile", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/data-sources", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/data-sources/3/health", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/data-source-templates/2/data-sources", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/admin/data-source-templates", "POST", false)
+	assertAllowed(t, s, "user", "/api/v1/config/data-sources", "PUT", true)
+	assertAllowed(t, s, "user", "/api/v1/nl2sql/queries/5/versions", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/rag/search", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/rag/similar-questions", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/rag/question-topics", "GET", false)
+	assertAllowed(t, s, "user", "/api/v1/usage/quota", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/shares/7", "DELETE", true)
+	assertAllowed(t, s, "user", "/api/v1/embed/tokens", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/graphql/schema", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/notifications/live", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/tenant", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/admin/tenants", "GET", false)
+	assertAllowed(t, s, "user", "/api/v1/feature-flags", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/admin/feature-flags", "GET", false)
+	assertAllowed(t, s, "user", "/api/v1/kpis/3/compute", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/reports/4/runs/9/download", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/integrations/2/share", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/alerts/5/check", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/admin/integrations", "POST", false)
+	assertAllowed(t, s, "user", "/api/v1/analysis/diff", "POST", true)
+	assertAllowed(t, s, "user", "/api/v1/extracts/6/data", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/usage", "DELETE", false)
+	assertAllowed(t, s, "user", "/api/v1/rag/embed-all", "POST", false)
+	assertAllowed(t, s, "user", "/api/v1/admin/users", "GET", false)
+
+	assertAllowed(t, s, "admin", "/api/v1/admin/users/4/role", "PUT", true)
+	assertAllowed(t, s, "admin", "/api/v1/rag/embed-all", "POST", true)
+	assertAllowed(t, s, "admin", "/api/v1/data-sources", "GET", true)
+
+	// PII unmasking is never granted by route policies
+	assertAllowed(t, s, "admin", "pii", "unmask", false)
+	_, err := s.AddPolicy("analyst@narapulse.com", "pii", "unmask")
+	require.NoError(t, err)
+	assertAllowed(t, s, "analyst@narapulse.com", "pii", "unmask", true)
+}
+
+func TestDefaultCasbinPoliciesMatchPolicyFile(t *testing.T) {
+	fromFile, err := casbin.NewSyncedEnforcer(testCasbinModelPath, "../../configs/rbac_policy.csv")
+	require.NoError(t, err)
+
+	policies, err := fromFile.GetPolicy()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, defaultCasbinPolicies, policies)
+}
+
+func TestCasbinPolicyChangesApplyImmediately(t *testing.T) {
+	s := newTestCasbinService(t)
+	assertAllowed(t, s, "user", "/api/v1/admin/usage", "GET", false)
+
+	added, err := s.AddPolicy("user", "/api/v1/admin/usage", "GET")
+	require.NoError(t, err)
+	assert.True(t, added)
+	assertAllowed(t, s, "user", "/api/v1/admin/usage", "GET", true)
+	assertAllowed(t, s, "user", "/api/v1/admin/usage", "POST", false)
+
+	added, err = s.AddPolicy("user", "/api/v1/admin/usage", "GET")
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	removed, err := s.RemovePolicy("user", "/api/v1/data-sources*", "*")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assertAllowed(t, s, "user", "/api/v1/data-sources/3", "GET", false)
+
+	policies, err := s.GetPolicies()
+	require.NoError(t, err)
+	assert.Len(t, policies, len(defaultCasbinPolicies))
+}
+
+func TestCasbinRoleAssignmentsApplyImmediately(t *testing.T) {
+	s := newTestCasbinService(t)
+	assertAllowed(t, s, "analyst@narapulse.com", "/api/v1/admin/audit-logs", "GET", false)
+
+	added, err := s.AddRoleForUser("analyst@narapulse.com", "admin")
+	require.NoError(t, err)
+	assert.True(t, added)
+	assertAllowed(t, s, "analyst@narapulse.com", "/api/v1/admin/audit-logs", "GET", true)
+
+	assignments, err := s.GetRoleAssignments()
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "analyst@narapulse.com", assignments[0].User)
+	assert.Equal(t, "admin", assignments[0].Role)
+
+	removed, err := s.DeleteRoleForUser("analyst@narapulse.com", "admin")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assertAllowed(t, s, "analyst@narapulse.com", "/api/v1/admin/audit-logs", "GET", false)
+}
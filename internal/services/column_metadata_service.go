@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrColumnNotFound is returned when curating a column the data source does not have
+var ErrColumnNotFound = errors.New("column not found")
+
+// ColumnMetadataService manages the curated display names, descriptions,
+// semantic tags and PII flags of data source columns
+type ColumnMetadataService struct {
+	db         *gorm.DB
+	jobs       *JobService
+	embeddings *EmbeddingService
+}
+
+// NewColumnMetadataService creates a new column metadata service and
+// registers the job re-embedding curated columns
+func NewColumnMetadataService(db *gorm.DB, jobs *JobService, embeddings *EmbeddingService) *ColumnMetadataService {
+	s := &ColumnMetadataService{
+		db:         db,
+		jobs:       jobs,
+		embeddings: embeddings,
+	}
+	jobs.Register(models.JobTypeSchemaReembed, s.runReembedJob)
+	return s
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ColumnMetadataService) WithContext(ctx context.Context) *ColumnMetadataService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.embeddings = s.embeddings.WithContext(ctx)
+	return &scoped
+}
+
+// List returns the curated metadata of the columns of a data source
+func (s *ColumnMetadataService) List(dataSourceID uint) ([]models.ColumnMetadataResponse, error) {
+	var metadata []models.ColumnMetadata
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("table_name, column_name").Find(&metadata).Error; err != nil {
+		return nil, fmt.Errorf("failed to get column metadata: %w", err)
+	}
+
+	responses := make([]models.ColumnMetadataResponse, 0, len(metadata))
+	for i := range metadata {
+		responses = append(responses, newColumnMetadataResponse(&metadata[i]))
+	}
+	return responses, nil
+}
+
+// UpdateColumn replaces the curated metadata of a column, applies it to the
+// current schema and queues the re-embedding of the column and its table
+func (s *ColumnMetadataService) UpdateColumn(ctx context.Context, dataSourceID uint, table, column string, userID uint, req *models.ColumnMetadataRequest) (*models.ColumnMetadataResponse, error) {
+	var schema models.Schema
+	if err := s.db.Where("data_source_id = ? AND name = ?", dataSourceID, table).First(&schema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s.%s", ErrColumnNotFound, table, column)
+		}
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	var columns []models.Column
+	if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+		return nil, fmt.Errorf("failed to parse columns: %w", err)
+	}
+	found := false
+	for _, col := range columns {
+		if col.Name == column {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s.%s", ErrColumnNotFound, table, column)
+	}
+
+	tags := normalizeTags(req.Tags)
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	// dbt tests are not curated here and stay on the column
+	var existing models.ColumnMetadata
+	if err := s.db.Select("tests").Where("data_source_id = ? AND table_name = ? AND column_name = ?", dataSourceID, table, column).
+		Limit(1).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get column metadata: %w", err)
+	}
+	metadata := &models.ColumnMetadata{
+		DataSourceID: dataSourceID,
+		Table:        table,
+		Column:       column,
+		DisplayName:  strings.TrimSpace(req.DisplayName),
+		Description:  strings.TrimSpace(req.Description),
+		Tags:         models.JSON(tagsJSON),
+		PII:          req.PII,
+		Unit:         strings.TrimSpace(req.Unit),
+		Currency:     strings.ToUpper(req.Currency),
+		Tests:        existing.Tests,
+		Source:       models.ColumnMetadataSourceUser,
+		UpdatedBy:    userID,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "data_source_id"}, {Name: "table_name"}, {Name: "column_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"display_name", "description", "tags", "pii", "unit", "currency", "source", "updated_by", "updated_at"}),
+		}).Create(metadata).Error; err != nil {
+			return fmt.Errorf("failed to save column metadata: %w", err)
+		}
+
+		applyColumnMetadata(columns, table, map[string]models.ColumnMetadata{columnMetadataKey(table, column): *metadata})
+		columnsJSON, err := json.Marshal(columns)
+		if err != nil {
+			return fmt.Errorf("failed to marshal columns: %w", err)
+		}
+		if err := tx.Model(&schema).Update("columns", models.JSON(columnsJSON)).Error; err != nil {
+			return fmt.Errorf("failed to update schema: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := models.SchemaReembedJobPayload{DataSourceID: dataSourceID, Table: table, Columns: []string{column}}
+	if _, err := s.jobs.Enqueue(ctx, models.JobTypeSchemaReembed, payload); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSourceID).Str("table", table).Msg("Failed to queue column re-embedding")
+	}
+
+	response := newColumnMetadataResponse(metadata)
+	return &response, nil
+}
+
+// ForDataSource returns the curated metadata of a data source by table and
+// column, to apply to discovered columns
+func (s *ColumnMetadataService) ForDataSource(dataSourceID uint) (map[string]models.ColumnMetadata, error) {
+	var metadata []models.ColumnMetadata
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Find(&metadata).Error; err != nil {
+		return nil, fmt.Errorf("failed to get column metadata: %w", err)
+	}
+
+	byColumn := make(map[string]models.ColumnMetadata, len(metadata))
+	for _, m := range metadata {
+		byColumn[columnMetadataKey(m.Table, m.Column)] = m
+	}
+	return byColumn, nil
+}
+
+// runReembedJob re-embeds a table and its curated columns
+func (s *ColumnMetadataService) runReembedJob(ctx context.Context, payload json.RawMessage) error {
+	var p models.SchemaReembedJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	var schema models.Schema
+	if err := s.db.Where("data_source_id = ? AND name = ?", p.DataSourceID, p.Table).First(&schema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Rediscovered or deleted since the job was queued; a schema sync embeds it again
+			return nil
+		}
+		return fmt.Errorf("failed to get schema: %w", err)
+	}
+	return s.embeddings.ReembedColumns(ctx, &schema, p.Columns)
+}
+
+func newColumnMetadataResponse(metadata *models.ColumnMetadata) models.ColumnMetadataResponse {
+	tags := []string{}
+	if len(metadata.Tags) > 0 {
+		_ = json.Unmarshal(metadata.Tags, &tags)
+	}
+	var tests []string
+	if len(metadata.Tests) > 0 {
+		_ = json.Unmarshal(metadata.Tests, &tests)
+	}
+	return models.ColumnMetadataResponse{
+		DataSourceID: metadata.DataSourceID,
+		Table:        metadata.Table,
+		Column:       metadata.Column,
+		DisplayName:  metadata.DisplayName,
+		Description:  metadata.Description,
+		Tags:         tags,
+		PII:          metadata.PII,
+		Unit:         metadata.Unit,
+		Currency:     metadata.Currency,
+		Tests:        tests,
+		Source:       metadata.Source,
+		UpdatedBy:    metadata.UpdatedBy,
+		UpdatedAt:    metadata.UpdatedAt,
+	}
+}
+
+func columnMetadataKey(table, column string) string {
+	return table + "\x00" + column
+}
+
+// applyColumnMetadata copies the curated metadata of a table onto its columns
+func applyColumnMetadata(columns []models.Column, table string, metadata map[string]models.ColumnMetadata) {
+	for i := range columns {
+		m, ok := metadata[columnMetadataKey(table, columns[i].Name)]
+		if !ok {
+			continue
+		}
+		var tags, tests []string
+		if len(m.Tags) > 0 {
+			_ = json.Unmarshal(m.Tags, &tags)
+		}
+		if len(m.Tests) > 0 {
+			_ = json.Unmarshal(m.Tests, &tests)
+		}
+		columns[i].DisplayName = m.DisplayName
+		columns[i].CuratedDescription = m.Description
+		columns[i].Tags = tags
+		columns[i].PII = m.PII
+		columns[i].Unit = m.Unit
+		columns[i].Currency = m.Currency
+		columns[i].Tests = tests
+	}
+}
+
+// applyTableMetadata copies the curated description of a table, kept in the
+// metadata row without a column name, onto the discovered table
+func applyTableMetadata(table *SchemaInfo, metadata map[string]models.ColumnMetadata) {
+	if m, ok := metadata[columnMetadataKey(table.Name, "")]; ok && m.Description != "" {
+		table.Description = m.Description
+	}
+}
+
+// normalizeTags lower-cases and trims tags, dropping empty and repeated ones
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyColumnMetadata(t *testing.T) {
+	columns := []models.Column{
+		{Name: "amount", Type: "numeric", Description: "Discovered comment"},
+		{Name: "email", Type: "text"},
+	}
+	metadata := map[string]models.ColumnMetadata{
+		columnMetadataKey("orders", "amount"): {
+			DisplayName: "Order amount",
+			Description: "Gross order value in USD",
+			Tags:        models.JSON(`["currency"]`),
+		},
+		columnMetadataKey("customers", "email"): {PII: true},
+	}
+
+	applyColumnMetadata(columns, "orders", metadata)
+
+	assert.Equal(t, "Order amount", columns[0].DisplayName)
+	assert.Equal(t, []string{"currency"}, columns[0].Tags)
+	assert.Equal(t, "Gross order value in USD", columns[0].PreferredDescription())
+	assert.Equal(t, "Discovered comment", columns[0].Description)
+	// Metadata of another table does not apply
+	assert.False(t, columns[1].PII)
+	assert.Equal(t, "", columns[1].PreferredDescription())
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"currency", "revenue"}, normalizeTags([]string{" Currency", "revenue", "", "currency"}))
+	assert.Equal(t, []string{}, normalizeTags(nil))
+}
+
+func TestBuildColumnContentCurated(t *testing.T) {
+	s := &EmbeddingService{}
+	content := s.buildColumnContent("orders", models.Column{
+		Name:               "amount",
+		Type:               "numeric",
+		Nullable:           true,
+		Description:        "Discovered comment",
+		DisplayName:        "Order amount",
+		CuratedDescription: "Gross order value in USD",
+		Tags:               []string{"currency", "revenue"},
+		PII:                true,
+	})
+
+	assert.Contains(t, content, "Column: orders.amount (Order amount)")
+	assert.Contains(t, content, "Description: Gross order value in USD")
+	assert.NotContains(t, content, "Discovered comment")
+	assert.Contains(t, content, "Tags: currency, revenue")
+	assert.Contains(t, content, "PII: true")
+}
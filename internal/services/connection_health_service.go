@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// healthLogRetention is how long connection health checks are kept
+	healthLogRetention = 30 * 24 * time.Hour
+	// recentHealthChecks is the number of checks returned with the health of a data source
+	recentHealthChecks = 20
+)
+
+// healthWindows are the periods uptime is reported for
+var healthWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// ErrHealthDataSourceNotFound is returned when the data source does not exist or belongs to another user
+var ErrHealthDataSourceNotFound = errors.New("data source not found")
+
+// ConnectionHealthService periodically tests the connection of every data
+// source, keeps its status current and records the history of the checks
+type ConnectionHealthService struct {
+	db           *gorm.DB
+	connectorSvc *connectorService
+	webhooks     *WebhookService
+}
+
+// NewConnectionHealthService creates a new connection health service
+func NewConnectionHealthService(db *gorm.DB, connectorSvc *connectorService, webhooks *WebhookService) *ConnectionHealthService {
+	return &ConnectionHealthService{
+		db:           db,
+		connectorSvc: connectorSvc,
+		webhooks:     webhooks,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ConnectionHealthService) WithContext(ctx context.Context) *ConnectionHealthService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.webhooks = s.webhooks.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the job that checks every data source; it is
+// scheduled at the health check interval
+func (s *ConnectionHealthService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeHealthCheck, func(ctx context.Context, _ json.RawMessage) error {
+		checked, failed, err := s.CheckAll(ctx)
+		if err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info().Int("checked", checked).Int("failing", failed).Msg("Connection health check completed")
+		return nil
+	})
+}
+
+// CheckAll tests the data sources that are active or in error, so failing
+// connections are noticed and recovered ones return to active, and prunes
+// checks older than the retention
+func (s *ConnectionHealthService) CheckAll(ctx context.Context) (int, int, error) {
+	var dataSources []models.DataSource
+	if err := s.db.Where("status IN ?", []models.ConnectionStatus{models.ConnectionStatusActive, models.ConnectionStatusError}).
+		Order("id").Find(&dataSources).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to list data sources: %w", err)
+	}
+
+	checked, failed := 0, 0
+	for i := range dataSources {
+		if ctx.Err() != nil {
+			break
+		}
+		// The check runs in the tenant of the data source, like the events it publishes
+		entry, err := s.WithContext(tenancy.WithTenant(ctx, dataSources[i].TenantID)).Check(&dataSources[i])
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSources[i].ID).Msg("Failed to record health check")
+			continue
+		}
+		checked++
+		if entry.Status != models.ConnectionStatusActive {
+			failed++
+		}
+	}
+
+	if err := s.db.Where("checked_at < ?", time.Now().Add(-healthLogRetention)).Delete(&models.ConnectionHealthLog{}).Error; err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to prune connection health logs")
+	}
+
+	return checked, failed, nil
+}
+
+// Check tests the connection of a data source, updates its status and records the check
+func (s *ConnectionHealthService) Check(dataSource *models.DataSource) (*models.ConnectionHealthLog, error) {
+	started := time.Now()
+	err := s.testConnection(dataSource)
+	entry := &models.ConnectionHealthLog{
+		TenantID:     dataSource.TenantID,
+		DataSourceID: dataSource.ID,
+		Status:       models.ConnectionStatusActive,
+		LatencyMs:    time.Since(started).Milliseconds(),
+		CheckedAt:    started,
+	}
+	if err != nil {
+		entry.Status = models.ConnectionStatusError
+		entry.ErrorMsg = fmt.Sprintf("Connection failed: %v", err)
+	}
+
+	if err := s.db.Model(&models.DataSource{}).Where("id = ?", dataSource.ID).Updates(map[string]interface{}{
+		"status":        entry.Status,
+		"error_message": entry.ErrorMsg,
+		"last_tested":   started,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update data source status: %w", err)
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to create health log: %w", err)
+	}
+
+	// Only the first failing check is an event; the next ones are still failing
+	if entry.Status == models.ConnectionStatusError && dataSource.Status != models.ConnectionStatusError {
+		s.webhooks.Publish(s.db.Statement.Context, models.WebhookEventDataSourceError, models.DataSourceErrorEvent{
+			DataSourceID: dataSource.ID,
+			UserID:       dataSource.UserID,
+			Name:         dataSource.Name,
+			Type:         string(dataSource.Type),
+			Error:        entry.ErrorMsg,
+			CheckedAt:    started,
+		})
+	}
+	return entry, nil
+}
+
+// GetHealth returns the status, uptime and recent checks of a data source owned by the user
+func (s *ConnectionHealthService) GetHealth(userID, dataSourceID uint) (*models.DataSourceHealthResponse, error) {
+	var dataSource models.DataSource
+	if err := s.db.Where("id = ? AND user_id = ?", dataSourceID, userID).First(&dataSource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHealthDataSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+
+	now := time.Now()
+	logs := []models.ConnectionHealthLog{}
+	if err := s.db.Where("data_source_id = ? AND checked_at >= ?", dataSource.ID, now.Add(-healthLogRetention)).
+		Order("checked_at DESC").Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get health logs: %w", err)
+	}
+
+	response := &models.DataSourceHealthResponse{
+		DataSourceID:        dataSource.ID,
+		Status:              dataSource.Status,
+		ErrorMsg:            dataSource.ErrorMsg,
+		LastTested:          dataSource.LastTested,
+		ConsecutiveFailures: consecutiveFailures(logs),
+		RecentChecks:        logs,
+	}
+	for _, window := range healthWindows {
+		response.Uptime = append(response.Uptime, summarizeUptime(window.name, logs, now.Add(-window.duration)))
+	}
+	if len(response.RecentChecks) > recentHealthChecks {
+		response.RecentChecks = response.RecentChecks[:recentHealthChecks]
+	}
+	return response, nil
+}
+
+// testConnection runs the connector test with the stored configuration
+func (s *ConnectionHealthService) testConnection(dataSource *models.DataSource) error {
+	var config map[string]interface{}
+	if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+	return s.connectorSvc.TestDataSourceConnection(dataSource.ID, models.TestConnectionRequest{
+		Type:   dataSource.Type,
+		Config: config,
+	})
+}
+
+// summarizeUptime computes the share of successful checks since the given time
+func summarizeUptime(window string, logs []models.ConnectionHealthLog, since time.Time) models.ConnectionUptime {
+	uptime := models.ConnectionUptime{Window: window}
+	var latency int64
+	for _, entry := range logs {
+		if entry.CheckedAt.Before(since) {
+			continue
+		}
+		uptime.Checks++
+		latency += entry.LatencyMs
+		if entry.Status == models.ConnectionStatusActive {
+			uptime.SuccessfulChecks++
+		}
+	}
+	if uptime.Checks > 0 {
+		percent := float64(uptime.SuccessfulChecks) / float64(uptime.Checks) * 100
+		uptime.UptimePercent = &percent
+		uptime.AvgLatencyMs = float64(latency) / float64(uptime.Checks)
+	}
+	return uptime
+}
+
+// consecutiveFailures counts the failed checks since the last success; logs are newest first
+func consecutiveFailures(logs []models.ConnectionHealthLog) int {
+	failures := 0
+	for _, entry := range logs {
+		if entry.Status == models.ConnectionStatusActive {
+			break
+		}
+		failures++
+	}
+	return failures
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthLog(status models.ConnectionStatus, latency int64, checkedAt time.Time) models.ConnectionHealthLog {
+	return models.ConnectionHealthLog{Status: status, LatencyMs: latency, CheckedAt: checkedAt}
+}
+
+func TestSummarizeUptime(t *testing.T) {
+	now := time.Now()
+	logs := []models.ConnectionHealthLog{
+		healthLog(models.ConnectionStatusError, 300, now.Add(-time.Hour)),
+		healthLog(models.ConnectionStatusActive, 100, now.Add(-2*time.Hour)),
+		healthLog(models.ConnectionStatusActive, 200, now.Add(-3*time.Hour)),
+		healthLog(models.ConnectionStatusActive, 400, now.Add(-48*time.Hour)),
+	}
+
+	day := summarizeUptime("24h", logs, now.Add(-24*time.Hour))
+	assert.Equal(t, 3, day.Checks)
+	assert.Equal(t, 2, day.SuccessfulChecks)
+	require.NotNil(t, day.UptimePercent)
+	assert.InDelta(t, 66.67, *day.UptimePercent, 0.01)
+	assert.InDelta(t, 200, day.AvgLatencyMs, 0.001)
+
+	week := summarizeUptime("7d", logs, now.Add(-7*24*time.Hour))
+	assert.Equal(t, 4, week.Checks)
+	assert.InDelta(t, 75, *week.UptimePercent, 0.001)
+}
+
+func TestSummarizeUptimeWithoutChecks(t *testing.T) {
+	uptime := summarizeUptime("24h", nil, time.Now().Add(-24*time.Hour))
+	assert.Equal(t, 0, uptime.Checks)
+	assert.Nil(t, uptime.UptimePercent)
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	now := time.Now()
+	logs := []models.ConnectionHealthLog{
+		healthLog(models.ConnectionStatusError, 0, now),
+		healthLog(models.ConnectionStatusError, 0, now.Add(-time.Minute)),
+		healthLog(models.ConnectionStatusActive, 0, now.Add(-2*time.Minute)),
+		healthLog(models.ConnectionStatusError, 0, now.Add(-3*time.Minute)),
+	}
+	assert.Equal(t, 2, consecutiveFailures(logs))
+	assert.Equal(t, 0, consecutiveFailures(logs[2:]))
+	assert.Equal(t, 0, consecutiveFailures(nil))
+}
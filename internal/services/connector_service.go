@@ -2,26 +2,84 @@ package services
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	models "narapulse-be/internal/models/entity"
 	"narapulse-be/internal/connectors"
+	"narapulse-be/internal/pkg/logger"
 	"github.com/xuri/excelize/v2"
 )
 
+// sampleRowLimit is the number of rows stored as sample data per table
+const sampleRowLimit = 5
+
+const (
+	// excelInferenceRowLimit is the number of rows of an Excel sheet used to infer column types
+	excelInferenceRowLimit = 1000
+	// excelStreamXMLLimit is the size above which worksheets are unzipped to disk instead of memory
+	excelStreamXMLLimit = 4 * 1024 * 1024
+)
+
 // connectorService implements connector functionality
-type connectorService struct{}
+type connectorService struct {
+	pgPool *connectors.PostgreSQLPool // Shared PostgreSQL connections per data source; nil connects on every call
+}
+
+// rowCounter is implemented by connectors that can report table row counts
+type rowCounter interface {
+	GetRowCount(tableName string) (int64, error)
+}
+
+// sqlQueryExecutor is implemented by connectors that run SQL queries
+type sqlQueryExecutor interface {
+	ExecuteQuery(query string, limit int) ([]models.Column, []map[string]interface{}, error)
+}
+
+// columnProfiler is implemented by connectors that can compute column statistics
+type columnProfiler interface {
+	ProfileColumns(tableName string, columns []models.Column) error
+}
 
 // NewConnectorService creates a new connector service
 func NewConnectorService() *connectorService {
 	return &connectorService{}
 }
 
+// NewPooledConnectorService creates a connector service that reuses PostgreSQL
+// connections of saved data sources through the pool
+func NewPooledConnectorService(pgPool *connectors.PostgreSQLPool) *connectorService {
+	return &connectorService{pgPool: pgPool}
+}
+
+// TestDataSourceConnection tests the connection of a saved data source. PostgreSQL
+// data sources ping their pooled connection.
+func (s *connectorService) TestDataSourceConnection(dataSourceID uint, request models.TestConnectionRequest) error {
+	if s.pgPool == nil || request.Type != models.DataSourceTypePostgreSQL {
+		return s.TestConnection(request)
+	}
+
+	db, err := s.pgPool.Get(dataSourceID, request.Config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	return db.Ping()
+}
+
+// InvalidateConnection drops the pooled connections of a data source, after its
+// configuration changed or it was deleted
+func (s *connectorService) InvalidateConnection(dataSourceID uint) {
+	if s.pgPool != nil {
+		s.pgPool.Invalidate(dataSourceID)
+	}
+}
+
 // TestConnection tests the connection to a data source
 func (s *connectorService) TestConnection(request models.TestConnectionRequest) error {
 	switch request.Type {
@@ -31,6 +89,15 @@ func (s *connectorService) TestConnection(request models.TestConnectionRequest)
 		return s.testBigQueryConnection(request.Config)
 	case models.DataSourceTypeGoogleSheets:
 		return s.testGoogleSheetsConnection(request.Config)
+	case models.DataSourceTypeMongoDB:
+		return s.testMongoDBConnection(request.Config)
+	case models.DataSourceTypeRESTAPI:
+		return s.testRESTAPIConnection(request.Config)
+	case models.DataSourceTypeGA4:
+		return s.testGA4Connection(request.Config)
+	case models.DataSourceTypeGoogleDrive:
+		_, err := s.GetGoogleDriveFile(request.Config)
+		return err
 	case models.DataSourceTypeCSV, models.DataSourceTypeExcel:
 		// File-based sources don't need connection testing
 		return nil
@@ -48,20 +115,230 @@ func (s *connectorService) DiscoverSchema(dsType models.DataSourceType, config m
 		return s.discoverBigQuerySchema(config)
 	case models.DataSourceTypeGoogleSheets:
 		return s.discoverGoogleSheetsSchema(config)
+	case models.DataSourceTypeMongoDB:
+		return s.discoverMongoDBSchema(config)
+	case models.DataSourceTypeGA4:
+		return s.discoverGA4Schema(config)
+	case models.DataSourceTypeRESTAPI:
+		table, _, err := s.FetchRESTAPITable(config)
+		if err != nil {
+			return nil, err
+		}
+		return table.Columns, nil
+	default:
+		return nil, fmt.Errorf("unsupported data source type: %s", dsType)
+	}
+}
+
+// DiscoverTables discovers the schema of a data source as one entry per table or sheet,
+// including row counts and sample data where the connector supports it
+func (s *connectorService) DiscoverTables(dsType models.DataSourceType, config map[string]interface{}) ([]SchemaInfo, error) {
+	return s.DiscoverDataSourceTables(0, dsType, config)
+}
+
+// DiscoverDataSourceTables is DiscoverTables for a saved data source, reusing its
+// pooled PostgreSQL connection
+func (s *connectorService) DiscoverDataSourceTables(dataSourceID uint, dsType models.DataSourceType, config map[string]interface{}) ([]SchemaInfo, error) {
+	connector, err := s.connect(dataSourceID, dsType, config)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Disconnect()
+
+	columns, err := connector.GetSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := groupColumnsByTable(columns)
+	for i := range tables {
+		sampleData, err := connector.GetData(tables[i].Name, sampleRowLimit)
+		if err != nil {
+			logger.L().Warn().Err(err).Uint("data_source_id", dataSourceID).Str("table", tables[i].Name).Msg("Failed to get sample data")
+		} else {
+			tables[i].SampleData = sampleData
+		}
+
+		if counter, ok := connector.(rowCounter); ok {
+			rowCount, err := counter.GetRowCount(tables[i].Name)
+			if err != nil {
+				logger.L().Warn().Err(err).Uint("data_source_id", dataSourceID).Str("table", tables[i].Name).Msg("Failed to count rows")
+			} else {
+				tables[i].RowCount = rowCount
+			}
+		}
+
+		if profiler, ok := connector.(columnProfiler); ok {
+			if err := profiler.ProfileColumns(tables[i].Name, tables[i].Columns); err != nil {
+				logger.L().Warn().Err(err).Uint("data_source_id", dataSourceID).Str("table", tables[i].Name).Msg("Failed to profile columns")
+			}
+		}
+		fillSampleValues(tables[i].Columns, tables[i].SampleData)
+	}
+
+	return tables, nil
+}
+
+// SampleDataSourceTable returns up to limit rows of a table of a saved data
+// source whose type has a connector
+func (s *connectorService) SampleDataSourceTable(dataSourceID uint, dsType models.DataSourceType, config map[string]interface{}, table string, limit int) ([]map[string]interface{}, error) {
+	connector, err := s.connect(dataSourceID, dsType, config)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Disconnect()
+
+	return connector.GetData(table, limit)
+}
+
+// ExecuteDataSourceQuery runs a validated query on a saved data source and
+// returns at most limit rows. PostgreSQL data sources run it on their pooled
+// connection.
+func (s *connectorService) ExecuteDataSourceQuery(dataSourceID uint, dsType models.DataSourceType, config map[string]interface{}, query string, limit int) ([]models.Column, []map[string]interface{}, error) {
+	connector, err := s.connect(dataSourceID, dsType, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer connector.Disconnect()
+
+	executor, ok := connector.(sqlQueryExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s data sources do not run SQL queries", dsType)
+	}
+	return executor.ExecuteQuery(query, limit)
+}
+
+// connect returns a connected connector. A saved PostgreSQL data source gets a
+// connector on its pooled connection, which Disconnect leaves open.
+func (s *connectorService) connect(dataSourceID uint, dsType models.DataSourceType, config map[string]interface{}) (Connector, error) {
+	if s.pgPool != nil && dataSourceID != 0 && dsType == models.DataSourceTypePostgreSQL {
+		db, err := s.pgPool.Get(dataSourceID, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", dsType, err)
+		}
+		return connectors.NewPooledPostgreSQLConnector(db), nil
+	}
+
+	connector, err := s.newConnector(dsType)
+	if err != nil {
+		return nil, err
+	}
+	if err := connector.Connect(config); err != nil {
+		connector.Disconnect()
+		return nil, fmt.Errorf("failed to connect to %s: %w", dsType, err)
+	}
+	return connector, nil
+}
+
+// newConnector returns the connector for a database-backed data source type
+func (s *connectorService) newConnector(dsType models.DataSourceType) (Connector, error) {
+	switch dsType {
+	case models.DataSourceTypePostgreSQL:
+		return connectors.NewPostgreSQLConnector(), nil
+	case models.DataSourceTypeBigQuery:
+		return connectors.NewBigQueryConnector(), nil
+	case models.DataSourceTypeGoogleSheets:
+		return connectors.NewGoogleSheetsConnector(), nil
+	case models.DataSourceTypeMongoDB:
+		return connectors.NewMongoDBConnector(), nil
+	case models.DataSourceTypeGA4:
+		return connectors.NewGA4Connector(), nil
 	default:
 		return nil, fmt.Errorf("unsupported data source type: %s", dsType)
 	}
 }
 
-// ProcessFileUpload processes uploaded CSV/Excel files
-func (s *connectorService) ProcessFileUpload(file *multipart.FileHeader) (*models.DataSource, []models.Column, error) {
-	ext := strings.ToLower(filepath.Ext(file.Filename))
+// fillSampleValues sets SampleValues from the sample rows for columns the
+// connector did not profile, keeping distinct non-empty values in row order
+func fillSampleValues(columns []models.Column, sampleData []map[string]interface{}) {
+	for i := range columns {
+		if len(columns[i].SampleValues) > 0 {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, row := range sampleData {
+			value, ok := row[columns[i].Name]
+			if !ok || value == nil {
+				continue
+			}
+			key := fmt.Sprintf("%v", value)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			columns[i].SampleValues = append(columns[i].SampleValues, value)
+			if len(columns[i].SampleValues) >= sampleRowLimit {
+				break
+			}
+		}
+	}
+}
+
+// groupColumnsByTable splits qualified "table.column" columns into one SchemaInfo per table,
+// keeping the order in which tables were discovered
+func groupColumnsByTable(columns []models.Column) []SchemaInfo {
+	relationships := models.BuildRelationships(columns)
+
+	var tables []SchemaInfo
+	index := make(map[string]int)
+	for _, col := range columns {
+		tableName, columnName := models.SplitColumnName(col.Name)
+		if tableName == "" {
+			tableName = "default"
+		}
+
+		i, ok := index[tableName]
+		if !ok {
+			i = len(tables)
+			index[tableName] = i
+			tables = append(tables, SchemaInfo{
+				Name:        tableName,
+				DisplayName: tableName,
+			})
+		}
+
+		col.Name = columnName
+		tables[i].Columns = append(tables[i].Columns, col)
+	}
+
+	for _, rel := range relationships {
+		if i, ok := index[rel.FromTable]; ok {
+			tables[i].Relationships = append(tables[i].Relationships, rel)
+		}
+	}
+
+	return tables
+}
+
+// ProcessFileUpload processes uploaded CSV/Excel files. A CSV file is one
+// table; every non-empty Excel sheet, or every sheet in options.Sheets, is a
+// table of its own.
+func (s *connectorService) ProcessFileUpload(file *multipart.FileHeader, options models.FileImportOptions) (*models.DataSource, []SchemaInfo, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	return s.ProcessFile(file.Filename, file.Size, src, options)
+}
+
+// ProcessFile processes a CSV/Excel file read from r, like ProcessFileUpload.
+// CSV files are streamed; only the sample rows are kept in memory.
+func (s *connectorService) ProcessFile(name string, size int64, r io.Reader, options models.FileImportOptions) (*models.DataSource, []SchemaInfo, error) {
+	settings, err := resolveFileImportOptions(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
 	
 	switch ext {
 	case ".csv":
-		return s.processCSVFile(file)
+		return s.processCSVFile(name, size, r, settings)
 	case ".xlsx", ".xls":
-		return s.processExcelFile(file)
+		return s.processExcelFile(name, size, r, settings)
 	default:
 		return nil, nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
@@ -113,6 +390,29 @@ func (s *connectorService) discoverBigQuerySchema(config map[string]interface{})
 	return connector.GetSchema()
 }
 
+// GA4 export connection methods
+func (s *connectorService) testGA4Connection(config map[string]interface{}) error {
+	connector := connectors.NewGA4Connector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return fmt.Errorf("failed to connect to GA4 export: %w", err)
+	}
+
+	return connector.TestConnection()
+}
+
+func (s *connectorService) discoverGA4Schema(config map[string]interface{}) ([]models.Column, error) {
+	connector := connectors.NewGA4Connector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to GA4 export: %w", err)
+	}
+
+	return connector.GetSchema()
+}
+
 // Google Sheets connection methods (placeholder implementations)
 func (s *connectorService) testGoogleSheetsConnection(config map[string]interface{}) error {
 	connector := connectors.NewGoogleSheetsConnector()
@@ -136,119 +436,437 @@ func (s *connectorService) discoverGoogleSheetsSchema(config map[string]interfac
 	return connector.GetSchema()
 }
 
-// File processing methods
-func (s *connectorService) processCSVFile(file *multipart.FileHeader) (*models.DataSource, []models.Column, error) {
-	src, err := file.Open()
+// MongoDB connection methods
+func (s *connectorService) testMongoDBConnection(config map[string]interface{}) error {
+	connector := connectors.NewMongoDBConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	return connector.TestConnection()
+}
+
+func (s *connectorService) discoverMongoDBSchema(config map[string]interface{}) ([]models.Column, error) {
+	connector := connectors.NewMongoDBConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	return connector.GetSchema()
+}
+
+// REST API connection methods
+func (s *connectorService) testRESTAPIConnection(config map[string]interface{}) error {
+	connector := connectors.NewRESTAPIConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return fmt.Errorf("invalid REST API config: %w", err)
+	}
+
+	return connector.TestConnection()
+}
+
+// GetGoogleDriveFile returns the metadata of the file of a Google Drive data source
+func (s *connectorService) GetGoogleDriveFile(config map[string]interface{}) (*models.GoogleDriveFile, error) {
+	connector := connectors.NewGoogleDriveConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to Google Drive: %w", err)
+	}
+
+	return connector.GetFile()
+}
+
+// DownloadGoogleDriveFile returns the metadata and content of the file of a
+// Google Drive data source; the caller closes the content
+func (s *connectorService) DownloadGoogleDriveFile(config map[string]interface{}) (*models.GoogleDriveFile, io.ReadCloser, error) {
+	connector := connectors.NewGoogleDriveConnector()
+	if err := connector.Connect(config); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to Google Drive: %w", err)
+	}
+
+	return connector.Download()
+}
+
+// ListGoogleDriveFiles lists the CSV and Excel files and the folders of a
+// Drive folder, or searches Drive, with the credentials in config
+func (s *connectorService) ListGoogleDriveFiles(config map[string]interface{}, folderID, query, pageToken string, pageSize int) (*models.GoogleDriveBrowseResponse, error) {
+	connector := connectors.NewGoogleDriveConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to Google Drive: %w", err)
+	}
+
+	return connector.ListFiles(folderID, query, pageToken, pageSize)
+}
+
+// ListGoogleSheets lists the sheets of the spreadsheet of a Google Sheets
+// config with the headers of the configured range, marking the selected ones
+func (s *connectorService) ListGoogleSheets(config map[string]interface{}) ([]models.GoogleSheet, error) {
+	connector := connectors.NewGoogleSheetsConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to Google Sheets: %w", err)
+	}
+
+	sheetInfos, err := connector.ListSheets()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open CSV file: %w", err)
+		return nil, err
 	}
-	defer src.Close()
-	
-	reader := csv.NewReader(src)
+	selected, err := connector.SelectedSheets()
+	if err != nil {
+		return nil, err
+	}
+	isSelected := make(map[string]bool, len(selected))
+	for _, sheet := range selected {
+		isSelected[sheet.Title] = true
+	}
+
+	sheets := make([]models.GoogleSheet, len(sheetInfos))
+	for i, info := range sheetInfos {
+		sheets[i] = models.GoogleSheet{Title: info.Title, RowCount: info.RowCount, Selected: isSelected[info.Title]}
+		rows, err := connector.GetRows(info.Title, 1)
+		if err != nil {
+			logger.L().Warn().Err(err).Str("sheet", info.Title).Msg("Failed to read sheet headers")
+			continue
+		}
+		if len(rows) > 0 {
+			sheets[i].Headers = rows[0]
+		}
+	}
+	return sheets, nil
+}
+
+// FetchRESTAPITable fetches all records of a REST API data source and infers
+// the table schema from them. The records are returned for materialization.
+func (s *connectorService) FetchRESTAPITable(config map[string]interface{}) (*SchemaInfo, []map[string]interface{}, error) {
+	records, err := s.FetchRESTAPIRecords(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, err := NewSchemaInferenceService().InferSchemaFromSample(records, connectors.RESTTableName(config))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to infer schema: %w", err)
+	}
+
+	var columns []models.Column
+	if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+		return nil, nil, fmt.Errorf("failed to read inferred columns: %w", err)
+	}
+	// Records are maps, so keep the columns in a stable order
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+
+	table := &SchemaInfo{
+		Name:        schema.Name,
+		DisplayName: schema.DisplayName,
+		Description: schema.Description,
+		Columns:     columns,
+		RowCount:    schema.RowCount,
+	}
+	if len(records) > sampleRowLimit {
+		table.SampleData = records[:sampleRowLimit]
+	} else {
+		table.SampleData = records
+	}
+	fillSampleValues(table.Columns, table.SampleData)
+
+	return table, records, nil
+}
+
+// FetchRESTAPIRecords fetches all records of a REST API data source. With
+// incremental_since set, it is sent as the incremental_param query parameter.
+func (s *connectorService) FetchRESTAPIRecords(config map[string]interface{}) ([]map[string]interface{}, error) {
+	connector := connectors.NewRESTAPIConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("invalid REST API config: %w", err)
+	}
+
+	records, err := connector.FetchRecords()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch records: %w", err)
+	}
+	return records, nil
+}
+
+// MaterializedRecords are the records of one table pulled for materialization
+type MaterializedRecords struct {
+	Table   string
+	Records []map[string]interface{}
+}
+
+// FetchGoogleSheetsRecords fetches every row of the configured range of the
+// selected sheets of a spreadsheet, keyed by the header row, for materialization
+func (s *connectorService) FetchGoogleSheetsRecords(config map[string]interface{}) ([]MaterializedRecords, error) {
+	connector := connectors.NewGoogleSheetsConnector()
+	defer connector.Disconnect()
+
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to Google Sheets: %w", err)
+	}
+
+	sheets, err := connector.SelectedSheets()
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []MaterializedRecords
+	for _, sheet := range sheets {
+		if sheet.RowCount == 0 {
+			continue
+		}
+		rows, err := connector.GetRows(sheet.Title, int(sheet.RowCount))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", sheet.Title, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		tables = append(tables, MaterializedRecords{Table: sheet.Title, Records: sheetRecords(rows)})
+	}
+	return tables, nil
+}
+
+// sheetRecords turns sheet rows into records keyed by the header row; blank
+// headers are named like in schema discovery
+func sheetRecords(rows [][]string) []map[string]interface{} {
+	headers := make([]string, len(rows[0]))
+	for i, header := range rows[0] {
+		if header == "" {
+			header = fmt.Sprintf("Column_%d", i+1)
+		}
+		headers[i] = header
+	}
+
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(headers))
+		for i, header := range headers {
+			if i < len(row) && row[i] != "" {
+				record[header] = row[i]
+			} else {
+				record[header] = nil
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// File processing methods
+func (s *connectorService) processCSVFile(fileName string, size int64, src io.Reader, settings *fileImportSettings) (*models.DataSource, []SchemaInfo, error) {
+	reader := csv.NewReader(decodeFileReader(src, settings.encoding))
+	reader.Comma = settings.delimiter
+	reader.FieldsPerRecord = -1
 	
 	// Read header row
-	headers, err := reader.Read()
+	header, err := reader.Read()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to read CSV headers: %w", err)
 	}
 	
-	// Read a few sample rows to infer data types
+	// Read a few sample rows to infer data types, counting the rest
 	sampleRows := make([][]string, 0, 10)
-	for i := 0; i < 10; i++ {
+	var rowCount int64
+	if !settings.hasHeader {
+		sampleRows = append(sampleRows, header)
+		rowCount++
+	}
+	for {
 		row, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to read CSV row: %w", err)
+			return nil, nil, fmt.Errorf("failed to read CSV row %d: %w", rowCount+1, err)
+		}
+		if len(sampleRows) < cap(sampleRows) {
+			sampleRows = append(sampleRows, row)
 		}
-		sampleRows = append(sampleRows, row)
+		rowCount++
 	}
 	
-	// Create data source
-	dataSource := &models.DataSource{
-		Name:        strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename)),
-		Type:        models.DataSourceTypeCSV,
-		Description: fmt.Sprintf("CSV file: %s", file.Filename),
-		Status:      models.ConnectionStatusActive,
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	dataSource, err := newFileDataSource(fileName, size, models.DataSourceTypeCSV, settings, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 	
 	// Infer column types
-	columns := make([]models.Column, len(headers))
-	for i, header := range headers {
+	names := fileColumnNames(header, settings.hasHeader)
+	columns := make([]models.Column, len(names))
+	for i, columnName := range names {
 		columns[i] = models.Column{
-			Name:     header,
+			Name:     columnName,
 			Type:     s.inferDataType(sampleRows, i),
 			Nullable: true, // CSV columns are generally nullable
 		}
 	}
 	
-	return dataSource, columns, nil
+	table := SchemaInfo{
+		Name:        name,
+		DisplayName: name,
+		Columns:     columns,
+		RowCount:    rowCount,
+		SampleData:  fileSampleData(names, sampleRows),
+	}
+	fillSampleValues(table.Columns, table.SampleData)
+	
+	return dataSource, []SchemaInfo{table}, nil
 }
 
-func (s *connectorService) processExcelFile(file *multipart.FileHeader) (*models.DataSource, []models.Column, error) {
-	src, err := file.Open()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open Excel file: %w", err)
-	}
-	defer src.Close()
-
-	// Create temporary file to read Excel
-	tempData := make([]byte, file.Size)
-	_, err = io.ReadFull(src, tempData)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read Excel file: %w", err)
-	}
-
-	f, err := excelize.OpenReader(strings.NewReader(string(tempData)))
+// processExcelFile reads the sheets of an Excel file row by row. Worksheets
+// larger than excelStreamXMLLimit are unzipped to temporary files rather than
+// memory, and only the first excelInferenceRowLimit rows of a sheet are kept
+// to infer its column types; the rest are counted.
+func (s *connectorService) processExcelFile(fileName string, size int64, src io.Reader, settings *fileImportSettings) (*models.DataSource, []SchemaInfo, error) {
+	f, err := excelize.OpenReader(src, excelize.Options{UnzipXMLSizeLimit: excelStreamXMLLimit})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse Excel file: %w", err)
 	}
 	defer f.Close()
 
-	// Get the first sheet
-	sheetName := f.GetSheetName(0)
-	if sheetName == "" {
+	// Import the selected sheets, or all of them
+	sheetNames := f.GetSheetList()
+	if len(sheetNames) == 0 {
 		return nil, nil, fmt.Errorf("no sheets found in Excel file")
 	}
+	if len(settings.sheets) > 0 {
+		for _, sheet := range settings.sheets {
+			if idx, _ := f.GetSheetIndex(sheet); idx < 0 {
+				return nil, nil, fmt.Errorf("%w: sheet %q not found", ErrInvalidFileImportOptions, sheet)
+			}
+		}
+		sheetNames = settings.sheets
+	}
 
-	rows, err := f.GetRows(sheetName)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read Excel rows: %w", err)
+	var tables []SchemaInfo
+	var imported []string
+	for _, sheetName := range sheetNames {
+		table, err := s.processExcelSheet(f, sheetName, settings)
+		if err != nil {
+			return nil, nil, err
+		}
+		if table == nil {
+			continue
+		}
+		tables = append(tables, *table)
+		imported = append(imported, sheetName)
 	}
 
-	if len(rows) == 0 {
+	if len(tables) == 0 {
 		return nil, nil, fmt.Errorf("Excel file is empty")
 	}
 
-	// Create data source
-	dataSource := &models.DataSource{
-		Name:        strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename)),
-		Type:        models.DataSourceTypeExcel,
-		Description: fmt.Sprintf("Excel file: %s", file.Filename),
-		Status:      models.ConnectionStatusActive,
+	dataSource, err := newFileDataSource(fileName, size, models.DataSourceTypeExcel, settings, imported)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// First row as headers
-	headers := rows[0]
-	columns := make([]models.Column, len(headers))
+	return dataSource, tables, nil
+}
+
+// processExcelSheet streams the rows of a sheet and infers its table; it
+// returns nil for an empty sheet. Like GetRows, blank rows after the last
+// row with a value are not counted.
+func (s *connectorService) processExcelSheet(f *excelize.File, sheetName string, settings *fileImportSettings) (*SchemaInfo, error) {
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Excel rows of sheet %q: %w", sheetName, err)
+	}
+	defer rows.Close()
 
-	// Analyze data types from sample rows
-	for i, header := range headers {
-		dataType := "text" // default
-		if len(rows) > 1 {
-			// Sample first few rows to determine data type
-			dataType = s.inferDataTypeFromRows(rows[1:], i)
+	var header []string
+	var sampleRows [][]string
+	var rowCount, lastRow int64
+	for first := true; rows.Next(); first = false {
+		row, err := rows.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Excel rows of sheet %q: %w", sheetName, err)
+		}
+		if first {
+			// First row as headers, unless the sheet has none
+			header = row
+			if settings.hasHeader {
+				continue
+			}
+		}
+		rowCount++
+		if len(row) > 0 {
+			lastRow = rowCount
 		}
+		if len(sampleRows) < excelInferenceRowLimit {
+			sampleRows = append(sampleRows, row)
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read Excel rows of sheet %q: %w", sheetName, err)
+	}
+	if len(header) == 0 && lastRow == 0 {
+		return nil, nil
+	}
+	if int64(len(sampleRows)) > lastRow {
+		sampleRows = sampleRows[:lastRow]
+	}
 
+	// Analyze data types from the sampled rows
+	names := fileColumnNames(header, settings.hasHeader)
+	columns := make([]models.Column, len(names))
+	for i, columnName := range names {
 		columns[i] = models.Column{
-			Name:     strings.TrimSpace(header),
-			Type:     dataType,
+			Name:     columnName,
+			Type:     s.inferDataTypeFromRows(sampleRows, i),
 			Nullable: true,
 		}
 	}
 
-	return dataSource, columns, nil
+	table := &SchemaInfo{
+		Name:        sheetName,
+		DisplayName: sheetName,
+		Columns:     columns,
+		RowCount:    lastRow,
+		SampleData:  fileSampleData(names, sampleRows),
+	}
+	fillSampleValues(table.Columns, table.SampleData)
+	return table, nil
+}
+
+// newFileDataSource describes an uploaded file as a data source, recording how it was read
+func newFileDataSource(fileName string, size int64, dsType models.DataSourceType, settings *fileImportSettings, sheets []string) (*models.DataSource, error) {
+	config := models.ConnectionConfig{
+		FileName:  fileName,
+		FileSize:  size,
+		HasHeader: settings.hasHeader,
+		Sheets:    sheets,
+	}
+	label := "Excel"
+	if dsType == models.DataSourceTypeCSV {
+		label = "CSV"
+		config.Delimiter = string(settings.delimiter)
+		config.Encoding = settings.charset
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode file configuration: %w", err)
+	}
+
+	return &models.DataSource{
+		Name:        strings.TrimSuffix(fileName, filepath.Ext(fileName)),
+		Type:        dsType,
+		Description: fmt.Sprintf("%s file: %s", label, fileName),
+		Config:      models.JSON(configJSON),
+		Status:      models.ConnectionStatusActive,
+	}, nil
 }
 
 // inferDataType infers the data type from sample data
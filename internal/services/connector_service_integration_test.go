@@ -0,0 +1,110 @@
+//go:build integration
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/testenv"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var integrationPostgres *testenv.Postgres
+
+func TestMain(m *testing.M) {
+	env, err := testenv.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration tests need docker: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer env.Close()
+
+		if integrationPostgres, err = env.StartPostgres(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		_, err := integrationPostgres.DB.Exec(`
+			CREATE TABLE customers (id SERIAL PRIMARY KEY, name VARCHAR(100) NOT NULL, region VARCHAR(20));
+			CREATE TABLE orders (
+				id SERIAL PRIMARY KEY,
+				customer_id INTEGER NOT NULL REFERENCES customers(id),
+				amount NUMERIC(10,2) NOT NULL
+			);
+			INSERT INTO customers (name, region) VALUES ('Ann', 'EU'), ('Bob', 'US');
+			INSERT INTO orders (customer_id, amount) VALUES (1, 10.50), (2, 5.25), (2, 7.00);
+		`)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load postgres fixture: %v\n", err)
+			return 1
+		}
+
+		return m.Run()
+	}()
+	os.Exit(code)
+}
+
+func TestConnectorServiceIntegration_DiscoverPostgreSQLTables(t *testing.T) {
+	service := NewConnectorService()
+
+	require.NoError(t, service.TestConnection(models.TestConnectionRequest{
+		Type:   models.DataSourceTypePostgreSQL,
+		Config: integrationPostgres.Config,
+	}))
+
+	tables, err := service.DiscoverTables(models.DataSourceTypePostgreSQL, integrationPostgres.Config)
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+
+	byName := make(map[string]SchemaInfo)
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+
+	orders := byName["orders"]
+	assert.Equal(t, int64(3), orders.RowCount)
+	assert.Len(t, orders.SampleData, 3)
+	require.Len(t, orders.Relationships, 1)
+	assert.Equal(t, "customers", orders.Relationships[0].ToTable)
+
+	customers := byName["customers"]
+	assert.Equal(t, int64(2), customers.RowCount)
+	for _, column := range customers.Columns {
+		if column.Name == "region" {
+			assert.ElementsMatch(t, []interface{}{"EU", "US"}, column.SampleValues)
+		}
+	}
+}
+
+func TestDryImportIntegration_GoogleSheets(t *testing.T) {
+	server := testenv.NewSheetsServer(map[string][]testenv.Sheet{
+		"sheet-1": {{Title: "Orders", Values: [][]interface{}{
+			{"id", "amount", "amount"},
+			{"1", "10.5", "x"},
+			{"2", "n/a", "y"},
+			{"3", "7", "z"},
+			{"4", "8", "z"},
+		}}},
+	})
+	defer server.Close()
+	t.Setenv("GOOGLE_SHEETS_ENDPOINT", server.URL)
+
+	report, err := NewDryImportService().AnalyzeGoogleSheets(map[string]interface{}{"spreadsheet_id": "sheet-1"})
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 1)
+	assert.Equal(t, int64(4), report.Tables[0].RowCount)
+	assert.False(t, report.CanImport)
+
+	codes := make(map[models.DryImportIssueCode]bool)
+	for _, issue := range report.Issues {
+		codes[issue.Code] = true
+	}
+	assert.True(t, codes[models.DryImportIssueDuplicateHeader])
+	assert.True(t, codes[models.DryImportIssueTypeConflict])
+}
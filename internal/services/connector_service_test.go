@@ -2,7 +2,10 @@ package services
 
 import (
 	"bytes"
+	"fmt"
 	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"net/textproto"
 	"path/filepath"
 	"strings"
@@ -136,6 +139,57 @@ func TestConnectorService_DiscoverSchema(t *testing.T) {
 	}
 }
 
+func TestConnectorService_DiscoverTables_UnsupportedType(t *testing.T) {
+	service := NewConnectorService()
+
+	tables, err := service.DiscoverTables("unsupported", map[string]interface{}{})
+	assert.Error(t, err)
+	assert.Nil(t, tables)
+}
+
+func TestGroupColumnsByTable(t *testing.T) {
+	columns := []models.Column{
+		{Name: "orders.id", Type: "integer", PrimaryKey: true},
+		{Name: "orders.customer_id", Type: "integer", ForeignKey: &models.ForeignKeyRef{Table: "customers", Column: "id"}},
+		{Name: "customers.id", Type: "integer", PrimaryKey: true},
+		{Name: "customers.name", Type: "string"},
+	}
+
+	tables := groupColumnsByTable(columns)
+	assert.Len(t, tables, 2)
+
+	assert.Equal(t, "orders", tables[0].Name)
+	assert.Len(t, tables[0].Columns, 2)
+	assert.Equal(t, "id", tables[0].Columns[0].Name)
+	assert.Equal(t, "customer_id", tables[0].Columns[1].Name)
+	assert.Len(t, tables[0].Relationships, 1)
+	assert.Equal(t, "customers", tables[0].Relationships[0].ToTable)
+
+	assert.Equal(t, "customers", tables[1].Name)
+	assert.Len(t, tables[1].Columns, 2)
+	assert.Empty(t, tables[1].Relationships)
+}
+
+func TestFillSampleValues(t *testing.T) {
+	columns := []models.Column{
+		{Name: "status", Type: "string"},
+		{Name: "amount", Type: "decimal"},
+		{Name: "region", Type: "string", SampleValues: []interface{}{"north"}},
+	}
+	sampleData := []map[string]interface{}{
+		{"status": "paid", "amount": 10.5, "region": "south"},
+		{"status": "paid", "amount": nil, "region": "east"},
+		{"status": "refunded", "amount": 3.0, "region": "west"},
+	}
+
+	fillSampleValues(columns, sampleData)
+
+	assert.Equal(t, []interface{}{"paid", "refunded"}, columns[0].SampleValues)
+	assert.Equal(t, []interface{}{10.5, 3.0}, columns[1].SampleValues)
+	// Values from the connector's profile are kept
+	assert.Equal(t, []interface{}{"north"}, columns[2].SampleValues)
+}
+
 func TestConnectorService_ProcessFileUpload(t *testing.T) {
 	service := NewConnectorService()
 
@@ -176,16 +230,16 @@ func TestConnectorService_ProcessFileUpload(t *testing.T) {
 			// Create a multipart file header
 			fileHeader := createTestFileHeader(tt.filename, tt.content)
 
-			dataSource, columns, err := service.ProcessFileUpload(fileHeader)
+			dataSource, tables, err := service.ProcessFileUpload(fileHeader, models.FileImportOptions{})
 
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, dataSource)
-				assert.Nil(t, columns)
+				assert.Nil(t, tables)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, dataSource)
-				assert.NotNil(t, columns)
+				assert.NotNil(t, tables)
 				// DataSource name should be filename without extension
 				expectedName := strings.TrimSuffix(tt.filename, filepath.Ext(tt.filename))
 				assert.Equal(t, expectedName, dataSource.Name)
@@ -317,4 +371,32 @@ func createTestFileHeader(filename, content string) *multipart.FileHeader {
 		Header:   header,
 		Size:     int64(len(content)),
 	}
-}
\ No newline at end of file
+}
+func TestConnectorService_FetchRESTAPITable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"id":1,"price":{"amount":"9.99"},"sold_at":"2024-03-01"},{"id":2,"price":{"amount":"12.50"},"sold_at":"2024-03-02"}]}`)
+	}))
+	defer server.Close()
+
+	service := NewConnectorService()
+	table, records, err := service.FetchRESTAPITable(map[string]interface{}{
+		"url":          server.URL + "/sales",
+		"records_path": "items",
+	})
+	if err != nil {
+		t.Fatalf("FetchRESTAPITable() error = %v", err)
+	}
+
+	assert.Equal(t, "sales", table.Name)
+	assert.Equal(t, int64(2), table.RowCount)
+	assert.Len(t, records, 2)
+	assert.Len(t, table.SampleData, 2)
+
+	types := make(map[string]string)
+	for _, column := range table.Columns {
+		types[column.Name] = column.Type
+	}
+	assert.Equal(t, []string{"id", "price_amount", "sold_at"}, []string{table.Columns[0].Name, table.Columns[1].Name, table.Columns[2].Name})
+	assert.Equal(t, "float", types["price_amount"])
+	assert.Equal(t, "date", types["sold_at"])
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrCustomSQLFunctionNotFound is returned when a custom SQL function does not exist
+var ErrCustomSQLFunctionNotFound = errors.New("custom SQL function not found")
+
+// CustomSQLFunctionService manages the warehouse functions registered per data
+// source. The validator allows them and the NL2SQL prompt documents them.
+type CustomSQLFunctionService struct {
+	db                *gorm.DB
+	governanceService *GovernanceService
+	sqlValidator      *SQLValidatorService
+}
+
+// NewCustomSQLFunctionService creates a new custom SQL function service
+func NewCustomSQLFunctionService(db *gorm.DB, governanceService *GovernanceService) *CustomSQLFunctionService {
+	return &CustomSQLFunctionService{
+		db:                db,
+		governanceService: governanceService,
+		sqlValidator:      NewSQLValidatorService(),
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *CustomSQLFunctionService) WithContext(ctx context.Context) *CustomSQLFunctionService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.governanceService = s.governanceService.WithContext(ctx)
+	return &scoped
+}
+
+// List returns the functions registered for a data source by name
+func (s *CustomSQLFunctionService) List(dataSourceID uint) ([]models.CustomSQLFunction, error) {
+	if _, err := s.getDataSource(dataSourceID); err != nil {
+		return nil, err
+	}
+
+	functions := []models.CustomSQLFunction{}
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("name").Find(&functions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list custom SQL functions: %w", err)
+	}
+	return functions, nil
+}
+
+// Create registers a function for a data source
+func (s *CustomSQLFunctionService) Create(adminID, dataSourceID uint, req *models.CustomSQLFunctionRequest) (*models.CustomSQLFunction, error) {
+	dataSource, err := s.getDataSource(dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.validateRequest(dataSource, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.CustomSQLFunction{}).
+		Where("data_source_id = ? AND name = ?", dataSourceID, name).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing function: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("function %s is already registered for this data source", name)
+	}
+
+	function := &models.CustomSQLFunction{DataSourceID: dataSourceID}
+	applyCustomSQLFunctionRequest(function, name, req, adminID)
+	if err := s.db.Create(function).Error; err != nil {
+		return nil, fmt.Errorf("failed to create custom SQL function: %w", err)
+	}
+
+	s.emitFunctionChanged(function, adminID, "created")
+	return function, nil
+}
+
+// Update replaces the signature and documentation of a function. Renaming is
+// allowed as long as the new name is not taken.
+func (s *CustomSQLFunctionService) Update(adminID, dataSourceID, id uint, req *models.CustomSQLFunctionRequest) (*models.CustomSQLFunction, error) {
+	dataSource, err := s.getDataSource(dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	function, err := s.get(dataSourceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.validateRequest(dataSource, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != function.Name {
+		var existing int64
+		if err := s.db.Model(&models.CustomSQLFunction{}).
+			Where("data_source_id = ? AND name = ? AND id <> ?", dataSourceID, name, id).Count(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to check existing function: %w", err)
+		}
+		if existing > 0 {
+			return nil, fmt.Errorf("function %s is already registered for this data source", name)
+		}
+	}
+
+	applyCustomSQLFunctionRequest(function, name, req, adminID)
+	if err := s.db.Save(function).Error; err != nil {
+		return nil, fmt.Errorf("failed to update custom SQL function: %w", err)
+	}
+
+	s.emitFunctionChanged(function, adminID, "updated")
+	return function, nil
+}
+
+// Delete unregisters a function; queries using it fail validation again
+func (s *CustomSQLFunctionService) Delete(adminID, dataSourceID, id uint) error {
+	function, err := s.get(dataSourceID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(function).Error; err != nil {
+		return fmt.Errorf("failed to delete custom SQL function: %w", err)
+	}
+
+	s.emitFunctionChanged(function, adminID, "deleted")
+	return nil
+}
+
+func (s *CustomSQLFunctionService) get(dataSourceID, id uint) (*models.CustomSQLFunction, error) {
+	var function models.CustomSQLFunction
+	if err := s.db.Where("id = ? AND data_source_id = ?", id, dataSourceID).First(&function).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomSQLFunctionNotFound
+		}
+		return nil, fmt.Errorf("failed to get custom SQL function: %w", err)
+	}
+	return &function, nil
+}
+
+func (s *CustomSQLFunctionService) getDataSource(id uint) (*models.DataSource, error) {
+	var dataSource models.DataSource
+	if err := s.db.Select("id", "type").First(&dataSource, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("data source not found")
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+	return &dataSource, nil
+}
+
+// validateRequest checks the function name and returns it upper-cased. The
+// signature must name the function, and functions blocked for the data source
+// dialect cannot be registered since the validator would still reject them.
+func (s *CustomSQLFunctionService) validateRequest(dataSource *models.DataSource, req *models.CustomSQLFunctionRequest) (string, error) {
+	if dataSource.Type.UsesAggregationPipeline() {
+		return "", errors.New("custom SQL functions are not supported for aggregation pipeline data sources")
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(req.Name))
+	if !policyIdentifierPattern.MatchString(name) {
+		return "", fmt.Errorf("invalid function name: %q", req.Name)
+	}
+	if nonFunctionKeywords[name] {
+		return "", fmt.Errorf("%s is a SQL keyword, not a function", name)
+	}
+	if s.sqlValidator.isFunctionBlocked(name, models.DialectForDataSourceType(dataSource.Type)) {
+		return "", fmt.Errorf("function %s is blocked for this data source", name)
+	}
+	if !strings.Contains(strings.ToUpper(req.Signature), name+"(") {
+		return "", fmt.Errorf("signature must contain the call %s(...)", strings.ToLower(name))
+	}
+	return name, nil
+}
+
+// applyCustomSQLFunctionRequest copies the request into the function
+func applyCustomSQLFunctionRequest(function *models.CustomSQLFunction, name string, req *models.CustomSQLFunctionRequest, adminID uint) {
+	function.Name = name
+	function.Signature = strings.TrimSpace(req.Signature)
+	function.Description = strings.TrimSpace(req.Description)
+	function.Example = strings.TrimSpace(req.Example)
+	function.UpdatedBy = adminID
+}
+
+// emitFunctionChanged records the change in the governance event log
+func (s *CustomSQLFunctionService) emitFunctionChanged(function *models.CustomSQLFunction, adminID uint, action string) {
+	err := s.governanceService.Emit(models.GovernanceEventPolicyChanged, function.DataSourceID, adminID, map[string]interface{}{
+		"policy_type": "custom_sql_function",
+		"function_id": function.ID,
+		"name":        function.Name,
+		"action":      action,
+	})
+	if err != nil {
+		logger.L().Error().Err(err).Uint("function_id", function.ID).Msg("Failed to emit policy change event for custom SQL function")
+	}
+}
+
+// formatCustomFunctions renders registered functions for the NL2SQL prompt
+func formatCustomFunctions(functions []models.CustomSQLFunction) string {
+	var b strings.Builder
+	for _, function := range functions {
+		b.WriteString("- " + function.Signature)
+		if function.Description != "" {
+			b.WriteString(": " + function.Description)
+		}
+		if function.Example != "" {
+			b.WriteString(" (e.g. " + function.Example + ")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// dashboardPresenceTTL is how long an analyst stays present without a heartbeat
+const dashboardPresenceTTL = 45 * time.Second
+
+// dashboardEventBuffer is the number of events queued per connection. A
+// connection that falls further behind is closed so the client reloads.
+const dashboardEventBuffer = 64
+
+// DashboardHub keeps one in-memory room per dashboard with the open realtime
+// connections and the presence of the analysts viewing it
+type DashboardHub struct {
+	mu    sync.Mutex
+	rooms map[uint]*dashboardRoom
+	now   func() time.Time
+}
+
+type dashboardRoom struct {
+	subscribers map[*dashboardSubscriber]bool
+	presence    map[uint]models.DashboardPresence
+}
+
+type dashboardSubscriber struct {
+	userID uint
+	events chan models.DashboardEvent
+}
+
+// NewDashboardHub creates a new dashboard hub
+func NewDashboardHub() *DashboardHub {
+	return &DashboardHub{
+		rooms: make(map[uint]*dashboardRoom),
+		now:   time.Now,
+	}
+}
+
+// Subscribe joins the dashboard room and marks the user present. The returned
+// function leaves the room; the channel is closed when the connection is left
+// or dropped for falling behind.
+func (h *DashboardHub) Subscribe(dashboardID uint, presence models.DashboardPresence) (<-chan models.DashboardEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[dashboardID]
+	if !ok {
+		room = &dashboardRoom{
+			subscribers: make(map[*dashboardSubscriber]bool),
+			presence:    make(map[uint]models.DashboardPresence),
+		}
+		h.rooms[dashboardID] = room
+	}
+
+	sub := &dashboardSubscriber{
+		userID: presence.UserID,
+		events: make(chan models.DashboardEvent, dashboardEventBuffer),
+	}
+	room.subscribers[sub] = true
+
+	presence.LastSeen = h.now()
+	room.presence[presence.UserID] = presence
+	h.broadcastPresenceLocked(dashboardID, room, presence.UserID)
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() { h.unsubscribe(dashboardID, sub) })
+	}
+}
+
+// Publish sends an event to every connection in the dashboard room
+func (h *DashboardHub) Publish(event models.DashboardEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if room, ok := h.rooms[event.DashboardID]; ok {
+		h.publishLocked(room, event)
+	}
+}
+
+// Touch refreshes the presence of a connected user and broadcasts it when the
+// selected widget changed. It reports false when the user is not connected.
+func (h *DashboardHub) Touch(dashboardID uint, presence models.DashboardPresence) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[dashboardID]
+	if !ok {
+		return false
+	}
+	current, ok := room.presence[presence.UserID]
+	if !ok {
+		return false
+	}
+
+	changed := current.SelectedWidget != presence.SelectedWidget
+	current.SelectedWidget = presence.SelectedWidget
+	current.LastSeen = h.now()
+	room.presence[presence.UserID] = current
+
+	if h.expirePresenceLocked(room) || changed {
+		h.broadcastPresenceLocked(dashboardID, room, presence.UserID)
+	}
+	return true
+}
+
+// KeepAlive refreshes the presence of a connected user without changing it
+func (h *DashboardHub) KeepAlive(dashboardID, userID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[dashboardID]
+	if !ok {
+		return
+	}
+	if presence, ok := room.presence[userID]; ok {
+		presence.LastSeen = h.now()
+		room.presence[userID] = presence
+	}
+	if h.expirePresenceLocked(room) {
+		h.broadcastPresenceLocked(dashboardID, room, 0)
+	}
+}
+
+// Presence lists the analysts currently on a dashboard
+func (h *DashboardHub) Presence(dashboardID uint) []models.DashboardPresence {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[dashboardID]
+	if !ok {
+		return []models.DashboardPresence{}
+	}
+	if h.expirePresenceLocked(room) {
+		h.broadcastPresenceLocked(dashboardID, room, 0)
+	}
+	return presenceList(room)
+}
+
+func (h *DashboardHub) unsubscribe(dashboardID uint, sub *dashboardSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[dashboardID]
+	if !ok {
+		return
+	}
+	if room.subscribers[sub] {
+		delete(room.subscribers, sub)
+		close(sub.events)
+	}
+
+	// The user may have the dashboard open in several tabs
+	for other := range room.subscribers {
+		if other.userID == sub.userID {
+			return
+		}
+	}
+	delete(room.presence, sub.userID)
+
+	if len(room.subscribers) == 0 {
+		delete(h.rooms, dashboardID)
+		return
+	}
+	h.broadcastPresenceLocked(dashboardID, room, sub.userID)
+}
+
+func (h *DashboardHub) publishLocked(room *dashboardRoom, event models.DashboardEvent) {
+	for sub := range room.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// Dropping an event would leave the client with a stale layout
+			delete(room.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (h *DashboardHub) broadcastPresenceLocked(dashboardID uint, room *dashboardRoom, userID uint) {
+	h.publishLocked(room, models.DashboardEvent{
+		Type:        models.DashboardEventPresence,
+		DashboardID: dashboardID,
+		UserID:      userID,
+		Presence:    presenceList(room),
+		Timestamp:   h.now(),
+	})
+}
+
+// expirePresenceLocked removes users whose last heartbeat is older than the TTL
+func (h *DashboardHub) expirePresenceLocked(room *dashboardRoom) bool {
+	expired := false
+	cutoff := h.now().Add(-dashboardPresenceTTL)
+	for userID, presence := range room.presence {
+		if presence.LastSeen.Before(cutoff) {
+			delete(room.presence, userID)
+			expired = true
+		}
+	}
+	return expired
+}
+
+func presenceList(room *dashboardRoom) []models.DashboardPresence {
+	list := make([]models.DashboardPresence, 0, len(room.presence))
+	for _, presence := range room.presence {
+		list = append(list, presence)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UserID < list[j].UserID })
+	return list
+}
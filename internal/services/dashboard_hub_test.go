@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	models "narapulse-be/internal/models/entity"
+)
+
+func nextEvent(t *testing.T, events <-chan models.DashboardEvent) models.DashboardEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "event channel closed")
+		return event
+	default:
+		t.Fatal("no event queued")
+		return models.DashboardEvent{}
+	}
+}
+
+func TestDashboardHub_PublishAndPresence(t *testing.T) {
+	hub := NewDashboardHub()
+
+	alice, leaveAlice := hub.Subscribe(1, models.DashboardPresence{UserID: 1, Username: "alice"})
+	event := nextEvent(t, alice)
+	assert.Equal(t, models.DashboardEventPresence, event.Type)
+	assert.Len(t, event.Presence, 1)
+
+	bob, leaveBob := hub.Subscribe(1, models.DashboardPresence{UserID: 2, Username: "bob"})
+	assert.Len(t, nextEvent(t, alice).Presence, 2)
+	assert.Len(t, nextEvent(t, bob).Presence, 2)
+
+	// Rooms are isolated per dashboard
+	other, leaveOther := hub.Subscribe(2, models.DashboardPresence{UserID: 3})
+	nextEvent(t, other)
+	defer leaveOther()
+
+	hub.Publish(models.DashboardEvent{Type: models.DashboardEventWidgetUpdated, DashboardID: 1, UserID: 2, WidgetID: 9})
+	assert.Equal(t, uint(9), nextEvent(t, alice).WidgetID)
+	assert.Equal(t, uint(9), nextEvent(t, bob).WidgetID)
+	assert.Empty(t, other)
+
+	// Selecting a widget is shared with the room
+	assert.True(t, hub.Touch(1, models.DashboardPresence{UserID: 2, SelectedWidget: 9}))
+	presence := nextEvent(t, alice).Presence
+	assert.Equal(t, uint(9), presence[1].SelectedWidget)
+	nextEvent(t, bob)
+	assert.False(t, hub.Touch(1, models.DashboardPresence{UserID: 5}))
+
+	leaveBob()
+	leaveBob()
+	_, open := <-bob
+	assert.False(t, open)
+	assert.Len(t, nextEvent(t, alice).Presence, 1)
+
+	leaveAlice()
+	assert.Empty(t, hub.Presence(1))
+}
+
+func TestDashboardHub_MultipleTabsAndExpiry(t *testing.T) {
+	now := time.Date(2025, 9, 11, 9, 0, 0, 0, time.UTC)
+	hub := NewDashboardHub()
+	hub.now = func() time.Time { return now }
+
+	tab1, leaveTab1 := hub.Subscribe(1, models.DashboardPresence{UserID: 1})
+	tab2, leaveTab2 := hub.Subscribe(1, models.DashboardPresence{UserID: 1})
+	defer leaveTab2()
+	nextEvent(t, tab1)
+	nextEvent(t, tab1)
+	nextEvent(t, tab2)
+
+	// Closing one tab keeps the user present
+	leaveTab1()
+	assert.Len(t, hub.Presence(1), 1)
+	assert.Empty(t, tab2)
+
+	now = now.Add(dashboardPresenceTTL + time.Second)
+	assert.Empty(t, hub.Presence(1))
+	assert.Empty(t, nextEvent(t, tab2).Presence)
+}
+
+func TestDashboardHub_DropsSlowSubscriber(t *testing.T) {
+	hub := NewDashboardHub()
+	events, leave := hub.Subscribe(1, models.DashboardPresence{UserID: 1})
+	defer leave()
+
+	for i := 0; i < dashboardEventBuffer+1; i++ {
+		hub.Publish(models.DashboardEvent{Type: models.DashboardEventWidgetUpdated, DashboardID: 1})
+	}
+
+	count := 0
+	for range events {
+		count++
+	}
+	assert.Equal(t, dashboardEventBuffer, count)
+}
@@ -0,0 +1,481 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDashboardNotFound is returned when the dashboard does not exist or is not shared with the user
+	ErrDashboardNotFound = errors.New("dashboard not found")
+	// ErrDashboardReadOnly is returned when a viewer tries to change a dashboard
+	ErrDashboardReadOnly = errors.New("dashboard is read-only for this user")
+	// ErrDashboardOwnerOnly is returned when a collaborator tries an owner-only action
+	ErrDashboardOwnerOnly = errors.New("only the dashboard owner can do this")
+	// ErrWidgetNotFound is returned when the widget is not on the dashboard
+	ErrWidgetNotFound = errors.New("widget not found")
+	// ErrWidgetVersionConflict is returned when a widget edit is based on an outdated version
+	ErrWidgetVersionConflict = errors.New("widget was changed by another user")
+)
+
+// DashboardService manages dashboards and broadcasts every change to the
+// analysts editing the same dashboard
+type DashboardService struct {
+	db  *gorm.DB
+	hub *DashboardHub
+}
+
+// NewDashboardService creates a new dashboard service
+func NewDashboardService(db *gorm.DB, hub *DashboardHub) *DashboardService {
+	return &DashboardService{
+		db:  db,
+		hub: hub,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *DashboardService) WithContext(ctx context.Context) *DashboardService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// CreateDashboard creates an empty dashboard owned by the user
+func (s *DashboardService) CreateDashboard(userID uint, req *models.DashboardRequest) (*models.Dashboard, error) {
+	dashboard := &models.Dashboard{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.db.Create(dashboard).Error; err != nil {
+		return nil, fmt.Errorf("failed to create dashboard: %w", err)
+	}
+	return dashboard, nil
+}
+
+// ListDashboards returns the dashboards the user owns or collaborates on
+func (s *DashboardService) ListDashboards(userID uint) ([]models.Dashboard, error) {
+	var dashboards []models.Dashboard
+	err := s.db.Where("user_id = ? OR id IN (?)", userID,
+		s.db.Model(&models.DashboardCollaborator{}).Select("dashboard_id").Where("user_id = ?", userID)).
+		Order("updated_at DESC").Find(&dashboards).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dashboards: %w", err)
+	}
+	return dashboards, nil
+}
+
+// GetDashboard returns a dashboard with its widgets in grid order
+func (s *DashboardService) GetDashboard(userID, dashboardID uint) (*models.Dashboard, error) {
+	if _, _, err := s.access(userID, dashboardID); err != nil {
+		return nil, err
+	}
+
+	var dashboard models.Dashboard
+	err := s.db.Preload("Widgets", func(db *gorm.DB) *gorm.DB {
+		return db.Order("y ASC, x ASC, id ASC")
+	}).First(&dashboard, dashboardID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+	return &dashboard, nil
+}
+
+// UpdateDashboard renames a dashboard
+func (s *DashboardService) UpdateDashboard(userID, dashboardID uint, req *models.DashboardRequest) (*models.Dashboard, error) {
+	dashboard, err := s.editable(userID, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard.Name = req.Name
+	dashboard.Description = req.Description
+	if err := s.db.Model(dashboard).Select("name", "description").Updates(dashboard).Error; err != nil {
+		return nil, fmt.Errorf("failed to update dashboard: %w", err)
+	}
+
+	s.publish(models.DashboardEvent{
+		Type:        models.DashboardEventDashboardUpdate,
+		DashboardID: dashboard.ID,
+		UserID:      userID,
+		Dashboard:   dashboard,
+	})
+	return dashboard, nil
+}
+
+// DeleteDashboard deletes a dashboard owned by the user
+func (s *DashboardService) DeleteDashboard(userID, dashboardID uint) error {
+	dashboard, role, err := s.access(userID, dashboardID)
+	if err != nil {
+		return err
+	}
+	if role != "" {
+		return ErrDashboardOwnerOnly
+	}
+
+	if err := s.db.Delete(dashboard).Error; err != nil {
+		return fmt.Errorf("failed to delete dashboard: %w", err)
+	}
+	return nil
+}
+
+// AddWidget places a new widget on the dashboard
+func (s *DashboardService) AddWidget(userID, dashboardID uint, req *models.DashboardWidgetCreateRequest) (*models.DashboardWidget, error) {
+	if _, err := s.editable(userID, dashboardID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWidgetQuery(userID, req.QueryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWidgetExtract(userID, req.ExtractID); err != nil {
+		return nil, err
+	}
+
+	widget := &models.DashboardWidget{
+		DashboardID: dashboardID,
+		QueryID:     req.QueryID,
+		ExtractID:   req.ExtractID,
+		Type:        req.Type,
+		Title:       req.Title,
+		X:           req.X,
+		Y:           req.Y,
+		Width:       req.Width,
+		Height:      req.Height,
+		Version:     1,
+		UpdatedBy:   userID,
+	}
+	if widget.Width == 0 {
+		widget.Width = 4
+	}
+	if widget.Height == 0 {
+		widget.Height = 3
+	}
+	configJSON, _ := json.Marshal(req.Config)
+	widget.Config = models.JSON(configJSON)
+
+	if err := s.db.Create(widget).Error; err != nil {
+		return nil, fmt.Errorf("failed to add widget: %w", err)
+	}
+
+	s.touchDashboard(dashboardID)
+	s.publish(models.DashboardEvent{
+		Type:        models.DashboardEventWidgetAdded,
+		DashboardID: dashboardID,
+		UserID:      userID,
+		Widget:      widget,
+		WidgetID:    widget.ID,
+	})
+	return widget, nil
+}
+
+// UpdateWidget applies a partial edit made against req.BaseVersion. Edits of
+// different widgets never conflict; when another analyst changed the same
+// widget first, ErrWidgetVersionConflict is returned with the latest widget.
+func (s *DashboardService) UpdateWidget(userID, dashboardID, widgetID uint, req *models.DashboardWidgetPatchRequest) (*models.DashboardWidget, error) {
+	if _, err := s.editable(userID, dashboardID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWidgetQuery(userID, req.QueryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkWidgetExtract(userID, req.ExtractID); err != nil {
+		return nil, err
+	}
+
+	updates := widgetPatchUpdates(req)
+	updates["version"] = gorm.Expr("version + 1")
+	updates["updated_by"] = userID
+	updates["updated_at"] = time.Now()
+
+	// The version check and increment happen in one statement, so concurrent
+	// edits of the same widget cannot both succeed
+	result := s.db.Model(&models.DashboardWidget{}).
+		Where("id = ? AND dashboard_id = ? AND version = ?", widgetID, dashboardID, req.BaseVersion).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update widget: %w", result.Error)
+	}
+
+	widget, err := s.getWidget(dashboardID, widgetID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return widget, ErrWidgetVersionConflict
+	}
+
+	s.touchDashboard(dashboardID)
+	s.publish(models.DashboardEvent{
+		Type:        models.DashboardEventWidgetUpdated,
+		DashboardID: dashboardID,
+		UserID:      userID,
+		Widget:      widget,
+		WidgetID:    widget.ID,
+	})
+	return widget, nil
+}
+
+// DeleteWidget removes a widget from the dashboard
+func (s *DashboardService) DeleteWidget(userID, dashboardID, widgetID uint) error {
+	if _, err := s.editable(userID, dashboardID); err != nil {
+		return err
+	}
+
+	result := s.db.Where("id = ? AND dashboard_id = ?", widgetID, dashboardID).Delete(&models.DashboardWidget{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete widget: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWidgetNotFound
+	}
+
+	s.touchDashboard(dashboardID)
+	s.publish(models.DashboardEvent{
+		Type:        models.DashboardEventWidgetRemoved,
+		DashboardID: dashboardID,
+		UserID:      userID,
+		WidgetID:    widgetID,
+	})
+	return nil
+}
+
+// ListCollaborators returns the users a dashboard is shared with
+func (s *DashboardService) ListCollaborators(userID, dashboardID uint) ([]models.DashboardCollaborator, error) {
+	if _, _, err := s.access(userID, dashboardID); err != nil {
+		return nil, err
+	}
+
+	var collaborators []models.DashboardCollaborator
+	if err := s.db.Where("dashboard_id = ?", dashboardID).Order("created_at ASC").Find(&collaborators).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+// SetCollaborator shares a dashboard owned by the user or changes a collaborator's role
+func (s *DashboardService) SetCollaborator(userID, dashboardID uint, req *models.DashboardCollaboratorRequest) (*models.DashboardCollaborator, error) {
+	dashboard, err := s.owned(userID, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == dashboard.UserID {
+		return nil, errors.New("the owner cannot be added as a collaborator")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ? AND is_active = ?", req.UserID, true).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check user: %w", err)
+	}
+	if count == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	collaborator := &models.DashboardCollaborator{
+		DashboardID: dashboardID,
+		UserID:      req.UserID,
+		Role:        req.Role,
+	}
+	if err := s.db.Save(collaborator).Error; err != nil {
+		return nil, fmt.Errorf("failed to save collaborator: %w", err)
+	}
+	return collaborator, nil
+}
+
+// RemoveCollaborator stops sharing a dashboard owned by the user
+func (s *DashboardService) RemoveCollaborator(userID, dashboardID, collaboratorID uint) error {
+	if _, err := s.owned(userID, dashboardID); err != nil {
+		return err
+	}
+
+	if err := s.db.Where("dashboard_id = ? AND user_id = ?", dashboardID, collaboratorID).
+		Delete(&models.DashboardCollaborator{}).Error; err != nil {
+		return fmt.Errorf("failed to remove collaborator: %w", err)
+	}
+	return nil
+}
+
+// Join opens a realtime connection to the dashboard room
+func (s *DashboardService) Join(userID, dashboardID uint) (<-chan models.DashboardEvent, func(), error) {
+	if _, _, err := s.access(userID, dashboardID); err != nil {
+		return nil, nil, err
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "username").First(&user, userID).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	events, leave := s.hub.Subscribe(dashboardID, models.DashboardPresence{UserID: userID, Username: user.Username})
+	return events, leave, nil
+}
+
+// Heartbeat keeps a connected user present and shares the widget they are editing
+func (s *DashboardService) Heartbeat(userID, dashboardID uint, req *models.DashboardPresenceRequest) ([]models.DashboardPresence, error) {
+	if _, _, err := s.access(userID, dashboardID); err != nil {
+		return nil, err
+	}
+	if !s.hub.Touch(dashboardID, models.DashboardPresence{UserID: userID, SelectedWidget: req.SelectedWidget}) {
+		return nil, errors.New("open the dashboard live stream before sending presence")
+	}
+	return s.hub.Presence(dashboardID), nil
+}
+
+// KeepAlive refreshes the presence of a user with an open live stream
+func (s *DashboardService) KeepAlive(userID, dashboardID uint) {
+	s.hub.KeepAlive(dashboardID, userID)
+}
+
+// Presence lists the analysts currently on a dashboard
+func (s *DashboardService) Presence(userID, dashboardID uint) ([]models.DashboardPresence, error) {
+	if _, _, err := s.access(userID, dashboardID); err != nil {
+		return nil, err
+	}
+	return s.hub.Presence(dashboardID), nil
+}
+
+// access returns the dashboard and the collaborator role of the user; the
+// role is empty for the owner
+func (s *DashboardService) access(userID, dashboardID uint) (*models.Dashboard, models.DashboardRole, error) {
+	var dashboard models.Dashboard
+	if err := s.db.First(&dashboard, dashboardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrDashboardNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get dashboard: %w", err)
+	}
+	if dashboard.UserID == userID {
+		return &dashboard, "", nil
+	}
+
+	var collaborator models.DashboardCollaborator
+	if err := s.db.Where("dashboard_id = ? AND user_id = ?", dashboardID, userID).First(&collaborator).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrDashboardNotFound
+		}
+		return nil, "", fmt.Errorf("failed to check dashboard access: %w", err)
+	}
+	return &dashboard, collaborator.Role, nil
+}
+
+func (s *DashboardService) editable(userID, dashboardID uint) (*models.Dashboard, error) {
+	dashboard, role, err := s.access(userID, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+	if role == models.DashboardRoleViewer {
+		return nil, ErrDashboardReadOnly
+	}
+	return dashboard, nil
+}
+
+func (s *DashboardService) owned(userID, dashboardID uint) (*models.Dashboard, error) {
+	dashboard, role, err := s.access(userID, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return nil, ErrDashboardOwnerOnly
+	}
+	return dashboard, nil
+}
+
+func (s *DashboardService) getWidget(dashboardID, widgetID uint) (*models.DashboardWidget, error) {
+	var widget models.DashboardWidget
+	if err := s.db.Where("id = ? AND dashboard_id = ?", widgetID, dashboardID).First(&widget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWidgetNotFound
+		}
+		return nil, fmt.Errorf("failed to get widget: %w", err)
+	}
+	return &widget, nil
+}
+
+// checkWidgetQuery makes sure a widget only displays queries of the user
+func (s *DashboardService) checkWidgetQuery(userID uint, queryID *uint) error {
+	if queryID == nil {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.NL2SQLQuery{}).Where("id = ? AND user_id = ?", *queryID, userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check query: %w", err)
+	}
+	if count == 0 {
+		return errors.New("query not found")
+	}
+	return nil
+}
+
+// checkWidgetExtract makes sure a widget only displays extracts of the user;
+// 0 unbinds the extract of a widget
+func (s *DashboardService) checkWidgetExtract(userID uint, extractID *uint) error {
+	if extractID == nil || *extractID == 0 {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&models.Extract{}).Where("id = ? AND user_id = ?", *extractID, userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check extract: %w", err)
+	}
+	if count == 0 {
+		return errors.New("extract not found")
+	}
+	return nil
+}
+
+// touchDashboard bumps UpdatedAt so dashboard lists show recent edits first
+func (s *DashboardService) touchDashboard(dashboardID uint) {
+	s.db.Model(&models.Dashboard{}).Where("id = ?", dashboardID).Update("updated_at", time.Now())
+}
+
+func (s *DashboardService) publish(event models.DashboardEvent) {
+	event.Timestamp = time.Now()
+	s.hub.Publish(event)
+}
+
+// widgetPatchUpdates returns the columns changed by a widget patch
+func widgetPatchUpdates(req *models.DashboardWidgetPatchRequest) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if req.QueryID != nil {
+		updates["query_id"] = *req.QueryID
+	}
+	if req.ExtractID != nil {
+		if *req.ExtractID == 0 {
+			updates["extract_id"] = nil
+		} else {
+			updates["extract_id"] = *req.ExtractID
+		}
+	}
+	if req.Type != nil {
+		updates["type"] = *req.Type
+	}
+	if req.Title != nil {
+		updates["title"] = *req.Title
+	}
+	if req.Config != nil {
+		configJSON, _ := json.Marshal(req.Config)
+		updates["config"] = models.JSON(configJSON)
+	}
+	if req.X != nil {
+		updates["x"] = *req.X
+	}
+	if req.Y != nil {
+		updates["y"] = *req.Y
+	}
+	if req.Width != nil {
+		updates["width"] = *req.Width
+	}
+	if req.Height != nil {
+		updates["height"] = *req.Height
+	}
+	return updates
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestWidgetPatchUpdates(t *testing.T) {
+	x, width := 2, 6
+	title := "Revenue"
+	chart := models.WidgetTypeChart
+
+	updates := widgetPatchUpdates(&models.DashboardWidgetPatchRequest{
+		BaseVersion: 3,
+		X:           &x,
+		Width:       &width,
+		Title:       &title,
+		Type:        &chart,
+		Config:      map[string]interface{}{"chart": "bar"},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"x":      2,
+		"width":  6,
+		"title":  "Revenue",
+		"type":   models.WidgetTypeChart,
+		"config": models.JSON(`{"chart":"bar"}`),
+	}, updates)
+
+	// Extract 0 unbinds the widget from its extract
+	unbind := uint(0)
+	assert.Equal(t, map[string]interface{}{"extract_id": nil},
+		widgetPatchUpdates(&models.DashboardWidgetPatchRequest{BaseVersion: 2, ExtractID: &unbind}))
+
+	// Fields that are not set are left to other analysts' edits
+	assert.Empty(t, widgetPatchUpdates(&models.DashboardWidgetPatchRequest{BaseVersion: 1}))
+}
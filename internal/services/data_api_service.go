@@ -0,0 +1,675 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDataAPINotFound is returned when no active endpoint matches the slug or ID
+	ErrDataAPINotFound = errors.New("data API endpoint not found")
+	// ErrInvalidAPIKey is returned when the API key is missing, unknown or revoked
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrDataAPIRateLimited is returned when the key exceeded the endpoint rate limit
+	ErrDataAPIRateLimited = errors.New("rate limit exceeded")
+	// ErrInvalidDataAPIParams is returned when request parameters do not match the declaration
+	ErrInvalidDataAPIParams = errors.New("invalid parameters")
+)
+
+// dataAPIKeyPrefix marks NaraPulse data API keys
+const dataAPIKeyPrefix = "npk_"
+
+// dataAPICacheMaxEntries bounds the in-memory result cache
+const dataAPICacheMaxEntries = 1000
+
+var (
+	dataAPISlugPattern        = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$`)
+	dataAPIParamNamePattern   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	dataAPIPlaceholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+)
+
+// DataAPIRateStatus describes the rate limit window after a request
+type DataAPIRateStatus struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// dataAPICacheEntry is a cached endpoint result
+type dataAPICacheEntry struct {
+	result    models.DataAPIResult
+	expiresAt time.Time
+}
+
+// dataAPICache holds the cached endpoint results; the copies of the service
+// scoped to a request share it
+type dataAPICache struct {
+	mu      sync.Mutex
+	entries map[string]dataAPICacheEntry
+}
+
+// DataAPIService publishes saved queries as parameterized, API key protected endpoints
+type DataAPIService struct {
+	db            *gorm.DB
+	nl2sqlService *NL2SQLService
+	sqlValidator  *SQLValidatorService
+	cache         *dataAPICache
+}
+
+// NewDataAPIService creates a new data API service
+func NewDataAPIService(db *gorm.DB, nl2sqlService *NL2SQLService) *DataAPIService {
+	return &DataAPIService{
+		db:            db,
+		nl2sqlService: nl2sqlService,
+		sqlValidator:  NewSQLValidatorService(),
+		cache:         &dataAPICache{entries: make(map[string]dataAPICacheEntry)},
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *DataAPIService) WithContext(ctx context.Context) *DataAPIService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sqlService = s.nl2sqlService.WithContext(ctx)
+	return &scoped
+}
+
+// CreateEndpoint publishes a saved query or SQL template under a slug
+func (s *DataAPIService) CreateEndpoint(userID uint, req *models.DataAPIEndpointCreateRequest) (*models.DataAPIEndpointResponse, error) {
+	if !dataAPISlugPattern.MatchString(req.Slug) {
+		return nil, errors.New("slug may only contain lowercase letters, digits and dashes")
+	}
+
+	sqlTemplate := req.SQLTemplate
+	dataSourceID := req.DataSourceID
+	if req.QueryID > 0 {
+		var query models.NL2SQLQuery
+		if err := s.db.Where("id = ? AND user_id = ?", req.QueryID, userID).First(&query).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("saved query not found")
+			}
+			return nil, fmt.Errorf("failed to get saved query: %w", err)
+		}
+		if sqlTemplate == "" {
+			sqlTemplate = query.GeneratedSQL
+		}
+		dataSourceID = query.DataSourceID
+	}
+	if strings.TrimSpace(sqlTemplate) == "" {
+		return nil, errors.New("sql_template or query_id is required")
+	}
+
+	dataSource, err := s.getUserDataSource(userID, dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.nl2sqlService.policyService.RulesForDataSource(dataSource.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateTemplate(sqlTemplate, req.Parameters, models.DialectForDataSourceType(dataSource.Type), rules); err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	if err := s.db.Unscoped().Model(&models.DataAPIEndpoint{}).Where("slug = ?", req.Slug).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check slug: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("slug is already in use")
+	}
+
+	paramsJSON, err := json.Marshal(req.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+
+	endpoint := &models.DataAPIEndpoint{
+		UserID:             userID,
+		DataSourceID:       dataSource.ID,
+		QueryID:            req.QueryID,
+		Slug:               req.Slug,
+		Name:               req.Name,
+		Description:        req.Description,
+		SQLTemplate:        sqlTemplate,
+		Parameters:         models.JSON(paramsJSON),
+		RateLimitPerMinute: valueOrDefault(req.RateLimitPerMinute, 60),
+		CacheTTLSeconds:    valueOrDefault(req.CacheTTLSeconds, 300),
+		MaxRows:            valueOrDefault(req.MaxRows, 1000),
+		IsActive:           true,
+	}
+	if err := s.db.Create(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to create data API endpoint: %w", err)
+	}
+
+	return endpoint.ToResponse(), nil
+}
+
+// ListEndpoints returns the endpoints published by a user
+func (s *DataAPIService) ListEndpoints(userID uint) ([]*models.DataAPIEndpointResponse, error) {
+	var endpoints []models.DataAPIEndpoint
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data API endpoints: %w", err)
+	}
+
+	responses := make([]*models.DataAPIEndpointResponse, len(endpoints))
+	for i := range endpoints {
+		responses[i] = endpoints[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// GetEndpoint returns a single endpoint owned by the user
+func (s *DataAPIService) GetEndpoint(userID, id uint) (*models.DataAPIEndpointResponse, error) {
+	endpoint, err := s.getUserEndpoint(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint.ToResponse(), nil
+}
+
+// UpdateEndpoint updates an endpoint; template changes are re-validated
+func (s *DataAPIService) UpdateEndpoint(userID, id uint, req *models.DataAPIEndpointUpdateRequest) (*models.DataAPIEndpointResponse, error) {
+	endpoint, err := s.getUserEndpoint(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		endpoint.Name = *req.Name
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.RateLimitPerMinute != nil {
+		endpoint.RateLimitPerMinute = *req.RateLimitPerMinute
+	}
+	if req.CacheTTLSeconds != nil {
+		endpoint.CacheTTLSeconds = *req.CacheTTLSeconds
+	}
+	if req.MaxRows != nil {
+		endpoint.MaxRows = *req.MaxRows
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if req.SQLTemplate != nil || req.Parameters != nil {
+		sqlTemplate := endpoint.SQLTemplate
+		if req.SQLTemplate != nil {
+			sqlTemplate = *req.SQLTemplate
+		}
+		params := endpoint.GetParameters()
+		if req.Parameters != nil {
+			params = req.Parameters
+		}
+
+		dataSource, err := s.getUserDataSource(userID, endpoint.DataSourceID)
+		if err != nil {
+			return nil, err
+		}
+		rules, err := s.nl2sqlService.policyService.RulesForDataSource(dataSource.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.validateTemplate(sqlTemplate, params, models.DialectForDataSourceType(dataSource.Type), rules); err != nil {
+			return nil, err
+		}
+
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal parameters: %w", err)
+		}
+		endpoint.SQLTemplate = sqlTemplate
+		endpoint.Parameters = models.JSON(paramsJSON)
+	}
+
+	if err := s.db.Save(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to update data API endpoint: %w", err)
+	}
+
+	return endpoint.ToResponse(), nil
+}
+
+// DeleteEndpoint unpublishes an endpoint and revokes its keys
+func (s *DataAPIService) DeleteEndpoint(userID, id uint) error {
+	endpoint, err := s.getUserEndpoint(userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.DataAPIKey{}).
+			Where("endpoint_id = ? AND revoked_at IS NULL", endpoint.ID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to revoke API keys: %w", err)
+		}
+		if err := tx.Delete(endpoint).Error; err != nil {
+			return fmt.Errorf("failed to delete data API endpoint: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateKey issues a new API key for an endpoint. The plaintext key is only returned here.
+func (s *DataAPIService) CreateKey(userID, endpointID uint, req *models.DataAPIKeyCreateRequest) (*models.DataAPIKeyCreateResponse, error) {
+	endpoint, err := s.getUserEndpoint(userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := dataAPIKeyPrefix + hex.EncodeToString(secret)
+
+	key := models.DataAPIKey{
+		EndpointID: endpoint.ID,
+		Name:       req.Name,
+		KeyPrefix:  plaintext[:len(dataAPIKeyPrefix)+8],
+		KeyHash:    hashAPIKey(plaintext),
+	}
+	if err := s.db.Create(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &models.DataAPIKeyCreateResponse{DataAPIKey: key, Key: plaintext}, nil
+}
+
+// ListKeys returns the keys of an endpoint without their secrets
+func (s *DataAPIService) ListKeys(userID, endpointID uint) ([]models.DataAPIKey, error) {
+	endpoint, err := s.getUserEndpoint(userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []models.DataAPIKey
+	if err := s.db.Where("endpoint_id = ?", endpoint.ID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey revokes an API key of an endpoint
+func (s *DataAPIService) RevokeKey(userID, endpointID, keyID uint) error {
+	endpoint, err := s.getUserEndpoint(userID, endpointID)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.DataAPIKey{}).
+		Where("id = ? AND endpoint_id = ? AND revoked_at IS NULL", keyID, endpoint.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("API key not found")
+	}
+	return nil
+}
+
+// Invoke runs a published endpoint for an API key holder
+func (s *DataAPIService) Invoke(slug, apiKey string, values map[string]string) (*models.DataAPIResult, *DataAPIRateStatus, error) {
+	var endpoint models.DataAPIEndpoint
+	if err := s.db.Where("slug = ? AND is_active = ?", slug, true).First(&endpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrDataAPINotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get data API endpoint: %w", err)
+	}
+
+	key, err := s.authenticate(endpoint.ID, apiKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rateStatus, err := s.checkRateLimit(&endpoint, key)
+	if err != nil {
+		return nil, rateStatus, err
+	}
+
+	var dataSource models.DataSource
+	if err := s.db.First(&dataSource, endpoint.DataSourceID).Error; err != nil {
+		return nil, rateStatus, fmt.Errorf("failed to get data source: %w", err)
+	}
+	dialect := models.DialectForDataSourceType(dataSource.Type)
+
+	sql, err := renderDataAPITemplate(endpoint.SQLTemplate, endpoint.GetParameters(), values, dialect)
+	if err != nil {
+		return nil, rateStatus, err
+	}
+
+	// Updating the endpoint changes UpdatedAt, which invalidates earlier cache entries
+	cacheKey := fmt.Sprintf("%d:%d:%s", endpoint.ID, endpoint.UpdatedAt.UnixNano(), sql)
+	if cached, ok := s.cacheGet(cacheKey); ok {
+		return cached, rateStatus, nil
+	}
+
+	rules, err := s.nl2sqlService.policyService.RulesForDataSource(dataSource.ID)
+	if err != nil {
+		return nil, rateStatus, err
+	}
+	validation, err := s.sqlValidator.ValidateSQLWithRules(sql, dialect, rules)
+	if err != nil {
+		return nil, rateStatus, fmt.Errorf("endpoint query failed validation: %w", err)
+	}
+	if !validation.IsValid {
+		return nil, rateStatus, fmt.Errorf("endpoint query failed validation: %s", strings.Join(validation.Violations, "; "))
+	}
+	sql, err = s.sqlValidator.EnforceLimitForDialect(sql, rules.RowLimit(endpoint.MaxRows), dialect)
+	if err != nil {
+		return nil, rateStatus, fmt.Errorf("failed to enforce row limit: %w", err)
+	}
+
+	// Endpoint queries run under the owner's cost ceiling
+	if _, err := s.nl2sqlService.checkQueryCost(endpoint.UserID, &dataSource, sql); err != nil {
+		return nil, rateStatus, err
+	}
+
+	queryResult, err := s.nl2sqlService.executeQueryOnDataSource(&dataSource, sql, endpoint.MaxRows)
+	if err != nil {
+		return nil, rateStatus, fmt.Errorf("failed to execute endpoint query: %w", err)
+	}
+
+	// API key holders are not users, so PII is always masked
+	data, maskedColumns := s.nl2sqlService.piiMasker.MaskRows(dataSource.ID, queryResult.Columns, queryResult.Data)
+
+	result := &models.DataAPIResult{
+		Columns:       queryResult.Columns,
+		Data:          data,
+		RowCount:      len(queryResult.Data),
+		GeneratedAt:   time.Now(),
+		MaskedColumns: maskedColumns,
+	}
+	if endpoint.CacheTTLSeconds > 0 {
+		s.cacheSet(cacheKey, *result, time.Duration(endpoint.CacheTTLSeconds)*time.Second)
+	}
+
+	return result, rateStatus, nil
+}
+
+// authenticate resolves an active API key of the endpoint
+func (s *DataAPIService) authenticate(endpointID uint, apiKey string) (*models.DataAPIKey, error) {
+	if !strings.HasPrefix(apiKey, dataAPIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var key models.DataAPIKey
+	if err := s.db.Where("endpoint_id = ? AND key_hash = ? AND revoked_at IS NULL", endpointID, hashAPIKey(apiKey)).
+		First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to check API key: %w", err)
+	}
+
+	s.db.Model(&key).Update("last_used_at", time.Now())
+	return &key, nil
+}
+
+// checkRateLimit counts the request in the current one minute window. Counters
+// live in the database so the limit holds across instances.
+func (s *DataAPIService) checkRateLimit(endpoint *models.DataAPIEndpoint, key *models.DataAPIKey) (*DataAPIRateStatus, error) {
+	windowStart := time.Now().UTC().Truncate(time.Minute)
+
+	var count int
+	if err := s.db.Raw(`
+		INSERT INTO data_api_rate_windows (endpoint_id, api_key_id, window_start, request_count)
+		VALUES (?, ?, ?, 1)
+		ON CONFLICT (endpoint_id, api_key_id, window_start)
+		DO UPDATE SET request_count = data_api_rate_windows.request_count + 1
+		RETURNING request_count`, endpoint.ID, key.ID, windowStart).
+		Scan(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	// First request of a new window: drop the previous windows of this key
+	if count == 1 {
+		s.db.Where("endpoint_id = ? AND api_key_id = ? AND window_start < ?", endpoint.ID, key.ID, windowStart).
+			Delete(&models.DataAPIRateWindow{})
+	}
+
+	status := &DataAPIRateStatus{
+		Limit:     endpoint.RateLimitPerMinute,
+		Remaining: endpoint.RateLimitPerMinute - count,
+		ResetAt:   windowStart.Add(time.Minute),
+	}
+	if status.Remaining < 0 {
+		status.Remaining = 0
+		return status, ErrDataAPIRateLimited
+	}
+	return status, nil
+}
+
+// validateTemplate checks that every placeholder is declared outside string
+// literals and that the template renders to a safe SELECT in the dialect
+// under the validation policy of the data source
+func (s *DataAPIService) validateTemplate(sqlTemplate string, params []models.DataAPIParameter, dialect models.SQLDialect, rules *ValidationRules) error {
+	declared := make(map[string]bool, len(params))
+	for _, param := range params {
+		if !dataAPIParamNamePattern.MatchString(param.Name) {
+			return fmt.Errorf("invalid parameter name: %s", param.Name)
+		}
+		if declared[param.Name] {
+			return fmt.Errorf("duplicate parameter: %s", param.Name)
+		}
+		declared[param.Name] = true
+	}
+
+	for _, match := range dataAPIPlaceholderPattern.FindAllStringSubmatch(sqlTemplate, -1) {
+		if !declared[match[1]] {
+			return fmt.Errorf("placeholder {{%s}} is not declared in parameters", match[1])
+		}
+	}
+
+	tokens, err := tokenizeSQL(sqlTemplate, dialect)
+	if err != nil {
+		return fmt.Errorf("invalid SQL template: %w", err)
+	}
+	for _, t := range tokens {
+		if t.kind == sqlTokenString && dataAPIPlaceholderPattern.MatchString(t.text) {
+			return errors.New("placeholders must be used as values, not inside string literals")
+		}
+	}
+
+	// Render with sample values so the validator sees a complete query
+	samples := make(map[string]string, len(params))
+	for _, param := range params {
+		samples[param.Name] = sampleParamValue(param.Type)
+	}
+	sql, err := renderDataAPITemplate(sqlTemplate, params, samples, dialect)
+	if err != nil {
+		return err
+	}
+
+	validation, err := s.sqlValidator.ValidateSQLWithRules(sql, dialect, rules)
+	if err != nil {
+		return fmt.Errorf("SQL template failed validation: %w", err)
+	}
+	if !validation.IsValid {
+		return fmt.Errorf("SQL template failed validation: %s", strings.Join(validation.Violations, "; "))
+	}
+	return nil
+}
+
+// getUserEndpoint loads an endpoint owned by the user
+func (s *DataAPIService) getUserEndpoint(userID, id uint) (*models.DataAPIEndpoint, error) {
+	var endpoint models.DataAPIEndpoint
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&endpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDataAPINotFound
+		}
+		return nil, fmt.Errorf("failed to get data API endpoint: %w", err)
+	}
+	return &endpoint, nil
+}
+
+// getUserDataSource loads a data source owned by the user
+func (s *DataAPIService) getUserDataSource(userID, dataSourceID uint) (*models.DataSource, error) {
+	if dataSourceID == 0 {
+		return nil, errors.New("data_source_id is required")
+	}
+
+	var dataSource models.DataSource
+	if err := s.db.Where("id = ? AND user_id = ?", dataSourceID, userID).First(&dataSource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("data source not found")
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+	return &dataSource, nil
+}
+
+func (s *DataAPIService) cacheGet(key string) (*models.DataAPIResult, bool) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	entry, ok := s.cache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(s.cache.entries, key)
+		return nil, false
+	}
+	result := entry.result
+	result.Cached = true
+	return &result, true
+}
+
+func (s *DataAPIService) cacheSet(key string, result models.DataAPIResult, ttl time.Duration) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	if len(s.cache.entries) >= dataAPICacheMaxEntries {
+		now := time.Now()
+		for k, entry := range s.cache.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.cache.entries, k)
+			}
+		}
+		if len(s.cache.entries) >= dataAPICacheMaxEntries {
+			s.cache.entries = make(map[string]dataAPICacheEntry)
+		}
+	}
+	s.cache.entries[key] = dataAPICacheEntry{result: result, expiresAt: time.Now().Add(ttl)}
+}
+
+// renderDataAPITemplate replaces {{name}} placeholders with typed, quoted literals
+func renderDataAPITemplate(sqlTemplate string, params []models.DataAPIParameter, values map[string]string, dialect models.SQLDialect) (string, error) {
+	literals := make(map[string]string, len(params))
+	var problems []string
+	for _, param := range params {
+		value, ok := values[param.Name]
+		if !ok || value == "" {
+			value = param.Default
+		}
+		if value == "" {
+			if param.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", param.Name))
+				continue
+			}
+			literals[param.Name] = "NULL"
+			continue
+		}
+
+		literal, err := formatParamLiteral(param.Type, value, dialect)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", param.Name, err))
+			continue
+		}
+		literals[param.Name] = literal
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return "", fmt.Errorf("%w: %s", ErrInvalidDataAPIParams, strings.Join(problems, "; "))
+	}
+
+	return dataAPIPlaceholderPattern.ReplaceAllStringFunc(sqlTemplate, func(placeholder string) string {
+		name := dataAPIPlaceholderPattern.FindStringSubmatch(placeholder)[1]
+		return literals[name]
+	}), nil
+}
+
+// formatParamLiteral converts a request value into a SQL literal of the declared type
+func formatParamLiteral(paramType models.DataAPIParamType, value string, dialect models.SQLDialect) (string, error) {
+	switch paramType {
+	case models.DataAPIParamInteger:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", errors.New("must be an integer")
+		}
+		return strconv.FormatInt(n, 10), nil
+	case models.DataAPIParamNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", errors.New("must be a number")
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case models.DataAPIParamBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", errors.New("must be a boolean")
+		}
+		if b {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case models.DataAPIParamDate:
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", errors.New("must be a date (YYYY-MM-DD)")
+		}
+		return "DATE '" + d.Format("2006-01-02") + "'", nil
+	default:
+		return quoteStringLiteral(value, dialect), nil
+	}
+}
+
+// sampleParamValue returns a valid value of the type, used to validate templates
+func sampleParamValue(paramType models.DataAPIParamType) string {
+	switch paramType {
+	case models.DataAPIParamInteger, models.DataAPIParamNumber:
+		return "1"
+	case models.DataAPIParamBoolean:
+		return "true"
+	case models.DataAPIParamDate:
+		return "2000-01-01"
+	default:
+		return "sample"
+	}
+}
+
+// hashAPIKey returns the hex SHA-256 of an API key
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// valueOrDefault returns value unless it is zero
+func valueOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestRenderDataAPITemplate(t *testing.T) {
+	params := []models.DataAPIParameter{
+		{Name: "region", Type: models.DataAPIParamString, Required: true},
+		{Name: "min_total", Type: models.DataAPIParamNumber, Default: "0"},
+		{Name: "since", Type: models.DataAPIParamDate},
+	}
+	template := "SELECT * FROM orders WHERE region = {{region}} AND total >= {{ min_total }} AND created_at >= {{since}}"
+
+	sql, err := renderDataAPITemplate(template, params, map[string]string{"region": "O'Hare", "since": "2024-01-31"}, models.SQLDialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM orders WHERE region = 'O''Hare' AND total >= 0 AND created_at >= DATE '2024-01-31'", sql)
+
+	sql, err = renderDataAPITemplate(template, params, map[string]string{"region": "north"}, models.SQLDialectPostgreSQL)
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "created_at >= NULL")
+
+	_, err = renderDataAPITemplate(template, params, map[string]string{"min_total": "abc"}, models.SQLDialectPostgreSQL)
+	assert.True(t, errors.Is(err, ErrInvalidDataAPIParams))
+	assert.Contains(t, err.Error(), "region is required")
+	assert.Contains(t, err.Error(), "min_total: must be a number")
+}
+
+func TestFormatParamLiteral(t *testing.T) {
+	tests := []struct {
+		paramType models.DataAPIParamType
+		value     string
+		dialect   models.SQLDialect
+		expected  string
+		wantErr   bool
+	}{
+		{models.DataAPIParamInteger, "42", models.SQLDialectPostgreSQL, "42", false},
+		{models.DataAPIParamInteger, "1; DROP TABLE users", models.SQLDialectPostgreSQL, "", true},
+		{models.DataAPIParamNumber, "NaN", models.SQLDialectPostgreSQL, "", true},
+		{models.DataAPIParamBoolean, "1", models.SQLDialectPostgreSQL, "TRUE", false},
+		{models.DataAPIParamDate, "2024-13-01", models.SQLDialectPostgreSQL, "", true},
+		{models.DataAPIParamString, `it's`, models.SQLDialectDuckDB, `'it''s'`, false},
+		{models.DataAPIParamString, `a\' OR 1=1`, models.SQLDialectBigQuery, `'a\\\' OR 1=1'`, false},
+	}
+
+	for _, tt := range tests {
+		literal, err := formatParamLiteral(tt.paramType, tt.value, tt.dialect)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, literal)
+	}
+}
+
+func TestDataAPIService_ValidateTemplate(t *testing.T) {
+	service := &DataAPIService{sqlValidator: NewSQLValidatorService()}
+	params := []models.DataAPIParameter{{Name: "region", Type: models.DataAPIParamString}}
+
+	assert.NoError(t, service.validateTemplate("SELECT id FROM orders WHERE region = {{region}}", params, models.SQLDialectPostgreSQL, nil))
+	assert.Error(t, service.validateTemplate("SELECT id FROM orders WHERE region = {{country}}", params, models.SQLDialectPostgreSQL, nil))
+	assert.Error(t, service.validateTemplate("SELECT id FROM orders WHERE region LIKE '%{{region}}%'", params, models.SQLDialectPostgreSQL, nil))
+	assert.Error(t, service.validateTemplate("DELETE FROM orders WHERE region = {{region}}", params, models.SQLDialectPostgreSQL, nil))
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// profileSampleRows is the number of rows sampled to profile a table
+	profileSampleRows = 1000
+	// profileCacheTTL is how long a profile is served from cache unless a refresh is asked for
+	profileCacheTTL = time.Hour
+	// profileTopValues is the number of most frequent values reported per column
+	profileTopValues = 5
+	// profileHistogramBuckets is the number of equal-width buckets of numeric histograms
+	profileHistogramBuckets = 10
+)
+
+var (
+	// ErrProfileDataSourceNotFound is returned when the user does not own the data source
+	ErrProfileDataSourceNotFound = errors.New("data source not found")
+	// ErrSchemaNotFound is returned when the schema does not belong to the data source
+	ErrSchemaNotFound = errors.New("schema not found")
+)
+
+// DataProfileService profiles the columns of a table over a sample of its rows
+type DataProfileService struct {
+	db           *gorm.DB
+	connectorSvc *connectorService
+	piiMasker    *PIIMaskingService
+	inference    *SchemaInferenceService
+}
+
+// NewDataProfileService creates a new data profile service
+func NewDataProfileService(db *gorm.DB, connectorSvc *connectorService, piiMasker *PIIMaskingService) *DataProfileService {
+	return &DataProfileService{
+		db:           db,
+		connectorSvc: connectorSvc,
+		piiMasker:    piiMasker,
+		inference:    NewSchemaInferenceService(),
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *DataProfileService) WithContext(ctx context.Context) *DataProfileService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.piiMasker = s.piiMasker.WithContext(ctx)
+	return &scoped
+}
+
+// GetProfile returns the profile of a schema of a data source owned by the
+// user, from cache when it is recent enough and refresh is not asked for
+func (s *DataProfileService) GetProfile(userID, dataSourceID, schemaID uint, refresh bool) (*models.SchemaProfileResponse, error) {
+	var dataSource models.DataSource
+	if err := s.db.Where("id = ? AND user_id = ?", dataSourceID, userID).First(&dataSource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProfileDataSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+
+	var schema models.Schema
+	if err := s.db.Where("id = ? AND data_source_id = ?", schemaID, dataSource.ID).First(&schema).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSchemaNotFound
+		}
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	if !refresh {
+		var cached models.SchemaProfile
+		err := s.db.Where("schema_id = ?", schema.ID).First(&cached).Error
+		if err == nil && time.Since(cached.UpdatedAt) < profileCacheTTL {
+			var profile models.SchemaProfileResponse
+			if err := json.Unmarshal(cached.Profile, &profile); err == nil {
+				profile.Cached = true
+				return &profile, nil
+			}
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get cached profile: %w", err)
+		}
+	}
+
+	rows, source, err := s.sampleRows(&dataSource, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []models.Column
+	if len(schema.Columns) > 0 {
+		if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+			return nil, fmt.Errorf("failed to parse columns: %w", err)
+		}
+	}
+
+	profile := &models.SchemaProfileResponse{
+		SchemaID:     schema.ID,
+		DataSourceID: dataSource.ID,
+		Table:        schema.Name,
+		Source:       source,
+		SampledRows:  len(rows),
+		Columns:      make([]models.ColumnProfile, 0, len(columns)),
+		ProfiledAt:   time.Now(),
+	}
+	piiColumns := make(map[string]bool)
+	for _, name := range s.piiMasker.PIIColumns(dataSource.ID, columns, rows) {
+		piiColumns[name] = true
+	}
+	for _, col := range columns {
+		columnProfile := s.profileColumn(col, rows)
+		if piiColumns[col.Name] {
+			withoutValues(&columnProfile)
+		}
+		profile.Columns = append(profile.Columns, columnProfile)
+	}
+
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	cached := &models.SchemaProfile{TenantID: dataSource.TenantID, SchemaID: schema.ID, DataSourceID: dataSource.ID, Profile: models.JSON(profileJSON)}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "schema_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"profile", "updated_at"}),
+	}).Create(cached).Error; err != nil {
+		return nil, fmt.Errorf("failed to cache profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// sampleRows samples the table from the data source. Sources without a
+// connector (files and REST APIs) are profiled over their stored sample rows.
+func (s *DataProfileService) sampleRows(dataSource *models.DataSource, schema *models.Schema) ([]map[string]interface{}, string, error) {
+	switch dataSource.Type {
+	case models.DataSourceTypePostgreSQL, models.DataSourceTypeBigQuery, models.DataSourceTypeGoogleSheets,
+		models.DataSourceTypeMongoDB, models.DataSourceTypeGA4:
+		var config map[string]interface{}
+		if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+			return nil, "", fmt.Errorf("invalid data source configuration: %w", err)
+		}
+		rows, err := s.connectorSvc.SampleDataSourceTable(dataSource.ID, dataSource.Type, config, schema.Name, profileSampleRows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to sample %s: %w", schema.Name, err)
+		}
+		return rows, models.ProfileSourceLive, nil
+	}
+
+	var rows []map[string]interface{}
+	if len(schema.SampleData) > 0 {
+		if err := json.Unmarshal(schema.SampleData, &rows); err != nil {
+			return nil, "", fmt.Errorf("failed to parse sample data: %w", err)
+		}
+	}
+	return rows, models.ProfileSourceStoredSample, nil
+}
+
+// profileColumn computes the profile of a column over the sampled rows
+func (s *DataProfileService) profileColumn(col models.Column, rows []map[string]interface{}) models.ColumnProfile {
+	values := make([]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = row[col.Name]
+	}
+
+	profile := models.ColumnProfile{
+		Name:       col.Name,
+		Type:       col.Type,
+		TotalCount: len(values),
+		TopValues:  []models.ValueCount{},
+	}
+	if len(values) == 0 {
+		return profile
+	}
+
+	quality := s.inference.AnalyzeDataQuality(values)
+	profile.NullCount, _ = quality["null_count"].(int)
+	profile.EmptyCount, _ = quality["empty_count"].(int)
+	profile.DistinctCount, _ = quality["unique_count"].(int)
+	profile.CompletenessPct, _ = quality["completeness_pct"].(float64)
+	profile.UniquenessPct, _ = quality["uniqueness_pct"].(float64)
+
+	var texts []string
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		if text := strings.TrimSpace(fmt.Sprintf("%v", value)); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	profile.TopValues = topValues(texts, profileTopValues)
+
+	if numbers, ok := parseNumbers(texts); ok {
+		minValue, maxValue := numbers[0], numbers[0]
+		for _, n := range numbers {
+			minValue = math.Min(minValue, n)
+			maxValue = math.Max(maxValue, n)
+		}
+		profile.Min = strconv.FormatFloat(minValue, 'f', -1, 64)
+		profile.Max = strconv.FormatFloat(maxValue, 'f', -1, 64)
+		profile.Histogram = histogram(numbers, minValue, maxValue, profileHistogramBuckets)
+	} else if len(texts) > 0 {
+		// Lexical order, which also orders ISO dates and timestamps
+		sorted := append([]string(nil), texts...)
+		sort.Strings(sorted)
+		profile.Min, profile.Max = sorted[0], sorted[len(sorted)-1]
+	}
+
+	return profile
+}
+
+// withoutValues drops the values of a PII column from its profile, keeping
+// its counts
+func withoutValues(profile *models.ColumnProfile) {
+	profile.PII = true
+	profile.Min, profile.Max = "", ""
+	profile.TopValues = []models.ValueCount{}
+	profile.Histogram = nil
+}
+
+// topValues returns the most frequent values, ties ordered by value
+func topValues(texts []string, limit int) []models.ValueCount {
+	counts := make(map[string]int)
+	for _, text := range texts {
+		counts[text]++
+	}
+
+	top := make([]models.ValueCount, 0, len(counts))
+	for value, count := range counts {
+		top = append(top, models.ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Value < top[j].Value
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// parseNumbers parses the values as numbers; ok is false when any is not a number
+func parseNumbers(texts []string) ([]float64, bool) {
+	if len(texts) == 0 {
+		return nil, false
+	}
+	numbers := make([]float64, 0, len(texts))
+	for _, text := range texts {
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
+
+// histogram counts the numbers in equal-width buckets between min and max.
+// All numbers fall in a single bucket when they are equal.
+func histogram(numbers []float64, minValue, maxValue float64, buckets int) []models.HistogramBucket {
+	if maxValue == minValue {
+		return []models.HistogramBucket{{Lower: minValue, Upper: maxValue, Count: len(numbers)}}
+	}
+
+	width := (maxValue - minValue) / float64(buckets)
+	result := make([]models.HistogramBucket, buckets)
+	for i := range result {
+		result[i].Lower = minValue + float64(i)*width
+		result[i].Upper = minValue + float64(i+1)*width
+	}
+	result[buckets-1].Upper = maxValue
+
+	for _, n := range numbers {
+		i := int((n - minValue) / width)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		result[i].Count++
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileColumnNumeric(t *testing.T) {
+	s := &DataProfileService{inference: NewSchemaInferenceService()}
+	rows := []map[string]interface{}{
+		{"amount": 10.0}, {"amount": 20.0}, {"amount": 20.0}, {"amount": nil}, {"amount": 110.0},
+	}
+
+	profile := s.profileColumn(models.Column{Name: "amount", Type: "numeric"}, rows)
+	assert.Equal(t, 5, profile.TotalCount)
+	assert.Equal(t, 1, profile.NullCount)
+	assert.Equal(t, 3, profile.DistinctCount)
+	assert.InDelta(t, 80.0, profile.CompletenessPct, 0.001)
+	assert.InDelta(t, 60.0, profile.UniquenessPct, 0.001)
+	assert.Equal(t, "10", profile.Min)
+	assert.Equal(t, "110", profile.Max)
+	assert.Equal(t, []models.ValueCount{{Value: "20", Count: 2}, {Value: "10", Count: 1}, {Value: "110", Count: 1}}, profile.TopValues)
+
+	require.Len(t, profile.Histogram, profileHistogramBuckets)
+	assert.Equal(t, 10.0, profile.Histogram[0].Lower)
+	assert.Equal(t, 20.0, profile.Histogram[0].Upper)
+	assert.Equal(t, 1, profile.Histogram[0].Count)
+	assert.Equal(t, 2, profile.Histogram[1].Count)
+	assert.Equal(t, 110.0, profile.Histogram[9].Upper)
+	assert.Equal(t, 1, profile.Histogram[9].Count)
+}
+
+func TestProfileColumnText(t *testing.T) {
+	s := &DataProfileService{inference: NewSchemaInferenceService()}
+	rows := []map[string]interface{}{
+		{"country": "ID"}, {"country": "SG"}, {"country": " "}, {"country": "ID"}, {},
+	}
+
+	profile := s.profileColumn(models.Column{Name: "country", Type: "string"}, rows)
+	assert.Equal(t, 1, profile.NullCount)
+	assert.Equal(t, 1, profile.EmptyCount)
+	assert.Equal(t, "ID", profile.Min)
+	assert.Equal(t, "SG", profile.Max)
+	assert.Equal(t, []models.ValueCount{{Value: "ID", Count: 2}, {Value: "SG", Count: 1}}, profile.TopValues)
+	assert.Empty(t, profile.Histogram)
+}
+
+func TestProfileColumnWithoutRows(t *testing.T) {
+	s := &DataProfileService{inference: NewSchemaInferenceService()}
+
+	profile := s.profileColumn(models.Column{Name: "id", Type: "integer"}, nil)
+	assert.Zero(t, profile.TotalCount)
+	assert.Zero(t, profile.CompletenessPct)
+	assert.Empty(t, profile.TopValues)
+}
+
+func TestWithoutValues(t *testing.T) {
+	s := &DataProfileService{inference: NewSchemaInferenceService()}
+	rows := []map[string]interface{}{{"email": "a@example.com"}, {"email": "b@example.com"}}
+
+	profile := s.profileColumn(models.Column{Name: "email", Type: "string"}, rows)
+	withoutValues(&profile)
+	assert.True(t, profile.PII)
+	assert.Equal(t, 2, profile.DistinctCount)
+	assert.Empty(t, profile.Min)
+	assert.Empty(t, profile.Max)
+	assert.Empty(t, profile.TopValues)
+}
+
+func TestHistogramConstantValues(t *testing.T) {
+	assert.Equal(t, []models.HistogramBucket{{Lower: 3, Upper: 3, Count: 4}}, histogram([]float64{3, 3, 3, 3}, 3, 3, profileHistogramBuckets))
+}
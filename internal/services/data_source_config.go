@@ -0,0 +1,363 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+)
+
+// ErrDataSourceConfigInvalid is returned when a data source declaration cannot
+// be applied: a name is declared twice or matches several data sources, a
+// secret cannot be resolved or a configuration is invalid
+var ErrDataSourceConfigInvalid = errors.New("invalid data source declaration")
+
+// DataSourceConfigApplyError is returned when applying a change failed. The
+// plan marks the changes applied before it.
+type DataSourceConfigApplyError struct {
+	Plan *models.DataSourceConfigPlan
+	Err  error
+}
+
+func (e *DataSourceConfigApplyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DataSourceConfigApplyError) Unwrap() error {
+	return e.Err
+}
+
+// secretRefEnvPrefix marks secret references read from the environment
+const secretRefEnvPrefix = "env:"
+
+// serverConfigFields are written to the configuration of Google Drive and REST
+// API data sources when their data is downloaded. Unless declared, they are
+// kept on updates and not reported as removed.
+var serverConfigFields = map[string]bool{
+	"file_path":       true,
+	"file_name":       true,
+	"file_size":       true,
+	"revision_id":     true,
+	"materialized_at": true,
+	"checked_at":      true,
+}
+
+// declaredDataSource is a data source declaration with its secrets resolved
+// and its configuration validated
+type declaredDataSource struct {
+	spec   models.DataSourceSpec
+	config map[string]interface{}
+}
+
+// ApplyDataSourceConfig converges the data sources of the user to the
+// declaration: declared data sources that do not exist are created, those that
+// differ are updated and those not declared are deleted; they can be restored
+// within the restore window. With dryRun, the plan is returned without
+// applying it. Every declaration is checked before anything is changed.
+func (s *dataSourceService) ApplyDataSourceConfig(userID uint, req *models.DataSourceConfigRequest, dryRun bool) (*models.DataSourceConfigPlan, error) {
+	declared := make([]declaredDataSource, 0, len(req.DataSources))
+	names := make(map[string]bool, len(req.DataSources))
+	for _, spec := range req.DataSources {
+		if names[spec.Name] {
+			return nil, fmt.Errorf("%w: %s is declared more than once", ErrDataSourceConfigInvalid, spec.Name)
+		}
+		names[spec.Name] = true
+
+		config, err := s.prepareDeclaredConfig(userID, spec)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrDataSourceConfigInvalid, spec.Name, err)
+		}
+		declared = append(declared, declaredDataSource{spec: spec, config: config})
+	}
+
+	existing, err := s.dataSourceRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data sources: %w", err)
+	}
+
+	changes, err := planDataSourceConfig(existing, declared)
+	if err != nil {
+		return nil, err
+	}
+	plan := &models.DataSourceConfigPlan{DryRun: dryRun, Changes: changes}
+	for _, change := range changes {
+		switch change.Action {
+		case models.DataSourceConfigActionCreate:
+			plan.Created++
+		case models.DataSourceConfigActionUpdate:
+			plan.Updated++
+		case models.DataSourceConfigActionReplace:
+			plan.Replaced++
+		case models.DataSourceConfigActionDelete:
+			plan.Deleted++
+		default:
+			plan.Unchanged++
+		}
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	byName := make(map[string]declaredDataSource, len(declared))
+	for _, d := range declared {
+		byName[d.spec.Name] = d
+	}
+	byID := make(map[uint]*models.DataSource, len(existing))
+	for i := range existing {
+		byID[existing[i].ID] = &existing[i]
+	}
+
+	for i := range plan.Changes {
+		change := &plan.Changes[i]
+		if err := s.applyDataSourceConfigChange(userID, change, byName[change.Name], byID[change.DataSourceID]); err != nil {
+			return nil, &DataSourceConfigApplyError{Plan: plan, Err: fmt.Errorf("failed to %s %s: %w", change.Action, change.Name, err)}
+		}
+	}
+	return plan, nil
+}
+
+// applyDataSourceConfigChange applies one change of a plan
+func (s *dataSourceService) applyDataSourceConfigChange(userID uint, change *models.DataSourceConfigChange, declared declaredDataSource, dataSource *models.DataSource) error {
+	switch change.Action {
+	case models.DataSourceConfigActionUnchanged:
+		return nil
+	case models.DataSourceConfigActionDelete:
+		if err := s.DeleteDataSource(change.DataSourceID, userID, false); err != nil {
+			return err
+		}
+	case models.DataSourceConfigActionReplace, models.DataSourceConfigActionCreate:
+		if change.Action == models.DataSourceConfigActionReplace {
+			if err := s.DeleteDataSource(change.DataSourceID, userID, false); err != nil {
+				return err
+			}
+			change.ReplacedID = change.DataSourceID
+		}
+		created, err := s.CreateDataSource(userID, &models.DataSourceCreateRequest{
+			Name:        declared.spec.Name,
+			Description: declared.spec.Description,
+			Type:        declared.spec.Type,
+			Config:      declared.config,
+		})
+		if err != nil {
+			return err
+		}
+		change.DataSourceID = created.ID
+	case models.DataSourceConfigActionUpdate:
+		configChanged := false
+		for _, field := range change.Fields {
+			configChanged = configChanged || strings.HasPrefix(field, "config.")
+		}
+		dataSource.Description = declared.spec.Description
+		if configChanged {
+			config, err := keepServerConfigFields(dataSource.Config, declared.config)
+			if err != nil {
+				return err
+			}
+			configJSON, err := json.Marshal(config)
+			if err != nil {
+				return fmt.Errorf("failed to marshal config: %w", err)
+			}
+			dataSource.Config = models.JSON(configJSON)
+			dataSource.Status = models.ConnectionStatusInactive
+		}
+		if err := s.dataSourceRepo.Update(dataSource); err != nil {
+			return fmt.Errorf("failed to update data source: %w", err)
+		}
+		if configChanged {
+			s.connectorSvc.InvalidateConnection(dataSource.ID)
+			if err := s.enqueueDiscovery(s.ctx, dataSource); err != nil {
+				logger.L().Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to queue schema discovery")
+			}
+		}
+	}
+	change.Applied = true
+	return nil
+}
+
+// prepareDeclaredConfig resolves the secrets of a declaration into its
+// configuration and validates it like a created data source
+func (s *dataSourceService) prepareDeclaredConfig(userID uint, spec models.DataSourceSpec) (map[string]interface{}, error) {
+	config := make(map[string]interface{}, len(spec.Config)+len(spec.Secrets))
+	for field, value := range spec.Config {
+		config[field] = value
+	}
+	if err := resolveSecretRefs(config, spec.Secrets, s.secretEnvPrefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := s.attachCredentials(userID, spec.Type, config); err != nil {
+		return nil, err
+	}
+	if err := s.validateConfig(spec.Type, config); err != nil {
+		return nil, err
+	}
+	return normalizeConfig(config)
+}
+
+// resolveSecretRefs sets the config fields of the secrets to the values they
+// reference. Only env:NAME references to environment variables starting with
+// the prefix are resolved, so a declaration cannot read other settings of the
+// server; without a prefix, secret references are disabled.
+func resolveSecretRefs(config map[string]interface{}, secrets map[string]string, envPrefix string, lookupEnv func(string) (string, bool)) error {
+	fields := make([]string, 0, len(secrets))
+	for field := range secrets {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		ref := secrets[field]
+		name, ok := strings.CutPrefix(ref, secretRefEnvPrefix)
+		if !ok {
+			return fmt.Errorf("secret %s: unsupported reference %q, expected env:NAME", field, ref)
+		}
+		if envPrefix == "" {
+			return fmt.Errorf("secret %s: secret references are disabled", field)
+		}
+		if !strings.HasPrefix(name, envPrefix) {
+			return fmt.Errorf("secret %s: environment variable %s does not start with %s", field, name, envPrefix)
+		}
+		value, ok := lookupEnv(name)
+		if !ok || value == "" {
+			return fmt.Errorf("secret %s: environment variable %s is not set", field, name)
+		}
+		config[field] = value
+	}
+	return nil
+}
+
+// normalizeConfig round-trips a configuration through JSON, so it compares
+// equal to a stored one with the same values
+func normalizeConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return normalized, nil
+}
+
+// planDataSourceConfig diffs the data sources of a user against a declaration:
+// the declared data sources in order, then the deleted ones by ID
+func planDataSourceConfig(existing []models.DataSource, declared []declaredDataSource) ([]models.DataSourceConfigChange, error) {
+	existingByName := make(map[string]*models.DataSource, len(existing))
+	for i := range existing {
+		name := existing[i].Name
+		if _, ok := existingByName[name]; ok && declaredName(declared, name) {
+			return nil, fmt.Errorf("%w: several data sources are named %s, rename or delete all but one first", ErrDataSourceConfigInvalid, name)
+		}
+		existingByName[name] = &existing[i]
+	}
+
+	changes := make([]models.DataSourceConfigChange, 0, len(declared)+len(existing))
+	matched := make(map[uint]bool, len(declared))
+	for _, d := range declared {
+		change := models.DataSourceConfigChange{Name: d.spec.Name, Type: d.spec.Type}
+		dataSource, ok := existingByName[d.spec.Name]
+		switch {
+		case !ok:
+			change.Action = models.DataSourceConfigActionCreate
+		case dataSource.Type != d.spec.Type:
+			change.Action = models.DataSourceConfigActionReplace
+			change.DataSourceID = dataSource.ID
+			change.Fields = []string{"type"}
+		default:
+			change.DataSourceID = dataSource.ID
+			fields, err := changedDataSourceFields(dataSource, d)
+			if err != nil {
+				return nil, err
+			}
+			change.Fields = fields
+			change.Action = models.DataSourceConfigActionUnchanged
+			if len(fields) > 0 {
+				change.Action = models.DataSourceConfigActionUpdate
+			}
+		}
+		if ok {
+			matched[dataSource.ID] = true
+		}
+		changes = append(changes, change)
+	}
+
+	var deleted []models.DataSourceConfigChange
+	for _, dataSource := range existing {
+		if !matched[dataSource.ID] {
+			deleted = append(deleted, models.DataSourceConfigChange{
+				Action:       models.DataSourceConfigActionDelete,
+				Name:         dataSource.Name,
+				Type:         dataSource.Type,
+				DataSourceID: dataSource.ID,
+			})
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i].DataSourceID < deleted[j].DataSourceID })
+	return append(changes, deleted...), nil
+}
+
+// changedDataSourceFields returns the fields of a data source that differ from
+// its declaration, sorted: description and config.<field> for each config
+// field added, changed or removed
+func changedDataSourceFields(dataSource *models.DataSource, declared declaredDataSource) ([]string, error) {
+	var fields []string
+	if dataSource.Description != declared.spec.Description {
+		fields = append(fields, "description")
+	}
+
+	current := map[string]interface{}{}
+	if len(dataSource.Config) > 0 {
+		if err := json.Unmarshal(dataSource.Config, &current); err != nil {
+			return nil, fmt.Errorf("invalid stored configuration of %s: %w", dataSource.Name, err)
+		}
+	}
+	for field, value := range declared.config {
+		if currentValue, ok := current[field]; !ok || !reflect.DeepEqual(currentValue, value) {
+			fields = append(fields, "config."+field)
+		}
+	}
+	for field := range current {
+		if _, ok := declared.config[field]; !ok && !serverConfigFields[field] {
+			fields = append(fields, "config."+field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// keepServerConfigFields returns the declared configuration with the server
+// fields of the stored one it does not declare
+func keepServerConfigFields(stored models.JSON, declared map[string]interface{}) (map[string]interface{}, error) {
+	current := map[string]interface{}{}
+	if len(stored) > 0 {
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return nil, fmt.Errorf("invalid stored configuration: %w", err)
+		}
+	}
+	config := make(map[string]interface{}, len(declared))
+	for field, value := range declared {
+		config[field] = value
+	}
+	for field, value := range current {
+		if _, ok := config[field]; !ok && serverConfigFields[field] {
+			config[field] = value
+		}
+	}
+	return config, nil
+}
+
+// declaredName reports whether the declaration has a data source of the name
+func declaredName(declared []declaredDataSource, name string) bool {
+	for _, d := range declared {
+		if d.spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
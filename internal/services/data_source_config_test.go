@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecretRefs(t *testing.T) {
+	env := map[string]string{"NARAPULSE_SECRET_PG": "s3cret", "JWT_SECRET": "server"}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	config := map[string]interface{}{"host": "db.internal"}
+	require.NoError(t, resolveSecretRefs(config, map[string]string{"password": "env:NARAPULSE_SECRET_PG"}, "NARAPULSE_SECRET_", lookupEnv))
+	assert.Equal(t, "s3cret", config["password"])
+
+	err := resolveSecretRefs(config, map[string]string{"password": "env:JWT_SECRET"}, "NARAPULSE_SECRET_", lookupEnv)
+	assert.ErrorContains(t, err, "does not start with NARAPULSE_SECRET_", "other settings of the server cannot be read")
+	err = resolveSecretRefs(config, map[string]string{"password": "env:NARAPULSE_SECRET_MISSING"}, "NARAPULSE_SECRET_", lookupEnv)
+	assert.ErrorContains(t, err, "is not set")
+	err = resolveSecretRefs(config, map[string]string{"password": "vault:db/pg"}, "NARAPULSE_SECRET_", lookupEnv)
+	assert.ErrorContains(t, err, "unsupported reference")
+	err = resolveSecretRefs(config, map[string]string{"password": "env:NARAPULSE_SECRET_PG"}, "", lookupEnv)
+	assert.ErrorContains(t, err, "disabled")
+}
+
+func TestPlanDataSourceConfig(t *testing.T) {
+	existing := []models.DataSource{
+		{ID: 1, Name: "warehouse", Type: models.DataSourceTypePostgreSQL, Config: models.JSON(`{"host":"db","port":5432,"password":"old"}`)},
+		{ID: 2, Name: "events", Type: models.DataSourceTypeBigQuery, Description: "Raw events", Config: models.JSON(`{"project_id":"p"}`)},
+		{ID: 3, Name: "leads", Type: models.DataSourceTypeGoogleSheets},
+		{ID: 4, Name: "feed", Type: models.DataSourceTypeRESTAPI, Config: models.JSON(`{"url":"https://api","file_path":"/data/4.jsonl"}`)},
+	}
+	declared := []declaredDataSource{
+		{spec: models.DataSourceSpec{Name: "warehouse", Type: models.DataSourceTypePostgreSQL}, config: map[string]interface{}{"host": "db", "port": float64(5432), "password": "new"}},
+		{spec: models.DataSourceSpec{Name: "events", Type: models.DataSourceTypeBigQuery, Description: "Raw events"}, config: map[string]interface{}{"project_id": "p"}},
+		{spec: models.DataSourceSpec{Name: "leads", Type: models.DataSourceTypeCSV}, config: map[string]interface{}{"file_path": "/data/leads.csv"}},
+		{spec: models.DataSourceSpec{Name: "sales", Type: models.DataSourceTypeCSV}, config: map[string]interface{}{"file_path": "/data/sales.csv"}},
+	}
+
+	changes, err := planDataSourceConfig(existing, declared)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+
+	assert.Equal(t, models.DataSourceConfigActionUpdate, changes[0].Action)
+	assert.Equal(t, uint(1), changes[0].DataSourceID)
+	assert.Equal(t, []string{"config.password"}, changes[0].Fields)
+	assert.Equal(t, models.DataSourceConfigActionUnchanged, changes[1].Action)
+	assert.Equal(t, models.DataSourceConfigActionReplace, changes[2].Action, "the type changed")
+	assert.Equal(t, models.DataSourceConfigActionCreate, changes[3].Action)
+	assert.Zero(t, changes[3].DataSourceID)
+	assert.Equal(t, models.DataSourceConfigActionDelete, changes[4].Action)
+	assert.Equal(t, uint(4), changes[4].DataSourceID)
+
+	duplicated := append(existing, models.DataSource{ID: 5, Name: "warehouse", Type: models.DataSourceTypePostgreSQL})
+	_, err = planDataSourceConfig(duplicated, declared)
+	assert.ErrorIs(t, err, ErrDataSourceConfigInvalid)
+}
+
+func TestChangedDataSourceFieldsIgnoresServerFields(t *testing.T) {
+	dataSource := &models.DataSource{Name: "feed", Config: models.JSON(`{"url":"https://api","file_path":"/data/4.jsonl","materialized_at":"2025-10-01T00:00:00Z","page_size":100}`)}
+
+	fields, err := changedDataSourceFields(dataSource, declaredDataSource{config: map[string]interface{}{"url": "https://api"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config.page_size"}, fields)
+
+	config, err := keepServerConfigFields(dataSource.Config, map[string]interface{}{"url": "https://api/v2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"url": "https://api/v2", "file_path": "/data/4.jsonl", "materialized_at": "2025-10-01T00:00:00Z"}, config)
+}
@@ -1,38 +1,144 @@
 package services
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"narapulse-be/internal/connectors"
 	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
 	"narapulse-be/internal/repositories"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
 type DataSourceService interface {
+	WithContext(ctx context.Context) DataSourceService
 	CreateDataSource(userID uint, req *models.DataSourceCreateRequest) (*models.DataSourceResponse, error)
 	GetDataSource(id uint, userID uint) (*models.DataSourceResponse, error)
 	GetUserDataSources(userID uint) ([]models.DataSourceResponse, error)
 	UpdateDataSource(id uint, userID uint, req *models.DataSourceUpdateRequest) (*models.DataSourceResponse, error)
-	DeleteDataSource(id uint, userID uint) error
-	TestConnection(req *models.TestConnectionRequest) (*models.TestConnectionResponse, error)
+	DeleteDataSource(id uint, userID uint, deleteQueries bool) error
+	RestoreDataSource(id uint, userID uint) (*models.DataSourceResponse, error)
+	TestConnection(userID uint, req *models.TestConnectionRequest) (*models.TestConnectionResponse, error)
 	RefreshSchema(id uint, userID uint) (*models.DataSourceResponse, error)
+	GetDiscovery(id uint, userID uint) (*models.DataSourceDiscoveryResponse, error)
+	DuplicateDataSource(id uint, userID uint, req *models.DataSourceDuplicateRequest) (*models.DataSourceResponse, error)
+	RetryDiscovery(id uint, userID uint) (*models.DataSourceResponse, error)
+	GetSchemaChanges(id uint, userID uint) ([]models.SchemaChangeResponse, error)
+	InspectFile(file *multipart.FileHeader, options models.FileImportOptions) ([]models.FileUploadTable, error)
+	ApplyDataSourceConfig(userID uint, req *models.DataSourceConfigRequest, dryRun bool) (*models.DataSourceConfigPlan, error)
 }
 
 type dataSourceService struct {
-	dataSourceRepo repositories.DataSourceRepository
-	schemaRepo     repositories.SchemaRepository
-	connectorSvc   *connectorService
+	dataSourceRepo   repositories.DataSourceRepository
+	schemaRepo       repositories.SchemaRepository
+	connectorSvc     *connectorService
+	governanceSvc    *GovernanceService
+	materializeDir   string // Directory Google Drive files are downloaded to
+	ga4Templates     *GA4TemplateService
+	restoreWindow    time.Duration // How long a deleted data source can be restored
+	jobs             *JobService
+	schemaChanges    *SchemaChangeService
+	columnMetadata   *ColumnMetadataService
+	materializations *MaterializationService // Copies of REST API records and materialized Sheets
+	serviceAccounts  *BigQueryServiceAccountService
+	secretEnvPrefix  string // Environment variables declarations may reference as secrets start with it
+	notifications    *NotificationHub
+	ctx              context.Context // Context of the request the service is scoped to
 }
 
-func NewDataSourceService(dataSourceRepo repositories.DataSourceRepository, schemaRepo repositories.SchemaRepository, connectorSvc *connectorService) DataSourceService {
-	return &dataSourceService{
-		dataSourceRepo: dataSourceRepo,
-		schemaRepo:     schemaRepo,
-		connectorSvc:   connectorSvc,
+var (
+	// ErrDeletedDataSourceNotFound is returned when restoring a data source that is not deleted or does not exist
+	ErrDeletedDataSourceNotFound = errors.New("deleted data source not found")
+	// ErrDataSourceRestoreExpired is returned when the data source was deleted before the restore window
+	ErrDataSourceRestoreExpired = errors.New("data source was deleted too long ago to be restored")
+	// ErrDiscoveryInProgress is returned when retrying the discovery of a data source that is still being discovered
+	ErrDiscoveryInProgress = errors.New("schema discovery is already in progress")
+)
+
+// ErrMissingConfigValues is returned when a duplicated data source or a template
+// lacks credentials or placeholder values
+var ErrMissingConfigValues = errors.New("configuration values are missing")
+
+// MissingConfigValuesError lists the configuration fields or template
+// placeholders the request has to provide
+type MissingConfigValuesError struct {
+	Fields []string
+}
+
+func (e *MissingConfigValuesError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrMissingConfigValues, strings.Join(e.Fields, ", "))
+}
+
+func (e *MissingConfigValuesError) Unwrap() error {
+	return ErrMissingConfigValues
+}
+
+// discoveryEventLimit is the number of discovery events returned with the discovery status
+const discoveryEventLimit = 50
+
+// schemaChangeLimit is the number of schema changes returned for a data source
+const schemaChangeLimit = 50
+
+// defaultGoogleDriveRefreshInterval is how often a Google Drive file is checked for a new revision unless set
+const defaultGoogleDriveRefreshInterval = time.Hour
+
+func NewDataSourceService(dataSourceRepo repositories.DataSourceRepository, schemaRepo repositories.SchemaRepository, connectorSvc *connectorService, governanceSvc *GovernanceService, materializeDir string, ga4Templates *GA4TemplateService, restoreWindow time.Duration, jobs *JobService, schemaChanges *SchemaChangeService, columnMetadata *ColumnMetadataService, materializations *MaterializationService, serviceAccounts *BigQueryServiceAccountService, secretEnvPrefix string, notifications *NotificationHub) DataSourceService {
+	s := &dataSourceService{
+		dataSourceRepo:   dataSourceRepo,
+		schemaRepo:       schemaRepo,
+		connectorSvc:     connectorSvc,
+		governanceSvc:    governanceSvc,
+		materializeDir:   materializeDir,
+		ga4Templates:     ga4Templates,
+		restoreWindow:    restoreWindow,
+		jobs:             jobs,
+		schemaChanges:    schemaChanges,
+		columnMetadata:   columnMetadata,
+		materializations: materializations,
+		serviceAccounts:  serviceAccounts,
+		secretEnvPrefix:  secretEnvPrefix,
+		notifications:    notifications,
+		ctx:              context.Background(),
+	}
+	jobs.Register(models.JobTypeDataSourceDiscover, s.runDiscoverJob)
+	jobs.Register(models.JobTypeGoogleDriveSync, s.runGoogleDriveSyncJob)
+	return s
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *dataSourceService) WithContext(ctx context.Context) DataSourceService {
+	if s == nil {
+		return nil
 	}
+	scoped := *s
+	scoped.ctx = ctx
+	scoped.dataSourceRepo = s.dataSourceRepo.WithContext(ctx)
+	scoped.schemaRepo = s.schemaRepo.WithContext(ctx)
+	scoped.governanceSvc = s.governanceSvc.WithContext(ctx)
+	scoped.ga4Templates = s.ga4Templates.WithContext(ctx)
+	scoped.schemaChanges = s.schemaChanges.WithContext(ctx)
+	scoped.columnMetadata = s.columnMetadata.WithContext(ctx)
+	scoped.materializations = s.materializations.WithContext(ctx)
+	scoped.serviceAccounts = s.serviceAccounts.WithContext(ctx)
+	return &scoped
 }
 
 func (s *dataSourceService) CreateDataSource(userID uint, req *models.DataSourceCreateRequest) (*models.DataSourceResponse, error) {
+	if err := s.attachCredentials(userID, req.Type, req.Config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Validate configuration based on data source type
 	if err := s.validateConfig(req.Type, req.Config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -58,8 +164,10 @@ func (s *dataSourceService) CreateDataSource(userID uint, req *models.DataSource
 		return nil, fmt.Errorf("failed to create data source: %w", err)
 	}
 
-	// Test connection and discover schema
-	go s.testAndDiscoverSchema(dataSource)
+	// Test connection and discover schema in the background
+	if err := s.enqueueDiscovery(s.ctx, dataSource); err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to queue schema discovery")
+	}
 
 	return dataSource.ToResponse(), nil
 }
@@ -92,6 +200,67 @@ func (s *dataSourceService) GetUserDataSources(userID uint) ([]models.DataSource
 	return responses, nil
 }
 
+// DuplicateDataSource creates a data source with the type and configuration of
+// another one. Credentials are never copied: the request has to provide every
+// credential the original has, otherwise a MissingConfigValuesError lists them.
+func (s *dataSourceService) DuplicateDataSource(id uint, userID uint, req *models.DataSourceDuplicateRequest) (*models.DataSourceResponse, error) {
+	dataSource, err := s.dataSourceRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("data source not found: %w", err)
+	}
+
+	// Check ownership
+	if dataSource.UserID != userID {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	config, missing, err := duplicateConfig(dataSource.Config, req.Config)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, &MissingConfigValuesError{Fields: missing}
+	}
+
+	return s.CreateDataSource(userID, &models.DataSourceCreateRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        dataSource.Type,
+		Config:      config,
+	})
+}
+
+// duplicateConfig copies a configuration without its credentials and applies
+// the overrides. It returns the credentials of the original that the
+// overrides do not provide, sorted.
+func duplicateConfig(original models.JSON, overrides map[string]interface{}) (map[string]interface{}, []string, error) {
+	var config map[string]interface{}
+	if err := json.Unmarshal(original, &config); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	var missing []string
+	for _, field := range models.SensitiveConfigFields {
+		if _, ok := config[field]; !ok {
+			continue
+		}
+		delete(config, field)
+		// The key of a stored service account is attached again from service_account_id
+		if _, ok := config["service_account_id"]; ok && field == "credentials_encrypted" {
+			continue
+		}
+		if value, ok := overrides[field]; !ok || value == "" {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+
+	for field, value := range overrides {
+		config[field] = value
+	}
+	return config, missing, nil
+}
+
 func (s *dataSourceService) UpdateDataSource(id uint, userID uint, req *models.DataSourceUpdateRequest) (*models.DataSourceResponse, error) {
 	dataSource, err := s.dataSourceRepo.GetByID(id)
 	if err != nil {
@@ -111,6 +280,10 @@ func (s *dataSourceService) UpdateDataSource(id uint, userID uint, req *models.D
 		dataSource.Description = req.Description
 	}
 	if req.Config != nil {
+		if err := s.attachCredentials(userID, dataSource.Type, req.Config); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+
 		// Validate new configuration
 		if err := s.validateConfig(dataSource.Type, req.Config); err != nil {
 			return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -130,13 +303,19 @@ func (s *dataSourceService) UpdateDataSource(id uint, userID uint, req *models.D
 
 	// If config was updated, test connection and refresh schema
 	if req.Config != nil {
-		go s.testAndDiscoverSchema(dataSource)
+		s.connectorSvc.InvalidateConnection(dataSource.ID)
+		if err := s.enqueueDiscovery(s.ctx, dataSource); err != nil {
+			logger.L().Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to queue schema discovery")
+		}
 	}
 
 	return dataSource.ToResponse(), nil
 }
 
-func (s *dataSourceService) DeleteDataSource(id uint, userID uint) error {
+// DeleteDataSource soft-deletes a data source with its schemas and embeddings,
+// and with its query history and results when deleteQueries is set. It can be
+// restored within the restore window.
+func (s *dataSourceService) DeleteDataSource(id uint, userID uint, deleteQueries bool) error {
 	dataSource, err := s.dataSourceRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("data source not found: %w", err)
@@ -147,20 +326,46 @@ func (s *dataSourceService) DeleteDataSource(id uint, userID uint) error {
 		return fmt.Errorf("access denied")
 	}
 
-	// Delete associated schemas first
-	if err := s.schemaRepo.DeleteByDataSourceID(id); err != nil {
-		return fmt.Errorf("failed to delete schemas: %w", err)
-	}
-
-	// Delete data source
-	if err := s.dataSourceRepo.Delete(id); err != nil {
+	if err := s.dataSourceRepo.DeleteCascade(id, deleteQueries); err != nil {
 		return fmt.Errorf("failed to delete data source: %w", err)
 	}
+	s.connectorSvc.InvalidateConnection(id)
 
 	return nil
 }
 
-func (s *dataSourceService) TestConnection(req *models.TestConnectionRequest) (*models.TestConnectionResponse, error) {
+// RestoreDataSource un-deletes a data source deleted within the restore window,
+// together with the schemas, embeddings, queries and results deleted with it
+func (s *dataSourceService) RestoreDataSource(id uint, userID uint) (*models.DataSourceResponse, error) {
+	dataSource, err := s.dataSourceRepo.GetDeletedByID(id)
+	if err != nil || dataSource.UserID != userID {
+		return nil, ErrDeletedDataSourceNotFound
+	}
+
+	if !restorable(dataSource.DeletedAt.Time, s.restoreWindow, time.Now()) {
+		return nil, ErrDataSourceRestoreExpired
+	}
+
+	if err := s.dataSourceRepo.Restore(dataSource); err != nil {
+		return nil, fmt.Errorf("failed to restore data source: %w", err)
+	}
+
+	return s.GetDataSource(id, userID)
+}
+
+// restorable reports whether something deleted at deletedAt is still within the restore window
+func restorable(deletedAt time.Time, window time.Duration, now time.Time) bool {
+	return now.Sub(deletedAt) <= window
+}
+
+func (s *dataSourceService) TestConnection(userID uint, req *models.TestConnectionRequest) (*models.TestConnectionResponse, error) {
+	if err := s.attachCredentials(userID, req.Type, req.Config); err != nil {
+		return &models.TestConnectionResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid configuration: %v", err),
+		}, nil
+	}
+
 	// Validate configuration
 	if err := s.validateConfig(req.Type, req.Config); err != nil {
 		return &models.TestConnectionResponse{
@@ -195,14 +400,9 @@ func (s *dataSourceService) RefreshSchema(id uint, userID uint) (*models.DataSou
 		return nil, fmt.Errorf("access denied")
 	}
 
-	// Delete existing schemas
-	if err := s.schemaRepo.DeleteByDataSourceID(id); err != nil {
-		return nil, fmt.Errorf("failed to delete existing schemas: %w", err)
-	}
-
-	// Discover new schema
-	if err := s.discoverSchema(dataSource); err != nil {
-		return nil, fmt.Errorf("failed to discover schema: %w", err)
+	// Replace the schemas, recording what changed
+	if err := s.rediscoverSchema(s.ctx, dataSource); err != nil {
+		return nil, err
 	}
 
 	// Get updated data source with schemas
@@ -225,6 +425,14 @@ func (s *dataSourceService) validateConfig(dsType models.DataSourceType, config
 		return s.validateBigQueryConfig(config)
 	case models.DataSourceTypeGoogleSheets:
 		return s.validateGoogleSheetsConfig(config)
+	case models.DataSourceTypeMongoDB:
+		return s.validateMongoDBConfig(config)
+	case models.DataSourceTypeRESTAPI:
+		return s.validateRESTAPIConfig(config)
+	case models.DataSourceTypeGA4:
+		return s.validateGA4Config(config)
+	case models.DataSourceTypeGoogleDrive:
+		return s.validateGoogleDriveConfig(config)
 	default:
 		return fmt.Errorf("unsupported data source type: %s", dsType)
 	}
@@ -247,16 +455,32 @@ func (s *dataSourceService) validatePostgreSQLConfig(config map[string]interface
 	return nil
 }
 
+// validateBigQueryConfig requires the dataset and a key, given inline as
+// credentials_json or as the service_account_id of a stored service account
 func (s *dataSourceService) validateBigQueryConfig(config map[string]interface{}) error {
-	requiredFields := []string{"project_id", "dataset_id", "credentials_json"}
+	requiredFields := []string{"project_id", "dataset_id"}
 	for _, field := range requiredFields {
 		if _, ok := config[field]; !ok {
 			return fmt.Errorf("%s is required", field)
 		}
 	}
+	_, hasCredentials := config["credentials_json"]
+	_, hasServiceAccount := config["service_account_id"]
+	if !hasCredentials && !hasServiceAccount {
+		return fmt.Errorf("credentials_json or service_account_id is required")
+	}
 	return nil
 }
 
+// attachCredentials resolves the service_account_id of BigQuery and GA4
+// configs to the encrypted key of the stored service account
+func (s *dataSourceService) attachCredentials(userID uint, dsType models.DataSourceType, config map[string]interface{}) error {
+	if s.serviceAccounts == nil || (dsType != models.DataSourceTypeBigQuery && dsType != models.DataSourceTypeGA4) {
+		return nil
+	}
+	return s.serviceAccounts.AttachCredentials(userID, config)
+}
+
 func (s *dataSourceService) validateGoogleSheetsConfig(config map[string]interface{}) error {
 	requiredFields := []string{"spreadsheet_id", "access_token"}
 	for _, field := range requiredFields {
@@ -264,44 +488,321 @@ func (s *dataSourceService) validateGoogleSheetsConfig(config map[string]interfa
 			return fmt.Errorf("%s is required", field)
 		}
 	}
+	if _, err := connectors.SheetSelectionFromConfig(config); err != nil {
+		return err
+	}
+	return validateMaterializationConfig(config)
+}
+
+func (s *dataSourceService) validateMongoDBConfig(config map[string]interface{}) error {
+	if _, ok := config["database"]; !ok {
+		return fmt.Errorf("database is required")
+	}
+	_, hasURI := config["connection_uri"]
+	_, hasHost := config["host"]
+	if !hasURI && !hasHost {
+		return fmt.Errorf("connection_uri or host is required")
+	}
 	return nil
 }
 
-func (s *dataSourceService) testAndDiscoverSchema(dataSource *models.DataSource) {
+func (s *dataSourceService) validateRESTAPIConfig(config map[string]interface{}) error {
+	if _, ok := config["url"]; !ok {
+		return fmt.Errorf("url is required")
+	}
+	return validateMaterializationConfig(config)
+}
+
+// validateGA4Config requires the GCP project and the GA4 property (or the
+// export dataset). Credentials fall back to Application Default Credentials.
+func (s *dataSourceService) validateGA4Config(config map[string]interface{}) error {
+	if _, ok := config["project_id"]; !ok {
+		return fmt.Errorf("project_id is required")
+	}
+	_, hasProperty := config["property_id"]
+	_, hasDataset := config["dataset_id"]
+	if !hasProperty && !hasDataset {
+		return fmt.Errorf("property_id or dataset_id is required")
+	}
+	return nil
+}
+
+// validateGoogleDriveConfig requires the Drive file. Credentials fall back to
+// Application Default Credentials, like GA4.
+func (s *dataSourceService) validateGoogleDriveConfig(config map[string]interface{}) error {
+	if _, ok := config["file_id"]; !ok {
+		return fmt.Errorf("file_id is required")
+	}
+	if _, err := resolveFileImportOptions(fileImportOptionsFromConfig(config)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// enqueueDiscovery queues the connection test and schema discovery of a data
+// source and marks it as connecting. When the job cannot be queued the data
+// source is marked as failed, so the discovery can be retried.
+func (s *dataSourceService) enqueueDiscovery(ctx context.Context, dataSource *models.DataSource) error {
+	payload := models.DataSourceJobPayload{DataSourceID: dataSource.ID}
+	job, err := s.jobs.Enqueue(ctx, models.JobTypeDataSourceDiscover, payload)
+	if err != nil {
+		s.setDiscoveryStatus(ctx, dataSource, 0, models.ConnectionStatusError, fmt.Sprintf("Failed to queue schema discovery: %v", err))
+		return fmt.Errorf("failed to queue schema discovery: %w", err)
+	}
+	s.setDiscoveryStatus(ctx, dataSource, job.ID, models.ConnectionStatusConnecting, "Schema discovery queued")
+	return nil
+}
+
+// setDiscoveryStatus moves the data source to a discovery status and records
+// the transition. The message of an error status becomes its error message.
+// The owner is notified once the discovery ends in active or error.
+func (s *dataSourceService) setDiscoveryStatus(ctx context.Context, dataSource *models.DataSource, jobID uint, status models.ConnectionStatus, message string) {
+	dataSource.Status = status
+	dataSource.ErrorMsg = ""
+	if status == models.ConnectionStatusError {
+		dataSource.ErrorMsg = message
+	}
+	if err := s.dataSourceRepo.Update(dataSource); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to update data source status")
+	}
+
+	event := &models.DataSourceDiscoveryEvent{
+		TenantID:     dataSource.TenantID,
+		DataSourceID: dataSource.ID,
+		JobID:        jobID,
+		Status:       status,
+		Message:      message,
+	}
+	if err := s.dataSourceRepo.CreateDiscoveryEvent(event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to record discovery event")
+	}
+
+	if status == models.ConnectionStatusActive || status == models.ConnectionStatusError {
+		s.notifications.Notify(ctx, dataSource.UserID, models.NotificationDiscoveryFinished, models.DiscoveryFinishedNotification{
+			DataSourceID: dataSource.ID,
+			Name:         dataSource.Name,
+			Status:       status,
+			Message:      message,
+		})
+	}
+}
+
+// runDiscoverJob tests the connection of a data source and discovers its
+// schema, then queues the sync of its schema embeddings. A failed connection
+// is recorded on the data source rather than retried; the health checks pick
+// it up once it recovers. The discovery runs in the tenant of the data source.
+func (s *dataSourceService) runDiscoverJob(ctx context.Context, payload json.RawMessage) error {
+	var p models.DataSourceJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	dataSource, err := s.dataSourceRepo.GetByID(p.DataSourceID)
+	if err != nil {
+		// Deleted since the job was queued
+		logger.FromContext(ctx).Info().Uint("data_source_id", p.DataSourceID).Msg("Data source no longer exists, skipping discovery")
+		return nil
+	}
+	ctx = tenancy.WithTenant(ctx, dataSource.TenantID)
+	s = s.WithContext(ctx).(*dataSourceService)
+
+	if err := s.testAndDiscoverSchema(ctx, dataSource); err != nil {
+		return err
+	}
+	if dataSource.Status != models.ConnectionStatusActive {
+		return nil
+	}
+
+	if _, err := s.jobs.Enqueue(ctx, models.JobTypeSchemaSync, p); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to enqueue schema sync")
+	}
+	return nil
+}
+
+// testAndDiscoverSchema moves the data source through connecting and
+// discovering to active or error. Only a failed discovery is returned, so the
+// job retries it.
+func (s *dataSourceService) testAndDiscoverSchema(ctx context.Context, dataSource *models.DataSource) error {
+	jobID := JobIDFromContext(ctx)
+
 	// Parse config
 	var config map[string]interface{}
 	if err := json.Unmarshal(dataSource.Config, &config); err != nil {
-		dataSource.Status = models.ConnectionStatusError
-		dataSource.ErrorMsg = fmt.Sprintf("Invalid config: %v", err)
-		s.dataSourceRepo.Update(dataSource)
-		return
+		s.setDiscoveryStatus(ctx, dataSource, jobID, models.ConnectionStatusError, fmt.Sprintf("Invalid config: %v", err))
+		return nil
 	}
 
 	// Test connection
+	s.setDiscoveryStatus(ctx, dataSource, jobID, models.ConnectionStatusConnecting, "Testing connection")
 	testReq := models.TestConnectionRequest{
 		Type:   dataSource.Type,
 		Config: config,
 	}
 
-	err := s.connectorSvc.TestConnection(testReq)
+	err := s.connectorSvc.TestDataSourceConnection(dataSource.ID, testReq)
+	now := time.Now()
+	dataSource.LastTested = &now
 	if err != nil {
-		dataSource.Status = models.ConnectionStatusError
-		dataSource.ErrorMsg = fmt.Sprintf("Connection failed: %v", err)
-		now := time.Now()
-		dataSource.LastTested = &now
-		s.dataSourceRepo.Update(dataSource)
-		return
+		s.setDiscoveryStatus(ctx, dataSource, jobID, models.ConnectionStatusError, fmt.Sprintf("Connection failed: %v", err))
+		return nil
 	}
 
-	// Connection successful, discover schema
-	dataSource.Status = models.ConnectionStatusActive
-	dataSource.ErrorMsg = ""
-	now := time.Now()
-	dataSource.LastTested = &now
-	s.dataSourceRepo.Update(dataSource)
+	// Connection successful, discover schema, replacing what an earlier attempt or configuration left
+	s.setDiscoveryStatus(ctx, dataSource, jobID, models.ConnectionStatusDiscovering, "Connected, discovering schema")
+	if err := s.rediscoverSchema(ctx, dataSource); err != nil {
+		s.setDiscoveryStatus(ctx, dataSource, jobID, models.ConnectionStatusError, fmt.Sprintf("Schema discovery failed: %v", err))
+		return err
+	}
+
+	if dataSource.Type == models.DataSourceTypeGA4 {
+		s.seedGA4Templates(dataSource)
+	}
+	s.setDiscoveryStatus(ctx, dataSource, jobID, models.ConnectionStatusActive, "Schema discovered")
+	return nil
+}
+
+// rediscoverSchema replaces the schemas of the data source with a fresh
+// discovery. When it had schemas before, the differences are recorded as a
+// schema change; failing to record them does not fail the discovery.
+func (s *dataSourceService) rediscoverSchema(ctx context.Context, dataSource *models.DataSource) error {
+	before, err := s.schemaRepo.GetByDataSourceID(dataSource.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get existing schemas: %w", err)
+	}
 
-	// Discover schema
-	s.discoverSchema(dataSource)
+	if err := s.schemaRepo.DeleteByDataSourceID(dataSource.ID); err != nil {
+		return fmt.Errorf("failed to delete existing schemas: %w", err)
+	}
+	if err := s.discoverSchema(dataSource); err != nil {
+		return fmt.Errorf("failed to discover schema: %w", err)
+	}
+
+	// First discovery, nothing to compare with
+	if len(before) == 0 {
+		return nil
+	}
+
+	after, err := s.schemaRepo.GetByDataSourceID(dataSource.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to get discovered schemas")
+		return nil
+	}
+	change, err := s.schemaChanges.Record(dataSource, before, after)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to record schema change")
+		return nil
+	}
+	if change.HasChanges {
+		logger.FromContext(ctx).Info().Uint("data_source_id", dataSource.ID).
+			Int("affected_queries", len(change.AffectedQueries)).Int("affected_kpis", len(change.AffectedKPIs)).
+			Msg("Data source schema changed")
+	}
+	return nil
+}
+
+// GetSchemaChanges returns the latest schema changes of a data source, newest first
+func (s *dataSourceService) GetSchemaChanges(id uint, userID uint) ([]models.SchemaChangeResponse, error) {
+	dataSource, err := s.dataSourceRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("data source not found: %w", err)
+	}
+
+	// Check ownership
+	if dataSource.UserID != userID {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	return s.schemaChanges.List(id, schemaChangeLimit)
+}
+
+// InspectFile reads an uploaded CSV or Excel file with the import options and
+// returns the inferred table of the file, or of each imported sheet
+func (s *dataSourceService) InspectFile(file *multipart.FileHeader, options models.FileImportOptions) ([]models.FileUploadTable, error) {
+	_, tables, err := s.connectorSvc.ProcessFileUpload(file, options)
+	if err != nil {
+		return nil, err
+	}
+	return fileUploadTables(tables), nil
+}
+
+// GetDiscovery returns the discovery status of a data source with its latest
+// transitions and discovery job
+func (s *dataSourceService) GetDiscovery(id uint, userID uint) (*models.DataSourceDiscoveryResponse, error) {
+	dataSource, err := s.dataSourceRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("data source not found: %w", err)
+	}
+
+	// Check ownership
+	if dataSource.UserID != userID {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	events, err := s.dataSourceRepo.GetDiscoveryEvents(id, discoveryEventLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discovery events: %w", err)
+	}
+
+	response := &models.DataSourceDiscoveryResponse{
+		DataSourceID: dataSource.ID,
+		Status:       dataSource.Status,
+		ErrorMsg:     dataSource.ErrorMsg,
+		Events:       events,
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].JobID == 0 {
+			continue
+		}
+		if job, err := s.jobs.GetJob(events[i].JobID); err == nil {
+			response.Job = job
+		}
+		break
+	}
+	return response, nil
+}
+
+// RetryDiscovery queues a new connection test and schema discovery of a data
+// source, e.g. after its discovery failed
+func (s *dataSourceService) RetryDiscovery(id uint, userID uint) (*models.DataSourceResponse, error) {
+	dataSource, err := s.dataSourceRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("data source not found: %w", err)
+	}
+
+	// Check ownership
+	if dataSource.UserID != userID {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	if discoveryInProgress(dataSource.Status) {
+		return nil, ErrDiscoveryInProgress
+	}
+
+	s.connectorSvc.InvalidateConnection(dataSource.ID)
+	if err := s.enqueueDiscovery(s.ctx, dataSource); err != nil {
+		return nil, err
+	}
+	return dataSource.ToResponse(), nil
+}
+
+// discoveryInProgress reports whether a discovery is queued or running for a data source in the status
+func discoveryInProgress(status models.ConnectionStatus) bool {
+	return status == models.ConnectionStatusConnecting || status == models.ConnectionStatusDiscovering
+}
+
+// seedGA4Templates gives the owner of a GA4 data source the GA4 KPIs and
+// glossary terms, so marketing questions are understood out of the box
+func (s *dataSourceService) seedGA4Templates(dataSource *models.DataSource) {
+	if s.ga4Templates == nil {
+		return
+	}
+
+	kpis, terms, err := s.ga4Templates.Seed(s.ctx, dataSource.UserID)
+	if err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to seed GA4 templates")
+		return
+	}
+	logger.L().Info().Uint("data_source_id", dataSource.ID).Int("kpis", kpis).Int("glossary_terms", terms).Msg("Seeded GA4 templates")
 }
 
 func (s *dataSourceService) discoverSchema(dataSource *models.DataSource) error {
@@ -310,42 +811,338 @@ func (s *dataSourceService) discoverSchema(dataSource *models.DataSource) error
 		return err
 	}
 
-	columns, err := s.connectorSvc.DiscoverSchema(dataSource.Type, config)
+	if dataSource.Type == models.DataSourceTypeRESTAPI {
+		return s.materializeRESTAPI(dataSource, config)
+	}
+	if dataSource.Type == models.DataSourceTypeGoogleDrive {
+		return s.materializeGoogleDrive(dataSource, config)
+	}
+
+	tables, err := s.connectorSvc.DiscoverDataSourceTables(dataSource.ID, dataSource.Type, config)
 	if err != nil {
 		return err
 	}
 
-	// Create a default schema with discovered columns
-	columnsJSON, err := json.Marshal(columns)
+	// Curated column metadata outlives rediscovery
+	curated, err := s.columnMetadata.ForDataSource(dataSource.ID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal columns: %w", err)
+		return err
 	}
 
-	schema := &models.Schema{
-		DataSourceID: dataSource.ID,
-		Name:         "default",
-		DisplayName:  "Default Schema",
-		Columns:      models.JSON(columnsJSON),
-		RowCount:     0, // Will be updated later
-		IsActive:     true,
+	// Create one schema per discovered table/sheet
+	for _, table := range tables {
+		applyColumnMetadata(table.Columns, table.Name, curated)
+		applyTableMetadata(&table, curated)
+		schema, err := table.toSchema(dataSource)
+		if err != nil {
+			return err
+		}
+
+		if err := s.schemaRepo.Create(schema); err != nil {
+			return fmt.Errorf("failed to save schema %s: %w", table.Name, err)
+		}
+
+		s.emitPIIDetected(dataSource, table)
+	}
+
+	// Materialized Sheets are copied right away, so they can be queried once active
+	if Materializes(dataSource.Type, config) {
+		if err := s.materializations.Refresh(dataSource, true); err != nil {
+			return fmt.Errorf("failed to materialize data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// materializeRESTAPI fetches the records of a REST API data source, writes
+// them as JSON lines for the DuckDB file engine and saves the inferred schema.
+// The file path is stored in the config, so queries run like on a file upload.
+func (s *dataSourceService) materializeRESTAPI(dataSource *models.DataSource, config map[string]interface{}) error {
+	table, records, err := s.connectorSvc.FetchRESTAPITable(config)
+	if err != nil {
+		return err
+	}
+
+	// Recorded as a full pull, so the next refresh can be incremental
+	path := s.materializations.FilePath(dataSource, table.Name)
+	column, _ := config["incremental_column"].(string)
+	if err := s.materializations.Store(dataSource.ID, table.Name, path, column, records, models.MaterializationModeFull); err != nil {
+		return err
+	}
+
+	config["file_path"] = path
+	config["materialized_at"] = time.Now().UTC().Format(time.RFC3339)
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	dataSource.Config = models.JSON(configJSON)
+	if err := s.dataSourceRepo.Update(dataSource); err != nil {
+		return fmt.Errorf("failed to save materialized file path: %w", err)
+	}
+
+	curated, err := s.columnMetadata.ForDataSource(dataSource.ID)
+	if err != nil {
+		return err
+	}
+	applyColumnMetadata(table.Columns, table.Name, curated)
+	applyTableMetadata(table, curated)
+
+	schema, err := table.toSchema(dataSource)
+	if err != nil {
+		return err
+	}
+	if err := s.schemaRepo.Create(schema); err != nil {
+		return fmt.Errorf("failed to save schema %s: %w", table.Name, err)
+	}
+
+	s.emitPIIDetected(dataSource, *table)
+	return nil
+}
+
+// materializeGoogleDrive downloads the file of a Google Drive data source for
+// the DuckDB file engine and saves the tables inferred from it. The file path
+// and Drive revision are stored in the config, so the sync job can tell when
+// the file changed.
+func (s *dataSourceService) materializeGoogleDrive(dataSource *models.DataSource, config map[string]interface{}) error {
+	file, content, err := s.connectorSvc.DownloadGoogleDriveFile(config)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	ext := connectors.GoogleDriveFileExtension(file.Name, file.MimeType)
+	path := filepath.Join(s.materializeDir, fmt.Sprintf("google_drive_%d%s", dataSource.ID, ext))
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.Copy(w, content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download Drive file: %w", err)
+	}
+
+	downloaded, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded Drive file: %w", err)
+	}
+	defer downloaded.Close()
+	info, err := downloaded.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded Drive file: %w", err)
+	}
+	// The extension decides how the file is read, whatever its name in Drive
+	name := strings.TrimSuffix(file.Name, filepath.Ext(file.Name)) + ext
+	_, tables, err := s.connectorSvc.ProcessFile(name, info.Size(), downloaded, fileImportOptionsFromConfig(config))
+	if err != nil {
+		return err
 	}
 
-	err = s.schemaRepo.Create(schema)
+	now := time.Now().UTC().Format(time.RFC3339)
+	config["file_path"] = path
+	config["file_name"] = file.Name
+	config["file_size"] = info.Size()
+	config["revision_id"] = file.RevisionID
+	config["materialized_at"] = now
+	config["checked_at"] = now
+	configJSON, err := json.Marshal(config)
 	if err != nil {
-		return fmt.Errorf("failed to save schema: %w", err)
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	dataSource.Config = models.JSON(configJSON)
+	if err := s.dataSourceRepo.Update(dataSource); err != nil {
+		return fmt.Errorf("failed to save downloaded file path: %w", err)
 	}
 
+	curated, err := s.columnMetadata.ForDataSource(dataSource.ID)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		applyColumnMetadata(table.Columns, table.Name, curated)
+		applyTableMetadata(&table, curated)
+		schema, err := table.toSchema(dataSource)
+		if err != nil {
+			return err
+		}
+		if err := s.schemaRepo.Create(schema); err != nil {
+			return fmt.Errorf("failed to save schema %s: %w", table.Name, err)
+		}
+		s.emitPIIDetected(dataSource, table)
+	}
+	return nil
+}
+
+// runGoogleDriveSyncJob checks the Google Drive data sources whose refresh
+// interval has passed for a new revision of their file. A changed file is
+// downloaded again and its schema rediscovered, recording schema changes;
+// an unchanged file is only marked as checked.
+func (s *dataSourceService) runGoogleDriveSyncJob(ctx context.Context, _ json.RawMessage) error {
+	dataSources, err := s.dataSourceRepo.GetByType(models.DataSourceTypeGoogleDrive)
+	if err != nil {
+		return fmt.Errorf("failed to list Google Drive data sources: %w", err)
+	}
+
+	now := time.Now()
+	for i := range dataSources {
+		dataSource := &dataSources[i]
+		if dataSource.Status != models.ConnectionStatusActive {
+			continue
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal(dataSource.Config, &config); err != nil || !googleDriveSyncDue(config, now) {
+			continue
+		}
+
+		// The data source is checked in its tenant, like the sync it queues
+		tenantCtx := tenancy.WithTenant(ctx, dataSource.TenantID)
+		scoped := s.WithContext(tenantCtx).(*dataSourceService)
+
+		file, err := s.connectorSvc.GetGoogleDriveFile(config)
+		if err != nil {
+			// Connection problems are reported by the health checks
+			logger.FromContext(ctx).Warn().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to check Google Drive file")
+			continue
+		}
+		if revision, _ := config["revision_id"].(string); revision == file.RevisionID {
+			config["checked_at"] = now.UTC().Format(time.RFC3339)
+			if configJSON, err := json.Marshal(config); err == nil {
+				dataSource.Config = models.JSON(configJSON)
+				if err := scoped.dataSourceRepo.Update(dataSource); err != nil {
+					logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to save Google Drive check")
+				}
+			}
+			continue
+		}
+
+		jobID := JobIDFromContext(ctx)
+		scoped.setDiscoveryStatus(tenantCtx, dataSource, jobID, models.ConnectionStatusDiscovering, "Drive file changed, downloading new revision")
+		if err := scoped.rediscoverSchema(tenantCtx, dataSource); err != nil {
+			scoped.setDiscoveryStatus(tenantCtx, dataSource, jobID, models.ConnectionStatusError, fmt.Sprintf("Schema discovery failed: %v", err))
+			continue
+		}
+		scoped.setDiscoveryStatus(tenantCtx, dataSource, jobID, models.ConnectionStatusActive, "Schema rediscovered from new Drive revision")
+		if _, err := scoped.jobs.Enqueue(tenantCtx, models.JobTypeSchemaSync, models.DataSourceJobPayload{DataSourceID: dataSource.ID}); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to enqueue schema sync")
+		}
+	}
 	return nil
 }
 
+// googleDriveSyncDue reports whether the refresh interval of a Google Drive
+// data source has passed since its file was last checked
+func googleDriveSyncDue(config map[string]interface{}, now time.Time) bool {
+	interval := defaultGoogleDriveRefreshInterval
+	if minutes, ok := config["refresh_interval_minutes"].(float64); ok && minutes > 0 {
+		interval = time.Duration(minutes) * time.Minute
+	}
+	checkedAt, _ := config["checked_at"].(string)
+	checked, err := time.Parse(time.RFC3339, checkedAt)
+	if err != nil {
+		return true
+	}
+	return !now.Before(checked.Add(interval))
+}
+
+// writeJSONLines writes records as newline-delimited JSON
+func writeJSONLines(path string, records []map[string]interface{}) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		writer := bufio.NewWriter(w)
+		encoder := json.NewEncoder(writer)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	})
+}
+
+// writeFileAtomic writes a file next to the target and renames it, so queries
+// never read a partial file
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// emitPIIDetected records a governance event for columns that look like personal data
+func (s *dataSourceService) emitPIIDetected(dataSource *models.DataSource, table SchemaInfo) {
+	if s.governanceSvc == nil {
+		return
+	}
+
+	piiColumns := s.governanceSvc.DetectPIIColumns(table.Columns)
+	if len(piiColumns) == 0 {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"data_source_name": dataSource.Name,
+		"table":            table.Name,
+		"columns":          piiColumns,
+	}
+	if err := s.governanceSvc.Emit(models.GovernanceEventPIIColumnDetected, dataSource.ID, dataSource.UserID, payload); err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to emit PII detection event")
+	}
+}
+
 // SchemaInfo represents discovered schema information
 type SchemaInfo struct {
-	Name        string                   `json:"name"`
-	DisplayName string                   `json:"display_name"`
-	Description string                   `json:"description"`
-	Columns     []models.Column          `json:"columns"`
-	RowCount    int64                    `json:"row_count"`
-	SampleData  []map[string]interface{} `json:"sample_data"`
+	Name          string                     `json:"name"`
+	DisplayName   string                     `json:"display_name"`
+	Description   string                     `json:"description"`
+	Columns       []models.Column            `json:"columns"`
+	RowCount      int64                      `json:"row_count"`
+	SampleData    []map[string]interface{}   `json:"sample_data"`
+	Relationships []models.TableRelationship `json:"relationships,omitempty"`
+}
+
+// toSchema converts discovered table information into a Schema record of the data source
+func (info SchemaInfo) toSchema(dataSource *models.DataSource) (*models.Schema, error) {
+	columnsJSON, err := json.Marshal(info.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal columns: %w", err)
+	}
+
+	sampleDataJSON, err := json.Marshal(info.SampleData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sample data: %w", err)
+	}
+
+	// Persist foreign key relationships so prompts can use real join paths
+	relationshipsJSON, err := json.Marshal(info.Relationships)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal relationships: %w", err)
+	}
+
+	return &models.Schema{
+		TenantID:      dataSource.TenantID,
+		DataSourceID:  dataSource.ID,
+		Name:          info.Name,
+		DisplayName:   info.DisplayName,
+		Description:   info.Description,
+		Columns:       models.JSON(columnsJSON),
+		RowCount:      info.RowCount,
+		SampleData:    models.JSON(sampleDataJSON),
+		Relationships: models.JSON(relationshipsJSON),
+		IsActive:      true,
+	}, nil
 }
 
 // ConnectorServiceInterface interface for different data source connectors
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestorable(t *testing.T) {
+	now := time.Now()
+	window := 30 * 24 * time.Hour
+
+	assert.True(t, restorable(now.Add(-time.Hour), window, now))
+	assert.True(t, restorable(now.Add(-window), window, now))
+	assert.False(t, restorable(now.Add(-window-time.Minute), window, now))
+	assert.False(t, restorable(now.Add(-time.Hour), 0, now))
+}
+
+func TestDiscoveryInProgress(t *testing.T) {
+	assert.True(t, discoveryInProgress(models.ConnectionStatusConnecting))
+	assert.True(t, discoveryInProgress(models.ConnectionStatusDiscovering))
+	assert.False(t, discoveryInProgress(models.ConnectionStatusActive))
+	assert.False(t, discoveryInProgress(models.ConnectionStatusError))
+	assert.False(t, discoveryInProgress(models.ConnectionStatusInactive))
+}
+
+func TestDuplicateConfig(t *testing.T) {
+	original := models.JSON(`{"host":"db.internal","port":5432,"database":"sales","username":"app","password":"secret"}`)
+
+	config, missing, err := duplicateConfig(original, map[string]interface{}{"database": "marketing"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"password"}, missing)
+	assert.NotContains(t, config, "password")
+	assert.Equal(t, "marketing", config["database"])
+
+	config, missing, err = duplicateConfig(original, map[string]interface{}{"password": "other"})
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, "other", config["password"])
+	assert.Equal(t, "sales", config["database"])
+}
+
+func TestDuplicateConfig_ServiceAccount(t *testing.T) {
+	original := models.JSON(`{"project_id":"acme","dataset_id":"sales","service_account_id":3,"credentials_encrypted":"c2VhbGVk"}`)
+
+	// The encrypted key is attached again from the service account, so it is not missing
+	config, missing, err := duplicateConfig(original, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.NotContains(t, config, "credentials_encrypted")
+	assert.Equal(t, float64(3), config["service_account_id"])
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDataSourceTemplateNotFound is returned when the template does not exist
+	ErrDataSourceTemplateNotFound = errors.New("data source template not found")
+	// ErrDataSourceTemplateNameTaken is returned when another template has the name
+	ErrDataSourceTemplateNameTaken = errors.New("a data source template with this name already exists")
+	// ErrTemplateCredentials is returned when a template stores a credential instead of a placeholder
+	ErrTemplateCredentials = errors.New("credentials in templates must be placeholders")
+)
+
+// templatePlaceholder matches {{name}} placeholders in template values
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// DataSourceTemplateService manages admin-curated connection templates and
+// creates data sources from them
+type DataSourceTemplateService struct {
+	db          *gorm.DB
+	dataSources DataSourceService
+}
+
+// NewDataSourceTemplateService creates a new data source template service
+func NewDataSourceTemplateService(db *gorm.DB, dataSources DataSourceService) *DataSourceTemplateService {
+	return &DataSourceTemplateService{
+		db:          db,
+		dataSources: dataSources,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *DataSourceTemplateService) WithContext(ctx context.Context) *DataSourceTemplateService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.dataSources = s.dataSources.WithContext(ctx)
+	return &scoped
+}
+
+// ListTemplates returns all templates ordered by name
+func (s *DataSourceTemplateService) ListTemplates() ([]models.DataSourceTemplateResponse, error) {
+	var templates []models.DataSourceTemplate
+	if err := s.db.Order("name").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data source templates: %w", err)
+	}
+
+	responses := make([]models.DataSourceTemplateResponse, 0, len(templates))
+	for i := range templates {
+		response, err := newDataSourceTemplateResponse(&templates[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetTemplate returns a template with its placeholders
+func (s *DataSourceTemplateService) GetTemplate(id uint) (*models.DataSourceTemplateResponse, error) {
+	template, err := s.getTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	return newDataSourceTemplateResponse(template)
+}
+
+func (s *DataSourceTemplateService) getTemplate(id uint) (*models.DataSourceTemplate, error) {
+	var template models.DataSourceTemplate
+	if err := s.db.First(&template, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDataSourceTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get data source template: %w", err)
+	}
+	return &template, nil
+}
+
+// CreateTemplate adds a template
+func (s *DataSourceTemplateService) CreateTemplate(adminID uint, req *models.DataSourceTemplateRequest) (*models.DataSourceTemplateResponse, error) {
+	template := &models.DataSourceTemplate{CreatedBy: adminID}
+	if err := s.saveTemplate(template, req); err != nil {
+		return nil, err
+	}
+	return newDataSourceTemplateResponse(template)
+}
+
+// UpdateTemplate replaces a template; data sources created from it are not changed
+func (s *DataSourceTemplateService) UpdateTemplate(id uint, req *models.DataSourceTemplateRequest) (*models.DataSourceTemplateResponse, error) {
+	template, err := s.getTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveTemplate(template, req); err != nil {
+		return nil, err
+	}
+	return newDataSourceTemplateResponse(template)
+}
+
+func (s *DataSourceTemplateService) saveTemplate(template *models.DataSourceTemplate, req *models.DataSourceTemplateRequest) error {
+	if fields := storedTemplateCredentials(req.Config); len(fields) > 0 {
+		return fmt.Errorf("%w: %s", ErrTemplateCredentials, strings.Join(fields, ", "))
+	}
+
+	var taken int64
+	if err := s.db.Model(&models.DataSourceTemplate{}).Where("name = ? AND id <> ?", req.Name, template.ID).Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check template name: %w", err)
+	}
+	if taken > 0 {
+		return ErrDataSourceTemplateNameTaken
+	}
+
+	configJSON, err := json.Marshal(req.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Type = req.Type
+	template.Config = models.JSON(configJSON)
+	if err := s.db.Save(template).Error; err != nil {
+		return fmt.Errorf("failed to save data source template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes a template; data sources created from it are kept
+func (s *DataSourceTemplateService) DeleteTemplate(id uint) error {
+	result := s.db.Delete(&models.DataSourceTemplate{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete data source template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDataSourceTemplateNotFound
+	}
+	return nil
+}
+
+// CreateDataSource creates a data source of the user from a template. Every
+// placeholder needs a value, otherwise a MissingConfigValuesError lists them.
+func (s *DataSourceTemplateService) CreateDataSource(id uint, userID uint, req *models.DataSourceFromTemplateRequest) (*models.DataSourceResponse, error) {
+	template, err := s.getTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(template.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid template configuration: %w", err)
+	}
+
+	filled, missing := fillTemplateConfig(config, req.Values)
+	if len(missing) > 0 {
+		return nil, &MissingConfigValuesError{Fields: missing}
+	}
+
+	return s.dataSources.CreateDataSource(userID, &models.DataSourceCreateRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        template.Type,
+		Config:      filled,
+	})
+}
+
+func newDataSourceTemplateResponse(template *models.DataSourceTemplate) (*models.DataSourceTemplateResponse, error) {
+	var config map[string]interface{}
+	if err := json.Unmarshal(template.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid template configuration: %w", err)
+	}
+
+	return &models.DataSourceTemplateResponse{
+		ID:           template.ID,
+		Name:         template.Name,
+		Description:  template.Description,
+		Type:         template.Type,
+		Config:       config,
+		Placeholders: templatePlaceholders(config),
+		CreatedBy:    template.CreatedBy,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}, nil
+}
+
+// templatePlaceholders returns the distinct placeholder names of a template configuration, sorted
+func templatePlaceholders(config map[string]interface{}) []string {
+	seen := map[string]bool{}
+	for _, value := range config {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		for _, match := range templatePlaceholder.FindAllStringSubmatch(str, -1) {
+			seen[match[1]] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// storedTemplateCredentials returns the credential fields of a template
+// configuration that hold a value rather than a single placeholder, sorted
+func storedTemplateCredentials(config map[string]interface{}) []string {
+	var fields []string
+	for _, field := range models.SensitiveConfigFields {
+		value, ok := config[field]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok || !isSinglePlaceholder(str) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isSinglePlaceholder reports whether the value is exactly one placeholder
+func isSinglePlaceholder(value string) bool {
+	match := templatePlaceholder.FindStringIndex(strings.TrimSpace(value))
+	return match != nil && match[0] == 0 && match[1] == len(strings.TrimSpace(value))
+}
+
+// fillTemplateConfig replaces the placeholders of a template configuration
+// with the values. A value that is a single placeholder takes the value as is,
+// so numbers such as ports keep their type; placeholders inside longer
+// strings are replaced by the value's text. It returns the placeholders
+// without a value, sorted.
+func fillTemplateConfig(config map[string]interface{}, values map[string]interface{}) (map[string]interface{}, []string) {
+	filled := make(map[string]interface{}, len(config))
+	missingSet := map[string]bool{}
+
+	for field, value := range config {
+		str, ok := value.(string)
+		if !ok {
+			filled[field] = value
+			continue
+		}
+
+		if isSinglePlaceholder(str) {
+			name := templatePlaceholder.FindStringSubmatch(str)[1]
+			v, ok := values[name]
+			if !ok || v == "" {
+				missingSet[name] = true
+				continue
+			}
+			filled[field] = v
+			continue
+		}
+
+		filled[field] = templatePlaceholder.ReplaceAllStringFunc(str, func(placeholder string) string {
+			name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+			v, ok := values[name]
+			if !ok || v == "" {
+				missingSet[name] = true
+				return placeholder
+			}
+			return fmt.Sprint(v)
+		})
+	}
+
+	missing := make([]string, 0, len(missingSet))
+	for name := range missingSet {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return filled, missing
+}
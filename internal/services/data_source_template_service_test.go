@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplatePlaceholders(t *testing.T) {
+	config := map[string]interface{}{
+		"host":     "{{host}}",
+		"port":     5432,
+		"database": "analytics_{{ team }}",
+		"username": "{{username}}",
+		"password": "{{password}}",
+		"sslmode":  "require",
+	}
+
+	assert.Equal(t, []string{"host", "password", "team", "username"}, templatePlaceholders(config))
+}
+
+func TestStoredTemplateCredentials(t *testing.T) {
+	assert.Empty(t, storedTemplateCredentials(map[string]interface{}{
+		"host":     "db.internal",
+		"password": "{{password}}",
+	}))
+	assert.Equal(t, []string{"access_token", "password"}, storedTemplateCredentials(map[string]interface{}{
+		"password":     "hunter2",
+		"access_token": "prefix-{{token}}",
+	}))
+}
+
+func TestFillTemplateConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"host":     "{{host}}",
+		"port":     "{{port}}",
+		"database": "analytics_{{team}}",
+		"password": "{{password}}",
+		"sslmode":  "require",
+	}
+
+	filled, missing := fillTemplateConfig(config, map[string]interface{}{
+		"host":     "db.internal",
+		"port":     float64(6432),
+		"team":     "growth",
+		"password": "secret",
+	})
+	assert.Empty(t, missing)
+	assert.Equal(t, map[string]interface{}{
+		"host":     "db.internal",
+		"port":     float64(6432),
+		"database": "analytics_growth",
+		"password": "secret",
+		"sslmode":  "require",
+	}, filled)
+
+	_, missing = fillTemplateConfig(config, map[string]interface{}{"host": "db.internal", "password": ""})
+	assert.Equal(t, []string{"password", "port", "team"}, missing)
+}
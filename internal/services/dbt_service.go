@@ -0,0 +1,621 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDbtManifestInvalid is returned when an uploaded manifest or catalog is not valid dbt JSON
+var ErrDbtManifestInvalid = errors.New("invalid dbt manifest")
+
+// dbtGlossaryCategory marks the glossary terms imported from dbt metrics, which
+// a later import may update
+const dbtGlossaryCategory = "dbt"
+
+// dbtRelationTypes are the manifest resources that are tables of a data source
+var dbtRelationTypes = map[string]bool{"model": true, "seed": true, "snapshot": true, "source": true}
+
+// dbtRefPattern extracts the model of ref('orders') and the source of
+// source('shop', 'orders') in the kwargs of relationships tests
+var dbtRefPattern = regexp.MustCompile(`(ref|source)\(\s*['"]([^'"]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
+
+// DbtService imports the documentation of dbt projects: model, source and
+// column descriptions, tags and tests become column metadata of the matching
+// tables, and metrics become glossary terms, so NL2SQL retrieval sees them
+type DbtService struct {
+	db         *gorm.DB
+	jobs       *JobService
+	embeddings *EmbeddingService
+}
+
+// NewDbtService creates a new dbt import service
+func NewDbtService(db *gorm.DB, jobs *JobService, embeddings *EmbeddingService) *DbtService {
+	return &DbtService{
+		db:         db,
+		jobs:       jobs,
+		embeddings: embeddings,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *DbtService) WithContext(ctx context.Context) *DbtService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.embeddings = s.embeddings.WithContext(ctx)
+	return &scoped
+}
+
+type dbtManifest struct {
+	Metadata struct {
+		DbtVersion  string `json:"dbt_version"`
+		ProjectName string `json:"project_name"`
+	} `json:"metadata"`
+	Nodes   map[string]dbtNode   `json:"nodes"`
+	Sources map[string]dbtNode   `json:"sources"`
+	Metrics map[string]dbtMetric `json:"metrics"`
+}
+
+type dbtNode struct {
+	UniqueID     string                 `json:"unique_id"`
+	ResourceType string                 `json:"resource_type"`
+	Name         string                 `json:"name"`
+	Alias        string                 `json:"alias"`
+	Identifier   string                 `json:"identifier"`  // Sources only
+	SourceName   string                 `json:"source_name"` // Sources only
+	Database     string                 `json:"database"`
+	Schema       string                 `json:"schema"`
+	Description  string                 `json:"description"`
+	Tags         []string               `json:"tags"`
+	Meta         map[string]interface{} `json:"meta"`
+	Columns      map[string]dbtColumn   `json:"columns"`
+
+	// Tests only
+	ColumnName   string           `json:"column_name"`
+	AttachedNode string           `json:"attached_node"`
+	TestMetadata *dbtTestMetadata `json:"test_metadata"`
+	DependsOn    struct {
+		Nodes []string `json:"nodes"`
+	} `json:"depends_on"`
+}
+
+type dbtColumn struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Tags        []string               `json:"tags"`
+	Meta        map[string]interface{} `json:"meta"`
+}
+
+type dbtTestMetadata struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Kwargs    map[string]interface{} `json:"kwargs"`
+}
+
+type dbtMetric struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+type dbtCatalog struct {
+	Nodes   map[string]dbtCatalogNode `json:"nodes"`
+	Sources map[string]dbtCatalogNode `json:"sources"`
+}
+
+type dbtCatalogNode struct {
+	Metadata struct {
+		Database string `json:"database"`
+		Schema   string `json:"schema"`
+		Name     string `json:"name"`
+		Comment  string `json:"comment"`
+	} `json:"metadata"`
+	Columns map[string]struct {
+		Name    string `json:"name"`
+		Comment string `json:"comment"`
+	} `json:"columns"`
+}
+
+// dbtProject is the documentation parsed from a manifest and its catalog
+type dbtProject struct {
+	Name       string
+	DbtVersion string
+	Relations  []dbtRelation
+	Metrics    []dbtMetric
+}
+
+// dbtRelation is a documented model, seed, snapshot or source
+type dbtRelation struct {
+	UniqueID    string
+	Name        string // model name, or source_name.name of sources
+	Database    string
+	Schema      string
+	Identifier  string // Name of the table in the warehouse
+	Description string
+	Tags        []string
+	Tests       []string // Tests of the whole table
+	Columns     []dbtRelationColumn
+}
+
+type dbtRelationColumn struct {
+	Name        string
+	Description string
+	Tags        []string
+	Tests       []string
+	PII         bool // meta: {pii: true}
+}
+
+// Import applies a dbt manifest, and optionally its catalog, to the tables of
+// a data source. Descriptions and tags fill the column metadata the user did
+// not write themselves; tests are always replaced. The changed tables are
+// re-embedded in the background and metrics are upserted as glossary terms.
+func (s *DbtService) Import(ctx context.Context, dataSourceID, userID uint, manifest, catalog []byte) (*models.DbtImportResult, error) {
+	project, err := parseDbtProject(manifest, catalog)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.DbtImportResult{Project: project.Name, DbtVersion: project.DbtVersion}
+
+	var schemas []models.Schema
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schemas: %w", err)
+	}
+	var existing []models.ColumnMetadata
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get column metadata: %w", err)
+	}
+	curated := make(map[string]models.ColumnMetadata, len(existing))
+	for _, m := range existing {
+		curated[columnMetadataKey(m.Table, m.Column)] = m
+	}
+
+	var updates []models.ColumnMetadata
+	reembed := make(map[uint][]string)
+	for _, relation := range project.Relations {
+		schema := matchDbtSchema(relation, schemas)
+		if schema == nil {
+			result.UnmatchedModels = append(result.UnmatchedModels, relation.Name)
+			continue
+		}
+		result.ModelsMatched++
+
+		var columns []models.Column
+		if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+			return nil, fmt.Errorf("failed to parse columns of %s: %w", schema.Name, err)
+		}
+
+		table := dbtRelationColumn{Description: relation.Description, Tags: relation.Tags, Tests: relation.Tests}
+		if m, ok := mergeDbtMetadata(lookupColumnMetadata(curated, schema.Name, ""), table); ok {
+			m.DataSourceID, m.Table, m.UpdatedBy = dataSourceID, schema.Name, userID
+			updates = append(updates, upsertableColumnMetadata(m))
+			curated[columnMetadataKey(schema.Name, "")] = m
+			result.TablesUpdated++
+			reembed[schema.ID] = []string{}
+		}
+		result.TestsImported += len(relation.Tests)
+
+		byName := make(map[string]string, len(columns))
+		for _, column := range columns {
+			byName[strings.ToLower(column.Name)] = column.Name
+		}
+		for _, column := range relation.Columns {
+			name, ok := byName[strings.ToLower(column.Name)]
+			if !ok {
+				continue
+			}
+			result.TestsImported += len(column.Tests)
+			m, ok := mergeDbtMetadata(lookupColumnMetadata(curated, schema.Name, name), column)
+			if !ok {
+				continue
+			}
+			m.DataSourceID, m.Table, m.Column, m.UpdatedBy = dataSourceID, schema.Name, name, userID
+			updates = append(updates, upsertableColumnMetadata(m))
+			curated[columnMetadataKey(schema.Name, name)] = m
+			result.ColumnsUpdated++
+			reembed[schema.ID] = append(reembed[schema.ID], name)
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range updates {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "data_source_id"}, {Name: "table_name"}, {Name: "column_name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"description", "tags", "tests", "pii", "source", "updated_by", "updated_at"}),
+			}).Create(&updates[i]).Error; err != nil {
+				return fmt.Errorf("failed to save column metadata: %w", err)
+			}
+		}
+
+		for i := range schemas {
+			schema := &schemas[i]
+			if _, ok := reembed[schema.ID]; !ok {
+				continue
+			}
+			var columns []models.Column
+			if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+				return fmt.Errorf("failed to parse columns of %s: %w", schema.Name, err)
+			}
+			applyColumnMetadata(columns, schema.Name, curated)
+			info := SchemaInfo{Name: schema.Name, Description: schema.Description}
+			applyTableMetadata(&info, curated)
+			columnsJSON, err := json.Marshal(columns)
+			if err != nil {
+				return fmt.Errorf("failed to marshal columns: %w", err)
+			}
+			if err := tx.Model(schema).Updates(map[string]interface{}{
+				"columns":     models.JSON(columnsJSON),
+				"description": info.Description,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update schema %s: %w", schema.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range schemas {
+		columns, ok := reembed[schemas[i].ID]
+		if !ok {
+			continue
+		}
+		payload := models.SchemaReembedJobPayload{DataSourceID: dataSourceID, Table: schemas[i].Name, Columns: columns}
+		if _, err := s.jobs.Enqueue(ctx, models.JobTypeSchemaReembed, payload); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSourceID).Str("table", schemas[i].Name).Msg("Failed to queue re-embedding of dbt model")
+		}
+	}
+
+	if err := s.importMetrics(ctx, userID, project, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importMetrics upserts the described metrics of a project as glossary terms
+// of the user. Terms of the same name that were not imported from dbt are kept.
+// A failed embedding is logged; the embed-all endpoint picks the term up later.
+func (s *DbtService) importMetrics(ctx context.Context, userID uint, project *dbtProject, result *models.DbtImportResult) error {
+	for _, metric := range project.Metrics {
+		term, err := newDbtGlossaryTerm(userID, project.Name, metric)
+		if err != nil {
+			return err
+		}
+
+		var existing models.BusinessGlossary
+		if err := s.db.Where("user_id = ? AND term = ?", userID, term.Term).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check glossary term %s: %w", term.Term, err)
+		}
+		switch {
+		case existing.ID == 0:
+			if err := s.db.Create(term).Error; err != nil {
+				return fmt.Errorf("failed to create glossary term %s: %w", term.Term, err)
+			}
+			result.GlossaryTermsCreated++
+		case existing.Category != dbtGlossaryCategory:
+			result.GlossaryTermsSkipped++
+			continue
+		case existing.Definition == term.Definition && existing.Domain == term.Domain && bytes.Equal(existing.Synonyms, term.Synonyms):
+			continue
+		default:
+			if err := s.db.Model(&existing).Updates(map[string]interface{}{
+				"definition": term.Definition,
+				"domain":     term.Domain,
+				"synonyms":   term.Synonyms,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update glossary term %s: %w", term.Term, err)
+			}
+			if err := s.db.Where("element_type = ? AND element_name = ? AND user_id = ?", "glossary", term.Term, userID).
+				Delete(&models.SchemaEmbedding{}).Error; err != nil {
+				return fmt.Errorf("failed to delete embedding of glossary term %s: %w", term.Term, err)
+			}
+			term.ID = existing.ID
+			result.GlossaryTermsUpdated++
+		}
+
+		if err := s.embeddings.EmbedGlossaryTerm(ctx, term); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Str("term", term.Term).Msg("Failed to embed dbt glossary term")
+		}
+	}
+	return nil
+}
+
+// parseDbtProject reads the documented relations, their tests and the
+// metrics of a manifest. Catalog comments fill in missing descriptions and
+// its relation names replace the manifest's, as they are the built tables.
+func parseDbtProject(manifestJSON, catalogJSON []byte) (*dbtProject, error) {
+	var manifest dbtManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDbtManifestInvalid, err)
+	}
+	if manifest.Nodes == nil && manifest.Sources == nil {
+		return nil, fmt.Errorf("%w: no nodes or sources", ErrDbtManifestInvalid)
+	}
+	var catalog dbtCatalog
+	if len(catalogJSON) > 0 {
+		if err := json.Unmarshal(catalogJSON, &catalog); err != nil {
+			return nil, fmt.Errorf("%w: catalog: %v", ErrDbtManifestInvalid, err)
+		}
+	}
+
+	project := &dbtProject{Name: manifest.Metadata.ProjectName, DbtVersion: manifest.Metadata.DbtVersion}
+
+	nodes := make(map[string]dbtNode, len(manifest.Nodes)+len(manifest.Sources))
+	for id, node := range manifest.Nodes {
+		nodes[id] = node
+	}
+	for id, node := range manifest.Sources {
+		nodes[id] = node
+	}
+
+	// Tests by tested relation, then by column; "" holds tests of the table
+	tests := make(map[string]map[string][]string)
+	for _, node := range manifest.Nodes {
+		if node.ResourceType != "test" || node.TestMetadata == nil {
+			continue
+		}
+		target := node.AttachedNode
+		if target == "" && len(node.DependsOn.Nodes) == 1 {
+			target = node.DependsOn.Nodes[0]
+		}
+		if !dbtRelationTypes[nodes[target].ResourceType] {
+			continue
+		}
+		column := node.ColumnName
+		if column == "" {
+			column, _ = node.TestMetadata.Kwargs["column_name"].(string)
+		}
+		if tests[target] == nil {
+			tests[target] = make(map[string][]string)
+		}
+		column = strings.ToLower(column)
+		tests[target][column] = append(tests[target][column], describeDbtTest(node.TestMetadata))
+	}
+
+	for id, node := range nodes {
+		if !dbtRelationTypes[node.ResourceType] {
+			continue
+		}
+		relation := dbtRelation{
+			UniqueID:    id,
+			Name:        node.Name,
+			Database:    node.Database,
+			Schema:      node.Schema,
+			Identifier:  firstNonEmpty(node.Alias, node.Identifier, node.Name),
+			Description: strings.TrimSpace(node.Description),
+			Tags:        node.Tags,
+			Tests:       dedupeSorted(tests[id][""]),
+		}
+		if node.ResourceType == "source" && node.SourceName != "" {
+			relation.Name = node.SourceName + "." + node.Name
+		}
+
+		built, inCatalog := catalog.Nodes[id]
+		if !inCatalog {
+			built, inCatalog = catalog.Sources[id]
+		}
+		if inCatalog {
+			relation.Database = firstNonEmpty(built.Metadata.Database, relation.Database)
+			relation.Schema = firstNonEmpty(built.Metadata.Schema, relation.Schema)
+			relation.Identifier = firstNonEmpty(built.Metadata.Name, relation.Identifier)
+			relation.Description = firstNonEmpty(relation.Description, strings.TrimSpace(built.Metadata.Comment))
+		}
+
+		columns := make(map[string]*dbtRelationColumn)
+		for key, column := range node.Columns {
+			name := firstNonEmpty(column.Name, key)
+			pii, _ := column.Meta["pii"].(bool)
+			columns[strings.ToLower(name)] = &dbtRelationColumn{
+				Name:        name,
+				Description: strings.TrimSpace(column.Description),
+				Tags:        column.Tags,
+				PII:         pii,
+			}
+		}
+		if inCatalog {
+			for key, column := range built.Columns {
+				name := firstNonEmpty(column.Name, key)
+				documented, ok := columns[strings.ToLower(name)]
+				if !ok {
+					documented = &dbtRelationColumn{Name: name}
+					columns[strings.ToLower(name)] = documented
+				}
+				documented.Description = firstNonEmpty(documented.Description, strings.TrimSpace(column.Comment))
+			}
+		}
+		for column, columnTests := range tests[id] {
+			if column == "" {
+				continue
+			}
+			documented, ok := columns[column]
+			if !ok {
+				documented = &dbtRelationColumn{Name: column}
+				columns[column] = documented
+			}
+			documented.Tests = dedupeSorted(columnTests)
+		}
+
+		for _, column := range columns {
+			relation.Columns = append(relation.Columns, *column)
+		}
+		sort.Slice(relation.Columns, func(i, j int) bool { return relation.Columns[i].Name < relation.Columns[j].Name })
+		project.Relations = append(project.Relations, relation)
+	}
+	sort.Slice(project.Relations, func(i, j int) bool { return project.Relations[i].UniqueID < project.Relations[j].UniqueID })
+
+	for _, metric := range manifest.Metrics {
+		if strings.TrimSpace(metric.Description) == "" {
+			continue
+		}
+		project.Metrics = append(project.Metrics, metric)
+	}
+	sort.Slice(project.Metrics, func(i, j int) bool { return project.Metrics[i].Name < project.Metrics[j].Name })
+
+	return project, nil
+}
+
+// describeDbtTest names a test the way the NL2SQL prompt can use it, with the
+// accepted values or the referenced column of generic tests
+func describeDbtTest(test *dbtTestMetadata) string {
+	name := test.Name
+	if test.Namespace != "" && test.Namespace != "dbt" {
+		name = test.Namespace + "." + name
+	}
+
+	switch test.Name {
+	case "accepted_values":
+		values, _ := test.Kwargs["values"].([]interface{})
+		parts := make([]string, 0, len(values))
+		for _, value := range values {
+			parts = append(parts, fmt.Sprintf("%v", value))
+		}
+		return fmt.Sprintf("%s(%s)", name, strings.Join(parts, ", "))
+	case "relationships":
+		to, _ := test.Kwargs["to"].(string)
+		field, _ := test.Kwargs["field"].(string)
+		if match := dbtRefPattern.FindStringSubmatch(to); match != nil {
+			to = match[2]
+			if match[3] != "" {
+				to += "." + match[3]
+			}
+		}
+		if to != "" && field != "" {
+			return fmt.Sprintf("%s(%s.%s)", name, to, field)
+		}
+	}
+	return name
+}
+
+// matchDbtSchema finds the table of a data source a dbt relation was built
+// as, by identifier optionally qualified with its schema and database
+func matchDbtSchema(relation dbtRelation, schemas []models.Schema) *models.Schema {
+	candidates := []string{relation.Identifier}
+	if relation.Schema != "" {
+		candidates = append(candidates, relation.Schema+"."+relation.Identifier)
+		if relation.Database != "" {
+			candidates = append(candidates, relation.Database+"."+relation.Schema+"."+relation.Identifier)
+		}
+	}
+	// Qualified names win over a bare table name another schema may share
+	for i := len(candidates) - 1; i >= 0; i-- {
+		for j := range schemas {
+			if strings.EqualFold(schemas[j].Name, candidates[i]) {
+				return &schemas[j]
+			}
+		}
+	}
+	return nil
+}
+
+// mergeDbtMetadata applies the dbt documentation of a table or column to its
+// metadata. Metadata written by users keeps its description unless it has
+// none and gains the dbt tags; metadata imported before is replaced. Tests
+// always come from dbt. Returns false when nothing changed, or when there is
+// nothing to import for a column without metadata.
+func mergeDbtMetadata(existing *models.ColumnMetadata, documented dbtRelationColumn) (models.ColumnMetadata, bool) {
+	tags := normalizeTags(documented.Tags)
+	tests := documented.Tests
+	if tests == nil {
+		tests = []string{}
+	}
+
+	if existing == nil {
+		if documented.Description == "" && len(tags) == 0 && len(tests) == 0 && !documented.PII {
+			return models.ColumnMetadata{}, false
+		}
+		existing = &models.ColumnMetadata{}
+	}
+	merged := *existing
+
+	var currentTags []string
+	if len(existing.Tags) > 0 {
+		_ = json.Unmarshal(existing.Tags, &currentTags)
+	}
+	if existing.ID == 0 || existing.Source == models.ColumnMetadataSourceDbt {
+		merged.Description = documented.Description
+		merged.Source = models.ColumnMetadataSourceDbt
+	} else {
+		merged.Description = firstNonEmpty(existing.Description, documented.Description)
+		tags = normalizeTags(append(currentTags, tags...))
+	}
+	merged.PII = existing.PII || documented.PII
+
+	tagsJSON, _ := json.Marshal(tags)
+	testsJSON, _ := json.Marshal(tests)
+	merged.Tags = models.JSON(tagsJSON)
+	merged.Tests = models.JSON(testsJSON)
+
+	changed := existing.ID == 0 ||
+		merged.Description != existing.Description ||
+		merged.PII != existing.PII ||
+		!bytes.Equal(merged.Tags, existing.Tags) ||
+		!bytes.Equal(merged.Tests, existing.Tests)
+	return merged, changed
+}
+
+// newDbtGlossaryTerm builds the glossary term of a dbt metric, named by its
+// label with the metric name as synonym
+func newDbtGlossaryTerm(userID uint, project string, metric dbtMetric) (*models.BusinessGlossary, error) {
+	synonyms := []string{}
+	if metric.Label != "" && metric.Label != metric.Name {
+		synonyms = append(synonyms, metric.Name)
+	}
+	term, err := newTemplateGlossaryTerm(userID, models.BusinessGlossaryRequest{
+		Term:       firstNonEmpty(metric.Label, metric.Name),
+		Definition: strings.TrimSpace(metric.Description),
+		Synonyms:   synonyms,
+		Category:   dbtGlossaryCategory,
+		Domain:     project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// upsertableColumnMetadata drops the primary key of existing metadata, which
+// is upserted by data source, table and column
+func upsertableColumnMetadata(m models.ColumnMetadata) models.ColumnMetadata {
+	m.ID = 0
+	return m
+}
+
+func lookupColumnMetadata(metadata map[string]models.ColumnMetadata, table, column string) *models.ColumnMetadata {
+	m, ok := metadata[columnMetadataKey(table, column)]
+	if !ok {
+		return nil
+	}
+	return &m
+}
+
+// dedupeSorted returns the distinct values sorted, or nil for none
+func dedupeSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	var distinct []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			distinct = append(distinct, value)
+		}
+	}
+	sort.Strings(distinct)
+	return distinct
+}
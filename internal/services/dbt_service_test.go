@@ -0,0 +1,166 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDbtManifest = `{
+	"metadata": {"dbt_version": "1.7.4", "project_name": "shop"},
+	"nodes": {
+		"model.shop.orders": {
+			"resource_type": "model", "name": "orders", "alias": "fct_orders", "schema": "analytics", "database": "warehouse",
+			"description": "One row per order", "tags": ["Finance"],
+			"columns": {
+				"status": {"name": "status", "description": "Fulfilment status", "tags": ["state"]},
+				"email": {"name": "email", "description": "", "meta": {"pii": true}}
+			}
+		},
+		"model.shop.stg_events": {"resource_type": "model", "name": "stg_events", "schema": "staging", "description": "Raw events"},
+		"test.shop.accepted_values_orders_status": {
+			"resource_type": "test", "column_name": "status", "attached_node": "model.shop.orders",
+			"test_metadata": {"name": "accepted_values", "kwargs": {"column_name": "status", "values": ["placed", "shipped"]}}
+		},
+		"test.shop.not_null_orders_status": {
+			"resource_type": "test", "attached_node": "model.shop.orders",
+			"test_metadata": {"name": "not_null", "kwargs": {"column_name": "status"}}
+		},
+		"test.shop.relationships_orders_customer_id": {
+			"resource_type": "test", "column_name": "customer_id", "depends_on": {"nodes": ["model.shop.orders"]},
+			"test_metadata": {"name": "relationships", "kwargs": {"to": "ref('customers')", "field": "id"}}
+		},
+		"test.shop.orders_recency": {
+			"resource_type": "test", "attached_node": "model.shop.orders",
+			"test_metadata": {"name": "recency", "namespace": "dbt_utils", "kwargs": {}}
+		}
+	},
+	"sources": {
+		"source.shop.crm.customers": {"resource_type": "source", "name": "customers", "source_name": "crm", "identifier": "crm_customers", "schema": "raw", "description": "CRM export"}
+	},
+	"metrics": {
+		"metric.shop.revenue": {"name": "revenue", "label": "Revenue", "description": "Sum of order amounts"},
+		"metric.shop.draft": {"name": "draft", "description": ""}
+	}
+}`
+
+const testDbtCatalog = `{
+	"nodes": {
+		"model.shop.orders": {
+			"metadata": {"name": "FCT_ORDERS", "schema": "ANALYTICS", "comment": "Ignored, the manifest has a description"},
+			"columns": {"AMOUNT": {"name": "AMOUNT", "comment": "Order total"}}
+		}
+	}
+}`
+
+func TestParseDbtProject(t *testing.T) {
+	project, err := parseDbtProject([]byte(testDbtManifest), []byte(testDbtCatalog))
+	require.NoError(t, err)
+	assert.Equal(t, "shop", project.Name)
+	assert.Equal(t, "1.7.4", project.DbtVersion)
+	require.Len(t, project.Relations, 3)
+
+	orders := project.Relations[0]
+	assert.Equal(t, "model.shop.orders", orders.UniqueID)
+	assert.Equal(t, "FCT_ORDERS", orders.Identifier, "the catalog names the built table")
+	assert.Equal(t, "ANALYTICS", orders.Schema)
+	assert.Equal(t, "One row per order", orders.Description)
+	assert.Equal(t, []string{"dbt_utils.recency"}, orders.Tests)
+
+	columns := make(map[string]dbtRelationColumn)
+	for _, column := range orders.Columns {
+		columns[column.Name] = column
+	}
+	assert.Equal(t, "Order total", columns["AMOUNT"].Description, "catalog comments fill in")
+	assert.True(t, columns["email"].PII)
+	assert.Equal(t, []string{"accepted_values(placed, shipped)", "not_null"}, columns["status"].Tests)
+	assert.Equal(t, []string{"relationships(customers.id)"}, columns["customer_id"].Tests)
+
+	customers := project.Relations[2]
+	assert.Equal(t, "crm.customers", customers.Name)
+	assert.Equal(t, "crm_customers", customers.Identifier)
+
+	require.Len(t, project.Metrics, 1, "metrics without a description are skipped")
+	assert.Equal(t, "revenue", project.Metrics[0].Name)
+
+	_, err = parseDbtProject([]byte(`{"metadata": {}}`), nil)
+	assert.ErrorIs(t, err, ErrDbtManifestInvalid)
+	_, err = parseDbtProject([]byte(testDbtManifest), []byte(`not json`))
+	assert.ErrorIs(t, err, ErrDbtManifestInvalid)
+}
+
+func TestMatchDbtSchema(t *testing.T) {
+	schemas := []models.Schema{{ID: 1, Name: "fct_orders"}, {ID: 2, Name: "analytics.fct_orders"}, {ID: 3, Name: "events"}}
+
+	match := matchDbtSchema(dbtRelation{Schema: "ANALYTICS", Identifier: "FCT_ORDERS"}, schemas)
+	require.NotNil(t, match)
+	assert.Equal(t, uint(2), match.ID, "the qualified name wins")
+
+	match = matchDbtSchema(dbtRelation{Schema: "staging", Identifier: "fct_orders"}, schemas)
+	require.NotNil(t, match)
+	assert.Equal(t, uint(1), match.ID)
+
+	assert.Nil(t, matchDbtSchema(dbtRelation{Schema: "staging", Identifier: "stg_events"}, schemas))
+}
+
+func TestMergeDbtMetadata(t *testing.T) {
+	documented := dbtRelationColumn{Description: "Fulfilment status", Tags: []string{"State"}, Tests: []string{"not_null"}}
+
+	created, ok := mergeDbtMetadata(nil, documented)
+	require.True(t, ok)
+	assert.Equal(t, "Fulfilment status", created.Description)
+	assert.Equal(t, models.ColumnMetadataSourceDbt, created.Source)
+	assert.JSONEq(t, `["state"]`, string(created.Tags))
+	assert.JSONEq(t, `["not_null"]`, string(created.Tests))
+
+	_, ok = mergeDbtMetadata(nil, dbtRelationColumn{})
+	assert.False(t, ok, "nothing to import")
+
+	created.ID = 7
+	_, ok = mergeDbtMetadata(&created, documented)
+	assert.False(t, ok, "unchanged since the last import")
+
+	updated, ok := mergeDbtMetadata(&created, dbtRelationColumn{Description: "Order status"})
+	require.True(t, ok)
+	assert.Equal(t, "Order status", updated.Description, "imported metadata is replaced")
+	assert.JSONEq(t, `[]`, string(updated.Tags))
+
+	curated := &models.ColumnMetadata{ID: 8, Description: "Where the order is", Tags: models.JSON(`["status"]`), Source: models.ColumnMetadataSourceUser}
+	merged, ok := mergeDbtMetadata(curated, documented)
+	require.True(t, ok)
+	assert.Equal(t, "Where the order is", merged.Description, "descriptions written by users are kept")
+	assert.Equal(t, models.ColumnMetadataSourceUser, merged.Source)
+	assert.JSONEq(t, `["status","state"]`, string(merged.Tags))
+	assert.JSONEq(t, `["not_null"]`, string(merged.Tests))
+
+	curated.Description = ""
+	merged, _ = mergeDbtMetadata(curated, documented)
+	assert.Equal(t, "Fulfilment status", merged.Description, "empty descriptions are filled")
+}
+
+func TestApplyTableMetadata(t *testing.T) {
+	table := SchemaInfo{Name: "orders", Description: "Discovered comment"}
+	applyTableMetadata(&table, map[string]models.ColumnMetadata{columnMetadataKey("orders", "status"): {Description: "Column"}})
+	assert.Equal(t, "Discovered comment", table.Description)
+
+	applyTableMetadata(&table, map[string]models.ColumnMetadata{columnMetadataKey("orders", ""): {Description: "One row per order"}})
+	assert.Equal(t, "One row per order", table.Description)
+}
+
+func TestNewDbtGlossaryTerm(t *testing.T) {
+	term, err := newDbtGlossaryTerm(3, "shop", dbtMetric{Name: "revenue", Label: "Revenue", Description: " Sum of order amounts "})
+	require.NoError(t, err)
+	assert.Equal(t, "Revenue", term.Term)
+	assert.Equal(t, "Sum of order amounts", term.Definition)
+	assert.Equal(t, dbtGlossaryCategory, term.Category)
+	assert.Equal(t, "shop", term.Domain)
+	assert.JSONEq(t, `["revenue"]`, string(term.Synonyms))
+
+	term, err = newDbtGlossaryTerm(3, "shop", dbtMetric{Name: "orders", Description: "Number of orders"})
+	require.NoError(t, err)
+	assert.Equal(t, "orders", term.Term)
+	assert.JSONEq(t, `[]`, string(term.Synonyms))
+}
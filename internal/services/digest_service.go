@@ -0,0 +1,431 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/mailer"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// digestTopQueries is the number of most-run questions listed in a digest
+	digestTopQueries = 5
+	// digestRunBatch bounds the subscriptions handled by one run of the digest job
+	digestRunBatch = 200
+	// digestRetryDelay is how long a digest waits before another attempt after a failed send
+	digestRetryDelay = time.Hour
+)
+
+// DigestService composes the workspace digest of a user and emails it on the
+// user's schedule. RunDue is meant to be called periodically by a cron job.
+type DigestService struct {
+	db     *gorm.DB
+	sender mailer.Sender
+	now    func() time.Time
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(db *gorm.DB, sender mailer.Sender) *DigestService {
+	return &DigestService{
+		db:     db,
+		sender: sender,
+		now:    time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *DigestService) WithContext(ctx context.Context) *DigestService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// GetSubscription returns the digest preference of a user; users who never
+// subscribed get an "off" preference
+func (s *DigestService) GetSubscription(userID uint) (*models.DigestSubscription, error) {
+	var subscription models.DigestSubscription
+	err := s.db.Where("user_id = ?", userID).First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.DigestSubscription{UserID: userID, Frequency: models.DigestFrequencyOff}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// UpdateSubscription sets the digest frequency of a user. Changing the frequency
+// schedules the first digest one period from now.
+func (s *DigestService) UpdateSubscription(userID uint, req *models.DigestSubscriptionRequest) (*models.DigestSubscription, error) {
+	subscription, err := s.GetSubscription(userID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.ID != 0 && subscription.Frequency == req.Frequency {
+		return subscription, nil
+	}
+
+	subscription.Frequency = req.Frequency
+	subscription.NextRunAt = nextDigestRun(req.Frequency, s.now())
+	if err := s.db.Save(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to save digest subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// Preview composes the digest a user would receive now. A user without a
+// subscription previews the weekly digest.
+func (s *DigestService) Preview(userID uint, frequency models.DigestFrequency) (*models.Digest, error) {
+	if frequency == "" {
+		subscription, err := s.GetSubscription(userID)
+		if err != nil {
+			return nil, err
+		}
+		frequency = subscription.Frequency
+	}
+	if frequency != models.DigestFrequencyDaily {
+		frequency = models.DigestFrequencyWeekly
+	}
+	return s.Compose(userID, frequency, s.now())
+}
+
+// RunDue sends every digest that is due. Each subscription is claimed by moving
+// its next run forward before sending, so instances running the job at the same
+// time do not send a digest twice.
+func (s *DigestService) RunDue(ctx context.Context) (*models.DigestRunResult, error) {
+	now := s.now()
+
+	var subscriptions []models.DigestSubscription
+	err := s.db.Where("frequency <> ? AND next_run_at <= ?", models.DigestFrequencyOff, now).
+		Order("next_run_at").Limit(digestRunBatch).Find(&subscriptions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due digests: %w", err)
+	}
+
+	result := &models.DigestRunResult{Due: len(subscriptions)}
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			break
+		}
+
+		claim := s.db.Model(&models.DigestSubscription{}).
+			Where("id = ? AND next_run_at = ?", subscription.ID, subscription.NextRunAt).
+			Updates(map[string]interface{}{
+				"next_run_at":  nextDigestRun(subscription.Frequency, now),
+				"last_sent_at": now,
+			})
+		if claim.Error != nil {
+			return result, fmt.Errorf("failed to claim digest: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		sent, err := s.send(ctx, &subscription, now)
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("user_id", subscription.UserID).Msg("Failed to send digest")
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("user %d: %v", subscription.UserID, err))
+			s.db.Model(&models.DigestSubscription{}).Where("id = ?", subscription.ID).Updates(map[string]interface{}{
+				"next_run_at":  now.Add(digestRetryDelay),
+				"last_sent_at": subscription.LastSentAt,
+			})
+			continue
+		}
+		if !sent {
+			result.Skipped++
+			continue
+		}
+		result.Sent++
+	}
+	return result, nil
+}
+
+// send composes and emails the digest of a subscription. It reports false
+// without an error for users who are inactive or deleted.
+func (s *DigestService) send(ctx context.Context, subscription *models.DigestSubscription, now time.Time) (bool, error) {
+	var user models.User
+	if err := s.db.First(&user, subscription.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive {
+		return false, nil
+	}
+
+	digest, err := s.Compose(user.ID, subscription.Frequency, now)
+	if err != nil {
+		return false, err
+	}
+
+	msg, err := renderDigestEmail(&user, digest)
+	if err != nil {
+		return false, err
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Compose gathers the digest of a user for the period of the given frequency ending at end
+func (s *DigestService) Compose(userID uint, frequency models.DigestFrequency, end time.Time) (*models.Digest, error) {
+	period := digestPeriod(frequency)
+	digest := &models.Digest{
+		UserID:      userID,
+		Frequency:   frequency,
+		PeriodStart: end.Add(-period),
+		PeriodEnd:   end,
+	}
+
+	err := s.db.Model(&models.NL2SQLQuery{}).
+		Select("nl_query, COUNT(*) AS run_count").
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, digest.PeriodStart, end).
+		Group("nl_query").Order("run_count DESC, nl_query").Limit(digestTopQueries).
+		Scan(&digest.TopQueries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top queries: %w", err)
+	}
+
+	var kpis []models.KPIDefinition
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&kpis).Error; err != nil {
+		return nil, fmt.Errorf("failed to get KPI definitions: %w", err)
+	}
+	var snapshots []models.KPISnapshot
+	err = s.db.Where("user_id = ? AND recorded_at >= ? AND recorded_at < ?", userID, digest.PeriodStart.Add(-period), end).
+		Order("recorded_at").Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KPI snapshots: %w", err)
+	}
+	digest.KPIMovements = kpiMovements(kpis, snapshots, digest.PeriodStart)
+
+	var dataSources []models.DataSource
+	if err := s.db.Where("user_id = ?", userID).Find(&dataSources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get data sources: %w", err)
+	}
+	names := make(map[uint]string, len(dataSources))
+	ids := make([]uint, 0, len(dataSources))
+	digest.NewDataSources = []models.DigestDataSource{}
+	for _, ds := range dataSources {
+		names[ds.ID] = ds.Name
+		ids = append(ids, ds.ID)
+		if !ds.CreatedAt.Before(digest.PeriodStart) && ds.CreatedAt.Before(end) {
+			digest.NewDataSources = append(digest.NewDataSources, models.DigestDataSource{
+				ID:        ds.ID,
+				Name:      ds.Name,
+				Type:      ds.Type,
+				CreatedAt: ds.CreatedAt,
+			})
+		}
+	}
+
+	var runs []models.SchemaSyncRun
+	if len(ids) > 0 {
+		err = s.db.Where("data_source_id IN ? AND status = ? AND started_at >= ? AND started_at < ?",
+			ids, models.SchemaSyncStatusFailed, digest.PeriodStart, end).
+			Order("started_at").Find(&runs).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to get failed syncs: %w", err)
+		}
+	}
+	digest.FailedSyncs = summarizeFailedSyncs(runs, names)
+
+	if digest.TopQueries == nil {
+		digest.TopQueries = []models.DigestQuery{}
+	}
+	return digest, nil
+}
+
+// digestPeriod is the length of the period a digest covers
+func digestPeriod(frequency models.DigestFrequency) time.Duration {
+	if frequency == models.DigestFrequencyDaily {
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// nextDigestRun returns when the next digest is due after from
+func nextDigestRun(frequency models.DigestFrequency, from time.Time) time.Time {
+	return from.Add(digestPeriod(frequency))
+}
+
+// kpiMovements compares the last snapshot of each KPI in the period starting at
+// periodStart with its last snapshot before it. Snapshots must be ordered by
+// recorded_at; KPIs not observed in the period are left out.
+func kpiMovements(kpis []models.KPIDefinition, snapshots []models.KPISnapshot, periodStart time.Time) []models.DigestKPIMovement {
+	current := make(map[uint]float64)
+	previous := make(map[uint]float64)
+	for _, snapshot := range snapshots {
+		if snapshot.RecordedAt.Before(periodStart) {
+			previous[snapshot.KPIID] = snapshot.Value
+		} else {
+			current[snapshot.KPIID] = snapshot.Value
+		}
+	}
+
+	movements := []models.DigestKPIMovement{}
+	for _, kpi := range kpis {
+		value, ok := current[kpi.ID]
+		if !ok {
+			continue
+		}
+
+		name := kpi.DisplayName
+		if name == "" {
+			name = kpi.Name
+		}
+		movement := models.DigestKPIMovement{KPIID: kpi.ID, Name: name, Unit: kpi.Unit, Current: value}
+		if prior, ok := previous[kpi.ID]; ok {
+			change := value - prior
+			movement.Previous = &prior
+			movement.Change = &change
+			if prior != 0 {
+				percent := change / prior * 100
+				movement.ChangePercent = &percent
+			}
+		}
+		movements = append(movements, movement)
+	}
+
+	sort.SliceStable(movements, func(i, j int) bool { return movements[i].Name < movements[j].Name })
+	return movements
+}
+
+// summarizeFailedSyncs groups failed sync runs by data source. Runs must be
+// ordered by started_at so the last error wins.
+func summarizeFailedSyncs(runs []models.SchemaSyncRun, names map[uint]string) []models.DigestFailedSync {
+	byDataSource := make(map[uint]*models.DigestFailedSync)
+	var order []uint
+	for _, run := range runs {
+		summary, ok := byDataSource[run.DataSourceID]
+		if !ok {
+			summary = &models.DigestFailedSync{DataSourceID: run.DataSourceID, DataSourceName: names[run.DataSourceID]}
+			byDataSource[run.DataSourceID] = summary
+			order = append(order, run.DataSourceID)
+		}
+		summary.Failures++
+		summary.LastError = run.Message
+		summary.LastFailedAt = run.StartedAt
+	}
+
+	failed := make([]models.DigestFailedSync, 0, len(order))
+	for _, id := range order {
+		failed = append(failed, *byDataSource[id])
+	}
+	sort.SliceStable(failed, func(i, j int) bool { return failed[i].Failures > failed[j].Failures })
+	return failed
+}
+
+// digestHTMLTemplate renders the HTML part of the digest email
+var digestHTMLTemplate = template.Must(template.New("digest").Parse(`<h2>{{.Title}}</h2>
+<p>{{.Period}}</p>
+<h3>Top queries</h3>
+{{if .Digest.TopQueries}}<ol>{{range .Digest.TopQueries}}<li>{{.NLQuery}} ({{.RunCount}}×)</li>{{end}}</ol>{{else}}<p>No queries were run.</p>{{end}}
+<h3>KPI movements</h3>
+{{if .KPIs}}<ul>{{range .KPIs}}<li>{{.}}</li>{{end}}</ul>{{else}}<p>No KPI values were recorded.</p>{{end}}
+<h3>Failed syncs</h3>
+{{if .Digest.FailedSyncs}}<ul>{{range .Digest.FailedSyncs}}<li>{{.DataSourceName}}: {{.Failures}} failed, last error: {{.LastError}}</li>{{end}}</ul>{{else}}<p>All syncs succeeded.</p>{{end}}
+<h3>New data sources</h3>
+{{if .Digest.NewDataSources}}<ul>{{range .Digest.NewDataSources}}<li>{{.Name}} ({{.Type}})</li>{{end}}</ul>{{else}}<p>No data sources were added.</p>{{end}}
+`))
+
+// renderDigestEmail renders the digest as a text and HTML email to the user
+func renderDigestEmail(user *models.User, digest *models.Digest) (mailer.Message, error) {
+	title := fmt.Sprintf("Your NaraPulse %s digest", digest.Frequency)
+	period := fmt.Sprintf("%s – %s", digest.PeriodStart.Format("Jan 2, 2006"), digest.PeriodEnd.Format("Jan 2, 2006"))
+
+	kpis := make([]string, len(digest.KPIMovements))
+	for i, movement := range digest.KPIMovements {
+		kpis[i] = describeKPIMovement(movement)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\n%s\n\nTop queries\n", title, period)
+	if len(digest.TopQueries) == 0 {
+		text.WriteString("- No queries were run.\n")
+	}
+	for i, query := range digest.TopQueries {
+		fmt.Fprintf(&text, "%d. %s (%d×)\n", i+1, query.NLQuery, query.RunCount)
+	}
+	text.WriteString("\nKPI movements\n")
+	if len(kpis) == 0 {
+		text.WriteString("- No KPI values were recorded.\n")
+	}
+	for _, kpi := range kpis {
+		text.WriteString("- " + kpi + "\n")
+	}
+	text.WriteString("\nFailed syncs\n")
+	if len(digest.FailedSyncs) == 0 {
+		text.WriteString("- All syncs succeeded.\n")
+	}
+	for _, sync := range digest.FailedSyncs {
+		fmt.Fprintf(&text, "- %s: %d failed, last error: %s\n", sync.DataSourceName, sync.Failures, sync.LastError)
+	}
+	text.WriteString("\nNew data sources\n")
+	if len(digest.NewDataSources) == 0 {
+		text.WriteString("- No data sources were added.\n")
+	}
+	for _, ds := range digest.NewDataSources {
+		fmt.Fprintf(&text, "- %s (%s)\n", ds.Name, ds.Type)
+	}
+
+	var html bytes.Buffer
+	err := digestHTMLTemplate.Execute(&html, map[string]interface{}{
+		"Title":  title,
+		"Period": period,
+		"Digest": digest,
+		"KPIs":   kpis,
+	})
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	return mailer.Message{
+		To:       []string{user.Email},
+		Subject:  fmt.Sprintf("%s (%s)", title, period),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
+
+// describeKPIMovement phrases a KPI movement, e.g. "Revenue: 12,500 USD (up 4.2% from 12,000 USD)"
+func describeKPIMovement(movement models.DigestKPIMovement) string {
+	current := formatAnswerValue(movement.Current, movement.Unit)
+	if movement.Previous == nil {
+		return fmt.Sprintf("%s: %s (no value in the prior period)", movement.Name, current)
+	}
+
+	previous := formatAnswerValue(*movement.Previous, movement.Unit)
+	switch {
+	case *movement.Change == 0:
+		return fmt.Sprintf("%s: %s (unchanged)", movement.Name, current)
+	case movement.ChangePercent == nil:
+		return fmt.Sprintf("%s: %s (from %s)", movement.Name, current, previous)
+	}
+
+	direction := "up"
+	if *movement.Change < 0 {
+		direction = "down"
+	}
+	percent := *movement.ChangePercent
+	if percent < 0 {
+		percent = -percent
+	}
+	return fmt.Sprintf("%s: %s (%s %s%% from %s)", movement.Name, current, direction, formatAnswerValue(percent, ""), previous)
+}
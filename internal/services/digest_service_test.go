@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDigestRun(t *testing.T) {
+	from := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, from.AddDate(0, 0, 1), nextDigestRun(models.DigestFrequencyDaily, from))
+	assert.Equal(t, from.AddDate(0, 0, 7), nextDigestRun(models.DigestFrequencyWeekly, from))
+}
+
+func TestKPIMovements(t *testing.T) {
+	periodStart := time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC)
+	kpis := []models.KPIDefinition{
+		{ID: 1, Name: "revenue", Unit: "USD"},
+		{ID: 2, Name: "orders", DisplayName: "Orders"},
+		{ID: 3, Name: "churn"},
+		{ID: 4, Name: "signups"},
+	}
+	snapshots := []models.KPISnapshot{
+		{KPIID: 1, Value: 900, RecordedAt: periodStart.Add(-48 * time.Hour)},
+		{KPIID: 1, Value: 1000, RecordedAt: periodStart.Add(-time.Hour)},
+		{KPIID: 2, Value: 0, RecordedAt: periodStart.Add(-time.Hour)},
+		{KPIID: 3, Value: 5, RecordedAt: periodStart.Add(-time.Hour)},
+		{KPIID: 1, Value: 1200, RecordedAt: periodStart.Add(time.Hour)},
+		{KPIID: 1, Value: 1250, RecordedAt: periodStart.Add(48 * time.Hour)},
+		{KPIID: 2, Value: 10, RecordedAt: periodStart.Add(time.Hour)},
+		{KPIID: 4, Value: 3, RecordedAt: periodStart.Add(time.Hour)},
+	}
+
+	movements := kpiMovements(kpis, snapshots, periodStart)
+	require.Len(t, movements, 3, "churn was not observed in the period")
+
+	assert.Equal(t, "Orders", movements[0].Name)
+	require.NotNil(t, movements[0].Previous)
+	assert.Equal(t, 10.0, *movements[0].Change)
+	assert.Nil(t, movements[0].ChangePercent, "previous value is zero")
+
+	assert.Equal(t, "revenue", movements[1].Name)
+	assert.Equal(t, 1250.0, movements[1].Current)
+	assert.Equal(t, 1000.0, *movements[1].Previous)
+	assert.Equal(t, 25.0, *movements[1].ChangePercent)
+
+	assert.Equal(t, "signups", movements[2].Name)
+	assert.Nil(t, movements[2].Previous)
+}
+
+func TestSummarizeFailedSyncs(t *testing.T) {
+	start := time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC)
+	runs := []models.SchemaSyncRun{
+		{DataSourceID: 1, Message: "timeout", StartedAt: start},
+		{DataSourceID: 2, Message: "auth failed", StartedAt: start.Add(time.Hour)},
+		{DataSourceID: 2, Message: "auth expired", StartedAt: start.Add(2 * time.Hour)},
+	}
+
+	failed := summarizeFailedSyncs(runs, map[uint]string{1: "Sales DB", 2: "Ads"})
+	require.Len(t, failed, 2)
+	assert.Equal(t, models.DigestFailedSync{
+		DataSourceID:   2,
+		DataSourceName: "Ads",
+		Failures:       2,
+		LastError:      "auth expired",
+		LastFailedAt:   start.Add(2 * time.Hour),
+	}, failed[0])
+	assert.Equal(t, "Sales DB", failed[1].DataSourceName)
+
+	assert.Empty(t, summarizeFailedSyncs(nil, nil))
+}
+
+func TestDescribeKPIMovement(t *testing.T) {
+	previous, change, percent := 12000.0, 500.0, 4.1666
+	assert.Equal(t, "Revenue: 12,500 USD (up 4.17% from 12,000 USD)", describeKPIMovement(models.DigestKPIMovement{
+		Name: "Revenue", Unit: "USD", Current: 12500, Previous: &previous, Change: &change, ChangePercent: &percent,
+	}))
+
+	down, downPercent := -2.0, -20.0
+	ten := 10.0
+	assert.Equal(t, "Churn: 8% (down 20% from 10%)", describeKPIMovement(models.DigestKPIMovement{
+		Name: "Churn", Unit: "percentage", Current: 8, Previous: &ten, Change: &down, ChangePercent: &downPercent,
+	}))
+
+	assert.Equal(t, "Orders: 10 (no value in the prior period)", describeKPIMovement(models.DigestKPIMovement{Name: "Orders", Current: 10}))
+}
+
+func TestRenderDigestEmail(t *testing.T) {
+	digest := &models.Digest{
+		Frequency:   models.DigestFrequencyWeekly,
+		PeriodStart: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC),
+		TopQueries:  []models.DigestQuery{{NLQuery: "revenue <by> region", RunCount: 3}},
+		FailedSyncs: []models.DigestFailedSync{{DataSourceName: "Ads", Failures: 1, LastError: "auth failed"}},
+	}
+
+	msg, err := renderDigestEmail(&models.User{Email: "ann@example.com"}, digest)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ann@example.com"}, msg.To)
+	assert.Equal(t, "Your NaraPulse weekly digest (Sep 1, 2025 – Sep 8, 2025)", msg.Subject)
+	assert.Contains(t, msg.TextBody, "1. revenue <by> region (3×)")
+	assert.Contains(t, msg.TextBody, "- No KPI values were recorded.")
+	assert.Contains(t, msg.TextBody, "- Ads: 1 failed, last error: auth failed")
+	assert.Contains(t, msg.TextBody, "- No data sources were added.")
+	assert.Contains(t, msg.HTMLBody, "revenue &lt;by&gt; region")
+	assert.NotContains(t, msg.HTMLBody, "<by>")
+}
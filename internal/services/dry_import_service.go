@@ -0,0 +1,491 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"narapulse-be/internal/connectors"
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// dryImportScanRows bounds the data rows scanned per table; larger tables get an estimated row count
+const dryImportScanRows = 100000
+
+// dryImportMaxExamples bounds the rows and values listed per issue
+const dryImportMaxExamples = 5
+
+// importTypeAccepts lists the value kinds each inferred column type accepts.
+// "binary" is a 0 or 1, which fits integer, float and boolean columns.
+var importTypeAccepts = []struct {
+	columnType string
+	kinds      map[string]bool
+}{
+	{"integer", map[string]bool{"integer": true, "binary": true}},
+	{"float", map[string]bool{"integer": true, "float": true, "binary": true}},
+	{"boolean", map[string]bool{"boolean": true, "binary": true}},
+	{"date", map[string]bool{"date": true}},
+	{"datetime", map[string]bool{"date": true, "datetime": true}},
+	{"time", map[string]bool{"time": true}},
+}
+
+// sheetRowReader is implemented by connectors that return raw spreadsheet rows
+type sheetRowReader interface {
+	Connect(config map[string]interface{}) error
+	Disconnect() error
+	ListSheets() ([]connectors.SheetInfo, error)
+	GetRows(sheetName string, limit int) ([][]string, error)
+}
+
+// DryImportService validates files and spreadsheets without creating a data
+// source, so users can fix problems before committing to an import
+type DryImportService struct {
+	inference      *SchemaInferenceService
+	newSheetReader func() sheetRowReader
+}
+
+// NewDryImportService creates a new dry import service
+func NewDryImportService() *DryImportService {
+	return &DryImportService{
+		inference: NewSchemaInferenceService(),
+		newSheetReader: func() sheetRowReader {
+			return connectors.NewGoogleSheetsConnector()
+		},
+	}
+}
+
+// AnalyzeFile reports the inferred schema and problems of an uploaded CSV or Excel file
+func (s *DryImportService) AnalyzeFile(file *multipart.FileHeader) (*models.DryImportReport, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	tableName := strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))
+	report := &models.DryImportReport{FileName: file.Filename}
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		report.Type = models.DataSourceTypeCSV
+		err = s.analyzeCSV(report, src, file.Size, tableName)
+	case ".xlsx", ".xls":
+		report.Type = models.DataSourceTypeExcel
+		err = s.analyzeExcel(report, src)
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s", filepath.Ext(file.Filename))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	finishDryImportReport(report)
+	return report, nil
+}
+
+// AnalyzeGoogleSheets reports the inferred schema and problems of each sheet of a
+// spreadsheet, or only of the configured sheet_name
+func (s *DryImportService) AnalyzeGoogleSheets(config map[string]interface{}) (*models.DryImportReport, error) {
+	reader := s.newSheetReader()
+	if err := reader.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to Google Sheets: %w", err)
+	}
+	defer reader.Disconnect()
+
+	sheets, err := reader.ListSheets()
+	if err != nil {
+		return nil, err
+	}
+
+	sheetName, _ := config["sheet_name"].(string)
+	report := &models.DryImportReport{Type: models.DataSourceTypeGoogleSheets}
+	for _, sheet := range sheets {
+		if sheetName != "" && sheet.Title != sheetName {
+			continue
+		}
+
+		rows, err := reader.GetRows(sheet.Title, dryImportScanRows+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", sheet.Title, err)
+		}
+
+		rowCount, estimated := int64(len(rows)-1), false
+		if len(rows) > dryImportScanRows && sheet.RowCount > rowCount {
+			// The grid may contain empty trailing rows, so this is an upper bound
+			rowCount, estimated = sheet.RowCount-1, true
+		}
+		s.analyzeRows(report, sheet.Title, rows, rowCount, estimated, false)
+	}
+
+	if len(report.Tables) == 0 {
+		if sheetName != "" {
+			return nil, fmt.Errorf("sheet %q not found", sheetName)
+		}
+		return nil, errors.New("spreadsheet has no sheets")
+	}
+
+	finishDryImportReport(report)
+	return report, nil
+}
+
+// analyzeCSV scans a CSV file, estimating the row count from the bytes read
+// when the file has more rows than are scanned
+func (s *DryImportService) analyzeCSV(report *models.DryImportReport, r io.Reader, size int64, tableName string) error {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(2); bytes.Equal(bom, []byte{0xFF, 0xFE}) || bytes.Equal(bom, []byte{0xFE, 0xFF}) {
+		report.Tables = append(report.Tables, models.DryImportTable{Name: tableName, Columns: []models.Column{}})
+		report.Issues = append(report.Issues, models.DryImportIssue{
+			Severity: models.DryImportSeverityError,
+			Code:     models.DryImportIssueInvalidEncoding,
+			Table:    tableName,
+			Message:  "File is UTF-16 encoded; save it as UTF-8 CSV",
+		})
+		return nil
+	}
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1 // Ragged rows are reported, not rejected
+
+	var rows [][]string
+	malformed := &models.DryImportIssue{
+		Severity: models.DryImportSeverityError,
+		Code:     models.DryImportIssueMalformedRow,
+		Table:    tableName,
+	}
+	estimated := false
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			malformed.Count++
+			if len(malformed.Rows) < dryImportMaxExamples {
+				malformed.Rows = append(malformed.Rows, parseErr.Line)
+				malformed.Examples = append(malformed.Examples, parseErr.Err.Error())
+			}
+			if malformed.Count > dryImportScanRows {
+				break
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV file: %w", err)
+		}
+
+		rows = append(rows, record)
+		if len(rows) > dryImportScanRows {
+			estimated = true
+			break
+		}
+	}
+
+	rowCount := int64(len(rows) - 1)
+	if estimated {
+		if offset := reader.InputOffset(); offset > 0 {
+			rowCount = int64(float64(rowCount) * float64(size) / float64(offset))
+		}
+	}
+	if malformed.Count > 0 {
+		malformed.Message = fmt.Sprintf("%d rows could not be parsed (unbalanced quotes or stray characters); rows lists line numbers", malformed.Count)
+		report.Issues = append(report.Issues, *malformed)
+	}
+
+	s.analyzeRows(report, tableName, rows, rowCount, estimated, true)
+	return nil
+}
+
+// analyzeExcel analyzes every sheet of a workbook
+func (s *DryImportService) analyzeExcel(report *models.DryImportReport, r io.Reader) error {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to parse Excel file: %w", err)
+	}
+	defer f.Close()
+
+	for _, sheetName := range f.GetSheetList() {
+		rows, err := f.GetRows(sheetName)
+		if err != nil {
+			return fmt.Errorf("failed to read sheet %s: %w", sheetName, err)
+		}
+
+		rowCount := int64(len(rows) - 1)
+		if len(rows) > dryImportScanRows+1 {
+			rows = rows[:dryImportScanRows+1]
+		}
+		s.analyzeRows(report, sheetName, rows, rowCount, false, false)
+	}
+
+	return nil
+}
+
+// analyzeRows infers the schema of one table and reports header, encoding,
+// shape and type problems. rows[0] is the header row. Spreadsheets drop
+// trailing empty cells, so short rows are only reported when strictWidth is set.
+func (s *DryImportService) analyzeRows(report *models.DryImportReport, tableName string, rows [][]string, rowCount int64, estimated, strictWidth bool) {
+	table := models.DryImportTable{
+		Name:              tableName,
+		Columns:           []models.Column{},
+		RowCountEstimated: estimated,
+	}
+
+	if len(rows) == 0 || strings.TrimSpace(strings.Join(rows[0], "")) == "" {
+		report.Tables = append(report.Tables, table)
+		report.Issues = append(report.Issues, models.DryImportIssue{
+			Severity: models.DryImportSeverityError,
+			Code:     models.DryImportIssueEmptyFile,
+			Table:    tableName,
+			Message:  "No header row found",
+		})
+		return
+	}
+
+	header := rows[0]
+	data := rows[1:]
+	table.RowCount = rowCount
+	table.RowsScanned = int64(len(data))
+
+	// Header problems
+	names := make([]string, len(header))
+	firstByName := make(map[string]int)
+	for i, raw := range header {
+		name := strings.TrimSpace(raw)
+		names[i] = name
+		if name == "" {
+			names[i] = fmt.Sprintf("column_%d", i+1)
+			report.Issues = append(report.Issues, models.DryImportIssue{
+				Severity: models.DryImportSeverityError,
+				Code:     models.DryImportIssueEmptyHeader,
+				Table:    tableName,
+				Column:   names[i],
+				Message:  fmt.Sprintf("Column %d has no header", i+1),
+			})
+			continue
+		}
+		key := strings.ToLower(name)
+		if first, ok := firstByName[key]; ok {
+			report.Issues = append(report.Issues, models.DryImportIssue{
+				Severity: models.DryImportSeverityError,
+				Code:     models.DryImportIssueDuplicateHeader,
+				Table:    tableName,
+				Column:   name,
+				Message:  fmt.Sprintf("Header %q is used by columns %d and %d", name, first+1, i+1),
+			})
+			continue
+		}
+		firstByName[key] = i
+	}
+
+	// Rows wider than the header have data without a column name
+	ragged := models.DryImportIssue{
+		Severity: models.DryImportSeverityWarning,
+		Code:     models.DryImportIssueRaggedRow,
+		Table:    tableName,
+	}
+	for i, row := range data {
+		if len(row) > len(header) || (strictWidth && len(row) < len(header)) {
+			addIssueExample(&ragged, i+2, fmt.Sprintf("%d cells", len(row)))
+		}
+	}
+	if ragged.Count > 0 {
+		ragged.Message = fmt.Sprintf("%d rows do not have %d cells like the header row", ragged.Count, len(header))
+		report.Issues = append(report.Issues, ragged)
+	}
+
+	// Encoding in the header row
+	for i, raw := range header {
+		if !utf8.ValidString(raw) {
+			report.Issues = append(report.Issues, encodingIssue(tableName, names[i], 1, 1))
+		}
+	}
+
+	for col := range header {
+		column, issues := s.analyzeColumn(tableName, names[col], data, col)
+		table.Columns = append(table.Columns, column)
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	for i := 0; i < len(data) && i < sampleRowLimit; i++ {
+		sample := make(map[string]interface{}, len(names))
+		for col, name := range names {
+			if col < len(data[i]) {
+				sample[name] = data[i][col]
+			}
+		}
+		table.SampleData = append(table.SampleData, sample)
+	}
+
+	report.Tables = append(report.Tables, table)
+}
+
+// analyzeColumn infers the type of a column and reports encoding problems and
+// values that do not fit the inferred type
+func (s *DryImportService) analyzeColumn(tableName, name string, data [][]string, col int) (models.Column, []models.DryImportIssue) {
+	column := models.Column{Name: name, Type: "string", Nullable: len(data) == 0}
+
+	invalid := encodingIssue(tableName, name, 0, 0)
+	replaced := models.DryImportIssue{
+		Severity: models.DryImportSeverityWarning,
+		Code:     models.DryImportIssueReplacementChar,
+		Table:    tableName,
+		Column:   name,
+	}
+
+	var values []string
+	var valueRows []int
+	seen := make(map[string]bool)
+	for i, row := range data {
+		value := ""
+		if col < len(row) {
+			value = strings.TrimSpace(row[col])
+		}
+		if value == "" {
+			column.Nullable = true
+			continue
+		}
+
+		if !utf8.ValidString(value) {
+			addIssueExample(&invalid, i+2, "")
+			continue
+		}
+		if strings.ContainsRune(value, utf8.RuneError) {
+			addIssueExample(&replaced, i+2, value)
+		}
+
+		values = append(values, value)
+		valueRows = append(valueRows, i+2)
+		if !seen[value] && len(column.SampleValues) < sampleRowLimit {
+			seen[value] = true
+			column.SampleValues = append(column.SampleValues, value)
+		}
+	}
+
+	var issues []models.DryImportIssue
+	if invalid.Count > 0 {
+		invalid.Message = fmt.Sprintf("%d values are not valid UTF-8; re-save the file as UTF-8", invalid.Count)
+		issues = append(issues, invalid)
+	}
+	if replaced.Count > 0 {
+		replaced.Message = fmt.Sprintf("%d values contain the replacement character, the file was probably converted from another encoding", replaced.Count)
+		issues = append(issues, replaced)
+	}
+
+	columnType, conflicts := s.inferImportColumnType(values)
+	column.Type = columnType
+	if len(conflicts) > 0 {
+		conflict := models.DryImportIssue{
+			Severity: models.DryImportSeverityWarning,
+			Code:     models.DryImportIssueTypeConflict,
+			Table:    tableName,
+			Column:   name,
+		}
+		for _, i := range conflicts {
+			addIssueExample(&conflict, valueRows[i], values[i])
+		}
+		conflict.Message = fmt.Sprintf("%d values are not %s like the rest of the column and will be imported as NULL or text", conflict.Count, columnType)
+		issues = append(issues, conflict)
+	}
+
+	return column, issues
+}
+
+// inferImportColumnType picks the type most values fit, requiring 70% like
+// SchemaInferenceService, and returns the indexes of values that do not fit
+func (s *DryImportService) inferImportColumnType(values []string) (string, []int) {
+	if len(values) == 0 {
+		return "string", nil
+	}
+
+	kinds := make([]string, len(values))
+	kindCounts := make(map[string]int)
+	for i, value := range values {
+		kind := s.inference.detectValueType(value)
+		if kind == "boolean" && (value == "0" || value == "1") {
+			kind = "binary"
+		}
+		kinds[i] = kind
+		kindCounts[kind]++
+	}
+
+	best, bestFits := -1, 0
+	for i, candidate := range importTypeAccepts {
+		fits := 0
+		for kind, count := range kindCounts {
+			if candidate.kinds[kind] {
+				fits += count
+			}
+		}
+		if fits > bestFits {
+			best, bestFits = i, fits
+		}
+	}
+	if best < 0 || float64(bestFits) < float64(len(values))*0.7 {
+		return "string", nil
+	}
+
+	accepted := importTypeAccepts[best]
+	var conflicts []int
+	for i, kind := range kinds {
+		if !accepted.kinds[kind] {
+			conflicts = append(conflicts, i)
+		}
+	}
+	return accepted.columnType, conflicts
+}
+
+func encodingIssue(tableName, column string, count, row int) models.DryImportIssue {
+	issue := models.DryImportIssue{
+		Severity: models.DryImportSeverityError,
+		Code:     models.DryImportIssueInvalidEncoding,
+		Table:    tableName,
+		Column:   column,
+		Count:    count,
+	}
+	if row > 0 {
+		issue.Rows = []int{row}
+		issue.Message = "Header is not valid UTF-8; re-save the file as UTF-8"
+	}
+	return issue
+}
+
+// addIssueExample counts an affected row and keeps the first few rows and values
+func addIssueExample(issue *models.DryImportIssue, row int, example string) {
+	issue.Count++
+	if len(issue.Rows) < dryImportMaxExamples {
+		issue.Rows = append(issue.Rows, row)
+		if example != "" {
+			issue.Examples = append(issue.Examples, example)
+		}
+	}
+}
+
+// finishDryImportReport decides whether the data can be imported as is
+func finishDryImportReport(report *models.DryImportReport) {
+	if report.Tables == nil {
+		report.Tables = []models.DryImportTable{}
+	}
+	if report.Issues == nil {
+		report.Issues = []models.DryImportIssue{}
+	}
+
+	report.CanImport = len(report.Tables) > 0
+	for _, issue := range report.Issues {
+		if issue.Severity == models.DryImportSeverityError {
+			report.CanImport = false
+			break
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"narapulse-be/internal/connectors"
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSheetReader struct {
+	sheets []connectors.SheetInfo
+	rows   map[string][][]string
+}
+
+func (f *fakeSheetReader) Connect(config map[string]interface{}) error { return nil }
+func (f *fakeSheetReader) Disconnect() error                           { return nil }
+func (f *fakeSheetReader) ListSheets() ([]connectors.SheetInfo, error) {
+	return f.sheets, nil
+}
+func (f *fakeSheetReader) GetRows(sheetName string, limit int) ([][]string, error) {
+	rows, ok := f.rows[sheetName]
+	if !ok {
+		return nil, errors.New("sheet not found")
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+func analyzeTestCSV(t *testing.T, content string) *models.DryImportReport {
+	t.Helper()
+	service := NewDryImportService()
+	report := &models.DryImportReport{Type: models.DataSourceTypeCSV}
+	require.NoError(t, service.analyzeCSV(report, strings.NewReader(content), int64(len(content)), "sales"))
+	finishDryImportReport(report)
+	return report
+}
+
+func findIssue(report *models.DryImportReport, code models.DryImportIssueCode, column string) *models.DryImportIssue {
+	for i := range report.Issues {
+		if report.Issues[i].Code == code && report.Issues[i].Column == column {
+			return &report.Issues[i]
+		}
+	}
+	return nil
+}
+
+func TestDryImportService_CleanCSV(t *testing.T) {
+	report := analyzeTestCSV(t, "\xEF\xBB\xBFid,name,amount,active,created\n1,Ann,10.5,true,2024-01-01\n2,Bob,12,false,2024-01-02\n3,Cy,,true,2024-01-03\n")
+
+	assert.True(t, report.CanImport)
+	assert.Empty(t, report.Issues)
+	require.Len(t, report.Tables, 1)
+
+	table := report.Tables[0]
+	assert.Equal(t, int64(3), table.RowCount)
+	assert.False(t, table.RowCountEstimated)
+	assert.Len(t, table.SampleData, 3)
+
+	types := map[string]string{}
+	for _, column := range table.Columns {
+		types[column.Name] = column.Type
+	}
+	assert.Equal(t, map[string]string{
+		"id": "integer", "name": "string", "amount": "float", "active": "boolean", "created": "date",
+	}, types)
+	assert.True(t, table.Columns[2].Nullable)
+	assert.False(t, table.Columns[0].Nullable)
+}
+
+func TestDryImportService_HeaderProblems(t *testing.T) {
+	report := analyzeTestCSV(t, "id,Name,,name\n1,a,b,c\n")
+
+	assert.False(t, report.CanImport)
+	assert.NotNil(t, findIssue(report, models.DryImportIssueEmptyHeader, "column_3"))
+	duplicate := findIssue(report, models.DryImportIssueDuplicateHeader, "name")
+	require.NotNil(t, duplicate)
+	assert.Equal(t, models.DryImportSeverityError, duplicate.Severity)
+	assert.Contains(t, duplicate.Message, "columns 2 and 4")
+}
+
+func TestDryImportService_TypeConflict(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,amount\n")
+	for i := 1; i <= 9; i++ {
+		fmt.Fprintf(&b, "%d,%d\n", i, i*100)
+	}
+	b.WriteString("10,1.234,00\n")
+	b.WriteString("11,n/a\n")
+
+	report := analyzeTestCSV(t, b.String())
+
+	conflict := findIssue(report, models.DryImportIssueTypeConflict, "amount")
+	require.NotNil(t, conflict)
+	assert.Equal(t, models.DryImportSeverityWarning, conflict.Severity)
+	assert.Equal(t, 1, conflict.Count)
+	assert.Equal(t, []int{12}, conflict.Rows)
+	assert.Equal(t, []string{"n/a"}, conflict.Examples)
+
+	// Row 11 has an extra cell from the unquoted thousands separator
+	ragged := findIssue(report, models.DryImportIssueRaggedRow, "")
+	require.NotNil(t, ragged)
+	assert.Equal(t, []int{11}, ragged.Rows)
+
+	// Warnings alone do not block the import
+	assert.True(t, report.CanImport)
+}
+
+func TestDryImportService_Encoding(t *testing.T) {
+	t.Run("invalid utf-8", func(t *testing.T) {
+		report := analyzeTestCSV(t, "city\nM\xfcnchen\nBerlin\n")
+		issue := findIssue(report, models.DryImportIssueInvalidEncoding, "city")
+		require.NotNil(t, issue)
+		assert.Equal(t, []int{2}, issue.Rows)
+		assert.False(t, report.CanImport)
+	})
+
+	t.Run("replacement character", func(t *testing.T) {
+		report := analyzeTestCSV(t, "city\nM�nchen\n")
+		issue := findIssue(report, models.DryImportIssueReplacementChar, "city")
+		require.NotNil(t, issue)
+		assert.Equal(t, models.DryImportSeverityWarning, issue.Severity)
+	})
+
+	t.Run("utf-16", func(t *testing.T) {
+		report := analyzeTestCSV(t, "\xFF\xFEi\x00d\x00")
+		assert.NotNil(t, findIssue(report, models.DryImportIssueInvalidEncoding, ""))
+		assert.False(t, report.CanImport)
+	})
+}
+
+func TestDryImportService_MalformedAndEmpty(t *testing.T) {
+	report := analyzeTestCSV(t, "id,note\n1,\"ok\"\n2,bad \"quote\n3,fine\n")
+	issue := findIssue(report, models.DryImportIssueMalformedRow, "")
+	require.NotNil(t, issue)
+	assert.Equal(t, []int{3}, issue.Rows)
+	assert.False(t, report.CanImport)
+
+	report = analyzeTestCSV(t, "")
+	assert.NotNil(t, findIssue(report, models.DryImportIssueEmptyFile, ""))
+	assert.False(t, report.CanImport)
+}
+
+func TestDryImportService_EstimatesLargeCSV(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,value\n")
+	total := dryImportScanRows * 2
+	for i := 0; i < total; i++ {
+		fmt.Fprintf(&b, "%d,%d\n", i%10, i%10)
+	}
+
+	report := analyzeTestCSV(t, b.String())
+	table := report.Tables[0]
+	assert.True(t, table.RowCountEstimated)
+	assert.Equal(t, int64(dryImportScanRows), table.RowsScanned)
+	assert.InDelta(t, total, table.RowCount, float64(total)/100)
+}
+
+func TestDryImportService_AnalyzeGoogleSheets(t *testing.T) {
+	service := NewDryImportService()
+	reader := &fakeSheetReader{
+		sheets: []connectors.SheetInfo{{Title: "Orders", RowCount: 1000}, {Title: "Empty", RowCount: 1000}},
+		rows: map[string][][]string{
+			// Sheets drop trailing empty cells, which is not a ragged row
+			"Orders": {{"id", "customer", "note"}, {"1", "Ann"}, {"2", "Bob", "vip"}},
+			"Empty":  {},
+		},
+	}
+	service.newSheetReader = func() sheetRowReader { return reader }
+
+	report, err := service.AnalyzeGoogleSheets(map[string]interface{}{"spreadsheet_id": "abc"})
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 2)
+	assert.Equal(t, int64(2), report.Tables[0].RowCount)
+	assert.Nil(t, findIssue(report, models.DryImportIssueRaggedRow, ""))
+	assert.NotNil(t, findIssue(report, models.DryImportIssueEmptyFile, ""))
+	assert.False(t, report.CanImport)
+
+	report, err = service.AnalyzeGoogleSheets(map[string]interface{}{"spreadsheet_id": "abc", "sheet_name": "Orders"})
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 1)
+	assert.True(t, report.CanImport)
+
+	_, err = service.AnalyzeGoogleSheets(map[string]interface{}{"spreadsheet_id": "abc", "sheet_name": "Missing"})
+	assert.Error(t, err)
+}
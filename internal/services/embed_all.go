@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+// defaultEmbedBatchSize is the number of texts sent per embedding request
+const defaultEmbedBatchSize = 50
+
+// missingKPIEmbedding and missingGlossaryEmbedding match rows without a stored
+// embedding, such as rows imported with SQL instead of the embed endpoints
+const (
+	missingKPIEmbedding = `NOT EXISTS (SELECT 1 FROM schema_embeddings e WHERE e.element_type = 'kpi'
+		AND e.element_name = kpi_definitions.name AND e.user_id = kpi_definitions.user_id
+		AND e.deleted_at IS NULL)`
+	missingGlossaryEmbedding = `NOT EXISTS (SELECT 1 FROM schema_embeddings e WHERE e.element_type = 'glossary'
+		AND e.element_name = business_glossaries.term AND e.user_id = business_glossaries.user_id
+		AND e.deleted_at IS NULL)`
+)
+
+// embedItem is a KPI or glossary term waiting to be embedded
+type embedItem struct {
+	id      uint
+	content string
+	record  func(embedding []float32) *models.SchemaEmbedding
+}
+
+// EmbedAllMissing embeds the active KPI definitions and glossary terms that
+// have no embedding yet, sending batchSize texts per embedding request.
+// progress is called after every batch. A failed batch is counted and
+// skipped, so one bad batch does not stop the run.
+func (s *EmbeddingService) EmbedAllMissing(ctx context.Context, batchSize int, progress func(models.EmbedAllProgress)) (*models.EmbedAllProgress, error) {
+	if batchSize <= 0 {
+		batchSize = defaultEmbedBatchSize
+	}
+
+	var kpiCount, glossaryCount int64
+	if err := s.db.Model(&models.KPIDefinition{}).Where("is_active").Where(missingKPIEmbedding).Count(&kpiCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count KPIs without embeddings: %w", err)
+	}
+	if err := s.db.Model(&models.BusinessGlossary{}).Where("is_active").Where(missingGlossaryEmbedding).Count(&glossaryCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count glossary terms without embeddings: %w", err)
+	}
+
+	result := &models.EmbedAllProgress{Total: int(kpiCount + glossaryCount)}
+
+	err := s.embedMissing(ctx, "kpi", batchSize, result, progress, func(lastID uint) ([]embedItem, error) {
+		var kpis []models.KPIDefinition
+		if err := s.missingQuery(&models.KPIDefinition{}, missingKPIEmbedding, lastID, batchSize).Find(&kpis).Error; err != nil {
+			return nil, err
+		}
+		items := make([]embedItem, len(kpis))
+		for i := range kpis {
+			kpi := &kpis[i]
+			content := s.buildKPIContent(kpi)
+			items[i] = embedItem{id: kpi.ID, content: content, record: func(embedding []float32) *models.SchemaEmbedding {
+				return kpiEmbeddingRecord(kpi, content, embedding)
+			}}
+		}
+		return items, nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = s.embedMissing(ctx, "glossary", batchSize, result, progress, func(lastID uint) ([]embedItem, error) {
+		var terms []models.BusinessGlossary
+		if err := s.missingQuery(&models.BusinessGlossary{}, missingGlossaryEmbedding, lastID, batchSize).Find(&terms).Error; err != nil {
+			return nil, err
+		}
+		items := make([]embedItem, len(terms))
+		for i := range terms {
+			term := &terms[i]
+			content := s.buildGlossaryContent(term)
+			items[i] = embedItem{id: term.ID, content: content, record: func(embedding []float32) *models.SchemaEmbedding {
+				return glossaryEmbeddingRecord(term, content, embedding)
+			}}
+		}
+		return items, nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ElementType = ""
+	result.Done = true
+	return result, nil
+}
+
+// missingQuery selects the next batch of active rows without embeddings after lastID
+func (s *EmbeddingService) missingQuery(model interface{}, missing string, lastID uint, batchSize int) *gorm.DB {
+	return s.db.Model(model).Where("is_active").Where(missing).
+		Where("id > ?", lastID).Order("id").Limit(batchSize)
+}
+
+// embedMissing walks the batches returned by next, keyed by ID so failed
+// rows are not fetched again, and stores the embeddings of each batch
+func (s *EmbeddingService) embedMissing(ctx context.Context, elementType string, batchSize int, result *models.EmbedAllProgress, progress func(models.EmbedAllProgress), next func(lastID uint) ([]embedItem, error)) error {
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, err := next(lastID)
+		if err != nil {
+			return fmt.Errorf("failed to load %s rows without embeddings: %w", elementType, err)
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		lastID = items[len(items)-1].id
+
+		if err := s.embedBatch(ctx, items); err != nil {
+			result.Failed += len(items)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s batch ending at id %d: %v", elementType, lastID, err))
+		} else {
+			result.Embedded += len(items)
+		}
+
+		result.ElementType = elementType
+		if progress != nil {
+			progress(*result)
+		}
+		if len(items) < batchSize {
+			return nil
+		}
+	}
+}
+
+// embedBatch embeds the contents of a batch with one request and stores the embeddings
+func (s *EmbeddingService) embedBatch(ctx context.Context, items []embedItem) error {
+	contents := make([]string, len(items))
+	for i, item := range items {
+		contents[i] = item.content
+	}
+
+	embeddings, err := s.GenerateEmbeddings(ctx, contents)
+	if err != nil {
+		return err
+	}
+
+	records := make([]*models.SchemaEmbedding, len(items))
+	for i, item := range items {
+		records[i] = item.record(embeddings[i])
+	}
+	if err := s.db.Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to store embeddings: %w", err)
+	}
+	return nil
+}
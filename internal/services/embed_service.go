@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+)
+
+var (
+	// ErrInvalidEmbedDomain is returned when an embed domain is not a host name
+	ErrInvalidEmbedDomain = errors.New("invalid embed domain")
+	// ErrEmbedScope is returned when an embed token does not grant the requested dashboard or widget
+	ErrEmbedScope = errors.New("embed token does not grant access to this resource")
+)
+
+// defaultEmbedTokenTTL is how long an embed token is valid unless set
+const defaultEmbedTokenTTL = time.Hour
+
+// embedDomainPattern matches a host name, optionally with a leading "*." wildcard
+var embedDomainPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// EmbedService issues embed tokens, JWTs scoped to one dashboard or widget,
+// so customers can embed visualizations in their own products, and serves
+// the embedded dashboards and widgets read-only. Like share links, embedded
+// rows are PII-masked and limited to the row limit of the token.
+type EmbedService struct {
+	dashboards *DashboardService
+	shares     *ShareService
+	secret     string
+}
+
+// NewEmbedService creates a new embed service signing tokens with secret
+func NewEmbedService(dashboards *DashboardService, shares *ShareService, secret string) *EmbedService {
+	return &EmbedService{
+		dashboards: dashboards,
+		shares:     shares,
+		secret:     secret,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *EmbedService) WithContext(ctx context.Context) *EmbedService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.dashboards = s.dashboards.WithContext(ctx)
+	scoped.shares = s.shares.WithContext(ctx)
+	return &scoped
+}
+
+// IssueToken issues an embed token for a dashboard the user owns or edits
+func (s *EmbedService) IssueToken(userID uint, req *models.EmbedTokenRequest) (*models.EmbedTokenResponse, error) {
+	dashboard, err := s.dashboards.editable(userID, req.DashboardID)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("/api/v1/embed/dashboards/%d", dashboard.ID)
+	if req.WidgetID != 0 {
+		if _, err := s.dashboards.getWidget(dashboard.ID, req.WidgetID); err != nil {
+			return nil, err
+		}
+		url = fmt.Sprintf("%s/widgets/%d", url, req.WidgetID)
+	}
+	domains, err := normalizeEmbedDomains(req.Domains)
+	if err != nil {
+		return nil, err
+	}
+
+	rowLimit := req.RowLimit
+	if rowLimit == 0 {
+		rowLimit = defaultShareRowLimit
+	}
+	ttl := defaultEmbedTokenTTL
+	if req.ExpiresInMinutes > 0 {
+		ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+
+	token, expiresAt, err := utils.GenerateEmbedToken(utils.EmbedClaims{
+		UserID:      userID,
+		DashboardID: dashboard.ID,
+		WidgetID:    req.WidgetID,
+		Domains:     domains,
+		RowLimit:    rowLimit,
+	}, s.secret, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign embed token: %w", err)
+	}
+
+	return &models.EmbedTokenResponse{
+		Token:       token,
+		DashboardID: dashboard.ID,
+		WidgetID:    req.WidgetID,
+		Domains:     domains,
+		RowLimit:    rowLimit,
+		ExpiresAt:   expiresAt,
+		URL:         url,
+	}, nil
+}
+
+// Dashboard returns an embedded dashboard; the token must grant the whole dashboard
+func (s *EmbedService) Dashboard(claims *utils.EmbedClaims, dashboardID uint) (*models.SharedDashboard, error) {
+	if claims.DashboardID != dashboardID || claims.WidgetID != 0 {
+		return nil, ErrEmbedScope
+	}
+	dashboard, err := s.shares.sharedDashboard(dashboardID, claims.RowLimit)
+	if errors.Is(err, ErrShareExpired) {
+		return nil, ErrDashboardNotFound
+	}
+	return dashboard, err
+}
+
+// Widget returns an embedded widget; the token must grant its dashboard or the widget itself
+func (s *EmbedService) Widget(claims *utils.EmbedClaims, dashboardID, widgetID uint) (*models.SharedWidget, error) {
+	if claims.DashboardID != dashboardID || (claims.WidgetID != 0 && claims.WidgetID != widgetID) {
+		return nil, ErrEmbedScope
+	}
+	widget, err := s.dashboards.getWidget(dashboardID, widgetID)
+	if err != nil {
+		return nil, err
+	}
+	return s.shares.sharedWidget(widget, claims.RowLimit), nil
+}
+
+// normalizeEmbedDomains lower-cases the domains and checks they are host names
+func normalizeEmbedDomains(domains []string) ([]string, error) {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if !embedDomainPattern.MatchString(domain) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEmbedDomain, domain)
+		}
+		normalized = append(normalized, domain)
+	}
+	return normalized, nil
+}
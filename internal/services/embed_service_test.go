@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"narapulse-be/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmbedDomains(t *testing.T) {
+	domains, err := normalizeEmbedDomains([]string{" App.Example.com ", "*.customer.io"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app.example.com", "*.customer.io"}, domains)
+
+	for _, domain := range []string{"https://app.example.com", "app.example.com/path", "*", "a.*.example.com", ""} {
+		_, err := normalizeEmbedDomains([]string{domain})
+		assert.ErrorIs(t, err, ErrInvalidEmbedDomain, domain)
+	}
+}
+
+func TestEmbedScope(t *testing.T) {
+	service := NewEmbedService(nil, nil, "secret")
+
+	_, err := service.Dashboard(&utils.EmbedClaims{DashboardID: 2}, 3)
+	assert.ErrorIs(t, err, ErrEmbedScope)
+	_, err = service.Dashboard(&utils.EmbedClaims{DashboardID: 2, WidgetID: 5}, 2)
+	assert.ErrorIs(t, err, ErrEmbedScope)
+	_, err = service.Widget(&utils.EmbedClaims{DashboardID: 2, WidgetID: 5}, 2, 6)
+	assert.ErrorIs(t, err, ErrEmbedScope)
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidEmbeddingMaintenance is returned for an unknown vector index or an unreadable date
+var ErrInvalidEmbeddingMaintenance = errors.New("invalid embedding maintenance request")
+
+// embeddingCompactBatch bounds the rows purged per statement, so a compaction
+// does not hold long locks while syncs write embeddings
+const embeddingCompactBatch = 1000
+
+// vectorIndex is a pgvector index and the statement creating it
+type vectorIndex struct {
+	name   string
+	table  string
+	create string
+}
+
+// vectorIndexes are the HNSW indexes of the migrations
+var vectorIndexes = []vectorIndex{
+	{
+		name:   "idx_schema_embeddings_embedding_cosine",
+		table:  "schema_embeddings",
+		create: "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_schema_embeddings_embedding_cosine ON schema_embeddings USING hnsw (embedding vector_cosine_ops)",
+	},
+	{
+		name:   "idx_rag_query_contexts_embedding_cosine",
+		table:  "rag_query_contexts",
+		create: "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_rag_query_contexts_embedding_cosine ON rag_query_contexts USING hnsw (embedding vector_cosine_ops)",
+	},
+}
+
+// EmbeddingMaintenanceService keeps the embedding storage healthy as syncs
+// churn it: it purges soft-deleted embeddings and vacuums the table, rebuilds
+// the vector indexes and reports storage statistics. The storage is shared by
+// the installation, so maintenance covers the embeddings of every tenant.
+type EmbeddingMaintenanceService struct {
+	db *gorm.DB
+}
+
+// NewEmbeddingMaintenanceService creates a new embedding maintenance service
+func NewEmbeddingMaintenanceService(db *gorm.DB) *EmbeddingMaintenanceService {
+	return &EmbeddingMaintenanceService{db: db}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *EmbeddingMaintenanceService) WithContext(ctx context.Context) *EmbeddingMaintenanceService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Compact permanently deletes the soft-deleted embeddings the request selects
+// and vacuums the table so the space and the dead index entries are reclaimed
+func (s *EmbeddingMaintenanceService) Compact(ctx context.Context, req *models.EmbeddingCompactRequest) (*models.EmbeddingCompactRun, error) {
+	ctx = tenancy.WithTenant(ctx, 0)
+	deletedBefore, err := parseHistoryTime(req.DeletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("%w: deleted_before: %v", ErrInvalidEmbeddingMaintenance, err)
+	}
+
+	start := time.Now()
+	run := &models.EmbeddingCompactRun{Vacuum: vacuumStatement(req.Full)}
+	if run.TableBytesBefore, err = s.totalRelationSize(ctx, "schema_embeddings"); err != nil {
+		return nil, err
+	}
+
+	if run.EmbeddingsPurged, err = s.purgeDeleted(ctx, req.DataSourceID, deletedBefore); err != nil {
+		return nil, err
+	}
+
+	// VACUUM cannot run in a transaction; Exec runs it on its own connection
+	if err := s.db.WithContext(ctx).Exec(run.Vacuum).Error; err != nil {
+		return nil, fmt.Errorf("failed to vacuum schema embeddings: %w", err)
+	}
+
+	if run.TableBytesAfter, err = s.totalRelationSize(ctx, "schema_embeddings"); err != nil {
+		return nil, err
+	}
+	run.DurationMs = time.Since(start).Milliseconds()
+	return run, nil
+}
+
+// purgeDeleted permanently deletes the soft-deleted embeddings of a data
+// source, or of all of them, deleted before a time, in batches
+func (s *EmbeddingMaintenanceService) purgeDeleted(ctx context.Context, dataSourceID *uint, deletedBefore *time.Time) (int64, error) {
+	scope := s.db.WithContext(ctx).Unscoped().Model(&models.SchemaEmbedding{}).Where("deleted_at IS NOT NULL")
+	if dataSourceID != nil {
+		scope = scope.Where("data_source_id = ?", *dataSourceID)
+	}
+	if deletedBefore != nil {
+		scope = scope.Where("deleted_at < ?", *deletedBefore)
+	}
+	var total int64
+	for {
+		var ids []uint
+		if err := scope.Session(&gorm.Session{}).Order("id").Limit(embeddingCompactBatch).Pluck("id", &ids).Error; err != nil {
+			return total, fmt.Errorf("failed to get deleted embeddings: %w", err)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		purged := s.db.WithContext(ctx).Unscoped().Where("id IN ?", ids).Delete(&models.SchemaEmbedding{})
+		if purged.Error != nil {
+			return total, fmt.Errorf("failed to purge deleted embeddings: %w", purged.Error)
+		}
+		total += purged.RowsAffected
+	}
+}
+
+// vacuumStatement returns the VACUUM of the schema embeddings table
+func vacuumStatement(full bool) string {
+	if full {
+		return "VACUUM (FULL, ANALYZE) schema_embeddings"
+	}
+	return "VACUUM (ANALYZE) schema_embeddings"
+}
+
+// RebuildIndexes rebuilds the named vector index, or all of them when name is
+// empty, without blocking searches or syncs. A missing index is created.
+func (s *EmbeddingMaintenanceService) RebuildIndexes(ctx context.Context, name string) ([]models.VectorIndexRebuild, error) {
+	ctx = tenancy.WithTenant(ctx, 0)
+	indexes, err := selectVectorIndexes(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rebuilds := make([]models.VectorIndexRebuild, 0, len(indexes))
+	for _, index := range indexes {
+		start := time.Now()
+		rebuild := models.VectorIndexRebuild{Name: index.name, Table: index.table}
+
+		stats, err := s.vectorIndexStats(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		rebuild.BytesBefore = stats.Bytes
+
+		if stats.Exists {
+			err = s.db.WithContext(ctx).Exec(fmt.Sprintf("REINDEX INDEX CONCURRENTLY %s", index.name)).Error
+		} else {
+			rebuild.Created = true
+			err = s.db.WithContext(ctx).Exec(index.create).Error
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild %s: %w", index.name, err)
+		}
+
+		if stats, err = s.vectorIndexStats(ctx, index); err != nil {
+			return nil, err
+		}
+		rebuild.BytesAfter = stats.Bytes
+		rebuild.DurationMs = time.Since(start).Milliseconds()
+		rebuilds = append(rebuilds, rebuild)
+	}
+
+	return rebuilds, nil
+}
+
+// selectVectorIndexes returns the vector index named name, or all of them when name is empty
+func selectVectorIndexes(name string) ([]vectorIndex, error) {
+	if name == "" {
+		return vectorIndexes, nil
+	}
+	for _, index := range vectorIndexes {
+		if index.name == name || index.table == name {
+			return []vectorIndex{index}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown vector index %q", ErrInvalidEmbeddingMaintenance, name)
+}
+
+// GetStorageStats reports the size of the schema embeddings table, its vector
+// indexes and dead rows, and the embeddings of each data source
+func (s *EmbeddingMaintenanceService) GetStorageStats(ctx context.Context) (*models.EmbeddingStorageStats, error) {
+	ctx = tenancy.WithTenant(ctx, 0)
+	stats := &models.EmbeddingStorageStats{
+		VectorIndexes: []models.VectorIndexStats{},
+		DataSources:   []models.DataSourceEmbeddingStorage{},
+	}
+	db := s.db.WithContext(ctx)
+
+	if err := db.Raw(`SELECT pg_table_size('schema_embeddings') AS table_bytes,
+		pg_indexes_size('schema_embeddings') AS index_bytes`).Scan(stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get embedding table size: %w", err)
+	}
+
+	var activity struct {
+		LiveRows      int64
+		DeadRows      int64
+		LastVacuumAt  *time.Time
+		LastAnalyzeAt *time.Time
+	}
+	if err := db.Raw(`SELECT n_live_tup AS live_rows, n_dead_tup AS dead_rows,
+		GREATEST(last_vacuum, last_autovacuum) AS last_vacuum_at,
+		GREATEST(last_analyze, last_autoanalyze) AS last_analyze_at
+		FROM pg_stat_user_tables WHERE relname = 'schema_embeddings'`).Scan(&activity).Error; err != nil {
+		return nil, fmt.Errorf("failed to get embedding table activity: %w", err)
+	}
+	stats.LiveRows = activity.LiveRows
+	stats.DeadRows = activity.DeadRows
+	stats.LastVacuumAt = activity.LastVacuumAt
+	stats.LastAnalyzeAt = activity.LastAnalyzeAt
+
+	for _, index := range vectorIndexes {
+		indexStats, err := s.vectorIndexStats(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		stats.VectorIndexes = append(stats.VectorIndexes, *indexStats)
+	}
+
+	dataSources, err := s.dataSourceStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.DataSources = dataSources
+
+	return stats, nil
+}
+
+// dataSourceStorage counts the embeddings of each data source. Row sizes and
+// update times are only reported by Postgres; other databases, such as the
+// SQLite of tests, report no size and no time.
+func (s *EmbeddingMaintenanceService) dataSourceStorage(ctx context.Context) ([]models.DataSourceEmbeddingStorage, error) {
+	db := s.db.WithContext(ctx)
+	rowBytes, lastUpdated := "0", "NULL"
+	if db.Dialector.Name() == "postgres" {
+		rowBytes, lastUpdated = "pg_column_size(e.*)", "MAX(e.updated_at)"
+	}
+	storage := []models.DataSourceEmbeddingStorage{}
+	if err := db.Raw(`SELECT e.data_source_id, COALESCE(ds.name, '') AS data_source_name,
+		SUM(CASE WHEN e.deleted_at IS NULL THEN 1 ELSE 0 END) AS embeddings,
+		SUM(CASE WHEN e.deleted_at IS NOT NULL THEN 1 ELSE 0 END) AS deleted_embeddings,
+		SUM(CASE WHEN e.deleted_at IS NULL AND e.element_type = 'table' THEN 1 ELSE 0 END) AS tables,
+		SUM(CASE WHEN e.deleted_at IS NULL AND e.element_type = 'column' THEN 1 ELSE 0 END) AS columns,
+		SUM(CASE WHEN e.deleted_at IS NULL AND e.element_type = 'kpi' THEN 1 ELSE 0 END) AS kpis,
+		SUM(CASE WHEN e.deleted_at IS NULL AND e.element_type = 'glossary' THEN 1 ELSE 0 END) AS glossary_terms,
+		SUM(CASE WHEN e.deleted_at IS NULL AND COALESCE(e.content_hash, '') = '' THEN 1 ELSE 0 END) AS unhashed,
+		COALESCE(SUM(` + rowBytes + `), 0) AS bytes,
+		` + lastUpdated + ` AS last_updated_at
+		FROM schema_embeddings e
+		LEFT JOIN data_sources ds ON ds.id = e.data_source_id
+		GROUP BY e.data_source_id, ds.name
+		ORDER BY e.data_source_id`).Scan(&storage).Error; err != nil {
+		return nil, fmt.Errorf("failed to get embedding storage per data source: %w", err)
+	}
+	return storage, nil
+}
+
+// vectorIndexStats reports whether a vector index exists, is valid and its size
+func (s *EmbeddingMaintenanceService) vectorIndexStats(ctx context.Context, index vectorIndex) (*models.VectorIndexStats, error) {
+	stats := &models.VectorIndexStats{Name: index.name, Table: index.table}
+	if err := s.db.WithContext(ctx).Raw(`SELECT TRUE AS "exists", i.indisvalid AS valid, pg_relation_size(i.indexrelid) AS bytes
+		FROM pg_index i WHERE i.indexrelid = to_regclass(?)`, index.name).Scan(stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get size of %s: %w", index.name, err)
+	}
+	return stats, nil
+}
+
+// totalRelationSize returns the size of a table with its TOAST and indexes
+func (s *EmbeddingMaintenanceService) totalRelationSize(ctx context.Context, table string) (int64, error) {
+	var size int64
+	if err := s.db.WithContext(ctx).Raw("SELECT COALESCE(pg_total_relation_size(to_regclass(?)), 0)", table).Scan(&size).Error; err != nil {
+		return 0, fmt.Errorf("failed to get size of %s: %w", table, err)
+	}
+	return size, nil
+}
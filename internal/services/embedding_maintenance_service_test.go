@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestSelectVectorIndexes(t *testing.T) {
+	all, err := selectVectorIndexes("")
+	require.NoError(t, err)
+	assert.Len(t, all, len(vectorIndexes))
+
+	byName, err := selectVectorIndexes("idx_schema_embeddings_embedding_cosine")
+	require.NoError(t, err)
+	require.Len(t, byName, 1)
+	assert.Equal(t, "schema_embeddings", byName[0].table)
+
+	byTable, err := selectVectorIndexes("rag_query_contexts")
+	require.NoError(t, err)
+	require.Len(t, byTable, 1)
+	assert.Equal(t, "idx_rag_query_contexts_embedding_cosine", byTable[0].name)
+
+	// Only the known indexes can be rebuilt; the name is part of the statement
+	_, err = selectVectorIndexes("users_pkey; DROP TABLE users")
+	assert.ErrorIs(t, err, ErrInvalidEmbeddingMaintenance)
+}
+
+func TestVacuumStatement(t *testing.T) {
+	assert.Equal(t, "VACUUM (ANALYZE) schema_embeddings", vacuumStatement(false))
+	assert.Equal(t, "VACUUM (FULL, ANALYZE) schema_embeddings", vacuumStatement(true))
+}
+
+func TestEmbeddingMaintenanceService_CompactValidation(t *testing.T) {
+	service := NewEmbeddingMaintenanceService(nil)
+
+	// The date is validated before the database is used
+	_, err := service.Compact(context.Background(), &models.EmbeddingCompactRequest{DeletedBefore: "last week"})
+	assert.ErrorIs(t, err, ErrInvalidEmbeddingMaintenance)
+}
+
+func TestEmbeddingMaintenanceService_PurgeAndStorage(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.DataSource{}, &models.SchemaEmbedding{}))
+
+	// Maintenance covers the embeddings of every tenant
+	acme := tenancy.WithTenant(context.Background(), 1)
+	globex := tenancy.WithTenant(context.Background(), 2)
+	installation := tenancy.WithTenant(context.Background(), 0)
+	warehouse := &models.DataSource{UserID: 1, Name: "warehouse", Type: models.DataSourceTypePostgreSQL}
+	crm := &models.DataSource{UserID: 2, Name: "crm", Type: models.DataSourceTypePostgreSQL}
+	require.NoError(t, db.WithContext(acme).Create(warehouse).Error)
+	require.NoError(t, db.WithContext(globex).Create(crm).Error)
+
+	embed := func(ctx context.Context, dataSourceID uint, elementType, name, hash string) *models.SchemaEmbedding {
+		embedding := &models.SchemaEmbedding{DataSourceID: dataSourceID, ElementType: elementType, ElementName: name, ContentHash: hash}
+		require.NoError(t, db.WithContext(ctx).Create(embedding).Error)
+		return embedding
+	}
+	embed(acme, warehouse.ID, "table", "orders", "h1")
+	embed(acme, warehouse.ID, "column", "orders.total", "h2")
+	embed(acme, warehouse.ID, "kpi", "revenue", "")
+	dropped := embed(acme, warehouse.ID, "column", "orders.legacy", "h3")
+	embed(globex, crm.ID, "glossary", "churn", "h4")
+	renamed := embed(globex, crm.ID, "table", "contacts", "h5")
+
+	// The dropped column was deleted two days ago, the renamed table just now
+	require.NoError(t, db.WithContext(acme).Delete(dropped).Error)
+	require.NoError(t, db.WithContext(acme).Unscoped().Model(dropped).Update("deleted_at", time.Now().Add(-48*time.Hour)).Error)
+	require.NoError(t, db.WithContext(globex).Delete(renamed).Error)
+
+	service := NewEmbeddingMaintenanceService(db)
+	storage, err := service.dataSourceStorage(installation)
+	require.NoError(t, err)
+	require.Len(t, storage, 2)
+	assert.Equal(t, models.DataSourceEmbeddingStorage{
+		DataSourceID: warehouse.ID, DataSourceName: "warehouse", Embeddings: 3, DeletedEmbeddings: 1,
+		Tables: 1, Columns: 1, KPIs: 1, Unhashed: 1,
+	}, storage[0])
+	assert.Equal(t, models.DataSourceEmbeddingStorage{
+		DataSourceID: crm.ID, DataSourceName: "crm", Embeddings: 1, DeletedEmbeddings: 1,
+		GlossaryTerms: 1,
+	}, storage[1])
+
+	// Only soft-deleted embeddings of the selected data source and deletion time are purged
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	purged, err := service.purgeDeleted(installation, nil, &dayAgo)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged, "only the column deleted before the day")
+	purged, err = service.purgeDeleted(installation, &warehouse.ID, nil)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	purged, err = service.purgeDeleted(installation, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged, "the table of the other tenant")
+
+	var remaining []models.SchemaEmbedding
+	require.NoError(t, db.WithContext(installation).Unscoped().Order("id").Find(&remaining).Error)
+	require.Len(t, remaining, 4)
+	for _, embedding := range remaining {
+		assert.False(t, embedding.DeletedAt.Valid, "live embeddings are kept")
+	}
+
+	storage, err = service.dataSourceStorage(installation)
+	require.NoError(t, err)
+	require.Len(t, storage, 2)
+	assert.Equal(t, int64(3), storage[0].Embeddings)
+	assert.Zero(t, storage[0].DeletedEmbeddings)
+	assert.Equal(t, int64(1), storage[1].Embeddings)
+	assert.Zero(t, storage[1].DeletedEmbeddings)
+}
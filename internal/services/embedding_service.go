@@ -3,14 +3,19 @@ package services
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/metrics"
+	"narapulse-be/internal/pkg/tenancy"
 	"gorm.io/gorm"
 )
 
@@ -18,20 +23,44 @@ import (
 type EmbeddingService struct {
 	db     *gorm.DB
 	apiKey string
+	model  string // Embedding model; must produce 1536 dimensions
 	client *http.Client
+	usage  *UsageService // Records tokens of embedding requests and enforces quotas
 }
 
-// NewEmbeddingService creates a new embedding service
-func NewEmbeddingService(db *gorm.DB, apiKey string) *EmbeddingService {
+// defaultEmbeddingModel is used when no model is configured
+const defaultEmbeddingModel = "text-embedding-ada-002"
+
+// NewEmbeddingService creates a new embedding service. Multilingual models such
+// as text-embedding-3-small let questions in other languages match English
+// schemas; changing the model requires re-embedding everything.
+func NewEmbeddingService(db *gorm.DB, apiKey, model string, usage *UsageService) *EmbeddingService {
+	if model == "" {
+		model = defaultEmbeddingModel
+	}
 	return &EmbeddingService{
 		db:     db,
 		apiKey: apiKey,
+		model:  model,
+		usage:  usage,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *EmbeddingService) WithContext(ctx context.Context) *EmbeddingService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.usage = s.usage.WithContext(ctx)
+	return &scoped
+}
+
 // OpenAI Embedding API structures
 type EmbeddingRequest struct {
 	Input []string `json:"input"`
@@ -56,9 +85,26 @@ func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) (
 		return nil, fmt.Errorf("text cannot be empty")
 	}
 
+	embeddings, err := s.GenerateEmbeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// GenerateEmbeddings generates embeddings for several texts in one API request.
+// The embeddings are returned in the order of the texts.
+func (s *EmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts to embed")
+	}
+	if err := s.usage.CheckQuota(ctx); err != nil {
+		return nil, err
+	}
+
 	reqBody := EmbeddingRequest{
-		Input: []string{text},
-		Model: "text-embedding-ada-002",
+		Input: texts,
+		Model: s.model,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -74,6 +120,30 @@ func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) (
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
 
+	body, err := s.doEmbeddingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var embeddingResp EmbeddingResponse
+	if err := json.Unmarshal(body, &embeddingResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	s.usage.Record(ctx, models.UsageKindEmbedding, reqBody.Model, embeddingResp.Usage.TotalTokens, 0)
+
+	if len(embeddingResp.Data) == 0 {
+		return nil, fmt.Errorf("no embedding data received")
+	}
+
+	return orderEmbeddings(embeddingResp, len(texts))
+}
+
+// doEmbeddingRequest sends a request to the embedding API and returns the body of a successful response
+func (s *EmbeddingService) doEmbeddingRequest(req *http.Request) (_ []byte, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveEmbeddingRequest(start, err) }()
+
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
@@ -88,77 +158,297 @@ func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) (
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
+	return body, nil
+}
 
-	var embeddingResp EmbeddingResponse
-	if err := json.Unmarshal(body, &embeddingResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+// orderEmbeddings places the embeddings of a response by their input index
+func orderEmbeddings(resp EmbeddingResponse, count int) ([][]float32, error) {
+	embeddings := make([][]float32, count)
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= count {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
 	}
-
-	if len(embeddingResp.Data) == 0 {
-		return nil, fmt.Errorf("no embedding data received")
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			return nil, fmt.Errorf("no embedding received for input %d", i)
+		}
 	}
-
-	return embeddingResp.Data[0].Embedding, nil
+	return embeddings, nil
 }
 
 // EmbedSchema generates and stores embeddings for schema elements
 func (s *EmbeddingService) EmbedSchema(ctx context.Context, dataSourceID uint, schemaID uint) error {
-	// Get schema with columns
 	var schema models.Schema
 	if err := s.db.First(&schema, schemaID).Error; err != nil {
 		return fmt.Errorf("failed to get schema: %w", err)
 	}
+	schema.DataSourceID = dataSourceID
+
+	_, err := s.SyncSchema(ctx, &schema)
+	return err
+}
 
-	// Parse columns
+// SyncSchema brings the embeddings of a table and its columns up to date. Only
+// elements whose content hash changed are embedded again; embeddings of
+// dropped columns are removed. The changes are applied in one transaction
+// after all embeddings were generated, so a failure leaves the previous
+// embeddings of the schema in place.
+func (s *EmbeddingService) SyncSchema(ctx context.Context, schema *models.Schema) (models.SchemaSyncProgress, error) {
 	var columns []models.Column
 	if err := json.Unmarshal(schema.Columns, &columns); err != nil {
-		return fmt.Errorf("failed to parse columns: %w", err)
+		return models.SchemaSyncProgress{}, fmt.Errorf("failed to parse columns: %w", err)
 	}
 
-	// Generate embedding for table/schema
-	tableContent := s.buildTableContent(schema, columns)
-	tableEmbedding, err := s.GenerateEmbedding(ctx, tableContent)
+	owner, err := s.dataSourceOwner(schema.DataSourceID)
 	if err != nil {
-		return fmt.Errorf("failed to generate table embedding: %w", err)
+		return models.SchemaSyncProgress{}, err
 	}
+	// The embeddings and the AI usage belong to the tenant of the data source
+	ctx = tenancy.WithTenant(ctx, owner.TenantID)
+	s = s.WithContext(ctx)
 
-	// Store table embedding
-	tableEmbeddingRecord := &models.SchemaEmbedding{
-		DataSourceID: dataSourceID,
-		SchemaID:     schemaID,
-		ElementType:  "table",
-		ElementName:  schema.Name,
-		Content:      tableContent,
-		Embedding:    tableEmbedding,
-		Metadata:     models.JSON(`{"display_name":"` + schema.DisplayName + `","description":"` + schema.Description + `","row_count":` + fmt.Sprintf("%d", schema.RowCount) + `}`),
+	var existing []models.SchemaEmbedding
+	if err := s.db.Select("id", "user_id", "tenant_id", "element_type", "element_name", "metadata", "content_hash").
+		Where("schema_id = ? AND element_type IN ?", schema.ID, []string{"table", "column"}).
+		Find(&existing).Error; err != nil {
+		return models.SchemaSyncProgress{}, fmt.Errorf("failed to get embeddings: %w", err)
+	}
+
+	return s.applyEmbeddingDiff(ctx, diffSchemaEmbeddings(existing, s.schemaEmbeddingRecords(schema, columns, owner)))
+}
+
+// dataSourceOwner returns the data source with the user and tenant owning
+// it, who own the embeddings of its tables and columns
+func (s *EmbeddingService) dataSourceOwner(dataSourceID uint) (*models.DataSource, error) {
+	var dataSource models.DataSource
+	if err := s.db.Unscoped().Select("id", "user_id", "tenant_id").First(&dataSource, dataSourceID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get owner of data source %d: %w", dataSourceID, err)
 	}
+	return &dataSource, nil
+}
 
-	if err := s.db.Create(tableEmbeddingRecord).Error; err != nil {
-		return fmt.Errorf("failed to store table embedding: %w", err)
+// SyncKnowledge brings the embeddings of the active KPI definitions and
+// glossary terms up to date, like SyncSchema does for a table: only changed
+// ones are embedded again and those of deleted or deactivated ones removed.
+// The embeddings of each tenant are synced in the context of that tenant, so
+// they and the AI usage of embedding them belong to it.
+func (s *EmbeddingService) SyncKnowledge(ctx context.Context) (models.SchemaSyncProgress, error) {
+	var kpis []models.KPIDefinition
+	if err := s.db.Where("is_active").Find(&kpis).Error; err != nil {
+		return models.SchemaSyncProgress{}, fmt.Errorf("failed to get KPIs: %w", err)
+	}
+	var terms []models.BusinessGlossary
+	if err := s.db.Where("is_active").Find(&terms).Error; err != nil {
+		return models.SchemaSyncProgress{}, fmt.Errorf("failed to get glossary terms: %w", err)
 	}
 
-	// Generate embeddings for each column
-	for _, column := range columns {
-		columnContent := s.buildColumnContent(schema.Name, column)
-		columnEmbedding, err := s.GenerateEmbedding(ctx, columnContent)
+	desired := make(map[uint][]*models.SchemaEmbedding)
+	for i := range kpis {
+		desired[kpis[i].TenantID] = append(desired[kpis[i].TenantID], kpiEmbeddingRecord(&kpis[i], s.buildKPIContent(&kpis[i]), nil))
+	}
+	for i := range terms {
+		desired[terms[i].TenantID] = append(desired[terms[i].TenantID], glossaryEmbeddingRecord(&terms[i], s.buildGlossaryContent(&terms[i]), nil))
+	}
+
+	var embeddings []models.SchemaEmbedding
+	if err := s.db.Select("id", "user_id", "tenant_id", "element_type", "element_name", "metadata", "content_hash").
+		Where("element_type IN ?", []string{"kpi", "glossary"}).
+		Find(&embeddings).Error; err != nil {
+		return models.SchemaSyncProgress{}, fmt.Errorf("failed to get embeddings: %w", err)
+	}
+	existing := make(map[uint][]models.SchemaEmbedding)
+	for _, embedding := range embeddings {
+		existing[embedding.TenantID] = append(existing[embedding.TenantID], embedding)
+	}
+
+	tenants := make([]uint, 0, len(desired)+len(existing))
+	for tenantID := range desired {
+		tenants = append(tenants, tenantID)
+	}
+	for tenantID := range existing {
+		if _, ok := desired[tenantID]; !ok {
+			tenants = append(tenants, tenantID)
+		}
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i] < tenants[j] })
+
+	var progress models.SchemaSyncProgress
+	for _, tenantID := range tenants {
+		tenantCtx := tenancy.WithTenant(ctx, tenantID)
+		tenantProgress, err := s.WithContext(tenantCtx).applyEmbeddingDiff(tenantCtx, diffSchemaEmbeddings(existing[tenantID], desired[tenantID]))
 		if err != nil {
-			continue // Skip failed embeddings but don't fail the whole process
+			return progress, err
 		}
+		progress.Add(tenantProgress)
+	}
+	return progress, nil
+}
 
-		columnEmbeddingRecord := &models.SchemaEmbedding{
-			DataSourceID: dataSourceID,
-			SchemaID:     schemaID,
+// applyEmbeddingDiff embeds the changed elements of a diff, then stores them
+// and deletes the stale embeddings in one transaction
+func (s *EmbeddingService) applyEmbeddingDiff(ctx context.Context, diff schemaEmbeddingDiff) (models.SchemaSyncProgress, error) {
+	changed, keep, stale := diff.changed, diff.keep, diff.stale
+
+	for start := 0; start < len(changed); start += defaultEmbedBatchSize {
+		batch := changed[start:min(start+defaultEmbedBatchSize, len(changed))]
+		texts := make([]string, len(batch))
+		for i, record := range batch {
+			texts[i] = record.Content
+		}
+		embeddings, err := s.GenerateEmbeddings(ctx, texts)
+		if err != nil {
+			return models.SchemaSyncProgress{}, fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		for i, record := range batch {
+			record.Embedding = embeddings[i]
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if len(stale) > 0 {
+			if err := tx.Where("id IN ?", stale).Delete(&models.SchemaEmbedding{}).Error; err != nil {
+				return fmt.Errorf("failed to delete embeddings: %w", err)
+			}
+		}
+		if len(changed) > 0 {
+			if err := tx.Create(changed).Error; err != nil {
+				return fmt.Errorf("failed to store embeddings: %w", err)
+			}
+		}
+		// Unchanged embeddings were verified by this sync
+		if len(keep) > 0 {
+			if err := tx.Model(&models.SchemaEmbedding{}).Where("id IN ?", keep).Update("updated_at", time.Now()).Error; err != nil {
+				return fmt.Errorf("failed to update embeddings: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.SchemaSyncProgress{}, err
+	}
+
+	return models.SchemaSyncProgress{
+		ElementsEmbedded:  len(changed),
+		ElementsUnchanged: len(keep),
+		ElementsRemoved:   diff.removed,
+	}, nil
+}
+
+// schemaEmbeddingRecords builds the embeddings a table and its columns should
+// have, without the vectors, owned by the user and tenant owning the data source
+func (s *EmbeddingService) schemaEmbeddingRecords(schema *models.Schema, columns []models.Column, owner *models.DataSource) []*models.SchemaEmbedding {
+	tableContent := s.buildTableContent(*schema, columns)
+	tableMetadata := buildTableMetadata(schema)
+	records := []*models.SchemaEmbedding{{
+		DataSourceID: schema.DataSourceID,
+		SchemaID:     schema.ID,
+		UserID:       owner.UserID,
+		TenantID:     owner.TenantID,
+		ElementType:  "table",
+		ElementName:  schema.Name,
+		Content:      tableContent,
+		Metadata:     tableMetadata,
+		ContentHash:  embeddingContentHash(tableContent, tableMetadata),
+	}}
+	for _, column := range columns {
+		content := s.buildColumnContent(schema.Name, column)
+		metadata := s.buildColumnMetadata(schema.Name, column)
+		records = append(records, &models.SchemaEmbedding{
+			DataSourceID: schema.DataSourceID,
+			SchemaID:     schema.ID,
+			UserID:       owner.UserID,
+			TenantID:     owner.TenantID,
 			ElementType:  "column",
 			ElementName:  column.Name,
-			Content:      columnContent,
-			Embedding:    columnEmbedding,
-			Metadata:     models.JSON(fmt.Sprintf(`{"table":"%s","type":"%s","nullable":%t,"primary_key":%t}`, schema.Name, column.Type, column.Nullable, column.PrimaryKey)),
+			Content:      content,
+			Metadata:     metadata,
+			ContentHash:  embeddingContentHash(content, metadata),
+		})
+	}
+	return records
+}
+
+// schemaEmbeddingDiff is what a sync changes in the embeddings of a schema, or
+// of the KPIs and glossary terms
+type schemaEmbeddingDiff struct {
+	changed []*models.SchemaEmbedding // New or changed elements to embed
+	keep    []uint                    // Stored embeddings still current
+	stale   []uint                    // Stored embeddings to delete: replaced, dropped or duplicate
+	removed int                       // Elements no longer in the schema
+}
+
+// diffSchemaEmbeddings compares the stored embeddings of a schema, or of the
+// KPIs and glossary terms, with the ones it should have. Embeddings stored without a hash count as changed.
+func diffSchemaEmbeddings(existing []models.SchemaEmbedding, desired []*models.SchemaEmbedding) schemaEmbeddingDiff {
+	var diff schemaEmbeddingDiff
+
+	current := make(map[string]models.SchemaEmbedding, len(existing))
+	for _, embedding := range existing {
+		key := embeddingKey(&embedding)
+		if _, ok := current[key]; ok {
+			diff.stale = append(diff.stale, embedding.ID)
+			continue
 		}
+		current[key] = embedding
+	}
 
-		s.db.Create(columnEmbeddingRecord)
+	for _, record := range desired {
+		key := embeddingKey(record)
+		embedding, ok := current[key]
+		if !ok {
+			diff.changed = append(diff.changed, record)
+			continue
+		}
+		delete(current, key)
+		// An embedding stored for another owner or tenant, e.g. before owners were recorded, is replaced
+		if embedding.ContentHash != "" && embedding.ContentHash == record.ContentHash && embedding.UserID == record.UserID && embedding.TenantID == record.TenantID {
+			diff.keep = append(diff.keep, embedding.ID)
+			continue
+		}
+		diff.stale = append(diff.stale, embedding.ID)
+		diff.changed = append(diff.changed, record)
 	}
 
-	return nil
+	for _, embedding := range current {
+		diff.stale = append(diff.stale, embedding.ID)
+		diff.removed++
+	}
+	sort.Slice(diff.stale, func(i, j int) bool { return diff.stale[i] < diff.stale[j] })
+	return diff
+}
+
+// embeddingKey identifies the element an embedding is for. KPIs and glossary
+// terms are per user, so their key includes the user ID in the metadata.
+func embeddingKey(embedding *models.SchemaEmbedding) string {
+	var metadata struct {
+		UserID uint `json:"user_id"`
+	}
+	if embedding.ElementType == "kpi" || embedding.ElementType == "glossary" {
+		json.Unmarshal(embedding.Metadata, &metadata)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d", embedding.ElementType, embedding.ElementName, metadata.UserID)
+}
+
+// embeddingContentHash hashes what an embedding is generated from together
+// with the metadata shown next to it in prompts
+func embeddingContentHash(content string, metadata models.JSON) string {
+	hash := sha256.New()
+	hash.Write([]byte(content))
+	hash.Write([]byte{0})
+	hash.Write(metadata)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// buildTableMetadata carries the table details shown with a retrieved table
+func buildTableMetadata(schema *models.Schema) models.JSON {
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"display_name": schema.DisplayName,
+		"description":  schema.Description,
+		"row_count":    schema.RowCount,
+	})
+	return models.JSON(metadata)
 }
 
 // EmbedKPIDefinition generates and stores embedding for KPI definition
@@ -169,18 +459,7 @@ func (s *EmbeddingService) EmbedKPIDefinition(ctx context.Context, kpi *models.K
 		return fmt.Errorf("failed to generate KPI embedding: %w", err)
 	}
 
-	// Store KPI embedding (using schema_id = 0 for KPIs)
-	kpiEmbeddingRecord := &models.SchemaEmbedding{
-		DataSourceID: 0, // KPIs are not tied to specific data sources
-		SchemaID:     0,
-		ElementType:  "kpi",
-		ElementName:  kpi.Name,
-		Content:      content,
-		Embedding:    embedding,
-		Metadata:     models.JSON(fmt.Sprintf(`{"category":"%s","unit":"%s","grain":"%s","user_id":%d}`, kpi.Category, kpi.Unit, kpi.Grain, kpi.UserID)),
-	}
-
-	if err := s.db.Create(kpiEmbeddingRecord).Error; err != nil {
+	if err := s.db.Create(kpiEmbeddingRecord(kpi, content, embedding)).Error; err != nil {
 		return fmt.Errorf("failed to store KPI embedding: %w", err)
 	}
 
@@ -195,22 +474,125 @@ func (s *EmbeddingService) EmbedGlossaryTerm(ctx context.Context, glossary *mode
 		return fmt.Errorf("failed to generate glossary embedding: %w", err)
 	}
 
-	// Store glossary embedding (using schema_id = 0 for glossary)
-	glossaryEmbeddingRecord := &models.SchemaEmbedding{
+	if err := s.db.Create(glossaryEmbeddingRecord(glossary, content, embedding)).Error; err != nil {
+		return fmt.Errorf("failed to store glossary embedding: %w", err)
+	}
+
+	return nil
+}
+
+// kpiEmbeddingRecord builds the stored embedding of a KPI (schema_id = 0 for KPIs)
+func kpiEmbeddingRecord(kpi *models.KPIDefinition, content string, embedding []float32) *models.SchemaEmbedding {
+	metadata := models.JSON(fmt.Sprintf(`{"category":"%s","unit":"%s","grain":"%s","user_id":%d}`, kpi.Category, kpi.Unit, kpi.Grain, kpi.UserID))
+	return &models.SchemaEmbedding{
+		DataSourceID: 0, // KPIs are not tied to specific data sources
+		SchemaID:     0,
+		UserID:       kpi.UserID,
+		TenantID:     kpi.TenantID,
+		ElementType:  "kpi",
+		ElementName:  kpi.Name,
+		Content:      content,
+		Embedding:    embedding,
+		Metadata:     metadata,
+		ContentHash:  embeddingContentHash(content, metadata),
+	}
+}
+
+// glossaryEmbeddingRecord builds the stored embedding of a glossary term (schema_id = 0 for glossary)
+func glossaryEmbeddingRecord(glossary *models.BusinessGlossary, content string, embedding []float32) *models.SchemaEmbedding {
+	metadata := models.JSON(fmt.Sprintf(`{"category":"%s","domain":"%s","user_id":%d}`, glossary.Category, glossary.Domain, glossary.UserID))
+	return &models.SchemaEmbedding{
 		DataSourceID: 0, // Glossary terms are not tied to specific data sources
 		SchemaID:     0,
+		UserID:       glossary.UserID,
+		TenantID:     glossary.TenantID,
 		ElementType:  "glossary",
 		ElementName:  glossary.Term,
 		Content:      content,
 		Embedding:    embedding,
-		Metadata:     models.JSON(fmt.Sprintf(`{"category":"%s","domain":"%s","user_id":%d}`, glossary.Category, glossary.Domain, glossary.UserID)),
+		Metadata:     metadata,
+		ContentHash:  embeddingContentHash(content, metadata),
 	}
+}
 
-	if err := s.db.Create(glossaryEmbeddingRecord).Error; err != nil {
-		return fmt.Errorf("failed to store glossary embedding: %w", err)
+// ReembedColumns replaces the embeddings of a table and of the named columns,
+// e.g. after their metadata was curated. Columns no longer in the schema are
+// skipped.
+func (s *EmbeddingService) ReembedColumns(ctx context.Context, schema *models.Schema, names []string) error {
+	var columns []models.Column
+	if err := json.Unmarshal(schema.Columns, &columns); err != nil {
+		return fmt.Errorf("failed to parse columns: %w", err)
 	}
 
-	return nil
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var changed []models.Column
+	for _, column := range columns {
+		if wanted[column.Name] {
+			changed = append(changed, column)
+		}
+	}
+
+	owner, err := s.dataSourceOwner(schema.DataSourceID)
+	if err != nil {
+		return err
+	}
+	// The embeddings and the AI usage belong to the tenant of the data source
+	ctx = tenancy.WithTenant(ctx, owner.TenantID)
+	s = s.WithContext(ctx)
+
+	texts := []string{s.buildTableContent(*schema, columns)}
+	for _, column := range changed {
+		texts = append(texts, s.buildColumnContent(schema.Name, column))
+	}
+	embeddings, err := s.GenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+
+	tableMetadata := buildTableMetadata(schema)
+	records := []*models.SchemaEmbedding{{
+		DataSourceID: schema.DataSourceID,
+		SchemaID:     schema.ID,
+		UserID:       owner.UserID,
+		TenantID:     owner.TenantID,
+		ElementType:  "table",
+		ElementName:  schema.Name,
+		Content:      texts[0],
+		Embedding:    embeddings[0],
+		Metadata:     tableMetadata,
+		ContentHash:  embeddingContentHash(texts[0], tableMetadata),
+	}}
+	changedNames := make([]string, 0, len(changed))
+	for i, column := range changed {
+		changedNames = append(changedNames, column.Name)
+		metadata := s.buildColumnMetadata(schema.Name, column)
+		records = append(records, &models.SchemaEmbedding{
+			DataSourceID: schema.DataSourceID,
+			SchemaID:     schema.ID,
+			UserID:       owner.UserID,
+			TenantID:     owner.TenantID,
+			ElementType:  "column",
+			ElementName:  column.Name,
+			Content:      texts[i+1],
+			Embedding:    embeddings[i+1],
+			Metadata:     metadata,
+			ContentHash:  embeddingContentHash(texts[i+1], metadata),
+		})
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schema_id = ? AND (element_type = ? OR (element_type = ? AND element_name IN ?))",
+			schema.ID, "table", "column", append(changedNames, "")).Delete(&models.SchemaEmbedding{}).Error; err != nil {
+			return fmt.Errorf("failed to delete embeddings: %w", err)
+		}
+		if err := tx.Create(records).Error; err != nil {
+			return fmt.Errorf("failed to store embeddings: %w", err)
+		}
+		return nil
+	})
 }
 
 // DeleteEmbeddings removes embeddings for a specific schema
@@ -234,8 +616,8 @@ func (s *EmbeddingService) buildTableContent(schema models.Schema, columns []mod
 	content.WriteString("\nColumn details:")
 	for _, col := range columns {
 		content.WriteString(fmt.Sprintf("\n- %s (%s)", col.Name, col.Type))
-		if col.Description != "" {
-			content.WriteString(fmt.Sprintf(": %s", col.Description))
+		if desc := col.PreferredDescription(); desc != "" {
+			content.WriteString(fmt.Sprintf(": %s", desc))
 		}
 	}
 
@@ -245,16 +627,40 @@ func (s *EmbeddingService) buildTableContent(schema models.Schema, columns []mod
 func (s *EmbeddingService) buildColumnContent(tableName string, column models.Column) string {
 	var content strings.Builder
 	content.WriteString(fmt.Sprintf("Column: %s.%s", tableName, column.Name))
+	if column.DisplayName != "" {
+		content.WriteString(fmt.Sprintf(" (%s)", column.DisplayName))
+	}
 	content.WriteString(fmt.Sprintf("\nType: %s", column.Type))
-	if column.Description != "" {
-		content.WriteString(fmt.Sprintf("\nDescription: %s", column.Description))
+	if desc := column.PreferredDescription(); desc != "" {
+		content.WriteString(fmt.Sprintf("\nDescription: %s", desc))
+	}
+	if len(column.Tags) > 0 {
+		content.WriteString(fmt.Sprintf("\nTags: %s", strings.Join(column.Tags, ", ")))
+	}
+	if len(column.Tests) > 0 {
+		content.WriteString(fmt.Sprintf("\nTests: %s", strings.Join(column.Tests, ", ")))
+	}
+	if column.PII {
+		content.WriteString("\nPII: true")
 	}
 	if column.PrimaryKey {
 		content.WriteString("\nPrimary Key: true")
 	}
+	if column.ForeignKey != nil {
+		content.WriteString(fmt.Sprintf("\nForeign Key: references %s.%s", column.ForeignKey.Table, column.ForeignKey.Column))
+	}
 	if !column.Nullable {
 		content.WriteString("\nNullable: false")
 	}
+	if column.Stats != nil {
+		if column.Stats.Min != "" || column.Stats.Max != "" {
+			content.WriteString(fmt.Sprintf("\nRange: %s to %s", column.Stats.Min, column.Stats.Max))
+		}
+		content.WriteString(fmt.Sprintf("\nDistinct values: %d", column.Stats.DistinctCount))
+		if column.Stats.NullRatio > 0 {
+			content.WriteString(fmt.Sprintf("\nNull ratio: %.2f", column.Stats.NullRatio))
+		}
+	}
 	if len(column.SampleValues) > 0 {
 		content.WriteString("\nSample values: ")
 		for i, val := range column.SampleValues {
@@ -271,6 +677,44 @@ func (s *EmbeddingService) buildColumnContent(tableName string, column models.Co
 	return content.String()
 }
 
+// buildColumnMetadata carries the column type, statistics and sample values so
+// they can be shown in the NL2SQL prompt without reloading the schema
+func (s *EmbeddingService) buildColumnMetadata(tableName string, column models.Column) models.JSON {
+	metadata := map[string]interface{}{
+		"table":       tableName,
+		"type":        column.Type,
+		"nullable":    column.Nullable,
+		"primary_key": column.PrimaryKey,
+	}
+	if len(column.SampleValues) > 0 {
+		metadata["sample_values"] = column.SampleValues
+	}
+	if column.Stats != nil {
+		metadata["stats"] = column.Stats
+	}
+	if desc := column.PreferredDescription(); desc != "" {
+		metadata["description"] = desc
+	}
+	if column.DisplayName != "" {
+		metadata["display_name"] = column.DisplayName
+	}
+	if len(column.Tags) > 0 {
+		metadata["tags"] = column.Tags
+	}
+	if len(column.Tests) > 0 {
+		metadata["tests"] = column.Tests
+	}
+	if column.PII {
+		metadata["pii"] = true
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return models.JSON(fmt.Sprintf(`{"table":"%s","type":"%s"}`, tableName, column.Type))
+	}
+	return models.JSON(metadataJSON)
+}
+
 func (s *EmbeddingService) buildKPIContent(kpi *models.KPIDefinition) string {
 	var content strings.Builder
 	content.WriteString(fmt.Sprintf("KPI: %s", kpi.Name))
@@ -314,6 +758,22 @@ func (s *EmbeddingService) buildGlossaryContent(glossary *models.BusinessGlossar
 		}
 	}
 
+	// Add translations, so questions in other languages retrieve the term
+	var translations map[string][]string
+	if glossary.Translations != nil {
+		json.Unmarshal(glossary.Translations, &translations)
+		languages := make([]string, 0, len(translations))
+		for language := range translations {
+			languages = append(languages, language)
+		}
+		sort.Strings(languages)
+		for _, language := range languages {
+			if len(translations[language]) > 0 {
+				content.WriteString(fmt.Sprintf("\nTranslations (%s): %s", language, strings.Join(translations[language], ", ")))
+			}
+		}
+	}
+
 	// Add examples
 	var examples []string
 	if glossary.Examples != nil {
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderEmbeddings(t *testing.T) {
+	var resp EmbeddingResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"data":[{"index":1,"embedding":[0.2]},{"index":0,"embedding":[0.1]}]}`), &resp))
+
+	embeddings, err := orderEmbeddings(resp, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.1}, {0.2}}, embeddings)
+
+	_, err = orderEmbeddings(resp, 3)
+	assert.EqualError(t, err, "no embedding received for input 2")
+
+	_, err = orderEmbeddings(resp, 1)
+	assert.EqualError(t, err, "embedding index 1 out of range")
+}
+
+func TestDiffSchemaEmbeddings(t *testing.T) {
+	service := &EmbeddingService{}
+	schema := &models.Schema{ID: 7, DataSourceID: 3, Name: "orders", Columns: models.JSON(`[{"name":"id","type":"integer"},{"name":"total","type":"numeric"}]`)}
+	var columns []models.Column
+	require.NoError(t, json.Unmarshal(schema.Columns, &columns))
+	desired := service.schemaEmbeddingRecords(schema, columns, &models.DataSource{UserID: 9, TenantID: 2})
+	require.Len(t, desired, 3)
+	for _, record := range desired {
+		assert.Equal(t, uint(9), record.UserID)
+		assert.Equal(t, uint(2), record.TenantID)
+	}
+
+	existing := []models.SchemaEmbedding{
+		{ID: 1, UserID: 9, TenantID: 2, ElementType: "table", ElementName: "orders", ContentHash: desired[0].ContentHash},
+		{ID: 2, UserID: 9, TenantID: 2, ElementType: "column", ElementName: "id", ContentHash: desired[1].ContentHash},
+		{ID: 3, UserID: 9, TenantID: 2, ElementType: "column", ElementName: "id", ContentHash: desired[1].ContentHash}, // Duplicate
+		{ID: 4, UserID: 9, TenantID: 2, ElementType: "column", ElementName: "total"},                                   // Stored before hashes
+		{ID: 5, ElementType: "column", ElementName: "discount", ContentHash: "dropped"},
+	}
+
+	diff := diffSchemaEmbeddings(existing, desired)
+	assert.Equal(t, []uint{1, 2}, diff.keep)
+	assert.Equal(t, []uint{3, 4, 5}, diff.stale)
+	assert.Equal(t, 1, diff.removed)
+	require.Len(t, diff.changed, 1)
+	assert.Equal(t, "total", diff.changed[0].ElementName)
+
+	// A changed description changes the table and column hashes
+	columns[1].Description = "Order total in IDR"
+	changed := service.schemaEmbeddingRecords(schema, columns, &models.DataSource{UserID: 9, TenantID: 2})
+	assert.NotEqual(t, desired[0].ContentHash, changed[0].ContentHash)
+	assert.Equal(t, desired[1].ContentHash, changed[1].ContentHash)
+	assert.NotEqual(t, desired[2].ContentHash, changed[2].ContentHash)
+
+	diff = diffSchemaEmbeddings(nil, desired)
+	assert.Len(t, diff.changed, 3)
+	assert.Empty(t, diff.stale)
+
+	// Unchanged elements stored for another owner are replaced
+	diff = diffSchemaEmbeddings([]models.SchemaEmbedding{
+		{ID: 6, UserID: 4, ElementType: "table", ElementName: "orders", ContentHash: desired[0].ContentHash},
+	}, desired[:1])
+	assert.Empty(t, diff.keep)
+	assert.Equal(t, []uint{6}, diff.stale)
+	require.Len(t, diff.changed, 1)
+	assert.Equal(t, uint(9), diff.changed[0].UserID)
+
+	// Unchanged elements stored in another tenant are replaced
+	diff = diffSchemaEmbeddings([]models.SchemaEmbedding{
+		{ID: 7, UserID: 9, TenantID: 1, ElementType: "table", ElementName: "orders", ContentHash: desired[0].ContentHash},
+	}, desired[:1])
+	assert.Empty(t, diff.keep)
+	assert.Equal(t, []uint{7}, diff.stale)
+	require.Len(t, diff.changed, 1)
+	assert.Equal(t, uint(2), diff.changed[0].TenantID)
+}
+
+func TestEmbeddingKey(t *testing.T) {
+	service := &EmbeddingService{}
+	first := kpiEmbeddingRecord(&models.KPIDefinition{UserID: 1, Name: "revenue"}, "Revenue", nil)
+	second := kpiEmbeddingRecord(&models.KPIDefinition{UserID: 2, Name: "revenue"}, "Revenue", nil)
+	assert.NotEqual(t, embeddingKey(first), embeddingKey(second))
+	assert.NotEmpty(t, first.ContentHash)
+	assert.Equal(t, uint(1), first.UserID)
+	assert.Equal(t, uint(2), second.UserID)
+
+	// The KPI of one user is kept while the other user's changed KPI is re-embedded
+	existing := []models.SchemaEmbedding{
+		{ID: 1, UserID: 1, ElementType: "kpi", ElementName: "revenue", Metadata: first.Metadata, ContentHash: first.ContentHash},
+		{ID: 2, UserID: 2, ElementType: "kpi", ElementName: "revenue", Metadata: second.Metadata, ContentHash: "old"},
+	}
+	diff := diffSchemaEmbeddings(existing, []*models.SchemaEmbedding{first, second})
+	assert.Equal(t, []uint{1}, diff.keep)
+	assert.Equal(t, []uint{2}, diff.stale)
+	assert.Equal(t, 0, diff.removed)
+
+	glossary := &models.BusinessGlossary{UserID: 1, Term: "GMV", Definition: "Gross merchandise value"}
+	record := glossaryEmbeddingRecord(glossary, service.buildGlossaryContent(glossary), nil)
+	assert.Equal(t, embeddingContentHash(record.Content, record.Metadata), record.ContentHash)
+}
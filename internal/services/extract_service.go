@@ -0,0 +1,465 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrExtractNotFound is returned when an extract does not exist or belongs to another user
+	ErrExtractNotFound = errors.New("extract not found")
+	// ErrExtractInvalid is returned for extract requests that cannot be applied
+	ErrExtractInvalid = errors.New("invalid extract")
+	// ErrExtractNotRefreshed is returned when the snapshot of an extract was never taken
+	ErrExtractNotRefreshed = errors.New("extract has not been refreshed yet")
+)
+
+const (
+	// defaultExtractIntervalMinutes is how often an extract is refreshed unless refresh_interval_minutes is set
+	defaultExtractIntervalMinutes = 60
+	// defaultExtractRowLimit is the rows an extract keeps unless row_limit is set
+	defaultExtractRowLimit = 10000
+	// defaultExtractPageSize is the rows of a snapshot returned per page unless limit is set
+	defaultExtractPageSize = 1000
+)
+
+// ExtractService keeps snapshots of query results that dashboard widgets
+// display instead of querying the data source on every view. Snapshots are
+// JSON lines in the extract directory, taken when an extract is created,
+// when its refresh interval passes, or on demand. Rows are stored unmasked
+// and PII-masked when read, like stored results.
+type ExtractService struct {
+	db     *gorm.DB
+	nl2sql *NL2SQLService
+	jobs   *JobService
+	dir    string // Directory of the snapshot files
+	now    func() time.Time
+}
+
+// NewExtractService creates a new extract service writing snapshots to dir
+func NewExtractService(db *gorm.DB, nl2sql *NL2SQLService, jobs *JobService, dir string) *ExtractService {
+	return &ExtractService{
+		db:     db,
+		nl2sql: nl2sql,
+		jobs:   jobs,
+		dir:    dir,
+		now:    time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ExtractService) WithContext(ctx context.Context) *ExtractService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sql = s.nl2sql.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the job that refreshes the extracts that are due,
+// which is scheduled at the extract sync interval, and the job that
+// refreshes one extract on demand
+func (s *ExtractService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeExtractSync, func(ctx context.Context, _ json.RawMessage) error {
+		refreshed, failed, err := s.SyncDue(ctx)
+		if err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info().Int("refreshed", refreshed).Int("failed", failed).Msg("Extract sync completed")
+		return nil
+	})
+	jobs.Register(models.JobTypeExtractRefresh, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.ExtractJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		var extract models.Extract
+		if err := s.db.First(&extract, p.ExtractID).Error; err != nil {
+			// Deleted since the job was queued
+			logger.FromContext(ctx).Info().Uint("extract_id", p.ExtractID).Msg("Extract no longer exists, skipping refresh")
+			return nil
+		}
+		return s.WithContext(tenancy.WithTenant(ctx, extract.TenantID)).Refresh(&extract)
+	})
+}
+
+// List returns the extracts of a user with their freshness
+func (s *ExtractService) List(userID uint) ([]models.ExtractResponse, error) {
+	var extracts []models.Extract
+	if err := s.db.Where("user_id = ?", userID).Order("name, id").Find(&extracts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list extracts: %w", err)
+	}
+	responses := make([]models.ExtractResponse, 0, len(extracts))
+	for i := range extracts {
+		responses = append(responses, *s.response(&extracts[i]))
+	}
+	return responses, nil
+}
+
+// Get returns an extract of the user with its freshness
+func (s *ExtractService) Get(userID, extractID uint) (*models.ExtractResponse, error) {
+	extract, err := s.owned(userID, extractID)
+	if err != nil {
+		return nil, err
+	}
+	return s.response(extract), nil
+}
+
+// Create makes an extract of a query of the user and queues its first snapshot
+func (s *ExtractService) Create(ctx context.Context, userID uint, req *models.ExtractRequest) (*models.ExtractResponse, error) {
+	if req.QueryID == 0 {
+		return nil, fmt.Errorf("%w: query_id is required", ErrExtractInvalid)
+	}
+	var query models.NL2SQLQuery
+	if err := s.db.Select("id", "data_source_id").Where("id = ? AND user_id = ?", req.QueryID, userID).First(&query).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQueryNotFound
+		}
+		return nil, fmt.Errorf("failed to get query: %w", err)
+	}
+
+	extract := &models.Extract{
+		UserID:                 userID,
+		QueryID:                query.ID,
+		DataSourceID:           query.DataSourceID,
+		RefreshIntervalMinutes: defaultExtractIntervalMinutes,
+		RowLimit:               defaultExtractRowLimit,
+		Status:                 models.MaterializationStatusSyncing,
+	}
+	applyExtractRequest(extract, req)
+	if err := s.db.Create(extract).Error; err != nil {
+		return nil, fmt.Errorf("failed to create extract: %w", err)
+	}
+	return s.queueRefresh(ctx, extract)
+}
+
+// Update changes the name, description, refresh interval or row limit of an extract of the user
+func (s *ExtractService) Update(userID, extractID uint, req *models.ExtractRequest) (*models.ExtractResponse, error) {
+	extract, err := s.owned(userID, extractID)
+	if err != nil {
+		return nil, err
+	}
+	if req.QueryID != 0 && req.QueryID != extract.QueryID {
+		return nil, fmt.Errorf("%w: the query of an extract cannot change", ErrExtractInvalid)
+	}
+
+	applyExtractRequest(extract, req)
+	err = s.db.Model(extract).Updates(map[string]interface{}{
+		"name":                     extract.Name,
+		"description":              extract.Description,
+		"refresh_interval_minutes": extract.RefreshIntervalMinutes,
+		"row_limit":                extract.RowLimit,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update extract: %w", err)
+	}
+	return s.response(extract), nil
+}
+
+// Delete removes an extract of the user and its snapshot. Widgets bound to it
+// go back to the latest result of their query.
+func (s *ExtractService) Delete(userID, extractID uint) error {
+	extract, err := s.owned(userID, extractID)
+	if err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.DashboardWidget{}).Where("extract_id = ?", extract.ID).Update("extract_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(extract).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete extract: %w", err)
+	}
+	if extract.FilePath != "" {
+		if err := os.Remove(extract.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.L().Warn().Err(err).Uint("extract_id", extract.ID).Msg("Failed to remove extract snapshot")
+		}
+	}
+	return nil
+}
+
+// QueueRefresh queues a refresh of an extract of the user now rather than at its refresh interval
+func (s *ExtractService) QueueRefresh(ctx context.Context, userID, extractID uint) (*models.ExtractResponse, error) {
+	extract, err := s.owned(userID, extractID)
+	if err != nil {
+		return nil, err
+	}
+	return s.queueRefresh(ctx, extract)
+}
+
+func (s *ExtractService) queueRefresh(ctx context.Context, extract *models.Extract) (*models.ExtractResponse, error) {
+	payload := models.ExtractJobPayload{ExtractID: extract.ID}
+	job, err := s.jobs.EnqueueUnique(ctx, models.JobTypeExtractRefresh, fmt.Sprintf("extract:%d", extract.ID), payload)
+	if err != nil {
+		return nil, err
+	}
+	response := s.response(extract)
+	response.JobID = job.ID
+	return response, nil
+}
+
+// SyncDue refreshes the extracts whose refresh interval has passed. A failed
+// refresh keeps the previous snapshot and is recorded on the extract.
+func (s *ExtractService) SyncDue(ctx context.Context) (int, int, error) {
+	var extracts []models.Extract
+	if err := s.db.Where("refresh_interval_minutes > 0").Order("id").Find(&extracts).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to list extracts: %w", err)
+	}
+
+	refreshed, failed := 0, 0
+	now := s.now()
+	for i := range extracts {
+		if ctx.Err() != nil {
+			break
+		}
+		extract := &extracts[i]
+		if !extractDue(extract, now) {
+			continue
+		}
+		if err := s.WithContext(tenancy.WithTenant(ctx, extract.TenantID)).Refresh(extract); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Uint("extract_id", extract.ID).Msg("Failed to refresh extract")
+			failed++
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, failed, nil
+}
+
+// Refresh runs the query of an extract as its owner and replaces the snapshot
+func (s *ExtractService) Refresh(extract *models.Extract) error {
+	response, err := s.nl2sql.ExecuteQuery(extract.UserID, &models.QueryExecutionRequest{
+		QueryID:   extract.QueryID,
+		Limit:     extract.RowLimit,
+		UnmaskPII: true, // Masked when read
+	})
+	if err == nil && response.Status == models.QueryStatusFailed {
+		err = errors.New(response.Message)
+	}
+	if err != nil {
+		s.recordFailure(extract, err)
+		return fmt.Errorf("failed to run extract query: %w", err)
+	}
+
+	path := s.FilePath(extract.ID)
+	if err := writeJSONLines(path, response.Data); err != nil {
+		s.recordFailure(extract, err)
+		return fmt.Errorf("failed to write extract snapshot: %w", err)
+	}
+	columns, _ := json.Marshal(response.Columns)
+
+	now := s.now()
+	extract.FilePath = path
+	extract.Columns = models.JSON(columns)
+	extract.RowCount = int64(len(response.Data))
+	extract.Truncated = extract.RowLimit > 0 && len(response.Data) >= extract.RowLimit
+	extract.Status = models.MaterializationStatusCompleted
+	extract.Error = ""
+	extract.DurationMs = response.ExecutionTime
+	extract.RefreshedAt = &now
+	err = s.db.Model(extract).Updates(map[string]interface{}{
+		"file_path":    extract.FilePath,
+		"columns":      extract.Columns,
+		"row_count":    extract.RowCount,
+		"truncated":    extract.Truncated,
+		"status":       extract.Status,
+		"error":        "",
+		"duration_ms":  extract.DurationMs,
+		"refreshed_at": now,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record extract refresh: %w", err)
+	}
+	return nil
+}
+
+// recordFailure marks an extract as failed, keeping the previous snapshot
+func (s *ExtractService) recordFailure(extract *models.Extract, cause error) {
+	extract.Status = models.MaterializationStatusFailed
+	extract.Error = cause.Error()
+	err := s.db.Model(extract).Updates(map[string]interface{}{
+		"status": extract.Status,
+		"error":  extract.Error,
+	}).Error
+	if err != nil {
+		logger.L().Error().Err(err).Uint("extract_id", extract.ID).Msg("Failed to record extract failure")
+	}
+}
+
+// Data returns a page of the snapshot of an extract of the user
+func (s *ExtractService) Data(userID, extractID uint, req *models.ExtractDataRequest) (*models.ExtractData, error) {
+	extract, err := s.owned(userID, extractID)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultExtractPageSize
+	}
+
+	columns, rows, err := s.snapshot(extract)
+	if err != nil {
+		return nil, err
+	}
+	data := &models.ExtractData{
+		ExtractID: extract.ID,
+		Columns:   columns,
+		Data:      pageRows(rows, req.Offset, limit),
+		TotalRows: int64(len(rows)),
+		HasMore:   req.Offset+limit < len(rows),
+		Freshness: extractFreshness(extract, s.now()),
+	}
+	if !req.UnmaskPII {
+		data.Data, data.MaskedColumns = s.nl2sql.piiMasker.MaskRows(extract.DataSourceID, columns, data.Data)
+	}
+	return data, nil
+}
+
+// widgetResult returns the PII-masked snapshot a widget bound to an extract
+// displays, with the question of the extract query
+func (s *ExtractService) widgetResult(extractID uint, limit int) (*models.SharedResult, error) {
+	var extract models.Extract
+	if err := s.db.First(&extract, extractID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExtractNotFound
+		}
+		return nil, fmt.Errorf("failed to get extract: %w", err)
+	}
+	columns, rows, err := s.snapshot(&extract)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SharedResult{
+		Columns:   columns,
+		Data:      pageRows(rows, 0, limit),
+		TotalRows: int64(len(rows)),
+		Truncated: len(rows) > limit,
+		Freshness: extractFreshness(&extract, s.now()),
+	}
+	result.Data, result.MaskedColumns = s.nl2sql.piiMasker.MaskRows(extract.DataSourceID, columns, result.Data)
+	if extract.RefreshedAt != nil {
+		result.CreatedAt = *extract.RefreshedAt
+	}
+	var query models.NL2SQLQuery
+	if err := s.db.Select("id", "nl_query").First(&query, extract.QueryID).Error; err == nil {
+		result.Question = query.NLQuery
+	}
+	return result, nil
+}
+
+// snapshot reads the columns and rows of the snapshot of an extract
+func (s *ExtractService) snapshot(extract *models.Extract) ([]models.Column, []map[string]interface{}, error) {
+	if extract.RefreshedAt == nil || extract.FilePath == "" {
+		return nil, nil, ErrExtractNotRefreshed
+	}
+	var columns []models.Column
+	if len(extract.Columns) > 0 {
+		if err := json.Unmarshal(extract.Columns, &columns); err != nil {
+			return nil, nil, fmt.Errorf("invalid extract columns: %w", err)
+		}
+	}
+	rows, err := readJSONLines(extract.FilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read extract snapshot: %w", err)
+	}
+	if rows == nil {
+		rows = []map[string]interface{}{}
+	}
+	return columns, rows, nil
+}
+
+// FilePath returns the file the snapshot of an extract is written to
+func (s *ExtractService) FilePath(extractID uint) string {
+	return filepath.Join(s.dir, fmt.Sprintf("extract_%d.jsonl", extractID))
+}
+
+func (s *ExtractService) response(extract *models.Extract) *models.ExtractResponse {
+	return &models.ExtractResponse{
+		Extract:   *extract,
+		Freshness: extractFreshness(extract, s.now()),
+	}
+}
+
+func (s *ExtractService) owned(userID, extractID uint) (*models.Extract, error) {
+	var extract models.Extract
+	if err := s.db.Where("id = ? AND user_id = ?", extractID, userID).First(&extract).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExtractNotFound
+		}
+		return nil, fmt.Errorf("failed to get extract: %w", err)
+	}
+	return &extract, nil
+}
+
+// applyExtractRequest copies the settings of a request onto an extract
+func applyExtractRequest(extract *models.Extract, req *models.ExtractRequest) {
+	extract.Name = req.Name
+	extract.Description = req.Description
+	if req.RefreshIntervalMinutes != nil {
+		extract.RefreshIntervalMinutes = *req.RefreshIntervalMinutes
+	}
+	if req.RowLimit > 0 {
+		extract.RowLimit = req.RowLimit
+	}
+}
+
+// extractDue reports whether the refresh interval of an extract has passed
+// since its last successful refresh; extracts without an interval are only
+// refreshed on demand
+func extractDue(extract *models.Extract, now time.Time) bool {
+	if extract.RefreshIntervalMinutes <= 0 {
+		return false
+	}
+	if extract.RefreshedAt == nil {
+		return true
+	}
+	interval := time.Duration(extract.RefreshIntervalMinutes) * time.Minute
+	return !now.Before(extract.RefreshedAt.Add(interval))
+}
+
+// extractFreshness describes the snapshot of an extract: its age, stale when
+// it was never taken, the last refresh failed, or a refresh is overdue
+func extractFreshness(extract *models.Extract, now time.Time) *models.DataFreshness {
+	freshness := &models.DataFreshness{Source: "extract"}
+	if extract.RefreshedAt == nil {
+		freshness.Stale = true
+		return freshness
+	}
+
+	freshness.MaterializedAt = extract.RefreshedAt
+	age := now.Sub(*extract.RefreshedAt)
+	freshness.AgeSeconds = int64(age.Seconds())
+	interval := time.Duration(extract.RefreshIntervalMinutes) * time.Minute
+	freshness.Stale = extract.Status == models.MaterializationStatusFailed ||
+		(interval > 0 && age > materializationStaleFactor*interval)
+	return freshness
+}
+
+// pageRows returns the rows from offset, at most limit of them
+func pageRows(rows []map[string]interface{}, offset, limit int) []map[string]interface{} {
+	if offset >= len(rows) {
+		return []map[string]interface{}{}
+	}
+	end := offset + limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[offset:end]
+}
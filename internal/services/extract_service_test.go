@@ -0,0 +1,81 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDueAndFreshness(t *testing.T) {
+	now := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+	refreshed := now.Add(-90 * time.Minute)
+	extract := &models.Extract{RefreshIntervalMinutes: 60, Status: models.MaterializationStatusCompleted, RefreshedAt: &refreshed}
+
+	assert.True(t, extractDue(extract, now), "the hour has passed")
+	assert.True(t, extractDue(&models.Extract{RefreshIntervalMinutes: 60}, now), "never refreshed")
+	assert.False(t, extractDue(&models.Extract{RefreshIntervalMinutes: 0}, now), "refreshed on demand only")
+	extract.RefreshIntervalMinutes = 120
+	assert.False(t, extractDue(extract, now))
+
+	freshness := extractFreshness(extract, now)
+	assert.Equal(t, "extract", freshness.Source)
+	require.NotNil(t, freshness.MaterializedAt)
+	assert.Equal(t, refreshed, *freshness.MaterializedAt)
+	assert.Equal(t, int64(5400), freshness.AgeSeconds)
+	assert.False(t, freshness.Stale)
+
+	extract.RefreshIntervalMinutes = 30
+	assert.True(t, extractFreshness(extract, now).Stale, "more than two intervals late")
+	extract.RefreshIntervalMinutes = 0
+	assert.False(t, extractFreshness(extract, now).Stale, "on demand extracts are never overdue")
+	extract.Status = models.MaterializationStatusFailed
+	assert.True(t, extractFreshness(extract, now).Stale, "a failed refresh is stale")
+	assert.True(t, extractFreshness(&models.Extract{RefreshIntervalMinutes: 60}, now).Stale, "never refreshed")
+}
+
+func TestApplyExtractRequest(t *testing.T) {
+	extract := &models.Extract{RefreshIntervalMinutes: defaultExtractIntervalMinutes, RowLimit: defaultExtractRowLimit}
+	applyExtractRequest(extract, &models.ExtractRequest{Name: "Daily revenue"})
+	assert.Equal(t, "Daily revenue", extract.Name)
+	assert.Equal(t, 60, extract.RefreshIntervalMinutes)
+	assert.Equal(t, 10000, extract.RowLimit)
+
+	onDemand := 0
+	applyExtractRequest(extract, &models.ExtractRequest{Name: "Revenue", RefreshIntervalMinutes: &onDemand, RowLimit: 500})
+	assert.Equal(t, 0, extract.RefreshIntervalMinutes)
+	assert.Equal(t, 500, extract.RowLimit)
+}
+
+func TestExtractSnapshot(t *testing.T) {
+	s := NewExtractService(nil, nil, nil, t.TempDir())
+	assert.Equal(t, filepath.Join(s.dir, "extract_3.jsonl"), s.FilePath(3))
+
+	_, _, err := s.snapshot(&models.Extract{ID: 3})
+	assert.ErrorIs(t, err, ErrExtractNotRefreshed)
+
+	rows := []map[string]interface{}{{"region": "north", "revenue": float64(12)}, {"region": "south", "revenue": float64(8)}}
+	require.NoError(t, writeJSONLines(s.FilePath(3), rows))
+	refreshed := time.Now()
+	extract := &models.Extract{
+		ID:          3,
+		FilePath:    s.FilePath(3),
+		Columns:     models.JSON(`[{"name":"region","type":"string"},{"name":"revenue","type":"float"}]`),
+		RefreshedAt: &refreshed,
+	}
+	columns, read, err := s.snapshot(extract)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"region", "revenue"}, []string{columns[0].Name, columns[1].Name})
+	assert.Equal(t, rows, read)
+}
+
+func TestPageRows(t *testing.T) {
+	rows := []map[string]interface{}{{"n": 1}, {"n": 2}, {"n": 3}}
+	assert.Equal(t, rows[1:3], pageRows(rows, 1, 5))
+	assert.Equal(t, rows[:2], pageRows(rows, 0, 2))
+	assert.Empty(t, pageRows(rows, 3, 2))
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+// featureFlagCacheTTL is how long flags are cached for evaluation; changes
+// made on another instance apply after it
+const featureFlagCacheTTL = 30 * time.Second
+
+var featureFlagKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+var (
+	// ErrFeatureFlagNotFound is returned when no flag has the key
+	ErrFeatureFlagNotFound = errors.New("feature flag not found")
+	// ErrFeatureFlagKeyTaken is returned when creating a flag with the key of another
+	ErrFeatureFlagKeyTaken = errors.New("feature flag key is already taken")
+	// ErrInvalidFeatureFlagKey is returned for keys with other characters than
+	// lowercase letters, digits, dots, dashes and underscores
+	ErrInvalidFeatureFlagKey = errors.New("feature flag key may only contain lowercase letters, digits, dots, dashes and underscores")
+	// ErrFeatureDisabled is returned when using a capability whose feature flag is off for the user
+	ErrFeatureDisabled = errors.New("feature is not enabled")
+)
+
+// FeatureFlagSubject is who a flag is evaluated for
+type FeatureFlagSubject struct {
+	TenantID uint
+	UserID   uint
+}
+
+// FeatureFlagService manages feature flags and evaluates them for users. Flags
+// are evaluated from an in-memory copy, so checking one costs no query.
+type FeatureFlagService struct {
+	db       *gorm.DB
+	mu       sync.Mutex
+	flags    map[string]*models.FeatureFlag
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+// NewFeatureFlagService creates a new feature flag service
+func NewFeatureFlagService(db *gorm.DB) *FeatureFlagService {
+	return &FeatureFlagService{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+// Enabled reports whether the flag is on for the user, in the tenant of the
+// context. Unknown flags are off.
+func (s *FeatureFlagService) Enabled(ctx context.Context, key string, userID uint) bool {
+	tenantID, _ := tenancy.FromContext(ctx)
+	return s.Evaluate(key, FeatureFlagSubject{TenantID: tenantID, UserID: userID}).Enabled
+}
+
+// Evaluate returns whether the flag is on for the subject, and why
+func (s *FeatureFlagService) Evaluate(key string, subject FeatureFlagSubject) models.FeatureFlagEvaluation {
+	flag := s.cached()[key]
+	return evaluateFeatureFlag(key, flag, subject)
+}
+
+// EvaluateAll evaluates every flag for the user, in the tenant of the context
+func (s *FeatureFlagService) EvaluateAll(ctx context.Context, userID uint) []models.FeatureFlagEvaluation {
+	tenantID, _ := tenancy.FromContext(ctx)
+	subject := FeatureFlagSubject{TenantID: tenantID, UserID: userID}
+
+	flags := s.cached()
+	evaluations := make([]models.FeatureFlagEvaluation, 0, len(flags))
+	for key, flag := range flags {
+		evaluations = append(evaluations, evaluateFeatureFlag(key, flag, subject))
+	}
+	sort.Slice(evaluations, func(i, j int) bool { return evaluations[i].Key < evaluations[j].Key })
+	return evaluations
+}
+
+func evaluateFeatureFlag(key string, flag *models.FeatureFlag, subject FeatureFlagSubject) models.FeatureFlagEvaluation {
+	evaluation := models.FeatureFlagEvaluation{Key: key, Reason: models.FeatureFlagReasonUnknown}
+	if flag == nil {
+		return evaluation
+	}
+
+	var tenantOverride *models.FeatureFlagOverride
+	for i := range flag.Overrides {
+		override := &flag.Overrides[i]
+		switch {
+		case override.Scope == models.FeatureFlagScopeUser && subject.UserID != 0 && override.SubjectID == subject.UserID:
+			evaluation.Enabled, evaluation.Reason = override.Enabled, models.FeatureFlagReasonUserOverride
+			return evaluation
+		case override.Scope == models.FeatureFlagScopeTenant && subject.TenantID != 0 && override.SubjectID == subject.TenantID:
+			tenantOverride = override
+		}
+	}
+	if tenantOverride != nil {
+		evaluation.Enabled, evaluation.Reason = tenantOverride.Enabled, models.FeatureFlagReasonTenantOverride
+		return evaluation
+	}
+	if !flag.Enabled {
+		evaluation.Reason = models.FeatureFlagReasonDisabled
+		return evaluation
+	}
+	evaluation.Enabled = rolloutBucket(key, subject.UserID) < flag.RolloutPercentage
+	evaluation.Reason = models.FeatureFlagReasonRollout
+	return evaluation
+}
+
+// rolloutBucket places a user in one of 100 buckets of a flag. Buckets are
+// stable, so raising the rollout percentage only adds users; hashing the key
+// too keeps the first users of every rollout from being the same ones.
+func rolloutBucket(key string, userID uint) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatUint(uint64(userID), 10)))
+	return int(h.Sum32() % 100)
+}
+
+// cached returns the flags by key, reloading them when the cache expired. When
+// reloading fails the previous flags are kept.
+func (s *FeatureFlagService) cached() map[string]*models.FeatureFlag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.flags != nil && s.now().Sub(s.loadedAt) < featureFlagCacheTTL {
+		return s.flags
+	}
+
+	var flags []models.FeatureFlag
+	if err := s.db.Preload("Overrides").Find(&flags).Error; err != nil {
+		logger.L().Error().Err(err).Msg("Failed to load feature flags")
+		if s.flags == nil {
+			return map[string]*models.FeatureFlag{}
+		}
+		return s.flags
+	}
+	s.flags = make(map[string]*models.FeatureFlag, len(flags))
+	for i := range flags {
+		s.flags[flags[i].Key] = &flags[i]
+	}
+	s.loadedAt = s.now()
+	return s.flags
+}
+
+// invalidate reloads the flags on their next evaluation after a change
+func (s *FeatureFlagService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags = nil
+}
+
+// ListFlags returns every flag with its overrides
+func (s *FeatureFlagService) ListFlags(ctx context.Context) ([]models.FeatureFlag, error) {
+	flags := []models.FeatureFlag{}
+	if err := s.db.WithContext(ctx).Preload("Overrides").Order("key").Find(&flags).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// GetFlag returns a flag with its overrides
+func (s *FeatureFlagService) GetFlag(ctx context.Context, key string) (*models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	if err := s.db.WithContext(ctx).Preload("Overrides").Where("key = ?", key).First(&flag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFeatureFlagNotFound
+		}
+		return nil, fmt.Errorf("failed to load feature flag: %w", err)
+	}
+	return &flag, nil
+}
+
+// CreateFlag creates a flag
+func (s *FeatureFlagService) CreateFlag(ctx context.Context, req *models.FeatureFlagCreateRequest) (*models.FeatureFlag, error) {
+	if !featureFlagKeyPattern.MatchString(req.Key) {
+		return nil, ErrInvalidFeatureFlagKey
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.FeatureFlag{}).Where("key = ?", req.Key).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check feature flag key: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrFeatureFlagKeyTaken
+	}
+
+	flag := &models.FeatureFlag{
+		Key:               req.Key,
+		Description:       req.Description,
+		Enabled:           req.Enabled,
+		RolloutPercentage: 100,
+		Overrides:         []models.FeatureFlagOverride{},
+	}
+	if req.RolloutPercentage != nil {
+		flag.RolloutPercentage = *req.RolloutPercentage
+	}
+	if err := s.db.WithContext(ctx).Create(flag).Error; err != nil {
+		return nil, fmt.Errorf("failed to create feature flag: %w", err)
+	}
+	s.invalidate()
+	return flag, nil
+}
+
+// UpdateFlag changes the description, state or rollout of a flag
+func (s *FeatureFlagService) UpdateFlag(ctx context.Context, key string, req *models.FeatureFlagUpdateRequest) (*models.FeatureFlag, error) {
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		flag.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		flag.Enabled = *req.Enabled
+	}
+	if req.RolloutPercentage != nil {
+		flag.RolloutPercentage = *req.RolloutPercentage
+	}
+	if err := s.db.WithContext(ctx).Omit("Overrides").Save(flag).Error; err != nil {
+		return nil, fmt.Errorf("failed to update feature flag: %w", err)
+	}
+	s.invalidate()
+	return flag, nil
+}
+
+// DeleteFlag deletes a flag with its overrides. Capabilities it gated are off
+// afterwards.
+func (s *FeatureFlagService) DeleteFlag(ctx context.Context, key string) error {
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		return err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("flag_id = ?", flag.ID).Delete(&models.FeatureFlagOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(flag).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+	s.invalidate()
+	return nil
+}
+
+// SetOverride turns a flag on or off for a tenant or user, replacing the
+// previous override of the tenant or user
+func (s *FeatureFlagService) SetOverride(ctx context.Context, key string, req *models.FeatureFlagOverrideRequest) (*models.FeatureFlagOverride, error) {
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	override := models.FeatureFlagOverride{FlagID: flag.ID, Scope: req.Scope, SubjectID: req.SubjectID}
+	for _, existing := range flag.Overrides {
+		if existing.Scope == req.Scope && existing.SubjectID == req.SubjectID {
+			override = existing
+		}
+	}
+	override.Enabled = req.Enabled
+	if err := s.db.WithContext(ctx).Save(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to save feature flag override: %w", err)
+	}
+	s.invalidate()
+	return &override, nil
+}
+
+// DeleteOverride removes the override of a flag for a tenant or user, if any
+func (s *FeatureFlagService) DeleteOverride(ctx context.Context, key, scope string, subjectID uint) error {
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		return err
+	}
+	err = s.db.WithContext(ctx).Where("flag_id = ? AND scope = ? AND subject_id = ?", flag.ID, scope, subjectID).
+		Delete(&models.FeatureFlagOverride{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+	s.invalidate()
+	return nil
+}
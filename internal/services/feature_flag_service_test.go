@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func newFeatureFlagTestService(t *testing.T) *FeatureFlagService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.FeatureFlag{}, &models.FeatureFlagOverride{}))
+	return NewFeatureFlagService(db)
+}
+
+func TestEvaluateFeatureFlag(t *testing.T) {
+	flag := &models.FeatureFlag{
+		Key:               "federated_queries",
+		Enabled:           false,
+		RolloutPercentage: 100,
+		Overrides: []models.FeatureFlagOverride{
+			{Scope: models.FeatureFlagScopeTenant, SubjectID: 2, Enabled: true},
+			{Scope: models.FeatureFlagScopeUser, SubjectID: 7, Enabled: false},
+		},
+	}
+
+	evaluation := evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{TenantID: 1, UserID: 5})
+	assert.Equal(t, models.FeatureFlagEvaluation{Key: flag.Key, Enabled: false, Reason: models.FeatureFlagReasonDisabled}, evaluation)
+
+	evaluation = evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{TenantID: 2, UserID: 5})
+	assert.Equal(t, models.FeatureFlagEvaluation{Key: flag.Key, Enabled: true, Reason: models.FeatureFlagReasonTenantOverride}, evaluation)
+
+	evaluation = evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{TenantID: 2, UserID: 7})
+	assert.Equal(t, models.FeatureFlagEvaluation{Key: flag.Key, Enabled: false, Reason: models.FeatureFlagReasonUserOverride}, evaluation, "user overrides win over tenant overrides")
+
+	flag.Enabled = true
+	evaluation = evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{TenantID: 1, UserID: 5})
+	assert.Equal(t, models.FeatureFlagEvaluation{Key: flag.Key, Enabled: true, Reason: models.FeatureFlagReasonRollout}, evaluation)
+
+	evaluation = evaluateFeatureFlag("unknown", nil, FeatureFlagSubject{TenantID: 1, UserID: 5})
+	assert.Equal(t, models.FeatureFlagEvaluation{Key: "unknown", Enabled: false, Reason: models.FeatureFlagReasonUnknown}, evaluation)
+}
+
+func TestFeatureFlagRollout(t *testing.T) {
+	flag := &models.FeatureFlag{Key: "new_chart_types", Enabled: true, RolloutPercentage: 25}
+
+	enabled := map[uint]bool{}
+	for userID := uint(1); userID <= 1000; userID++ {
+		enabled[userID] = evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{UserID: userID}).Enabled
+	}
+	count := 0
+	for _, on := range enabled {
+		if on {
+			count++
+		}
+	}
+	assert.InDelta(t, 250, count, 60, "about a quarter of the users get the flag")
+
+	// Growing the rollout keeps the users that already had the flag
+	flag.RolloutPercentage = 50
+	for userID, on := range enabled {
+		if on {
+			assert.True(t, evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{UserID: userID}).Enabled)
+		}
+	}
+
+	flag.RolloutPercentage = 0
+	assert.False(t, evaluateFeatureFlag(flag.Key, flag, FeatureFlagSubject{UserID: 1}).Enabled)
+}
+
+func TestFeatureFlagServiceCachesEvaluation(t *testing.T) {
+	s := newFeatureFlagTestService(t)
+	ctx := context.Background()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	_, err := s.CreateFlag(ctx, &models.FeatureFlagCreateRequest{Key: "federated_queries", Enabled: false})
+	require.NoError(t, err)
+	assert.False(t, s.Enabled(ctx, "federated_queries", 1))
+
+	// Overrides made through the service apply at once
+	_, err = s.SetOverride(ctx, "federated_queries", &models.FeatureFlagOverrideRequest{Scope: models.FeatureFlagScopeTenant, SubjectID: 2, Enabled: true})
+	require.NoError(t, err)
+	assert.True(t, s.Enabled(tenancy.WithTenant(ctx, 2), "federated_queries", 1))
+	assert.False(t, s.Enabled(tenancy.WithTenant(ctx, 3), "federated_queries", 1))
+
+	// Setting an override again replaces it
+	_, err = s.SetOverride(ctx, "federated_queries", &models.FeatureFlagOverrideRequest{Scope: models.FeatureFlagScopeTenant, SubjectID: 2, Enabled: false})
+	require.NoError(t, err)
+	flag, err := s.GetFlag(ctx, "federated_queries")
+	require.NoError(t, err)
+	assert.Len(t, flag.Overrides, 1)
+	assert.False(t, s.Enabled(tenancy.WithTenant(ctx, 2), "federated_queries", 1))
+
+	// Changes made elsewhere apply once the cache expires
+	require.NoError(t, s.db.Model(&models.FeatureFlag{}).Where("key = ?", "federated_queries").Update("enabled", true).Error)
+	assert.False(t, s.Enabled(ctx, "federated_queries", 1))
+	now = now.Add(featureFlagCacheTTL)
+	assert.True(t, s.Enabled(ctx, "federated_queries", 1))
+
+	require.NoError(t, s.DeleteFlag(ctx, "federated_queries"))
+	assert.False(t, s.Enabled(ctx, "federated_queries", 1), "unknown flags are off")
+}
+
+func TestFeatureFlagServiceValidatesFlags(t *testing.T) {
+	s := newFeatureFlagTestService(t)
+	ctx := context.Background()
+
+	_, err := s.CreateFlag(ctx, &models.FeatureFlagCreateRequest{Key: "LLM Provider"})
+	assert.ErrorIs(t, err, ErrInvalidFeatureFlagKey)
+
+	rollout := 0
+	flag, err := s.CreateFlag(ctx, &models.FeatureFlagCreateRequest{Key: "llm.provider-switch", Enabled: true, RolloutPercentage: &rollout})
+	require.NoError(t, err)
+	assert.Equal(t, 0, flag.RolloutPercentage, "a zero rollout is kept")
+
+	_, err = s.CreateFlag(ctx, &models.FeatureFlagCreateRequest{Key: "llm.provider-switch"})
+	assert.ErrorIs(t, err, ErrFeatureFlagKeyTaken)
+
+	_, err = s.UpdateFlag(ctx, "missing", &models.FeatureFlagUpdateRequest{})
+	assert.ErrorIs(t, err, ErrFeatureFlagNotFound)
+}
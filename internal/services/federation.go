@@ -0,0 +1,428 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// ErrFederationInvalid is returned for federated questions or queries that cannot be planned or run
+var ErrFederationInvalid = errors.New("invalid federated query")
+
+const (
+	// defaultFederatedRowLimit is the rows pulled per table of a federated source unless row_limit is set
+	defaultFederatedRowLimit = 10000
+	// maxFederatedSources bounds the data sources one federated query joins
+	maxFederatedSources = 5
+)
+
+// federatedView is a table pulled for a federated query, registered in DuckDB
+// as the view Schema.Table over its staged file
+type federatedView struct {
+	Schema  string
+	Table   string
+	Path    string
+	Columns []models.Column
+}
+
+// planFederation validates the data sources a question spans and gives each
+// an alias its tables are referenced by. It returns nil for questions on one
+// data source. Federated queries are gated by the federated_queries flag.
+func (s *NL2SQLService) planFederation(userID uint, primary *models.DataSource, requests []models.FederatedSourceRequest) (*models.Federation, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	subject := FeatureFlagSubject{TenantID: primary.TenantID, UserID: userID}
+	if !s.flags.Evaluate(models.FeatureFlagFederatedQueries, subject).Enabled {
+		return nil, fmt.Errorf("%w: federated queries", ErrFeatureDisabled)
+	}
+
+	limits := make(map[uint]int)
+	ids := []uint{primary.ID}
+	for _, request := range requests {
+		if _, seen := limits[request.DataSourceID]; seen {
+			return nil, fmt.Errorf("%w: data source %d is listed twice", ErrFederationInvalid, request.DataSourceID)
+		}
+		limits[request.DataSourceID] = request.RowLimit
+		if request.DataSourceID != primary.ID {
+			ids = append(ids, request.DataSourceID)
+		}
+	}
+	if len(ids) < 2 {
+		return nil, fmt.Errorf("%w: a federated query spans at least two data sources", ErrFederationInvalid)
+	}
+	if len(ids) > maxFederatedSources {
+		return nil, fmt.Errorf("%w: a federated query spans at most %d data sources", ErrFederationInvalid, maxFederatedSources)
+	}
+
+	federation := &models.Federation{Engine: models.FederationEngineDuckDB}
+	aliases := make(map[string]bool)
+	for _, id := range ids {
+		dataSource := primary
+		if id != primary.ID {
+			var err error
+			if dataSource, err = s.validateDataSourceAccess(userID, id); err != nil {
+				return nil, fmt.Errorf("%w: data source %d: %v", ErrFederationInvalid, id, err)
+			}
+		}
+		if dataSource.Type.UsesAggregationPipeline() {
+			return nil, fmt.Errorf("%w: %s is queried with aggregation pipelines and cannot be federated", ErrFederationInvalid, dataSource.Name)
+		}
+		rowLimit := limits[id]
+		if rowLimit <= 0 {
+			rowLimit = defaultFederatedRowLimit
+		}
+		federation.Sources = append(federation.Sources, models.FederatedSource{
+			DataSourceID: dataSource.ID,
+			Name:         dataSource.Name,
+			Type:         dataSource.Type,
+			Alias:        federationAlias(dataSource, aliases),
+			Tables:       []string{},
+			RowLimit:     rowLimit,
+		})
+	}
+	return federation, nil
+}
+
+// addFederationContext tells the generator to write DuckDB SQL over the
+// tables of every source of a federated question, qualified by source alias
+func (s *NL2SQLService) addFederationContext(enhancedContext map[string]interface{}, federation *models.Federation) error {
+	schemas, err := s.federatedSchemas(federation)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\nThe question spans several data sources. Their tables are pulled into DuckDB and joined there, ")
+	b.WriteString("so write a single query and reference every table as alias.table with the aliases below.\n")
+	b.WriteString(dialectPromptGuidance(models.SQLDialectDuckDB))
+	b.WriteString("\n")
+	for _, source := range federation.Sources {
+		fmt.Fprintf(&b, "\nData source %q (%s), alias %s:\n", source.Name, source.Type, source.Alias)
+		for _, schema := range schemas[source.DataSourceID] {
+			var columns []models.Column
+			_ = json.Unmarshal(schema.Columns, &columns)
+			parts := make([]string, 0, len(columns))
+			for _, column := range columns {
+				parts = append(parts, column.Name+" "+column.Type)
+			}
+			fmt.Fprintf(&b, "- %s.%s(%s)\n", source.Alias, schema.Name, strings.Join(parts, ", "))
+		}
+	}
+
+	prompt, _ := enhancedContext["enhanced_prompt"].(string)
+	enhancedContext["enhanced_prompt"] = prompt + b.String()
+	enhancedContext["sql_dialect"] = models.SQLDialectDuckDB
+	enhancedContext["federation"] = federation
+	return nil
+}
+
+// prepareFederatedSQL validates the SQL of a federated query as DuckDB SQL
+// under the validation policy of its primary data source, and records the
+// tables of each source it reads
+func (s *NL2SQLService) prepareFederatedSQL(primary *models.DataSource, federation *models.Federation, sql string) (string, *models.SQLValidationResult, error) {
+	sql, validationResult, err := s.prepareSQLForDialect(primary.ID, models.SQLDialectDuckDB, sql)
+	if err != nil {
+		return "", validationResult, err
+	}
+	schemas, err := s.federatedSchemas(federation)
+	if err != nil {
+		return "", validationResult, err
+	}
+
+	sources, violations := resolveFederatedTables(federation.Sources, sql, federatedTableNames(schemas))
+	federation.Sources = sources
+	if len(violations) > 0 {
+		validationResult.Violations = append(validationResult.Violations, violations...)
+		validationResult.IsValid = false
+	}
+	return sql, validationResult, nil
+}
+
+// prepareQueryFor prepares new SQL for an existing query, federated or not
+func (s *NL2SQLService) prepareQueryFor(query *models.NL2SQLQuery, dataSource *models.DataSource, sql string) (string, *models.SQLValidationResult, error) {
+	if !query.Federated {
+		return s.prepareQuery(dataSource, sql)
+	}
+	federation, err := queryFederation(query)
+	if err != nil {
+		return "", nil, err
+	}
+	sql, validationResult, err := s.prepareFederatedSQL(dataSource, federation, sql)
+	if err != nil {
+		return "", validationResult, err
+	}
+	query.Federation = marshalFederation(federation)
+	return sql, validationResult, nil
+}
+
+// executeQuerySQL runs SQL of a query on its data source, or pulls the tables
+// it reads and joins them in DuckDB when it is federated
+func (s *NL2SQLService) executeQuerySQL(userID uint, query *models.NL2SQLQuery, dataSource *models.DataSource, sql string, limit int) (*QueryResult, *models.Federation, error) {
+	if !query.Federated {
+		result, err := s.executeQueryOnDataSource(dataSource, sql, limit)
+		return result, nil, err
+	}
+	federation, err := queryFederation(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := s.executeFederatedQuery(userID, federation, sql, limit)
+	return result, federation, err
+}
+
+// executeFederatedQuery pulls the tables the SQL reads from their data
+// sources, at most the row limit of each source per table, stages them as
+// JSON lines and joins them in DuckDB. Each pull is checked against the cost
+// ceiling of its data source. The rows pulled are recorded on the federation.
+func (s *NL2SQLService) executeFederatedQuery(userID uint, federation *models.Federation, sql string, limit int) (*QueryResult, error) {
+	schemas, err := s.federatedSchemas(federation)
+	if err != nil {
+		return nil, err
+	}
+	sources, violations := resolveFederatedTables(federation.Sources, sql, federatedTableNames(schemas))
+	if len(violations) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrFederationInvalid, strings.Join(violations, "; "))
+	}
+	federation.Sources = sources
+
+	if err := os.MkdirAll(s.federationDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create federation directory: %w", err)
+	}
+	stageDir, err := os.MkdirTemp(s.federationDir, "query-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage federated tables: %w", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	var views []federatedView
+	federation.Pulls = nil
+	for _, source := range federation.Sources {
+		if len(source.Tables) == 0 {
+			continue
+		}
+		// Access is checked again: a source may have been removed or deactivated since
+		dataSource, err := s.validateDataSourceAccess(userID, source.DataSourceID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: data source %q: %v", ErrFederationInvalid, source.Name, err)
+		}
+		dialect := models.DialectForDataSourceType(dataSource.Type)
+		for _, table := range source.Tables {
+			// One row past the limit tells whether the table was cut off
+			pullSQL := federatedPullSQL(table, dialect, source.RowLimit+1)
+			if _, err := s.checkQueryCost(userID, dataSource, pullSQL); err != nil {
+				return nil, fmt.Errorf("failed to pull %s.%s: %w", source.Alias, table, err)
+			}
+			pulled, err := s.executeQueryOnDataSource(dataSource, pullSQL, source.RowLimit+1)
+			if err != nil {
+				return nil, fmt.Errorf("failed to pull %s.%s: %w", source.Alias, table, err)
+			}
+			rows := pulled.Data
+			truncated := len(rows) > source.RowLimit
+			if truncated {
+				rows = rows[:source.RowLimit]
+			}
+
+			path := filepath.Join(stageDir, fmt.Sprintf("%s__%s.jsonl", source.Alias, federationName(table)))
+			if err := writeJSONLines(path, rows); err != nil {
+				return nil, fmt.Errorf("failed to stage %s.%s: %w", source.Alias, table, err)
+			}
+			views = append(views, federatedView{Schema: source.Alias, Table: table, Path: path, Columns: pulled.Columns})
+			federation.Pulls = append(federation.Pulls, models.FederatedTablePull{
+				DataSourceID: source.DataSourceID,
+				Table:        source.Alias + "." + table,
+				Rows:         int64(len(rows)),
+				Truncated:    truncated,
+			})
+		}
+	}
+
+	return s.executeDuckDBQuery(views, sql, limit)
+}
+
+// executeDuckDBQuery runs a federated query on the staged tables
+func (s *NL2SQLService) executeDuckDBQuery(views []federatedView, sql string, limit int) (*QueryResult, error) {
+	// Mock implementation - in real scenario, DuckDB creates a schema per source
+	// alias with a view over read_json_auto of each staged file and runs the query
+	return &QueryResult{
+		Columns: []models.Column{
+			{Name: "name", Type: "string"},
+			{Name: "value", Type: "decimal"},
+		},
+		Data: []map[string]interface{}{
+			{"name": "Product A", "value": 100.0},
+			{"name": "Product B", "value": 200.0},
+		},
+	}, nil
+}
+
+// queryPIIColumns returns the result columns of a query to mask, by the PII
+// settings of its data source or, when federated, of any of its sources
+func (s *NL2SQLService) queryPIIColumns(query *models.NL2SQLQuery, columns []models.Column, rows []map[string]interface{}) []string {
+	if !query.Federated {
+		return s.piiMasker.PIIColumns(query.DataSourceID, columns, rows)
+	}
+	federation, err := queryFederation(query)
+	if err != nil {
+		return s.piiMasker.PIIColumns(query.DataSourceID, columns, rows)
+	}
+
+	seen := make(map[string]bool)
+	var piiColumns []string
+	for _, source := range federation.Sources {
+		for _, column := range s.piiMasker.PIIColumns(source.DataSourceID, columns, rows) {
+			if !seen[column] {
+				seen[column] = true
+				piiColumns = append(piiColumns, column)
+			}
+		}
+	}
+	sort.Strings(piiColumns)
+	return piiColumns
+}
+
+// maskQueryRows masks the PII columns of rows of a query
+func (s *NL2SQLService) maskQueryRows(query *models.NL2SQLQuery, columns []models.Column, rows []map[string]interface{}) ([]map[string]interface{}, []string) {
+	piiColumns := s.queryPIIColumns(query, columns, rows)
+	if len(piiColumns) == 0 {
+		return rows, nil
+	}
+	return s.piiMasker.maskColumns(rows, piiColumns), piiColumns
+}
+
+// federatedSchemas returns the active tables of each source of a federation
+func (s *NL2SQLService) federatedSchemas(federation *models.Federation) (map[uint][]models.Schema, error) {
+	ids := make([]uint, 0, len(federation.Sources))
+	for _, source := range federation.Sources {
+		ids = append(ids, source.DataSourceID)
+	}
+	var schemas []models.Schema
+	if err := s.db.Where("data_source_id IN ? AND is_active = ?", ids, true).Order("name").Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schemas: %w", err)
+	}
+	bySource := make(map[uint][]models.Schema)
+	for _, schema := range schemas {
+		bySource[schema.DataSourceID] = append(bySource[schema.DataSourceID], schema)
+	}
+	return bySource, nil
+}
+
+// queryDialect is the dialect the SQL of a query is written in
+func queryDialect(query *models.NL2SQLQuery, dataSource *models.DataSource) models.SQLDialect {
+	if query.Federated {
+		return models.SQLDialectDuckDB
+	}
+	return models.DialectForDataSourceType(dataSource.Type)
+}
+
+// queryFederation decodes the federation of a federated query
+func queryFederation(query *models.NL2SQLQuery) (*models.Federation, error) {
+	var federation models.Federation
+	if err := json.Unmarshal(query.Federation, &federation); err != nil || len(federation.Sources) == 0 {
+		return nil, fmt.Errorf("%w: query %d has no federated data sources", ErrFederationInvalid, query.ID)
+	}
+	return &federation, nil
+}
+
+func marshalFederation(federation *models.Federation) models.JSON {
+	if federation == nil {
+		return nil
+	}
+	data, _ := json.Marshal(models.Federation{Engine: federation.Engine, Sources: federation.Sources})
+	return models.JSON(data)
+}
+
+// federatedTableNames maps the lower-case table names of each source to their names
+func federatedTableNames(schemas map[uint][]models.Schema) map[uint]map[string]string {
+	names := make(map[uint]map[string]string, len(schemas))
+	for id, tables := range schemas {
+		names[id] = make(map[string]string, len(tables))
+		for _, schema := range tables {
+			names[id][strings.ToLower(schema.Name)] = schema.Name
+		}
+	}
+	return names
+}
+
+// resolveFederatedTables returns the sources with the tables the SQL reads
+// from each, and a violation for every alias-qualified table that is not a
+// table of its source. Unqualified names are CTEs or subquery aliases.
+func resolveFederatedTables(sources []models.FederatedSource, sql string, tables map[uint]map[string]string) ([]models.FederatedSource, []string) {
+	tokens, err := tokenizeSQL(sql, models.SQLDialectDuckDB)
+	if err != nil {
+		return sources, []string{fmt.Sprintf("federated SQL could not be parsed: %v", err)}
+	}
+
+	resolved := make([]models.FederatedSource, len(sources))
+	byAlias := make(map[string]int, len(sources))
+	for i, source := range sources {
+		resolved[i] = source
+		resolved[i].Tables = []string{}
+		byAlias[strings.ToLower(source.Alias)] = i
+	}
+
+	var violations []string
+	read := 0
+	seen := make(map[string]bool)
+	for _, parts := range tableReferences(significantTokens(tokens)) {
+		if len(parts) < 2 {
+			continue
+		}
+		reference := strings.Join(parts, ".")
+		i, ok := byAlias[strings.ToLower(parts[0])]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("table %s does not belong to a federated data source; qualify tables with the alias of their source", reference))
+			continue
+		}
+		name, ok := tables[resolved[i].DataSourceID][strings.ToLower(strings.Join(parts[1:], "."))]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("data source %q has no table %s", resolved[i].Name, strings.Join(parts[1:], ".")))
+			continue
+		}
+		read++
+		if key := resolved[i].Alias + "." + name; !seen[key] {
+			seen[key] = true
+			resolved[i].Tables = append(resolved[i].Tables, name)
+		}
+	}
+	if read == 0 && len(violations) == 0 {
+		violations = append(violations, "federated SQL reads no table of its data sources; qualify tables with the alias of their source")
+	}
+	return resolved, violations
+}
+
+// federatedPullSQL selects up to limit rows of a table in the dialect of its data source
+func federatedPullSQL(table string, dialect models.SQLDialect, limit int) string {
+	name := quoteIdentifier(table, dialect)
+	if dialect != models.SQLDialectBigQuery {
+		// Schema-qualified names are quoted part by part
+		parts := strings.Split(table, ".")
+		for i, part := range parts {
+			parts[i] = quoteIdentifier(part, dialect)
+		}
+		name = strings.Join(parts, ".")
+	}
+	return fmt.Sprintf("SELECT * FROM %s LIMIT %d", name, limit)
+}
+
+// federationAlias names a source in federated SQL after its data source,
+// falling back to its ID when the name is taken or has no usable characters
+func federationAlias(dataSource *models.DataSource, taken map[string]bool) string {
+	alias := federationName(dataSource.Name)
+	if alias == "" || alias[0] >= '0' && alias[0] <= '9' || nonFunctionKeywords[strings.ToUpper(alias)] || taken[alias] {
+		alias = fmt.Sprintf("source_%d", dataSource.ID)
+	}
+	taken[alias] = true
+	return alias
+}
+
+// federationName turns a name into a lower-case identifier of letters, digits and underscores
+func federationName(name string) string {
+	return strings.Trim(materializedTableNamePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederationAlias(t *testing.T) {
+	taken := map[string]bool{}
+	assert.Equal(t, "sales_db", federationAlias(&models.DataSource{ID: 1, Name: "Sales DB"}, taken))
+	assert.Equal(t, "source_2", federationAlias(&models.DataSource{ID: 2, Name: "sales-db"}, taken), "taken aliases fall back to the ID")
+	assert.Equal(t, "source_3", federationAlias(&models.DataSource{ID: 3, Name: "2024 targets"}, taken))
+	assert.Equal(t, "source_4", federationAlias(&models.DataSource{ID: 4, Name: "Select"}, taken), "keywords are not aliases")
+}
+
+func TestResolveFederatedTables(t *testing.T) {
+	sources := []models.FederatedSource{
+		{DataSourceID: 1, Name: "Warehouse", Alias: "warehouse", RowLimit: 100},
+		{DataSourceID: 2, Name: "Targets", Alias: "targets", RowLimit: 100},
+	}
+	tables := map[uint]map[string]string{
+		1: {"orders": "orders", "public.customers": "public.customers"},
+		2: {"regional_targets": "Regional_Targets"},
+	}
+
+	sql := `WITH totals AS (SELECT region, SUM(amount) AS revenue FROM warehouse.orders o JOIN warehouse.public.customers c ON c.id = o.customer_id GROUP BY region)
+SELECT t.region, t.revenue, g.target FROM totals t JOIN "targets"."regional_targets" g ON g.region = t.region LIMIT 100`
+	resolved, violations := resolveFederatedTables(sources, sql, tables)
+	assert.Empty(t, violations)
+	require.Len(t, resolved, 2)
+	assert.Equal(t, []string{"orders", "public.customers"}, resolved[0].Tables)
+	assert.Equal(t, []string{"Regional_Targets"}, resolved[1].Tables)
+	assert.Empty(t, sources[0].Tables, "the sources passed in are left alone")
+
+	_, violations = resolveFederatedTables(sources, "SELECT * FROM crm.contacts JOIN warehouse.refunds ON true", tables)
+	assert.Len(t, violations, 2)
+	assert.Contains(t, violations[0], "crm.contacts")
+	assert.Contains(t, violations[1], "no table refunds")
+
+	_, violations = resolveFederatedTables(sources, "SELECT 1 FROM orders", tables)
+	assert.Len(t, violations, 1, "tables must be qualified by their source")
+}
+
+func TestFederatedPullSQL(t *testing.T) {
+	assert.Equal(t, `SELECT * FROM "public"."orders" LIMIT 101`, federatedPullSQL("public.orders", models.SQLDialectPostgreSQL, 101))
+	assert.Equal(t, "SELECT * FROM `sales.orders` LIMIT 5", federatedPullSQL("sales.orders", models.SQLDialectBigQuery, 5))
+	assert.Equal(t, `SELECT * FROM "Q3 Sales" LIMIT 5`, federatedPullSQL("Q3 Sales", models.SQLDialectDuckDB, 5))
+}
+
+func TestQueryFederationRoundTrip(t *testing.T) {
+	federation := &models.Federation{
+		Engine:  models.FederationEngineDuckDB,
+		Sources: []models.FederatedSource{{DataSourceID: 1, Alias: "warehouse", Tables: []string{"orders"}, RowLimit: 10}},
+		Pulls:   []models.FederatedTablePull{{DataSourceID: 1, Table: "warehouse.orders", Rows: 10}},
+	}
+	query := &models.NL2SQLQuery{Federated: true, Federation: marshalFederation(federation)}
+
+	decoded, err := queryFederation(query)
+	require.NoError(t, err)
+	assert.Equal(t, federation.Sources, decoded.Sources)
+	assert.Empty(t, decoded.Pulls, "pulls belong to a run, not the query")
+	assert.Equal(t, models.SQLDialectDuckDB, queryDialect(query, &models.DataSource{Type: models.DataSourceTypePostgreSQL}))
+
+	_, err = queryFederation(&models.NL2SQLQuery{Federated: true})
+	assert.ErrorIs(t, err, ErrFederationInvalid)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	models "narapulse-be/internal/models/entity"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// ErrInvalidFileImportOptions is returned when upload options cannot be applied
+var ErrInvalidFileImportOptions = errors.New("invalid file import options")
+
+// encodingSniffSize is how much of a CSV file is checked to detect its encoding
+const encodingSniffSize = 64 * 1024
+
+// fileImportSettings are FileImportOptions resolved to what the readers use
+type fileImportSettings struct {
+	delimiter rune
+	encoding  encoding.Encoding // nil detects UTF-8 or Windows-1252
+	charset   string            // Name of the encoding as given
+	hasHeader bool
+	sheets    []string
+}
+
+// resolveFileImportOptions checks the options and applies their defaults
+func resolveFileImportOptions(options models.FileImportOptions) (*fileImportSettings, error) {
+	settings := &fileImportSettings{
+		delimiter: ',',
+		hasHeader: options.HasHeader == nil || *options.HasHeader,
+		sheets:    options.Sheets,
+	}
+
+	switch delimiter := options.Delimiter; {
+	case delimiter == "":
+	case delimiter == "tab" || delimiter == `\t`:
+		settings.delimiter = '\t'
+	case utf8.RuneCountInString(delimiter) == 1:
+		r, _ := utf8.DecodeRuneInString(delimiter)
+		if r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return nil, fmt.Errorf("%w: %q cannot be a delimiter", ErrInvalidFileImportOptions, delimiter)
+		}
+		settings.delimiter = r
+	default:
+		return nil, fmt.Errorf("%w: the delimiter must be a single character", ErrInvalidFileImportOptions)
+	}
+
+	if name := strings.TrimSpace(options.Encoding); name != "" {
+		enc, err := htmlindex.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unsupported encoding %q", ErrInvalidFileImportOptions, name)
+		}
+		if enc == unicode.UTF8 {
+			enc = unicode.UTF8BOM
+		}
+		settings.encoding = enc
+		settings.charset = strings.ToLower(name)
+	}
+	return settings, nil
+}
+
+// decodeFileReader converts a file in the given encoding to UTF-8. Without an
+// encoding, the start of the file decides: valid UTF-8 is read as is, minus a
+// byte order mark, and anything else as Windows-1252, the usual encoding of
+// CSV exports from Excel.
+func decodeFileReader(r io.Reader, enc encoding.Encoding) io.Reader {
+	if enc == nil {
+		buffered := bufio.NewReaderSize(r, encodingSniffSize)
+		head, _ := buffered.Peek(encodingSniffSize)
+		enc = unicode.UTF8BOM
+		if !validUTF8Prefix(head, len(head) == encodingSniffSize) {
+			enc = charmap.Windows1252
+		}
+		r = buffered
+	}
+	return transform.NewReader(r, enc.NewDecoder())
+}
+
+// validUTF8Prefix reports whether b is valid UTF-8. When b is a truncated
+// prefix of the file, a rune cut off at its end is allowed.
+func validUTF8Prefix(b []byte, truncated bool) bool {
+	if utf8.Valid(b) {
+		return true
+	}
+	for i := 1; truncated && i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.Valid(b[:len(b)-i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileColumnNames returns the column names of a file: the header row, with
+// blank and repeated names made unique, or column_1, column_2, ... without one
+func fileColumnNames(header []string, hasHeader bool) []string {
+	names := make([]string, len(header))
+	seen := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !hasHeader || name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		if n := seen[name]; n > 0 {
+			seen[name] = n + 1
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		} else {
+			seen[name] = 1
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// fileSampleData converts the first sample rows of a file to records keyed by column name
+func fileSampleData(names []string, rows [][]string) []map[string]interface{} {
+	if len(rows) > sampleRowLimit {
+		rows = rows[:sampleRowLimit]
+	}
+	data := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		record := make(map[string]interface{}, len(names))
+		for j, name := range names {
+			if j < len(row) {
+				record[name] = row[j]
+			} else {
+				record[name] = nil
+			}
+		}
+		data[i] = record
+	}
+	return data
+}
+
+// fileUploadTables converts inferred schemas to the tables of an upload response
+func fileUploadTables(tables []SchemaInfo) []models.FileUploadTable {
+	result := make([]models.FileUploadTable, len(tables))
+	for i, table := range tables {
+		result[i] = models.FileUploadTable{
+			Name:       table.Name,
+			Columns:    table.Columns,
+			RowCount:   table.RowCount,
+			SampleData: table.SampleData,
+		}
+	}
+	return result
+}
+
+// fileImportOptionsFromConfig reads the import options stored in the config
+// of a data source backed by a file, such as a Google Drive file
+func fileImportOptionsFromConfig(config map[string]interface{}) models.FileImportOptions {
+	var options models.FileImportOptions
+	options.Delimiter, _ = config["delimiter"].(string)
+	options.Encoding, _ = config["encoding"].(string)
+	if hasHeader, ok := config["has_header"].(bool); ok {
+		options.HasHeader = &hasHeader
+	}
+	if sheets, ok := config["sheets"].([]interface{}); ok {
+		for _, sheet := range sheets {
+			if name, ok := sheet.(string); ok && name != "" {
+				options.Sheets = append(options.Sheets, name)
+			}
+		}
+	}
+	return options
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestResolveFileImportOptions(t *testing.T) {
+	settings, err := resolveFileImportOptions(models.FileImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ',', settings.delimiter)
+	assert.True(t, settings.hasHeader)
+	assert.Nil(t, settings.encoding)
+
+	noHeader := false
+	settings, err = resolveFileImportOptions(models.FileImportOptions{Delimiter: "tab", Encoding: "Windows-1252", HasHeader: &noHeader})
+	require.NoError(t, err)
+	assert.Equal(t, '\t', settings.delimiter)
+	assert.Equal(t, charmap.Windows1252, settings.encoding)
+	assert.Equal(t, "windows-1252", settings.charset)
+	assert.False(t, settings.hasHeader)
+
+	for _, options := range []models.FileImportOptions{{Delimiter: ";;"}, {Delimiter: `"`}, {Encoding: "klingon"}} {
+		_, err := resolveFileImportOptions(options)
+		assert.ErrorIs(t, err, ErrInvalidFileImportOptions)
+	}
+}
+
+func TestDecodeFileReader(t *testing.T) {
+	latin, err := charmap.Windows1252.NewEncoder().String("kota;harga\nBogotá;€5\n")
+	require.NoError(t, err)
+
+	detected, err := io.ReadAll(decodeFileReader(strings.NewReader(latin), nil))
+	require.NoError(t, err)
+	assert.Equal(t, "kota;harga\nBogotá;€5\n", string(detected))
+
+	bom, err := io.ReadAll(decodeFileReader(strings.NewReader("\ufeffnama\nAndi\n"), nil))
+	require.NoError(t, err)
+	assert.Equal(t, "nama\nAndi\n", string(bom))
+
+	// A multi-byte rune cut off by the sniffed prefix is still UTF-8
+	assert.True(t, validUTF8Prefix([]byte("caf\xc3"), true))
+	assert.False(t, validUTF8Prefix([]byte("caf\xc3"), false))
+}
+
+func TestFileColumnNames(t *testing.T) {
+	assert.Equal(t, []string{"id", "column_2", "name", "name_2"}, fileColumnNames([]string{" id", "", "name", "name"}, true))
+	assert.Equal(t, []string{"column_1", "column_2"}, fileColumnNames([]string{"1", "Andi"}, false))
+}
+
+func TestProcessFileUploadOptions(t *testing.T) {
+	service := NewConnectorService()
+	latin, err := charmap.Windows1252.NewEncoder().String("1;Bogotá;10.5\n2;Jakarta;7.25\n")
+	require.NoError(t, err)
+
+	noHeader := false
+	dataSource, tables, err := service.ProcessFileUpload(createTestFileHeader("cities.csv", latin),
+		models.FileImportOptions{Delimiter: ";", HasHeader: &noHeader})
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, "cities", tables[0].Name)
+	assert.Equal(t, int64(2), tables[0].RowCount)
+	assert.Equal(t, []string{"integer", "text", "decimal"}, []string{tables[0].Columns[0].Type, tables[0].Columns[1].Type, tables[0].Columns[2].Type})
+	assert.Equal(t, "Bogotá", tables[0].SampleData[0]["column_2"])
+	assert.Contains(t, string(dataSource.Config), `"delimiter":";"`)
+}
+
+func TestProcessFileUploadExcelSheets(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"region", "revenue"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{"north", 120})
+	f.NewSheet("Targets")
+	f.SetSheetRow("Targets", "A1", &[]interface{}{"region", "target"})
+	f.SetSheetRow("Targets", "A2", &[]interface{}{"north", 150})
+	f.NewSheet("Empty")
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+	service := NewConnectorService()
+
+	_, tables, err := service.ProcessFileUpload(createTestFileHeader("sales.xlsx", buf.String()), models.FileImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	assert.Equal(t, "Sheet1", tables[0].Name)
+	assert.Equal(t, "Targets", tables[1].Name)
+	assert.Equal(t, "target", tables[1].Columns[1].Name)
+
+	dataSource, tables, err := service.ProcessFileUpload(createTestFileHeader("sales.xlsx", buf.String()), models.FileImportOptions{Sheets: []string{"Targets"}})
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, "Targets", tables[0].Name)
+	assert.Contains(t, string(dataSource.Config), `"sheets":["Targets"]`)
+
+	_, _, err = service.ProcessFileUpload(createTestFileHeader("sales.xlsx", buf.String()), models.FileImportOptions{Sheets: []string{"Missing"}})
+	assert.ErrorIs(t, err, ErrInvalidFileImportOptions)
+}
+
+func TestProcessFileUploadExcelStreamsRows(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"id", "amount"})
+	for i := 1; i <= excelInferenceRowLimit+500; i++ {
+		f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", i+1), &[]interface{}{i, float64(i) + 0.5})
+	}
+	// A blank row inside the data is counted, like GetRows does
+	f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", excelInferenceRowLimit+503), &[]interface{}{9999, 1.5})
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	_, tables, err := NewConnectorService().ProcessFileUpload(createTestFileHeader("big.xlsx", buf.String()), models.FileImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, int64(excelInferenceRowLimit+502), tables[0].RowCount)
+	assert.Equal(t, "integer", tables[0].Columns[0].Type)
+	assert.Equal(t, "decimal", tables[0].Columns[1].Type)
+	assert.Len(t, tables[0].SampleData, sampleRowLimit)
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/objectstore"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrFileUploadNotFound is returned when an upload does not exist or belongs to another user
+	ErrFileUploadNotFound = errors.New("file upload not found")
+	// ErrFileUploadState is returned when an upload no longer accepts the operation, e.g. parts after completion
+	ErrFileUploadState = errors.New("file upload is not in progress")
+	// ErrInvalidFileUpload is returned for unsupported files and parts of the wrong number or size
+	ErrInvalidFileUpload = errors.New("invalid file upload")
+	// ErrFileUploadIncomplete is returned when completing an upload with missing parts
+	ErrFileUploadIncomplete = errors.New("file upload is missing parts")
+)
+
+const (
+	// defaultUploadChunkSize is the part size unless set
+	defaultUploadChunkSize = 8 * 1024 * 1024
+	// fileUploadExpiry is how long an upload can take before its parts are deleted
+	fileUploadExpiry = 24 * time.Hour
+	// FileUploadPurgeInterval is how often expired uploads are deleted
+	FileUploadPurgeInterval = time.Hour
+)
+
+// FileUploadService receives CSV and Excel files too large for a single
+// request in parts. Parts can be sent in any order and resent, so an
+// interrupted upload resumes with its missing parts. Completing an upload
+// queues a job that assembles the parts into one file and infers its tables,
+// streaming the file rather than loading it into memory.
+type FileUploadService struct {
+	db        *gorm.DB
+	connector *connectorService
+	store     *objectstore.FileStore // A local file store, as file data sources are read by path
+	jobs      *JobService
+	maxSize   int64
+	now       func() time.Time
+}
+
+// NewFileUploadService creates a new file upload service accepting files up to maxSize bytes
+func NewFileUploadService(db *gorm.DB, connector *connectorService, store *objectstore.FileStore, jobs *JobService, maxSize int64) *FileUploadService {
+	return &FileUploadService{
+		db:        db,
+		connector: connector,
+		store:     store,
+		jobs:      jobs,
+		maxSize:   maxSize,
+		now:       time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *FileUploadService) WithContext(ctx context.Context) *FileUploadService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the jobs that process completed uploads and purge expired ones
+func (s *FileUploadService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeFileUploadProcess, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.FileUploadJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid file upload job payload: %w", err)
+		}
+		return s.WithContext(ctx).process(ctx, p.UploadID)
+	})
+	jobs.Register(models.JobTypeFileUploadPurge, func(ctx context.Context, _ json.RawMessage) error {
+		return s.PurgeExpired(ctx)
+	})
+}
+
+// Initiate starts an upload of a file of the declared size
+func (s *FileUploadService) Initiate(userID uint, req *models.FileUploadInitiateRequest) (*models.FileUploadSessionResponse, error) {
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	if ext != ".csv" && ext != ".xlsx" && ext != ".xls" {
+		return nil, fmt.Errorf("%w: only CSV and Excel files are allowed", ErrInvalidFileUpload)
+	}
+	if s.maxSize > 0 && req.FileSize > s.maxSize {
+		return nil, fmt.Errorf("%w: file is larger than %d bytes", ErrInvalidFileUpload, s.maxSize)
+	}
+	if _, err := resolveFileImportOptions(req.Options); err != nil {
+		return nil, err
+	}
+	options, err := json.Marshal(req.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode import options: %w", err)
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+	upload := &models.FileUpload{
+		UserID:     userID,
+		FileName:   filepath.Base(req.FileName),
+		FileSize:   req.FileSize,
+		MimeType:   req.MimeType,
+		ChunkSize:  chunkSize,
+		TotalParts: int((req.FileSize + chunkSize - 1) / chunkSize),
+		Status:     models.FileUploadStatusUploading,
+		Options:    models.JSON(options),
+		ExpiresAt:  s.now().Add(fileUploadExpiry),
+	}
+	if err := s.db.Create(upload).Error; err != nil {
+		return nil, fmt.Errorf("failed to create file upload: %w", err)
+	}
+	return s.response(upload, nil, nil)
+}
+
+// Get returns an upload with its received and missing parts
+func (s *FileUploadService) Get(userID, id uint) (*models.FileUploadSessionResponse, error) {
+	upload, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := s.parts(upload.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.response(upload, parts, nil)
+}
+
+// UploadPart stores part number of an upload. Every part but the last must be
+// exactly the chunk size; resending a part replaces it.
+func (s *FileUploadService) UploadPart(ctx context.Context, userID, id uint, number int, r io.Reader) (*models.FileUploadPart, error) {
+	upload, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != models.FileUploadStatusUploading || !s.now().Before(upload.ExpiresAt) {
+		return nil, ErrFileUploadState
+	}
+	expected, err := expectedPartSize(upload, number)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one byte past the expected size to detect oversized parts
+	hash := sha256.New()
+	counter := &countingReader{r: io.TeeReader(io.LimitReader(r, expected+1), hash)}
+	if err := s.store.Put(ctx, fileUploadPartKey(upload.ID, number), counter); err != nil {
+		return nil, fmt.Errorf("failed to store part: %w", err)
+	}
+	if counter.n != expected {
+		s.store.Delete(ctx, fileUploadPartKey(upload.ID, number))
+		return nil, fmt.Errorf("%w: part %d must be %d bytes, got %d", ErrInvalidFileUpload, number, expected, counter.n)
+	}
+
+	part := &models.FileUploadPart{
+		TenantID:   upload.TenantID,
+		UploadID:   upload.ID,
+		PartNumber: number,
+		Size:       counter.n,
+		Checksum:   hex.EncodeToString(hash.Sum(nil)),
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "upload_id"}, {Name: "part_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "checksum", "created_at"}),
+	}).Create(part).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record part: %w", err)
+	}
+	return part, nil
+}
+
+// Complete checks that every part was received and queues the job that
+// assembles the file and infers its tables
+func (s *FileUploadService) Complete(ctx context.Context, userID, id uint) (*models.FileUploadSessionResponse, error) {
+	upload, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != models.FileUploadStatusUploading {
+		return nil, ErrFileUploadState
+	}
+	parts, err := s.parts(upload.ID)
+	if err != nil {
+		return nil, err
+	}
+	if missing := missingParts(upload.TotalParts, parts); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrFileUploadIncomplete, missing)
+	}
+
+	// Only one request moves the upload on, even when completion is retried concurrently
+	result := s.db.Model(&models.FileUpload{}).
+		Where("id = ? AND status = ?", upload.ID, models.FileUploadStatusUploading).
+		Update("status", models.FileUploadStatusProcessing)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to complete file upload: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrFileUploadState
+	}
+	upload.Status = models.FileUploadStatusProcessing
+
+	job, err := s.jobs.EnqueueUnique(ctx, models.JobTypeFileUploadProcess, fmt.Sprintf("file_upload:%d", upload.ID),
+		models.FileUploadJobPayload{UploadID: upload.ID})
+	if err != nil {
+		return nil, err
+	}
+	return s.response(upload, parts, &job.ID)
+}
+
+// Abort deletes an upload with its parts and assembled file
+func (s *FileUploadService) Abort(ctx context.Context, userID, id uint) error {
+	upload, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	if upload.Status == models.FileUploadStatusProcessing {
+		return ErrFileUploadState
+	}
+	return s.delete(ctx, upload)
+}
+
+// PurgeExpired deletes the uploads that were not completed before they expired
+func (s *FileUploadService) PurgeExpired(ctx context.Context) error {
+	var uploads []models.FileUpload
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", models.FileUploadStatusUploading, s.now()).
+		Find(&uploads).Error
+	if err != nil {
+		return fmt.Errorf("failed to list expired file uploads: %w", err)
+	}
+	for i := range uploads {
+		if err := s.delete(ctx, &uploads[i]); err != nil {
+			return err
+		}
+	}
+	if len(uploads) > 0 {
+		logger.L().Info().Int("uploads", len(uploads)).Msg("Purged expired file uploads")
+	}
+	return nil
+}
+
+// process assembles the parts of a completed upload into one file and infers
+// its tables. Storage errors fail the job so it is retried; a file that cannot
+// be read fails the upload.
+func (s *FileUploadService) process(ctx context.Context, id uint) error {
+	var upload models.FileUpload
+	if err := s.db.WithContext(ctx).First(&upload, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Aborted
+		}
+		return fmt.Errorf("failed to get file upload: %w", err)
+	}
+	if upload.Status != models.FileUploadStatusProcessing {
+		return nil
+	}
+
+	key, err := s.assemble(ctx, &upload)
+	if err != nil {
+		return err
+	}
+
+	var options models.FileImportOptions
+	if len(upload.Options) > 0 {
+		if err := json.Unmarshal(upload.Options, &options); err != nil {
+			return fmt.Errorf("invalid import options: %w", err)
+		}
+	}
+	tables, inferErr := s.inspect(ctx, key, &upload, options)
+
+	updates := map[string]interface{}{"object_key": key, "completed_at": s.now()}
+	if inferErr != nil {
+		updates["status"] = models.FileUploadStatusFailed
+		updates["error"] = inferErr.Error()
+	} else {
+		tablesJSON, err := json.Marshal(tables)
+		if err != nil {
+			return fmt.Errorf("failed to encode inferred tables: %w", err)
+		}
+		updates["status"] = models.FileUploadStatusCompleted
+		updates["tables"] = models.JSON(tablesJSON)
+	}
+	if err := s.db.WithContext(ctx).Model(&upload).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to save file upload: %w", err)
+	}
+	return nil
+}
+
+// assemble concatenates the parts of an upload into its file and deletes the parts
+func (s *FileUploadService) assemble(ctx context.Context, upload *models.FileUpload) (string, error) {
+	key := fileUploadObjectKey(upload)
+	reader := &fileUploadPartsReader{ctx: ctx, store: s.store, uploadID: upload.ID, total: upload.TotalParts}
+	defer reader.Close()
+	if err := s.store.Put(ctx, key, reader); err != nil {
+		return "", fmt.Errorf("failed to assemble file upload: %w", err)
+	}
+	for number := 1; number <= upload.TotalParts; number++ {
+		s.store.Delete(ctx, fileUploadPartKey(upload.ID, number))
+	}
+	return key, nil
+}
+
+// inspect infers the tables of an assembled file, streaming it from the store
+func (s *FileUploadService) inspect(ctx context.Context, key string, upload *models.FileUpload, options models.FileImportOptions) ([]models.FileUploadTable, error) {
+	file, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	_, tables, err := s.connector.ProcessFile(upload.FileName, upload.FileSize, file, options)
+	if err != nil {
+		return nil, err
+	}
+	return fileUploadTables(tables), nil
+}
+
+// delete removes an upload, its parts and its assembled file
+func (s *FileUploadService) delete(ctx context.Context, upload *models.FileUpload) error {
+	for number := 1; number <= upload.TotalParts; number++ {
+		if err := s.store.Delete(ctx, fileUploadPartKey(upload.ID, number)); err != nil {
+			return err
+		}
+	}
+	if upload.ObjectKey != "" {
+		if err := s.store.Delete(ctx, upload.ObjectKey); err != nil {
+			return err
+		}
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.FileUploadPart{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(upload).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file upload: %w", err)
+	}
+	return nil
+}
+
+func (s *FileUploadService) owned(userID, id uint) (*models.FileUpload, error) {
+	var upload models.FileUpload
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&upload).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileUploadNotFound
+		}
+		return nil, fmt.Errorf("failed to get file upload: %w", err)
+	}
+	return &upload, nil
+}
+
+func (s *FileUploadService) parts(uploadID uint) ([]models.FileUploadPart, error) {
+	var parts []models.FileUploadPart
+	if err := s.db.Where("upload_id = ?", uploadID).Order("part_number ASC").Find(&parts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list file upload parts: %w", err)
+	}
+	return parts, nil
+}
+
+// response describes an upload; jobID is set when completing it
+func (s *FileUploadService) response(upload *models.FileUpload, parts []models.FileUploadPart, jobID *uint) (*models.FileUploadSessionResponse, error) {
+	response := &models.FileUploadSessionResponse{
+		FileUpload:    *upload,
+		ReceivedParts: make([]int, 0, len(parts)),
+		MissingParts:  missingParts(upload.TotalParts, parts),
+		JobID:         jobID,
+	}
+	for _, part := range parts {
+		response.ReceivedParts = append(response.ReceivedParts, part.PartNumber)
+		response.UploadedBytes += part.Size
+	}
+	if len(upload.Options) > 0 {
+		if err := json.Unmarshal(upload.Options, &response.Options); err != nil {
+			return nil, fmt.Errorf("invalid import options: %w", err)
+		}
+	}
+	if len(upload.Tables) > 0 {
+		if err := json.Unmarshal(upload.Tables, &response.Tables); err != nil {
+			return nil, fmt.Errorf("invalid inferred tables: %w", err)
+		}
+	}
+	if upload.Status == models.FileUploadStatusCompleted && upload.ObjectKey != "" {
+		path, err := s.store.Path(upload.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		response.FilePath = path
+	}
+	return response, nil
+}
+
+// expectedPartSize returns the size part number of an upload must have
+func expectedPartSize(upload *models.FileUpload, number int) (int64, error) {
+	if number < 1 || number > upload.TotalParts {
+		return 0, fmt.Errorf("%w: part number must be between 1 and %d", ErrInvalidFileUpload, upload.TotalParts)
+	}
+	if number < upload.TotalParts {
+		return upload.ChunkSize, nil
+	}
+	return upload.FileSize - int64(upload.TotalParts-1)*upload.ChunkSize, nil
+}
+
+// missingParts lists the part numbers of an upload that were not received
+func missingParts(total int, parts []models.FileUploadPart) []int {
+	received := make(map[int]bool, len(parts))
+	for _, part := range parts {
+		received[part.PartNumber] = true
+	}
+	missing := make([]int, 0)
+	for number := 1; number <= total; number++ {
+		if !received[number] {
+			missing = append(missing, number)
+		}
+	}
+	return missing
+}
+
+func fileUploadPartKey(uploadID uint, number int) string {
+	return fmt.Sprintf("uploads/%d/parts/%06d", uploadID, number)
+}
+
+func fileUploadObjectKey(upload *models.FileUpload) string {
+	return fmt.Sprintf("uploads/%d/file%s", upload.ID, strings.ToLower(filepath.Ext(upload.FileName)))
+}
+
+// fileUploadPartsReader reads the parts of an upload one after another,
+// opening each part only when the previous one is exhausted
+type fileUploadPartsReader struct {
+	ctx      context.Context
+	store    objectstore.Store
+	uploadID uint
+	total    int
+	next     int
+	current  io.ReadCloser
+}
+
+func (r *fileUploadPartsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.next >= r.total {
+				return 0, io.EOF
+			}
+			r.next++
+			part, err := r.store.Get(r.ctx, fileUploadPartKey(r.uploadID, r.next))
+			if err != nil {
+				return 0, fmt.Errorf("failed to open part %d: %w", r.next, err)
+			}
+			r.current = part
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+// Close closes the part being read
+func (r *fileUploadPartsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
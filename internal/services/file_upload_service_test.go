@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/objectstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedPartSize(t *testing.T) {
+	upload := &models.FileUpload{FileSize: 25, ChunkSize: 10, TotalParts: 3}
+
+	size, err := expectedPartSize(upload, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), size)
+	size, err = expectedPartSize(upload, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+
+	for _, number := range []int{0, 4} {
+		_, err := expectedPartSize(upload, number)
+		assert.ErrorIs(t, err, ErrInvalidFileUpload)
+	}
+}
+
+func TestMissingParts(t *testing.T) {
+	parts := []models.FileUploadPart{{PartNumber: 1}, {PartNumber: 3}}
+	assert.Equal(t, []int{2, 4}, missingParts(4, parts))
+	assert.Empty(t, missingParts(1, []models.FileUploadPart{{PartNumber: 1}}))
+}
+
+func TestFileUploadAssembleAndInspect(t *testing.T) {
+	ctx := context.Background()
+	store := objectstore.NewFileStore(t.TempDir())
+	service := NewFileUploadService(nil, NewConnectorService(), store, nil, 0)
+
+	content := "id,city\n1,Jakarta\n2,Bandung\n3,Surabaya\n"
+	upload := &models.FileUpload{ID: 7, FileName: "cities.csv", FileSize: int64(len(content)), ChunkSize: 10}
+	upload.TotalParts = int((upload.FileSize + upload.ChunkSize - 1) / upload.ChunkSize)
+	for number := 1; number <= upload.TotalParts; number++ {
+		end := number * 10
+		if end > len(content) {
+			end = len(content)
+		}
+		require.NoError(t, store.Put(ctx, fileUploadPartKey(upload.ID, number), strings.NewReader(content[(number-1)*10:end])))
+	}
+
+	key, err := service.assemble(ctx, upload)
+	require.NoError(t, err)
+	assert.Equal(t, "uploads/7/file.csv", key)
+
+	file, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assembled, err := io.ReadAll(file)
+	file.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(assembled))
+
+	// The parts are deleted once assembled
+	_, err = store.Get(ctx, fileUploadPartKey(upload.ID, 1))
+	assert.ErrorIs(t, err, objectstore.ErrNotFound)
+
+	tables, err := service.inspect(ctx, key, upload, models.FileImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, "cities", tables[0].Name)
+	assert.Equal(t, int64(3), tables[0].RowCount)
+	assert.Equal(t, "city", tables[0].Columns[1].Name)
+}
+
+func TestFileUploadPartsReaderMissingPart(t *testing.T) {
+	store := objectstore.NewFileStore(t.TempDir())
+	require.NoError(t, store.Put(context.Background(), fileUploadPartKey(1, 1), strings.NewReader("abc")))
+
+	reader := &fileUploadPartsReader{ctx: context.Background(), store: store, uploadID: 1, total: 2}
+	defer reader.Close()
+	_, err := io.ReadAll(reader)
+	assert.ErrorIs(t, err, objectstore.ErrNotFound)
+}
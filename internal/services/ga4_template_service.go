@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ga4SessionKey identifies a GA4 session: the session id is only unique per user
+const ga4SessionKey = "CONCAT(user_pseudo_id, CAST((SELECT value.int_value FROM UNNEST(event_params) WHERE key = 'ga_session_id') AS STRING))"
+
+// ga4KPITemplates are the KPI definitions seeded for a GA4 data source. The
+// formulas are BigQuery expressions over the events_* export tables.
+var ga4KPITemplates = []models.KPIDefinitionRequest{
+	{
+		Name:        "ga4_sessions",
+		DisplayName: "Sessions",
+		Description: "Number of sessions: distinct ga_session_id values per user in the GA4 events_* tables",
+		Formula:     "COUNT(DISTINCT " + ga4SessionKey + ")",
+		Category:    "marketing",
+		Unit:        "count",
+		Grain:       "daily",
+		Tags:        []string{"ga4", "traffic"},
+	},
+	{
+		Name:        "ga4_active_users",
+		DisplayName: "Active Users",
+		Description: "Number of distinct users (user_pseudo_id) with at least one event in the GA4 events_* tables",
+		Formula:     "COUNT(DISTINCT user_pseudo_id)",
+		Category:    "marketing",
+		Unit:        "count",
+		Grain:       "daily",
+		Tags:        []string{"ga4", "traffic"},
+	},
+	{
+		Name:        "ga4_conversions",
+		DisplayName: "Conversions",
+		Description: "Number of conversion (key) events in the GA4 events_* tables; adjust the event names to the key events of the property",
+		Formula:     "COUNTIF(event_name IN ('purchase', 'generate_lead', 'sign_up'))",
+		Category:    "marketing",
+		Unit:        "count",
+		Grain:       "daily",
+		Tags:        []string{"ga4", "conversion"},
+	},
+	{
+		Name:        "ga4_conversion_rate",
+		DisplayName: "Session Conversion Rate",
+		Description: "Share of sessions with at least one conversion event in the GA4 events_* tables",
+		Formula:     "SAFE_DIVIDE(COUNT(DISTINCT IF(event_name IN ('purchase', 'generate_lead', 'sign_up'), " + ga4SessionKey + ", NULL)), COUNT(DISTINCT " + ga4SessionKey + "))",
+		Category:    "marketing",
+		Unit:        "percentage",
+		Grain:       "daily",
+		Tags:        []string{"ga4", "conversion"},
+	},
+	{
+		Name:        "ga4_revenue",
+		DisplayName: "Revenue",
+		Description: "Purchase revenue of the purchase events in the GA4 events_* tables, in the property currency",
+		Formula:     "SUM(IF(event_name = 'purchase', ecommerce.purchase_revenue, 0))",
+		Category:    "revenue",
+		Unit:        "currency",
+		Grain:       "daily",
+		Tags:        []string{"ga4", "ecommerce"},
+	},
+}
+
+// ga4GlossaryTemplates are the glossary terms seeded for a GA4 data source
+var ga4GlossaryTemplates = []models.BusinessGlossaryRequest{
+	{
+		Term:         "Session",
+		Definition:   "A group of user interactions on the site or app, identified by the ga_session_id event parameter together with user_pseudo_id",
+		Synonyms:     []string{"visit"},
+		Category:     "domain-specific",
+		Domain:       "marketing",
+		Examples:     []string{"How many sessions did we have last week?"},
+		RelatedTerms: []string{"Engaged session", "Active user"},
+	},
+	{
+		Term:         "Engaged session",
+		Definition:   "A session that lasted 10 seconds or more, had a conversion or had two or more page views; flagged by the session_engaged event parameter",
+		Category:     "domain-specific",
+		Domain:       "marketing",
+		RelatedTerms: []string{"Session"},
+	},
+	{
+		Term:         "Active user",
+		Definition:   "A distinct user_pseudo_id with at least one event in the period",
+		Synonyms:     []string{"users", "visitors"},
+		Category:     "domain-specific",
+		Domain:       "marketing",
+		RelatedTerms: []string{"Session"},
+	},
+	{
+		Term:         "Conversion",
+		Definition:   "An event marked as a key event in GA4, such as purchase, generate_lead or sign_up",
+		Synonyms:     []string{"key event", "goal completion"},
+		Category:     "domain-specific",
+		Domain:       "marketing",
+		Examples:     []string{"What was the conversion rate by traffic source?"},
+		RelatedTerms: []string{"Revenue"},
+	},
+	{
+		Term:         "Revenue",
+		Definition:   "Purchase revenue from ecommerce.purchase_revenue of purchase events",
+		Synonyms:     []string{"sales", "purchase revenue"},
+		Category:     "domain-specific",
+		Domain:       "marketing",
+		RelatedTerms: []string{"Conversion"},
+	},
+	{
+		Term:       "Traffic source",
+		Definition: "The source, medium and campaign that first acquired the user (traffic_source.source, traffic_source.medium, traffic_source.name)",
+		Synonyms:   []string{"channel", "acquisition source", "utm source"},
+		Category:   "domain-specific",
+		Domain:     "marketing",
+	},
+	{
+		Term:       "Intraday table",
+		Definition: "The events_intraday_* tables hold today's events until the daily events_* table is written; a query on events_* also reads them unless _TABLE_SUFFIX excludes 'intraday_%'",
+		Category:   "technical",
+		Domain:     "marketing",
+	},
+}
+
+// GA4TemplateService seeds the KPI definitions and glossary terms that make
+// NL2SQL answers on a GA4 export useful without manual setup
+type GA4TemplateService struct {
+	db               *gorm.DB
+	embeddingService *EmbeddingService
+}
+
+// NewGA4TemplateService creates a new GA4 template service
+func NewGA4TemplateService(db *gorm.DB, embeddingService *EmbeddingService) *GA4TemplateService {
+	return &GA4TemplateService{
+		db:               db,
+		embeddingService: embeddingService,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *GA4TemplateService) WithContext(ctx context.Context) *GA4TemplateService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.embeddingService = s.embeddingService.WithContext(ctx)
+	return &scoped
+}
+
+// Seed creates the GA4 KPIs and glossary terms the user does not have yet and
+// embeds them. Existing definitions with the same name are left untouched. A
+// failed embedding is logged; the embed-all endpoint picks the item up later.
+func (s *GA4TemplateService) Seed(ctx context.Context, userID uint) (int, int, error) {
+	kpis := 0
+	for _, template := range ga4KPITemplates {
+		var existing int64
+		if err := s.db.Model(&models.KPIDefinition{}).Where("user_id = ? AND name = ?", userID, template.Name).Count(&existing).Error; err != nil {
+			return kpis, 0, fmt.Errorf("failed to check KPI %s: %w", template.Name, err)
+		}
+		if existing > 0 {
+			continue
+		}
+
+		kpi, err := newTemplateKPI(userID, template)
+		if err != nil {
+			return kpis, 0, err
+		}
+		if err := s.db.Create(kpi).Error; err != nil {
+			return kpis, 0, fmt.Errorf("failed to create KPI %s: %w", template.Name, err)
+		}
+		kpis++
+
+		if err := s.embeddingService.EmbedKPIDefinition(ctx, kpi); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Str("kpi", kpi.Name).Msg("Failed to embed GA4 KPI")
+		}
+	}
+
+	terms := 0
+	for _, template := range ga4GlossaryTemplates {
+		var existing int64
+		if err := s.db.Model(&models.BusinessGlossary{}).Where("user_id = ? AND term = ?", userID, template.Term).Count(&existing).Error; err != nil {
+			return kpis, terms, fmt.Errorf("failed to check glossary term %s: %w", template.Term, err)
+		}
+		if existing > 0 {
+			continue
+		}
+
+		term, err := newTemplateGlossaryTerm(userID, template)
+		if err != nil {
+			return kpis, terms, err
+		}
+		if err := s.db.Create(term).Error; err != nil {
+			return kpis, terms, fmt.Errorf("failed to create glossary term %s: %w", template.Term, err)
+		}
+		terms++
+
+		if err := s.embeddingService.EmbedGlossaryTerm(ctx, term); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Str("term", term.Term).Msg("Failed to embed GA4 glossary term")
+		}
+	}
+
+	return kpis, terms, nil
+}
+
+// newTemplateKPI builds the KPI definition of a template for a user
+func newTemplateKPI(userID uint, template models.KPIDefinitionRequest) (*models.KPIDefinition, error) {
+	tags, err := json.Marshal(template.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	return &models.KPIDefinition{
+		UserID:      userID,
+		Name:        template.Name,
+		DisplayName: template.DisplayName,
+		Description: template.Description,
+		Formula:     template.Formula,
+		Category:    template.Category,
+		Unit:        template.Unit,
+		Grain:       template.Grain,
+		Tags:        models.JSON(tags),
+		IsActive:    true,
+	}, nil
+}
+
+// newTemplateGlossaryTerm builds the glossary term of a template for a user
+func newTemplateGlossaryTerm(userID uint, template models.BusinessGlossaryRequest) (*models.BusinessGlossary, error) {
+	term := &models.BusinessGlossary{
+		UserID:     userID,
+		Term:       template.Term,
+		Definition: template.Definition,
+		Category:   template.Category,
+		Domain:     template.Domain,
+		IsActive:   true,
+	}
+
+	for _, field := range []struct {
+		target *models.JSON
+		values []string
+	}{
+		{&term.Synonyms, template.Synonyms},
+		{&term.Examples, template.Examples},
+		{&term.RelatedTerms, template.RelatedTerms},
+	} {
+		if field.values == nil {
+			field.values = []string{}
+		}
+		encoded, err := json.Marshal(field.values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal glossary term %s: %w", template.Term, err)
+		}
+		*field.target = models.JSON(encoded)
+	}
+	return term, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGA4KPITemplates_ValidBigQuery(t *testing.T) {
+	validator := NewSQLValidatorService()
+	names := make(map[string]bool)
+
+	for _, template := range ga4KPITemplates {
+		assert.False(t, names[template.Name], "duplicate KPI %s", template.Name)
+		names[template.Name] = true
+
+		sql := "SELECT event_date, " + template.Formula + " AS value FROM `analytics_123.events_*` GROUP BY event_date"
+		result, err := validator.ValidateSQLForDialect(sql, models.SQLDialectBigQuery)
+		require.NoError(t, err, template.Name)
+		assert.True(t, result.IsValid, "%s: %v", template.Name, result.Violations)
+	}
+
+	for _, name := range []string{"ga4_sessions", "ga4_conversions", "ga4_revenue"} {
+		assert.True(t, names[name], "missing KPI %s", name)
+	}
+}
+
+func TestNewTemplateGlossaryTerm(t *testing.T) {
+	term, err := newTemplateGlossaryTerm(7, models.BusinessGlossaryRequest{
+		Term:       "Session",
+		Definition: "A visit",
+		Synonyms:   []string{"visit"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(7), term.UserID)
+	assert.True(t, term.IsActive)
+
+	var synonyms, examples []string
+	require.NoError(t, json.Unmarshal(term.Synonyms, &synonyms))
+	require.NoError(t, json.Unmarshal(term.Examples, &examples))
+	assert.Equal(t, []string{"visit"}, synonyms)
+	assert.Equal(t, []string{}, examples)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"narapulse-be/internal/connectors"
+	models "narapulse-be/internal/models/entity"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+// ErrGoogleOAuthNotConfigured is returned when GOOGLE_CLIENT_ID or GOOGLE_CLIENT_SECRET is not set
+var ErrGoogleOAuthNotConfigured = errors.New("Google OAuth client is not configured")
+
+// GoogleDriveService lets users pick a CSV or Excel file in Google Drive for a
+// google_drive data source: it grants read-only Drive access through Google
+// OAuth and browses Drive with the granted tokens.
+type GoogleDriveService struct {
+	connector *connectorService
+}
+
+// NewGoogleDriveService creates a new Google Drive service
+func NewGoogleDriveService(connector *connectorService) *GoogleDriveService {
+	return &GoogleDriveService{connector: connector}
+}
+
+// AuthURL returns the Google consent page asking for read-only Drive access.
+// Access is requested offline, so the data source gets a refresh token and
+// can download its file on schedule.
+func (s *GoogleDriveService) AuthURL(redirectURI, state string) (*models.GoogleDriveAuthURLResponse, error) {
+	config := connectors.GoogleDriveOAuthConfig(redirectURI)
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, ErrGoogleOAuthNotConfigured
+	}
+	return &models.GoogleDriveAuthURLResponse{
+		URL:   config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce),
+		Scope: drive.DriveReadonlyScope,
+	}, nil
+}
+
+// ExchangeCode exchanges the code of the consent page for the tokens of the data source config
+func (s *GoogleDriveService) ExchangeCode(ctx context.Context, req *models.GoogleDriveTokenRequest) (*models.GoogleDriveTokenResponse, error) {
+	config := connectors.GoogleDriveOAuthConfig(req.RedirectURI)
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, ErrGoogleOAuthNotConfigured
+	}
+	token, err := config.Exchange(ctx, req.Code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange Google authorization code: %w", err)
+	}
+	return &models.GoogleDriveTokenResponse{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}, nil
+}
+
+// Browse lists a Drive folder, or searches Drive, for files to import
+func (s *GoogleDriveService) Browse(req *models.GoogleDriveBrowseRequest) (*models.GoogleDriveBrowseResponse, error) {
+	return s.connector.ListGoogleDriveFiles(req.Config, req.FolderID, req.Query, req.PageToken, req.PageSize)
+}
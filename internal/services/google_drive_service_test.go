@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleDriveSyncDue(t *testing.T) {
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, googleDriveSyncDue(map[string]interface{}{}, now), "never checked")
+	assert.False(t, googleDriveSyncDue(map[string]interface{}{"checked_at": "2025-10-08T11:30:00Z"}, now))
+	assert.True(t, googleDriveSyncDue(map[string]interface{}{"checked_at": "2025-10-08T11:00:00Z"}, now))
+	assert.True(t, googleDriveSyncDue(map[string]interface{}{
+		"checked_at":               "2025-10-08T11:50:00Z",
+		"refresh_interval_minutes": float64(5),
+	}, now))
+}
+
+func TestFileImportOptionsFromConfig(t *testing.T) {
+	options := fileImportOptionsFromConfig(map[string]interface{}{
+		"delimiter":  ";",
+		"encoding":   "windows-1252",
+		"has_header": false,
+		"sheets":     []interface{}{"Orders", ""},
+	})
+	assert.Equal(t, ";", options.Delimiter)
+	assert.Equal(t, "windows-1252", options.Encoding)
+	require.NotNil(t, options.HasHeader)
+	assert.False(t, *options.HasHeader)
+	assert.Equal(t, []string{"Orders"}, options.Sheets)
+
+	assert.Nil(t, fileImportOptionsFromConfig(map[string]interface{}{}).HasHeader)
+}
+
+func TestGoogleDriveAuthURLRequiresClient(t *testing.T) {
+	t.Setenv("GOOGLE_CLIENT_ID", "")
+	_, err := NewGoogleDriveService(nil).AuthURL("https://app.example.com/callback", "state")
+	assert.ErrorIs(t, err, ErrGoogleOAuthNotConfigured)
+
+	t.Setenv("GOOGLE_CLIENT_ID", "client")
+	t.Setenv("GOOGLE_CLIENT_SECRET", "secret")
+	authURL, err := NewGoogleDriveService(nil).AuthURL("https://app.example.com/callback", "state")
+	require.NoError(t, err)
+	assert.Contains(t, authURL.URL, "access_type=offline")
+	assert.Contains(t, authURL.URL, "drive.readonly")
+	assert.Contains(t, authURL.URL, "state=state")
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	models "narapulse-be/internal/models/entity"
+)
+
+// GoogleSheetsService lets users pick the sheets and range of a google_sheets
+// data source before creating it
+type GoogleSheetsService struct {
+	connector *connectorService
+}
+
+// NewGoogleSheetsService creates a new Google Sheets service
+func NewGoogleSheetsService(connector *connectorService) *GoogleSheetsService {
+	return &GoogleSheetsService{connector: connector}
+}
+
+// ListSheets lists the sheets of the spreadsheet in the config, with the
+// headers the configured range and header row give each
+func (s *GoogleSheetsService) ListSheets(req *models.GoogleSheetsListRequest) (*models.GoogleSheetsListResponse, error) {
+	sheets, err := s.connector.ListGoogleSheets(req.Config)
+	if err != nil {
+		return nil, err
+	}
+	spreadsheetID, _ := req.Config["spreadsheet_id"].(string)
+	return &models.GoogleSheetsListResponse{SpreadsheetID: spreadsheetID, Sheets: sheets}, nil
+}
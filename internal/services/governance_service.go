@@ -0,0 +1,331 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+)
+
+// governanceBatchSize bounds how many events are delivered per dispatch run
+const governanceBatchSize = 100
+
+// governanceCursorID is the single cursor row for the compliance webhook channel
+const governanceCursorID = 1
+
+// governanceLogLockKey is the advisory lock that serializes writes to the event log,
+// so sequence numbers become visible in the order they were assigned
+const governanceLogLockKey = 72840001
+
+// piiColumnHints are column name fragments that usually hold personal data
+var piiColumnHints = []string{
+	"email", "phone", "mobile", "ssn", "nik", "passport", "birth", "dob",
+	"address", "first_name", "last_name", "full_name", "credit_card", "card_number",
+}
+
+// GovernanceService records governance events in an ordered log and delivers
+// them to the compliance webhook. Delivery stops at the first failure so the
+// receiver never sees events out of order; the next dispatch resumes from there.
+type GovernanceService struct {
+	db         *gorm.DB
+	webhookURL string
+	secret     string
+	client     *http.Client
+}
+
+// NewGovernanceService creates a new governance service
+func NewGovernanceService(db *gorm.DB, webhookURL, secret string) *GovernanceService {
+	return &GovernanceService{
+		db:         db,
+		webhookURL: webhookURL,
+		secret:     secret,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *GovernanceService) WithContext(ctx context.Context) *GovernanceService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// governanceWebhookPayload is the body posted to the compliance webhook
+type governanceWebhookPayload struct {
+	Sequence     uint                       `json:"sequence"`
+	Type         models.GovernanceEventType `json:"type"`
+	DataSourceID uint                       `json:"data_source_id,omitempty"`
+	ActorID      uint                       `json:"actor_id,omitempty"`
+	Payload      json.RawMessage            `json:"payload,omitempty"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	Replay       bool                       `json:"replay"`
+}
+
+// Emit appends an event to the governance log and triggers delivery in the
+// background. Events of a data source belong to its tenant, the others to the
+// tenant of the service's context.
+func (s *GovernanceService) Emit(eventType models.GovernanceEventType, dataSourceID, actorID uint, payload map[string]interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	event := &models.GovernanceEvent{
+		Type:         eventType,
+		DataSourceID: dataSourceID,
+		ActorID:      actorID,
+		Payload:      models.JSON(payloadJSON),
+	}
+	if dataSourceID != 0 {
+		if err := s.installation().Unscoped().Model(&models.DataSource{}).Select("tenant_id").
+			Where("id = ?", dataSourceID).Scan(&event.TenantID).Error; err != nil {
+			return fmt.Errorf("failed to get tenant of data source: %w", err)
+		}
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", governanceLogLockKey).Error; err != nil {
+			return err
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record governance event: %w", err)
+	}
+
+	go func() {
+		if _, err := s.Dispatch(); err != nil {
+			logger.L().Error().Err(err).Msg("Governance webhook dispatch failed")
+		}
+	}()
+
+	return nil
+}
+
+// DetectPIIColumns returns the columns whose names look like personal data
+func (s *GovernanceService) DetectPIIColumns(columns []models.Column) []string {
+	var piiColumns []string
+	for _, col := range columns {
+		name := strings.ToLower(col.Name)
+		for _, hint := range piiColumnHints {
+			if strings.Contains(name, hint) {
+				piiColumns = append(piiColumns, col.Name)
+				break
+			}
+		}
+	}
+	return piiColumns
+}
+
+// installation returns the database outside of the tenant of the service's
+// context. The compliance webhook receives the events of every tenant.
+func (s *GovernanceService) installation() *gorm.DB {
+	return s.db.WithContext(tenancy.WithTenant(s.db.Statement.Context, 0))
+}
+
+// Dispatch delivers pending events in sequence order. The cursor row is locked for
+// the duration of the run so only one instance delivers at a time.
+func (s *GovernanceService) Dispatch() (*models.GovernanceDeliveryResult, error) {
+	if s.webhookURL == "" {
+		return &models.GovernanceDeliveryResult{}, nil
+	}
+	db := s.installation()
+
+	// Make sure the cursor row exists before locking it
+	cursor := models.GovernanceWebhookCursor{ID: governanceCursorID}
+	if err := db.FirstOrCreate(&cursor, models.GovernanceWebhookCursor{ID: governanceCursorID}).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webhook cursor: %w", err)
+	}
+
+	result := &models.GovernanceDeliveryResult{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&cursor, governanceCursorID).Error; err != nil {
+			return fmt.Errorf("failed to lock webhook cursor: %w", err)
+		}
+
+		var events []models.GovernanceEvent
+		if err := tx.Where("id > ?", cursor.LastSequence).
+			Order("id ASC").
+			Limit(governanceBatchSize).
+			Find(&events).Error; err != nil {
+			return fmt.Errorf("failed to load pending events: %w", err)
+		}
+
+		var deliveryErr error
+		for _, event := range events {
+			if deliveryErr = s.deliver(event, false); deliveryErr != nil {
+				break
+			}
+			now := time.Now()
+			cursor.LastSequence = event.ID
+			cursor.LastDeliveredAt = &now
+			result.Delivered++
+		}
+
+		cursor.LastError = ""
+		if deliveryErr != nil {
+			cursor.LastError = deliveryErr.Error()
+			result.Error = deliveryErr.Error()
+		}
+		result.LastSequence = cursor.LastSequence
+
+		return tx.Save(&cursor).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Replay re-sends already delivered events in order without moving the cursor.
+// A zero toSequence replays up to the last delivered event.
+func (s *GovernanceService) Replay(fromSequence, toSequence uint) (*models.GovernanceDeliveryResult, error) {
+	if s.webhookURL == "" {
+		return nil, fmt.Errorf("compliance webhook is not configured")
+	}
+	db := s.installation()
+
+	if toSequence == 0 {
+		var cursor models.GovernanceWebhookCursor
+		if err := db.First(&cursor, governanceCursorID).Error; err != nil {
+			return nil, fmt.Errorf("no events have been delivered yet: %w", err)
+		}
+		toSequence = cursor.LastSequence
+	}
+	if fromSequence == 0 {
+		fromSequence = 1
+	}
+	if fromSequence > toSequence {
+		return nil, fmt.Errorf("from_sequence must not be greater than to_sequence")
+	}
+
+	result := &models.GovernanceDeliveryResult{}
+	lastSequence := fromSequence - 1
+	for {
+		var events []models.GovernanceEvent
+		if err := db.Where("id > ? AND id <= ?", lastSequence, toSequence).
+			Order("id ASC").
+			Limit(governanceBatchSize).
+			Find(&events).Error; err != nil {
+			return nil, fmt.Errorf("failed to load events: %w", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		for _, event := range events {
+			if err := s.deliver(event, true); err != nil {
+				result.Error = err.Error()
+				return result, nil
+			}
+			lastSequence = event.ID
+			result.Delivered++
+			result.LastSequence = event.ID
+		}
+	}
+
+	return result, nil
+}
+
+// ListEvents returns events from the log starting after the given sequence
+func (s *GovernanceService) ListEvents(afterSequence uint, eventType string, limit int) ([]models.GovernanceEvent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	query := s.db.Where("id > ?", afterSequence).Order("id ASC").Limit(limit)
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+
+	var events []models.GovernanceEvent
+	if err := query.Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list governance events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetCursor returns the delivery state of the compliance webhook
+func (s *GovernanceService) GetCursor() (*models.GovernanceWebhookCursor, error) {
+	var cursor models.GovernanceWebhookCursor
+	if err := s.db.FirstOrCreate(&cursor, models.GovernanceWebhookCursor{ID: governanceCursorID}).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webhook cursor: %w", err)
+	}
+	return &cursor, nil
+}
+
+// deliver posts a single event to the compliance webhook
+func (s *GovernanceService) deliver(event models.GovernanceEvent, replay bool) error {
+	body, err := json.Marshal(governanceWebhookPayload{
+		Sequence:     event.ID,
+		Type:         event.Type,
+		DataSourceID: event.DataSourceID,
+		ActorID:      event.ActorID,
+		Payload:      json.RawMessage(event.Payload),
+		CreatedAt:    event.CreatedAt,
+		Replay:       replay,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %d: %w", event.ID, err)
+	}
+
+	req, err := http.NewRequest("POST", s.webhookURL, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Narapulse-Event", string(event.Type))
+	req.Header.Set("X-Narapulse-Sequence", strconv.FormatUint(uint64(event.ID), 10))
+	if replay {
+		req.Header.Set("X-Narapulse-Replay", "true")
+	}
+	if s.secret != "" {
+		req.Header.Set("X-Narapulse-Signature", "sha256="+s.sign(body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver event %d: %w", event.ID, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook rejected event %d with status %d", event.ID, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of the body using the webhook secret
+func (s *GovernanceService) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestGovernanceService_DetectPIIColumns(t *testing.T) {
+	service := NewGovernanceService(nil, "", "")
+
+	columns := []models.Column{
+		{Name: "id", Type: "integer"},
+		{Name: "Email", Type: "string"},
+		{Name: "phone_number", Type: "string"},
+		{Name: "total_amount", Type: "decimal"},
+		{Name: "date_of_birth", Type: "date"},
+	}
+
+	assert.Equal(t, []string{"Email", "phone_number", "date_of_birth"}, service.DetectPIIColumns(columns))
+	assert.Empty(t, service.DetectPIIColumns([]models.Column{{Name: "amount"}}))
+}
+
+func TestGovernanceService_Deliver(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewGovernanceService(nil, server.URL, "webhook-secret")
+	event := models.GovernanceEvent{
+		ID:      42,
+		Type:    models.GovernanceEventPolicyChanged,
+		Payload: models.JSON(`{"policy":"mask_email"}`),
+	}
+
+	err := service.deliver(event, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "policy_changed", received.Header.Get("X-Narapulse-Event"))
+	assert.Equal(t, "42", received.Header.Get("X-Narapulse-Sequence"))
+	assert.Equal(t, "true", received.Header.Get("X-Narapulse-Replay"))
+	assert.Equal(t, "sha256="+service.sign(body), received.Header.Get("X-Narapulse-Signature"))
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, float64(42), payload["sequence"])
+	assert.Equal(t, true, payload["replay"])
+}
+
+func TestGovernanceService_DeliverRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	service := NewGovernanceService(nil, server.URL, "")
+	err := service.deliver(models.GovernanceEvent{ID: 7, Type: models.GovernanceEventApprovalGranted}, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status 503")
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/graphql"
+	"narapulse-be/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+type graphQLUserKey struct{}
+
+// WithGraphQLUser runs GraphQL queries made with the context as the user
+func WithGraphQLUser(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, graphQLUserKey{}, userID)
+}
+
+func graphQLUser(ctx context.Context) uint {
+	userID, _ := ctx.Value(graphQLUserKey{}).(uint)
+	return userID
+}
+
+// GraphQLService serves users, data sources, schemas, saved queries, KPI
+// values and dashboards as one GraphQL graph, so dashboards load what they
+// show in one request. Relations are loaded for every parent at once from the
+// repositories. Everything is read as the user of the context, with the same
+// visibility as the REST endpoints.
+type GraphQLService struct {
+	db             *gorm.DB
+	userRepo       repositories.UserRepository
+	dataSourceRepo repositories.DataSourceRepository
+	schemaRepo     repositories.SchemaRepository
+	ragRepo        repositories.RAGRepository
+	savedQueries   *SavedQueryService
+	kpis           *KPIService
+	dashboards     *DashboardService
+	schema         *graphql.Schema
+}
+
+// NewGraphQLService creates a new GraphQL service
+func NewGraphQLService(db *gorm.DB, dataSourceRepo repositories.DataSourceRepository, schemaRepo repositories.SchemaRepository, savedQueries *SavedQueryService, kpis *KPIService, dashboards *DashboardService) (*GraphQLService, error) {
+	s := &GraphQLService{
+		db:             db,
+		userRepo:       repositories.NewUserRepository(db),
+		dataSourceRepo: dataSourceRepo,
+		schemaRepo:     schemaRepo,
+		ragRepo:        repositories.NewRAGRepository(db),
+		savedQueries:   savedQueries,
+		kpis:           kpis,
+		dashboards:     dashboards,
+	}
+	schema, err := graphql.NewSchema(s.queryType(), s.objectTypes(), 0)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Execute runs a query as the user
+func (s *GraphQLService) Execute(ctx context.Context, userID uint, req graphql.Request) *graphql.Response {
+	return s.schema.Execute(WithGraphQLUser(ctx, userID), req)
+}
+
+// SDL returns the schema in the GraphQL schema definition language
+func (s *GraphQLService) SDL() string {
+	return s.schema.SDL()
+}
+
+func (s *GraphQLService) queryType() *graphql.Object {
+	return &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "me", Type: "User!", Description: "The current user",
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, _ graphql.Args) (interface{}, error) {
+				return s.userRepo.WithContext(ctx).GetByID(graphQLUser(ctx))
+			})},
+		{Name: "dataSources", Type: "[DataSource!]!", Description: "The data sources of the current user",
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, _ graphql.Args) (interface{}, error) {
+				dataSources, err := s.dataSourceRepo.WithContext(ctx).GetByUserID(graphQLUser(ctx))
+				if err != nil {
+					return nil, fmt.Errorf("failed to get data sources: %w", err)
+				}
+				return pointers(dataSources), nil
+			})},
+		{Name: "dataSource", Type: "DataSource", Args: []*graphql.Argument{{Name: "id", Type: "ID!"}},
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, args graphql.Args) (interface{}, error) {
+				dataSources, err := s.ownedDataSources(ctx, []uint{args.ID("id")})
+				if err != nil {
+					return nil, err
+				}
+				return dataSources[args.ID("id")], nil
+			})},
+		{Name: "savedQueries", Type: "[SavedQuery!]!", Description: "Saved queries of the current user and those shared with the workspace",
+			Args: []*graphql.Argument{
+				{Name: "mine", Type: "Boolean", Description: "Only the user's own", Default: false},
+				{Name: "dataSourceId", Type: "ID"},
+				{Name: "tag", Type: "String"},
+				{Name: "search", Type: "String", Description: "Matches the name, description or question"},
+			},
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, args graphql.Args) (interface{}, error) {
+				saved, err := s.savedQueries.WithContext(ctx).List(graphQLUser(ctx), &models.SavedQueryFilter{
+					Mine:         args.Bool("mine"),
+					DataSourceID: args.ID("dataSourceId"),
+					Tag:          args.String("tag"),
+					Search:       args.String("search"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				return pointers(saved), nil
+			})},
+		{Name: "savedQuery", Type: "SavedQuery", Args: []*graphql.Argument{{Name: "id", Type: "ID!"}},
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, args graphql.Args) (interface{}, error) {
+				saved, err := s.savedQueries.WithContext(ctx).Get(graphQLUser(ctx), args.ID("id"))
+				if errors.Is(err, ErrSavedQueryNotFound) {
+					return nil, nil
+				}
+				return saved, err
+			})},
+		{Name: "kpis", Type: "[KPI!]!", Description: "The active KPI definitions of the current user",
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, _ graphql.Args) (interface{}, error) {
+				kpis, err := s.ragRepo.WithContext(ctx).GetKPIDefinitionsByUser(graphQLUser(ctx))
+				if err != nil {
+					return nil, fmt.Errorf("failed to get KPIs: %w", err)
+				}
+				return pointers(kpis), nil
+			})},
+		{Name: "dashboards", Type: "[Dashboard!]!", Description: "Dashboards the current user owns or collaborates on",
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, _ graphql.Args) (interface{}, error) {
+				dashboards, err := s.dashboards.WithContext(ctx).ListDashboards(graphQLUser(ctx))
+				if err != nil {
+					return nil, err
+				}
+				return pointers(dashboards), nil
+			})},
+		{Name: "dashboard", Type: "Dashboard", Args: []*graphql.Argument{{Name: "id", Type: "ID!"}},
+			Resolve: graphql.Each(func(ctx context.Context, _ interface{}, args graphql.Args) (interface{}, error) {
+				dashboard, err := s.dashboards.WithContext(ctx).GetDashboard(graphQLUser(ctx), args.ID("id"))
+				if errors.Is(err, ErrDashboardNotFound) {
+					return nil, nil
+				}
+				return dashboard, err
+			})},
+	}}
+}
+
+func (s *GraphQLService) objectTypes() []*graphql.Object {
+	user := &graphql.Object{Name: "User", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.User).ID })},
+		{Name: "email", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.User).Email })},
+		{Name: "username", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.User).Username })},
+		{Name: "firstName", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.User).FirstName })},
+		{Name: "lastName", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.User).LastName })},
+	}}
+
+	dataSource := &graphql.Object{Name: "DataSource", Description: "A data source; the connection configuration is not exposed", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).ID })},
+		{Name: "name", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).Name })},
+		{Name: "description", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).Description })},
+		{Name: "type", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return string(v.(*models.DataSource).Type) })},
+		{Name: "status", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return string(v.(*models.DataSource).Status) })},
+		{Name: "lastTested", Type: "Time", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).LastTested })},
+		{Name: "errorMessage", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).ErrorMsg })},
+		{Name: "createdAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).CreatedAt })},
+		{Name: "updatedAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DataSource).UpdatedAt })},
+		{Name: "owner", Type: "User", Resolve: s.usersBy(func(v interface{}) uint { return v.(*models.DataSource).UserID })},
+		{Name: "schemas", Type: "[Schema!]!", Description: "Discovered tables, sheets or collections", Resolve: s.resolveSchemas},
+	}}
+
+	schema := &graphql.Object{Name: "Schema", Description: "A table, sheet or collection of a data source", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Schema).ID })},
+		{Name: "name", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Schema).Name })},
+		{Name: "displayName", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Schema).DisplayName })},
+		{Name: "description", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Schema).Description })},
+		{Name: "rowCount", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Schema).RowCount })},
+		{Name: "isActive", Type: "Boolean!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Schema).IsActive })},
+		{Name: "columns", Type: "[Column!]!", Resolve: graphql.Property(func(v interface{}) interface{} {
+			var columns []*models.Column
+			_ = json.Unmarshal(v.(*models.Schema).Columns, &columns)
+			if columns == nil {
+				columns = []*models.Column{}
+			}
+			return columns
+		})},
+		{Name: "dataSource", Type: "DataSource", Resolve: s.dataSourcesBy(func(v interface{}) uint { return v.(*models.Schema).DataSourceID })},
+	}}
+
+	column := &graphql.Object{Name: "Column", Fields: []*graphql.Field{
+		{Name: "name", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Column).Name })},
+		{Name: "type", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Column).Type })},
+		{Name: "nullable", Type: "Boolean!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Column).Nullable })},
+		{Name: "primaryKey", Type: "Boolean!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Column).PrimaryKey })},
+		{Name: "description", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Column).Description })},
+	}}
+
+	savedQuery := &graphql.Object{Name: "SavedQuery", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).ID })},
+		{Name: "name", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).Name })},
+		{Name: "description", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).Description })},
+		{Name: "tags", Type: "[String!]!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).Tags })},
+		{Name: "nlQuery", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).NLQuery })},
+		{Name: "sql", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).SQL })},
+		{Name: "shared", Type: "Boolean!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).Shared })},
+		{Name: "owned", Type: "Boolean!", Description: "Saved by the current user", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).Owned })},
+		{Name: "runCount", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).RunCount })},
+		{Name: "lastRunAt", Type: "Time", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).LastRunAt })},
+		{Name: "createdAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).CreatedAt })},
+		{Name: "updatedAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.SavedQueryResponse).UpdatedAt })},
+		{Name: "owner", Type: "User", Resolve: s.usersBy(func(v interface{}) uint { return v.(*models.SavedQueryResponse).UserID })},
+		{Name: "dataSource", Type: "DataSource", Description: "Null when the query was shared by a user whose data source is not the current user's",
+			Resolve: s.dataSourcesBy(func(v interface{}) uint { return v.(*models.SavedQueryResponse).DataSourceID })},
+	}}
+
+	kpi := &graphql.Object{Name: "KPI", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).ID })},
+		{Name: "name", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).Name })},
+		{Name: "displayName", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).DisplayName })},
+		{Name: "description", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).Description })},
+		{Name: "formula", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).Formula })},
+		{Name: "category", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).Category })},
+		{Name: "unit", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).Unit })},
+		{Name: "grain", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIDefinition).Grain })},
+		{Name: "dataSource", Type: "DataSource", Resolve: s.dataSourcesBy(func(v interface{}) uint {
+			if id := v.(*models.KPIDefinition).DataSourceID; id != nil {
+				return *id
+			}
+			return 0
+		})},
+		{Name: "value", Type: "KPIValue", Description: "Computes the KPI on its data source, like POST /kpis/{id}/compute",
+			Args: []*graphql.Argument{
+				{Name: "from", Type: "String", Description: "YYYY-MM-DD, defaults to 29 days before to"},
+				{Name: "to", Type: "String", Description: "YYYY-MM-DD, inclusive, defaults to today (UTC)"},
+				{Name: "grain", Type: "String", Description: "daily, weekly or monthly; defaults to the KPI grain"},
+				{Name: "compare", Type: "Boolean", Description: "Also compute the prior period of the same length", Default: false},
+			},
+			Resolve: graphql.Each(func(ctx context.Context, v interface{}, args graphql.Args) (interface{}, error) {
+				grain := args.String("grain")
+				if _, ok := kpiGrains[grain]; grain != "" && !ok {
+					return nil, fmt.Errorf("%w: grain must be daily, weekly or monthly", ErrInvalidKPICompute)
+				}
+				return s.kpis.WithContext(ctx).Compute(graphQLUser(ctx), v.(*models.KPIDefinition).ID, &models.KPIComputeRequest{
+					From:    args.String("from"),
+					To:      args.String("to"),
+					Grain:   grain,
+					Compare: args.Bool("compare"),
+				})
+			})},
+	}}
+
+	kpiValue := &graphql.Object{Name: "KPIValue", Fields: []*graphql.Field{
+		{Name: "grain", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIComputeResponse).Grain })},
+		{Name: "current", Type: "KPIPeriod!", Resolve: graphql.Property(func(v interface{}) interface{} { return &v.(*models.KPIComputeResponse).Current })},
+		{Name: "previous", Type: "KPIPeriod", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIComputeResponse).Previous })},
+		{Name: "change", Type: "Float", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIComputeResponse).Change })},
+		{Name: "percentChange", Type: "Float", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIComputeResponse).PercentChange })},
+	}}
+
+	kpiPeriod := &graphql.Object{Name: "KPIPeriod", Fields: []*graphql.Field{
+		{Name: "from", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIPeriodValue).From })},
+		{Name: "to", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIPeriodValue).To })},
+		{Name: "value", Type: "Float", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPIPeriodValue).Value })},
+		{Name: "series", Type: "[KPIPoint!]!", Resolve: graphql.Property(func(v interface{}) interface{} {
+			return pointers(v.(*models.KPIPeriodValue).Series)
+		})},
+	}}
+
+	kpiPoint := &graphql.Object{Name: "KPIPoint", Fields: []*graphql.Field{
+		{Name: "period", Type: "String!", Description: "Start of the period", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPISeriesPoint).Period })},
+		{Name: "value", Type: "Float", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.KPISeriesPoint).Value })},
+	}}
+
+	dashboard := &graphql.Object{Name: "Dashboard", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Dashboard).ID })},
+		{Name: "name", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Dashboard).Name })},
+		{Name: "description", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Dashboard).Description })},
+		{Name: "createdAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Dashboard).CreatedAt })},
+		{Name: "updatedAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.Dashboard).UpdatedAt })},
+		{Name: "owner", Type: "User", Resolve: s.usersBy(func(v interface{}) uint { return v.(*models.Dashboard).UserID })},
+		{Name: "widgets", Type: "[Widget!]!", Description: "Widgets in grid order", Resolve: s.resolveWidgets},
+	}}
+
+	widget := &graphql.Object{Name: "Widget", Fields: []*graphql.Field{
+		{Name: "id", Type: "ID!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).ID })},
+		{Name: "type", Type: "String!", Resolve: graphql.Property(func(v interface{}) interface{} { return string(v.(*models.DashboardWidget).Type) })},
+		{Name: "title", Type: "String", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).Title })},
+		{Name: "config", Type: "JSON", Description: "Visualization settings", Resolve: graphql.Property(func(v interface{}) interface{} {
+			return rawJSON(v.(*models.DashboardWidget).Config)
+		})},
+		{Name: "x", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).X })},
+		{Name: "y", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).Y })},
+		{Name: "width", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).Width })},
+		{Name: "height", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).Height })},
+		{Name: "version", Type: "Int!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).Version })},
+		{Name: "queryId", Type: "ID", Description: "NL2SQL query the widget displays", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).QueryID })},
+		{Name: "extractId", Type: "ID", Description: "Snapshot displayed instead of the latest result of the query", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).ExtractID })},
+		{Name: "updatedAt", Type: "Time!", Resolve: graphql.Property(func(v interface{}) interface{} { return v.(*models.DashboardWidget).UpdatedAt })},
+	}}
+
+	return []*graphql.Object{user, dataSource, schema, column, savedQuery, kpi, kpiValue, kpiPeriod, kpiPoint, dashboard, widget}
+}
+
+// usersBy resolves the users referenced by a batch of sources with one lookup
+func (s *GraphQLService) usersBy(userID func(source interface{}) uint) graphql.Resolver {
+	return func(ctx context.Context, sources []interface{}, _ graphql.Args) ([]interface{}, error) {
+		users, err := s.userRepo.WithContext(ctx).GetByIDs(distinctIDs(sources, userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		byID := make(map[uint]*models.User, len(users))
+		for _, user := range users {
+			byID[user.ID] = user
+		}
+
+		values := make([]interface{}, len(sources))
+		for i, source := range sources {
+			if user, ok := byID[userID(source)]; ok {
+				values[i] = user
+			}
+		}
+		return values, nil
+	}
+}
+
+// dataSourcesBy resolves the data sources referenced by a batch of sources
+// with one lookup; data sources of other users resolve to null
+func (s *GraphQLService) dataSourcesBy(dataSourceID func(source interface{}) uint) graphql.Resolver {
+	return func(ctx context.Context, sources []interface{}, _ graphql.Args) ([]interface{}, error) {
+		dataSources, err := s.ownedDataSources(ctx, distinctIDs(sources, dataSourceID))
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]interface{}, len(sources))
+		for i, source := range sources {
+			if dataSource, ok := dataSources[dataSourceID(source)]; ok {
+				values[i] = dataSource
+			}
+		}
+		return values, nil
+	}
+}
+
+func (s *GraphQLService) ownedDataSources(ctx context.Context, ids []uint) (map[uint]*models.DataSource, error) {
+	userID := graphQLUser(ctx)
+	dataSources, err := s.dataSourceRepo.WithContext(ctx).GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data sources: %w", err)
+	}
+	owned := make(map[uint]*models.DataSource, len(dataSources))
+	for i := range dataSources {
+		if dataSources[i].UserID == userID {
+			owned[dataSources[i].ID] = &dataSources[i]
+		}
+	}
+	return owned, nil
+}
+
+// resolveSchemas loads the schemas of a batch of data sources with one lookup
+func (s *GraphQLService) resolveSchemas(ctx context.Context, sources []interface{}, _ graphql.Args) ([]interface{}, error) {
+	schemas, err := s.schemaRepo.WithContext(ctx).GetByDataSourceIDs(distinctIDs(sources, func(v interface{}) uint { return v.(*models.DataSource).ID }))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schemas: %w", err)
+	}
+	byDataSource := map[uint][]*models.Schema{}
+	for i := range schemas {
+		byDataSource[schemas[i].DataSourceID] = append(byDataSource[schemas[i].DataSourceID], &schemas[i])
+	}
+
+	values := make([]interface{}, len(sources))
+	for i, source := range sources {
+		values[i] = nonNilSlice(byDataSource[source.(*models.DataSource).ID])
+	}
+	return values, nil
+}
+
+// resolveWidgets loads the widgets of a batch of dashboards with one query.
+// The dashboards are already visible to the user.
+func (s *GraphQLService) resolveWidgets(ctx context.Context, sources []interface{}, _ graphql.Args) ([]interface{}, error) {
+	ids := distinctIDs(sources, func(v interface{}) uint { return v.(*models.Dashboard).ID })
+	var widgets []models.DashboardWidget
+	if err := s.db.WithContext(ctx).Where("dashboard_id IN ?", ids).Order("y ASC, x ASC, id ASC").Find(&widgets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get widgets: %w", err)
+	}
+	byDashboard := map[uint][]*models.DashboardWidget{}
+	for i := range widgets {
+		byDashboard[widgets[i].DashboardID] = append(byDashboard[widgets[i].DashboardID], &widgets[i])
+	}
+
+	values := make([]interface{}, len(sources))
+	for i, source := range sources {
+		values[i] = nonNilSlice(byDashboard[source.(*models.Dashboard).ID])
+	}
+	return values, nil
+}
+
+// distinctIDs returns the non-zero IDs of a batch of sources, once each
+func distinctIDs(sources []interface{}, id func(source interface{}) uint) []uint {
+	seen := make(map[uint]bool, len(sources))
+	ids := make([]uint, 0, len(sources))
+	for _, source := range sources {
+		if v := id(source); v != 0 && !seen[v] {
+			seen[v] = true
+			ids = append(ids, v)
+		}
+	}
+	return ids
+}
+
+// pointers returns pointers to the items of a slice, as GraphQL sources
+func pointers[T any](items []T) []*T {
+	result := make([]*T, len(items))
+	for i := range items {
+		result[i] = &items[i]
+	}
+	return result
+}
+
+func nonNilSlice[T any](items []*T) []*T {
+	if items == nil {
+		return []*T{}
+	}
+	return items
+}
+
+// rawJSON returns a JSON column as is, or nil when it is empty
+func rawJSON(value models.JSON) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return json.RawMessage(value)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/graphql"
+	"narapulse-be/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLDataSourceRepo struct {
+	repositories.DataSourceRepository
+	dataSources []models.DataSource
+	lookups     int
+}
+
+func (r *graphQLDataSourceRepo) WithContext(context.Context) repositories.DataSourceRepository {
+	return r
+}
+
+func (r *graphQLDataSourceRepo) GetByUserID(userID uint) ([]models.DataSource, error) {
+	var owned []models.DataSource
+	for _, dataSource := range r.dataSources {
+		if dataSource.UserID == userID {
+			owned = append(owned, dataSource)
+		}
+	}
+	return owned, nil
+}
+
+func (r *graphQLDataSourceRepo) GetByIDs(ids []uint) ([]models.DataSource, error) {
+	r.lookups++
+	var found []models.DataSource
+	for _, dataSource := range r.dataSources {
+		for _, id := range ids {
+			if dataSource.ID == id {
+				found = append(found, dataSource)
+			}
+		}
+	}
+	return found, nil
+}
+
+type graphQLSchemaRepo struct {
+	repositories.SchemaRepository
+	schemas []models.Schema
+	lookups int
+}
+
+func (r *graphQLSchemaRepo) WithContext(context.Context) repositories.SchemaRepository {
+	return r
+}
+
+func (r *graphQLSchemaRepo) GetByDataSourceIDs(ids []uint) ([]models.Schema, error) {
+	r.lookups++
+	var found []models.Schema
+	for _, schema := range r.schemas {
+		for _, id := range ids {
+			if schema.DataSourceID == id {
+				found = append(found, schema)
+			}
+		}
+	}
+	return found, nil
+}
+
+type graphQLUserRepo struct {
+	repositories.UserRepository
+	lookups int
+}
+
+func (r *graphQLUserRepo) WithContext(context.Context) repositories.UserRepository {
+	return r
+}
+
+func (r *graphQLUserRepo) GetByIDs(ids []uint) ([]*models.User, error) {
+	r.lookups++
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, &models.User{ID: id, Username: map[uint]string{1: "ana", 2: "budi"}[id]})
+	}
+	return users, nil
+}
+
+func TestGraphQLServiceBatchesRelations(t *testing.T) {
+	dataSources := &graphQLDataSourceRepo{dataSources: []models.DataSource{
+		{ID: 1, UserID: 1, Name: "Sales", Type: models.DataSourceTypePostgreSQL},
+		{ID: 2, UserID: 1, Name: "Marketing", Type: models.DataSourceTypeCSV},
+		{ID: 3, UserID: 2, Name: "Finance", Type: models.DataSourceTypePostgreSQL},
+	}}
+	schemas := &graphQLSchemaRepo{schemas: []models.Schema{
+		{ID: 10, DataSourceID: 1, Name: "orders", Columns: models.JSON(`[{"name":"id","type":"integer","primary_key":true}]`)},
+		{ID: 11, DataSourceID: 1, Name: "customers"},
+		{ID: 12, DataSourceID: 3, Name: "ledger"},
+	}}
+	users := &graphQLUserRepo{}
+
+	service, err := NewGraphQLService(nil, dataSources, schemas, nil, nil, nil)
+	require.NoError(t, err)
+	service.userRepo = users
+
+	response := service.Execute(context.Background(), 1, graphql.Request{
+		Query: `{
+			dataSources { id name owner { username } schemas { name columns { name primaryKey } dataSource { name } } }
+			other: dataSource(id: 3) { name }
+		}`,
+	})
+	require.Empty(t, response.Errors)
+	data, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"dataSources": [
+			{"id": "1", "name": "Sales", "owner": {"username": "ana"}, "schemas": [
+				{"name": "orders", "columns": [{"name": "id", "primaryKey": true}], "dataSource": {"name": "Sales"}},
+				{"name": "customers", "columns": [], "dataSource": {"name": "Sales"}}
+			]},
+			{"id": "2", "name": "Marketing", "owner": {"username": "ana"}, "schemas": []}
+		],
+		"other": null
+	}`, string(data), "data sources of other users are not visible")
+
+	assert.Equal(t, 1, users.lookups, "owners are loaded once for every data source")
+	assert.Equal(t, 1, schemas.lookups, "schemas are loaded once for every data source")
+	assert.Equal(t, 2, dataSources.lookups, "once for dataSource(id), once for the data sources of every schema")
+}
+
+func TestGraphQLServiceSDL(t *testing.T) {
+	service, err := NewGraphQLService(nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	sdl := service.SDL()
+	assert.Contains(t, sdl, "type Query {")
+	assert.Contains(t, sdl, "  value(from: String, to: String, grain: String, compare: Boolean = false): KPIValue\n")
+	assert.Contains(t, sdl, "  widgets: [Widget!]!\n")
+	assert.Contains(t, sdl, "  schemas: [Schema!]!\n")
+}
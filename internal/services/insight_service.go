@@ -0,0 +1,464 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+const (
+	// insightCacheTTL is how long the insights of a result are reused
+	insightCacheTTL = time.Hour
+	// insightCacheMaxEntries bounds the in-memory insight cache
+	insightCacheMaxEntries = 500
+	// insightFlatPercent is the change below which a trend is flat
+	insightFlatPercent = 1.0
+)
+
+// insightTimeLayouts are the formats of time values in result rows
+var insightTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02", "2006-01"}
+
+// insightCacheEntry is cached insights of a result
+type insightCacheEntry struct {
+	insights  models.QueryInsights
+	expiresAt time.Time
+}
+
+// InsightService summarizes query results in natural language: the trend of
+// a measure over time, the category that moved the most and the one that
+// contributes the most. The facts are computed from the rows; the LLM only
+// phrases them, and identical results are served from a cache.
+type InsightService struct {
+	usage *UsageService
+
+	cacheMu sync.Mutex
+	cache   map[string]insightCacheEntry
+}
+
+// NewInsightService creates a new insight service
+func NewInsightService(usage *UsageService) *InsightService {
+	return &InsightService{
+		usage: usage,
+		cache: make(map[string]insightCacheEntry),
+	}
+}
+
+// Summarize returns the insights of a result set. Results without a numeric
+// column get an empty narrative and cost no LLM call.
+func (s *InsightService) Summarize(ctx context.Context, question string, columns []models.Column, rows []map[string]interface{}) *models.QueryInsights {
+	key := insightCacheKey(question, columns, rows)
+	if cached, ok := s.cacheGet(key); ok {
+		return cached
+	}
+
+	insights := &models.QueryInsights{Insights: analyzeResult(columns, rows)}
+	if len(insights.Insights) > 0 {
+		// The AI provider is not wired up yet, so the facts are joined as they
+		// are; the prompt is what the provider will be asked to phrase
+		prompt := insightPrompt(question, insights.Insights)
+		insights.Narrative = narrateInsights(insights.Insights)
+		s.usage.Record(ctx, models.UsageKindLLM, s.usage.llmModel(), estimateTokens(prompt), estimateTokens(insights.Narrative))
+	}
+
+	s.cacheSet(key, *insights)
+	return insights
+}
+
+// insightCacheKey identifies a question and its result
+func insightCacheKey(question string, columns []models.Column, rows []map[string]interface{}) string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	payload, _ := json.Marshal(struct {
+		Question string                   `json:"q"`
+		Columns  []string                 `json:"c"`
+		Rows     []map[string]interface{} `json:"r"`
+	}{question, names, rows})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *InsightService) cacheGet(key string) (*models.QueryInsights, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	entry, ok := s.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(s.cache, key)
+		return nil, false
+	}
+	insights := entry.insights
+	insights.Cached = true
+	return &insights, true
+}
+
+func (s *InsightService) cacheSet(key string, insights models.QueryInsights) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if len(s.cache) >= insightCacheMaxEntries {
+		now := time.Now()
+		for k, entry := range s.cache {
+			if now.After(entry.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= insightCacheMaxEntries {
+			s.cache = make(map[string]insightCacheEntry)
+		}
+	}
+	s.cache[key] = insightCacheEntry{insights: insights, expiresAt: time.Now().Add(insightCacheTTL)}
+}
+
+// insightPrompt asks the LLM to phrase the facts of a result as a short narrative
+func insightPrompt(question string, insights []models.QueryInsight) string {
+	var b strings.Builder
+	b.WriteString("Summarize the result of a data question for a business user in two or three sentences. ")
+	b.WriteString("Only use the facts below; do not add numbers.\n\n")
+	if question != "" {
+		b.WriteString("Question: " + question + "\n")
+	}
+	b.WriteString("Facts:\n")
+	for _, insight := range insights {
+		b.WriteString("- " + insight.Sentence + "\n")
+	}
+	return b.String()
+}
+
+// narrateInsights joins the sentences of the insights
+func narrateInsights(insights []models.QueryInsight) string {
+	sentences := make([]string, len(insights))
+	for i, insight := range insights {
+		sentences[i] = insight.Sentence
+	}
+	return strings.Join(sentences, " ")
+}
+
+// insightPoint is the value of a measure for a category and period
+type insightPoint struct {
+	category string
+	period   time.Time
+	value    float64
+}
+
+// analyzeResult finds the trend, top mover and biggest contributor of the
+// first numeric column, by the first time column and the first other column
+func analyzeResult(columns []models.Column, rows []map[string]interface{}) []models.QueryInsight {
+	var measure, timeColumn, category *models.Column
+	for i := range columns {
+		col := &columns[i]
+		switch {
+		case timeColumn == nil && timeValuedColumn(col.Name, rows):
+			timeColumn = col
+		case measure == nil && numericColumn(col.Name, rows):
+			measure = col
+		case category == nil && !numericColumn(col.Name, rows):
+			category = col
+		}
+	}
+	if measure == nil {
+		return nil
+	}
+
+	var points []insightPoint
+	for _, row := range rows {
+		value, ok := answerNumber(row[measure.Name])
+		if !ok {
+			continue
+		}
+		point := insightPoint{value: value}
+		if timeColumn != nil {
+			if point.period, ok = insightTime(row[timeColumn.Name]); !ok {
+				continue
+			}
+		}
+		if category != nil {
+			point.category = insightLabel(row[category.Name])
+		}
+		points = append(points, point)
+	}
+	if len(points) < 2 {
+		return nil
+	}
+
+	var insights []models.QueryInsight
+	if timeColumn != nil {
+		if trend := trendInsight(measure, points); trend != nil {
+			insights = append(insights, *trend)
+		}
+		if mover := topMoverInsight(measure, timeColumn, category, points); mover != nil {
+			insights = append(insights, *mover)
+		}
+	}
+	if category != nil {
+		if contributor := contributorInsight(measure, category, points); contributor != nil {
+			insights = append(insights, *contributor)
+		}
+	}
+	return insights
+}
+
+// trendInsight compares the total of the measure in the first and last period
+func trendInsight(measure *models.Column, points []insightPoint) *models.QueryInsight {
+	periods, totals := periodTotals(points)
+	if len(periods) < 2 {
+		return nil
+	}
+	first, last := totals[periods[0]], totals[periods[len(periods)-1]]
+	change, percent := kpiChange(&last, &first)
+
+	insight := &models.QueryInsight{
+		Kind:          models.InsightKindTrend,
+		Measure:       measure.Name,
+		Label:         formatInsightTime(periods[len(periods)-1]),
+		Value:         last,
+		Change:        change,
+		PercentChange: percent,
+		Direction:     insightDirection(*change, percent),
+	}
+	from, to := formatInsightTime(periods[0]), formatInsightTime(periods[len(periods)-1])
+	name := humanizeColumnName(measure.Name)
+	switch insight.Direction {
+	case models.InsightDirectionFlat:
+		insight.Sentence = fmt.Sprintf("%s stayed flat from %s to %s at %s.", name, from, to, insightValue(measure, last))
+	default:
+		by := ""
+		if percent != nil {
+			by = " by " + strings.TrimSuffix(formatAnswerNumber(math.Abs(*percent), 1), ".0") + "%"
+		}
+		insight.Sentence = fmt.Sprintf("%s went %s%s from %s to %s, from %s to %s.",
+			name, insight.Direction, by, from, to, insightValue(measure, first), insightValue(measure, last))
+	}
+	return insight
+}
+
+// topMoverInsight finds the category whose measure changed the most between
+// the first and last period or, without categories, the largest change
+// between consecutive periods
+func topMoverInsight(measure, timeColumn, category *models.Column, points []insightPoint) *models.QueryInsight {
+	periods, totals := periodTotals(points)
+	if len(periods) < 2 {
+		return nil
+	}
+	name := strings.ToLower(humanizeColumnName(measure.Name))
+
+	if category == nil {
+		best := -1
+		for i := 1; i < len(periods); i++ {
+			change := totals[periods[i]] - totals[periods[i-1]]
+			if best < 0 || math.Abs(change) > math.Abs(totals[periods[best]]-totals[periods[best-1]]) {
+				best = i
+			}
+		}
+		current, previous := totals[periods[best]], totals[periods[best-1]]
+		change, percent := kpiChange(&current, &previous)
+		if *change == 0 {
+			return nil
+		}
+		label := formatInsightTime(periods[best])
+		return &models.QueryInsight{
+			Kind:          models.InsightKindTopMover,
+			Measure:       measure.Name,
+			Dimension:     timeColumn.Name,
+			Label:         label,
+			Value:         current,
+			Change:        change,
+			PercentChange: percent,
+			Direction:     insightDirection(*change, percent),
+			Sentence: fmt.Sprintf("The biggest change in %s was in %s: %s %s%s on the period before.",
+				name, label, insightDirection(*change, percent), insightValue(measure, math.Abs(*change)), insightPercent(percent)),
+		}
+	}
+
+	first, last := periods[0], periods[len(periods)-1]
+	byCategory := make(map[string][2]float64)
+	for _, point := range points {
+		values := byCategory[point.category]
+		switch {
+		case point.period.Equal(first):
+			values[0] += point.value
+		case point.period.Equal(last):
+			values[1] += point.value
+		}
+		byCategory[point.category] = values
+	}
+
+	labels := make([]string, 0, len(byCategory))
+	for label := range byCategory {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	bestLabel, bestChange := "", 0.0
+	for _, label := range labels {
+		values := byCategory[label]
+		if change := values[1] - values[0]; math.Abs(change) > math.Abs(bestChange) {
+			bestLabel, bestChange = label, change
+		}
+	}
+	if bestChange == 0 {
+		return nil
+	}
+	values := byCategory[bestLabel]
+	change, percent := kpiChange(&values[1], &values[0])
+	direction := insightDirection(*change, percent)
+	return &models.QueryInsight{
+		Kind:          models.InsightKindTopMover,
+		Measure:       measure.Name,
+		Dimension:     category.Name,
+		Label:         bestLabel,
+		Value:         values[1],
+		Change:        change,
+		PercentChange: percent,
+		Direction:     direction,
+		Sentence: fmt.Sprintf("%s moved the most: %s went %s by %s%s between %s and %s.",
+			bestLabel, name, direction, insightValue(measure, math.Abs(*change)), insightPercent(percent), formatInsightTime(first), formatInsightTime(last)),
+	}
+}
+
+// contributorInsight finds the category with the largest total of the measure
+func contributorInsight(measure, category *models.Column, points []insightPoint) *models.QueryInsight {
+	totals := make(map[string]float64)
+	total := 0.0
+	for _, point := range points {
+		if point.value < 0 {
+			// Shares of totals with negative values mislead
+			return nil
+		}
+		totals[point.category] += point.value
+		total += point.value
+	}
+	if len(totals) < 2 || total == 0 {
+		return nil
+	}
+
+	labels := make([]string, 0, len(totals))
+	for label := range totals {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	best := labels[0]
+	for _, label := range labels[1:] {
+		if totals[label] > totals[best] {
+			best = label
+		}
+	}
+
+	share := totals[best] / total * 100
+	return &models.QueryInsight{
+		Kind:      models.InsightKindBiggestContributor,
+		Measure:   measure.Name,
+		Dimension: category.Name,
+		Label:     best,
+		Value:     totals[best],
+		Share:     &share,
+		Sentence: fmt.Sprintf("%s contributed the most %s: %s, %s%% of the total.",
+			best, strings.ToLower(humanizeColumnName(measure.Name)), insightValue(measure, totals[best]), formatAnswerNumber(share, 0)),
+	}
+}
+
+// periodTotals sums the measure per period, returning the periods in order
+func periodTotals(points []insightPoint) ([]time.Time, map[time.Time]float64) {
+	totals := make(map[time.Time]float64)
+	var periods []time.Time
+	for _, point := range points {
+		if _, ok := totals[point.period]; !ok {
+			periods = append(periods, point.period)
+		}
+		totals[point.period] += point.value
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Before(periods[j]) })
+	return periods, totals
+}
+
+// insightDirection tells whether a change goes up, down or is negligible
+func insightDirection(change float64, percent *float64) string {
+	switch {
+	case change == 0 || (percent != nil && math.Abs(*percent) < insightFlatPercent):
+		return models.InsightDirectionFlat
+	case change > 0:
+		return models.InsightDirectionUp
+	default:
+		return models.InsightDirectionDown
+	}
+}
+
+// timeValuedColumn reports whether the column has a value and all its values are times
+func timeValuedColumn(column string, rows []map[string]interface{}) bool {
+	found := false
+	for _, row := range rows {
+		if row[column] == nil {
+			continue
+		}
+		if _, ok := insightTime(row[column]); !ok {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// insightTime parses a time value of a result row
+func insightTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range insightTimeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatInsightTime formats a period, without the time of day at midnight
+func formatInsightTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// insightLabel formats a category value
+func insightLabel(value interface{}) string {
+	if value == nil {
+		return "(empty)"
+	}
+	return fmt.Sprint(value)
+}
+
+// insightValue formats a value of the measure, with the column format when known
+func insightValue(measure *models.Column, value float64) string {
+	if measure.Format != nil {
+		return formatColumnValue(measure.Format, value)
+	}
+	formatted := formatAnswerNumber(value, 2)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimSuffix(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
+
+// insightPercent formats a percent change as " (+12.5%)", or nothing without one
+func insightPercent(percent *float64) string {
+	if percent == nil {
+		return ""
+	}
+	formatted := formatAnswerNumber(*percent, 1)
+	formatted = strings.TrimSuffix(formatted, ".0")
+	if *percent > 0 {
+		formatted = "+" + formatted
+	}
+	return " (" + formatted + "%)"
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeResult(t *testing.T) {
+	t.Run("trend and top mover by category", func(t *testing.T) {
+		columns := []models.Column{{Name: "month"}, {Name: "region"}, {Name: "revenue"}}
+		rows := []map[string]interface{}{
+			{"month": "2025-01-01", "region": "Java", "revenue": 100.0},
+			{"month": "2025-01-01", "region": "Bali", "revenue": 50.0},
+			{"month": "2025-02-01", "region": "Java", "revenue": 110.0},
+			{"month": "2025-02-01", "region": "Bali", "revenue": 90.0},
+		}
+
+		insights := analyzeResult(columns, rows)
+		require.Len(t, insights, 3)
+
+		trend := insights[0]
+		assert.Equal(t, models.InsightKindTrend, trend.Kind)
+		assert.Equal(t, models.InsightDirectionUp, trend.Direction)
+		assert.InDelta(t, 33.33, *trend.PercentChange, 0.01)
+		assert.Equal(t, "Revenue went up by 33.3% from 2025-01-01 to 2025-02-01, from 150 to 200.", trend.Sentence)
+
+		mover := insights[1]
+		assert.Equal(t, models.InsightKindTopMover, mover.Kind)
+		assert.Equal(t, "Bali", mover.Label)
+		assert.Equal(t, 40.0, *mover.Change)
+
+		contributor := insights[2]
+		assert.Equal(t, models.InsightKindBiggestContributor, contributor.Kind)
+		assert.Equal(t, "Java", contributor.Label)
+		assert.InDelta(t, 60.0, *contributor.Share, 1e-9)
+		assert.Equal(t, "Java contributed the most revenue: 210, 60% of the total.", contributor.Sentence)
+	})
+
+	t.Run("largest change between periods", func(t *testing.T) {
+		columns := []models.Column{{Name: "day"}, {Name: "orders"}}
+		rows := []map[string]interface{}{
+			{"day": "2025-01-03", "orders": int64(10)},
+			{"day": "2025-01-01", "orders": int64(12)},
+			{"day": "2025-01-02", "orders": int64(30)},
+		}
+
+		insights := analyzeResult(columns, rows)
+		require.Len(t, insights, 2)
+		assert.Equal(t, models.InsightDirectionDown, insights[0].Direction, "periods are ordered by time")
+		assert.Equal(t, "2025-01-03", insights[1].Label)
+		assert.Equal(t, -20.0, *insights[1].Change)
+	})
+
+	t.Run("nothing to say", func(t *testing.T) {
+		assert.Empty(t, analyzeResult([]models.Column{{Name: "name"}}, []map[string]interface{}{{"name": "a"}, {"name": "b"}}))
+		assert.Empty(t, analyzeResult([]models.Column{{Name: "total"}}, []map[string]interface{}{{"total": 3.0}}))
+	})
+}
+
+func TestInsightServiceCache(t *testing.T) {
+	s := NewInsightService(nil)
+	columns := []models.Column{{Name: "region"}, {Name: "revenue"}}
+	rows := []map[string]interface{}{{"region": "Java", "revenue": 3.0}, {"region": "Bali", "revenue": 1.0}}
+
+	first := s.Summarize(context.Background(), "revenue by region", columns, rows)
+	assert.False(t, first.Cached)
+	assert.Equal(t, "Java contributed the most revenue: 3, 75% of the total.", first.Narrative)
+
+	second := s.Summarize(context.Background(), "revenue by region", columns, rows)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.Narrative, second.Narrative)
+
+	rows[0]["revenue"] = 4.0
+	assert.False(t, s.Summarize(context.Background(), "revenue by region", columns, rows).Cached, "other results are summarized again")
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/chat"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIntegrationNotFound is returned when a chat integration does not exist or is inactive
+	ErrIntegrationNotFound = errors.New("integration not found")
+	// ErrInvalidIntegration is returned when an integration cannot deliver as configured
+	ErrInvalidIntegration = errors.New("invalid integration")
+	// ErrIntegrationCommandsDisabled is returned for commands sent to an integration
+	// without a signing secret, command user or data source
+	ErrIntegrationCommandsDisabled = errors.New("commands are not enabled for this integration")
+)
+
+const (
+	// chatSnapshotRows is the default number of rows in a result snapshot
+	chatSnapshotRows = 10
+	// chatCommandTimeout bounds answering a command whose reply is posted later
+	chatCommandTimeout = 2 * time.Minute
+	// integrationCommandPathFormat is where a platform sends the commands of an integration
+	integrationCommandPathFormat = "/api/v1/integrations/%d/command"
+)
+
+// teamsMentions matches the bot mention and markup Teams adds to outgoing webhook text
+var teamsMentions = regexp.MustCompile(`<at>[^<]*</at>|<[^>]+>`)
+
+// IntegrationService delivers query results and alerts to Slack and
+// Microsoft Teams, and answers questions sent from them as commands.
+// Integrations are configured once per workspace by an admin.
+type IntegrationService struct {
+	db        *gorm.DB
+	nl2sql    *NL2SQLService
+	posterFor func(integration *models.ChatIntegration) (chat.Poster, error)
+	now       func() time.Time
+}
+
+// NewIntegrationService creates a new integration service
+func NewIntegrationService(db *gorm.DB, nl2sql *NL2SQLService) *IntegrationService {
+	return &IntegrationService{
+		db:        db,
+		nl2sql:    nl2sql,
+		posterFor: newChatPoster,
+		now:       time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *IntegrationService) WithContext(ctx context.Context) *IntegrationService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sql = s.nl2sql.WithContext(ctx)
+	return &scoped
+}
+
+// List returns every integration, for admins
+func (s *IntegrationService) List() ([]models.ChatIntegrationResponse, error) {
+	var integrations []models.ChatIntegration
+	if err := s.db.Order("name").Find(&integrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list integrations: %w", err)
+	}
+
+	responses := make([]models.ChatIntegrationResponse, len(integrations))
+	for i := range integrations {
+		responses[i] = *integrationResponse(&integrations[i])
+	}
+	return responses, nil
+}
+
+// ListActive returns the integrations users can share to
+func (s *IntegrationService) ListActive() ([]models.ChatIntegrationSummary, error) {
+	var integrations []models.ChatIntegration
+	if err := s.db.Where("is_active = ?", true).Order("name").Find(&integrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list integrations: %w", err)
+	}
+
+	summaries := make([]models.ChatIntegrationSummary, 0, len(integrations))
+	for _, integration := range integrations {
+		if integration.WebhookURL == "" && integration.BotToken == "" {
+			continue
+		}
+		summaries = append(summaries, models.ChatIntegrationSummary{
+			ID:             integration.ID,
+			Provider:       integration.Provider,
+			Name:           integration.Name,
+			DefaultChannel: integration.DefaultChannel,
+			AnyChannel:     integration.BotToken != "",
+		})
+	}
+	return summaries, nil
+}
+
+// Create configures a new integration
+func (s *IntegrationService) Create(adminID uint, req *models.ChatIntegrationRequest) (*models.ChatIntegrationResponse, error) {
+	integration := &models.ChatIntegration{CreatedBy: adminID}
+	if err := s.apply(integration, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(integration).Error; err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+	return integrationResponse(integration), nil
+}
+
+// Update reconfigures an integration; secrets left empty are kept
+func (s *IntegrationService) Update(id uint, req *models.ChatIntegrationRequest) (*models.ChatIntegrationResponse, error) {
+	integration, err := s.get(id, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(integration, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(integration).Error; err != nil {
+		return nil, fmt.Errorf("failed to update integration: %w", err)
+	}
+	return integrationResponse(integration), nil
+}
+
+// Delete removes an integration; alert rules posting to it stop delivering
+func (s *IntegrationService) Delete(id uint) error {
+	integration, err := s.get(id, false)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(integration).Error; err != nil {
+		return fmt.Errorf("failed to delete integration: %w", err)
+	}
+	return nil
+}
+
+// Test posts a test message to an integration
+func (s *IntegrationService) Test(ctx context.Context, id uint, channel string) error {
+	return s.Post(ctx, id, channel, chat.Message{Text: "NaraPulse is connected. Query results and alerts will be posted here."})
+}
+
+// Post posts a message through an active integration
+func (s *IntegrationService) Post(ctx context.Context, id uint, channel string, msg chat.Message) error {
+	integration, err := s.get(id, true)
+	if err != nil {
+		return err
+	}
+	if channel != "" && integration.BotToken == "" {
+		return fmt.Errorf("%w: only Slack apps with a bot token can post to another channel", ErrInvalidIntegration)
+	}
+	poster, err := s.posterFor(integration)
+	if err != nil {
+		return err
+	}
+	return poster.Post(ctx, channel, msg)
+}
+
+// ShareResult posts a snapshot of a stored result of one of the user's queries
+func (s *IntegrationService) ShareResult(ctx context.Context, userID, id uint, req *models.ChatShareRequest) error {
+	query, err := s.nl2sql.GetQueryDetails(userID, req.QueryID)
+	if err != nil {
+		return err
+	}
+	rows := req.Rows
+	if rows == 0 {
+		rows = chatSnapshotRows
+	}
+	page, err := s.nl2sql.GetQueryResults(userID, req.QueryID, &models.QueryResultPageRequest{ResultID: req.ResultID, Limit: rows})
+	if err != nil {
+		return err
+	}
+
+	msg := resultSnapshot(query.NLQuery, page.Columns, page.Data, rows, page.TotalRows)
+	if req.Comment != "" {
+		msg.Text = req.Comment + "\n" + msg.Text
+	}
+	return s.Post(ctx, id, req.Channel, msg)
+}
+
+// HandleCommand answers a question sent from Slack (a slash command) or Teams
+// (an outgoing webhook) and returns the reply payload. Slack commands with a
+// response_url are acknowledged at once and answered there, since Slack waits
+// only three seconds for the reply. Commands run in the tenant of the
+// integration, as the route is public and its request may carry no tenant.
+func (s *IntegrationService) HandleCommand(ctx context.Context, id uint, req *models.ChatCommandRequest) (map[string]interface{}, error) {
+	integration, err := s.get(id, true)
+	if err != nil {
+		return nil, err
+	}
+	if integration.SigningSecret == "" || integration.CommandUserID == nil || integration.CommandDataSourceID == nil {
+		return nil, ErrIntegrationCommandsDisabled
+	}
+	s = s.WithContext(tenancy.WithTenant(ctx, integration.TenantID))
+
+	if integration.Provider == models.IntegrationProviderTeams {
+		if err := chat.VerifyTeamsSignature(integration.SigningSecret, req.Authorization, req.Body); err != nil {
+			return nil, err
+		}
+		var activity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(req.Body, &activity); err != nil {
+			return nil, fmt.Errorf("%w: invalid Teams message", ErrInvalidIntegration)
+		}
+		question := strings.TrimSpace(teamsMentions.ReplaceAllString(activity.Text, ""))
+		return chat.TeamsPayload(s.answerCommand(integration, question)), nil
+	}
+
+	if err := chat.VerifySlackSignature(integration.SigningSecret, req.Timestamp, req.Signature, req.Body, s.now()); err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid Slack command", ErrInvalidIntegration)
+	}
+	question := strings.TrimSpace(form.Get("text"))
+	if question == "" {
+		return chat.SlackPayload(chat.Message{Text: "Ask a question about your data, e.g. `" + form.Get("command") + " revenue by month`"}, "ephemeral"), nil
+	}
+
+	responseURL := form.Get("response_url")
+	if responseURL == "" {
+		return chat.SlackPayload(s.answerCommand(integration, question), "in_channel"), nil
+	}
+	log := logger.FromContext(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(tenancy.WithTenant(context.Background(), integration.TenantID), chatCommandTimeout)
+		defer cancel()
+		reply := chat.SlackPayload(s.WithContext(ctx).answerCommand(integration, question), "in_channel")
+		if err := chat.PostResponse(ctx, responseURL, reply); err != nil {
+			log.Error().Err(err).Uint("integration_id", integration.ID).Msg("Failed to reply to Slack command")
+		}
+	}()
+	return chat.SlackPayload(chat.Message{Text: "Working on: " + question}, "ephemeral"), nil
+}
+
+// answerCommand answers a question as the command user of an integration:
+// one sentence for single values, a table snapshot otherwise
+func (s *IntegrationService) answerCommand(integration *models.ChatIntegration, question string) chat.Message {
+	userID := *integration.CommandUserID
+	answer, err := s.nl2sql.AnswerQuestion(userID, &models.NL2SQLAnswerRequest{
+		NLQuery:      question,
+		DataSourceID: *integration.CommandDataSourceID,
+	})
+	if err != nil {
+		return chat.Message{Text: fmt.Sprintf("%s\nCould not answer: %v", question, err)}
+	}
+	if answer.Answered {
+		return chat.Message{Text: question + "\n" + answer.Answer}
+	}
+	if answer.ResultID == 0 {
+		return chat.Message{Text: question + "\n" + answer.Message}
+	}
+
+	// Answering only looks at the first rows; run the query again for the snapshot
+	result, err := s.nl2sql.ExecuteQuery(userID, &models.QueryExecutionRequest{QueryID: answer.QueryID, Limit: chatSnapshotRows + 1})
+	if err != nil {
+		return chat.Message{Text: fmt.Sprintf("%s\nCould not run the query: %v", question, err)}
+	}
+	return resultSnapshot(question, result.Columns, result.Data, chatSnapshotRows, 0)
+}
+
+// apply validates a request and copies it onto an integration
+func (s *IntegrationService) apply(integration *models.ChatIntegration, req *models.ChatIntegrationRequest) error {
+	if req.WebhookURL != "" {
+		integration.WebhookURL = req.WebhookURL
+	}
+	if req.BotToken != "" {
+		integration.BotToken = req.BotToken
+	}
+	if req.SigningSecret != "" {
+		integration.SigningSecret = req.SigningSecret
+	}
+	integration.Provider = req.Provider
+	integration.Name = req.Name
+	integration.DefaultChannel = req.DefaultChannel
+	integration.CommandUserID = req.CommandUserID
+	integration.CommandDataSourceID = req.CommandDataSourceID
+	integration.IsActive = req.IsActive == nil || *req.IsActive
+
+	if integration.Provider == models.IntegrationProviderTeams && integration.BotToken != "" {
+		return fmt.Errorf("%w: Teams integrations post through an incoming webhook, not a bot token", ErrInvalidIntegration)
+	}
+	if integration.WebhookURL == "" && integration.BotToken == "" && integration.SigningSecret == "" {
+		return fmt.Errorf("%w: a webhook URL, bot token or signing secret is required", ErrInvalidIntegration)
+	}
+	if (integration.CommandUserID == nil) != (integration.CommandDataSourceID == nil) {
+		return fmt.Errorf("%w: commands need both a user and a data source", ErrInvalidIntegration)
+	}
+	if integration.CommandUserID != nil {
+		var count int64
+		err := s.db.Model(&models.DataSource{}).
+			Where("id = ? AND user_id = ?", *integration.CommandDataSourceID, *integration.CommandUserID).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to check data source: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: the command data source must belong to the command user", ErrInvalidIntegration)
+		}
+	}
+	return nil
+}
+
+func (s *IntegrationService) get(id uint, active bool) (*models.ChatIntegration, error) {
+	query := s.db.Where("id = ?", id)
+	if active {
+		query = query.Where("is_active = ?", true)
+	}
+	var integration models.ChatIntegration
+	if err := query.First(&integration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIntegrationNotFound
+		}
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+	return &integration, nil
+}
+
+// newChatPoster returns the poster of an integration; Slack bot tokens win
+// over webhooks since they can reach any channel
+func newChatPoster(integration *models.ChatIntegration) (chat.Poster, error) {
+	switch {
+	case integration.Provider == models.IntegrationProviderSlack && integration.BotToken != "":
+		return chat.NewSlackApp(integration.BotToken, integration.DefaultChannel), nil
+	case integration.Provider == models.IntegrationProviderSlack && integration.WebhookURL != "":
+		return chat.NewSlackWebhook(integration.WebhookURL), nil
+	case integration.Provider == models.IntegrationProviderTeams && integration.WebhookURL != "":
+		return chat.NewTeamsWebhook(integration.WebhookURL), nil
+	}
+	return nil, fmt.Errorf("%w: the integration has no webhook URL or bot token to post with", ErrInvalidIntegration)
+}
+
+// integrationResponse converts an integration to its response, without secrets
+func integrationResponse(integration *models.ChatIntegration) *models.ChatIntegrationResponse {
+	response := &models.ChatIntegrationResponse{
+		ChatIntegration:  *integration,
+		HasWebhook:       integration.WebhookURL != "",
+		HasBotToken:      integration.BotToken != "",
+		HasSigningSecret: integration.SigningSecret != "",
+	}
+	if response.HasSigningSecret && integration.CommandUserID != nil && integration.CommandDataSourceID != nil {
+		response.CommandPath = fmt.Sprintf(integrationCommandPathFormat, integration.ID)
+	}
+	return response
+}
+
+// resultSnapshot phrases a question and the first rows of its result as a
+// chat message, formatted like report tables. total is the row count of the
+// whole result when known; data may hold one row past limit to tell that more
+// rows exist.
+func resultSnapshot(question string, columns []models.Column, data []map[string]interface{}, limit int, total int64) chat.Message {
+	more := len(data) > limit
+	if more {
+		data = data[:limit]
+	}
+	names, rows, _ := reportTable(columns, data)
+
+	text := question
+	switch {
+	case len(data) == 0:
+		text += "\nNo rows."
+	case total > int64(len(data)):
+		text += fmt.Sprintf("\nFirst %d of %d rows", len(data), total)
+	case more:
+		text += fmt.Sprintf("\nFirst %d rows", len(data))
+	}
+	return chat.Message{Text: text, Table: textTable(names, rows, reportCellWidth)}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/chat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultSnapshot(t *testing.T) {
+	columns := []models.Column{{Name: "region"}, {Name: "orders"}}
+	var data []map[string]interface{}
+	for i := 0; i < chatSnapshotRows+1; i++ {
+		data = append(data, map[string]interface{}{"region": "Jakarta", "orders": int64(1200)})
+	}
+
+	msg := resultSnapshot("orders by region", columns, data, chatSnapshotRows, 0)
+	assert.Equal(t, "orders by region\nFirst 10 rows", msg.Text)
+	require.Len(t, msg.Table, chatSnapshotRows+2)
+	assert.Equal(t, "Jakarta  1,200", msg.Table[2])
+
+	msg = resultSnapshot("orders by region", columns, data[:3], 5, 40)
+	assert.Equal(t, "orders by region\nFirst 3 of 40 rows", msg.Text)
+
+	msg = resultSnapshot("orders by region", columns, data[:3], 5, 3)
+	assert.Equal(t, "orders by region", msg.Text)
+
+	msg = resultSnapshot("orders by region", columns, nil, 5, 0)
+	assert.Equal(t, "orders by region\nNo rows.", msg.Text)
+}
+
+func TestNewChatPoster(t *testing.T) {
+	poster, err := newChatPoster(&models.ChatIntegration{Provider: models.IntegrationProviderSlack, WebhookURL: "https://hooks.slack.com/x", BotToken: "xoxb"})
+	require.NoError(t, err)
+	assert.IsType(t, &chat.SlackApp{}, poster)
+
+	poster, err = newChatPoster(&models.ChatIntegration{Provider: models.IntegrationProviderSlack, WebhookURL: "https://hooks.slack.com/x"})
+	require.NoError(t, err)
+	assert.IsType(t, &chat.SlackWebhook{}, poster)
+
+	poster, err = newChatPoster(&models.ChatIntegration{Provider: models.IntegrationProviderTeams, WebhookURL: "https://example.webhook.office.com/x"})
+	require.NoError(t, err)
+	assert.IsType(t, &chat.TeamsWebhook{}, poster)
+
+	_, err = newChatPoster(&models.ChatIntegration{Provider: models.IntegrationProviderSlack, SigningSecret: "secret"})
+	assert.ErrorIs(t, err, ErrInvalidIntegration)
+}
+
+func TestIntegrationResponse(t *testing.T) {
+	userID, dataSourceID := uint(3), uint(7)
+	integration := &models.ChatIntegration{ID: 5, Provider: models.IntegrationProviderSlack, SigningSecret: "secret", BotToken: "xoxb"}
+
+	response := integrationResponse(integration)
+	assert.True(t, response.HasBotToken)
+	assert.True(t, response.HasSigningSecret)
+	assert.False(t, response.HasWebhook)
+	assert.Empty(t, response.CommandPath)
+
+	integration.CommandUserID, integration.CommandDataSourceID = &userID, &dataSourceID
+	assert.Equal(t, "/api/v1/integrations/5/command", integrationResponse(integration).CommandPath)
+}
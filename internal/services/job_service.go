@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/metrics"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultJobWorkers      = 4
+	defaultJobPollInterval = 5 * time.Second
+	defaultJobMaxAttempts  = 5
+	defaultJobPageSize     = 20
+	maxJobPageSize         = 100
+
+	// jobRunTimeout bounds a single run of a job
+	jobRunTimeout = 30 * time.Minute
+	// jobLockTimeout is how long a job can stay running before it is considered
+	// abandoned by a crashed instance and requeued
+	jobLockTimeout = time.Hour
+	// jobRetention is how long succeeded jobs are kept
+	jobRetention           = 7 * 24 * time.Hour
+	jobBaseBackoff         = 30 * time.Second
+	jobMaxBackoff          = time.Hour
+	jobMaintenanceInterval = time.Minute
+)
+
+var (
+	// ErrJobNotFound is returned when the job does not exist
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotRetryable is returned when retrying a job that is not dead
+	ErrJobNotRetryable = errors.New("only dead jobs can be retried")
+)
+
+type jobIDKey struct{}
+
+// JobIDFromContext returns the ID of the job a handler runs, or 0 outside of a job
+func JobIDFromContext(ctx context.Context) uint {
+	id, _ := ctx.Value(jobIDKey{}).(uint)
+	return id
+}
+
+// JobHandler runs a job with its JSON payload. A returned error fails the run;
+// the job is retried with backoff until it runs out of attempts.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// JobOptions configures the job runner
+type JobOptions struct {
+	Workers      int           // Jobs run concurrently by this instance
+	PollInterval time.Duration // How often idle workers look for due jobs
+	MaxAttempts  int           // Runs of a job before it is dead-lettered
+}
+
+// JobService persists background jobs and runs them on a pool of workers.
+// Jobs are claimed with FOR UPDATE SKIP LOCKED, so several instances can
+// share the table.
+type JobService struct {
+	db         *gorm.DB
+	opts       JobOptions
+	instanceID string
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	wake     chan struct{}
+}
+
+// NewJobService creates a new job service
+func NewJobService(db *gorm.DB, instanceID string, opts JobOptions) *JobService {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultJobWorkers
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultJobPollInterval
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultJobMaxAttempts
+	}
+	return &JobService{
+		db:         db,
+		opts:       opts,
+		instanceID: resolveInstanceID(instanceID),
+		handlers:   make(map[string]JobHandler),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler of a job type. Jobs of types without a handler
+// are dead-lettered when they run.
+func (s *JobService) Register(jobType string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+func (s *JobService) handler(jobType string) (JobHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.handlers[jobType]
+	return handler, ok
+}
+
+// Enqueue adds a job that runs as soon as a worker is free. A job enqueued
+// with the context of a tenant runs for that tenant: its handler gets a
+// context carrying the tenant.
+func (s *JobService) Enqueue(ctx context.Context, jobType string, payload interface{}) (*models.Job, error) {
+	return s.enqueue(ctx, jobType, "", payload)
+}
+
+// EnqueueUnique adds a job unless a pending or running job has the same key,
+// in which case that job is returned
+func (s *JobService) EnqueueUnique(ctx context.Context, jobType, key string, payload interface{}) (*models.Job, error) {
+	return s.enqueue(ctx, jobType, key, payload)
+}
+
+func (s *JobService) enqueue(ctx context.Context, jobType, key string, payload interface{}) (*models.Job, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	tenantID, _ := tenancy.FromContext(ctx)
+	job := &models.Job{
+		Type:        jobType,
+		TenantID:    tenantID,
+		Payload:     models.JSON(payloadJSON),
+		Key:         key,
+		Status:      models.JobStatusPending,
+		MaxAttempts: s.opts.MaxAttempts,
+		RunAt:       time.Now(),
+	}
+
+	// The partial unique index on key only covers pending and running jobs
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// The key is unique across tenants, so the queued job may be of another tenant or of the installation
+		var existing models.Job
+		if err := s.db.WithContext(tenancy.WithTenant(ctx, 0)).Where("key = ? AND status IN ?", key, []models.JobStatus{models.JobStatusPending, models.JobStatusRunning}).
+			First(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to find queued job: %w", err)
+		}
+		return &existing, nil
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Start runs the workers and the maintenance of abandoned and old jobs until
+// the context is cancelled
+func (s *JobService) Start(ctx context.Context) {
+	for i := 0; i < s.opts.Workers; i++ {
+		go s.work(ctx)
+	}
+
+	go func() {
+		ticker := time.NewTicker(jobMaintenanceInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.maintain(ctx)
+			}
+		}
+	}()
+
+	logger.FromContext(ctx).Info().Int("workers", s.opts.Workers).Str("instance", s.instanceID).Msg("Job runner started")
+}
+
+// Schedule enqueues a job of the type every interval until the context is
+// cancelled. The job type is the key, so runs do not pile up: nothing is
+// enqueued while a job of the type is pending or running on any instance. A
+// non-positive interval disables it.
+func (s *JobService) Schedule(ctx context.Context, jobType string, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.EnqueueUnique(ctx, jobType, jobType, nil); err != nil {
+					logger.FromContext(ctx).Error().Err(err).Str("job_type", jobType).Msg("Failed to enqueue scheduled job")
+				}
+			}
+		}
+	}()
+}
+
+// work claims and runs due jobs until none are left, then waits for the next
+// poll or an enqueue on this instance
+func (s *JobService) work(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := s.claim(ctx)
+			if err != nil {
+				logger.FromContext(ctx).Error().Err(err).Msg("Failed to claim job")
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// claim locks the next due job for this instance, or returns nil when there is none
+func (s *JobService) claim(ctx context.Context) (*models.Job, error) {
+	now := time.Now()
+	var jobs []models.Job
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, locked_by = ?, locked_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs WHERE status = ? AND run_at <= ?
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.JobStatusRunning, s.instanceID, now, now,
+		models.JobStatusPending, now,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// run executes a claimed job and records its outcome
+func (s *JobService) run(ctx context.Context, job *models.Job) {
+	l := logger.FromContext(ctx).With().Uint("job_id", job.ID).Str("job_type", job.Type).Int("attempt", job.Attempts).Logger()
+	runCtx := context.WithValue(l.WithContext(ctx), jobIDKey{}, job.ID)
+	if job.TenantID != 0 {
+		runCtx = tenancy.WithTenant(runCtx, job.TenantID)
+	}
+	runCtx, cancel := context.WithTimeout(runCtx, jobRunTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.execute(runCtx, job)
+	metrics.ObserveJob(job.Type, start, err)
+
+	now := time.Now()
+	if err == nil {
+		job.Status = models.JobStatusSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+	} else {
+		markJobFailed(job, err, now)
+	}
+	job.LockedBy = ""
+	job.LockedAt = nil
+
+	if err := s.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":      job.Status,
+		"run_at":      job.RunAt,
+		"last_error":  job.LastError,
+		"finished_at": job.FinishedAt,
+		"locked_by":   job.LockedBy,
+		"locked_at":   job.LockedAt,
+	}).Error; err != nil {
+		l.Error().Err(err).Msg("Failed to record job outcome")
+		return
+	}
+
+	switch job.Status {
+	case models.JobStatusSucceeded:
+		l.Info().Dur("duration", now.Sub(start)).Msg("Job succeeded")
+	case models.JobStatusDead:
+		l.Error().Err(err).Msg("Job failed and was dead-lettered")
+	default:
+		l.Warn().Err(err).Time("retry_at", job.RunAt).Msg("Job failed, retrying")
+	}
+}
+
+// execute runs the handler of the job, turning a panic into an error
+func (s *JobService) execute(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := s.handler(job.Type)
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, json.RawMessage(job.Payload))
+}
+
+// markJobFailed schedules a retry of a failed run, or dead-letters the job
+// when it is out of attempts
+func markJobFailed(job *models.Job, err error, now time.Time) {
+	job.LastError = err.Error()
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = models.JobStatusDead
+		job.FinishedAt = &now
+		return
+	}
+	job.Status = models.JobStatusPending
+	job.RunAt = now.Add(jobBackoff(job.Attempts))
+}
+
+// jobBackoff returns the delay before retrying a job that failed its attempt,
+// doubling from jobBaseBackoff up to jobMaxBackoff
+func jobBackoff(attempt int) time.Duration {
+	backoff := jobBaseBackoff
+	for i := 1; i < attempt && backoff < jobMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > jobMaxBackoff {
+		backoff = jobMaxBackoff
+	}
+	return backoff
+}
+
+// maintain requeues jobs abandoned by crashed instances, dead-letters the
+// ones out of attempts, and prunes old succeeded jobs
+func (s *JobService) maintain(ctx context.Context) {
+	staleBefore := time.Now().Add(-jobLockTimeout)
+	stale := s.db.WithContext(ctx).Model(&models.Job{}).Where("status = ? AND locked_at < ?", models.JobStatusRunning, staleBefore)
+
+	if err := stale.Session(&gorm.Session{}).Where("attempts >= max_attempts").Updates(map[string]interface{}{
+		"status":      models.JobStatusDead,
+		"last_error":  "job was abandoned while running",
+		"finished_at": time.Now(),
+		"locked_by":   "",
+		"locked_at":   nil,
+	}).Error; err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to dead-letter abandoned jobs")
+	}
+	if err := stale.Session(&gorm.Session{}).Updates(map[string]interface{}{
+		"status":    models.JobStatusPending,
+		"run_at":    time.Now(),
+		"locked_by": "",
+		"locked_at": nil,
+	}).Error; err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to requeue abandoned jobs")
+	}
+
+	if err := s.db.WithContext(ctx).Where("status = ? AND finished_at < ?", models.JobStatusSucceeded, time.Now().Add(-jobRetention)).
+		Delete(&models.Job{}).Error; err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to prune succeeded jobs")
+	}
+}
+
+// ListJobs returns the jobs matching the filter, newest first, with the total count
+func (s *JobService) ListJobs(filter *models.JobListFilter) ([]models.Job, int64, error) {
+	normalizeJobListFilter(filter)
+
+	query := s.db.Model(&models.Job{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	var jobs []models.Job
+	if err := query.Order("id DESC").Limit(filter.Limit).Offset((filter.Page - 1) * filter.Limit).Find(&jobs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, total, nil
+}
+
+func normalizeJobListFilter(filter *models.JobListFilter) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultJobPageSize
+	}
+	if filter.Limit > maxJobPageSize {
+		filter.Limit = maxJobPageSize
+	}
+}
+
+// GetJob returns a job by ID
+func (s *JobService) GetJob(id uint) (*models.Job, error) {
+	var job models.Job
+	if err := s.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetStats counts the jobs in each status
+func (s *JobService) GetStats() (*models.JobStats, error) {
+	var rows []struct {
+		Status models.JobStatus
+		Count  int64
+	}
+	if err := s.db.Model(&models.Job{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	stats := &models.JobStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case models.JobStatusPending:
+			stats.Pending = row.Count
+		case models.JobStatusRunning:
+			stats.Running = row.Count
+		case models.JobStatusSucceeded:
+			stats.Succeeded = row.Count
+		case models.JobStatusDead:
+			stats.Dead = row.Count
+		}
+	}
+	return stats, nil
+}
+
+// RetryJob moves a dead job back to the queue with a fresh set of attempts
+func (s *JobService) RetryJob(id uint) (*models.Job, error) {
+	job, err := s.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobStatusDead {
+		return nil, ErrJobNotRetryable
+	}
+
+	job.Status = models.JobStatusPending
+	job.Attempts = 0
+	job.RunAt = time.Now()
+	job.FinishedAt = nil
+	result := s.db.Model(&models.Job{}).Where("id = ? AND status = ?", id, models.JobStatusDead).Updates(map[string]interface{}{
+		"status":      job.Status,
+		"attempts":    job.Attempts,
+		"run_at":      job.RunAt,
+		"finished_at": nil,
+	})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retry job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobNotRetryable
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestJobBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, jobBackoff(0))
+	assert.Equal(t, 30*time.Second, jobBackoff(1))
+	assert.Equal(t, time.Minute, jobBackoff(2))
+	assert.Equal(t, 4*time.Minute, jobBackoff(4))
+	assert.Equal(t, time.Hour, jobBackoff(8))
+	assert.Equal(t, time.Hour, jobBackoff(100))
+}
+
+func TestMarkJobFailedRetries(t *testing.T) {
+	now := time.Date(2025, 9, 21, 9, 0, 0, 0, time.UTC)
+	job := &models.Job{Status: models.JobStatusRunning, Attempts: 2, MaxAttempts: 5}
+
+	markJobFailed(job, errors.New("connection refused"), now)
+
+	assert.Equal(t, models.JobStatusPending, job.Status)
+	assert.Equal(t, now.Add(time.Minute), job.RunAt)
+	assert.Equal(t, "connection refused", job.LastError)
+	assert.Nil(t, job.FinishedAt)
+}
+
+func TestMarkJobFailedDeadLetters(t *testing.T) {
+	now := time.Date(2025, 9, 21, 9, 0, 0, 0, time.UTC)
+	job := &models.Job{Status: models.JobStatusRunning, Attempts: 5, MaxAttempts: 5}
+
+	markJobFailed(job, errors.New("connection refused"), now)
+
+	assert.Equal(t, models.JobStatusDead, job.Status)
+	require.NotNil(t, job.FinishedAt)
+	assert.Equal(t, now, *job.FinishedAt)
+}
+
+func TestJobExecuteRecoversPanics(t *testing.T) {
+	jobs := NewJobService(nil, "test", JobOptions{})
+	jobs.Register("panics", func(ctx context.Context, payload json.RawMessage) error {
+		panic("boom")
+	})
+
+	err := jobs.execute(context.Background(), &models.Job{Type: "panics"})
+	assert.EqualError(t, err, "job panicked: boom")
+
+	err = jobs.execute(context.Background(), &models.Job{Type: "unknown"})
+	assert.EqualError(t, err, `no handler registered for job type "unknown"`)
+}
+
+func TestNormalizeJobListFilter(t *testing.T) {
+	filter := &models.JobListFilter{Page: 0, Limit: 1000}
+	normalizeJobListFilter(filter)
+	assert.Equal(t, 1, filter.Page)
+	assert.Equal(t, maxJobPageSize, filter.Limit)
+
+	filter = &models.JobListFilter{Page: 3}
+	normalizeJobListFilter(filter)
+	assert.Equal(t, 3, filter.Page)
+	assert.Equal(t, defaultJobPageSize, filter.Limit)
+}
+
+func TestJobRunsInTenantOfJob(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Job{}))
+	jobs := NewJobService(db, "test", JobOptions{})
+
+	var tenants []uint
+	jobs.Register("tenant", func(ctx context.Context, payload json.RawMessage) error {
+		tenantID, _ := tenancy.FromContext(ctx)
+		tenants = append(tenants, tenantID)
+		return nil
+	})
+
+	// A job queued in a tenant records it and runs in it; other jobs run for the installation
+	queued, err := jobs.Enqueue(tenancy.WithTenant(context.Background(), 2), "tenant", nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), queued.TenantID)
+	jobs.run(context.Background(), queued)
+	jobs.run(context.Background(), &models.Job{ID: 2, Type: "tenant", MaxAttempts: 1})
+	assert.Equal(t, []uint{2, 0}, tenants)
+}
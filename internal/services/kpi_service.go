@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrKPINotFound is returned when a KPI does not exist or is not active for the user
+	ErrKPINotFound = errors.New("KPI not found")
+	// ErrInvalidKPICompute is returned when a KPI cannot be computed as requested
+	ErrInvalidKPICompute = errors.New("invalid KPI computation")
+)
+
+// kpiGrains maps the grains a KPI can be computed at to time grains
+var kpiGrains = map[string]models.TimeGrain{
+	"daily":   models.TimeGrainDay,
+	"weekly":  models.TimeGrainWeek,
+	"monthly": models.TimeGrainMonth,
+}
+
+// maxKPIComputeDays bounds the range of a computation, so a daily series
+// stays within the row limit
+const maxKPIComputeDays = 1000
+
+// KPIService computes KPI definitions on their data source, so dashboards can
+// show KPI cards without going through NL2SQL. Formulas are aggregate
+// expressions over a table, computed for a date range on its time column.
+type KPIService struct {
+	db            *gorm.DB
+	nl2sqlService *NL2SQLService
+	now           func() time.Time
+}
+
+// NewKPIService creates a new KPI service
+func NewKPIService(db *gorm.DB, nl2sqlService *NL2SQLService) *KPIService {
+	return &KPIService{
+		db:            db,
+		nl2sqlService: nl2sqlService,
+		now:           time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *KPIService) WithContext(ctx context.Context) *KPIService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sqlService = s.nl2sqlService.WithContext(ctx)
+	return &scoped
+}
+
+// Compute runs the formula of a KPI of the user for a date range, with a
+// series per grain and optionally the prior period of the same length
+func (s *KPIService) Compute(userID, kpiID uint, req *models.KPIComputeRequest) (*models.KPIComputeResponse, error) {
+	var kpi models.KPIDefinition
+	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", kpiID, userID, true).First(&kpi).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKPINotFound
+		}
+		return nil, fmt.Errorf("failed to get KPI: %w", err)
+	}
+
+	formula := strings.TrimSpace(kpi.Formula)
+	if formula == "" || strings.HasPrefix(strings.ToUpper(formula), "SELECT") || strings.HasPrefix(strings.ToUpper(formula), "WITH") {
+		return nil, fmt.Errorf("%w: the formula must be an aggregate expression such as SUM(amount), not a query", ErrInvalidKPICompute)
+	}
+
+	dataSourceID := req.DataSourceID
+	if dataSourceID == 0 && kpi.DataSourceID != nil {
+		dataSourceID = *kpi.DataSourceID
+	}
+	table := firstNonEmpty(req.SourceTable, kpi.SourceTable)
+	timeColumn := firstNonEmpty(req.TimeColumn, kpi.TimeColumn)
+	if dataSourceID == 0 || table == "" || timeColumn == "" {
+		return nil, fmt.Errorf("%w: data_source_id, source_table and time_column are required on the KPI or the request", ErrInvalidKPICompute)
+	}
+
+	dataSource, err := s.nl2sqlService.validateDataSourceAccess(userID, dataSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKPICompute, err)
+	}
+	if dataSource.Type.UsesAggregationPipeline() {
+		return nil, fmt.Errorf("%w: KPIs cannot be computed on aggregation pipeline data sources", ErrInvalidKPICompute)
+	}
+
+	from, to, err := kpiComputeRange(req.From, req.To, s.now())
+	if err != nil {
+		return nil, err
+	}
+	grain := req.Grain
+	if grain == "" {
+		grain = strings.ToLower(kpi.Grain)
+	}
+	if _, ok := kpiGrains[grain]; !ok {
+		grain = ""
+	}
+
+	var filters map[string]interface{}
+	if len(kpi.Filters) > 0 {
+		_ = json.Unmarshal(kpi.Filters, &filters)
+	}
+	query := kpiQuery{
+		formula:    formula,
+		table:      table,
+		timeColumn: timeColumn,
+		filters:    filters,
+		dialect:    models.DialectForDataSourceType(dataSource.Type),
+	}
+
+	response := &models.KPIComputeResponse{
+		KPIID:        kpi.ID,
+		Name:         kpi.Name,
+		DisplayName:  kpi.DisplayName,
+		Unit:         kpi.Unit,
+		DataSourceID: dataSource.ID,
+		Grain:        grain,
+	}
+	current, err := s.computePeriod(userID, dataSource, &query, from, to, grain)
+	if err != nil {
+		return nil, err
+	}
+	response.Current = *current
+
+	if req.Compare {
+		previousFrom, previousTo := priorKPIPeriod(from, to)
+		previous, err := s.computePeriod(userID, dataSource, &query, previousFrom, previousTo, grain)
+		if err != nil {
+			return nil, err
+		}
+		response.Previous = previous
+		response.Change, response.PercentChange = kpiChange(current.Value, previous.Value)
+	}
+	return response, nil
+}
+
+// computePeriod computes the total of a date range and its series per grain
+func (s *KPIService) computePeriod(userID uint, dataSource *models.DataSource, query *kpiQuery, from, to time.Time, grain string) (*models.KPIPeriodValue, error) {
+	period := &models.KPIPeriodValue{From: from.Format("2006-01-02"), To: to.Format("2006-01-02")}
+
+	// Ratios and distinct counts do not add up, so the total is its own query
+	rows, err := s.run(userID, dataSource, query.sql(from, to, ""))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 {
+		if value, ok := answerNumber(rows[0]["value"]); ok {
+			period.Value = &value
+		}
+	}
+
+	if grain == "" {
+		return period, nil
+	}
+	rows, err = s.run(userID, dataSource, query.sql(from, to, grain))
+	if err != nil {
+		return nil, err
+	}
+	period.Series = make([]models.KPISeriesPoint, 0, len(rows))
+	for _, row := range rows {
+		point := models.KPISeriesPoint{Period: formatKPIPeriod(row["period"])}
+		if value, ok := answerNumber(row["value"]); ok {
+			point.Value = &value
+		}
+		period.Series = append(period.Series, point)
+	}
+	return period, nil
+}
+
+// run validates a KPI query against the data source policy and cost ceiling and executes it
+func (s *KPIService) run(userID uint, dataSource *models.DataSource, sql string) ([]map[string]interface{}, error) {
+	sql, validation, err := s.nl2sqlService.prepareQuery(dataSource, sql)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKPICompute, err)
+	}
+	if !s.nl2sqlService.sqlValidator.IsQuerySafe(validation) {
+		return nil, fmt.Errorf("%w: KPI query failed safety validation: %s", ErrInvalidKPICompute, strings.Join(validation.Violations, "; "))
+	}
+	if _, err := s.nl2sqlService.checkQueryCost(userID, dataSource, sql); err != nil {
+		return nil, err
+	}
+	result, err := s.nl2sqlService.executeQueryOnDataSource(dataSource, sql, maxKPIComputeDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute KPI: %w", err)
+	}
+	return result.Data, nil
+}
+
+// kpiQuery builds the SQL computing a KPI formula over a date range
+type kpiQuery struct {
+	formula    string
+	table      string
+	timeColumn string
+	filters    map[string]interface{} // Default filters of the KPI, column = value
+	dialect    models.SQLDialect
+}
+
+// sql returns the query of the total of a range, or of its series per grain.
+// Dates compare on DATE values, so date and timestamp columns both work.
+func (q *kpiQuery) sql(from, to time.Time, grain string) string {
+	column := quoteIdentifier(q.timeColumn, q.dialect)
+	day := column
+	if q.dialect == models.SQLDialectBigQuery {
+		day = "DATE(" + column + ")"
+	}
+
+	conditions := []string{
+		fmt.Sprintf("%s >= CAST('%s' AS DATE)", day, from.Format("2006-01-02")),
+		fmt.Sprintf("%s < CAST('%s' AS DATE)", day, to.AddDate(0, 0, 1).Format("2006-01-02")),
+	}
+	conditions = append(conditions, kpiFilterConditions(q.filters, q.dialect)...)
+	where := " FROM " + quoteQualifiedName(q.table, q.dialect) + " WHERE " + strings.Join(conditions, " AND ")
+
+	if grain == "" {
+		return "SELECT " + q.formula + " AS value" + where
+	}
+	period := truncateToGrain(column, kpiGrains[grain], q.dialect)
+	return "SELECT " + period + " AS period, " + q.formula + " AS value" + where +
+		" GROUP BY " + period + " ORDER BY " + period
+}
+
+// kpiFilterConditions renders the default filters of a KPI as equality
+// conditions, or IN lists for arrays, in column order
+func kpiFilterConditions(filters map[string]interface{}, dialect models.SQLDialect) []string {
+	columns := make([]string, 0, len(filters))
+	for column := range filters {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	conditions := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted := quoteIdentifier(column, dialect)
+		switch value := filters[column].(type) {
+		case nil:
+			conditions = append(conditions, quoted+" IS NULL")
+		case []interface{}:
+			literals := make([]string, 0, len(value))
+			for _, item := range value {
+				literals = append(literals, kpiLiteral(item, dialect))
+			}
+			if len(literals) > 0 {
+				conditions = append(conditions, quoted+" IN ("+strings.Join(literals, ", ")+")")
+			}
+		default:
+			conditions = append(conditions, quoted+" = "+kpiLiteral(value, dialect))
+		}
+	}
+	return conditions
+}
+
+// kpiLiteral renders a JSON filter value as a SQL literal
+func kpiLiteral(value interface{}, dialect models.SQLDialect) string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float64:
+		return fmt.Sprintf("%v", v)
+	case nil:
+		return "NULL"
+	default:
+		return quoteStringLiteral(fmt.Sprint(v), dialect)
+	}
+}
+
+// kpiComputeRange parses the inclusive date range of a computation; to
+// defaults to today and from to the 30 days ending on to
+func kpiComputeRange(fromValue, toValue string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toValue = strings.TrimSpace(toValue); toValue != "" {
+		parsed, err := time.Parse("2006-01-02", toValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be a YYYY-MM-DD date", ErrInvalidKPICompute)
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -29)
+	if fromValue = strings.TrimSpace(fromValue); fromValue != "" {
+		parsed, err := time.Parse("2006-01-02", fromValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be a YYYY-MM-DD date", ErrInvalidKPICompute)
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must not be after to", ErrInvalidKPICompute)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxKPIComputeDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the range spans %d days, more than %d", ErrInvalidKPICompute, days, maxKPIComputeDays)
+	}
+	return from, to, nil
+}
+
+// priorKPIPeriod returns the range of the same length ending the day before from
+func priorKPIPeriod(from, to time.Time) (time.Time, time.Time) {
+	days := int(to.Sub(from).Hours()/24) + 1
+	return from.AddDate(0, 0, -days), from.AddDate(0, 0, -1)
+}
+
+// kpiChange returns the absolute and percent change from the previous value;
+// the percent change is undefined when the previous value is zero
+func kpiChange(current, previous *float64) (*float64, *float64) {
+	if current == nil || previous == nil {
+		return nil, nil
+	}
+	change := *current - *previous
+	if *previous == 0 {
+		return &change, nil
+	}
+	percent := change / math.Abs(*previous) * 100
+	return &change, &percent
+}
+
+// formatKPIPeriod renders the start of a series period as a date
+func formatKPIPeriod(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format("2006-01-02")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// firstNonEmpty returns the first value that is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
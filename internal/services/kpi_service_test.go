@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKPIComputeRange(t *testing.T) {
+	now := time.Date(2025, 3, 15, 18, 30, 0, 0, time.UTC)
+
+	from, to, err := kpiComputeRange("", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-02-14", from.Format("2006-01-02"), "defaults to the 30 days ending today")
+	assert.Equal(t, "2025-03-15", to.Format("2006-01-02"))
+
+	from, to, err = kpiComputeRange("2025-01-01", "2025-01-31", now)
+	require.NoError(t, err)
+	previousFrom, previousTo := priorKPIPeriod(from, to)
+	assert.Equal(t, "2024-12-01", previousFrom.Format("2006-01-02"))
+	assert.Equal(t, "2024-12-31", previousTo.Format("2006-01-02"))
+
+	for _, tc := range [][2]string{{"2025-02-01", "2025-01-01"}, {"2025/01/01", ""}, {"2020-01-01", "2025-01-01"}} {
+		_, _, err := kpiComputeRange(tc[0], tc[1], now)
+		assert.True(t, errors.Is(err, ErrInvalidKPICompute), tc)
+	}
+}
+
+func TestKPIQuerySQL(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	query := kpiQuery{
+		formula:    "SUM(amount)",
+		table:      "sales.orders",
+		timeColumn: "created_at",
+		filters:    map[string]interface{}{"status": "paid", "channel": []interface{}{"web", "app"}},
+		dialect:    models.SQLDialectPostgreSQL,
+	}
+
+	assert.Equal(t, `SELECT SUM(amount) AS value FROM "sales"."orders"`+
+		` WHERE "created_at" >= CAST('2025-01-01' AS DATE) AND "created_at" < CAST('2025-02-01' AS DATE)`+
+		` AND "channel" IN ('web', 'app') AND "status" = 'paid'`, query.sql(from, to, ""))
+
+	query.filters = nil
+	query.dialect = models.SQLDialectBigQuery
+	assert.Equal(t, "SELECT DATE_TRUNC(DATE(`created_at`), WEEK) AS period, SUM(amount) AS value FROM `sales`.`orders`"+
+		" WHERE DATE(`created_at`) >= CAST('2025-01-01' AS DATE) AND DATE(`created_at`) < CAST('2025-02-01' AS DATE)"+
+		" GROUP BY DATE_TRUNC(DATE(`created_at`), WEEK) ORDER BY DATE_TRUNC(DATE(`created_at`), WEEK)", query.sql(from, to, "weekly"))
+}
+
+func TestKPIChange(t *testing.T) {
+	current, previous, zero := 150.0, 120.0, 0.0
+
+	change, percent := kpiChange(&current, &previous)
+	require.NotNil(t, change)
+	require.NotNil(t, percent)
+	assert.Equal(t, 30.0, *change)
+	assert.InDelta(t, 25.0, *percent, 1e-9)
+
+	change, percent = kpiChange(&current, &zero)
+	assert.Equal(t, 150.0, *change)
+	assert.Nil(t, percent, "no percent change from zero")
+
+	change, percent = kpiChange(&current, nil)
+	assert.Nil(t, change)
+	assert.Nil(t, percent)
+}
@@ -0,0 +1,569 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"narapulse-be/internal/connectors"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrMaterializationDataSourceNotFound is returned when the data source does not exist or belongs to another user
+	ErrMaterializationDataSourceNotFound = errors.New("data source not found")
+	// ErrNotMaterialized is returned for data sources that are queried live rather than through a copy
+	ErrNotMaterialized = errors.New("data source is not materialized")
+)
+
+const (
+	// defaultMaterializationInterval is how often a copy is refreshed unless refresh_interval_minutes is set
+	defaultMaterializationInterval = time.Hour
+	// materializationStaleFactor is how many refresh intervals a copy may be behind before it is stale
+	materializationStaleFactor = 2
+)
+
+var materializedTableNamePattern = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// MaterializationService keeps local copies of the tables of slow data
+// sources, Google Sheets and REST APIs, as JSON lines the DuckDB file engine
+// queries instead of the source. Copies are refreshed on a schedule. A REST
+// API with an incremental column only pulls the records past the watermark
+// of the last pull and merges them into the copy; Sheets are pulled in full.
+type MaterializationService struct {
+	db           *gorm.DB
+	connectorSvc *connectorService
+	jobs         *JobService
+	dir          string // Directory of the materialized files
+	now          func() time.Time
+}
+
+// NewMaterializationService creates a new materialization service writing copies to dir
+func NewMaterializationService(db *gorm.DB, connectorSvc *connectorService, jobs *JobService, dir string) *MaterializationService {
+	return &MaterializationService{
+		db:           db,
+		connectorSvc: connectorSvc,
+		jobs:         jobs,
+		dir:          dir,
+		now:          time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *MaterializationService) WithContext(ctx context.Context) *MaterializationService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the job that refreshes the copies that are due, which
+// is scheduled at the materialization sync interval, and the job that
+// refreshes one data source on demand
+func (s *MaterializationService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeMaterializationSync, func(ctx context.Context, _ json.RawMessage) error {
+		refreshed, failed, err := s.SyncDue(ctx)
+		if err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info().Int("refreshed", refreshed).Int("failed", failed).Msg("Materialization sync completed")
+		return nil
+	})
+	jobs.Register(models.JobTypeMaterializationRefresh, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.MaterializationJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		var dataSource models.DataSource
+		if err := s.db.First(&dataSource, p.DataSourceID).Error; err != nil {
+			// Deleted since the job was queued
+			logger.FromContext(ctx).Info().Uint("data_source_id", p.DataSourceID).Msg("Data source no longer exists, skipping materialization")
+			return nil
+		}
+		return s.WithContext(tenancy.WithTenant(ctx, dataSource.TenantID)).Refresh(&dataSource, p.Full)
+	})
+}
+
+// Materializes reports whether queries on a data source run on a materialized
+// copy: always for REST APIs, and for Google Sheets with materialize set
+func Materializes(dsType models.DataSourceType, config map[string]interface{}) bool {
+	switch dsType {
+	case models.DataSourceTypeRESTAPI:
+		return true
+	case models.DataSourceTypeGoogleSheets:
+		materialize, _ := config["materialize"].(bool)
+		return materialize
+	}
+	return false
+}
+
+// SyncDue refreshes the copies of the active data sources whose refresh
+// interval has passed. A failed refresh keeps the previous copy and is
+// recorded on its tables.
+func (s *MaterializationService) SyncDue(ctx context.Context) (int, int, error) {
+	var dataSources []models.DataSource
+	if err := s.db.Where("type IN ? AND status = ?", []models.DataSourceType{models.DataSourceTypeRESTAPI, models.DataSourceTypeGoogleSheets},
+		models.ConnectionStatusActive).Order("id").Find(&dataSources).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to list data sources: %w", err)
+	}
+
+	refreshed, failed := 0, 0
+	now := s.now()
+	for i := range dataSources {
+		if ctx.Err() != nil {
+			break
+		}
+		dataSource := &dataSources[i]
+		var config map[string]interface{}
+		if err := json.Unmarshal(dataSource.Config, &config); err != nil || !Materializes(dataSource.Type, config) {
+			continue
+		}
+		tables, err := s.tables(dataSource.ID)
+		if err != nil {
+			return refreshed, failed, err
+		}
+		if !materializationDue(tables, config, now) {
+			continue
+		}
+
+		if err := s.WithContext(tenancy.WithTenant(ctx, dataSource.TenantID)).Refresh(dataSource, false); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to refresh materialized data")
+			failed++
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, failed, nil
+}
+
+// Refresh pulls the records of a data source into its copy, incrementally
+// when possible unless full is set
+func (s *MaterializationService) Refresh(dataSource *models.DataSource, full bool) error {
+	var config map[string]interface{}
+	if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+		return fmt.Errorf("invalid data source configuration: %w", err)
+	}
+	if !Materializes(dataSource.Type, config) {
+		return ErrNotMaterialized
+	}
+
+	if dataSource.Type == models.DataSourceTypeGoogleSheets {
+		return s.refreshGoogleSheets(dataSource, config)
+	}
+	return s.refreshRESTAPI(dataSource, config, full)
+}
+
+// refreshRESTAPI pulls the records of a REST API. With an incremental column
+// and a previous copy, the watermark is sent as incremental_param, records at
+// or before it are dropped and the rest replace the records with the same
+// primary key, or are appended.
+func (s *MaterializationService) refreshRESTAPI(dataSource *models.DataSource, config map[string]interface{}, full bool) error {
+	table := connectors.RESTTableName(config)
+	path := s.FilePath(dataSource, table)
+	column, _ := config["incremental_column"].(string)
+
+	var previous models.DataSourceMaterialization
+	err := s.db.Where("data_source_id = ? AND table_name = ?", dataSource.ID, table).First(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get materialization: %w", err)
+	}
+	incremental := !full && column != "" && previous.Watermark != "" && previous.WatermarkColumn == column
+	if _, err := os.Stat(path); err != nil {
+		incremental = false
+	}
+
+	fetchConfig := make(map[string]interface{}, len(config)+1)
+	for key, value := range config {
+		fetchConfig[key] = value
+	}
+	if incremental {
+		fetchConfig["incremental_since"] = previous.Watermark
+	}
+	records, err := s.connectorSvc.FetchRESTAPIRecords(fetchConfig)
+	if err != nil {
+		s.recordFailure(dataSource.ID, table, path, err)
+		return err
+	}
+
+	if !incremental {
+		return s.Store(dataSource.ID, table, path, column, records, models.MaterializationModeFull)
+	}
+
+	existing, err := readJSONLines(path)
+	if err != nil {
+		s.recordFailure(dataSource.ID, table, path, err)
+		return fmt.Errorf("failed to read materialized records: %w", err)
+	}
+	primaryKey, _ := config["primary_key"].(string)
+	merged := mergeRecords(existing, recordsAfter(records, column, previous.Watermark), primaryKey)
+	return s.Store(dataSource.ID, table, path, column, merged, models.MaterializationModeIncremental)
+}
+
+// refreshGoogleSheets pulls every sheet of a spreadsheet in full and drops the
+// copies of sheets that were removed
+func (s *MaterializationService) refreshGoogleSheets(dataSource *models.DataSource, config map[string]interface{}) error {
+	tables, err := s.connectorSvc.FetchGoogleSheetsRecords(config)
+	if err != nil {
+		var previous []models.DataSourceMaterialization
+		s.db.Where("data_source_id = ?", dataSource.ID).Find(&previous)
+		for _, table := range previous {
+			s.recordFailure(dataSource.ID, table.Table, table.FilePath, err)
+		}
+		return err
+	}
+
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if err := s.Store(dataSource.ID, table.Table, s.FilePath(dataSource, table.Table), "", table.Records, models.MaterializationModeFull); err != nil {
+			return err
+		}
+		names = append(names, table.Table)
+	}
+
+	var removed []models.DataSourceMaterialization
+	query := s.db.Where("data_source_id = ?", dataSource.ID)
+	if len(names) > 0 {
+		query = query.Where("table_name NOT IN ?", names)
+	}
+	if err := query.Find(&removed).Error; err != nil {
+		return fmt.Errorf("failed to list removed sheets: %w", err)
+	}
+	for _, table := range removed {
+		os.Remove(table.FilePath)
+		if err := s.db.Delete(&table).Error; err != nil {
+			return fmt.Errorf("failed to delete materialization of %s: %w", table.Table, err)
+		}
+	}
+	return nil
+}
+
+// Store writes the records of a table to its copy and records the refresh,
+// with the highest value of the incremental column as the watermark
+func (s *MaterializationService) Store(dataSourceID uint, table, path, column string, records []map[string]interface{}, mode models.MaterializationMode) error {
+	if err := writeJSONLines(path, records); err != nil {
+		s.recordFailure(dataSourceID, table, path, err)
+		return fmt.Errorf("failed to materialize records: %w", err)
+	}
+
+	now := s.now()
+	materialization := &models.DataSourceMaterialization{
+		DataSourceID:    dataSourceID,
+		Table:           table,
+		FilePath:        path,
+		Mode:            mode,
+		WatermarkColumn: column,
+		Watermark:       maxWatermark(records, column),
+		RowCount:        int64(len(records)),
+		Status:          models.MaterializationStatusCompleted,
+		SyncedAt:        &now,
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "data_source_id"}, {Name: "table_name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"file_path":        materialization.FilePath,
+			"mode":             materialization.Mode,
+			"watermark_column": materialization.WatermarkColumn,
+			"watermark":        materialization.Watermark,
+			"row_count":        materialization.RowCount,
+			"status":           materialization.Status,
+			"error":            "",
+			"synced_at":        now,
+			"updated_at":       now,
+		}),
+	}).Create(materialization).Error
+	if err != nil {
+		return fmt.Errorf("failed to record materialization: %w", err)
+	}
+	return nil
+}
+
+// recordFailure marks the copy of a table as failed, keeping the previous copy and watermark
+func (s *MaterializationService) recordFailure(dataSourceID uint, table, path string, cause error) {
+	materialization := &models.DataSourceMaterialization{
+		DataSourceID: dataSourceID,
+		Table:        table,
+		FilePath:     path,
+		Status:       models.MaterializationStatusFailed,
+		Error:        cause.Error(),
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "data_source_id"}, {Name: "table_name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":     materialization.Status,
+			"error":      materialization.Error,
+			"updated_at": s.now(),
+		}),
+	}).Create(materialization).Error
+	if err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSourceID).Str("table", table).Msg("Failed to record materialization failure")
+	}
+}
+
+// List returns the materialized tables of a data source of the user with their freshness
+func (s *MaterializationService) List(userID, dataSourceID uint) (*models.DataSourceMaterializationsResponse, error) {
+	dataSource, err := s.owned(userID, dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := s.tables(dataSource.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DataSourceMaterializationsResponse{
+		Tables:    tables,
+		Freshness: s.Freshness(dataSource),
+	}, nil
+}
+
+// QueueRefresh queues a refresh of the copy of a data source of the user
+func (s *MaterializationService) QueueRefresh(ctx context.Context, userID, dataSourceID uint, full bool) (*models.DataSourceMaterializationsResponse, error) {
+	response, err := s.List(userID, dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+	if response.Freshness == nil {
+		return nil, ErrNotMaterialized
+	}
+
+	payload := models.MaterializationJobPayload{DataSourceID: dataSourceID, Full: full}
+	job, err := s.jobs.EnqueueUnique(ctx, models.JobTypeMaterializationRefresh, fmt.Sprintf("materialization:%d", dataSourceID), payload)
+	if err != nil {
+		return nil, err
+	}
+	response.JobID = job.ID
+	return response, nil
+}
+
+// Freshness returns how current the copy a data source is queried through
+// is, or nil when it is queried live
+func (s *MaterializationService) Freshness(dataSource *models.DataSource) *models.DataFreshness {
+	var config map[string]interface{}
+	if err := json.Unmarshal(dataSource.Config, &config); err != nil || !Materializes(dataSource.Type, config) {
+		return nil
+	}
+	tables, err := s.tables(dataSource.ID)
+	if err != nil {
+		logger.L().Warn().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to get materializations")
+		return nil
+	}
+	return dataFreshness(tables, config, s.now())
+}
+
+// FilePath returns the file the copy of a table of a data source is written to
+func (s *MaterializationService) FilePath(dataSource *models.DataSource, table string) string {
+	if dataSource.Type == models.DataSourceTypeRESTAPI {
+		// Where discovery first materializes the records
+		return filepath.Join(s.dir, fmt.Sprintf("rest_api_%d.jsonl", dataSource.ID))
+	}
+	name := strings.Trim(materializedTableNamePattern.ReplaceAllString(strings.ToLower(table), "_"), "_")
+	return filepath.Join(s.dir, fmt.Sprintf("%s_%d_%s.jsonl", dataSource.Type, dataSource.ID, name))
+}
+
+func (s *MaterializationService) tables(dataSourceID uint) ([]models.DataSourceMaterialization, error) {
+	tables := []models.DataSourceMaterialization{}
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("table_name").Find(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to get materializations: %w", err)
+	}
+	return tables, nil
+}
+
+func (s *MaterializationService) owned(userID, dataSourceID uint) (*models.DataSource, error) {
+	var dataSource models.DataSource
+	if err := s.db.Where("id = ? AND user_id = ?", dataSourceID, userID).First(&dataSource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMaterializationDataSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+	return &dataSource, nil
+}
+
+// validateMaterializationConfig checks the refresh settings of a materialized
+// data source: incremental pulls need the column the watermark is read from
+func validateMaterializationConfig(config map[string]interface{}) error {
+	if minutes, ok := config["refresh_interval_minutes"]; ok {
+		if value, isNumber := minutes.(float64); !isNumber || value <= 0 {
+			return fmt.Errorf("refresh_interval_minutes must be a positive number")
+		}
+	}
+	if _, ok := config["incremental_param"]; ok {
+		if column, _ := config["incremental_column"].(string); column == "" {
+			return fmt.Errorf("incremental_column is required with incremental_param")
+		}
+	}
+	return nil
+}
+
+// materializationInterval is the refresh interval of a data source
+func materializationInterval(config map[string]interface{}) time.Duration {
+	if minutes, ok := config["refresh_interval_minutes"].(float64); ok && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultMaterializationInterval
+}
+
+// lastMaterialized returns the oldest refresh among the tables, falling back
+// to the materialized_at discovery stores, or nil when a table was never refreshed
+func lastMaterialized(tables []models.DataSourceMaterialization, config map[string]interface{}) *time.Time {
+	if len(tables) == 0 {
+		materializedAt, _ := config["materialized_at"].(string)
+		if at, err := time.Parse(time.RFC3339, materializedAt); err == nil {
+			return &at
+		}
+		return nil
+	}
+
+	var oldest *time.Time
+	for _, table := range tables {
+		if table.SyncedAt == nil {
+			return nil
+		}
+		if oldest == nil || table.SyncedAt.Before(*oldest) {
+			oldest = table.SyncedAt
+		}
+	}
+	return oldest
+}
+
+// materializationDue reports whether the refresh interval of a data source has
+// passed since its oldest table was refreshed
+func materializationDue(tables []models.DataSourceMaterialization, config map[string]interface{}, now time.Time) bool {
+	last := lastMaterialized(tables, config)
+	return last == nil || !now.Before(last.Add(materializationInterval(config)))
+}
+
+// dataFreshness describes the copy of a data source: its age from the oldest
+// table refresh, stale when a refresh failed or is overdue
+func dataFreshness(tables []models.DataSourceMaterialization, config map[string]interface{}, now time.Time) *models.DataFreshness {
+	freshness := &models.DataFreshness{Source: "materialized"}
+	last := lastMaterialized(tables, config)
+	if last == nil {
+		freshness.Stale = true
+		return freshness
+	}
+
+	freshness.MaterializedAt = last
+	age := now.Sub(*last)
+	freshness.AgeSeconds = int64(age.Seconds())
+	freshness.Stale = age > materializationStaleFactor*materializationInterval(config)
+	for _, table := range tables {
+		if table.Status == models.MaterializationStatusFailed {
+			freshness.Stale = true
+		}
+	}
+	return freshness
+}
+
+// watermarkValue formats the incremental column of a record for comparison
+func watermarkValue(record map[string]interface{}, column string) (string, bool) {
+	switch value := record[column].(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, value != ""
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	default:
+		return fmt.Sprintf("%v", value), true
+	}
+}
+
+// watermarkAfter reports whether a watermark is past another: numerically when
+// both are numbers, else as strings, which orders ISO 8601 timestamps
+func watermarkAfter(a, b string) bool {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		return x > y
+	}
+	return a > b
+}
+
+// maxWatermark returns the highest value of the incremental column, or "" without one
+func maxWatermark(records []map[string]interface{}, column string) string {
+	if column == "" {
+		return ""
+	}
+	watermark := ""
+	for _, record := range records {
+		if value, ok := watermarkValue(record, column); ok && (watermark == "" || watermarkAfter(value, watermark)) {
+			watermark = value
+		}
+	}
+	return watermark
+}
+
+// recordsAfter drops the records at or before the watermark; APIs may ignore
+// the incremental parameter or treat it as inclusive
+func recordsAfter(records []map[string]interface{}, column, watermark string) []map[string]interface{} {
+	newer := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		if value, ok := watermarkValue(record, column); ok && watermarkAfter(value, watermark) {
+			newer = append(newer, record)
+		}
+	}
+	return newer
+}
+
+// mergeRecords replaces the existing records with the same primary key as an
+// updated record and appends the others, keeping the order of the records
+func mergeRecords(existing, updates []map[string]interface{}, primaryKey string) []map[string]interface{} {
+	if primaryKey == "" {
+		return append(existing, updates...)
+	}
+
+	index := make(map[string]int, len(existing))
+	for i, record := range existing {
+		if key, ok := watermarkValue(record, primaryKey); ok {
+			index[key] = i
+		}
+	}
+	for _, record := range updates {
+		key, ok := watermarkValue(record, primaryKey)
+		if i, found := index[key]; ok && found {
+			existing[i] = record
+			continue
+		}
+		if ok {
+			index[key] = len(existing)
+		}
+		existing = append(existing, record)
+	}
+	return existing
+}
+
+// readJSONLines reads records written by writeJSONLines
+func readJSONLines(path string) ([]map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaterializes(t *testing.T) {
+	assert.True(t, Materializes(models.DataSourceTypeRESTAPI, map[string]interface{}{}))
+	assert.False(t, Materializes(models.DataSourceTypeGoogleSheets, map[string]interface{}{}))
+	assert.True(t, Materializes(models.DataSourceTypeGoogleSheets, map[string]interface{}{"materialize": true}))
+	assert.False(t, Materializes(models.DataSourceTypePostgreSQL, map[string]interface{}{"materialize": true}))
+}
+
+func TestWatermarks(t *testing.T) {
+	assert.True(t, watermarkAfter("10", "9"), "numbers compare numerically")
+	assert.True(t, watermarkAfter("2025-10-02T00:00:00Z", "2025-10-01T23:59:59Z"))
+	assert.False(t, watermarkAfter("2025-10-01T00:00:00Z", "2025-10-01T00:00:00Z"))
+
+	records := []map[string]interface{}{
+		{"id": float64(1), "updated_at": "2025-10-01T00:00:00Z"},
+		{"id": float64(2), "updated_at": nil},
+		{"id": float64(3), "updated_at": "2025-10-03T00:00:00Z"},
+	}
+	assert.Equal(t, "2025-10-03T00:00:00Z", maxWatermark(records, "updated_at"))
+	assert.Equal(t, "3", maxWatermark(records, "id"))
+	assert.Empty(t, maxWatermark(records, ""))
+
+	newer := recordsAfter(records, "updated_at", "2025-10-01T00:00:00Z")
+	require.Len(t, newer, 1)
+	assert.Equal(t, float64(3), newer[0]["id"])
+}
+
+func TestMergeRecords(t *testing.T) {
+	existing := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"id": float64(1), "status": "open"},
+			{"id": float64(2), "status": "open"},
+		}
+	}
+	updates := []map[string]interface{}{
+		{"id": float64(2), "status": "closed"},
+		{"id": float64(3), "status": "open"},
+	}
+
+	merged := mergeRecords(existing(), updates, "id")
+	require.Len(t, merged, 3)
+	assert.Equal(t, "closed", merged[1]["status"])
+	assert.Equal(t, float64(3), merged[2]["id"])
+
+	assert.Len(t, mergeRecords(existing(), updates, ""), 4, "without a primary key updates are appended")
+}
+
+func TestMaterializationDueAndFreshness(t *testing.T) {
+	now := time.Date(2025, 10, 9, 12, 0, 0, 0, time.UTC)
+	synced := now.Add(-90 * time.Minute)
+	recent := now.Add(-10 * time.Minute)
+	tables := []models.DataSourceMaterialization{
+		{Table: "orders", Status: models.MaterializationStatusCompleted, SyncedAt: &recent},
+		{Table: "customers", Status: models.MaterializationStatusCompleted, SyncedAt: &synced},
+	}
+	config := map[string]interface{}{}
+
+	assert.True(t, materializationDue(nil, config, now), "never materialized")
+	assert.True(t, materializationDue(tables, config, now), "oldest table is past the default hour")
+	assert.False(t, materializationDue(tables, map[string]interface{}{"refresh_interval_minutes": float64(120)}, now))
+	assert.False(t, materializationDue(nil, map[string]interface{}{"materialized_at": "2025-10-09T11:30:00Z"}, now))
+
+	freshness := dataFreshness(tables, config, now)
+	require.NotNil(t, freshness.MaterializedAt)
+	assert.Equal(t, synced, *freshness.MaterializedAt)
+	assert.Equal(t, int64(5400), freshness.AgeSeconds)
+	assert.False(t, freshness.Stale)
+
+	assert.True(t, dataFreshness(tables, map[string]interface{}{"refresh_interval_minutes": float64(30)}, now).Stale)
+	tables[0].Status = models.MaterializationStatusFailed
+	assert.True(t, dataFreshness(tables, config, now).Stale, "a failed refresh is stale")
+	assert.True(t, dataFreshness(nil, config, now).Stale, "never materialized")
+}
+
+func TestValidateMaterializationConfig(t *testing.T) {
+	assert.NoError(t, validateMaterializationConfig(map[string]interface{}{"incremental_column": "updated_at", "incremental_param": "since"}))
+	assert.Error(t, validateMaterializationConfig(map[string]interface{}{"incremental_param": "since"}))
+	assert.Error(t, validateMaterializationConfig(map[string]interface{}{"refresh_interval_minutes": float64(0)}))
+}
+
+func TestJSONLinesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	records := []map[string]interface{}{{"id": float64(1), "name": "Ann"}, {"id": float64(2), "name": nil}}
+
+	require.NoError(t, writeJSONLines(path, records))
+	read, err := readJSONLines(path)
+	require.NoError(t, err)
+	assert.Equal(t, records, read)
+}
+
+func TestMaterializationFilePath(t *testing.T) {
+	s := NewMaterializationService(nil, nil, nil, "/data")
+	assert.Equal(t, "/data/rest_api_4.jsonl", s.FilePath(&models.DataSource{ID: 4, Type: models.DataSourceTypeRESTAPI}, "orders"))
+	assert.Equal(t, "/data/google_sheets_7_q3_sales.jsonl", s.FilePath(&models.DataSource{ID: 7, Type: models.DataSourceTypeGoogleSheets}, "Q3 Sales"))
+}
+
+func TestSheetRecords(t *testing.T) {
+	records := sheetRecords([][]string{{"region", ""}, {"north", "12"}, {"south"}})
+	require.Len(t, records, 2)
+	assert.Equal(t, map[string]interface{}{"region": "north", "Column_2": "12"}, records[0])
+	assert.Equal(t, map[string]interface{}{"region": "south", "Column_2": nil}, records[1])
+}
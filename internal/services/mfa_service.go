@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/totp"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMFAInvalidCode is returned when a TOTP or recovery code is wrong, expired or already used
+	ErrMFAInvalidCode = errors.New("invalid MFA code")
+	// ErrMFAAlreadyEnabled is returned when enrolling a user whose MFA is already enabled
+	ErrMFAAlreadyEnabled = errors.New("MFA is already enabled")
+	// ErrMFANotEnrolled is returned when the user has not set up MFA
+	ErrMFANotEnrolled = errors.New("MFA is not enabled")
+	// ErrMFARequired is returned when disabling MFA a user is required to use
+	ErrMFARequired = errors.New("MFA is required for this user")
+)
+
+const (
+	// mfaSettingsID is the ID of the single workspace MFA settings row
+	mfaSettingsID = 1
+	// mfaCodeSkew is the number of 30 second steps a TOTP code may be early or late
+	mfaCodeSkew = 1
+	// mfaRecoveryCodeCount is the number of recovery codes generated at a time
+	mfaRecoveryCodeCount = 10
+)
+
+// recoveryCodeEncoding renders random bytes as lowercase-friendly recovery codes
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAService manages TOTP second factors, their recovery codes and the flags
+// that make MFA mandatory for a user, for admins or for everyone
+type MFAService struct {
+	db     *gorm.DB
+	issuer string // Shown by authenticator apps next to the account
+}
+
+// NewMFAService creates a new MFA service
+func NewMFAService(db *gorm.DB, issuer string) *MFAService {
+	return &MFAService{
+		db:     db,
+		issuer: issuer,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *MFAService) WithContext(ctx context.Context) *MFAService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Status returns the MFA state of a user
+func (s *MFAService) Status(user *models.User) (*models.MFAStatus, error) {
+	mfa, err := s.getUserMFA(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.Required(user)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.MFAStatus{Required: required}
+	if mfa != nil && mfa.Enabled {
+		status.Enabled = true
+		status.EnabledAt = mfa.EnabledAt
+
+		var remaining int64
+		if err := s.db.Model(&models.MFARecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Count(&remaining).Error; err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.RecoveryCodesRemaining = int(remaining)
+	}
+	return status, nil
+}
+
+// Enabled reports whether the user has confirmed MFA enrollment
+func (s *MFAService) Enabled(userID uint) (bool, error) {
+	mfa, err := s.getUserMFA(userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.Enabled, nil
+}
+
+// Required reports whether the user must use MFA, by the user flag or the workspace settings
+func (s *MFAService) Required(user *models.User) (bool, error) {
+	mfa, err := s.getUserMFA(user.ID)
+	if err != nil {
+		return false, err
+	}
+	settings, err := s.GetSettings()
+	if err != nil {
+		return false, err
+	}
+	return mfaRequired(mfa, settings, user.Role), nil
+}
+
+// BeginEnrollment generates a new secret for the user. MFA is enabled once a
+// code generated from it is confirmed; until then login is unaffected.
+func (s *MFAService) BeginEnrollment(user *models.User) (*models.MFAEnrollment, error) {
+	mfa, err := s.getUserMFA(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil && mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil {
+		mfa = &models.UserMFA{UserID: user.ID}
+	}
+	mfa.Secret = secret
+	mfa.LastUsedCounter = 0
+	if err := s.db.Save(mfa).Error; err != nil {
+		return nil, fmt.Errorf("failed to save MFA secret: %w", err)
+	}
+
+	return &models.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user proves the authenticator app
+// generates codes from the secret, and returns the new recovery codes
+func (s *MFAService) ConfirmEnrollment(userID uint, code string) ([]string, error) {
+	mfa, err := s.getUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || mfa.Secret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	counter, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaCodeSkew)
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(mfa).Updates(map[string]interface{}{
+			"enabled":           true,
+			"enabled_at":        now,
+			"last_used_counter": counter,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to enable MFA: %w", err)
+		}
+
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code, or else a recovery code, of a user with MFA
+// enabled. Each TOTP code and recovery code is accepted only once.
+func (s *MFAService) Verify(userID uint, code string) error {
+	mfa, err := s.getUserMFA(userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return ErrMFANotEnrolled
+	}
+
+	if err := s.verifyTOTP(mfa, code); !errors.Is(err, ErrMFAInvalidCode) {
+		return err
+	}
+	return s.useRecoveryCode(userID, code)
+}
+
+// Disable turns MFA off after checking a current code. Users that are required
+// to use MFA cannot disable it; an admin can reset it instead.
+func (s *MFAService) Disable(user *models.User, code string) error {
+	required, err := s.Required(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+	if err := s.Verify(user.ID, code); err != nil {
+		return err
+	}
+	return s.Reset(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking a current TOTP code
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	mfa, err := s.getUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.verifyTOTP(mfa, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// SetUserRequired sets whether a user must use MFA. A user that is required to
+// but has not enrolled is asked to set up MFA at the next login.
+func (s *MFAService) SetUserRequired(userID uint, required bool) (*models.UserMFA, error) {
+	mfa, err := s.getUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		mfa = &models.UserMFA{UserID: userID}
+	}
+	mfa.Required = required
+	if err := s.db.Save(mfa).Error; err != nil {
+		return nil, fmt.Errorf("failed to save MFA requirement: %w", err)
+	}
+	return mfa, nil
+}
+
+// Reset turns MFA off and removes the secret and recovery codes of a user, e.g.
+// after the authenticator device is lost. The requirement flag is kept.
+func (s *MFAService) Reset(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserMFA{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"secret":            "",
+			"enabled":           false,
+			"enabled_at":        nil,
+			"last_used_counter": 0,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reset MFA: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetSettings returns the workspace MFA enforcement flags
+func (s *MFAService) GetSettings() (*models.MFASettings, error) {
+	var settings models.MFASettings
+	if err := s.db.FirstOrCreate(&settings, models.MFASettings{ID: mfaSettingsID}).Error; err != nil {
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateSettings changes the workspace MFA enforcement flags
+func (s *MFAService) UpdateSettings(req *models.MFASettingsRequest, adminID uint) (*models.MFASettings, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	settings.RequireAll = req.RequireAll
+	settings.RequireAdmins = req.RequireAdmins
+	settings.UpdatedBy = adminID
+	if err := s.db.Save(settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to update MFA settings: %w", err)
+	}
+	return settings, nil
+}
+
+// getUserMFA returns the MFA record of a user, or nil when there is none
+func (s *MFAService) getUserMFA(userID uint) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	if err := s.db.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get MFA of user %d: %w", userID, err)
+	}
+	return &mfa, nil
+}
+
+// verifyTOTP checks a TOTP code and records its time step, so neither it nor
+// an earlier code can be used again
+func (s *MFAService) verifyTOTP(mfa *models.UserMFA, code string) error {
+	counter, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaCodeSkew)
+	if !ok || counter <= mfa.LastUsedCounter {
+		return ErrMFAInvalidCode
+	}
+
+	// Conditional on the stored step, so a code submitted twice concurrently is accepted once
+	result := s.db.Model(&models.UserMFA{}).
+		Where("id = ? AND last_used_counter < ?", mfa.ID, counter).
+		Update("last_used_counter", counter)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record MFA code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAInvalidCode
+	}
+	mfa.LastUsedCounter = counter
+	return nil
+}
+
+// useRecoveryCode marks an unused recovery code of the user as used
+func (s *MFAService) useRecoveryCode(userID uint, code string) error {
+	hash := hashRecoveryCode(code)
+	if hash == "" {
+		return ErrMFAInvalidCode
+	}
+
+	result := s.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAInvalidCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of a user and stores new
+// ones, returning them in plain text
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := generateRecoveryCodes(mfaRecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	records := make([]models.MFARecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = models.MFARecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// mfaRequired reports whether a user with the role must use MFA
+func mfaRequired(mfa *models.UserMFA, settings *models.MFASettings, role string) bool {
+	if mfa != nil && mfa.Required {
+		return true
+	}
+	if settings == nil {
+		return false
+	}
+	return settings.RequireAll || (settings.RequireAdmins && role == "admin")
+}
+
+// generateRecoveryCodes returns n random recovery codes formatted as xxxxx-xxxxx
+func generateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode returns the stored hash of a recovery code, ignoring case,
+// spaces and dashes. It is empty for input that cannot be a recovery code.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	if len(normalized) != 10 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
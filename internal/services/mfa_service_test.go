@@ -0,0 +1,60 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFARequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		mfa      *models.UserMFA
+		settings *models.MFASettings
+		role     string
+		want     bool
+	}{
+		{"nothing set", nil, &models.MFASettings{}, "user", false},
+		{"user flag", &models.UserMFA{Required: true}, &models.MFASettings{}, "user", true},
+		{"everyone", nil, &models.MFASettings{RequireAll: true}, "user", true},
+		{"admins, user", nil, &models.MFASettings{RequireAdmins: true}, "user", false},
+		{"admins, admin", nil, &models.MFASettings{RequireAdmins: true}, "admin", true},
+		{"no settings", &models.UserMFA{Enabled: true}, nil, "admin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mfaRequired(tt.mfa, tt.settings, tt.role))
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := generateRecoveryCodes(mfaRecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, mfaRecoveryCodeCount)
+
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, format, code)
+		assert.NotEmpty(t, hashRecoveryCode(code))
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := hashRecoveryCode("abcde-fghij")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashRecoveryCode(" ABCDE FGHIJ "))
+	assert.Equal(t, hash, hashRecoveryCode("abcdefghij"))
+	assert.NotEqual(t, hash, hashRecoveryCode("abcde-fghik"))
+
+	assert.Empty(t, hashRecoveryCode("123456"))
+	assert.Empty(t, hashRecoveryCode(""))
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// numericFieldTypes are the column types a pipeline can sum or average
+var numericFieldTypes = map[string]bool{
+	"integer": true, "bigint": true, "smallint": true, "double": true, "float": true, "decimal": true,
+}
+
+// generatePipeline generates an aggregation query for a MongoDB data source
+// from natural language (mock implementation, like generateSQL)
+func (s *NL2SQLService) generatePipeline(nlQuery string, enhancedContext map[string]interface{}) (string, error) {
+	schemas, _ := enhancedContext["schemas"].([]map[string]interface{})
+	query, err := generateAggregationQuery(nlQuery, schemas)
+	if err != nil {
+		return "", err
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode pipeline: %v", err)
+	}
+	return string(queryJSON), nil
+}
+
+// generateAggregationQuery picks the collection named in the question (or the
+// first one) and builds a count, sum or average pipeline, grouped when the
+// question says "by <field>"
+func generateAggregationQuery(nlQuery string, schemas []map[string]interface{}) (*models.AggregationQuery, error) {
+	if len(schemas) == 0 {
+		return nil, errors.New("no collections discovered for this data source")
+	}
+
+	lower := strings.ToLower(nlQuery)
+	schema := schemas[0]
+	for _, candidate := range schemas {
+		name, _ := candidate["name"].(string)
+		if name != "" && (strings.Contains(lower, strings.ToLower(name)) || strings.Contains(lower, strings.ToLower(strings.TrimSuffix(name, "s")))) {
+			schema = candidate
+			break
+		}
+	}
+	collection, _ := schema["name"].(string)
+	columns, _ := schema["columns"].([]models.Column)
+
+	// "... by region" groups on the field named after "by"
+	var groupBy interface{}
+	if idx := strings.LastIndex(lower, " by "); idx >= 0 {
+		rest := strings.Fields(lower[idx+4:])
+		if len(rest) > 0 {
+			for _, column := range columns {
+				if strings.ToLower(column.Name) == strings.Trim(rest[0], "?.,!") {
+					groupBy = "$" + column.Name
+					break
+				}
+			}
+		}
+	}
+
+	// Prefer a numeric field named in the question, then the first numeric field
+	var measure string
+	for _, column := range columns {
+		if !numericFieldTypes[column.Type] || column.PrimaryKey {
+			continue
+		}
+		if strings.Contains(lower, strings.ToLower(column.Name)) {
+			measure = column.Name
+			break
+		}
+		if measure == "" {
+			measure = column.Name
+		}
+	}
+
+	var stages []map[string]interface{}
+	switch {
+	case mentionsWord(lower, "count", "number", "how many"):
+		if groupBy == nil {
+			stages = append(stages, map[string]interface{}{"$count": "total_count"})
+			break
+		}
+		stages = append(stages,
+			map[string]interface{}{"$group": map[string]interface{}{"_id": groupBy, "total_count": map[string]interface{}{"$sum": 1}}},
+			map[string]interface{}{"$sort": map[string]interface{}{"total_count": -1}},
+		)
+	case measure != "" && mentionsWord(lower, "average", "avg", "mean"):
+		stages = append(stages,
+			map[string]interface{}{"$group": map[string]interface{}{"_id": groupBy, "average_" + measure: map[string]interface{}{"$avg": "$" + measure}}},
+		)
+	case measure != "" && mentionsWord(lower, "sales", "revenue", "total", "sum"):
+		stages = append(stages,
+			map[string]interface{}{"$group": map[string]interface{}{"_id": groupBy, "total_" + measure: map[string]interface{}{"$sum": "$" + measure}}},
+			map[string]interface{}{"$sort": map[string]interface{}{"total_" + measure: -1}},
+		)
+	default:
+		stages = append(stages, map[string]interface{}{"$limit": 100})
+	}
+
+	query := &models.AggregationQuery{Collection: collection}
+	for _, stage := range stages {
+		stageJSON, err := json.Marshal(stage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode pipeline stage: %v", err)
+		}
+		query.Pipeline = append(query.Pipeline, stageJSON)
+	}
+	return query, nil
+}
+
+// mentionsWord reports whether the text contains any of the words as a whole
+// word, so "count" does not match "country"
+func mentionsWord(text string, words ...string) bool {
+	for _, word := range words {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`).MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// pipelineResultColumns derives the columns of aggregation results: documents
+// have no fixed shape, so every field seen in any row becomes a column, _id first
+func pipelineResultColumns(rows []map[string]interface{}) []models.Column {
+	types := make(map[string]string)
+	for _, row := range rows {
+		for key, value := range row {
+			if value == nil {
+				if _, ok := types[key]; !ok {
+					types[key] = ""
+				}
+				continue
+			}
+			if types[key] == "" {
+				types[key] = pipelineValueType(value)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "_id" || names[j] == "_id" {
+			return names[i] == "_id"
+		}
+		return names[i] < names[j]
+	})
+
+	columns := make([]models.Column, 0, len(names))
+	for _, name := range names {
+		columnType := types[name]
+		if columnType == "" {
+			columnType = "string"
+		}
+		columns = append(columns, models.Column{Name: name, Type: columnType, Nullable: true})
+	}
+	return columns
+}
+
+// pipelineValueType maps a normalized result value to a column type
+func pipelineValueType(value interface{}) string {
+	switch value.(type) {
+	case int32, int, int64:
+		return "integer"
+	case float64, float32:
+		return "double"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "timestamp"
+	case map[string]interface{}, []interface{}:
+		return "json"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// evalRowLimit caps the rows compared per query in an evaluation
+	evalRowLimit = 1000
+	// defaultEvalRunLimit is the number of runs listed when no limit is given
+	defaultEvalRunLimit = 50
+)
+
+var (
+	ErrGoldenQueryNotFound   = errors.New("golden query not found")
+	ErrEvalRunNotFound       = errors.New("evaluation run not found")
+	ErrEvalDataSource        = errors.New("data source not found or not active")
+	ErrInvalidGoldenSQL      = errors.New("invalid expected SQL")
+	ErrNoActiveGoldenQueries = errors.New("data source has no active golden queries")
+)
+
+// NL2SQLEvalService measures NL2SQL accuracy: it runs the generator over the
+// golden queries of a data source, executes both the generated and the
+// expected query and compares their result sets
+type NL2SQLEvalService struct {
+	db            *gorm.DB
+	nl2sqlService *NL2SQLService
+	jobs          *JobService
+}
+
+// NewNL2SQLEvalService creates a new NL2SQL evaluation service and registers
+// the job that runs evaluations
+func NewNL2SQLEvalService(db *gorm.DB, nl2sqlService *NL2SQLService, jobs *JobService) *NL2SQLEvalService {
+	s := &NL2SQLEvalService{
+		db:            db,
+		nl2sqlService: nl2sqlService,
+		jobs:          jobs,
+	}
+	jobs.Register(models.JobTypeNL2SQLEval, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.EvalRunJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		return s.WithContext(ctx).runEvaluation(ctx, p.RunID)
+	})
+	return s
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *NL2SQLEvalService) WithContext(ctx context.Context) *NL2SQLEvalService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sqlService = s.nl2sqlService.WithContext(ctx)
+	return &scoped
+}
+
+// ListGoldenQueries returns the golden queries, of one data source when dataSourceID is set
+func (s *NL2SQLEvalService) ListGoldenQueries(dataSourceID uint) ([]models.GoldenQuery, error) {
+	query := s.db.Order("id")
+	if dataSourceID != 0 {
+		query = query.Where("data_source_id = ?", dataSourceID)
+	}
+
+	var goldens []models.GoldenQuery
+	if err := query.Find(&goldens).Error; err != nil {
+		return nil, fmt.Errorf("failed to get golden queries: %w", err)
+	}
+	return goldens, nil
+}
+
+// CreateGoldenQuery adds a golden query; the expected SQL must pass the
+// validation of the data source
+func (s *NL2SQLEvalService) CreateGoldenQuery(userID uint, req *models.GoldenQueryRequest) (*models.GoldenQuery, error) {
+	golden := &models.GoldenQuery{CreatedBy: userID}
+	if err := s.applyGoldenQueryRequest(golden, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(golden).Error; err != nil {
+		return nil, fmt.Errorf("failed to create golden query: %w", err)
+	}
+	return golden, nil
+}
+
+// UpdateGoldenQuery replaces a golden query
+func (s *NL2SQLEvalService) UpdateGoldenQuery(id uint, req *models.GoldenQueryRequest) (*models.GoldenQuery, error) {
+	var golden models.GoldenQuery
+	if err := s.db.First(&golden, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGoldenQueryNotFound
+		}
+		return nil, fmt.Errorf("failed to get golden query: %w", err)
+	}
+
+	if err := s.applyGoldenQueryRequest(&golden, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&golden).Error; err != nil {
+		return nil, fmt.Errorf("failed to update golden query: %w", err)
+	}
+	return &golden, nil
+}
+
+// DeleteGoldenQuery removes a golden query; results of past runs are kept
+func (s *NL2SQLEvalService) DeleteGoldenQuery(id uint) error {
+	result := s.db.Delete(&models.GoldenQuery{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete golden query: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrGoldenQueryNotFound
+	}
+	return nil
+}
+
+func (s *NL2SQLEvalService) applyGoldenQueryRequest(golden *models.GoldenQuery, req *models.GoldenQueryRequest) error {
+	dataSource, err := s.activeDataSource(req.DataSourceID)
+	if err != nil {
+		return err
+	}
+
+	_, validation, err := s.nl2sqlService.prepareQuery(dataSource, req.ExpectedSQL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGoldenSQL, err)
+	}
+	if !s.nl2sqlService.sqlValidator.IsQuerySafe(validation) {
+		return fmt.Errorf("%w: %s", ErrInvalidGoldenSQL, strings.Join(validation.Violations, "; "))
+	}
+
+	golden.DataSourceID = req.DataSourceID
+	golden.NLQuery = strings.TrimSpace(req.NLQuery)
+	golden.ExpectedSQL = strings.TrimSpace(req.ExpectedSQL)
+	golden.Notes = req.Notes
+	golden.IsActive = req.IsActive == nil || *req.IsActive
+	return nil
+}
+
+// StartRun queues an evaluation of the active golden queries of a data source
+func (s *NL2SQLEvalService) StartRun(ctx context.Context, userID uint, req *models.EvalRunRequest) (*models.EvalRun, error) {
+	dataSource, err := s.activeDataSource(req.DataSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.GoldenQuery{}).Where("data_source_id = ? AND is_active = ?", req.DataSourceID, true).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count golden queries: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrNoActiveGoldenQueries
+	}
+
+	run := &models.EvalRun{
+		TenantID:     dataSource.TenantID,
+		DataSourceID: req.DataSourceID,
+		Label:        strings.TrimSpace(req.Label),
+		Model:        s.nl2sqlService.usageService.llmModel(),
+		Status:       models.EvalRunStatusPending,
+		Total:        int(count),
+		TriggeredBy:  userID,
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to create evaluation run: %w", err)
+	}
+
+	if _, err := s.jobs.Enqueue(ctx, models.JobTypeNL2SQLEval, models.EvalRunJobPayload{RunID: run.ID}); err != nil {
+		s.db.Model(run).Updates(map[string]interface{}{"status": models.EvalRunStatusFailed, "error_msg": err.Error()})
+		return nil, fmt.Errorf("failed to queue evaluation run: %w", err)
+	}
+	return run, nil
+}
+
+// ListRuns returns the latest evaluation runs, newest first, of one data
+// source when dataSourceID is set
+func (s *NL2SQLEvalService) ListRuns(dataSourceID uint, limit int) ([]models.EvalRun, error) {
+	if limit <= 0 {
+		limit = defaultEvalRunLimit
+	}
+	query := s.db.Order("id DESC").Limit(limit)
+	if dataSourceID != 0 {
+		query = query.Where("data_source_id = ?", dataSourceID)
+	}
+
+	var runs []models.EvalRun
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get evaluation runs: %w", err)
+	}
+	return runs, nil
+}
+
+// GetRun returns an evaluation run with the result of each golden query
+func (s *NL2SQLEvalService) GetRun(id uint) (*models.EvalRunDetailResponse, error) {
+	var run models.EvalRun
+	if err := s.db.First(&run, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEvalRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get evaluation run: %w", err)
+	}
+
+	results := []models.EvalResult{}
+	if err := s.db.Where("run_id = ?", run.ID).Order("id").Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to get evaluation results: %w", err)
+	}
+	return &models.EvalRunDetailResponse{EvalRun: run, Results: results}, nil
+}
+
+func (s *NL2SQLEvalService) activeDataSource(dataSourceID uint) (*models.DataSource, error) {
+	var dataSource models.DataSource
+	if err := s.db.First(&dataSource, dataSourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEvalDataSource
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+	if dataSource.Status != models.ConnectionStatusActive {
+		return nil, ErrEvalDataSource
+	}
+	return &dataSource, nil
+}
+
+// runEvaluation evaluates the active golden queries of the run's data source
+// and stores the results and metrics. A retried job starts the run over.
+func (s *NL2SQLEvalService) runEvaluation(ctx context.Context, runID uint) error {
+	var run models.EvalRun
+	if err := s.db.First(&run, runID).Error; err != nil {
+		return fmt.Errorf("failed to get evaluation run: %w", err)
+	}
+
+	fail := func(err error) error {
+		s.db.Model(&run).Updates(map[string]interface{}{"status": models.EvalRunStatusFailed, "error_msg": err.Error()})
+		return err
+	}
+
+	dataSource, err := s.activeDataSource(run.DataSourceID)
+	if err != nil {
+		return fail(err)
+	}
+
+	var goldens []models.GoldenQuery
+	if err := s.db.Where("data_source_id = ? AND is_active = ?", run.DataSourceID, true).Order("id").Find(&goldens).Error; err != nil {
+		return fail(fmt.Errorf("failed to get golden queries: %w", err))
+	}
+
+	if err := s.db.Where("run_id = ?", run.ID).Delete(&models.EvalResult{}).Error; err != nil {
+		return fail(fmt.Errorf("failed to clear evaluation results: %w", err))
+	}
+	startedAt := time.Now()
+	if err := s.db.Model(&run).Updates(map[string]interface{}{
+		"status": models.EvalRunStatusRunning, "started_at": startedAt, "total": len(goldens), "error_msg": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to start evaluation run: %w", err)
+	}
+
+	// Generation is billed to whoever started the run
+	ctx = WithUsageUser(ctx, run.TriggeredBy)
+	results := make([]models.EvalResult, 0, len(goldens))
+	for _, golden := range goldens {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		result := s.evaluate(ctx, dataSource, &golden)
+		result.RunID = run.ID
+		if err := s.db.Create(&result).Error; err != nil {
+			return fail(fmt.Errorf("failed to save evaluation result: %w", err))
+		}
+		results = append(results, result)
+	}
+
+	summarizeEvalRun(&run, results)
+	completedAt := time.Now()
+	run.Status = models.EvalRunStatusCompleted
+	run.StartedAt = &startedAt
+	run.CompletedAt = &completedAt
+	if err := s.db.Save(&run).Error; err != nil {
+		return fmt.Errorf("failed to save evaluation run: %w", err)
+	}
+
+	logger.FromContext(ctx).Info().Uint("run_id", run.ID).Uint("data_source_id", run.DataSourceID).
+		Int("evaluated", run.Evaluated).Float64("accuracy", run.Accuracy).Msg("NL2SQL evaluation completed")
+	return nil
+}
+
+// evaluate generates SQL for a golden query, runs it and the expected SQL,
+// and compares the result sets
+func (s *NL2SQLEvalService) evaluate(ctx context.Context, dataSource *models.DataSource, golden *models.GoldenQuery) models.EvalResult {
+	result := models.EvalResult{
+		TenantID:      dataSource.TenantID,
+		GoldenQueryID: golden.ID,
+		NLQuery:       golden.NLQuery,
+		ExpectedSQL:   golden.ExpectedSQL,
+	}
+
+	expectedSQL, expected, err := s.execute(dataSource, golden.ExpectedSQL)
+	if err != nil {
+		result.Outcome = models.EvalOutcomeGoldenFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.ExpectedRows = len(expected.Data)
+
+	start := time.Now()
+	generatedSQL, err := s.generate(ctx, dataSource, golden.NLQuery)
+	result.GenerationMs = time.Since(start).Milliseconds()
+	result.GeneratedSQL = generatedSQL
+	if err != nil {
+		result.Outcome = models.EvalOutcomeGenerationFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.SQLMatch = normalizeEvalSQL(generatedSQL) == normalizeEvalSQL(expectedSQL)
+
+	_, generated, err := s.execute(dataSource, generatedSQL)
+	if err != nil {
+		result.Outcome = models.EvalOutcomeExecutionFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.GeneratedRows = len(generated.Data)
+
+	if sameResultSet(expected, generated) {
+		result.Outcome = models.EvalOutcomeMatch
+	} else {
+		result.Outcome = models.EvalOutcomeMismatch
+	}
+	return result
+}
+
+// generate runs the NL2SQL generator like a request of the data source owner,
+// with the owner's KPIs and glossary, without saving a query
+func (s *NL2SQLEvalService) generate(ctx context.Context, dataSource *models.DataSource, nlQuery string) (string, error) {
+	enhancedContext, err := s.nl2sqlService.buildEnhancedContext(ctx, dataSource.UserID, dataSource, nlQuery, NL2SQLContextOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to build enhanced context: %v", err)
+	}
+
+	var generated string
+	if dataSource.Type.UsesAggregationPipeline() {
+		generated, err = s.nl2sqlService.generatePipeline(nlQuery, enhancedContext)
+	} else {
+		generated, err = s.nl2sqlService.generateSQLWithRAG(nlQuery, enhancedContext)
+	}
+	if err != nil {
+		return "", fmt.Errorf("SQL generation failed: %v", err)
+	}
+	s.nl2sqlService.recordGenerationUsage(ctx, nlQuery, enhancedContext, generated)
+
+	prepared, validation, err := s.nl2sqlService.prepareQuery(dataSource, generated)
+	if err != nil {
+		return generated, err
+	}
+	if !s.nl2sqlService.sqlValidator.IsQuerySafe(validation) {
+		return prepared, fmt.Errorf("query failed safety validation: %s", strings.Join(validation.Violations, "; "))
+	}
+	return prepared, nil
+}
+
+// execute prepares and runs a query, with placeholders bound to NULL, and
+// returns the prepared query with its result
+func (s *NL2SQLEvalService) execute(dataSource *models.DataSource, sql string) (string, *QueryResult, error) {
+	prepared, validation, err := s.nl2sqlService.prepareQuery(dataSource, sql)
+	if err != nil {
+		return "", nil, err
+	}
+	if !s.nl2sqlService.sqlValidator.IsQuerySafe(validation) {
+		return prepared, nil, fmt.Errorf("query failed safety validation: %s", strings.Join(validation.Violations, "; "))
+	}
+	result, err := s.nl2sqlService.executeQueryOnDataSource(dataSource, renderQueryParameters(prepared, nil), evalRowLimit)
+	return prepared, result, err
+}
+
+// summarizeEvalRun computes the metrics of a run from its results
+func summarizeEvalRun(run *models.EvalRun, results []models.EvalResult) {
+	run.Total = len(results)
+	run.Evaluated, run.Matches, run.ExactMatches = 0, 0, 0
+	run.GenerationFailures, run.ExecutionFailures, run.GoldenFailures = 0, 0, 0
+	run.Accuracy, run.ExactMatchRate, run.AvgGenerationMs = 0, 0, 0
+
+	var generationMs int64
+	generated := 0
+	for _, result := range results {
+		switch result.Outcome {
+		case models.EvalOutcomeGoldenFailed:
+			run.GoldenFailures++
+			continue
+		case models.EvalOutcomeMatch:
+			run.Matches++
+		case models.EvalOutcomeGenerationFailed:
+			run.GenerationFailures++
+		case models.EvalOutcomeExecutionFailed:
+			run.ExecutionFailures++
+		}
+		run.Evaluated++
+		if result.SQLMatch {
+			run.ExactMatches++
+		}
+		generationMs += result.GenerationMs
+		generated++
+	}
+
+	if run.Evaluated > 0 {
+		run.Accuracy = float64(run.Matches) / float64(run.Evaluated) * 100
+		run.ExactMatchRate = float64(run.ExactMatches) / float64(run.Evaluated) * 100
+	}
+	if generated > 0 {
+		run.AvgGenerationMs = generationMs / int64(generated)
+	}
+}
+
+// normalizeEvalSQL folds case and whitespace and drops a trailing semicolon
+// so equivalent spellings of the same SQL compare equal
+func normalizeEvalSQL(sql string) string {
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	return strings.ToLower(strings.Join(strings.Fields(sql), " "))
+}
+
+// sameResultSet reports whether two results hold the same rows. Column names
+// and order are ignored, since generated aliases rarely match the expected
+// ones, and so is row order; numbers compare with a 1e-6 tolerance.
+func sameResultSet(expected, actual *QueryResult) bool {
+	if len(expected.Data) != len(actual.Data) {
+		return false
+	}
+
+	expectedRows := canonicalRows(expected)
+	actualRows := canonicalRows(actual)
+	for i := range expectedRows {
+		if expectedRows[i] != actualRows[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalRows renders each row as its sorted values, and sorts the rows
+func canonicalRows(result *QueryResult) []string {
+	rows := make([]string, len(result.Data))
+	for i, row := range result.Data {
+		values := make([]string, 0, len(row))
+		for _, value := range row {
+			values = append(values, canonicalValue(value))
+		}
+		sort.Strings(values)
+		rows[i] = strings.Join(values, "\x1f")
+	}
+	sort.Strings(rows)
+	return rows
+}
+
+func canonicalValue(value interface{}) string {
+	var number float64
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case float64:
+		number = v
+	case float32:
+		number = float64(v)
+	case int:
+		number = float64(v)
+	case int32:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case uint:
+		number = float64(v)
+	case uint32:
+		number = float64(v)
+	case uint64:
+		number = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		number = f
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+	return strconv.FormatFloat(math.Round(number*1e6)/1e6, 'f', -1, 64)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameResultSet(t *testing.T) {
+	expected := &QueryResult{Data: []map[string]interface{}{
+		{"region": "west", "total": 100.5},
+		{"region": "east", "total": int64(42)},
+	}}
+
+	// Aliases, row order and number types differ
+	assert.True(t, sameResultSet(expected, &QueryResult{Data: []map[string]interface{}{
+		{"sum": float64(42), "r": "east"},
+		{"sum": json.Number("100.5000000001"), "r": "west"},
+	}}))
+
+	assert.False(t, sameResultSet(expected, &QueryResult{Data: []map[string]interface{}{
+		{"region": "east", "total": 42},
+	}}))
+	assert.False(t, sameResultSet(expected, &QueryResult{Data: []map[string]interface{}{
+		{"region": "west", "total": 100.5},
+		{"region": "east", "total": 43},
+	}}))
+	assert.False(t, sameResultSet(
+		&QueryResult{Data: []map[string]interface{}{{"a": nil}}},
+		&QueryResult{Data: []map[string]interface{}{{"a": "NULL "}}},
+	))
+}
+
+func TestNormalizeEvalSQL(t *testing.T) {
+	assert.Equal(t,
+		normalizeEvalSQL("SELECT COUNT(*)\n  FROM sales LIMIT 1000"),
+		normalizeEvalSQL("select count(*) from sales limit 1000;"))
+}
+
+func TestSummarizeEvalRun(t *testing.T) {
+	run := &models.EvalRun{}
+	summarizeEvalRun(run, []models.EvalResult{
+		{Outcome: models.EvalOutcomeMatch, SQLMatch: true, GenerationMs: 10},
+		{Outcome: models.EvalOutcomeMatch, GenerationMs: 20},
+		{Outcome: models.EvalOutcomeMismatch, GenerationMs: 30},
+		{Outcome: models.EvalOutcomeGenerationFailed, GenerationMs: 40},
+		{Outcome: models.EvalOutcomeGoldenFailed},
+	})
+
+	assert.Equal(t, 5, run.Total)
+	assert.Equal(t, 4, run.Evaluated)
+	assert.Equal(t, 2, run.Matches)
+	assert.Equal(t, 1, run.ExactMatches)
+	assert.Equal(t, 1, run.GenerationFailures)
+	assert.Equal(t, 1, run.GoldenFailures)
+	assert.InDelta(t, 50.0, run.Accuracy, 0.001)
+	assert.InDelta(t, 25.0, run.ExactMatchRate, 0.001)
+	assert.Equal(t, int64(25), run.AvgGenerationMs)
+}
@@ -8,15 +8,15 @@ import (
 	"strings"
 	"time"
 
+	"narapulse-be/internal/config"
+	"narapulse-be/internal/connectors"
 	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/metrics"
+	"narapulse-be/internal/pkg/tokenizer"
 	"gorm.io/gorm"
 )
 
-// ConnectorService placeholder - will be implemented later
-type ConnectorService struct {
-	// TODO: Implement connector service
-}
-
 // AIService placeholder - will be implemented later  
 type AIService struct {
 	// TODO: Implement AI service
@@ -26,37 +26,137 @@ type AIService struct {
 type NL2SQLService struct {
 	db               *gorm.DB
 	sqlValidator     *SQLValidatorService
-	connectorService *ConnectorService
+	connectorService *connectorService // Runs queries on PostgreSQL data sources through their pooled connections
 	aiService        *AIService // Will be implemented later
 	ragService       *RAGService
+	anonymizer       *AnonymizerService
+	piiMasker        *PIIMaskingService
+	costService      *QueryCostService
+	versionService   *SQLVersionService
+	policyService    *ValidationPolicyService
+	resultService    *QueryResultService
+	canaryThreshold  float64 // Relative change that makes a canary run diverge
+	usageService     *UsageService
+	semanticLayer    *SemanticLayerService
+	formatter        *ResultFormatService
+	insights         *InsightService
+	webhooks         *WebhookService
+	materializations *MaterializationService
+	llm              models.LLMSettings // Recorded with each generated query for its trace
+	questions        *QuestionAnalyticsService
+	federationDir    string // Where tables pulled for federated queries are staged
+	flags            *FeatureFlagService
 }
 
-// NewNL2SQLService creates a new NL2SQL service
-func NewNL2SQLService(db *gorm.DB, ragService *RAGService) *NL2SQLService {
+// NewNL2SQLService creates a new NL2SQL service. Without connectorSvc queries
+// connect to their data source on every execution.
+func NewNL2SQLService(db *gorm.DB, connectorSvc *connectorService, ragService *RAGService, usageService *UsageService, webhooks *WebhookService, flags *FeatureFlagService) *NL2SQLService {
+	cfg := config.Load()
+	if connectorSvc == nil {
+		connectorSvc = NewConnectorService()
+	}
 	return &NL2SQLService{
 		db:               db,
 		sqlValidator:     NewSQLValidatorService(),
-		connectorService: &ConnectorService{}, // Placeholder
+		connectorService: connectorSvc,
 		ragService:       ragService,
+		anonymizer:       NewAnonymizerService(cfg.AnonymizeSecret),
+		piiMasker:        NewPIIMaskingService(db, cfg.PIIMaskMode, cfg.AnonymizeSecret),
+		costService:      NewQueryCostService(db, int64(cfg.BigQueryMaxBytesBilledGB)<<30, cfg.BigQueryPricePerTiB),
+		versionService:   NewSQLVersionService(db),
+		policyService:    NewValidationPolicyService(db, nil),
+		resultService:    NewQueryResultService(db),
+		canaryThreshold:  cfg.CanaryDivergenceThreshold,
+		usageService:     usageService,
+		semanticLayer:    NewSemanticLayerService(db),
+		formatter:        NewResultFormatService(db, NewStaticRatesProvider(cfg.CurrencyRates), cfg.DefaultCurrency),
+		insights:         NewInsightService(usageService),
+		webhooks:         webhooks,
+		materializations: NewMaterializationService(db, nil, nil, cfg.MaterializedDataDir),
+		llm:              models.LLMSettings{Model: cfg.AILLMModel, Temperature: cfg.AILLMTemperature},
+		questions:        NewQuestionAnalyticsService(db, ragService),
+		federationDir:    cfg.FederationDir,
+		flags:            flags,
 		// aiService will be initialized when AI integration is ready
 	}
 }
 
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *NL2SQLService) WithContext(ctx context.Context) *NL2SQLService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.ragService = s.ragService.WithContext(ctx)
+	scoped.piiMasker = s.piiMasker.WithContext(ctx)
+	scoped.costService = s.costService.WithContext(ctx)
+	scoped.versionService = s.versionService.WithContext(ctx)
+	scoped.policyService = s.policyService.WithContext(ctx)
+	scoped.resultService = s.resultService.WithContext(ctx)
+	scoped.usageService = s.usageService.WithContext(ctx)
+	scoped.semanticLayer = s.semanticLayer.WithContext(ctx)
+	scoped.formatter = s.formatter.WithContext(ctx)
+	scoped.webhooks = s.webhooks.WithContext(ctx)
+	scoped.materializations = s.materializations.WithContext(ctx)
+	scoped.questions = s.questions.WithContext(ctx)
+	return &scoped
+}
+
 // ConvertNL2SQL converts natural language query to SQL
 func (s *NL2SQLService) ConvertNL2SQL(userID uint, request *models.NL2SQLRequest) (*models.NL2SQLResponse, error) {
+	return s.convertNL2SQL(userID, request, nil)
+}
+
+func (s *NL2SQLService) convertNL2SQL(userID uint, request *models.NL2SQLRequest, progress nl2sqlProgress) (_ *models.NL2SQLResponse, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveNL2SQLConversion(start, err) }()
+
 	// Validate data source access
 	dataSource, err := s.validateDataSourceAccess(userID, request.DataSourceID)
 	if err != nil {
 		return nil, fmt.Errorf("data source validation failed: %v", err)
 	}
 
+	// Questions spanning several data sources are answered with a federated query
+	federation, err := s.planFederation(userID, dataSource, request.FederatedSources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Embedding and LLM calls count against the user's monthly quota
+	ctx := WithUsageUser(s.db.Statement.Context, userID)
+	if err := s.usageService.CheckQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	// Questions may be asked in Indonesian against English schemas
+	language := request.Language
+	if language == "" {
+		language = detectQueryLanguage(request.NLQuery)
+	}
+
 	// Create query record
 	query := &models.NL2SQLQuery{
 		UserID:       userID,
 		DataSourceID: request.DataSourceID,
 		NLQuery:      request.NLQuery,
+		Language:     language,
 		Status:       models.QueryStatusPending,
 		Type:         request.Type,
+		Federated:    federation != nil,
+		Federation:   marshalFederation(federation),
+	}
+
+	// Follow-up questions are linked to the query they build on
+	if request.ParentQueryID != nil {
+		parent, err := s.GetQueryDetails(userID, *request.ParentQueryID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrParentQueryNotFound, err)
+		}
+		query.ParentQueryID = &parent.ID
+		query.ParentSQLVersion = parent.SQLVersion
 	}
 
 	// Set default type if not provided
@@ -70,47 +170,104 @@ func (s *NL2SQLService) ConvertNL2SQL(userID uint, request *models.NL2SQLRequest
 		query.Context = models.JSON(contextJSON)
 	}
 
-	// Save query to database
-	if err := s.db.Create(query).Error; err != nil {
-		return nil, fmt.Errorf("failed to create query record: %v", err)
+	// Save query to database; a dry run only returns what would be generated
+	if !request.DryRun {
+		if err := s.db.Create(query).Error; err != nil {
+			return nil, fmt.Errorf("failed to create query record: %v", err)
+		}
 	}
+	fail := func(err error) {
+		query.MarkFailed(err.Error())
+		if !request.DryRun {
+			s.db.Save(query)
+		}
+	}
+
+	// Glossary synonyms, KPI display names and translated domain words are expanded
+	// to their canonical terms for retrieval and generation; the stored question
+	// stays as asked
+	expandedQuery, expansions := s.expandQuery(userID, request.NLQuery, language)
 
 	// Build enhanced context using RAG system
-	enhancedContext, err := s.buildEnhancedContext(dataSource, request.NLQuery)
+	enhancedContext, err := s.buildEnhancedContext(ctx, userID, dataSource, expandedQuery, NL2SQLContextOptions{
+		Rerank:          request.Rerank,
+		RerankThreshold: request.RerankThreshold,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to build enhanced context: %v", err)
 	}
+	if federation != nil {
+		if err := s.addFederationContext(enhancedContext, federation); err != nil {
+			return nil, fmt.Errorf("failed to build enhanced context: %v", err)
+		}
+	}
+	if prompt, _ := enhancedContext["enhanced_prompt"].(string); prompt != "" {
+		enhancedContext["enhanced_prompt"] = prompt + languagePromptSection(language)
+	}
+	progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageContextBuilt, QueryID: query.ID})
+
+	// Questions about metrics of the semantic model compile to deterministic SQL;
+	// otherwise the definitions they name are passed on to the generator. The
+	// semantic model covers one data source, so federated questions skip it.
+	var semantic *models.SemanticResolution
+	var semanticSQL string
+	if federation == nil {
+		semantic, semanticSQL, err = s.semanticLayer.Resolve(dataSource, request.NLQuery)
+		if err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to resolve semantic model")
+		}
+	}
 
-	// Generate SQL using enhanced context
-	generatedSQL, err := s.generateSQLWithRAG(request.NLQuery, enhancedContext)
+	// Generate SQL using enhanced context; document stores get an aggregation pipeline instead
+	var generatedSQL string
+	switch {
+	case semantic != nil && semantic.Compiled:
+		generatedSQL = semanticSQL
+	case dataSource.Type.UsesAggregationPipeline():
+		generatedSQL, err = s.generatePipeline(expandedQuery, enhancedContext)
+	default:
+		if prompt, _ := enhancedContext["enhanced_prompt"].(string); prompt != "" && semantic != nil {
+			enhancedContext["enhanced_prompt"] = prompt + semanticSQL
+		}
+		generatedSQL, err = s.generateSQLWithRAG(expandedQuery, enhancedContext)
+	}
 	if err != nil {
-		query.MarkFailed(err.Error())
-		s.db.Save(query)
+		fail(err)
 		return nil, fmt.Errorf("SQL generation failed: %v", err)
 	}
-
-	// Validate generated SQL
-	validationResult, err := s.sqlValidator.ValidateSQL(generatedSQL)
+	if semantic == nil || !semantic.Compiled {
+		s.recordGenerationUsage(ctx, expandedQuery, enhancedContext, generatedSQL)
+	}
+	progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageSQLGenerated, QueryID: query.ID, GeneratedSQL: generatedSQL})
+
+	// Validate generated SQL against the data source dialect and validation policy;
+	// federated SQL is DuckDB SQL over the tables of its sources
+	var validationResult *models.SQLValidationResult
+	if federation != nil {
+		generatedSQL, validationResult, err = s.prepareFederatedSQL(dataSource, federation, generatedSQL)
+		query.Federation = marshalFederation(federation)
+	} else {
+		generatedSQL, validationResult, err = s.prepareQuery(dataSource, generatedSQL)
+	}
 	if err != nil {
-		query.MarkFailed(fmt.Sprintf("SQL validation failed: %v", err))
-		s.db.Save(query)
-		return nil, fmt.Errorf("SQL validation failed: %v", err)
+		fail(err)
+		return nil, err
 	}
 
-	// Enforce LIMIT if not present
-	if !validationResult.HasLimit {
-		generatedSQL, err = s.sqlValidator.EnforceLimit(generatedSQL, 1000)
-		if err != nil {
-			query.MarkFailed(fmt.Sprintf("Failed to enforce LIMIT: %v", err))
-			s.db.Save(query)
-			return nil, fmt.Errorf("failed to enforce LIMIT: %v", err)
-		}
-		// Re-validate after adding LIMIT
-		validationResult, _ = s.sqlValidator.ValidateSQL(generatedSQL)
+	// Remember the placeholders so the query can be re-run with other values
+	params, err := detectQueryParameters(generatedSQL, queryDialect(query, dataSource))
+	if err != nil {
+		fail(err)
+		return nil, err
 	}
 
 	// Set the generated SQL to the query object
 	query.GeneratedSQL = generatedSQL
+	if template, _ := enhancedContext["prompt_template"].(*models.PromptTemplate); template != nil {
+		query.PromptTemplateID = &template.ID
+		query.PromptTemplateVersion = template.Version
+	}
+	query.Parameters = marshalQueryParameters(params)
 
 	// Check if query is safe to execute
 	canExecute := s.sqlValidator.IsQuerySafe(validationResult)
@@ -120,18 +277,52 @@ func (s *NL2SQLService) ConvertNL2SQL(userID uint, request *models.NL2SQLRequest
 		query.MarkFailed("Query failed safety validation")
 	}
 
-	// Store metadata
+	// Ask the engine what the query will cost before anyone runs it; the pulls
+	// of a federated query are checked against their sources when it runs
+	var costEstimate *models.QueryCostEstimate
+	if canExecute && federation == nil {
+		costEstimate = s.costService.Estimate(dataSource, renderQueryParameters(generatedSQL, nil), validationResult.EstimatedCost)
+		if err := s.costService.Check(userID, dataSource.ID, costEstimate); err != nil {
+			return nil, fmt.Errorf("failed to check cost ceiling: %v", err)
+		}
+		if costEstimate.ExceedsCeiling {
+			canExecute = false
+		}
+	}
+
+	// The question embedding is recorded for similar question search rather than in the metadata
+	questionEmbedding, _ := enhancedContext["query_embedding"].([]float32)
+	delete(enhancedContext, "query_embedding")
+
+	// Store metadata, with the size of the prompt as it was sent
+	prompt, _ := enhancedContext["enhanced_prompt"].(string)
 	metadata := map[string]interface{}{
 		"validation_result": validationResult,
 		"enhanced_context":  enhancedContext,
+		"prompt_tokens":     tokenizer.Count(prompt),
+		"prompt_budget":     enhancedContext["prompt_budget"],
+		"llm":               s.llm,
+		"cost_estimate":     costEstimate,
+		"semantic_layer":    semantic,
+		"query_expansions":  expansions,
 		"generated_at":      time.Now(),
 	}
 	metadataJSON, _ := json.Marshal(metadata)
 	query.Metadata = models.JSON(metadataJSON)
 
-	// Save updated query
-	if err := s.db.Save(query).Error; err != nil {
-		return nil, fmt.Errorf("failed to update query record: %v", err)
+	// Save updated query together with the first version of its SQL
+	if !request.DryRun {
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if _, err := s.versionService.Record(tx, query, models.SQLVersionSourceGenerated, userID, "", 0); err != nil {
+				return err
+			}
+			return tx.Save(query).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update query record: %v", err)
+		}
+		retrieved, _ := enhancedContext["retrieved"].(*models.RetrievedContext)
+		s.questions.Record(ctx, query, questionEmbedding, retrieved)
 	}
 
 	// Prepare response
@@ -140,9 +331,18 @@ func (s *NL2SQLService) ConvertNL2SQL(userID uint, request *models.NL2SQLRequest
 		GeneratedSQL:  generatedSQL,
 		Validation:    *validationResult,
 		EstimatedCost: validationResult.EstimatedCost,
+		CostEstimate:  costEstimate,
 		SafetyScore:   validationResult.SafetyScore,
 		CanExecute:    canExecute,
 		Messages:      []string{},
+		Parameters:    params,
+		DryRun:        request.DryRun,
+		Semantic:      semantic,
+		Language:      language,
+		Federation:    federation,
+	}
+	if costEstimate != nil {
+		response.EstimatedCost = costEstimate.EstimatedCost
 	}
 
 	// Add messages based on validation
@@ -152,15 +352,44 @@ func (s *NL2SQLService) ConvertNL2SQL(userID uint, request *models.NL2SQLRequest
 	if len(validationResult.Warnings) > 0 {
 		response.Messages = append(response.Messages, "Query has warnings")
 	}
-	if canExecute {
+	if costEstimate != nil && costEstimate.Source == models.QueryCostSourceDryRun {
+		response.Messages = append(response.Messages, "BigQuery estimate: "+describeDryRun(costEstimate))
+	}
+	if costEstimate != nil && costEstimate.ExceedsCeiling {
+		response.Messages = append(response.Messages, "Query exceeds the cost ceiling: "+strings.Join(costEstimate.Violations, "; "))
+	}
+	if federation != nil {
+		response.Messages = append(response.Messages, fmt.Sprintf("Federated query: tables of %d data sources are pulled into DuckDB and joined there, up to the row limit of each source per table", len(federation.Sources)))
+	}
+	if request.DryRun {
+		response.Messages = append(response.Messages, "Dry run: the query was not saved; convert it without dry_run to execute it")
+	} else if canExecute {
 		response.Messages = append(response.Messages, "Query is ready for execution")
 	}
+	for i, message := range response.Messages {
+		response.Messages[i] = localizeMessage(language, message)
+	}
+	progress.emit(models.NL2SQLProgressEvent{
+		Stage:        models.NL2SQLStageValidated,
+		QueryID:      query.ID,
+		GeneratedSQL: generatedSQL,
+		Validation:   validationResult,
+		CanExecute:   &canExecute,
+		Message:      strings.Join(response.Messages, "; "),
+	})
 
 	return response, nil
 }
 
 // ExecuteQuery executes a validated NL2SQL query
 func (s *NL2SQLService) ExecuteQuery(userID uint, request *models.QueryExecutionRequest) (*models.QueryExecutionResponse, error) {
+	return s.executeQuery(userID, request, nil)
+}
+
+func (s *NL2SQLService) executeQuery(userID uint, request *models.QueryExecutionRequest, progress nl2sqlProgress) (_ *models.QueryExecutionResponse, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveNL2SQLExecution(start, err) }()
+
 	// Get query record
 	var query models.NL2SQLQuery
 	if err := s.db.Where("id = ? AND user_id = ?", request.QueryID, userID).First(&query).Error; err != nil {
@@ -181,15 +410,50 @@ func (s *NL2SQLService) ExecuteQuery(userID uint, request *models.QueryExecution
 		return nil, fmt.Errorf("failed to get data source: %v", err)
 	}
 
+	// Refuse conversions to unknown currencies before running anything
+	if request.Currency != "" {
+		if err := s.formatter.CheckCurrency(request.Currency); err != nil {
+			return nil, err
+		}
+	}
+
 	// Set default limit if not provided
 	limit := request.Limit
 	if limit <= 0 {
 		limit = 1000
 	}
 
+	// Bind parameter values, then validate the bound SQL like any other new SQL
+	sql := query.GeneratedSQL
+	executedSQL := ""
+	if params := query.GetParameters(); len(params) > 0 || len(request.Parameters) > 0 {
+		boundSQL, err := bindQueryParameters(sql, params, request.Parameters, queryDialect(&query, &dataSource))
+		if err != nil {
+			return nil, err
+		}
+		boundSQL, validation, err := s.prepareQueryFor(&query, &dataSource, boundSQL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidQueryParameters, err)
+		}
+		if !s.sqlValidator.IsQuerySafe(validation) {
+			return nil, fmt.Errorf("%w: bound query failed safety validation: %s", ErrInvalidQueryParameters, strings.Join(validation.Violations, "; "))
+		}
+		sql, executedSQL = boundSQL, boundSQL
+	}
+
+	// Re-estimate right before execution: data and ceilings may have changed since
+	// generation. Each pull of a federated query is checked against its source instead.
+	var costEstimate *models.QueryCostEstimate
+	if !query.Federated {
+		if costEstimate, err = s.checkQueryCost(userID, &dataSource, sql); err != nil {
+			return nil, err
+		}
+	}
+
 	// Execute query using connector service
+	progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageExecuting, QueryID: query.ID})
 	startTime := time.Now()
-	result, err := s.executeQueryOnDataSource(&dataSource, query.GeneratedSQL, limit)
+	result, federation, err := s.executeQuerySQL(userID, &query, &dataSource, sql, limit)
 	executionTime := time.Since(startTime).Milliseconds()
 
 	if err != nil {
@@ -204,6 +468,7 @@ func (s *NL2SQLService) ExecuteQuery(userID uint, request *models.QueryExecution
 			Status:        models.QueryStatusFailed,
 			Message:       err.Error(),
 			ExecutionTime: executionTime,
+			ExecutedSQL:   executedSQL,
 		}, nil
 	}
 
@@ -212,31 +477,87 @@ func (s *NL2SQLService) ExecuteQuery(userID uint, request *models.QueryExecution
 	query.RowsReturned = int64(len(result.Data))
 	s.db.Save(&query)
 
-	// Store query result
-	queryResult := &models.QueryResult{
-		QueryID:  query.ID,
-		RowCount: int64(len(result.Data)),
+	// PII columns are masked unless the caller may see them
+	var maskedColumns []string
+	if !request.UnmaskPII {
+		maskedColumns = s.queryPIIColumns(&query, result.Columns, result.Data)
+	}
+	present := func(rows []map[string]interface{}) []map[string]interface{} {
+		if request.Anonymize {
+			rows = s.anonymizer.AnonymizeRows(result.Columns, rows)
+		}
+		if len(maskedColumns) > 0 {
+			rows = s.piiMasker.maskColumns(rows, maskedColumns)
+		}
+		return rows
 	}
 
-	// Store columns
-	columnsJSON, _ := json.Marshal(result.Columns)
-	queryResult.Columns = models.JSON(columnsJSON)
+	// Store the result in chunks so it can be paged through later
+	resultID := s.storeQueryResult(&query, result, present, progress)
 
-	// Store data
-	dataJSON, _ := json.Marshal(result.Data)
-	queryResult.Data = models.JSON(dataJSON)
+	s.webhooks.Publish(s.db.Statement.Context, models.WebhookEventQueryCompleted, models.QueryCompletedEvent{
+		QueryID:       query.ID,
+		ResultID:      resultID,
+		UserID:        userID,
+		DataSourceID:  dataSource.ID,
+		NLQuery:       query.NLQuery,
+		RowCount:      int64(len(result.Data)),
+		ExecutionTime: executionTime,
+	})
+
+	// Return the first page only when asked; the rest is read from the stored result
+	data := result.Data
+	nextCursor := ""
+	if request.PageSize > 0 && len(data) > request.PageSize {
+		data = data[:request.PageSize]
+		if resultID != 0 {
+			nextCursor = encodeResultCursor(resultID, int64(request.PageSize))
+		}
+	}
+
+	// Anonymize and mask only what is returned; the stored result keeps the real values
+	data = present(data)
 
-	// Save result
-	s.db.Create(queryResult)
+	// Annotate numeric columns with their unit, currency and scale for rendering
+	columns := s.formatter.Annotate(userID, dataSource.ID, result.Columns, data)
+
+	// Insights cover the whole result, in its original currency
+	var insights *models.QueryInsights
+	if request.IncludeInsights {
+		rows := data
+		if len(data) < len(result.Data) {
+			rows = present(result.Data)
+		}
+		insights = s.insights.Summarize(WithUsageUser(s.db.Statement.Context, userID), query.NLQuery, columns, rows)
+	}
+
+	if request.Currency != "" {
+		columns, data = s.formatter.Convert(columns, data, request.Currency)
+	}
+
+	// Federated queries join pulled tables rather than one materialized copy
+	var freshness *models.DataFreshness
+	if federation == nil {
+		freshness = s.materializations.Freshness(&dataSource)
+	}
 
 	return &models.QueryExecutionResponse{
 		QueryID:       query.ID,
-		Columns:       result.Columns,
-		Data:          result.Data,
+		Columns:       columns,
+		Data:          data,
 		RowCount:      int64(len(result.Data)),
 		ExecutionTime: executionTime,
 		Status:        models.QueryStatusCompleted,
 		Message:       "Query executed successfully",
+		Anonymized:    request.Anonymize,
+		MaskedColumns: maskedColumns,
+		CostEstimate:  costEstimate,
+		ExecutedSQL:   executedSQL,
+		ResultID:      resultID,
+		NextCursor:    nextCursor,
+		Insights:      insights,
+		Freshness:     freshness,
+		Federation:    federation,
 	}, nil
 }
 
@@ -264,8 +585,8 @@ func (s *NL2SQLService) DeleteQuery(userID uint, queryID uint) error {
 	}
 
 	// Delete associated query results first
-	if err := s.db.Where("query_id = ?", queryID).Delete(&models.QueryResult{}).Error; err != nil {
-		return fmt.Errorf("failed to delete query results: %v", err)
+	if err := s.resultService.DeleteForQuery(s.db, queryID); err != nil {
+		return err
 	}
 
 	// Delete the query
@@ -276,6 +597,27 @@ func (s *NL2SQLService) DeleteQuery(userID uint, queryID uint) error {
 	return nil
 }
 
+// GetQueryResults returns a page of a stored result of the user's query
+func (s *NL2SQLService) GetQueryResults(userID uint, queryID uint, request *models.QueryResultPageRequest) (*models.QueryResultPage, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.resultService.Page(query.ID, request)
+	if err != nil {
+		return nil, err
+	}
+	if request.Anonymize {
+		page.Data = s.anonymizer.AnonymizeRows(page.Columns, page.Data)
+		page.Anonymized = true
+	}
+	if !request.UnmaskPII {
+		page.Data, page.MaskedColumns = s.maskQueryRows(query, page.Columns, page.Data)
+	}
+	return page, nil
+}
+
 // GetQueryHistory gets query history for a user
 func (s *NL2SQLService) GetQueryHistory(userID uint, limit int, offset int) ([]*models.QueryHistoryResponse, error) {
 	var queries []models.NL2SQLQuery
@@ -362,7 +704,7 @@ func (s *NL2SQLService) buildSchemaContext(dataSource *models.DataSource) (map[s
 }
 
 // buildEnhancedContext builds context using RAG system for better NL2SQL conversion
-func (s *NL2SQLService) buildEnhancedContext(dataSource *models.DataSource, nlQuery string) (map[string]interface{}, error) {
+func (s *NL2SQLService) buildEnhancedContext(ctx context.Context, userID uint, dataSource *models.DataSource, nlQuery string, opts NL2SQLContextOptions) (map[string]interface{}, error) {
 	// Get basic schema context
 	schemaContext, err := s.buildSchemaContext(dataSource)
 	if err != nil {
@@ -370,7 +712,7 @@ func (s *NL2SQLService) buildEnhancedContext(dataSource *models.DataSource, nlQu
 	}
 
 	// Use RAG service to build enhanced context
-	ragContext, err := s.ragService.BuildNL2SQLContext(context.Background(), nlQuery, dataSource.ID)
+	ragContext, queryEmbedding, err := s.ragService.buildNL2SQLContext(ctx, userID, nlQuery, dataSource.ID, opts)
 	if err != nil {
 		// If RAG fails, fallback to basic schema context
 		return schemaContext, nil
@@ -386,11 +728,37 @@ func (s *NL2SQLService) buildEnhancedContext(dataSource *models.DataSource, nlQu
 		"business_glossary":  ragContext["business_glossary"],
 		"query_examples":     ragContext["query_examples"],
 		"enhanced_prompt":    ragContext["enhanced_prompt"],
+		"reranked":           ragContext["reranked"],
+		"retrieval":          ragContext["retrieval"],
+		"retrieved":          ragContext["retrieved"],
+		"query_embedding":    queryEmbedding,
+		"custom_functions":   ragContext["custom_functions"],
+		"sql_dialect":        models.DialectForDataSourceType(dataSource.Type),
 	}
 
+	// Render the prompt with the active template and remember which version it was
+	prompt, template, err := s.ragService.RenderNL2SQLPrompt(nlQuery, dataSource.ID, ragContext)
+	if err != nil {
+		logger.FromContext(ctx).Warn().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to render NL2SQL prompt")
+		return enhancedContext, nil
+	}
+	enhancedContext["enhanced_prompt"] = prompt
+	enhancedContext["prompt_template"] = template
+	enhancedContext["prompt_budget"] = ragContext["prompt_budget"]
+
 	return enhancedContext, nil
 }
 
+// recordGenerationUsage records the LLM usage of generating a query. The
+// generator does not report tokens, so they are estimated from the prompt and output.
+func (s *NL2SQLService) recordGenerationUsage(ctx context.Context, nlQuery string, enhancedContext map[string]interface{}, generated string) {
+	prompt, _ := enhancedContext["enhanced_prompt"].(string)
+	if prompt == "" {
+		prompt = nlQuery
+	}
+	s.usageService.Record(ctx, models.UsageKindLLM, s.usageService.llmModel(), estimateTokens(prompt), estimateTokens(generated))
+}
+
 // generateSQL generates SQL from natural language (mock implementation)
 func (s *NL2SQLService) generateSQL(nlQuery string, schemaContext map[string]interface{}) (string, error) {
 	// This is a mock implementation
@@ -478,16 +846,351 @@ func (s *NL2SQLService) generateSQLWithEnhancedPatterns(nlQuery string, enhanced
 	return "SELECT * FROM sales LIMIT 100", nil
 }
 
+// UpdateQuerySQL replaces the SQL of a query with a human edit and records it as a new version
+func (s *NL2SQLService) UpdateQuerySQL(userID uint, queryID uint, request *models.QuerySQLUpdateRequest) (*models.QuerySQLVersionResponse, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applySQLVersion(userID, query, request.SQL, models.SQLVersionSourceHumanEdit, request.Comment, 0, request.Confirm)
+}
+
+// RefineQuerySQL regenerates the SQL of a query from its question, its current
+// SQL and a correction hint. The result becomes a new version, recorded with the
+// hint as comment and returned with its diff against the SQL it replaced.
+func (s *NL2SQLService) RefineQuerySQL(userID uint, queryID uint, request *models.QuerySQLRefineRequest) (*models.QuerySQLRefineResponse, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+	dataSource, err := s.validateDataSourceAccess(userID, query.DataSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("data source validation failed: %v", err)
+	}
+
+	ctx := WithUsageUser(s.db.Statement.Context, userID)
+	if err := s.usageService.CheckQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	// The hint takes part in retrieval, so tables it names reach the prompt
+	hint := strings.TrimSpace(request.Hint)
+	question := query.NLQuery + "\n" + hint
+	enhancedContext, err := s.buildEnhancedContext(ctx, userID, dataSource, question, NL2SQLContextOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build enhanced context: %v", err)
+	}
+	if prompt, _ := enhancedContext["enhanced_prompt"].(string); prompt != "" {
+		enhancedContext["enhanced_prompt"] = prompt + refinementPromptSection(query.GeneratedSQL, hint)
+	}
+
+	var generatedSQL string
+	if dataSource.Type.UsesAggregationPipeline() {
+		generatedSQL, err = s.generatePipeline(question, enhancedContext)
+	} else {
+		generatedSQL, err = s.generateSQLWithRAG(question, enhancedContext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SQL generation failed: %v", err)
+	}
+	s.recordGenerationUsage(ctx, question, enhancedContext, generatedSQL)
+
+	response, err := s.applySQLVersion(userID, query, generatedSQL, models.SQLVersionSourceRefinement, hint, 0, request.Confirm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Queries generated before versioning get their original SQL recorded first,
+	// so the replaced SQL is always the previous version
+	diff, err := s.versionService.Diff(query.ID, response.Version.Version-1, response.Version.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &models.QuerySQLRefineResponse{QuerySQLVersionResponse: *response, Diff: diff}, nil
+}
+
+// refinementPromptSection asks the generator to revise the previous SQL of a
+// query following a correction hint
+func refinementPromptSection(previousSQL, hint string) string {
+	return "\n\nPREVIOUS SQL:\n" + previousSQL +
+		"\n\nCORRECTION: " + hint +
+		"\nRevise the previous SQL to follow the correction; keep what the correction does not change."
+}
+
+// RollbackQuerySQL restores the SQL of an earlier version. History is append-only,
+// so the restored SQL becomes a new version.
+func (s *NL2SQLService) RollbackQuerySQL(userID uint, queryID uint, version int, request *models.QuerySQLRollbackRequest) (*models.QuerySQLVersionResponse, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.versionService.Get(query.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if target.Version == query.SQLVersion {
+		return nil, fmt.Errorf("version %d is already the current version", version)
+	}
+
+	return s.applySQLVersion(userID, query, target.SQL, models.SQLVersionSourceRollback, request.Comment, target.Version, request.Confirm)
+}
+
+// ListQueryVersions returns the SQL version history of a query
+func (s *NL2SQLService) ListQueryVersions(userID uint, queryID uint) ([]models.QuerySQLVersion, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.versionService.List(query.ID)
+}
+
+// GetQueryVersion returns a single SQL version of a query
+func (s *NL2SQLService) GetQueryVersion(userID uint, queryID uint, version int) (*models.QuerySQLVersion, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.versionService.Get(query.ID, version)
+}
+
+// DiffQueryVersions returns the diff between two SQL versions of a query
+func (s *NL2SQLService) DiffQueryVersions(userID uint, queryID uint, fromVersion, toVersion int) (*models.SQLVersionDiff, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.versionService.Diff(query.ID, fromVersion, toVersion)
+}
+
+// applySQLVersion validates new SQL for a query, makes it current and records the version.
+// When dashboards show the query, a canary run must not diverge unless confirm is set.
+func (s *NL2SQLService) applySQLVersion(userID uint, query *models.NL2SQLQuery, sql string, source models.SQLVersionSource, comment string, rolledBackFrom int, confirm bool) (*models.QuerySQLVersionResponse, error) {
+	var dataSource models.DataSource
+	if err := s.db.First(&dataSource, query.DataSourceID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get data source: %v", err)
+	}
+
+	sql, validationResult, err := s.prepareQueryFor(query, &dataSource, sql)
+	if err != nil {
+		return nil, err
+	}
+	if (source == models.SQLVersionSourceHumanEdit || source == models.SQLVersionSourceSuggestion || source == models.SQLVersionSourceRefinement) && sql == query.GeneratedSQL {
+		return nil, errors.New("SQL is unchanged")
+	}
+	params, err := detectQueryParameters(sql, queryDialect(query, &dataSource))
+	if err != nil {
+		return nil, err
+	}
+
+	var version *models.QuerySQLVersion
+	var canary *models.CanaryReport
+	canExecute := s.sqlValidator.IsQuerySafe(validationResult)
+	if canExecute {
+		if canary, err = s.runCanary(&dataSource, query, sql, confirm); err != nil {
+			return nil, err
+		}
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Queries generated before versioning get their original SQL recorded first
+		if query.SQLVersion == 0 && query.GeneratedSQL != "" {
+			if _, err := s.versionService.Record(tx, query, models.SQLVersionSourceGenerated, query.UserID, "", 0); err != nil {
+				return err
+			}
+		}
+
+		query.GeneratedSQL = sql
+		query.Parameters = marshalQueryParameters(params)
+		setQueryValidation(query, validationResult)
+		if canExecute {
+			query.Status = models.QueryStatusCompleted
+			query.ErrorMsg = ""
+			query.UpdatedAt = time.Now()
+		} else {
+			query.MarkFailed("Query failed safety validation")
+		}
+
+		var err error
+		if version, err = s.versionService.Record(tx, query, source, userID, comment, rolledBackFrom); err != nil {
+			return err
+		}
+		if canary != nil {
+			version.Canary = marshalCanaryReport(canary)
+			if err := tx.Model(version).Update("canary", version.Canary).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(query).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save SQL version: %v", err)
+	}
+
+	return &models.QuerySQLVersionResponse{
+		QueryID:    query.ID,
+		Version:    *version,
+		Validation: *validationResult,
+		CanExecute: canExecute,
+		Canary:     canary,
+	}, nil
+}
+
+// setQueryValidation replaces the validation result in the metadata of a
+// query, so it describes the current SQL rather than the generated one
+func setQueryValidation(query *models.NL2SQLQuery, validationResult *models.SQLValidationResult) {
+	metadata := map[string]interface{}{}
+	if len(query.Metadata) > 0 {
+		_ = json.Unmarshal(query.Metadata, &metadata)
+	}
+	metadata["validation_result"] = validationResult
+	metadata["validated_at"] = time.Now()
+	if metadataJSON, err := json.Marshal(metadata); err == nil {
+		query.Metadata = models.JSON(metadataJSON)
+	}
+}
+
+// prepareQuery prepares the SQL of a query, or its aggregation pipeline for
+// data sources queried with pipelines
+func (s *NL2SQLService) prepareQuery(dataSource *models.DataSource, sql string) (string, *models.SQLValidationResult, error) {
+	if dataSource.Type.UsesAggregationPipeline() {
+		return s.preparePipeline(dataSource, sql)
+	}
+	return s.prepareSQL(dataSource, sql)
+}
+
+// preparePipeline validates an aggregation query against the data source
+// validation policy and enforces a $limit within the policy maximum
+func (s *NL2SQLService) preparePipeline(dataSource *models.DataSource, text string) (string, *models.SQLValidationResult, error) {
+	rules, err := s.policyService.RulesForDataSource(dataSource.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, err := ParseAggregationQuery(text)
+	if err != nil {
+		return "", nil, fmt.Errorf("pipeline validation failed: %v", err)
+	}
+	s.sqlValidator.EnforcePipelineLimit(query, rules.RowLimit(1000))
+	validationResult := s.sqlValidator.ValidatePipeline(query, rules)
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return "", validationResult, fmt.Errorf("failed to encode pipeline: %v", err)
+	}
+	return string(queryJSON), validationResult, nil
+}
+
+// prepareSQL normalizes SQL to the data source dialect, validates it against the
+// data source validation policy and enforces a LIMIT within the policy maximum.
+// Placeholders are validated as NULL values.
+func (s *NL2SQLService) prepareSQL(dataSource *models.DataSource, sql string) (string, *models.SQLValidationResult, error) {
+	return s.prepareSQLForDialect(dataSource.ID, models.DialectForDataSourceType(dataSource.Type), sql)
+}
+
+// prepareSQLForDialect prepares SQL written in a dialect under the validation
+// policy of a data source
+func (s *NL2SQLService) prepareSQLForDialect(dataSourceID uint, dialect models.SQLDialect, sql string) (string, *models.SQLValidationResult, error) {
+	rules, err := s.policyService.RulesForDataSource(dataSourceID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if normalizedSQL, err := s.sqlValidator.NormalizeSQL(sql, dialect); err == nil {
+		sql = normalizedSQL
+	}
+	if rules != nil {
+		sql = s.sqlValidator.CapLimitForDialect(sql, rules.MaxLimit, dialect)
+	}
+
+	validationResult, err := s.sqlValidator.ValidateSQLWithRules(renderQueryParameters(sql, nil), dialect, rules)
+	if err != nil {
+		return "", validationResult, fmt.Errorf("SQL validation failed: %v", err)
+	}
+
+	// Enforce LIMIT if not present
+	if !validationResult.HasLimit {
+		if sql, err = s.sqlValidator.EnforceLimitForDialect(sql, rules.RowLimit(1000), dialect); err != nil {
+			return "", validationResult, fmt.Errorf("failed to enforce LIMIT: %v", err)
+		}
+		// Re-validate after adding LIMIT
+		validationResult, _ = s.sqlValidator.ValidateSQLWithRules(renderQueryParameters(sql, nil), dialect, rules)
+	}
+
+	return sql, validationResult, nil
+}
+
+// ValidateSQLForDataSource validates SQL against the dialect and validation
+// policy of a data source the user can access
+func (s *NL2SQLService) ValidateSQLForDataSource(userID uint, dataSourceID uint, sql string) (*models.SQLValidationResult, error) {
+	dataSource, err := s.validateDataSourceAccess(userID, dataSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("data source validation failed: %v", err)
+	}
+
+	if dataSource.Type.UsesAggregationPipeline() {
+		_, validationResult, err := s.preparePipeline(dataSource, sql)
+		return validationResult, err
+	}
+
+	rules, err := s.policyService.RulesForDataSource(dataSource.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sqlValidator.ValidateSQLWithRules(renderQueryParameters(sql, nil), models.DialectForDataSourceType(dataSource.Type), rules)
+}
+
+// checkQueryCost estimates a query and returns ErrQueryCostExceeded when it is over
+// the effective cost ceiling of the user and data source
+func (s *NL2SQLService) checkQueryCost(userID uint, dataSource *models.DataSource, sql string) (*models.QueryCostEstimate, error) {
+	var validation *models.SQLValidationResult
+	var err error
+	if dataSource.Type.UsesAggregationPipeline() {
+		if _, validation, err = s.preparePipeline(dataSource, sql); err != nil {
+			return nil, err
+		}
+	} else {
+		var rules *ValidationRules
+		if rules, err = s.policyService.RulesForDataSource(dataSource.ID); err != nil {
+			return nil, err
+		}
+		if validation, err = s.sqlValidator.ValidateSQLWithRules(sql, models.DialectForDataSourceType(dataSource.Type), rules); err != nil {
+			return nil, fmt.Errorf("SQL validation failed: %v", err)
+		}
+	}
+
+	estimate := s.costService.Estimate(dataSource, sql, validation.EstimatedCost)
+	if err := s.costService.Check(userID, dataSource.ID, estimate); err != nil {
+		return nil, fmt.Errorf("failed to check cost ceiling: %v", err)
+	}
+	if estimate.ExceedsCeiling {
+		return estimate, fmt.Errorf("%w: %s", ErrQueryCostExceeded, strings.Join(estimate.Violations, "; "))
+	}
+	return estimate, nil
+}
+
 // executeQueryOnDataSource executes query on the specified data source
-func (s *NL2SQLService) executeQueryOnDataSource(dataSource *models.DataSource, sql string, limit int) (*QueryResult, error) {
+func (s *NL2SQLService) executeQueryOnDataSource(dataSource *models.DataSource, sql string, limit int) (_ *QueryResult, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveConnectorQuery(string(dataSource.Type), start, err) }()
+
 	// Use connector service to execute query
 	switch dataSource.Type {
 	case models.DataSourceTypePostgreSQL:
 		return s.executePostgreSQLQuery(dataSource, sql, limit)
-	case models.DataSourceTypeBigQuery:
+	case models.DataSourceTypeBigQuery, models.DataSourceTypeGA4:
 		return s.executeBigQueryQuery(dataSource, sql, limit)
-	case models.DataSourceTypeCSV, models.DataSourceTypeExcel:
+	case models.DataSourceTypeCSV, models.DataSourceTypeExcel, models.DataSourceTypeRESTAPI, models.DataSourceTypeGoogleDrive:
+		return s.executeFileQuery(dataSource, sql, limit)
+	case models.DataSourceTypeGoogleSheets:
+		// Sheets are too slow to query live, so only their materialized copy is queried
+		var config map[string]interface{}
+		if err := json.Unmarshal(dataSource.Config, &config); err != nil || !Materializes(dataSource.Type, config) {
+			return nil, fmt.Errorf("%w: set materialize to query Google Sheets", ErrNotMaterialized)
+		}
 		return s.executeFileQuery(dataSource, sql, limit)
+	case models.DataSourceTypeMongoDB:
+		return s.executeMongoDBQuery(dataSource, sql, limit)
 	default:
 		return nil, fmt.Errorf("unsupported data source type: %s", dataSource.Type)
 	}
@@ -499,36 +1202,104 @@ type QueryResult struct {
 	Data    []map[string]interface{}   `json:"data"`
 }
 
-// executePostgreSQLQuery executes query on PostgreSQL
+// executePostgreSQLQuery runs a query on a PostgreSQL data source through the
+// connector service, which reuses the pooled connection of the data source
 func (s *NL2SQLService) executePostgreSQLQuery(dataSource *models.DataSource, sql string, limit int) (*QueryResult, error) {
-	// Mock implementation - in real scenario, use the PostgreSQL connector
+	var config map[string]interface{}
+	if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid data source configuration: %v", err)
+	}
+
+	columns, rows, err := s.connectorService.ExecuteDataSourceQuery(dataSource.ID, dataSource.Type, config, sql, limit)
+	if err != nil {
+		return nil, err
+	}
+
 	return &QueryResult{
-		Columns: []models.Column{
-			{Name: "id", Type: "integer"},
-			{Name: "amount", Type: "decimal"},
-			{Name: "date", Type: "date"},
-		},
-		Data: []map[string]interface{}{
-			{"id": 1, "amount": 100.50, "date": "2024-01-15"},
-			{"id": 2, "amount": 250.75, "date": "2024-01-16"},
-		},
+		Columns: columns,
+		Data:    rows,
 	}, nil
 }
 
-// executeBigQueryQuery executes query on BigQuery
+// bigQueryExecutor is implemented by the BigQuery and GA4 connectors
+type bigQueryExecutor interface {
+	Connect(config map[string]interface{}) error
+	Disconnect() error
+	ExecuteQuery(query string, limit int, maxBytesBilled int64) ([]models.Column, []map[string]interface{}, error)
+}
+
+// executeBigQueryQuery runs a query through the BigQuery connector, or the GA4
+// one for GA4 exports. The job runs with the platform maximum bytes billed, so
+// a query the dry run underestimated fails rather than bill more.
 func (s *NL2SQLService) executeBigQueryQuery(dataSource *models.DataSource, sql string, limit int) (*QueryResult, error) {
-	// Mock implementation - in real scenario, use the BigQuery connector
+	var config map[string]interface{}
+	if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid data source configuration: %v", err)
+	}
+
+	var connector bigQueryExecutor = connectors.NewBigQueryConnector()
+	if dataSource.Type == models.DataSourceTypeGA4 {
+		connector = connectors.NewGA4Connector()
+	}
+	defer connector.Disconnect()
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to BigQuery: %w", err)
+	}
+
+	maxBytesBilled := s.costService.MaxBytesBilled()
+	columns, rows, err := connector.ExecuteQuery(sql, limit, maxBytesBilled)
+	if err != nil {
+		if connectors.IsBytesBilledLimitExceeded(err) {
+			return nil, fmt.Errorf("%w: query would bill more than %d bytes", ErrQueryCostExceeded, maxBytesBilled)
+		}
+		return nil, err
+	}
+
 	return &QueryResult{
-		Columns: []models.Column{
-			{Name: "total_sales", Type: "decimal"},
-		},
-		Data: []map[string]interface{}{
-			{"total_sales": 15750.25},
-		},
+		Columns: columns,
+		Data:    rows,
+	}, nil
+}
+
+// executeMongoDBQuery runs the aggregation pipeline of a query through the MongoDB connector
+func (s *NL2SQLService) executeMongoDBQuery(dataSource *models.DataSource, text string, limit int) (*QueryResult, error) {
+	query, err := ParseAggregationQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pipeline runs as stored, so never send one that is not read-only
+	if validation := s.sqlValidator.ValidatePipeline(query, nil); !validation.IsValid || !validation.IsReadOnly {
+		return nil, fmt.Errorf("pipeline failed validation: %s", strings.Join(validation.Violations, "; "))
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+		return nil, fmt.Errorf("invalid data source configuration: %v", err)
+	}
+
+	connector := connectors.NewMongoDBConnector()
+	defer connector.Disconnect()
+	if err := connector.Connect(config); err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	pipelineJSON, err := json.Marshal(query.Pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pipeline: %v", err)
+	}
+	rows, err := connector.Aggregate(query.Collection, pipelineJSON, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryResult{
+		Columns: pipelineResultColumns(rows),
+		Data:    rows,
 	}, nil
 }
 
-// executeFileQuery executes query on CSV/Excel files
+// executeFileQuery executes query on CSV/Excel files and materialized Sheets and REST API records
 func (s *NL2SQLService) executeFileQuery(dataSource *models.DataSource, sql string, limit int) (*QueryResult, error) {
 	// Mock implementation - in real scenario, use DuckDB or similar for SQL on files
 	return &QueryResult{
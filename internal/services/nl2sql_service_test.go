@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestRefinementPromptSection(t *testing.T) {
+	section := refinementPromptSection("SELECT SUM(amount) FROM sales LIMIT 1000", "use the orders table, not sales")
+
+	assert.Equal(t, "\n\nPREVIOUS SQL:\nSELECT SUM(amount) FROM sales LIMIT 1000"+
+		"\n\nCORRECTION: use the orders table, not sales"+
+		"\nRevise the previous SQL to follow the correction; keep what the correction does not change.", section)
+}
+
+func TestSetQueryValidation(t *testing.T) {
+	query := &models.NL2SQLQuery{Metadata: models.JSON(`{"validation_result":{"is_valid":false},"generated_at":"2025-09-01T00:00:00Z"}`)}
+
+	setQueryValidation(query, &models.SQLValidationResult{IsValid: true, SafetyScore: 1})
+
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal(query.Metadata, &metadata))
+	assert.Equal(t, true, metadata["validation_result"].(map[string]interface{})["is_valid"])
+	assert.Equal(t, "2025-09-01T00:00:00Z", metadata["generated_at"])
+	assert.Contains(t, metadata, "validated_at")
+}
+
+// embeddingRoundTripper answers embedding requests without calling the API
+type embeddingRoundTripper struct{}
+
+func (embeddingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	body := `{"data":[{"embedding":[0.1,0.2,0.3],"index":0}],"model":"test","usage":{"prompt_tokens":3,"total_tokens":3}}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestConvertNL2SQLDryRunWritesNothing(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.DataSource{}, &models.Schema{}, &models.NL2SQLQuery{}, &models.QuerySQLVersion{},
+		&models.RetrievalConfig{}, &models.PromptTemplate{}, &models.CustomSQLFunction{}, &models.ValidationPolicy{},
+		&models.BusinessGlossary{}, &models.KPIDefinition{}, &models.SemanticMetric{}, &models.SemanticDimension{},
+	))
+	// Nothing is retrieved, so the question needs no vector search
+	require.NoError(t, db.Create(&models.RetrievalConfig{}).Error)
+	dataSource := &models.DataSource{UserID: 1, Name: "warehouse", Type: models.DataSourceTypePostgreSQL, Status: models.ConnectionStatusActive}
+	require.NoError(t, db.Create(dataSource).Error)
+
+	embeddings := NewEmbeddingService(db, "test-key", "", nil)
+	embeddings.client = &http.Client{Transport: embeddingRoundTripper{}}
+	service := NewNL2SQLService(db, nil, NewRAGService(db, embeddings, NewRerankService("", "", "", 0)), nil, nil, nil)
+
+	// SQLite cannot store the question embedding, so question history writes are counted as they are attempted
+	var historyWrites int
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:question_history", func(tx *gorm.DB) {
+		if tx.Statement.Table == "rag_query_contexts" {
+			historyWrites++
+		}
+	}))
+
+	count := func(model interface{}) int64 {
+		var n int64
+		require.NoError(t, db.Model(model).Count(&n).Error)
+		return n
+	}
+
+	response, err := service.ConvertNL2SQL(1, &models.NL2SQLRequest{DataSourceID: dataSource.ID, NLQuery: "total sales", DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, response.DryRun)
+	assert.Contains(t, response.GeneratedSQL, "SUM(amount)")
+	assert.Zero(t, response.QueryID)
+	assert.Zero(t, count(&models.NL2SQLQuery{}), "a dry run saves no query")
+	assert.Zero(t, count(&models.QuerySQLVersion{}), "a dry run records no SQL version")
+	assert.Zero(t, historyWrites, "a dry run leaves the question history alone")
+
+	// The same conversion without dry_run writes all three
+	response, err = service.ConvertNL2SQL(1, &models.NL2SQLRequest{DataSourceID: dataSource.ID, NLQuery: "total sales"})
+	require.NoError(t, err)
+	assert.NotZero(t, response.QueryID)
+	assert.Equal(t, int64(1), count(&models.NL2SQLQuery{}))
+	assert.Equal(t, int64(1), count(&models.QuerySQLVersion{}))
+	assert.Equal(t, 1, historyWrites)
+}
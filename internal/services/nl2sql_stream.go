@@ -0,0 +1,137 @@
+package services
+
+import (
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+)
+
+// nl2sqlStreamBatchRows is the number of rows sent per rows_streamed event
+const nl2sqlStreamBatchRows = 100
+
+// nl2sqlProgress receives the stage events of a conversion or execution. A nil
+// progress ignores them, which is how the non-streaming endpoints run.
+type nl2sqlProgress func(models.NL2SQLProgressEvent)
+
+func (p nl2sqlProgress) emit(event models.NL2SQLProgressEvent) {
+	if p == nil {
+		return
+	}
+	event.Timestamp = time.Now()
+	p(event)
+}
+
+// StreamNL2SQL converts a question and executes the SQL when it is safe,
+// reporting every stage to emit. Failures are reported as an error event
+// rather than returned, since the response is already streaming by then.
+func (s *NL2SQLService) StreamNL2SQL(userID uint, request *models.NL2SQLStreamRequest, emit func(models.NL2SQLProgressEvent)) {
+	progress := nl2sqlProgress(emit)
+
+	// Streaming executes the query, which needs its record
+	if request.DryRun {
+		progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageError, Message: "dry_run is not supported when streaming"})
+		return
+	}
+
+	conversion, err := s.convertNL2SQL(userID, &request.NL2SQLRequest, progress)
+	if err != nil {
+		progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageError, Message: err.Error()})
+		return
+	}
+
+	done := models.NL2SQLProgressEvent{
+		Stage:      models.NL2SQLStageDone,
+		QueryID:    conversion.QueryID,
+		Conversion: conversion,
+	}
+	if !conversion.CanExecute {
+		done.Message = "Query was not executed: it is not ready for execution"
+		progress.emit(done)
+		return
+	}
+	if len(conversion.Parameters) > 0 {
+		done.Message = "Query was not executed: it needs parameter values"
+		progress.emit(done)
+		return
+	}
+
+	execution, err := s.executeQuery(userID, &models.QueryExecutionRequest{
+		QueryID:   conversion.QueryID,
+		Limit:     request.Limit,
+		Anonymize: request.Anonymize,
+		UnmaskPII: request.UnmaskPII,
+	}, progress)
+	if err != nil {
+		progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageError, QueryID: conversion.QueryID, Message: err.Error()})
+		return
+	}
+	if execution.Status != models.QueryStatusCompleted {
+		progress.emit(models.NL2SQLProgressEvent{
+			Stage:     models.NL2SQLStageError,
+			QueryID:   conversion.QueryID,
+			Message:   execution.Message,
+			Execution: execution,
+		})
+		return
+	}
+
+	// The rows went out in rows_streamed events already
+	execution.Data = nil
+	done.Message = execution.Message
+	done.Execution = execution
+	progress.emit(done)
+}
+
+// storeQueryResult writes the rows of an execution to a chunked result and
+// reports them in batches as they are written, passed through present to
+// anonymize or mask them. It returns the stored result ID, or 0 when the
+// result could not be stored; execution still succeeds then.
+func (s *NL2SQLService) storeQueryResult(query *models.NL2SQLQuery, result *QueryResult, present func([]map[string]interface{}) []map[string]interface{}, progress nl2sqlProgress) uint {
+	writer, err := s.resultService.NewWriter(query.ID, query.SQLVersion, result.Columns)
+	if err != nil {
+		logger.L().Error().Err(err).Uint("query_id", query.ID).Msg("Failed to store query result")
+	}
+
+	for start := 0; start < len(result.Data); start += nl2sqlStreamBatchRows {
+		end := start + nl2sqlStreamBatchRows
+		if end > len(result.Data) {
+			end = len(result.Data)
+		}
+		batch := result.Data[start:end]
+
+		if writer != nil {
+			for _, row := range batch {
+				if err := writer.Write(row); err != nil {
+					logger.L().Error().Err(err).Uint("query_id", query.ID).Msg("Failed to store query result")
+					writer = nil
+					break
+				}
+			}
+		}
+
+		if progress != nil {
+			rows := present(batch)
+			event := models.NL2SQLProgressEvent{
+				Stage:     models.NL2SQLStageRowsStreamed,
+				QueryID:   query.ID,
+				Rows:      rows,
+				RowOffset: int64(start),
+			}
+			if start == 0 {
+				event.Columns = result.Columns
+			}
+			progress.emit(event)
+		}
+	}
+
+	if writer == nil {
+		return 0
+	}
+	stored, err := writer.Close()
+	if err != nil {
+		logger.L().Error().Err(err).Uint("query_id", query.ID).Msg("Failed to store query result")
+		return 0
+	}
+	return stored.ID
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNL2SQLProgress_Emit(t *testing.T) {
+	var nilProgress nl2sqlProgress
+	assert.NotPanics(t, func() {
+		nilProgress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageExecuting})
+	})
+
+	var events []models.NL2SQLProgressEvent
+	progress := nl2sqlProgress(func(event models.NL2SQLProgressEvent) {
+		events = append(events, event)
+	})
+	progress.emit(models.NL2SQLProgressEvent{Stage: models.NL2SQLStageContextBuilt, QueryID: 7})
+
+	require.Len(t, events, 1)
+	assert.Equal(t, models.NL2SQLStageContextBuilt, events[0].Stage)
+	assert.Equal(t, uint(7), events[0].QueryID)
+	assert.False(t, events[0].Timestamp.IsZero())
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+)
+
+// queryResultURLFormat links an answer to the stored result it was read from
+const queryResultURLFormat = "/api/v1/nl2sql/queries/%d/results?result_id=%d"
+
+// AnswerQuestion converts and runs a question and, when the result is a single
+// value, phrases it as one sentence using the unit of the matching KPI definition.
+// Questions that produce tables are still stored and linked, just not answered.
+func (s *NL2SQLService) AnswerQuestion(userID uint, request *models.NL2SQLAnswerRequest) (*models.NL2SQLAnswerResponse, error) {
+	converted, err := s.ConvertNL2SQL(userID, &models.NL2SQLRequest{
+		NLQuery:      request.NLQuery,
+		DataSourceID: request.DataSourceID,
+		Type:         models.QueryTypeAnalytics,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.NL2SQLAnswerResponse{
+		QueryID:      converted.QueryID,
+		GeneratedSQL: converted.GeneratedSQL,
+	}
+	if !converted.CanExecute {
+		response.Message = strings.Join(converted.Messages, "; ")
+		return response, nil
+	}
+	if len(converted.Parameters) > 0 {
+		response.Message = "Query needs parameter values; execute it with parameters instead"
+		return response, nil
+	}
+
+	// Two rows are enough to tell a single value from a table
+	result, err := s.ExecuteQuery(userID, &models.QueryExecutionRequest{QueryID: converted.QueryID, Limit: 2})
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != models.QueryStatusCompleted {
+		response.Message = result.Message
+		return response, nil
+	}
+	if result.ResultID != 0 {
+		response.ResultID = result.ResultID
+		response.ResultURL = fmt.Sprintf(queryResultURLFormat, result.QueryID, result.ResultID)
+	}
+
+	column, value, ok := answerValue(result.Columns, result.Data)
+	if !ok {
+		response.Message = "The result is not a single value; see the full result"
+		return response, nil
+	}
+
+	var kpis []models.KPIDefinition
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&kpis).Error; err != nil {
+		return nil, fmt.Errorf("failed to get KPI definitions: %v", err)
+	}
+
+	label := humanizeColumnName(column)
+	kpi := matchAnswerKPI(request.NLQuery, kpis)
+	if kpi != nil {
+		response.KPI = kpi.Name
+		response.Unit = kpi.Unit
+		label = kpi.DisplayName
+		if label == "" {
+			label = humanizeColumnName(kpi.Name)
+		}
+	}
+
+	response.Answered = true
+	response.Value = value
+	if number, ok := answerNumber(value); ok {
+		response.Value = number
+
+		// Remember the KPI value so digests can report how it moved
+		if kpi != nil {
+			snapshot := &models.KPISnapshot{
+				UserID:     userID,
+				KPIID:      kpi.ID,
+				QueryID:    result.QueryID,
+				Value:      number,
+				RecordedAt: time.Now(),
+			}
+			if err := s.db.Create(snapshot).Error; err != nil {
+				logger.L().Error().Err(err).Uint("kpi_id", kpi.ID).Msg("Failed to record KPI value")
+			}
+		}
+	}
+	response.Answer = fmt.Sprintf("%s is %s.", label, formatAnswerValue(value, response.Unit))
+	return response, nil
+}
+
+// answerValue returns the value of a single-row result: its first numeric
+// column, or its only column when none is numeric
+func answerValue(columns []models.Column, rows []map[string]interface{}) (string, interface{}, bool) {
+	if len(rows) != 1 || len(columns) == 0 {
+		return "", nil, false
+	}
+
+	row := rows[0]
+	for _, column := range columns {
+		if _, ok := answerNumber(row[column.Name]); ok {
+			return column.Name, row[column.Name], true
+		}
+	}
+	if len(columns) == 1 && row[columns[0].Name] != nil {
+		return columns[0].Name, row[columns[0].Name], true
+	}
+	return "", nil, false
+}
+
+// answerNumber reads the numeric types drivers return, including NUMERIC
+// values that arrive as text
+func answerNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case []byte:
+		return answerNumber(string(v))
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+	default:
+		return 0, false
+	}
+}
+
+// matchAnswerKPI returns the KPI whose name appears in the question, preferring
+// the longest match so "net revenue" wins over "revenue"
+func matchAnswerKPI(nlQuery string, kpis []models.KPIDefinition) *models.KPIDefinition {
+	question := strings.ToLower(nlQuery)
+
+	var best *models.KPIDefinition
+	bestLength := 0
+	for i := range kpis {
+		for _, name := range []string{kpis[i].Name, strings.ReplaceAll(kpis[i].Name, "_", " "), kpis[i].DisplayName} {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && len(name) > bestLength && strings.Contains(question, name) {
+				best, bestLength = &kpis[i], len(name)
+			}
+		}
+	}
+	return best
+}
+
+// formatAnswerValue formats a value for a sentence. KPI units are free text;
+// percentage, count and currency get special formatting, anything else (like
+// "USD" or "ms") is appended as is.
+func formatAnswerValue(value interface{}, unit string) string {
+	number, ok := answerNumber(value)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	// Up to two decimals, without trailing zeros
+	formatted := formatAnswerNumber(number, 2)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimSuffix(strings.TrimRight(formatted, "0"), ".")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "":
+		return formatted
+	case "percentage", "percent", "%":
+		return formatted + "%"
+	case "count":
+		return formatAnswerNumber(math.Round(number), 0)
+	case "currency":
+		return formatAnswerNumber(number, 2)
+	default:
+		return formatted + " " + strings.TrimSpace(unit)
+	}
+}
+
+// formatAnswerNumber formats a number with thousands separators
+func formatAnswerNumber(number float64, decimals int) string {
+	formatted := strconv.FormatFloat(math.Abs(number), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(formatted, ".")
+
+	var b strings.Builder
+	if number < 0 && formatted != strconv.FormatFloat(0, 'f', decimals, 64) {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
+
+// humanizeColumnName turns a column name like total_sales into "Total sales"
+func humanizeColumnName(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+	if name == "" {
+		return "The result"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnswerValue(t *testing.T) {
+	columns := []models.Column{{Name: "region"}, {Name: "total_sales"}}
+
+	column, value, ok := answerValue(columns, []map[string]interface{}{{"region": "EU", "total_sales": []byte("1250.50")}})
+	assert.True(t, ok)
+	assert.Equal(t, "total_sales", column)
+	assert.Equal(t, []byte("1250.50"), value)
+
+	column, value, ok = answerValue([]models.Column{{Name: "top_region"}}, []map[string]interface{}{{"top_region": "EU"}})
+	assert.True(t, ok)
+	assert.Equal(t, "top_region", column)
+	assert.Equal(t, "EU", value)
+
+	_, _, ok = answerValue(columns, []map[string]interface{}{{"region": "EU"}, {"region": "US"}})
+	assert.False(t, ok, "more than one row")
+	_, _, ok = answerValue(columns, []map[string]interface{}{{"region": "EU", "total_sales": nil}})
+	assert.False(t, ok, "no numeric column among several")
+	_, _, ok = answerValue(columns, nil)
+	assert.False(t, ok, "no rows")
+}
+
+func TestMatchAnswerKPI(t *testing.T) {
+	kpis := []models.KPIDefinition{
+		{Name: "revenue", Unit: "USD"},
+		{Name: "net_revenue", Unit: "USD"},
+		{Name: "aov", DisplayName: "Average Order Value", Unit: "currency"},
+	}
+
+	assert.Equal(t, "revenue", matchAnswerKPI("What was revenue last month?", kpis).Name)
+	assert.Equal(t, "net_revenue", matchAnswerKPI("What was net revenue last month?", kpis).Name)
+	assert.Equal(t, "aov", matchAnswerKPI("average order value in March", kpis).Name)
+	assert.Nil(t, matchAnswerKPI("how many users signed up?", kpis))
+}
+
+func TestFormatAnswerValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		unit  string
+		want  string
+	}{
+		{1234567.891, "", "1,234,567.89"},
+		{int64(1500), "", "1,500"},
+		{"12500", "USD", "12,500 USD"},
+		{12.5, "percentage", "12.5%"},
+		{12.0, "%", "12%"},
+		{1499.6, "count", "1,500"},
+		{100, "currency", "100.00"},
+		{-2500.5, "", "-2,500.5"},
+		{-0.001, "", "0"},
+		{"EU", "", "EU"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatAnswerValue(tt.value, tt.unit), "%v %q", tt.value, tt.unit)
+	}
+}
+
+func TestHumanizeColumnName(t *testing.T) {
+	assert.Equal(t, "Total sales", humanizeColumnName("total_sales"))
+	assert.Equal(t, "Revenue", humanizeColumnName("revenue"))
+	assert.Equal(t, "The result", humanizeColumnName(""))
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/pubsub"
+)
+
+// notificationChannel is the broker channel notifications are relayed on
+const notificationChannel = "narapulse:notifications"
+
+// notificationBuffer is the number of notifications queued per connection. A
+// connection that falls further behind is closed; the client reconnects.
+const notificationBuffer = 64
+
+// NotificationHub pushes events of background work to the connected clients
+// of a user. Notifications go through the broker, so a job running on any
+// instance reaches the clients connected to every instance; clients that are
+// not connected miss them.
+type NotificationHub struct {
+	broker      pubsub.Broker
+	mu          sync.Mutex
+	subscribers map[uint]map[*notificationSubscriber]bool
+	now         func() time.Time
+}
+
+type notificationSubscriber struct {
+	notifications chan models.Notification
+}
+
+// NewNotificationHub creates a new notification hub
+func NewNotificationHub(broker pubsub.Broker) *NotificationHub {
+	return &NotificationHub{
+		broker:      broker,
+		subscribers: make(map[uint]map[*notificationSubscriber]bool),
+		now:         time.Now,
+	}
+}
+
+// Start relays the notifications of the broker to the clients connected to
+// this instance until ctx is done
+func (h *NotificationHub) Start(ctx context.Context) error {
+	return h.broker.Subscribe(ctx, notificationChannel, h.deliver)
+}
+
+// Subscribe opens a notification channel of the user. The returned function
+// closes it; the channel is also closed when the connection falls behind.
+func (h *NotificationHub) Subscribe(userID uint) (<-chan models.Notification, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &notificationSubscriber{notifications: make(chan models.Notification, notificationBuffer)}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*notificationSubscriber]bool)
+	}
+	h.subscribers[userID][sub] = true
+
+	var once sync.Once
+	return sub.notifications, func() {
+		once.Do(func() { h.unsubscribe(userID, sub) })
+	}
+}
+
+// Notify publishes an event to the clients of the user. Failures are logged,
+// as notifications never fail the work they report. A nil hub does nothing.
+func (h *NotificationHub) Notify(ctx context.Context, userID uint, typ models.NotificationType, data interface{}) {
+	if h == nil {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("type", string(typ)).Msg("Failed to encode notification")
+		return
+	}
+	notification, err := json.Marshal(models.Notification{
+		Type:      typ,
+		UserID:    userID,
+		Data:      payload,
+		Timestamp: h.now(),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("type", string(typ)).Msg("Failed to encode notification")
+		return
+	}
+	if err := h.broker.Publish(ctx, notificationChannel, notification); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("type", string(typ)).Uint("user_id", userID).Msg("Failed to publish notification")
+	}
+}
+
+// deliver passes a notification of the broker to the connections of its user
+func (h *NotificationHub) deliver(payload []byte) {
+	var notification models.Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		logger.L().Warn().Err(err).Msg("Ignoring malformed notification")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers[notification.UserID] {
+		select {
+		case sub.notifications <- notification:
+		default:
+			h.removeLocked(notification.UserID, sub)
+		}
+	}
+}
+
+func (h *NotificationHub) unsubscribe(userID uint, sub *notificationSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(userID, sub)
+}
+
+func (h *NotificationHub) removeLocked(userID uint, sub *notificationSubscriber) {
+	if !h.subscribers[userID][sub] {
+		return
+	}
+	delete(h.subscribers[userID], sub)
+	close(sub.notifications)
+	if len(h.subscribers[userID]) == 0 {
+		delete(h.subscribers, userID)
+	}
+}
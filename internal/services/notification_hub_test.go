@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/pubsub"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationHubDeliversToTheUser(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := NewNotificationHub(pubsub.NewMemoryBroker())
+	require.NoError(t, hub.Start(ctx))
+
+	analyst, leave := hub.Subscribe(1)
+	defer leave()
+	other, leaveOther := hub.Subscribe(2)
+	defer leaveOther()
+
+	hub.Notify(ctx, 1, models.NotificationDiscoveryFinished, models.DiscoveryFinishedNotification{
+		DataSourceID: 4,
+		Name:         "Sales",
+		Status:       models.ConnectionStatusActive,
+		Message:      "Schema discovered",
+	})
+
+	require.Len(t, analyst, 1)
+	notification := <-analyst
+	assert.Equal(t, models.NotificationDiscoveryFinished, notification.Type)
+	assert.Equal(t, uint(1), notification.UserID)
+	var data models.DiscoveryFinishedNotification
+	require.NoError(t, json.Unmarshal(notification.Data, &data))
+	assert.Equal(t, uint(4), data.DataSourceID)
+	assert.Empty(t, other, "other users are not notified")
+
+	// A nil hub, as in services built without one, does nothing
+	var none *NotificationHub
+	none.Notify(ctx, 1, models.NotificationAlertTriggered, nil)
+}
+
+func TestNotificationHubDropsSlowConnections(t *testing.T) {
+	hub := NewNotificationHub(pubsub.NewMemoryBroker())
+	require.NoError(t, hub.Start(context.Background()))
+
+	notifications, leave := hub.Subscribe(1)
+	for i := 0; i <= notificationBuffer; i++ {
+		hub.Notify(context.Background(), 1, models.NotificationReportCompleted, models.ReportCompletedNotification{ReportID: uint(i)})
+	}
+
+	received := 0
+	for range notifications {
+		received++
+	}
+	assert.Equal(t, notificationBuffer, received, "the channel is closed once the buffer overflows")
+	assert.NotContains(t, hub.subscribers, uint(1))
+	leave()
+}
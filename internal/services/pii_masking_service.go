@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// PII mask modes
+const (
+	PIIMaskModeRedact = "redact" // Replace values with piiRedacted
+	PIIMaskModeHash   = "hash"   // Replace values with a keyed hash, so equal values still match
+)
+
+// piiRedacted replaces the values of PII columns in redact mode
+const piiRedacted = "[REDACTED]"
+
+// piiDetectionSampleRows is the number of rows whose values are checked for emails and phone numbers
+const piiDetectionSampleRows = 20
+
+// PIIMaskingService masks PII columns in query results for callers without
+// the unmask permission. A result column is PII when a column of that name is
+// flagged as PII on the data source, or when its values look like emails or
+// phone numbers.
+type PIIMaskingService struct {
+	db        *gorm.DB
+	mode      string
+	secret    []byte
+	inference *SchemaInferenceService
+}
+
+// NewPIIMaskingService creates a new PII masking service; unknown modes redact
+func NewPIIMaskingService(db *gorm.DB, mode, secret string) *PIIMaskingService {
+	if mode != PIIMaskModeHash {
+		mode = PIIMaskModeRedact
+	}
+	return &PIIMaskingService{
+		db:        db,
+		mode:      mode,
+		secret:    []byte(secret),
+		inference: NewSchemaInferenceService(),
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *PIIMaskingService) WithContext(ctx context.Context) *PIIMaskingService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// MaskRows returns a copy of the rows with the PII columns masked, and the
+// masked column names. Rows are returned as-is when there is nothing to mask.
+func (s *PIIMaskingService) MaskRows(dataSourceID uint, columns []models.Column, rows []map[string]interface{}) ([]map[string]interface{}, []string) {
+	piiColumns := s.PIIColumns(dataSourceID, columns, rows)
+	if len(piiColumns) == 0 {
+		return rows, nil
+	}
+	return s.maskColumns(rows, piiColumns), piiColumns
+}
+
+// PIIColumns returns the result columns to mask, sorted
+func (s *PIIMaskingService) PIIColumns(dataSourceID uint, columns []models.Column, rows []map[string]interface{}) []string {
+	flagged, err := s.flaggedColumns(dataSourceID)
+	if err != nil {
+		// Fall back to pattern detection rather than failing the query
+		logger.L().Error().Err(err).Uint("data_source_id", dataSourceID).Msg("Failed to load PII column flags")
+	}
+
+	var piiColumns []string
+	for _, col := range columns {
+		if col.PII || flagged[strings.ToLower(col.Name)] || s.looksLikePII(col.Name, rows) {
+			piiColumns = append(piiColumns, col.Name)
+		}
+	}
+	sort.Strings(piiColumns)
+	return piiColumns
+}
+
+// flaggedColumns returns the lower-case names of the columns flagged as PII
+// on any table of the data source
+func (s *PIIMaskingService) flaggedColumns(dataSourceID uint) (map[string]bool, error) {
+	var schemas []models.Schema
+	if err := s.db.Select("columns").Where("data_source_id = ?", dataSourceID).Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schemas: %w", err)
+	}
+
+	flagged := make(map[string]bool)
+	for _, schema := range schemas {
+		var columns []models.Column
+		if len(schema.Columns) == 0 || json.Unmarshal(schema.Columns, &columns) != nil {
+			continue
+		}
+		for _, col := range columns {
+			if col.PII {
+				flagged[strings.ToLower(col.Name)] = true
+			}
+		}
+	}
+	return flagged, nil
+}
+
+// looksLikePII reports whether every non-empty string value of the column in
+// the first rows is an email or a phone number. Phone numbers need a
+// separator or a leading +, so plain numeric identifiers are not masked.
+func (s *PIIMaskingService) looksLikePII(column string, rows []map[string]interface{}) bool {
+	seen := 0
+	for i := 0; i < len(rows) && i < piiDetectionSampleRows; i++ {
+		str, ok := rows[i][column].(string)
+		if !ok {
+			if rows[i][column] != nil {
+				return false
+			}
+			continue
+		}
+		str = strings.TrimSpace(str)
+		if str == "" {
+			continue
+		}
+		if !s.inference.isEmailValue(str) && !(s.inference.isPhoneValue(str) && strings.ContainsAny(str, "+-(). ")) {
+			return false
+		}
+		seen++
+	}
+	return seen > 0
+}
+
+// maskColumns returns a copy of the rows with the values of the columns masked
+func (s *PIIMaskingService) maskColumns(rows []map[string]interface{}, piiColumns []string) []map[string]interface{} {
+	masked := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		newRow := make(map[string]interface{}, len(row))
+		for name, value := range row {
+			newRow[name] = value
+		}
+		for _, name := range piiColumns {
+			if value, ok := row[name]; ok && value != nil {
+				newRow[name] = s.maskValue(value)
+			}
+		}
+		masked[i] = newRow
+	}
+	return masked
+}
+
+func (s *PIIMaskingService) maskValue(value interface{}) interface{} {
+	if s.mode != PIIMaskModeHash {
+		return piiRedacted
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(fmt.Sprint(value)))
+	return "pii_" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPIIMaskingLooksLikePII(t *testing.T) {
+	s := NewPIIMaskingService(nil, PIIMaskModeRedact, "secret")
+	rows := []map[string]interface{}{
+		{"contact": "ana@example.com", "phone": "+62 812-3456-7890", "order_ref": "10000231", "amount": 12.5},
+		{"contact": "", "phone": "(021) 555-0199", "order_ref": "10000232", "amount": 3},
+		{"contact": nil, "phone": nil, "order_ref": "10000233", "amount": nil},
+	}
+
+	assert.True(t, s.looksLikePII("contact", rows))
+	assert.True(t, s.looksLikePII("phone", rows))
+	assert.False(t, s.looksLikePII("order_ref", rows))
+	assert.False(t, s.looksLikePII("amount", rows))
+	assert.False(t, s.looksLikePII("missing", rows))
+}
+
+func TestPIIMaskingMaskColumns(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"email": "ana@example.com", "name": "Ana"},
+		{"email": nil, "name": "Budi"},
+	}
+
+	redacted := NewPIIMaskingService(nil, "unknown", "secret").maskColumns(rows, []string{"email"})
+	assert.Equal(t, piiRedacted, redacted[0]["email"])
+	assert.Nil(t, redacted[1]["email"])
+	assert.Equal(t, "Ana", redacted[0]["name"])
+	// The input rows are not changed
+	assert.Equal(t, "ana@example.com", rows[0]["email"])
+
+	hasher := NewPIIMaskingService(nil, PIIMaskModeHash, "secret")
+	hashed := hasher.maskColumns(rows, []string{"email", "name"})
+	assert.Regexp(t, `^pii_[0-9a-f]{16}$`, hashed[0]["email"])
+	assert.Equal(t, hashed[0]["email"], hasher.maskValue("ana@example.com"))
+	assert.NotEqual(t, hashed[0]["name"], hashed[1]["name"])
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// readOnlyPipelineStages are the aggregation stages a generated pipeline may use
+var readOnlyPipelineStages = map[string]bool{
+	"$match": true, "$project": true, "$addFields": true, "$set": true, "$unset": true,
+	"$group": true, "$sort": true, "$limit": true, "$skip": true, "$count": true,
+	"$unwind": true, "$lookup": true, "$graphLookup": true, "$unionWith": true, "$facet": true,
+	"$bucket": true, "$bucketAuto": true, "$sortByCount": true, "$replaceRoot": true,
+	"$replaceWith": true, "$sample": true, "$densify": true, "$fill": true,
+	"$setWindowFields": true, "$redact": true, "$geoNear": true,
+}
+
+// writePipelineStages modify data; they are reported as write operations
+var writePipelineStages = map[string]bool{
+	"$out": true, "$merge": true,
+}
+
+// blockedPipelineOperators run server-side JavaScript
+var blockedPipelineOperators = map[string]bool{
+	"$function": true, "$accumulator": true, "$where": true,
+}
+
+// ParseAggregationQuery decodes a stored aggregation query
+func ParseAggregationQuery(text string) (*models.AggregationQuery, error) {
+	var query models.AggregationQuery
+	if err := json.Unmarshal([]byte(text), &query); err != nil {
+		return nil, fmt.Errorf("invalid aggregation query: %v", err)
+	}
+	if query.Collection == "" {
+		return nil, errors.New("invalid aggregation query: collection is required")
+	}
+	return &query, nil
+}
+
+// ValidatePipeline validates an aggregation pipeline: every stage must be a
+// read-only stage, nested pipelines included, and no operator may run
+// JavaScript. Policy rules cap $limit and require a leading $match on large
+// collections. Results use the same shape and safety score as SQL validation.
+func (s *SQLValidatorService) ValidatePipeline(query *models.AggregationQuery, rules *ValidationRules) *models.SQLValidationResult {
+	result := &models.SQLValidationResult{
+		Dialect:    models.SQLDialectMongoDB,
+		IsReadOnly: true,
+		Violations: []string{},
+		Warnings:   []string{},
+	}
+
+	stages := make([]interface{}, 0, len(query.Pipeline))
+	for i, raw := range query.Pipeline {
+		var stage interface{}
+		if err := json.Unmarshal(raw, &stage); err != nil {
+			result.Violations = append(result.Violations, fmt.Sprintf("Stage %d is not valid JSON", i+1))
+			continue
+		}
+		stages = append(stages, stage)
+	}
+	checkPipelineStages(stages, "", result)
+
+	for _, stage := range stages {
+		body, op := pipelineStage(stage)
+		switch op {
+		case "$limit":
+			result.HasLimit = true
+			if rules != nil && rules.MaxLimit > 0 {
+				if n, ok := body.(float64); !ok || int(n) > rules.MaxLimit {
+					result.Violations = append(result.Violations, fmt.Sprintf("$limit %v exceeds the maximum of %d documents", body, rules.MaxLimit))
+				}
+			}
+		case "$lookup", "$graphLookup", "$unionWith":
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s reads another collection", op))
+		}
+	}
+
+	if rules != nil {
+		if rowCount, ok := rules.LargeTables[strings.ToLower(query.Collection)]; ok {
+			if _, op := pipelineStage(firstStage(stages)); op != "$match" {
+				result.Violations = append(result.Violations, fmt.Sprintf("Collection %s has %d documents; a leading $match stage is required", query.Collection, rowCount))
+			}
+		}
+	}
+
+	result.IsValid = len(result.Violations) == 0
+	result.EstimatedCost = float64(len(stages))
+	result.SafetyScore = s.calculateSafetyScore(result)
+	return result
+}
+
+// checkPipelineStages validates the stages of a pipeline and the pipelines
+// nested in $lookup, $unionWith and $facet
+func checkPipelineStages(stages []interface{}, prefix string, result *models.SQLValidationResult) {
+	for i, stage := range stages {
+		body, op := pipelineStage(stage)
+		name := fmt.Sprintf("%sstage %d", prefix, i+1)
+		switch {
+		case op == "":
+			result.Violations = append(result.Violations, fmt.Sprintf("%s must be an object with exactly one stage operator", name))
+			continue
+		case writePipelineStages[op]:
+			result.IsReadOnly = false
+			result.Violations = append(result.Violations, fmt.Sprintf("%s writes data and is not allowed", op))
+			continue
+		case !readOnlyPipelineStages[op]:
+			result.Violations = append(result.Violations, fmt.Sprintf("%s is not an allowed stage", op))
+			continue
+		}
+
+		for _, operator := range findPipelineOperators(body, blockedPipelineOperators) {
+			result.Violations = append(result.Violations, fmt.Sprintf("%s runs JavaScript and is not allowed", operator))
+		}
+
+		switch op {
+		case "$lookup", "$unionWith":
+			if spec, ok := body.(map[string]interface{}); ok {
+				if nested, ok := spec["pipeline"].([]interface{}); ok {
+					checkPipelineStages(nested, fmt.Sprintf("%s %s ", name, op), result)
+				}
+			}
+		case "$facet":
+			if spec, ok := body.(map[string]interface{}); ok {
+				for facet, value := range spec {
+					nested, ok := value.([]interface{})
+					if !ok {
+						result.Violations = append(result.Violations, fmt.Sprintf("$facet %s must be a pipeline", facet))
+						continue
+					}
+					checkPipelineStages(nested, fmt.Sprintf("%s $facet %s ", name, facet), result)
+				}
+			}
+		}
+	}
+}
+
+// pipelineStage returns the operator and body of a stage, or "" when the
+// stage is not an object with a single $-prefixed key
+func pipelineStage(stage interface{}) (interface{}, string) {
+	spec, ok := stage.(map[string]interface{})
+	if !ok || len(spec) != 1 {
+		return nil, ""
+	}
+	for op, body := range spec {
+		if strings.HasPrefix(op, "$") {
+			return body, op
+		}
+	}
+	return nil, ""
+}
+
+func firstStage(stages []interface{}) interface{} {
+	if len(stages) == 0 {
+		return nil
+	}
+	return stages[0]
+}
+
+// findPipelineOperators returns the operators of the given set used anywhere in a value, sorted
+func findPipelineOperators(value interface{}, operators map[string]bool) []string {
+	found := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if operators[key] {
+					found[key] = true
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(value)
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnforcePipelineLimit appends a $limit stage when the pipeline has none at
+// the top level
+func (s *SQLValidatorService) EnforcePipelineLimit(query *models.AggregationQuery, limit int) {
+	for _, raw := range query.Pipeline {
+		var stage interface{}
+		if err := json.Unmarshal(raw, &stage); err == nil {
+			if _, op := pipelineStage(stage); op == "$limit" {
+				return
+			}
+		}
+	}
+	query.Pipeline = append(query.Pipeline, json.RawMessage(fmt.Sprintf(`{"$limit":%d}`, limit)))
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pipelineQuery(t *testing.T, collection, pipeline string) *models.AggregationQuery {
+	t.Helper()
+	query, err := ParseAggregationQuery(`{"collection":"` + collection + `","pipeline":` + pipeline + `}`)
+	require.NoError(t, err)
+	return query
+}
+
+func TestValidatePipeline_ReadOnly(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	result := validator.ValidatePipeline(pipelineQuery(t, "events",
+		`[{"$match":{"event":"purchase"}},{"$group":{"_id":"$country","total":{"$sum":"$amount"}}},{"$sort":{"total":-1}},{"$limit":10}]`), nil)
+	assert.True(t, result.IsValid)
+	assert.True(t, result.IsReadOnly)
+	assert.True(t, result.HasLimit)
+	assert.Equal(t, models.SQLDialectMongoDB, result.Dialect)
+	assert.True(t, validator.IsQuerySafe(result))
+}
+
+func TestValidatePipeline_RejectsWritesAndJavaScript(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	tests := []struct {
+		name      string
+		pipeline  string
+		violation string
+		writes    bool
+	}{
+		{"out", `[{"$match":{}},{"$out":"copy"}]`, "$out writes data", true},
+		{"merge", `[{"$merge":{"into":"copy"}}]`, "$merge writes data", true},
+		{"merge in facet", `[{"$facet":{"a":[{"$merge":{"into":"copy"}}]}}]`, "$merge writes data", true},
+		{"out in lookup", `[{"$lookup":{"from":"users","pipeline":[{"$out":"copy"}],"as":"u"}}]`, "$out writes data", true},
+		{"function", `[{"$addFields":{"x":{"$function":{"body":"function(){}","args":[],"lang":"js"}}}}]`, "$function runs JavaScript", false},
+		{"where", `[{"$match":{"$where":"this.a > 1"}}]`, "$where runs JavaScript", false},
+		{"accumulator", `[{"$group":{"_id":null,"x":{"$accumulator":{}}}}]`, "$accumulator runs JavaScript", false},
+		{"admin stage", `[{"$currentOp":{}}]`, "$currentOp is not an allowed stage", false},
+		{"two operators", `[{"$match":{},"$limit":5}]`, "exactly one stage operator", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.ValidatePipeline(pipelineQuery(t, "events", tt.pipeline), nil)
+			assert.False(t, result.IsValid)
+			assert.Equal(t, !tt.writes, result.IsReadOnly)
+			require.NotEmpty(t, result.Violations)
+			assert.Contains(t, result.Violations[0], tt.violation)
+			assert.False(t, validator.IsQuerySafe(result))
+		})
+	}
+}
+
+func TestValidatePipeline_PolicyRules(t *testing.T) {
+	validator := NewSQLValidatorService()
+	rules := &ValidationRules{MaxLimit: 100, LargeTables: map[string]int64{"events": 5000000}}
+
+	result := validator.ValidatePipeline(pipelineQuery(t, "Events", `[{"$group":{"_id":"$country"}},{"$limit":500}]`), rules)
+	assert.False(t, result.IsValid)
+	assert.Contains(t, result.Violations, "$limit 500 exceeds the maximum of 100 documents")
+	assert.Contains(t, result.Violations, "Collection Events has 5000000 documents; a leading $match stage is required")
+
+	result = validator.ValidatePipeline(pipelineQuery(t, "events", `[{"$match":{"event":"signup"}},{"$limit":50}]`), rules)
+	assert.True(t, result.IsValid)
+}
+
+func TestEnforcePipelineLimit(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	query := pipelineQuery(t, "events", `[{"$match":{"event":"signup"}}]`)
+	validator.EnforcePipelineLimit(query, 1000)
+	require.Len(t, query.Pipeline, 2)
+	assert.JSONEq(t, `{"$limit":1000}`, string(query.Pipeline[1]))
+
+	query = pipelineQuery(t, "events", `[{"$limit":5}]`)
+	validator.EnforcePipelineLimit(query, 1000)
+	assert.Len(t, query.Pipeline, 1, "existing $limit is kept")
+}
+
+func TestParseAggregationQuery(t *testing.T) {
+	_, err := ParseAggregationQuery(`SELECT * FROM events`)
+	assert.Error(t, err)
+
+	_, err = ParseAggregationQuery(`{"pipeline":[]}`)
+	assert.EqualError(t, err, "invalid aggregation query: collection is required")
+}
+
+func TestGenerateAggregationQuery(t *testing.T) {
+	schemas := []map[string]interface{}{
+		{"name": "users", "columns": []models.Column{{Name: "_id", Type: "objectid", PrimaryKey: true}, {Name: "age", Type: "integer"}}},
+		{"name": "orders", "columns": []models.Column{
+			{Name: "_id", Type: "objectid", PrimaryKey: true},
+			{Name: "country", Type: "string"},
+			{Name: "quantity", Type: "integer"},
+			{Name: "amount", Type: "double"},
+		}},
+	}
+
+	stages := func(query *models.AggregationQuery) string {
+		pipeline, err := json.Marshal(query.Pipeline)
+		require.NoError(t, err)
+		return string(pipeline)
+	}
+
+	query, err := generateAggregationQuery("total order amount by country", schemas)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", query.Collection)
+	assert.JSONEq(t, `[{"$group":{"_id":"$country","total_amount":{"$sum":"$amount"}}},{"$sort":{"total_amount":-1}}]`, stages(query))
+
+	query, err = generateAggregationQuery("How many users?", schemas)
+	require.NoError(t, err)
+	assert.Equal(t, "users", query.Collection)
+	assert.JSONEq(t, `[{"$count":"total_count"}]`, stages(query))
+
+	query, err = generateAggregationQuery("average age of users", schemas)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"$group":{"_id":null,"average_age":{"$avg":"$age"}}}]`, stages(query))
+
+	_, err = generateAggregationQuery("anything", nil)
+	assert.Error(t, err)
+}
+
+func TestPipelineResultColumns(t *testing.T) {
+	columns := pipelineResultColumns([]map[string]interface{}{
+		{"_id": nil, "total": 12.5},
+		{"_id": "ID", "total": 3.0, "tags": []interface{}{"a"}},
+	})
+
+	assert.Equal(t, []models.Column{
+		{Name: "_id", Type: "string", Nullable: true},
+		{Name: "tags", Type: "json", Nullable: true},
+		{Name: "total", Type: "double", Nullable: true},
+	}, columns)
+}
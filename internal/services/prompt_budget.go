@@ -0,0 +1,66 @@
+package services
+
+import (
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tokenizer"
+)
+
+// fitPromptToBudget renders the prompt and, while it is over the token
+// budget, leaves out the lowest scoring retrieved element of the context and
+// renders it again. Sections of the context are rebuilt from what is left, so
+// the schema, KPIs and glossary lose their least relevant entries first.
+func (s *RAGService) fitPromptToBudget(budget int, context map[string]interface{}, render func() string) (string, *models.PromptBudget) {
+	prompt := render()
+	report := &models.PromptBudget{MaxTokens: budget, Tokens: tokenizer.Count(prompt)}
+	if budget <= 0 || report.Tokens <= budget {
+		return prompt, report
+	}
+
+	retrieved, ok := context["retrieved"].(*models.RetrievedContext)
+	if !ok {
+		report.OverBudget = true
+		return prompt, report
+	}
+
+	for report.Tokens > budget {
+		if !dropLowestScored(retrieved) {
+			report.OverBudget = true
+			break
+		}
+		report.ElementsTrimmed++
+
+		context["schema_context"] = s.buildSchemaContext(retrieved.Schema)
+		context["kpi_context"] = s.buildKPIContext(retrieved.KPIs)
+		context["glossary_context"] = s.buildGlossaryContext(retrieved.Glossary)
+		if joinPaths, ok := context["join_paths"].([]models.TableRelationship); ok && len(retrieved.Schema) > 0 {
+			context["join_paths"] = s.filterJoinPaths(joinPaths, retrieved.Schema)
+		}
+
+		prompt = render()
+		report.Tokens = tokenizer.Count(prompt)
+	}
+	return prompt, report
+}
+
+// dropLowestScored removes the lowest scoring element of the retrieved
+// context, the last one of its section on ties. It reports whether there was
+// one to remove.
+func dropLowestScored(retrieved *models.RetrievedContext) bool {
+	sections := []*[]models.RAGSearchResult{&retrieved.Schema, &retrieved.KPIs, &retrieved.Glossary}
+
+	var lowest *[]models.RAGSearchResult
+	index := -1
+	for _, section := range sections {
+		for i, result := range *section {
+			if index < 0 || result.Score <= (*lowest)[index].Score {
+				lowest, index = section, i
+			}
+		}
+	}
+	if index < 0 {
+		return false
+	}
+
+	*lowest = append((*lowest)[:index:index], (*lowest)[index+1:]...)
+	return true
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tokenizer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFitPromptToBudget(t *testing.T) {
+	service := &RAGService{}
+	newContext := func() map[string]interface{} {
+		return map[string]interface{}{
+			"retrieved": &models.RetrievedContext{
+				Schema: []models.RAGSearchResult{
+					{ElementType: "table", ElementName: "orders", Content: "customer orders with totals and status", Score: 0.9},
+					{ElementType: "table", ElementName: "audit_log", Content: "changes made by staff to every record", Score: 0.2},
+				},
+				KPIs:     []models.RAGSearchResult{{ElementName: "revenue", Content: "sum of order totals", Score: 0.6}},
+				Glossary: []models.RAGSearchResult{{ElementName: "gmv", Content: "gross merchandise value", Score: 0.4}},
+			},
+		}
+	}
+	render := func(context map[string]interface{}) func() string {
+		return func() string {
+			retrieved := context["retrieved"].(*models.RetrievedContext)
+			parts := []string{"Question: monthly revenue by region"}
+			for _, section := range [][]models.RAGSearchResult{retrieved.Schema, retrieved.KPIs, retrieved.Glossary} {
+				for _, result := range section {
+					parts = append(parts, result.ElementName+": "+result.Content)
+				}
+			}
+			return strings.Join(parts, "\n")
+		}
+	}
+
+	// No budget, or a prompt within it, is left alone
+	context := newContext()
+	prompt, report := service.fitPromptToBudget(0, context, render(context))
+	assert.Equal(t, tokenizer.Count(prompt), report.Tokens)
+	assert.Zero(t, report.ElementsTrimmed)
+	assert.NotContains(t, context, "schema_context")
+
+	full := report.Tokens
+	context = newContext()
+	_, report = service.fitPromptToBudget(full, context, render(context))
+	assert.Zero(t, report.ElementsTrimmed)
+	assert.False(t, report.OverBudget)
+
+	// The lowest scoring elements go first, whatever their section
+	context = newContext()
+	prompt, report = service.fitPromptToBudget(full-1, context, render(context))
+	assert.Equal(t, 1, report.ElementsTrimmed)
+	assert.False(t, report.OverBudget)
+	assert.LessOrEqual(t, report.Tokens, full-1)
+	assert.NotContains(t, prompt, "audit_log")
+	assert.Contains(t, prompt, "gmv")
+	assert.Len(t, context["schema_context"].(map[string]interface{})["tables"], 1)
+
+	context = newContext()
+	prompt, report = service.fitPromptToBudget(full/2, context, render(context))
+	assert.NotContains(t, prompt, "gmv")
+	assert.Contains(t, prompt, "orders")
+	assert.Nil(t, context["glossary_context"])
+
+	// A prompt that stays over budget with nothing left to drop is reported
+	context = newContext()
+	prompt, report = service.fitPromptToBudget(1, context, render(context))
+	assert.Equal(t, "Question: monthly revenue by region", prompt)
+	assert.Equal(t, 4, report.ElementsTrimmed)
+	assert.True(t, report.OverBudget)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPromptTemplateNotFound is returned when a prompt template version does not exist
+	ErrPromptTemplateNotFound = errors.New("prompt template not found")
+	// ErrPromptDataSourceNotFound is returned when a template overrides a data source that does not exist
+	ErrPromptDataSourceNotFound = errors.New("data source not found")
+	// ErrInvalidPromptTemplate is returned when a template uses unknown variables or leaves out the query
+	ErrInvalidPromptTemplate = errors.New("invalid prompt template")
+)
+
+// PromptTemplateService manages the versions of the NL2SQL prompt template of
+// the workspace and of data sources, and resolves the one a prompt is built with
+type PromptTemplateService struct {
+	db *gorm.DB
+}
+
+// NewPromptTemplateService creates a new prompt template service
+func NewPromptTemplateService(db *gorm.DB) *PromptTemplateService {
+	return &PromptTemplateService{db: db}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *PromptTemplateService) WithContext(ctx context.Context) *PromptTemplateService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Resolve returns the active template of a data source, falling back to the
+// active workspace default. It returns nil when the built-in prompt applies.
+func (s *PromptTemplateService) Resolve(dataSourceID uint) (*models.PromptTemplate, error) {
+	var templates []models.PromptTemplate
+	if err := s.db.Where("is_active = ? AND (data_source_id = ? OR data_source_id IS NULL)", true, dataSourceID).
+		Order("data_source_id IS NULL").Limit(1).Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load prompt template: %w", err)
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	return &templates[0], nil
+}
+
+// ListActive returns the active templates, the workspace default first
+func (s *PromptTemplateService) ListActive() (*models.PromptTemplateListResponse, error) {
+	templates := []models.PromptTemplate{}
+	if err := s.db.Where("is_active = ?", true).Order("data_source_id IS NOT NULL, data_source_id").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	return &models.PromptTemplateListResponse{
+		Active:    templates,
+		Builtin:   models.BuiltinPromptTemplate,
+		Variables: models.PromptTemplateVariables,
+	}, nil
+}
+
+// ListVersions returns the versions of the workspace default template, or of
+// the override of a data source, newest first
+func (s *PromptTemplateService) ListVersions(dataSourceID *uint) ([]models.PromptTemplate, error) {
+	templates := []models.PromptTemplate{}
+	if err := promptTemplateScope(s.db, dataSourceID).Order("version DESC").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list prompt template versions: %w", err)
+	}
+	return templates, nil
+}
+
+// Get returns a prompt template version by ID
+func (s *PromptTemplateService) Get(id uint) (*models.PromptTemplate, error) {
+	var template models.PromptTemplate
+	if err := s.db.First(&template, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromptTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get prompt template: %w", err)
+	}
+	return &template, nil
+}
+
+// Create saves a new version of the template of a scope and activates it
+func (s *PromptTemplateService) Create(adminID uint, req *models.PromptTemplateRequest) (*models.PromptTemplate, error) {
+	if err := validatePromptTemplate(req.Template); err != nil {
+		return nil, err
+	}
+	if req.DataSourceID != nil {
+		var count int64
+		if err := s.db.Model(&models.DataSource{}).Where("id = ?", *req.DataSourceID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check data source: %w", err)
+		}
+		if count == 0 {
+			return nil, ErrPromptDataSourceNotFound
+		}
+	}
+
+	template := &models.PromptTemplate{
+		DataSourceID: req.DataSourceID,
+		Template:     req.Template,
+		Comment:      strings.TrimSpace(req.Comment),
+		IsActive:     true,
+		CreatedBy:    adminID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := promptTemplateScope(tx.Model(&models.PromptTemplate{}), req.DataSourceID).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		if err := promptTemplateScope(tx.Model(&models.PromptTemplate{}), req.DataSourceID).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		template.Version = latest + 1
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prompt template: %w", err)
+	}
+	return template, nil
+}
+
+// Activate makes a version the active template of its scope, e.g. to roll back
+func (s *PromptTemplateService) Activate(id uint) (*models.PromptTemplate, error) {
+	template, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := promptTemplateScope(tx.Model(&models.PromptTemplate{}), template.DataSourceID).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(template).Update("is_active", true).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate prompt template: %w", err)
+	}
+	template.IsActive = true
+	return template, nil
+}
+
+// Delete removes a version. When it was active, the latest remaining version
+// of its scope becomes active; a scope without versions falls back to the
+// workspace default, then to the built-in prompt.
+func (s *PromptTemplateService) Delete(id uint) error {
+	template, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(template).Error; err != nil {
+			return err
+		}
+		if !template.IsActive {
+			return nil
+		}
+
+		var latest []models.PromptTemplate
+		if err := promptTemplateScope(tx, template.DataSourceID).Order("version DESC").Limit(1).Find(&latest).Error; err != nil {
+			return err
+		}
+		if len(latest) == 0 {
+			return nil
+		}
+		return tx.Model(&latest[0]).Update("is_active", true).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt template: %w", err)
+	}
+	return nil
+}
+
+// promptTemplateScope narrows a query to the versions of the workspace
+// default template, or of the override of a data source
+func promptTemplateScope(db *gorm.DB, dataSourceID *uint) *gorm.DB {
+	if dataSourceID == nil {
+		return db.Where("data_source_id IS NULL")
+	}
+	return db.Where("data_source_id = ?", *dataSourceID)
+}
+
+// validatePromptTemplate checks that a template only uses known variables and
+// includes the query
+func validatePromptTemplate(text string) error {
+	known := make(map[string]bool, len(models.PromptTemplateVariables))
+	for _, name := range models.PromptTemplateVariables {
+		known[name] = true
+	}
+
+	var unknown []string
+	hasQuery := false
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+		switch name := match[1]; {
+		case name == models.PromptVariableQuery:
+			hasQuery = true
+		case !known[name]:
+			known[name] = true // Report each unknown variable once
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: unknown variables %s; available: %s", ErrInvalidPromptTemplate,
+			strings.Join(unknown, ", "), strings.Join(models.PromptTemplateVariables, ", "))
+	}
+	if !hasQuery {
+		return fmt.Errorf("%w: the template must include {{%s}}", ErrInvalidPromptTemplate, models.PromptVariableQuery)
+	}
+	return nil
+}
+
+// renderPromptTemplate replaces the variables of a template in a single pass,
+// so values that contain {{...}} are left as they are
+func renderPromptTemplate(text string, values map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		return values[templatePlaceholder.FindStringSubmatch(placeholder)[1]]
+	})
+}
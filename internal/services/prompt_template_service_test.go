@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePromptTemplate(t *testing.T) {
+	assert.NoError(t, validatePromptTemplate(models.BuiltinPromptTemplate))
+	assert.NoError(t, validatePromptTemplate("Answer in {{ dialect }}: {{query}}"))
+
+	err := validatePromptTemplate("{{query}} {{tables}} {{tables}} {{examples}}")
+	require.ErrorIs(t, err, ErrInvalidPromptTemplate)
+	assert.Contains(t, err.Error(), "unknown variables examples, tables;")
+
+	assert.ErrorIs(t, validatePromptTemplate("{{schema}}{{instructions}}"), ErrInvalidPromptTemplate)
+}
+
+func TestRenderPromptTemplate(t *testing.T) {
+	values := map[string]string{"query": "total {{kpis}} by region", "kpis": "\nRELEVANT KPIs:\n- revenue\n"}
+
+	// Values are not expanded again, and empty sections render as nothing
+	assert.Equal(t, "Q: total {{kpis}} by region\n\nRELEVANT KPIs:\n- revenue\n",
+		renderPromptTemplate("Q: {{query}}\n{{kpis}}{{glossary}}", values))
+}
+
+func TestBuiltinPromptTemplate(t *testing.T) {
+	context := map[string]interface{}{
+		"schema_context": map[string]interface{}{
+			"tables": map[string]interface{}{"sales": map[string]interface{}{"description": "Sales transactions"}},
+		},
+		"kpi_context": []map[string]interface{}{{"name": "revenue", "description": "SUM(amount)"}},
+	}
+
+	prompt := renderPromptTemplate(models.BuiltinPromptTemplate, nl2sqlPromptValues("total sales", models.SQLDialectPostgreSQL, context))
+	assert.Equal(t, "You are an expert SQL generator. Convert natural language queries to SQL using the provided schema context.\n\n"+
+		"SQL DIALECT: PostgreSQL\n"+dialectPromptGuidance(models.SQLDialectPostgreSQL)+"\n\n"+
+		"AVAILABLE TABLES AND COLUMNS:\nTable: sales\nDescription: Sales transactions\n"+
+		"\nRELEVANT KPIs:\n- revenue: SUM(amount)\n"+
+		"\nQUERY: total sales\n\n"+
+		"INSTRUCTIONS:\n"+
+		"1. Generate a SELECT-only SQL query in PostgreSQL\n"+
+		"2. Use only the tables and columns provided above\n"+
+		"3. Include appropriate WHERE clauses, JOINs, and aggregations\n"+
+		"4. Add LIMIT clause for large result sets; when filtering, prefer the sample values shown for a column\n"+
+		"5. Return only the SQL query, no explanations\n", prompt)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// canaryRowLimit caps the rows read by each side of a canary run
+const canaryRowLimit = 1000
+
+// ErrCanaryDivergence is returned when the edited SQL of a query used by
+// dashboards produces results that diverge from the current SQL
+var ErrCanaryDivergence = errors.New("canary run diverged from the current version")
+
+// CanaryDivergenceError carries the report of a diverging canary run; resend
+// the edit with confirm set to apply it anyway
+type CanaryDivergenceError struct {
+	Report *models.CanaryReport
+}
+
+func (e *CanaryDivergenceError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCanaryDivergence, strings.Join(e.Report.Differences, "; "))
+}
+
+func (e *CanaryDivergenceError) Unwrap() error {
+	return ErrCanaryDivergence
+}
+
+// runCanary executes the current and the edited SQL of a query that dashboard
+// widgets display and compares result shape, row count and column totals. It
+// returns nil when no widget uses the query. Placeholders run as NULL.
+func (s *NL2SQLService) runCanary(dataSource *models.DataSource, query *models.NL2SQLQuery, sql string, confirm bool) (*models.CanaryReport, error) {
+	if query.GeneratedSQL == "" {
+		return nil, nil
+	}
+
+	var widgets int64
+	if err := s.db.Model(&models.DashboardWidget{}).Where("query_id = ?", query.ID).Count(&widgets).Error; err != nil {
+		return nil, fmt.Errorf("failed to check dashboards using the query: %v", err)
+	}
+	if widgets == 0 {
+		return nil, nil
+	}
+
+	report := &models.CanaryReport{
+		Threshold: s.canaryThreshold,
+		Widgets:   widgets,
+		Confirmed: confirm,
+	}
+
+	candidate, _, err := s.executeQuerySQL(query.UserID, query, dataSource, renderQueryParameters(sql, nil), canaryRowLimit)
+	if err != nil {
+		return nil, fmt.Errorf("canary run of the new SQL failed: %v", err)
+	}
+	report.Candidate = summarizeCanaryResult(candidate)
+
+	previous, _, err := s.executeQuerySQL(query.UserID, query, dataSource, renderQueryParameters(query.GeneratedSQL, nil), canaryRowLimit)
+	if err != nil {
+		// Without a baseline there is nothing to diverge from
+		report.Previous = models.CanaryResultSummary{Error: err.Error()}
+		return report, nil
+	}
+	report.Previous = summarizeCanaryResult(previous)
+
+	report.Differences = compareCanaryResults(report.Previous, report.Candidate, s.canaryThreshold)
+	report.Diverged = len(report.Differences) > 0
+	if report.Diverged && !confirm {
+		return report, &CanaryDivergenceError{Report: report}
+	}
+	return report, nil
+}
+
+// summarizeCanaryResult reduces a result to its shape, row count and the sum
+// of every numeric column
+func summarizeCanaryResult(result *QueryResult) models.CanaryResultSummary {
+	summary := models.CanaryResultSummary{
+		Columns:  result.Columns,
+		RowCount: len(result.Data),
+		Totals:   make(map[string]float64),
+	}
+
+	for _, column := range result.Columns {
+		numeric := false
+		total := 0.0
+		for _, row := range result.Data {
+			if number, ok := answerNumber(row[column.Name]); ok {
+				numeric = true
+				total += number
+			}
+		}
+		if numeric {
+			summary.Totals[column.Name] = total
+		}
+	}
+	return summary
+}
+
+// compareCanaryResults lists how the candidate result differs from the
+// previous one: added or removed columns, changed column types, and a row count
+// or column total that changed by more than the threshold
+func compareCanaryResults(previous, candidate models.CanaryResultSummary, threshold float64) []string {
+	var differences []string
+
+	previousTypes := make(map[string]string, len(previous.Columns))
+	for _, column := range previous.Columns {
+		previousTypes[column.Name] = column.Type
+	}
+	candidateTypes := make(map[string]string, len(candidate.Columns))
+	for _, column := range candidate.Columns {
+		candidateTypes[column.Name] = column.Type
+		previousType, ok := previousTypes[column.Name]
+		switch {
+		case !ok:
+			differences = append(differences, fmt.Sprintf("column %s added", column.Name))
+		case previousType != column.Type:
+			differences = append(differences, fmt.Sprintf("column %s changed type from %s to %s", column.Name, previousType, column.Type))
+		}
+	}
+	for _, column := range previous.Columns {
+		if _, ok := candidateTypes[column.Name]; !ok {
+			differences = append(differences, fmt.Sprintf("column %s removed", column.Name))
+		}
+	}
+
+	if change := relativeChange(float64(previous.RowCount), float64(candidate.RowCount)); change > threshold {
+		differences = append(differences, fmt.Sprintf("row count changed from %d to %d (%.1f%%)", previous.RowCount, candidate.RowCount, change*100))
+	}
+
+	names := make([]string, 0, len(candidate.Totals))
+	for name := range candidate.Totals {
+		if _, ok := previous.Totals[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if change := relativeChange(previous.Totals[name], candidate.Totals[name]); change > threshold {
+			differences = append(differences, fmt.Sprintf("total of %s changed from %g to %g (%.1f%%)", name, previous.Totals[name], candidate.Totals[name], change*100))
+		}
+	}
+
+	return differences
+}
+
+// relativeChange returns |to - from| relative to from; any change from zero counts as 100%
+func relativeChange(from, to float64) float64 {
+	if from == to {
+		return 0
+	}
+	if from == 0 {
+		return 1
+	}
+	return math.Abs(to-from) / math.Abs(from)
+}
+
+// marshalCanaryReport encodes a canary report for the version history
+func marshalCanaryReport(report *models.CanaryReport) models.JSON {
+	if report == nil {
+		return nil
+	}
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil
+	}
+	return models.JSON(reportJSON)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeCanaryResult(t *testing.T) {
+	summary := summarizeCanaryResult(&QueryResult{
+		Columns: []models.Column{{Name: "region", Type: "string"}, {Name: "revenue", Type: "decimal"}},
+		Data: []map[string]interface{}{
+			{"region": "north", "revenue": 100.5},
+			{"region": "south", "revenue": int64(50)},
+			{"region": "east", "revenue": nil},
+		},
+	})
+
+	assert.Equal(t, 3, summary.RowCount)
+	assert.Equal(t, map[string]float64{"revenue": 150.5}, summary.Totals)
+}
+
+func TestCompareCanaryResults(t *testing.T) {
+	previous := models.CanaryResultSummary{
+		Columns:  []models.Column{{Name: "region", Type: "string"}, {Name: "revenue", Type: "decimal"}},
+		RowCount: 10,
+		Totals:   map[string]float64{"revenue": 1000},
+	}
+
+	within := models.CanaryResultSummary{Columns: previous.Columns, RowCount: 10, Totals: map[string]float64{"revenue": 1050}}
+	assert.Empty(t, compareCanaryResults(previous, within, 0.1))
+
+	diverged := models.CanaryResultSummary{
+		Columns:  []models.Column{{Name: "region", Type: "integer"}, {Name: "revenue", Type: "decimal"}, {Name: "orders", Type: "integer"}},
+		RowCount: 5,
+		Totals:   map[string]float64{"revenue": 1200, "orders": 7},
+	}
+	assert.Equal(t, []string{
+		"column region changed type from string to integer",
+		"column orders added",
+		"row count changed from 10 to 5 (50.0%)",
+		"total of revenue changed from 1000 to 1200 (20.0%)",
+	}, compareCanaryResults(previous, diverged, 0.1))
+
+	removed := models.CanaryResultSummary{Columns: previous.Columns[:1], RowCount: 10}
+	assert.Equal(t, []string{"column revenue removed"}, compareCanaryResults(previous, removed, 0.1))
+}
+
+func TestRelativeChange(t *testing.T) {
+	assert.Equal(t, 0.0, relativeChange(0, 0))
+	assert.Equal(t, 1.0, relativeChange(0, 5))
+	assert.InDelta(t, 0.5, relativeChange(-10, -5), 1e-9)
+}
+
+func TestCanaryDivergenceError(t *testing.T) {
+	err := error(&CanaryDivergenceError{Report: &models.CanaryReport{Differences: []string{"column a removed"}}})
+	assert.True(t, errors.Is(err, ErrCanaryDivergence))
+	assert.EqualError(t, err, "canary run diverged from the current version: column a removed")
+}
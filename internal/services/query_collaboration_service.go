@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	collaborationTokenPrefix = "qcl_"
+	defaultCollaborationTTL  = 60 * time.Minute
+)
+
+var (
+	// ErrCollaborationLinkInvalid is returned for unknown, revoked or expired collaboration tokens
+	ErrCollaborationLinkInvalid = errors.New("collaboration link is invalid or has expired")
+	// ErrCollaborationLinkNotFound is returned when a link does not exist for the query
+	ErrCollaborationLinkNotFound = errors.New("collaboration link not found")
+	// ErrQuerySuggestionNotFound is returned when a suggestion does not exist for the query
+	ErrQuerySuggestionNotFound = errors.New("suggestion not found")
+)
+
+// QueryCollaborationService manages short-lived links through which a teammate
+// reviews a query's SQL and validation before execution and suggests edits.
+// Every action is attributed to the acting user and recorded.
+type QueryCollaborationService struct {
+	db            *gorm.DB
+	nl2sqlService *NL2SQLService
+}
+
+// NewQueryCollaborationService creates a new query collaboration service
+func NewQueryCollaborationService(db *gorm.DB, nl2sqlService *NL2SQLService) *QueryCollaborationService {
+	return &QueryCollaborationService{
+		db:            db,
+		nl2sqlService: nl2sqlService,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *QueryCollaborationService) WithContext(ctx context.Context) *QueryCollaborationService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sqlService = s.nl2sqlService.WithContext(ctx)
+	return &scoped
+}
+
+// CreateLink issues a collaboration link for a query owned by the user. The
+// plaintext token is only returned here.
+func (s *QueryCollaborationService) CreateLink(userID, queryID uint, req *models.QueryCollaborationLinkRequest) (*models.QueryCollaborationLinkResponse, error) {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newCollaborationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := defaultCollaborationTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	link := models.QueryCollaborationLink{
+		QueryID:     query.ID,
+		CreatedBy:   userID,
+		TokenPrefix: token[:len(collaborationTokenPrefix)+8],
+		TokenHash:   hashAPIKey(token),
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	if err := s.db.Create(&link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create collaboration link: %w", err)
+	}
+
+	s.record(query.ID, link.ID, 0, userID, models.QueryCollaborationLinkCreated)
+	return &models.QueryCollaborationLinkResponse{QueryCollaborationLink: link, Token: token}, nil
+}
+
+// ListLinks returns the collaboration links of a query without their tokens
+func (s *QueryCollaborationService) ListLinks(userID, queryID uint) ([]models.QueryCollaborationLink, error) {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []models.QueryCollaborationLink{}
+	if err := s.db.Where("query_id = ?", query.ID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collaboration links: %w", err)
+	}
+	return links, nil
+}
+
+// RevokeLink ends a collaboration link before it expires
+func (s *QueryCollaborationService) RevokeLink(userID, queryID, linkID uint) error {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.QueryCollaborationLink{}).
+		Where("id = ? AND query_id = ? AND revoked_at IS NULL", linkID, query.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke collaboration link: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrCollaborationLinkNotFound
+	}
+
+	s.record(query.ID, linkID, 0, userID, models.QueryCollaborationLinkRevoked)
+	return nil
+}
+
+// View returns the query of a collaboration link with the validation result of
+// its current SQL and the suggestions made so far
+func (s *QueryCollaborationService) View(userID uint, token string) (*models.QueryCollaborationView, error) {
+	link, query, err := s.resolve(token)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &models.QueryCollaborationView{
+		QueryID:      query.ID,
+		DataSourceID: query.DataSourceID,
+		NLQuery:      query.NLQuery,
+		SQL:          query.GeneratedSQL,
+		SQLVersion:   query.SQLVersion,
+		Status:       query.Status,
+		ExpiresAt:    link.ExpiresAt,
+	}
+
+	if query.GeneratedSQL != "" {
+		validation, err := s.validate(query, query.GeneratedSQL)
+		if err != nil {
+			return nil, err
+		}
+		view.Validation = validation
+		view.CanExecute = s.nl2sqlService.sqlValidator.IsQuerySafe(validation)
+	}
+
+	if view.Suggestions, err = s.suggestions(query.ID); err != nil {
+		return nil, err
+	}
+
+	s.record(query.ID, link.ID, 0, userID, models.QueryCollaborationViewed)
+	return view, nil
+}
+
+// Suggest records a SQL edit proposed through a collaboration link. The SQL is
+// validated against the data source policy; the owner decides whether to apply it.
+func (s *QueryCollaborationService) Suggest(userID uint, token string, req *models.QuerySQLSuggestionRequest) (*models.QuerySQLSuggestion, error) {
+	link, query, err := s.resolve(token)
+	if err != nil {
+		return nil, err
+	}
+
+	validation, err := s.validate(query, req.SQL)
+	if err != nil {
+		return nil, err
+	}
+	validationJSON, err := json.Marshal(validation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal validation result: %w", err)
+	}
+
+	suggestion := models.QuerySQLSuggestion{
+		QueryID:     query.ID,
+		LinkID:      link.ID,
+		SuggestedBy: userID,
+		BaseVersion: query.SQLVersion,
+		SQL:         req.SQL,
+		Comment:     req.Comment,
+		Validation:  models.JSON(validationJSON),
+		Status:      models.QuerySuggestionPending,
+	}
+	if err := s.db.Create(&suggestion).Error; err != nil {
+		return nil, fmt.Errorf("failed to create suggestion: %w", err)
+	}
+
+	s.record(query.ID, link.ID, suggestion.ID, userID, models.QueryCollaborationSuggested)
+	return &suggestion, nil
+}
+
+// ListSuggestions returns the suggestions made on a query owned by the user
+func (s *QueryCollaborationService) ListSuggestions(userID, queryID uint) ([]models.QuerySQLSuggestion, error) {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.suggestions(query.ID)
+}
+
+// AcceptSuggestion applies a pending suggestion as a new SQL version of the
+// query. The version is attributed to the teammate who suggested the SQL.
+func (s *QueryCollaborationService) AcceptSuggestion(userID, queryID, suggestionID uint, req *models.QuerySuggestionReviewRequest) (*models.QuerySQLVersionResponse, error) {
+	query, suggestion, err := s.pendingSuggestion(userID, queryID, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSuggestionBase(query, suggestion); err != nil {
+		return nil, err
+	}
+
+	comment := req.Comment
+	if comment == "" {
+		comment = suggestion.Comment
+	}
+	response, err := s.nl2sqlService.applySQLVersion(suggestion.SuggestedBy, query, suggestion.SQL, models.SQLVersionSourceSuggestion, comment, 0, req.Confirm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(suggestion, userID, models.QuerySuggestionAccepted, response.Version.Version); err != nil {
+		return nil, err
+	}
+	s.record(query.ID, suggestion.LinkID, suggestion.ID, userID, models.QueryCollaborationSuggestionAccepted)
+	return response, nil
+}
+
+// RejectSuggestion closes a pending suggestion without changing the query
+func (s *QueryCollaborationService) RejectSuggestion(userID, queryID, suggestionID uint) (*models.QuerySQLSuggestion, error) {
+	query, suggestion, err := s.pendingSuggestion(userID, queryID, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(suggestion, userID, models.QuerySuggestionRejected, 0); err != nil {
+		return nil, err
+	}
+	s.record(query.ID, suggestion.LinkID, suggestion.ID, userID, models.QueryCollaborationSuggestionRejected)
+	return suggestion, nil
+}
+
+// ListEvents returns the collaboration audit trail of a query, oldest first
+func (s *QueryCollaborationService) ListEvents(userID, queryID uint) ([]models.QueryCollaborationEvent, error) {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []models.QueryCollaborationEvent{}
+	if err := s.db.Where("query_id = ?", query.ID).Order("created_at, id").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collaboration events: %w", err)
+	}
+	return events, nil
+}
+
+// resolve returns the active link of a token and its query
+func (s *QueryCollaborationService) resolve(token string) (*models.QueryCollaborationLink, *models.NL2SQLQuery, error) {
+	var link models.QueryCollaborationLink
+	if err := s.db.Where("token_hash = ?", hashAPIKey(token)).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCollaborationLinkInvalid
+		}
+		return nil, nil, fmt.Errorf("failed to get collaboration link: %w", err)
+	}
+	if !collaborationLinkActive(&link, time.Now()) {
+		return nil, nil, ErrCollaborationLinkInvalid
+	}
+
+	var query models.NL2SQLQuery
+	if err := s.db.First(&query, link.QueryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCollaborationLinkInvalid
+		}
+		return nil, nil, fmt.Errorf("failed to get query: %w", err)
+	}
+	return &link, &query, nil
+}
+
+// validate runs the SQL through the same preparation as an edit by the owner
+func (s *QueryCollaborationService) validate(query *models.NL2SQLQuery, sql string) (*models.SQLValidationResult, error) {
+	var dataSource models.DataSource
+	if err := s.db.First(&dataSource, query.DataSourceID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+
+	_, validation, err := s.nl2sqlService.prepareQuery(&dataSource, sql)
+	if err != nil {
+		return nil, err
+	}
+	return validation, nil
+}
+
+func (s *QueryCollaborationService) suggestions(queryID uint) ([]models.QuerySQLSuggestion, error) {
+	suggestions := []models.QuerySQLSuggestion{}
+	if err := s.db.Where("query_id = ?", queryID).Order("created_at DESC").Find(&suggestions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+func (s *QueryCollaborationService) pendingSuggestion(userID, queryID, suggestionID uint) (*models.NL2SQLQuery, *models.QuerySQLSuggestion, error) {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var suggestion models.QuerySQLSuggestion
+	if err := s.db.Where("id = ? AND query_id = ?", suggestionID, query.ID).First(&suggestion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrQuerySuggestionNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get suggestion: %w", err)
+	}
+	if suggestion.Status != models.QuerySuggestionPending {
+		return nil, nil, fmt.Errorf("suggestion was already %s", suggestion.Status)
+	}
+	return query, &suggestion, nil
+}
+
+func (s *QueryCollaborationService) review(suggestion *models.QuerySQLSuggestion, userID uint, status models.QuerySuggestionStatus, appliedVersion int) error {
+	now := time.Now()
+	suggestion.Status = status
+	suggestion.ReviewedBy = userID
+	suggestion.ReviewedAt = &now
+	suggestion.AppliedVersion = appliedVersion
+	if err := s.db.Save(suggestion).Error; err != nil {
+		return fmt.Errorf("failed to update suggestion: %w", err)
+	}
+	return nil
+}
+
+// record appends to the collaboration audit trail. A failure is logged rather
+// than failing the action that already happened.
+func (s *QueryCollaborationService) record(queryID, linkID, suggestionID, userID uint, action models.QueryCollaborationAction) {
+	event := models.QueryCollaborationEvent{
+		QueryID:      queryID,
+		LinkID:       linkID,
+		SuggestionID: suggestionID,
+		UserID:       userID,
+		Action:       action,
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		logger.L().Error().Err(err).Str("action", string(action)).Uint("query_id", queryID).Msg("Failed to record collaboration event")
+	}
+}
+
+// newCollaborationToken returns a random collaboration link token
+func newCollaborationToken() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate collaboration token: %w", err)
+	}
+	return collaborationTokenPrefix + hex.EncodeToString(secret), nil
+}
+
+// collaborationLinkActive reports whether a link is neither revoked nor expired
+func collaborationLinkActive(link *models.QueryCollaborationLink, now time.Time) bool {
+	return link.RevokedAt == nil && now.Before(link.ExpiresAt)
+}
+
+// checkSuggestionBase rejects a suggestion made on an older SQL version, so
+// accepting it does not silently discard edits made since
+func checkSuggestionBase(query *models.NL2SQLQuery, suggestion *models.QuerySQLSuggestion) error {
+	if suggestion.BaseVersion != query.SQLVersion {
+		return fmt.Errorf("suggestion was made on SQL version %d but the query is at version %d", suggestion.BaseVersion, query.SQLVersion)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCollaborationToken(t *testing.T) {
+	token, err := newCollaborationToken()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "qcl_"))
+	assert.Len(t, token, len("qcl_")+48)
+
+	other, err := newCollaborationToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestCollaborationLinkActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	assert.True(t, collaborationLinkActive(&models.QueryCollaborationLink{ExpiresAt: now.Add(time.Minute)}, now))
+	assert.False(t, collaborationLinkActive(&models.QueryCollaborationLink{ExpiresAt: now}, now))
+	assert.False(t, collaborationLinkActive(&models.QueryCollaborationLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, now))
+}
+
+func TestCheckSuggestionBase(t *testing.T) {
+	query := &models.NL2SQLQuery{SQLVersion: 3}
+
+	assert.NoError(t, checkSuggestionBase(query, &models.QuerySQLSuggestion{BaseVersion: 3}))
+	assert.EqualError(t, checkSuggestionBase(query, &models.QuerySQLSuggestion{BaseVersion: 2}),
+		"suggestion was made on SQL version 2 but the query is at version 3")
+}
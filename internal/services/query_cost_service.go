@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"narapulse-be/internal/connectors"
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+// ErrQueryCostExceeded is returned when a query's estimate exceeds the cost ceiling
+var ErrQueryCostExceeded = errors.New("query exceeds the cost ceiling")
+
+// queryCostEstimator is implemented by connectors that can estimate a query before running it
+type queryCostEstimator interface {
+	Connect(config map[string]interface{}) error
+	Disconnect() error
+	EstimateQueryCost(query string) (*models.QueryCostEstimate, error)
+}
+
+// bytesPerTiB is the unit BigQuery on-demand queries are priced in
+const bytesPerTiB = 1 << 40
+
+// QueryCostService estimates query cost with EXPLAIN (PostgreSQL) or a dry run
+// (BigQuery) and enforces per-user and per-data-source cost ceilings. BigQuery
+// queries are also held to a platform limit on bytes billed, which their jobs
+// run with.
+type QueryCostService struct {
+	db             *gorm.DB
+	newEstimator   func(dsType models.DataSourceType) queryCostEstimator
+	maxBytesBilled int64   // Platform limit on bytes processed by BigQuery queries; 0 disables it
+	pricePerTiB    float64 // BigQuery on-demand price
+}
+
+// NewQueryCostService creates a new query cost service
+func NewQueryCostService(db *gorm.DB, maxBytesBilled int64, pricePerTiB float64) *QueryCostService {
+	return &QueryCostService{
+		db:             db,
+		newEstimator:   newQueryCostEstimator,
+		maxBytesBilled: maxBytesBilled,
+		pricePerTiB:    pricePerTiB,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *QueryCostService) WithContext(ctx context.Context) *QueryCostService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// MaxBytesBilled is the limit BigQuery jobs run with, or 0 for none
+func (s *QueryCostService) MaxBytesBilled() int64 {
+	return s.maxBytesBilled
+}
+
+// newQueryCostEstimator returns the estimator for a data source type, or nil
+// when the engine cannot estimate queries
+func newQueryCostEstimator(dsType models.DataSourceType) queryCostEstimator {
+	switch dsType {
+	case models.DataSourceTypePostgreSQL:
+		return connectors.NewPostgreSQLConnector()
+	case models.DataSourceTypeBigQuery:
+		return connectors.NewBigQueryConnector()
+	case models.DataSourceTypeGA4:
+		return connectors.NewGA4Connector()
+	default:
+		return nil
+	}
+}
+
+// Estimate asks the data source engine for the cost of a query. When the engine
+// cannot be reached the static heuristic cost is returned instead.
+func (s *QueryCostService) Estimate(dataSource *models.DataSource, sql string, heuristicCost float64) *models.QueryCostEstimate {
+	fallback := func(reason string) *models.QueryCostEstimate {
+		return &models.QueryCostEstimate{
+			Source:        models.QueryCostSourceHeuristic,
+			EstimatedCost: heuristicCost,
+			Message:       reason,
+		}
+	}
+
+	estimator := s.newEstimator(dataSource.Type)
+	if estimator == nil {
+		return fallback(fmt.Sprintf("%s data sources do not support cost estimation", dataSource.Type))
+	}
+
+	var config map[string]interface{}
+	if len(dataSource.Config) > 0 {
+		if err := json.Unmarshal(dataSource.Config, &config); err != nil {
+			return fallback("invalid data source configuration")
+		}
+	}
+
+	if err := estimator.Connect(config); err != nil {
+		return fallback(fmt.Sprintf("could not connect to estimate cost: %v", err))
+	}
+	defer estimator.Disconnect()
+
+	estimate, err := estimator.EstimateQueryCost(sql)
+	if err != nil {
+		return fallback(fmt.Sprintf("cost estimation failed: %v", err))
+	}
+	if estimate.Source == models.QueryCostSourceDryRun {
+		estimate.CostUSD = float64(estimate.BytesScanned) / bytesPerTiB * s.pricePerTiB
+		estimate.MaxBytesBilled = s.maxBytesBilled
+	}
+	return estimate
+}
+
+// Check compares an estimate with the effective ceiling of the user and data
+// source, recording violations on the estimate. Heuristic estimates are not in
+// engine units and are never blocked.
+func (s *QueryCostService) Check(userID, dataSourceID uint, estimate *models.QueryCostEstimate) error {
+	if estimate.Source == models.QueryCostSourceHeuristic {
+		return nil
+	}
+
+	ceiling, err := s.GetEffectiveCeiling(userID, dataSourceID)
+	if err != nil {
+		return err
+	}
+
+	applyCostCeiling(estimate, ceiling)
+	return nil
+}
+
+// GetEffectiveCeiling merges the user and data source ceilings, keeping the
+// strictest non-zero limit of each kind
+func (s *QueryCostService) GetEffectiveCeiling(userID, dataSourceID uint) (*models.EffectiveQueryCostCeiling, error) {
+	effective := &models.EffectiveQueryCostCeiling{MaxBytesBilled: s.maxBytesBilled}
+
+	var ceilings []models.QueryCostCeiling
+	if err := s.db.Where("user_id = ? OR data_source_id = ?", userID, dataSourceID).Find(&ceilings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load cost ceilings: %w", err)
+	}
+
+	for i := range ceilings {
+		ceiling := &ceilings[i]
+		if ceiling.UserID != nil {
+			effective.UserCeiling = ceiling
+		} else {
+			effective.SourceCeiling = ceiling
+		}
+		effective.MaxCost = minPositiveFloat(effective.MaxCost, ceiling.MaxCost)
+		effective.MaxBytesScanned = minPositiveInt(effective.MaxBytesScanned, ceiling.MaxBytesScanned)
+		effective.MaxRows = minPositiveInt(effective.MaxRows, ceiling.MaxRows)
+	}
+
+	return effective, nil
+}
+
+// SetUserCeiling creates or replaces the cost ceiling of a user
+func (s *QueryCostService) SetUserCeiling(userID, updatedBy uint, req *models.QueryCostCeilingRequest) (*models.QueryCostCeiling, error) {
+	var ceiling models.QueryCostCeiling
+	if err := s.db.Where("user_id = ?", userID).Attrs(models.QueryCostCeiling{UserID: &userID}).
+		FirstOrInit(&ceiling).Error; err != nil {
+		return nil, fmt.Errorf("failed to load cost ceiling: %w", err)
+	}
+	return s.saveCeiling(&ceiling, updatedBy, req)
+}
+
+// SetDataSourceCeiling creates or replaces the cost ceiling of a data source owned by the user
+func (s *QueryCostService) SetDataSourceCeiling(userID, dataSourceID uint, req *models.QueryCostCeilingRequest) (*models.QueryCostCeiling, error) {
+	if err := s.checkDataSourceOwner(userID, dataSourceID); err != nil {
+		return nil, err
+	}
+
+	var ceiling models.QueryCostCeiling
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Attrs(models.QueryCostCeiling{DataSourceID: &dataSourceID}).
+		FirstOrInit(&ceiling).Error; err != nil {
+		return nil, fmt.Errorf("failed to load cost ceiling: %w", err)
+	}
+	return s.saveCeiling(&ceiling, userID, req)
+}
+
+// DeleteUserCeiling removes the cost ceiling of a user
+func (s *QueryCostService) DeleteUserCeiling(userID uint) error {
+	if err := s.db.Where("user_id = ?", userID).Delete(&models.QueryCostCeiling{}).Error; err != nil {
+		return fmt.Errorf("failed to delete cost ceiling: %w", err)
+	}
+	return nil
+}
+
+// DeleteDataSourceCeiling removes the cost ceiling of a data source owned by the user
+func (s *QueryCostService) DeleteDataSourceCeiling(userID, dataSourceID uint) error {
+	if err := s.checkDataSourceOwner(userID, dataSourceID); err != nil {
+		return err
+	}
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Delete(&models.QueryCostCeiling{}).Error; err != nil {
+		return fmt.Errorf("failed to delete cost ceiling: %w", err)
+	}
+	return nil
+}
+
+func (s *QueryCostService) saveCeiling(ceiling *models.QueryCostCeiling, updatedBy uint, req *models.QueryCostCeilingRequest) (*models.QueryCostCeiling, error) {
+	ceiling.MaxCost = req.MaxCost
+	ceiling.MaxBytesScanned = req.MaxBytesScanned
+	ceiling.MaxRows = req.MaxRows
+	ceiling.UpdatedBy = updatedBy
+	if err := s.db.Save(ceiling).Error; err != nil {
+		return nil, fmt.Errorf("failed to save cost ceiling: %w", err)
+	}
+	return ceiling, nil
+}
+
+func (s *QueryCostService) checkDataSourceOwner(userID, dataSourceID uint) error {
+	var count int64
+	if err := s.db.Model(&models.DataSource{}).Where("id = ? AND user_id = ?", dataSourceID, userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check data source: %w", err)
+	}
+	if count == 0 {
+		return errors.New("data source not found")
+	}
+	return nil
+}
+
+// applyCostCeiling marks the estimate as exceeding the ceiling and lists the violated limits
+func applyCostCeiling(estimate *models.QueryCostEstimate, ceiling *models.EffectiveQueryCostCeiling) {
+	estimate.Violations = nil
+	if ceiling.MaxCost > 0 && estimate.EstimatedCost > ceiling.MaxCost {
+		estimate.Violations = append(estimate.Violations,
+			fmt.Sprintf("estimated cost %.2f exceeds the limit of %.2f", estimate.EstimatedCost, ceiling.MaxCost))
+	}
+	if ceiling.MaxBytesScanned > 0 && estimate.BytesScanned > ceiling.MaxBytesScanned {
+		estimate.Violations = append(estimate.Violations,
+			fmt.Sprintf("%d bytes scanned exceeds the limit of %d", estimate.BytesScanned, ceiling.MaxBytesScanned))
+	}
+	if ceiling.MaxRows > 0 && estimate.EstimatedRows > ceiling.MaxRows {
+		estimate.Violations = append(estimate.Violations,
+			fmt.Sprintf("%d estimated rows exceeds the limit of %d", estimate.EstimatedRows, ceiling.MaxRows))
+	}
+	// The job would fail once it reached the limit, so do not start it
+	if estimate.Source == models.QueryCostSourceDryRun && ceiling.MaxBytesBilled > 0 && estimate.BytesScanned > ceiling.MaxBytesBilled {
+		estimate.Violations = append(estimate.Violations,
+			fmt.Sprintf("%d bytes processed exceeds the BigQuery limit of %d bytes billed", estimate.BytesScanned, ceiling.MaxBytesBilled))
+	}
+	estimate.ExceedsCeiling = len(estimate.Violations) > 0
+}
+
+// describeDryRun summarizes a BigQuery dry run for users, e.g. "1.5 GiB processed, about $0.01"
+func describeDryRun(estimate *models.QueryCostEstimate) string {
+	return fmt.Sprintf("%s processed, about $%.2f", formatByteCount(estimate.BytesScanned), estimate.CostUSD)
+}
+
+// formatByteCount formats a byte count in binary units
+func formatByteCount(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTP"[exp])
+}
+
+func minPositiveFloat(current, limit float64) float64 {
+	if limit > 0 && (current == 0 || limit < current) {
+		return limit
+	}
+	return current
+}
+
+func minPositiveInt(current, limit int64) int64 {
+	if limit > 0 && (current == 0 || limit < current) {
+		return limit
+	}
+	return current
+}
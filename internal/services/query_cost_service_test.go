@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+type fakeCostEstimator struct {
+	connectErr error
+	estimate   *models.QueryCostEstimate
+}
+
+func (f *fakeCostEstimator) Connect(config map[string]interface{}) error { return f.connectErr }
+func (f *fakeCostEstimator) Disconnect() error                           { return nil }
+func (f *fakeCostEstimator) EstimateQueryCost(query string) (*models.QueryCostEstimate, error) {
+	return f.estimate, nil
+}
+
+func TestQueryCostService_Estimate(t *testing.T) {
+	estimator := &fakeCostEstimator{
+		estimate: &models.QueryCostEstimate{Source: models.QueryCostSourceExplain, EstimatedCost: 1234.5, EstimatedRows: 10},
+	}
+	service := &QueryCostService{
+		newEstimator: func(dsType models.DataSourceType) queryCostEstimator {
+			if dsType == models.DataSourceTypeCSV {
+				return nil
+			}
+			return estimator
+		},
+	}
+	dataSource := &models.DataSource{Type: models.DataSourceTypePostgreSQL, Config: models.JSON(`{"host":"db"}`)}
+
+	estimate := service.Estimate(dataSource, "SELECT 1", 0.02)
+	assert.Equal(t, models.QueryCostSourceExplain, estimate.Source)
+	assert.Equal(t, 1234.5, estimate.EstimatedCost)
+
+	estimator.connectErr = errors.New("connection refused")
+	estimate = service.Estimate(dataSource, "SELECT 1", 0.02)
+	assert.Equal(t, models.QueryCostSourceHeuristic, estimate.Source)
+	assert.Equal(t, 0.02, estimate.EstimatedCost)
+	assert.Contains(t, estimate.Message, "connection refused")
+
+	estimate = service.Estimate(&models.DataSource{Type: models.DataSourceTypeCSV}, "SELECT 1", 0.02)
+	assert.Equal(t, models.QueryCostSourceHeuristic, estimate.Source)
+}
+
+func TestApplyCostCeiling(t *testing.T) {
+	estimate := &models.QueryCostEstimate{EstimatedCost: 5000, BytesScanned: 2 << 30, EstimatedRows: 100}
+
+	applyCostCeiling(estimate, &models.EffectiveQueryCostCeiling{MaxCost: 10000, MaxBytesScanned: 1 << 30})
+	assert.True(t, estimate.ExceedsCeiling)
+	assert.Len(t, estimate.Violations, 1)
+	assert.Contains(t, estimate.Violations[0], "bytes scanned")
+
+	applyCostCeiling(estimate, &models.EffectiveQueryCostCeiling{})
+	assert.False(t, estimate.ExceedsCeiling)
+	assert.Empty(t, estimate.Violations)
+}
+
+func TestMinPositive(t *testing.T) {
+	assert.Equal(t, int64(50), minPositiveInt(0, 50))
+	assert.Equal(t, int64(20), minPositiveInt(20, 50))
+	assert.Equal(t, int64(20), minPositiveInt(20, 0))
+	assert.Equal(t, 1.5, minPositiveFloat(3, 1.5))
+}
+
+func TestQueryCostService_EstimateDryRun(t *testing.T) {
+	service := &QueryCostService{
+		newEstimator: func(dsType models.DataSourceType) queryCostEstimator {
+			return &fakeCostEstimator{estimate: &models.QueryCostEstimate{Source: models.QueryCostSourceDryRun, BytesScanned: 1 << 40}}
+		},
+		maxBytesBilled: 10 << 30,
+		pricePerTiB:    6.25,
+	}
+
+	estimate := service.Estimate(&models.DataSource{Type: models.DataSourceTypeBigQuery}, "SELECT 1", 0)
+	assert.Equal(t, 6.25, estimate.CostUSD)
+	assert.Equal(t, int64(10<<30), estimate.MaxBytesBilled)
+	assert.Equal(t, "1.0 TiB processed, about $6.25", describeDryRun(estimate))
+}
+
+func TestApplyCostCeiling_MaxBytesBilled(t *testing.T) {
+	ceiling := &models.EffectiveQueryCostCeiling{MaxBytesBilled: 1 << 30}
+
+	estimate := &models.QueryCostEstimate{Source: models.QueryCostSourceDryRun, BytesScanned: 2 << 30}
+	applyCostCeiling(estimate, ceiling)
+	assert.True(t, estimate.ExceedsCeiling)
+	assert.Contains(t, estimate.Violations[0], "BigQuery limit")
+
+	// EXPLAIN estimates are not billed by bytes
+	estimate = &models.QueryCostEstimate{Source: models.QueryCostSourceExplain, BytesScanned: 2 << 30}
+	applyCostCeiling(estimate, ceiling)
+	assert.False(t, estimate.ExceedsCeiling)
+}
+
+func TestFormatByteCount(t *testing.T) {
+	assert.Equal(t, "512 B", formatByteCount(512))
+	assert.Equal(t, "1.5 KiB", formatByteCount(1536))
+	assert.Equal(t, "2.0 GiB", formatByteCount(2<<30))
+}
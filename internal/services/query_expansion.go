@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// expandQuery expands a question with the glossary terms and KPI names it
+// refers to by another name, or in another language, so retrieval and
+// generation see the canonical vocabulary. A failure to load the glossary
+// only skips the expansion.
+func (s *NL2SQLService) expandQuery(userID uint, nlQuery, language string) (string, []models.QueryExpansion) {
+	var glossary []models.BusinessGlossary
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&glossary).Error; err != nil {
+		return nlQuery, nil
+	}
+	var kpis []models.KPIDefinition
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&kpis).Error; err != nil {
+		return nlQuery, nil
+	}
+	return expandNLQuery(nlQuery, language, glossary, kpis)
+}
+
+// queryExpansionCandidate is a phrase that expands to a canonical term
+type queryExpansionCandidate struct {
+	phrase string
+	term   string
+	source string
+}
+
+// expandNLQuery appends the canonical terms of the glossary synonyms and KPI
+// display names found in the question, e.g. "turnover by month" becomes
+// "turnover by month (revenue)". Questions in other languages are expanded
+// with the glossary translations and built-in domain terms of the language.
+// Longer phrases win over the phrases they contain, and terms the question
+// already uses are not repeated.
+func expandNLQuery(nlQuery, language string, glossary []models.BusinessGlossary, kpis []models.KPIDefinition) (string, []models.QueryExpansion) {
+	var candidates []queryExpansionCandidate
+	for _, entry := range glossary {
+		var synonyms []string
+		if entry.Synonyms != nil {
+			_ = json.Unmarshal(entry.Synonyms, &synonyms)
+		}
+		if language != "" && language != models.LanguageEnglish && entry.Translations != nil {
+			var translations map[string][]string
+			_ = json.Unmarshal(entry.Translations, &translations)
+			synonyms = append(synonyms, translations[language]...)
+		}
+		for _, synonym := range synonyms {
+			candidates = append(candidates, queryExpansionCandidate{synonym, entry.Term, models.QueryExpansionSourceGlossary})
+		}
+	}
+	for _, kpi := range kpis {
+		for _, name := range []string{kpi.DisplayName, strings.ReplaceAll(kpi.Name, "_", " ")} {
+			candidates = append(candidates, queryExpansionCandidate{name, kpi.Name, models.QueryExpansionSourceKPI})
+		}
+	}
+	for phrase, term := range builtinTranslations(language) {
+		candidates = append(candidates, queryExpansionCandidate{phrase, term, models.QueryExpansionSourceTranslation})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].phrase) != len(candidates[j].phrase) {
+			return len(candidates[i].phrase) > len(candidates[j].phrase)
+		}
+		return candidates[i].phrase < candidates[j].phrase
+	})
+
+	question := strings.ToLower(nlQuery)
+	matched := make([]bool, len(question))
+	seen := map[string]bool{}
+	var positions []int
+	var expansions []models.QueryExpansion
+	for _, candidate := range candidates {
+		phrase := strings.ToLower(strings.TrimSpace(candidate.phrase))
+		term := strings.TrimSpace(candidate.term)
+		if phrase == "" || term == "" || strings.EqualFold(phrase, term) || seen[strings.ToLower(term)] {
+			continue
+		}
+		if containsWord(question, strings.ToLower(term)) {
+			continue
+		}
+
+		loc := findUnmatchedWord(question, phrase, matched)
+		if loc == nil {
+			continue
+		}
+		for i := loc[0]; i < loc[1]; i++ {
+			matched[i] = true
+		}
+		seen[strings.ToLower(term)] = true
+		positions = append(positions, loc[0])
+		written := phrase
+		if len(question) == len(nlQuery) {
+			written = nlQuery[loc[0]:loc[1]]
+		}
+		expansions = append(expansions, models.QueryExpansion{
+			Phrase:     written,
+			ExpandedTo: term,
+			Source:     candidate.source,
+		})
+	}
+
+	if len(expansions) == 0 {
+		return nlQuery, nil
+	}
+
+	// Terms follow the order of the phrases in the question
+	order := make([]int, len(expansions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return positions[order[i]] < positions[order[j]] })
+	sorted := make([]models.QueryExpansion, len(expansions))
+	terms := make([]string, len(expansions))
+	for i, index := range order {
+		sorted[i] = expansions[index]
+		terms[i] = expansions[index].ExpandedTo
+	}
+	return nlQuery + " (" + strings.Join(terms, ", ") + ")", sorted
+}
+
+// containsWord reports whether phrase occurs in text on word boundaries
+func containsWord(text, phrase string) bool {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(phrase) + `\b`).MatchString(text)
+}
+
+// findUnmatchedWord finds phrase in text on word boundaries, skipping
+// occurrences that overlap a phrase already expanded
+func findUnmatchedWord(text, phrase string, matched []bool) []int {
+	pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(phrase) + `\b`)
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		overlaps := false
+		for i := loc[0]; i < loc[1]; i++ {
+			if matched[i] {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			return loc
+		}
+	}
+	return nil
+}
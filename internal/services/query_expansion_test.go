@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandNLQuery(t *testing.T) {
+	glossary := []models.BusinessGlossary{
+		{Term: "revenue", Synonyms: models.JSON(`["turnover", "sales"]`)},
+		{Term: "gross margin", Synonyms: models.JSON(`["gross turnover margin"]`)},
+	}
+	kpis := []models.KPIDefinition{
+		{Name: "monthly_active_users", DisplayName: "MAU"},
+	}
+
+	expanded, expansions := expandNLQuery("Turnover and MAU by month", models.LanguageEnglish, glossary, kpis)
+	assert.Equal(t, "Turnover and MAU by month (revenue, monthly_active_users)", expanded)
+	require.Len(t, expansions, 2)
+	assert.Equal(t, models.QueryExpansion{Phrase: "Turnover", ExpandedTo: "revenue", Source: models.QueryExpansionSourceGlossary}, expansions[0])
+	assert.Equal(t, models.QueryExpansion{Phrase: "MAU", ExpandedTo: "monthly_active_users", Source: models.QueryExpansionSourceKPI}, expansions[1])
+
+	expanded, expansions = expandNLQuery("gross turnover margin last quarter", models.LanguageEnglish, glossary, kpis)
+	assert.Equal(t, "gross turnover margin last quarter (gross margin)", expanded, "longer phrases win over the phrases they contain")
+	require.Len(t, expansions, 1)
+
+	expanded, expansions = expandNLQuery("revenue vs sales target", models.LanguageEnglish, glossary, kpis)
+	assert.Equal(t, "revenue vs sales target", expanded, "terms already in the question are not repeated")
+	assert.Empty(t, expansions)
+
+	expanded, _ = expandNLQuery("salesforce accounts", models.LanguageEnglish, glossary, kpis)
+	assert.Equal(t, "salesforce accounts", expanded, "only whole words match")
+}
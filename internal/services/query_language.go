@@ -0,0 +1,139 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// Common Indonesian words that do not occur in English questions; question
+// words are enough on their own to tell the language, markers need company
+var (
+	indonesianQuestionWords = map[string]bool{
+		"berapa": true, "apa": true, "siapa": true, "bagaimana": true, "kapan": true,
+		"mana": true, "tampilkan": true, "tunjukkan": true, "hitung": true, "daftar": true,
+	}
+	indonesianMarkers = map[string]bool{
+		"yang": true, "dan": true, "di": true, "dari": true, "untuk": true, "dengan": true,
+		"ini": true, "itu": true, "setiap": true, "berdasarkan": true, "selama": true,
+		"lalu": true, "terakhir": true, "tahun": true, "bulan": true, "minggu": true,
+		"hari": true, "jumlah": true, "rata": true, "tertinggi": true, "terendah": true,
+		"terbanyak": true, "kami": true, "kita": true, "adalah": true, "pada": true,
+	}
+)
+
+// indonesianDomainTerms translates Indonesian business vocabulary to the
+// English terms schemas use. Glossary translations extend this per user.
+var indonesianDomainTerms = map[string]string{
+	"penjualan":    "sales",
+	"pendapatan":   "revenue",
+	"omzet":        "revenue",
+	"laba":         "profit",
+	"keuntungan":   "profit",
+	"biaya":        "cost",
+	"harga":        "price",
+	"diskon":       "discount",
+	"pelanggan":    "customers",
+	"pengguna":     "users",
+	"karyawan":     "employees",
+	"pesanan":      "orders",
+	"transaksi":    "transactions",
+	"produk":       "products",
+	"stok":         "stock",
+	"toko":         "store",
+	"cabang":       "branch",
+	"kategori":     "category",
+	"wilayah":      "region",
+	"kota":         "city",
+	"negara":       "country",
+	"jumlah":       "count",
+	"rata-rata":    "average",
+	"pertumbuhan":  "growth",
+	"pengembalian": "refunds",
+	"tertinggi":    "highest",
+	"terendah":     "lowest",
+	"terbanyak":    "most",
+	"berdasarkan":  "by",
+	"harian":       "daily",
+	"mingguan":     "weekly",
+	"bulanan":      "monthly",
+	"tahunan":      "yearly",
+	"hari":         "day",
+	"minggu":       "week",
+	"bulan":        "month",
+	"kuartal":      "quarter",
+	"tahun":        "year",
+	"hari ini":     "today",
+	"kemarin":      "yesterday",
+	"minggu lalu":  "last week",
+	"bulan lalu":   "last month",
+	"tahun lalu":   "last year",
+}
+
+// detectQueryLanguage guesses the language of a question. Indonesian needs a
+// question word or two marker words; anything else is taken as English.
+func detectQueryLanguage(nlQuery string) string {
+	words := strings.FieldsFunc(strings.ToLower(nlQuery), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	markers := 0
+	for _, word := range words {
+		if indonesianQuestionWords[word] {
+			return models.LanguageIndonesian
+		}
+		if indonesianMarkers[word] {
+			markers++
+		}
+	}
+	if markers >= 2 {
+		return models.LanguageIndonesian
+	}
+	return models.LanguageEnglish
+}
+
+// builtinTranslations returns the built-in domain term translations of a language
+func builtinTranslations(language string) map[string]string {
+	if language == models.LanguageIndonesian {
+		return indonesianDomainTerms
+	}
+	return nil
+}
+
+// languagePromptSection tells the generator which language the question is in
+func languagePromptSection(language string) string {
+	if language == models.LanguageIndonesian {
+		return "\n\nThe question is in Indonesian. Table and column names are in English; " +
+			"the English terms in parentheses translate the domain words of the question.\n"
+	}
+	return ""
+}
+
+// indonesianMessages translates conversion messages. Entries ending in ": " are
+// prefixes followed by details that stay as they are.
+var indonesianMessages = map[string]string{
+	"Query has validation violations":  "Kueri memiliki pelanggaran validasi",
+	"Query has warnings":               "Kueri memiliki peringatan",
+	"Query exceeds the cost ceiling: ": "Kueri melebihi batas biaya: ",
+	"BigQuery estimate: ":              "Perkiraan BigQuery: ",
+	"Query is ready for execution":     "Kueri siap dijalankan",
+	"Dry run: the query was not saved; convert it without dry_run to execute it": "Uji coba: kueri tidak disimpan; konversi tanpa dry_run untuk menjalankannya",
+}
+
+// localizeMessage translates a conversion message to the language of the
+// question; messages without a translation are returned in English
+func localizeMessage(language, message string) string {
+	if language != models.LanguageIndonesian {
+		return message
+	}
+	if translated, ok := indonesianMessages[message]; ok {
+		return translated
+	}
+	for prefix, translated := range indonesianMessages {
+		if strings.HasSuffix(prefix, ": ") && strings.HasPrefix(message, prefix) {
+			return translated + strings.TrimPrefix(message, prefix)
+		}
+	}
+	return message
+}
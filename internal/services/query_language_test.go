@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectQueryLanguage(t *testing.T) {
+	for question, want := range map[string]string{
+		"Berapa total penjualan bulan lalu?":       models.LanguageIndonesian,
+		"tampilkan 10 produk terlaris":             models.LanguageIndonesian,
+		"jumlah pelanggan baru per kota tahun ini": models.LanguageIndonesian,
+		"What was total revenue last month?":       models.LanguageEnglish,
+		"revenue by month":                         models.LanguageEnglish,
+		"orders in Jakarta by day":                 models.LanguageEnglish,
+	} {
+		assert.Equal(t, want, detectQueryLanguage(question), question)
+	}
+}
+
+func TestExpandNLQueryTranslations(t *testing.T) {
+	glossary := []models.BusinessGlossary{
+		{Term: "gross merchandise value", Translations: models.JSON(`{"id": ["nilai transaksi bruto"]}`)},
+	}
+
+	expanded, expansions := expandNLQuery("Berapa penjualan dan nilai transaksi bruto bulan lalu?", models.LanguageIndonesian, glossary, nil)
+	assert.Equal(t, "Berapa penjualan dan nilai transaksi bruto bulan lalu? (sales, gross merchandise value, last month)", expanded)
+	require.Len(t, expansions, 3)
+	assert.Equal(t, models.QueryExpansion{Phrase: "nilai transaksi bruto", ExpandedTo: "gross merchandise value", Source: models.QueryExpansionSourceGlossary}, expansions[1])
+	assert.Equal(t, models.QueryExpansionSourceTranslation, expansions[2].Source)
+
+	expanded, _ = expandNLQuery("nilai transaksi bruto", models.LanguageEnglish, glossary, nil)
+	assert.Equal(t, "nilai transaksi bruto", expanded, "translations only apply to questions in their language")
+}
+
+func TestLocalizeMessage(t *testing.T) {
+	assert.Equal(t, "Kueri siap dijalankan", localizeMessage(models.LanguageIndonesian, "Query is ready for execution"))
+	assert.Equal(t, "Kueri melebihi batas biaya: scans 2 TB", localizeMessage(models.LanguageIndonesian, "Query exceeds the cost ceiling: scans 2 TB"))
+	assert.Equal(t, "Query is ready for execution", localizeMessage(models.LanguageEnglish, "Query is ready for execution"))
+}
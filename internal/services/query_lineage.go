@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// ErrParentQueryNotFound is returned when a query names a parent the user does not own
+var ErrParentQueryNotFound = errors.New("parent query not found")
+
+// maxQueryLineageDepth bounds the walk up to the root of a lineage
+const maxQueryLineageDepth = 100
+
+// GetQueryLineage returns the tree of queries a query of the user belongs to:
+// its oldest ancestor, every query derived from it, and their SQL versions
+func (s *NL2SQLService) GetQueryLineage(userID uint, queryID uint) (*models.QueryLineage, error) {
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk up to the oldest ancestor still in the user's history
+	queries := map[uint]models.NL2SQLQuery{query.ID: *query}
+	root := *query
+	for depth := 0; root.ParentQueryID != nil && depth < maxQueryLineageDepth; depth++ {
+		if _, seen := queries[*root.ParentQueryID]; seen {
+			break
+		}
+		var parent models.NL2SQLQuery
+		if err := s.db.Where("id = ? AND user_id = ?", *root.ParentQueryID, userID).Limit(1).Find(&parent).Error; err != nil {
+			return nil, fmt.Errorf("failed to get parent query: %v", err)
+		}
+		if parent.ID == 0 {
+			break
+		}
+		queries[parent.ID] = parent
+		root = parent
+	}
+
+	// Then collect the descendants of the root level by level
+	frontier := []uint{root.ID}
+	for depth := 0; len(frontier) > 0 && depth < maxQueryLineageDepth; depth++ {
+		var children []models.NL2SQLQuery
+		if err := s.db.Where("parent_query_id IN ? AND user_id = ?", frontier, userID).Order("id").Find(&children).Error; err != nil {
+			return nil, fmt.Errorf("failed to get derived queries: %v", err)
+		}
+		frontier = frontier[:0]
+		for _, child := range children {
+			if _, seen := queries[child.ID]; !seen {
+				queries[child.ID] = child
+				frontier = append(frontier, child.ID)
+			}
+		}
+	}
+
+	ids := make([]uint, 0, len(queries))
+	for id := range queries {
+		ids = append(ids, id)
+	}
+	var versions []models.QuerySQLVersion
+	if err := s.db.Where("query_id IN ?", ids).Order("query_id, version").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get SQL versions: %v", err)
+	}
+
+	return &models.QueryLineage{
+		QueryID: query.ID,
+		Root:    buildQueryLineage(root.ID, queries, versions),
+	}, nil
+}
+
+// buildQueryLineage assembles the lineage tree below a query. Versions are
+// expected in version order.
+func buildQueryLineage(rootID uint, queries map[uint]models.NL2SQLQuery, versions []models.QuerySQLVersion) models.QueryLineageNode {
+	versionsByQuery := make(map[uint][]models.QuerySQLVersion)
+	for _, version := range versions {
+		versionsByQuery[version.QueryID] = append(versionsByQuery[version.QueryID], version)
+	}
+	children := make(map[uint][]uint)
+	for id, query := range queries {
+		if query.ParentQueryID != nil && id != rootID {
+			children[*query.ParentQueryID] = append(children[*query.ParentQueryID], id)
+		}
+	}
+
+	var build func(id uint, depth int) models.QueryLineageNode
+	build = func(id uint, depth int) models.QueryLineageNode {
+		query := queries[id]
+		node := models.QueryLineageNode{
+			QueryID:          query.ID,
+			ParentQueryID:    query.ParentQueryID,
+			ParentSQLVersion: query.ParentSQLVersion,
+			NLQuery:          query.NLQuery,
+			Status:           query.Status,
+			CurrentVersion:   query.SQLVersion,
+			CreatedAt:        query.CreatedAt,
+			Versions:         []models.QueryLineageVersion{},
+			Children:         []models.QueryLineageNode{},
+		}
+		for _, version := range versionsByQuery[id] {
+			parentVersion := version.Version - 1
+			if version.Source == models.SQLVersionSourceRollback {
+				parentVersion = version.RolledBackFrom
+			}
+			node.Versions = append(node.Versions, models.QueryLineageVersion{
+				Version:       version.Version,
+				ParentVersion: parentVersion,
+				Source:        version.Source,
+				SQL:           version.SQL,
+				Comment:       version.Comment,
+				Current:       version.Version == query.SQLVersion,
+				CreatedBy:     version.CreatedBy,
+				CreatedAt:     version.CreatedAt,
+			})
+		}
+		if depth < maxQueryLineageDepth {
+			childIDs := children[id]
+			sort.Slice(childIDs, func(i, j int) bool { return childIDs[i] < childIDs[j] })
+			for _, childID := range childIDs {
+				node.Children = append(node.Children, build(childID, depth+1))
+			}
+		}
+		return node
+	}
+	return build(rootID, 0)
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildQueryLineage(t *testing.T) {
+	root, followUp := uint(1), uint(2)
+	queries := map[uint]models.NL2SQLQuery{
+		1: {ID: 1, NLQuery: "total sales", SQLVersion: 3},
+		2: {ID: 2, NLQuery: "and by month?", ParentQueryID: &root, ParentSQLVersion: 3, SQLVersion: 1},
+		3: {ID: 3, NLQuery: "only 2025", ParentQueryID: &followUp, ParentSQLVersion: 1},
+		4: {ID: 4, NLQuery: "by region", ParentQueryID: &root, ParentSQLVersion: 2},
+	}
+	versions := []models.QuerySQLVersion{
+		{QueryID: 1, Version: 1, Source: models.SQLVersionSourceGenerated},
+		{QueryID: 1, Version: 2, Source: models.SQLVersionSourceRefinement, Comment: "use orders"},
+		{QueryID: 1, Version: 3, Source: models.SQLVersionSourceRollback, RolledBackFrom: 1},
+		{QueryID: 2, Version: 1, Source: models.SQLVersionSourceGenerated},
+	}
+
+	lineage := buildQueryLineage(1, queries, versions)
+
+	assert.Equal(t, uint(1), lineage.QueryID)
+	require.Len(t, lineage.Versions, 3)
+	assert.Equal(t, 0, lineage.Versions[0].ParentVersion)
+	assert.Equal(t, 1, lineage.Versions[1].ParentVersion)
+	assert.Equal(t, 1, lineage.Versions[2].ParentVersion, "a rollback descends from the version it restored")
+	assert.True(t, lineage.Versions[2].Current)
+	assert.False(t, lineage.Versions[0].Current)
+
+	require.Len(t, lineage.Children, 2)
+	assert.Equal(t, uint(2), lineage.Children[0].QueryID)
+	assert.Equal(t, uint(4), lineage.Children[1].QueryID)
+	assert.Equal(t, 2, lineage.Children[1].ParentSQLVersion)
+	require.Len(t, lineage.Children[0].Children, 1)
+	assert.Equal(t, uint(3), lineage.Children[0].Children[0].QueryID)
+	assert.Empty(t, lineage.Children[1].Children)
+}
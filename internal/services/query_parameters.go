@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// ErrInvalidQueryParameters is returned when execution parameters do not match the query placeholders
+var ErrInvalidQueryParameters = errors.New("invalid query parameters")
+
+// maxQueryParameterListSize bounds the values bound to a single IN list placeholder
+const maxQueryParameterListSize = 1000
+
+// detectQueryParameters returns the {{name}} placeholders of a query in order of
+// first use. Query placeholders share the syntax of data API templates, so a
+// parameterized query can be published as is. Placeholders inside string
+// literals, quoted identifiers or comments are rejected because they cannot be
+// bound safely.
+func detectQueryParameters(sql string, dialect models.SQLDialect) ([]models.QueryParameter, error) {
+	if dialect == models.SQLDialectMongoDB {
+		return nil, nil // aggregation pipelines do not take parameters
+	}
+
+	matches := dataAPIPlaceholderPattern.FindAllStringSubmatch(sql, -1)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	tokens, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if !dataAPIPlaceholderPattern.MatchString(t.text) {
+			continue
+		}
+		switch t.kind {
+		case sqlTokenString, sqlTokenQuotedIdent:
+			return nil, errors.New("placeholders must be used as values, not inside quotes")
+		case sqlTokenComment:
+			return nil, errors.New("placeholders must be used as values, not inside comments")
+		}
+	}
+
+	seen := make(map[string]bool, len(matches))
+	var params []models.QueryParameter
+	for _, match := range matches {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			params = append(params, models.QueryParameter{Name: match[1]})
+		}
+	}
+	return params, nil
+}
+
+// marshalQueryParameters encodes detected placeholders for NL2SQLQuery.Parameters
+func marshalQueryParameters(params []models.QueryParameter) models.JSON {
+	if len(params) == 0 {
+		return nil
+	}
+	paramsJSON, _ := json.Marshal(params)
+	return models.JSON(paramsJSON)
+}
+
+// bindQueryParameters replaces the placeholders of a query with literals built
+// from the supplied values. Every placeholder needs a value and unknown names
+// are rejected, so a typo cannot silently drop a filter.
+func bindQueryParameters(sql string, params []models.QueryParameter, values map[string]interface{}, dialect models.SQLDialect) (string, error) {
+	declared := make(map[string]bool, len(params))
+	literals := make(map[string]string, len(params))
+	var problems []string
+	for _, param := range params {
+		declared[param.Name] = true
+		value, ok := values[param.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is required", param.Name))
+			continue
+		}
+
+		literal, err := queryParameterLiteral(value, dialect, true)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", param.Name, err))
+			continue
+		}
+		literals[param.Name] = literal
+	}
+	for name := range values {
+		if !declared[name] {
+			problems = append(problems, fmt.Sprintf("%s is not a parameter of this query", name))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return "", fmt.Errorf("%w: %s", ErrInvalidQueryParameters, strings.Join(problems, "; "))
+	}
+
+	return renderQueryParameters(sql, literals), nil
+}
+
+// renderQueryParameters replaces each placeholder with its literal, or NULL when
+// there is none. Rendering with NULLs lets the validator check a query template.
+func renderQueryParameters(sql string, literals map[string]string) string {
+	return dataAPIPlaceholderPattern.ReplaceAllStringFunc(sql, func(placeholder string) string {
+		name := dataAPIPlaceholderPattern.FindStringSubmatch(placeholder)[1]
+		if literal, ok := literals[name]; ok {
+			return literal
+		}
+		return "NULL"
+	})
+}
+
+// queryParameterLiteral converts a JSON value into a SQL literal. Arrays become
+// comma separated lists for IN (...) filters when allowList is set.
+func queryParameterLiteral(value interface{}, dialect models.SQLDialect, allowList bool) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", errors.New("must be a finite number")
+		}
+		return numericLiteral(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case int:
+		return numericLiteral(strconv.Itoa(v)), nil
+	case int64:
+		return numericLiteral(strconv.FormatInt(v, 10)), nil
+	case string:
+		if d, err := time.Parse("2006-01-02", v); err == nil {
+			return "DATE '" + d.Format("2006-01-02") + "'", nil
+		}
+		if ts, err := time.Parse(time.RFC3339, v); err == nil {
+			return "TIMESTAMP '" + ts.UTC().Format("2006-01-02 15:04:05") + "'", nil
+		}
+		return quoteStringLiteral(v, dialect), nil
+	case []interface{}:
+		if !allowList {
+			return "", errors.New("lists cannot be nested")
+		}
+		if len(v) == 0 {
+			return "", errors.New("list must not be empty")
+		}
+		if len(v) > maxQueryParameterListSize {
+			return "", fmt.Errorf("list has more than %d values", maxQueryParameterListSize)
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			literal, err := queryParameterLiteral(item, dialect, false)
+			if err != nil {
+				return "", err
+			}
+			items[i] = literal
+		}
+		return strings.Join(items, ", "), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// numericLiteral parenthesizes negative numbers so a minus sign next to the
+// placeholder cannot turn into a "--" comment
+func numericLiteral(number string) string {
+	if strings.HasPrefix(number, "-") {
+		return "(" + number + ")"
+	}
+	return number
+}
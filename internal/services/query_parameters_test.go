@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectQueryParameters(t *testing.T) {
+	params, err := detectQueryParameters(
+		"SELECT * FROM sales WHERE date >= {{start}} AND date < {{ end }} AND region IN ({{regions}}) AND date <> {{start}}",
+		models.SQLDialectPostgreSQL,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []models.QueryParameter{{Name: "start"}, {Name: "end"}, {Name: "regions"}}, params)
+
+	params, err = detectQueryParameters("SELECT * FROM sales LIMIT 10", models.SQLDialectPostgreSQL)
+	require.NoError(t, err)
+	assert.Nil(t, params)
+
+	_, err = detectQueryParameters("SELECT * FROM sales WHERE region = '{{region}}'", models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+	_, err = detectQueryParameters(`SELECT "{{column}}" FROM sales`, models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+	_, err = detectQueryParameters("SELECT * FROM sales -- region = {{region}}\nWHERE amount > {{min}}", models.SQLDialectPostgreSQL)
+	assert.EqualError(t, err, "placeholders must be used as values, not inside comments")
+	_, err = detectQueryParameters("SELECT * FROM sales /* {{region}} */ WHERE amount > {{min}}", models.SQLDialectPostgreSQL)
+	assert.EqualError(t, err, "placeholders must be used as values, not inside comments")
+}
+
+func TestBindQueryParameters(t *testing.T) {
+	sql := "SELECT * FROM sales WHERE date >= {{start}} AND region IN ({{regions}}) AND amount > {{min}} AND note = {{note}} AND active = {{active}}"
+	params := []models.QueryParameter{{Name: "start"}, {Name: "regions"}, {Name: "min"}, {Name: "note"}, {Name: "active"}}
+
+	bound, err := bindQueryParameters(sql, params, map[string]interface{}{
+		"start":   "2024-01-01",
+		"regions": []interface{}{"EU", "US"},
+		"min":     float64(-10.5),
+		"note":    "it's; DROP TABLE sales --",
+		"active":  true,
+	}, models.SQLDialectPostgreSQL)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM sales WHERE date >= DATE '2024-01-01' AND region IN ('EU', 'US') AND amount > (-10.5) AND note = 'it''s; DROP TABLE sales --' AND active = TRUE", bound)
+
+	bound, err = bindQueryParameters("SELECT * FROM t WHERE region = {{region}}", []models.QueryParameter{{Name: "region"}},
+		map[string]interface{}{"region": `it's \`}, models.SQLDialectBigQuery)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM t WHERE region = 'it\'s \\'`, bound)
+
+	bound, err = bindQueryParameters("SELECT * FROM t WHERE ts >= {{since}}", []models.QueryParameter{{Name: "since"}},
+		map[string]interface{}{"since": "2024-03-01T10:00:00+02:00"}, models.SQLDialectPostgreSQL)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE ts >= TIMESTAMP '2024-03-01 08:00:00'", bound)
+}
+
+func TestBindQueryParameters_Invalid(t *testing.T) {
+	params := []models.QueryParameter{{Name: "start"}, {Name: "regions"}}
+	sql := "SELECT * FROM sales WHERE date >= {{start}} AND region IN ({{regions}})"
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   string
+	}{
+		{"missing value", map[string]interface{}{"start": "2024-01-01"}, "regions is required"},
+		{"unknown parameter", map[string]interface{}{"start": "2024-01-01", "regions": []interface{}{"EU"}, "stat": 1}, "stat is not a parameter"},
+		{"empty list", map[string]interface{}{"start": "2024-01-01", "regions": []interface{}{}}, "list must not be empty"},
+		{"nested list", map[string]interface{}{"start": "2024-01-01", "regions": []interface{}{[]interface{}{"EU"}}}, "lists cannot be nested"},
+		{"object", map[string]interface{}{"start": map[string]interface{}{"a": 1}, "regions": []interface{}{"EU"}}, "unsupported value type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bindQueryParameters(sql, params, tt.values, models.SQLDialectPostgreSQL)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidQueryParameters))
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestRenderQueryParameters_ValidatesAsNull(t *testing.T) {
+	validator := NewSQLValidatorService()
+	sql := renderQueryParameters("SELECT * FROM sales WHERE date >= {{start}} AND region IN ({{regions}}) LIMIT 100", nil)
+	assert.Equal(t, "SELECT * FROM sales WHERE date >= NULL AND region IN (NULL) LIMIT 100", sql)
+
+	result, err := validator.ValidateSQLForDialect(sql, models.SQLDialectPostgreSQL)
+	require.NoError(t, err)
+	assert.True(t, result.IsValid)
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/pkg/objectstore"
+
+	"gorm.io/gorm"
+)
+
+// ErrQueryResultNotArchived is returned when rehydrating a result that is not archived
+var ErrQueryResultNotArchived = errors.New("query result is not archived")
+
+// errQueryResultArchiveRace means another instance archived the result first
+var errQueryResultArchiveRace = errors.New("query result was archived concurrently")
+
+// queryResultArchiveBatch bounds the results archived by one run of the job
+const queryResultArchiveBatch = 100
+
+// QueryResultArchiveService moves the rows of old query results to compressed
+// object storage. The query_results row stays as a stub with the metadata
+// (columns, row count, SQL version), and its rows can be rehydrated on demand.
+type QueryResultArchiveService struct {
+	db           *gorm.DB
+	store        objectstore.Store
+	archiveAfter time.Duration
+	now          func() time.Time
+}
+
+// NewQueryResultArchiveService creates a service archiving results older than archiveAfter
+func NewQueryResultArchiveService(db *gorm.DB, store objectstore.Store, archiveAfter time.Duration) *QueryResultArchiveService {
+	return &QueryResultArchiveService{
+		db:           db,
+		store:        store,
+		archiveAfter: archiveAfter,
+		now:          time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *QueryResultArchiveService) WithContext(ctx context.Context) *QueryResultArchiveService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// queryResultArchiveHeader is the first JSON value of an archive; one JSON
+// value per row follows, all gzip-compressed
+type queryResultArchiveHeader struct {
+	ResultID   uint            `json:"result_id"`
+	QueryID    uint            `json:"query_id"`
+	SQLVersion int             `json:"sql_version"`
+	RowCount   int64           `json:"row_count"`
+	Columns    json.RawMessage `json:"columns,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Archive moves the rows of results older than the archive age to object
+// storage. A run handles up to queryResultArchiveBatch results; failures are
+// reported and retried by the next run. Results of every tenant are archived.
+func (s *QueryResultArchiveService) Archive(ctx context.Context) (*models.QueryResultArchiveRun, error) {
+	ctx = tenancy.WithTenant(ctx, 0)
+	s = s.WithContext(ctx)
+	run := &models.QueryResultArchiveRun{Cutoff: s.now().Add(-s.archiveAfter)}
+
+	var results []models.QueryResult
+	err := s.db.Where("archived_at IS NULL AND created_at < ?", run.Cutoff).
+		Order("id").Limit(queryResultArchiveBatch).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results to archive: %w", err)
+	}
+
+	for i := range results {
+		if ctx.Err() != nil {
+			break
+		}
+		size, err := s.archiveResult(ctx, &results[i])
+		if errors.Is(err, errQueryResultArchiveRace) {
+			continue
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("result_id", results[i].ID).Msg("Failed to archive query result")
+			run.Failed++
+			run.Errors = append(run.Errors, fmt.Sprintf("result %d: %v", results[i].ID, err))
+			continue
+		}
+		run.Archived++
+		run.Rows += results[i].RowCount
+		run.Bytes += size
+	}
+	return run, nil
+}
+
+// archiveResult uploads the rows of a result, then clears them from the database
+func (s *QueryResultArchiveService) archiveResult(ctx context.Context, result *models.QueryResult) (int64, error) {
+	var buf bytes.Buffer
+	if err := s.writeArchive(&buf, result); err != nil {
+		return 0, err
+	}
+	size := int64(buf.Len())
+
+	key := queryResultArchiveKey(result)
+	if err := s.store.Put(ctx, key, &buf); err != nil {
+		return 0, fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		update := tx.Model(&models.QueryResult{}).
+			Where("id = ? AND archived_at IS NULL", result.ID).
+			Updates(map[string]interface{}{
+				"archived_at":   s.now(),
+				"archive_key":   key,
+				"archive_bytes": size,
+				"data":          nil,
+				"chunk_count":   0,
+			})
+		if update.Error != nil {
+			return fmt.Errorf("failed to mark result archived: %w", update.Error)
+		}
+		if update.RowsAffected == 0 {
+			return errQueryResultArchiveRace
+		}
+		if err := tx.Where("result_id = ?", result.ID).Delete(&models.QueryResultChunk{}).Error; err != nil {
+			return fmt.Errorf("failed to delete result chunks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// writeArchive writes the header and rows of a result, reading one chunk at a
+// time. Rows are copied as raw JSON so values round-trip exactly.
+func (s *QueryResultArchiveService) writeArchive(w io.Writer, result *models.QueryResult) error {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+
+	header := queryResultArchiveHeader{
+		ResultID:   result.ID,
+		QueryID:    result.QueryID,
+		SQLVersion: result.SQLVersion,
+		RowCount:   result.RowCount,
+		Columns:    json.RawMessage(result.Columns),
+		CreatedAt:  result.CreatedAt,
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	writeRows := func(data models.JSON) error {
+		if len(data) == 0 {
+			return nil
+		}
+		var rows []json.RawMessage
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return fmt.Errorf("failed to decode result rows: %w", err)
+		}
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return fmt.Errorf("failed to write archive: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if result.ChunkCount == 0 {
+		if err := writeRows(result.Data); err != nil {
+			return err
+		}
+	}
+	for index := 0; index < result.ChunkCount; index++ {
+		var chunk models.QueryResultChunk
+		if err := s.db.Where("result_id = ? AND chunk_index = ?", result.ID, index).First(&chunk).Error; err != nil {
+			return fmt.Errorf("failed to get result chunk %d: %w", index, err)
+		}
+		if err := writeRows(chunk.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// Rehydrate restores the rows of an archived result of the user's query so it
+// can be paged through again, then removes the archive
+func (s *QueryResultArchiveService) Rehydrate(ctx context.Context, userID, queryID, resultID uint) (*models.QueryResult, error) {
+	var result models.QueryResult
+	err := s.db.Select("query_results.*").
+		Joins("JOIN nl2_sql_queries ON nl2_sql_queries.id = query_results.query_id").
+		Where("query_results.id = ? AND query_results.query_id = ?", resultID, queryID).
+		Where("nl2_sql_queries.user_id = ? AND nl2_sql_queries.deleted_at IS NULL", userID).
+		First(&result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQueryResultNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query result: %w", err)
+	}
+	if result.ArchivedAt == nil {
+		return nil, ErrQueryResultNotArchived
+	}
+
+	archive, err := s.store.Get(ctx, result.ArchiveKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download archive: %w", err)
+	}
+	defer archive.Close()
+
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	dec := json.NewDecoder(gz)
+	dec.UseNumber()
+
+	var header queryResultArchiveHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if header.ResultID != result.ID {
+		return nil, fmt.Errorf("archive %s belongs to result %d", result.ArchiveKey, header.ResultID)
+	}
+
+	key := result.ArchiveKey
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result.RowCount, result.ChunkCount = 0, 0
+		writer := &QueryResultWriter{db: tx, result: &result}
+		for dec.More() {
+			var row map[string]interface{}
+			if err := dec.Decode(&row); err != nil {
+				return fmt.Errorf("failed to read archive: %w", err)
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+		if _, err := writer.Close(); err != nil {
+			return err
+		}
+		if result.RowCount != header.RowCount {
+			return fmt.Errorf("archive %s has %d rows, expected %d", key, result.RowCount, header.RowCount)
+		}
+
+		return tx.Model(&result).Updates(map[string]interface{}{
+			"archived_at":   nil,
+			"archive_key":   "",
+			"archive_bytes": 0,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Delete(ctx, key); err != nil {
+		logger.FromContext(ctx).Warn().Err(err).Str("key", key).Uint("result_id", result.ID).Msg("Failed to delete archive of rehydrated result")
+	}
+	result.ArchivedAt, result.ArchiveKey, result.ArchiveBytes = nil, "", 0
+	return &result, nil
+}
+
+// queryResultArchiveKey is the object storage key of a result's archive
+func queryResultArchiveKey(result *models.QueryResult) string {
+	return fmt.Sprintf("query-results/%d/%d.json.gz", result.QueryID, result.ID)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryResultArchive_WriteLegacyResult(t *testing.T) {
+	service := &QueryResultArchiveService{}
+	result := &models.QueryResult{
+		ID:         9,
+		QueryID:    4,
+		SQLVersion: 2,
+		RowCount:   2,
+		Columns:    models.JSON(`[{"name":"amount","type":"decimal"}]`),
+		Data:       models.JSON(`[{"amount":12345678901234567890},{"amount":0.1}]`),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, service.writeArchive(&buf, result))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	dec := json.NewDecoder(gz)
+
+	var header queryResultArchiveHeader
+	require.NoError(t, dec.Decode(&header))
+	assert.Equal(t, uint(9), header.ResultID)
+	assert.Equal(t, uint(4), header.QueryID)
+	assert.Equal(t, 2, header.SQLVersion)
+	assert.Equal(t, int64(2), header.RowCount)
+	assert.JSONEq(t, `[{"name":"amount","type":"decimal"}]`, string(header.Columns))
+
+	var rows []string
+	for dec.More() {
+		var row json.RawMessage
+		require.NoError(t, dec.Decode(&row))
+		rows = append(rows, string(row))
+	}
+	assert.Equal(t, []string{`{"amount":12345678901234567890}`, `{"amount":0.1}`}, rows, "rows are copied verbatim")
+}
+
+func TestQueryResultArchiveKey(t *testing.T) {
+	assert.Equal(t, "query-results/4/9.json.gz", queryResultArchiveKey(&models.QueryResult{ID: 9, QueryID: 4}))
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQueryResultNotFound is returned when a query has no such stored result
+	ErrQueryResultNotFound = errors.New("query result not found")
+	// ErrInvalidResultCursor is returned for malformed cursors or cursors of another result
+	ErrInvalidResultCursor = errors.New("invalid result cursor")
+	// ErrQueryResultArchived is returned when the rows of a result are in cold storage
+	ErrQueryResultArchived = errors.New("query result is archived; rehydrate it to read its rows")
+	// ErrQueryResultTooLarge is returned when a result has too many rows to load at once
+	ErrQueryResultTooLarge = errors.New("query result is too large")
+)
+
+const (
+	// queryResultChunkRows is the number of rows stored per result chunk
+	queryResultChunkRows = 500
+	// defaultResultPageLimit and maxResultPageLimit bound the rows returned per page
+	defaultResultPageLimit = 100
+	maxResultPageLimit     = 1000
+)
+
+// QueryResultService stores query results in chunks and pages through them
+type QueryResultService struct {
+	db *gorm.DB
+}
+
+// NewQueryResultService creates a new query result service
+func NewQueryResultService(db *gorm.DB) *QueryResultService {
+	return &QueryResultService{db: db}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *QueryResultService) WithContext(ctx context.Context) *QueryResultService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// QueryResultWriter appends rows to a stored result, flushing a chunk every
+// queryResultChunkRows rows so the whole result never has to be held at once
+type QueryResultWriter struct {
+	db      *gorm.DB
+	result  *models.QueryResult
+	pending []map[string]interface{}
+}
+
+// NewWriter creates the result record and returns a writer for its rows
+func (s *QueryResultService) NewWriter(queryID uint, sqlVersion int, columns []models.Column) (*QueryResultWriter, error) {
+	columnsJSON, err := json.Marshal(columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result columns: %w", err)
+	}
+
+	result := &models.QueryResult{
+		QueryID:    queryID,
+		SQLVersion: sqlVersion,
+		Columns:    models.JSON(columnsJSON),
+	}
+	if err := s.db.Create(result).Error; err != nil {
+		return nil, fmt.Errorf("failed to create query result: %w", err)
+	}
+
+	return &QueryResultWriter{db: s.db, result: result}, nil
+}
+
+// Write buffers a row and stores a chunk once enough rows are buffered
+func (w *QueryResultWriter) Write(row map[string]interface{}) error {
+	w.pending = append(w.pending, row)
+	if len(w.pending) >= queryResultChunkRows {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close stores the remaining rows and the final row count
+func (w *QueryResultWriter) Close() (*models.QueryResult, error) {
+	if len(w.pending) > 0 {
+		if err := w.flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	err := w.db.Model(w.result).Updates(map[string]interface{}{
+		"row_count":   w.result.RowCount,
+		"chunk_count": w.result.ChunkCount,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish query result: %w", err)
+	}
+	return w.result, nil
+}
+
+func (w *QueryResultWriter) flush() error {
+	dataJSON, err := json.Marshal(w.pending)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result rows: %w", err)
+	}
+
+	chunk := &models.QueryResultChunk{
+		TenantID:   w.result.TenantID,
+		ResultID:   w.result.ID,
+		ChunkIndex: w.result.ChunkCount,
+		RowOffset:  w.result.RowCount,
+		RowCount:   len(w.pending),
+		Data:       models.JSON(dataJSON),
+	}
+	if err := w.db.Create(chunk).Error; err != nil {
+		return fmt.Errorf("failed to store result chunk: %w", err)
+	}
+
+	w.result.ChunkCount++
+	w.result.RowCount += int64(len(w.pending))
+	w.pending = nil
+	return nil
+}
+
+// Store writes a complete set of rows as a chunked result
+func (s *QueryResultService) Store(queryID uint, sqlVersion int, columns []models.Column, rows []map[string]interface{}) (*models.QueryResult, error) {
+	writer, err := s.NewWriter(queryID, sqlVersion, columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	return writer.Close()
+}
+
+// Page returns rows of a stored result of the query. The latest result is used
+// when the request names none; a cursor takes precedence over the page number.
+func (s *QueryResultService) Page(queryID uint, req *models.QueryResultPageRequest) (*models.QueryResultPage, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultResultPageLimit
+	}
+	if limit > maxResultPageLimit {
+		limit = maxResultPageLimit
+	}
+
+	resultID := req.ResultID
+	var offset int64
+	if req.Cursor != "" {
+		cursorResultID, cursorOffset, err := decodeResultCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if resultID != 0 && resultID != cursorResultID {
+			return nil, fmt.Errorf("%w: cursor belongs to result %d", ErrInvalidResultCursor, cursorResultID)
+		}
+		resultID, offset = cursorResultID, cursorOffset
+	} else if req.Page > 1 {
+		offset = int64(req.Page-1) * int64(limit)
+	}
+
+	result, err := s.getResult(queryID, resultID)
+	if err != nil {
+		return nil, err
+	}
+	if result.ArchivedAt != nil {
+		return nil, ErrQueryResultArchived
+	}
+
+	var columns []models.Column
+	if len(result.Columns) > 0 {
+		if err := json.Unmarshal(result.Columns, &columns); err != nil {
+			return nil, fmt.Errorf("failed to decode result columns: %w", err)
+		}
+	}
+
+	rows, err := s.readRows(result, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.QueryResultPage{
+		QueryID:   queryID,
+		ResultID:  result.ID,
+		Columns:   columns,
+		Data:      rows,
+		TotalRows: result.RowCount,
+		Offset:    offset,
+		Limit:     limit,
+		Page:      int(offset/int64(limit)) + 1,
+		CreatedAt: result.CreatedAt,
+	}
+	if next := offset + int64(len(rows)); next < result.RowCount {
+		page.HasMore = true
+		page.NextCursor = encodeResultCursor(result.ID, next)
+	}
+	return page, nil
+}
+
+// ReadAll loads every row of a result of the query, or of its latest result
+// when resultID is 0, for processing in memory. Results of more than maxRows
+// rows are refused.
+func (s *QueryResultService) ReadAll(queryID, resultID uint, maxRows int64) (*models.QueryResult, []models.Column, []map[string]interface{}, error) {
+	result, err := s.getResult(queryID, resultID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if result.ArchivedAt != nil {
+		return nil, nil, nil, ErrQueryResultArchived
+	}
+	if result.RowCount > maxRows {
+		return nil, nil, nil, fmt.Errorf("%w: result %d has %d rows, at most %d can be processed", ErrQueryResultTooLarge, result.ID, result.RowCount, maxRows)
+	}
+
+	var columns []models.Column
+	if len(result.Columns) > 0 {
+		if err := json.Unmarshal(result.Columns, &columns); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode result columns: %w", err)
+		}
+	}
+	rows, err := s.readRows(result, 0, int(result.RowCount))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return result, columns, rows, nil
+}
+
+// getResult loads a result of the query, or its latest result when resultID is 0
+func (s *QueryResultService) getResult(queryID, resultID uint) (*models.QueryResult, error) {
+	query := s.db.Where("query_id = ?", queryID)
+	if resultID != 0 {
+		query = query.Where("id = ?", resultID)
+	}
+
+	var result models.QueryResult
+	if err := query.Order("id DESC").First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQueryResultNotFound
+		}
+		return nil, fmt.Errorf("failed to get query result: %w", err)
+	}
+	return &result, nil
+}
+
+// readRows returns up to limit rows starting at offset, loading only the chunks
+// that overlap the page. Results stored before chunking keep rows in Data.
+func (s *QueryResultService) readRows(result *models.QueryResult, offset int64, limit int) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	if offset >= result.RowCount {
+		return rows, nil
+	}
+
+	if result.ChunkCount == 0 {
+		var all []map[string]interface{}
+		if len(result.Data) > 0 {
+			if err := json.Unmarshal(result.Data, &all); err != nil {
+				return nil, fmt.Errorf("failed to decode result rows: %w", err)
+			}
+		}
+		return sliceRows(all, offset, limit), nil
+	}
+
+	var chunks []models.QueryResultChunk
+	end := offset + int64(limit)
+	err := s.db.Where("result_id = ? AND row_offset < ? AND row_offset + row_count > ?", result.ID, end, offset).
+		Order("chunk_index").Find(&chunks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result chunks: %w", err)
+	}
+
+	for _, chunk := range chunks {
+		var chunkRows []map[string]interface{}
+		if err := json.Unmarshal(chunk.Data, &chunkRows); err != nil {
+			return nil, fmt.Errorf("failed to decode result chunk %d: %w", chunk.ChunkIndex, err)
+		}
+		start := offset - chunk.RowOffset
+		if start < 0 {
+			start = 0
+		}
+		rows = append(rows, sliceRows(chunkRows, start, limit-len(rows))...)
+		if len(rows) >= limit {
+			break
+		}
+	}
+	return rows, nil
+}
+
+// DeleteForQuery removes every result of a query and its chunks
+func (s *QueryResultService) DeleteForQuery(tx *gorm.DB, queryID uint) error {
+	resultIDs := tx.Model(&models.QueryResult{}).Unscoped().Select("id").Where("query_id = ?", queryID)
+	if err := tx.Where("result_id IN (?)", resultIDs).Delete(&models.QueryResultChunk{}).Error; err != nil {
+		return fmt.Errorf("failed to delete query result chunks: %w", err)
+	}
+	if err := tx.Where("query_id = ?", queryID).Delete(&models.QueryResult{}).Error; err != nil {
+		return fmt.Errorf("failed to delete query results: %w", err)
+	}
+	return nil
+}
+
+// sliceRows returns up to limit rows starting at offset
+func sliceRows(rows []map[string]interface{}, offset int64, limit int) []map[string]interface{} {
+	if offset >= int64(len(rows)) || limit <= 0 {
+		return []map[string]interface{}{}
+	}
+	end := offset + int64(limit)
+	if end > int64(len(rows)) {
+		end = int64(len(rows))
+	}
+	return rows[offset:end]
+}
+
+// encodeResultCursor returns an opaque cursor for the row at offset of a result
+func encodeResultCursor(resultID uint, offset int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", resultID, offset)))
+}
+
+func decodeResultCursor(cursor string) (uint, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, ErrInvalidResultCursor
+	}
+	idPart, offsetPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, 0, ErrInvalidResultCursor
+	}
+	resultID, err := strconv.ParseUint(idPart, 10, 32)
+	if err != nil || resultID == 0 {
+		return 0, 0, ErrInvalidResultCursor
+	}
+	offset, err := strconv.ParseInt(offsetPart, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, 0, ErrInvalidResultCursor
+	}
+	return uint(resultID), offset, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCursor_RoundTrip(t *testing.T) {
+	cursor := encodeResultCursor(42, 1500)
+
+	resultID, offset, err := decodeResultCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), resultID)
+	assert.Equal(t, int64(1500), offset)
+}
+
+func TestResultCursor_Invalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	for _, cursor := range []string{
+		"not base64!",
+		encode("42"),
+		encode("0:10"),
+		encode("42:-1"),
+		encode("x:10"),
+		encode("42:ten"),
+	} {
+		_, _, err := decodeResultCursor(cursor)
+		assert.ErrorIs(t, err, ErrInvalidResultCursor, cursor)
+	}
+}
+
+func TestSliceRows(t *testing.T) {
+	rows := make([]map[string]interface{}, 5)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"n": i}
+	}
+
+	assert.Equal(t, rows[1:3], sliceRows(rows, 1, 2))
+	assert.Equal(t, rows[3:], sliceRows(rows, 3, 10))
+	assert.Empty(t, sliceRows(rows, 5, 10))
+	assert.Empty(t, sliceRows(rows, 0, 0))
+	assert.NotNil(t, sliceRows(nil, 0, 10))
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/pkg/objectstore"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidHistoryFilter is returned when a bulk delete has no filter or an unreadable date
+var ErrInvalidHistoryFilter = errors.New("invalid query history filter")
+
+// queryRetentionBatch bounds the rows deleted per statement, so a purge does
+// not hold long locks on the history tables
+const queryRetentionBatch = 500
+
+// QueryRetentionService keeps the query history from growing unbounded: a
+// scheduled purge deletes results and queries older than their retention, and
+// bulk deletes remove history by data source or date range. Deletes are
+// permanent, including soft-deleted rows and archived result rows.
+type QueryRetentionService struct {
+	db              *gorm.DB
+	store           objectstore.Store
+	resultRetention time.Duration
+	queryRetention  time.Duration
+	now             func() time.Time
+}
+
+// NewQueryRetentionService creates a service purging results older than
+// resultRetention and queries older than queryRetention; zero keeps them
+func NewQueryRetentionService(db *gorm.DB, store objectstore.Store, resultRetention, queryRetention time.Duration) *QueryRetentionService {
+	return &QueryRetentionService{
+		db:              db,
+		store:           store,
+		resultRetention: resultRetention,
+		queryRetention:  queryRetention,
+		now:             time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *QueryRetentionService) WithContext(ctx context.Context) *QueryRetentionService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the purge job; it is scheduled at the retention interval
+func (s *QueryRetentionService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeQueryRetention, func(ctx context.Context, _ json.RawMessage) error {
+		run, err := s.Purge(ctx)
+		if err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info().Int64("queries", run.QueriesDeleted).Int64("results", run.ResultsDeleted).
+			Int("archives", run.ArchivesDeleted).Msg("Query history retention purge completed")
+		return nil
+	})
+}
+
+// Purge deletes the results and then the queries older than their retention.
+// Retention applies to the history of every tenant.
+func (s *QueryRetentionService) Purge(ctx context.Context) (*models.QueryRetentionRun, error) {
+	ctx = tenancy.WithTenant(ctx, 0)
+	s = s.WithContext(ctx)
+	run := &models.QueryRetentionRun{}
+
+	if s.resultRetention > 0 {
+		cutoff := s.now().Add(-s.resultRetention)
+		run.ResultCutoff = &cutoff
+		for ctx.Err() == nil {
+			var results []models.QueryResult
+			if err := s.db.Unscoped().Select("id", "archive_key").Where("created_at < ?", cutoff).
+				Order("id").Limit(queryRetentionBatch).Find(&results).Error; err != nil {
+				return nil, fmt.Errorf("failed to get results to purge: %w", err)
+			}
+			if len(results) == 0 {
+				break
+			}
+			if err := s.deleteResults(ctx, results, run); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.queryRetention > 0 {
+		cutoff := s.now().Add(-s.queryRetention)
+		run.QueryCutoff = &cutoff
+		if err := s.deleteQueries(ctx, s.db.Unscoped().Where("created_at < ?", cutoff), run); err != nil {
+			return nil, err
+		}
+	}
+	return run, ctx.Err()
+}
+
+// DeleteHistory permanently deletes the queries matching the filter with
+// their results, SQL versions and collaboration records
+func (s *QueryRetentionService) DeleteHistory(ctx context.Context, filter *models.QueryHistoryDeleteRequest) (*models.QueryRetentionRun, error) {
+	scope, err := queryHistoryScope(s.db.Unscoped(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.QueryRetentionRun{}
+	if err := s.deleteQueries(ctx, scope, run); err != nil {
+		return nil, err
+	}
+	return run, ctx.Err()
+}
+
+// deleteQueries deletes the queries of the scope in batches
+func (s *QueryRetentionService) deleteQueries(ctx context.Context, scope *gorm.DB, run *models.QueryRetentionRun) error {
+	for ctx.Err() == nil {
+		var ids []uint
+		if err := scope.Session(&gorm.Session{}).Model(&models.NL2SQLQuery{}).
+			Order("id").Limit(queryRetentionBatch).Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("failed to get queries to delete: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		var results []models.QueryResult
+		if err := s.db.Unscoped().Select("id", "archive_key").Where("query_id IN ?", ids).Find(&results).Error; err != nil {
+			return fmt.Errorf("failed to get query results: %w", err)
+		}
+		if err := s.deleteResults(ctx, results, run); err != nil {
+			return err
+		}
+
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			for _, model := range []interface{}{
+				&models.QuerySQLVersion{},
+				&models.QuerySQLSuggestion{},
+				&models.QueryCollaborationLink{},
+				&models.QueryCollaborationEvent{},
+				&models.RAGQueryContext{},
+			} {
+				if err := tx.Where("query_id IN ?", ids).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			// Derived queries stay in the history as roots of their own lineage
+			if err := tx.Unscoped().Model(&models.NL2SQLQuery{}).Where("parent_query_id IN ?", ids).
+				UpdateColumn("parent_query_id", nil).Error; err != nil {
+				return err
+			}
+			// Saved queries keep their pinned SQL; only the link to the history goes
+			if err := tx.Model(&models.SavedQuery{}).Where("query_id IN ?", ids).Update("query_id", nil).Error; err != nil {
+				return err
+			}
+			deleted := tx.Unscoped().Where("id IN ?", ids).Delete(&models.NL2SQLQuery{})
+			run.QueriesDeleted += deleted.RowsAffected
+			return deleted.Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete queries: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteResults deletes results with their chunks and archived rows. Archives
+// that cannot be removed are reported; the database rows are deleted anyway.
+func (s *QueryRetentionService) deleteResults(ctx context.Context, results []models.QueryResult, run *models.QueryRetentionRun) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+		if result.ArchiveKey == "" {
+			continue
+		}
+		if err := s.store.Delete(ctx, result.ArchiveKey); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("result_id", result.ID).Msg("Failed to delete query result archive")
+			run.Errors = append(run.Errors, fmt.Sprintf("result %d: %v", result.ID, err))
+			continue
+		}
+		run.ArchivesDeleted++
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("result_id IN ?", ids).Delete(&models.QueryResultChunk{}).Error; err != nil {
+			return err
+		}
+		deleted := tx.Unscoped().Where("id IN ?", ids).Delete(&models.QueryResult{})
+		run.ResultsDeleted += deleted.RowsAffected
+		return deleted.Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete query results: %w", err)
+	}
+	return nil
+}
+
+// queryHistoryScope narrows queries to a bulk delete filter
+func queryHistoryScope(db *gorm.DB, filter *models.QueryHistoryDeleteRequest) (*gorm.DB, error) {
+	from, err := parseHistoryTime(filter.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from: %v", ErrInvalidHistoryFilter, err)
+	}
+	to, err := parseHistoryTime(filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to: %v", ErrInvalidHistoryFilter, err)
+	}
+	if filter.DataSourceID == 0 && from == nil && to == nil {
+		return nil, fmt.Errorf("%w: data_source_id, from or to is required", ErrInvalidHistoryFilter)
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidHistoryFilter)
+	}
+
+	if filter.UserID != 0 {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if filter.DataSourceID != 0 {
+		db = db.Where("data_source_id = ?", filter.DataSourceID)
+	}
+	if from != nil {
+		db = db.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		db = db.Where("created_at < ?", *to)
+	}
+	return db, nil
+}
+
+// parseHistoryTime reads a YYYY-MM-DD date (UTC midnight) or an RFC 3339
+// timestamp; an empty value is no bound
+func parseHistoryTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a YYYY-MM-DD date or RFC 3339 timestamp", value)
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHistoryTime(t *testing.T) {
+	date, err := parseHistoryTime("2025-09-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), *date)
+
+	timestamp, err := parseHistoryTime("2025-09-01T08:30:00+07:00")
+	require.NoError(t, err)
+	assert.True(t, timestamp.Equal(time.Date(2025, 9, 1, 1, 30, 0, 0, time.UTC)))
+
+	empty, err := parseHistoryTime(" ")
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+
+	_, err = parseHistoryTime("01/09/2025")
+	assert.Error(t, err)
+}
+
+func TestQueryHistoryScopeRejectsUnboundedFilters(t *testing.T) {
+	// A user filter alone would delete a user's whole history
+	_, err := queryHistoryScope(nil, &models.QueryHistoryDeleteRequest{UserID: 1})
+	assert.ErrorIs(t, err, ErrInvalidHistoryFilter)
+
+	_, err = queryHistoryScope(nil, &models.QueryHistoryDeleteRequest{From: "2025-09-02", To: "2025-09-01"})
+	assert.ErrorIs(t, err, ErrInvalidHistoryFilter)
+
+	_, err = queryHistoryScope(nil, &models.QueryHistoryDeleteRequest{DataSourceID: 3, To: "yesterday"})
+	assert.ErrorIs(t, err, ErrInvalidHistoryFilter)
+}
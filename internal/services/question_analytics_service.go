@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// Defaults and bounds of similar question search and topic clustering
+const (
+	similarQuestionsLimit    = 5
+	similarQuestionsMinScore = 0.75
+	similarQuestionsScanned  = 1000 // Most recent questions of the user compared
+	questionTopicsDays       = 30
+	questionTopicsThreshold  = 0.85
+	questionTopicsMinSize    = 2
+	questionTopicsLimit      = 20
+	questionTopicsScanned    = 1000 // Most recent questions clustered
+	questionTopicSamples     = 5
+)
+
+// QuestionAnalyticsService keeps the embedding and retrieved context of every
+// converted question, to find a user's similar past questions and to cluster
+// the questions asked into topics
+type QuestionAnalyticsService struct {
+	db         *gorm.DB
+	repo       repositories.RAGRepository
+	ragService *RAGService
+}
+
+// NewQuestionAnalyticsService creates a new question analytics service
+func NewQuestionAnalyticsService(db *gorm.DB, ragService *RAGService) *QuestionAnalyticsService {
+	return &QuestionAnalyticsService{
+		db:         db,
+		repo:       repositories.NewRAGRepository(db),
+		ragService: ragService,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *QuestionAnalyticsService) WithContext(ctx context.Context) *QuestionAnalyticsService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.repo = s.repo.WithContext(ctx)
+	scoped.ragService = s.ragService.WithContext(ctx)
+	return &scoped
+}
+
+// Record stores the question of a converted query with its embedding and the
+// context retrieved for it. A failure is logged rather than failing the
+// conversion that already happened.
+func (s *QuestionAnalyticsService) Record(ctx context.Context, query *models.NL2SQLQuery, embedding []float32, retrieved *models.RetrievedContext) {
+	if len(embedding) == 0 {
+		return
+	}
+
+	contextJSON, err := json.Marshal(map[string]interface{}{
+		"language":  query.Language,
+		"retrieved": retrieved,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("query_id", query.ID).Msg("Failed to encode RAG query context")
+		return
+	}
+
+	queryID := query.ID
+	record := &models.RAGQueryContext{
+		UserID:       query.UserID,
+		DataSourceID: query.DataSourceID,
+		QueryID:      &queryID,
+		Query:        query.NLQuery,
+		Context:      models.JSON(contextJSON),
+		Embedding:    embedding,
+	}
+	if err := s.repo.CreateRAGQueryContext(record); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint("query_id", query.ID).Msg("Failed to record RAG query context")
+	}
+}
+
+// SimilarQuestions returns the user's past questions on the data source most
+// similar to the request query, one per distinct question, with their SQL
+func (s *QuestionAnalyticsService) SimilarQuestions(ctx context.Context, userID uint, req *models.SimilarQuestionsRequest) ([]models.SimilarQuestion, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = similarQuestionsLimit
+	}
+	minScore := req.MinScore
+	if minScore <= 0 {
+		minScore = similarQuestionsMinScore
+	}
+
+	embedding, err := s.ragService.embeddingService.GenerateEmbedding(ctx, req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	var contexts []models.RAGQueryContext
+	if err := s.questionScope().
+		Where("user_id = ? AND data_source_id = ?", userID, req.DataSourceID).
+		Order("created_at DESC").Limit(similarQuestionsScanned).
+		Find(&contexts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get past questions: %w", err)
+	}
+
+	// Contexts are newest first, so a question asked again keeps its latest query
+	seen := make(map[string]bool)
+	var similar []models.SimilarQuestion
+	for _, questionContext := range contexts {
+		key := normalizeQuestion(questionContext.Query)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		score := s.ragService.cosineSimilarity(embedding, questionContext.Embedding)
+		if score < minScore {
+			continue
+		}
+		similar = append(similar, models.SimilarQuestion{
+			QueryID:      *questionContext.QueryID,
+			DataSourceID: questionContext.DataSourceID,
+			Question:     questionContext.Query,
+			Score:        score,
+			AskedAt:      questionContext.CreatedAt,
+		})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool { return similar[i].Score > similar[j].Score })
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	if len(similar) == 0 {
+		return []models.SimilarQuestion{}, nil
+	}
+
+	// Add the SQL each question was converted to
+	ids := make([]uint, len(similar))
+	for i, question := range similar {
+		ids[i] = question.QueryID
+	}
+	var queries []models.NL2SQLQuery
+	if err := s.db.Select("id", "generated_sql", "status").Where("id IN ?", ids).Find(&queries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get past queries: %w", err)
+	}
+	byID := make(map[uint]models.NL2SQLQuery, len(queries))
+	for _, query := range queries {
+		byID[query.ID] = query
+	}
+	for i := range similar {
+		similar[i].GeneratedSQL = byID[similar[i].QueryID].GeneratedSQL
+		similar[i].Status = byID[similar[i].QueryID].Status
+	}
+
+	return similar, nil
+}
+
+// Topics clusters the recent questions of all users into topics of similar
+// questions and returns the most frequent ones
+func (s *QuestionAnalyticsService) Topics(req *models.QuestionTopicsRequest) (*models.QuestionTopicsResponse, error) {
+	days := req.Days
+	if days <= 0 {
+		days = questionTopicsDays
+	}
+	threshold := req.Threshold
+	if threshold <= 0 {
+		threshold = questionTopicsThreshold
+	}
+	minQuestions := req.MinQuestions
+	if minQuestions <= 0 {
+		minQuestions = questionTopicsMinSize
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = questionTopicsLimit
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	scope := s.questionScope().Where("created_at >= ?", since)
+	if req.DataSourceID != 0 {
+		scope = scope.Where("data_source_id = ?", req.DataSourceID)
+	}
+	var contexts []models.RAGQueryContext
+	if err := scope.Select("id", "user_id", "query", "embedding", "created_at").
+		Order("created_at DESC").Limit(questionTopicsScanned).
+		Find(&contexts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get questions: %w", err)
+	}
+
+	topics := []models.QuestionTopic{}
+	for _, topic := range s.clusterQuestions(contexts, threshold) {
+		if topic.Questions >= minQuestions {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) > limit {
+		topics = topics[:limit]
+	}
+
+	return &models.QuestionTopicsResponse{
+		Topics:            topics,
+		QuestionsAnalyzed: len(contexts),
+		Since:             since,
+		Threshold:         threshold,
+	}, nil
+}
+
+// frequentQuestions clusters the questions asked on the data source since the
+// given time whose query converted to valid SQL, largest topic first
+func (s *QuestionAnalyticsService) frequentQuestions(dataSourceID uint, since time.Time) ([]models.QuestionTopic, error) {
+	completed := s.db.Model(&models.NL2SQLQuery{}).Select("id").Where("status = ?", models.QueryStatusCompleted)
+
+	var contexts []models.RAGQueryContext
+	if err := s.db.Model(&models.RAGQueryContext{}).Where("query_id IN (?)", completed).
+		Where("data_source_id = ? AND created_at >= ?", dataSourceID, since).
+		Select("id", "user_id", "query", "embedding", "created_at").
+		Order("created_at DESC").Limit(questionTopicsScanned).
+		Find(&contexts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get questions: %w", err)
+	}
+	return s.clusterQuestions(contexts, questionTopicsThreshold), nil
+}
+
+// questionScope selects the recorded questions whose query is still in the
+// history, so deleted questions no longer show up
+func (s *QuestionAnalyticsService) questionScope() *gorm.DB {
+	return s.db.Model(&models.RAGQueryContext{}).
+		Where("query_id IN (?)", s.db.Model(&models.NL2SQLQuery{}).Select("id"))
+}
+
+// questionCluster is a topic being built: the sum of its member embeddings,
+// whose direction is the centre of the topic, and the members
+type questionCluster struct {
+	sum     []float32
+	members []*models.RAGQueryContext
+}
+
+// clusterQuestions groups questions, given newest first, in a single pass:
+// each question joins the topic whose centre is most similar to it when that
+// similarity reaches the threshold, and starts a new topic otherwise. Topics
+// are returned largest first.
+func (s *QuestionAnalyticsService) clusterQuestions(contexts []models.RAGQueryContext, threshold float64) []models.QuestionTopic {
+	var clusters []*questionCluster
+	for i := len(contexts) - 1; i >= 0; i-- {
+		question := &contexts[i]
+		if len(question.Embedding) == 0 {
+			continue
+		}
+
+		var best *questionCluster
+		bestScore := threshold
+		for _, cluster := range clusters {
+			if score := s.ragService.cosineSimilarity(question.Embedding, cluster.sum); score >= bestScore {
+				best, bestScore = cluster, score
+			}
+		}
+		if best == nil {
+			best = &questionCluster{sum: make([]float32, len(question.Embedding))}
+			clusters = append(clusters, best)
+		}
+		for j, value := range question.Embedding {
+			best.sum[j] += value
+		}
+		best.members = append(best.members, question)
+	}
+
+	topics := make([]models.QuestionTopic, 0, len(clusters))
+	for _, cluster := range clusters {
+		topics = append(topics, s.describeTopic(cluster))
+	}
+	sort.SliceStable(topics, func(i, j int) bool {
+		if topics[i].Questions != topics[j].Questions {
+			return topics[i].Questions > topics[j].Questions
+		}
+		return topics[i].LastAskedAt.After(topics[j].LastAskedAt)
+	})
+	return topics
+}
+
+// describeTopic labels a cluster with its most central question and samples
+// its distinct questions, most recent first
+func (s *QuestionAnalyticsService) describeTopic(cluster *questionCluster) models.QuestionTopic {
+	topic := models.QuestionTopic{Questions: len(cluster.members), Samples: []string{}}
+
+	users := make(map[uint]bool)
+	seen := make(map[string]bool)
+	bestScore := -1.0
+	// Members were added oldest first
+	for i := len(cluster.members) - 1; i >= 0; i-- {
+		member := cluster.members[i]
+		users[member.UserID] = true
+		if member.CreatedAt.After(topic.LastAskedAt) {
+			topic.LastAskedAt = member.CreatedAt
+		}
+		if score := s.ragService.cosineSimilarity(member.Embedding, cluster.sum); score > bestScore {
+			topic.Label, bestScore = member.Query, score
+		}
+		if key := normalizeQuestion(member.Query); !seen[key] && len(topic.Samples) < questionTopicSamples {
+			seen[key] = true
+			topic.Samples = append(topic.Samples, member.Query)
+		}
+	}
+	topic.Users = len(users)
+	return topic
+}
+
+// normalizeQuestion folds case, spacing and final punctuation, so the same
+// question asked twice counts as one
+func normalizeQuestion(question string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(strings.ToLower(question)), " "), "?.! ")
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionAnalyticsService_ClusterQuestions(t *testing.T) {
+	service := &QuestionAnalyticsService{ragService: &RAGService{}}
+	asked := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	// Newest first, like the contexts are loaded
+	contexts := []models.RAGQueryContext{
+		{UserID: 2, Query: "Revenue by month", Embedding: []float32{0.95, 0.05, 0}, CreatedAt: asked.Add(5 * time.Hour)},
+		{UserID: 3, Query: "How many active users?", Embedding: []float32{0, 1, 0.1}, CreatedAt: asked.Add(4 * time.Hour)},
+		{UserID: 1, Query: "revenue  by month", Embedding: []float32{0.95, 0.05, 0}, CreatedAt: asked.Add(3 * time.Hour)},
+		{UserID: 1, Query: "Total revenue last month", Embedding: []float32{1, 0, 0}, CreatedAt: asked.Add(2 * time.Hour)},
+		{UserID: 4, Query: "Top products by margin", Embedding: []float32{0, 0, 1}, CreatedAt: asked.Add(time.Hour)},
+		{UserID: 2, Query: "Active users this week", Embedding: []float32{0.05, 1, 0}, CreatedAt: asked},
+	}
+
+	topics := service.clusterQuestions(contexts, 0.9)
+	require.Len(t, topics, 3)
+
+	// Largest topic first, labelled by its most central question
+	revenue := topics[0]
+	assert.Equal(t, 3, revenue.Questions)
+	assert.Equal(t, 2, revenue.Users)
+	assert.Equal(t, asked.Add(5*time.Hour), revenue.LastAskedAt)
+	assert.Equal(t, "Revenue by month", revenue.Label)
+	// The same question asked twice is sampled once, most recent first
+	assert.Equal(t, []string{"Revenue by month", "Total revenue last month"}, revenue.Samples)
+
+	assert.Equal(t, 2, topics[1].Questions)
+	assert.Equal(t, 2, topics[1].Users)
+	assert.Equal(t, []string{"Top products by margin"}, topics[2].Samples)
+
+	// Without a threshold every question joins the first topic
+	assert.Len(t, service.clusterQuestions(contexts, 0), 1)
+	assert.Empty(t, service.clusterQuestions(nil, 0.9))
+}
+
+func TestNormalizeQuestion(t *testing.T) {
+	assert.Equal(t, "revenue by month", normalizeQuestion("  Revenue   by\tMonth "))
+	assert.Equal(t, "revenue by month", normalizeQuestion("revenue by month?"))
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tokenizer"
 	"gorm.io/gorm"
 )
 
@@ -16,24 +18,51 @@ import (
 type RAGService struct {
 	db               *gorm.DB
 	embeddingService *EmbeddingService
+	rerankService    *RerankService
+	promptTemplates  *PromptTemplateService
+	retrievalConfigs *RetrievalConfigService
 }
 
 // NewRAGService creates a new RAG service
-func NewRAGService(db *gorm.DB, embeddingService *EmbeddingService) *RAGService {
+func NewRAGService(db *gorm.DB, embeddingService *EmbeddingService, rerankService *RerankService) *RAGService {
 	return &RAGService{
 		db:               db,
 		embeddingService: embeddingService,
+		rerankService:    rerankService,
+		promptTemplates:  NewPromptTemplateService(db),
+		retrievalConfigs: NewRetrievalConfigService(db),
 	}
 }
 
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *RAGService) WithContext(ctx context.Context) *RAGService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.embeddingService = s.embeddingService.WithContext(ctx)
+	scoped.promptTemplates = s.promptTemplates.WithContext(ctx)
+	scoped.retrievalConfigs = s.retrievalConfigs.WithContext(ctx)
+	return &scoped
+}
+
+// NL2SQLContextOptions controls how retrieval context is assembled
+type NL2SQLContextOptions struct {
+	Rerank          bool    // Rerank retrieved candidates before building the context
+	RerankThreshold float64 // Minimum rerank relevance to keep (0 uses the reranker default)
+}
+
 // SearchResult represents a search result with similarity score
 type SearchResult struct {
 	Embedding *models.SchemaEmbedding
 	Score     float64
 }
 
-// SearchSimilar performs similarity search using cosine similarity
-func (s *RAGService) SearchSimilar(ctx context.Context, query string, dataSourceID uint, topK int, elementTypes []string) (*models.RAGSearchResponse, error) {
+// SearchSimilar performs similarity search using cosine similarity over the
+// embeddings owned by the user
+func (s *RAGService) SearchSimilar(ctx context.Context, userID uint, query string, dataSourceID uint, topK int, elementTypes []string) (*models.RAGSearchResponse, error) {
 	if topK <= 0 {
 		topK = 5
 	}
@@ -47,8 +76,23 @@ func (s *RAGService) SearchSimilar(ctx context.Context, query string, dataSource
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// Build query conditions
-	queryBuilder := s.db.Model(&models.SchemaEmbedding{})
+	searchResults, err := s.searchEmbeddings(userID, queryEmbedding, dataSourceID, topK, elementTypes, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RAGSearchResponse{
+		Results: searchResults,
+		Query:   query,
+		TopK:    topK,
+	}, nil
+}
+
+// searchEmbeddings returns the topK embeddings of the user most similar to the
+// query embedding, leaving out those scoring below minScore
+func (s *RAGService) searchEmbeddings(userID uint, queryEmbedding []float32, dataSourceID uint, topK int, elementTypes []string, minScore float64) ([]models.RAGSearchResult, error) {
+	// Build query conditions; other users' KPIs, glossary terms and tables never match
+	queryBuilder := s.db.Model(&models.SchemaEmbedding{}).Where("user_id = ?", userID)
 
 	// Filter by data source (0 means global like KPIs and glossary)
 	if dataSourceID > 0 {
@@ -72,6 +116,9 @@ func (s *RAGService) SearchSimilar(ctx context.Context, query string, dataSource
 	var results []SearchResult
 	for _, embedding := range embeddings {
 		score := s.cosineSimilarity(queryEmbedding, embedding.Embedding)
+		if score < minScore {
+			continue
+		}
 		results = append(results, SearchResult{
 			Embedding: &embedding,
 			Score:     score,
@@ -105,50 +152,246 @@ func (s *RAGService) SearchSimilar(ctx context.Context, query string, dataSource
 		})
 	}
 
-	return &models.RAGSearchResponse{
-		Results: searchResults,
-		Query:   query,
-		TopK:    topK,
-	}, nil
+	return searchResults, nil
 }
 
 // BuildNL2SQLContext builds context for NL2SQL conversion
-func (s *RAGService) BuildNL2SQLContext(ctx context.Context, query string, dataSourceID uint) (map[string]interface{}, error) {
-	// Search for relevant schema elements
-	schemaResults, err := s.SearchSimilar(ctx, query, dataSourceID, 10, []string{"table", "column"})
+func (s *RAGService) BuildNL2SQLContext(ctx context.Context, userID uint, query string, dataSourceID uint) (map[string]interface{}, error) {
+	return s.BuildNL2SQLContextWithOptions(ctx, userID, query, dataSourceID, NL2SQLContextOptions{})
+}
+
+// BuildNL2SQLContextWithOptions builds context for NL2SQL conversion with the
+// retrieval config of the data source, optionally reranking the retrieved
+// candidates so only the most relevant ones reach the prompt
+func (s *RAGService) BuildNL2SQLContextWithOptions(ctx context.Context, userID uint, query string, dataSourceID uint, opts NL2SQLContextOptions) (map[string]interface{}, error) {
+	context, _, err := s.buildNL2SQLContext(ctx, userID, query, dataSourceID, opts)
+	return context, err
+}
+
+// buildNL2SQLContext builds the context of BuildNL2SQLContextWithOptions and
+// also returns the embedding of the query
+func (s *RAGService) buildNL2SQLContext(ctx context.Context, userID uint, query string, dataSourceID uint, opts NL2SQLContextOptions) (map[string]interface{}, []float32, error) {
+	config, err := s.retrievalConfigs.Resolve(dataSourceID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search schema: %w", err)
+		return nil, nil, err
 	}
 
-	// Search for relevant KPIs
-	kpiResults, err := s.SearchSimilar(ctx, query, 0, 5, []string{"kpi"})
+	queryEmbedding, err := s.embeddingService.GenerateEmbedding(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search KPIs: %w", err)
+		return nil, nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// Search for relevant glossary terms
-	glossaryResults, err := s.SearchSimilar(ctx, query, 0, 5, []string{"glossary"})
-	if err != nil {
-		return nil, fmt.Errorf("failed to search glossary: %w", err)
+	// Search for relevant schema elements, KPIs and glossary terms
+	var schemaResults, kpiResults, glossaryResults []models.RAGSearchResult
+	if config.SchemaTopK > 0 {
+		if schemaResults, err = s.searchEmbeddings(userID, queryEmbedding, dataSourceID, config.SchemaTopK, config.GetSchemaElementTypes(), config.MinScore); err != nil {
+			return nil, nil, fmt.Errorf("failed to search schema: %w", err)
+		}
+	}
+	if config.KPITopK > 0 {
+		if kpiResults, err = s.searchEmbeddings(userID, queryEmbedding, 0, config.KPITopK, []string{"kpi"}, config.MinScore); err != nil {
+			return nil, nil, fmt.Errorf("failed to search KPIs: %w", err)
+		}
+	}
+	if config.GlossaryTopK > 0 {
+		if glossaryResults, err = s.searchEmbeddings(userID, queryEmbedding, 0, config.GlossaryTopK, []string{"glossary"}, config.MinScore); err != nil {
+			return nil, nil, fmt.Errorf("failed to search glossary: %w", err)
+		}
 	}
 
+	reranked := false
+	if opts.Rerank && s.rerankService.IsEnabled() {
+		schemaResults = s.rerankResults(ctx, query, schemaResults, opts.RerankThreshold)
+		kpiResults = s.rerankResults(ctx, query, kpiResults, opts.RerankThreshold)
+		glossaryResults = s.rerankResults(ctx, query, glossaryResults, opts.RerankThreshold)
+		reranked = true
+	}
+
+	trimmed := trimToTokenBudget(config.MaxContextTokens, &schemaResults, &kpiResults, &glossaryResults)
+
 	// Build context object
 	context := map[string]interface{}{
-		"query":           query,
-		"data_source_id":  dataSourceID,
-		"schema_context":  s.buildSchemaContext(schemaResults.Results),
-		"kpi_context":     s.buildKPIContext(kpiResults.Results),
-		"glossary_context": s.buildGlossaryContext(glossaryResults.Results),
-		"timestamp":       ctx.Value("timestamp"),
+		"query":            query,
+		"data_source_id":   dataSourceID,
+		"schema_context":   s.buildSchemaContext(schemaResults),
+		"kpi_context":      s.buildKPIContext(kpiResults),
+		"glossary_context": s.buildGlossaryContext(glossaryResults),
+		"join_paths":       s.buildJoinPaths(dataSourceID, schemaResults),
+		"custom_functions": s.customFunctions(dataSourceID),
+		"reranked":         reranked,
+		"retrieval":        config,
+		"retrieved":        &models.RetrievedContext{Schema: schemaResults, KPIs: kpiResults, Glossary: glossaryResults},
+		"budget_trimmed":   trimmed,
+		"timestamp":        ctx.Value("timestamp"),
+	}
+
+	return context, queryEmbedding, nil
+}
+
+// trimToTokenBudget keeps the highest scoring results of the sections whose
+// estimated tokens fit the budget, in their order within each section. It
+// returns how many results were left out; a budget of 0 keeps all.
+func trimToTokenBudget(budget int, sections ...*[]models.RAGSearchResult) int {
+	if budget <= 0 {
+		return 0
+	}
+
+	type ranked struct {
+		section, index int
+		score          float64
+		tokens         int
+	}
+	var all []ranked
+	for i, section := range sections {
+		for j, result := range *section {
+			all = append(all, ranked{section: i, index: j, score: result.Score, tokens: tokenizer.Count(result.ElementName + " " + result.Content)})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	kept := make(map[[2]int]bool, len(all))
+	used := 0
+	for _, result := range all {
+		if used+result.tokens > budget {
+			continue
+		}
+		used += result.tokens
+		kept[[2]int{result.section, result.index}] = true
+	}
+
+	trimmed := 0
+	for i, section := range sections {
+		var results []models.RAGSearchResult
+		for j, result := range *section {
+			if kept[[2]int{i, j}] {
+				results = append(results, result)
+			} else {
+				trimmed++
+			}
+		}
+		*section = results
+	}
+	return trimmed
+}
+
+// rerankResults reranks candidates, falling back to the embedding order if the reranker fails
+func (s *RAGService) rerankResults(ctx context.Context, query string, results []models.RAGSearchResult, threshold float64) []models.RAGSearchResult {
+	reranked, err := s.rerankService.Rerank(ctx, query, results, threshold)
+	if err != nil {
+		logger.FromContext(ctx).Warn().Err(err).Msg("Rerank failed, using embedding scores")
+		return results
+	}
+	return reranked
+}
+
+// dialectForDataSource looks up the SQL dialect of a data source, defaulting to PostgreSQL
+func (s *RAGService) dialectForDataSource(dataSourceID uint) models.SQLDialect {
+	var dataSource models.DataSource
+	if err := s.db.Select("id", "type").First(&dataSource, dataSourceID).Error; err != nil {
+		return models.SQLDialectPostgreSQL
+	}
+	return models.DialectForDataSourceType(dataSource.Type)
+}
+
+// customFunctions loads the custom SQL functions registered for a data source
+func (s *RAGService) customFunctions(dataSourceID uint) []models.CustomSQLFunction {
+	var functions []models.CustomSQLFunction
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("name").Find(&functions).Error; err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSourceID).Msg("Failed to load custom SQL functions")
+		return nil
+	}
+	return functions
+}
+
+// formatColumnProfile renders the range and sample values stored in column
+// embedding metadata, so generated filters use literals that exist in the data
+func formatColumnProfile(metadata map[string]interface{}) string {
+	var parts []string
+
+	if stats, ok := metadata["stats"].(map[string]interface{}); ok {
+		minValue, _ := stats["min"].(string)
+		maxValue, _ := stats["max"].(string)
+		if minValue != "" || maxValue != "" {
+			parts = append(parts, fmt.Sprintf("range %s to %s", minValue, maxValue))
+		}
+	}
+
+	if values, ok := metadata["sample_values"].([]interface{}); ok && len(values) > 0 {
+		quoted := make([]string, 0, len(values))
+		for _, value := range values {
+			quoted = append(quoted, fmt.Sprintf("'%v'", value))
+		}
+		parts = append(parts, "e.g. "+strings.Join(quoted, ", "))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " - " + strings.Join(parts, "; ")
+}
+
+// buildJoinPaths loads stored foreign key relationships that touch the retrieved tables
+func (s *RAGService) buildJoinPaths(dataSourceID uint, results []models.RAGSearchResult) []models.TableRelationship {
+	var schemas []models.Schema
+	if err := s.db.Select("relationships").
+		Where("data_source_id = ? AND is_active = ?", dataSourceID, true).
+		Find(&schemas).Error; err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSourceID).Msg("Failed to load relationships")
+		return nil
+	}
+
+	var relationships []models.TableRelationship
+	for _, schema := range schemas {
+		if schema.Relationships == nil {
+			continue
+		}
+		var schemaRelationships []models.TableRelationship
+		if err := json.Unmarshal(schema.Relationships, &schemaRelationships); err != nil {
+			continue
+		}
+		relationships = append(relationships, schemaRelationships...)
 	}
 
-	return context, nil
+	return s.filterJoinPaths(relationships, results)
 }
 
-// GetAvailableSchemas returns available schemas for a data source
-func (s *RAGService) GetAvailableSchemas(dataSourceID uint) ([]map[string]interface{}, error) {
+// filterJoinPaths keeps relationships that involve at least one retrieved table.
+// When no tables could be identified all relationships are returned.
+func (s *RAGService) filterJoinPaths(relationships []models.TableRelationship, results []models.RAGSearchResult) []models.TableRelationship {
+	tables := make(map[string]bool)
+	for _, result := range results {
+		switch result.ElementType {
+		case "table":
+			tables[result.ElementName] = true
+		case "column":
+			if result.Metadata != nil {
+				if table, ok := result.Metadata["table"].(string); ok && table != "" {
+					tables[table] = true
+				}
+			}
+			if table, _ := models.SplitColumnName(result.ElementName); table != "" {
+				tables[table] = true
+			}
+		}
+	}
+
+	if len(tables) == 0 {
+		return relationships
+	}
+
+	var filtered []models.TableRelationship
+	for _, rel := range relationships {
+		if tables[rel.FromTable] || tables[rel.ToTable] {
+			filtered = append(filtered, rel)
+		}
+	}
+	return filtered
+}
+
+// GetAvailableSchemas returns available schemas for a data source of the user
+func (s *RAGService) GetAvailableSchemas(userID uint, dataSourceID uint) ([]map[string]interface{}, error) {
 	var embeddings []models.SchemaEmbedding
-	if err := s.db.Where("data_source_id = ? AND element_type = ?", dataSourceID, "table").Find(&embeddings).Error; err != nil {
+	if err := s.db.Where("user_id = ? AND data_source_id = ? AND element_type = ?", userID, dataSourceID, "table").Find(&embeddings).Error; err != nil {
 		return nil, fmt.Errorf("failed to get schemas: %w", err)
 	}
 
@@ -172,28 +415,68 @@ func (s *RAGService) GetAvailableSchemas(dataSourceID uint) ([]map[string]interf
 }
 
 // SyncSchemaEmbeddings synchronizes embeddings for a data source
-func (s *RAGService) SyncSchemaEmbeddings(ctx context.Context, dataSourceID uint) error {
+func (s *RAGService) SyncSchemaEmbeddings(ctx context.Context, dataSourceID uint) (models.SchemaSyncProgress, error) {
+	return s.SyncSchemaEmbeddingsWithProgress(ctx, dataSourceID, nil)
+}
+
+// SyncSchemaEmbeddingsWithProgress synchronizes the embeddings of a data
+// source schema by schema, reporting progress after each. Only changed tables
+// and columns are embedded again, and each schema is updated in its own
+// transaction, so a failed or interrupted sync keeps the embeddings it did not
+// reach; running it again picks up where it stopped.
+func (s *RAGService) SyncSchemaEmbeddingsWithProgress(ctx context.Context, dataSourceID uint, report func(models.SchemaSyncProgress)) (models.SchemaSyncProgress, error) {
+	var progress models.SchemaSyncProgress
+
 	// Get all schemas for the data source
 	var schemas []models.Schema
 	if err := s.db.Where("data_source_id = ? AND is_active = ?", dataSourceID, true).Find(&schemas).Error; err != nil {
-		return fmt.Errorf("failed to get schemas: %w", err)
+		return progress, fmt.Errorf("failed to get schemas: %w", err)
 	}
+	progress.SchemasTotal = len(schemas)
 
-	// Delete existing embeddings for this data source
-	if err := s.embeddingService.DeleteEmbeddings(dataSourceID, 0); err != nil {
-		return fmt.Errorf("failed to delete existing embeddings: %w", err)
+	// Remove the embeddings of tables that were dropped or deactivated
+	schemaIDs := make([]uint, len(schemas))
+	for i, schema := range schemas {
+		schemaIDs[i] = schema.ID
+	}
+	query := s.db.Where("data_source_id = ? AND element_type IN ?", dataSourceID, []string{"table", "column"})
+	if len(schemaIDs) > 0 {
+		query = query.Where("schema_id NOT IN ?", schemaIDs)
+	}
+	result := query.Delete(&models.SchemaEmbedding{})
+	if result.Error != nil {
+		return progress, fmt.Errorf("failed to remove embeddings of dropped schemas: %w", result.Error)
 	}
+	progress.ElementsRemoved = int(result.RowsAffected)
 
-	// Generate new embeddings for each schema
-	for _, schema := range schemas {
-		if err := s.embeddingService.EmbedSchema(ctx, dataSourceID, schema.ID); err != nil {
+	var firstErr error
+	for i := range schemas {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		schemaProgress, err := s.embeddingService.SyncSchema(ctx, &schemas[i])
+		if err != nil {
 			// Log error but continue with other schemas
-			fmt.Printf("Failed to embed schema %s: %v\n", schema.Name, err)
-			continue
+			logger.FromContext(ctx).Warn().Err(err).Uint("data_source_id", dataSourceID).Str("schema", schemas[i].Name).Msg("Failed to sync schema embeddings")
+			progress.SchemasFailed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			progress.SchemasSynced++
+			progress.Add(schemaProgress)
+		}
+
+		if report != nil {
+			report(progress)
 		}
 	}
 
-	return nil
+	if firstErr != nil {
+		return progress, fmt.Errorf("%d of %d schemas failed to sync: %w", progress.SchemasFailed, progress.SchemasTotal, firstErr)
+	}
+	return progress, nil
 }
 
 // Helper methods
@@ -284,19 +567,54 @@ func (s *RAGService) buildGlossaryContext(results []models.RAGSearchResult) []ma
 }
 
 // Enhanced NL2SQL prompt building
-func (s *RAGService) BuildEnhancedNL2SQLPrompt(ctx context.Context, query string, dataSourceID uint) (string, error) {
-	context, err := s.BuildNL2SQLContext(ctx, query, dataSourceID)
+func (s *RAGService) BuildEnhancedNL2SQLPrompt(ctx context.Context, userID uint, query string, dataSourceID uint) (string, error) {
+	context, err := s.BuildNL2SQLContext(ctx, userID, query, dataSourceID)
 	if err != nil {
 		return "", fmt.Errorf("failed to build context: %w", err)
 	}
 
-	var promptBuilder strings.Builder
+	prompt, _, err := s.RenderNL2SQLPrompt(query, dataSourceID, context)
+	return prompt, err
+}
 
-	// System prompt
-	promptBuilder.WriteString("You are an expert SQL generator. Convert natural language queries to SQL using the provided schema context.\n\n")
+// RenderNL2SQLPrompt renders the prompt template of the data source with a
+// context built by BuildNL2SQLContext, trimmed to the prompt token budget of
+// its retrieval config; how it fits is recorded under "prompt_budget". It
+// returns the template used, or nil for the built-in prompt.
+func (s *RAGService) RenderNL2SQLPrompt(query string, dataSourceID uint, context map[string]interface{}) (string, *models.PromptTemplate, error) {
+	template, err := s.promptTemplates.Resolve(dataSourceID)
+	if err != nil {
+		return "", nil, err
+	}
+	text := models.BuiltinPromptTemplate
+	if template != nil {
+		text = template.Template
+	}
+
+	budget := 0
+	if config, ok := context["retrieval"].(*models.RetrievalConfig); ok {
+		budget = config.MaxPromptTokens
+	}
+
+	dialect := s.dialectForDataSource(dataSourceID)
+	prompt, report := s.fitPromptToBudget(budget, context, func() string {
+		return renderPromptTemplate(text, nl2sqlPromptValues(query, dialect, context))
+	})
+	context["prompt_budget"] = report
+	return prompt, template, nil
+}
+
+// nl2sqlPromptValues renders the prompt template variables from an NL2SQL context
+func nl2sqlPromptValues(query string, dialect models.SQLDialect, context map[string]interface{}) map[string]string {
+	values := map[string]string{
+		models.PromptVariableDialect:         dialect.DisplayName(),
+		models.PromptVariableDialectGuidance: dialectPromptGuidance(dialect),
+		models.PromptVariableQuery:           query,
+	}
 
 	// Schema context
 	if schemaCtx, ok := context["schema_context"].(map[string]interface{}); ok {
+		var promptBuilder strings.Builder
 		promptBuilder.WriteString("AVAILABLE TABLES AND COLUMNS:\n")
 		if tables, ok := schemaCtx["tables"].(map[string]interface{}); ok {
 			for tableName, tableInfo := range tables {
@@ -320,6 +638,10 @@ func (s *RAGService) BuildEnhancedNL2SQLPrompt(ctx context.Context, query string
 								if colType, ok := metadata["type"].(string); ok {
 									promptBuilder.WriteString(fmt.Sprintf(" (%s)", colType))
 								}
+								if desc, ok := metadata["description"].(string); ok && desc != "" {
+									promptBuilder.WriteString(fmt.Sprintf(": %s", desc))
+								}
+								promptBuilder.WriteString(formatColumnProfile(metadata))
 							}
 							promptBuilder.WriteString("\n")
 						}
@@ -327,10 +649,12 @@ func (s *RAGService) BuildEnhancedNL2SQLPrompt(ctx context.Context, query string
 				}
 			}
 		}
+		values[models.PromptVariableSchema] = promptBuilder.String()
 	}
 
 	// KPI context
 	if kpiCtx, ok := context["kpi_context"].([]map[string]interface{}); ok && len(kpiCtx) > 0 {
+		var promptBuilder strings.Builder
 		promptBuilder.WriteString("\nRELEVANT KPIs:\n")
 		for _, kpi := range kpiCtx {
 			if name, ok := kpi["name"].(string); ok {
@@ -341,10 +665,12 @@ func (s *RAGService) BuildEnhancedNL2SQLPrompt(ctx context.Context, query string
 				promptBuilder.WriteString("\n")
 			}
 		}
+		values[models.PromptVariableKPIs] = promptBuilder.String()
 	}
 
 	// Glossary context
 	if glossaryCtx, ok := context["glossary_context"].([]map[string]interface{}); ok && len(glossaryCtx) > 0 {
+		var promptBuilder strings.Builder
 		promptBuilder.WriteString("\nBUSINESS TERMS:\n")
 		for _, term := range glossaryCtx {
 			if name, ok := term["term"].(string); ok {
@@ -355,16 +681,45 @@ func (s *RAGService) BuildEnhancedNL2SQLPrompt(ctx context.Context, query string
 				promptBuilder.WriteString("\n")
 			}
 		}
+		values[models.PromptVariableGlossary] = promptBuilder.String()
+	}
+
+	// Join paths from foreign keys
+	joinPaths, _ := context["join_paths"].([]models.TableRelationship)
+	if len(joinPaths) > 0 {
+		var promptBuilder strings.Builder
+		promptBuilder.WriteString("\nJOIN PATHS:\n")
+		for _, rel := range joinPaths {
+			promptBuilder.WriteString(fmt.Sprintf("- %s.%s = %s.%s\n", rel.FromTable, rel.FromColumn, rel.ToTable, rel.ToColumn))
+		}
+		values[models.PromptVariableJoinPaths] = promptBuilder.String()
+	}
+
+	// Custom functions registered for the data source
+	if functions, _ := context["custom_functions"].([]models.CustomSQLFunction); len(functions) > 0 {
+		values[models.PromptVariableCustomFunctions] = "\nCUSTOM FUNCTIONS:\n" + formatCustomFunctions(functions)
 	}
 
-	// Query and instructions
-	promptBuilder.WriteString(fmt.Sprintf("\nQUERY: %s\n\n", query))
-	promptBuilder.WriteString("INSTRUCTIONS:\n")
-	promptBuilder.WriteString("1. Generate a SELECT-only SQL query\n")
-	promptBuilder.WriteString("2. Use only the tables and columns provided above\n")
-	promptBuilder.WriteString("3. Include appropriate WHERE clauses, JOINs, and aggregations\n")
-	promptBuilder.WriteString("4. Add LIMIT clause for large result sets\n")
-	promptBuilder.WriteString("5. Return only the SQL query, no explanations\n")
+	// Instructions
+	var promptBuilder strings.Builder
+	if dialect == models.SQLDialectMongoDB {
+		promptBuilder.WriteString("1. Generate a read-only aggregation pipeline as JSON: {\"collection\": \"<name>\", \"pipeline\": [<stages>]}\n")
+		promptBuilder.WriteString("2. Use only the collections (tables) and fields (columns) provided above\n")
+		promptBuilder.WriteString("3. Never use $out, $merge, $function, $accumulator or $where\n")
+		promptBuilder.WriteString("4. End with a $limit stage for large result sets; when filtering, prefer the sample values shown for a field\n")
+		promptBuilder.WriteString("5. Return only the JSON, no explanations\n")
+	} else {
+		promptBuilder.WriteString(fmt.Sprintf("1. Generate a SELECT-only SQL query in %s\n", dialect.DisplayName()))
+		promptBuilder.WriteString("2. Use only the tables and columns provided above\n")
+		if len(joinPaths) > 0 {
+			promptBuilder.WriteString("3. Include appropriate WHERE clauses and aggregations; join tables only through the JOIN PATHS listed above\n")
+		} else {
+			promptBuilder.WriteString("3. Include appropriate WHERE clauses, JOINs, and aggregations\n")
+		}
+		promptBuilder.WriteString("4. Add LIMIT clause for large result sets; when filtering, prefer the sample values shown for a column\n")
+		promptBuilder.WriteString("5. Return only the SQL query, no explanations\n")
+	}
+	values[models.PromptVariableInstructions] = promptBuilder.String()
 
-	return promptBuilder.String(), nil
+	return values
 }
\ No newline at end of file
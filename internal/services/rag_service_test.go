@@ -1,10 +1,17 @@
 package services
 
 import (
+	"context"
 	"testing"
 
-	"github.com/stretchr/testify/assert"
 	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 // TestRAGService_CosineSimilarity tests the cosineSimilarity functionality
@@ -144,4 +151,168 @@ func TestRAGService_Validation(t *testing.T) {
 	zeroVec2 := []float32{0.0, 0.0, 0.0}
 	similarity2 := ragService.cosineSimilarity(zeroVec1, zeroVec2)
 	assert.Equal(t, 0.0, similarity2) // Should handle zero vectors
-}
\ No newline at end of file
+}
+
+// TestRAGService_FilterJoinPaths tests that join paths are limited to retrieved tables
+func TestRAGService_FilterJoinPaths(t *testing.T) {
+	ragService := &RAGService{}
+
+	relationships := []models.TableRelationship{
+		{FromTable: "orders", FromColumn: "customer_id", ToTable: "customers", ToColumn: "id"},
+		{FromTable: "order_items", FromColumn: "order_id", ToTable: "orders", ToColumn: "id"},
+		{FromTable: "invoices", FromColumn: "vendor_id", ToTable: "vendors", ToColumn: "id"},
+	}
+
+	results := []models.RAGSearchResult{
+		{ElementType: "table", ElementName: "customers"},
+		{ElementType: "column", ElementName: "order_items.quantity", Metadata: map[string]interface{}{"table": "order_items"}},
+	}
+
+	filtered := ragService.filterJoinPaths(relationships, results)
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, "orders", filtered[0].FromTable)
+	assert.Equal(t, "order_items", filtered[1].FromTable)
+
+	// Without any identified tables all relationships are kept
+	assert.Len(t, ragService.filterJoinPaths(relationships, nil), 3)
+}
+
+// TestBuildRelationships tests deriving relationships from foreign key columns
+func TestBuildRelationships(t *testing.T) {
+	columns := []models.Column{
+		{Name: "orders.id", PrimaryKey: true},
+		{Name: "orders.customer_id", ForeignKey: &models.ForeignKeyRef{Table: "customers", Column: "id"}},
+	}
+
+	relationships := models.BuildRelationships(columns)
+	assert.Len(t, relationships, 1)
+	assert.Equal(t, models.TableRelationship{FromTable: "orders", FromColumn: "customer_id", ToTable: "customers", ToColumn: "id"}, relationships[0])
+}
+// TestFormatColumnProfile tests rendering of column statistics and sample values in the prompt
+func TestFormatColumnProfile(t *testing.T) {
+	metadata := map[string]interface{}{
+		"type":          "string",
+		"sample_values": []interface{}{"paid", "refunded"},
+	}
+	assert.Equal(t, " - e.g. 'paid', 'refunded'", formatColumnProfile(metadata))
+
+	metadata = map[string]interface{}{
+		"type":  "date",
+		"stats": map[string]interface{}{"min": "2024-01-01", "max": "2024-12-31", "distinct_count": float64(365)},
+	}
+	assert.Equal(t, " - range 2024-01-01 to 2024-12-31", formatColumnProfile(metadata))
+
+	assert.Equal(t, "", formatColumnProfile(map[string]interface{}{"type": "integer"}))
+}
+
+func TestTrimToTokenBudget(t *testing.T) {
+	// The results count 5, 5, 5 and 6 tokens
+	schema := []models.RAGSearchResult{
+		{ElementName: "a", Content: "orders table...", Score: 0.9},
+		{ElementName: "b", Content: "order total....", Score: 0.4},
+	}
+	kpis := []models.RAGSearchResult{{ElementName: "c", Content: "revenue kpi....", Score: 0.7}}
+	glossary := []models.RAGSearchResult{{ElementName: "d", Content: "gmv glossary...", Score: 0.5}}
+
+	// No budget keeps everything
+	assert.Equal(t, 0, trimToTokenBudget(0, &schema, &kpis, &glossary))
+	assert.Len(t, schema, 2)
+
+	// The lowest scoring results across sections go first
+	trimmed := trimToTokenBudget(16, &schema, &kpis, &glossary)
+	assert.Equal(t, 1, trimmed)
+	assert.Len(t, schema, 1)
+	assert.Equal(t, "a", schema[0].ElementName)
+	assert.Len(t, kpis, 1)
+	assert.Len(t, glossary, 1)
+
+	trimmed = trimToTokenBudget(4, &schema, &kpis, &glossary)
+	assert.Equal(t, 3, trimmed)
+	assert.Empty(t, schema)
+	assert.Empty(t, kpis)
+	assert.Empty(t, glossary)
+}
+
+func TestRetrievalConfig_Defaults(t *testing.T) {
+	config := models.DefaultRetrievalConfig()
+	assert.Equal(t, 10, config.SchemaTopK)
+	assert.Equal(t, 5, config.KPITopK)
+	assert.Equal(t, 5, config.GlossaryTopK)
+	assert.Equal(t, []string{"table", "column"}, config.GetSchemaElementTypes())
+
+	// Unset element types retrieve both
+	assert.Equal(t, []string{"table", "column"}, (&models.RetrievalConfig{}).GetSchemaElementTypes())
+	assert.Equal(t, []string{"table"}, (&models.RetrievalConfig{SchemaElementTypes: models.JSON(`["table"]`)}).GetSchemaElementTypes())
+}
+
+func TestRetrievalConfigService_Resolve(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.RetrievalConfig{}, &models.DataSource{}))
+
+	acme := tenancy.WithTenant(context.Background(), 1)
+	globex := tenancy.WithTenant(context.Background(), 2)
+	warehouse := &models.DataSource{UserID: 1, Name: "warehouse", Type: models.DataSourceTypePostgreSQL}
+	crm := &models.DataSource{UserID: 1, Name: "crm", Type: models.DataSourceTypePostgreSQL}
+	require.NoError(t, db.WithContext(acme).Create(warehouse).Error)
+	require.NoError(t, db.WithContext(acme).Create(crm).Error)
+	service := NewRetrievalConfigService(db).WithContext(acme)
+
+	// Without configs the built-in defaults apply
+	config, err := service.Resolve(warehouse.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultRetrievalConfig().SchemaTopK, config.SchemaTopK)
+	assert.Zero(t, config.ID)
+
+	// The workspace default applies to data sources without a config of their own
+	_, err = service.Set(1, &models.RetrievalConfigRequest{SchemaTopK: 20, KPITopK: 3})
+	require.NoError(t, err)
+	config, err = service.Resolve(warehouse.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20, config.SchemaTopK)
+	assert.Nil(t, config.DataSourceID)
+
+	// The config of a data source wins over the workspace default
+	_, err = service.Set(1, &models.RetrievalConfigRequest{DataSourceID: &warehouse.ID, SchemaTopK: 5})
+	require.NoError(t, err)
+	config, err = service.Resolve(warehouse.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, config.SchemaTopK)
+	require.NotNil(t, config.DataSourceID)
+	assert.Equal(t, warehouse.ID, *config.DataSourceID)
+	config, err = service.Resolve(crm.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20, config.SchemaTopK, "other data sources keep the workspace default")
+
+	// Saving a scope again replaces its config rather than adding one
+	saved, err := service.Set(2, &models.RetrievalConfigRequest{SchemaTopK: 30})
+	require.NoError(t, err)
+	assert.Equal(t, 30, saved.SchemaTopK)
+	assert.Equal(t, uint(2), saved.UpdatedBy)
+	_, err = service.Set(2, &models.RetrievalConfigRequest{DataSourceID: &warehouse.ID, SchemaTopK: 6})
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Model(&models.RetrievalConfig{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	config, err = service.Resolve(crm.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, config.SchemaTopK)
+
+	// Another tenant has its own workspace default and does not see this one
+	other := NewRetrievalConfigService(db).WithContext(globex)
+	config, err = other.Resolve(crm.ID)
+	require.NoError(t, err)
+	assert.Zero(t, config.ID, "the built-in defaults apply")
+	_, err = other.Set(3, &models.RetrievalConfigRequest{SchemaTopK: 40})
+	require.NoError(t, err)
+	config, err = service.Resolve(crm.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, config.SchemaTopK)
+
+	// Deleting the data source's config falls back to the workspace default
+	require.NoError(t, service.Delete(&warehouse.ID))
+	config, err = service.Resolve(warehouse.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, config.SchemaTopK)
+}
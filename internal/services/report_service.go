@@ -0,0 +1,874 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+	"narapulse-be/internal/pkg/mailer"
+	"narapulse-be/internal/pkg/objectstore"
+	"narapulse-be/internal/pkg/pdf"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrReportNotFound is returned when a report does not exist or belongs to another user
+	ErrReportNotFound = errors.New("report not found")
+	// ErrInvalidReport is returned when a report refers to items the user cannot access
+	ErrInvalidReport = errors.New("invalid report")
+	// ErrReportRunNotFound is returned when a run does not exist or has no stored document
+	ErrReportRunNotFound = errors.New("report run not found")
+)
+
+const (
+	// reportRowLimit bounds the rows shown per table of a report
+	reportRowLimit = 25
+	// reportRunBatch bounds the reports handled by one run of the report job
+	reportRunBatch = 100
+	// reportRetryDelay is how long a report waits before another attempt after a failed run
+	reportRetryDelay = time.Hour
+	// reportRunHistory is the number of most recent runs listed for a report
+	reportRunHistory = 50
+	// reportCellWidth bounds the width of a table cell in the PDF rendering
+	reportCellWidth = 30
+)
+
+// ReportService composes reports from saved queries, KPIs and dashboards,
+// renders them to HTML or PDF and emails them to their recipients. Rendered
+// documents are kept in the object store so past runs can be downloaded.
+// RunDue is run periodically by the report job.
+type ReportService struct {
+	db            *gorm.DB
+	savedQueries  *SavedQueryService
+	kpis          *KPIService
+	dashboards    *DashboardService
+	nl2sql        *NL2SQLService
+	extracts      *ExtractService
+	store         objectstore.Store
+	sender        mailer.Sender
+	notifications *NotificationHub
+	now           func() time.Time
+}
+
+// NewReportService creates a new report service
+func NewReportService(db *gorm.DB, savedQueries *SavedQueryService, kpis *KPIService, dashboards *DashboardService, nl2sql *NL2SQLService, extracts *ExtractService, store objectstore.Store, sender mailer.Sender, notifications *NotificationHub) *ReportService {
+	return &ReportService{
+		db:            db,
+		savedQueries:  savedQueries,
+		kpis:          kpis,
+		dashboards:    dashboards,
+		nl2sql:        nl2sql,
+		extracts:      extracts,
+		store:         store,
+		sender:        sender,
+		notifications: notifications,
+		now:           time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ReportService) WithContext(ctx context.Context) *ReportService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.savedQueries = s.savedQueries.WithContext(ctx)
+	scoped.kpis = s.kpis.WithContext(ctx)
+	scoped.dashboards = s.dashboards.WithContext(ctx)
+	scoped.nl2sql = s.nl2sql.WithContext(ctx)
+	scoped.extracts = s.extracts.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the job delivering due reports; it is scheduled at the report interval
+func (s *ReportService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeReportRunDue, func(ctx context.Context, _ json.RawMessage) error {
+		result, err := s.RunDue(ctx)
+		if err != nil {
+			return err
+		}
+		if result.Due > 0 {
+			logger.FromContext(ctx).Info().Int("due", result.Due).Int("sent", result.Sent).
+				Int("failed", result.Failed).Msg("Scheduled reports processed")
+		}
+		return nil
+	})
+}
+
+// List returns the reports of a user, most recently updated first
+func (s *ReportService) List(userID uint) ([]models.ReportResponse, error) {
+	var reports []models.Report
+	if err := s.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	responses := make([]models.ReportResponse, len(reports))
+	for i := range reports {
+		responses[i] = *reportResponse(&reports[i])
+	}
+	return responses, nil
+}
+
+// Get returns a report of the user
+func (s *ReportService) Get(userID, id uint) (*models.ReportResponse, error) {
+	report, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return reportResponse(report), nil
+}
+
+// Create creates a report. Scheduled reports are first delivered one period from now.
+func (s *ReportService) Create(userID uint, req *models.ReportRequest) (*models.ReportResponse, error) {
+	report := &models.Report{UserID: userID}
+	if err := s.apply(userID, report, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(report).Error; err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+	return reportResponse(report), nil
+}
+
+// Update replaces a report. Changing the frequency reschedules the next delivery.
+func (s *ReportService) Update(userID, id uint, req *models.ReportRequest) (*models.ReportResponse, error) {
+	report, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(userID, report, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(report).Error; err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+	return reportResponse(report), nil
+}
+
+// Delete deletes a report; its run history and documents are kept
+func (s *ReportService) Delete(userID, id uint) error {
+	report, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(report).Error; err != nil {
+		return fmt.Errorf("failed to delete report: %w", err)
+	}
+	return nil
+}
+
+// Run renders a report now and, when asked, emails it to its recipients. The
+// run is returned whether or not it succeeded.
+func (s *ReportService) Run(ctx context.Context, userID, id uint, req *models.ReportRunRequest) (*models.ReportRun, error) {
+	report, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.execute(ctx, report, models.ReportRunTriggerManual, req.Send)
+	if run == nil {
+		return nil, err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn().Err(err).Uint("report_id", report.ID).Msg("Report run failed")
+	}
+	return run, nil
+}
+
+// ListRuns returns the most recent runs of a report
+func (s *ReportService) ListRuns(userID, id uint) ([]models.ReportRun, error) {
+	if _, err := s.owned(userID, id); err != nil {
+		return nil, err
+	}
+
+	var runs []models.ReportRun
+	err := s.db.Where("report_id = ?", id).Order("started_at DESC, id DESC").Limit(reportRunHistory).Find(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report runs: %w", err)
+	}
+	return runs, nil
+}
+
+// Download returns the document rendered by a run, with its content type and file name
+func (s *ReportService) Download(ctx context.Context, userID, id, runID uint) ([]byte, string, string, error) {
+	report, err := s.owned(userID, id)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var run models.ReportRun
+	if err := s.db.Where("id = ? AND report_id = ?", runID, id).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", "", ErrReportRunNotFound
+		}
+		return nil, "", "", fmt.Errorf("failed to get report run: %w", err)
+	}
+	if run.OutputKey == "" {
+		return nil, "", "", ErrReportRunNotFound
+	}
+
+	r, err := s.store.Get(ctx, run.OutputKey)
+	if err != nil {
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return nil, "", "", ErrReportRunNotFound
+		}
+		return nil, "", "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read report: %w", err)
+	}
+	return data, reportContentType(run.Format), reportFilename(report, &run), nil
+}
+
+// RunDue renders and emails every report that is due. Each report is claimed
+// by moving its next run forward before rendering, so instances running the
+// job at the same time do not deliver a report twice.
+func (s *ReportService) RunDue(ctx context.Context) (*models.ReportRunResult, error) {
+	now := s.now()
+
+	var reports []models.Report
+	err := s.db.Where("frequency <> ? AND next_run_at <= ?", models.ReportFrequencyOff, now).
+		Order("next_run_at").Limit(reportRunBatch).Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due reports: %w", err)
+	}
+
+	result := &models.ReportRunResult{Due: len(reports)}
+	for i := range reports {
+		report := &reports[i]
+		if ctx.Err() != nil {
+			break
+		}
+
+		claim := s.db.Model(&models.Report{}).
+			Where("id = ? AND next_run_at = ?", report.ID, report.NextRunAt).
+			Update("next_run_at", nextReportRun(report.Frequency, now))
+		if claim.Error != nil {
+			return result, fmt.Errorf("failed to claim report: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		var owner models.User
+		if err := s.db.First(&owner, report.UserID).Error; err != nil || !owner.IsActive {
+			result.Skipped++
+			continue
+		}
+
+		// The report runs in the tenant of its owner
+		tenantCtx := tenancy.WithTenant(ctx, report.TenantID)
+		run, err := s.WithContext(tenantCtx).execute(tenantCtx, report, models.ReportRunTriggerSchedule, true)
+		if run != nil {
+			s.notifications.Notify(ctx, report.UserID, models.NotificationReportCompleted, models.ReportCompletedNotification{
+				ReportID: report.ID,
+				RunID:    run.ID,
+				Name:     report.Name,
+				Status:   run.Status,
+				Error:    run.Error,
+			})
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("report_id", report.ID).Msg("Failed to deliver report")
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("report %d: %v", report.ID, err))
+			s.db.Model(&models.Report{}).Where("id = ?", report.ID).Update("next_run_at", now.Add(reportRetryDelay))
+			continue
+		}
+		result.Sent++
+	}
+	return result, nil
+}
+
+// execute renders a report, stores the document and optionally emails it,
+// recording the run. The run is nil only when it could not be recorded.
+func (s *ReportService) execute(ctx context.Context, report *models.Report, trigger models.ReportRunTrigger, send bool) (*models.ReportRun, error) {
+	now := s.now()
+	run := &models.ReportRun{
+		TenantID:  report.TenantID,
+		ReportID:  report.ID,
+		Trigger:   trigger,
+		Status:    models.ReportRunStatusRunning,
+		Format:    report.Format,
+		StartedAt: now,
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record report run: %w", err)
+	}
+
+	err := s.deliver(ctx, report, run, send)
+	finished := s.now()
+	run.FinishedAt = &finished
+	run.Status = models.ReportRunStatusSucceeded
+	if err != nil {
+		run.Status = models.ReportRunStatusFailed
+		run.Error = err.Error()
+	}
+	if saveErr := s.db.Save(run).Error; saveErr != nil {
+		return run, fmt.Errorf("failed to update report run: %w", saveErr)
+	}
+	if err == nil {
+		s.db.Model(&models.Report{}).Where("id = ?", report.ID).UpdateColumn("last_run_at", now)
+		report.LastRunAt = &now
+	}
+	return run, err
+}
+
+// deliver composes and renders the report, stores the document on the run and
+// emails it when send is set
+func (s *ReportService) deliver(ctx context.Context, report *models.Report, run *models.ReportRun, send bool) error {
+	sections := s.compose(ctx, report)
+
+	htmlBody, err := renderReportHTML(report, sections, run.StartedAt)
+	if err != nil {
+		return err
+	}
+	document := []byte(htmlBody)
+	if report.Format == models.ReportFormatPDF {
+		document = renderReportPDF(report, sections, run.StartedAt)
+	}
+
+	key := fmt.Sprintf("reports/%d/%d.%s", report.ID, run.ID, report.Format)
+	if err := s.store.Put(ctx, key, bytes.NewReader(document)); err != nil {
+		return fmt.Errorf("failed to store report: %w", err)
+	}
+	run.OutputKey = key
+	run.OutputSize = int64(len(document))
+
+	if !send {
+		return nil
+	}
+	recipients, err := s.recipients(report)
+	if err != nil {
+		return err
+	}
+	msg := mailer.Message{
+		To:       recipients,
+		Subject:  fmt.Sprintf("%s (%s)", report.Name, run.StartedAt.Format("Jan 2, 2006")),
+		TextBody: renderReportText(report, sections, run.StartedAt),
+	}
+	if report.Format == models.ReportFormatPDF {
+		msg.Attachments = []mailer.Attachment{{
+			Filename:    reportFilename(report, run),
+			ContentType: reportContentType(report.Format),
+			Data:        document,
+		}}
+	} else {
+		msg.HTMLBody = htmlBody
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return err
+	}
+	run.Recipients = len(recipients)
+	return nil
+}
+
+// recipients returns the recipients of a report, defaulting to its owner
+func (s *ReportService) recipients(report *models.Report) ([]string, error) {
+	var recipients []string
+	if len(report.Recipients) > 0 {
+		_ = json.Unmarshal(report.Recipients, &recipients)
+	}
+	if len(recipients) > 0 {
+		return recipients, nil
+	}
+
+	var owner models.User
+	if err := s.db.First(&owner, report.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get report owner: %w", err)
+	}
+	return []string{owner.Email}, nil
+}
+
+// compose runs the items of a report as its owner. Items that fail render
+// their error so one broken item does not hold back the rest of the report.
+func (s *ReportService) compose(ctx context.Context, report *models.Report) []models.ReportSection {
+	var items []models.ReportItem
+	if len(report.Items) > 0 {
+		_ = json.Unmarshal(report.Items, &items)
+	}
+
+	var sections []models.ReportSection
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		switch item.Type {
+		case models.ReportItemSavedQuery:
+			sections = append(sections, s.savedQuerySection(report.UserID, item))
+		case models.ReportItemKPI:
+			sections = append(sections, s.kpiSection(report.UserID, item))
+		case models.ReportItemDashboard:
+			sections = append(sections, s.dashboardSections(report.UserID, item)...)
+		}
+	}
+	return sections
+}
+
+// savedQuerySection runs a saved query on fresh data
+func (s *ReportService) savedQuerySection(userID uint, item models.ReportItem) models.ReportSection {
+	section := models.ReportSection{Title: item.Title, Type: item.Type}
+	saved, err := s.savedQueries.Get(userID, item.RefID)
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	if section.Title == "" {
+		section.Title = saved.Name
+	}
+	section.Summary = saved.NLQuery
+
+	response, err := s.savedQueries.Run(userID, item.RefID, &models.SavedQueryRunRequest{
+		Limit:    reportRowLimit + 1,
+		PageSize: reportRowLimit + 1,
+	})
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	section.Columns, section.Rows, section.Truncated = reportTable(response.Columns, response.Data)
+	return section
+}
+
+// kpiSection computes a KPI over its default range, compared with the prior period
+func (s *ReportService) kpiSection(userID uint, item models.ReportItem) models.ReportSection {
+	section := models.ReportSection{Title: item.Title, Type: item.Type}
+	kpi, err := s.kpis.Compute(userID, item.RefID, &models.KPIComputeRequest{Compare: true})
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+
+	name := kpi.DisplayName
+	if name == "" {
+		name = kpi.Name
+	}
+	if section.Title == "" {
+		section.Title = name
+	}
+	section.Summary = describeReportKPI(name, kpi)
+	if len(kpi.Current.Series) > 0 {
+		section.Columns = []string{"Period", "Value"}
+		for _, point := range kpi.Current.Series {
+			value := ""
+			if point.Value != nil {
+				value = formatAnswerValue(*point.Value, kpi.Unit)
+			}
+			section.Rows = append(section.Rows, []string{point.Period, value})
+		}
+	}
+	return section
+}
+
+// dashboardSections shows the latest stored results of the query widgets of a
+// dashboard, or the snapshots of the widgets bound to an extract
+func (s *ReportService) dashboardSections(userID uint, item models.ReportItem) []models.ReportSection {
+	dashboard, err := s.dashboards.GetDashboard(userID, item.RefID)
+	if err != nil {
+		return []models.ReportSection{{Title: item.Title, Type: item.Type, Error: err.Error()}}
+	}
+	title := item.Title
+	if title == "" {
+		title = dashboard.Name
+	}
+
+	var sections []models.ReportSection
+	for _, widget := range dashboard.Widgets {
+		if widget.QueryID == nil && widget.ExtractID == nil {
+			continue
+		}
+		section := models.ReportSection{Title: title, Type: item.Type}
+		if widget.Title != "" {
+			section.Title = title + " – " + widget.Title
+		}
+
+		if widget.ExtractID != nil {
+			result, err := s.extracts.widgetResult(*widget.ExtractID, reportRowLimit)
+			if err != nil {
+				section.Error = err.Error()
+			} else {
+				section.Summary = result.Question
+				section.Columns, section.Rows, _ = reportTable(result.Columns, result.Data)
+				section.Truncated = result.Truncated
+			}
+			sections = append(sections, section)
+			continue
+		}
+
+		// Widgets display the queries of whoever added them
+		var query models.NL2SQLQuery
+		if err := s.db.Select("id", "user_id", "nl_query").First(&query, *widget.QueryID).Error; err != nil {
+			section.Error = "query not found"
+			sections = append(sections, section)
+			continue
+		}
+		section.Summary = query.NLQuery
+		page, err := s.nl2sql.GetQueryResults(query.UserID, query.ID, &models.QueryResultPageRequest{Limit: reportRowLimit})
+		if err != nil {
+			section.Error = err.Error()
+			sections = append(sections, section)
+			continue
+		}
+		section.Columns, section.Rows, _ = reportTable(page.Columns, page.Data)
+		section.Truncated = page.HasMore
+		sections = append(sections, section)
+	}
+	if len(sections) == 0 {
+		return []models.ReportSection{{Title: title, Type: item.Type, Summary: "The dashboard has no query widgets."}}
+	}
+	return sections
+}
+
+// apply validates a request and copies it onto a report
+func (s *ReportService) apply(userID uint, report *models.Report, req *models.ReportRequest) error {
+	for _, item := range req.Items {
+		if err := s.checkItem(userID, item); err != nil {
+			return err
+		}
+	}
+
+	format := req.Format
+	if format == "" {
+		format = models.ReportFormatHTML
+	}
+	frequency := req.Frequency
+	if frequency == "" {
+		frequency = models.ReportFrequencyOff
+	}
+
+	seen := make(map[string]bool, len(req.Recipients))
+	recipients := make([]string, 0, len(req.Recipients))
+	for _, recipient := range req.Recipients {
+		recipient = strings.ToLower(strings.TrimSpace(recipient))
+		if recipient != "" && !seen[recipient] {
+			seen[recipient] = true
+			recipients = append(recipients, recipient)
+		}
+	}
+	recipientsJSON, _ := json.Marshal(recipients)
+	itemsJSON, _ := json.Marshal(req.Items)
+
+	if report.ID == 0 || report.Frequency != frequency || report.NextRunAt == nil {
+		report.NextRunAt = nextReportRun(frequency, s.now())
+	}
+	report.Name = req.Name
+	report.Description = req.Description
+	report.Format = format
+	report.Frequency = frequency
+	report.Recipients = recipientsJSON
+	report.Items = itemsJSON
+	return nil
+}
+
+// checkItem makes sure the user can run the item of a report
+func (s *ReportService) checkItem(userID uint, item models.ReportItem) error {
+	switch item.Type {
+	case models.ReportItemSavedQuery:
+		if _, err := s.savedQueries.Get(userID, item.RefID); err != nil {
+			if errors.Is(err, ErrSavedQueryNotFound) {
+				return fmt.Errorf("%w: saved query %d not found", ErrInvalidReport, item.RefID)
+			}
+			return err
+		}
+	case models.ReportItemKPI:
+		var count int64
+		err := s.db.Model(&models.KPIDefinition{}).
+			Where("id = ? AND user_id = ? AND is_active = ?", item.RefID, userID, true).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to check KPI: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: KPI %d not found", ErrInvalidReport, item.RefID)
+		}
+	case models.ReportItemDashboard:
+		if _, _, err := s.dashboards.access(userID, item.RefID); err != nil {
+			if errors.Is(err, ErrDashboardNotFound) {
+				return fmt.Errorf("%w: dashboard %d not found", ErrInvalidReport, item.RefID)
+			}
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown item type %q", ErrInvalidReport, item.Type)
+	}
+	return nil
+}
+
+func (s *ReportService) owned(userID, id uint) (*models.Report, error) {
+	var report models.Report
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	return &report, nil
+}
+
+// reportResponse converts a report to its response
+func reportResponse(report *models.Report) *models.ReportResponse {
+	response := &models.ReportResponse{
+		ID:          report.ID,
+		Name:        report.Name,
+		Description: report.Description,
+		Format:      report.Format,
+		Frequency:   report.Frequency,
+		Recipients:  []string{},
+		Items:       []models.ReportItem{},
+		NextRunAt:   report.NextRunAt,
+		LastRunAt:   report.LastRunAt,
+		CreatedAt:   report.CreatedAt,
+		UpdatedAt:   report.UpdatedAt,
+	}
+	if len(report.Recipients) > 0 {
+		_ = json.Unmarshal(report.Recipients, &response.Recipients)
+	}
+	if len(report.Items) > 0 {
+		_ = json.Unmarshal(report.Items, &response.Items)
+	}
+	return response
+}
+
+// nextReportRun returns when a report is next due after from; nil for reports
+// that only run on demand
+func nextReportRun(frequency models.ReportFrequency, from time.Time) *time.Time {
+	var next time.Time
+	switch frequency {
+	case models.ReportFrequencyDaily:
+		next = from.Add(24 * time.Hour)
+	case models.ReportFrequencyWeekly:
+		next = from.Add(7 * 24 * time.Hour)
+	default:
+		return nil
+	}
+	return &next
+}
+
+// reportContentType is the MIME type of a rendered report
+func reportContentType(format models.ReportFormat) string {
+	if format == models.ReportFormatPDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// reportFilename names the document of a run, e.g. "weekly-sales-2025-10-06.pdf"
+func reportFilename(report *models.Report, run *models.ReportRun) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(report.Name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" {
+		name = "report"
+	}
+	return fmt.Sprintf("%s-%s.%s", name, run.StartedAt.Format("2006-01-02"), run.Format)
+}
+
+// reportTable formats result rows for display, using the formats of
+// annotated columns. It reports whether rows beyond the row limit were cut.
+func reportTable(columns []models.Column, data []map[string]interface{}) ([]string, [][]string, bool) {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+
+	truncated := len(data) > reportRowLimit
+	if truncated {
+		data = data[:reportRowLimit]
+	}
+	rows := make([][]string, len(data))
+	for i, row := range data {
+		cells := make([]string, len(columns))
+		for j, col := range columns {
+			cells[j] = reportCell(col, row[col.Name])
+		}
+		rows[i] = cells
+	}
+	return names, rows, truncated
+}
+
+// reportCell formats a value of a result column
+func reportCell(col models.Column, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04")
+	case string:
+		return v
+	}
+	if number, ok := answerNumber(value); ok && col.Format != nil {
+		return formatColumnValue(col.Format, number)
+	}
+	return formatAnswerValue(value, "")
+}
+
+// describeReportKPI phrases a computed KPI like the KPI movements of digests
+func describeReportKPI(name string, kpi *models.KPIComputeResponse) string {
+	if kpi.Current.Value == nil {
+		return fmt.Sprintf("%s: no value from %s to %s", name, kpi.Current.From, kpi.Current.To)
+	}
+	movement := models.DigestKPIMovement{Name: name, Unit: kpi.Unit, Current: *kpi.Current.Value}
+	if kpi.Previous != nil && kpi.Previous.Value != nil {
+		movement.Previous = kpi.Previous.Value
+		movement.Change = kpi.Change
+		movement.ChangePercent = kpi.PercentChange
+	}
+	return fmt.Sprintf("%s, %s to %s", describeKPIMovement(movement), kpi.Current.From, kpi.Current.To)
+}
+
+// reportHTMLTemplate renders a report as a standalone HTML document
+var reportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Report.Name}}</title>
+<style>body{font-family:Helvetica,Arial,sans-serif;color:#222}table{border-collapse:collapse;margin:8px 0}th,td{border:1px solid #ccc;padding:4px 8px;text-align:left}th{background:#f3f3f3}.error{color:#b00}.note{color:#666;font-size:small}</style>
+</head><body>
+<h1>{{.Report.Name}}</h1>
+{{if .Report.Description}}<p>{{.Report.Description}}</p>{{end}}
+<p class="note">Generated {{.GeneratedAt}}</p>
+{{range .Sections}}<h2>{{.Title}}</h2>
+{{if .Error}}<p class="error">Could not be generated: {{.Error}}</p>{{else}}{{if .Summary}}<p>{{.Summary}}</p>{{end}}
+{{if .Columns}}<table><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{if .Truncated}}<p class="note">Only the first rows are shown.</p>{{end}}{{end}}{{end}}
+{{else}}<p>The report has no items.</p>
+{{end}}</body></html>
+`))
+
+// renderReportHTML renders a report as HTML
+func renderReportHTML(report *models.Report, sections []models.ReportSection, generatedAt time.Time) (string, error) {
+	var html bytes.Buffer
+	err := reportHTMLTemplate.Execute(&html, map[string]interface{}{
+		"Report":      report,
+		"Sections":    sections,
+		"GeneratedAt": generatedAt.Format("Jan 2, 2006 15:04 MST"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return html.String(), nil
+}
+
+// renderReportPDF renders a report as PDF, with tables in a fixed-width font
+func renderReportPDF(report *models.Report, sections []models.ReportSection, generatedAt time.Time) []byte {
+	doc := pdf.New()
+	doc.Heading(report.Name)
+	if report.Description != "" {
+		doc.Text(report.Description)
+	}
+	doc.Text("Generated " + generatedAt.Format("Jan 2, 2006 15:04 MST"))
+	if len(sections) == 0 {
+		doc.Text("The report has no items.")
+	}
+	for _, section := range sections {
+		doc.Heading(section.Title)
+		if section.Error != "" {
+			doc.Text("Could not be generated: " + section.Error)
+			continue
+		}
+		if section.Summary != "" {
+			doc.Text(section.Summary)
+		}
+		for _, line := range textTable(section.Columns, section.Rows, reportCellWidth) {
+			doc.Mono(line)
+		}
+		if section.Truncated {
+			doc.Text("Only the first rows are shown.")
+		}
+	}
+	return doc.Bytes()
+}
+
+// renderReportText renders a report as the plain text body of its email
+func renderReportText(report *models.Report, sections []models.ReportSection, generatedAt time.Time) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\nGenerated %s\n", report.Name, generatedAt.Format("Jan 2, 2006 15:04 MST"))
+	if report.Description != "" {
+		text.WriteString(report.Description + "\n")
+	}
+	if report.Format == models.ReportFormatPDF {
+		text.WriteString("The full report is attached as a PDF.\n")
+	}
+	for _, section := range sections {
+		text.WriteString("\n" + section.Title + "\n")
+		if section.Error != "" {
+			text.WriteString("Could not be generated: " + section.Error + "\n")
+			continue
+		}
+		if section.Summary != "" {
+			text.WriteString(section.Summary + "\n")
+		}
+		for _, line := range textTable(section.Columns, section.Rows, reportCellWidth) {
+			text.WriteString(line + "\n")
+		}
+	}
+	return text.String()
+}
+
+// textTable lays out a table in fixed-width columns, cutting cells longer than width
+func textTable(columns []string, rows [][]string, width int) []string {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	cut := func(cell string) string {
+		if runes := []rune(cell); len(runes) > width {
+			return string(runes[:width-1]) + "…"
+		}
+		return cell
+	}
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = len([]rune(cut(col)))
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && len([]rune(cut(cell))) > widths[i] {
+				widths[i] = len([]rune(cut(cell)))
+			}
+		}
+	}
+
+	line := func(cells []string) string {
+		parts := make([]string, len(widths))
+		for i := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cut(cells[i])
+			}
+			parts[i] = cell + strings.Repeat(" ", widths[i]-len([]rune(cell)))
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+	separators := make([]string, len(widths))
+	for i, w := range widths {
+		separators[i] = strings.Repeat("-", w)
+	}
+
+	lines := []string{line(columns), line(separators)}
+	for _, row := range rows {
+		lines = append(lines, line(row))
+	}
+	return lines
+}
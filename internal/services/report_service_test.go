@@ -0,0 +1,110 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextReportRun(t *testing.T) {
+	from := time.Date(2025, 10, 6, 8, 0, 0, 0, time.UTC)
+
+	require.NotNil(t, nextReportRun(models.ReportFrequencyDaily, from))
+	assert.Equal(t, from.AddDate(0, 0, 1), *nextReportRun(models.ReportFrequencyDaily, from))
+	assert.Equal(t, from.AddDate(0, 0, 7), *nextReportRun(models.ReportFrequencyWeekly, from))
+	assert.Nil(t, nextReportRun(models.ReportFrequencyOff, from))
+}
+
+func TestReportTableFormatsAndTruncates(t *testing.T) {
+	columns := []models.Column{
+		{Name: "day"},
+		{Name: "revenue", Format: &models.ColumnFormat{Kind: models.ColumnFormatCurrency, Currency: "IDR", Symbol: "Rp", Divisor: 1e6, Suffix: "M", Decimals: 1}},
+		{Name: "orders"},
+	}
+	var data []map[string]interface{}
+	for i := 0; i < reportRowLimit+5; i++ {
+		data = append(data, map[string]interface{}{
+			"day":     time.Date(2025, 10, 1+i%28, 0, 0, 0, 0, time.UTC),
+			"revenue": 1260000.0,
+			"orders":  int64(1200),
+		})
+	}
+	data[1]["orders"] = nil
+
+	names, rows, truncated := reportTable(columns, data)
+	assert.Equal(t, []string{"day", "revenue", "orders"}, names)
+	assert.True(t, truncated)
+	require.Len(t, rows, reportRowLimit)
+	assert.Equal(t, []string{"2025-10-01", "Rp 1.3M", "1,200"}, rows[0])
+	assert.Equal(t, "", rows[1][2])
+}
+
+func TestTextTableAlignsColumns(t *testing.T) {
+	lines := textTable([]string{"region", "revenue"}, [][]string{
+		{"Jakarta", "Rp 1.3M"},
+		{"A very long region name that does not fit", "Rp 900K"},
+	}, 12)
+
+	assert.Equal(t, []string{
+		"region        revenue",
+		"------------  -------",
+		"Jakarta       Rp 1.3M",
+		"A very long…  Rp 900K",
+	}, lines)
+	assert.Nil(t, textTable(nil, nil, 12))
+}
+
+func TestDescribeReportKPI(t *testing.T) {
+	current, previous, change, percent := 1200.0, 1000.0, 200.0, 20.0
+	kpi := &models.KPIComputeResponse{
+		Name:          "revenue",
+		Unit:          "USD",
+		Current:       models.KPIPeriodValue{From: "2025-09-07", To: "2025-10-06", Value: &current},
+		Previous:      &models.KPIPeriodValue{From: "2025-08-08", To: "2025-09-06", Value: &previous},
+		Change:        &change,
+		PercentChange: &percent,
+	}
+
+	summary := describeReportKPI("Revenue", kpi)
+	assert.True(t, strings.HasPrefix(summary, "Revenue: "), summary)
+	assert.Contains(t, summary, "up 20% from")
+	assert.True(t, strings.HasSuffix(summary, ", 2025-09-07 to 2025-10-06"), summary)
+
+	kpi.Current.Value = nil
+	assert.Equal(t, "Revenue: no value from 2025-09-07 to 2025-10-06", describeReportKPI("Revenue", kpi))
+}
+
+func TestRenderReport(t *testing.T) {
+	report := &models.Report{Name: "Weekly <sales>", Format: models.ReportFormatPDF}
+	sections := []models.ReportSection{
+		{Title: "Revenue by region", Summary: "revenue by region", Columns: []string{"region", "revenue"}, Rows: [][]string{{"Jakarta", "Rp 1.3M"}}},
+		{Title: "Churn", Error: "KPI not found"},
+	}
+	generatedAt := time.Date(2025, 10, 6, 8, 0, 0, 0, time.UTC)
+
+	html, err := renderReportHTML(report, sections, generatedAt)
+	require.NoError(t, err)
+	assert.Contains(t, html, "<h1>Weekly &lt;sales&gt;</h1>")
+	assert.Contains(t, html, "<td>Rp 1.3M</td>")
+	assert.Contains(t, html, "Could not be generated: KPI not found")
+
+	text := renderReportText(report, sections, generatedAt)
+	assert.Contains(t, text, "attached as a PDF")
+	assert.Contains(t, text, "Jakarta  Rp 1.3M")
+
+	document := renderReportPDF(report, sections, generatedAt)
+	assert.True(t, strings.HasPrefix(string(document), "%PDF-"))
+	assert.Contains(t, string(document), "(Jakarta  Rp 1.3M) Tj")
+}
+
+func TestReportFilename(t *testing.T) {
+	run := &models.ReportRun{Format: models.ReportFormatPDF, StartedAt: time.Date(2025, 10, 6, 8, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, "weekly-sales-q4-2025-10-06.pdf", reportFilename(&models.Report{Name: "Weekly Sales (Q4)"}, run))
+	assert.Equal(t, "report-2025-10-06.pdf", reportFilename(&models.Report{Name: "日報"}, run))
+}
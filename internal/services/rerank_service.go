@@ -0,0 +1,164 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// RerankService re-scores RAG candidates against the NL query using a
+// cross-encoder style rerank API (Cohere/Jina compatible request format)
+type RerankService struct {
+	apiURL           string
+	apiKey           string
+	model            string
+	defaultThreshold float64
+	client           *http.Client
+}
+
+// NewRerankService creates a new rerank service
+func NewRerankService(apiURL, apiKey, model string, defaultThreshold float64) *RerankService {
+	return &RerankService{
+		apiURL:           apiURL,
+		apiKey:           apiKey,
+		model:            model,
+		defaultThreshold: defaultThreshold,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Rerank API structures
+type RerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+type RerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// IsEnabled reports whether a rerank endpoint has been configured
+func (s *RerankService) IsEnabled() bool {
+	return s != nil && s.apiURL != ""
+}
+
+// Rerank scores candidates against the query and keeps only the ones whose
+// relevance passes the threshold, ordered by relevance (descending).
+// A threshold <= 0 falls back to the service default.
+func (s *RerankService) Rerank(ctx context.Context, query string, candidates []models.RAGSearchResult, threshold float64) ([]models.RAGSearchResult, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+	if !s.IsEnabled() {
+		return nil, fmt.Errorf("reranker is not configured")
+	}
+	if threshold <= 0 {
+		threshold = s.defaultThreshold
+	}
+
+	documents := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		documents[i] = s.buildDocument(candidate)
+	}
+
+	scores, err := s.score(ctx, query, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	var reranked []models.RAGSearchResult
+	for i, candidate := range candidates {
+		score, ok := scores[i]
+		if !ok || score < threshold {
+			continue
+		}
+		candidate.RerankScore = score
+		reranked = append(reranked, candidate)
+	}
+
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].RerankScore > reranked[j].RerankScore
+	})
+
+	return reranked, nil
+}
+
+// score calls the rerank API and returns relevance scores keyed by document index
+func (s *RerankService) score(ctx context.Context, query string, documents []string) (map[int]float64, error) {
+	reqBody := RerankRequest{
+		Model:     s.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var rerankResp RerankResponse
+	if err := json.Unmarshal(body, &rerankResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	scores := make(map[int]float64, len(rerankResp.Results))
+	for _, result := range rerankResp.Results {
+		if result.Index >= 0 && result.Index < len(documents) {
+			scores[result.Index] = result.RelevanceScore
+		}
+	}
+
+	return scores, nil
+}
+
+// buildDocument renders a candidate as plain text for the reranker
+func (s *RerankService) buildDocument(candidate models.RAGSearchResult) string {
+	var doc strings.Builder
+	doc.WriteString(fmt.Sprintf("%s: %s", candidate.ElementType, candidate.ElementName))
+	if candidate.Content != "" {
+		doc.WriteString("\n")
+		doc.WriteString(candidate.Content)
+	}
+	return doc.String()
+}
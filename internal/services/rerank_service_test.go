@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+// TestRerankService_IsEnabled tests that the reranker is disabled without an API URL
+func TestRerankService_IsEnabled(t *testing.T) {
+	var nilService *RerankService
+	assert.False(t, nilService.IsEnabled())
+	assert.False(t, NewRerankService("", "", "", 0.3).IsEnabled())
+	assert.True(t, NewRerankService("http://localhost/rerank", "", "", 0.3).IsEnabled())
+}
+
+// TestRerankService_Rerank tests threshold filtering and ordering of reranked results
+func TestRerankService_Rerank(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var req RerankRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Len(t, req.Documents, 3)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[{"index":2,"relevance_score":0.91},{"index":0,"relevance_score":0.55},{"index":1,"relevance_score":0.1}]}`))
+	}))
+	defer server.Close()
+
+	rerankService := NewRerankService(server.URL, "test-key", "test-model", 0.3)
+
+	candidates := []models.RAGSearchResult{
+		{ElementType: "table", ElementName: "sales", Content: "Sales transaction table", Score: 0.9},
+		{ElementType: "table", ElementName: "users", Content: "Registered users", Score: 0.8},
+		{ElementType: "column", ElementName: "amount", Content: "Sales amount column", Score: 0.7},
+	}
+
+	results, err := rerankService.Rerank(context.Background(), "total sales", candidates, 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "amount", results[0].ElementName)
+	assert.Equal(t, 0.91, results[0].RerankScore)
+	assert.Equal(t, "sales", results[1].ElementName)
+
+	// A stricter threshold drops more candidates
+	results, err = rerankService.Rerank(context.Background(), "total sales", candidates, 0.9)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
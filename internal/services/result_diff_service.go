@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrResultDiffInvalid is returned when a diff request names no results or unknown key columns
+	ErrResultDiffInvalid = errors.New("invalid result diff request")
+	// ErrResultDiffNotEnoughRuns is returned when a saved query has not been run twice
+	ErrResultDiffNotEnoughRuns = errors.New("saved query needs two runs with results to diff")
+)
+
+const (
+	// resultDiffMaxRows bounds the rows of each diffed result, which are held in memory
+	resultDiffMaxRows = 50000
+	// defaultResultDiffChanges is the number of differing rows listed by default
+	defaultResultDiffChanges = 100
+)
+
+// ResultDiffService compares two stored query results row by row
+type ResultDiffService struct {
+	db            *gorm.DB
+	nl2sqlService *NL2SQLService
+	savedQueries  *SavedQueryService
+}
+
+// NewResultDiffService creates a new result diff service
+func NewResultDiffService(db *gorm.DB, nl2sqlService *NL2SQLService, savedQueries *SavedQueryService) *ResultDiffService {
+	return &ResultDiffService{
+		db:            db,
+		nl2sqlService: nl2sqlService,
+		savedQueries:  savedQueries,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ResultDiffService) WithContext(ctx context.Context) *ResultDiffService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sqlService = s.nl2sqlService.WithContext(ctx)
+	scoped.savedQueries = s.savedQueries.WithContext(ctx)
+	return &scoped
+}
+
+// diffedResult is a stored result loaded for diffing
+type diffedResult struct {
+	query   *models.NL2SQLQuery
+	result  *models.QueryResult
+	columns []models.Column
+	rows    []map[string]interface{}
+}
+
+// Diff compares two results of the user's queries, e.g. yesterday's and
+// today's run of a saved query. PII columns are diffed on their real values
+// and masked in the response unless the request may unmask them.
+func (s *ResultDiffService) Diff(userID uint, req *models.ResultDiffRequest) (*models.ResultDiff, error) {
+	base, compare, err := s.resolve(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	maxChanges := req.MaxChanges
+	if maxChanges <= 0 {
+		maxChanges = defaultResultDiffChanges
+	}
+	diff, err := diffResultRows(base.columns, compare.columns, base.rows, compare.rows, req.KeyColumns, maxChanges)
+	if err != nil {
+		return nil, err
+	}
+	diff.Base = diffSide(base)
+	diff.Compare = diffSide(compare)
+	diff.RowCountDelta = compare.result.RowCount - base.result.RowCount
+
+	if !req.UnmaskPII {
+		masker := s.nl2sqlService.piiMasker
+		piiColumns := mergeSortedColumns(
+			masker.PIIColumns(base.query.DataSourceID, base.columns, base.rows),
+			masker.PIIColumns(compare.query.DataSourceID, compare.columns, compare.rows),
+		)
+		maskRowDiffs(masker, diff.Rows, piiColumns)
+		diff.MaskedColumns = piiColumns
+	}
+	return diff, nil
+}
+
+// resolve loads the base and compared results the request names
+func (s *ResultDiffService) resolve(userID uint, req *models.ResultDiffRequest) (*diffedResult, *diffedResult, error) {
+	if req.SavedQueryID != 0 {
+		return s.resolveSavedQueryRuns(userID, req.SavedQueryID)
+	}
+	if req.BaseQueryID == 0 || req.CompareQueryID == 0 {
+		return nil, nil, fmt.Errorf("%w: base_query_id and compare_query_id, or saved_query_id, are required", ErrResultDiffInvalid)
+	}
+
+	baseResultID, compareResultID := req.BaseResultID, req.CompareResultID
+	// Two executions of one query: its previous result against its latest
+	if req.BaseQueryID == req.CompareQueryID && baseResultID == 0 && compareResultID == 0 {
+		var results []models.QueryResult
+		if err := s.db.Select("id").Where("query_id = ?", req.BaseQueryID).Order("id DESC").Limit(2).Find(&results).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to get query results: %w", err)
+		}
+		if len(results) < 2 {
+			return nil, nil, fmt.Errorf("%w: query %d has fewer than two results", ErrResultDiffInvalid, req.BaseQueryID)
+		}
+		baseResultID, compareResultID = results[1].ID, results[0].ID
+	}
+
+	base, err := s.load(userID, req.BaseQueryID, baseResultID)
+	if err != nil {
+		return nil, nil, err
+	}
+	compare, err := s.load(userID, req.CompareQueryID, compareResultID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return base, compare, nil
+}
+
+// resolveSavedQueryRuns loads the latest results of the user's two latest
+// runs of a saved query
+func (s *ResultDiffService) resolveSavedQueryRuns(userID, savedQueryID uint) (*diffedResult, *diffedResult, error) {
+	if _, err := s.savedQueries.visible(userID, savedQueryID); err != nil {
+		return nil, nil, err
+	}
+
+	var runs []models.NL2SQLQuery
+	withResults := s.db.Model(&models.QueryResult{}).Select("query_id")
+	if err := s.db.Select("id").Where("saved_query_id = ? AND user_id = ?", savedQueryID, userID).
+		Where("id IN (?)", withResults).
+		Order("id DESC").Limit(2).Find(&runs).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get saved query runs: %w", err)
+	}
+	if len(runs) < 2 {
+		return nil, nil, ErrResultDiffNotEnoughRuns
+	}
+
+	base, err := s.load(userID, runs[1].ID, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	compare, err := s.load(userID, runs[0].ID, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return base, compare, nil
+}
+
+// load reads every row of a result of one of the user's queries, its latest
+// result when resultID is 0
+func (s *ResultDiffService) load(userID, queryID, resultID uint) (*diffedResult, error) {
+	query, err := s.nl2sqlService.GetQueryDetails(userID, queryID)
+	if err != nil {
+		if err.Error() == "query not found" {
+			return nil, ErrQueryNotFound
+		}
+		return nil, err
+	}
+
+	result, columns, rows, err := s.nl2sqlService.resultService.ReadAll(query.ID, resultID, resultDiffMaxRows)
+	if err != nil {
+		return nil, err
+	}
+	return &diffedResult{query: query, result: result, columns: columns, rows: rows}, nil
+}
+
+// diffSide describes a diffed result
+func diffSide(side *diffedResult) models.ResultDiffSide {
+	return models.ResultDiffSide{
+		QueryID:    side.query.ID,
+		ResultID:   side.result.ID,
+		SQLVersion: side.result.SQLVersion,
+		RowCount:   side.result.RowCount,
+		ExecutedAt: side.result.CreatedAt,
+	}
+}
+
+// diffResultRows matches the rows of two results on the key columns, or by
+// position without any, and reports the rows added, removed and changed, up
+// to maxChanges of them, with the column changes and the totals of numeric
+// columns. Rows with the same key are matched in order.
+func diffResultRows(baseColumns, compareColumns []models.Column, baseRows, compareRows []map[string]interface{}, keyColumns []string, maxChanges int) (*models.ResultDiff, error) {
+	diff := &models.ResultDiff{
+		Columns:    diffColumns(baseColumns, compareColumns),
+		Aggregates: []models.AggregateDelta{},
+		Rows:       []models.RowDiff{},
+	}
+
+	// Columns in both results, in the compared result's order
+	inBase := make(map[string]bool, len(baseColumns))
+	for _, column := range baseColumns {
+		inBase[column.Name] = true
+	}
+	var shared []string
+	for _, column := range compareColumns {
+		if inBase[column.Name] {
+			shared = append(shared, column.Name)
+		}
+	}
+	numeric := make(map[string]bool, len(shared))
+	for _, column := range shared {
+		numeric[column] = numericInRows(column, baseRows, compareRows)
+	}
+
+	if len(keyColumns) == 0 {
+		for _, column := range shared {
+			if !numeric[column] {
+				keyColumns = append(keyColumns, column)
+			}
+		}
+	} else {
+		sharedSet := make(map[string]bool, len(shared))
+		for _, column := range shared {
+			sharedSet[column] = true
+		}
+		for _, column := range keyColumns {
+			if !sharedSet[column] {
+				return nil, fmt.Errorf("%w: key column %s is not in both results", ErrResultDiffInvalid, column)
+			}
+		}
+	}
+	diff.KeyColumns = append([]string{}, keyColumns...)
+	isKey := make(map[string]bool, len(keyColumns))
+	for _, column := range keyColumns {
+		isKey[column] = true
+	}
+
+	record := func(row models.RowDiff) {
+		if len(diff.Rows) < maxChanges {
+			diff.Rows = append(diff.Rows, row)
+		} else {
+			diff.Truncated = true
+		}
+	}
+
+	// Base rows waiting for a match, by key
+	pending := make(map[string][]int)
+	for i, row := range baseRows {
+		key := resultRowKey(row, keyColumns, i)
+		pending[key] = append(pending[key], i)
+	}
+	matched := make([]bool, len(baseRows))
+
+	for i, row := range compareRows {
+		key := resultRowKey(row, keyColumns, i)
+		candidates := pending[key]
+		if len(candidates) == 0 {
+			diff.RowsAdded++
+			record(models.RowDiff{Status: models.RowDiffAdded, Key: rowKeyValues(row, keyColumns), Index: i, Compare: row})
+			continue
+		}
+		baseIndex := candidates[0]
+		pending[key] = candidates[1:]
+		matched[baseIndex] = true
+
+		var changes []models.CellChange
+		for _, column := range shared {
+			if isKey[column] {
+				continue
+			}
+			if change, ok := diffCell(column, baseRows[baseIndex][column], row[column]); ok {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			diff.RowsUnchanged++
+			continue
+		}
+		diff.RowsChanged++
+		record(models.RowDiff{Status: models.RowDiffChanged, Key: rowKeyValues(row, keyColumns), Index: i, Base: baseRows[baseIndex], Compare: row, Changes: changes})
+	}
+	for i, row := range baseRows {
+		if !matched[i] {
+			diff.RowsRemoved++
+			record(models.RowDiff{Status: models.RowDiffRemoved, Key: rowKeyValues(row, keyColumns), Index: i, Base: row})
+		}
+	}
+
+	for _, column := range shared {
+		if !numeric[column] || isKey[column] {
+			continue
+		}
+		aggregate := models.AggregateDelta{Column: column, Base: sumColumn(column, baseRows), Compare: sumColumn(column, compareRows)}
+		aggregate.Delta = aggregate.Compare - aggregate.Base
+		aggregate.PctChange = percentChange(aggregate.Base, aggregate.Compare)
+		diff.Aggregates = append(diff.Aggregates, aggregate)
+	}
+	return diff, nil
+}
+
+// diffColumns lists the columns added, removed and retyped between results
+func diffColumns(baseColumns, compareColumns []models.Column) models.ResultDiffColumns {
+	columns := models.ResultDiffColumns{Added: []string{}, Removed: []string{}, TypeChanged: []models.ColumnTypeChange{}}
+
+	baseTypes := make(map[string]string, len(baseColumns))
+	for _, column := range baseColumns {
+		baseTypes[column.Name] = column.Type
+	}
+	compareTypes := make(map[string]string, len(compareColumns))
+	for _, column := range compareColumns {
+		compareTypes[column.Name] = column.Type
+		baseType, ok := baseTypes[column.Name]
+		switch {
+		case !ok:
+			columns.Added = append(columns.Added, column.Name)
+		case baseType != column.Type:
+			columns.TypeChanged = append(columns.TypeChanged, models.ColumnTypeChange{Column: column.Name, From: baseType, To: column.Type})
+		}
+	}
+	for _, column := range baseColumns {
+		if _, ok := compareTypes[column.Name]; !ok {
+			columns.Removed = append(columns.Removed, column.Name)
+		}
+	}
+	return columns
+}
+
+// diffCell compares a value of a matched row. Numbers compare by value, so
+// 10 and "10.0" are the same, and numeric changes carry their delta.
+func diffCell(column string, base, compare interface{}) (models.CellChange, bool) {
+	baseNumber, baseIsNumber := answerNumber(base)
+	compareNumber, compareIsNumber := answerNumber(compare)
+	if baseIsNumber && compareIsNumber {
+		if baseNumber == compareNumber {
+			return models.CellChange{}, false
+		}
+		delta := compareNumber - baseNumber
+		return models.CellChange{Column: column, Base: base, Compare: compare, Delta: &delta, PctChange: percentChange(baseNumber, compareNumber)}, true
+	}
+	if reflect.DeepEqual(base, compare) {
+		return models.CellChange{}, false
+	}
+	return models.CellChange{Column: column, Base: base, Compare: compare}, true
+}
+
+// resultRowKey identifies a row by its key column values, or by its
+// position without key columns
+func resultRowKey(row map[string]interface{}, keyColumns []string, index int) string {
+	if len(keyColumns) == 0 {
+		return fmt.Sprintf("#%d", index)
+	}
+	values := make([]interface{}, len(keyColumns))
+	for i, column := range keyColumns {
+		values[i] = row[column]
+		// Numeric keys match whatever type the driver returned them as
+		if number, ok := answerNumber(row[column]); ok {
+			values[i] = number
+		}
+	}
+	key, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values...)
+	}
+	return string(key)
+}
+
+// rowKeyValues returns the key column values of a row
+func rowKeyValues(row map[string]interface{}, keyColumns []string) map[string]interface{} {
+	if len(keyColumns) == 0 {
+		return nil
+	}
+	key := make(map[string]interface{}, len(keyColumns))
+	for _, column := range keyColumns {
+		key[column] = row[column]
+	}
+	return key
+}
+
+// numericInRows reports whether the column has a number in some row and
+// nothing but numbers and nulls in all of them
+func numericInRows(column string, rowSets ...[]map[string]interface{}) bool {
+	found := false
+	for _, rows := range rowSets {
+		for _, row := range rows {
+			value := row[column]
+			if value == nil {
+				continue
+			}
+			if _, ok := answerNumber(value); !ok {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// sumColumn adds up the numeric values of a column
+func sumColumn(column string, rows []map[string]interface{}) float64 {
+	total := 0.0
+	for _, row := range rows {
+		if number, ok := answerNumber(row[column]); ok {
+			total += number
+		}
+	}
+	return total
+}
+
+// percentChange returns the change from base to compare in percent of base,
+// or nil when base is zero
+func percentChange(base, compare float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	change := (compare - base) / math.Abs(base) * 100
+	return &change
+}
+
+// mergeSortedColumns returns the sorted union of column names
+func mergeSortedColumns(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, column := range append(append([]string{}, a...), b...) {
+		if !seen[column] {
+			seen[column] = true
+			merged = append(merged, column)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// maskRowDiffs masks the PII columns in the rows, keys and cell changes of a diff
+func maskRowDiffs(masker *PIIMaskingService, rows []models.RowDiff, piiColumns []string) {
+	if len(piiColumns) == 0 {
+		return
+	}
+	pii := make(map[string]bool, len(piiColumns))
+	for _, column := range piiColumns {
+		pii[strings.ToLower(column)] = true
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		for _, values := range []*map[string]interface{}{&row.Key, &row.Base, &row.Compare} {
+			if *values != nil {
+				*values = masker.maskColumns([]map[string]interface{}{*values}, piiColumns)[0]
+			}
+		}
+		for j := range row.Changes {
+			change := &row.Changes[j]
+			if !pii[strings.ToLower(change.Column)] {
+				continue
+			}
+			if change.Base != nil {
+				change.Base = masker.maskValue(change.Base)
+			}
+			if change.Compare != nil {
+				change.Compare = masker.maskValue(change.Compare)
+			}
+			change.Delta, change.PctChange = nil, nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffResultRows(t *testing.T) {
+	baseColumns := []models.Column{{Name: "region", Type: "string"}, {Name: "revenue", Type: "float"}, {Name: "orders", Type: "integer"}}
+	compareColumns := []models.Column{{Name: "region", Type: "string"}, {Name: "revenue", Type: "float"}, {Name: "margin", Type: "float"}}
+	baseRows := []map[string]interface{}{
+		{"region": "north", "revenue": 100.0, "orders": 4.0},
+		{"region": "south", "revenue": 50.0, "orders": 2.0},
+		{"region": "west", "revenue": 20.0, "orders": 1.0},
+	}
+	compareRows := []map[string]interface{}{
+		{"region": "north", "revenue": "100", "margin": 0.2},
+		{"region": "south", "revenue": 75.0, "margin": 0.1},
+		{"region": "east", "revenue": 10.0, "margin": 0.3},
+	}
+
+	diff, err := diffResultRows(baseColumns, compareColumns, baseRows, compareRows, nil, 10)
+	require.NoError(t, err)
+
+	// Shared non-numeric columns are the key
+	assert.Equal(t, []string{"region"}, diff.KeyColumns)
+	assert.Equal(t, []string{"margin"}, diff.Columns.Added)
+	assert.Equal(t, []string{"orders"}, diff.Columns.Removed)
+	assert.Equal(t, 1, diff.RowsAdded)
+	assert.Equal(t, 1, diff.RowsRemoved)
+	assert.Equal(t, 1, diff.RowsChanged)
+	// Numbers compare by value whatever their type
+	assert.Equal(t, 1, diff.RowsUnchanged)
+
+	require.Len(t, diff.Rows, 3)
+	changed := diff.Rows[0]
+	assert.Equal(t, models.RowDiffChanged, changed.Status)
+	assert.Equal(t, map[string]interface{}{"region": "south"}, changed.Key)
+	require.Len(t, changed.Changes, 1)
+	assert.Equal(t, "revenue", changed.Changes[0].Column)
+	assert.Equal(t, 25.0, *changed.Changes[0].Delta)
+	assert.Equal(t, 50.0, *changed.Changes[0].PctChange)
+	assert.Equal(t, models.RowDiffAdded, diff.Rows[1].Status)
+	assert.Equal(t, "east", diff.Rows[1].Key["region"])
+	assert.Equal(t, models.RowDiffRemoved, diff.Rows[2].Status)
+	assert.Equal(t, 2, diff.Rows[2].Index)
+
+	require.Len(t, diff.Aggregates, 1)
+	assert.Equal(t, "revenue", diff.Aggregates[0].Column)
+	assert.Equal(t, 170.0, diff.Aggregates[0].Base)
+	assert.Equal(t, 185.0, diff.Aggregates[0].Compare)
+	assert.Equal(t, 15.0, diff.Aggregates[0].Delta)
+
+	// Only max changes rows are listed
+	diff, err = diffResultRows(baseColumns, compareColumns, baseRows, compareRows, nil, 2)
+	require.NoError(t, err)
+	assert.Len(t, diff.Rows, 2)
+	assert.True(t, diff.Truncated)
+	assert.Equal(t, 1, diff.RowsRemoved)
+
+	_, err = diffResultRows(baseColumns, compareColumns, baseRows, compareRows, []string{"orders"}, 10)
+	assert.ErrorIs(t, err, ErrResultDiffInvalid)
+}
+
+func TestDiffResultRows_ByPosition(t *testing.T) {
+	columns := []models.Column{{Name: "total", Type: "float"}}
+	diff, err := diffResultRows(columns, columns,
+		[]map[string]interface{}{{"total": 10.0}},
+		[]map[string]interface{}{{"total": 12.0}, {"total": 1.0}}, nil, 10)
+	require.NoError(t, err)
+
+	assert.Empty(t, diff.KeyColumns)
+	assert.Equal(t, 1, diff.RowsChanged)
+	assert.Equal(t, 1, diff.RowsAdded)
+	assert.Nil(t, diff.Rows[0].Key)
+	assert.Equal(t, 2.0, *diff.Rows[0].Changes[0].Delta)
+	assert.Equal(t, 13.0, diff.Aggregates[0].Compare)
+}
+
+func TestMaskRowDiffs(t *testing.T) {
+	masker := &PIIMaskingService{mode: PIIMaskModeHash, secret: []byte("secret")}
+	rows := []models.RowDiff{{
+		Status:  models.RowDiffChanged,
+		Key:     map[string]interface{}{"email": "a@example.com"},
+		Base:    map[string]interface{}{"email": "a@example.com", "phone": "+62 811-111"},
+		Compare: map[string]interface{}{"email": "a@example.com", "phone": "+62 822-222"},
+		Changes: []models.CellChange{{Column: "phone", Base: "+62 811-111", Compare: "+62 822-222"}},
+	}}
+
+	maskRowDiffs(masker, rows, []string{"email", "phone"})
+	assert.NotEqual(t, "a@example.com", rows[0].Key["email"])
+	assert.Equal(t, rows[0].Key["email"], rows[0].Base["email"])
+	assert.NotEqual(t, "+62 811-111", rows[0].Changes[0].Base)
+	assert.NotEqual(t, rows[0].Changes[0].Base, rows[0].Changes[0].Compare)
+}
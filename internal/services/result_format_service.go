@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnsupportedCurrency is returned when results are converted to a currency without a rate
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// currencySymbols are the symbols of the currencies results are commonly in
+var currencySymbols = map[string]string{
+	"IDR": "Rp",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"SGD": "S$",
+	"MYR": "RM",
+	"AUD": "A$",
+	"INR": "₹",
+}
+
+// zeroDecimalCurrencies are shown without cents
+var zeroDecimalCurrencies = map[string]bool{"IDR": true, "JPY": true}
+
+// columnScales abbreviate large values, largest first
+var columnScales = []struct {
+	divisor float64
+	suffix  string
+}{
+	{1e12, "T"},
+	{1e9, "B"},
+	{1e6, "M"},
+	{1e3, "K"},
+}
+
+// CurrencyRatesProvider returns the rate to multiply amounts in one currency
+// by to get them in another
+type CurrencyRatesProvider interface {
+	Rate(from, to string) (float64, error)
+}
+
+// StaticRatesProvider converts currencies with fixed rates from configuration
+type StaticRatesProvider struct {
+	rates map[string]float64 // Units per base currency
+}
+
+// NewStaticRatesProvider parses rates given as CODE=units per base currency,
+// e.g. "USD=1,IDR=16250". Invalid entries are skipped.
+func NewStaticRatesProvider(spec string) *StaticRatesProvider {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		code, value, _ := strings.Cut(entry, "=")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if len(code) != 3 || err != nil || rate <= 0 {
+			logger.L().Warn().Str("entry", entry).Msg("Skipping invalid currency rate")
+			continue
+		}
+		rates[code] = rate
+	}
+	return &StaticRatesProvider{rates: rates}
+}
+
+// Rate returns the rate converting amounts from one currency to another
+func (p *StaticRatesProvider) Rate(from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: no rate for %s", ErrUnsupportedCurrency, from)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: no rate for %s", ErrUnsupportedCurrency, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// ResultFormatService annotates numeric result columns with formatting
+// metadata from the curated column metadata and the KPI definitions of the
+// user, and converts currency columns on request
+type ResultFormatService struct {
+	db              *gorm.DB
+	rates           CurrencyRatesProvider
+	defaultCurrency string // Currency of currency columns and KPIs without one
+}
+
+// NewResultFormatService creates a new result format service
+func NewResultFormatService(db *gorm.DB, rates CurrencyRatesProvider, defaultCurrency string) *ResultFormatService {
+	return &ResultFormatService{
+		db:              db,
+		rates:           rates,
+		defaultCurrency: strings.ToUpper(defaultCurrency),
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ResultFormatService) WithContext(ctx context.Context) *ResultFormatService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// CheckCurrency returns ErrUnsupportedCurrency when results cannot be
+// converted to the currency
+func (s *ResultFormatService) CheckCurrency(currency string) error {
+	_, err := s.rates.Rate(s.defaultCurrency, currency)
+	return err
+}
+
+// Annotate returns a copy of the result columns with formats for the numeric
+// columns whose unit is known. Curated column metadata takes precedence over
+// KPIs named like the column; failing to load either only skips annotation.
+func (s *ResultFormatService) Annotate(userID, dataSourceID uint, columns []models.Column, rows []map[string]interface{}) []models.Column {
+	var metadata []models.ColumnMetadata
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Find(&metadata).Error; err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSourceID).Msg("Failed to load column units")
+	}
+	var kpis []models.KPIDefinition
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&kpis).Error; err != nil {
+		logger.L().Error().Err(err).Uint("user_id", userID).Msg("Failed to load KPI units")
+	}
+	return annotateResultColumns(columns, rows, metadata, kpis, s.defaultCurrency)
+}
+
+// Convert returns the rows with the values of currency columns converted to
+// the currency, and the columns with their formats updated. Columns in a
+// currency without a rate are left as they are.
+func (s *ResultFormatService) Convert(columns []models.Column, rows []map[string]interface{}, currency string) ([]models.Column, []map[string]interface{}) {
+	currency = strings.ToUpper(currency)
+	converted := make([]models.Column, len(columns))
+	copy(converted, columns)
+
+	rates := make(map[string]float64)
+	for i, col := range converted {
+		if col.Format == nil || col.Format.Kind != models.ColumnFormatCurrency || col.Format.Currency == currency {
+			continue
+		}
+		rate, err := s.rates.Rate(col.Format.Currency, currency)
+		if err != nil {
+			logger.L().Warn().Err(err).Str("column", col.Name).Msg("Leaving currency column unconverted")
+			continue
+		}
+		rates[col.Name] = rate
+
+		format := *col.Format
+		format.ConvertedFrom = format.Currency
+		format.Currency = currency
+		format.Symbol = currencySymbols[currency]
+		format.Rate = rate
+		format.Decimals = 2
+		if zeroDecimalCurrencies[currency] {
+			format.Decimals = 0
+		}
+		converted[i].Format = &format
+	}
+	if len(rates) == 0 {
+		return converted, rows
+	}
+
+	// Copy the rows; they may still be referenced by the stored result
+	convertedRows := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		convertedRow := make(map[string]interface{}, len(row))
+		for name, value := range row {
+			convertedRow[name] = value
+			if rate, ok := rates[name]; ok {
+				if number, ok := answerNumber(value); ok {
+					convertedRow[name] = number * rate
+				}
+			}
+		}
+		convertedRows[i] = convertedRow
+	}
+	for i := range converted {
+		if _, ok := rates[converted[i].Name]; ok {
+			scaleColumnFormat(converted[i].Format, converted[i].Name, convertedRows)
+		}
+	}
+	return converted, convertedRows
+}
+
+// annotateResultColumns sets the formats of numeric columns with a known unit
+func annotateResultColumns(columns []models.Column, rows []map[string]interface{}, metadata []models.ColumnMetadata, kpis []models.KPIDefinition, defaultCurrency string) []models.Column {
+	byColumn := make(map[string]models.ColumnMetadata, len(metadata))
+	for _, m := range metadata {
+		var tags []string
+		if len(m.Tags) > 0 {
+			_ = json.Unmarshal(m.Tags, &tags)
+		}
+		for _, tag := range tags {
+			if tag == "currency" && m.Unit == "" {
+				m.Unit = "currency"
+			}
+		}
+		if m.Unit != "" || m.Currency != "" {
+			byColumn[strings.ToLower(m.Column)] = m
+		}
+	}
+	byKPI := make(map[string]models.KPIDefinition, len(kpis))
+	for _, kpi := range kpis {
+		if kpi.Unit != "" {
+			byKPI[strings.ToLower(kpi.Name)] = kpi
+		}
+	}
+
+	annotated := make([]models.Column, len(columns))
+	copy(annotated, columns)
+	for i, col := range annotated {
+		name := strings.ToLower(col.Name)
+		var format *models.ColumnFormat
+		if m, ok := byColumn[name]; ok {
+			format = newColumnFormat(m.Unit, m.Currency, defaultCurrency, models.ColumnFormatSourceColumnMetadata)
+		} else if kpi, ok := byKPI[strings.ReplaceAll(name, " ", "_")]; ok {
+			format = newColumnFormat(kpi.Unit, "", defaultCurrency, models.ColumnFormatSourceKPI)
+		}
+		if format == nil || !numericColumn(col.Name, rows) {
+			continue
+		}
+		scaleColumnFormat(format, col.Name, rows)
+		annotated[i].Format = format
+	}
+	return annotated
+}
+
+// newColumnFormat builds the format of a unit. KPI units are free text:
+// currency, an ISO currency code, percentage and count are recognized, any
+// other unit is kept for number columns.
+func newColumnFormat(unit, currency, defaultCurrency, source string) *models.ColumnFormat {
+	unit = strings.TrimSpace(unit)
+	code := strings.ToUpper(unit)
+	if _, ok := currencySymbols[code]; ok && currency == "" {
+		currency = code
+	}
+
+	format := &models.ColumnFormat{Kind: models.ColumnFormatNumber, Unit: unit, Decimals: 2, Source: source}
+	switch {
+	case currency != "" || strings.EqualFold(unit, "currency"):
+		if currency == "" {
+			currency = defaultCurrency
+		}
+		currency = strings.ToUpper(currency)
+		format.Kind, format.Unit, format.Currency, format.Symbol = models.ColumnFormatCurrency, "", currency, currencySymbols[currency]
+		if zeroDecimalCurrencies[currency] {
+			format.Decimals = 0
+		}
+	case unit == "%" || strings.EqualFold(unit, "percentage") || strings.EqualFold(unit, "percent"):
+		format.Kind, format.Unit, format.Decimals = models.ColumnFormatPercentage, "", 1
+	case strings.EqualFold(unit, "count"):
+		format.Kind, format.Unit, format.Decimals = models.ColumnFormatCount, "", 0
+	case unit == "":
+		return nil
+	}
+	return format
+}
+
+// numericColumn reports whether the column has a value and all its values are numbers
+func numericColumn(column string, rows []map[string]interface{}) bool {
+	found := false
+	for _, row := range rows {
+		value := row[column]
+		if value == nil {
+			continue
+		}
+		if _, ok := answerNumber(value); !ok {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// scaleColumnFormat picks the abbreviation of the largest value of the column
+// and formats it as the example. Percentages are never abbreviated.
+func scaleColumnFormat(format *models.ColumnFormat, column string, rows []map[string]interface{}) {
+	largest := 0.0
+	for _, row := range rows {
+		if number, ok := answerNumber(row[column]); ok && math.Abs(number) > math.Abs(largest) {
+			largest = number
+		}
+	}
+
+	format.Divisor, format.Suffix = 0, ""
+	if format.Kind != models.ColumnFormatPercentage {
+		for _, scale := range columnScales {
+			if math.Abs(largest) >= scale.divisor {
+				format.Divisor, format.Suffix = scale.divisor, scale.suffix
+				if format.Decimals == 0 {
+					format.Decimals = 1
+				}
+				break
+			}
+		}
+	}
+	format.Example = formatColumnValue(format, largest)
+}
+
+// formatColumnValue renders a value the way clients should, e.g. "Rp 1.2M"
+func formatColumnValue(format *models.ColumnFormat, value float64) string {
+	if format.Divisor > 0 {
+		value /= format.Divisor
+	}
+	number := formatAnswerNumber(value, format.Decimals)
+	if strings.Contains(number, ".") {
+		number = strings.TrimSuffix(strings.TrimRight(number, "0"), ".")
+	}
+	number += format.Suffix
+
+	switch format.Kind {
+	case models.ColumnFormatCurrency:
+		symbol := format.Symbol
+		if symbol == "" {
+			return format.Currency + " " + number
+		}
+		// Letter symbols are separated from the amount: "Rp 1.2M" but "$1.2M"
+		if last := []rune(symbol)[len([]rune(symbol))-1]; unicode.IsLetter(last) {
+			return symbol + " " + number
+		}
+		return symbol + number
+	case models.ColumnFormatPercentage:
+		return number + "%"
+	}
+	if format.Unit != "" {
+		return number + " " + format.Unit
+	}
+	return number
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateResultColumns(t *testing.T) {
+	columns := []models.Column{{Name: "region"}, {Name: "revenue"}, {Name: "conversion_rate"}, {Name: "latency"}, {Name: "orders"}}
+	rows := []map[string]interface{}{
+		{"region": "Java", "revenue": 1260000.0, "conversion_rate": 3.27, "latency": "120", "orders": int64(1520)},
+		{"region": "Bali", "revenue": 830000.0, "conversion_rate": 2.5, "latency": "95", "orders": nil},
+	}
+	metadata := []models.ColumnMetadata{
+		{Table: "sales", Column: "revenue", Tags: models.JSON(`["currency"]`)},
+		{Table: "metrics", Column: "latency", Unit: "ms"},
+	}
+	kpis := []models.KPIDefinition{
+		{Name: "conversion_rate", Unit: "percentage"},
+		{Name: "orders", Unit: "count"},
+		{Name: "revenue", Unit: "USD"},
+	}
+
+	annotated := annotateResultColumns(columns, rows, metadata, kpis, "IDR")
+	assert.Nil(t, columns[1].Format, "the input columns are not modified")
+	assert.Nil(t, annotated[0].Format, "text columns are not formatted")
+
+	revenue := annotated[1].Format
+	require.NotNil(t, revenue)
+	assert.Equal(t, models.ColumnFormatCurrency, revenue.Kind)
+	assert.Equal(t, "IDR", revenue.Currency, "column metadata wins over KPIs")
+	assert.Equal(t, models.ColumnFormatSourceColumnMetadata, revenue.Source)
+	assert.Equal(t, 1e6, revenue.Divisor)
+	assert.Equal(t, "Rp 1.3M", revenue.Example)
+
+	rate := annotated[2].Format
+	require.NotNil(t, rate)
+	assert.Equal(t, models.ColumnFormatPercentage, rate.Kind)
+	assert.Equal(t, "3.3%", rate.Example)
+
+	latency := annotated[3].Format
+	require.NotNil(t, latency)
+	assert.Equal(t, "120 ms", latency.Example, "numeric strings are numbers")
+
+	orders := annotated[4].Format
+	require.NotNil(t, orders)
+	assert.Equal(t, models.ColumnFormatCount, orders.Kind)
+	assert.Equal(t, "1.5K", orders.Example)
+}
+
+func TestResultFormatConvert(t *testing.T) {
+	s := &ResultFormatService{rates: NewStaticRatesProvider("USD=1, IDR=16000, bad, EUR=-1"), defaultCurrency: "IDR"}
+	columns := []models.Column{{Name: "revenue", Format: newColumnFormat("currency", "", "IDR", models.ColumnFormatSourceKPI)}}
+	rows := []map[string]interface{}{{"revenue": 32000000.0}}
+
+	converted, convertedRows := s.Convert(columns, rows, "usd")
+	assert.Equal(t, 32000000.0, rows[0]["revenue"], "the input rows are not modified")
+	assert.InDelta(t, 2000.0, convertedRows[0]["revenue"], 1e-9)
+	format := converted[0].Format
+	assert.Equal(t, "USD", format.Currency)
+	assert.Equal(t, "IDR", format.ConvertedFrom)
+	assert.Equal(t, "$2K", format.Example)
+
+	require.NoError(t, s.CheckCurrency("USD"))
+	assert.True(t, errors.Is(s.CheckCurrency("EUR"), ErrUnsupportedCurrency), "invalid rates are skipped")
+}
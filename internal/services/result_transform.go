@@ -0,0 +1,502 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// ErrResultTransformInvalid is returned for unknown operations or columns
+var ErrResultTransformInvalid = errors.New("invalid result transform")
+
+const (
+	// resultTransformMaxRows bounds the rows of a transformed result, which are held in memory
+	resultTransformMaxRows = 50000
+	// resultTransformMaxPivotColumns bounds the distinct values a pivot turns into columns
+	resultTransformMaxPivotColumns = 100
+	// defaultResultTransformLimit and maxResultTransformLimit bound the derived rows returned
+	defaultResultTransformLimit = 1000
+	maxResultTransformLimit     = 10000
+	// maxResultTransformOperations bounds the steps of a transform
+	maxResultTransformOperations = 10
+)
+
+// TransformQueryResult reshapes a stored result of the user's query with
+// group-by, pivot, sort and top-N operations, without querying the data
+// source again. PII columns are masked before transforming unless the request
+// may unmask them, so masked values never leak through group keys or pivoted
+// column names.
+func (s *NL2SQLService) TransformQueryResult(userID uint, queryID uint, req *models.ResultTransformRequest) (*models.ResultTransformResponse, error) {
+	if len(req.Operations) == 0 || len(req.Operations) > maxResultTransformOperations {
+		return nil, fmt.Errorf("%w: between 1 and %d operations are required", ErrResultTransformInvalid, maxResultTransformOperations)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultResultTransformLimit
+	}
+	if limit > maxResultTransformLimit {
+		limit = maxResultTransformLimit
+	}
+
+	query, err := s.GetQueryDetails(userID, queryID)
+	if err != nil {
+		return nil, err
+	}
+	result, columns, rows, err := s.resultService.ReadAll(query.ID, req.ResultID, resultTransformMaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	var maskedColumns []string
+	if !req.UnmaskPII {
+		rows, maskedColumns = s.piiMasker.MaskRows(query.DataSourceID, columns, rows)
+	}
+
+	columns, rows, err = transformResultRows(columns, rows, req.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ResultTransformResponse{
+		QueryID:       query.ID,
+		ResultID:      result.ID,
+		Columns:       columns,
+		Data:          rows,
+		TotalRows:     len(rows),
+		SourceRows:    result.RowCount,
+		MaskedColumns: maskedColumns,
+		CreatedAt:     result.CreatedAt,
+	}
+	if len(rows) > limit {
+		response.Data = rows[:limit]
+		response.Truncated = true
+	}
+	return response, nil
+}
+
+// transformResultRows applies the operations in order, each to the output of
+// the previous one
+func transformResultRows(columns []models.Column, rows []map[string]interface{}, operations []models.ResultTransformOperation) ([]models.Column, []map[string]interface{}, error) {
+	for i, operation := range operations {
+		var err error
+		switch operation.Op {
+		case models.ResultTransformGroupBy:
+			columns, rows, err = groupResultRows(columns, rows, operation.GroupBy, operation.Aggregates)
+		case models.ResultTransformPivot:
+			columns, rows, err = pivotResultRows(columns, rows, &operation)
+		case models.ResultTransformSort:
+			rows, err = sortResultRows(columns, rows, operation.Sort)
+		case models.ResultTransformTopN:
+			rows, err = topResultRows(columns, rows, &operation)
+		default:
+			err = fmt.Errorf("%w: unknown operation %q", ErrResultTransformInvalid, operation.Op)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("operation %d: %w", i+1, err)
+		}
+	}
+	return columns, rows, nil
+}
+
+// groupResultRows aggregates the rows of each distinct combination of the
+// group columns, in order of first appearance. Without aggregates the rows of
+// each group are counted; without group columns all rows form one group.
+func groupResultRows(columns []models.Column, rows []map[string]interface{}, groupBy []string, aggregates []models.ResultAggregate) ([]models.Column, []map[string]interface{}, error) {
+	byName, err := resultColumnsByName(columns, groupBy...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(aggregates) == 0 {
+		aggregates = []models.ResultAggregate{{Function: models.AggregateCount, As: "count"}}
+	}
+	outColumns := make([]models.Column, 0, len(groupBy)+len(aggregates))
+	for _, column := range groupBy {
+		outColumns = append(outColumns, byName[column])
+	}
+	names := make(map[string]bool, len(outColumns))
+	for _, column := range outColumns {
+		names[column.Name] = true
+	}
+	for i, aggregate := range aggregates {
+		column, err := aggregateColumn(byName, columns, aggregate)
+		if err != nil {
+			return nil, nil, err
+		}
+		if names[column.Name] {
+			return nil, nil, fmt.Errorf("%w: aggregate %d repeats column name %s; set as", ErrResultTransformInvalid, i+1, column.Name)
+		}
+		names[column.Name] = true
+		outColumns = append(outColumns, column)
+	}
+
+	type group struct {
+		key          map[string]interface{}
+		accumulators []*resultAccumulator
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	for _, row := range rows {
+		key := resultRowKey(row, groupBy, 0)
+		g, ok := index[key]
+		if !ok {
+			g = &group{key: rowKeyValues(row, groupBy), accumulators: make([]*resultAccumulator, len(aggregates))}
+			for i := range aggregates {
+				g.accumulators[i] = newResultAccumulator()
+			}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		for i, aggregate := range aggregates {
+			if aggregate.Column == "" {
+				g.accumulators[i].addRow()
+			} else {
+				g.accumulators[i].add(row[aggregate.Column])
+			}
+		}
+	}
+	// A grand total has one row even without input rows
+	if len(groupBy) == 0 && len(groups) == 0 {
+		g := &group{accumulators: make([]*resultAccumulator, len(aggregates))}
+		for i := range aggregates {
+			g.accumulators[i] = newResultAccumulator()
+		}
+		groups = append(groups, g)
+	}
+
+	outRows := make([]map[string]interface{}, 0, len(groups))
+	for _, g := range groups {
+		row := make(map[string]interface{}, len(outColumns))
+		for name, value := range g.key {
+			row[name] = value
+		}
+		for i, aggregate := range aggregates {
+			row[outColumns[len(groupBy)+i].Name] = g.accumulators[i].result(aggregate.Function)
+		}
+		outRows = append(outRows, row)
+	}
+	return outColumns, outRows, nil
+}
+
+// pivotResultRows turns each distinct value of the pivot column into a column
+// holding the aggregate of the value column, with a row per distinct
+// combination of the group columns. Pivoted columns are ordered by value.
+func pivotResultRows(columns []models.Column, rows []map[string]interface{}, operation *models.ResultTransformOperation) ([]models.Column, []map[string]interface{}, error) {
+	function := operation.Function
+	if function == "" {
+		function = models.AggregateSum
+	}
+	if operation.PivotColumn == "" {
+		return nil, nil, fmt.Errorf("%w: pivot needs pivot_column", ErrResultTransformInvalid)
+	}
+	if operation.Value == "" && function != models.AggregateCount {
+		return nil, nil, fmt.Errorf("%w: pivot needs value unless function is count", ErrResultTransformInvalid)
+	}
+	referenced := append([]string{operation.PivotColumn}, operation.GroupBy...)
+	if operation.Value != "" {
+		referenced = append(referenced, operation.Value)
+	}
+	byName, err := resultColumnsByName(columns, referenced...)
+	if err != nil {
+		return nil, nil, err
+	}
+	valueColumn, err := aggregateColumn(byName, columns, models.ResultAggregate{Function: function, Column: operation.Value})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Distinct pivot values, by name
+	pivotValues := make(map[string]interface{})
+	for _, row := range rows {
+		name := pivotColumnName(row[operation.PivotColumn])
+		if _, ok := pivotValues[name]; !ok {
+			if len(pivotValues) == resultTransformMaxPivotColumns {
+				return nil, nil, fmt.Errorf("%w: %s has more than %d distinct values to pivot", ErrResultTransformInvalid, operation.PivotColumn, resultTransformMaxPivotColumns)
+			}
+			pivotValues[name] = row[operation.PivotColumn]
+		}
+	}
+	pivotNames := make([]string, 0, len(pivotValues))
+	for name := range pivotValues {
+		pivotNames = append(pivotNames, name)
+	}
+	sort.Slice(pivotNames, func(i, j int) bool {
+		return compareResultValues(pivotValues[pivotNames[i]], pivotValues[pivotNames[j]]) < 0
+	})
+
+	outColumns := make([]models.Column, 0, len(operation.GroupBy)+len(pivotNames))
+	isGroupColumn := make(map[string]bool, len(operation.GroupBy))
+	for _, column := range operation.GroupBy {
+		outColumns = append(outColumns, byName[column])
+		isGroupColumn[column] = true
+	}
+	for _, name := range pivotNames {
+		if isGroupColumn[name] {
+			return nil, nil, fmt.Errorf("%w: pivoted value %s clashes with a group column", ErrResultTransformInvalid, name)
+		}
+		outColumns = append(outColumns, models.Column{Name: name, Type: valueColumn.Type, Nullable: true})
+	}
+
+	type group struct {
+		key          map[string]interface{}
+		accumulators map[string]*resultAccumulator
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	for _, row := range rows {
+		key := resultRowKey(row, operation.GroupBy, 0)
+		g, ok := index[key]
+		if !ok {
+			g = &group{key: rowKeyValues(row, operation.GroupBy), accumulators: make(map[string]*resultAccumulator)}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		name := pivotColumnName(row[operation.PivotColumn])
+		accumulator, ok := g.accumulators[name]
+		if !ok {
+			accumulator = newResultAccumulator()
+			g.accumulators[name] = accumulator
+		}
+		if operation.Value == "" {
+			accumulator.addRow()
+		} else {
+			accumulator.add(row[operation.Value])
+		}
+	}
+
+	outRows := make([]map[string]interface{}, 0, len(groups))
+	for _, g := range groups {
+		row := make(map[string]interface{}, len(outColumns))
+		for name, value := range g.key {
+			row[name] = value
+		}
+		for _, name := range pivotNames {
+			row[name] = nil
+			if accumulator, ok := g.accumulators[name]; ok {
+				row[name] = accumulator.result(function)
+			}
+		}
+		outRows = append(outRows, row)
+	}
+	return outColumns, outRows, nil
+}
+
+// sortResultRows orders rows by the sort keys, keeping the order of equal rows.
+// Nulls come last in either direction.
+func sortResultRows(columns []models.Column, rows []map[string]interface{}, keys []models.ResultSortKey) ([]map[string]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: sort needs at least one key", ErrResultTransformInvalid)
+	}
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Column
+	}
+	if _, err := resultColumnsByName(columns, names...); err != nil {
+		return nil, err
+	}
+
+	sorted := append([]map[string]interface{}{}, rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := sorted[i][key.Column], sorted[j][key.Column]
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			order := compareResultValues(a, b)
+			if order == 0 {
+				continue
+			}
+			if key.Desc {
+				return order > 0
+			}
+			return order < 0
+		}
+		return false
+	})
+	return sorted, nil
+}
+
+// topResultRows keeps the n rows with the largest values of the by column, or
+// the smallest when ascending; without a column, the first n rows
+func topResultRows(columns []models.Column, rows []map[string]interface{}, operation *models.ResultTransformOperation) ([]map[string]interface{}, error) {
+	if operation.N <= 0 {
+		return nil, fmt.Errorf("%w: top_n needs a positive n", ErrResultTransformInvalid)
+	}
+	if operation.By != "" {
+		var err error
+		rows, err = sortResultRows(columns, rows, []models.ResultSortKey{{Column: operation.By, Desc: !operation.Ascending}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(rows) > operation.N {
+		rows = rows[:operation.N]
+	}
+	return rows, nil
+}
+
+// resultColumnsByName indexes the columns by name, checking the referenced ones exist
+func resultColumnsByName(columns []models.Column, referenced ...string) (map[string]models.Column, error) {
+	byName := make(map[string]models.Column, len(columns))
+	for _, column := range columns {
+		byName[column.Name] = column
+	}
+	for _, name := range referenced {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrResultTransformInvalid, name)
+		}
+	}
+	return byName, nil
+}
+
+// aggregateColumn describes the column an aggregate produces
+func aggregateColumn(byName map[string]models.Column, columns []models.Column, aggregate models.ResultAggregate) (models.Column, error) {
+	if aggregate.Column != "" {
+		if _, err := resultColumnsByName(columns, aggregate.Column); err != nil {
+			return models.Column{}, err
+		}
+	} else if aggregate.Function != models.AggregateCount {
+		return models.Column{}, fmt.Errorf("%w: %s needs a column", ErrResultTransformInvalid, aggregate.Function)
+	}
+
+	name := aggregate.As
+	if name == "" {
+		name = aggregate.Function
+		if aggregate.Column != "" {
+			name += "_" + aggregate.Column
+		}
+	}
+
+	column := models.Column{Name: name, Nullable: true}
+	switch aggregate.Function {
+	case models.AggregateCount, models.AggregateCountDistinct:
+		column.Type, column.Nullable = "integer", false
+	case models.AggregateAvg:
+		column.Type = "float"
+	case models.AggregateSum:
+		column.Type = "float"
+		if strings.Contains(strings.ToLower(byName[aggregate.Column].Type), "int") {
+			column.Type = byName[aggregate.Column].Type
+		}
+	case models.AggregateMin, models.AggregateMax:
+		column.Type = byName[aggregate.Column].Type
+	default:
+		return models.Column{}, fmt.Errorf("%w: unknown aggregate function %q", ErrResultTransformInvalid, aggregate.Function)
+	}
+	return column, nil
+}
+
+// resultAccumulator folds the values of a column within a group
+type resultAccumulator struct {
+	count    int // Non-null values, or rows for count without a column
+	numbers  int
+	sum      float64
+	min, max interface{}
+	distinct map[string]bool
+}
+
+func newResultAccumulator() *resultAccumulator {
+	return &resultAccumulator{distinct: make(map[string]bool)}
+}
+
+// addRow counts a row for count without a column
+func (a *resultAccumulator) addRow() {
+	a.count++
+}
+
+func (a *resultAccumulator) add(value interface{}) {
+	if value == nil {
+		return
+	}
+	a.count++
+	if number, ok := answerNumber(value); ok {
+		a.numbers++
+		a.sum += number
+	}
+	if a.min == nil || compareResultValues(value, a.min) < 0 {
+		a.min = value
+	}
+	if a.max == nil || compareResultValues(value, a.max) > 0 {
+		a.max = value
+	}
+	a.distinct[resultValueKey(value)] = true
+}
+
+// result returns the aggregate; sums and averages of no numbers are null
+func (a *resultAccumulator) result(function string) interface{} {
+	switch function {
+	case models.AggregateCount:
+		return a.count
+	case models.AggregateCountDistinct:
+		return len(a.distinct)
+	case models.AggregateSum:
+		if a.numbers == 0 {
+			return nil
+		}
+		return a.sum
+	case models.AggregateAvg:
+		if a.numbers == 0 {
+			return nil
+		}
+		return a.sum / float64(a.numbers)
+	case models.AggregateMin:
+		return a.min
+	case models.AggregateMax:
+		return a.max
+	}
+	return nil
+}
+
+// compareResultValues orders two values: numbers by value, before anything
+// else, then text; nulls first
+func compareResultValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	aNumber, aIsNumber := answerNumber(a)
+	bNumber, bIsNumber := answerNumber(b)
+	switch {
+	case aIsNumber && bIsNumber:
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	case aIsNumber:
+		return -1
+	case bIsNumber:
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// resultValueKey identifies a value for distinct counts, numbers by value
+func resultValueKey(value interface{}) string {
+	if number, ok := answerNumber(value); ok {
+		value = number
+	}
+	key, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(key)
+}
+
+// pivotColumnName names the column of a pivoted value
+func pivotColumnName(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	return fmt.Sprint(value)
+}
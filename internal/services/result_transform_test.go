@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func transformTestResult() ([]models.Column, []map[string]interface{}) {
+	columns := []models.Column{{Name: "region", Type: "string"}, {Name: "month", Type: "string"}, {Name: "revenue", Type: "float"}, {Name: "orders", Type: "integer"}}
+	rows := []map[string]interface{}{
+		{"region": "north", "month": "2025-02", "revenue": 100.0, "orders": 4.0},
+		{"region": "south", "month": "2025-01", "revenue": 50.0, "orders": 2.0},
+		{"region": "north", "month": "2025-01", "revenue": 80.0, "orders": 3.0},
+		{"region": "west", "month": "2025-02", "revenue": nil, "orders": 1.0},
+	}
+	return columns, rows
+}
+
+func TestTransformResultRows_GroupBy(t *testing.T) {
+	columns, rows := transformTestResult()
+	outColumns, outRows, err := transformResultRows(columns, rows, []models.ResultTransformOperation{{
+		Op:      models.ResultTransformGroupBy,
+		GroupBy: []string{"region"},
+		Aggregates: []models.ResultAggregate{
+			{Function: models.AggregateSum, Column: "revenue"},
+			{Function: models.AggregateAvg, Column: "orders", As: "avg_orders"},
+			{Function: models.AggregateCount},
+		},
+	}})
+	require.NoError(t, err)
+
+	require.Len(t, outColumns, 4)
+	assert.Equal(t, "sum_revenue", outColumns[1].Name)
+	assert.Equal(t, "integer", outColumns[3].Type)
+	// Groups in order of first appearance
+	require.Len(t, outRows, 3)
+	assert.Equal(t, map[string]interface{}{"region": "north", "sum_revenue": 180.0, "avg_orders": 3.5, "count": 2}, outRows[0])
+	// Sums of nothing but nulls are null
+	assert.Nil(t, outRows[2]["sum_revenue"])
+
+	// Without group columns all rows are one group
+	_, outRows, err = transformResultRows(columns, rows, []models.ResultTransformOperation{{
+		Op: models.ResultTransformGroupBy, Aggregates: []models.ResultAggregate{{Function: models.AggregateCountDistinct, Column: "region", As: "regions"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"regions": 3}}, outRows)
+}
+
+func TestTransformResultRows_Pivot(t *testing.T) {
+	columns, rows := transformTestResult()
+	outColumns, outRows, err := transformResultRows(columns, rows, []models.ResultTransformOperation{{
+		Op: models.ResultTransformPivot, GroupBy: []string{"region"}, PivotColumn: "month", Value: "revenue",
+	}})
+	require.NoError(t, err)
+
+	// Pivoted columns are ordered by value
+	require.Len(t, outColumns, 3)
+	assert.Equal(t, "2025-01", outColumns[1].Name)
+	assert.Equal(t, "2025-02", outColumns[2].Name)
+	require.Len(t, outRows, 3)
+	assert.Equal(t, map[string]interface{}{"region": "north", "2025-01": 80.0, "2025-02": 100.0}, outRows[0])
+	assert.Nil(t, outRows[1]["2025-02"])
+}
+
+func TestTransformResultRows_SortAndTopN(t *testing.T) {
+	columns, rows := transformTestResult()
+	_, outRows, err := transformResultRows(columns, rows, []models.ResultTransformOperation{
+		{Op: models.ResultTransformSort, Sort: []models.ResultSortKey{{Column: "month"}, {Column: "revenue", Desc: true}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{80.0, 50.0, 100.0, nil}, []interface{}{outRows[0]["revenue"], outRows[1]["revenue"], outRows[2]["revenue"], outRows[3]["revenue"]})
+
+	// Nulls come last in either direction
+	_, outRows, err = transformResultRows(columns, rows, []models.ResultTransformOperation{
+		{Op: models.ResultTransformTopN, N: 2, By: "revenue"},
+	})
+	require.NoError(t, err)
+	require.Len(t, outRows, 2)
+	assert.Equal(t, 100.0, outRows[0]["revenue"])
+	assert.Equal(t, 80.0, outRows[1]["revenue"])
+
+	// Operations chain: the region with the most orders
+	_, outRows, err = transformResultRows(columns, rows, []models.ResultTransformOperation{
+		{Op: models.ResultTransformGroupBy, GroupBy: []string{"region"}, Aggregates: []models.ResultAggregate{{Function: models.AggregateSum, Column: "orders"}}},
+		{Op: models.ResultTransformTopN, N: 1, By: "sum_orders"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"region": "north", "sum_orders": 7.0}}, outRows)
+}
+
+func TestTransformResultRows_Invalid(t *testing.T) {
+	columns, rows := transformTestResult()
+	for name, operation := range map[string]models.ResultTransformOperation{
+		"unknown op":        {Op: "explode"},
+		"unknown column":    {Op: models.ResultTransformGroupBy, GroupBy: []string{"country"}},
+		"sum needs column":  {Op: models.ResultTransformGroupBy, Aggregates: []models.ResultAggregate{{Function: models.AggregateSum}}},
+		"unknown function":  {Op: models.ResultTransformGroupBy, Aggregates: []models.ResultAggregate{{Function: "median", Column: "revenue"}}},
+		"pivot no column":   {Op: models.ResultTransformPivot, Value: "revenue"},
+		"top_n without n":   {Op: models.ResultTransformTopN, By: "revenue"},
+		"sort without keys": {Op: models.ResultTransformSort},
+	} {
+		_, _, err := transformResultRows(columns, rows, []models.ResultTransformOperation{operation})
+		assert.ErrorIs(t, err, ErrResultTransformInvalid, name)
+	}
+}
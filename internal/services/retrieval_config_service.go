@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRetrievalConfigNotFound is returned when a scope has no retrieval config
+	ErrRetrievalConfigNotFound = errors.New("retrieval config not found")
+	// ErrRetrievalDataSourceNotFound is returned when a config is set for a data source that does not exist
+	ErrRetrievalDataSourceNotFound = errors.New("data source not found")
+)
+
+// RetrievalConfigService manages the NL2SQL retrieval parameters of the
+// workspace and of data sources, and resolves the ones a context is built with
+type RetrievalConfigService struct {
+	db *gorm.DB
+}
+
+// NewRetrievalConfigService creates a new retrieval config service
+func NewRetrievalConfigService(db *gorm.DB) *RetrievalConfigService {
+	return &RetrievalConfigService{db: db}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *RetrievalConfigService) WithContext(ctx context.Context) *RetrievalConfigService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Resolve returns the config of a data source, falling back to the workspace
+// default and then to the built-in defaults
+func (s *RetrievalConfigService) Resolve(dataSourceID uint) (*models.RetrievalConfig, error) {
+	var configs []models.RetrievalConfig
+	if err := s.db.Where("data_source_id = ? OR data_source_id IS NULL", dataSourceID).
+		Order("data_source_id IS NULL").Limit(1).Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load retrieval config: %w", err)
+	}
+	if len(configs) == 0 {
+		return models.DefaultRetrievalConfig(), nil
+	}
+	return &configs[0], nil
+}
+
+// List returns the configs, the workspace default first, with the built-in defaults
+func (s *RetrievalConfigService) List() (*models.RetrievalConfigListResponse, error) {
+	configs := []models.RetrievalConfig{}
+	if err := s.db.Order("data_source_id IS NOT NULL, data_source_id").Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list retrieval configs: %w", err)
+	}
+	return &models.RetrievalConfigListResponse{
+		Configs: configs,
+		Builtin: models.DefaultRetrievalConfig(),
+	}, nil
+}
+
+// Set creates or replaces the config of the workspace or of a data source
+func (s *RetrievalConfigService) Set(adminID uint, req *models.RetrievalConfigRequest) (*models.RetrievalConfig, error) {
+	if req.DataSourceID != nil {
+		var count int64
+		if err := s.db.Model(&models.DataSource{}).Where("id = ?", *req.DataSourceID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check data source: %w", err)
+		}
+		if count == 0 {
+			return nil, ErrRetrievalDataSourceNotFound
+		}
+	}
+
+	types := req.SchemaElementTypes
+	if len(types) == 0 {
+		types = models.SchemaElementTypes
+	}
+	encoded, _ := json.Marshal(types)
+	config := &models.RetrievalConfig{
+		DataSourceID:       req.DataSourceID,
+		SchemaTopK:         req.SchemaTopK,
+		SchemaElementTypes: models.JSON(encoded),
+		KPITopK:            req.KPITopK,
+		GlossaryTopK:       req.GlossaryTopK,
+		MinScore:           req.MinScore,
+		MaxContextTokens:   req.MaxContextTokens,
+		MaxPromptTokens:    req.MaxPromptTokens,
+		UpdatedBy:          adminID,
+	}
+	// Upserted, so concurrent saves of a scope replace each other rather than
+	// adding a second config; the workspace default of a tenant is unique
+	// through a partial index
+	conflict := clause.OnConflict{
+		Columns: []clause.Column{{Name: "data_source_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"schema_top_k", "schema_element_types", "kpi_top_k", "glossary_top_k",
+			"min_score", "max_context_tokens", "max_prompt_tokens", "updated_by", "updated_at"}),
+	}
+	if req.DataSourceID == nil {
+		conflict.Columns = []clause.Column{{Name: "tenant_id"}}
+		conflict.TargetWhere = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "data_source_id IS NULL"}}}
+	}
+	if err := s.db.Clauses(conflict).Create(config).Error; err != nil {
+		return nil, fmt.Errorf("failed to save retrieval config: %w", err)
+	}
+
+	saved := &models.RetrievalConfig{}
+	if err := retrievalConfigScope(s.db, req.DataSourceID).First(saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to load retrieval config: %w", err)
+	}
+	return saved, nil
+}
+
+// Delete removes the config of the workspace or of a data source, which then
+// falls back to the workspace default or the built-in defaults
+func (s *RetrievalConfigService) Delete(dataSourceID *uint) error {
+	deleted := retrievalConfigScope(s.db, dataSourceID).Delete(&models.RetrievalConfig{})
+	if deleted.Error != nil {
+		return fmt.Errorf("failed to delete retrieval config: %w", deleted.Error)
+	}
+	if deleted.RowsAffected == 0 {
+		return ErrRetrievalConfigNotFound
+	}
+	return nil
+}
+
+// retrievalConfigScope narrows a query to the config of the workspace default
+// or of a data source
+func retrievalConfigScope(db *gorm.DB, dataSourceID *uint) *gorm.DB {
+	if dataSourceID == nil {
+		return db.Where("data_source_id IS NULL")
+	}
+	return db.Where("data_source_id = ?", *dataSourceID)
+}
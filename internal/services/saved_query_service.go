@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSavedQueryNotFound is returned when a saved query does not exist or is neither owned nor shared
+	ErrSavedQueryNotFound = errors.New("saved query not found")
+	// ErrSavedQueryDataSource is returned when the data source of a saved query cannot be used
+	ErrSavedQueryDataSource = errors.New("data source not found or not active")
+	// ErrSavedQueryInvalid is returned when a saved query has no SQL or its SQL fails validation
+	ErrSavedQueryInvalid = errors.New("invalid saved query")
+)
+
+// SavedQueryService manages bookmarked queries. Saved queries are private to
+// their owner unless shared with the workspace; a shared query can be run by
+// anyone, against the owner's data source, but only the owner edits it.
+type SavedQueryService struct {
+	db            *gorm.DB
+	nl2sqlService *NL2SQLService
+}
+
+// NewSavedQueryService creates a new saved query service
+func NewSavedQueryService(db *gorm.DB, nl2sqlService *NL2SQLService) *SavedQueryService {
+	return &SavedQueryService{
+		db:            db,
+		nl2sqlService: nl2sqlService,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *SavedQueryService) WithContext(ctx context.Context) *SavedQueryService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sqlService = s.nl2sqlService.WithContext(ctx)
+	return &scoped
+}
+
+// List returns the saved queries of the user and those shared with the
+// workspace, most recently updated first
+func (s *SavedQueryService) List(userID uint, filter *models.SavedQueryFilter) ([]models.SavedQueryResponse, error) {
+	db := s.db.Where("user_id = ?", userID)
+	if !filter.Mine {
+		db = s.db.Where("(user_id = ? OR shared = ?)", userID, true)
+	}
+	if filter.DataSourceID != 0 {
+		db = db.Where("data_source_id = ?", filter.DataSourceID)
+	}
+	if tags := normalizeTags([]string{filter.Tag}); len(tags) > 0 {
+		tagJSON, _ := json.Marshal(tags)
+		db = db.Where("tags @> ?", string(tagJSON))
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + search + "%"
+		db = db.Where("(name ILIKE ? OR description ILIKE ? OR nl_query ILIKE ?)", pattern, pattern, pattern)
+	}
+
+	var saved []models.SavedQuery
+	if err := db.Order("updated_at DESC").Find(&saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to list saved queries: %w", err)
+	}
+
+	responses := make([]models.SavedQueryResponse, 0, len(saved))
+	for i := range saved {
+		responses = append(responses, savedQueryResponse(&saved[i], userID))
+	}
+	return responses, nil
+}
+
+// Get returns a saved query the user owns or that is shared with the workspace
+func (s *SavedQueryService) Get(userID, id uint) (*models.SavedQueryResponse, error) {
+	saved, err := s.visible(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	response := savedQueryResponse(saved, userID)
+	return &response, nil
+}
+
+// Create saves a query. The SQL is pinned as validated against the data
+// source, so running it again does not call the LLM.
+func (s *SavedQueryService) Create(userID uint, req *models.SavedQueryRequest) (*models.SavedQueryResponse, error) {
+	saved := &models.SavedQuery{UserID: userID}
+	if err := s.apply(userID, saved, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to create saved query: %w", err)
+	}
+	response := savedQueryResponse(saved, userID)
+	return &response, nil
+}
+
+// Update replaces a saved query owned by the user
+func (s *SavedQueryService) Update(userID, id uint, req *models.SavedQueryRequest) (*models.SavedQueryResponse, error) {
+	saved, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(userID, saved, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to update saved query: %w", err)
+	}
+	response := savedQueryResponse(saved, userID)
+	return &response, nil
+}
+
+// Delete removes a saved query owned by the user; query history is kept
+func (s *SavedQueryService) Delete(userID, id uint) error {
+	saved, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(saved).Error; err != nil {
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+	return nil
+}
+
+// Run executes the pinned SQL of a saved query on fresh data. The run is
+// recorded in the history of the user running it, so results can be paged
+// and audited like any other execution; cost ceilings and PII masking apply
+// to that user.
+func (s *SavedQueryService) Run(userID, id uint, req *models.SavedQueryRunRequest) (*models.QueryExecutionResponse, error) {
+	saved, err := s.visible(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shared queries run on the owner's data source
+	var dataSource models.DataSource
+	if err := s.db.First(&dataSource, saved.DataSourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedQueryDataSource
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+	if dataSource.Status != models.ConnectionStatusActive {
+		return nil, ErrSavedQueryDataSource
+	}
+
+	// The validation policy may have changed since the query was saved
+	sql, params, err := s.pin(&dataSource, saved.SQL)
+	if err != nil {
+		return nil, err
+	}
+
+	nlQuery := saved.NLQuery
+	if nlQuery == "" {
+		nlQuery = saved.Name
+	}
+	query := &models.NL2SQLQuery{
+		UserID:       userID,
+		DataSourceID: dataSource.ID,
+		NLQuery:      nlQuery,
+		GeneratedSQL: sql,
+		Parameters:   marshalQueryParameters(params),
+		Type:         models.QueryTypeAnalytics,
+		SavedQueryID: &saved.ID,
+	}
+	// Runs of the user's own saved queries are linked to the history entry they were saved from
+	if saved.QueryID != nil && saved.UserID == userID {
+		if parent, err := s.nl2sqlService.GetQueryDetails(userID, *saved.QueryID); err == nil {
+			query.ParentQueryID = &parent.ID
+			query.ParentSQLVersion = parent.SQLVersion
+		}
+	}
+	query.MarkCompleted(0, 0) // Updated by the execution
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(query).Error; err != nil {
+			return err
+		}
+		comment := fmt.Sprintf("Saved query #%d", saved.ID)
+		if _, err := s.nl2sqlService.versionService.Record(tx, query, models.SQLVersionSourceSavedQuery, userID, comment, 0); err != nil {
+			return err
+		}
+		return tx.Save(query).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query record: %w", err)
+	}
+
+	response, err := s.nl2sqlService.ExecuteQuery(userID, &models.QueryExecutionRequest{
+		QueryID:    query.ID,
+		Limit:      req.Limit,
+		Anonymize:  req.Anonymize,
+		PageSize:   req.PageSize,
+		Parameters: req.Parameters,
+		UnmaskPII:  req.UnmaskPII,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(saved).UpdateColumns(map[string]interface{}{
+		"run_count":   gorm.Expr("run_count + 1"),
+		"last_run_at": time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update saved query: %w", err)
+	}
+	return response, nil
+}
+
+// apply validates a request and copies it onto a saved query. With a query
+// ID, the question, SQL and data source default to the history entry's.
+func (s *SavedQueryService) apply(userID uint, saved *models.SavedQuery, req *models.SavedQueryRequest) error {
+	nlQuery, sql, dataSourceID := req.NLQuery, req.SQL, req.DataSourceID
+	if req.QueryID != nil {
+		query, err := s.nl2sqlService.GetQueryDetails(userID, *req.QueryID)
+		if err != nil {
+			return fmt.Errorf("%w: query %d not found in history", ErrSavedQueryInvalid, *req.QueryID)
+		}
+		if query.Federated {
+			// Saved queries run on one data source
+			return fmt.Errorf("%w: federated queries cannot be saved", ErrSavedQueryInvalid)
+		}
+		if nlQuery == "" {
+			nlQuery = query.NLQuery
+		}
+		if sql == "" {
+			sql = query.GeneratedSQL
+		}
+		if dataSourceID == 0 {
+			dataSourceID = query.DataSourceID
+		}
+	}
+	if dataSourceID == 0 {
+		return fmt.Errorf("%w: data_source_id or query_id is required", ErrSavedQueryInvalid)
+	}
+	if strings.TrimSpace(sql) == "" {
+		return fmt.Errorf("%w: sql or query_id is required", ErrSavedQueryInvalid)
+	}
+
+	dataSource, err := s.nl2sqlService.validateDataSourceAccess(userID, dataSourceID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSavedQueryDataSource, err)
+	}
+	sql, _, err = s.pin(dataSource, sql)
+	if err != nil {
+		return err
+	}
+
+	tagsJSON, err := json.Marshal(normalizeTags(req.Tags))
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	saved.DataSourceID = dataSource.ID
+	saved.QueryID = req.QueryID
+	saved.Name = strings.TrimSpace(req.Name)
+	saved.Description = strings.TrimSpace(req.Description)
+	saved.Tags = models.JSON(tagsJSON)
+	saved.NLQuery = strings.TrimSpace(nlQuery)
+	saved.SQL = sql
+	saved.Shared = req.Shared
+	return nil
+}
+
+// pin prepares SQL for a data source and checks it is safe to execute,
+// returning it with its placeholders
+func (s *SavedQueryService) pin(dataSource *models.DataSource, sql string) (string, []models.QueryParameter, error) {
+	sql, validation, err := s.nl2sqlService.prepareQuery(dataSource, sql)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrSavedQueryInvalid, err)
+	}
+	if !s.nl2sqlService.sqlValidator.IsQuerySafe(validation) {
+		return "", nil, fmt.Errorf("%w: query failed safety validation: %s", ErrSavedQueryInvalid, strings.Join(validation.Violations, "; "))
+	}
+	params, err := detectQueryParameters(sql, models.DialectForDataSourceType(dataSource.Type))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrSavedQueryInvalid, err)
+	}
+	return sql, params, nil
+}
+
+// visible loads a saved query the user owns or that is shared with the workspace
+func (s *SavedQueryService) visible(userID, id uint) (*models.SavedQuery, error) {
+	var saved models.SavedQuery
+	if err := s.db.Where("id = ? AND (user_id = ? OR shared = ?)", id, userID, true).First(&saved).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedQueryNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved query: %w", err)
+	}
+	return &saved, nil
+}
+
+// owned loads a saved query owned by the user
+func (s *SavedQueryService) owned(userID, id uint) (*models.SavedQuery, error) {
+	var saved models.SavedQuery
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&saved).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedQueryNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved query: %w", err)
+	}
+	return &saved, nil
+}
+
+// savedQueryResponse converts a saved query for the user viewing it
+func savedQueryResponse(saved *models.SavedQuery, userID uint) models.SavedQueryResponse {
+	tags := []string{}
+	if len(saved.Tags) > 0 {
+		_ = json.Unmarshal(saved.Tags, &tags)
+	}
+	return models.SavedQueryResponse{
+		ID:           saved.ID,
+		UserID:       saved.UserID,
+		DataSourceID: saved.DataSourceID,
+		QueryID:      saved.QueryID,
+		Name:         saved.Name,
+		Description:  saved.Description,
+		Tags:         tags,
+		NLQuery:      saved.NLQuery,
+		SQL:          saved.SQL,
+		Shared:       saved.Shared,
+		Owned:        saved.UserID == userID,
+		RunCount:     saved.RunCount,
+		LastRunAt:    saved.LastRunAt,
+		CreatedAt:    saved.CreatedAt,
+		UpdatedAt:    saved.UpdatedAt,
+	}
+}
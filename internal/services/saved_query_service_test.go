@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedQueryResponse(t *testing.T) {
+	saved := &models.SavedQuery{
+		ID:           7,
+		UserID:       1,
+		DataSourceID: 3,
+		Name:         "Revenue by region",
+		Tags:         models.JSON(`["finance","weekly"]`),
+		SQL:          "SELECT region, SUM(amount) FROM sales GROUP BY region LIMIT 100",
+		Shared:       true,
+	}
+
+	owner := savedQueryResponse(saved, 1)
+	assert.True(t, owner.Owned)
+	assert.Equal(t, []string{"finance", "weekly"}, owner.Tags)
+
+	teammate := savedQueryResponse(saved, 2)
+	assert.False(t, teammate.Owned)
+
+	saved.Tags = nil
+	assert.Equal(t, []string{}, savedQueryResponse(saved, 1).Tags)
+}
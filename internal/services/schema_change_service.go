@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+// SchemaChangeService records the schema changes found when a data source is
+// rediscovered and the saved queries and KPIs they break
+type SchemaChangeService struct {
+	db       *gorm.DB
+	webhooks *WebhookService
+}
+
+// NewSchemaChangeService creates a new schema change service
+func NewSchemaChangeService(db *gorm.DB, webhooks *WebhookService) *SchemaChangeService {
+	return &SchemaChangeService{db: db, webhooks: webhooks}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *SchemaChangeService) WithContext(ctx context.Context) *SchemaChangeService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.webhooks = s.webhooks.WithContext(ctx)
+	return &scoped
+}
+
+// Record compares the schemas of a data source before and after a discovery
+// and persists the diff with the saved queries of the data source and the
+// KPIs of its owner that reference removed tables or columns
+func (s *SchemaChangeService) Record(dataSource *models.DataSource, before, after []models.Schema) (*models.SchemaChangeResponse, error) {
+	diff := diffSchemas(before, after)
+
+	var affectedQueries, affectedKPIs []models.SchemaChangeImpact
+	if len(diff.RemovedTables) > 0 || len(diff.RemovedColumns) > 0 {
+		var err error
+		if affectedQueries, err = s.affectedQueries(dataSource, &diff); err != nil {
+			return nil, err
+		}
+		if affectedKPIs, err = s.affectedKPIs(dataSource, &diff); err != nil {
+			return nil, err
+		}
+	}
+
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema diff: %w", err)
+	}
+	queriesJSON, err := json.Marshal(affectedQueries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal affected queries: %w", err)
+	}
+	kpisJSON, err := json.Marshal(affectedKPIs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal affected KPIs: %w", err)
+	}
+
+	change := &models.SchemaChange{
+		TenantID:        dataSource.TenantID,
+		DataSourceID:    dataSource.ID,
+		HasChanges:      !diff.Empty(),
+		Diff:            models.JSON(diffJSON),
+		AffectedQueries: models.JSON(queriesJSON),
+		AffectedKPIs:    models.JSON(kpisJSON),
+	}
+	if err := s.db.Create(change).Error; err != nil {
+		return nil, fmt.Errorf("failed to save schema change: %w", err)
+	}
+
+	if change.HasChanges {
+		s.webhooks.Publish(s.db.Statement.Context, models.WebhookEventSchemaChanged, models.SchemaChangedEvent{
+			ChangeID:        change.ID,
+			DataSourceID:    dataSource.ID,
+			UserID:          dataSource.UserID,
+			Name:            dataSource.Name,
+			Diff:            diff,
+			AffectedQueries: len(affectedQueries),
+			AffectedKPIs:    len(affectedKPIs),
+		})
+	}
+	return newSchemaChangeResponse(change)
+}
+
+// List returns the latest schema changes of a data source, newest first
+func (s *SchemaChangeService) List(dataSourceID uint, limit int) ([]models.SchemaChangeResponse, error) {
+	var changes []models.SchemaChange
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("id DESC").Limit(limit).Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schema changes: %w", err)
+	}
+
+	responses := make([]models.SchemaChangeResponse, 0, len(changes))
+	for i := range changes {
+		response, err := newSchemaChangeResponse(&changes[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// affectedQueries returns the saved queries of the data source whose SQL uses
+// removed tables or columns. Aggregation pipelines are not inspected.
+func (s *SchemaChangeService) affectedQueries(dataSource *models.DataSource, diff *models.SchemaDiff) ([]models.SchemaChangeImpact, error) {
+	dialect := models.DialectForDataSourceType(dataSource.Type)
+	if dialect == models.SQLDialectMongoDB {
+		return nil, nil
+	}
+
+	var queries []models.NL2SQLQuery
+	if err := s.db.Select("id", "nl_query", "generated_sql").
+		Where("data_source_id = ? AND generated_sql <> ''", dataSource.ID).Order("id").Find(&queries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get saved queries: %w", err)
+	}
+
+	var impacts []models.SchemaChangeImpact
+	for _, query := range queries {
+		if refs := removedSchemaReferences(query.GeneratedSQL, dialect, diff, true); len(refs) > 0 {
+			impacts = append(impacts, models.SchemaChangeImpact{ID: query.ID, Name: query.NLQuery, References: refs})
+		}
+	}
+	return impacts, nil
+}
+
+// affectedKPIs returns the active KPIs of the data source owner whose formula
+// uses removed tables or columns. Formulas are often bare expressions, so a
+// column name matches without its table.
+func (s *SchemaChangeService) affectedKPIs(dataSource *models.DataSource, diff *models.SchemaDiff) ([]models.SchemaChangeImpact, error) {
+	dialect := models.DialectForDataSourceType(dataSource.Type)
+	if dialect == models.SQLDialectMongoDB {
+		dialect = models.SQLDialectPostgreSQL
+	}
+
+	var kpis []models.KPIDefinition
+	if err := s.db.Select("id", "name", "formula").
+		Where("user_id = ? AND is_active = ? AND formula <> ''", dataSource.UserID, true).Order("id").Find(&kpis).Error; err != nil {
+		return nil, fmt.Errorf("failed to get KPI definitions: %w", err)
+	}
+
+	var impacts []models.SchemaChangeImpact
+	for _, kpi := range kpis {
+		if refs := removedSchemaReferences(kpi.Formula, dialect, diff, false); len(refs) > 0 {
+			impacts = append(impacts, models.SchemaChangeImpact{ID: kpi.ID, Name: kpi.Name, References: refs})
+		}
+	}
+	return impacts, nil
+}
+
+func newSchemaChangeResponse(change *models.SchemaChange) (*models.SchemaChangeResponse, error) {
+	response := &models.SchemaChangeResponse{
+		ID:           change.ID,
+		DataSourceID: change.DataSourceID,
+		HasChanges:   change.HasChanges,
+		CreatedAt:    change.CreatedAt,
+	}
+	if err := json.Unmarshal(change.Diff, &response.Diff); err != nil {
+		return nil, fmt.Errorf("invalid schema diff: %w", err)
+	}
+	if len(change.AffectedQueries) > 0 {
+		if err := json.Unmarshal(change.AffectedQueries, &response.AffectedQueries); err != nil {
+			return nil, fmt.Errorf("invalid affected queries: %w", err)
+		}
+	}
+	if len(change.AffectedKPIs) > 0 {
+		if err := json.Unmarshal(change.AffectedKPIs, &response.AffectedKPIs); err != nil {
+			return nil, fmt.Errorf("invalid affected KPIs: %w", err)
+		}
+	}
+	return response, nil
+}
+
+// diffSchemas compares two discoveries of the same data source. Tables and
+// columns are matched case-insensitively; results are sorted by name.
+func diffSchemas(before, after []models.Schema) models.SchemaDiff {
+	oldTables := schemaColumnsByTable(before)
+	newTables := schemaColumnsByTable(after)
+
+	diff := models.SchemaDiff{
+		AddedTables:    []string{},
+		RemovedTables:  []string{},
+		AddedColumns:   []models.SchemaColumnChange{},
+		RemovedColumns: []models.SchemaColumnChange{},
+		RetypedColumns: []models.SchemaColumnChange{},
+	}
+
+	for key, oldTable := range oldTables {
+		newTable, ok := newTables[key]
+		if !ok {
+			diff.RemovedTables = append(diff.RemovedTables, oldTable.name)
+			continue
+		}
+		for col, oldCol := range oldTable.columns {
+			newCol, ok := newTable.columns[col]
+			if !ok {
+				diff.RemovedColumns = append(diff.RemovedColumns, models.SchemaColumnChange{Table: newTable.name, Column: oldCol.Name, OldType: oldCol.Type})
+				continue
+			}
+			if !strings.EqualFold(oldCol.Type, newCol.Type) {
+				diff.RetypedColumns = append(diff.RetypedColumns, models.SchemaColumnChange{Table: newTable.name, Column: newCol.Name, OldType: oldCol.Type, NewType: newCol.Type})
+			}
+		}
+		for col, newCol := range newTable.columns {
+			if _, ok := oldTable.columns[col]; !ok {
+				diff.AddedColumns = append(diff.AddedColumns, models.SchemaColumnChange{Table: newTable.name, Column: newCol.Name, NewType: newCol.Type})
+			}
+		}
+	}
+	for key, newTable := range newTables {
+		if _, ok := oldTables[key]; !ok {
+			diff.AddedTables = append(diff.AddedTables, newTable.name)
+		}
+	}
+
+	sort.Strings(diff.AddedTables)
+	sort.Strings(diff.RemovedTables)
+	for _, changes := range [][]models.SchemaColumnChange{diff.AddedColumns, diff.RemovedColumns, diff.RetypedColumns} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].Table != changes[j].Table {
+				return changes[i].Table < changes[j].Table
+			}
+			return changes[i].Column < changes[j].Column
+		})
+	}
+	return diff
+}
+
+type schemaTableColumns struct {
+	name    string
+	columns map[string]models.Column // By lower-case name
+}
+
+// schemaColumnsByTable indexes the columns of schemas by lower-case table name
+func schemaColumnsByTable(schemas []models.Schema) map[string]schemaTableColumns {
+	tables := make(map[string]schemaTableColumns, len(schemas))
+	for _, schema := range schemas {
+		var columns []models.Column
+		if len(schema.Columns) > 0 {
+			_ = json.Unmarshal(schema.Columns, &columns)
+		}
+		table := schemaTableColumns{name: schema.Name, columns: make(map[string]models.Column, len(columns))}
+		for _, col := range columns {
+			table.columns[strings.ToLower(col.Name)] = col
+		}
+		tables[strings.ToLower(schema.Name)] = table
+	}
+	return tables
+}
+
+// removedSchemaReferences returns the removed tables and table.column names
+// that the SQL uses, sorted. With requireTable, a column only matches when its
+// table is queried; otherwise unqualified column names match any table.
+func removedSchemaReferences(sql string, dialect models.SQLDialect, diff *models.SchemaDiff, requireTable bool) []string {
+	tokens, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return nil
+	}
+
+	removedTables := make(map[string]string, len(diff.RemovedTables))
+	for _, table := range diff.RemovedTables {
+		removedTables[strings.ToLower(table)] = table
+	}
+	removedColumns := make(map[string][]models.SchemaColumnChange)
+	for _, col := range diff.RemovedColumns {
+		key := strings.ToLower(col.Column)
+		removedColumns[key] = append(removedColumns[key], col)
+	}
+
+	identifier := func(t sqlToken) (string, bool) {
+		switch t.kind {
+		case sqlTokenWord:
+			return strings.ToLower(t.text), true
+		case sqlTokenQuotedIdent:
+			return strings.ToLower(unquoteIdentifier(t.text)), true
+		}
+		return "", false
+	}
+
+	// Tables the SQL queries, plus qualifiers such as orders in orders.amount
+	tables := make(map[string]bool)
+	for _, table := range referencedTables(tokens) {
+		tables[table] = true
+	}
+	for i := 0; i+2 < len(tokens); i++ {
+		if name, ok := identifier(tokens[i]); ok && tokens[i+1].isSymbol(".") {
+			tables[name] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var refs []string
+	add := func(ref string) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for table := range tables {
+		if name, ok := removedTables[table]; ok {
+			add(name)
+		}
+	}
+	for i, t := range tokens {
+		name, ok := identifier(t)
+		if !ok || (i+1 < len(tokens) && tokens[i+1].isSymbol(".")) {
+			continue
+		}
+		for _, col := range removedColumns[name] {
+			if tables[strings.ToLower(col.Table)] || (!requireTable && len(tables) == 0) {
+				add(col.Table + "." + col.Column)
+			}
+		}
+	}
+
+	sort.Strings(refs)
+	return refs
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchema(name string, columns ...models.Column) models.Schema {
+	columnsJSON, _ := json.Marshal(columns)
+	return models.Schema{Name: name, Columns: models.JSON(columnsJSON)}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	before := []models.Schema{
+		testSchema("orders",
+			models.Column{Name: "id", Type: "integer"},
+			models.Column{Name: "amount", Type: "integer"},
+			models.Column{Name: "coupon", Type: "text"}),
+		testSchema("legacy_events", models.Column{Name: "id", Type: "integer"}),
+	}
+	after := []models.Schema{
+		testSchema("Orders",
+			models.Column{Name: "ID", Type: "INTEGER"},
+			models.Column{Name: "amount", Type: "numeric"},
+			models.Column{Name: "currency", Type: "text"}),
+		testSchema("customers", models.Column{Name: "id", Type: "integer"}),
+	}
+
+	diff := diffSchemas(before, after)
+	assert.Equal(t, []string{"customers"}, diff.AddedTables)
+	assert.Equal(t, []string{"legacy_events"}, diff.RemovedTables)
+	assert.Equal(t, []models.SchemaColumnChange{{Table: "Orders", Column: "currency", NewType: "text"}}, diff.AddedColumns)
+	assert.Equal(t, []models.SchemaColumnChange{{Table: "Orders", Column: "coupon", OldType: "text"}}, diff.RemovedColumns)
+	assert.Equal(t, []models.SchemaColumnChange{{Table: "Orders", Column: "amount", OldType: "integer", NewType: "numeric"}}, diff.RetypedColumns)
+	assert.False(t, diff.Empty())
+
+	unchanged := diffSchemas(before, before)
+	assert.True(t, unchanged.Empty())
+}
+
+func TestRemovedSchemaReferences(t *testing.T) {
+	diff := &models.SchemaDiff{
+		RemovedTables: []string{"legacy_events"},
+		RemovedColumns: []models.SchemaColumnChange{
+			{Table: "orders", Column: "coupon"},
+			{Table: "customers", Column: "segment"},
+		},
+	}
+	pg := models.SQLDialectPostgreSQL
+
+	assert.Equal(t, []string{"orders.coupon"},
+		removedSchemaReferences(`SELECT o.coupon, segment FROM orders o`, pg, diff, true))
+	assert.Equal(t, []string{"customers.segment", "legacy_events"},
+		removedSchemaReferences(`SELECT c.segment FROM customers c JOIN public.legacy_events e ON e.id = c.id`, pg, diff, true))
+	assert.Empty(t, removedSchemaReferences(`SELECT 'coupon' FROM orders`, pg, diff, true))
+
+	// Bare KPI formulas match a removed column of any table
+	assert.Equal(t, []string{"orders.coupon"}, removedSchemaReferences(`COUNT(DISTINCT coupon)`, pg, diff, false))
+	assert.Empty(t, removedSchemaReferences(`COUNT(DISTINCT coupon)`, pg, diff, true))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// SchemaSyncScheduler queues a sync of all data sources every interval. The
+// sync runs as a background job keyed so only one is queued or running across
+// instances; a tick while one is still running does not queue another.
+type SchemaSyncScheduler struct {
+	db       *gorm.DB
+	jobs     *JobService
+	enabled  bool
+	interval time.Duration
+
+	mu      sync.Mutex
+	nextRun *time.Time
+}
+
+// NewSchemaSyncScheduler creates a scheduler queuing syncs every interval when enabled
+func NewSchemaSyncScheduler(db *gorm.DB, jobs *JobService, enabled bool, interval time.Duration) *SchemaSyncScheduler {
+	return &SchemaSyncScheduler{
+		db:       db,
+		jobs:     jobs,
+		enabled:  enabled && interval > 0,
+		interval: interval,
+	}
+}
+
+// Start queues syncs until ctx is done. It does nothing when disabled.
+func (s *SchemaSyncScheduler) Start(ctx context.Context) {
+	if !s.enabled {
+		logger.FromContext(ctx).Info().Msg("Scheduled schema sync disabled")
+		return
+	}
+	logger.FromContext(ctx).Info().Dur("interval", s.interval).Msg("Scheduled schema sync enabled")
+
+	s.setNextRun(time.Now().Add(s.interval))
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				s.mu.Lock()
+				s.nextRun = nil
+				s.mu.Unlock()
+				return
+			case now := <-ticker.C:
+				s.setNextRun(now.Add(s.interval))
+				if err := s.enqueue(ctx); err != nil {
+					logger.FromContext(ctx).Error().Err(err).Msg("Failed to queue scheduled schema sync")
+				}
+			}
+		}
+	}()
+}
+
+// enqueue queues a scheduled sync unless one is already queued or running
+func (s *SchemaSyncScheduler) enqueue(ctx context.Context) error {
+	job, err := s.jobs.EnqueueUnique(ctx, models.JobTypeSchemaSyncAll, models.JobTypeSchemaSyncAll,
+		models.SchemaSyncJobPayload{Trigger: models.SchemaSyncTriggerScheduled})
+	if err != nil {
+		return err
+	}
+	if job.Status == models.JobStatusRunning {
+		logger.FromContext(ctx).Info().Uint("job_id", job.ID).Msg("Previous schema sync still running, not queuing another")
+	}
+	return nil
+}
+
+func (s *SchemaSyncScheduler) setNextRun(next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun = &next
+}
+
+// Schedule reports whether scheduled syncs are enabled, when this instance
+// queues the next one and the latest sync of all data sources
+func (s *SchemaSyncScheduler) Schedule() (*models.SchemaSyncSchedule, error) {
+	schedule := &models.SchemaSyncSchedule{
+		Enabled:         s.enabled,
+		IntervalMinutes: int(s.interval / time.Minute),
+	}
+
+	s.mu.Lock()
+	if s.nextRun != nil {
+		next := *s.nextRun
+		schedule.NextRunAt = &next
+	}
+	s.mu.Unlock()
+
+	var job models.Job
+	err := s.db.Where("type = ?", models.JobTypeSchemaSyncAll).Order("created_at DESC").First(&job).Error
+	if err == nil {
+		schedule.LastRun = &job
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get last schema sync: %w", err)
+	}
+
+	return schedule, nil
+}
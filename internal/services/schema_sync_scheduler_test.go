@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestSchemaSyncScheduler_Disabled(t *testing.T) {
+	// Disabled, or enabled without an interval, never schedules a sync
+	for _, scheduler := range []*SchemaSyncScheduler{
+		NewSchemaSyncScheduler(nil, nil, false, time.Hour),
+		NewSchemaSyncScheduler(nil, nil, true, 0),
+	} {
+		scheduler.Start(context.Background())
+		assert.False(t, scheduler.enabled)
+		assert.Nil(t, scheduler.nextRun)
+	}
+}
+
+func TestSchemaSyncScheduler_NextRun(t *testing.T) {
+	scheduler := NewSchemaSyncScheduler(nil, nil, true, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	before := time.Now()
+	scheduler.Start(ctx)
+	scheduler.mu.Lock()
+	next := scheduler.nextRun
+	scheduler.mu.Unlock()
+	if assert.NotNil(t, next) {
+		assert.WithinDuration(t, before.Add(time.Hour), *next, time.Second)
+	}
+
+	// Stopping clears the next run
+	cancel()
+	assert.Eventually(t, func() bool {
+		scheduler.mu.Lock()
+		defer scheduler.mu.Unlock()
+		return scheduler.nextRun == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSchemaSyncScheduler_Schedule(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.Job{}))
+	scheduler := NewSchemaSyncScheduler(db, NewJobService(db, "test", JobOptions{}), true, 90*time.Minute)
+
+	// Before it is started and before any sync there is neither a next nor a last run
+	schedule, err := scheduler.Schedule()
+	require.NoError(t, err)
+	assert.True(t, schedule.Enabled)
+	assert.Equal(t, 90, schedule.IntervalMinutes)
+	assert.Nil(t, schedule.NextRunAt)
+	assert.Nil(t, schedule.LastRun)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := time.Now()
+	scheduler.Start(ctx)
+
+	// An earlier sync finished, the latest is still queued, and other jobs are not syncs
+	require.NoError(t, scheduler.enqueue(ctx))
+	require.NoError(t, db.Model(&models.Job{}).Where("type = ?", models.JobTypeSchemaSyncAll).
+		Updates(map[string]interface{}{"status": models.JobStatusSucceeded, "created_at": started.Add(-time.Hour)}).Error)
+	require.NoError(t, scheduler.enqueue(ctx))
+	require.NoError(t, db.Create(&models.Job{Type: "report.run", Status: models.JobStatusPending, RunAt: time.Now()}).Error)
+
+	schedule, err = scheduler.Schedule()
+	require.NoError(t, err)
+	if assert.NotNil(t, schedule.NextRunAt) {
+		assert.WithinDuration(t, started.Add(90*time.Minute), *schedule.NextRunAt, time.Second)
+	}
+	require.NotNil(t, schedule.LastRun)
+	assert.Equal(t, models.JobTypeSchemaSyncAll, schedule.LastRun.Type)
+	assert.Equal(t, models.JobStatusPending, schedule.LastRun.Status, "the latest sync is reported")
+	assert.WithinDuration(t, time.Now(), schedule.LastRun.CreatedAt, time.Minute)
+}
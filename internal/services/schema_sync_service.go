@@ -2,19 +2,40 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"narapulse-be/internal/config"
 	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// syncLockTTL bounds how long a crashed instance can hold a data source lock.
+// Running syncs extend their lock every syncLockHeartbeat, so a sync may take
+// longer than the TTL without another instance taking the lock over.
+const (
+	syncLockTTL       = 5 * time.Minute
+	syncLockHeartbeat = syncLockTTL / 5
+)
+
+// ErrSyncInProgress is returned when another instance is already syncing the data source
+var ErrSyncInProgress = errors.New("schema sync already in progress")
+
 // SchemaSyncService handles automatic synchronization of schema embeddings
 type SchemaSyncService struct {
 	db               *gorm.DB
 	ragService       *RAGService
 	embeddingService *EmbeddingService
+	instanceID       string
+	syncingAll       *atomic.Bool // A sync of all data sources is running on this instance; shared by scoped copies
 }
 
 // NewSchemaSyncService creates a new schema sync service
@@ -23,19 +44,87 @@ func NewSchemaSyncService(db *gorm.DB, ragService *RAGService, embeddingService
 		db:               db,
 		ragService:       ragService,
 		embeddingService: embeddingService,
+		instanceID:       resolveInstanceID(config.Load().InstanceID),
+		syncingAll:       new(atomic.Bool),
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *SchemaSyncService) WithContext(ctx context.Context) *SchemaSyncService {
+	if s == nil {
+		return nil
 	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.ragService = s.ragService.WithContext(ctx)
+	scoped.embeddingService = s.embeddingService.WithContext(ctx)
+	return &scoped
+}
+
+// resolveInstanceID returns the configured instance ID or derives one from hostname and PID
+func resolveInstanceID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// InstanceID returns the identifier this instance records in locks and sync history
+func (s *SchemaSyncService) InstanceID() string {
+	return s.instanceID
+}
+
+// RegisterJobs registers the jobs that sync one data source (queued after
+// schema discovery) and all data sources (manual, cron or scheduled triggers)
+func (s *SchemaSyncService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeSchemaSync, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.DataSourceJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		return s.WithContext(ctx).AutoSyncOnSchemaChange(ctx, p.DataSourceID)
+	})
+	jobs.Register(models.JobTypeSchemaSyncAll, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.SchemaSyncJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		if p.Trigger == "" || p.Trigger == models.SchemaSyncTriggerScheduled {
+			return s.WithContext(ctx).ScheduledSync(ctx)
+		}
+		return s.WithContext(ctx).syncAllDataSources(ctx, p.Trigger)
+	})
 }
 
 // SyncAllDataSources synchronizes embeddings for all active data sources
 func (s *SchemaSyncService) SyncAllDataSources(ctx context.Context) error {
+	return s.syncAllDataSources(ctx, models.SchemaSyncTriggerManual)
+}
+
+// syncAllDataSources syncs every active data source, skipping ones locked by other instances
+func (s *SchemaSyncService) syncAllDataSources(ctx context.Context, trigger models.SchemaSyncTrigger) error {
+	if !s.syncingAll.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: all data sources are being synced on this instance", ErrSyncInProgress)
+	}
+	defer s.syncingAll.Store(false)
+
 	var dataSources []models.DataSource
-	if err := s.db.Where("is_active = ?", true).Find(&dataSources).Error; err != nil {
+	if err := s.db.Where("status = ?", models.ConnectionStatusActive).Find(&dataSources).Error; err != nil {
 		return fmt.Errorf("failed to get active data sources: %w", err)
 	}
 
 	for _, dataSource := range dataSources {
-		if err := s.SyncDataSource(ctx, dataSource.ID); err != nil {
-			log.Printf("Failed to sync data source %d: %v", dataSource.ID, err)
+		if err := s.syncDataSource(ctx, dataSource.ID, trigger); err != nil {
+			if errors.Is(err, ErrSyncInProgress) {
+				logger.FromContext(ctx).Info().Err(err).Uint("data_source_id", dataSource.ID).Msg("Skipping data source")
+				continue
+			}
+			logger.FromContext(ctx).Error().Err(err).Uint("data_source_id", dataSource.ID).Msg("Failed to sync data source")
 			// Continue with other data sources even if one fails
 			continue
 		}
@@ -46,111 +135,360 @@ func (s *SchemaSyncService) SyncAllDataSources(ctx context.Context) error {
 
 // SyncDataSource synchronizes embeddings for a specific data source
 func (s *SchemaSyncService) SyncDataSource(ctx context.Context, dataSourceID uint) error {
+	return s.syncDataSource(ctx, dataSourceID, models.SchemaSyncTriggerManual)
+}
+
+// syncDataSource runs a sync under the data source lock and records it in the sync history
+func (s *SchemaSyncService) syncDataSource(ctx context.Context, dataSourceID uint, trigger models.SchemaSyncTrigger) error {
 	// Get the data source
 	var dataSource models.DataSource
 	if err := s.db.First(&dataSource, dataSourceID).Error; err != nil {
 		return fmt.Errorf("data source not found: %w", err)
 	}
 
+	run := &models.SchemaSyncRun{
+		TenantID:     dataSource.TenantID,
+		SyncID:       uuid.NewString(),
+		DataSourceID: dataSourceID,
+		InstanceID:   s.instanceID,
+		TriggeredBy:  trigger,
+		Status:       models.SchemaSyncStatusRunning,
+		StartedAt:    time.Now(),
+	}
+
+	// Take the per data source lock so other instances skip this data source
+	acquired, holder, err := s.acquireLock(dataSourceID, run.SyncID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire sync lock: %w", err)
+	}
+	if !acquired {
+		run.Finish(models.SchemaSyncStatusSkipped, fmt.Sprintf("locked by instance %s", holder))
+		s.recordRun(run)
+		return fmt.Errorf("%w: data source %d is locked by instance %s", ErrSyncInProgress, dataSourceID, holder)
+	}
+	ctx, release := s.holdLock(ctx, dataSourceID, run.SyncID)
+	defer release()
+
+	if err := s.db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to record sync run: %w", err)
+	}
+
+	if err := s.performSync(ctx, &dataSource, run); err != nil {
+		run.Finish(models.SchemaSyncStatusFailed, err.Error())
+		s.recordRun(run)
+		return err
+	}
+
+	s.recordRun(run)
+	return nil
+}
+
+// performSync does the actual embedding refresh while the lock is held
+func (s *SchemaSyncService) performSync(ctx context.Context, dataSource *models.DataSource, run *models.SchemaSyncRun) error {
+	dataSourceID := dataSource.ID
+
 	// Check if sync is needed
-	needSync, err := s.checkSyncNeeded(dataSourceID)
+	check, err := s.checkSyncNeeded(dataSourceID)
 	if err != nil {
 		return fmt.Errorf("failed to check sync status: %w", err)
 	}
 
-	if !needSync {
-		log.Printf("Data source %d is already up to date", dataSourceID)
+	if check.reason == "" {
+		logger.FromContext(ctx).Info().Uint("data_source_id", dataSourceID).Msg("Data source is already up to date")
+		run.Finish(models.SchemaSyncStatusSkipped, "already up to date")
 		return nil
 	}
 
 	// Perform synchronization
-	log.Printf("Starting sync for data source %d (%s)", dataSourceID, dataSource.Name)
+	logger.FromContext(ctx).Info().Str("sync_id", run.SyncID).Uint("data_source_id", dataSourceID).Str("data_source", dataSource.Name).Str("instance", s.instanceID).Str("reason", check.reason).Msg("Starting schema sync")
+
+	if check.schemasStale {
+		// Re-embed changed elements, saving progress after every schema. The
+		// embeddings and the AI usage belong to the tenant of the data source.
+		tenantCtx := tenancy.WithTenant(ctx, dataSource.TenantID)
+		progress, err := s.ragService.WithContext(tenantCtx).SyncSchemaEmbeddingsWithProgress(tenantCtx, dataSourceID, func(progress models.SchemaSyncProgress) {
+			run.Progress = progress
+			s.recordRun(run)
+		})
+		run.Progress = progress
+		if err != nil {
+			return fmt.Errorf("failed to sync embeddings: %w", err)
+		}
+		if err := s.saveSyncState(dataSourceID, check.schemas, run.SyncID); err != nil {
+			return err
+		}
+	}
+
+	if check.knowledgeStale {
+		if err := s.syncKnowledge(ctx, run, check.knowledge); err != nil {
+			return err
+		}
+	}
+
+	run.Finish(models.SchemaSyncStatusCompleted, check.reason)
+	logger.FromContext(ctx).Info().Str("sync_id", run.SyncID).Uint("data_source_id", dataSourceID).
+		Int("embedded", run.Progress.ElementsEmbedded).Int("unchanged", run.Progress.ElementsUnchanged).Int("removed", run.Progress.ElementsRemoved).
+		Msg("Schema sync completed")
+	return nil
+}
+
+// syncKnowledge re-embeds changed KPIs and glossary terms under their own
+// lock, as every data source shares them. It is left to the other instance
+// when one already holds the lock.
+func (s *SchemaSyncService) syncKnowledge(ctx context.Context, run *models.SchemaSyncRun, knowledge models.SyncSourceVersion) error {
+	acquired, holder, err := s.acquireLock(models.KnowledgeSyncStateID, run.SyncID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire KPI and glossary sync lock: %w", err)
+	}
+	if !acquired {
+		logger.FromContext(ctx).Info().Str("sync_id", run.SyncID).Str("holder", holder).Msg("KPIs and glossary are being synced by another instance")
+		return nil
+	}
+	ctx, release := s.holdLock(ctx, models.KnowledgeSyncStateID, run.SyncID)
+	defer release()
 
-	// Remove old embeddings
-	if err := s.removeOldEmbeddings(dataSourceID); err != nil {
-		return fmt.Errorf("failed to remove old embeddings: %w", err)
+	// The sync state covers the KPIs and glossary terms of every tenant, so
+	// they are synced outside of the tenant of the data source
+	ctx = tenancy.WithTenant(ctx, 0)
+	progress, err := s.embeddingService.WithContext(ctx).SyncKnowledge(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync KPI and glossary embeddings: %w", err)
 	}
+	run.Progress.Add(progress)
+	return s.saveSyncState(models.KnowledgeSyncStateID, knowledge, run.SyncID)
+}
 
-	// Generate new embeddings
-	if err := s.ragService.SyncSchemaEmbeddings(ctx, dataSourceID); err != nil {
-		return fmt.Errorf("failed to generate new embeddings: %w", err)
+// acquireLock takes the sync lock for a data source if it is free or expired.
+// It returns the instance currently holding the lock when it cannot be taken.
+func (s *SchemaSyncService) acquireLock(dataSourceID uint, syncID string) (bool, string, error) {
+	now := time.Now()
+	result := s.db.Exec(`
+		INSERT INTO schema_sync_locks (data_source_id, sync_id, instance_id, locked_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (data_source_id) DO UPDATE
+		SET sync_id = EXCLUDED.sync_id,
+			instance_id = EXCLUDED.instance_id,
+			locked_at = EXCLUDED.locked_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE schema_sync_locks.expires_at < ?`,
+		dataSourceID, syncID, s.instanceID, now, now.Add(syncLockTTL), now)
+	if result.Error != nil {
+		return false, "", result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, s.instanceID, nil
 	}
 
-	// Update sync timestamp
-	if err := s.updateSyncTimestamp(dataSourceID); err != nil {
-		return fmt.Errorf("failed to update sync timestamp: %w", err)
+	var lock models.SchemaSyncLock
+	if err := s.db.Where("data_source_id = ?", dataSourceID).First(&lock).Error; err != nil {
+		return false, "", fmt.Errorf("failed to read sync lock: %w", err)
 	}
+	return false, lock.InstanceID, nil
+}
 
-	log.Printf("Successfully synced data source %d", dataSourceID)
-	return nil
+// holdLock extends a lock taken by acquireLock every syncLockHeartbeat while
+// its sync runs. The returned context is cancelled when the lock was lost,
+// e.g. after the database was unreachable for longer than the TTL and another
+// instance took it over, so the sync stops instead of overlapping with theirs.
+// release stops the heartbeat and releases the lock.
+func (s *SchemaSyncService) holdLock(ctx context.Context, dataSourceID uint, syncID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(syncLockHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := s.renewLock(dataSourceID, syncID)
+				if err != nil {
+					// The lock stays ours until it expires; the next beat retries
+					logger.FromContext(ctx).Warn().Err(err).Uint("data_source_id", dataSourceID).Str("sync_id", syncID).Msg("Failed to extend sync lock")
+					continue
+				}
+				if !renewed {
+					logger.FromContext(ctx).Error().Uint("data_source_id", dataSourceID).Str("sync_id", syncID).Msg("Sync lock lost, stopping the sync")
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		cancel()
+		<-done
+		s.releaseLock(dataSourceID, syncID)
+	}
+}
+
+// renewLock extends the lock of a sync run. It reports false when the run no
+// longer holds the lock.
+func (s *SchemaSyncService) renewLock(dataSourceID uint, syncID string) (bool, error) {
+	result := s.db.Model(&models.SchemaSyncLock{}).
+		Where("data_source_id = ? AND sync_id = ?", dataSourceID, syncID).
+		Update("expires_at", time.Now().Add(syncLockTTL))
+	return result.RowsAffected > 0, result.Error
+}
+
+// releaseLock releases the lock only if it is still owned by the given sync run
+func (s *SchemaSyncService) releaseLock(dataSourceID uint, syncID string) {
+	if err := s.db.Where("data_source_id = ? AND sync_id = ?", dataSourceID, syncID).
+		Delete(&models.SchemaSyncLock{}).Error; err != nil {
+		logger.L().Error().Err(err).Uint("data_source_id", dataSourceID).Str("sync_id", syncID).Msg("Failed to release sync lock")
+	}
+}
+
+// recordRun persists the current state of a sync run
+func (s *SchemaSyncService) recordRun(run *models.SchemaSyncRun) {
+	if err := s.db.Save(run).Error; err != nil {
+		logger.L().Error().Err(err).Str("sync_id", run.SyncID).Msg("Failed to record sync run")
+	}
+}
+
+// GetSyncHistory returns recent sync runs of the user's data sources,
+// optionally filtered by data source
+func (s *SchemaSyncService) GetSyncHistory(userID, dataSourceID uint, limit int) ([]models.SchemaSyncRun, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	owned := s.db.Model(&models.DataSource{}).Select("id").Where("user_id = ?", userID)
+	query := s.db.Model(&models.SchemaSyncRun{}).Where("data_source_id IN (?)", owned).Order("started_at DESC").Limit(limit)
+	if dataSourceID > 0 {
+		query = query.Where("data_source_id = ?", dataSourceID)
+	}
+
+	var runs []models.SchemaSyncRun
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sync history: %w", err)
+	}
+
+	return runs, nil
+}
+
+// syncCheck is what checkSyncNeeded found for a data source
+type syncCheck struct {
+	reason         string // Why a sync is needed; empty when up to date
+	schemas        models.SyncSourceVersion
+	knowledge      models.SyncSourceVersion
+	schemasStale   bool // The schema embeddings need a sync
+	knowledgeStale bool // The KPI and glossary embeddings need a sync
 }
 
 // checkSyncNeeded determines if synchronization is needed for a data source
-func (s *SchemaSyncService) checkSyncNeeded(dataSourceID uint) (bool, error) {
-	// Get the latest schema update time
-	var latestSchemaUpdate time.Time
-	if err := s.db.Model(&models.Schema{}).
-		Where("data_source_id = ?", dataSourceID).
-		Select("MAX(updated_at)").
-		Scan(&latestSchemaUpdate).Error; err != nil {
-		return false, fmt.Errorf("failed to get latest schema update: %w", err)
+// by comparing its schemas, and the KPIs and glossary terms, with the sync
+// states of the last completed syncs
+func (s *SchemaSyncService) checkSyncNeeded(dataSourceID uint) (syncCheck, error) {
+	var check syncCheck
+
+	// The versions are compared with the sync states of the installation, so
+	// they are read outside of the tenant of the context
+	installation := s.db.WithContext(tenancy.WithTenant(s.db.Statement.Context, 0))
+
+	// Deleted rows count as changes, so the latest deletion is part of the version
+	if err := installation.Raw(`SELECT COUNT(*) FILTER (WHERE is_active AND deleted_at IS NULL) AS count,
+		MAX(GREATEST(updated_at, deleted_at)) AS version
+		FROM schemas WHERE data_source_id = ?`, dataSourceID).Scan(&check.schemas).Error; err != nil {
+		return check, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if err := installation.Raw(`SELECT
+		(SELECT COUNT(*) FROM kpi_definitions WHERE is_active AND deleted_at IS NULL) +
+		(SELECT COUNT(*) FROM business_glossaries WHERE is_active AND deleted_at IS NULL) AS count,
+		GREATEST(
+			(SELECT MAX(GREATEST(updated_at, deleted_at)) FROM kpi_definitions),
+			(SELECT MAX(GREATEST(updated_at, deleted_at)) FROM business_glossaries)) AS version`).Scan(&check.knowledge).Error; err != nil {
+		return check, fmt.Errorf("failed to get KPI and glossary version: %w", err)
 	}
 
-	// Get the latest embedding sync time
-	var latestEmbeddingSync time.Time
+	var tableEmbeddings int64
 	if err := s.db.Model(&models.SchemaEmbedding{}).
-		Where("data_source_id = ?", dataSourceID).
-		Select("MAX(updated_at)").
-		Scan(&latestEmbeddingSync).Error; err != nil {
-		// If no embeddings exist, sync is needed
-		if err == gorm.ErrRecordNotFound {
-			return true, nil
+		Where("data_source_id = ? AND element_type = ?", dataSourceID, "table").
+		Count(&tableEmbeddings).Error; err != nil {
+		return check, fmt.Errorf("failed to count embeddings: %w", err)
+	}
+
+	var states []models.SchemaSyncState
+	if err := s.db.Where("data_source_id IN ?", []uint{dataSourceID, models.KnowledgeSyncStateID}).Find(&states).Error; err != nil {
+		return check, fmt.Errorf("failed to get sync states: %w", err)
+	}
+	var state, knowledgeState *models.SchemaSyncState
+	for i := range states {
+		if states[i].DataSourceID == models.KnowledgeSyncStateID {
+			knowledgeState = &states[i]
+		} else {
+			state = &states[i]
 		}
-		return false, fmt.Errorf("failed to get latest embedding sync: %w", err)
 	}
 
-	// Sync is needed if schema was updated after last embedding sync
-	return latestSchemaUpdate.After(latestEmbeddingSync), nil
+	check.evaluate(state, tableEmbeddings, knowledgeState)
+	return check, nil
 }
 
-// removeOldEmbeddings removes existing embeddings for a data source
-func (s *SchemaSyncService) removeOldEmbeddings(dataSourceID uint) error {
-	result := s.db.Where("data_source_id = ?", dataSourceID).Delete(&models.SchemaEmbedding{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete old embeddings: %w", result.Error)
+// evaluate decides what needs a sync from the states of the last completed
+// syncs, which are nil before the first one
+func (c *syncCheck) evaluate(state *models.SchemaSyncState, tableEmbeddings int64, knowledgeState *models.SchemaSyncState) {
+	switch {
+	case state == nil:
+		c.reason, c.schemasStale = models.SyncReasonNeverSynced, true
+	case c.schemas.Count > 0 && tableEmbeddings == 0:
+		c.reason, c.schemasStale = models.SyncReasonNoEmbeddings, true
+	case c.schemas.Changed(state):
+		c.reason, c.schemasStale = models.SyncReasonSchemaChanged, true
 	}
 
-	log.Printf("Removed %d old embeddings for data source %d", result.RowsAffected, dataSourceID)
-	return nil
+	if knowledgeState == nil {
+		c.knowledgeStale = c.knowledge.Count > 0
+	} else {
+		c.knowledgeStale = c.knowledge.Changed(knowledgeState)
+	}
+	if c.knowledgeStale && c.reason == "" {
+		c.reason = models.SyncReasonKnowledgeChanged
+	}
 }
 
-// updateSyncTimestamp updates the sync timestamp for tracking
-func (s *SchemaSyncService) updateSyncTimestamp(dataSourceID uint) error {
-	// Update the data source's updated_at timestamp to track sync
-	return s.db.Model(&models.DataSource{}).
-		Where("id = ?", dataSourceID).
-		Update("updated_at", time.Now()).Error
+// saveSyncState records the sources a completed sync embedded
+func (s *SchemaSyncService) saveSyncState(dataSourceID uint, version models.SyncSourceVersion, syncID string) error {
+	state := &models.SchemaSyncState{
+		DataSourceID: dataSourceID,
+		SourceCount:  version.Count,
+		SyncID:       syncID,
+		SyncedAt:     time.Now(),
+	}
+	if version.Version != nil {
+		state.SourceVersion = *version.Version
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "data_source_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source_version", "source_count", "sync_id", "synced_at", "updated_at"}),
+	}).Create(state).Error; err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
 }
 
 // ScheduledSync performs scheduled synchronization (can be called by cron job)
 func (s *SchemaSyncService) ScheduledSync(ctx context.Context) error {
-	log.Println("Starting scheduled schema synchronization")
+	logger.FromContext(ctx).Info().Str("instance", s.instanceID).Msg("Starting scheduled schema synchronization")
 	start := time.Now()
 
-	if err := s.SyncAllDataSources(ctx); err != nil {
-		log.Printf("Scheduled sync failed: %v", err)
+	if err := s.syncAllDataSources(ctx, models.SchemaSyncTriggerScheduled); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Scheduled sync failed")
 		return err
 	}
 
 	duration := time.Since(start)
-	log.Printf("Scheduled sync completed in %v", duration)
+	logger.FromContext(ctx).Info().Dur("duration", duration).Msg("Scheduled sync completed")
 	return nil
 }
 
 // GetSyncStatus returns the synchronization status for all data sources
 func (s *SchemaSyncService) GetSyncStatus() ([]SyncStatusInfo, error) {
 	var dataSources []models.DataSource
-	if err := s.db.Where("is_active = ?", true).Find(&dataSources).Error; err != nil {
+	if err := s.db.Where("status = ?", models.ConnectionStatusActive).Find(&dataSources).Error; err != nil {
 		return nil, fmt.Errorf("failed to get data sources: %w", err)
 	}
 
@@ -158,7 +496,7 @@ func (s *SchemaSyncService) GetSyncStatus() ([]SyncStatusInfo, error) {
 	for _, ds := range dataSources {
 		status, err := s.getDataSourceSyncStatus(ds.ID)
 		if err != nil {
-			log.Printf("Failed to get sync status for data source %d: %v", ds.ID, err)
+			logger.L().Error().Err(err).Uint("data_source_id", ds.ID).Msg("Failed to get sync status")
 			continue
 		}
 		status.DataSourceName = ds.Name
@@ -189,19 +527,31 @@ func (s *SchemaSyncService) getDataSourceSyncStatus(dataSourceID uint) (SyncStat
 	}
 
 	// Get last sync time
-	if err := s.db.Model(&models.SchemaEmbedding{}).
-		Where("data_source_id = ?", dataSourceID).
-		Select("MAX(updated_at)").
-		Scan(&status.LastSyncTime).Error; err != nil && err != gorm.ErrRecordNotFound {
+	var state models.SchemaSyncState
+	if err := s.db.Where("data_source_id = ?", dataSourceID).First(&state).Error; err == nil {
+		status.LastSyncTime = state.SyncedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return status, fmt.Errorf("failed to get last sync time: %w", err)
 	}
 
 	// Check if sync is needed
-	needSync, err := s.checkSyncNeeded(dataSourceID)
+	check, err := s.checkSyncNeeded(dataSourceID)
 	if err != nil {
 		return status, fmt.Errorf("failed to check sync status: %w", err)
 	}
-	status.NeedSync = needSync
+	status.NeedSync = check.reason != ""
+	status.SyncReason = check.reason
+
+	// Progress of the running sync, or of the last one
+	var run models.SchemaSyncRun
+	err = s.db.Where("data_source_id = ? AND status <> ?", dataSourceID, models.SchemaSyncStatusSkipped).
+		Order("started_at DESC").First(&run).Error
+	if err == nil {
+		status.LastSyncStatus = run.Status
+		status.Progress = &run.Progress
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return status, fmt.Errorf("failed to get last sync run: %w", err)
+	}
 
 	return status, nil
 }
@@ -214,11 +564,14 @@ type SyncStatusInfo struct {
 	EmbeddingCount int64     `json:"embedding_count"`
 	LastSyncTime   time.Time `json:"last_sync_time"`
 	NeedSync       bool      `json:"need_sync"`
+	SyncReason     string    `json:"sync_reason,omitempty"` // never_synced, no_embeddings, schema_changed or knowledge_changed
+	LastSyncStatus models.SchemaSyncStatus    `json:"last_sync_status,omitempty"` // running while a sync is in progress
+	Progress       *models.SchemaSyncProgress `json:"progress,omitempty"`         // Of the running sync, or of the last one
 }
 
 // TriggerSync manually triggers synchronization for a data source
 func (s *SchemaSyncService) TriggerSync(ctx context.Context, dataSourceID uint) error {
-	log.Printf("Manual sync triggered for data source %d", dataSourceID)
+	logger.FromContext(ctx).Info().Uint("data_source_id", dataSourceID).Msg("Manual sync triggered")
 	return s.SyncDataSource(ctx, dataSourceID)
 }
 
@@ -226,6 +579,6 @@ func (s *SchemaSyncService) TriggerSync(ctx context.Context, dataSourceID uint)
 func (s *SchemaSyncService) AutoSyncOnSchemaChange(ctx context.Context, dataSourceID uint) error {
 	// This method can be called from schema inference service or data source handlers
 	// when schema changes are detected
-	log.Printf("Auto sync triggered for data source %d due to schema change", dataSourceID)
-	return s.SyncDataSource(ctx, dataSourceID)
+	logger.FromContext(ctx).Info().Uint("data_source_id", dataSourceID).Msg("Auto sync triggered due to schema change")
+	return s.syncDataSource(ctx, dataSourceID, models.SchemaSyncTriggerAuto)
 }
\ No newline at end of file
@@ -2,10 +2,19 @@ package services
 
 import (
 	"context"
+	"net/http"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 func TestSchemaSyncService_Validation(t *testing.T) {
@@ -32,15 +41,90 @@ func TestSchemaSyncService_CheckSyncNeeded(t *testing.T) {
 	})
 }
 
-func TestSchemaSyncService_UpdateSyncTimestamp(t *testing.T) {
+func TestSchemaSyncService_SaveSyncState(t *testing.T) {
 	service := &SchemaSyncService{}
 
-	// Test updating sync timestamp with nil db should panic
+	// Test saving the sync state with nil db should panic
 	assert.Panics(t, func() {
-		service.updateSyncTimestamp(999)
+		service.saveSyncState(999, models.SyncSourceVersion{}, "sync-1")
 	})
 }
 
+func TestSyncCheck_Evaluate(t *testing.T) {
+	synced := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	later := synced.Add(time.Hour)
+	state := &models.SchemaSyncState{DataSourceID: 4, SourceCount: 3, SourceVersion: synced}
+	knowledgeState := &models.SchemaSyncState{SourceCount: 2, SourceVersion: synced}
+
+	tests := []struct {
+		name           string
+		check          syncCheck
+		state          *models.SchemaSyncState
+		embeddings     int64
+		knowledgeState *models.SchemaSyncState
+		reason         string
+		schemasStale   bool
+		knowledgeStale bool
+	}{
+		{
+			name:   "new data source without schemas",
+			check:  syncCheck{},
+			reason: models.SyncReasonNeverSynced, schemasStale: true,
+		},
+		{
+			name:  "up to date",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 3, Version: &synced}, knowledge: models.SyncSourceVersion{Count: 2, Version: &synced}},
+			state: state, embeddings: 3, knowledgeState: knowledgeState,
+		},
+		{
+			name:  "embeddings deleted",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 3, Version: &synced}, knowledge: models.SyncSourceVersion{Count: 2, Version: &synced}},
+			state: state, knowledgeState: knowledgeState,
+			reason: models.SyncReasonNoEmbeddings, schemasStale: true,
+		},
+		{
+			name:  "schema edited",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 3, Version: &later}, knowledge: models.SyncSourceVersion{Count: 2, Version: &synced}},
+			state: state, embeddings: 3, knowledgeState: knowledgeState,
+			reason: models.SyncReasonSchemaChanged, schemasStale: true,
+		},
+		{
+			name:  "schema deactivated",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 2, Version: &synced}, knowledge: models.SyncSourceVersion{Count: 2, Version: &synced}},
+			state: state, embeddings: 3, knowledgeState: knowledgeState,
+			reason: models.SyncReasonSchemaChanged, schemasStale: true,
+		},
+		{
+			name:  "glossary term edited",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 3, Version: &synced}, knowledge: models.SyncSourceVersion{Count: 2, Version: &later}},
+			state: state, embeddings: 3, knowledgeState: knowledgeState,
+			reason: models.SyncReasonKnowledgeChanged, knowledgeStale: true,
+		},
+		{
+			name:  "KPIs never synced",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 3, Version: &synced}, knowledge: models.SyncSourceVersion{Count: 1, Version: &synced}},
+			state: state, embeddings: 3,
+			reason: models.SyncReasonKnowledgeChanged, knowledgeStale: true,
+		},
+		{
+			name:  "schema and KPI changed",
+			check: syncCheck{schemas: models.SyncSourceVersion{Count: 4, Version: &later}, knowledge: models.SyncSourceVersion{Count: 1, Version: &later}},
+			state: state, embeddings: 3, knowledgeState: knowledgeState,
+			reason: models.SyncReasonSchemaChanged, schemasStale: true, knowledgeStale: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			check.evaluate(tt.state, tt.embeddings, tt.knowledgeState)
+			assert.Equal(t, tt.reason, check.reason)
+			assert.Equal(t, tt.schemasStale, check.schemasStale)
+			assert.Equal(t, tt.knowledgeStale, check.knowledgeStale)
+		})
+	}
+}
+
 func TestSchemaSyncService_ScheduledSync(t *testing.T) {
 	service := &SchemaSyncService{}
 
@@ -53,6 +137,22 @@ func TestSchemaSyncService_ScheduledSync(t *testing.T) {
 	})
 }
 
+func TestSchemaSyncService_ScheduledSyncOverlap(t *testing.T) {
+	service := &SchemaSyncService{syncingAll: new(atomic.Bool)}
+
+	// A sync of all data sources already running on this instance is not overlapped
+	service.syncingAll.Store(true)
+	err := service.ScheduledSync(context.Background())
+	assert.ErrorIs(t, err, ErrSyncInProgress)
+
+	// The guard is released when a sync ends, even by panicking
+	service.syncingAll.Store(false)
+	assert.Panics(t, func() {
+		service.ScheduledSync(context.Background())
+	})
+	assert.False(t, service.syncingAll.Load())
+}
+
 func TestSchemaSyncService_TriggerSync(t *testing.T) {
 	service := &SchemaSyncService{}
 
@@ -61,4 +161,177 @@ func TestSchemaSyncService_TriggerSync(t *testing.T) {
 	assert.Panics(t, func() {
 		service.TriggerSync(ctx, 999)
 	})
-}
\ No newline at end of file
+}
+
+func TestSchemaSyncService_ResolveInstanceID(t *testing.T) {
+	// Configured instance ID is used as-is
+	assert.Equal(t, "worker-1", resolveInstanceID("worker-1"))
+
+	// Falls back to a generated hostname-pid identifier
+	assert.NotEmpty(t, resolveInstanceID(""))
+}
+
+// newSchemaSyncLockTestServices returns two services sharing a database, as
+// two instances would
+func newSchemaSyncLockTestServices(t *testing.T) (*SchemaSyncService, *SchemaSyncService) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.SchemaSyncLock{}))
+	return &SchemaSyncService{db: db, instanceID: "instance-a"}, &SchemaSyncService{db: db, instanceID: "instance-b"}
+}
+
+func TestSchemaSyncService_Lock(t *testing.T) {
+	a, b := newSchemaSyncLockTestServices(t)
+
+	acquired, holder, err := a.acquireLock(7, "sync-a")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "instance-a", holder)
+
+	// Another instance skips the data source while the lock is held
+	acquired, holder, err = b.acquireLock(7, "sync-b")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "instance-a", holder)
+
+	// Other data sources are locked separately
+	acquired, _, err = b.acquireLock(8, "sync-b")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Releasing a lock held by another run does nothing
+	b.releaseLock(7, "sync-b")
+	acquired, _, err = b.acquireLock(7, "sync-b")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	a.releaseLock(7, "sync-a")
+	acquired, holder, err = b.acquireLock(7, "sync-b")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "instance-b", holder)
+}
+
+func TestSchemaSyncService_LockExpiry(t *testing.T) {
+	a, b := newSchemaSyncLockTestServices(t)
+
+	acquired, _, err := a.acquireLock(7, "sync-a")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// A renewed lease stays held past the TTL it was taken with
+	renewed, err := a.renewLock(7, "sync-a")
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	var lock models.SchemaSyncLock
+	require.NoError(t, a.db.First(&lock, 7).Error)
+	assert.WithinDuration(t, time.Now().Add(syncLockTTL), lock.ExpiresAt, time.Minute)
+
+	// An expired lock, e.g. of a crashed instance, is taken over
+	require.NoError(t, a.db.Model(&lock).Update("expires_at", time.Now().Add(-time.Second)).Error)
+	acquired, holder, err := b.acquireLock(7, "sync-b")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "instance-b", holder)
+
+	// and the previous holder can no longer renew it
+	renewed, err = a.renewLock(7, "sync-a")
+	require.NoError(t, err)
+	assert.False(t, renewed)
+}
+
+func TestSchemaSyncService_HoldLockReleases(t *testing.T) {
+	a, b := newSchemaSyncLockTestServices(t)
+
+	acquired, _, err := a.acquireLock(7, "sync-a")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	ctx, release := a.holdLock(context.Background(), 7, "sync-a")
+	assert.NoError(t, ctx.Err())
+	release()
+	assert.Error(t, ctx.Err(), "the sync context ends with the lock")
+
+	acquired, _, err = b.acquireLock(7, "sync-b")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestSchemaSyncWritesRowsOfDataSourceTenant(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.DataSource{}, &models.Schema{}, &models.SchemaEmbedding{}, &models.KPIDefinition{}, &models.BusinessGlossary{}, &models.UsageRecord{}))
+	// SQLite cannot store the vectors, so embeddings are stored without them
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:omit_vectors", func(tx *gorm.DB) {
+		if tx.Statement.Table == "schema_embeddings" {
+			tx.Statement.Omits = append(tx.Statement.Omits, "embedding")
+		}
+	}))
+
+	globex := tenancy.WithTenant(context.Background(), 2)
+	dataSource := &models.DataSource{UserID: 5, Name: "warehouse", Type: models.DataSourceTypePostgreSQL, Status: models.ConnectionStatusActive}
+	require.NoError(t, db.WithContext(globex).Create(dataSource).Error)
+	require.NoError(t, db.Create(&models.Schema{DataSourceID: dataSource.ID, Name: "orders", Columns: models.JSON(`[]`), IsActive: true}).Error)
+	require.NoError(t, db.WithContext(globex).Create(&models.KPIDefinition{UserID: 5, Name: "revenue", Formula: "SUM(amount)", IsActive: true}).Error)
+
+	embeddings := NewEmbeddingService(db, "test-key", "", NewUsageService(db, UsagePricing{}, models.UsageQuotaRequest{}))
+	embeddings.client = &http.Client{Transport: embeddingRoundTripper{}}
+	rag := NewRAGService(db, embeddings, NewRerankService("", "", "", 0))
+
+	// Background syncs run without a tenant in their context
+	_, err = rag.SyncSchemaEmbeddingsWithProgress(context.Background(), dataSource.ID, nil)
+	require.NoError(t, err)
+	_, err = embeddings.SyncKnowledge(context.Background())
+	require.NoError(t, err)
+
+	var stored []models.SchemaEmbedding
+	require.NoError(t, db.Order("id").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	for _, embedding := range stored {
+		assert.Equal(t, uint(2), embedding.TenantID, "%s embedding", embedding.ElementType)
+	}
+	var usage []models.UsageRecord
+	require.NoError(t, db.Find(&usage).Error)
+	require.Len(t, usage, 2)
+	for _, record := range usage {
+		assert.Equal(t, uint(2), record.TenantID, "the usage is charged to the tenant of the data source")
+	}
+
+	// The tenant sees its embeddings
+	var count int64
+	require.NoError(t, db.WithContext(globex).Model(&models.SchemaEmbedding{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestSchemaSyncHistoryOnlyListsRunsOfOwnDataSources(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.DataSource{}, &models.SchemaSyncRun{}))
+
+	own := &models.DataSource{UserID: 1, TenantID: 1, Name: "warehouse", Type: models.DataSourceTypePostgreSQL}
+	colleague := &models.DataSource{UserID: 2, TenantID: 1, Name: "crm", Type: models.DataSourceTypePostgreSQL}
+	otherTenant := &models.DataSource{UserID: 1, TenantID: 2, Name: "ledger", Type: models.DataSourceTypePostgreSQL}
+	for i, dataSource := range []*models.DataSource{own, colleague, otherTenant} {
+		require.NoError(t, db.Create(dataSource).Error)
+		require.NoError(t, db.Create(&models.SchemaSyncRun{
+			TenantID: dataSource.TenantID, SyncID: dataSource.Name, DataSourceID: dataSource.ID, InstanceID: "instance-a",
+			TriggeredBy: models.SchemaSyncTriggerManual, Status: models.SchemaSyncStatusFailed, StartedAt: time.Now().Add(time.Duration(i) * time.Minute),
+		}).Error)
+	}
+
+	service := NewSchemaSyncService(db, nil, nil).WithContext(tenancy.WithTenant(context.Background(), 1))
+	runs, err := service.GetSyncHistory(1, 0, 20)
+	require.NoError(t, err)
+	require.Len(t, runs, 1, "runs of colleagues and of other tenants are not listed")
+	assert.Equal(t, own.ID, runs[0].DataSourceID)
+
+	runs, err = service.GetSyncHistory(1, colleague.ID, 20)
+	require.NoError(t, err)
+	assert.Empty(t, runs, "runs of a colleague's data source are not listed")
+}
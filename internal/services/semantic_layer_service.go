@@ -0,0 +1,679 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSemanticMetricNotFound is returned when a metric does not exist for the data source
+	ErrSemanticMetricNotFound = errors.New("semantic metric not found")
+	// ErrSemanticDimensionNotFound is returned when a dimension does not exist for the data source
+	ErrSemanticDimensionNotFound = errors.New("semantic dimension not found")
+	// ErrSemanticDataSourceNotFound is returned when the data source of a semantic model does not exist
+	ErrSemanticDataSourceNotFound = errors.New("data source not found")
+	// ErrInvalidSemanticModel is returned when a metric or dimension does not fit the data source schema
+	ErrInvalidSemanticModel = errors.New("invalid semantic model")
+)
+
+// semanticAggregates are the functions a metric expression must aggregate with
+var semanticAggregates = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	"STDDEV": true, "VARIANCE": true, "COUNTIF": true, "APPROX_COUNT_DISTINCT": true,
+}
+
+// semanticGrainPatterns detect the time grain asked for in a question
+var semanticGrainPatterns = []struct {
+	grain   models.TimeGrain
+	pattern *regexp.Regexp
+}{
+	{models.TimeGrainDay, regexp.MustCompile(`\b(daily|(by|per|each) day)\b`)},
+	{models.TimeGrainWeek, regexp.MustCompile(`\b(weekly|(by|per|each) week)\b`)},
+	{models.TimeGrainMonth, regexp.MustCompile(`\b(monthly|(by|per|each) month)\b`)},
+	{models.TimeGrainQuarter, regexp.MustCompile(`\b(quarterly|(by|per|each) quarter)\b`)},
+	{models.TimeGrainYear, regexp.MustCompile(`\b(yearly|annually|annual|(by|per|each) year)\b`)},
+}
+
+// semanticFillerWords may surround metrics and dimensions in a question the
+// compiler answers; any other word, e.g. a filter value or a range, leaves
+// the question to the LLM
+var semanticFillerWords = map[string]bool{
+	"what": true, "what's": true, "whats": true, "is": true, "are": true, "was": true, "were": true,
+	"the": true, "a": true, "an": true, "our": true, "my": true, "total": true, "overall": true,
+	"show": true, "me": true, "give": true, "get": true, "list": true, "display": true, "tell": true,
+	"how": true, "much": true, "many": true, "and": true, "of": true, "by": true, "per": true,
+	"for": true, "each": true, "every": true, "all": true, "in": true, "across": true, "please": true,
+	"breakdown": true, "broken": true, "down": true, "split": true, "grouped": true, "group": true,
+}
+
+// SemanticLayerService manages the semantic model of data sources: metrics
+// defined as aggregates over a table and the dimensions they are broken down
+// by. NL2SQL compiles questions made of known metrics, dimensions and a time
+// grain to SQL, and passes the definitions to the LLM for everything else.
+type SemanticLayerService struct {
+	db           *gorm.DB
+	sqlValidator *SQLValidatorService
+}
+
+// NewSemanticLayerService creates a new semantic layer service
+func NewSemanticLayerService(db *gorm.DB) *SemanticLayerService {
+	return &SemanticLayerService{
+		db:           db,
+		sqlValidator: NewSQLValidatorService(),
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *SemanticLayerService) WithContext(ctx context.Context) *SemanticLayerService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// GetModel returns the metrics and dimensions of a data source by name
+func (s *SemanticLayerService) GetModel(dataSourceID uint) (*models.SemanticModelResponse, error) {
+	if _, err := s.getDataSource(dataSourceID); err != nil {
+		return nil, err
+	}
+
+	metrics, dimensions, err := s.load(dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.SemanticModelResponse{
+		DataSourceID: dataSourceID,
+		Metrics:      make([]models.SemanticMetricResponse, 0, len(metrics)),
+		Dimensions:   make([]models.SemanticDimensionResponse, 0, len(dimensions)),
+	}
+	for i := range metrics {
+		response.Metrics = append(response.Metrics, metrics[i].ToResponse())
+	}
+	for i := range dimensions {
+		response.Dimensions = append(response.Dimensions, dimensions[i].ToResponse())
+	}
+	return response, nil
+}
+
+// CreateMetric defines a metric for a data source
+func (s *SemanticLayerService) CreateMetric(adminID, dataSourceID uint, req *models.SemanticMetricRequest) (*models.SemanticMetricResponse, error) {
+	metric := &models.SemanticMetric{DataSourceID: dataSourceID}
+	if err := s.applyMetric(adminID, metric, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(metric).Error; err != nil {
+		return nil, fmt.Errorf("failed to create semantic metric: %w", err)
+	}
+	response := metric.ToResponse()
+	return &response, nil
+}
+
+// UpdateMetric replaces the definition of a metric
+func (s *SemanticLayerService) UpdateMetric(adminID, dataSourceID, id uint, req *models.SemanticMetricRequest) (*models.SemanticMetricResponse, error) {
+	var metric models.SemanticMetric
+	if err := s.db.Where("id = ? AND data_source_id = ?", id, dataSourceID).First(&metric).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemanticMetricNotFound
+		}
+		return nil, fmt.Errorf("failed to get semantic metric: %w", err)
+	}
+	if err := s.applyMetric(adminID, &metric, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&metric).Error; err != nil {
+		return nil, fmt.Errorf("failed to update semantic metric: %w", err)
+	}
+	response := metric.ToResponse()
+	return &response, nil
+}
+
+// DeleteMetric removes a metric; questions about it are generated by the LLM again
+func (s *SemanticLayerService) DeleteMetric(dataSourceID, id uint) error {
+	deleted := s.db.Where("id = ? AND data_source_id = ?", id, dataSourceID).Delete(&models.SemanticMetric{})
+	if deleted.Error != nil {
+		return fmt.Errorf("failed to delete semantic metric: %w", deleted.Error)
+	}
+	if deleted.RowsAffected == 0 {
+		return ErrSemanticMetricNotFound
+	}
+	return nil
+}
+
+// CreateDimension defines a dimension for a data source
+func (s *SemanticLayerService) CreateDimension(adminID, dataSourceID uint, req *models.SemanticDimensionRequest) (*models.SemanticDimensionResponse, error) {
+	dimension := &models.SemanticDimension{DataSourceID: dataSourceID}
+	if err := s.applyDimension(adminID, dimension, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(dimension).Error; err != nil {
+		return nil, fmt.Errorf("failed to create semantic dimension: %w", err)
+	}
+	response := dimension.ToResponse()
+	return &response, nil
+}
+
+// UpdateDimension replaces the definition of a dimension
+func (s *SemanticLayerService) UpdateDimension(adminID, dataSourceID, id uint, req *models.SemanticDimensionRequest) (*models.SemanticDimensionResponse, error) {
+	var dimension models.SemanticDimension
+	if err := s.db.Where("id = ? AND data_source_id = ?", id, dataSourceID).First(&dimension).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemanticDimensionNotFound
+		}
+		return nil, fmt.Errorf("failed to get semantic dimension: %w", err)
+	}
+	if err := s.applyDimension(adminID, &dimension, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&dimension).Error; err != nil {
+		return nil, fmt.Errorf("failed to update semantic dimension: %w", err)
+	}
+	response := dimension.ToResponse()
+	return &response, nil
+}
+
+// DeleteDimension removes a dimension
+func (s *SemanticLayerService) DeleteDimension(dataSourceID, id uint) error {
+	deleted := s.db.Where("id = ? AND data_source_id = ?", id, dataSourceID).Delete(&models.SemanticDimension{})
+	if deleted.Error != nil {
+		return fmt.Errorf("failed to delete semantic dimension: %w", deleted.Error)
+	}
+	if deleted.RowsAffected == 0 {
+		return ErrSemanticDimensionNotFound
+	}
+	return nil
+}
+
+// Resolve matches a question against the semantic model of a data source. It
+// returns nil when the question names no metric. With a resolution marked
+// compiled, the returned SQL answers the question; otherwise the returned
+// prompt section documents the matched definitions for the LLM.
+func (s *SemanticLayerService) Resolve(dataSource *models.DataSource, nlQuery string) (*models.SemanticResolution, string, error) {
+	if dataSource.Type.UsesAggregationPipeline() {
+		return nil, "", nil
+	}
+
+	metrics, dimensions, err := s.load(dataSource.ID)
+	if err != nil || len(metrics) == 0 {
+		return nil, "", err
+	}
+
+	match := matchSemanticQuestion(nlQuery, metrics, dimensions)
+	if len(match.metrics) == 0 {
+		return nil, "", nil
+	}
+
+	resolution := &models.SemanticResolution{TimeGrain: match.grain}
+	for _, metric := range match.metrics {
+		resolution.Metrics = append(resolution.Metrics, metric.Name)
+	}
+	for _, dimension := range match.dimensions {
+		resolution.Dimensions = append(resolution.Dimensions, dimension.Name)
+	}
+
+	relationships, err := s.relationships(dataSource.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	sql, reason := compileSemanticQuery(match, relationships, models.DialectForDataSourceType(dataSource.Type))
+	if reason != "" {
+		resolution.Reason = reason
+		return resolution, semanticPromptSection(match.metrics, match.dimensions), nil
+	}
+	resolution.Compiled = true
+	return resolution, sql, nil
+}
+
+// semanticMatch is what a question asks of the semantic model
+type semanticMatch struct {
+	metrics    []models.SemanticMetric
+	dimensions []models.SemanticDimension
+	grain      models.TimeGrain
+	unknown    string // First word the semantic model does not cover
+}
+
+// matchSemanticQuestion finds the metrics named in a question, the dimensions
+// it groups by ("by country", "per channel") and its time grain. Longer names
+// are matched first, so "net revenue" wins over "revenue".
+func matchSemanticQuestion(nlQuery string, metrics []models.SemanticMetric, dimensions []models.SemanticDimension) *semanticMatch {
+	question := " " + strings.Join(strings.Fields(strings.ToLower(nlQuery)), " ") + " "
+	match := &semanticMatch{}
+
+	for _, i := range matchSemanticTerms(&question, len(metrics), func(i int) []string {
+		return append([]string{metrics[i].Name}, metrics[i].SynonymList()...)
+	}, `\b%s(s|es)?\b`) {
+		match.metrics = append(match.metrics, metrics[i])
+	}
+	if len(match.metrics) == 0 {
+		return match
+	}
+
+	for _, candidate := range semanticGrainPatterns {
+		if candidate.pattern.MatchString(question) {
+			match.grain = candidate.grain
+			question = candidate.pattern.ReplaceAllString(question, " ")
+			break
+		}
+	}
+
+	for _, i := range matchSemanticTerms(&question, len(dimensions), func(i int) []string {
+		return append([]string{dimensions[i].Name}, dimensions[i].SynonymList()...)
+	}, `\b(by|per|each|across|and)\s+(the\s+)?%s(s|es)?\b`) {
+		match.dimensions = append(match.dimensions, dimensions[i])
+	}
+
+	for _, word := range strings.FieldsFunc(question, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '\''
+	}) {
+		if !semanticFillerWords[word] {
+			match.unknown = word
+			break
+		}
+	}
+	return match
+}
+
+// matchSemanticTerms returns the indexes of the items with a name found in the
+// question, removing each match so it is not matched again
+func matchSemanticTerms(question *string, count int, names func(int) []string, format string) []int {
+	type term struct {
+		name  string
+		index int
+	}
+	var terms []term
+	for i := 0; i < count; i++ {
+		for _, name := range names(i) {
+			if name = strings.TrimSpace(name); name != "" {
+				terms = append(terms, term{name, i})
+			}
+		}
+	}
+	sort.SliceStable(terms, func(a, b int) bool { return len(terms[a].name) > len(terms[b].name) })
+
+	var matched []int
+	seen := make(map[int]bool)
+	for _, t := range terms {
+		pattern := regexp.MustCompile(fmt.Sprintf(format, regexp.QuoteMeta(t.name)))
+		if !pattern.MatchString(*question) {
+			continue
+		}
+		*question = pattern.ReplaceAllString(*question, " ")
+		if !seen[t.index] {
+			seen[t.index] = true
+			matched = append(matched, t.index)
+		}
+	}
+	sort.Ints(matched)
+	return matched
+}
+
+// compileSemanticQuery builds the SQL of a match, or returns why it cannot.
+// Metrics must share their table and filter; dimensions on other tables are
+// joined through a direct relationship.
+func compileSemanticQuery(match *semanticMatch, relationships []models.TableRelationship, dialect models.SQLDialect) (string, string) {
+	if match.unknown != "" {
+		return "", fmt.Sprintf("the semantic model does not cover %q in the question", match.unknown)
+	}
+
+	base := match.metrics[0]
+	for _, metric := range match.metrics[1:] {
+		if !strings.EqualFold(metric.Table, base.Table) {
+			return "", fmt.Sprintf("metrics %s and %s are on different tables", base.Name, metric.Name)
+		}
+		if strings.TrimSpace(metric.Filter) != strings.TrimSpace(base.Filter) {
+			return "", fmt.Sprintf("metrics %s and %s have different filters", base.Name, metric.Name)
+		}
+	}
+
+	var groups, joins []string
+	joined := map[string]bool{strings.ToLower(base.Table): true}
+	hasTime := false
+	for _, dimension := range match.dimensions {
+		if !strings.EqualFold(dimension.Table, base.Table) {
+			relationship := findRelationship(relationships, base.Table, dimension.Table)
+			if relationship == nil {
+				return "", fmt.Sprintf("dimension %s is on %s, which has no relationship with %s", dimension.Name, dimension.Table, base.Table)
+			}
+			if !joined[strings.ToLower(dimension.Table)] {
+				joined[strings.ToLower(dimension.Table)] = true
+				joins = append(joins, fmt.Sprintf("JOIN %s ON %s = %s",
+					quoteQualifiedName(dimension.Table, dialect),
+					qualifiedColumn(relationship.FromTable, relationship.FromColumn, dialect),
+					qualifiedColumn(relationship.ToTable, relationship.ToColumn, dialect)))
+			}
+		}
+		expression := qualifiedColumn(dimension.Table, dimension.Column, dialect)
+		if dimension.IsTime {
+			hasTime = true
+			if match.grain != "" {
+				expression = truncateToGrain(expression, match.grain, dialect)
+			}
+		}
+		groups = append(groups, expression, semanticAlias(dimension.Name, dialect))
+	}
+	if match.grain != "" && !hasTime {
+		if base.TimeColumn == "" {
+			return "", fmt.Sprintf("metric %s has no time column for a %s breakdown", base.Name, match.grain)
+		}
+		expression := truncateToGrain(qualifiedColumn(base.Table, base.TimeColumn, dialect), match.grain, dialect)
+		groups = append([]string{expression, semanticAlias(string(match.grain), dialect)}, groups...)
+	}
+
+	var selects, groupBy []string
+	for i := 0; i < len(groups); i += 2 {
+		selects = append(selects, groups[i]+" AS "+groups[i+1])
+		groupBy = append(groupBy, groups[i])
+	}
+	for _, metric := range match.metrics {
+		selects = append(selects, metric.Expression+" AS "+semanticAlias(metric.Name, dialect))
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT " + strings.Join(selects, ", "))
+	b.WriteString(" FROM " + quoteQualifiedName(base.Table, dialect))
+	for _, join := range joins {
+		b.WriteString(" " + join)
+	}
+	if filter := strings.TrimSpace(base.Filter); filter != "" {
+		b.WriteString(" WHERE " + filter)
+	}
+	if len(groupBy) > 0 {
+		b.WriteString(" GROUP BY " + strings.Join(groupBy, ", "))
+		b.WriteString(" ORDER BY " + groupBy[0])
+	}
+	return b.String(), ""
+}
+
+// findRelationship returns the relationship joining two tables in either direction
+func findRelationship(relationships []models.TableRelationship, from, to string) *models.TableRelationship {
+	for i, relationship := range relationships {
+		if (strings.EqualFold(relationship.FromTable, from) && strings.EqualFold(relationship.ToTable, to)) ||
+			(strings.EqualFold(relationship.FromTable, to) && strings.EqualFold(relationship.ToTable, from)) {
+			return &relationships[i]
+		}
+	}
+	return nil
+}
+
+// truncateToGrain buckets a time expression to the grain in the dialect
+func truncateToGrain(expression string, grain models.TimeGrain, dialect models.SQLDialect) string {
+	if dialect == models.SQLDialectBigQuery {
+		return fmt.Sprintf("DATE_TRUNC(DATE(%s), %s)", expression, strings.ToUpper(string(grain)))
+	}
+	return fmt.Sprintf("DATE_TRUNC('%s', %s)", grain, expression)
+}
+
+// quoteQualifiedName quotes each part of a possibly schema-qualified table name
+func quoteQualifiedName(name string, dialect models.SQLDialect) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part, dialect)
+	}
+	return strings.Join(parts, ".")
+}
+
+// qualifiedColumn quotes a column qualified with its table
+func qualifiedColumn(table, column string, dialect models.SQLDialect) string {
+	return quoteQualifiedName(table, dialect) + "." + quoteIdentifier(column, dialect)
+}
+
+// semanticAlias turns a metric or dimension name into a column alias
+func semanticAlias(name string, dialect models.SQLDialect) string {
+	return quoteIdentifier(strings.Join(strings.Fields(name), "_"), dialect)
+}
+
+// semanticPromptSection documents matched definitions for the LLM
+func semanticPromptSection(metrics []models.SemanticMetric, dimensions []models.SemanticDimension) string {
+	var b strings.Builder
+	b.WriteString("\n\nSEMANTIC MODEL (use these definitions exactly):\n")
+	for _, metric := range metrics {
+		b.WriteString(fmt.Sprintf("- metric %s = %s on %s", metric.Name, metric.Expression, metric.Table))
+		if metric.Filter != "" {
+			b.WriteString(" where " + metric.Filter)
+		}
+		if metric.TimeColumn != "" {
+			b.WriteString(", time column " + metric.TimeColumn)
+		}
+		b.WriteString("\n")
+	}
+	for _, dimension := range dimensions {
+		b.WriteString(fmt.Sprintf("- dimension %s = %s.%s\n", dimension.Name, dimension.Table, dimension.Column))
+	}
+	return b.String()
+}
+
+// applyMetric validates a request against the data source schema and copies it onto a metric
+func (s *SemanticLayerService) applyMetric(adminID uint, metric *models.SemanticMetric, req *models.SemanticMetricRequest) error {
+	dataSource, err := s.getDataSource(metric.DataSourceID)
+	if err != nil {
+		return err
+	}
+	name, err := s.checkName(&models.SemanticMetric{}, metric.DataSourceID, metric.ID, req.Name)
+	if err != nil {
+		return err
+	}
+	tables, err := s.tables(dataSource)
+	if err != nil {
+		return err
+	}
+	table, columns, err := lookupSemanticTable(tables, req.Table)
+	if err != nil {
+		return err
+	}
+	timeColumn := ""
+	if strings.TrimSpace(req.TimeColumn) != "" {
+		if timeColumn, err = lookupSemanticColumn(table, columns, req.TimeColumn); err != nil {
+			return err
+		}
+	}
+
+	expression := strings.TrimSpace(req.Expression)
+	filter := strings.TrimSpace(req.Filter)
+	if err := s.validateExpression(dataSource, table, expression, filter); err != nil {
+		return err
+	}
+
+	synonymsJSON, err := json.Marshal(normalizeTags(req.Synonyms))
+	if err != nil {
+		return fmt.Errorf("failed to marshal synonyms: %w", err)
+	}
+
+	metric.Name = name
+	metric.Synonyms = models.JSON(synonymsJSON)
+	metric.Description = strings.TrimSpace(req.Description)
+	metric.Table = table
+	metric.Expression = expression
+	metric.Filter = filter
+	metric.TimeColumn = timeColumn
+	metric.UpdatedBy = adminID
+	return nil
+}
+
+// applyDimension validates a request against the data source schema and copies it onto a dimension
+func (s *SemanticLayerService) applyDimension(adminID uint, dimension *models.SemanticDimension, req *models.SemanticDimensionRequest) error {
+	dataSource, err := s.getDataSource(dimension.DataSourceID)
+	if err != nil {
+		return err
+	}
+	name, err := s.checkName(&models.SemanticDimension{}, dimension.DataSourceID, dimension.ID, req.Name)
+	if err != nil {
+		return err
+	}
+	tables, err := s.tables(dataSource)
+	if err != nil {
+		return err
+	}
+	table, columns, err := lookupSemanticTable(tables, req.Table)
+	if err != nil {
+		return err
+	}
+	column, err := lookupSemanticColumn(table, columns, req.Column)
+	if err != nil {
+		return err
+	}
+
+	synonymsJSON, err := json.Marshal(normalizeTags(req.Synonyms))
+	if err != nil {
+		return fmt.Errorf("failed to marshal synonyms: %w", err)
+	}
+
+	dimension.Name = name
+	dimension.Synonyms = models.JSON(synonymsJSON)
+	dimension.Description = strings.TrimSpace(req.Description)
+	dimension.Table = table
+	dimension.Column = column
+	dimension.IsTime = req.IsTime
+	dimension.UpdatedBy = adminID
+	return nil
+}
+
+// checkName normalizes a metric or dimension name and checks it is not taken
+func (s *SemanticLayerService) checkName(model interface{}, dataSourceID, id uint, name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidSemanticModel)
+	}
+	var existing int64
+	if err := s.db.Model(model).Where("data_source_id = ? AND name = ? AND id <> ?", dataSourceID, name, id).
+		Count(&existing).Error; err != nil {
+		return "", fmt.Errorf("failed to check existing name: %w", err)
+	}
+	if existing > 0 {
+		return "", fmt.Errorf("%w: %s is already defined for this data source", ErrInvalidSemanticModel, name)
+	}
+	return name, nil
+}
+
+// validateExpression checks that a metric aggregates and, with its filter,
+// makes a query the validator accepts for the data source dialect
+func (s *SemanticLayerService) validateExpression(dataSource *models.DataSource, table, expression, filter string) error {
+	dialect := models.DialectForDataSourceType(dataSource.Type)
+	tokens, err := tokenizeSQL(expression, dialect)
+	if err != nil {
+		return fmt.Errorf("%w: expression: %v", ErrInvalidSemanticModel, err)
+	}
+	aggregates := false
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].kind == sqlTokenWord && semanticAggregates[tokens[i].upper()] && tokens[i+1].isSymbol("(") {
+			aggregates = true
+			break
+		}
+	}
+	if !aggregates {
+		return fmt.Errorf("%w: expression must aggregate, e.g. SUM(amount) or COUNT(DISTINCT id)", ErrInvalidSemanticModel)
+	}
+
+	probe := "SELECT " + expression + " AS value FROM " + quoteQualifiedName(table, dialect)
+	if filter != "" {
+		probe += " WHERE " + filter
+	}
+	validation, err := s.sqlValidator.ValidateSQLForDialect(probe+" LIMIT 1", dialect)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSemanticModel, err)
+	}
+	if !s.sqlValidator.IsQuerySafe(validation) {
+		return fmt.Errorf("%w: expression or filter failed validation: %s", ErrInvalidSemanticModel, strings.Join(validation.Violations, "; "))
+	}
+	return nil
+}
+
+// lookupSemanticTable finds a table of the data source by name, ignoring case
+func lookupSemanticTable(tables map[string][]models.Column, name string) (string, []models.Column, error) {
+	name = strings.TrimSpace(name)
+	for table, columns := range tables {
+		if strings.EqualFold(table, name) {
+			return table, columns, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: table %s not found in the data source schema", ErrInvalidSemanticModel, name)
+}
+
+// lookupSemanticColumn finds a column of a table by name, ignoring case
+func lookupSemanticColumn(table string, columns []models.Column, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	for _, column := range columns {
+		if strings.EqualFold(column.Name, name) {
+			return column.Name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: column %s not found in table %s", ErrInvalidSemanticModel, name, table)
+}
+
+// load returns the metrics and dimensions of a data source by name
+func (s *SemanticLayerService) load(dataSourceID uint) ([]models.SemanticMetric, []models.SemanticDimension, error) {
+	var metrics []models.SemanticMetric
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("name").Find(&metrics).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get semantic metrics: %w", err)
+	}
+	var dimensions []models.SemanticDimension
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("name").Find(&dimensions).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get semantic dimensions: %w", err)
+	}
+	return metrics, dimensions, nil
+}
+
+// tables returns the columns of the active tables of a data source by table name
+func (s *SemanticLayerService) tables(dataSource *models.DataSource) (map[string][]models.Column, error) {
+	var schemas []models.Schema
+	if err := s.db.Select("name", "columns").
+		Where("data_source_id = ? AND is_active = ?", dataSource.ID, true).Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get schemas: %w", err)
+	}
+	tables := make(map[string][]models.Column, len(schemas))
+	for _, schema := range schemas {
+		var columns []models.Column
+		if len(schema.Columns) > 0 {
+			_ = json.Unmarshal(schema.Columns, &columns)
+		}
+		tables[schema.Name] = columns
+	}
+	return tables, nil
+}
+
+// relationships returns the stored relationships between the tables of a data source
+func (s *SemanticLayerService) relationships(dataSourceID uint) ([]models.TableRelationship, error) {
+	var schemas []models.Schema
+	if err := s.db.Select("relationships").
+		Where("data_source_id = ? AND is_active = ?", dataSourceID, true).Find(&schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get relationships: %w", err)
+	}
+	var relationships []models.TableRelationship
+	for _, schema := range schemas {
+		if schema.Relationships == nil {
+			continue
+		}
+		var schemaRelationships []models.TableRelationship
+		if err := json.Unmarshal(schema.Relationships, &schemaRelationships); err != nil {
+			continue
+		}
+		relationships = append(relationships, schemaRelationships...)
+	}
+	return relationships, nil
+}
+
+func (s *SemanticLayerService) getDataSource(id uint) (*models.DataSource, error) {
+	var dataSource models.DataSource
+	if err := s.db.Select("id", "type").First(&dataSource, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemanticDataSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get data source: %w", err)
+	}
+	if dataSource.Type.UsesAggregationPipeline() {
+		return nil, fmt.Errorf("%w: semantic models are not supported for aggregation pipeline data sources", ErrInvalidSemanticModel)
+	}
+	return &dataSource, nil
+}
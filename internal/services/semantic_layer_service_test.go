@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func semanticTestModel() ([]models.SemanticMetric, []models.SemanticDimension) {
+	metrics := []models.SemanticMetric{
+		{Name: "revenue", Synonyms: models.JSON(`["sales"]`), Table: "orders", Expression: "SUM(amount)", Filter: "status = 'paid'", TimeColumn: "created_at"},
+		{Name: "net revenue", Table: "orders", Expression: "SUM(amount - refunded)", Filter: "status = 'paid'", TimeColumn: "created_at"},
+		{Name: "orders", Table: "orders", Expression: "COUNT(*)", TimeColumn: "created_at"},
+		{Name: "signups", Table: "users", Expression: "COUNT(*)"},
+	}
+	dimensions := []models.SemanticDimension{
+		{Name: "country", Table: "customers", Column: "country"},
+		{Name: "channel", Table: "orders", Column: "channel"},
+		{Name: "order date", Table: "orders", Column: "created_at", IsTime: true},
+	}
+	return metrics, dimensions
+}
+
+func TestMatchSemanticQuestion(t *testing.T) {
+	metrics, dimensions := semanticTestModel()
+
+	match := matchSemanticQuestion("What is our net revenue by channel, monthly?", metrics, dimensions)
+	require.Len(t, match.metrics, 1)
+	assert.Equal(t, "net revenue", match.metrics[0].Name, "longer names win over the names they contain")
+	require.Len(t, match.dimensions, 1)
+	assert.Equal(t, "channel", match.dimensions[0].Name)
+	assert.Equal(t, models.TimeGrainMonth, match.grain)
+	assert.Empty(t, match.unknown)
+
+	match = matchSemanticQuestion("total sales per country", metrics, dimensions)
+	require.Len(t, match.metrics, 1)
+	assert.Equal(t, "revenue", match.metrics[0].Name, "synonyms match")
+	require.Len(t, match.dimensions, 1)
+	assert.Equal(t, "country", match.dimensions[0].Name)
+
+	match = matchSemanticQuestion("revenue in germany", metrics, dimensions)
+	assert.Equal(t, "germany", match.unknown)
+
+	match = matchSemanticQuestion("how many customers do we have", metrics, dimensions)
+	assert.Empty(t, match.metrics)
+}
+
+func TestCompileSemanticQuery(t *testing.T) {
+	metrics, dimensions := semanticTestModel()
+	relationships := []models.TableRelationship{
+		{FromTable: "orders", FromColumn: "customer_id", ToTable: "customers", ToColumn: "id"},
+	}
+
+	t.Run("time grain on the default time column", func(t *testing.T) {
+		match := matchSemanticQuestion("revenue and net revenue by month", metrics, dimensions)
+		sql, reason := compileSemanticQuery(match, relationships, models.SQLDialectPostgreSQL)
+		require.Empty(t, reason)
+		assert.Equal(t, `SELECT DATE_TRUNC('month', "orders"."created_at") AS "month", SUM(amount) AS "revenue", SUM(amount - refunded) AS "net_revenue"`+
+			` FROM "orders" WHERE status = 'paid' GROUP BY DATE_TRUNC('month', "orders"."created_at") ORDER BY DATE_TRUNC('month', "orders"."created_at")`, sql)
+	})
+
+	t.Run("joined dimension", func(t *testing.T) {
+		match := matchSemanticQuestion("revenue by country", metrics, dimensions)
+		sql, reason := compileSemanticQuery(match, relationships, models.SQLDialectBigQuery)
+		require.Empty(t, reason)
+		assert.Equal(t, "SELECT `customers`.`country` AS `country`, SUM(amount) AS `revenue` FROM `orders`"+
+			" JOIN `customers` ON `orders`.`customer_id` = `customers`.`id` WHERE status = 'paid'"+
+			" GROUP BY `customers`.`country` ORDER BY `customers`.`country`", sql)
+	})
+
+	t.Run("time dimension bucketed by grain", func(t *testing.T) {
+		match := matchSemanticQuestion("weekly orders by order date", metrics, dimensions)
+		sql, reason := compileSemanticQuery(match, relationships, models.SQLDialectBigQuery)
+		require.Empty(t, reason)
+		assert.Contains(t, sql, "DATE_TRUNC(DATE(`orders`.`created_at`), WEEK) AS `order_date`")
+	})
+
+	t.Run("falls back to the LLM", func(t *testing.T) {
+		for question, want := range map[string]string{
+			"revenue since 2024":  `does not cover "since"`,
+			"revenue and signups": "different tables",
+			"revenue and orders":  "different filters",
+			"signups by month":    "no time column",
+			"signups by channel":  "no relationship",
+		} {
+			match := matchSemanticQuestion(question, metrics, dimensions)
+			_, reason := compileSemanticQuery(match, relationships, models.SQLDialectPostgreSQL)
+			assert.Contains(t, reason, want, question)
+		}
+	})
+}
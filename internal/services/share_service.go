@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrShareNotFound is returned when a share link does not exist or belongs to another user
+	ErrShareNotFound = errors.New("share link not found")
+	// ErrShareExpired is returned when a share link has expired or was revoked
+	ErrShareExpired = errors.New("share link has expired or was revoked")
+	// ErrSharePasswordRequired is returned when a share link's password is missing or wrong
+	ErrSharePasswordRequired = errors.New("share link password is missing or wrong")
+)
+
+const (
+	// shareTokenPrefix marks NaraPulse share tokens
+	shareTokenPrefix = "nps_"
+	// defaultShareRowLimit is the rows shown per result unless set
+	defaultShareRowLimit = 100
+	// defaultShareExpiry is how long a link is valid unless set
+	defaultShareExpiry = 7 * 24 * time.Hour
+)
+
+// ShareService manages public read-only links to query results and
+// dashboards, for stakeholders without an account. Shared rows are always
+// PII-masked and limited to the row limit of the link.
+type ShareService struct {
+	db         *gorm.DB
+	nl2sql     *NL2SQLService
+	dashboards *DashboardService
+	extracts   *ExtractService
+	now        func() time.Time
+}
+
+// NewShareService creates a new share service
+func NewShareService(db *gorm.DB, nl2sql *NL2SQLService, dashboards *DashboardService, extracts *ExtractService) *ShareService {
+	return &ShareService{
+		db:         db,
+		nl2sql:     nl2sql,
+		dashboards: dashboards,
+		extracts:   extracts,
+		now:        time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ShareService) WithContext(ctx context.Context) *ShareService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.nl2sql = s.nl2sql.WithContext(ctx)
+	scoped.dashboards = s.dashboards.WithContext(ctx)
+	scoped.extracts = s.extracts.WithContext(ctx)
+	return &scoped
+}
+
+// List returns the share links created by a user, newest first
+func (s *ShareService) List(userID uint) ([]models.ShareLinkResponse, error) {
+	var links []models.ShareLink
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	responses := make([]models.ShareLinkResponse, len(links))
+	for i := range links {
+		responses[i] = *s.response(&links[i], "")
+	}
+	return responses, nil
+}
+
+// Create shares a query result the user owns or a dashboard the user owns.
+// The URL holding the token is only returned here.
+func (s *ShareService) Create(userID uint, req *models.ShareLinkRequest) (*models.ShareLinkResponse, error) {
+	link := &models.ShareLink{
+		UserID:       userID,
+		ResourceType: req.ResourceType,
+		Title:        req.Title,
+		RowLimit:     req.RowLimit,
+	}
+	if link.RowLimit == 0 {
+		link.RowLimit = defaultShareRowLimit
+	}
+	expiry := defaultShareExpiry
+	if req.ExpiresInHours > 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	link.ExpiresAt = s.now().Add(expiry)
+
+	switch req.ResourceType {
+	case models.ShareResourceQueryResult:
+		query, err := s.nl2sql.GetQueryDetails(userID, req.QueryID)
+		if err != nil {
+			return nil, err
+		}
+		page, err := s.nl2sql.GetQueryResults(userID, query.ID, &models.QueryResultPageRequest{ResultID: req.ResultID, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		link.QueryID = &query.ID
+		link.ResultID = &page.ResultID
+		if link.Title == "" {
+			link.Title = query.NLQuery
+		}
+	case models.ShareResourceDashboard:
+		dashboard, err := s.dashboards.owned(userID, req.DashboardID)
+		if err != nil {
+			return nil, err
+		}
+		link.DashboardID = &dashboard.ID
+		if link.Title == "" {
+			link.Title = dashboard.Name
+		}
+	}
+
+	if req.Password != "" {
+		hash, err := utils.HashPassword(req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash share password: %w", err)
+		}
+		link.PasswordHash = hash
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+	token := shareTokenPrefix + hex.EncodeToString(secret)
+	link.TokenPrefix = token[:len(shareTokenPrefix)+8]
+	link.TokenHash = hashAPIKey(token)
+
+	if err := s.db.Create(link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return s.response(link, token), nil
+}
+
+// Revoke revokes a share link of the user at once
+func (s *ShareService) Revoke(userID, id uint) error {
+	var link models.ShareLink
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareNotFound
+		}
+		return fmt.Errorf("failed to get share link: %w", err)
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+	if err := s.db.Model(&link).Update("revoked_at", s.now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	return nil
+}
+
+// View returns the shared resource of a token, checking its expiry and password
+func (s *ShareService) View(token, password string) (*models.SharedResourceResponse, error) {
+	if !strings.HasPrefix(token, shareTokenPrefix) {
+		return nil, ErrShareNotFound
+	}
+	var link models.ShareLink
+	if err := s.db.Where("token_hash = ?", hashAPIKey(token)).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	now := s.now()
+	if !shareActive(&link, now) {
+		return nil, ErrShareExpired
+	}
+	if link.PasswordHash != "" && !utils.CheckPasswordHash(password, link.PasswordHash) {
+		return nil, ErrSharePasswordRequired
+	}
+
+	response := &models.SharedResourceResponse{
+		ResourceType: link.ResourceType,
+		Title:        link.Title,
+		ExpiresAt:    link.ExpiresAt,
+	}
+	switch link.ResourceType {
+	case models.ShareResourceQueryResult:
+		result, err := s.sharedResult(*link.QueryID, *link.ResultID, link.RowLimit)
+		if err != nil {
+			return nil, err
+		}
+		response.Result = result
+	case models.ShareResourceDashboard:
+		dashboard, err := s.sharedDashboard(*link.DashboardID, link.RowLimit)
+		if err != nil {
+			return nil, err
+		}
+		response.Dashboard = dashboard
+	}
+
+	s.db.Model(&models.ShareLink{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+		"view_count":     gorm.Expr("view_count + 1"),
+		"last_viewed_at": now,
+	})
+	return response, nil
+}
+
+// sharedResult reads the first rows of a result as the owner of its query,
+// with PII masked. resultID 0 reads the latest result.
+func (s *ShareService) sharedResult(queryID, resultID uint, limit int) (*models.SharedResult, error) {
+	var query models.NL2SQLQuery
+	if err := s.db.Select("id", "user_id", "nl_query").First(&query, queryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareExpired
+		}
+		return nil, fmt.Errorf("failed to get query: %w", err)
+	}
+	page, err := s.nl2sql.GetQueryResults(query.UserID, query.ID, &models.QueryResultPageRequest{ResultID: resultID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return &models.SharedResult{
+		Question:      query.NLQuery,
+		Columns:       page.Columns,
+		Data:          page.Data,
+		TotalRows:     page.TotalRows,
+		Truncated:     page.HasMore,
+		MaskedColumns: page.MaskedColumns,
+		CreatedAt:     page.CreatedAt,
+	}, nil
+}
+
+// sharedDashboard shows the widgets of a dashboard with the latest results of their queries
+func (s *ShareService) sharedDashboard(dashboardID uint, rowLimit int) (*models.SharedDashboard, error) {
+	var dashboard models.Dashboard
+	err := s.db.Preload("Widgets", func(db *gorm.DB) *gorm.DB {
+		return db.Order("y ASC, x ASC, id ASC")
+	}).First(&dashboard, dashboardID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareExpired
+		}
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+
+	shared := &models.SharedDashboard{
+		Name:        dashboard.Name,
+		Description: dashboard.Description,
+		Widgets:     make([]models.SharedWidget, 0, len(dashboard.Widgets)),
+	}
+	for i := range dashboard.Widgets {
+		shared.Widgets = append(shared.Widgets, *s.sharedWidget(&dashboard.Widgets[i], rowLimit))
+	}
+	return shared, nil
+}
+
+// sharedWidget shows a widget with the snapshot of its extract, or the latest result of its query
+func (s *ShareService) sharedWidget(widget *models.DashboardWidget, rowLimit int) *models.SharedWidget {
+	shared := &models.SharedWidget{
+		ID:     widget.ID,
+		Type:   widget.Type,
+		Title:  widget.Title,
+		X:      widget.X,
+		Y:      widget.Y,
+		Width:  widget.Width,
+		Height: widget.Height,
+	}
+	if len(widget.Config) > 0 {
+		shared.Config = []byte(widget.Config)
+	}
+	var (
+		result *models.SharedResult
+		err    error
+	)
+	switch {
+	case widget.ExtractID != nil:
+		result, err = s.extracts.widgetResult(*widget.ExtractID, rowLimit)
+	case widget.QueryID != nil:
+		result, err = s.sharedResult(*widget.QueryID, 0, rowLimit)
+	default:
+		return shared
+	}
+	if err != nil {
+		shared.Error = "result unavailable"
+	} else {
+		shared.Result = result
+	}
+	return shared
+}
+
+// response converts a link for its owner; token is set only on creation
+func (s *ShareService) response(link *models.ShareLink, token string) *models.ShareLinkResponse {
+	response := &models.ShareLinkResponse{
+		ShareLink:   *link,
+		HasPassword: link.PasswordHash != "",
+		Active:      shareActive(link, s.now()),
+	}
+	if token != "" {
+		response.URL = "/api/v1/public/shares/" + token
+	}
+	return response
+}
+
+// shareActive reports whether a link is neither revoked nor expired
+func shareActive(link *models.ShareLink, now time.Time) bool {
+	return link.RevokedAt == nil && now.Before(link.ExpiresAt)
+}
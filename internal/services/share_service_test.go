@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareActive(t *testing.T) {
+	now := time.Date(2025, 10, 7, 9, 0, 0, 0, time.UTC)
+	revoked := now.Add(-time.Minute)
+
+	assert.True(t, shareActive(&models.ShareLink{ExpiresAt: now.Add(time.Hour)}, now))
+	assert.False(t, shareActive(&models.ShareLink{ExpiresAt: now}, now))
+	assert.False(t, shareActive(&models.ShareLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &revoked}, now))
+}
+
+func TestShareResponse(t *testing.T) {
+	now := time.Date(2025, 10, 7, 9, 0, 0, 0, time.UTC)
+	service := &ShareService{now: func() time.Time { return now }}
+	link := &models.ShareLink{ID: 3, PasswordHash: "hash", ExpiresAt: now.Add(time.Hour)}
+
+	created := service.response(link, "nps_abc")
+	assert.Equal(t, "/api/v1/public/shares/nps_abc", created.URL)
+	assert.True(t, created.HasPassword)
+	assert.True(t, created.Active)
+
+	listed := service.response(link, "")
+	assert.Empty(t, listed.URL)
+}
+
+func TestShareViewRejectsForeignTokens(t *testing.T) {
+	service := NewShareService(nil, nil, nil, nil)
+
+	_, err := service.View("npk_0123456789", "")
+	assert.ErrorIs(t, err, ErrShareNotFound)
+}
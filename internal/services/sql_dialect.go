@@ -0,0 +1,454 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+)
+
+// sqlTokenKind classifies a lexical SQL token
+type sqlTokenKind int
+
+const (
+	sqlTokenWord sqlTokenKind = iota
+	sqlTokenQuotedIdent
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenSymbol
+	sqlTokenComment
+	sqlTokenParam
+)
+
+// sqlToken is a lexical token with its byte offsets in the original query
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string
+	start int
+	end   int
+}
+
+// upper returns the upper-cased token text, used for keyword comparisons
+func (t sqlToken) upper() string {
+	return strings.ToUpper(t.text)
+}
+
+// isWord reports whether the token is the given unquoted keyword
+func (t sqlToken) isWord(keyword string) bool {
+	return t.kind == sqlTokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// isSymbol reports whether the token is the given symbol
+func (t sqlToken) isSymbol(symbol string) bool {
+	return t.kind == sqlTokenSymbol && t.text == symbol
+}
+
+// tokenizeSQL splits a query into tokens following the quoting rules of the
+// dialect. String literals, quoted identifiers and comments become single
+// tokens, so keyword checks never match inside them.
+func tokenizeSQL(sql string, dialect models.SQLDialect) ([]sqlToken, error) {
+	var tokens []sqlToken
+	i := 0
+	for i < len(sql) {
+		c := sql[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+			continue
+
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-',
+			c == '#' && dialect == models.SQLDialectBigQuery:
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			tokens = append(tokens, sqlToken{sqlTokenComment, sql[start:i], start, i})
+
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at position %d", start)
+			}
+			i += end + 4
+			tokens = append(tokens, sqlToken{sqlTokenComment, sql[start:i], start, i})
+
+		case c == '\'':
+			end, err := scanQuoted(sql, i, '\'', dialect == models.SQLDialectBigQuery)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, sqlToken{sqlTokenString, sql[start:i], start, i})
+
+		case c == '"':
+			// BigQuery uses double quotes for strings, the others for identifiers
+			end, err := scanQuoted(sql, i, '"', dialect == models.SQLDialectBigQuery)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			kind := sqlTokenQuotedIdent
+			if dialect == models.SQLDialectBigQuery {
+				kind = sqlTokenString
+			}
+			tokens = append(tokens, sqlToken{kind, sql[start:i], start, i})
+
+		case c == '`':
+			end, err := scanQuoted(sql, i, '`', false)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, sqlToken{sqlTokenQuotedIdent, sql[start:i], start, i})
+
+		case c == '$' && dialect != models.SQLDialectBigQuery:
+			// Positional parameter ($1) or dollar-quoted string ($$...$$, $tag$...$tag$)
+			j := i + 1
+			for j < len(sql) && isDigit(sql[j]) {
+				j++
+			}
+			if j > i+1 {
+				i = j
+				tokens = append(tokens, sqlToken{sqlTokenParam, sql[start:i], start, i})
+				continue
+			}
+			for j < len(sql) && isIdentChar(sql[j]) {
+				j++
+			}
+			if j >= len(sql) || sql[j] != '$' {
+				return nil, fmt.Errorf("unexpected character '$' at position %d", start)
+			}
+			tag := sql[i : j+1]
+			end := strings.Index(sql[j+1:], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string at position %d", start)
+			}
+			i = j + 1 + end + len(tag)
+			tokens = append(tokens, sqlToken{sqlTokenString, sql[start:i], start, i})
+
+		case c == '@' && dialect == models.SQLDialectBigQuery:
+			j := i + 1
+			for j < len(sql) && isIdentChar(sql[j]) {
+				j++
+			}
+			i = j
+			tokens = append(tokens, sqlToken{sqlTokenParam, sql[start:i], start, i})
+
+		case c == '?':
+			i++
+			tokens = append(tokens, sqlToken{sqlTokenParam, sql[start:i], start, i})
+
+		case isDigit(c) || (c == '.' && i+1 < len(sql) && isDigit(sql[i+1])):
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+				j := i + 1
+				if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+					j++
+				}
+				if j < len(sql) && isDigit(sql[j]) {
+					i = j
+					for i < len(sql) && isDigit(sql[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, sqlToken{sqlTokenNumber, sql[start:i], start, i})
+
+		case isIdentStart(c):
+			for i < len(sql) && isIdentChar(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{sqlTokenWord, sql[start:i], start, i})
+
+		default:
+			i++
+			if i < len(sql) {
+				switch sql[start : i+1] {
+				case "::", "<=", ">=", "<>", "!=", "||":
+					i++
+				}
+			}
+			tokens = append(tokens, sqlToken{sqlTokenSymbol, sql[start:i], start, i})
+		}
+	}
+
+	return tokens, nil
+}
+
+// scanQuoted returns the offset just past a quoted token starting at start.
+// A doubled quote character is an escaped quote; backslash escapes are
+// honoured when allowBackslash is set (BigQuery string literals).
+func scanQuoted(sql string, start int, quote byte, allowBackslash bool) (int, error) {
+	i := start + 1
+	for i < len(sql) {
+		switch {
+		case allowBackslash && sql[i] == '\\':
+			i += 2
+		case sql[i] == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1, nil
+		default:
+			i++
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted text at position %d", start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
+
+// significantTokens drops comments
+func significantTokens(tokens []sqlToken) []sqlToken {
+	result := make([]sqlToken, 0, len(tokens))
+	for _, t := range tokens {
+		if t.kind != sqlTokenComment {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// topLevelLimit finds the LIMIT and OFFSET keywords outside parentheses. The
+// returned indexes are -1 when the clause is absent.
+func topLevelLimit(tokens []sqlToken) (limitIdx, offsetIdx int) {
+	limitIdx, offsetIdx = -1, -1
+	depth := 0
+	for i, t := range tokens {
+		switch {
+		case t.isSymbol("("):
+			depth++
+		case t.isSymbol(")"):
+			depth--
+		case depth == 0 && t.isWord("LIMIT"):
+			limitIdx = i
+		case depth == 0 && t.isWord("OFFSET"):
+			offsetIdx = i
+		}
+	}
+	return limitIdx, offsetIdx
+}
+
+// quoteIdentifier quotes an identifier for the dialect
+func quoteIdentifier(name string, dialect models.SQLDialect) string {
+	if dialect == models.SQLDialectBigQuery {
+		return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// unquoteIdentifier strips the quotes of a quoted identifier token
+func unquoteIdentifier(text string) string {
+	if len(text) < 2 {
+		return text
+	}
+	quote := text[:1]
+	return strings.ReplaceAll(text[1:len(text)-1], quote+quote, quote)
+}
+
+// normalizeSQLForDialect rewrites common cross-dialect mistakes in generated SQL:
+// identifier quoting, TOP n and FETCH FIRST n ROWS ONLY become LIMIT n, and
+// trailing semicolons are removed.
+func normalizeSQLForDialect(sql string, dialect models.SQLDialect) (string, error) {
+	tokens, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	appendLimit := ""
+
+	sig := significantTokens(tokens)
+	for i := 0; i < len(sig); i++ {
+		t := sig[i]
+		switch {
+		case t.kind == sqlTokenQuotedIdent && dialect != models.SQLDialectBigQuery && strings.HasPrefix(t.text, "`"):
+			edits = append(edits, edit{t.start, t.end, quoteIdentifier(unquoteIdentifier(t.text), dialect)})
+
+		case t.isWord("SELECT") && i+2 < len(sig) && sig[i+1].isWord("TOP") && sig[i+2].kind == sqlTokenNumber:
+			// SELECT TOP n -> SELECT ... LIMIT n
+			end := sig[i+2].end
+			if i+3 < len(sig) {
+				end = sig[i+3].start
+			}
+			edits = append(edits, edit{sig[i+1].start, end, ""})
+			appendLimit = sig[i+2].text
+			i += 2
+
+		case t.isWord("FETCH") && i+4 < len(sig) && (sig[i+1].isWord("FIRST") || sig[i+1].isWord("NEXT")) &&
+			sig[i+2].kind == sqlTokenNumber && (sig[i+3].isWord("ROWS") || sig[i+3].isWord("ROW")) && sig[i+4].isWord("ONLY"):
+			edits = append(edits, edit{t.start, sig[i+4].end, "LIMIT " + sig[i+2].text})
+			i += 4
+		}
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, e := range edits {
+		builder.WriteString(sql[last:e.start])
+		builder.WriteString(e.text)
+		last = e.end
+	}
+	builder.WriteString(sql[last:])
+
+	normalized := strings.TrimSpace(builder.String())
+	for strings.HasSuffix(normalized, ";") {
+		normalized = strings.TrimSpace(strings.TrimSuffix(normalized, ";"))
+	}
+
+	if appendLimit != "" {
+		normalizedTokens, err := tokenizeSQL(normalized, dialect)
+		if err != nil {
+			return "", err
+		}
+		if limitIdx, _ := topLevelLimit(significantTokens(normalizedTokens)); limitIdx < 0 {
+			normalized += " LIMIT " + appendLimit
+		}
+	}
+
+	return normalized, nil
+}
+
+// enforceLimitForDialect makes sure the query returns at most limit rows by
+// lowering an existing top-level LIMIT or inserting one (before OFFSET, which
+// every supported dialect accepts).
+func enforceLimitForDialect(sql string, limit int, dialect models.SQLDialect) (string, error) {
+	normalized, err := normalizeSQLForDialect(sql, dialect)
+	if err != nil {
+		return "", err
+	}
+
+	tokens, err := tokenizeSQL(normalized, dialect)
+	if err != nil {
+		return "", err
+	}
+	sig := significantTokens(tokens)
+	limitIdx, offsetIdx := topLevelLimit(sig)
+	limitText := strconv.Itoa(limit)
+
+	switch {
+	case limitIdx >= 0 && limitIdx+1 < len(sig):
+		value := sig[limitIdx+1]
+		if value.kind == sqlTokenNumber {
+			if current, err := strconv.Atoi(value.text); err == nil && current <= limit {
+				return normalized, nil
+			}
+		}
+		// Replace larger, ALL or parameterised limits
+		return normalized[:value.start] + limitText + normalized[value.end:], nil
+	case limitIdx >= 0:
+		return normalized + " " + limitText, nil
+	case offsetIdx >= 0:
+		offset := sig[offsetIdx]
+		return normalized[:offset.start] + "LIMIT " + limitText + " " + normalized[offset.start:], nil
+	default:
+		return normalized + " LIMIT " + limitText, nil
+	}
+}
+
+// toParserSQL renders the tokens in the MySQL flavour understood by the
+// structural parser: identifiers in backticks, strings in single quotes,
+// casts (::type) dropped and ILIKE treated as LIKE.
+func toParserSQL(tokens []sqlToken) string {
+	parts := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.kind {
+		case sqlTokenComment:
+			continue
+		case sqlTokenQuotedIdent:
+			parts = append(parts, "`"+strings.ReplaceAll(unquoteIdentifier(t.text), "`", "``")+"`")
+		case sqlTokenString:
+			parts = append(parts, "'x'")
+		case sqlTokenParam:
+			parts = append(parts, "?")
+		case sqlTokenSymbol:
+			if t.text == "::" {
+				i = skipCastType(tokens, i+1) - 1
+				continue
+			}
+			if t.text == ";" {
+				continue
+			}
+			parts = append(parts, t.text)
+		default:
+			if t.isWord("ILIKE") {
+				parts = append(parts, "LIKE")
+				continue
+			}
+			parts = append(parts, t.text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// castTypeWords are words that can continue a multi-word type name after ::
+var castTypeWords = map[string]bool{
+	"PRECISION": true, "VARYING": true, "WITH": true, "WITHOUT": true, "TIME": true, "ZONE": true,
+}
+
+// skipCastType returns the index just past the type name that follows ::
+func skipCastType(tokens []sqlToken, i int) int {
+	if i < len(tokens) && (tokens[i].kind == sqlTokenWord || tokens[i].kind == sqlTokenQuotedIdent) {
+		i++
+	}
+	for i < len(tokens) && tokens[i].kind == sqlTokenWord && castTypeWords[tokens[i].upper()] {
+		i++
+	}
+	if i < len(tokens) && tokens[i].isSymbol("(") {
+		for i < len(tokens) && !tokens[i].isSymbol(")") {
+			i++
+		}
+		i++
+	}
+	for i+1 < len(tokens) && tokens[i].isSymbol("[") && tokens[i+1].isSymbol("]") {
+		i += 2
+	}
+	return i
+}
+
+// dialectPromptGuidance tells the model which SQL flavour to emit
+func dialectPromptGuidance(dialect models.SQLDialect) string {
+	switch dialect {
+	case models.SQLDialectBigQuery:
+		return "Use BigQuery Standard SQL: quote identifiers with backticks (`dataset.table`), use single quotes for strings, " +
+			"DATE_TRUNC(col, MONTH) / TIMESTAMP_TRUNC for time buckets, SAFE_DIVIDE for ratios, and LIMIT n for row limits."
+	case models.SQLDialectDuckDB:
+		return "Use DuckDB SQL: quote identifiers with double quotes, use single quotes for strings, " +
+			"date_trunc('month', col) and strftime for dates, and LIMIT n for row limits."
+	case models.SQLDialectMongoDB:
+		return "Use a MongoDB aggregation pipeline: filter early with $match, group with $group and accumulators such as $sum and $avg, " +
+			"bucket dates with $dateTrunc, reference fields as \"$field\" and nested fields with dot paths, and end with $limit."
+	default:
+		return "Use PostgreSQL: quote identifiers with double quotes, use single quotes for strings, " +
+			"date_trunc('month', col) and col::type casts, ILIKE for case-insensitive matching, and LIMIT n for row limits (never TOP)."
+	}
+}
+
+// quoteStringLiteral quotes a string value as a SQL literal for the dialect
+func quoteStringLiteral(value string, dialect models.SQLDialect) string {
+	if dialect == models.SQLDialectBigQuery {
+		escaped := strings.ReplaceAll(value, `\`, `\\`)
+		return "'" + strings.ReplaceAll(escaped, "'", `\'`) + "'"
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	models "narapulse-be/internal/models/entity"
@@ -13,26 +14,96 @@ import (
 // SQLValidatorService handles SQL validation and safety checks
 type SQLValidatorService struct {
 	allowedFunctions []string
+	dialectFunctions map[models.SQLDialect][]string
+	blockedFunctions map[models.SQLDialect][]string
 	blockedKeywords  []string
+	dialectKeywords  map[models.SQLDialect][]string
 	maxJoinTables    int
 	maxRowLimit      int
 }
 
+// ValidationRules extend the built-in validator rules for one data source.
+// They are compiled from a ValidationPolicy; a nil *ValidationRules applies
+// only the built-in rules.
+type ValidationRules struct {
+	PolicyID         uint
+	AllowedFunctions map[string]bool
+	BlockedFunctions map[string]bool
+	BlockedKeywords  map[string]bool
+	BlockedPatterns  []*regexp.Regexp
+	MaxJoins         int              // Exceeding it is a violation; 0 keeps the default table-count warning
+	MaxLimit         int              // Largest LIMIT allowed; 0 keeps the default
+	LargeTables      map[string]int64 // Lower-case table name to row count; queries on them need a WHERE clause
+}
+
+// RowLimit returns the limit to enforce: the requested limit capped by the policy maximum
+func (r *ValidationRules) RowLimit(limit int) int {
+	if r != nil && r.MaxLimit > 0 && (limit <= 0 || limit > r.MaxLimit) {
+		return r.MaxLimit
+	}
+	return limit
+}
+
+// nonFunctionKeywords are keywords that may be directly followed by "(" without being a function call
+var nonFunctionKeywords = map[string]bool{
+	"IN": true, "AND": true, "OR": true, "NOT": true, "OVER": true, "AS": true, "EXISTS": true,
+	"FROM": true, "JOIN": true, "ON": true, "WHERE": true, "VALUES": true, "USING": true,
+	"FILTER": true, "WITHIN": true, "SELECT": true, "ANY": true, "ALL": true, "SOME": true,
+	"BETWEEN": true, "WHEN": true, "THEN": true, "ELSE": true, "BY": true, "HAVING": true,
+	"LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true, "EXCEPT": true,
+	"WITH": true, "LATERAL": true, "ROWS": true, "RANGE": true, "ARRAY": true, "STRUCT": true,
+	"INTERVAL": true, "DISTINCT": true, "IS": true, "LIKE": true, "ILIKE": true,
+}
+
 // NewSQLValidatorService creates a new SQL validator service
 func NewSQLValidatorService() *SQLValidatorService {
 	return &SQLValidatorService{
 		allowedFunctions: []string{
 			// Aggregate functions
-			"COUNT", "SUM", "AVG", "MIN", "MAX",
+			"COUNT", "SUM", "AVG", "MIN", "MAX", "STDDEV", "VARIANCE", "STRING_AGG", "ARRAY_AGG",
 			// String functions
-			"UPPER", "LOWER", "TRIM", "LENGTH", "SUBSTRING", "CONCAT",
+			"UPPER", "LOWER", "TRIM", "LENGTH", "SUBSTRING", "CONCAT", "REPLACE", "LEFT", "RIGHT",
+			"LPAD", "RPAD", "POSITION", "REGEXP_REPLACE",
 			// Date functions
 			"DATE", "YEAR", "MONTH", "DAY", "DATE_TRUNC", "DATE_ADD", "DATE_SUB",
 			"EXTRACT", "NOW", "CURRENT_DATE", "CURRENT_TIMESTAMP",
 			// Math functions
-			"ROUND", "CEIL", "FLOOR", "ABS", "COALESCE", "NULLIF",
-			// Conditional functions
-			"CASE", "IF", "IFNULL",
+			"ROUND", "CEIL", "FLOOR", "ABS", "COALESCE", "NULLIF", "GREATEST", "LEAST",
+			"POWER", "SQRT", "MOD",
+			// Conditional and conversion functions
+			"CASE", "IF", "IFNULL", "CAST",
+			// Window functions
+			"ROW_NUMBER", "RANK", "DENSE_RANK", "LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE", "NTILE",
+		},
+		dialectFunctions: map[models.SQLDialect][]string{
+			models.SQLDialectPostgreSQL: {
+				"TO_CHAR", "TO_DATE", "TO_TIMESTAMP", "AGE", "DATE_PART", "SPLIT_PART", "INITCAP",
+				"MAKE_DATE", "BOOL_AND", "BOOL_OR", "PERCENTILE_CONT", "JSONB_EXTRACT_PATH_TEXT",
+			},
+			models.SQLDialectBigQuery: {
+				"FORMAT_DATE", "FORMAT_TIMESTAMP", "PARSE_DATE", "PARSE_TIMESTAMP", "DATE_DIFF",
+				"TIMESTAMP_DIFF", "TIMESTAMP_TRUNC", "DATETIME_TRUNC", "SAFE_DIVIDE", "SAFE_CAST",
+				"COUNTIF", "APPROX_COUNT_DISTINCT", "DATETIME", "TIMESTAMP", "CURRENT_DATETIME",
+				"FORMAT", "STARTS_WITH", "ENDS_WITH", "SPLIT", "UNNEST", "ARRAY_LENGTH",
+			},
+			models.SQLDialectDuckDB: {
+				"STRFTIME", "STRPTIME", "DATE_DIFF", "DATEDIFF", "DATE_PART", "EPOCH", "MAKE_DATE",
+				"STRING_SPLIT", "MEDIAN", "MODE", "QUANTILE_CONT", "COUNT_IF", "ARG_MAX", "ARG_MIN",
+			},
+		},
+		blockedFunctions: map[models.SQLDialect][]string{
+			models.SQLDialectPostgreSQL: {
+				"PG_SLEEP", "PG_READ_FILE", "PG_READ_BINARY_FILE", "PG_LS_DIR", "PG_TERMINATE_BACKEND",
+				"PG_CANCEL_BACKEND", "PG_RELOAD_CONF", "LO_IMPORT", "LO_EXPORT", "DBLINK",
+				"SET_CONFIG", "CURRENT_SETTING",
+			},
+			models.SQLDialectBigQuery: {
+				"EXTERNAL_QUERY",
+			},
+			models.SQLDialectDuckDB: {
+				"READ_CSV", "READ_CSV_AUTO", "READ_PARQUET", "PARQUET_SCAN", "READ_JSON",
+				"READ_JSON_AUTO", "READ_TEXT", "READ_BLOB", "GLOB",
+			},
 		},
 		blockedKeywords: []string{
 			// DML operations
@@ -45,19 +116,42 @@ func NewSQLValidatorService() *SQLValidatorService {
 			"COMMIT", "ROLLBACK", "SAVEPOINT",
 			// System functions
 			"EXEC", "EXECUTE", "CALL", "LOAD", "COPY",
-			// File operations
-			"INTO OUTFILE", "LOAD DATA", "SELECT INTO",
+			// File operations and SELECT INTO
+			"INTO", "OUTFILE",
 			// Administrative
 			"SHOW", "DESCRIBE", "EXPLAIN", "ANALYZE",
 		},
+		dialectKeywords: map[models.SQLDialect][]string{
+			models.SQLDialectPostgreSQL: {"VACUUM", "LISTEN", "NOTIFY", "LOCK", "SET", "RESET", "DO"},
+			models.SQLDialectBigQuery:   {"EXPORT", "DECLARE", "SET"},
+			models.SQLDialectDuckDB:     {"ATTACH", "DETACH", "INSTALL", "PRAGMA", "EXPORT", "IMPORT", "SET"},
+		},
 		maxJoinTables: 5,
 		maxRowLimit:   10000,
 	}
 }
 
-// ValidateSQL validates a SQL query for safety and compliance
+// ValidateSQL validates a SQL query for safety and compliance using the PostgreSQL dialect
 func (s *SQLValidatorService) ValidateSQL(sql string) (*models.SQLValidationResult, error) {
+	return s.ValidateSQLForDialect(sql, models.SQLDialectPostgreSQL)
+}
+
+// ValidateSQLForDialect validates a SQL query for safety and compliance. Keyword and
+// function checks work on dialect-aware tokens, so text inside string literals and
+// quoted identifiers never triggers them.
+func (s *SQLValidatorService) ValidateSQLForDialect(sql string, dialect models.SQLDialect) (*models.SQLValidationResult, error) {
+	return s.ValidateSQLWithRules(sql, dialect, nil)
+}
+
+// ValidateSQLWithRules validates a SQL query with the built-in rules extended by
+// the validation policy of a data source
+func (s *SQLValidatorService) ValidateSQLWithRules(sql string, dialect models.SQLDialect, rules *ValidationRules) (*models.SQLValidationResult, error) {
+	if !dialect.IsValid() {
+		dialect = models.SQLDialectPostgreSQL
+	}
+
 	result := &models.SQLValidationResult{
+		Dialect:      dialect,
 		IsValid:      false,
 		IsReadOnly:   false,
 		HasLimit:     false,
@@ -73,22 +167,35 @@ func (s *SQLValidatorService) ValidateSQL(sql string) (*models.SQLValidationResu
 		return result, errors.New("empty SQL query")
 	}
 
-	// Check for blocked keywords
-	if violations := s.checkBlockedKeywords(sql); len(violations) > 0 {
-		result.Violations = append(result.Violations, violations...)
-		return result, errors.New("SQL contains blocked operations")
-	}
-
-	// Parse SQL using sqlparser
-	stmt, err := sqlparser.Parse(sql)
+	tokens, err := tokenizeSQL(sql, dialect)
 	if err != nil {
 		result.Violations = append(result.Violations, fmt.Sprintf("SQL parsing error: %v", err))
 		return result, fmt.Errorf("failed to parse SQL: %v", err)
 	}
+	sig := significantTokens(tokens)
 
-	// Validate that it's a SELECT statement
-	selectStmt, ok := stmt.(*sqlparser.Select)
-	if !ok {
+	// Only a single statement is allowed; a trailing semicolon is tolerated
+	for i, t := range sig {
+		if t.isSymbol(";") && i != len(sig)-1 {
+			result.Violations = append(result.Violations, "Multiple statements are not allowed")
+			return result, errors.New("multiple statements are not allowed")
+		}
+	}
+
+	// Check for patterns blocked by the policy
+	if violations := s.checkBlockedPatterns(sql, rules); len(violations) > 0 {
+		result.Violations = append(result.Violations, violations...)
+		return result, errors.New("SQL matches a blocked pattern")
+	}
+
+	// Check for blocked keywords
+	if violations := s.checkBlockedKeywords(sig, dialect, rules); len(violations) > 0 {
+		result.Violations = append(result.Violations, violations...)
+		return result, errors.New("SQL contains blocked operations")
+	}
+
+	// Validate that it's a SELECT statement (optionally with CTEs)
+	if !s.isSelectStatement(sig) {
 		result.Violations = append(result.Violations, "Only SELECT statements are allowed")
 		return result, errors.New("only SELECT statements are allowed")
 	}
@@ -96,24 +203,48 @@ func (s *SQLValidatorService) ValidateSQL(sql string) (*models.SQLValidationResu
 	result.IsReadOnly = true
 
 	// Check for LIMIT clause
-	result.HasLimit = s.hasLimitClause(selectStmt)
+	limitIdx, _ := topLevelLimit(sig)
+	result.HasLimit = limitIdx >= 0
 	if !result.HasLimit {
 		result.Warnings = append(result.Warnings, "Query should include LIMIT clause for performance")
 	}
 
-	// Validate JOIN complexity
-	if warnings := s.validateJoinComplexity(selectStmt); len(warnings) > 0 {
-		result.Warnings = append(result.Warnings, warnings...)
+	// Structural checks use the parser when the query fits its grammar. A policy
+	// join limit is enforced; the default limit only warns.
+	maxJoins := s.maxJoinTables
+	if rules != nil && rules.MaxJoins > 0 {
+		maxJoins = rules.MaxJoins + 1 // Joined tables, not joins
+	}
+	selectStmt := s.parseSelect(sig)
+	var joinIssues []string
+	if selectStmt != nil {
+		joinIssues = s.validateJoinComplexity(selectStmt, maxJoins)
+	} else if tableCount := s.countTablesLexically(sig); tableCount > maxJoins {
+		joinIssues = []string{fmt.Sprintf("Query joins too many tables (%d > %d)", tableCount, maxJoins)}
+	}
+	if rules != nil && rules.MaxJoins > 0 {
+		result.Violations = append(result.Violations, joinIssues...)
+	} else {
+		result.Warnings = append(result.Warnings, joinIssues...)
 	}
 
 	// Validate functions
-	if violations := s.validateFunctions(sql); len(violations) > 0 {
+	if violations := s.validateFunctions(sig, dialect, rules); len(violations) > 0 {
 		result.Violations = append(result.Violations, violations...)
 		return result, errors.New("SQL contains unauthorized functions")
 	}
 
+	// Row limit and WHERE requirements of the policy
+	if violations := s.checkPolicyLimits(sig, limitIdx, rules); len(violations) > 0 {
+		result.Violations = append(result.Violations, violations...)
+		return result, errors.New("SQL violates the validation policy")
+	}
+	if len(result.Violations) > 0 {
+		return result, errors.New("SQL violates the validation policy")
+	}
+
 	// Check for potential security issues
-	if warnings := s.checkSecurityIssues(sql); len(warnings) > 0 {
+	if warnings := s.checkSecurityIssues(tokens); len(warnings) > 0 {
 		result.Warnings = append(result.Warnings, warnings...)
 	}
 
@@ -121,44 +252,92 @@ func (s *SQLValidatorService) ValidateSQL(sql string) (*models.SQLValidationResu
 	result.SafetyScore = s.calculateSafetyScore(result)
 
 	// Estimate cost (simplified)
-	result.EstimatedCost = s.estimateQueryCost(selectStmt)
+	if selectStmt != nil {
+		result.EstimatedCost = s.estimateQueryCost(selectStmt)
+	} else {
+		result.EstimatedCost = s.estimateQueryCostLexically(sig)
+	}
 
 	result.IsValid = len(result.Violations) == 0
 
 	return result, nil
 }
 
-// EnforceLimit adds or modifies LIMIT clause in SQL
+// EnforceLimit adds or modifies LIMIT clause in PostgreSQL SQL
 func (s *SQLValidatorService) EnforceLimit(sql string, limit int) (string, error) {
+	return s.EnforceLimitForDialect(sql, limit, models.SQLDialectPostgreSQL)
+}
+
+// EnforceLimitForDialect adds or lowers the LIMIT clause, keeping the original
+// formatting and translating TOP/FETCH FIRST and identifier quoting to the dialect
+func (s *SQLValidatorService) EnforceLimitForDialect(sql string, limit int, dialect models.SQLDialect) (string, error) {
 	if limit <= 0 || limit > s.maxRowLimit {
 		limit = s.maxRowLimit
 	}
 
-	stmt, err := sqlparser.Parse(sql)
+	tokens, err := tokenizeSQL(sql, dialect)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse SQL: %v", err)
 	}
-
-	selectStmt, ok := stmt.(*sqlparser.Select)
-	if !ok {
+	if !s.isSelectStatement(significantTokens(tokens)) {
 		return "", errors.New("only SELECT statements are supported")
 	}
 
-	// Add or modify LIMIT clause
-	selectStmt.Limit = &sqlparser.Limit{
-		Rowcount: sqlparser.NewIntVal([]byte(fmt.Sprintf("%d", limit))),
-	}
+	return enforceLimitForDialect(sql, limit, dialect)
+}
 
-	return sqlparser.String(selectStmt), nil
+// CapLimitForDialect lowers a top-level LIMIT above max and leaves queries
+// without a LIMIT unchanged
+func (s *SQLValidatorService) CapLimitForDialect(sql string, max int, dialect models.SQLDialect) string {
+	if max <= 0 {
+		return sql
+	}
+	tokens, err := tokenizeSQL(sql, dialect)
+	if err != nil {
+		return sql
+	}
+	if limitIdx, _ := topLevelLimit(significantTokens(tokens)); limitIdx < 0 {
+		return sql
+	}
+	if capped, err := s.EnforceLimitForDialect(sql, max, dialect); err == nil {
+		return capped
+	}
+	return sql
 }
 
-// checkBlockedKeywords checks for blocked SQL keywords
-func (s *SQLValidatorService) checkBlockedKeywords(sql string) []string {
-	var violations []string
-	sqlUpper := strings.ToUpper(sql)
+// NormalizeSQL rewrites quoting and row-limit syntax to the given dialect
+func (s *SQLValidatorService) NormalizeSQL(sql string, dialect models.SQLDialect) (string, error) {
+	return normalizeSQLForDialect(sql, dialect)
+}
 
+// checkBlockedKeywords checks for blocked SQL keywords outside literals and quoted identifiers
+func (s *SQLValidatorService) checkBlockedKeywords(tokens []sqlToken, dialect models.SQLDialect, rules *ValidationRules) []string {
+	blocked := make(map[string]bool)
 	for _, keyword := range s.blockedKeywords {
-		if strings.Contains(sqlUpper, keyword) {
+		blocked[keyword] = true
+	}
+	for _, keyword := range s.dialectKeywords[dialect] {
+		blocked[keyword] = true
+	}
+	if rules != nil {
+		for keyword := range rules.BlockedKeywords {
+			blocked[keyword] = true
+		}
+	}
+
+	var violations []string
+	reported := make(map[string]bool)
+	for i, t := range tokens {
+		if t.kind != sqlTokenWord {
+			continue
+		}
+		// Qualified names such as orders.update are column references, not keywords
+		if i > 0 && tokens[i-1].isSymbol(".") {
+			continue
+		}
+		keyword := t.upper()
+		if blocked[keyword] && !reported[keyword] {
+			reported[keyword] = true
 			violations = append(violations, fmt.Sprintf("Blocked keyword detected: %s", keyword))
 		}
 	}
@@ -166,20 +345,48 @@ func (s *SQLValidatorService) checkBlockedKeywords(sql string) []string {
 	return violations
 }
 
-// hasLimitClause checks if the SELECT statement has a LIMIT clause
-func (s *SQLValidatorService) hasLimitClause(stmt *sqlparser.Select) bool {
-	return stmt.Limit != nil
+// isSelectStatement checks that the statement starts with SELECT or WITH
+func (s *SQLValidatorService) isSelectStatement(tokens []sqlToken) bool {
+	for _, t := range tokens {
+		if t.isSymbol("(") {
+			continue
+		}
+		return t.isWord("SELECT") || t.isWord("WITH")
+	}
+	return false
+}
+
+// parseSelect parses the query with the structural parser, returning nil
+// when it uses syntax the parser does not support (CTEs, dialect functions)
+func (s *SQLValidatorService) parseSelect(tokens []sqlToken) *sqlparser.Select {
+	stmt, err := sqlparser.Parse(toParserSQL(tokens))
+	if err != nil {
+		return nil
+	}
+	selectStmt, _ := stmt.(*sqlparser.Select)
+	return selectStmt
+}
+
+// countTablesLexically counts FROM and JOIN sources when the query cannot be parsed
+func (s *SQLValidatorService) countTablesLexically(tokens []sqlToken) int {
+	count := 0
+	for _, t := range tokens {
+		if t.isWord("FROM") || t.isWord("JOIN") {
+			count++
+		}
+	}
+	return count
 }
 
 // validateJoinComplexity validates the complexity of JOIN operations
-func (s *SQLValidatorService) validateJoinComplexity(stmt *sqlparser.Select) []string {
+func (s *SQLValidatorService) validateJoinComplexity(stmt *sqlparser.Select, maxJoins int) []string {
 	var warnings []string
 
 	// Count tables in FROM clause
 	tableCount := s.countTablesInFrom(stmt.From)
 
-	if tableCount > s.maxJoinTables {
-		warnings = append(warnings, fmt.Sprintf("Query joins too many tables (%d > %d)", tableCount, s.maxJoinTables))
+	if tableCount > maxJoins {
+		warnings = append(warnings, fmt.Sprintf("Query joins too many tables (%d > %d)", tableCount, maxJoins))
 	}
 
 	return warnings
@@ -203,67 +410,232 @@ func (s *SQLValidatorService) countTablesInFrom(from []sqlparser.TableExpr) int
 func (s *SQLValidatorService) countTablesInJoin(join *sqlparser.JoinTableExpr) int {
 	count := 0
 	
-	// Count left side
-	if _, ok := join.LeftExpr.(*sqlparser.AliasedTableExpr); ok {
-		count++
-	}
-	
-	// Count right side
-	if _, ok := join.RightExpr.(*sqlparser.AliasedTableExpr); ok {
-		count++
+	// Count both sides, descending into chained joins
+	for _, side := range []sqlparser.TableExpr{join.LeftExpr, join.RightExpr} {
+		switch t := side.(type) {
+		case *sqlparser.AliasedTableExpr:
+			count++
+		case *sqlparser.JoinTableExpr:
+			count += s.countTablesInJoin(t)
+		}
 	}
-	
+
 	return count
 }
 
 // validateFunctions validates that only allowed functions are used
-func (s *SQLValidatorService) validateFunctions(sql string) []string {
+func (s *SQLValidatorService) validateFunctions(tokens []sqlToken, dialect models.SQLDialect, rules *ValidationRules) []string {
 	var violations []string
 
-	// Simple regex to find function calls
-	funcRegex := regexp.MustCompile(`(?i)\b([A-Z_]+)\s*\(`)
-	matches := funcRegex.FindAllStringSubmatch(sql, -1)
+	for i := 0; i+1 < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind != sqlTokenWord || !tokens[i+1].isSymbol("(") {
+			continue
+		}
+		funcName := t.upper()
+		if nonFunctionKeywords[funcName] {
+			continue
+		}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			funcName := strings.ToUpper(match[1])
-			if !s.isFunctionAllowed(funcName) {
-				violations = append(violations, fmt.Sprintf("Unauthorized function: %s", funcName))
-			}
+		switch {
+		case s.isFunctionBlocked(funcName, dialect), rules != nil && rules.BlockedFunctions[funcName]:
+			violations = append(violations, fmt.Sprintf("Blocked function: %s", funcName))
+		case rules != nil && rules.AllowedFunctions[funcName]:
+		case !s.isFunctionAllowed(funcName, dialect):
+			violations = append(violations, fmt.Sprintf("Unauthorized function: %s", funcName))
 		}
 	}
 
 	return violations
 }
 
-// isFunctionAllowed checks if a function is in the allowed list
-func (s *SQLValidatorService) isFunctionAllowed(funcName string) bool {
+// isFunctionAllowed checks if a function is in the common or dialect allowed list
+func (s *SQLValidatorService) isFunctionAllowed(funcName string, dialect models.SQLDialect) bool {
 	for _, allowed := range s.allowedFunctions {
 		if allowed == funcName {
 			return true
 		}
 	}
+	for _, allowed := range s.dialectFunctions[dialect] {
+		if allowed == funcName {
+			return true
+		}
+	}
 	return false
 }
 
-// checkSecurityIssues checks for potential security issues
-func (s *SQLValidatorService) checkSecurityIssues(sql string) []string {
-	var warnings []string
-	sqlUpper := strings.ToUpper(sql)
+// isFunctionBlocked checks if a function is explicitly blocked for the dialect
+func (s *SQLValidatorService) isFunctionBlocked(funcName string, dialect models.SQLDialect) bool {
+	for _, blocked := range s.blockedFunctions[dialect] {
+		if blocked == funcName {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBlockedPatterns matches the policy's blocked patterns against the query text
+func (s *SQLValidatorService) checkBlockedPatterns(sql string, rules *ValidationRules) []string {
+	if rules == nil {
+		return nil
+	}
+
+	var violations []string
+	for _, pattern := range rules.BlockedPatterns {
+		if pattern.MatchString(sql) {
+			violations = append(violations, fmt.Sprintf("Query matches blocked pattern: %s", pattern.String()))
+		}
+	}
+	return violations
+}
+
+// checkPolicyLimits enforces the policy's maximum LIMIT and WHERE requirement on large tables
+func (s *SQLValidatorService) checkPolicyLimits(tokens []sqlToken, limitIdx int, rules *ValidationRules) []string {
+	if rules == nil {
+		return nil
+	}
+
+	var violations []string
+	if rules.MaxLimit > 0 && limitIdx >= 0 && limitIdx+1 < len(tokens) {
+		value := tokens[limitIdx+1]
+		if n, err := strconv.Atoi(value.text); value.kind != sqlTokenNumber || err != nil || n > rules.MaxLimit {
+			violations = append(violations, fmt.Sprintf("LIMIT %s exceeds the maximum of %d rows", value.text, rules.MaxLimit))
+		}
+	}
+
+	if len(rules.LargeTables) > 0 && !hasWord(tokens, "WHERE") {
+		for _, table := range referencedTables(tokens) {
+			if rowCount, ok := rules.LargeTables[table]; ok {
+				violations = append(violations, fmt.Sprintf("Table %s has %d rows; a WHERE clause is required", table, rowCount))
+			}
+		}
+	}
+
+	return violations
+}
+
+// hasWord reports whether any token is the given keyword
+func hasWord(tokens []sqlToken, keyword string) bool {
+	for _, t := range tokens {
+		if t.isWord(keyword) {
+			return true
+		}
+	}
+	return false
+}
 
-	// Check for potential SQL injection patterns
-	suspiciousPatterns := []string{
-		"--", "/*", "*/", ";",
-		"UNION", "OR 1=1", "AND 1=1",
-		"DROP", "DELETE", "UPDATE",
+// referencedTables returns the lower-case, unqualified names of the tables
+// that follow FROM and JOIN, including comma-separated FROM lists
+func referencedTables(tokens []sqlToken) []string {
+	var tables []string
+	seen := make(map[string]bool)
+	for _, parts := range tableReferences(tokens) {
+		name := parts[len(parts)-1]
+		// Dataset-qualified BigQuery names are quoted as one identifier
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = name[dot+1:]
+		}
+		if name = strings.ToLower(name); name != "" && !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
 	}
+	return tables
+}
 
-	for _, pattern := range suspiciousPatterns {
-		if strings.Contains(sqlUpper, pattern) {
+// tableReferences returns the unquoted name parts of every table reference
+// that follows FROM and JOIN, including comma-separated FROM lists, in order
+func tableReferences(tokens []sqlToken) [][]string {
+	var references [][]string
+
+	readTable := func(i int) int {
+		var parts []string
+		for i < len(tokens) {
+			t := tokens[i]
+			switch t.kind {
+			case sqlTokenWord:
+				parts = append(parts, t.text)
+			case sqlTokenQuotedIdent:
+				parts = append(parts, unquoteIdentifier(t.text))
+			default:
+				if len(parts) > 0 {
+					references = append(references, parts)
+				}
+				return i
+			}
+			i++
+			if i < len(tokens) && tokens[i].isSymbol(".") {
+				i++
+				continue
+			}
+			break
+		}
+		if len(parts) > 0 {
+			references = append(references, parts)
+		}
+		return i
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isWord("FROM") && !tokens[i].isWord("JOIN") {
+			continue
+		}
+		j := readTable(i + 1)
+		// FROM a x, b y: skip the alias and read the next table
+		for j < len(tokens) {
+			if tokens[j].isWord("AS") {
+				j++
+			}
+			if j < len(tokens) && (tokens[j].kind == sqlTokenWord || tokens[j].kind == sqlTokenQuotedIdent) &&
+				!nonFunctionKeywords[tokens[j].upper()] && !isClauseKeyword(tokens[j]) {
+				j++
+			}
+			if j < len(tokens) && tokens[j].isSymbol(",") && j+1 < len(tokens) && !tokens[j+1].isSymbol("(") {
+				j = readTable(j + 1)
+				continue
+			}
+			break
+		}
+	}
+
+	return references
+}
+
+// isClauseKeyword reports whether a word starts a clause after a table reference
+func isClauseKeyword(t sqlToken) bool {
+	switch t.upper() {
+	case "LEFT", "RIGHT", "INNER", "OUTER", "FULL", "CROSS", "NATURAL", "GROUP", "ORDER", "WINDOW", "QUALIFY":
+		return true
+	}
+	return false
+}
+
+// checkSecurityIssues checks for potential security issues
+func (s *SQLValidatorService) checkSecurityIssues(tokens []sqlToken) []string {
+	var warnings []string
+	reported := make(map[string]bool)
+	warn := func(pattern string) {
+		if !reported[pattern] {
+			reported[pattern] = true
 			warnings = append(warnings, fmt.Sprintf("Potentially suspicious pattern detected: %s", pattern))
 		}
 	}
 
+	for i, t := range tokens {
+		switch {
+		case t.kind == sqlTokenComment && strings.HasPrefix(t.text, "/*"):
+			warn("/*")
+		case t.kind == sqlTokenComment:
+			warn("--")
+		case t.isWord("UNION"):
+			warn("UNION")
+		case (t.isWord("OR") || t.isWord("AND")) && i+3 < len(tokens) &&
+			tokens[i+1].kind == sqlTokenNumber && tokens[i+2].isSymbol("=") &&
+			tokens[i+3].kind == sqlTokenNumber && tokens[i+1].text == tokens[i+3].text:
+			warn(fmt.Sprintf("%s %s=%s", t.upper(), tokens[i+1].text, tokens[i+3].text))
+		}
+	}
+
 	return warnings
 }
 
@@ -329,6 +701,30 @@ func (s *SQLValidatorService) estimateQueryCost(stmt *sqlparser.Select) float64
 	return cost
 }
 
+// estimateQueryCostLexically mirrors estimateQueryCost for queries the parser cannot handle
+func (s *SQLValidatorService) estimateQueryCostLexically(tokens []sqlToken) float64 {
+	cost := 0.01 // Base cost
+
+	tableCount := s.countTablesLexically(tokens)
+	cost += float64(tableCount) * 0.005
+	if tableCount > 1 {
+		cost += float64(tableCount-1) * 0.01
+	}
+
+	for i, t := range tokens {
+		switch {
+		case t.isWord("WHERE"):
+			cost += 0.005
+		case t.isWord("GROUP") && i+1 < len(tokens) && tokens[i+1].isWord("BY"):
+			cost += 0.01
+		case t.isWord("ORDER") && i+1 < len(tokens) && tokens[i+1].isWord("BY"):
+			cost += 0.005
+		}
+	}
+
+	return cost
+}
+
 // IsQuerySafe checks if a query meets safety requirements
 func (s *SQLValidatorService) IsQuerySafe(result *models.SQLValidationResult) bool {
 	return result.IsValid && result.IsReadOnly && result.SafetyScore >= 0.7
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestSQLValidatorService_KeywordsInsideNamesAndLiterals(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	// Column names and string literals that contain blocked words are allowed
+	result, err := validator.ValidateSQLForDialect(
+		"SELECT created_at, updated_at FROM orders WHERE status = 'deleted' LIMIT 10",
+		models.SQLDialectPostgreSQL,
+	)
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid)
+	assert.True(t, result.HasLimit)
+	assert.Equal(t, models.SQLDialectPostgreSQL, result.Dialect)
+
+	_, err = validator.ValidateSQLForDialect("DELETE FROM orders", models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+
+	_, err = validator.ValidateSQLForDialect("SELECT 1; DROP TABLE orders", models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+}
+
+func TestSQLValidatorService_DialectSyntax(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	tests := []struct {
+		name    string
+		sql     string
+		dialect models.SQLDialect
+	}{
+		{"postgres casts and ILIKE", "SELECT date_trunc('month', created_at)::date AS m, COUNT(*) FROM orders WHERE name ILIKE '%a%' GROUP BY 1 LIMIT 10", models.SQLDialectPostgreSQL},
+		{"postgres CTE", "WITH recent AS (SELECT id FROM orders) SELECT COUNT(*) FROM recent LIMIT 1", models.SQLDialectPostgreSQL},
+		{"bigquery backticks and double-quoted strings", "SELECT SAFE_DIVIDE(SUM(a), COUNT(*)) FROM `proj.ds.orders` WHERE name = \"bob\" LIMIT 5", models.SQLDialectBigQuery},
+		{"duckdb strftime", "SELECT strftime(created_at, '%Y-%m') AS month FROM sales LIMIT 5", models.SQLDialectDuckDB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateSQLForDialect(tt.sql, tt.dialect)
+			assert.NoError(t, err)
+			assert.True(t, result.IsValid, "violations: %v", result.Violations)
+			assert.True(t, result.HasLimit)
+		})
+	}
+}
+
+func TestSQLValidatorService_BlockedFunctions(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	result, err := validator.ValidateSQLForDialect("SELECT pg_sleep(10)", models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+	assert.Contains(t, result.Violations, "Blocked function: PG_SLEEP")
+
+	result, err = validator.ValidateSQLForDialect("SELECT * FROM read_csv('/etc/passwd')", models.SQLDialectDuckDB)
+	assert.Error(t, err)
+	assert.Contains(t, result.Violations, "Blocked function: READ_CSV")
+
+	// Dialect specific functions are not allowed in other dialects
+	_, err = validator.ValidateSQLForDialect("SELECT SAFE_DIVIDE(a, b) FROM t LIMIT 1", models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+}
+
+func TestSQLValidatorService_EnforceLimitForDialect(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	tests := []struct {
+		name     string
+		sql      string
+		dialect  models.SQLDialect
+		expected string
+	}{
+		{"append", "SELECT * FROM orders;", models.SQLDialectPostgreSQL, "SELECT * FROM orders LIMIT 1000"},
+		{"lower larger limit", "SELECT * FROM orders LIMIT 50000", models.SQLDialectPostgreSQL, "SELECT * FROM orders LIMIT 1000"},
+		{"keep smaller limit", "SELECT * FROM orders LIMIT 10", models.SQLDialectPostgreSQL, "SELECT * FROM orders LIMIT 10"},
+		{"insert before offset", "SELECT * FROM orders OFFSET 5", models.SQLDialectBigQuery, "SELECT * FROM orders LIMIT 1000 OFFSET 5"},
+		{"ignore subquery limit", "SELECT * FROM (SELECT * FROM t LIMIT 5) s", models.SQLDialectPostgreSQL, "SELECT * FROM (SELECT * FROM t LIMIT 5) s LIMIT 1000"},
+		{"translate TOP and backticks", "SELECT TOP 10 * FROM `orders`", models.SQLDialectPostgreSQL, `SELECT * FROM "orders" LIMIT 10`},
+		{"translate FETCH FIRST", "SELECT * FROM orders ORDER BY id FETCH FIRST 20 ROWS ONLY", models.SQLDialectDuckDB, "SELECT * FROM orders ORDER BY id LIMIT 20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.EnforceLimitForDialect(tt.sql, 1000, tt.dialect)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := validator.EnforceLimitForDialect("UPDATE orders SET a = 1", 1000, models.SQLDialectPostgreSQL)
+	assert.Error(t, err)
+}
+
+func TestDialectForDataSourceType(t *testing.T) {
+	assert.Equal(t, models.SQLDialectPostgreSQL, models.DialectForDataSourceType(models.DataSourceTypePostgreSQL))
+	assert.Equal(t, models.SQLDialectBigQuery, models.DialectForDataSourceType(models.DataSourceTypeBigQuery))
+	assert.Equal(t, models.SQLDialectDuckDB, models.DialectForDataSourceType(models.DataSourceTypeCSV))
+	assert.Equal(t, models.SQLDialectDuckDB, models.DialectForDataSourceType(models.DataSourceTypeGoogleSheets))
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSQLVersionNotFound is returned when a query has no such SQL version
+var ErrSQLVersionNotFound = errors.New("SQL version not found")
+
+// SQLVersionService keeps the append-only version history of query SQL, so
+// audits can show exactly which SQL a query held at any point in time
+type SQLVersionService struct {
+	db *gorm.DB
+}
+
+// NewSQLVersionService creates a new SQL version service
+func NewSQLVersionService(db *gorm.DB) *SQLVersionService {
+	return &SQLVersionService{db: db}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *SQLVersionService) WithContext(ctx context.Context) *SQLVersionService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Record appends the current SQL of the query as a new version and sets
+// query.SQLVersion. It must run inside tx; the query row is locked so
+// concurrent edits get consecutive version numbers.
+func (s *SQLVersionService) Record(tx *gorm.DB, query *models.NL2SQLQuery, source models.SQLVersionSource, createdBy uint, comment string, rolledBackFrom int) (*models.QuerySQLVersion, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&models.NL2SQLQuery{}, query.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock query: %w", err)
+	}
+
+	var latest int
+	if err := tx.Model(&models.QuerySQLVersion{}).Where("query_id = ?", query.ID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest SQL version: %w", err)
+	}
+
+	version := &models.QuerySQLVersion{
+		TenantID:       query.TenantID,
+		QueryID:        query.ID,
+		Version:        latest + 1,
+		SQL:            query.GeneratedSQL,
+		Source:         source,
+		RolledBackFrom: rolledBackFrom,
+		Comment:        comment,
+		CreatedBy:      createdBy,
+	}
+	if err := tx.Create(version).Error; err != nil {
+		return nil, fmt.Errorf("failed to record SQL version: %w", err)
+	}
+
+	query.SQLVersion = version.Version
+	return version, nil
+}
+
+// List returns every version of a query, oldest first
+func (s *SQLVersionService) List(queryID uint) ([]models.QuerySQLVersion, error) {
+	var versions []models.QuerySQLVersion
+	if err := s.db.Where("query_id = ?", queryID).Order("version ASC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list SQL versions: %w", err)
+	}
+	return versions, nil
+}
+
+// Get returns a single version of a query
+func (s *SQLVersionService) Get(queryID uint, version int) (*models.QuerySQLVersion, error) {
+	var v models.QuerySQLVersion
+	if err := s.db.Where("query_id = ? AND version = ?", queryID, version).First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSQLVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get SQL version: %w", err)
+	}
+	return &v, nil
+}
+
+// Diff returns the line diff between two versions of a query
+func (s *SQLVersionService) Diff(queryID uint, fromVersion, toVersion int) (*models.SQLVersionDiff, error) {
+	from, err := s.Get(queryID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.Get(queryID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := diffSQLLines(from.SQL, to.SQL)
+	return &models.SQLVersionDiff{
+		QueryID:     queryID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Unified:     formatUnifiedDiff(lines, fmt.Sprintf("v%d", fromVersion), fmt.Sprintf("v%d", toVersion)),
+		Lines:       lines,
+	}, nil
+}
+
+// diffSQLLines computes a line diff using the longest common subsequence
+func diffSQLLines(from, to string) []models.SQLDiffLine {
+	a := strings.Split(strings.TrimRight(from, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(to, "\n"), "\n")
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []models.SQLDiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, models.SQLDiffLine{Op: models.SQLDiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, models.SQLDiffLine{Op: models.SQLDiffDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, models.SQLDiffLine{Op: models.SQLDiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, models.SQLDiffLine{Op: models.SQLDiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, models.SQLDiffLine{Op: models.SQLDiffInsert, Text: b[j]})
+	}
+
+	return lines
+}
+
+// formatUnifiedDiff renders diff lines in unified diff style without hunks
+func formatUnifiedDiff(lines []models.SQLDiffLine, fromLabel, toLabel string) string {
+	var builder strings.Builder
+	builder.WriteString("--- " + fromLabel + "\n")
+	builder.WriteString("+++ " + toLabel + "\n")
+	for _, line := range lines {
+		switch line.Op {
+		case models.SQLDiffInsert:
+			builder.WriteString("+")
+		case models.SQLDiffDelete:
+			builder.WriteString("-")
+		default:
+			builder.WriteString(" ")
+		}
+		builder.WriteString(line.Text + "\n")
+	}
+	return builder.String()
+}
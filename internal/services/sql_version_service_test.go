@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	models "narapulse-be/internal/models/entity"
+)
+
+func TestDiffSQLLines(t *testing.T) {
+	from := "SELECT region, SUM(total)\nFROM orders\nGROUP BY region\nLIMIT 1000"
+	to := "SELECT region, SUM(total)\nFROM orders\nWHERE status = 'paid'\nGROUP BY region\nLIMIT 100"
+
+	lines := diffSQLLines(from, to)
+	assert.Equal(t, []models.SQLDiffLine{
+		{Op: models.SQLDiffEqual, Text: "SELECT region, SUM(total)"},
+		{Op: models.SQLDiffEqual, Text: "FROM orders"},
+		{Op: models.SQLDiffInsert, Text: "WHERE status = 'paid'"},
+		{Op: models.SQLDiffEqual, Text: "GROUP BY region"},
+		{Op: models.SQLDiffDelete, Text: "LIMIT 1000"},
+		{Op: models.SQLDiffInsert, Text: "LIMIT 100"},
+	}, lines)
+
+	unified := formatUnifiedDiff(lines, "v1", "v2")
+	assert.Equal(t, "--- v1\n+++ v2\n SELECT region, SUM(total)\n FROM orders\n+WHERE status = 'paid'\n GROUP BY region\n-LIMIT 1000\n+LIMIT 100\n", unified)
+}
+
+func TestDiffSQLLines_Identical(t *testing.T) {
+	lines := diffSQLLines("SELECT 1", "SELECT 1")
+	assert.Equal(t, []models.SQLDiffLine{{Op: models.SQLDiffEqual, Text: "SELECT 1"}}, lines)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+// ErrSuggestedQuestionNotFound is returned when a curated question does not exist
+var ErrSuggestedQuestionNotFound = errors.New("suggested question not found")
+
+// Bounds of question suggestions
+const (
+	defaultSuggestionLimit = 10
+	maxSuggestionLimit     = 50
+	suggestionMiningDays   = 90 // Questions of the last days are mined
+	recentSuggestionsRead  = 100
+)
+
+// SuggestedQuestionService suggests example questions for a data source to
+// seed the ask-a-question UI: the curated ones first, then questions mined from
+// the query history that converted to valid SQL, the most frequently asked
+// before the most recent
+type SuggestedQuestionService struct {
+	db        *gorm.DB
+	questions *QuestionAnalyticsService
+}
+
+// NewSuggestedQuestionService creates a new suggested question service
+func NewSuggestedQuestionService(db *gorm.DB, questions *QuestionAnalyticsService) *SuggestedQuestionService {
+	return &SuggestedQuestionService{
+		db:        db,
+		questions: questions,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *SuggestedQuestionService) WithContext(ctx context.Context) *SuggestedQuestionService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.questions = s.questions.WithContext(ctx)
+	return &scoped
+}
+
+// Suggest returns up to limit suggestions for the data source, each question once
+func (s *SuggestedQuestionService) Suggest(dataSourceID uint, limit int) ([]models.QuestionSuggestion, error) {
+	if limit <= 0 {
+		limit = defaultSuggestionLimit
+	}
+	if limit > maxSuggestionLimit {
+		limit = maxSuggestionLimit
+	}
+
+	curated, err := s.List(dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().AddDate(0, 0, -suggestionMiningDays)
+	topics, err := s.questions.frequentQuestions(dataSourceID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []models.NL2SQLQuery
+	if err := s.db.Select("id", "nl_query", "created_at").
+		Where("data_source_id = ? AND status = ? AND created_at >= ?", dataSourceID, models.QueryStatusCompleted, since).
+		Order("created_at DESC").Limit(recentSuggestionsRead).
+		Find(&recent).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recent questions: %w", err)
+	}
+
+	return mergeSuggestions(limit, curated, topics, recent), nil
+}
+
+// mergeSuggestions lists curated questions, then frequent topics asked more
+// than once, then recent questions, leaving out questions already listed
+func mergeSuggestions(limit int, curated []models.SuggestedQuestion, topics []models.QuestionTopic, recent []models.NL2SQLQuery) []models.QuestionSuggestion {
+	suggestions := []models.QuestionSuggestion{}
+	seen := make(map[string]bool)
+	add := func(suggestion models.QuestionSuggestion) {
+		key := normalizeQuestion(suggestion.Question)
+		if len(suggestions) >= limit || key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		suggestions = append(suggestions, suggestion)
+	}
+
+	for i := range curated {
+		add(models.QuestionSuggestion{Question: curated[i].Question, Source: models.SuggestionSourceCurated, ID: &curated[i].ID})
+	}
+	for i := range topics {
+		if topics[i].Questions < questionTopicsMinSize {
+			continue
+		}
+		add(models.QuestionSuggestion{
+			Question:  topics[i].Label,
+			Source:    models.SuggestionSourceFrequent,
+			Asked:     topics[i].Questions,
+			LastAsked: &topics[i].LastAskedAt,
+		})
+		// Other wordings of the topic are not suggested as recent questions
+		for _, sample := range topics[i].Samples {
+			seen[normalizeQuestion(sample)] = true
+		}
+	}
+	for i := range recent {
+		add(models.QuestionSuggestion{
+			Question:  recent[i].NLQuery,
+			Source:    models.SuggestionSourceRecent,
+			QueryID:   &recent[i].ID,
+			LastAsked: &recent[i].CreatedAt,
+		})
+	}
+	return suggestions
+}
+
+// List returns the curated questions of the data source in position order
+func (s *SuggestedQuestionService) List(dataSourceID uint) ([]models.SuggestedQuestion, error) {
+	var questions []models.SuggestedQuestion
+	if err := s.db.Where("data_source_id = ?", dataSourceID).Order("position, id").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get suggested questions: %w", err)
+	}
+	return questions, nil
+}
+
+// Create curates an example question for the data source
+func (s *SuggestedQuestionService) Create(dataSourceID, userID uint, req *models.SuggestedQuestionRequest) (*models.SuggestedQuestion, error) {
+	question := &models.SuggestedQuestion{
+		DataSourceID: dataSourceID,
+		Question:     req.Question,
+		Position:     req.Position,
+		CreatedBy:    userID,
+	}
+	if err := s.db.Create(question).Error; err != nil {
+		return nil, fmt.Errorf("failed to create suggested question: %w", err)
+	}
+	return question, nil
+}
+
+// Delete removes a curated question of the data source
+func (s *SuggestedQuestionService) Delete(dataSourceID, id uint) error {
+	result := s.db.Where("id = ? AND data_source_id = ?", id, dataSourceID).Delete(&models.SuggestedQuestion{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete suggested question: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSuggestedQuestionNotFound
+	}
+	return nil
+}
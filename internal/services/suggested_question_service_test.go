@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSuggestions(t *testing.T) {
+	asked := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	curated := []models.SuggestedQuestion{
+		{ID: 1, Question: "What was revenue last month?"},
+		{ID: 2, Question: "Top 10 customers by spend"},
+	}
+	topics := []models.QuestionTopic{
+		{Label: "Active users this week", Questions: 4, LastAskedAt: asked, Samples: []string{"Active users this week", "How many active users?"}},
+		{Label: "what was revenue last month", Questions: 3, LastAskedAt: asked, Samples: []string{"what was revenue last month"}},
+		{Label: "Churn by plan", Questions: 1, LastAskedAt: asked, Samples: []string{"Churn by plan"}},
+	}
+	recent := []models.NL2SQLQuery{
+		{ID: 9, NLQuery: "How many active users?", CreatedAt: asked},
+		{ID: 8, NLQuery: "Orders by country", CreatedAt: asked},
+		{ID: 7, NLQuery: "Churn by plan", CreatedAt: asked},
+	}
+
+	suggestions := mergeSuggestions(10, curated, topics, recent)
+	require.Len(t, suggestions, 5)
+
+	// Curated first, in their order
+	assert.Equal(t, models.SuggestionSourceCurated, suggestions[0].Source)
+	assert.Equal(t, uint(1), *suggestions[0].ID)
+	assert.Equal(t, "Top 10 customers by spend", suggestions[1].Question)
+
+	// Frequent topics asked more than once; the curated question is not repeated
+	assert.Equal(t, models.QuestionSuggestion{Question: "Active users this week", Source: models.SuggestionSourceFrequent, Asked: 4, LastAsked: &asked}, suggestions[2])
+
+	// Recent questions, leaving out other wordings of frequent topics
+	assert.Equal(t, "Orders by country", suggestions[3].Question)
+	assert.Equal(t, models.SuggestionSourceRecent, suggestions[3].Source)
+	assert.Equal(t, uint(8), *suggestions[3].QueryID)
+	assert.Equal(t, "Churn by plan", suggestions[4].Question)
+
+	// The limit applies across sources
+	assert.Len(t, mergeSuggestions(3, curated, topics, recent), 3)
+	assert.Empty(t, mergeSuggestions(10, nil, nil, nil))
+}
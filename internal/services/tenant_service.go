@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"gorm.io/gorm"
+)
+
+// tenantCacheTTL is how long tenants are cached for resolving requests;
+// changes made on another instance apply after it
+const tenantCacheTTL = 30 * time.Second
+
+var (
+	// ErrTenantNotFound is returned when a tenant does not exist
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantSlugTaken is returned when another tenant has the slug
+	ErrTenantSlugTaken = errors.New("tenant slug is already taken")
+	// ErrTenantDomainTaken is returned when another tenant is served on the domain
+	ErrTenantDomainTaken = errors.New("tenant domain is already taken")
+	// ErrDefaultTenantDeactivation is returned when deactivating the default tenant
+	ErrDefaultTenantDeactivation = errors.New("the default tenant cannot be deactivated")
+)
+
+// TenantService manages tenants and resolves the tenant of requests
+type TenantService struct {
+	db  *gorm.DB
+	mu  sync.Mutex
+	ids map[uint]cachedTenant
+	// domains caches the tenant served on a domain, nil when none is
+	domains map[string]cachedTenant
+	now     func() time.Time
+}
+
+type cachedTenant struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(db *gorm.DB) *TenantService {
+	return &TenantService{
+		db:      db,
+		ids:     make(map[uint]cachedTenant),
+		domains: make(map[string]cachedTenant),
+		now:     time.Now,
+	}
+}
+
+// GetTenant returns a tenant, cached for resolving requests
+func (s *TenantService) GetTenant(ctx context.Context, id uint) (*models.Tenant, error) {
+	s.mu.Lock()
+	cached, ok := s.ids[id]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.tenant, nil
+	}
+
+	var tenant models.Tenant
+	if err := s.db.WithContext(ctx).First(&tenant, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to load tenant: %w", err)
+	}
+	s.mu.Lock()
+	s.ids[id] = cachedTenant{tenant: &tenant, expiresAt: s.now().Add(tenantCacheTTL)}
+	s.mu.Unlock()
+	return &tenant, nil
+}
+
+// GetTenantByDomain returns the tenant served on the domain, or nil when none is
+func (s *TenantService) GetTenantByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	domain = normalizeTenantDomain(domain)
+	s.mu.Lock()
+	cached, ok := s.domains[domain]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.tenant, nil
+	}
+
+	var tenants []models.Tenant
+	if err := s.db.WithContext(ctx).Where("domain = ?", domain).Limit(1).Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tenant: %w", err)
+	}
+	var tenant *models.Tenant
+	if len(tenants) > 0 {
+		tenant = &tenants[0]
+	}
+	s.mu.Lock()
+	s.domains[domain] = cachedTenant{tenant: tenant, expiresAt: s.now().Add(tenantCacheTTL)}
+	s.mu.Unlock()
+	return tenant, nil
+}
+
+// ListTenants returns every tenant
+func (s *TenantService) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	tenants := []models.Tenant{}
+	if err := s.db.WithContext(ctx).Order("id").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// CreateTenant creates a tenant
+func (s *TenantService) CreateTenant(ctx context.Context, req *models.TenantCreateRequest) (*models.Tenant, error) {
+	tenant := &models.Tenant{
+		Name:     req.Name,
+		Slug:     strings.ToLower(req.Slug),
+		Features: req.Features,
+		IsActive: true,
+	}
+	if req.Domain != "" {
+		domain := normalizeTenantDomain(req.Domain)
+		tenant.Domain = &domain
+	}
+	if err := s.checkUnique(ctx, tenant); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	s.invalidate()
+	return tenant, nil
+}
+
+// UpdateTenant changes the name, domain, feature flags or active state of a tenant
+func (s *TenantService) UpdateTenant(ctx context.Context, id uint, req *models.TenantUpdateRequest) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.db.WithContext(ctx).First(&tenant, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to load tenant: %w", err)
+	}
+
+	if req.Name != nil {
+		tenant.Name = *req.Name
+	}
+	if req.Domain != nil {
+		tenant.Domain = nil
+		if *req.Domain != "" {
+			domain := normalizeTenantDomain(*req.Domain)
+			tenant.Domain = &domain
+		}
+	}
+	if req.Features != nil {
+		tenant.Features = req.Features
+	}
+	if req.IsActive != nil {
+		if !*req.IsActive && tenant.ID == models.DefaultTenantID {
+			return nil, ErrDefaultTenantDeactivation
+		}
+		tenant.IsActive = *req.IsActive
+	}
+	if err := s.checkUnique(ctx, &tenant); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+	s.invalidate()
+	return &tenant, nil
+}
+
+// checkUnique refuses a slug or domain of another tenant
+func (s *TenantService) checkUnique(ctx context.Context, tenant *models.Tenant) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("slug = ? AND id <> ?", tenant.Slug, tenant.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check tenant slug: %w", err)
+	}
+	if count > 0 {
+		return ErrTenantSlugTaken
+	}
+	if tenant.Domain == nil {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("domain = ? AND id <> ?", *tenant.Domain, tenant.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check tenant domain: %w", err)
+	}
+	if count > 0 {
+		return ErrTenantDomainTaken
+	}
+	return nil
+}
+
+// invalidate forgets the cached tenants after a change
+func (s *TenantService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids = make(map[uint]cachedTenant)
+	s.domains = make(map[string]cachedTenant)
+}
+
+func normalizeTenantDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrUsageQuotaExceeded is returned when a user has used up the monthly AI quota
+var ErrUsageQuotaExceeded = errors.New("monthly AI usage quota exceeded")
+
+// UsagePricing is the price in USD per 1,000 tokens used to estimate the cost of AI calls
+type UsagePricing struct {
+	LLMModel           string
+	EmbeddingPer1K     float64
+	LLMPromptPer1K     float64
+	LLMCompletionPer1K float64
+}
+
+type usageUserKey struct{}
+
+// WithUsageUser attributes the AI calls made with the context to a user
+func WithUsageUser(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, usageUserKey{}, userID)
+}
+
+// usageUser returns the user AI calls made with the context are attributed to
+func usageUser(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(usageUserKey{}).(uint)
+	return userID, ok && userID != 0
+}
+
+// UsageService records the tokens and estimated cost of embedding and LLM
+// calls, reports them per user and day and enforces monthly quotas
+type UsageService struct {
+	db           *gorm.DB
+	pricing      UsagePricing
+	defaultQuota models.UsageQuotaRequest // Applies to users without a quota of their own
+}
+
+// NewUsageService creates a new usage service
+func NewUsageService(db *gorm.DB, pricing UsagePricing, defaultQuota models.UsageQuotaRequest) *UsageService {
+	return &UsageService{
+		db:           db,
+		pricing:      pricing,
+		defaultQuota: defaultQuota,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *UsageService) WithContext(ctx context.Context) *UsageService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// Record stores the usage of an AI call made with the context. A failure is
+// logged rather than failing the call that already happened.
+func (s *UsageService) Record(ctx context.Context, kind models.UsageKind, model string, promptTokens, completionTokens int) {
+	if s == nil {
+		return
+	}
+
+	userID, _ := usageUser(ctx)
+	record := models.UsageRecord{
+		UserID:           userID,
+		Kind:             kind,
+		Model:            model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		CostUSD:          usageCost(s.pricing, kind, promptTokens, completionTokens),
+	}
+	if err := s.db.WithContext(ctx).Create(&record).Error; err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("kind", string(kind)).Uint("user_id", userID).Msg("Failed to record usage")
+	}
+}
+
+// llmModel is the model LLM usage is recorded for
+func (s *UsageService) llmModel() string {
+	if s == nil {
+		return ""
+	}
+	return s.pricing.LLMModel
+}
+
+// CheckQuota returns ErrUsageQuotaExceeded when the user of the context has
+// used up the monthly quota. Calls not attributed to a user are not limited.
+func (s *UsageService) CheckQuota(ctx context.Context) error {
+	userID, ok := usageUser(ctx)
+	if s == nil || !ok {
+		return nil
+	}
+
+	status, err := s.QuotaStatus(userID)
+	if err != nil {
+		return err
+	}
+	if status.Exceeded {
+		return fmt.Errorf("%w: %d tokens and $%.4f used since %s", ErrUsageQuotaExceeded,
+			status.UsedTokens, status.UsedCostUSD, status.PeriodStart.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// QuotaStatus returns the month-to-date usage of a user against the effective quota
+func (s *UsageService) QuotaStatus(userID uint) (*models.UsageQuotaStatus, error) {
+	status := &models.UsageQuotaStatus{
+		MonthlyTokens:  s.defaultQuota.MonthlyTokens,
+		MonthlyCostUSD: s.defaultQuota.MonthlyCostUSD,
+		PeriodStart:    usageMonthStart(time.Now()),
+	}
+
+	var quota models.UsageQuota
+	err := s.db.Where("user_id = ?", userID).First(&quota).Error
+	switch {
+	case err == nil:
+		status.MonthlyTokens = quota.MonthlyTokens
+		status.MonthlyCostUSD = quota.MonthlyCostUSD
+		status.Custom = true
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to get usage quota: %w", err)
+	}
+
+	var used struct {
+		Tokens int64
+		Cost   float64
+	}
+	if err := s.db.Model(&models.UsageRecord{}).
+		Select("COALESCE(SUM(total_tokens), 0) AS tokens, COALESCE(SUM(cost_usd), 0) AS cost").
+		Where("user_id = ? AND created_at >= ?", userID, status.PeriodStart).
+		Scan(&used).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum usage: %w", err)
+	}
+	status.UsedTokens = used.Tokens
+	status.UsedCostUSD = used.Cost
+	status.Exceeded = quotaExceeded(status)
+	return status, nil
+}
+
+// Summary returns the daily usage of a user between from and to with the quota status
+func (s *UsageService) Summary(userID uint, from, to time.Time) (*models.UsageSummary, error) {
+	daily, err := s.daily(s.db.Where("user_id = ?", userID), from, to, false)
+	if err != nil {
+		return nil, err
+	}
+
+	quota, err := s.QuotaStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UsageSummary{
+		From:   from,
+		To:     to,
+		Daily:  daily,
+		Totals: usageTotals(daily),
+		Quota:  *quota,
+	}, nil
+}
+
+// WorkspaceDaily returns the usage of every user per day between from and to
+func (s *UsageService) WorkspaceDaily(from, to time.Time) ([]models.UsageDailyTotal, error) {
+	return s.daily(s.db, from, to, true)
+}
+
+// SetQuota creates or replaces the monthly quota of a user
+func (s *UsageService) SetQuota(userID, updatedBy uint, req *models.UsageQuotaRequest) (*models.UsageQuota, error) {
+	var quota models.UsageQuota
+	if err := s.db.Where("user_id = ?", userID).Attrs(models.UsageQuota{UserID: userID}).
+		FirstOrInit(&quota).Error; err != nil {
+		return nil, fmt.Errorf("failed to load usage quota: %w", err)
+	}
+
+	quota.MonthlyTokens = req.MonthlyTokens
+	quota.MonthlyCostUSD = req.MonthlyCostUSD
+	quota.UpdatedBy = updatedBy
+	if err := s.db.Save(&quota).Error; err != nil {
+		return nil, fmt.Errorf("failed to save usage quota: %w", err)
+	}
+	return &quota, nil
+}
+
+// DeleteQuota returns a user to the default quota
+func (s *UsageService) DeleteQuota(userID uint) error {
+	if err := s.db.Where("user_id = ?", userID).Delete(&models.UsageQuota{}).Error; err != nil {
+		return fmt.Errorf("failed to delete usage quota: %w", err)
+	}
+	return nil
+}
+
+func (s *UsageService) daily(scope *gorm.DB, from, to time.Time, perUser bool) ([]models.UsageDailyTotal, error) {
+	columns := "TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS date, kind"
+	group := "date, kind"
+	if perUser {
+		columns += ", user_id"
+		group += ", user_id"
+	}
+
+	daily := []models.UsageDailyTotal{}
+	if err := scope.Model(&models.UsageRecord{}).
+		Select(columns+", COUNT(*) AS calls, SUM(total_tokens) AS total_tokens, SUM(cost_usd) AS cost_usd").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group(group).Order(group).
+		Scan(&daily).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage: %w", err)
+	}
+	return daily, nil
+}
+
+// usageCost estimates the cost in USD of an AI call
+func usageCost(pricing UsagePricing, kind models.UsageKind, promptTokens, completionTokens int) float64 {
+	switch kind {
+	case models.UsageKindEmbedding:
+		return float64(promptTokens+completionTokens) / 1000 * pricing.EmbeddingPer1K
+	case models.UsageKindLLM:
+		return float64(promptTokens)/1000*pricing.LLMPromptPer1K + float64(completionTokens)/1000*pricing.LLMCompletionPer1K
+	default:
+		return 0
+	}
+}
+
+// estimateTokens approximates the token count of text for calls whose
+// provider does not report usage, at about four characters per token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// quotaExceeded reports whether the usage reached a limit of the quota
+func quotaExceeded(status *models.UsageQuotaStatus) bool {
+	return (status.MonthlyTokens > 0 && status.UsedTokens >= status.MonthlyTokens) ||
+		(status.MonthlyCostUSD > 0 && status.UsedCostUSD >= status.MonthlyCostUSD)
+}
+
+// usageMonthStart returns the start of the calendar month (UTC) quotas are counted from
+func usageMonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// usageTotals sums daily usage per kind
+func usageTotals(daily []models.UsageDailyTotal) []models.UsageDailyTotal {
+	byKind := make(map[models.UsageKind]*models.UsageDailyTotal)
+	for _, day := range daily {
+		total, ok := byKind[day.Kind]
+		if !ok {
+			total = &models.UsageDailyTotal{Kind: day.Kind}
+			byKind[day.Kind] = total
+		}
+		total.Calls += day.Calls
+		total.TotalTokens += day.TotalTokens
+		total.CostUSD += day.CostUSD
+	}
+
+	totals := make([]models.UsageDailyTotal, 0, len(byKind))
+	for _, total := range byKind {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Kind < totals[j].Kind })
+	return totals
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageCost(t *testing.T) {
+	pricing := UsagePricing{EmbeddingPer1K: 0.0001, LLMPromptPer1K: 0.00015, LLMCompletionPer1K: 0.0006}
+
+	assert.InDelta(t, 0.0002, usageCost(pricing, models.UsageKindEmbedding, 2000, 0), 1e-12)
+	assert.InDelta(t, 0.00015+0.0003, usageCost(pricing, models.UsageKindLLM, 1000, 500), 1e-12)
+	assert.Zero(t, usageCost(pricing, models.UsageKind("other"), 1000, 1000))
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, estimateTokens(""))
+	assert.Equal(t, 1, estimateTokens("abc"))
+	assert.Equal(t, 1, estimateTokens("abcd"))
+	assert.Equal(t, 2, estimateTokens("abcde"))
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	tests := []struct {
+		name   string
+		status models.UsageQuotaStatus
+		want   bool
+	}{
+		{"unlimited", models.UsageQuotaStatus{UsedTokens: 1e9, UsedCostUSD: 1000}, false},
+		{"under token limit", models.UsageQuotaStatus{MonthlyTokens: 1000, UsedTokens: 999}, false},
+		{"token limit reached", models.UsageQuotaStatus{MonthlyTokens: 1000, UsedTokens: 1000}, true},
+		{"cost limit reached", models.UsageQuotaStatus{MonthlyTokens: 1000, MonthlyCostUSD: 5, UsedTokens: 10, UsedCostUSD: 5.01}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, quotaExceeded(&tt.status))
+		})
+	}
+}
+
+func TestUsageMonthStart(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// Still the last day of March in UTC
+	now := time.Date(2025, 4, 1, 3, 0, 0, 0, jakarta)
+
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), usageMonthStart(now))
+}
+
+func TestUsageTotals(t *testing.T) {
+	daily := []models.UsageDailyTotal{
+		{Date: "2025-09-01", Kind: models.UsageKindLLM, Calls: 2, TotalTokens: 300, CostUSD: 0.01},
+		{Date: "2025-09-01", Kind: models.UsageKindEmbedding, Calls: 5, TotalTokens: 1000, CostUSD: 0.001},
+		{Date: "2025-09-02", Kind: models.UsageKindLLM, Calls: 1, TotalTokens: 100, CostUSD: 0.005},
+	}
+
+	totals := usageTotals(daily)
+	assert.Equal(t, []models.UsageDailyTotal{
+		{Kind: models.UsageKindEmbedding, Calls: 5, TotalTokens: 1000, CostUSD: 0.001},
+		{Kind: models.UsageKindLLM, Calls: 3, TotalTokens: 400, CostUSD: 0.015},
+	}, totals)
+}
+
+func TestUsageServiceDisabled(t *testing.T) {
+	var s *UsageService
+	ctx := WithUsageUser(context.Background(), 1)
+
+	assert.NoError(t, s.CheckQuota(ctx))
+	assert.NotPanics(t, func() { s.Record(ctx, models.UsageKindLLM, "model", 10, 10) })
+	assert.Empty(t, s.llmModel())
+}
+
+func TestUsageUser(t *testing.T) {
+	_, ok := usageUser(context.Background())
+	assert.False(t, ok)
+
+	_, ok = usageUser(WithUsageUser(context.Background(), 0))
+	assert.False(t, ok)
+
+	userID, ok := usageUser(WithUsageUser(context.Background(), 7))
+	assert.True(t, ok)
+	assert.Equal(t, uint(7), userID)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	entity "narapulse-be/internal/models/entity"
 	"narapulse-be/internal/repositories"
@@ -9,14 +10,41 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrLastAdmin is returned for changes to users that would leave no active admin
+var ErrLastAdmin = errors.New("at least one active admin must remain")
+
 type UserService interface {
 	CreateUser(req *entity.UserCreateRequest) (*entity.User, error)
 	GetUserByID(id uint) (*entity.User, error)
 	GetUserByEmail(email string) (*entity.User, error)
 	UpdateUser(id uint, req *entity.UserUpdateRequest) (*entity.User, error)
+	UpdateUserRole(id uint, role string) (*entity.User, error)
 	DeleteUser(id uint) error
 	AuthenticateUser(email, password string) (*entity.User, error)
-	GetAllUsers() ([]*entity.User, error)
+	ListUsers(filter *entity.UserListFilter) ([]*entity.User, int64, error)
+	UpdateUserAdmin(id uint, req *entity.UserAdminUpdateRequest) (*entity.User, error)
+	DeactivateUsers(ids []uint) (int64, error)
+	// GetAccount returns the current role and active state of the user a
+	// token was issued to; nil when the user no longer exists
+	GetAccount(ctx context.Context, id uint) (*entity.User, error)
+	// WithContext returns the service running its statements with ctx, which
+	// scopes them to the tenant of the request
+	WithContext(ctx context.Context) UserService
+}
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// userSortColumns are the columns users can be sorted by
+var userSortColumns = map[string]bool{
+	"created_at": true,
+	"email":      true,
+	"username":   true,
+	"first_name": true,
+	"last_name":  true,
+	"role":       true,
 }
 
 type userService struct {
@@ -29,6 +57,10 @@ func NewUserService(userRepo repositories.UserRepository) UserService {
 	}
 }
 
+func (s *userService) WithContext(ctx context.Context) UserService {
+	return &userService{userRepo: s.userRepo.WithContext(ctx)}
+}
+
 func (s *userService) CreateUser(req *entity.UserCreateRequest) (*entity.User, error) {
 	// Check if user already exists
 	existingUser, _ := s.userRepo.GetByEmail(req.Email)
@@ -92,6 +124,25 @@ func (s *userService) UpdateUser(id uint, req *entity.UserUpdateRequest) (*entit
 	return user, nil
 }
 
+func (s *userService) UpdateUserRole(id uint, role string) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	user.Role = role
+	if err := s.keepActiveAdmin(func(repo repositories.UserRepository) error {
+		return repo.Update(user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 func (s *userService) DeleteUser(id uint) error {
 	_, err := s.userRepo.GetByID(id)
 	if err != nil {
@@ -101,11 +152,102 @@ func (s *userService) DeleteUser(id uint) error {
 		return err
 	}
 
-	return s.userRepo.Delete(id)
+	return s.keepActiveAdmin(func(repo repositories.UserRepository) error {
+		return repo.Delete(id)
+	})
+}
+
+// ListUsers returns a page of the users matching the filter and the number of
+// matching users. Unknown sort columns and orders fall back to newest first.
+func (s *userService) ListUsers(filter *entity.UserListFilter) ([]*entity.User, int64, error) {
+	normalizeUserListFilter(filter)
+	return s.userRepo.List(filter)
+}
+
+// UpdateUserAdmin changes the role and active state of a user
+func (s *userService) UpdateUserAdmin(id uint, req *entity.UserAdminUpdateRequest) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+	if err := s.keepActiveAdmin(func(repo repositories.UserRepository) error {
+		return repo.Update(user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
-func (s *userService) GetAllUsers() ([]*entity.User, error) {
-	return s.userRepo.GetAll()
+// DeactivateUsers deactivates the users and returns how many were active
+func (s *userService) DeactivateUsers(ids []uint) (int64, error) {
+	var deactivated int64
+	err := s.keepActiveAdmin(func(repo repositories.UserRepository) error {
+		var err error
+		deactivated, err = repo.SetActive(ids, false)
+		return err
+	})
+	return deactivated, err
+}
+
+// keepActiveAdmin applies a change to users in a transaction, which is rolled
+// back with ErrLastAdmin when it leaves no active admin. The active admins are
+// locked first, so concurrent changes cannot each remove a different one.
+func (s *userService) keepActiveAdmin(change func(repo repositories.UserRepository) error) error {
+	return s.userRepo.Transaction(func(repo repositories.UserRepository) error {
+		before, err := repo.ActiveAdminIDs()
+		if err != nil {
+			return err
+		}
+		if err := change(repo); err != nil {
+			return err
+		}
+		after, err := repo.ActiveAdminIDs()
+		if err != nil {
+			return err
+		}
+		if len(before) > 0 && len(after) == 0 {
+			return ErrLastAdmin
+		}
+		return nil
+	})
+}
+
+func (s *userService) GetAccount(ctx context.Context, id uint) (*entity.User, error) {
+	user, err := s.userRepo.WithContext(ctx).GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// normalizeUserListFilter applies the default page, page size and order
+func normalizeUserListFilter(filter *entity.UserListFilter) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserPageSize
+	}
+	if filter.Limit > maxUserPageSize {
+		filter.Limit = maxUserPageSize
+	}
+	if !userSortColumns[filter.Sort] {
+		filter.Sort = "created_at"
+	}
+	if filter.Order != "asc" {
+		filter.Order = "desc"
+	}
 }
 
 func (s *userService) AuthenticateUser(email, password string) (*entity.User, error) {
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	entity "narapulse-be/internal/models/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUserListFilter(t *testing.T) {
+	filter := &entity.UserListFilter{}
+	normalizeUserListFilter(filter)
+	assert.Equal(t, 1, filter.Page)
+	assert.Equal(t, defaultUserPageSize, filter.Limit)
+	assert.Equal(t, "created_at", filter.Sort)
+	assert.Equal(t, "desc", filter.Order)
+
+	filter = &entity.UserListFilter{Page: 3, Limit: 1000, Sort: "email", Order: "asc"}
+	normalizeUserListFilter(filter)
+	assert.Equal(t, 3, filter.Page)
+	assert.Equal(t, maxUserPageSize, filter.Limit)
+	assert.Equal(t, "email", filter.Sort)
+	assert.Equal(t, "asc", filter.Order)
+
+	// Sort columns are interpolated into the query, so anything unknown is replaced
+	filter = &entity.UserListFilter{Sort: "password; DROP TABLE users", Order: "sideways"}
+	normalizeUserListFilter(filter)
+	assert.Equal(t, "created_at", filter.Sort)
+	assert.Equal(t, "desc", filter.Order)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrValidationPolicyNotFound is returned when a validation policy does not exist
+var ErrValidationPolicyNotFound = errors.New("validation policy not found")
+
+var policyIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidationPolicyService manages SQL validation policies and compiles them
+// into validator rules at runtime
+type ValidationPolicyService struct {
+	db                *gorm.DB
+	governanceService *GovernanceService
+}
+
+// NewValidationPolicyService creates a new validation policy service
+func NewValidationPolicyService(db *gorm.DB, governanceService *GovernanceService) *ValidationPolicyService {
+	return &ValidationPolicyService{
+		db:                db,
+		governanceService: governanceService,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *ValidationPolicyService) WithContext(ctx context.Context) *ValidationPolicyService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	scoped.governanceService = s.governanceService.WithContext(ctx)
+	return &scoped
+}
+
+// RulesForDataSource loads the policy of a data source, falling back to the
+// workspace default policy, and allows the custom functions registered for
+// the data source. It returns nil rules when there is neither.
+func (s *ValidationPolicyService) RulesForDataSource(dataSourceID uint) (*ValidationRules, error) {
+	var functions []models.CustomSQLFunction
+	if err := s.db.Select("name").Where("data_source_id = ?", dataSourceID).Find(&functions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load custom SQL functions: %w", err)
+	}
+
+	var policies []models.ValidationPolicy
+	if err := s.db.Where("data_source_id = ? OR data_source_id IS NULL", dataSourceID).
+		Order("data_source_id IS NULL").Limit(1).Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load validation policy: %w", err)
+	}
+	if len(policies) == 0 {
+		if len(functions) == 0 {
+			return nil, nil
+		}
+		return withCustomFunctions(&ValidationRules{LargeTables: make(map[string]int64)}, functions), nil
+	}
+	policy := &policies[0]
+
+	var largeTables []models.Schema
+	if policy.RequireWhereOnLarge {
+		threshold := policy.LargeTableRowThreshold
+		if threshold <= 0 {
+			threshold = models.DefaultLargeTableRowThreshold
+		}
+		if err := s.db.Select("name", "row_count").
+			Where("data_source_id = ? AND row_count >= ?", dataSourceID, threshold).
+			Find(&largeTables).Error; err != nil {
+			return nil, fmt.Errorf("failed to load table sizes: %w", err)
+		}
+	}
+
+	return withCustomFunctions(compileValidationRules(policy, largeTables), functions), nil
+}
+
+// List returns all validation policies, the workspace default first
+func (s *ValidationPolicyService) List() ([]*models.ValidationPolicyResponse, error) {
+	var policies []models.ValidationPolicy
+	if err := s.db.Order("data_source_id IS NOT NULL, data_source_id").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list validation policies: %w", err)
+	}
+
+	responses := make([]*models.ValidationPolicyResponse, len(policies))
+	for i := range policies {
+		responses[i] = policies[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// Get returns a validation policy by ID
+func (s *ValidationPolicyService) Get(id uint) (*models.ValidationPolicyResponse, error) {
+	policy, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return policy.ToResponse(), nil
+}
+
+// Create adds a validation policy for a data source or the workspace default
+func (s *ValidationPolicyService) Create(adminID uint, req *models.ValidationPolicyRequest) (*models.ValidationPolicyResponse, error) {
+	if err := validatePolicyRequest(req); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Model(&models.ValidationPolicy{})
+	if req.DataSourceID != nil {
+		var count int64
+		if err := s.db.Model(&models.DataSource{}).Where("id = ?", *req.DataSourceID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check data source: %w", err)
+		}
+		if count == 0 {
+			return nil, errors.New("data source not found")
+		}
+		query = query.Where("data_source_id = ?", *req.DataSourceID)
+	} else {
+		query = query.Where("data_source_id IS NULL")
+	}
+
+	var existing int64
+	if err := query.Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing policy: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("a validation policy already exists for this scope")
+	}
+
+	policy := &models.ValidationPolicy{DataSourceID: req.DataSourceID}
+	applyPolicyRequest(policy, req, adminID)
+	if err := s.db.Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to create validation policy: %w", err)
+	}
+
+	s.emitPolicyChanged(policy, adminID, "created")
+	return policy.ToResponse(), nil
+}
+
+// Update replaces the rules of a validation policy. Its scope cannot change.
+func (s *ValidationPolicyService) Update(adminID, id uint, req *models.ValidationPolicyRequest) (*models.ValidationPolicyResponse, error) {
+	if err := validatePolicyRequest(req); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyPolicyRequest(policy, req, adminID)
+	if err := s.db.Save(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to update validation policy: %w", err)
+	}
+
+	s.emitPolicyChanged(policy, adminID, "updated")
+	return policy.ToResponse(), nil
+}
+
+// Delete removes a validation policy
+func (s *ValidationPolicyService) Delete(adminID, id uint) error {
+	policy, err := s.get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(policy).Error; err != nil {
+		return fmt.Errorf("failed to delete validation policy: %w", err)
+	}
+
+	s.emitPolicyChanged(policy, adminID, "deleted")
+	return nil
+}
+
+func (s *ValidationPolicyService) get(id uint) (*models.ValidationPolicy, error) {
+	var policy models.ValidationPolicy
+	if err := s.db.First(&policy, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrValidationPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get validation policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// emitPolicyChanged records the change in the governance event log
+func (s *ValidationPolicyService) emitPolicyChanged(policy *models.ValidationPolicy, adminID uint, action string) {
+	var dataSourceID uint
+	if policy.DataSourceID != nil {
+		dataSourceID = *policy.DataSourceID
+	}
+
+	err := s.governanceService.Emit(models.GovernanceEventPolicyChanged, dataSourceID, adminID, map[string]interface{}{
+		"policy_type": "sql_validation",
+		"policy_id":   policy.ID,
+		"name":        policy.Name,
+		"action":      action,
+	})
+	if err != nil {
+		logger.L().Error().Err(err).Uint("policy_id", policy.ID).Msg("Failed to emit policy change event for validation policy")
+	}
+}
+
+// validatePolicyRequest checks function and keyword names and compiles the patterns
+func validatePolicyRequest(req *models.ValidationPolicyRequest) error {
+	for _, list := range [][]string{req.AllowedFunctions, req.BlockedFunctions, req.BlockedKeywords} {
+		for _, name := range list {
+			if !policyIdentifierPattern.MatchString(name) {
+				return fmt.Errorf("invalid function or keyword name: %q", name)
+			}
+		}
+	}
+	for _, pattern := range req.BlockedPatterns {
+		if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+			return fmt.Errorf("invalid blocked pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// applyPolicyRequest copies the request into the policy
+func applyPolicyRequest(policy *models.ValidationPolicy, req *models.ValidationPolicyRequest, adminID uint) {
+	policy.Name = req.Name
+	policy.AllowedFunctions = marshalUpperList(req.AllowedFunctions)
+	policy.BlockedFunctions = marshalUpperList(req.BlockedFunctions)
+	policy.BlockedKeywords = marshalUpperList(req.BlockedKeywords)
+	patterns, _ := json.Marshal(nonNilStrings(req.BlockedPatterns))
+	policy.BlockedPatterns = models.JSON(patterns)
+	policy.MaxJoins = req.MaxJoins
+	policy.MaxLimit = req.MaxLimit
+	policy.RequireWhereOnLarge = req.RequireWhereOnLarge
+	policy.LargeTableRowThreshold = req.LargeTableRowThreshold
+	policy.UpdatedBy = adminID
+}
+
+// compileValidationRules turns a policy and the large tables of its data source into validator rules
+func compileValidationRules(policy *models.ValidationPolicy, largeTables []models.Schema) *ValidationRules {
+	rules := &ValidationRules{
+		PolicyID:         policy.ID,
+		AllowedFunctions: upperSet(policy.GetStringList(policy.AllowedFunctions)),
+		BlockedFunctions: upperSet(policy.GetStringList(policy.BlockedFunctions)),
+		BlockedKeywords:  upperSet(policy.GetStringList(policy.BlockedKeywords)),
+		MaxJoins:         policy.MaxJoins,
+		MaxLimit:         policy.MaxLimit,
+		LargeTables:      make(map[string]int64),
+	}
+
+	for _, pattern := range policy.GetStringList(policy.BlockedPatterns) {
+		// Patterns are validated on save; skip any that no longer compile
+		if re, err := regexp.Compile("(?i)" + pattern); err == nil {
+			rules.BlockedPatterns = append(rules.BlockedPatterns, re)
+		}
+	}
+
+	for _, table := range largeTables {
+		name := strings.ToLower(table.Name)
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = name[dot+1:]
+		}
+		rules.LargeTables[name] = table.RowCount
+	}
+
+	return rules
+}
+
+// withCustomFunctions adds registered custom functions to the allowed
+// functions; functions blocked by the policy stay blocked
+func withCustomFunctions(rules *ValidationRules, functions []models.CustomSQLFunction) *ValidationRules {
+	if rules.AllowedFunctions == nil {
+		rules.AllowedFunctions = make(map[string]bool, len(functions))
+	}
+	for _, function := range functions {
+		rules.AllowedFunctions[strings.ToUpper(function.Name)] = true
+	}
+	return rules
+}
+
+func upperSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToUpper(v)] = true
+	}
+	return set
+}
+
+func marshalUpperList(values []string) models.JSON {
+	upper := make([]string, len(values))
+	for i, v := range values {
+		upper[i] = strings.ToUpper(v)
+	}
+	data, _ := json.Marshal(upper)
+	return models.JSON(data)
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+)
+
+func TestCompileValidationRules(t *testing.T) {
+	policy := &models.ValidationPolicy{
+		ID:               7,
+		AllowedFunctions: models.JSON(`["my_udf"]`),
+		BlockedFunctions: models.JSON(`["NOW"]`),
+		BlockedPatterns:  models.JSON(`["pg_catalog", "("]`),
+		MaxJoins:         2,
+		MaxLimit:         500,
+	}
+	largeTables := []models.Schema{{Name: "public.Events", RowCount: 5000000}}
+
+	rules := compileValidationRules(policy, largeTables)
+	assert.Equal(t, uint(7), rules.PolicyID)
+	assert.True(t, rules.AllowedFunctions["MY_UDF"])
+	assert.True(t, rules.BlockedFunctions["NOW"])
+	assert.Len(t, rules.BlockedPatterns, 1, "patterns that no longer compile are skipped")
+	assert.Equal(t, int64(5000000), rules.LargeTables["events"])
+	assert.Equal(t, 500, rules.RowLimit(1000))
+	assert.Equal(t, 100, rules.RowLimit(100))
+
+	var noRules *ValidationRules
+	assert.Equal(t, 1000, noRules.RowLimit(1000))
+}
+
+func TestValidatePolicyRequest(t *testing.T) {
+	assert.NoError(t, validatePolicyRequest(&models.ValidationPolicyRequest{
+		AllowedFunctions: []string{"my_udf"},
+		BlockedPatterns:  []string{`\bssn\b`},
+	}))
+	assert.Error(t, validatePolicyRequest(&models.ValidationPolicyRequest{AllowedFunctions: []string{"drop table"}}))
+	assert.Error(t, validatePolicyRequest(&models.ValidationPolicyRequest{BlockedPatterns: []string{"("}}))
+}
+
+func TestSQLValidatorService_ValidateSQLWithRules(t *testing.T) {
+	validator := NewSQLValidatorService()
+	rules := compileValidationRules(&models.ValidationPolicy{
+		AllowedFunctions: models.JSON(`["MY_UDF"]`),
+		BlockedFunctions: models.JSON(`["LOWER"]`),
+		BlockedPatterns:  models.JSON(`["\\bssn\\b"]`),
+		MaxJoins:         2,
+		MaxLimit:         100,
+	}, []models.Schema{{Name: "events", RowCount: 2000000}})
+
+	tests := []struct {
+		name  string
+		sql   string
+		valid bool
+	}{
+		{"within policy", "SELECT id, MY_UDF(name) FROM users LIMIT 50", true},
+		{"blocked pattern", "SELECT ssn FROM users LIMIT 50", false},
+		{"blocked function", "SELECT LOWER(name) FROM users LIMIT 50", false},
+		{"limit above maximum", "SELECT id FROM users LIMIT 500", false},
+		{"large table without where", "SELECT id FROM events LIMIT 50", false},
+		{"large table with where", "SELECT id FROM events WHERE user_id = 1 LIMIT 50", true},
+		{"large table in join", "SELECT u.id FROM users u JOIN events e ON e.user_id = u.id LIMIT 50", false},
+		{"joins within maximum", "SELECT a.id FROM a JOIN b ON a.id = b.id JOIN c ON b.id = c.id LIMIT 50", true},
+		{"too many joins", "SELECT a.id FROM a JOIN b ON a.id = b.id JOIN c ON b.id = c.id JOIN d ON c.id = d.id LIMIT 50", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateSQLWithRules(tt.sql, models.SQLDialectPostgreSQL, rules)
+			require.NotNil(t, result)
+			assert.Equal(t, tt.valid, result.IsValid, "violations: %v", result.Violations)
+			assert.Equal(t, tt.valid, err == nil)
+		})
+	}
+
+	// Without a policy the same queries fall back to the built-in rules
+	result, err := validator.ValidateSQLWithRules("SELECT id FROM events LIMIT 500", models.SQLDialectPostgreSQL, nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsValid)
+}
+
+func TestSQLValidatorService_CapLimitForDialect(t *testing.T) {
+	validator := NewSQLValidatorService()
+
+	assert.Equal(t, "SELECT id FROM users LIMIT 100", validator.CapLimitForDialect("SELECT id FROM users LIMIT 500", 100, models.SQLDialectPostgreSQL))
+	assert.Equal(t, "SELECT id FROM users LIMIT 50", validator.CapLimitForDialect("SELECT id FROM users LIMIT 50", 100, models.SQLDialectPostgreSQL))
+	assert.Equal(t, "SELECT id FROM users", validator.CapLimitForDialect("SELECT id FROM users", 100, models.SQLDialectPostgreSQL))
+}
+
+func TestReferencedTables(t *testing.T) {
+	tokens, err := tokenizeSQL(`SELECT * FROM public.orders o, "Customers" c LEFT JOIN analytics.events AS e ON e.id = o.id`, models.SQLDialectPostgreSQL)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "customers", "events"}, referencedTables(significantTokens(tokens)))
+}
+
+func TestWithCustomFunctions(t *testing.T) {
+	validator := NewSQLValidatorService()
+	functions := []models.CustomSQLFunction{{Name: "FISCAL_QUARTER"}, {Name: "lower"}}
+
+	rules := withCustomFunctions(&ValidationRules{LargeTables: map[string]int64{}}, functions)
+	result, err := validator.ValidateSQLWithRules("SELECT FISCAL_QUARTER(created_at) FROM orders LIMIT 10", models.SQLDialectBigQuery, rules)
+	require.NoError(t, err)
+	assert.True(t, result.IsValid, "registered function is allowed: %v", result.Violations)
+
+	result, err = validator.ValidateSQLWithRules("SELECT FISCAL_QUARTER(created_at) FROM orders LIMIT 10", models.SQLDialectBigQuery, nil)
+	assert.Error(t, err)
+	assert.False(t, result.IsValid, "unregistered function is rejected")
+
+	policyRules := withCustomFunctions(compileValidationRules(&models.ValidationPolicy{
+		BlockedFunctions: models.JSON(`["LOWER"]`),
+	}, nil), functions)
+	result, err = validator.ValidateSQLWithRules("SELECT LOWER(name) FROM users LIMIT 10", models.SQLDialectBigQuery, policyRules)
+	assert.Error(t, err)
+	assert.False(t, result.IsValid, "functions blocked by the policy stay blocked")
+}
+
+func TestFormatCustomFunctions(t *testing.T) {
+	text := formatCustomFunctions([]models.CustomSQLFunction{
+		{Name: "FISCAL_QUARTER", Signature: "fiscal_quarter(d DATE) RETURNS INT64", Description: "Fiscal quarter starting in April", Example: "fiscal_quarter(order_date) = 1"},
+		{Name: "NET_REVENUE", Signature: "net_revenue(amount NUMERIC)"},
+	})
+
+	assert.Equal(t, "- fiscal_quarter(d DATE) RETURNS INT64: Fiscal quarter starting in April (e.g. fiscal_quarter(order_date) = 1)\n"+
+		"- net_revenue(amount NUMERIC)\n", text)
+}
+
+func TestValidationPolicyService_WorkspaceDefaultPerTenant(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.ValidationPolicy{}, &models.DataSource{}))
+
+	acme := NewValidationPolicyService(db, nil).WithContext(tenancy.WithTenant(context.Background(), 1))
+	globex := NewValidationPolicyService(db, nil).WithContext(tenancy.WithTenant(context.Background(), 2))
+
+	_, err = acme.Create(1, &models.ValidationPolicyRequest{Name: "acme default", MaxJoins: 3})
+	require.NoError(t, err)
+	_, err = acme.Create(1, &models.ValidationPolicyRequest{Name: "second default"})
+	assert.Error(t, err, "a tenant has one workspace default")
+
+	// Each tenant has its own workspace default, and a second one is refused
+	// by the database as well
+	_, err = globex.Create(2, &models.ValidationPolicyRequest{Name: "globex default", MaxJoins: 5})
+	require.NoError(t, err)
+	assert.Error(t, db.WithContext(tenancy.WithTenant(context.Background(), 2)).Create(&models.ValidationPolicy{Name: "duplicate"}).Error)
+
+	policies, err := globex.List()
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "globex default", policies[0].Name)
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/logger"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook does not exist
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a delivery does not exist or belongs to another webhook
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const (
+	// webhookTimeout bounds a delivery attempt
+	webhookTimeout = 10 * time.Second
+	// webhookResponseLimit bounds the response body kept in the delivery log
+	webhookResponseLimit = 1024
+	// defaultWebhookDeliveryPageSize and maxWebhookDeliveryPageSize bound the delivery log pages
+	defaultWebhookDeliveryPageSize = 50
+	maxWebhookDeliveryPageSize     = 200
+)
+
+// WebhookService lets external systems subscribe to platform events. Each
+// event is recorded as a delivery per subscribed webhook and posted by a job,
+// so failed deliveries are retried with the job backoff and dead-lettered
+// like any other job. Bodies are signed like the compliance webhook:
+// X-Narapulse-Signature is sha256= followed by the hex HMAC-SHA256 of the body.
+type WebhookService struct {
+	db     *gorm.DB
+	jobs   *JobService
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(db *gorm.DB, jobs *JobService) *WebhookService {
+	return &WebhookService{
+		db:     db,
+		jobs:   jobs,
+		client: &http.Client{Timeout: webhookTimeout},
+		now:    time.Now,
+	}
+}
+
+// WithContext returns a copy of the service that runs its statements with ctx,
+// scoping them to the tenant of ctx
+func (s *WebhookService) WithContext(ctx context.Context) *WebhookService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.db = s.db.WithContext(ctx)
+	return &scoped
+}
+
+// RegisterJobs registers the job that sends a delivery
+func (s *WebhookService) RegisterJobs(jobs *JobService) {
+	jobs.Register(models.JobTypeWebhookDeliver, func(ctx context.Context, payload json.RawMessage) error {
+		var p models.WebhookDeliveryJobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("invalid webhook delivery payload: %w", err)
+		}
+		return s.WithContext(ctx).Deliver(ctx, p.DeliveryID)
+	})
+}
+
+// Publish sends an event to the active webhooks of the tenant of ctx
+// subscribed to it. Events without a tenant are dropped, as they would reach
+// the webhooks of every tenant. The event already happened, so failures to
+// queue it are logged rather than returned. A nil service publishes nothing.
+func (s *WebhookService) Publish(ctx context.Context, event models.WebhookEvent, data interface{}) {
+	if s == nil {
+		return
+	}
+	if _, ok := tenancy.FromContext(ctx); !ok {
+		logger.FromContext(ctx).Warn().Str("event", string(event)).Msg("Dropping webhook event without a tenant")
+		return
+	}
+	s = s.WithContext(ctx)
+
+	var webhooks []models.Webhook
+	if err := s.db.Where("is_active = ?", true).Find(&webhooks).Error; err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("event", string(event)).Msg("Failed to list webhooks")
+		return
+	}
+
+	eventID := uuid.NewString()
+	for i := range webhooks {
+		if !webhookSubscribed(&webhooks[i], event) {
+			continue
+		}
+		if _, err := s.queue(ctx, &webhooks[i], eventID, event, data); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint("webhook_id", webhooks[i].ID).Str("event", string(event)).Msg("Failed to queue webhook delivery")
+		}
+	}
+}
+
+// List returns every webhook
+func (s *WebhookService) List() ([]models.WebhookResponse, error) {
+	var webhooks []models.Webhook
+	if err := s.db.Order("name").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	responses := make([]models.WebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = *webhookResponse(&webhooks[i], "")
+	}
+	return responses, nil
+}
+
+// Get returns a webhook
+func (s *WebhookService) Get(id uint) (*models.WebhookResponse, error) {
+	webhook, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return webhookResponse(webhook, ""), nil
+}
+
+func (s *WebhookService) get(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.First(&webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// Create creates a webhook, generating its secret unless one is given. The
+// secret is only returned here and when it is changed.
+func (s *WebhookService) Create(adminID uint, req *models.WebhookRequest) (*models.WebhookResponse, error) {
+	webhook := &models.Webhook{CreatedBy: adminID}
+	if req.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	applyWebhook(webhook, req)
+	if err := s.db.Create(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhookResponse(webhook, webhook.Secret), nil
+}
+
+// Update replaces a webhook; an empty secret keeps the current one
+func (s *WebhookService) Update(id uint, req *models.WebhookRequest) (*models.WebhookResponse, error) {
+	webhook, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	applyWebhook(webhook, req)
+	if err := s.db.Save(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhookResponse(webhook, req.Secret), nil
+}
+
+// Delete deletes a webhook; queued deliveries to it are dropped when they run
+func (s *WebhookService) Delete(id uint) error {
+	webhook, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(webhook).Error; err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// Ping queues a ping event to a webhook, to test its endpoint and signature check
+func (s *WebhookService) Ping(ctx context.Context, id uint) (*models.WebhookDeliveryResponse, error) {
+	webhook, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := s.queue(ctx, webhook, uuid.NewString(), models.WebhookEventPing, map[string]interface{}{"webhook_id": webhook.ID})
+	if err != nil {
+		return nil, err
+	}
+	return webhookDeliveryResponse(delivery), nil
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+func (s *WebhookService) ListDeliveries(id uint, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDeliveryResponse, error) {
+	if _, err := s.get(id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryPageSize
+	}
+	if limit > maxWebhookDeliveryPageSize {
+		limit = maxWebhookDeliveryPageSize
+	}
+
+	query := s.db.Where("webhook_id = ?", id)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	responses := make([]models.WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *webhookDeliveryResponse(&deliveries[i])
+	}
+	return responses, nil
+}
+
+// Redeliver queues a delivery of a webhook again, with its original body
+func (s *WebhookService) Redeliver(ctx context.Context, id, deliveryID uint) (*models.WebhookDeliveryResponse, error) {
+	var delivery models.WebhookDelivery
+	if err := s.db.Where("id = ? AND webhook_id = ?", deliveryID, id).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if _, err := s.jobs.Enqueue(ctx, models.JobTypeWebhookDeliver, models.WebhookDeliveryJobPayload{DeliveryID: delivery.ID}); err != nil {
+		return nil, err
+	}
+	return webhookDeliveryResponse(&delivery), nil
+}
+
+// Deliver posts a delivery to its webhook and records the attempt. A failed
+// attempt returns an error so the job is retried.
+func (s *WebhookService) Deliver(ctx context.Context, deliveryID uint) error {
+	var delivery models.WebhookDelivery
+	if err := s.db.First(&delivery, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	webhook, err := s.get(delivery.WebhookID)
+	if err != nil && !errors.Is(err, ErrWebhookNotFound) {
+		return err
+	}
+	if webhook == nil || !webhook.IsActive {
+		// Nothing to retry: the webhook was deleted or disabled after the event
+		s.record(&delivery, 0, "", errors.New("webhook was deleted or disabled"), 0)
+		return nil
+	}
+
+	started := s.now()
+	status, body, err := s.post(ctx, webhook, &delivery)
+	s.record(&delivery, status, body, err, s.now().Sub(started).Milliseconds())
+	return err
+}
+
+// queue records a delivery of an event to a webhook and queues the job sending it
+func (s *WebhookService) queue(ctx context.Context, webhook *models.Webhook, eventID string, event models.WebhookEvent, data interface{}) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(models.WebhookPayload{ID: eventID, Event: event, CreatedAt: s.now().UTC(), Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		TenantID:  webhook.TenantID,
+		WebhookID: webhook.ID,
+		EventID:   eventID,
+		Event:     event,
+		Payload:   models.JSON(body),
+		Status:    models.WebhookDeliveryPending,
+	}
+	if err := s.db.Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	if _, err := s.jobs.Enqueue(ctx, models.JobTypeWebhookDeliver, models.WebhookDeliveryJobPayload{DeliveryID: delivery.ID}); err != nil {
+		s.record(delivery, 0, "", err, 0)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// post sends the body of a delivery, returning the response status and the
+// start of the response body
+func (s *WebhookService) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NaraPulse-Webhooks/1.0")
+	req.Header.Set("X-Narapulse-Event", string(delivery.Event))
+	req.Header.Set("X-Narapulse-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Narapulse-Signature", "sha256="+signWebhook(webhook.Secret, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to deliver event: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("webhook rejected event with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// record saves the outcome of a delivery attempt
+func (s *WebhookService) record(delivery *models.WebhookDelivery, status int, body string, attemptErr error, durationMs int64) {
+	now := s.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.DurationMs = durationMs
+	delivery.Status = models.WebhookDeliverySucceeded
+	delivery.Error = ""
+	if attemptErr != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = attemptErr.Error()
+	} else {
+		delivery.DeliveredAt = &now
+	}
+	if err := s.db.Save(delivery).Error; err != nil {
+		logger.L().Error().Err(err).Uint("delivery_id", delivery.ID).Msg("Failed to record webhook delivery")
+	}
+}
+
+// applyWebhook copies a request onto a webhook
+func applyWebhook(webhook *models.Webhook, req *models.WebhookRequest) {
+	events, _ := json.Marshal(req.Events)
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.Events = models.JSON(events)
+	webhook.IsActive = req.IsActive == nil || *req.IsActive
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+}
+
+// webhookResponse converts a webhook to its response, with the secret when given
+func webhookResponse(webhook *models.Webhook, secret string) *models.WebhookResponse {
+	response := &models.WebhookResponse{Webhook: *webhook, Events: []models.WebhookEvent{}, Secret: secret}
+	json.Unmarshal(webhook.Events, &response.Events)
+	return response
+}
+
+// webhookDeliveryResponse converts a delivery to its response
+func webhookDeliveryResponse(delivery *models.WebhookDelivery) *models.WebhookDeliveryResponse {
+	return &models.WebhookDeliveryResponse{WebhookDelivery: *delivery, Payload: json.RawMessage(delivery.Payload)}
+}
+
+// webhookSubscribed reports whether a webhook subscribed to an event
+func webhookSubscribed(webhook *models.Webhook, event models.WebhookEvent) bool {
+	var events []models.WebhookEvent
+	if err := json.Unmarshal(webhook.Events, &events); err != nil {
+		return false
+	}
+	for _, subscribed := range events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// signWebhook returns the hex HMAC-SHA256 of a body with the webhook secret
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	models "narapulse-be/internal/models/entity"
+	"narapulse-be/internal/pkg/tenancy"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestWebhookSubscribed(t *testing.T) {
+	webhook := &models.Webhook{Events: models.JSON(`["query.completed","alert.triggered"]`)}
+
+	assert.True(t, webhookSubscribed(webhook, models.WebhookEventQueryCompleted))
+	assert.True(t, webhookSubscribed(webhook, models.WebhookEventAlertTriggered))
+	assert.False(t, webhookSubscribed(webhook, models.WebhookEventSchemaChanged))
+	assert.False(t, webhookSubscribed(&models.Webhook{}, models.WebhookEventQueryCompleted))
+}
+
+func TestWebhookPostSignsBody(t *testing.T) {
+	body := []byte(`{"id":"e1","event":"query.completed","data":{}}`)
+	var signature, event, delivery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		assert.Equal(t, body, got)
+		signature = r.Header.Get("X-Narapulse-Signature")
+		event = r.Header.Get("X-Narapulse-Event")
+		delivery = r.Header.Get("X-Narapulse-Delivery")
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	service := NewWebhookService(nil, nil)
+	webhook := &models.Webhook{URL: server.URL, Secret: "webhook-secret"}
+	status, response, err := service.post(context.Background(), webhook, &models.WebhookDelivery{ID: 9, Event: models.WebhookEventQueryCompleted, Payload: models.JSON(body)})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", response)
+
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+	assert.Equal(t, "query.completed", event)
+	assert.Equal(t, "9", delivery)
+}
+
+func TestWebhookPostFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("maintenance"))
+	}))
+	defer server.Close()
+
+	service := NewWebhookService(nil, nil)
+	status, response, err := service.post(context.Background(), &models.Webhook{URL: server.URL}, &models.WebhookDelivery{Payload: models.JSON(`{}`)})
+	assert.ErrorContains(t, err, "status 503")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "maintenance", response)
+}
+
+func TestPublishOnNilService(t *testing.T) {
+	var service *WebhookService
+	assert.NotPanics(t, func() { service.Publish(context.Background(), models.WebhookEventQueryCompleted, nil) })
+}
+
+func TestPublishOnlyQueuesToWebhooksOfEventTenant(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenancy.Plugin{Models: models.TenantModels()}))
+	require.NoError(t, db.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{}, &models.Job{}))
+
+	events := models.JSON(`["query.completed"]`)
+	acme := &models.Webhook{TenantID: 1, Name: "acme", URL: "https://acme.example.com", Secret: "s", Events: events, IsActive: true}
+	globex := &models.Webhook{TenantID: 2, Name: "globex", URL: "https://globex.example.com", Secret: "s", Events: events, IsActive: true}
+	require.NoError(t, db.Create(acme).Error)
+	require.NoError(t, db.Create(globex).Error)
+
+	service := NewWebhookService(db, NewJobService(db, "test", JobOptions{}))
+	service.Publish(tenancy.WithTenant(context.Background(), 2), models.WebhookEventQueryCompleted, map[string]string{"nl_query": "revenue of globex"})
+	service.Publish(context.Background(), models.WebhookEventQueryCompleted, map[string]string{"nl_query": "no tenant"})
+
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, db.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1, "events are neither queued to other tenants nor published without a tenant")
+	assert.Equal(t, globex.ID, deliveries[0].WebhookID)
+	assert.Equal(t, uint(2), deliveries[0].TenantID)
+
+	var job models.Job
+	require.NoError(t, db.First(&job).Error)
+	assert.Equal(t, uint(2), job.TenantID, "the delivery is sent in the tenant of the event")
+}
@@ -26,50 +26,106 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"narapulse-be/internal/config"
+	"narapulse-be/internal/middleware"
+	models "narapulse-be/internal/models/entity"
 	"narapulse-be/internal/pkg/database"
+	"narapulse-be/internal/pkg/logger"
 	"narapulse-be/internal/routes"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	// Load configuration: defaults, CONFIG_FILE and the environment, with secrets
+	// read from their backends. Problems stop the boot, listed together.
+	configService, err := config.NewService(context.Background())
+	if err != nil {
+		logger.L().Fatal().Err(err).Msg("Failed to load configuration")
+	}
+	config.SetDefault(configService)
+	cfg := configService.Config()
+	logger.Setup(cfg.LogLevel, cfg.LogFormat)
+
+	// Hot settings are reloaded on SIGHUP and when CONFIG_FILE changes
+	configService.OnReload(func(_, current *config.Config) {
+		if err := logger.SetLevel(current.LogLevel); err != nil {
+			logger.L().Warn().Err(err).Msg("Log level not changed")
+		}
+	})
+	go configService.Watch(context.Background(), time.Duration(cfg.ConfigReloadIntervalSeconds)*time.Second)
 
 	// Initialize database
 	db, err := database.Initialize(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		logger.L().Fatal().Err(err).Msg("Failed to connect to database")
 	}
 
-	// Run auto-migration
-	if err := database.AutoMigrate(db); err != nil {
-		log.Fatal("Failed to run auto-migration:", err)
+	// "migrate [up|down|status]" manages the migrations and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.RunMigrateCommand(context.Background(), db, os.Args[2:], os.Stdout); err != nil {
+			logger.L().Fatal().Err(err).Msg("Migration failed")
+		}
+		return
+	}
+
+	// Apply pending migrations
+	if cfg.DBMigrateOnStart {
+		if err := database.Migrate(context.Background(), db); err != nil {
+			logger.L().Fatal().Err(err).Msg("Failed to run migrations")
+		}
 	}
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
+		// Large enough for 50MB single-request uploads and 32MB parts of chunked uploads
+		BodyLimit: 64 * 1024 * 1024,
+		// Errors no handler turned into a response, such as unknown routes or
+		// oversized bodies, keep the status Fiber gave them
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Internal Server Error",
-				"error":   err.Error(),
-			})
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				return models.ErrorResponseWithStatus(c, fiberErr.Code, fiberErr.Message, nil)
+			}
+			return models.InternalServerErrorResponse(c, "Internal Server Error", err.Error())
 		},
 	})
 
 	// Middleware
-	app.Use(logger.New())
+	app.Use(middleware.RequestIDMiddleware())
+	app.Use(middleware.RequestLoggerMiddleware())
 	app.Use(cors.New())
+	app.Use(middleware.MetricsMiddleware())
 
 	// Setup routes
-	routes.Setup(app, db)
+	stopGRPC, err := routes.Setup(app, db)
+	if err != nil {
+		logger.L().Fatal().Err(err).Msg("Failed to set up the server")
+	}
+
+	// On SIGINT or SIGTERM stop accepting requests and let the ones in flight finish
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		logger.L().Info().Msg("Server shutting down")
+		stopGRPC()
+		if err := app.Shutdown(); err != nil {
+			logger.L().Error().Err(err).Msg("Failed to shut down the server")
+		}
+	}()
 
 	// Start server
-	log.Printf("Server starting on port %s", cfg.Port)
-	log.Fatal(app.Listen(":" + cfg.Port))
+	logger.L().Info().Str("port", cfg.Port).Msg("Server starting")
+	if err := app.Listen(":" + cfg.Port); err != nil {
+		logger.L().Fatal().Err(err).Msg("Server stopped")
+	}
 }
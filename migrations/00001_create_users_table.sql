@@ -1,5 +1,6 @@
 -- +goose Up
-CREATE TABLE users (
+-- Tables may exist where they were created before migrations were tracked
+CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     email VARCHAR(255) UNIQUE NOT NULL,
     username VARCHAR(255) UNIQUE NOT NULL,
@@ -13,9 +14,9 @@ CREATE TABLE users (
     deleted_at TIMESTAMP NULL
 );
 
-CREATE INDEX idx_users_email ON users(email);
-CREATE INDEX idx_users_username ON users(username);
-CREATE INDEX idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
 
 -- Insert default admin user
 INSERT INTO users (email, username, password, first_name, last_name, role) 
@@ -26,7 +27,7 @@ VALUES (
     'Admin', 
     'User', 
     'admin'
-);
+) ON CONFLICT DO NOTHING;
 
 -- +goose Down
 DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -2,7 +2,7 @@
 -- +goose StatementBegin
 
 -- Create data_sources table
-CREATE TABLE data_sources (
+CREATE TABLE IF NOT EXISTS data_sources (
     id SERIAL PRIMARY KEY,
     user_id INTEGER NOT NULL,
     name VARCHAR(255) NOT NULL,
@@ -19,11 +19,11 @@ CREATE TABLE data_sources (
 );
 
 -- Create indexes
-CREATE INDEX idx_data_sources_user_id ON data_sources(user_id);
-CREATE INDEX idx_data_sources_deleted_at ON data_sources(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_data_sources_user_id ON data_sources(user_id);
+CREATE INDEX IF NOT EXISTS idx_data_sources_deleted_at ON data_sources(deleted_at);
 
 -- Create schemas table
-CREATE TABLE schemas (
+CREATE TABLE IF NOT EXISTS schemas (
     id SERIAL PRIMARY KEY,
     data_source_id INTEGER NOT NULL,
     name VARCHAR(255) NOT NULL,
@@ -40,8 +40,8 @@ CREATE TABLE schemas (
 );
 
 -- Create indexes for schemas table
-CREATE INDEX idx_schemas_data_source_id ON schemas(data_source_id);
-CREATE INDEX idx_schemas_deleted_at ON schemas(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_schemas_data_source_id ON schemas(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_schemas_deleted_at ON schemas(deleted_at);
 
 -- +goose StatementEnd
 
@@ -0,0 +1,87 @@
+-- +goose Up
+-- Migration: Create NL2SQL query tables
+-- Description: Natural language questions with the SQL generated for them, and their results. Results
+-- too large for one row keep their rows in chunks. These tables used to be created by GORM
+-- auto-migration, so they may exist already, without the columns added since.
+
+CREATE TABLE IF NOT EXISTS nl2_sql_queries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    data_source_id INTEGER NOT NULL,
+    nl_query TEXT NOT NULL,
+    language VARCHAR(8) DEFAULT 'en',
+    generated_sql TEXT,
+    sql_version INTEGER DEFAULT 0, -- Current entry in query_sql_versions
+    parameters JSONB, -- {{name}} placeholders detected in generated_sql
+    prompt_template_id INTEGER, -- NULL for the built-in prompt
+    prompt_template_version INTEGER DEFAULT 0,
+    parent_query_id INTEGER, -- Query this one was derived from
+    parent_sql_version INTEGER DEFAULT 0,
+    status VARCHAR(50) DEFAULT 'pending',
+    type VARCHAR(50) DEFAULT 'analytics',
+    context JSONB,
+    metadata JSONB,
+    error_msg TEXT,
+    execution_time BIGINT DEFAULT 0, -- Milliseconds
+    rows_returned BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Columns added since GORM auto-migration created the table
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS language VARCHAR(8) DEFAULT 'en';
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS sql_version INTEGER DEFAULT 0;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS parameters JSONB;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS prompt_template_id INTEGER;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS prompt_template_version INTEGER DEFAULT 0;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS parent_query_id INTEGER;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS parent_sql_version INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_user_id ON nl2_sql_queries(user_id);
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_data_source_id ON nl2_sql_queries(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_parent_query_id ON nl2_sql_queries(parent_query_id);
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_updated_at ON nl2_sql_queries(updated_at);
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_deleted_at ON nl2_sql_queries(deleted_at);
+
+CREATE TABLE IF NOT EXISTS query_results (
+    id SERIAL PRIMARY KEY,
+    query_id INTEGER NOT NULL REFERENCES nl2_sql_queries(id),
+    columns JSONB,
+    data JSONB, -- Rows of results without chunks
+    row_count BIGINT DEFAULT 0,
+    sql_version INTEGER DEFAULT 0, -- Version of the query SQL that produced the result
+    chunk_count INTEGER DEFAULT 0, -- Rows are stored in query_result_chunks when > 0
+    archived_at TIMESTAMP WITH TIME ZONE, -- Rows moved to cold storage
+    archive_key TEXT,
+    archive_bytes BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE query_results ADD COLUMN IF NOT EXISTS sql_version INTEGER DEFAULT 0;
+ALTER TABLE query_results ADD COLUMN IF NOT EXISTS chunk_count INTEGER DEFAULT 0;
+ALTER TABLE query_results ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE query_results ADD COLUMN IF NOT EXISTS archive_key TEXT;
+ALTER TABLE query_results ADD COLUMN IF NOT EXISTS archive_bytes BIGINT DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_query_results_query_id ON query_results(query_id);
+CREATE INDEX IF NOT EXISTS idx_query_results_archived_at ON query_results(archived_at);
+CREATE INDEX IF NOT EXISTS idx_query_results_deleted_at ON query_results(deleted_at);
+
+CREATE TABLE IF NOT EXISTS query_result_chunks (
+    id SERIAL PRIMARY KEY,
+    result_id INTEGER NOT NULL,
+    chunk_index INTEGER NOT NULL,
+    row_offset BIGINT NOT NULL, -- Index of the first row of the chunk in the result
+    row_count INTEGER NOT NULL,
+    data JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_result_chunks_result_chunk ON query_result_chunks(result_id, chunk_index);
+
+-- +goose Down
+DROP TABLE IF EXISTS query_result_chunks;
+DROP TABLE IF EXISTS query_results;
+DROP TABLE IF EXISTS nl2_sql_queries;
@@ -112,6 +112,7 @@ CREATE INDEX IF NOT EXISTS idx_rag_query_contexts_embedding_cosine
 -- Note: Foreign key constraints will be added manually after confirming table existence
 
 -- Create updated_at trigger function if it doesn't exist
+-- +goose StatementBegin
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $func$
 BEGIN
@@ -119,6 +120,7 @@ BEGIN
     RETURN NEW;
 END;
 $func$ language 'plpgsql';
+-- +goose StatementEnd
 
 -- Create triggers for updated_at columns
 DROP TRIGGER IF EXISTS update_schema_embeddings_updated_at ON schema_embeddings;
@@ -140,14 +142,17 @@ CREATE TRIGGER update_business_glossaries_updated_at
     EXECUTE FUNCTION update_updated_at_column();
 
 -- Create a function to calculate cosine similarity (for reference)
+-- +goose StatementBegin
 CREATE OR REPLACE FUNCTION cosine_similarity(a vector, b vector)
 RETURNS float AS $cosine$
 BEGIN
     RETURN 1 - (a <=> b);
 END;
 $cosine$ LANGUAGE plpgsql IMMUTABLE STRICT;
+-- +goose StatementEnd
 
 -- Create a function to search similar embeddings
+-- +goose StatementBegin
 CREATE OR REPLACE FUNCTION search_similar_embeddings(
     query_embedding vector,
     data_source_filter INTEGER DEFAULT NULL,
@@ -186,6 +191,7 @@ BEGIN
     LIMIT result_limit;
 END;
 $search$ LANGUAGE plpgsql;
+-- +goose StatementEnd
 
 -- Add comments to tables
 COMMENT ON TABLE schema_embeddings IS 'Stores vector embeddings for schema elements (tables, columns, KPIs, glossary terms)';
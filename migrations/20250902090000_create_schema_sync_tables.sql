@@ -0,0 +1,40 @@
+-- +goose Up
+-- Migration: Create schema sync lock and history tables
+-- Description: Per data source sync locks and sync run history for running several instances safely
+
+-- Create schema_sync_locks table (one active lease per data source)
+CREATE TABLE IF NOT EXISTS schema_sync_locks (
+    data_source_id INTEGER PRIMARY KEY,
+    sync_id VARCHAR(64) NOT NULL,
+    instance_id VARCHAR(255) NOT NULL,
+    locked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_schema_sync_locks_expires_at ON schema_sync_locks(expires_at);
+
+-- Create schema_sync_runs table (sync history)
+CREATE TABLE IF NOT EXISTS schema_sync_runs (
+    id SERIAL PRIMARY KEY,
+    sync_id VARCHAR(64) NOT NULL UNIQUE,
+    data_source_id INTEGER NOT NULL,
+    instance_id VARCHAR(255) NOT NULL,
+    triggered_by VARCHAR(20) NOT NULL, -- manual, scheduled, auto
+    status VARCHAR(20) NOT NULL, -- running, completed, skipped, failed
+    message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_schema_sync_runs_data_source_id ON schema_sync_runs(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_schema_sync_runs_status ON schema_sync_runs(status);
+
+COMMENT ON TABLE schema_sync_locks IS 'Per data source lease held by the instance currently syncing it';
+COMMENT ON TABLE schema_sync_runs IS 'History of schema sync runs with the instance that ran them';
+
+-- +goose Down
+DROP TABLE IF EXISTS schema_sync_runs;
+DROP TABLE IF EXISTS schema_sync_locks;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: Store foreign key relationships on schemas
+-- Description: Adds a relationships column used to build JOIN PATHS for NL2SQL prompts
+
+ALTER TABLE schemas ADD COLUMN IF NOT EXISTS relationships JSONB;
+
+COMMENT ON COLUMN schemas.relationships IS 'Foreign key relationships (from_table, from_column, to_table, to_column) in JSON array format';
+
+-- +goose Down
+ALTER TABLE schemas DROP COLUMN IF EXISTS relationships;
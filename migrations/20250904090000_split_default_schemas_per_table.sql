@@ -0,0 +1,61 @@
+-- +goose Up
+-- Migration: Split legacy "default" schemas into one schema per table
+-- Description: Discovery used to store every column (as table.column) in a single schema named
+-- "default". Create one schema per table with unqualified column names and retire the old rows
+-- together with their embeddings so the next schema sync re-embeds tables and columns separately.
+
+-- +goose StatementBegin
+WITH legacy AS (
+    SELECT id, data_source_id, COALESCE(relationships, '[]'::jsonb) AS relationships,
+        CASE WHEN jsonb_typeof(columns) = 'array' THEN columns ELSE '[]'::jsonb END AS columns
+    FROM schemas
+    WHERE name = 'default' AND deleted_at IS NULL
+),
+split_columns AS (
+    SELECT
+        legacy.id AS legacy_id,
+        legacy.data_source_id,
+        legacy.relationships,
+        regexp_replace(col->>'name', '\.[^.]*$', '') AS table_name,
+        jsonb_set(col, '{name}', to_jsonb(regexp_replace(col->>'name', '^.*\.', ''))) AS column_def,
+        ord
+    FROM legacy, jsonb_array_elements(legacy.columns) WITH ORDINALITY AS c(col, ord)
+    WHERE strpos(col->>'name', '.') > 0
+)
+INSERT INTO schemas (data_source_id, name, display_name, columns, relationships, row_count, is_active, created_at, updated_at)
+SELECT
+    data_source_id,
+    table_name,
+    table_name,
+    jsonb_agg(column_def ORDER BY ord),
+    COALESCE((
+        SELECT jsonb_agg(rel)
+        FROM jsonb_array_elements(split_columns.relationships) AS rel
+        WHERE rel->>'from_table' = split_columns.table_name
+    ), '[]'::jsonb),
+    0,
+    true,
+    CURRENT_TIMESTAMP,
+    CURRENT_TIMESTAMP
+FROM split_columns
+GROUP BY legacy_id, data_source_id, relationships, table_name;
+-- +goose StatementEnd
+
+-- Retire embeddings of the legacy schemas that were split
+UPDATE schema_embeddings
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE deleted_at IS NULL AND schema_id IN (
+    SELECT id FROM schemas
+    WHERE name = 'default' AND deleted_at IS NULL
+        AND EXISTS (SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(columns) = 'array' THEN columns ELSE '[]'::jsonb END) AS col WHERE strpos(col->>'name', '.') > 0)
+);
+
+-- Retire the legacy schemas themselves
+UPDATE schemas
+SET deleted_at = CURRENT_TIMESTAMP, is_active = false
+WHERE name = 'default' AND deleted_at IS NULL
+    AND EXISTS (SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(columns) = 'array' THEN columns ELSE '[]'::jsonb END) AS col WHERE strpos(col->>'name', '.') > 0);
+
+-- +goose Down
+-- Splitting is not reversed; refresh the data source schema to rebuild it from the source
+SELECT 1;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Migration: Create governance event log and compliance webhook cursor
+-- Description: Append-only governance events delivered in sequence order to the compliance webhook
+
+CREATE TABLE IF NOT EXISTS governance_events (
+    id BIGSERIAL PRIMARY KEY, -- Doubles as the delivery sequence number
+    type VARCHAR(50) NOT NULL, -- pii_column_detected, masked_column_queried, approval_granted, policy_changed
+    data_source_id INTEGER,
+    actor_id INTEGER,
+    payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_governance_events_type ON governance_events(type);
+CREATE INDEX IF NOT EXISTS idx_governance_events_data_source_id ON governance_events(data_source_id);
+
+CREATE TABLE IF NOT EXISTS governance_webhook_cursors (
+    id INTEGER PRIMARY KEY,
+    last_sequence BIGINT NOT NULL DEFAULT 0,
+    last_delivered_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE governance_events IS 'Append-only governance event log, replayable by sequence';
+COMMENT ON TABLE governance_webhook_cursors IS 'Last governance event delivered to the compliance webhook';
+
+-- +goose Down
+DROP TABLE IF EXISTS governance_webhook_cursors;
+DROP TABLE IF EXISTS governance_events;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Migration: Create query analytics cache
+-- Description: Daily rollup of nl2_sql_queries, refreshed incrementally, used by the metrics endpoints
+
+CREATE TABLE IF NOT EXISTS query_analytics_daily (
+    day DATE NOT NULL,
+    user_id INTEGER NOT NULL,
+    data_source_id INTEGER NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    query_count BIGINT NOT NULL DEFAULT 0,
+    total_execution_ms BIGINT NOT NULL DEFAULT 0,
+    total_rows BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, user_id, data_source_id, status)
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_analytics_daily_user_day ON query_analytics_daily(user_id, day);
+CREATE INDEX IF NOT EXISTS idx_query_analytics_daily_data_source_day ON query_analytics_daily(data_source_id, day);
+
+CREATE TABLE IF NOT EXISTS analytics_refresh_states (
+    id INTEGER PRIMARY KEY,
+    watermark TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT '1970-01-01 00:00:00+00',
+    refreshed_at TIMESTAMP WITH TIME ZONE,
+    days_updated INTEGER NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE query_analytics_daily IS 'Daily query metrics rollup, recomputed per day when queries change';
+COMMENT ON TABLE analytics_refresh_states IS 'Watermark of the last query analytics refresh';
+
+-- +goose Down
+DROP TABLE IF EXISTS analytics_refresh_states;
+DROP TABLE IF EXISTS query_analytics_daily;
@@ -0,0 +1,58 @@
+-- +goose Up
+-- Migration: Create data API tables
+-- Description: Saved queries published as API key protected GET /apis/{slug} endpoints
+
+CREATE TABLE IF NOT EXISTS data_api_endpoints (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    data_source_id INTEGER NOT NULL,
+    query_id INTEGER, -- Saved NL2SQL query the endpoint was published from
+    slug VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    sql_template TEXT NOT NULL, -- May contain {{param}} placeholders
+    parameters JSONB,
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60,
+    cache_ttl_seconds INTEGER NOT NULL DEFAULT 300,
+    max_rows INTEGER NOT NULL DEFAULT 1000,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_api_endpoints_slug ON data_api_endpoints(slug);
+CREATE INDEX IF NOT EXISTS idx_data_api_endpoints_user_id ON data_api_endpoints(user_id);
+CREATE INDEX IF NOT EXISTS idx_data_api_endpoints_data_source_id ON data_api_endpoints(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_data_api_endpoints_deleted_at ON data_api_endpoints(deleted_at);
+
+CREATE TABLE IF NOT EXISTS data_api_keys (
+    id SERIAL PRIMARY KEY,
+    endpoint_id INTEGER NOT NULL REFERENCES data_api_endpoints(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL, -- SHA-256 of the key, the plaintext is never stored
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_api_keys_key_hash ON data_api_keys(key_hash);
+CREATE INDEX IF NOT EXISTS idx_data_api_keys_endpoint_id ON data_api_keys(endpoint_id);
+
+CREATE TABLE IF NOT EXISTS data_api_rate_windows (
+    endpoint_id INTEGER NOT NULL,
+    api_key_id INTEGER NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    request_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (endpoint_id, api_key_id, window_start)
+);
+
+COMMENT ON TABLE data_api_endpoints IS 'Saved queries published as parameterized REST endpoints';
+COMMENT ON TABLE data_api_keys IS 'Hashed API keys granting access to a data API endpoint';
+COMMENT ON TABLE data_api_rate_windows IS 'Per-minute request counters for data API rate limiting';
+
+-- +goose Down
+DROP TABLE IF EXISTS data_api_rate_windows;
+DROP TABLE IF EXISTS data_api_keys;
+DROP TABLE IF EXISTS data_api_endpoints;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Migration: Create query cost ceilings
+-- Description: Per-user and per-data-source limits on EXPLAIN / dry-run query estimates
+
+CREATE TABLE IF NOT EXISTS query_cost_ceilings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER, -- Set for a user ceiling
+    data_source_id INTEGER, -- Set for a data source ceiling
+    max_cost DOUBLE PRECISION NOT NULL DEFAULT 0, -- PostgreSQL planner cost units, 0 = unlimited
+    max_bytes_scanned BIGINT NOT NULL DEFAULT 0, -- 0 = unlimited
+    max_rows BIGINT NOT NULL DEFAULT 0, -- 0 = unlimited
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_query_cost_ceilings_scope CHECK ((user_id IS NULL) <> (data_source_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_cost_ceilings_user_id ON query_cost_ceilings(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_cost_ceilings_data_source_id ON query_cost_ceilings(data_source_id);
+
+COMMENT ON TABLE query_cost_ceilings IS 'Cost ceilings that block execution of expensive queries';
+
+-- +goose Down
+DROP TABLE IF EXISTS query_cost_ceilings;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: Create query SQL version history
+-- Description: Append-only history of every change to a query's SQL (generation, auto-repair, human edit, rollback)
+
+CREATE TABLE IF NOT EXISTS query_sql_versions (
+    id SERIAL PRIMARY KEY,
+    query_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    sql TEXT NOT NULL,
+    source VARCHAR(20) NOT NULL, -- generated, auto_repair, human_edit, rollback
+    rolled_back_from INTEGER, -- Version restored by a rollback
+    comment TEXT,
+    created_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_sql_versions_query_version ON query_sql_versions(query_id, version);
+
+COMMENT ON TABLE query_sql_versions IS 'Append-only SQL version history of NL2SQL queries for audits';
+
+-- +goose Down
+DROP TABLE IF EXISTS query_sql_versions;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Migration: Create validation policies
+-- Description: Per-data-source SQL validation rules with a workspace default policy
+
+CREATE TABLE IF NOT EXISTS validation_policies (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER, -- NULL for the workspace default policy
+    name VARCHAR(100) NOT NULL,
+    allowed_functions JSONB DEFAULT '[]',
+    blocked_functions JSONB DEFAULT '[]',
+    blocked_keywords JSONB DEFAULT '[]',
+    blocked_patterns JSONB DEFAULT '[]', -- Case-insensitive regular expressions
+    max_joins INTEGER NOT NULL DEFAULT 0, -- 0 = default (warning only)
+    max_limit INTEGER NOT NULL DEFAULT 0, -- 0 = default
+    require_where_on_large_tables BOOLEAN NOT NULL DEFAULT FALSE,
+    large_table_row_threshold BIGINT NOT NULL DEFAULT 0, -- 0 = 1,000,000 rows
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_validation_policies_data_source_id ON validation_policies(data_source_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_validation_policies_default ON validation_policies((data_source_id IS NULL)) WHERE data_source_id IS NULL;
+
+COMMENT ON TABLE validation_policies IS 'SQL validation rules loaded by the validator at runtime';
+
+-- +goose Down
+DROP TABLE IF EXISTS validation_policies;
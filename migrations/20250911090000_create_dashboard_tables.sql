@@ -0,0 +1,54 @@
+-- +goose Up
+-- Migration: Create dashboard tables
+-- Description: Dashboards of query widgets, shared with collaborators and edited in realtime
+
+CREATE TABLE IF NOT EXISTS dashboards (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL, -- Owner
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboards_user_id ON dashboards(user_id);
+CREATE INDEX IF NOT EXISTS idx_dashboards_deleted_at ON dashboards(deleted_at);
+
+CREATE TABLE IF NOT EXISTS dashboard_widgets (
+    id SERIAL PRIMARY KEY,
+    dashboard_id INTEGER NOT NULL REFERENCES dashboards(id) ON DELETE CASCADE,
+    query_id INTEGER, -- NL2SQL query the widget displays
+    type VARCHAR(20) NOT NULL, -- table, chart, kpi, text
+    title VARCHAR(200),
+    config JSONB,
+    x INTEGER NOT NULL DEFAULT 0,
+    y INTEGER NOT NULL DEFAULT 0,
+    width INTEGER NOT NULL DEFAULT 4,
+    height INTEGER NOT NULL DEFAULT 3,
+    version INTEGER NOT NULL DEFAULT 1, -- Optimistic concurrency for collaborative edits
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboard_widgets_dashboard_id ON dashboard_widgets(dashboard_id);
+
+CREATE TABLE IF NOT EXISTS dashboard_collaborators (
+    dashboard_id INTEGER NOT NULL REFERENCES dashboards(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'editor', -- editor, viewer
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (dashboard_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dashboard_collaborators_user_id ON dashboard_collaborators(user_id);
+
+COMMENT ON TABLE dashboards IS 'Dashboards of query widgets';
+COMMENT ON TABLE dashboard_widgets IS 'Widgets placed on a dashboard grid';
+COMMENT ON TABLE dashboard_collaborators IS 'Users a dashboard is shared with';
+
+-- +goose Down
+DROP TABLE IF EXISTS dashboard_collaborators;
+DROP TABLE IF EXISTS dashboard_widgets;
+DROP TABLE IF EXISTS dashboards;
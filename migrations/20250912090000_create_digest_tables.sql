@@ -0,0 +1,34 @@
+-- +goose Up
+-- Migration: Create digest tables
+-- Description: Digest email preferences and KPI values observed by answer mode for digest KPI movements
+
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE,
+    frequency VARCHAR(10) NOT NULL DEFAULT 'weekly', -- off, daily, weekly
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_subscriptions_next_run_at ON digest_subscriptions(next_run_at);
+
+CREATE TABLE IF NOT EXISTS kpi_snapshots (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    kpi_id INTEGER NOT NULL REFERENCES kpi_definitions(id) ON DELETE CASCADE,
+    query_id INTEGER, -- NL2SQL query whose answer produced the value
+    value DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_kpi_snapshots_user_id ON kpi_snapshots(user_id);
+CREATE INDEX IF NOT EXISTS idx_kpi_snapshots_kpi_id_recorded_at ON kpi_snapshots(kpi_id, recorded_at);
+
+COMMENT ON TABLE digest_subscriptions IS 'Digest email frequency per user and when the next digest is due';
+COMMENT ON TABLE kpi_snapshots IS 'KPI values observed when answering questions, compared across digest periods';
+
+-- +goose Down
+DROP TABLE IF EXISTS kpi_snapshots;
+DROP TABLE IF EXISTS digest_subscriptions;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: Create custom SQL functions
+-- Description: Per-data-source warehouse functions (UDFs) allowed by the SQL validator and documented in the NL2SQL prompt
+
+CREATE TABLE IF NOT EXISTS custom_sql_functions (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    name VARCHAR(128) NOT NULL, -- Upper-case function name
+    signature TEXT NOT NULL,
+    description TEXT,
+    example TEXT,
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_sql_functions_name ON custom_sql_functions(data_source_id, name);
+
+COMMENT ON TABLE custom_sql_functions IS 'Warehouse functions allowed per data source in addition to the validator defaults';
+
+-- +goose Down
+DROP TABLE IF EXISTS custom_sql_functions;
@@ -0,0 +1,54 @@
+-- +goose Up
+-- Migration: Create query collaboration tables
+-- Description: Short-lived links through which teammates review a query's SQL before execution, their suggested edits and the audit trail
+
+CREATE TABLE IF NOT EXISTS query_collaboration_links (
+    id SERIAL PRIMARY KEY,
+    query_id INTEGER NOT NULL,
+    created_by INTEGER NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL, -- SHA-256 of the token
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_collaboration_links_token_hash ON query_collaboration_links(token_hash);
+CREATE INDEX IF NOT EXISTS idx_query_collaboration_links_query_id ON query_collaboration_links(query_id);
+
+CREATE TABLE IF NOT EXISTS query_sql_suggestions (
+    id SERIAL PRIMARY KEY,
+    query_id INTEGER NOT NULL,
+    link_id INTEGER NOT NULL,
+    suggested_by INTEGER NOT NULL,
+    base_version INTEGER, -- SQL version the suggestion was made on
+    sql TEXT NOT NULL,
+    comment TEXT,
+    validation JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, rejected
+    reviewed_by INTEGER,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    applied_version INTEGER, -- SQL version created on accept
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_sql_suggestions_query_id ON query_sql_suggestions(query_id);
+
+CREATE TABLE IF NOT EXISTS query_collaboration_events (
+    id SERIAL PRIMARY KEY,
+    query_id INTEGER NOT NULL,
+    link_id INTEGER,
+    suggestion_id INTEGER,
+    user_id INTEGER NOT NULL,
+    action VARCHAR(30) NOT NULL, -- link_created, link_revoked, viewed, suggested, suggestion_accepted, suggestion_rejected
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_collaboration_events_query_id ON query_collaboration_events(query_id);
+
+COMMENT ON TABLE query_collaboration_events IS 'Attributed audit trail of actions taken through query collaboration links';
+
+-- +goose Down
+DROP TABLE IF EXISTS query_collaboration_events;
+DROP TABLE IF EXISTS query_sql_suggestions;
+DROP TABLE IF EXISTS query_collaboration_links;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: Record canary runs on query SQL versions
+-- Description: Edits of queries shown on dashboards are compared against the previous SQL before going live
+
+ALTER TABLE query_sql_versions ADD COLUMN IF NOT EXISTS canary JSONB;
+
+COMMENT ON COLUMN query_sql_versions.canary IS 'Canary report comparing the result with the previous version, including whether a divergence was confirmed';
+
+-- +goose Down
+ALTER TABLE query_sql_versions DROP COLUMN IF EXISTS canary;
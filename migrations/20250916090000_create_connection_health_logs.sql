@@ -0,0 +1,19 @@
+-- +goose Up
+-- Migration: Create connection health logs
+-- Description: History of the scheduled connection tests of data sources, used for uptime statistics
+
+CREATE TABLE IF NOT EXISTS connection_health_logs (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL, -- active or error
+    error_message TEXT,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_connection_health_logs_source_checked ON connection_health_logs(data_source_id, checked_at);
+
+COMMENT ON TABLE connection_health_logs IS 'Scheduled connection checks of data sources, kept for 30 days';
+
+-- +goose Down
+DROP TABLE IF EXISTS connection_health_logs;
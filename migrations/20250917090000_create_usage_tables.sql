@@ -0,0 +1,34 @@
+-- +goose Up
+-- Migration: Create AI usage tables
+-- Description: Tokens and estimated cost of embedding and LLM calls, and per-user monthly quota overrides
+
+CREATE TABLE IF NOT EXISTS usage_records (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL DEFAULT 0, -- 0 for calls outside a user request
+    kind VARCHAR(20) NOT NULL, -- embedding or llm
+    model VARCHAR(100) NOT NULL,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_user_created ON usage_records(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS usage_quotas (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE,
+    monthly_tokens BIGINT NOT NULL DEFAULT 0, -- 0 means unlimited
+    monthly_cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0, -- 0 means unlimited
+    updated_by INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE usage_records IS 'Token usage and estimated cost of each embedding and LLM call';
+COMMENT ON TABLE usage_quotas IS 'Monthly AI quotas of users overriding the configured default';
+
+-- +goose Down
+DROP TABLE IF EXISTS usage_quotas;
+DROP TABLE IF EXISTS usage_records;
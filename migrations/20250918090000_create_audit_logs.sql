@@ -0,0 +1,29 @@
+-- +goose Up
+-- Migration: Create audit logs
+-- Description: Audit trail of sensitive actions with the actor, client IP and before/after snapshots
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER NOT NULL DEFAULT 0,
+    actor_email VARCHAR(255),
+    action VARCHAR(50) NOT NULL, -- e.g. data_source.update, query.execute, user.role_change, data.export
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(100),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    before JSONB, -- Snapshot with secrets redacted
+    after JSONB,
+    changes JSONB, -- Changed field paths with their old and new values
+    details JSONB, -- e.g. the executed SQL
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at);
+
+COMMENT ON TABLE audit_logs IS 'Append-only audit trail of data source changes, query executions, role changes and exports';
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_logs;
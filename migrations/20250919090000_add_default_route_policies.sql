@@ -0,0 +1,67 @@
+-- +goose Up
+-- Migration: Add default route access policies
+-- Description: Casbin now authorizes every protected route, so installations seeded with only the
+-- profile and admin policies get the user route policies; existing rules are left untouched
+
+CREATE TABLE IF NOT EXISTS casbin_rule (
+    id SERIAL PRIMARY KEY,
+    ptype VARCHAR(100),
+    v0 VARCHAR(100),
+    v1 VARCHAR(100),
+    v2 VARCHAR(100),
+    v3 VARCHAR(100),
+    v4 VARCHAR(100),
+    v5 VARCHAR(100)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_casbin_rule ON casbin_rule(ptype, v0, v1, v2, v3, v4, v5);
+
+INSERT INTO casbin_rule (ptype, v0, v1, v2, v3, v4, v5)
+SELECT defaults.ptype, defaults.v0, defaults.v1, defaults.v2, '', '', ''
+FROM (VALUES
+    ('p', 'admin', '/api/v1/*', '*'),
+    ('p', 'user', '/api/v1/profile', '*'),
+    ('p', 'user', '/api/v1/data-sources*', '*'),
+    ('p', 'user', '/api/v1/nl2sql*', '*'),
+    ('p', 'user', '/api/v1/rag/search', '*'),
+    ('p', 'user', '/api/v1/rag/nl2sql-*', '*'),
+    ('p', 'user', '/api/v1/rag/schemas/*', '*'),
+    ('p', 'user', '/api/v1/rag/sync/*', '*'),
+    ('p', 'user', '/api/v1/rag/kpi', '*'),
+    ('p', 'user', '/api/v1/rag/glossary', '*'),
+    ('p', 'user', '/api/v1/rag/embeddings/*', '*'),
+    ('p', 'user', '/api/v1/schema-sync*', '*'),
+    ('p', 'user', '/api/v1/usage*', 'GET'),
+    ('p', 'user', '/api/v1/analytics/queries', 'GET'),
+    ('p', 'user', '/api/v1/data-apis*', '*'),
+    ('p', 'user', '/api/v1/dashboards*', '*'),
+    ('p', 'user', '/api/v1/digest*', '*')
+) AS defaults(ptype, v0, v1, v2)
+WHERE NOT EXISTS (
+    SELECT 1 FROM casbin_rule rule
+    WHERE rule.ptype = defaults.ptype AND rule.v0 = defaults.v0 AND rule.v1 = defaults.v1 AND rule.v2 = defaults.v2
+);
+
+COMMENT ON TABLE casbin_rule IS 'Casbin route policies (p) and role assignments (g)';
+
+-- +goose Down
+DELETE FROM casbin_rule
+WHERE ptype = 'p' AND (v0, v1, v2) IN (
+    ('admin', '/api/v1/*', '*'),
+    ('user', '/api/v1/profile', '*'),
+    ('user', '/api/v1/data-sources*', '*'),
+    ('user', '/api/v1/nl2sql*', '*'),
+    ('user', '/api/v1/rag/search', '*'),
+    ('user', '/api/v1/rag/nl2sql-*', '*'),
+    ('user', '/api/v1/rag/schemas/*', '*'),
+    ('user', '/api/v1/rag/sync/*', '*'),
+    ('user', '/api/v1/rag/kpi', '*'),
+    ('user', '/api/v1/rag/glossary', '*'),
+    ('user', '/api/v1/rag/embeddings/*', '*'),
+    ('user', '/api/v1/schema-sync*', '*'),
+    ('user', '/api/v1/usage*', 'GET'),
+    ('user', '/api/v1/analytics/queries', 'GET'),
+    ('user', '/api/v1/data-apis*', '*'),
+    ('user', '/api/v1/dashboards*', '*'),
+    ('user', '/api/v1/digest*', '*')
+);
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Migration: Create MFA tables
+-- Description: TOTP second factors of users, their single-use recovery codes and the
+-- workspace flags that require MFA for every user or for admins
+
+CREATE TABLE IF NOT EXISTS user_mfas (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE,
+    secret VARCHAR(64) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_counter BIGINT NOT NULL DEFAULT 0,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+CREATE TABLE IF NOT EXISTS mfa_settings (
+    id SERIAL PRIMARY KEY,
+    require_all BOOLEAN NOT NULL DEFAULT FALSE,
+    require_admins BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by INTEGER,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE user_mfas IS 'TOTP second factor of each user; secret is set at enrollment and enabled once a code is confirmed';
+COMMENT ON TABLE mfa_recovery_codes IS 'SHA-256 hashes of single-use MFA recovery codes';
+COMMENT ON TABLE mfa_settings IS 'Workspace MFA enforcement flags (single row)';
+
+-- +goose Down
+DROP TABLE IF EXISTS mfa_settings;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfas;
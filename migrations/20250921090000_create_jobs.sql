@@ -0,0 +1,35 @@
+-- +goose Up
+-- Migration: Create jobs table
+-- Description: Persistent queue of the background job runner (schema discovery, schema
+-- syncs, connection health checks) with retries and a dead letter state
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload JSONB,
+    key VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by VARCHAR(255) NOT NULL DEFAULT '',
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Workers claim the oldest due pending job
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(run_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs(type);
+
+-- At most one queued or running job per key (scheduled runs, sync of all data sources)
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_jobs_active_key ON jobs(key)
+    WHERE key <> '' AND status IN ('pending', 'running');
+
+COMMENT ON TABLE jobs IS 'Background jobs; failed runs are retried with exponential backoff until max_attempts, then marked dead';
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
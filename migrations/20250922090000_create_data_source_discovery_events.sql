@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration: Create data source discovery events table
+-- Description: Status transitions (connecting, discovering, active, error) of the background
+-- connection test and schema discovery of data sources
+
+CREATE TABLE IF NOT EXISTS data_source_discovery_events (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    job_id INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_source_discovery_events_data_source_id ON data_source_discovery_events(data_source_id);
+
+COMMENT ON TABLE data_source_discovery_events IS 'Status transitions of data source schema discovery, with the job that ran it';
+
+-- +goose Down
+DROP TABLE IF EXISTS data_source_discovery_events;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration: Create data source templates table
+-- Description: Admin-curated connection templates; string values may contain {{name}}
+-- placeholders that users fill in, and credentials are always placeholders
+
+CREATE TABLE IF NOT EXISTS data_source_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    type VARCHAR(50) NOT NULL,
+    config JSONB,
+    created_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE data_source_templates IS 'Connection templates with {{placeholders}} for onboarding similar data sources';
+
+-- +goose Down
+DROP TABLE IF EXISTS data_source_templates;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: Create schema changes table
+-- Description: Differences found when a data source is rediscovered, with the saved
+-- queries and KPIs that reference removed tables or columns
+
+CREATE TABLE IF NOT EXISTS schema_changes (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    has_changes BOOLEAN NOT NULL DEFAULT FALSE,
+    diff JSONB,
+    affected_queries JSONB,
+    affected_kpis JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_schema_changes_data_source_id ON schema_changes(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_schema_changes_created_at ON schema_changes(created_at);
+
+COMMENT ON TABLE schema_changes IS 'Added, removed and retyped tables and columns per schema rediscovery';
+
+-- +goose Down
+DROP TABLE IF EXISTS schema_changes;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Migration: Create column metadata table
+-- Description: Curated display names, descriptions, semantic tags and PII flags of data
+-- source columns, kept by table and column name so they survive schema rediscovery
+
+CREATE TABLE IF NOT EXISTS column_metadata (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    table_name VARCHAR(255) NOT NULL,
+    column_name VARCHAR(255) NOT NULL,
+    display_name VARCHAR(255),
+    description TEXT,
+    tags JSONB,
+    pii BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_column_metadata_column ON column_metadata(data_source_id, table_name, column_name);
+
+COMMENT ON TABLE column_metadata IS 'User-curated metadata of data source columns';
+
+-- +goose Down
+DROP TABLE IF EXISTS column_metadata;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Migration: Create schema profiles table
+-- Description: Latest data profile of each schema (per-column completeness, uniqueness,
+-- min/max, top values and histograms), served from cache until refreshed
+
+CREATE TABLE IF NOT EXISTS schema_profiles (
+    id SERIAL PRIMARY KEY,
+    schema_id INTEGER NOT NULL,
+    data_source_id INTEGER NOT NULL,
+    profile JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schema_profiles_schema_id ON schema_profiles(schema_id);
+CREATE INDEX IF NOT EXISTS idx_schema_profiles_data_source_id ON schema_profiles(data_source_id);
+
+COMMENT ON TABLE schema_profiles IS 'Cached data profiles of data source tables';
+
+-- +goose Down
+DROP TABLE IF EXISTS schema_profiles;
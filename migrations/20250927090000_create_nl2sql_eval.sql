@@ -0,0 +1,71 @@
+-- +goose Up
+-- Migration: Create NL2SQL evaluation tables
+-- Description: Golden NL to SQL pairs per data source, and evaluation runs that
+-- compare the generated queries with them, with per-query results
+
+CREATE TABLE IF NOT EXISTS golden_queries (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    nl_query TEXT NOT NULL,
+    expected_sql TEXT NOT NULL,
+    notes TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_golden_queries_data_source_id ON golden_queries(data_source_id);
+
+CREATE TABLE IF NOT EXISTS eval_runs (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    label VARCHAR(255),
+    model VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    total INTEGER NOT NULL DEFAULT 0,
+    evaluated INTEGER NOT NULL DEFAULT 0,
+    matches INTEGER NOT NULL DEFAULT 0,
+    exact_matches INTEGER NOT NULL DEFAULT 0,
+    generation_failures INTEGER NOT NULL DEFAULT 0,
+    execution_failures INTEGER NOT NULL DEFAULT 0,
+    golden_failures INTEGER NOT NULL DEFAULT 0,
+    accuracy DOUBLE PRECISION NOT NULL DEFAULT 0,
+    exact_match_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    avg_generation_ms BIGINT NOT NULL DEFAULT 0,
+    error_msg TEXT,
+    triggered_by INTEGER,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_eval_runs_data_source_id ON eval_runs(data_source_id);
+
+CREATE TABLE IF NOT EXISTS eval_results (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL,
+    golden_query_id INTEGER NOT NULL,
+    nl_query TEXT,
+    expected_sql TEXT,
+    generated_sql TEXT,
+    outcome VARCHAR(30) NOT NULL,
+    sql_match BOOLEAN NOT NULL DEFAULT FALSE,
+    expected_rows INTEGER NOT NULL DEFAULT 0,
+    generated_rows INTEGER NOT NULL DEFAULT 0,
+    generation_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_eval_results_run_id ON eval_results(run_id);
+
+COMMENT ON TABLE golden_queries IS 'Natural language questions with the SQL known to answer them, per data source';
+COMMENT ON TABLE eval_runs IS 'NL2SQL accuracy evaluation runs over the golden queries of a data source';
+COMMENT ON TABLE eval_results IS 'Outcome of each golden query in an evaluation run';
+
+-- +goose Down
+DROP TABLE IF EXISTS eval_results;
+DROP TABLE IF EXISTS eval_runs;
+DROP TABLE IF EXISTS golden_queries;
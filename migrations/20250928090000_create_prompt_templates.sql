@@ -0,0 +1,23 @@
+-- +goose Up
+-- Migration: Create prompt templates table
+-- Description: Versions of the NL2SQL prompt template of the workspace (data_source_id NULL)
+-- and of data source overrides; one version per scope is active
+
+CREATE TABLE IF NOT EXISTS prompt_templates (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER,
+    version INTEGER NOT NULL,
+    template TEXT NOT NULL,
+    comment VARCHAR(500),
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_scope_version ON prompt_templates(COALESCE(data_source_id, 0), version);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_scope_active ON prompt_templates(COALESCE(data_source_id, 0)) WHERE is_active;
+
+COMMENT ON TABLE prompt_templates IS 'Versioned NL2SQL prompt templates with per data source overrides';
+
+-- +goose Down
+DROP TABLE IF EXISTS prompt_templates;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Migration: Create saved queries table
+-- Description: Bookmarked questions with their pinned SQL, tagged and optionally
+-- shared with the workspace, to be run again on fresh data
+
+CREATE TABLE IF NOT EXISTS saved_queries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    data_source_id INTEGER NOT NULL,
+    query_id INTEGER,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    tags JSONB DEFAULT '[]',
+    nl_query TEXT,
+    sql TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    run_count INTEGER NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_queries_user_id ON saved_queries(user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_queries_data_source_id ON saved_queries(data_source_id);
+CREATE INDEX IF NOT EXISTS idx_saved_queries_shared ON saved_queries(shared) WHERE shared;
+CREATE INDEX IF NOT EXISTS idx_saved_queries_tags ON saved_queries USING GIN (tags);
+
+COMMENT ON TABLE saved_queries IS 'Saved queries with pinned SQL, tags and workspace sharing';
+
+-- +goose Down
+DROP TABLE IF EXISTS saved_queries;
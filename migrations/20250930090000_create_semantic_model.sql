@@ -0,0 +1,43 @@
+-- +goose Up
+-- Migration: Create semantic model
+-- Description: Metrics and dimensions per data source, compiled to SQL by NL2SQL instead of free-form generation
+
+CREATE TABLE IF NOT EXISTS semantic_metrics (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    name VARCHAR(128) NOT NULL, -- Lower-case metric name
+    synonyms JSONB,
+    description TEXT,
+    table_name VARCHAR(255) NOT NULL,
+    expression TEXT NOT NULL, -- Aggregate over the table, e.g. SUM(amount)
+    filter TEXT,
+    time_column VARCHAR(255),
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_semantic_metrics_name ON semantic_metrics(data_source_id, name);
+
+CREATE TABLE IF NOT EXISTS semantic_dimensions (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    name VARCHAR(128) NOT NULL, -- Lower-case dimension name
+    synonyms JSONB,
+    description TEXT,
+    table_name VARCHAR(255) NOT NULL,
+    column_name VARCHAR(255) NOT NULL,
+    is_time BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_semantic_dimensions_name ON semantic_dimensions(data_source_id, name);
+
+COMMENT ON TABLE semantic_metrics IS 'Business metrics defined as aggregates over a table of a data source';
+COMMENT ON TABLE semantic_dimensions IS 'Columns metrics can be broken down by';
+
+-- +goose Down
+DROP TABLE IF EXISTS semantic_dimensions;
+DROP TABLE IF EXISTS semantic_metrics;
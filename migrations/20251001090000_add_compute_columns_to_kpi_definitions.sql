@@ -0,0 +1,15 @@
+-- +goose Up
+-- Migration: Add compute columns to KPI definitions
+-- Description: Data source, table and time column a KPI formula is computed on for KPI cards
+
+ALTER TABLE kpi_definitions ADD COLUMN IF NOT EXISTS data_source_id INTEGER;
+ALTER TABLE kpi_definitions ADD COLUMN IF NOT EXISTS source_table VARCHAR(255);
+ALTER TABLE kpi_definitions ADD COLUMN IF NOT EXISTS time_column VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_kpi_definitions_data_source_id ON kpi_definitions(data_source_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_kpi_definitions_data_source_id;
+ALTER TABLE kpi_definitions DROP COLUMN IF EXISTS time_column;
+ALTER TABLE kpi_definitions DROP COLUMN IF EXISTS source_table;
+ALTER TABLE kpi_definitions DROP COLUMN IF EXISTS data_source_id;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Migration: Add translations to business glossaries
+-- Description: Phrases of a glossary term per language, used to expand questions asked in other languages
+
+ALTER TABLE business_glossaries ADD COLUMN IF NOT EXISTS translations JSONB;
+
+-- +goose Down
+ALTER TABLE business_glossaries DROP COLUMN IF EXISTS translations;
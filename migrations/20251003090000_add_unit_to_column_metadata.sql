@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: Add unit and currency to column metadata
+-- Description: Units and currencies of columns, used to annotate query results with formatting metadata
+
+ALTER TABLE column_metadata ADD COLUMN IF NOT EXISTS unit VARCHAR(50);
+ALTER TABLE column_metadata ADD COLUMN IF NOT EXISTS currency VARCHAR(3);
+
+-- +goose Down
+ALTER TABLE column_metadata DROP COLUMN IF EXISTS currency;
+ALTER TABLE column_metadata DROP COLUMN IF EXISTS unit;
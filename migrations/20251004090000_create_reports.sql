@@ -0,0 +1,46 @@
+-- +goose Up
+-- Migration: Create report tables
+-- Description: Reports composed of saved queries, KPIs and dashboards, delivered on a schedule, and their run history
+
+CREATE TABLE IF NOT EXISTS reports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL, -- Owner; items run as this user
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    format VARCHAR(10) NOT NULL DEFAULT 'html', -- html, pdf
+    frequency VARCHAR(10) NOT NULL DEFAULT 'off', -- off, daily, weekly
+    recipients JSONB, -- Email addresses; the owner when empty
+    items JSONB, -- [{"type": "saved_query|kpi|dashboard", "ref_id": 1, "title": "..."}]
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_user_id ON reports(user_id);
+CREATE INDEX IF NOT EXISTS idx_reports_next_run_at ON reports(next_run_at);
+CREATE INDEX IF NOT EXISTS idx_reports_deleted_at ON reports(deleted_at);
+
+CREATE TABLE IF NOT EXISTS report_runs (
+    id SERIAL PRIMARY KEY,
+    report_id INTEGER NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    trigger VARCHAR(20) NOT NULL, -- schedule, manual
+    status VARCHAR(20) NOT NULL, -- running, succeeded, failed
+    format VARCHAR(10) NOT NULL,
+    output_key VARCHAR(500), -- Object store key of the rendered document
+    output_size BIGINT NOT NULL DEFAULT 0,
+    recipients INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_runs_report_id_started_at ON report_runs(report_id, started_at);
+
+COMMENT ON TABLE reports IS 'Reports of saved queries, KPIs and dashboards rendered to HTML or PDF and emailed on a schedule';
+COMMENT ON TABLE report_runs IS 'Renderings and deliveries of reports, with the stored document to download';
+
+-- +goose Down
+DROP TABLE IF EXISTS report_runs;
+DROP TABLE IF EXISTS reports;
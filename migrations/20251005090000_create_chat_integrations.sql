@@ -0,0 +1,57 @@
+-- +goose Up
+-- Migration: Create chat integration and alert rule tables
+-- Description: Workspace-level Slack and Teams integrations, and KPI threshold alert rules delivered through them
+
+CREATE TABLE IF NOT EXISTS chat_integrations (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL, -- slack, teams
+    name VARCHAR(255) NOT NULL,
+    webhook_url TEXT, -- Incoming webhook
+    bot_token TEXT, -- Slack app bot token
+    signing_secret TEXT, -- Slack signing secret or Teams outgoing webhook token
+    default_channel VARCHAR(255),
+    command_user_id INTEGER, -- User commands run as
+    command_data_source_id INTEGER, -- Data source commands are asked about
+    created_by INTEGER NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_integrations_deleted_at ON chat_integrations(deleted_at);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL, -- Owner; the KPI is computed as this user
+    name VARCHAR(255) NOT NULL,
+    kpi_id INTEGER NOT NULL,
+    condition VARCHAR(10) NOT NULL, -- above, below
+    threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+    integration_id INTEGER NOT NULL,
+    channel VARCHAR(255), -- Defaults to the integration's channel
+    interval_minutes INTEGER NOT NULL DEFAULT 60,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    triggered BOOLEAN NOT NULL DEFAULT false,
+    last_value DOUBLE PRECISION,
+    last_error TEXT,
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    last_triggered_at TIMESTAMP WITH TIME ZONE,
+    next_check_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_user_id ON alert_rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_kpi_id ON alert_rules(kpi_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_integration_id ON alert_rules(integration_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_next_check_at ON alert_rules(next_check_at);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_deleted_at ON alert_rules(deleted_at);
+
+COMMENT ON TABLE chat_integrations IS 'Slack and Microsoft Teams destinations for shared query results and alerts, and command endpoints';
+COMMENT ON TABLE alert_rules IS 'KPI threshold rules posted to a chat integration when triggered and when resolved';
+
+-- +goose Down
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS chat_integrations;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Migration: Create share links table
+-- Description: Public read-only links to a query result or a dashboard; only the SHA-256 hash
+-- of the token is stored, and links expire, can be revoked and may require a password
+
+CREATE TABLE IF NOT EXISTS share_links (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    resource_type VARCHAR(20) NOT NULL,
+    query_id INTEGER,
+    result_id INTEGER,
+    dashboard_id INTEGER,
+    title VARCHAR(255),
+    token_prefix VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    password_hash VARCHAR(255),
+    row_limit INTEGER NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    view_count BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_share_links_token_hash ON share_links(token_hash);
+CREATE INDEX IF NOT EXISTS idx_share_links_user_id ON share_links(user_id);
+CREATE INDEX IF NOT EXISTS idx_share_links_query_id ON share_links(query_id);
+CREATE INDEX IF NOT EXISTS idx_share_links_dashboard_id ON share_links(dashboard_id);
+
+COMMENT ON TABLE share_links IS 'Expiring, revocable public links to query results and dashboards';
+
+-- +goose Down
+DROP TABLE IF EXISTS share_links;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Migration: Create file uploads tables
+-- Description: Chunked, resumable uploads of large CSV/Excel files; parts are stored in the
+-- upload store as they arrive and assembled in the background when the upload is completed
+
+CREATE TABLE IF NOT EXISTS file_uploads (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL,
+    mime_type VARCHAR(255),
+    chunk_size BIGINT NOT NULL,
+    total_parts INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'uploading',
+    options JSONB,
+    object_key VARCHAR(512),
+    tables JSONB,
+    error TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_uploads_user_id ON file_uploads(user_id);
+CREATE INDEX IF NOT EXISTS idx_file_uploads_status ON file_uploads(status);
+CREATE INDEX IF NOT EXISTS idx_file_uploads_expires_at ON file_uploads(expires_at);
+
+CREATE TABLE IF NOT EXISTS file_upload_parts (
+    id SERIAL PRIMARY KEY,
+    upload_id INTEGER NOT NULL REFERENCES file_uploads(id) ON DELETE CASCADE,
+    part_number INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_file_upload_parts_number ON file_upload_parts(upload_id, part_number);
+
+COMMENT ON TABLE file_uploads IS 'Chunked uploads of large CSV/Excel files and their inferred tables';
+COMMENT ON TABLE file_upload_parts IS 'Received parts of chunked file uploads';
+
+-- +goose Down
+DROP TABLE IF EXISTS file_upload_parts;
+DROP TABLE IF EXISTS file_uploads;
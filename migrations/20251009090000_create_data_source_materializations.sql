@@ -0,0 +1,27 @@
+-- +goose Up
+-- Migration: Create data source materializations table
+-- Description: Local copies of the tables of slow data sources (Google Sheets, REST APIs) that
+-- queries run on, with the watermark of the last incremental pull
+
+CREATE TABLE IF NOT EXISTS data_source_materializations (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    table_name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(1024) NOT NULL,
+    mode VARCHAR(20),
+    watermark_column VARCHAR(255),
+    watermark VARCHAR(255),
+    row_count BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'syncing',
+    error TEXT,
+    synced_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_source_materializations_table ON data_source_materializations(data_source_id, table_name);
+
+COMMENT ON TABLE data_source_materializations IS 'Materialized copies of data source tables and their incremental sync watermarks';
+
+-- +goose Down
+DROP TABLE IF EXISTS data_source_materializations;
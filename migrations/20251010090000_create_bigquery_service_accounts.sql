@@ -0,0 +1,24 @@
+-- +goose Up
+-- Migration: Create BigQuery service accounts table
+-- Description: Validated service account keys users uploaded for BigQuery data sources,
+-- stored encrypted and referenced from data source configs
+
+CREATE TABLE IF NOT EXISTS bigquery_service_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    project_id VARCHAR(255) NOT NULL,
+    client_email VARCHAR(255) NOT NULL,
+    private_key_id VARCHAR(255),
+    encrypted_key TEXT NOT NULL,
+    validated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bigquery_service_accounts_user_id ON bigquery_service_accounts(user_id);
+
+COMMENT ON TABLE bigquery_service_accounts IS 'Encrypted BigQuery service account keys uploaded by users';
+
+-- +goose Down
+DROP TABLE IF EXISTS bigquery_service_accounts;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: Add content hashes to schema embeddings and progress to sync runs
+-- Description: Syncs re-embed only the schema elements whose content hash changed, and record their progress after every schema
+
+ALTER TABLE schema_embeddings ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS schemas_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS schemas_synced INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS schemas_failed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS elements_embedded INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS elements_unchanged INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS elements_removed INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS elements_removed;
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS elements_unchanged;
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS elements_embedded;
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS schemas_failed;
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS schemas_synced;
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS schemas_total;
+
+ALTER TABLE schema_embeddings DROP COLUMN IF EXISTS content_hash;
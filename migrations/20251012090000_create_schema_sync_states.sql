@@ -0,0 +1,17 @@
+-- +goose Up
+-- Migration: Create schema sync states table
+-- Description: What the last completed embedding sync of each data source embedded, to detect schema, KPI and glossary changes
+
+CREATE TABLE IF NOT EXISTS schema_sync_states (
+    data_source_id INTEGER PRIMARY KEY, -- 0 for the KPI and glossary embeddings
+    source_version TIMESTAMP WITH TIME ZONE,
+    source_count BIGINT NOT NULL DEFAULT 0,
+    sync_id VARCHAR(64),
+    synced_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE schema_sync_states IS 'Fingerprint of the schemas, KPIs and glossary terms each data source last synced embeddings for';
+
+-- +goose Down
+DROP TABLE IF EXISTS schema_sync_states;
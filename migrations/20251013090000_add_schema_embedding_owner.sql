@@ -0,0 +1,35 @@
+-- +goose Up
+-- Migration: Add owner to schema embeddings
+-- Description: Scope embeddings to the user owning them so searches never retrieve another user's KPIs, glossary terms or tables
+
+ALTER TABLE schema_embeddings ADD COLUMN IF NOT EXISTS user_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_schema_embeddings_user_id ON schema_embeddings(user_id);
+
+COMMENT ON COLUMN schema_embeddings.user_id IS 'Owner: the user of a KPI or glossary term, or the owner of the data source of a table or column';
+
+-- KPI and glossary embeddings carry their user in the metadata
+UPDATE schema_embeddings
+SET user_id = (metadata->>'user_id')::integer
+WHERE user_id IS NULL
+    AND element_type IN ('kpi', 'glossary')
+    AND metadata->>'user_id' ~ '^[1-9][0-9]*$';
+
+-- Tables and columns belong to the owner of their data source
+UPDATE schema_embeddings e
+SET user_id = ds.user_id
+FROM data_sources ds
+WHERE e.user_id IS NULL
+    AND e.element_type IN ('table', 'column')
+    AND ds.id = e.data_source_id;
+
+-- Embeddings without an owner, such as KPIs embedded before the metadata held
+-- the user, are removed; forgetting the KPI and glossary sync state makes the
+-- next sync embed them again
+DELETE FROM schema_embeddings WHERE user_id IS NULL;
+DELETE FROM schema_sync_states WHERE data_source_id = 0;
+
+ALTER TABLE schema_embeddings ALTER COLUMN user_id SET NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_schema_embeddings_user_id;
+ALTER TABLE schema_embeddings DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Migration: Create retrieval configs
+-- Description: Per-data-source NL2SQL retrieval parameters with a workspace default
+
+CREATE TABLE IF NOT EXISTS retrieval_configs (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER, -- NULL for the workspace default
+    schema_top_k INTEGER NOT NULL DEFAULT 10, -- Tables and columns, ranked together
+    schema_element_types JSONB DEFAULT '["table", "column"]',
+    kpi_top_k INTEGER NOT NULL DEFAULT 5,
+    glossary_top_k INTEGER NOT NULL DEFAULT 5,
+    min_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_context_tokens INTEGER NOT NULL DEFAULT 0, -- 0 = no budget
+    updated_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retrieval_configs_data_source_id ON retrieval_configs(data_source_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retrieval_configs_default ON retrieval_configs((data_source_id IS NULL)) WHERE data_source_id IS NULL;
+
+COMMENT ON TABLE retrieval_configs IS 'How many schema elements, KPIs and glossary terms NL2SQL retrieves, the minimum similarity and the context token budget';
+
+-- +goose Down
+DROP TABLE IF EXISTS retrieval_configs;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Migration: Add prompt token budget to retrieval configs
+-- Description: Budget of the whole NL2SQL prompt; retrieved elements are trimmed, lowest scoring first, until the prompt fits
+
+ALTER TABLE retrieval_configs ADD COLUMN IF NOT EXISTS max_prompt_tokens INTEGER NOT NULL DEFAULT 12000; -- 0 = no budget
+
+-- +goose Down
+ALTER TABLE retrieval_configs DROP COLUMN IF EXISTS max_prompt_tokens;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: Link RAG query contexts to their NL2SQL queries
+-- Description: Each conversion stores the question embedding and retrieved context; similar past questions link back to the query and its SQL
+
+ALTER TABLE rag_query_contexts ADD COLUMN IF NOT EXISTS query_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_rag_query_contexts_query_id ON rag_query_contexts(query_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_rag_query_contexts_query_id;
+ALTER TABLE rag_query_contexts DROP COLUMN IF EXISTS query_id;
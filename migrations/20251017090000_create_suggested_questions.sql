@@ -0,0 +1,21 @@
+-- +goose Up
+-- Migration: Create suggested questions table
+-- Description: Example questions curated for a data source, suggested to new users before the
+-- questions mined from the query history
+
+CREATE TABLE IF NOT EXISTS suggested_questions (
+    id SERIAL PRIMARY KEY,
+    data_source_id INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_suggested_questions_data_source_id ON suggested_questions(data_source_id);
+
+COMMENT ON TABLE suggested_questions IS 'Example questions curated for data sources';
+
+-- +goose Down
+DROP TABLE IF EXISTS suggested_questions;
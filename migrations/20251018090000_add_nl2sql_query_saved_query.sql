@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: Link saved query runs to their saved query
+-- Description: Runs of a saved query are NL2SQL queries; the link lets two runs of the same saved query be diffed
+
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS saved_query_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_saved_query_id ON nl2_sql_queries(saved_query_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_nl2_sql_queries_saved_query_id;
+ALTER TABLE nl2_sql_queries DROP COLUMN IF EXISTS saved_query_id;
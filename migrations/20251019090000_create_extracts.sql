@@ -0,0 +1,40 @@
+-- +goose Up
+-- Migration: Create extracts table
+-- Description: Snapshots of query results kept in local storage and refreshed on a schedule;
+-- dashboard widgets bound to an extract display its snapshot instead of querying the data source
+
+CREATE TABLE IF NOT EXISTS extracts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    query_id INTEGER NOT NULL,
+    data_source_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    refresh_interval_minutes INTEGER NOT NULL DEFAULT 60,
+    row_limit INTEGER NOT NULL DEFAULT 10000,
+    file_path VARCHAR(1024),
+    columns JSONB,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'syncing',
+    error TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_extracts_user_id ON extracts(user_id);
+CREATE INDEX IF NOT EXISTS idx_extracts_query_id ON extracts(query_id);
+CREATE INDEX IF NOT EXISTS idx_extracts_deleted_at ON extracts(deleted_at);
+
+ALTER TABLE dashboard_widgets ADD COLUMN IF NOT EXISTS extract_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_dashboard_widgets_extract_id ON dashboard_widgets(extract_id);
+
+COMMENT ON TABLE extracts IS 'Scheduled snapshots of query results that dashboard widgets bind to';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_dashboard_widgets_extract_id;
+ALTER TABLE dashboard_widgets DROP COLUMN IF EXISTS extract_id;
+DROP TABLE IF EXISTS extracts;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Migration: Add federation to NL2SQL queries
+-- Description: Federated queries join tables of several data sources in DuckDB; the federation
+-- records the sources, their aliases in the SQL and the rows pulled per table
+
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS federated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS federation JSONB;
+
+-- +goose Down
+ALTER TABLE nl2_sql_queries DROP COLUMN IF EXISTS federation;
+ALTER TABLE nl2_sql_queries DROP COLUMN IF EXISTS federated;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Migration: Add dbt tests and source to column metadata
+-- Description: Tests imported from dbt manifests, and whether a user or a dbt import wrote the
+-- description and tags, so later imports update their own metadata but keep the user's.
+-- Rows without a column name hold the description of the table.
+
+ALTER TABLE column_metadata ADD COLUMN IF NOT EXISTS tests JSONB;
+ALTER TABLE column_metadata ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- +goose Down
+ALTER TABLE column_metadata DROP COLUMN IF EXISTS source;
+ALTER TABLE column_metadata DROP COLUMN IF EXISTS tests;
@@ -0,0 +1,98 @@
+-- +goose Up
+-- Migration: Create tenants and scope user-owned tables to them
+-- Description: Tenants isolate organizations sharing an installation. Every user-owned table gets
+-- the tenant of its rows; existing rows, and rows inserted without one, belong to the default tenant.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(63) NOT NULL,
+    domain VARCHAR(255),
+    features JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_slug ON tenants(slug);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_domain ON tenants(domain);
+
+COMMENT ON COLUMN tenants.domain IS 'Host the tenant is served on; requests to it must carry a token of the tenant';
+COMMENT ON COLUMN tenants.features IS 'Feature flags by name; features are on unless set to false';
+
+INSERT INTO tenants (id, name, slug) VALUES (1, 'Default', 'default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), GREATEST((SELECT MAX(id) FROM tenants), 1));
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE data_sources ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE nl2_sql_queries ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE saved_queries ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE dashboards ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE data_api_endpoints ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE digest_subscriptions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE kpi_snapshots ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE extracts ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE file_uploads ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE user_mfas ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE mfa_recovery_codes ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_collaboration_events ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schema_embeddings ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE kpi_definitions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE business_glossaries ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE rag_query_contexts ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE share_links ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE usage_quotas ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bigquery_service_accounts ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_data_sources_tenant_id ON data_sources(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_nl2_sql_queries_tenant_id ON nl2_sql_queries(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_saved_queries_tenant_id ON saved_queries(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_dashboards_tenant_id ON dashboards(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_data_api_endpoints_tenant_id ON data_api_endpoints(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_digest_subscriptions_tenant_id ON digest_subscriptions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_kpi_snapshots_tenant_id ON kpi_snapshots(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_extracts_tenant_id ON extracts(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_file_uploads_tenant_id ON file_uploads(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant_id ON alert_rules(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_user_mfas_tenant_id ON user_mfas(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_tenant_id ON mfa_recovery_codes(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_collaboration_events_tenant_id ON query_collaboration_events(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_schema_embeddings_tenant_id ON schema_embeddings(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_kpi_definitions_tenant_id ON kpi_definitions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_business_glossaries_tenant_id ON business_glossaries(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_rag_query_contexts_tenant_id ON rag_query_contexts(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_reports_tenant_id ON reports(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_share_links_tenant_id ON share_links(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_usage_records_tenant_id ON usage_records(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_usage_quotas_tenant_id ON usage_quotas(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_bigquery_service_accounts_tenant_id ON bigquery_service_accounts(tenant_id);
+
+-- +goose Down
+ALTER TABLE bigquery_service_accounts DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE usage_quotas DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE usage_records DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE share_links DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE reports DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE rag_query_contexts DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE business_glossaries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE kpi_definitions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE schema_embeddings DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_collaboration_events DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE mfa_recovery_codes DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE user_mfas DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE file_uploads DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE extracts DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE kpi_snapshots DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE digest_subscriptions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE data_api_endpoints DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE dashboards DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE saved_queries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE nl2_sql_queries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE data_sources DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Migration: Create feature flags and their overrides
+-- Description: Feature flags gate capabilities as they roll out; an enabled flag is on for its
+-- rollout percentage of users, and overrides turn it on or off for a tenant or user
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+    id SERIAL PRIMARY KEY,
+    key VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    rollout_percentage INTEGER NOT NULL DEFAULT 100 CHECK (rollout_percentage BETWEEN 0 AND 100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flags_key ON feature_flags(key);
+
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    id SERIAL PRIMARY KEY,
+    flag_id INTEGER NOT NULL REFERENCES feature_flags(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('tenant', 'user')),
+    subject_id INTEGER NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_target ON feature_flag_overrides(flag_id, scope, subject_id);
+
+COMMENT ON COLUMN feature_flag_overrides.subject_id IS 'ID of the tenant or user the override applies to';
+
+-- Federated queries shipped before the flag; keep them on for everyone
+INSERT INTO feature_flags (key, description, enabled, rollout_percentage)
+VALUES ('federated_queries', 'Questions spanning several data sources, joined in DuckDB', TRUE, 100)
+ON CONFLICT (key) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS feature_flag_overrides;
+DROP TABLE IF EXISTS feature_flags;
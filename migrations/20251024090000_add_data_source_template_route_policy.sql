@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration: Add the data source template route policy
+-- Description: Users reach the data source templates; installations seeded before the templates
+-- existed only get the policy through this migration
+
+INSERT INTO casbin_rule (ptype, v0, v1, v2, v3, v4, v5)
+SELECT defaults.ptype, defaults.v0, defaults.v1, defaults.v2, '', '', ''
+FROM (VALUES
+    ('p', 'user', '/api/v1/data-source-templates*', '*')
+) AS defaults(ptype, v0, v1, v2)
+WHERE NOT EXISTS (
+    SELECT 1 FROM casbin_rule rule
+    WHERE rule.ptype = defaults.ptype AND rule.v0 = defaults.v0 AND rule.v1 = defaults.v1 AND rule.v2 = defaults.v2
+);
+
+-- +goose Down
+DELETE FROM casbin_rule
+WHERE ptype = 'p' AND (v0, v1, v2) IN (
+    ('user', '/api/v1/data-source-templates*', '*')
+);
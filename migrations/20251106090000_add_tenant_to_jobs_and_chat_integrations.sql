@@ -0,0 +1,16 @@
+-- +goose Up
+-- Migration: Add the tenant of background jobs and chat integrations
+-- Description: Jobs run for the tenant they were queued in, or for the whole installation when 0.
+-- Chat integrations answer commands in the tenant of the admin who configured them.
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chat_integrations ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_id ON jobs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_chat_integrations_tenant_id ON chat_integrations(tenant_id);
+
+COMMENT ON COLUMN jobs.tenant_id IS 'Tenant the job runs for; 0 for jobs of the whole installation';
+
+-- +goose Down
+ALTER TABLE chat_integrations DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Migration: Add the tenant of schema sync runs
+-- Description: Sync runs belong to the tenant of their data source; existing runs are backfilled from it.
+
+ALTER TABLE schema_sync_runs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+
+UPDATE schema_sync_runs run SET tenant_id = ds.tenant_id
+FROM data_sources ds
+WHERE ds.id = run.data_source_id AND run.tenant_id <> ds.tenant_id;
+
+CREATE INDEX IF NOT EXISTS idx_schema_sync_runs_tenant_id ON schema_sync_runs(tenant_id);
+
+-- +goose Down
+ALTER TABLE schema_sync_runs DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Migration: Add the tenant of webhooks and their deliveries
+-- Description: Webhooks only receive the events of their tenant. Existing webhooks were managed by the
+-- admins of the default tenant, so they belong to it; deliveries take the tenant of their webhook.
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+
+UPDATE webhook_deliveries delivery SET tenant_id = webhook.tenant_id
+FROM webhooks webhook
+WHERE webhook.id = delivery.webhook_id AND delivery.tenant_id <> webhook.tenant_id;
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant_id ON webhook_deliveries(tenant_id);
+
+-- +goose Down
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Migration: Add the tenant of the query analytics rollup
+-- Description: The rollup is aggregated per tenant so metrics of one tenant never include the queries of
+-- another. Existing rows take the tenant of their user, which is the tenant of all of its queries.
+
+ALTER TABLE query_analytics_daily ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+
+UPDATE query_analytics_daily rollup SET tenant_id = u.tenant_id
+FROM users u
+WHERE u.id = rollup.user_id AND rollup.tenant_id <> u.tenant_id;
+
+ALTER TABLE query_analytics_daily DROP CONSTRAINT IF EXISTS query_analytics_daily_pkey;
+ALTER TABLE query_analytics_daily ADD PRIMARY KEY (day, tenant_id, user_id, data_source_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_query_analytics_daily_tenant_day ON query_analytics_daily(tenant_id, day);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_query_analytics_daily_tenant_day;
+ALTER TABLE query_analytics_daily DROP CONSTRAINT IF EXISTS query_analytics_daily_pkey;
+ALTER TABLE query_analytics_daily ADD PRIMARY KEY (day, user_id, data_source_id, status);
+ALTER TABLE query_analytics_daily DROP COLUMN IF EXISTS tenant_id;
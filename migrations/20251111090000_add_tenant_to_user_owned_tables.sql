@@ -0,0 +1,233 @@
+-- +goose Up
+-- Migration: Add the tenant of the remaining user-owned tables
+-- Description: Every table holding rows of users, their data sources, queries, dashboards or reports
+-- gets the tenant of the row, so statements in the context of a tenant are scoped to its rows. Existing
+-- rows take the tenant of the row they belong to.
+
+ALTER TABLE schemas ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE column_metadata ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE connection_health_logs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE custom_sql_functions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE data_source_discovery_events ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE data_source_materializations ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schema_profiles ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schema_changes ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE semantic_metrics ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE semantic_dimensions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE suggested_questions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE golden_queries ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE eval_runs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE retrieval_configs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE validation_policies ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE prompt_templates ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE dashboard_widgets ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE dashboard_collaborators ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE data_api_keys ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE file_upload_parts ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE report_runs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_results ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_result_chunks ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_sql_versions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_collaboration_links ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_sql_suggestions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE eval_results ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE query_cost_ceilings ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE governance_events ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1;
+
+-- Data source metadata, history and settings take the tenant of their data source; workspace defaults
+-- without a data source stay in the default tenant
+UPDATE schemas child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE column_metadata child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE connection_health_logs child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE custom_sql_functions child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE data_source_discovery_events child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE data_source_materializations child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE schema_profiles child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE schema_changes child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE semantic_metrics child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE semantic_dimensions child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE suggested_questions child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE golden_queries child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE eval_runs child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE retrieval_configs child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE validation_policies child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE prompt_templates child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+-- Rows of dashboards, data APIs, uploads and reports take the tenant of their parent
+UPDATE dashboard_widgets child SET tenant_id = parent.tenant_id
+FROM dashboards parent
+WHERE parent.id = child.dashboard_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE dashboard_collaborators child SET tenant_id = parent.tenant_id
+FROM dashboards parent
+WHERE parent.id = child.dashboard_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE data_api_keys child SET tenant_id = parent.tenant_id
+FROM data_api_endpoints parent
+WHERE parent.id = child.endpoint_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE file_upload_parts child SET tenant_id = parent.tenant_id
+FROM file_uploads parent
+WHERE parent.id = child.upload_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE report_runs child SET tenant_id = parent.tenant_id
+FROM reports parent
+WHERE parent.id = child.report_id AND child.tenant_id <> parent.tenant_id;
+
+-- Results, SQL versions and collaboration of queries take the tenant of their query
+UPDATE query_results child SET tenant_id = parent.tenant_id
+FROM nl2_sql_queries parent
+WHERE parent.id = child.query_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE query_result_chunks child SET tenant_id = parent.tenant_id
+FROM query_results parent
+WHERE parent.id = child.result_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE query_sql_versions child SET tenant_id = parent.tenant_id
+FROM nl2_sql_queries parent
+WHERE parent.id = child.query_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE query_collaboration_links child SET tenant_id = parent.tenant_id
+FROM nl2_sql_queries parent
+WHERE parent.id = child.query_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE query_sql_suggestions child SET tenant_id = parent.tenant_id
+FROM nl2_sql_queries parent
+WHERE parent.id = child.query_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE eval_results child SET tenant_id = parent.tenant_id
+FROM eval_runs parent
+WHERE parent.id = child.run_id AND child.tenant_id <> parent.tenant_id;
+
+-- Cost ceilings take the tenant of their user or data source, audit logs that of their actor, governance
+-- events that of their data source or else their actor
+UPDATE query_cost_ceilings child SET tenant_id = parent.tenant_id
+FROM users parent
+WHERE parent.id = child.user_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE query_cost_ceilings child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE audit_logs child SET tenant_id = parent.tenant_id
+FROM users parent
+WHERE parent.id = child.actor_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE governance_events child SET tenant_id = parent.tenant_id
+FROM data_sources parent
+WHERE parent.id = child.data_source_id AND child.tenant_id <> parent.tenant_id;
+
+UPDATE governance_events child SET tenant_id = parent.tenant_id
+FROM users parent
+WHERE parent.id = child.actor_id AND COALESCE(child.data_source_id, 0) = 0 AND child.tenant_id <> parent.tenant_id;
+
+CREATE INDEX IF NOT EXISTS idx_schemas_tenant_id ON schemas(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_column_metadata_tenant_id ON column_metadata(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_connection_health_logs_tenant_id ON connection_health_logs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_custom_sql_functions_tenant_id ON custom_sql_functions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_data_source_discovery_events_tenant_id ON data_source_discovery_events(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_data_source_materializations_tenant_id ON data_source_materializations(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_schema_profiles_tenant_id ON schema_profiles(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_schema_changes_tenant_id ON schema_changes(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_semantic_metrics_tenant_id ON semantic_metrics(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_semantic_dimensions_tenant_id ON semantic_dimensions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_suggested_questions_tenant_id ON suggested_questions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_golden_queries_tenant_id ON golden_queries(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_eval_runs_tenant_id ON eval_runs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_retrieval_configs_tenant_id ON retrieval_configs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_validation_policies_tenant_id ON validation_policies(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_prompt_templates_tenant_id ON prompt_templates(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_dashboard_widgets_tenant_id ON dashboard_widgets(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_dashboard_collaborators_tenant_id ON dashboard_collaborators(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_data_api_keys_tenant_id ON data_api_keys(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_file_upload_parts_tenant_id ON file_upload_parts(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_report_runs_tenant_id ON report_runs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_results_tenant_id ON query_results(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_result_chunks_tenant_id ON query_result_chunks(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_sql_versions_tenant_id ON query_sql_versions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_collaboration_links_tenant_id ON query_collaboration_links(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_sql_suggestions_tenant_id ON query_sql_suggestions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_eval_results_tenant_id ON eval_results(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_query_cost_ceilings_tenant_id ON query_cost_ceilings(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_governance_events_tenant_id ON governance_events(tenant_id);
+
+-- +goose Down
+ALTER TABLE governance_events DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_cost_ceilings DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE eval_results DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_sql_suggestions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_collaboration_links DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_sql_versions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_result_chunks DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE query_results DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE report_runs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE file_upload_parts DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE data_api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE dashboard_collaborators DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE dashboard_widgets DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE prompt_templates DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE validation_policies DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE retrieval_configs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE eval_runs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE golden_queries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE suggested_questions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE semantic_dimensions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE semantic_metrics DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE schema_changes DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE schema_profiles DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE data_source_materializations DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE data_source_discovery_events DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE custom_sql_functions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE connection_health_logs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE column_metadata DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE schemas DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: Make the retrieval workspace default per tenant
+-- Description: Each tenant has its own workspace default, so the default is unique per tenant rather than per installation
+
+DROP INDEX IF EXISTS idx_retrieval_configs_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retrieval_configs_default ON retrieval_configs(tenant_id) WHERE data_source_id IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_retrieval_configs_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retrieval_configs_default ON retrieval_configs((data_source_id IS NULL)) WHERE data_source_id IS NULL;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Migration: Make the validation policy and prompt template workspace defaults per tenant
+-- Description: Each tenant has its own workspace default policy and prompt template versions, so they are unique per tenant rather than per installation
+
+DROP INDEX IF EXISTS idx_validation_policies_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_validation_policies_default ON validation_policies(tenant_id) WHERE data_source_id IS NULL;
+
+DROP INDEX IF EXISTS idx_prompt_templates_scope_version;
+DROP INDEX IF EXISTS idx_prompt_templates_scope_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_scope_version ON prompt_templates(tenant_id, COALESCE(data_source_id, 0), version);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_scope_active ON prompt_templates(tenant_id, COALESCE(data_source_id, 0)) WHERE is_active;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_prompt_templates_scope_active;
+DROP INDEX IF EXISTS idx_prompt_templates_scope_version;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_scope_version ON prompt_templates(COALESCE(data_source_id, 0), version);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_scope_active ON prompt_templates(COALESCE(data_source_id, 0)) WHERE is_active;
+
+DROP INDEX IF EXISTS idx_validation_policies_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_validation_policies_default ON validation_policies((data_source_id IS NULL)) WHERE data_source_id IS NULL;
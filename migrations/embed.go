@@ -0,0 +1,10 @@
+// Package migrations holds the SQL migrations of the database, embedded in the
+// binary so it can apply them without the migration files
+package migrations
+
+import "embed"
+
+// FS holds the migration files
+//
+//go:embed *.sql
+var FS embed.FS